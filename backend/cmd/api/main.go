@@ -9,14 +9,12 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Digest send windows use the users' time zones; the runtime image has no zoneinfo
 
-	"github.com/gin-gonic/gin"
 	"github.com/xuangong/backend/internal/config"
 	"github.com/xuangong/backend/internal/database"
-	"github.com/xuangong/backend/internal/handlers"
-	"github.com/xuangong/backend/internal/middleware"
-	"github.com/xuangong/backend/internal/repositories"
-	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/jobs"
+	"github.com/xuangong/backend/internal/server"
 )
 
 func main() {
@@ -32,48 +30,155 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer database.Close(pool)
+	database.ConfigureRetry(&cfg.Database)
 
 	// Run migrations
 	if err := database.RunMigrations(cfg.Database.URL, "migrations"); err != nil {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
-	// Initialize repositories
-	userRepo := repositories.NewUserRepository(pool)
-	programRepo := repositories.NewProgramRepository(pool)
-	exerciseRepo := repositories.NewExerciseRepository(pool)
-	sessionRepo := repositories.NewSessionRepository(pool)
-	submissionRepo := repositories.NewSubmissionRepository(pool)
-
-	// Initialize services
-	authService := services.NewAuthService(userRepo, cfg)
-	programService := services.NewProgramService(programRepo, exerciseRepo)
-	sessionService := services.NewSessionService(sessionRepo, programRepo)
-	userService := services.NewUserService(userRepo, programRepo, exerciseRepo)
-	submissionService := services.NewSubmissionService(submissionRepo, programRepo)
-
-	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService)
-	programHandler := handlers.NewProgramHandler(programService)
-	sessionHandler := handlers.NewSessionHandler(sessionService)
-	userHandler := handlers.NewUserHandler(userService)
-	submissionHandler := handlers.NewSubmissionHandler(submissionService)
-
-	// Setup router
-	router := setupRouter(cfg, authService, authHandler, programHandler, sessionHandler, userHandler, submissionHandler)
-
-	// Suppress unused variable warnings
-	_ = exerciseRepo
+	// Wire repositories, services and handlers
+	api, err := server.New(cfg, pool)
+	if err != nil {
+		log.Fatalf("Failed to initialize server: %v", err)
+	}
 
 	// Create server
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%s", cfg.Server.Port),
-		Handler:      router,
+		Handler:      api.Handler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Background jobs
+	scheduler := jobs.NewScheduler()
+	scheduler.Every("purge-deleted-sessions", time.Hour, func(ctx context.Context) error {
+		purged, err := api.SessionService.PurgeDeletedSessions(ctx)
+		if err != nil {
+			return err
+		}
+		if purged > 0 {
+			log.Printf("[INFO] Purged %d deleted sessions", purged)
+		}
+		return nil
+	})
+	scheduler.Every("data-retention", 15*time.Minute, func(ctx context.Context) error {
+		runs, err := api.RetentionService.RunDue(ctx)
+		for _, run := range runs {
+			var total int64
+			for _, n := range run.Affected {
+				total += n
+			}
+			if run.DryRun {
+				log.Printf("[INFO] Retention rule %s (dry run) would change %d rows", run.Rule, total)
+			} else {
+				log.Printf("[INFO] Retention rule %s changed %d rows", run.Rule, total)
+			}
+		}
+		return err
+	})
+	scheduler.Every("program-recount-retries", time.Minute, func(ctx context.Context) error {
+		updated, err := api.SessionService.RetryRecounts(ctx)
+		if updated > 0 {
+			log.Printf("[INFO] Updated %d program repetition counts on retry", updated)
+		}
+		return err
+	})
+	scheduler.Every("repetitions-reconciliation", 15*time.Minute, func(ctx context.Context) error {
+		report, err := api.SessionService.ReconcileIfDue(ctx)
+		if err != nil {
+			return err
+		}
+		if report != nil {
+			log.Printf("[INFO] Reconciled repetitions of %d programs, %d had drifted", report.ProgramsChecked, len(report.Drifted))
+		}
+		return nil
+	})
+	scheduler.Every("program-publish-schedule", time.Minute, func(ctx context.Context) error {
+		published, unpublished, err := api.ProgramService.ApplyPublishSchedule(ctx)
+		if err != nil {
+			return err
+		}
+		if published > 0 || unpublished > 0 {
+			log.Printf("[INFO] Scheduled publishing: %d published, %d unpublished", published, unpublished)
+		}
+		return nil
+	})
+	scheduler.Every("program-fingerprints", 5*time.Minute, func(ctx context.Context) error {
+		refreshed, err := api.ProgramService.RefreshStaleFingerprints(ctx, 500)
+		if err != nil {
+			return err
+		}
+		if refreshed > 0 {
+			log.Printf("[INFO] Refreshed %d program fingerprints", refreshed)
+		}
+		return nil
+	})
+	scheduler.Every("scheduled-messages", time.Minute, func(ctx context.Context) error {
+		delivered, err := api.ScheduledMessageService.DeliverDue(ctx)
+		if err != nil {
+			return err
+		}
+		if delivered > 0 {
+			log.Printf("[INFO] Delivered %d scheduled messages", delivered)
+		}
+		return nil
+	})
+	scheduler.Every("homework-overdue", 5*time.Minute, func(ctx context.Context) error {
+		marked, err := api.HomeworkService.MarkOverdue(ctx)
+		if err != nil {
+			return err
+		}
+		if marked > 0 {
+			log.Printf("[INFO] Marked %d students overdue on homework", marked)
+		}
+		return nil
+	})
+	scheduler.Every("stats-recompute", time.Minute, func(ctx context.Context) error {
+		finished, err := api.StatsRecomputeService.RunPending(ctx)
+		if err != nil {
+			return err
+		}
+		if finished > 0 {
+			log.Printf("[INFO] Finished %d stats recomputes", finished)
+		}
+		return nil
+	})
+	scheduler.Every("integration-nonces", 10*time.Minute, func(ctx context.Context) error {
+		purged, err := api.IntegrationService.PurgeNonces(ctx)
+		if err != nil {
+			return err
+		}
+		if purged > 0 {
+			log.Printf("[INFO] Purged %d expired integration nonces", purged)
+		}
+		return nil
+	})
+	if cfg.Secrets.Provider != "env" && cfg.JWT.SecretRefreshSeconds > 0 {
+		scheduler.Every("jwt-secret-refresh", cfg.JWT.GetSecretRefreshInterval(), func(ctx context.Context) error {
+			rotated, err := api.AuthService.RefreshSigningKeys(ctx)
+			if rotated {
+				log.Printf("[INFO] JWT signing secret rotated; tokens signed with the old secret stay valid until they expire")
+			}
+			return err
+		})
+	}
+	if cfg.Mail.SMTPHost != "" {
+		scheduler.Every("weekly-digest", 15*time.Minute, func(ctx context.Context) error {
+			sent, err := api.DigestService.SendDue(ctx)
+			if err != nil {
+				return err
+			}
+			if sent > 0 {
+				log.Printf("[INFO] Sent %d weekly digests", sent)
+			}
+			return nil
+		})
+	}
+	scheduler.Start(context.Background())
+
 	// Start server in a goroutine
 	go func() {
 		log.Printf("Server starting on port %s (env: %s)", cfg.Server.Port, cfg.Server.Env)
@@ -89,6 +194,8 @@ func main() {
 
 	log.Println("Server shutting down...")
 
+	scheduler.Stop()
+
 	// Graceful shutdown with 10 second timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -97,130 +204,8 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
-	log.Println("Server exited")
-}
-
-func setupRouter(
-	cfg *config.Config,
-	authService *services.AuthService,
-	authHandler *handlers.AuthHandler,
-	programHandler *handlers.ProgramHandler,
-	sessionHandler *handlers.SessionHandler,
-	userHandler *handlers.UserHandler,
-	submissionHandler *handlers.SubmissionHandler,
-) *gin.Engine {
-	// Set gin mode
-	if cfg.Server.Env == "production" {
-		gin.SetMode(gin.ReleaseMode)
-	}
+	// Write the access logs of the last requests
+	api.AccessLogWriter.Close()
 
-	router := gin.New()
-
-	// Global middleware
-	router.Use(gin.Recovery())
-	router.Use(middleware.Logger())
-	router.Use(middleware.CORS(&cfg.CORS))
-	router.Use(middleware.RateLimit(&cfg.RateLimit))
-
-	// Health check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "healthy",
-			"version": cfg.Server.APIVersion,
-		})
-	})
-
-	// API routes
-	api := router.Group(fmt.Sprintf("/api/%s", cfg.Server.APIVersion))
-
-	// Public routes (no auth required)
-	auth := api.Group("/auth")
-	{
-		auth.POST("/register", authHandler.Register)
-		auth.POST("/login", authHandler.Login)
-		auth.POST("/refresh", authHandler.RefreshToken)
-	}
-
-	// Protected routes (require authentication)
-	protected := api.Group("")
-	protected.Use(middleware.Auth(authService))
-	{
-		// Auth
-		protected.POST("/auth/logout", authHandler.Logout)
-		protected.GET("/auth/me", authHandler.GetProfile)
-		protected.PUT("/auth/me", authHandler.UpdateProfile)
-		protected.PUT("/auth/change-password", authHandler.ChangePassword)
-
-		// Impersonate (admin only)
-		authAdmin := protected.Group("/auth")
-		authAdmin.Use(middleware.RequireRole("admin"))
-		{
-			authAdmin.POST("/impersonate/:userId", authHandler.Impersonate)
-		}
-
-		// Programs
-		programs := protected.Group("/programs")
-		{
-			programs.GET("", programHandler.ListPrograms)
-			programs.GET("/:id", programHandler.GetProgram)
-			programs.POST("", programHandler.CreateProgram)       // All users can create programs
-			programs.PUT("/:id", programHandler.UpdateProgram)    // Authorization check in handler
-			programs.DELETE("/:id", programHandler.DeleteProgram) // Authorization check needed
-
-			// Admin only
-			adminPrograms := programs.Group("")
-			adminPrograms.Use(middleware.RequireRole("admin"))
-			{
-				adminPrograms.POST("/:id/assign", programHandler.AssignProgram)
-			}
-		}
-
-		// My programs (student view)
-		protected.GET("/my-programs", programHandler.GetMyPrograms)
-
-		// Sessions
-		sessions := protected.Group("/sessions")
-		{
-			sessions.GET("", sessionHandler.ListSessions)
-			sessions.GET("/stats", sessionHandler.GetStats)
-			sessions.GET("/:id", sessionHandler.GetSession)
-			sessions.POST("/start", sessionHandler.StartSession)
-			sessions.PUT("/:id/exercise/:exercise_id", sessionHandler.LogExercise)
-			sessions.PUT("/:id/complete", sessionHandler.CompleteSession)
-			sessions.DELETE("/:id", sessionHandler.DeleteSession)
-		}
-
-		// Users (admin only)
-		users := protected.Group("/users")
-		users.Use(middleware.RequireRole("admin"))
-		{
-			users.GET("", userHandler.ListUsers)
-			users.GET("/:id", userHandler.GetUser)
-			users.POST("", userHandler.CreateUser)
-			users.PUT("/:id", userHandler.UpdateUser)
-			users.DELETE("/:id", userHandler.DeleteUser)
-			users.GET("/:id/programs", userHandler.GetUserPrograms)
-			users.GET("/:id/sessions", sessionHandler.GetUserSessions)
-			users.PUT("/:id/role", userHandler.UpdateUserRole)
-		}
-
-		// Submissions
-		submissions := protected.Group("/submissions")
-		{
-			submissions.GET("", submissionHandler.ListSubmissions)             // List with filters
-			submissions.GET("/unread-count", submissionHandler.GetUnreadCount) // Get unread counts
-			submissions.GET("/:id", submissionHandler.GetSubmission)           // Get single submission
-			submissions.GET("/:id/messages", submissionHandler.GetMessages)    // Get messages for submission
-			submissions.POST("/:id/messages", submissionHandler.CreateMessage) // Add message to submission
-			submissions.DELETE("/:id", submissionHandler.DeleteSubmission)     // Soft delete (admin only, checked in handler)
-		}
-
-		// Create submission for a program
-		protected.POST("/programs/:id/submissions", submissionHandler.CreateSubmission)
-
-		// Mark message as read
-		protected.PUT("/messages/:id/read", submissionHandler.MarkMessageAsRead)
-	}
-
-	return router
+	log.Println("Server exited")
 }
@@ -0,0 +1,82 @@
+package main
+
+// demoDomain is the email domain of every generated user, by which -reset finds them again
+const demoDomain = "demo.xuangong.local"
+
+// catalogue is the programs the demo instructor teaches
+var catalogue = []program{
+	{
+		name:        "Tai Chi Foundations",
+		description: "Daily basics: standing, Cloud Hands and Single Whip",
+		tags:        []string{"tai-chi", "beginner", "demo"},
+		exercises: []exercise{
+			{name: "Standing Meditation (Zhan Zhuang)", exerciseType: "timed", durationSeconds: intPtr(300), restAfterSeconds: 30},
+			{name: "Cloud Hands (Yun Shou)", exerciseType: "repetition", repetitions: intPtr(10), restAfterSeconds: 30, hasSides: true},
+			{name: "Single Whip", exerciseType: "combined", durationSeconds: intPtr(90), repetitions: intPtr(6), restAfterSeconds: 30, hasSides: true},
+			{name: "Closing Form", exerciseType: "timed", durationSeconds: intPtr(120)},
+		},
+	},
+	{
+		name:        "Eight Brocades (Ba Duan Jin)",
+		description: "The classic Qigong set for a morning routine",
+		tags:        []string{"qigong", "morning", "demo"},
+		exercises: []exercise{
+			{name: "Holding Up the Sky", exerciseType: "repetition", repetitions: intPtr(8), restAfterSeconds: 15},
+			{name: "Drawing the Bow", exerciseType: "repetition", repetitions: intPtr(8), restAfterSeconds: 15, hasSides: true},
+			{name: "Separating Heaven and Earth", exerciseType: "repetition", repetitions: intPtr(8), restAfterSeconds: 15, hasSides: true},
+			{name: "Wise Owl Gazes Backwards", exerciseType: "repetition", repetitions: intPtr(8), restAfterSeconds: 15, hasSides: true},
+			{name: "Bouncing on the Toes", exerciseType: "timed", durationSeconds: intPtr(60)},
+		},
+	},
+	{
+		name:        "Evening Stretch",
+		description: "Gentle stretches to wind down after the day",
+		tags:        []string{"stretching", "evening", "demo"},
+		exercises: []exercise{
+			{name: "Neck Rolls", exerciseType: "repetition", repetitions: intPtr(10), restAfterSeconds: 10},
+			{name: "Seated Forward Fold", exerciseType: "timed", durationSeconds: intPtr(120), restAfterSeconds: 20},
+			{name: "Hip Opener", exerciseType: "timed", durationSeconds: intPtr(60), restAfterSeconds: 20, hasSides: true},
+		},
+	},
+}
+
+var firstNames = []string{
+	"Anna", "Ben", "Chen", "Dana", "Elif", "Felix", "Grace", "Hannah", "Ivan", "Jin",
+	"Katrin", "Lena", "Mei", "Noah", "Olga", "Paul", "Qing", "Rosa", "Sven", "Tara",
+	"Uwe", "Vera", "Wei", "Xiao", "Yusuf", "Zoe",
+}
+
+var lastNames = []string{
+	"Bauer", "Chang", "Fischer", "García", "Hoffmann", "Kim", "Lehmann", "Li", "Meyer", "Nowak",
+	"Okafor", "Petrov", "Richter", "Schmidt", "Smith", "Tanaka", "Wang", "Weber", "Yilmaz", "Zhou",
+}
+
+// submissionTitles are formatted with the name of an exercise
+var submissionTitles = []string{
+	"%s – form check",
+	"%s, filmed from the side",
+	"Question about %s",
+	"%s after two weeks",
+}
+
+var studentReplies = []string{
+	"Here is my recording, I'm not sure about the weight shift.",
+	"My knees hurt a little after this one. Am I going too low?",
+	"Thanks! I tried again with your correction, does it look better?",
+	"I keep losing the breathing rhythm halfway through.",
+	"Is it okay to practice this twice a day?",
+	"I filmed it again in better light.",
+}
+
+var instructorReplies = []string{
+	"Good progress! Keep the shoulders relaxed and let the waist lead.",
+	"Bend the knees a little less and keep them over the toes.",
+	"Much better. Now slow the whole movement down by half.",
+	"Breathe out as the hands sink; don't force the rhythm.",
+	"Once a day is plenty for now, consistency matters more.",
+	"Looks solid. Let's add the next form next week.",
+}
+
+func intPtr(i int) *int {
+	return &i
+}
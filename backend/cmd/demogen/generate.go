@@ -0,0 +1,387 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// options control the size and shape of the generated dataset. The same options always produce
+// the same dataset, IDs included.
+type options struct {
+	Students int
+	Months   int
+	Seed     uint64
+	Until    time.Time // Day of the last generated activity
+}
+
+type dataset struct {
+	instructor  user
+	students    []user
+	programs    []program
+	assignments []assignment
+	sessions    []session
+	logs        []exerciseLog
+	submissions []submission
+	messages    []message
+	reads       []readReceipt
+}
+
+type user struct {
+	id        uuid.UUID
+	email     string
+	fullName  string
+	role      string
+	createdAt time.Time
+}
+
+type program struct {
+	id          uuid.UUID
+	name        string
+	description string
+	tags        []string
+	exercises   []exercise
+}
+
+type exercise struct {
+	id               uuid.UUID
+	name             string
+	exerciseType     string
+	durationSeconds  *int
+	repetitions      *int
+	restAfterSeconds int
+	hasSides         bool
+}
+
+// plannedSeconds is how long the exercise takes as planned, rest included
+func (e exercise) plannedSeconds() int {
+	seconds := 0
+	if e.durationSeconds != nil {
+		seconds = *e.durationSeconds
+	} else if e.repetitions != nil {
+		seconds = *e.repetitions * 6
+	}
+	if e.hasSides {
+		seconds *= 2
+	}
+	return seconds + e.restAfterSeconds
+}
+
+type assignment struct {
+	userID     uuid.UUID
+	programID  uuid.UUID
+	assignedAt time.Time
+}
+
+type session struct {
+	id             uuid.UUID
+	userID         uuid.UUID
+	programID      uuid.UUID
+	startedAt      time.Time
+	completedAt    *time.Time
+	totalSeconds   *int
+	completionRate *float64
+	mood           *int
+	energy         *int
+}
+
+type exerciseLog struct {
+	id                   uuid.UUID
+	sessionID            uuid.UUID
+	exerciseID           uuid.UUID
+	startedAt            time.Time
+	completedAt          *time.Time
+	plannedSeconds       *int
+	actualSeconds        *int
+	repetitionsPlanned   *int
+	repetitionsCompleted *int
+	skipped              bool
+}
+
+type submission struct {
+	id        uuid.UUID
+	programID uuid.UUID
+	userID    uuid.UUID
+	title     string
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+type message struct {
+	id           uuid.UUID
+	submissionID uuid.UUID
+	userID       uuid.UUID
+	content      string
+	createdAt    time.Time
+}
+
+type readReceipt struct {
+	userID    uuid.UUID
+	messageID uuid.UUID
+	readAt    time.Time
+}
+
+// profile is how a student practices: how often, when, and for how long they stick with it
+type profile struct {
+	joinedAt    time.Time
+	stopsAt     time.Time // Last day of practice; students who keep going practice until Until
+	perWeek     float64
+	hour        int
+	programs    []*program
+	moodBase    float64
+	moodTrend   float64 // Change of mood and energy from joining to Until
+	submissions int
+}
+
+type generator struct {
+	opts  options
+	rng   *rand.Rand
+	start time.Time
+	data  dataset
+}
+
+// generate builds the dataset in memory. Nothing random is drawn from outside rng, so the
+// output only depends on the options.
+func generate(opts options) *dataset {
+	g := &generator{
+		opts:  opts,
+		rng:   rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15)),
+		start: opts.Until.AddDate(0, -opts.Months, 0),
+	}
+
+	g.data.instructor = user{
+		id:        g.uuid(),
+		email:     "instructor@" + demoDomain,
+		fullName:  "Demo Instructor",
+		role:      "admin",
+		createdAt: g.start.AddDate(0, -1, 0),
+	}
+	g.programs()
+	for i := 0; i < opts.Students; i++ {
+		g.student(i)
+	}
+	return &g.data
+}
+
+func (g *generator) programs() {
+	for _, p := range catalogue {
+		prog := program{id: g.uuid(), name: p.name, description: p.description, tags: p.tags}
+		for _, e := range p.exercises {
+			e.id = g.uuid()
+			prog.exercises = append(prog.exercises, e)
+		}
+		g.data.programs = append(g.data.programs, prog)
+	}
+}
+
+func (g *generator) student(i int) {
+	first := firstNames[g.rng.IntN(len(firstNames))]
+	last := lastNames[g.rng.IntN(len(lastNames))]
+	p := g.profile()
+	s := user{
+		id:        g.uuid(),
+		email:     fmt.Sprintf("student%04d@%s", i+1, demoDomain),
+		fullName:  first + " " + last,
+		role:      "student",
+		createdAt: p.joinedAt.Add(-time.Duration(g.rng.IntN(72)) * time.Hour),
+	}
+	g.data.students = append(g.data.students, s)
+
+	for _, prog := range p.programs {
+		g.data.assignments = append(g.data.assignments, assignment{userID: s.id, programID: prog.id, assignedAt: s.createdAt})
+	}
+
+	lifetime := p.stopsAt.Sub(p.joinedAt)
+	for day := p.joinedAt; !day.After(p.stopsAt); day = day.AddDate(0, 0, 1) {
+		if g.rng.Float64() >= math.Min(p.perWeek/7*weekdayFactor[day.Weekday()], 0.98) {
+			continue
+		}
+		// Mostly the main program, now and then the second one
+		prog := p.programs[0]
+		if len(p.programs) > 1 && g.rng.Float64() < 0.25 {
+			prog = p.programs[1]
+		}
+		progress := 1.0
+		if lifetime > 0 {
+			progress = float64(day.Sub(p.joinedAt)) / float64(lifetime)
+		}
+		g.session(s.id, prog, day.Add(time.Duration(p.hour)*time.Hour+time.Duration(g.rng.IntN(90))*time.Minute), p.moodBase+p.moodTrend*progress)
+	}
+
+	for n := 0; n < p.submissions; n++ {
+		at := p.joinedAt.Add(time.Duration(g.rng.Int64N(int64(p.stopsAt.Sub(p.joinedAt)) + 1)))
+		g.thread(s.id, p.programs[0], at)
+	}
+}
+
+func (g *generator) profile() profile {
+	var p profile
+
+	// Most students start with the demo, the rest join over the first half of the period
+	days := int(g.opts.Until.Sub(g.start).Hours() / 24)
+	p.joinedAt = g.start
+	if g.rng.Float64() < 0.4 {
+		p.joinedAt = g.start.AddDate(0, 0, g.rng.IntN(days/2+1))
+	}
+	// A quarter give up at some point
+	p.stopsAt = g.opts.Until
+	if g.rng.Float64() < 0.25 {
+		remaining := int(g.opts.Until.Sub(p.joinedAt).Hours() / 24)
+		p.stopsAt = p.joinedAt.AddDate(0, 0, g.rng.IntN(remaining+1))
+	}
+
+	p.perWeek = math.Max(0.5, math.Min(7, g.rng.NormFloat64()*1.5+3.5))
+	switch r := g.rng.Float64(); {
+	case r < 0.55:
+		p.hour = 6 + g.rng.IntN(3)
+	case r < 0.9:
+		p.hour = 18 + g.rng.IntN(3)
+	default:
+		p.hour = 10 + g.rng.IntN(6)
+	}
+
+	// The foundations are the most popular, a third also practice a second program
+	first := 0
+	if r := g.rng.Float64(); r > 0.8 {
+		first = 2
+	} else if r > 0.5 {
+		first = 1
+	}
+	p.programs = []*program{&g.data.programs[first]}
+	if g.rng.Float64() < 0.3 {
+		p.programs = append(p.programs, &g.data.programs[(first+1+g.rng.IntN(len(g.data.programs)-1))%len(g.data.programs)])
+	}
+
+	p.moodBase = 2.5 + g.rng.Float64()*1.5
+	p.moodTrend = g.rng.Float64() * 0.8
+	p.submissions = g.rng.IntN(2)
+	if p.perWeek > 3 {
+		p.submissions += g.rng.IntN(4)
+	}
+	return p
+}
+
+// session adds a practice session with a log per exercise. A few are abandoned, others are cut
+// short; durations vary around the plan.
+func (g *generator) session(userID uuid.UUID, prog *program, startedAt time.Time, mood float64) {
+	if startedAt.After(g.opts.Until.Add(24 * time.Hour)) {
+		return
+	}
+	s := session{id: g.uuid(), userID: userID, programID: prog.id, startedAt: startedAt}
+
+	abandoned := g.rng.Float64() < 0.04
+	rate := 100.0
+	if abandoned {
+		rate = float64(g.rng.IntN(50))
+	} else if g.rng.Float64() < 0.2 {
+		rate = float64(50 + g.rng.IntN(46))
+	}
+	done := int(math.Ceil(float64(len(prog.exercises)) * rate / 100))
+
+	at := startedAt
+	for i, e := range prog.exercises {
+		planned := e.plannedSeconds()
+		l := exerciseLog{id: g.uuid(), sessionID: s.id, exerciseID: e.id, startedAt: at, plannedSeconds: &planned, repetitionsPlanned: e.repetitions}
+		if i >= done {
+			l.skipped = true
+		} else {
+			actual := int(float64(planned) * (0.85 + g.rng.Float64()*0.3))
+			completedAt := at.Add(time.Duration(actual) * time.Second)
+			l.actualSeconds, l.completedAt = &actual, &completedAt
+			if e.repetitions != nil {
+				reps := *e.repetitions
+				if g.rng.Float64() < 0.15 {
+					reps -= g.rng.IntN(reps/2 + 1)
+				}
+				l.repetitionsCompleted = &reps
+			}
+			at = completedAt
+		}
+		g.data.logs = append(g.data.logs, l)
+	}
+
+	if !abandoned {
+		total := int(at.Sub(startedAt).Seconds())
+		completedAt := at
+		s.completedAt, s.totalSeconds, s.completionRate = &completedAt, &total, &rate
+		// Most students rate how they feel, those who do get a little happier over time
+		if g.rng.Float64() < 0.7 {
+			m := clampScale(mood + g.rng.NormFloat64()*0.8)
+			e := clampScale(mood - 0.3 + g.rng.NormFloat64()*0.9)
+			s.mood, s.energy = &m, &e
+		}
+	}
+	g.data.sessions = append(g.data.sessions, s)
+}
+
+// thread adds a submission with a conversation between the student and the instructor. The
+// instructor has read everything but the latest student messages of a few threads.
+func (g *generator) thread(userID uuid.UUID, prog *program, at time.Time) {
+	sub := submission{
+		id:        g.uuid(),
+		programID: prog.id,
+		userID:    userID,
+		title:     fmt.Sprintf(submissionTitles[g.rng.IntN(len(submissionTitles))], prog.exercises[g.rng.IntN(len(prog.exercises))].name),
+		createdAt: at,
+	}
+
+	end := g.opts.Until.Add(24 * time.Hour)
+	unread := g.rng.Float64() < 0.3
+	count := 2 + g.rng.IntN(7)
+	var studentMessages []message
+	for n := 0; n < count && at.Before(end); n++ {
+		m := message{id: g.uuid(), submissionID: sub.id, createdAt: at}
+		if n%2 == 0 {
+			m.userID = userID
+			m.content = studentReplies[g.rng.IntN(len(studentReplies))]
+			studentMessages = append(studentMessages, m)
+			at = at.Add(time.Duration(2+g.rng.IntN(47)) * time.Hour)
+		} else {
+			m.userID = g.data.instructor.id
+			m.content = instructorReplies[g.rng.IntN(len(instructorReplies))]
+			at = at.Add(time.Duration(1+g.rng.IntN(24)) * time.Hour)
+		}
+		g.data.messages = append(g.data.messages, m)
+		sub.updatedAt = m.createdAt
+	}
+	g.data.submissions = append(g.data.submissions, sub)
+
+	for i, m := range studentMessages {
+		if unread && i == len(studentMessages)-1 {
+			break
+		}
+		g.data.reads = append(g.data.reads, readReceipt{userID: g.data.instructor.id, messageID: m.id, readAt: m.createdAt.Add(time.Hour)})
+	}
+}
+
+// uuid draws a version 4 UUID from rng, so IDs are reproducible too
+func (g *generator) uuid() uuid.UUID {
+	var id uuid.UUID
+	for i := 0; i < len(id); i += 8 {
+		v := g.rng.Uint64()
+		for j := 0; j < 8; j++ {
+			id[i+j] = byte(v >> (8 * j))
+		}
+	}
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return id
+}
+
+func clampScale(v float64) int {
+	return int(math.Max(1, math.Min(5, math.Round(v))))
+}
+
+// weekdayFactor makes weekends a little more and Fridays a little less likely for practice
+var weekdayFactor = map[time.Weekday]float64{
+	time.Monday:    1.05,
+	time.Tuesday:   1,
+	time.Wednesday: 1,
+	time.Thursday:  1,
+	time.Friday:    0.8,
+	time.Saturday:  1.15,
+	time.Sunday:    1.1,
+}
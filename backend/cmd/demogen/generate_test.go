@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenerate_Deterministic(t *testing.T) {
+	opts := options{Students: 20, Months: 3, Seed: 42, Until: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)}
+
+	first := generate(opts)
+	if len(first.students) != opts.Students || len(first.sessions) == 0 || len(first.logs) == 0 || len(first.messages) == 0 {
+		t.Fatalf("dataset has %d students, %d sessions, %d logs and %d messages, want all of them generated",
+			len(first.students), len(first.sessions), len(first.logs), len(first.messages))
+	}
+
+	t.Run("same_seed_same_dataset", func(t *testing.T) {
+		second := generate(opts)
+		if !reflect.DeepEqual(first, second) {
+			t.Error("two runs with the same options generated different datasets")
+		}
+		if first.instructor.id != second.instructor.id || first.sessions[0].id != second.sessions[0].id {
+			t.Errorf("IDs differ between runs: instructor %s and %s, first session %s and %s",
+				first.instructor.id, second.instructor.id, first.sessions[0].id, second.sessions[0].id)
+		}
+	})
+
+	t.Run("different_seed_different_dataset", func(t *testing.T) {
+		other := opts
+		other.Seed = 43
+		second := generate(other)
+		if reflect.DeepEqual(first, second) {
+			t.Fatal("different seeds generated the same dataset")
+		}
+		if first.instructor.id == second.instructor.id {
+			t.Errorf("different seeds generated the same instructor ID %s", first.instructor.id)
+		}
+	})
+}
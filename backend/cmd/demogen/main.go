@@ -0,0 +1,178 @@
+// Command demogen fills the database with a synthetic dataset for demos and load tests: a demo
+// instructor with three programs and students practicing them for months, with plausible
+// habits (some practice daily, some drop out, most in the morning or evening) and submission
+// threads with the instructor. The same flags always generate the same data, IDs included, so
+// -seed and -until make a dataset reproducible.
+//
+//	go run ./cmd/demogen [-students 30] [-months 6] [-seed 1] [-until 2026-06-30] [-reset]
+//
+// All generated users have @demo.xuangong.local addresses and the password given by -password.
+// -reset deletes an earlier demo dataset first. Afterwards, recompute the stored stats with
+// `make stats-recompute`.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/pkg/auth"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	students := flag.Int("students", 30, "number of students")
+	months := flag.Int("months", 6, "months of practice history")
+	seed := flag.Uint64("seed", 1, "seed of the random generator")
+	until := flag.String("until", "", "last day of activity as YYYY-MM-DD (default today)")
+	password := flag.String("password", "demo123", "password of the generated users")
+	reset := flag.Bool("reset", false, "delete an earlier demo dataset first")
+	flag.Parse()
+
+	if *students < 1 || *months < 1 {
+		log.Fatal("-students and -months must be at least 1")
+	}
+	opts := options{Students: *students, Months: *months, Seed: *seed, Until: time.Now().UTC().Truncate(24 * time.Hour)}
+	if *until != "" {
+		day, err := time.Parse(time.DateOnly, *until)
+		if err != nil {
+			log.Fatalf("Invalid -until: %v", err)
+		}
+		opts.Until = day
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	pool, err := database.NewPool(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close(pool)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	passwordHash, err := auth.HashPassword(*password)
+	if err != nil {
+		log.Fatalf("Failed to hash password: %v", err)
+	}
+
+	data := generate(opts)
+	log.Printf("Generated %d students, %d sessions, %d exercise logs, %d submissions with %d messages",
+		len(data.students), len(data.sessions), len(data.logs), len(data.submissions), len(data.messages))
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		log.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var existing int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE email LIKE '%@' || $1`, demoDomain).Scan(&existing); err != nil {
+		log.Fatalf("Failed to check for demo data: %v", err)
+	}
+	if existing > 0 {
+		if !*reset {
+			log.Fatalf("The database already has %d demo users; run with -reset to replace them", existing)
+		}
+		if err := deleteDemoData(ctx, tx); err != nil {
+			log.Fatalf("Failed to delete demo data: %v", err)
+		}
+		log.Printf("Deleted the earlier demo dataset")
+	}
+
+	if err := write(ctx, tx, data, passwordHash); err != nil {
+		log.Fatalf("Failed to write demo data: %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Fatalf("Failed to commit demo data: %v", err)
+	}
+
+	log.Printf("Demo data written. Sign in as %s or student0001@%s with password %q", data.instructor.email, demoDomain, *password)
+	log.Print("Run `make stats-recompute` to update streaks and program completion counts")
+}
+
+// deleteDemoData removes the demo users and their programs; everything else goes with them
+func deleteDemoData(ctx context.Context, tx pgx.Tx) error {
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM programs
+		WHERE owned_by IN (SELECT id FROM users WHERE email LIKE '%@' || $1)
+	`, demoDomain); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `DELETE FROM users WHERE email LIKE '%@' || $1`, demoDomain)
+	return err
+}
+
+// write copies the dataset into the database, parents first
+func write(ctx context.Context, tx pgx.Tx, data *dataset, passwordHash string) error {
+	users := append([]user{data.instructor}, data.students...)
+	tables := []struct {
+		name    string
+		columns []string
+		rows    [][]any
+	}{
+		{"users", []string{"id", "email", "password_hash", "full_name", "role", "is_active", "created_at", "updated_at"}, rows(users, func(u user) []any {
+			return []any{u.id, u.email, passwordHash, u.fullName, u.role, true, u.createdAt, u.createdAt}
+		})},
+		{"programs", []string{"id", "name", "description", "owned_by", "is_template", "is_public", "tags", "metadata", "created_at", "updated_at"}, rows(data.programs, func(p program) []any {
+			return []any{p.id, p.name, p.description, data.instructor.id, false, false, p.tags, map[string]any{"demo": true}, data.instructor.createdAt, data.instructor.createdAt}
+		})},
+		{"exercises", []string{"id", "program_id", "name", "description", "order_index", "exercise_type", "duration_seconds", "repetitions", "rest_after_seconds", "has_sides", "metadata"}, exerciseRows(data.programs)},
+		{"user_programs", []string{"user_id", "program_id", "assigned_by", "assigned_at", "is_active", "custom_settings"}, rows(data.assignments, func(a assignment) []any {
+			return []any{a.userID, a.programID, data.instructor.id, a.assignedAt, true, map[string]any{}}
+		})},
+		{"practice_sessions", []string{"id", "user_id", "program_id", "started_at", "completed_at", "total_duration_seconds", "completion_rate", "mood", "energy"}, rows(data.sessions, func(s session) []any {
+			return []any{s.id, s.userID, s.programID, s.startedAt, s.completedAt, s.totalSeconds, s.completionRate, s.mood, s.energy}
+		})},
+		{"exercise_logs", []string{"id", "session_id", "exercise_id", "started_at", "completed_at", "planned_duration_seconds", "actual_duration_seconds", "repetitions_planned", "repetitions_completed", "skipped"}, rows(data.logs, func(l exerciseLog) []any {
+			return []any{l.id, l.sessionID, l.exerciseID, l.startedAt, l.completedAt, l.plannedSeconds, l.actualSeconds, l.repetitionsPlanned, l.repetitionsCompleted, l.skipped}
+		})},
+		{"submissions", []string{"id", "program_id", "user_id", "title", "created_at", "updated_at"}, rows(data.submissions, func(s submission) []any {
+			return []any{s.id, s.programID, s.userID, s.title, s.createdAt, s.updatedAt}
+		})},
+		{"submission_messages", []string{"id", "submission_id", "user_id", "content", "created_at"}, rows(data.messages, func(m message) []any {
+			return []any{m.id, m.submissionID, m.userID, m.content, m.createdAt}
+		})},
+		{"message_read_status", []string{"user_id", "message_id", "read_at"}, rows(data.reads, func(r readReceipt) []any {
+			return []any{r.userID, r.messageID, r.readAt}
+		})},
+	}
+
+	for _, t := range tables {
+		copied, err := tx.CopyFrom(ctx, pgx.Identifier{t.name}, t.columns, pgx.CopyFromRows(t.rows))
+		if err != nil {
+			return err
+		}
+		log.Printf("  %s: %d rows", t.name, copied)
+	}
+	return nil
+}
+
+func rows[T any](items []T, row func(T) []any) [][]any {
+	out := make([][]any, len(items))
+	for i, item := range items {
+		out[i] = row(item)
+	}
+	return out
+}
+
+func exerciseRows(programs []program) [][]any {
+	var out [][]any
+	for _, p := range programs {
+		for i, e := range p.exercises {
+			out = append(out, []any{e.id, p.id, e.name, "", i, e.exerciseType, e.durationSeconds, e.repetitions, e.restAfterSeconds, e.hasSides, map[string]any{}})
+		}
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+// Command encryption rewrites the encrypted columns. After rotating ENCRYPTION_KEY, with the old
+// key moved to ENCRYPTION_PREVIOUS_KEYS, reencrypt writes every value with the new key, after
+// which the old key can be dropped. It also encrypts values stored before encryption was
+// enabled. decrypt writes all values back as plain text, for turning encryption off.
+//
+//	go run ./cmd/encryption reencrypt
+//	go run ./cmd/encryption decrypt
+//
+// reencrypt can run while the API is serving; decrypt while it is stopped, since the API keeps
+// encrypting new values until it is restarted without the key. Both can be repeated after an
+// interruption.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/repositories"
+)
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) != 2 || (os.Args[1] != "reencrypt" && os.Args[1] != "decrypt") {
+		usage()
+	}
+	decrypt := os.Args[1] == "decrypt"
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	keyring, err := cfg.Encryption.NewKeyring()
+	if err != nil {
+		log.Fatalf("Failed to initialize encryption: %v", err)
+	}
+	if keyring == nil {
+		log.Fatal("ENCRYPTION_KEY is not set")
+	}
+	pool, err := database.NewPool(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close(pool)
+	database.ConfigureRetry(&cfg.Database)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	encryptionRepo := repositories.NewEncryptionRepository(pool, keyring)
+	for _, column := range repositories.EncryptedColumns {
+		rewritten, err := encryptionRepo.Rewrite(ctx, column, decrypt)
+		if err != nil {
+			log.Fatalf("%s: %v (%d rows rewritten before the error)", column, err, rewritten)
+		}
+		log.Printf("%s: %d rows rewritten", column, rewritten)
+	}
+	if decrypt {
+		log.Print("All values are plain text; unset ENCRYPTION_KEY before starting the API")
+	} else {
+		log.Printf("All values are encrypted with key %s", keyring.CurrentID())
+	}
+}
+
+func usage() {
+	log.Fatal("usage: encryption reencrypt\n" +
+		"       encryption decrypt")
+}
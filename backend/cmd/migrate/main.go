@@ -0,0 +1,140 @@
+// Command migrate lints migrations and creates new ones from expand/contract templates.
+// Applying migrations is still done by the API at boot and by the golang-migrate CLI.
+//
+//	go run ./cmd/migrate lint [-dir migrations] [-all]
+//	go run ./cmd/migrate new [-dir migrations] [-template plain|expand|contract|index] <name>
+package main
+
+import (
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"text/template"
+
+	"github.com/xuangong/backend/internal/database"
+)
+
+//go:embed templates/*.sql
+var templates embed.FS
+
+var (
+	nameRe    = regexp.MustCompile(`^[a-z0-9_]+$`)
+	versionRe = regexp.MustCompile(`^(\d+)_`)
+)
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "lint":
+		lint(os.Args[2:])
+	case "new":
+		create(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	log.Fatal("usage: migrate lint [-dir migrations] [-all]\n" +
+		"       migrate new [-dir migrations] [-template plain|expand|contract|index] <name>")
+}
+
+// lint prints all findings and exits non-zero if any would stop the API from migrating
+func lint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	dir := fs.String("dir", "migrations", "migrations directory")
+	all := fs.Bool("all", false, "also lint migrations up to the baseline")
+	fs.Parse(args)
+
+	baseline := uint(database.LintBaseline)
+	if *all {
+		baseline = 0
+	}
+	findings, err := database.LintMigrations(*dir, baseline)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	unsafe := 0
+	for _, f := range findings {
+		fmt.Println(f)
+		if f.Severity == database.LintError {
+			unsafe++
+		}
+	}
+	if unsafe > 0 {
+		log.Fatalf("%d unsafe operation(s); use the expand/contract templates or add a -- lint:ignore <rule> <reason> comment", unsafe)
+	}
+	fmt.Printf("%d warning(s), no unsafe operations\n", len(findings))
+}
+
+// create writes the next numbered up/down migration pair from a template
+func create(args []string) {
+	fs := flag.NewFlagSet("new", flag.ExitOnError)
+	dir := fs.String("dir", "migrations", "migrations directory")
+	tmpl := fs.String("template", "plain", "template: plain, expand, contract or index")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || !nameRe.MatchString(fs.Arg(0)) {
+		log.Fatal("migration name is required and may only contain a-z, 0-9 and _")
+	}
+	name := fs.Arg(0)
+
+	version, err := nextVersion(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, direction := range []string{"up", "down"} {
+		t, err := template.ParseFS(templates, fmt.Sprintf("templates/%s.%s.sql", *tmpl, direction))
+		if err != nil {
+			log.Fatalf("Unknown template %q", *tmpl)
+		}
+
+		path := filepath.Join(*dir, fmt.Sprintf("%06d_%s.%s.sql", version, name, direction))
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", path, err)
+		}
+		err = t.Execute(file, struct{ Name string }{name})
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			log.Fatalf("Failed to write %s: %v", path, err)
+		}
+		fmt.Println(path)
+	}
+}
+
+// nextVersion returns the version after the highest one in dir
+func nextVersion(dir string) (uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var latest uint64
+	for _, entry := range entries {
+		m := versionRe.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil {
+			return 0, errors.New("invalid migration version in " + entry.Name())
+		}
+		latest = max(latest, version)
+	}
+	return latest + 1, nil
+}
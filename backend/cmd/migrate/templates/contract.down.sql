@@ -0,0 +1,5 @@
+-- Data in the dropped column cannot be restored; bring back the structure only
+ALTER TABLE table_name ADD COLUMN old_column TEXT;
+ALTER TABLE table_name ALTER COLUMN new_column DROP NOT NULL;
+ALTER TABLE table_name
+    ADD CONSTRAINT chk_table_name_new_column_not_null CHECK (new_column IS NOT NULL) NOT VALID;
@@ -0,0 +1,12 @@
+-- Contract: {{.Name}}
+-- Removes structure the running release no longer uses. Only merge this once the release
+-- that stopped reading and writing the old column is deployed everywhere.
+-- lint:ignore destructive old_column is unused since <release>
+-- lint:ignore set-not-null the validated CHECK below lets Postgres skip the table scan
+
+-- Validating takes a lock that still allows reads and writes
+ALTER TABLE table_name VALIDATE CONSTRAINT chk_table_name_new_column_not_null;
+ALTER TABLE table_name ALTER COLUMN new_column SET NOT NULL;
+ALTER TABLE table_name DROP CONSTRAINT chk_table_name_new_column_not_null;
+
+ALTER TABLE table_name DROP COLUMN old_column;
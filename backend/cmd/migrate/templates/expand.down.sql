@@ -0,0 +1,2 @@
+ALTER TABLE table_name DROP CONSTRAINT IF EXISTS chk_table_name_new_column_not_null;
+ALTER TABLE table_name DROP COLUMN IF EXISTS new_column;
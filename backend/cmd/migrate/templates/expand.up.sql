@@ -0,0 +1,15 @@
+-- Expand: {{.Name}}
+-- Adds new structure next to the old one. The running release does not know about it yet:
+--   * new columns are nullable or have a constant DEFAULT
+--   * constraints are added NOT VALID and validated in the contract migration
+--   * indexes on existing tables go in their own migration (template=index)
+-- Ship code that writes both old and new, backfill, switch reads, then contract.
+
+ALTER TABLE table_name ADD COLUMN new_column TEXT;
+
+-- Small tables can be backfilled here. Backfill large tables in batches outside the
+-- migration, so no single transaction holds row locks for long:
+-- UPDATE table_name SET new_column = old_column WHERE new_column IS NULL;
+
+ALTER TABLE table_name
+    ADD CONSTRAINT chk_table_name_new_column_not_null CHECK (new_column IS NOT NULL) NOT VALID;
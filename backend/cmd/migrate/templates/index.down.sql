@@ -0,0 +1 @@
+DROP INDEX CONCURRENTLY IF EXISTS idx_table_name_column;
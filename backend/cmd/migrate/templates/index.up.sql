@@ -0,0 +1,4 @@
+-- Index: {{.Name}}
+-- CREATE INDEX CONCURRENTLY does not block writes, but cannot run inside a transaction.
+-- Keep it as the only statement in this migration.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_table_name_column ON table_name(column_name);
@@ -0,0 +1 @@
+-- Revert {{.Name}}
@@ -34,6 +34,12 @@ func main() {
 	userRepo := repositories.NewUserRepository(pool)
 	programRepo := repositories.NewProgramRepository(pool)
 	exerciseRepo := repositories.NewExerciseRepository(pool)
+	translationRepo := repositories.NewTranslationRepository(pool)
+
+	packs, err := loadPacks()
+	if err != nil {
+		log.Fatalf("Failed to load content packs: %v", err)
+	}
 
 	// Create admin user
 	log.Println("Creating admin user...")
@@ -68,11 +74,14 @@ func main() {
 	}
 
 	// Create sample programs with different intensities
+	// Keys identify programs and exercises in the content packs
 	programs := []struct {
+		key         string
 		name        string
 		description string
 		tags        []string
 		exercises   []struct {
+			key                 string
 			name                string
 			description         string
 			exerciseType        models.ExerciseType
@@ -84,10 +93,12 @@ func main() {
 		}
 	}{
 		{
+			key:         "tai-chi-morning-light",
 			name:        "Tai Chi Morning Practice - Light",
 			description: "Gentle morning Tai Chi routine for beginners",
 			tags:        []string{"tai-chi", "morning", "beginner", "light"},
 			exercises: []struct {
+				key                 string
 				name                string
 				description         string
 				exerciseType        models.ExerciseType
@@ -98,6 +109,7 @@ func main() {
 				sideDurationSeconds *int
 			}{
 				{
+					key:              "zhan-zhuang",
 					name:             "Standing Meditation (Zhan Zhuang)",
 					description:      "Stand in Wu Ji posture with arms at sides, feet shoulder-width apart",
 					exerciseType:     models.ExerciseTypeTimed,
@@ -106,6 +118,7 @@ func main() {
 					hasSides:         false,
 				},
 				{
+					key:              "yun-shou",
 					name:             "Cloud Hands (Yun Shou)",
 					description:      "Flowing side-to-side movement coordinating arms and waist",
 					exerciseType:     models.ExerciseTypeRepetition,
@@ -114,6 +127,7 @@ func main() {
 					hasSides:         true,
 				},
 				{
+					key:                 "single-whip",
 					name:                "Single Whip",
 					description:         "Classic Tai Chi posture transitioning from center to side",
 					exerciseType:        models.ExerciseTypeCombined,
@@ -126,10 +140,12 @@ func main() {
 			},
 		},
 		{
+			key:         "tai-chi-morning-medium",
 			name:        "Tai Chi Morning Practice - Medium",
 			description: "Standard morning Tai Chi routine for regular practitioners",
 			tags:        []string{"tai-chi", "morning", "intermediate", "medium"},
 			exercises: []struct {
+				key                 string
 				name                string
 				description         string
 				exerciseType        models.ExerciseType
@@ -140,6 +156,7 @@ func main() {
 				sideDurationSeconds *int
 			}{
 				{
+					key:              "zhan-zhuang",
 					name:             "Standing Meditation (Zhan Zhuang)",
 					description:      "Stand in Wu Ji posture with arms at sides, feet shoulder-width apart",
 					exerciseType:     models.ExerciseTypeTimed,
@@ -148,6 +165,7 @@ func main() {
 					hasSides:         false,
 				},
 				{
+					key:              "yun-shou",
 					name:             "Cloud Hands (Yun Shou)",
 					description:      "Flowing side-to-side movement coordinating arms and waist",
 					exerciseType:     models.ExerciseTypeRepetition,
@@ -156,6 +174,7 @@ func main() {
 					hasSides:         true,
 				},
 				{
+					key:                 "single-whip",
 					name:                "Single Whip",
 					description:         "Classic Tai Chi posture transitioning from center to side",
 					exerciseType:        models.ExerciseTypeCombined,
@@ -168,10 +187,12 @@ func main() {
 			},
 		},
 		{
+			key:         "tai-chi-morning-intensive",
 			name:        "Tai Chi Morning Practice - Intensive",
 			description: "Intensive morning Tai Chi routine for advanced practitioners",
 			tags:        []string{"tai-chi", "morning", "advanced", "intensive"},
 			exercises: []struct {
+				key                 string
 				name                string
 				description         string
 				exerciseType        models.ExerciseType
@@ -182,6 +203,7 @@ func main() {
 				sideDurationSeconds *int
 			}{
 				{
+					key:              "zhan-zhuang",
 					name:             "Standing Meditation (Zhan Zhuang)",
 					description:      "Stand in Wu Ji posture with arms at sides, feet shoulder-width apart",
 					exerciseType:     models.ExerciseTypeTimed,
@@ -190,6 +212,7 @@ func main() {
 					hasSides:         false,
 				},
 				{
+					key:              "yun-shou",
 					name:             "Cloud Hands (Yun Shou)",
 					description:      "Flowing side-to-side movement coordinating arms and waist",
 					exerciseType:     models.ExerciseTypeRepetition,
@@ -198,6 +221,7 @@ func main() {
 					hasSides:         true,
 				},
 				{
+					key:                 "single-whip",
 					name:                "Single Whip",
 					description:         "Classic Tai Chi posture transitioning from center to side",
 					exerciseType:        models.ExerciseTypeCombined,
@@ -212,6 +236,8 @@ func main() {
 	}
 
 	var mediumProgramID uuid.UUID
+	programIDs := make(map[string]uuid.UUID)
+	exerciseIDs := make(map[string][]uuid.UUID)
 
 	for _, p := range programs {
 		log.Printf("Creating program: %s", p.name)
@@ -229,6 +255,7 @@ func main() {
 			continue
 		}
 		log.Printf("Program created: %s", program.Name)
+		programIDs[p.key] = program.ID
 
 		// Save the medium program ID for assignment
 		if p.name == "Tai Chi Morning Practice - Medium" {
@@ -255,9 +282,13 @@ func main() {
 				continue
 			}
 			log.Printf("  Exercise created: %s", exercise.Name)
+			exerciseIDs[ex.key] = append(exerciseIDs[ex.key], exercise.ID)
 		}
 	}
 
+	// Translate the programs from the content packs
+	seedTranslations(ctx, translationRepo, packs, programIDs, exerciseIDs, &admin.ID)
+
 	// Assign medium program to student
 	if mediumProgramID != uuid.Nil {
 		log.Println("Assigning medium program to student...")
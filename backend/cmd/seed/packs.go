@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/i18n"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+)
+
+// Content packs translate the seeded programs and exercises, one file per locale named after it
+// (packs/de.json). Entries are keyed by the seed keys of the programs and exercises, so all
+// intensities of a program share the translations of their exercises.
+//
+//go:embed packs/*.json
+var packFiles embed.FS
+
+type contentPack struct {
+	Locale    string               `json:"-"`
+	Programs  map[string]packEntry `json:"programs"`
+	Exercises map[string]packEntry `json:"exercises"`
+}
+
+type packEntry struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// loadPacks reads the embedded content packs, ordered by locale
+func loadPacks() ([]contentPack, error) {
+	files, err := packFiles.ReadDir("packs")
+	if err != nil {
+		return nil, err
+	}
+
+	packs := make([]contentPack, 0, len(files))
+	for _, file := range files {
+		locale := strings.TrimSuffix(file.Name(), ".json")
+		if !i18n.IsSupported(locale) || locale == i18n.DefaultLocale {
+			return nil, fmt.Errorf("%s: %q is not a locale content is translated into", file.Name(), locale)
+		}
+
+		data, err := packFiles.ReadFile(path.Join("packs", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		pack := contentPack{Locale: locale}
+		if err := json.Unmarshal(data, &pack); err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name(), err)
+		}
+		packs = append(packs, pack)
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Locale < packs[j].Locale })
+	return packs, nil
+}
+
+// seedTranslations links the packs to the programs and exercises created by this run as their
+// translations. Pack entries for keys that were not seeded are skipped with a warning.
+func seedTranslations(ctx context.Context, translationRepo *repositories.TranslationRepository, packs []contentPack, programIDs map[string]uuid.UUID, exerciseIDs map[string][]uuid.UUID, adminID *uuid.UUID) {
+	for _, pack := range packs {
+		log.Printf("Loading %s content pack...", pack.Locale)
+		for key, entry := range pack.Programs {
+			id, ok := programIDs[key]
+			if !ok {
+				log.Printf("Warning: %s pack translates program %q, which was not seeded", pack.Locale, key)
+				continue
+			}
+			upsertTranslation(ctx, translationRepo, models.TranslatableProgram, id, pack.Locale, entry, adminID)
+		}
+		for key, entry := range pack.Exercises {
+			ids, ok := exerciseIDs[key]
+			if !ok {
+				log.Printf("Warning: %s pack translates exercise %q, which was not seeded", pack.Locale, key)
+				continue
+			}
+			for _, id := range ids {
+				upsertTranslation(ctx, translationRepo, models.TranslatableExercise, id, pack.Locale, entry, adminID)
+			}
+		}
+	}
+}
+
+func upsertTranslation(ctx context.Context, translationRepo *repositories.TranslationRepository, entityType models.TranslatableType, id uuid.UUID, locale string, entry packEntry, adminID *uuid.UUID) {
+	translation := &models.Translation{
+		EntityID:    id,
+		Locale:      locale,
+		Name:        entry.Name,
+		Description: entry.Description,
+		UpdatedBy:   adminID,
+	}
+	if err := translationRepo.Upsert(ctx, entityType, translation); err != nil {
+		log.Printf("Warning: Could not translate %s %s into %s: %v", entityType, id, locale, err)
+	}
+}
@@ -0,0 +1,96 @@
+// Command stats recomputes stored practice stats (streak snapshots and program completion counts),
+// e.g. after changing the deployment's streak rules. It queues the same resumable job as the admin
+// endpoint and runs it in the foreground, printing progress. An interrupted run can be resumed.
+//
+//	go run ./cmd/stats recompute [-user <user id>]
+//	go run ./cmd/stats recompute -resume <recompute id>
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/internal/services"
+)
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 || os.Args[1] != "recompute" {
+		usage()
+	}
+
+	fs := flag.NewFlagSet("recompute", flag.ExitOnError)
+	user := fs.String("user", "", "only recompute this user")
+	resume := fs.String("resume", "", "resume an unfinished recompute")
+	fs.Parse(os.Args[2:])
+	if *user != "" && *resume != "" {
+		usage()
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	pool, err := database.NewPool(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close(pool)
+	database.ConfigureRetry(&cfg.Database)
+
+	userRepo := repositories.NewUserRepository(pool)
+	sessionRepo := repositories.NewSessionRepository(pool)
+	streakService := services.NewStreakService(repositories.NewStreakRepository(pool), sessionRepo, userRepo, &cfg.Streaks)
+	recomputeService := services.NewStatsRecomputeService(repositories.NewStatsRecomputeRepository(pool), userRepo, sessionRepo, repositories.NewProgramRepository(pool), streakService)
+
+	// Stop between users on Ctrl-C; the recompute is released and can be resumed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var id uuid.UUID
+	if *resume != "" {
+		if id, err = uuid.Parse(*resume); err != nil {
+			log.Fatalf("Invalid recompute ID: %v", err)
+		}
+	} else {
+		var userID *uuid.UUID
+		if *user != "" {
+			parsed, err := uuid.Parse(*user)
+			if err != nil {
+				log.Fatalf("Invalid user ID: %v", err)
+			}
+			userID = &parsed
+		}
+		job, err := recomputeService.Start(ctx, userID, nil)
+		if err != nil {
+			log.Fatalf("Failed to start recompute: %v", err)
+		}
+		id = job.ID
+		log.Printf("Recompute %s queued for %d users", id, job.Total)
+	}
+
+	err = recomputeService.Run(ctx, id, func(job *models.StatsRecompute) {
+		log.Printf("%d/%d users", job.Processed, job.Total)
+	})
+	if ctx.Err() != nil {
+		log.Fatalf("Interrupted; resume with: stats recompute -resume %s", id)
+	}
+	if err != nil {
+		log.Fatalf("Recompute failed: %v", err)
+	}
+	log.Printf("Recompute %s completed", id)
+}
+
+func usage() {
+	log.Fatal("usage: stats recompute [-user <user id>]\n" +
+		"       stats recompute -resume <recompute id>")
+}
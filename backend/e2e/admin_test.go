@@ -0,0 +1,187 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestStudentOverview(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name":                "E2E Overview Routine",
+		"repetitions_planned": 4,
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300},
+		},
+	}, http.StatusCreated, &created)
+	admin.do(http.MethodPost, "/programs/"+created.ID.String()+"/assign", map[string]any{"user_ids": []string{student.user.ID.String()}}, http.StatusOK, nil)
+
+	var session models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &session)
+	student.do(http.MethodPut, "/sessions/"+session.ID.String()+"/complete", map[string]any{"total_duration_seconds": 300, "completion_rate": 100}, http.StatusOK, nil)
+	admin.do(http.MethodPost, "/sessions/"+session.ID.String()+"/notes", map[string]any{"content": "Sink the shoulders"}, http.StatusCreated, nil)
+
+	var submission struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+created.ID.String()+"/submissions", map[string]any{"title": "Is my stance right?"}, http.StatusCreated, &submission)
+
+	overviewPath := "/admin/students/" + student.user.ID.String() + "/overview"
+	student.do(http.MethodGet, overviewPath, nil, http.StatusForbidden, nil)
+	admin.do(http.MethodGet, "/admin/students/00000000-0000-0000-0000-000000000000/overview", nil, http.StatusNotFound, nil)
+
+	var overview models.StudentOverview
+	admin.do(http.MethodGet, overviewPath, nil, http.StatusOK, &overview)
+	if overview.Profile.ID != student.user.ID || overview.Stats.CompletedSessions != 1 || overview.Stats.CurrentStreak != 1 {
+		t.Errorf("profile and stats = %+v / %+v, want the student with one session", overview.Profile, overview.Stats)
+	}
+	if len(overview.Programs) != 1 || overview.Programs[0].Program.ID != created.ID || overview.Programs[0].Progress.RepetitionsCompleted != 1 {
+		t.Errorf("programs = %+v, want the assigned program with one repetition", overview.Programs)
+	}
+	if len(overview.RecentSessions) != 1 || overview.RecentSessions[0].ID != session.ID {
+		t.Errorf("recent sessions = %+v, want the session", overview.RecentSessions)
+	}
+	if len(overview.OpenSubmissions) != 1 || overview.OpenSubmissions[0].ID != submission.Submission.ID {
+		t.Errorf("open submissions = %+v, want the unanswered one", overview.OpenSubmissions)
+	}
+	if len(overview.Notes) != 1 || overview.Notes[0].Content != "Sink the shoulders" {
+		t.Errorf("notes = %+v, want the private note", overview.Notes)
+	}
+
+	// Answered threads no longer wait for feedback
+	admin.do(http.MethodPost, "/submissions/"+submission.Submission.ID.String()+"/messages", map[string]any{"content": "Looks good"}, http.StatusCreated, nil)
+	admin.do(http.MethodGet, overviewPath, nil, http.StatusOK, &overview)
+	if len(overview.OpenSubmissions) != 0 {
+		t.Errorf("open submissions = %+v, want none after the reply", overview.OpenSubmissions)
+	}
+}
+
+func TestStudentAppState(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E App State Routine",
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300},
+		},
+	}, http.StatusCreated, &created)
+	admin.do(http.MethodPost, "/programs/"+created.ID.String()+"/assign", map[string]any{"user_ids": []string{student.user.ID.String()}}, http.StatusOK, nil)
+
+	var submission struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+created.ID.String()+"/submissions", map[string]any{"title": "Where is my program?"}, http.StatusCreated, &submission)
+	admin.do(http.MethodPost, "/submissions/"+submission.Submission.ID.String()+"/messages", map[string]any{"content": "Right here"}, http.StatusCreated, nil)
+
+	statePath := "/admin/students/" + student.user.ID.String() + "/app-state"
+	student.do(http.MethodGet, statePath, nil, http.StatusForbidden, nil)
+	admin.do(http.MethodGet, "/admin/students/00000000-0000-0000-0000-000000000000/app-state", nil, http.StatusNotFound, nil)
+
+	var state models.StudentAppState
+	admin.do(http.MethodGet, statePath, nil, http.StatusOK, &state)
+
+	var mine struct {
+		Programs []models.ProgramWithExercises `json:"programs"`
+	}
+	student.do(http.MethodGet, "/my-programs", nil, http.StatusOK, &mine)
+	if !reflect.DeepEqual(state.Programs, mine.Programs) {
+		t.Errorf("programs = %+v, want what /my-programs returns: %+v", state.Programs, mine.Programs)
+	}
+	var stats models.SessionStats
+	student.do(http.MethodGet, "/sessions/stats", nil, http.StatusOK, &stats)
+	if !reflect.DeepEqual(state.Stats, stats) {
+		t.Errorf("stats = %+v, want %+v", state.Stats, stats)
+	}
+
+	// Looking does not mark the reply read
+	var counts models.UnreadCounts
+	student.do(http.MethodGet, "/submissions/unread-count", nil, http.StatusOK, &counts)
+	if state.UnreadCounts.Total != 1 || !reflect.DeepEqual(state.UnreadCounts, counts) {
+		t.Errorf("unread counts = %+v, want the student's one unread reply: %+v", state.UnreadCounts, counts)
+	}
+}
+
+func TestProgramDeletion(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Deleted Routine"}, http.StatusCreated, &created)
+	programPath := "/programs/" + created.ID.String()
+	purgePath := "/admin" + programPath
+
+	// Deleting hides the program but keeps it for purging
+	admin.do(http.MethodDelete, purgePath, nil, http.StatusConflict, nil)
+	newStudent(t).do(http.MethodDelete, programPath, nil, http.StatusForbidden, nil)
+	student.do(http.MethodDelete, programPath, nil, http.StatusOK, nil)
+	student.do(http.MethodDelete, programPath, nil, http.StatusNotFound, nil)
+	student.do(http.MethodGet, programPath, nil, http.StatusNotFound, nil)
+
+	var mine struct {
+		Programs []models.ProgramWithExercises `json:"programs"`
+	}
+	student.do(http.MethodGet, "/my-programs", nil, http.StatusOK, &mine)
+	for _, p := range mine.Programs {
+		if p.Program.ID == created.ID {
+			t.Fatalf("my programs = %+v, want the deleted program left out", mine.Programs)
+		}
+	}
+
+	student.do(http.MethodDelete, purgePath, nil, http.StatusForbidden, nil)
+	admin.do(http.MethodDelete, purgePath, nil, http.StatusNoContent, nil)
+	admin.do(http.MethodDelete, purgePath, nil, http.StatusNotFound, nil)
+}
+
+func TestAdminSessionFeed(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Feed Routine"}, http.StatusCreated, &created)
+	var finished, open models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &finished)
+	student.do(http.MethodPut, "/sessions/"+finished.ID.String()+"/complete", map[string]any{"total_duration_seconds": 600, "completion_rate": 100}, http.StatusOK, nil)
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &open)
+
+	student.do(http.MethodGet, "/admin/sessions", nil, http.StatusForbidden, nil)
+	admin.do(http.MethodGet, "/admin/sessions?status=paused", nil, http.StatusBadRequest, nil)
+
+	var feed struct {
+		Sessions []models.PracticeSession `json:"sessions"`
+	}
+	feedPath := "/admin/sessions?user_id=" + student.user.ID.String()
+	admin.do(http.MethodGet, feedPath, nil, http.StatusOK, &feed)
+	if len(feed.Sessions) != 2 || feed.Sessions[0].ID != open.ID || feed.Sessions[1].ID != finished.ID {
+		t.Fatalf("feed = %+v, want the open session, then the finished one", feed.Sessions)
+	}
+	if s := feed.Sessions[0]; s.UserName == nil || *s.UserName != student.user.FullName || s.ProgramName == nil || *s.ProgramName != "E2E Feed Routine" {
+		t.Errorf("feed session = %+v, want the student's and the program's names", s)
+	}
+
+	admin.do(http.MethodGet, feedPath+"&status=completed", nil, http.StatusOK, &feed)
+	if len(feed.Sessions) != 1 || feed.Sessions[0].ID != finished.ID {
+		t.Errorf("completed sessions = %+v, want the finished one", feed.Sessions)
+	}
+	admin.do(http.MethodGet, feedPath+"&status=in_progress&program_id="+created.ID.String(), nil, http.StatusOK, &feed)
+	if len(feed.Sessions) != 1 || feed.Sessions[0].ID != open.ID {
+		t.Errorf("sessions in progress = %+v, want the open one", feed.Sessions)
+	}
+	admin.do(http.MethodGet, feedPath+"&status=abandoned", nil, http.StatusOK, &feed)
+	if len(feed.Sessions) != 0 {
+		t.Errorf("abandoned sessions = %+v, want none yet", feed.Sessions)
+	}
+	admin.do(http.MethodGet, feedPath+"&limit=1&offset=1", nil, http.StatusOK, &feed)
+	if len(feed.Sessions) != 1 || feed.Sessions[0].ID != finished.ID {
+		t.Errorf("second page = %+v, want the finished session", feed.Sessions)
+	}
+}
@@ -0,0 +1,102 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestOfficeHoursBooking(t *testing.T) {
+	instructor := newAdmin(t)
+	student := newStudent(t)
+	classmate := newStudent(t)
+
+	var program models.ProgramCreateResult
+	instructor.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Office Hours",
+	}, http.StatusCreated, &program)
+	instructor.do(http.MethodPost, "/programs/"+program.ID.String()+"/assign", map[string]any{
+		"user_ids": []string{student.user.ID.String(), classmate.user.ID.String()},
+	}, http.StatusOK, nil)
+
+	// Slots in two days, far enough out to be cancelled by students, and in two hours
+	start := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Hour)
+	var slot models.AvailabilitySlot
+	instructor.do(http.MethodPost, "/bookings/slots", map[string]any{
+		"starts_at": start.Format(time.RFC3339),
+		"ends_at":   start.Add(30 * time.Minute).Format(time.RFC3339),
+	}, http.StatusCreated, &slot)
+	instructor.do(http.MethodPost, "/bookings/slots", map[string]any{
+		"starts_at": start.Add(15 * time.Minute).Format(time.RFC3339),
+		"ends_at":   start.Add(45 * time.Minute).Format(time.RFC3339),
+	}, http.StatusConflict, nil)
+	student.do(http.MethodPost, "/bookings/slots", map[string]any{
+		"starts_at": start.Format(time.RFC3339),
+		"ends_at":   start.Add(30 * time.Minute).Format(time.RFC3339),
+	}, http.StatusForbidden, nil)
+
+	soon := time.Now().UTC().Add(2 * time.Hour).Truncate(time.Minute)
+	var soonSlot models.AvailabilitySlot
+	instructor.do(http.MethodPost, "/bookings/slots", map[string]any{
+		"starts_at": soon.Format(time.RFC3339),
+		"ends_at":   soon.Add(30 * time.Minute).Format(time.RFC3339),
+	}, http.StatusCreated, &soonSlot)
+
+	var booking models.Booking
+	student.do(http.MethodPost, "/bookings", map[string]any{
+		"slot_id":    slot.ID.String(),
+		"program_id": program.ID.String(),
+		"note":       "Please look at my stance in the second form",
+	}, http.StatusCreated, &booking)
+	if booking.Status != models.BookingConfirmed || !booking.StartsAt.Equal(start) {
+		t.Errorf("booking = %+v, want a confirmed booking starting at %s", booking, start)
+	}
+
+	// Booked slots are no longer offered and cannot be booked twice
+	classmate.do(http.MethodPost, "/bookings", map[string]any{
+		"slot_id":    slot.ID.String(),
+		"program_id": program.ID.String(),
+	}, http.StatusConflict, nil)
+	var open struct {
+		Slots []models.AvailabilitySlot `json:"slots"`
+	}
+	classmate.do(http.MethodGet, "/bookings/slots?instructor_id="+instructor.user.ID.String(), nil, http.StatusOK, &open)
+	if len(open.Slots) != 1 || open.Slots[0].ID != soonSlot.ID {
+		t.Errorf("open slots = %+v, want only %s", open.Slots, soonSlot.ID)
+	}
+
+	classmate.do(http.MethodGet, "/bookings/"+booking.ID.String(), nil, http.StatusNotFound, nil)
+	instructor.do(http.MethodGet, "/bookings/"+booking.ID.String()+"/ical", nil, http.StatusOK, nil)
+	instructor.do(http.MethodDelete, "/bookings/slots/"+slot.ID.String(), nil, http.StatusConflict, nil)
+
+	var upcoming struct {
+		Bookings []models.Booking `json:"bookings"`
+	}
+	instructor.do(http.MethodGet, "/bookings?upcoming=true", nil, http.StatusOK, &upcoming)
+	if len(upcoming.Bookings) != 1 || upcoming.Bookings[0].ID != booking.ID {
+		t.Errorf("instructor bookings = %+v, want only %s", upcoming.Bookings, booking.ID)
+	}
+
+	// Students cannot cancel within the notice period, instructors can
+	var late models.Booking
+	classmate.do(http.MethodPost, "/bookings", map[string]any{
+		"slot_id":    soonSlot.ID.String(),
+		"program_id": program.ID.String(),
+	}, http.StatusCreated, &late)
+	classmate.do(http.MethodPost, "/bookings/"+late.ID.String()+"/cancel", nil, http.StatusConflict, nil)
+	instructor.do(http.MethodPost, "/bookings/"+late.ID.String()+"/cancel", map[string]any{
+		"reason": "Sick today",
+	}, http.StatusOK, nil)
+
+	var cancelled models.Booking
+	student.do(http.MethodPost, "/bookings/"+booking.ID.String()+"/cancel", nil, http.StatusOK, &cancelled)
+	if cancelled.Status != models.BookingCancelled {
+		t.Errorf("status = %s, want %s", cancelled.Status, models.BookingCancelled)
+	}
+	student.do(http.MethodPost, "/bookings/"+booking.ID.String()+"/cancel", nil, http.StatusConflict, nil)
+	instructor.do(http.MethodDelete, "/bookings/slots/"+slot.ID.String(), nil, http.StatusOK, nil)
+}
@@ -0,0 +1,78 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestChangelog(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	student.do(http.MethodPost, "/changelog", map[string]any{"version": "9.9.9", "title": "Mine", "body": "Sneaky"}, http.StatusForbidden, nil)
+
+	var forStudents, forAdmins models.ChangelogEntry
+	admin.do(http.MethodPost, "/changelog", map[string]any{
+		"version":  "2.4.0",
+		"title":    "Offline practice",
+		"body":     "Programs now work **offline**.",
+		"audience": "students",
+	}, http.StatusCreated, &forStudents)
+	if forStudents.RenderedHTML == "" || forStudents.Audience != models.ChangelogStudents {
+		t.Errorf("entry = %+v, want it rendered for students", forStudents)
+	}
+	admin.do(http.MethodPost, "/changelog", map[string]any{
+		"version":  "2.4.0",
+		"title":    "Support queue",
+		"body":     "Answer tickets from the admin menu.",
+		"audience": "admins",
+	}, http.StatusCreated, &forAdmins)
+
+	var unseen struct {
+		Entries []models.ChangelogEntry `json:"entries"`
+	}
+	student.do(http.MethodGet, "/changelog/unseen", nil, http.StatusOK, &unseen)
+	if !containsEntry(unseen.Entries, forStudents.ID) || containsEntry(unseen.Entries, forAdmins.ID) {
+		t.Errorf("student's unseen entries = %+v, want only the one for students", unseen.Entries)
+	}
+
+	student.do(http.MethodPost, "/changelog/dismiss", map[string]any{"entry_ids": []string{"not-a-uuid"}}, http.StatusBadRequest, nil)
+	student.do(http.MethodPost, "/changelog/dismiss", map[string]any{"entry_ids": []string{forStudents.ID.String()}}, http.StatusNoContent, nil)
+	student.do(http.MethodGet, "/changelog/unseen", nil, http.StatusOK, &unseen)
+	if containsEntry(unseen.Entries, forStudents.ID) {
+		t.Errorf("unseen entries = %+v, want the dismissed one gone", unseen.Entries)
+	}
+
+	// Edits don't bring dismissed entries back
+	admin.do(http.MethodPut, "/changelog/"+forStudents.ID.String(), map[string]any{"title": "Offline practice, finally"}, http.StatusOK, nil)
+	student.do(http.MethodGet, "/changelog/unseen", nil, http.StatusOK, &unseen)
+	if containsEntry(unseen.Entries, forStudents.ID) {
+		t.Errorf("unseen entries = %+v, want the edited entry to stay dismissed", unseen.Entries)
+	}
+
+	var list struct {
+		Entries []models.ChangelogEntry `json:"entries"`
+	}
+	student.do(http.MethodGet, "/changelog?limit=100", nil, http.StatusOK, &list)
+	if !containsEntry(list.Entries, forStudents.ID) || containsEntry(list.Entries, forAdmins.ID) {
+		t.Errorf("student's changelog = %+v, want the entry for students only", list.Entries)
+	}
+
+	admin.do(http.MethodDelete, "/changelog/"+forAdmins.ID.String(), nil, http.StatusNoContent, nil)
+	admin.do(http.MethodDelete, "/changelog/"+forAdmins.ID.String(), nil, http.StatusNotFound, nil)
+	admin.do(http.MethodDelete, "/changelog/"+forStudents.ID.String(), nil, http.StatusNoContent, nil)
+}
+
+func containsEntry(entries []models.ChangelogEntry, id uuid.UUID) bool {
+	for _, entry := range entries {
+		if entry.ID == id {
+			return true
+		}
+	}
+	return false
+}
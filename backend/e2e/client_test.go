@@ -0,0 +1,119 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/auth"
+)
+
+const testPassword = "e2e-password-123"
+
+// client is an API user. Requests fail the test when the status code is not the expected one.
+type client struct {
+	t     *testing.T
+	user  models.UserResponse
+	token string
+}
+
+type authResponse struct {
+	User   models.UserResponse `json:"user"`
+	Tokens auth.TokenPair      `json:"tokens"`
+}
+
+// anonymous returns a client without credentials
+func anonymous(t *testing.T) *client {
+	return &client{t: t}
+}
+
+// newStudent registers a student with a unique email through the public API
+func newStudent(t *testing.T) *client {
+	t.Helper()
+
+	email := fmt.Sprintf("e2e-%s@test.com", uuid.New().String()[:8])
+	var resp authResponse
+	anonymous(t).do(http.MethodPost, "/auth/register", map[string]any{
+		"email":     email,
+		"password":  testPassword,
+		"full_name": "E2E Student",
+	}, http.StatusCreated, &resp)
+
+	return &client{t: t, user: resp.User, token: resp.Tokens.AccessToken}
+}
+
+// newAdmin registers a user, promotes it in the database and logs in again so the
+// access token carries the admin role
+func newAdmin(t *testing.T) *client {
+	t.Helper()
+
+	c := newStudent(t)
+	if _, err := pool.Exec(context.Background(), "UPDATE users SET role = 'admin' WHERE id = $1", c.user.ID); err != nil {
+		t.Fatalf("Failed to promote admin: %v", err)
+	}
+	return login(t, c.user.Email)
+}
+
+// login authenticates an existing user created with testPassword
+func login(t *testing.T, email string) *client {
+	t.Helper()
+
+	var resp authResponse
+	anonymous(t).do(http.MethodPost, "/auth/login", map[string]any{
+		"email":    email,
+		"password": testPassword,
+	}, http.StatusOK, &resp)
+
+	return &client{t: t, user: resp.User, token: resp.Tokens.AccessToken}
+}
+
+// do sends body as JSON to path (relative to the API root), checks the status code and
+// decodes the response into out unless it is nil
+func (c *client) do(method, path string, body any, wantStatus int, out any) {
+	c.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatalf("Failed to encode request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, apiURL+path, reader)
+	if err != nil {
+		c.t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatalf("Failed to read response body: %v", err)
+	}
+	if resp.StatusCode != wantStatus {
+		c.t.Fatalf("%s %s: status = %d, want %d\nbody: %s", method, path, resp.StatusCode, wantStatus, data)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			c.t.Fatalf("%s %s: failed to decode response: %v\nbody: %s", method, path, err, data)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestClientVersionGating(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	student.do(http.MethodPut, "/admin/client-versions/android", map[string]any{"min_version": "2.0"}, http.StatusForbidden, nil)
+	admin.do(http.MethodPut, "/admin/client-versions/windows", map[string]any{"min_version": "2.0"}, http.StatusNotFound, nil)
+	admin.do(http.MethodPut, "/admin/client-versions/android", map[string]any{"min_version": "two"}, http.StatusBadRequest, nil)
+
+	var policy models.ClientVersionPolicy
+	admin.do(http.MethodPut, "/admin/client-versions/android", map[string]any{
+		"min_version": "v2.0",
+		"upgrade_url": "https://play.google.com/store/apps/details?id=com.xuangong",
+	}, http.StatusOK, &policy)
+	t.Cleanup(func() {
+		admin.do(http.MethodDelete, "/admin/client-versions/android", nil, http.StatusOK, nil)
+	})
+	if policy.MinVersion != "2.0.0" || policy.Source != "admin" {
+		t.Errorf("policy = %+v, want an admin override at 2.0.0", policy)
+	}
+
+	if status := requestAsApp(t, student, "android", "1.9.3"); status != http.StatusUpgradeRequired {
+		t.Errorf("outdated app status = %d, want 426", status)
+	}
+	if status := requestAsApp(t, student, "android", "2.0.0"); status != http.StatusOK {
+		t.Errorf("current app status = %d, want 200", status)
+	}
+	if status := requestAsApp(t, student, "android", "not-a-version"); status != http.StatusBadRequest {
+		t.Errorf("unreadable version status = %d, want 400", status)
+	}
+	// Other clients, such as scripts, send no version and are not gated
+	student.do(http.MethodGet, "/auth/me", nil, http.StatusOK, nil)
+
+	var list struct {
+		Policies []models.ClientVersionPolicy `json:"policies"`
+	}
+	admin.do(http.MethodGet, "/admin/client-versions", nil, http.StatusOK, &list)
+	if len(list.Policies) != len(models.ClientPlatforms) {
+		t.Fatalf("policies = %+v, want one per platform", list.Policies)
+	}
+}
+
+// requestAsApp fetches the current user as the given app would and returns the status code
+func requestAsApp(t *testing.T, c *client, platform, version string) int {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, apiURL+"/auth/me", nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("X-App-Platform", platform)
+	req.Header.Set("X-App-Version", version)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /auth/me failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
@@ -0,0 +1,108 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestCourseProgress(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var basics, forms, advanced models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Course Basics"}, http.StatusCreated, &basics)
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Course Forms"}, http.StatusCreated, &forms)
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Course Advanced"}, http.StatusCreated, &advanced)
+
+	var course models.Course
+	admin.do(http.MethodPost, "/courses", map[string]any{
+		"name":         "E2E Foundations",
+		"is_published": false,
+		"modules": []map[string]any{
+			{"title": "Basics", "programs": []map[string]any{{"program_id": basics.ID}}},
+			{"title": "Forms", "unlock_rule": "after_previous", "programs": []map[string]any{{"program_id": forms.ID, "required_sessions": 2}}},
+			{"title": "Advanced", "unlock_rule": "after_days", "unlock_after_days": 30, "programs": []map[string]any{{"program_id": advanced.ID}}},
+		},
+	}, http.StatusCreated, &course)
+	if len(course.Modules) != 3 || course.Modules[1].Programs[0].RequiredSessions != 2 {
+		t.Fatalf("course = %+v, want 3 modules", course)
+	}
+
+	// Unpublished courses are hidden from students until they are enrolled
+	coursePath := "/courses/" + course.ID.String()
+	student.do(http.MethodGet, coursePath, nil, http.StatusNotFound, nil)
+	student.do(http.MethodPost, coursePath+"/enroll", nil, http.StatusNotFound, nil)
+	admin.do(http.MethodPost, coursePath+"/enrollments", map[string]any{
+		"user_ids": []string{student.user.ID.String()},
+	}, http.StatusOK, nil)
+	student.do(http.MethodGet, coursePath, nil, http.StatusOK, nil)
+
+	var progress models.CourseProgress
+	student.do(http.MethodGet, coursePath+"/progress", nil, http.StatusOK, &progress)
+	if !progress.Modules[0].Unlocked || progress.Modules[1].Unlocked || progress.Modules[2].Unlocked || progress.Modules[2].UnlocksAt == nil {
+		t.Errorf("modules = %+v, want only the first unlocked", progress.Modules)
+	}
+
+	// Only the unlocked module's program is assigned; completing it unlocks the next module
+	assertAssigned(t, student, basics.ID.String())
+	completeSession(student, basics.ID.String())
+
+	student.do(http.MethodGet, coursePath+"/progress", nil, http.StatusOK, &progress)
+	if !progress.Modules[0].Completed || !progress.Modules[1].Unlocked {
+		t.Errorf("modules = %+v, want the first complete and the second unlocked", progress.Modules)
+	}
+	if progress.Percent != 25 {
+		t.Errorf("percent = %v, want 25", progress.Percent)
+	}
+	assertAssigned(t, student, basics.ID.String(), forms.ID.String())
+
+	completeSession(student, forms.ID.String())
+	completeSession(student, forms.ID.String())
+	admin.do(http.MethodGet, coursePath+"/progress?user_id="+student.user.ID.String(), nil, http.StatusOK, &progress)
+	if !progress.Modules[1].Completed || progress.Completed || progress.Percent != 75 {
+		t.Errorf("progress = %+v, want 75%% with the advanced module still locked", progress)
+	}
+	student.do(http.MethodGet, coursePath+"/progress?user_id="+admin.user.ID.String(), nil, http.StatusForbidden, nil)
+
+	admin.do(http.MethodDelete, coursePath+"/enrollments/"+student.user.ID.String(), nil, http.StatusOK, nil)
+	student.do(http.MethodGet, coursePath+"/progress", nil, http.StatusNotFound, nil)
+	admin.do(http.MethodDelete, coursePath, nil, http.StatusOK, nil)
+}
+
+// assertAssigned checks that exactly the given programs are assigned to the student
+func assertAssigned(t *testing.T, c *client, programIDs ...string) {
+	t.Helper()
+
+	var mine struct {
+		Programs []models.ProgramWithExercises `json:"programs"`
+	}
+	c.do(http.MethodGet, "/my-programs", nil, http.StatusOK, &mine)
+	got := make(map[string]bool)
+	for _, p := range mine.Programs {
+		got[p.Program.ID.String()] = true
+	}
+	if len(got) != len(programIDs) {
+		t.Errorf("assigned programs = %v, want %v", got, programIDs)
+	}
+	for _, id := range programIDs {
+		if !got[id] {
+			t.Errorf("program %s is not assigned, got %v", id, got)
+		}
+	}
+}
+
+// completeSession records a completed practice session of the program
+func completeSession(c *client, programID string) {
+	c.t.Helper()
+
+	var session models.PracticeSession
+	c.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": programID}, http.StatusCreated, &session)
+	c.do(http.MethodPut, "/sessions/"+session.ID.String()+"/complete", map[string]any{
+		"total_duration_seconds": 600,
+		"completion_rate":        100,
+	}, http.StatusOK, nil)
+}
@@ -0,0 +1,86 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestPracticeDiary(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+	other := newStudent(t)
+
+	var created models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Diary Routine",
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 600},
+		},
+	}, http.StatusCreated, &created)
+	admin.do(http.MethodPost, "/programs/"+created.ID.String()+"/assign", map[string]any{"user_ids": []string{student.user.ID.String()}}, http.StatusOK, nil)
+	var session models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &session)
+
+	other.do(http.MethodPost, "/diary", map[string]any{"content": "Not mine", "session_ids": []string{session.ID.String()}}, http.StatusBadRequest, nil)
+	student.do(http.MethodPost, "/diary", map[string]any{"content": "No date", "entry_date": "yesterday"}, http.StatusBadRequest, nil)
+
+	var entry models.DiaryEntry
+	student.do(http.MethodPost, "/diary", map[string]any{
+		"entry_date":  "2026-03-14",
+		"title":       "Rooted",
+		"content":     "The **sinking** feeling finally came after ten minutes.",
+		"session_ids": []string{session.ID.String()},
+	}, http.StatusCreated, &entry)
+	if !strings.Contains(entry.RenderedHTML, "<strong>sinking</strong>") || len(entry.SessionIDs) != 1 || entry.Shared {
+		t.Errorf("entry = %+v, want rendered Markdown linked to the session", entry)
+	}
+	student.do(http.MethodPost, "/diary", map[string]any{"entry_date": "2026-03-15", "content": "Rest day, shoulders tight."}, http.StatusCreated, nil)
+
+	var diary struct {
+		Entries []models.DiaryEntry `json:"entries"`
+	}
+	student.do(http.MethodGet, "/diary?session_id="+session.ID.String(), nil, http.StatusOK, &diary)
+	if len(diary.Entries) != 1 || diary.Entries[0].ID != entry.ID {
+		t.Errorf("entries for the session = %+v, want the linked entry", diary.Entries)
+	}
+	student.do(http.MethodGet, "/diary?from=2026-03-15", nil, http.StatusOK, &diary)
+	if len(diary.Entries) != 1 || diary.Entries[0].EntryDate != "2026-03-15" {
+		t.Errorf("entries from March 15 = %+v, want the rest day", diary.Entries)
+	}
+
+	var search struct {
+		Results []models.DiarySearchResult `json:"results"`
+	}
+	student.do(http.MethodGet, "/diary/search?q=sinking", nil, http.StatusOK, &search)
+	if len(search.Results) != 1 || !strings.Contains(search.Results[0].Snippet, "<mark>sinking</mark>") {
+		t.Errorf("search = %+v, want the highlighted entry", search.Results)
+	}
+	other.do(http.MethodGet, "/diary/search?q=sinking", nil, http.StatusOK, &search)
+	if len(search.Results) != 0 {
+		t.Errorf("other student's search = %+v, want nothing", search.Results)
+	}
+
+	// Private until shared, even for instructors
+	entryPath := "/diary/" + entry.ID.String()
+	admin.do(http.MethodGet, entryPath, nil, http.StatusNotFound, nil)
+	other.do(http.MethodPut, entryPath, map[string]any{"shared": true}, http.StatusNotFound, nil)
+	student.do(http.MethodPut, entryPath, map[string]any{"shared": true, "session_ids": []string{}}, http.StatusOK, &entry)
+	if !entry.Shared || len(entry.SessionIDs) != 0 || entry.Title != "Rooted" {
+		t.Errorf("updated entry = %+v, want it shared without sessions", entry)
+	}
+	admin.do(http.MethodGet, entryPath, nil, http.StatusOK, nil)
+	other.do(http.MethodGet, entryPath, nil, http.StatusNotFound, nil)
+	admin.do(http.MethodGet, "/users/"+student.user.ID.String()+"/diary", nil, http.StatusOK, &diary)
+	if len(diary.Entries) != 1 || diary.Entries[0].ID != entry.ID {
+		t.Errorf("shared entries = %+v, want only the shared one", diary.Entries)
+	}
+
+	admin.do(http.MethodDelete, entryPath, nil, http.StatusNotFound, nil)
+	student.do(http.MethodDelete, entryPath, nil, http.StatusNoContent, nil)
+	student.do(http.MethodGet, entryPath, nil, http.StatusNotFound, nil)
+}
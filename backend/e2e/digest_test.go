@@ -0,0 +1,88 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/clock"
+)
+
+func TestWeeklyDigest(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Digest Routine"}, http.StatusCreated, &program)
+	admin.do(http.MethodPost, "/programs/"+program.ID.String()+"/assign", map[string]any{
+		"user_ids": []string{student.user.ID.String()},
+	}, http.StatusOK, nil)
+	admin.do(http.MethodPost, "/homework", map[string]any{
+		"program_id":  program.ID,
+		"title":       "Practice twice",
+		"requirement": "sessions",
+		"due_at":      time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339),
+	}, http.StatusCreated, nil)
+	completeSession(student, program.ID.String())
+
+	var prefs models.NotificationPreferences
+	student.do(http.MethodGet, "/notifications/preferences", nil, http.StatusOK, &prefs)
+	if !prefs.WeeklyDigest || prefs.Timezone != "UTC" {
+		t.Errorf("default preferences = %+v, want digest on in UTC", prefs)
+	}
+	student.do(http.MethodPut, "/notifications/preferences", map[string]any{"timezone": "Mars/Olympus_Mons"}, http.StatusBadRequest, nil)
+	student.do(http.MethodPut, "/notifications/preferences", map[string]any{"timezone": "Asia/Tokyo"}, http.StatusOK, &prefs)
+	if !prefs.WeeklyDigest || prefs.Timezone != "Asia/Tokyo" {
+		t.Errorf("preferences = %+v, want digest on in Asia/Tokyo", prefs)
+	}
+
+	var digest models.WeeklyDigest
+	student.do(http.MethodGet, "/notifications/digest", nil, http.StatusOK, &digest)
+	if digest.SessionsCompleted != 1 || digest.PracticeMinutes != 10 || digest.CurrentStreak != 1 {
+		t.Errorf("digest = %+v, want 1 session of 10 minutes and a 1 day streak", digest)
+	}
+	if len(digest.UpcomingHomework) != 1 || digest.UpcomingHomework[0].Title != "Practice twice" {
+		t.Errorf("upcoming homework = %+v, want the open homework", digest.UpcomingHomework)
+	}
+
+	// Monday 09:00 in Tokyo is inside the default window there, but only midnight in UTC
+	api.DigestService.WithClock(clock.NewFake(time.Date(2026, 10, 12, 9, 0, 0, 0, tokyo(t))))
+	t.Cleanup(func() { api.DigestService.WithClock(clock.System) })
+
+	if _, err := api.DigestService.SendDue(context.Background()); err != nil {
+		t.Fatalf("SendDue() error = %v", err)
+	}
+	student.do(http.MethodGet, "/notifications/preferences", nil, http.StatusOK, &prefs)
+	if prefs.LastDigestAt == nil {
+		t.Fatal("last_digest_at not set, want the student claimed in the send window")
+	}
+	claimedAt := *prefs.LastDigestAt
+
+	// A second run in the same window sends nothing again
+	if _, err := api.DigestService.SendDue(context.Background()); err != nil {
+		t.Fatalf("SendDue() error = %v", err)
+	}
+	student.do(http.MethodGet, "/notifications/preferences", nil, http.StatusOK, &prefs)
+	if prefs.LastDigestAt == nil || !prefs.LastDigestAt.Equal(claimedAt) {
+		t.Errorf("last_digest_at = %v, want unchanged %v", prefs.LastDigestAt, claimedAt)
+	}
+
+	// Opting out keeps the time zone
+	student.do(http.MethodPut, "/notifications/preferences", map[string]any{"weekly_digest": false}, http.StatusOK, &prefs)
+	if prefs.WeeklyDigest || prefs.Timezone != "Asia/Tokyo" {
+		t.Errorf("preferences = %+v, want digest off in Asia/Tokyo", prefs)
+	}
+}
+
+func tokyo(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("Failed to load time zone: %v", err)
+	}
+	return loc
+}
@@ -0,0 +1,74 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestDiscussionBoard(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+	classmate := newStudent(t)
+	outsider := newStudent(t)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Discussion Forms",
+	}, http.StatusCreated, &program)
+	programPath := "/programs/" + program.ID.String()
+	admin.do(http.MethodPost, programPath+"/assign", map[string]any{
+		"user_ids": []string{student.user.ID.String(), classmate.user.ID.String()},
+	}, http.StatusOK, nil)
+
+	// Only assigned students can see the board or be mentioned
+	outsider.do(http.MethodGet, programPath+"/topics", nil, http.StatusForbidden, nil)
+	student.do(http.MethodPost, programPath+"/topics", map[string]any{
+		"title":   "Breathing",
+		"content": fmt.Sprintf("@[Outsider](%s) how do you breathe?", outsider.user.ID),
+	}, http.StatusBadRequest, nil)
+
+	var topic models.DiscussionTopic
+	student.do(http.MethodPost, programPath+"/topics", map[string]any{
+		"title":   "Breathing",
+		"content": "When do you breathe out during the third form?",
+	}, http.StatusCreated, &topic)
+
+	classmate.do(http.MethodPost, "/topics/"+topic.ID.String()+"/replies", map[string]any{
+		"content": "On the push.",
+	}, http.StatusCreated, nil)
+
+	var notifications struct {
+		Notifications []models.Notification `json:"notifications"`
+	}
+	student.do(http.MethodGet, "/notifications", nil, http.StatusOK, &notifications)
+	if len(notifications.Notifications) != 1 || notifications.Notifications[0].Type != models.NotificationDiscussionReply {
+		t.Errorf("notifications = %+v, want one %s notification", notifications.Notifications, models.NotificationDiscussionReply)
+	}
+
+	// Locked topics only accept replies from admins
+	student.do(http.MethodPut, "/topics/"+topic.ID.String(), map[string]any{"is_locked": true}, http.StatusForbidden, nil)
+	admin.do(http.MethodPut, "/topics/"+topic.ID.String(), map[string]any{"is_locked": true, "is_pinned": true}, http.StatusOK, nil)
+	classmate.do(http.MethodPost, "/topics/"+topic.ID.String()+"/replies", map[string]any{
+		"content": "Also on the pull?",
+	}, http.StatusForbidden, nil)
+	admin.do(http.MethodPost, "/topics/"+topic.ID.String()+"/replies", map[string]any{
+		"content": "Exactly, on the push.",
+	}, http.StatusCreated, nil)
+
+	var board struct {
+		Topics []models.DiscussionTopic `json:"topics"`
+	}
+	classmate.do(http.MethodGet, programPath+"/topics", nil, http.StatusOK, &board)
+	if len(board.Topics) != 1 || !board.Topics[0].IsPinned || board.Topics[0].ReplyCount != 2 {
+		t.Errorf("topics = %+v, want one pinned topic with 2 replies", board.Topics)
+	}
+
+	classmate.do(http.MethodDelete, "/topics/"+topic.ID.String(), nil, http.StatusForbidden, nil)
+	student.do(http.MethodDelete, "/topics/"+topic.ID.String(), nil, http.StatusOK, nil)
+	student.do(http.MethodGet, "/topics/"+topic.ID.String(), nil, http.StatusNotFound, nil)
+}
@@ -0,0 +1,68 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestDisplayTokens(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var shown, hidden models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Wall Routine",
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 120},
+		},
+	}, http.StatusCreated, &shown)
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Private Routine"}, http.StatusCreated, &hidden)
+
+	var created models.DisplayToken
+	student.do(http.MethodPost, "/display-tokens", map[string]any{"name": "Hall", "program_ids": []any{shown.ID}}, http.StatusForbidden, nil)
+	admin.do(http.MethodPost, "/display-tokens", map[string]any{"name": "Hall", "program_ids": []any{}}, http.StatusBadRequest, nil)
+	admin.do(http.MethodPost, "/display-tokens", map[string]any{"name": "Hall", "program_ids": []any{shown.ID}}, http.StatusCreated, &created)
+	if created.Token == "" || created.ExpiresAt != nil {
+		t.Fatalf("display token = %+v, want a token without expiry", created)
+	}
+
+	display := &client{t: t, token: created.Token}
+	var list struct {
+		Programs []models.DisplayProgram `json:"programs"`
+	}
+	display.do(http.MethodGet, "/display/programs", nil, http.StatusOK, &list)
+	if len(list.Programs) != 1 || list.Programs[0].ID != shown.ID || list.Programs[0].Timeline != nil {
+		t.Fatalf("display programs = %+v, want only the shown program without timeline", list.Programs)
+	}
+
+	var program models.DisplayProgram
+	display.do(http.MethodGet, "/display/programs/"+shown.ID.String(), nil, http.StatusOK, &program)
+	if program.Timeline == nil || program.Timeline.TotalDurationSeconds != 120 {
+		t.Fatalf("display program = %+v, want a 120 second timeline", program)
+	}
+	// Kiosk browsers pass the token in the URL
+	anonymous(t).do(http.MethodGet, "/display/programs/"+shown.ID.String()+"?token="+created.Token, nil, http.StatusOK, nil)
+
+	// Programs outside the scope, user routes and user tokens are all off limits
+	display.do(http.MethodGet, "/display/programs/"+hidden.ID.String(), nil, http.StatusNotFound, nil)
+	display.do(http.MethodGet, "/programs/"+shown.ID.String(), nil, http.StatusUnauthorized, nil)
+	student.do(http.MethodGet, "/display/programs", nil, http.StatusUnauthorized, nil)
+
+	var tokens struct {
+		DisplayTokens []models.DisplayToken `json:"display_tokens"`
+	}
+	admin.do(http.MethodGet, "/display-tokens?active_only=true", nil, http.StatusOK, &tokens)
+	for _, tok := range tokens.DisplayTokens {
+		if tok.ID == created.ID && (tok.Token != "" || tok.LastUsedAt == nil) {
+			t.Errorf("listed display token = %+v, want last use recorded and no token", tok)
+		}
+	}
+
+	admin.do(http.MethodDelete, "/display-tokens/"+created.ID.String(), nil, http.StatusOK, nil)
+	admin.do(http.MethodDelete, "/display-tokens/"+created.ID.String(), nil, http.StatusBadRequest, nil)
+	display.do(http.MethodGet, "/display/programs", nil, http.StatusUnauthorized, nil)
+}
@@ -0,0 +1,57 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestEmbedProgramPreview(t *testing.T) {
+	admin := newAdmin(t)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Embedded Routine",
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 600},
+		},
+	}, http.StatusCreated, &program)
+	var link models.ProgramShareLink
+	admin.do(http.MethodPost, "/programs/"+program.ID.String()+"/share-link", nil, http.StatusCreated, &link)
+
+	// Any site can read the preview
+	req, err := http.NewRequest(http.MethodGet, apiURL+"/embed/programs/"+link.Token, nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Origin", "https://school.example.com")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /embed/programs failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Access-Control-Allow-Origin") != "*" || resp.Header.Get("Access-Control-Allow-Credentials") != "" {
+		t.Fatalf("embed response = %d with CORS headers %v, want 200 open to any origin without credentials", resp.StatusCode, resp.Header)
+	}
+
+	var preview models.ProgramPreview
+	anonymous(t).do(http.MethodGet, "/embed/programs/"+link.Token, nil, http.StatusOK, &preview)
+	if preview.Name != "E2E Embedded Routine" || preview.ExerciseCount != 1 || preview.TotalDurationSeconds != 600 || preview.URL != link.URL {
+		t.Fatalf("preview = %+v, want the routine summary linking to the share page", preview)
+	}
+	anonymous(t).do(http.MethodGet, "/embed/programs/not-a-token", nil, http.StatusNotFound, nil)
+
+	var embed models.OEmbed
+	oembedPath := "/oembed?maxwidth=320&url=" + url.QueryEscape(link.URL)
+	anonymous(t).do(http.MethodGet, oembedPath, nil, http.StatusOK, &embed)
+	if embed.Type != "rich" || embed.Version != "1.0" || embed.Width != 320 || !strings.Contains(embed.HTML, "<iframe") {
+		t.Fatalf("oEmbed = %+v, want a rich iframe at most 320 wide", embed)
+	}
+	anonymous(t).do(http.MethodGet, oembedPath+"&format=xml", nil, http.StatusNotImplemented, nil)
+	anonymous(t).do(http.MethodGet, "/oembed?url="+url.QueryEscape("https://elsewhere.example.com/?token="+link.Token), nil, http.StatusNotFound, nil)
+}
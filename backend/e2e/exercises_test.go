@@ -0,0 +1,92 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestExercises(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Exercise Editing",
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300},
+		},
+	}, http.StatusCreated, &program)
+	exercisesPath := "/programs/" + program.ID.String() + "/exercises"
+
+	circles := map[string]any{"name": "Arm Circles", "order_index": 1, "exercise_type": "repetition", "repetitions": 20}
+	student.do(http.MethodPost, exercisesPath, circles, http.StatusForbidden, nil)
+	var created models.Exercise
+	admin.do(http.MethodPost, exercisesPath, circles, http.StatusCreated, &created)
+
+	var list struct {
+		Exercises []models.Exercise `json:"exercises"`
+	}
+	student.do(http.MethodGet, exercisesPath, nil, http.StatusOK, &list)
+	if len(list.Exercises) != 2 || list.Exercises[1].ID != created.ID {
+		t.Fatalf("exercises = %+v, want the meditation and the arm circles", list.Exercises)
+	}
+	meditation := list.Exercises[0]
+
+	order := map[string]any{"exercise_ids": []string{created.ID.String(), meditation.ID.String()}}
+	student.do(http.MethodPut, exercisesPath+"/reorder", order, http.StatusForbidden, nil)
+	admin.do(http.MethodPut, exercisesPath+"/reorder", map[string]any{"exercise_ids": []string{created.ID.String()}}, http.StatusBadRequest, nil)
+	admin.do(http.MethodPut, exercisesPath+"/reorder", order, http.StatusOK, nil)
+
+	update := map[string]any{"name": "Big Arm Circles", "exercise_type": "repetition", "repetitions": 30}
+	student.do(http.MethodPut, "/exercises/"+created.ID.String(), update, http.StatusForbidden, nil)
+	admin.do(http.MethodPut, "/exercises/"+created.ID.String(), update, http.StatusOK, nil)
+
+	admin.do(http.MethodGet, exercisesPath, nil, http.StatusOK, &list)
+	if len(list.Exercises) != 2 || list.Exercises[0].Name != "Big Arm Circles" {
+		t.Fatalf("exercises = %+v, want the renamed arm circles first", list.Exercises)
+	}
+
+	student.do(http.MethodDelete, "/exercises/"+meditation.ID.String(), nil, http.StatusForbidden, nil)
+	admin.do(http.MethodDelete, "/exercises/"+meditation.ID.String(), nil, http.StatusOK, nil)
+	admin.do(http.MethodDelete, "/exercises/"+meditation.ID.String(), nil, http.StatusNotFound, nil)
+}
+
+func TestExerciseAuthorization(t *testing.T) {
+	admin := newAdmin(t)
+	owner := newStudent(t)
+	other := newStudent(t)
+
+	var program models.ProgramCreateResult
+	owner.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Own Routine"}, http.StatusCreated, &program)
+	exercisesPath := "/programs/" + program.ID.String() + "/exercises"
+
+	// Students edit the exercises of their own programs
+	var exercise models.Exercise
+	owner.do(http.MethodPost, exercisesPath, map[string]any{"name": "Horse Stance", "exercise_type": "timed", "duration_seconds": 120}, http.StatusCreated, &exercise)
+	exercisePath := "/exercises/" + exercise.ID.String()
+	owner.do(http.MethodPut, exercisePath, map[string]any{"name": "Low Horse Stance", "exercise_type": "timed", "duration_seconds": 180}, http.StatusOK, nil)
+
+	// Nobody else but admins
+	other.do(http.MethodPost, exercisesPath, map[string]any{"name": "Intruder", "exercise_type": "timed", "duration_seconds": 60}, http.StatusForbidden, nil)
+	other.do(http.MethodPut, exercisePath, map[string]any{"name": "Intruder", "exercise_type": "timed", "duration_seconds": 60}, http.StatusForbidden, nil)
+	other.do(http.MethodPut, exercisesPath+"/reorder", map[string]any{"exercise_ids": []string{exercise.ID.String()}}, http.StatusForbidden, nil)
+	other.do(http.MethodDelete, exercisePath, nil, http.StatusForbidden, nil)
+
+	var added models.Exercise
+	admin.do(http.MethodPost, exercisesPath, map[string]any{"name": "Cool Down", "exercise_type": "timed", "duration_seconds": 60}, http.StatusCreated, &added)
+	admin.do(http.MethodPut, exercisesPath+"/reorder", map[string]any{"exercise_ids": []string{added.ID.String(), exercise.ID.String()}}, http.StatusOK, nil)
+	admin.do(http.MethodDelete, "/exercises/"+added.ID.String(), nil, http.StatusOK, nil)
+	owner.do(http.MethodDelete, exercisePath, nil, http.StatusOK, nil)
+
+	var list struct {
+		Exercises []models.Exercise `json:"exercises"`
+	}
+	owner.do(http.MethodGet, exercisesPath, nil, http.StatusOK, &list)
+	if len(list.Exercises) != 0 {
+		t.Fatalf("exercises = %+v, want none left", list.Exercises)
+	}
+}
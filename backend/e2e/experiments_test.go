@@ -0,0 +1,84 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestProgramExperiment(t *testing.T) {
+	admin := newAdmin(t)
+
+	variants := make([]models.ProgramCreateResult, 2)
+	for i, rest := range []int{10, 30} {
+		admin.do(http.MethodPost, "/programs", map[string]any{
+			"name": "E2E Experiment Routine",
+			"exercises": []map[string]any{
+				{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300, "rest_after_seconds": rest},
+			},
+		}, http.StatusCreated, &variants[i])
+	}
+
+	var experiment models.ProgramExperiment
+	admin.do(http.MethodPost, "/experiments", map[string]any{"name": "Rest times", "program_a_id": variants[0].ID, "program_b_id": variants[0].ID}, http.StatusBadRequest, nil)
+	admin.do(http.MethodPost, "/experiments", map[string]any{"name": "Rest times", "program_a_id": variants[0].ID, "program_b_id": variants[1].ID}, http.StatusCreated, &experiment)
+
+	students := []*client{newStudent(t), newStudent(t), newStudent(t), newStudent(t)}
+	userIDs := make([]string, 0, len(students))
+	for _, s := range students {
+		userIDs = append(userIDs, s.user.ID.String())
+	}
+	students[0].do(http.MethodPost, "/experiments/"+experiment.ID.String()+"/assign", map[string]any{"user_ids": userIDs}, http.StatusForbidden, nil)
+	var assigned struct {
+		Participants []models.ExperimentParticipant `json:"participants"`
+	}
+	admin.do(http.MethodPost, "/experiments/"+experiment.ID.String()+"/assign", map[string]any{"user_ids": userIDs}, http.StatusOK, &assigned)
+	perVariant := map[string]int{}
+	variantOf := map[string]models.ExperimentParticipant{}
+	for _, p := range assigned.Participants {
+		perVariant[p.Variant]++
+		variantOf[p.UserID.String()] = p
+	}
+	if perVariant[models.VariantA] != 2 || perVariant[models.VariantB] != 2 {
+		t.Fatalf("participants = %+v, want two per variant", assigned.Participants)
+	}
+
+	// Assigning again keeps the variants
+	var again struct {
+		Participants []models.ExperimentParticipant `json:"participants"`
+	}
+	admin.do(http.MethodPost, "/experiments/"+experiment.ID.String()+"/assign", map[string]any{"user_ids": userIDs[:1]}, http.StatusOK, &again)
+	if len(again.Participants) != 1 || again.Participants[0].Variant != variantOf[userIDs[0]].Variant {
+		t.Errorf("participants = %+v, want the first student's variant kept", again.Participants)
+	}
+
+	// The first student completes a session of their variant
+	first := variantOf[userIDs[0]]
+	var session models.PracticeSession
+	students[0].do(http.MethodPost, "/sessions/start", map[string]any{"program_id": first.ProgramID}, http.StatusCreated, &session)
+	if session.ExperimentID == nil || *session.ExperimentID != experiment.ID || session.Variant == nil || *session.Variant != first.Variant {
+		t.Errorf("session = %+v, want it tagged with variant %s", session, first.Variant)
+	}
+	students[0].do(http.MethodPut, "/sessions/"+session.ID.String()+"/complete", map[string]any{"total_duration_seconds": 300, "completion_rate": 80}, http.StatusOK, nil)
+
+	var results models.ExperimentResults
+	admin.do(http.MethodGet, "/experiments/"+experiment.ID.String()+"/results", nil, http.StatusOK, &results)
+	if len(results.Variants) != 2 {
+		t.Fatalf("results = %+v, want both variants", results)
+	}
+	for _, v := range results.Variants {
+		if v.Participants != 2 {
+			t.Errorf("variant %s has %d participants, want 2", v.Variant, v.Participants)
+		}
+		if v.Variant == first.Variant {
+			if v.SessionsCompleted != 1 || v.ActiveParticipants != 1 || v.AverageCompletionRate == nil || *v.AverageCompletionRate != 80 || v.SessionsPerWeek != 0.5 {
+				t.Errorf("variant %s = %+v, want the completed session", v.Variant, v)
+			}
+		} else if v.SessionsStarted != 0 || v.AverageCompletionRate != nil {
+			t.Errorf("variant %s = %+v, want no sessions", v.Variant, v)
+		}
+	}
+}
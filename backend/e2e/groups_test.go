@@ -0,0 +1,124 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestGroupStats(t *testing.T) {
+	admin := newAdmin(t)
+
+	var group models.Group
+	admin.do(http.MethodPost, "/groups", map[string]any{"name": "E2E Class " + uuid.New().String()[:8]}, http.StatusCreated, &group)
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Class Routine"}, http.StatusCreated, &program)
+
+	diligent := joinGroup(t, admin, group.ID, program.ID)
+	idle := joinGroup(t, admin, group.ID, program.ID)
+
+	var session models.PracticeSession
+	diligent.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": program.ID}, http.StatusCreated, &session)
+	diligent.do(http.MethodPut, "/sessions/"+session.ID.String()+"/complete", map[string]any{
+		"total_duration_seconds": 1200,
+		"completion_rate":        80,
+	}, http.StatusOK, nil)
+	idle.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": program.ID}, http.StatusCreated, nil)
+
+	statsPath := "/groups/" + group.ID.String() + "/stats"
+	diligent.do(http.MethodGet, statsPath, nil, http.StatusForbidden, nil)
+	admin.do(http.MethodGet, "/groups/"+uuid.New().String()+"/stats", nil, http.StatusNotFound, nil)
+	admin.do(http.MethodGet, statsPath+"?from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z", nil, http.StatusBadRequest, nil)
+
+	var stats models.GroupStats
+	admin.do(http.MethodGet, statsPath, nil, http.StatusOK, &stats)
+	if len(stats.Members) != 2 {
+		t.Fatalf("members = %+v, want both students", stats.Members)
+	}
+	for _, m := range stats.Members {
+		switch m.UserID {
+		case diligent.user.ID:
+			if m.SessionsStarted != 1 || m.SessionsCompleted != 1 || m.PracticeMinutes != 20 || m.PracticeDays != 1 || m.SessionsPerWeek != 1 || m.CurrentStreak != 1 {
+				t.Errorf("diligent student = %+v, want one completed session of 20 minutes and a streak", m)
+			}
+		case idle.user.ID:
+			if m.SessionsStarted != 1 || m.SessionsCompleted != 0 || m.AverageCompletionRate != nil || m.CurrentStreak != 0 {
+				t.Errorf("idle student = %+v, want one unfinished session", m)
+			}
+		default:
+			t.Errorf("unexpected member %+v", m)
+		}
+	}
+	s := stats.Summary
+	if s.Members != 2 || s.ActiveMembers != 1 || s.SessionsStarted != 2 || s.SessionsCompleted != 1 || s.PracticeMinutes != 20 ||
+		s.SessionsPerWeek != 0.5 || s.MembersOnStreak != 1 || s.AverageCompletionRate == nil || *s.AverageCompletionRate != 80 {
+		t.Errorf("summary = %+v, want the diligent student's session over two members", s)
+	}
+
+	// Nothing was practiced in an earlier window
+	from := time.Now().AddDate(0, 0, -30).UTC().Format(time.RFC3339)
+	to := time.Now().AddDate(0, 0, -14).UTC().Format(time.RFC3339)
+	admin.do(http.MethodGet, fmt.Sprintf("%s?from=%s&to=%s", statsPath, from, to), nil, http.StatusOK, &stats)
+	if len(stats.Members) != 2 || stats.Summary.SessionsStarted != 0 || stats.Summary.SessionsPerWeek != 0 {
+		t.Errorf("earlier window = %+v, want both members without sessions", stats)
+	}
+}
+
+func TestGroupStats_UnratedSessions(t *testing.T) {
+	admin := newAdmin(t)
+
+	var group models.Group
+	admin.do(http.MethodPost, "/groups", map[string]any{"name": "E2E Class " + uuid.New().String()[:8]}, http.StatusCreated, &group)
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Class Routine"}, http.StatusCreated, &program)
+
+	complete := func(student *client, body map[string]any) {
+		var session models.PracticeSession
+		student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": program.ID}, http.StatusCreated, &session)
+		student.do(http.MethodPut, "/sessions/"+session.ID.String()+"/complete", body, http.StatusOK, nil)
+	}
+	first := joinGroup(t, admin, group.ID, program.ID)
+	complete(first, map[string]any{"total_duration_seconds": 600, "completion_rate": 80})
+	complete(first, map[string]any{"total_duration_seconds": 600}) // Without a completion rate
+	second := joinGroup(t, admin, group.ID, program.ID)
+	complete(second, map[string]any{"total_duration_seconds": 600, "completion_rate": 20})
+
+	var stats models.GroupStats
+	admin.do(http.MethodGet, "/groups/"+group.ID.String()+"/stats", nil, http.StatusOK, &stats)
+	for _, m := range stats.Members {
+		if m.UserID == first.user.ID && (m.SessionsCompleted != 2 || m.AverageCompletionRate == nil || *m.AverageCompletionRate != 80) {
+			t.Errorf("first student = %+v, want two sessions averaging the one rated 80", m)
+		}
+	}
+	// The unrated session doesn't count towards the average, which would otherwise be 60
+	s := stats.Summary
+	if s.SessionsCompleted != 3 || s.AverageCompletionRate == nil || *s.AverageCompletionRate != 50 {
+		t.Errorf("summary = %+v, want three sessions with an average completion rate of 50", s)
+	}
+}
+
+// joinGroup registers a student through an invitation to the group that assigns the program
+func joinGroup(t *testing.T, admin *client, groupID, programID uuid.UUID) *client {
+	t.Helper()
+
+	var invitation models.Invitation
+	admin.do(http.MethodPost, "/invitations", map[string]any{
+		"group_id":    groupID,
+		"program_ids": []uuid.UUID{programID},
+	}, http.StatusCreated, &invitation)
+
+	var resp authResponse
+	anonymous(t).do(http.MethodPost, "/auth/register", map[string]any{
+		"email":        fmt.Sprintf("e2e-%s@test.com", uuid.New().String()[:8]),
+		"password":     testPassword,
+		"full_name":    "E2E Group Member",
+		"invite_token": invitation.Token,
+	}, http.StatusCreated, &resp)
+	return &client{t: t, user: resp.User, token: resp.Tokens.AccessToken}
+}
@@ -0,0 +1,98 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestHomeworkDeadlines(t *testing.T) {
+	admin := newAdmin(t)
+	punctual := newStudent(t)
+	tardy := newStudent(t)
+	outsider := newStudent(t)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Homework Standing"}, http.StatusCreated, &program)
+	admin.do(http.MethodPost, "/programs/"+program.ID.String()+"/assign", map[string]any{
+		"user_ids": []string{punctual.user.ID.String(), tardy.user.ID.String()},
+	}, http.StatusOK, nil)
+
+	admin.do(http.MethodPost, "/homework", map[string]any{
+		"program_id":  program.ID,
+		"title":       "Too late",
+		"requirement": "sessions",
+		"due_at":      time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+	}, http.StatusBadRequest, nil)
+
+	var homework models.Homework
+	admin.do(http.MethodPost, "/homework", map[string]any{
+		"program_id":  program.ID,
+		"title":       "Stand every day",
+		"requirement": "sessions",
+		"due_at":      time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+	}, http.StatusCreated, &homework)
+	if homework.StudentCount != 2 || homework.RequiredSessions != 1 {
+		t.Fatalf("homework = %+v, want 2 students needing 1 session", homework)
+	}
+	homeworkPath := "/homework/" + homework.ID.String()
+	outsider.do(http.MethodGet, homeworkPath, nil, http.StatusNotFound, nil)
+
+	completeSession(punctual, program.ID.String())
+	var own models.Homework
+	punctual.do(http.MethodGet, homeworkPath, nil, http.StatusOK, &own)
+	if own.Status != models.HomeworkOnTime || own.CompletedAt == nil {
+		t.Errorf("own homework = %+v, want completed on time", own)
+	}
+
+	// Let the deadline pass and run the overdue job
+	if _, err := pool.Exec(context.Background(), "UPDATE homework SET due_at = CURRENT_TIMESTAMP WHERE id = $1", homework.ID); err != nil {
+		t.Fatalf("Failed to backdate homework: %v", err)
+	}
+	if _, err := api.HomeworkService.MarkOverdue(context.Background()); err != nil {
+		t.Fatalf("MarkOverdue() error = %v", err)
+	}
+
+	var notifications struct {
+		Notifications []models.Notification `json:"notifications"`
+	}
+	tardy.do(http.MethodGet, "/notifications", nil, http.StatusOK, &notifications)
+	if len(notifications.Notifications) != 1 || notifications.Notifications[0].Type != models.NotificationHomeworkOverdue {
+		t.Errorf("notifications = %+v, want one %s notification", notifications.Notifications, models.NotificationHomeworkOverdue)
+	}
+	punctual.do(http.MethodGet, "/notifications", nil, http.StatusOK, &notifications)
+	if len(notifications.Notifications) != 0 {
+		t.Errorf("notifications = %+v, want none for the punctual student", notifications.Notifications)
+	}
+
+	var detail models.HomeworkDetail
+	admin.do(http.MethodGet, homeworkPath+"/students", nil, http.StatusOK, &detail)
+	if detail.Counts.OnTime != 1 || detail.Counts.Overdue != 1 {
+		t.Errorf("counts = %+v, want 1 on time and 1 overdue", detail.Counts)
+	}
+
+	// Work after the deadline still counts, as late
+	completeSession(tardy, program.ID.String())
+	var mine struct {
+		Homework []models.Homework `json:"homework"`
+	}
+	tardy.do(http.MethodGet, "/homework", nil, http.StatusOK, &mine)
+	if len(mine.Homework) != 1 || mine.Homework[0].Status != models.HomeworkLate {
+		t.Errorf("homework = %+v, want it late", mine.Homework)
+	}
+
+	var report models.HomeworkReport
+	admin.do(http.MethodGet, "/admin/homework-report?program_id="+program.ID.String(), nil, http.StatusOK, &report)
+	if len(report.Groups) != 1 || report.Groups[0].GroupID != nil {
+		t.Fatalf("report groups = %+v, want only students without a group", report.Groups)
+	}
+	ungrouped := report.Groups[0]
+	if ungrouped.Assigned != 2 || ungrouped.OnTime != 1 || ungrouped.Late != 1 || ungrouped.OnTimeRate == nil || *ungrouped.OnTimeRate != 0.5 {
+		t.Errorf("ungrouped counts = %+v, want 1 on time and 1 late", ungrouped)
+	}
+}
@@ -0,0 +1,98 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/auth"
+)
+
+func TestIntegrationSignedRequests(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var public, private models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name":      "E2E Website Routine",
+		"is_public": true,
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 120},
+		},
+	}, http.StatusCreated, &public)
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Unlisted Routine"}, http.StatusCreated, &private)
+
+	student.do(http.MethodPost, "/integration-keys", map[string]any{"name": "Website"}, http.StatusForbidden, nil)
+	var key models.IntegrationKey
+	admin.do(http.MethodPost, "/integration-keys", map[string]any{"name": "Website"}, http.StatusCreated, &key)
+	if key.Secret == "" {
+		t.Fatalf("integration key = %+v, want the secret on creation", key)
+	}
+	var keys struct {
+		IntegrationKeys []models.IntegrationKey `json:"integration_keys"`
+	}
+	admin.do(http.MethodGet, "/integration-keys", nil, http.StatusOK, &keys)
+	for _, k := range keys.IntegrationKeys {
+		if k.Secret != "" {
+			t.Fatalf("listed key %s exposes its secret", k.ID)
+		}
+	}
+
+	now := time.Now()
+	programPath := "/integration/programs/" + public.ID.String()
+	if status := signedGet(t, key, programPath, now, "n-1"); status != http.StatusOK {
+		t.Errorf("signed request status = %d, want 200", status)
+	}
+	if status := signedGet(t, key, programPath, now, "n-1"); status != http.StatusUnauthorized {
+		t.Errorf("replayed request status = %d, want 401", status)
+	}
+	if status := signedGet(t, key, programPath, now.Add(-time.Hour), "n-2"); status != http.StatusUnauthorized {
+		t.Errorf("stale request status = %d, want 401", status)
+	}
+	if status := signedGet(t, key, "/integration/programs/"+private.ID.String(), now, "n-3"); status != http.StatusNotFound {
+		t.Errorf("private program status = %d, want 404", status)
+	}
+	wrongSecret := key
+	wrongSecret.Secret = "not-the-secret"
+	if status := signedGet(t, wrongSecret, programPath, now, "n-4"); status != http.StatusUnauthorized {
+		t.Errorf("wrongly signed request status = %d, want 401", status)
+	}
+	// Unsigned requests, even from signed-in users, are refused
+	student.do(http.MethodGet, "/integration/programs", nil, http.StatusUnauthorized, nil)
+
+	admin.do(http.MethodDelete, "/integration-keys/"+key.ID.String(), nil, http.StatusOK, nil)
+	if status := signedGet(t, key, programPath, now, "n-5"); status != http.StatusUnauthorized {
+		t.Errorf("revoked key status = %d, want 401", status)
+	}
+}
+
+// signedGet sends a GET to path signed with the key and returns the status code
+func signedGet(t *testing.T, key models.IntegrationKey, path string, at time.Time, nonce string) int {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, apiURL+path, nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	target, err := url.Parse(apiURL + path)
+	if err != nil {
+		t.Fatalf("Failed to parse URL: %v", err)
+	}
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	req.Header.Set("X-Integration-Key", key.ID.String())
+	req.Header.Set("X-Timestamp", timestamp)
+	req.Header.Set("X-Nonce", nonce)
+	req.Header.Set("X-Signature", auth.SignRequest(key.Secret, http.MethodGet, target.RequestURI(), timestamp, nonce, nil))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
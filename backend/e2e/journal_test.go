@@ -0,0 +1,142 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+// pngHeader is enough of a PNG for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00")
+
+func TestProgressJournal(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+	other := newStudent(t)
+
+	var created models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Journal Routine",
+		"exercises": []map[string]any{
+			{"name": "Horse Stance", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300},
+		},
+	}, http.StatusCreated, &created)
+	programID := created.ID.String()
+	var program models.ProgramWithExercises
+	admin.do(http.MethodGet, "/programs/"+programID, nil, http.StatusOK, &program)
+	admin.do(http.MethodPost, "/programs/"+programID+"/assign", map[string]any{"user_ids": []string{student.user.ID.String()}}, http.StatusOK, nil)
+
+	// Instructors ask for media, which notifies the student
+	requestPath := "/users/" + student.user.ID.String() + "/journal-requests"
+	admin.do(http.MethodPost, "/users/"+other.user.ID.String()+"/journal-requests", map[string]any{"program_id": programID}, http.StatusNotFound, nil)
+	admin.do(http.MethodPost, requestPath, map[string]any{"program_id": programID, "message": "Film your horse stance from the side"}, http.StatusNoContent, nil)
+	var notifications struct {
+		Notifications []models.Notification `json:"notifications"`
+	}
+	student.do(http.MethodGet, "/notifications", nil, http.StatusOK, &notifications)
+	if len(notifications.Notifications) == 0 || notifications.Notifications[0].Type != models.NotificationJournalRequest {
+		t.Errorf("notifications = %+v, want a journal request", notifications.Notifications)
+	}
+
+	fields := map[string]string{"program_id": programID, "exercise_id": program.Exercises[0].ID.String(), "caption": "Week 1"}
+	upload(t, other, fields, pngHeader, http.StatusNotFound, nil)
+	upload(t, student, fields, []byte("not an image"), http.StatusBadRequest, nil)
+	upload(t, student, map[string]string{"program_id": programID, "recorded_at": "2999-01-01T00:00:00Z"}, pngHeader, http.StatusBadRequest, nil)
+	var entry models.JournalEntry
+	upload(t, student, fields, pngHeader, http.StatusCreated, &entry)
+	if entry.MediaType != models.JournalPhoto || entry.ContentType != "image/png" || entry.SizeBytes != int64(len(pngHeader)) {
+		t.Errorf("entry = %+v, want the PNG photo", entry)
+	}
+
+	var journal struct {
+		Entries []models.JournalEntry `json:"entries"`
+	}
+	student.do(http.MethodGet, "/journal?program_id="+programID, nil, http.StatusOK, &journal)
+	if len(journal.Entries) != 1 || journal.Entries[0].ID != entry.ID {
+		t.Errorf("journal = %+v, want the uploaded entry", journal.Entries)
+	}
+
+	// Private until shared, for other students and instructors alike
+	entryPath := "/journal/" + entry.ID.String()
+	if media := download(t, student, entryPath+"/media", http.StatusOK); !bytes.Equal(media, pngHeader) {
+		t.Errorf("media = %q, want the uploaded bytes", media)
+	}
+	other.do(http.MethodGet, entryPath, nil, http.StatusNotFound, nil)
+	download(t, admin, entryPath+"/media", http.StatusNotFound)
+	student.do(http.MethodPut, entryPath+"/shares/"+other.user.ID.String(), nil, http.StatusBadRequest, nil)
+	other.do(http.MethodPut, entryPath+"/shares/"+admin.user.ID.String(), nil, http.StatusNotFound, nil)
+
+	var shared models.JournalEntry
+	student.do(http.MethodPut, entryPath+"/shares/"+admin.user.ID.String(), nil, http.StatusOK, &shared)
+	if !slices.Contains(shared.SharedWith, admin.user.ID) {
+		t.Errorf("shared_with = %v, want the instructor", shared.SharedWith)
+	}
+	download(t, admin, entryPath+"/media", http.StatusOK)
+	admin.do(http.MethodGet, "/journal/shared?user_id="+student.user.ID.String(), nil, http.StatusOK, &journal)
+	if len(journal.Entries) != 1 || journal.Entries[0].ID != entry.ID {
+		t.Errorf("shared journal = %+v, want the shared entry", journal.Entries)
+	}
+	student.do(http.MethodGet, "/journal/shared", nil, http.StatusForbidden, nil)
+	admin.do(http.MethodDelete, entryPath, nil, http.StatusNotFound, nil)
+
+	student.do(http.MethodDelete, entryPath+"/shares/"+admin.user.ID.String(), nil, http.StatusNoContent, nil)
+	download(t, admin, entryPath+"/media", http.StatusNotFound)
+	student.do(http.MethodDelete, entryPath, nil, http.StatusNoContent, nil)
+	student.do(http.MethodGet, entryPath, nil, http.StatusNotFound, nil)
+}
+
+// upload posts media with form fields to the journal, checks the status code and decodes the
+// response into out unless it is nil
+func upload(t *testing.T, c *client, fields map[string]string, media []byte, wantStatus int, out any) {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := w.WriteField(name, value); err != nil {
+			t.Fatalf("Failed to write form field: %v", err)
+		}
+	}
+	part, err := w.CreateFormFile("file", "media")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	if _, err := part.Write(media); err != nil {
+		t.Fatalf("Failed to write form file: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close form: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, apiURL+"/journal", &body)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /journal failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("POST /journal: status = %d, want %d\nbody: %s", resp.StatusCode, wantStatus, buf.Bytes())
+	}
+	if out != nil {
+		if err := json.Unmarshal(buf.Bytes(), out); err != nil {
+			t.Fatalf("POST /journal: failed to decode response: %v\nbody: %s", err, buf.Bytes())
+		}
+	}
+}
@@ -0,0 +1,123 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestLimitationsAdjustPrograms(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Adjusted Routine",
+		"exercises": []map[string]any{
+			{"name": "Horse Stance", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300, "contraindications": []string{"knees"}},
+			{"name": "Headstand", "order_index": 1, "exercise_type": "timed", "duration_seconds": 60, "contraindications": []string{"hypertension", "neck"}},
+			{"name": "Arm Circles", "order_index": 2, "exercise_type": "repetition", "repetitions": 20, "contraindications": []string{"shoulders"}},
+		},
+	}, http.StatusCreated, &created)
+	programPath := "/programs/" + created.ID.String()
+
+	var program models.ProgramWithExercises
+	admin.do(http.MethodGet, programPath, nil, http.StatusOK, &program)
+	horseID := program.Exercises[0].ID.String()
+	admin.do(http.MethodPost, "/exercises/"+horseID+"/substitutes", map[string]any{
+		"name": "Low Horse Stance", "contraindications": []string{"knees"},
+	}, http.StatusCreated, nil)
+	var seated models.ExerciseSubstitute
+	admin.do(http.MethodPost, "/exercises/"+horseID+"/substitutes", map[string]any{
+		"name": "Seated Meditation", "duration_seconds": 90,
+	}, http.StatusCreated, &seated)
+	admin.do(http.MethodPost, programPath+"/assign", map[string]any{"user_ids": []string{student.user.ID.String()}}, http.StatusOK, nil)
+
+	student.do(http.MethodPut, "/auth/me/limitations", map[string]any{"joints": []string{"hypertension"}}, http.StatusBadRequest, nil)
+	var profile models.Limitations
+	student.do(http.MethodPut, "/auth/me/limitations", map[string]any{
+		"joints": []string{"knees"}, "conditions": []string{"hypertension"}, "notes": "Torn meniscus in 2024",
+	}, http.StatusOK, &profile)
+
+	// Horse stance is replaced by its safe substitute, the headstand has none and is flagged
+	adjusted := myProgram(t, student, created.ID.String())
+	horse, headstand, circles := adjusted.Exercises[0], adjusted.Exercises[1], adjusted.Exercises[2]
+	if horse.Name != "Seated Meditation" || horse.Adjustment == nil || horse.Adjustment.Action != models.AdjustmentSubstituted ||
+		*horse.Adjustment.SubstituteID != seated.ID || horse.Adjustment.OriginalName != "Horse Stance" {
+		t.Errorf("horse stance = %+v, want the seated substitute", horse)
+	}
+	if headstand.Adjustment == nil || headstand.Adjustment.Action != models.AdjustmentFlagged {
+		t.Errorf("headstand adjustment = %+v, want flagged", headstand.Adjustment)
+	}
+	if circles.Adjustment != nil || adjusted.PlanReviewStatus != models.PlanReviewPending {
+		t.Errorf("program = %+v, want arm circles unchanged and the plan pending review", adjusted)
+	}
+
+	var tl models.Timeline
+	student.do(http.MethodGet, programPath+"/timeline", nil, http.StatusOK, &tl)
+	if tl.Cues[0].ExerciseName != "Seated Meditation" {
+		t.Errorf("first cue = %+v, want the seated substitute", tl.Cues[0])
+	}
+
+	// Instructors review the adjusted plan
+	planPath := "/users/" + student.user.ID.String() + programPath
+	if !pendingReview(t, admin, student, created.ID.String()) {
+		t.Error("plan is not waiting for review")
+	}
+	student.do(http.MethodGet, planPath+"/plan", nil, http.StatusForbidden, nil)
+	var plan models.AdjustedPlan
+	admin.do(http.MethodGet, planPath+"/plan", nil, http.StatusOK, &plan)
+	if plan.ReviewStatus != models.PlanReviewPending || plan.Limitations.Notes != "Torn meniscus in 2024" || plan.Exercises[0].Adjustment == nil {
+		t.Errorf("plan = %+v, want the pending adjusted plan", plan)
+	}
+	admin.do(http.MethodPut, planPath+"/plan-review", map[string]any{"status": "maybe"}, http.StatusBadRequest, nil)
+	admin.do(http.MethodPut, "/users/"+newStudent(t).user.ID.String()+programPath+"/plan-review", map[string]any{"status": "approved"}, http.StatusNotFound, nil)
+	admin.do(http.MethodPut, planPath+"/plan-review", map[string]any{"status": "approved", "note": "Keep the stool low"}, http.StatusOK, nil)
+
+	if status := myProgram(t, student, created.ID.String()).PlanReviewStatus; status != models.PlanReviewApproved {
+		t.Errorf("plan_review_status = %q, want approved", status)
+	}
+	if pendingReview(t, admin, student, created.ID.String()) {
+		t.Error("approved plan is still waiting for review")
+	}
+
+	// A changed profile needs a new review
+	student.do(http.MethodPut, "/auth/me/limitations", map[string]any{"joints": []string{"knees", "wrists"}}, http.StatusOK, nil)
+	if status := myProgram(t, student, created.ID.String()).PlanReviewStatus; status != models.PlanReviewPending {
+		t.Errorf("plan_review_status = %q, want pending after the profile changed", status)
+	}
+}
+
+func myProgram(t *testing.T, student *client, programID string) models.ProgramWithExercises {
+	t.Helper()
+
+	var mine struct {
+		Programs []models.ProgramWithExercises `json:"programs"`
+	}
+	student.do(http.MethodGet, "/my-programs", nil, http.StatusOK, &mine)
+	for _, p := range mine.Programs {
+		if p.Program.ID.String() == programID {
+			return p
+		}
+	}
+	t.Fatalf("my-programs doesn't contain %s", programID)
+	return models.ProgramWithExercises{}
+}
+
+func pendingReview(t *testing.T, admin, student *client, programID string) bool {
+	t.Helper()
+
+	var queue struct {
+		Reviews []models.PlanReviewQueueItem `json:"reviews"`
+	}
+	admin.do(http.MethodGet, "/admin/plan-reviews", nil, http.StatusOK, &queue)
+	for _, item := range queue.Reviews {
+		if item.UserID == student.user.ID && item.ProgramID.String() == programID {
+			return true
+		}
+	}
+	return false
+}
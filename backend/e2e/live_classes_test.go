@@ -0,0 +1,97 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestLiveClassAttendance(t *testing.T) {
+	admin := newAdmin(t)
+	early := newStudent(t)
+	latecomer := newStudent(t)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Live Class Forms"}, http.StatusCreated, &program)
+
+	start := time.Now().Add(-5 * time.Minute).UTC()
+	admin.do(http.MethodPost, "/classes", map[string]any{
+		"title":     "Backwards class",
+		"starts_at": start.Format(time.RFC3339),
+		"ends_at":   start.Add(-time.Hour).Format(time.RFC3339),
+	}, http.StatusBadRequest, nil)
+
+	var class models.LiveClass
+	admin.do(http.MethodPost, "/classes", map[string]any{
+		"title":      "Thursday forms",
+		"location":   "Main hall",
+		"program_id": program.ID,
+		"starts_at":  start.Format(time.RFC3339),
+		"ends_at":    start.Add(time.Hour).Format(time.RFC3339),
+	}, http.StatusCreated, &class)
+	classPath := "/classes/" + class.ID.String()
+
+	var code models.CheckInCode
+	early.do(http.MethodPost, classPath+"/check-in-code", nil, http.StatusForbidden, nil)
+	admin.do(http.MethodPost, classPath+"/check-in-code", map[string]any{"valid_minutes": 5}, http.StatusCreated, &code)
+	if len(code.Code) != 6 || !code.ExpiresAt.After(time.Now()) {
+		t.Fatalf("check-in code = %+v, want a 6-character code valid for a while", code)
+	}
+
+	early.do(http.MethodPost, "/classes/check-in", map[string]any{"code": "NOPE42"}, http.StatusBadRequest, nil)
+	var checkedIn models.LiveClass
+	early.do(http.MethodPost, "/classes/check-in", map[string]any{"code": code.Code}, http.StatusOK, &checkedIn)
+	if checkedIn.MyAttendance == nil || *checkedIn.MyAttendance != models.AttendancePresent || checkedIn.CheckInCode != nil {
+		t.Errorf("checked-in class = %+v, want present without the code", checkedIn)
+	}
+	early.do(http.MethodPost, "/classes/check-in", map[string]any{"code": code.Code}, http.StatusConflict, nil)
+
+	admin.do(http.MethodPut, classPath+"/attendance", map[string]any{
+		"entries": []map[string]any{{"user_id": latecomer.user.ID, "status": "late"}},
+	}, http.StatusOK, nil)
+	var roster struct {
+		Attendance []models.ClassAttendance `json:"attendance"`
+	}
+	admin.do(http.MethodGet, classPath+"/attendance", nil, http.StatusOK, &roster)
+	if len(roster.Attendance) != 2 {
+		t.Fatalf("attendance = %+v, want 2 students", roster.Attendance)
+	}
+	for _, a := range roster.Attendance {
+		if a.UserID == early.user.ID && a.Method != models.AttendanceCode {
+			t.Errorf("early attendance = %+v, want checked in with the code", a)
+		}
+	}
+
+	// Attendance shows up in the student's stats next to solo practice
+	completeSession(early, program.ID.String())
+	var stats models.SessionStats
+	early.do(http.MethodGet, "/sessions/stats", nil, http.StatusOK, &stats)
+	if stats.ClassesAttended != 1 || stats.ClassMinutes != 60 || stats.CompletedSessions != 1 {
+		t.Errorf("stats = %+v, want 1 class of 60 minutes and 1 session", stats)
+	}
+
+	var report models.AttendanceReport
+	admin.do(http.MethodGet, "/admin/attendance-report", nil, http.StatusOK, &report)
+	found := 0
+	for _, s := range report.Students {
+		switch s.UserID {
+		case early.user.ID:
+			found++
+			if s.ClassesAttended != 1 || s.PracticeSessions != 1 {
+				t.Errorf("early student report = %+v, want 1 class and 1 practice session", s)
+			}
+		case latecomer.user.ID:
+			found++
+			if s.ClassesAttended != 1 || s.ClassesLate != 1 || s.PracticeSessions != 0 {
+				t.Errorf("latecomer report = %+v, want 1 late class and no practice", s)
+			}
+		}
+	}
+	if found != 2 {
+		t.Errorf("report students = %+v, want both students", report.Students)
+	}
+}
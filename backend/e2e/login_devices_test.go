@@ -0,0 +1,96 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestLoginDevices(t *testing.T) {
+	student := newStudent(t)
+
+	// The first login is not alerted; there is no known device to compare with
+	phone, _ := loginFrom(t, student.user.Email, "Student phone")
+	var notifications struct {
+		Notifications []models.Notification `json:"notifications"`
+	}
+	phone.do(http.MethodGet, "/notifications", nil, http.StatusOK, &notifications)
+	if len(notifications.Notifications) != 0 {
+		t.Fatalf("notifications = %+v, want none after the first login", notifications.Notifications)
+	}
+
+	// Logging in again from the same device is not alerted either
+	loginFrom(t, student.user.Email, "Student phone")
+
+	intruder, intruderLogin := loginFrom(t, student.user.Email, "Unknown laptop")
+
+	// The alert is sent in the background, so give it a moment to land
+	for attempt := 0; attempt < 20 && len(notifications.Notifications) == 0; attempt++ {
+		if attempt > 0 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		phone.do(http.MethodGet, "/notifications", nil, http.StatusOK, &notifications)
+	}
+	if len(notifications.Notifications) != 1 || notifications.Notifications[0].Type != models.NotificationNewLogin {
+		t.Fatalf("notifications = %+v, want one %s notification", notifications.Notifications, models.NotificationNewLogin)
+	}
+
+	var list struct {
+		Devices []models.LoginDevice `json:"devices"`
+	}
+	phone.do(http.MethodGet, "/auth/me/devices", nil, http.StatusOK, &list)
+	if len(list.Devices) != 2 {
+		t.Fatalf("devices = %+v, want the phone and the laptop", list.Devices)
+	}
+	var laptop models.LoginDevice
+	for _, d := range list.Devices {
+		if d.Device != nil && *d.Device == "Unknown laptop" {
+			laptop = d
+		} else if !d.Current {
+			t.Errorf("device %+v should be marked current", d)
+		}
+	}
+
+	newStudent(t).do(http.MethodDelete, "/auth/me/devices/"+laptop.ID.String(), nil, http.StatusNotFound, nil)
+	phone.do(http.MethodDelete, "/auth/me/devices/"+laptop.ID.String(), nil, http.StatusNoContent, nil)
+
+	intruder.do(http.MethodGet, "/auth/me", nil, http.StatusUnauthorized, nil)
+	anonymous(t).do(http.MethodPost, "/auth/refresh", map[string]any{"refresh_token": intruderLogin.Tokens.RefreshToken}, http.StatusUnauthorized, nil)
+	phone.do(http.MethodGet, "/auth/me", nil, http.StatusOK, nil)
+
+	anonymous(t).do(http.MethodPost, "/auth/devices/revoke", map[string]any{"token": "not-a-token"}, http.StatusNotFound, nil)
+}
+
+// loginFrom logs in with an X-Device-Info header, as an app on that device would
+func loginFrom(t *testing.T, email, device string) (*client, authResponse) {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]any{"email": email, "password": testPassword})
+	req, err := http.NewRequest(http.MethodPost, apiURL+"/auth/login", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Device-Info", device)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Login status = %d, want 200", resp.StatusCode)
+	}
+
+	var auth authResponse
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		t.Fatalf("Failed to decode login response: %v", err)
+	}
+	return &client{t: t, user: auth.User, token: auth.Tokens.AccessToken}, auth
+}
@@ -0,0 +1,190 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestModerationAutoHidesReportedProgram(t *testing.T) {
+	owner := newStudent(t)
+	admin := newAdmin(t)
+
+	var program models.ProgramCreateResult
+	owner.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Reported Routine", "is_public": true}, http.StatusCreated, &program)
+	path := "/programs/" + program.ID.String()
+
+	owner.do(http.MethodPost, path+"/report", map[string]any{"reason": "spam"}, http.StatusBadRequest, nil)
+
+	reporters := []*client{newStudent(t), newStudent(t), newStudent(t)}
+	reporters[0].do(http.MethodPost, path+"/report", map[string]any{"reason": "rude"}, http.StatusBadRequest, nil)
+
+	var report models.ModerationReport
+	reporters[0].do(http.MethodPost, path+"/report", map[string]any{"reason": "spam", "details": "Advertising"}, http.StatusCreated, &report)
+	reporters[0].do(http.MethodPost, path+"/report", map[string]any{"reason": "spam"}, http.StatusConflict, nil)
+	reporters[1].do(http.MethodPost, path+"/report", map[string]any{"reason": "inappropriate"}, http.StatusCreated, nil)
+
+	// Below the threshold of 3 the program stays visible
+	reporters[2].do(http.MethodGet, path, nil, http.StatusOK, nil)
+	reporters[2].do(http.MethodPost, path+"/report", map[string]any{"reason": "other"}, http.StatusCreated, nil)
+
+	reporters[2].do(http.MethodGet, path, nil, http.StatusNotFound, nil)
+	reporters[2].do(http.MethodGet, path+"/exercises", nil, http.StatusNotFound, nil)
+	owner.do(http.MethodGet, path, nil, http.StatusOK, nil)
+	owner.do(http.MethodGet, path+"/exercises", nil, http.StatusOK, nil)
+
+	var moderationCase models.ModerationCase
+	admin.do(http.MethodGet, "/admin/moderation/"+report.CaseID.String(), nil, http.StatusOK, &moderationCase)
+	if moderationCase.Status != models.ModerationPending || !moderationCase.AutoHidden || !moderationCase.ContentHidden ||
+		moderationCase.ReportCount != 3 || len(moderationCase.Reports) != 3 || moderationCase.Excerpt != "E2E Reported Routine" {
+		t.Fatalf("case = %+v, want a pending, auto-hidden case with 3 reports", moderationCase)
+	}
+
+	var queue struct {
+		Cases []models.ModerationCase `json:"cases"`
+	}
+	admin.do(http.MethodGet, "/admin/moderation?limit=100", nil, http.StatusOK, &queue)
+	if !containsCase(queue.Cases, report.CaseID.String()) {
+		t.Errorf("pending queue doesn't contain case %s", report.CaseID)
+	}
+
+	// Dismissing the reports shows the program again
+	admin.do(http.MethodPost, "/admin/moderation/"+report.CaseID.String()+"/resolve", map[string]any{"note": "Not spam"}, http.StatusOK, &moderationCase)
+	if moderationCase.Status != models.ModerationResolved || moderationCase.ContentHidden {
+		t.Errorf("case = %+v, want resolved with the program shown", moderationCase)
+	}
+	admin.do(http.MethodPost, "/admin/moderation/"+report.CaseID.String()+"/hide", nil, http.StatusConflict, nil)
+	reporters[2].do(http.MethodGet, path, nil, http.StatusOK, nil)
+
+	// A new report after the decision opens a new case
+	var second models.ModerationReport
+	reporters[0].do(http.MethodPost, path+"/report", map[string]any{"reason": "spam"}, http.StatusCreated, &second)
+	if second.CaseID == report.CaseID {
+		t.Errorf("report joined the resolved case %s", report.CaseID)
+	}
+}
+
+func TestReportPrivateProgramRequiresAccess(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Private Reported Routine"}, http.StatusCreated, &program)
+	path := "/programs/" + program.ID.String() + "/report"
+
+	// A private program is not found for users it isn't assigned to, as when practicing it
+	student.do(http.MethodPost, path, map[string]any{"reason": "spam"}, http.StatusNotFound, nil)
+	student.do(http.MethodPost, "/programs/"+uuid.New().String()+"/report", map[string]any{"reason": "spam"}, http.StatusNotFound, nil)
+
+	admin.do(http.MethodPost, "/programs/"+program.ID.String()+"/assign", map[string]any{"user_ids": []string{student.user.ID.String()}}, http.StatusOK, nil)
+	student.do(http.MethodPost, path, map[string]any{"reason": "spam"}, http.StatusCreated, nil)
+}
+
+func TestModerationHidesReportedMessage(t *testing.T) {
+	student := newStudent(t)
+	admin := newAdmin(t)
+	outsider := newStudent(t)
+
+	var program models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Moderated Thread"}, http.StatusCreated, &program)
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{"title": "Feedback please"}, http.StatusCreated, &created)
+	submissionPath := "/submissions/" + created.Submission.ID.String() + "/messages"
+
+	var posted struct {
+		Message models.SubmissionMessage `json:"message"`
+	}
+	admin.do(http.MethodPost, submissionPath, map[string]any{"content": "Offensive reply"}, http.StatusCreated, &posted)
+	reportPath := "/messages/" + posted.Message.ID.String() + "/report"
+
+	// Only users with access to the thread can report its messages
+	outsider.do(http.MethodPost, reportPath, map[string]any{"reason": "harassment"}, http.StatusNotFound, nil)
+
+	var report models.ModerationReport
+	student.do(http.MethodPost, reportPath, map[string]any{"reason": "harassment"}, http.StatusCreated, &report)
+	newAdmin(t).do(http.MethodPost, "/admin/moderation/"+report.CaseID.String()+"/hide", nil, http.StatusOK, nil)
+
+	var thread struct {
+		Messages []models.MessageWithAuthor `json:"messages"`
+	}
+	student.do(http.MethodGet, submissionPath, nil, http.StatusOK, &thread)
+	if len(thread.Messages) != 0 {
+		t.Errorf("student messages = %+v, want the hidden message left out", thread.Messages)
+	}
+	admin.do(http.MethodGet, submissionPath, nil, http.StatusOK, &thread)
+	if len(thread.Messages) != 1 || thread.Messages[0].HiddenAt == nil {
+		t.Errorf("author messages = %+v, want the message marked hidden", thread.Messages)
+	}
+
+	// Hidden content can't be reported again
+	student.do(http.MethodPost, reportPath, map[string]any{"reason": "spam"}, http.StatusNotFound, nil)
+}
+
+func containsCase(cases []models.ModerationCase, id string) bool {
+	for _, c := range cases {
+		if c.ID.String() == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestContentFilterFlagsMessage(t *testing.T) {
+	student := newStudent(t)
+	admin := newAdmin(t)
+
+	var program models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Filtered Thread"}, http.StatusCreated, &program)
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{"title": "Filter check"}, http.StatusCreated, &created)
+	submissionPath := "/submissions/" + created.Submission.ID.String() + "/messages"
+
+	// Flagged content is stored and queued for review
+	var posted struct {
+		Message models.SubmissionMessage `json:"message"`
+	}
+	student.do(http.MethodPost, submissionPath, map[string]any{"content": "This is E2E Forbidden!"}, http.StatusCreated, &posted)
+
+	caseID, ok := pendingCase(t, posted.Message.ID)
+	if !ok {
+		t.Fatal("flagged message has no pending moderation case")
+	}
+	var flagged models.ModerationCase
+	admin.do(http.MethodGet, "/admin/moderation/"+caseID, nil, http.StatusOK, &flagged)
+	if flagged.ContentType != models.ModerationMessage || len(flagged.FlaggedTerms) != 1 ||
+		flagged.FlaggedTerms[0] != "e2e forbidden" || flagged.ReportCount != 0 || flagged.ContentHidden {
+		t.Fatalf("case = %+v, want a visible message flagged for e2e forbidden", flagged)
+	}
+
+	// Admins aren't filtered
+	admin.do(http.MethodPost, submissionPath, map[string]any{"content": "Quoting: e2e-forbidden"}, http.StatusCreated, &posted)
+	if _, ok := pendingCase(t, posted.Message.ID); ok {
+		t.Error("admin message was flagged")
+	}
+}
+
+// pendingCase returns the ID of the pending moderation case on the content, if there is one
+func pendingCase(t *testing.T, contentID uuid.UUID) (string, bool) {
+	t.Helper()
+
+	var id uuid.UUID
+	err := pool.QueryRow(context.Background(), "SELECT id FROM moderation_cases WHERE content_id = $1 AND status = 'pending'", contentID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false
+	}
+	if err != nil {
+		t.Fatalf("Failed to look up moderation case: %v", err)
+	}
+	return id.String(), true
+}
@@ -0,0 +1,483 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	anonymous(t).do(http.MethodGet, "/sessions", nil, http.StatusUnauthorized, nil)
+	anonymous(t).do(http.MethodGet, "/auth/me", nil, http.StatusUnauthorized, nil)
+}
+
+func TestPracticeFlow(t *testing.T) {
+	// Register, then log in with the same credentials
+	registered := newStudent(t)
+	student := login(t, registered.user.Email)
+
+	var me models.UserResponse
+	student.do(http.MethodGet, "/auth/me", nil, http.StatusOK, &me)
+	if me.ID != registered.user.ID || me.Role != models.RoleStudent {
+		t.Fatalf("GET /auth/me = %+v, want student %s", me, registered.user.ID)
+	}
+
+	// Create a program with a timed and a repetition exercise
+	var created models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Morning Routine",
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300},
+			{"name": "Arm Circles", "order_index": 1, "exercise_type": "repetition", "repetitions": 20},
+		},
+	}, http.StatusCreated, &created)
+	if created.Program == nil || created.OwnedBy == nil || *created.OwnedBy != student.user.ID {
+		t.Fatalf("created program = %+v, want one owned by the student", created.Program)
+	}
+
+	var program models.ProgramWithExercises
+	student.do(http.MethodGet, "/programs/"+created.ID.String(), nil, http.StatusOK, &program)
+	if len(program.Exercises) != 2 {
+		t.Fatalf("program has %d exercises, want 2", len(program.Exercises))
+	}
+
+	// Practice it
+	var session models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{
+		"program_id": created.ID,
+	}, http.StatusCreated, &session)
+
+	for _, ex := range program.Exercises {
+		student.do(http.MethodPut, "/sessions/"+session.ID.String()+"/exercise/"+ex.ID.String(), map[string]any{
+			"actual_duration_seconds": 300,
+		}, http.StatusOK, nil)
+	}
+
+	student.do(http.MethodPut, "/sessions/"+session.ID.String()+"/complete", map[string]any{
+		"total_duration_seconds": 600,
+		"completion_rate":        100,
+		"mood":                   4,
+	}, http.StatusOK, nil)
+
+	var completed models.PracticeSession
+	student.do(http.MethodGet, "/sessions/"+session.ID.String(), nil, http.StatusOK, &completed)
+	if completed.CompletedAt == nil {
+		t.Error("session should be completed")
+	}
+
+	// Stats reflect the completed session
+	var stats models.SessionStats
+	student.do(http.MethodGet, "/sessions/stats", nil, http.StatusOK, &stats)
+	if stats.TotalSessions != 1 || stats.CompletedSessions != 1 {
+		t.Errorf("stats sessions = %d total / %d completed, want 1 / 1", stats.TotalSessions, stats.CompletedSessions)
+	}
+	if stats.TotalDurationMinutes != 10 {
+		t.Errorf("stats total_duration_minutes = %d, want 10", stats.TotalDurationMinutes)
+	}
+	if stats.CurrentStreak != 1 {
+		t.Errorf("stats current_streak = %d, want 1", stats.CurrentStreak)
+	}
+
+	// Another student cannot see the session
+	newStudent(t).do(http.MethodGet, "/sessions/"+session.ID.String(), nil, http.StatusForbidden, nil)
+}
+
+func TestStartSessionRequiresAccess(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var private, public models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Unassigned Routine"}, http.StatusCreated, &private)
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Open Routine", "is_public": true}, http.StatusCreated, &public)
+
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": private.ID}, http.StatusForbidden, nil)
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": public.ID}, http.StatusCreated, nil)
+	admin.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": private.ID}, http.StatusCreated, nil)
+
+	admin.do(http.MethodPost, "/programs/"+private.ID.String()+"/assign", map[string]any{"user_ids": []string{student.user.ID.String()}}, http.StatusOK, nil)
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": private.ID}, http.StatusCreated, nil)
+
+	// Deleted programs cannot be practiced, assigned or not
+	admin.do(http.MethodDelete, "/programs/"+private.ID.String(), nil, http.StatusOK, nil)
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": private.ID}, http.StatusNotFound, nil)
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": uuid.New()}, http.StatusNotFound, nil)
+}
+
+func TestSessionReview(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{
+		"name":      "E2E Reviewed Routine",
+		"exercises": []map[string]any{{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300}},
+	}, http.StatusCreated, &created)
+
+	var session models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &session)
+	student.do(http.MethodPut, "/sessions/"+session.ID.String()+"/complete", map[string]any{"total_duration_seconds": 300, "completion_rate": 100}, http.StatusOK, nil)
+
+	// Admins open students' sessions from the review screen; other students can't
+	var reviewed models.SessionWithLogs
+	admin.do(http.MethodGet, "/sessions/"+session.ID.String(), nil, http.StatusOK, &reviewed)
+	if reviewed.Session.ID != session.ID || reviewed.Session.CompletedAt == nil {
+		t.Fatalf("session = %+v, want the completed session", reviewed.Session)
+	}
+	newStudent(t).do(http.MethodGet, "/sessions/"+session.ID.String(), nil, http.StatusForbidden, nil)
+
+	// The end date covers the whole day, not just its first moment
+	day := reviewed.Session.StartedAt.UTC().Format(time.DateOnly)
+	var list struct {
+		Sessions []models.SessionWithLogs `json:"sessions"`
+	}
+	sessionsPath := "/users/" + student.user.ID.String() + "/sessions"
+	admin.do(http.MethodGet, sessionsPath+"?start_date="+day+"&end_date="+day, nil, http.StatusOK, &list)
+	if len(list.Sessions) != 1 || list.Sessions[0].Session.ID != session.ID {
+		t.Fatalf("sessions on %s = %+v, want the session", day, list.Sessions)
+	}
+	admin.do(http.MethodGet, sessionsPath+"?end_date=2000-01-01", nil, http.StatusOK, &list)
+	if len(list.Sessions) != 0 {
+		t.Fatalf("sessions before 2000 = %+v, want none", list.Sessions)
+	}
+	student.do(http.MethodGet, sessionsPath, nil, http.StatusForbidden, nil)
+}
+
+func TestExerciseTempoTimeline(t *testing.T) {
+	admin := newAdmin(t)
+
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Untimed Tempo",
+		"exercises": []map[string]any{
+			{"name": "Standing", "order_index": 0, "exercise_type": "timed", "duration_seconds": 60, "tempo": map[string]any{"prep_seconds": 5}},
+		},
+	}, http.StatusBadRequest, nil)
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Too Slow",
+		"exercises": []map[string]any{
+			{"name": "Cloud Hands", "order_index": 0, "exercise_type": "repetition", "repetitions": 5, "tempo": map[string]any{"seconds_per_rep": 601}},
+		},
+	}, http.StatusBadRequest, nil)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Paced Cloud Hands",
+		"exercises": []map[string]any{
+			{"name": "Cloud Hands", "order_index": 0, "exercise_type": "repetition", "repetitions": 10,
+				"tempo": map[string]any{"seconds_per_rep": 6, "prep_seconds": 10, "transition_seconds": 1}},
+		},
+	}, http.StatusCreated, &program)
+
+	var tl models.Timeline
+	admin.do(http.MethodGet, "/programs/"+program.ID.String()+"/timeline", nil, http.StatusOK, &tl)
+	// 10s prep, then 10 reps of 6s with 9 transitions of 1s
+	if tl.TotalDurationSeconds != 79 || len(tl.Cues) < 2 {
+		t.Fatalf("timeline = %+v, want 79 seconds", tl)
+	}
+	if prep, start := tl.Cues[0], tl.Cues[1]; prep.Type != models.CuePrepStart || start.AtSeconds != 10 || start.SecondsPerRep != 6 || start.TransitionSeconds != 1 {
+		t.Errorf("cues = %+v, want prep followed by the paced exercise", tl.Cues)
+	}
+}
+
+func TestBreathingPatternTimeline(t *testing.T) {
+	admin := newAdmin(t)
+
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Breathless",
+		"exercises": []map[string]any{
+			{"name": "Embracing the Tree", "order_index": 0, "exercise_type": "repetition", "repetitions": 9,
+				"breathing_pattern": map[string]any{"inhale_seconds": 4, "cycles": 9}},
+		},
+	}, http.StatusBadRequest, nil)
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Double Paced",
+		"exercises": []map[string]any{
+			{"name": "Embracing the Tree", "order_index": 0, "exercise_type": "repetition", "repetitions": 9,
+				"tempo":             map[string]any{"seconds_per_rep": 6},
+				"breathing_pattern": map[string]any{"inhale_seconds": 4, "exhale_seconds": 6}},
+		},
+	}, http.StatusBadRequest, nil)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Breath Counts",
+		"exercises": []map[string]any{
+			{"name": "Embracing the Tree", "order_index": 0, "exercise_type": "repetition", "repetitions": 9,
+				"breathing_pattern": map[string]any{"inhale_seconds": 4, "hold_seconds": 2, "exhale_seconds": 6, "cycles": 9}},
+		},
+	}, http.StatusCreated, &program)
+
+	var tl models.Timeline
+	admin.do(http.MethodGet, "/programs/"+program.ID.String()+"/timeline", nil, http.StatusOK, &tl)
+	if tl.TotalDurationSeconds != 108 || tl.Cues[0].BreathingPattern == nil || tl.Cues[0].BreathingPattern.HoldSeconds != 2 {
+		t.Errorf("timeline = %+v, want 9 breaths of 12 seconds", tl)
+	}
+}
+
+func TestExerciseSubstitutes(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Knee Friendly",
+		"exercises": []map[string]any{
+			{"name": "Horse Stance", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300},
+		},
+	}, http.StatusCreated, &created)
+	programPath := "/programs/" + created.ID.String()
+
+	var program models.ProgramWithExercises
+	admin.do(http.MethodGet, programPath, nil, http.StatusOK, &program)
+	exerciseID := program.Exercises[0].ID.String()
+
+	substitute := map[string]any{"name": "Seated Meditation", "duration_seconds": 90, "reason": "Knee injuries"}
+	student.do(http.MethodPost, "/exercises/"+exerciseID+"/substitutes", substitute, http.StatusForbidden, nil)
+	var seated models.ExerciseSubstitute
+	admin.do(http.MethodPost, "/exercises/"+exerciseID+"/substitutes", substitute, http.StatusCreated, &seated)
+
+	admin.do(http.MethodPost, programPath+"/assign", map[string]any{"user_ids": []string{student.user.ID.String()}}, http.StatusOK, nil)
+
+	student.do(http.MethodGet, programPath, nil, http.StatusOK, &program)
+	if len(program.Exercises[0].Substitutes) != 1 || program.Exercises[0].Substitutes[0].ID != seated.ID {
+		t.Fatalf("exercise substitutes = %+v, want the seated variant", program.Exercises[0].Substitutes)
+	}
+
+	// Students choose a substitute of the exercise in their program settings
+	choose := func(substituteID string) map[string]any {
+		return map[string]any{"custom_settings": map[string]any{
+			"exercise_overrides": map[string]any{exerciseID: map[string]any{"substitute_id": substituteID}},
+		}}
+	}
+	student.do(http.MethodPut, programPath+"/settings", choose(uuid.NewString()), http.StatusBadRequest, nil)
+	newStudent(t).do(http.MethodPut, programPath+"/settings", choose(seated.ID.String()), http.StatusNotFound, nil)
+	student.do(http.MethodPut, programPath+"/settings", choose(seated.ID.String()), http.StatusOK, nil)
+
+	var tl models.Timeline
+	student.do(http.MethodGet, programPath+"/timeline", nil, http.StatusOK, &tl)
+	if tl.TotalDurationSeconds != 90 || tl.Cues[0].ExerciseName != "Seated Meditation" ||
+		tl.Cues[0].SubstituteID == nil || *tl.Cues[0].SubstituteID != seated.ID {
+		t.Errorf("timeline = %+v, want 90 seconds of the seated variant", tl)
+	}
+
+	// Logs record the variant that was done
+	var session models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &session)
+	logPath := "/sessions/" + session.ID.String() + "/exercise/" + exerciseID
+	student.do(http.MethodPut, logPath, map[string]any{"actual_duration_seconds": 90, "substitute_id": uuid.NewString()}, http.StatusBadRequest, nil)
+	student.do(http.MethodPut, logPath, map[string]any{"actual_duration_seconds": 90, "substitute_id": seated.ID}, http.StatusOK, nil)
+
+	row := reportRow(t, download(t, admin, "/admin/reports/exercise_substitutions", http.StatusOK), seated.ID.String())
+	if row["students_choosing"] != "1" || row["times_performed"] != "1" || row["students_performing"] != "1" {
+		t.Errorf("report row = %v, want one student choosing and performing it once", row)
+	}
+}
+
+func TestProgramProgress(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name":                "E2E Progress Routine",
+		"repetitions_planned": 10,
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300},
+			{"name": "Arm Circles", "order_index": 1, "exercise_type": "repetition", "repetitions": 20},
+		},
+	}, http.StatusCreated, &created)
+	progressPath := "/my-programs/" + created.ID.String() + "/progress"
+	student.do(http.MethodGet, progressPath, nil, http.StatusNotFound, nil)
+	admin.do(http.MethodPost, "/programs/"+created.ID.String()+"/assign", map[string]any{"user_ids": []string{student.user.ID.String()}}, http.StatusOK, nil)
+
+	var program models.ProgramWithExercises
+	student.do(http.MethodGet, "/programs/"+created.ID.String(), nil, http.StatusOK, &program)
+	var session models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &session)
+	sessionPath := "/sessions/" + session.ID.String()
+	student.do(http.MethodPut, sessionPath+"/exercise/"+program.Exercises[0].ID.String(), map[string]any{"actual_duration_seconds": 300}, http.StatusOK, nil)
+	student.do(http.MethodPut, sessionPath+"/exercise/"+program.Exercises[1].ID.String(), map[string]any{"skipped": true}, http.StatusOK, nil)
+	student.do(http.MethodPut, sessionPath+"/complete", map[string]any{"total_duration_seconds": 300, "completion_rate": 50}, http.StatusOK, nil)
+
+	var progress models.RepetitionProgress
+	student.do(http.MethodGet, progressPath, nil, http.StatusOK, &progress)
+	if progress.RepetitionsCompleted != 1 || progress.PercentComplete == nil || *progress.PercentComplete != 10 {
+		t.Errorf("progress = %+v, want 1 of 10 repetitions", progress)
+	}
+	if progress.SessionsPerWeek != 0.25 || progress.ProjectedCompletionDate == nil || progress.LastPracticedAt == nil {
+		t.Errorf("pace = %v sessions/week, projected %v, want 0.25 and a projection", progress.SessionsPerWeek, progress.ProjectedCompletionDate)
+	}
+	if len(progress.Exercises) != 2 {
+		t.Fatalf("exercises = %+v, want both", progress.Exercises)
+	}
+	if e := progress.Exercises[0]; e.TimesCompleted != 1 || e.CompletionRate != 100 || e.LastCompletedAt == nil {
+		t.Errorf("first exercise = %+v, want it done in the session", e)
+	}
+	if e := progress.Exercises[1]; e.TimesCompleted != 0 || e.TimesSkipped != 1 || e.CompletionRate != 0 {
+		t.Errorf("second exercise = %+v, want it skipped", e)
+	}
+
+	newStudent(t).do(http.MethodGet, progressPath, nil, http.StatusNotFound, nil)
+}
+
+func TestProgramAdoption(t *testing.T) {
+	author := newStudent(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	author.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Adopted Routine",
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300},
+			{"name": "Arm Circles", "order_index": 1, "exercise_type": "repetition", "repetitions": 20},
+		},
+	}, http.StatusCreated, &created)
+	var program models.ProgramWithExercises
+	author.do(http.MethodGet, "/programs/"+created.ID.String(), nil, http.StatusOK, &program)
+
+	// One session completed halfway, one abandoned after the first exercise
+	for _, rate := range []int{50, 0} {
+		var session models.PracticeSession
+		author.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &session)
+		sessionPath := "/sessions/" + session.ID.String()
+		author.do(http.MethodPut, sessionPath+"/exercise/"+program.Exercises[0].ID.String(), map[string]any{"actual_duration_seconds": 300}, http.StatusOK, nil)
+		if rate > 0 {
+			author.do(http.MethodPut, sessionPath+"/exercise/"+program.Exercises[1].ID.String(), map[string]any{"skipped": true}, http.StatusOK, nil)
+			author.do(http.MethodPut, sessionPath+"/complete", map[string]any{"total_duration_seconds": 300, "completion_rate": rate}, http.StatusOK, nil)
+		}
+	}
+
+	adoptionPath := "/programs/" + created.ID.String() + "/adoption"
+	student.do(http.MethodGet, adoptionPath, nil, http.StatusForbidden, nil)
+	var adoption models.ProgramAdoption
+	author.do(http.MethodGet, adoptionPath, nil, http.StatusOK, &adoption)
+	if adoption.PracticingUsers != 1 || adoption.SessionsStarted != 2 || adoption.SessionsCompleted != 1 {
+		t.Errorf("adoption = %+v, want 2 sessions of one user, 1 completed", adoption)
+	}
+	if adoption.AverageCompletionRate == nil || *adoption.AverageCompletionRate != 50 {
+		t.Errorf("average_completion_rate = %v, want 50", adoption.AverageCompletionRate)
+	}
+	if len(adoption.Exercises) != 2 {
+		t.Fatalf("exercises = %+v, want both", adoption.Exercises)
+	}
+	if e := adoption.Exercises[0]; e.TimesReached != 2 || e.DropOffRate != 0 {
+		t.Errorf("first exercise = %+v, want both sessions reaching it", e)
+	}
+	if e := adoption.Exercises[1]; e.TimesReached != 1 || e.TimesSkipped != 1 || e.DropOffRate != 50 {
+		t.Errorf("second exercise = %+v, want half the sessions stopping before it", e)
+	}
+
+	newAdmin(t).do(http.MethodGet, adoptionPath, nil, http.StatusOK, nil)
+}
+
+func TestRepetitionReconciliation(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Reconciled Routine",
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300},
+		},
+	}, http.StatusCreated, &created)
+	var session models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &session)
+	student.do(http.MethodPut, "/sessions/"+session.ID.String()+"/complete", map[string]any{"total_duration_seconds": 300, "completion_rate": 100}, http.StatusOK, nil)
+
+	// A count update that was lost
+	if _, err := pool.Exec(context.Background(), "UPDATE programs SET repetitions_completed = 42 WHERE id = $1", created.ID); err != nil {
+		t.Fatalf("drift count: %v", err)
+	}
+
+	student.do(http.MethodPost, "/admin/repetition-reconciliations", nil, http.StatusForbidden, nil)
+	var report models.RepetitionReconciliation
+	admin.do(http.MethodPost, "/admin/repetition-reconciliations", nil, http.StatusOK, &report)
+	var drift *models.RepetitionDrift
+	for i := range report.Drifted {
+		if report.Drifted[i].ProgramID == created.ID {
+			drift = &report.Drifted[i]
+		}
+	}
+	if drift == nil || drift.Stored == nil || *drift.Stored != 42 || drift.Actual != 1 || report.ProgramsChecked == 0 {
+		t.Fatalf("report = %+v, want the program corrected from 42 to 1", report)
+	}
+
+	var program models.ProgramWithExercises
+	student.do(http.MethodGet, "/programs/"+created.ID.String(), nil, http.StatusOK, &program)
+	if program.Program.RepetitionsCompleted == nil || *program.Program.RepetitionsCompleted != 1 {
+		t.Errorf("repetitions_completed = %v, want 1", program.Program.RepetitionsCompleted)
+	}
+
+	var list struct {
+		Reconciliations []models.RepetitionReconciliation `json:"reconciliations"`
+	}
+	admin.do(http.MethodGet, "/admin/repetition-reconciliations", nil, http.StatusOK, &list)
+	if len(list.Reconciliations) == 0 || list.Reconciliations[0].ID != report.ID {
+		t.Errorf("reports = %+v, want the latest first", list.Reconciliations)
+	}
+}
+
+func TestAdminCorrections(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Corrections Routine",
+		"exercises": []map[string]any{
+			{"name": "Arm Circles", "order_index": 0, "exercise_type": "repetition", "repetitions": 20},
+		},
+	}, http.StatusCreated, &created)
+	admin.do(http.MethodPost, "/programs/"+created.ID.String()+"/assign", map[string]any{"user_ids": []string{student.user.ID.String()}}, http.StatusOK, nil)
+
+	var program models.ProgramWithExercises
+	student.do(http.MethodGet, "/programs/"+created.ID.String(), nil, http.StatusOK, &program)
+	var session models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &session)
+	sessionPath := "/sessions/" + session.ID.String()
+	student.do(http.MethodPut, sessionPath+"/exercise/"+program.Exercises[0].ID.String(), map[string]any{"repetitions_completed": 2000}, http.StatusOK, nil)
+	student.do(http.MethodPut, sessionPath+"/complete", map[string]any{"total_duration_seconds": 36000}, http.StatusOK, nil)
+
+	// Only admins correct others' data, through the admin route, and they have to say why
+	fix := map[string]any{"total_duration_seconds": 0, "reason": "The app kept running overnight"}
+	student.do(http.MethodPut, "/admin"+sessionPath, fix, http.StatusForbidden, nil)
+	admin.do(http.MethodPut, sessionPath, map[string]any{"total_duration_seconds": 0}, http.StatusForbidden, nil)
+	admin.do(http.MethodPut, "/admin"+sessionPath, map[string]any{"total_duration_seconds": 0}, http.StatusBadRequest, nil)
+	var corrected models.PracticeSession
+	admin.do(http.MethodPut, "/admin"+sessionPath, fix, http.StatusOK, &corrected)
+	if corrected.EditedAt == nil || corrected.EditedBy == nil || *corrected.EditedBy != admin.user.ID {
+		t.Errorf("session = %+v, want it flagged as edited by the admin", corrected)
+	}
+
+	var details models.SessionWithLogs
+	student.do(http.MethodGet, sessionPath, nil, http.StatusOK, &details)
+	logPath := "/admin/exercise-logs/" + details.ExerciseLogs[0].ID.String()
+	admin.do(http.MethodPut, "/admin/exercise-logs/"+uuid.NewString(), map[string]any{"repetitions_completed": 20, "reason": "Typo"}, http.StatusNotFound, nil)
+	var log models.ExerciseLog
+	admin.do(http.MethodPut, logPath, map[string]any{"repetitions_completed": 20, "reason": "Typo"}, http.StatusOK, &log)
+	if log.RepetitionsCompleted == nil || *log.RepetitionsCompleted != 20 || log.EditedAt == nil {
+		t.Errorf("log = %+v, want 20 repetitions flagged as edited", log)
+	}
+
+	var history struct {
+		Edits []models.SessionEdit `json:"edits"`
+	}
+	newStudent(t).do(http.MethodGet, sessionPath+"/edits", nil, http.StatusForbidden, nil)
+	student.do(http.MethodGet, sessionPath+"/edits", nil, http.StatusOK, &history)
+	if len(history.Edits) != 2 {
+		t.Fatalf("edits = %+v, want the session and the log corrections", history.Edits)
+	}
+	sessionEdit, logEdit := history.Edits[0], history.Edits[1]
+	if sessionEdit.ExerciseLogID != nil || sessionEdit.Reason == nil || sessionEdit.Changes["total_duration_seconds"].From != float64(36000) {
+		t.Errorf("session edit = %+v, want the original 36000 seconds and the reason", sessionEdit)
+	}
+	if logEdit.ExerciseLogID == nil || *logEdit.ExerciseLogID != log.ID || logEdit.Changes["repetitions_completed"].From != float64(2000) {
+		t.Errorf("log edit = %+v, want the original 2000 repetitions", logEdit)
+	}
+}
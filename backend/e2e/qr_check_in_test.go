@@ -0,0 +1,72 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestQRCheckIn(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E QR Forms"}, http.StatusCreated, &program)
+
+	start := time.Now().Add(-time.Minute).UTC()
+	var class models.LiveClass
+	admin.do(http.MethodPost, "/classes", map[string]any{
+		"title":     "Park session",
+		"starts_at": start.Format(time.RFC3339),
+		"ends_at":   start.Add(time.Hour).Format(time.RFC3339),
+	}, http.StatusCreated, &class)
+
+	var classQR models.QRCode
+	student.do(http.MethodGet, "/classes/"+class.ID.String()+"/qr", nil, http.StatusForbidden, nil)
+	admin.do(http.MethodGet, "/classes/"+class.ID.String()+"/qr", nil, http.StatusOK, &classQR)
+	if classQR.Token == "" || !strings.Contains(classQR.URL, "token=") || !classQR.ExpiresAt.After(time.Now()) {
+		t.Fatalf("class QR = %+v, want a token, check-in URL and expiry", classQR)
+	}
+
+	student.do(http.MethodPost, "/check-in/qr", map[string]any{"token": "not-a-token"}, http.StatusBadRequest, nil)
+	var attended models.QRCheckInResult
+	student.do(http.MethodPost, "/check-in/qr", map[string]any{"token": classQR.Token}, http.StatusCreated, &attended)
+	if attended.Kind != models.QRCheckInAttendance || attended.Class == nil ||
+		attended.Class.MyAttendance == nil || *attended.Class.MyAttendance != models.AttendancePresent {
+		t.Fatalf("class check-in = %+v, want present attendance", attended)
+	}
+	// Replaying the same token is rejected
+	student.do(http.MethodPost, "/check-in/qr", map[string]any{"token": classQR.Token}, http.StatusConflict, nil)
+
+	var method string
+	var userAgent *string
+	err := pool.QueryRow(context.Background(), `
+		SELECT a.method, q.user_agent
+		FROM class_attendance a
+		JOIN qr_check_ins q ON q.class_id = a.class_id AND q.user_id = a.user_id
+		WHERE a.class_id = $1 AND a.user_id = $2
+	`, class.ID, student.user.ID).Scan(&method, &userAgent)
+	if err != nil {
+		t.Fatalf("Failed to fetch QR check-in: %v", err)
+	}
+	if method != string(models.AttendanceQR) || userAgent == nil {
+		t.Errorf("QR check-in method = %s, user agent = %v, want qr with the scanning device", method, userAgent)
+	}
+
+	var programQR models.QRCode
+	admin.do(http.MethodGet, "/programs/"+program.ID.String()+"/qr", nil, http.StatusOK, &programQR)
+	var started models.QRCheckInResult
+	student.do(http.MethodPost, "/check-in/qr", map[string]any{"token": programQR.Token}, http.StatusCreated, &started)
+	if started.Kind != models.QRCheckInSession || started.Session == nil || started.Session.ProgramID != program.ID {
+		t.Fatalf("program check-in = %+v, want a started session of the program", started)
+	}
+	if started.Session.DeviceInfo["check_in"] != "qr" {
+		t.Errorf("session device info = %v, want the QR check-in recorded", started.Session.DeviceInfo)
+	}
+}
@@ -0,0 +1,91 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestSessionQuestionnaire(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Questionnaire Routine",
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300},
+		},
+	}, http.StatusCreated, &created)
+	admin.do(http.MethodPost, "/programs/"+created.ID.String()+"/assign", map[string]any{"user_ids": []string{student.user.ID.String()}}, http.StatusOK, nil)
+	questionnairePath := "/programs/" + created.ID.String() + "/questionnaire"
+
+	student.do(http.MethodGet, questionnairePath, nil, http.StatusNotFound, nil)
+	admin.do(http.MethodPut, questionnairePath, map[string]any{"questions": []map[string]any{
+		{"id": "focus", "type": "scale", "prompt": "How focused were you?"},
+		{"id": "focus", "type": "text", "prompt": "Anything else?"},
+	}}, http.StatusBadRequest, nil)
+	student.do(http.MethodPut, questionnairePath, map[string]any{"questions": []map[string]any{
+		{"id": "focus", "type": "scale", "prompt": "How focused were you?"},
+	}}, http.StatusForbidden, nil)
+	admin.do(http.MethodPut, questionnairePath, map[string]any{"questions": []map[string]any{
+		{"id": "focus", "type": "scale", "prompt": "How focused were you?", "min_label": "Scattered", "max_label": "Completely"},
+		{"id": "felt", "type": "text", "prompt": "What did you notice?"},
+	}}, http.StatusOK, nil)
+
+	var questionnaire models.SessionQuestionnaire
+	student.do(http.MethodGet, questionnairePath, nil, http.StatusOK, &questionnaire)
+	if len(questionnaire.Questions) != 2 || questionnaire.Questions[0].Min != 1 || questionnaire.Questions[0].Max != 5 {
+		t.Fatalf("questionnaire = %+v, want two questions with a 1 to 5 scale", questionnaire)
+	}
+
+	// Answers are checked against the questionnaire before the session is completed
+	var first, second models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &first)
+	completePath := "/sessions/" + first.ID.String() + "/complete"
+	student.do(http.MethodPut, completePath, map[string]any{"answers": []map[string]any{{"question_id": "focus", "scale": 6}}}, http.StatusBadRequest, nil)
+	student.do(http.MethodPut, completePath, map[string]any{"answers": []map[string]any{{"question_id": "felt", "scale": 3}}}, http.StatusBadRequest, nil)
+	student.do(http.MethodPut, completePath, map[string]any{"answers": []map[string]any{{"question_id": "mood", "scale": 3}}}, http.StatusBadRequest, nil)
+	student.do(http.MethodPut, completePath, map[string]any{
+		"total_duration_seconds": 300,
+		"answers": []map[string]any{
+			{"question_id": "focus", "scale": 4},
+			{"question_id": "felt", "text": "Warm hands"},
+		},
+	}, http.StatusOK, nil)
+
+	// Or afterwards, replacing earlier answers
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &second)
+	answersPath := "/sessions/" + second.ID.String() + "/answers"
+	student.do(http.MethodPut, answersPath, map[string]any{"answers": []map[string]any{{"question_id": "focus", "scale": 2}}}, http.StatusBadRequest, nil)
+	student.do(http.MethodPut, "/sessions/"+second.ID.String()+"/complete", map[string]any{"notes": "From an older app"}, http.StatusOK, nil)
+	student.do(http.MethodPut, answersPath, map[string]any{"answers": []map[string]any{{"question_id": "focus", "scale": 3}}}, http.StatusOK, nil)
+	student.do(http.MethodPut, answersPath, map[string]any{"answers": []map[string]any{{"question_id": "focus", "scale": 2}}}, http.StatusOK, nil)
+	newStudent(t).do(http.MethodPut, answersPath, map[string]any{"answers": []map[string]any{{"question_id": "focus", "scale": 2}}}, http.StatusForbidden, nil)
+
+	var session models.SessionWithLogs
+	student.do(http.MethodGet, "/sessions/"+first.ID.String(), nil, http.StatusOK, &session)
+	if len(session.Answers) != 2 {
+		t.Errorf("answers = %+v, want focus and felt", session.Answers)
+	}
+
+	student.do(http.MethodGet, questionnairePath+"/results", nil, http.StatusForbidden, nil)
+	var results models.QuestionnaireResults
+	admin.do(http.MethodGet, questionnairePath+"/results", nil, http.StatusOK, &results)
+	if results.SessionsAnswered != 2 || len(results.Questions) != 2 {
+		t.Fatalf("results = %+v, want two answered sessions and two questions", results)
+	}
+	focus, felt := results.Questions[0], results.Questions[1]
+	if focus.Responses != 2 || focus.Average == nil || *focus.Average != 3 || len(focus.Distribution) != 2 {
+		t.Errorf("focus = %+v, want 2 and 4 averaging 3", focus)
+	}
+	if felt.Responses != 1 || len(felt.Latest) != 1 || felt.Latest[0].Text != "Warm hands" {
+		t.Errorf("felt = %+v, want the one text answer", felt)
+	}
+
+	admin.do(http.MethodDelete, questionnairePath, nil, http.StatusOK, nil)
+	admin.do(http.MethodDelete, questionnairePath, nil, http.StatusNotFound, nil)
+}
@@ -0,0 +1,112 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestQuizAttemptsAndCourseProgress(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Quiz Principles"}, http.StatusCreated, &program)
+	programPath := "/programs/" + program.ID.String()
+
+	admin.do(http.MethodPost, programPath+"/quizzes", map[string]any{
+		"title":     "No correct answer",
+		"questions": []map[string]any{{"prompt": "?", "choices": []map[string]any{{"text": "a"}, {"text": "b"}}}},
+	}, http.StatusBadRequest, nil)
+
+	var quiz models.Quiz
+	admin.do(http.MethodPost, programPath+"/quizzes", map[string]any{
+		"title":          "Principles",
+		"pass_threshold": 100,
+		"max_attempts":   2,
+		"questions": []map[string]any{
+			{"prompt": "Where does the breath settle?", "explanation": "The lower dantian.", "choices": []map[string]any{
+				{"text": "Lower dantian", "is_correct": true},
+				{"text": "Chest"},
+			}},
+			{"prompt": "Which are the three treasures?", "choices": []map[string]any{
+				{"text": "Jing", "is_correct": true},
+				{"text": "Qi", "is_correct": true},
+				{"text": "Shen", "is_correct": true},
+				{"text": "Li"},
+			}},
+		},
+	}, http.StatusCreated, &quiz)
+
+	var course models.Course
+	admin.do(http.MethodPost, "/courses", map[string]any{
+		"name":    "E2E Theory",
+		"modules": []map[string]any{{"title": "Principles", "programs": []map[string]any{{"program_id": program.ID}}}},
+	}, http.StatusCreated, &course)
+	admin.do(http.MethodPost, "/courses/"+course.ID.String()+"/enrollments", map[string]any{
+		"user_ids": []string{student.user.ID.String()},
+	}, http.StatusOK, nil)
+
+	// Students see the questions but not the answers
+	var studentView models.Quiz
+	student.do(http.MethodGet, "/quizzes/"+quiz.ID.String(), nil, http.StatusOK, &studentView)
+	if len(studentView.Questions) != 2 || !studentView.Questions[1].MultipleAnswers {
+		t.Fatalf("questions = %+v, want 2 with the second allowing multiple answers", studentView.Questions)
+	}
+	for _, question := range studentView.Questions {
+		if question.Explanation != nil || question.Choices[0].IsCorrect != nil {
+			t.Errorf("question %+v reveals the answer", question)
+		}
+	}
+
+	breath, treasures := quiz.Questions[0], quiz.Questions[1]
+	var attempt models.QuizAttempt
+	student.do(http.MethodPost, "/quizzes/"+quiz.ID.String()+"/attempts", map[string]any{
+		"answers": []map[string]any{
+			{"question_id": breath.ID, "choice_ids": []string{breath.Choices[0].ID.String()}},
+			{"question_id": treasures.ID, "choice_ids": []string{treasures.Choices[0].ID.String(), treasures.Choices[1].ID.String()}},
+		},
+	}, http.StatusCreated, &attempt)
+	if attempt.Score != 50 || attempt.Passed || attempt.Results[1].Correct || attempt.Results[0].CorrectChoiceIDs != nil {
+		t.Errorf("attempt = %+v, want a failed 50%% attempt without revealed answers", attempt)
+	}
+
+	// Sessions alone do not complete the program while its quiz is not passed
+	completeSession(student, program.ID.String())
+	var progress models.CourseProgress
+	student.do(http.MethodGet, "/courses/"+course.ID.String()+"/progress", nil, http.StatusOK, &progress)
+	if progress.Completed || progress.Percent != 50 {
+		t.Errorf("progress = %+v, want 50%% and not completed", progress)
+	}
+
+	student.do(http.MethodPost, "/quizzes/"+quiz.ID.String()+"/attempts", map[string]any{
+		"answers": []map[string]any{
+			{"question_id": breath.ID, "choice_ids": []string{breath.Choices[0].ID.String()}},
+			{"question_id": treasures.ID, "choice_ids": []string{
+				treasures.Choices[0].ID.String(), treasures.Choices[1].ID.String(), treasures.Choices[2].ID.String(),
+			}},
+		},
+	}, http.StatusCreated, &attempt)
+	if attempt.Score != 100 || !attempt.Passed || len(attempt.Results[1].CorrectChoiceIDs) != 3 {
+		t.Errorf("attempt = %+v, want a passed attempt with revealed answers", attempt)
+	}
+	student.do(http.MethodPost, "/quizzes/"+quiz.ID.String()+"/attempts", map[string]any{
+		"answers": []map[string]any{},
+	}, http.StatusConflict, nil)
+
+	var history struct {
+		Attempts []models.QuizAttempt `json:"attempts"`
+	}
+	admin.do(http.MethodGet, "/quizzes/"+quiz.ID.String()+"/attempts?user_id="+student.user.ID.String(), nil, http.StatusOK, &history)
+	if len(history.Attempts) != 2 || !history.Attempts[0].Passed {
+		t.Errorf("attempts = %+v, want 2 with the newest passed", history.Attempts)
+	}
+
+	student.do(http.MethodGet, "/courses/"+course.ID.String()+"/progress", nil, http.StatusOK, &progress)
+	if !progress.Completed || progress.Percent != 100 {
+		t.Errorf("progress = %+v, want the course completed", progress)
+	}
+}
@@ -0,0 +1,80 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestQuotas(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var quota models.UserQuota
+	student.do(http.MethodGet, "/auth/me/quota", nil, http.StatusOK, &quota)
+	if quota.Plan != models.DefaultQuotaPlan || quota.Exempt {
+		t.Errorf("quota = %+v, want the default plan", quota)
+	}
+
+	// The default plan is shared with other tests, so use a plan of our own
+	plan := "e2e-" + uuid.New().String()[:8]
+	admin.do(http.MethodPut, "/admin/quota-plans/"+plan, map[string]any{
+		"max_programs":         1,
+		"max_open_submissions": 1,
+	}, http.StatusOK, nil)
+	admin.do(http.MethodPut, "/users/"+student.user.ID.String()+"/quota", map[string]any{"plan": "missing-plan"}, http.StatusNotFound, nil)
+	admin.do(http.MethodPut, "/users/"+student.user.ID.String()+"/quota", map[string]any{"plan": plan}, http.StatusOK, &quota)
+	if quota.Plan != plan || quota.Limits.MaxPrograms == nil || *quota.Limits.MaxPrograms != 1 || quota.Limits.MaxStorageBytes != nil {
+		t.Fatalf("quota = %+v, want 1 program and unlimited storage", quota)
+	}
+
+	var program models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Quota Routine"}, http.StatusCreated, &program)
+
+	var failure struct {
+		Error struct {
+			Code    string         `json:"code"`
+			Details map[string]any `json:"details"`
+		} `json:"error"`
+	}
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Over Quota"}, http.StatusUnprocessableEntity, &failure)
+	if failure.Error.Code != "QUOTA_EXCEEDED" || failure.Error.Details["quota"] != "programs" || failure.Error.Details["limit"] != float64(1) {
+		t.Errorf("error = %+v, want the programs quota of 1", failure.Error)
+	}
+
+	// A per-user override beats the plan
+	admin.do(http.MethodPut, "/users/"+student.user.ID.String()+"/quota", map[string]any{"plan": plan, "max_programs": 2}, http.StatusOK, nil)
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Second Routine"}, http.StatusCreated, nil)
+
+	// A submission stops counting as open once an instructor replied last
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{"title": "First question"}, http.StatusCreated, &created)
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{"title": "Second question"}, http.StatusUnprocessableEntity, nil)
+	admin.do(http.MethodPost, "/submissions/"+created.Submission.ID.String()+"/messages", map[string]any{"content": "Answered"}, http.StatusCreated, nil)
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{"title": "Second question"}, http.StatusCreated, nil)
+
+	student.do(http.MethodGet, "/auth/me/quota", nil, http.StatusOK, &quota)
+	if quota.Usage.Programs != 2 || quota.Usage.OpenSubmissions != 1 {
+		t.Errorf("usage = %+v, want 2 programs and 1 open submission", quota.Usage)
+	}
+
+	// Admins are exempt
+	admin.do(http.MethodGet, "/users/"+admin.user.ID.String()+"/quota", nil, http.StatusOK, &quota)
+	if !quota.Exempt {
+		t.Errorf("admin quota = %+v, want exempt", quota)
+	}
+
+	// Deleting the plan moves its users to the default plan
+	admin.do(http.MethodDelete, "/admin/quota-plans/"+models.DefaultQuotaPlan, nil, http.StatusBadRequest, nil)
+	admin.do(http.MethodDelete, "/admin/quota-plans/"+plan, nil, http.StatusNoContent, nil)
+	student.do(http.MethodGet, "/auth/me/quota", nil, http.StatusOK, &quota)
+	if quota.Plan != models.DefaultQuotaPlan {
+		t.Errorf("plan = %q, want the default plan", quota.Plan)
+	}
+}
@@ -0,0 +1,125 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestAdminReports(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Reported Routine"}, http.StatusCreated, &program)
+	admin.do(http.MethodPost, "/programs/"+program.ID.String()+"/assign", map[string]any{
+		"user_ids": []string{student.user.ID.String()},
+	}, http.StatusOK, nil)
+	completeSession(student, program.ID.String())
+	completeSession(student, program.ID.String())
+
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{
+		"title": "Report me",
+	}, http.StatusCreated, &created)
+	submissionID := created.Submission.ID.String()
+	admin.do(http.MethodPost, "/submissions/"+submissionID+"/messages", map[string]any{
+		"content": "Looks good",
+	}, http.StatusCreated, nil)
+
+	// Other tests share the database, so only look at this test's rows
+	activity := reportRow(t, download(t, admin, "/admin/reports/user_activity", http.StatusOK), student.user.ID.String())
+	if activity["sessions"] != "2" || activity["practice_minutes"] != "20" || activity["active_days"] != "1" || activity["submissions"] != "1" {
+		t.Errorf("user activity = %v, want 2 sessions of 20 minutes on 1 day and 1 submission", activity)
+	}
+
+	adoption := reportRow(t, download(t, admin, "/admin/reports/program_adoption", http.StatusOK), program.ID.String())
+	if adoption["assigned_students"] != "1" || adoption["practicing_students"] != "1" || adoption["sessions"] != "2" || adoption["avg_completion_rate"] != "100" {
+		t.Errorf("program adoption = %v, want 1 student practicing twice at 100%%", adoption)
+	}
+
+	turnaround := reportRow(t, download(t, admin, "/admin/reports/submission_turnaround", http.StatusOK), submissionID)
+	if turnaround["title"] != "Report me" || turnaround["first_reply_at"] == "" || turnaround["hours_to_first_reply"] == "" {
+		t.Errorf("submission turnaround = %v, want the instructor's reply", turnaround)
+	}
+
+	// A range in the past leaves the new submission out
+	old := download(t, admin, "/admin/reports/submission_turnaround?from=2020-01-01T00:00:00Z&to=2020-02-01T00:00:00Z", http.StatusOK)
+	if strings.Contains(string(old), submissionID) {
+		t.Errorf("report for 2020 contains a new submission:\n%s", old)
+	}
+
+	xlsx := download(t, admin, "/admin/reports/program_adoption?format=xlsx", http.StatusOK)
+	archive, err := zip.NewReader(bytes.NewReader(xlsx), int64(len(xlsx)))
+	if err != nil {
+		t.Fatalf("xlsx report is not a zip archive: %v", err)
+	}
+	found := false
+	for _, f := range archive.File {
+		found = found || f.Name == "xl/worksheets/sheet1.xml"
+	}
+	if !found {
+		t.Error("xlsx report has no worksheet")
+	}
+
+	download(t, admin, "/admin/reports/unknown", http.StatusNotFound)
+	download(t, admin, "/admin/reports/user_activity?format=pdf", http.StatusBadRequest)
+	download(t, admin, "/admin/reports/user_activity?from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z", http.StatusBadRequest)
+	download(t, student, "/admin/reports/user_activity", http.StatusForbidden)
+}
+
+// download fetches a file from path (relative to the API root) and checks the status code
+func download(t *testing.T, c *client, path string, wantStatus int) []byte {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, apiURL+path, nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("GET %s: status = %d, want %d\nbody: %s", path, resp.StatusCode, wantStatus, data)
+	}
+	return data
+}
+
+// reportRow finds the CSV row whose first column is id and maps it by column name
+func reportRow(t *testing.T, data []byte, id string) map[string]string {
+	t.Helper()
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("report is not valid CSV: %v", err)
+	}
+	for _, record := range records[1:] {
+		if record[0] == id {
+			row := make(map[string]string, len(record))
+			for i, column := range records[0] {
+				row[column] = record[i]
+			}
+			return row
+		}
+	}
+	t.Fatalf("report has no row for %s:\n%s", id, data)
+	return nil
+}
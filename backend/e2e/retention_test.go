@@ -0,0 +1,68 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestRetentionDryRun(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Retained Routine",
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300},
+		},
+	}, http.StatusCreated, &created)
+	var session models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID, "device_info": map[string]any{"model": "Pixel"}}, http.StatusCreated, &session)
+
+	// A session from before the device_info cutoff
+	if _, err := pool.Exec(context.Background(), "UPDATE practice_sessions SET started_at = $2 WHERE id = $1", session.ID, time.Now().AddDate(0, 0, -200)); err != nil {
+		t.Fatalf("backdate session: %v", err)
+	}
+
+	student.do(http.MethodGet, "/admin/retention/rules", nil, http.StatusForbidden, nil)
+	var rules struct {
+		Rules []models.RetentionRule `json:"rules"`
+	}
+	admin.do(http.MethodGet, "/admin/retention/rules", nil, http.StatusOK, &rules)
+	if len(rules.Rules) != len(models.RetentionRules) {
+		t.Fatalf("rules = %+v, want %d", rules.Rules, len(models.RetentionRules))
+	}
+
+	var run models.RetentionRun
+	admin.do(http.MethodPost, "/admin/retention/rules/device_info/dry-run", nil, http.StatusOK, &run)
+	if !run.DryRun || run.Scheduled || run.Affected["practice_sessions"] == 0 {
+		t.Fatalf("run = %+v, want a dry run counting the backdated session", run)
+	}
+
+	// A dry run changes nothing
+	var stored map[string]any
+	if err := pool.QueryRow(context.Background(), "SELECT device_info FROM practice_sessions WHERE id = $1", session.ID).Scan(&stored); err != nil {
+		t.Fatalf("read session: %v", err)
+	}
+	if stored["model"] != "Pixel" {
+		t.Errorf("device_info = %v, want it kept", stored)
+	}
+
+	// Deleting sessions is off by default
+	admin.do(http.MethodPost, "/admin/retention/rules/sessions/dry-run", nil, http.StatusBadRequest, nil)
+	admin.do(http.MethodPost, "/admin/retention/rules/everything/dry-run", nil, http.StatusNotFound, nil)
+
+	var list struct {
+		Runs []models.RetentionRun `json:"runs"`
+	}
+	admin.do(http.MethodGet, "/admin/retention/runs?rule=device_info", nil, http.StatusOK, &list)
+	if len(list.Runs) == 0 || list.Runs[0].ID != run.ID {
+		t.Errorf("runs = %+v, want the dry run first", list.Runs)
+	}
+}
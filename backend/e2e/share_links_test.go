@@ -0,0 +1,52 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestProgramShareLinks(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Shared Routine",
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300, "rest_after_seconds": 30},
+			{"name": "Arm Circles", "order_index": 1, "exercise_type": "timed", "duration_seconds": 60},
+		},
+	}, http.StatusCreated, &program)
+	programPath := "/programs/" + program.ID.String()
+
+	student.do(http.MethodPost, programPath+"/share-link", nil, http.StatusForbidden, nil)
+	var link models.ProgramShareLink
+	admin.do(http.MethodPost, programPath+"/share-link", map[string]any{"expires_in_days": 7}, http.StatusCreated, &link)
+	if link.Token == "" || !strings.Contains(link.URL, "token=") {
+		t.Fatalf("share link = %+v, want a token and URL", link)
+	}
+
+	var shared models.SharedProgram
+	anonymous(t).do(http.MethodGet, "/shared/programs/"+link.Token, nil, http.StatusOK, &shared)
+	if shared.Name != "E2E Shared Routine" || len(shared.Exercises) != 2 || shared.TotalDurationSeconds != 390 {
+		t.Fatalf("shared program = %+v, want the routine with 2 exercises lasting 390 seconds", shared)
+	}
+	anonymous(t).do(http.MethodGet, "/shared/programs/not-a-token", nil, http.StatusNotFound, nil)
+
+	var links struct {
+		ShareLinks []models.ProgramShareLink `json:"share_links"`
+	}
+	admin.do(http.MethodGet, programPath+"/share-links", nil, http.StatusOK, &links)
+	if len(links.ShareLinks) != 1 || links.ShareLinks[0].ViewCount != 1 || links.ShareLinks[0].Token != "" {
+		t.Fatalf("share links = %+v, want one viewed once without its token", links.ShareLinks)
+	}
+
+	admin.do(http.MethodDelete, programPath+"/share-links/"+link.ID.String(), nil, http.StatusOK, nil)
+	admin.do(http.MethodDelete, programPath+"/share-links/"+link.ID.String(), nil, http.StatusBadRequest, nil)
+	anonymous(t).do(http.MethodGet, "/shared/programs/"+link.Token, nil, http.StatusNotFound, nil)
+}
@@ -0,0 +1,122 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestStreakPolicy(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+	policyPath := "/users/" + student.user.ID.String() + "/streak-policy"
+
+	var policy models.UserStreakPolicy
+	student.do(http.MethodGet, "/auth/me/streak-policy", nil, http.StatusOK, &policy)
+	if policy.Overrides.MinMinutes != nil || policy.Policy.MinMinutes != 0 {
+		t.Errorf("default policy = %+v, want no overrides", policy)
+	}
+	student.do(http.MethodPut, policyPath, map[string]any{"min_minutes": 30}, http.StatusForbidden, nil)
+	admin.do(http.MethodPut, policyPath, map[string]any{"rest_days_per_week": 7}, http.StatusBadRequest, nil)
+
+	var created models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Streak Routine",
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 600},
+		},
+	}, http.StatusCreated, &created)
+	var session models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &session)
+	student.do(http.MethodPut, "/sessions/"+session.ID.String()+"/complete", map[string]any{
+		"total_duration_seconds": 600,
+		"completion_rate":        100,
+	}, http.StatusOK, nil)
+
+	var stats models.SessionStats
+	student.do(http.MethodGet, "/sessions/stats", nil, http.StatusOK, &stats)
+	if stats.CurrentStreak != 1 || stats.LongestStreak != 1 {
+		t.Errorf("streaks = %d / %d, want 1 / 1", stats.CurrentStreak, stats.LongestStreak)
+	}
+
+	// Ten minutes no longer count once the student needs thirty
+	admin.do(http.MethodPut, policyPath, map[string]any{"min_minutes": 30, "rest_days_per_week": 2}, http.StatusOK, &policy)
+	if policy.Policy.MinMinutes != 30 || policy.Policy.RestDaysPerWeek != 2 || policy.Policy.GraceHours != 0 {
+		t.Errorf("policy = %+v, want the overrides applied", policy.Policy)
+	}
+	student.do(http.MethodGet, "/sessions/stats", nil, http.StatusOK, &stats)
+	if stats.CurrentStreak != 0 || stats.LongestStreak != 0 {
+		t.Errorf("streaks = %d / %d, want 0 / 0", stats.CurrentStreak, stats.LongestStreak)
+	}
+
+	// Replacing the overrides drops the ones left out
+	admin.do(http.MethodPut, policyPath, map[string]any{"rest_days_per_week": 2}, http.StatusOK, &policy)
+	if policy.Overrides.MinMinutes != nil || policy.Policy.MinMinutes != 0 {
+		t.Errorf("policy = %+v, want min_minutes back to the default", policy)
+	}
+	admin.do(http.MethodPut, "/users/00000000-0000-0000-0000-000000000000/streak-policy", map[string]any{}, http.StatusNotFound, nil)
+}
+
+func TestStatsRecompute(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Recompute Routine",
+		"exercises": []map[string]any{
+			{"name": "Standing Meditation", "order_index": 0, "exercise_type": "timed", "duration_seconds": 600},
+		},
+	}, http.StatusCreated, &created)
+	var session models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &session)
+	student.do(http.MethodPut, "/sessions/"+session.ID.String()+"/complete", map[string]any{
+		"total_duration_seconds": 600,
+		"completion_rate":        100,
+	}, http.StatusOK, nil)
+
+	// Stats drift from the sessions, e.g. after the deployment's streak rules changed
+	var stats models.SessionStats
+	student.do(http.MethodGet, "/sessions/stats", nil, http.StatusOK, &stats)
+	if _, err := pool.Exec(context.Background(), "UPDATE user_streaks SET current_streak = 5, longest_streak = 5 WHERE user_id = $1", student.user.ID); err != nil {
+		t.Fatalf("drift streaks: %v", err)
+	}
+	student.do(http.MethodGet, "/sessions/stats", nil, http.StatusOK, &stats)
+	if stats.CurrentStreak != 5 {
+		t.Fatalf("stored streak = %d, want the drifted 5", stats.CurrentStreak)
+	}
+
+	student.do(http.MethodPost, "/admin/stats-recomputes", map[string]any{"user_id": student.user.ID}, http.StatusForbidden, nil)
+	admin.do(http.MethodPost, "/admin/stats-recomputes", map[string]any{"user_id": "not-a-uuid"}, http.StatusBadRequest, nil)
+	admin.do(http.MethodPost, "/admin/stats-recomputes", map[string]any{"user_id": "00000000-0000-0000-0000-000000000000"}, http.StatusNotFound, nil)
+
+	var job models.StatsRecompute
+	admin.do(http.MethodPost, "/admin/stats-recomputes", map[string]any{"user_id": student.user.ID}, http.StatusAccepted, &job)
+	if job.Status != models.StatsRecomputePending || job.Total != 1 || job.Processed != 0 {
+		t.Errorf("queued recompute = %+v, want one pending user", job)
+	}
+
+	if _, err := api.StatsRecomputeService.RunPending(context.Background()); err != nil {
+		t.Fatalf("RunPending: %v", err)
+	}
+	admin.do(http.MethodGet, "/admin/stats-recomputes/"+job.ID.String(), nil, http.StatusOK, &job)
+	if job.Status != models.StatsRecomputeCompleted || job.Processed != 1 || job.CompletedAt == nil {
+		t.Errorf("recompute = %+v, want it completed for one user", job)
+	}
+	student.do(http.MethodGet, "/sessions/stats", nil, http.StatusOK, &stats)
+	if stats.CurrentStreak != 1 || stats.LongestStreak != 1 {
+		t.Errorf("streaks = %d / %d, want 1 / 1 again", stats.CurrentStreak, stats.LongestStreak)
+	}
+
+	var list struct {
+		Recomputes []models.StatsRecompute `json:"recomputes"`
+	}
+	admin.do(http.MethodGet, "/admin/stats-recomputes", nil, http.StatusOK, &list)
+	if len(list.Recomputes) == 0 || list.Recomputes[0].ID != job.ID {
+		t.Errorf("recomputes = %+v, want the newest first", list.Recomputes)
+	}
+}
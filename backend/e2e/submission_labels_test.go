@@ -0,0 +1,79 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestSubmissionLabels(t *testing.T) {
+	student := newStudent(t)
+	admin := newAdmin(t)
+
+	var program models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Labels"}, http.StatusCreated, &program)
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{"title": "Label me"}, http.StatusCreated, &created)
+	path := "/submissions/" + created.Submission.ID.String() + "/labels"
+
+	name := "e2e-follow-up-" + uuid.NewString()[:8]
+	student.do(http.MethodPost, "/submission-labels", map[string]any{"name": name}, http.StatusForbidden, nil)
+	admin.do(http.MethodPost, "/submission-labels", map[string]any{"name": name, "color": "red"}, http.StatusBadRequest, nil)
+
+	var label models.SubmissionLabel
+	admin.do(http.MethodPost, "/submission-labels", map[string]any{"name": name, "color": "#FF8800"}, http.StatusCreated, &label)
+	if label.Color != "#ff8800" {
+		t.Errorf("color = %q, want it lowercased", label.Color)
+	}
+	var other models.SubmissionLabel
+	admin.do(http.MethodPost, "/submission-labels", map[string]any{"name": name + "-other"}, http.StatusCreated, &other)
+	if other.Color != models.DefaultLabelColor {
+		t.Errorf("default color = %q", other.Color)
+	}
+	admin.do(http.MethodPut, "/submission-labels/"+other.ID.String(), map[string]any{"name": " " + name}, http.StatusConflict, nil)
+
+	student.do(http.MethodPut, path, map[string]any{"label_ids": []string{label.ID.String()}}, http.StatusForbidden, nil)
+	admin.do(http.MethodPut, path, map[string]any{"label_ids": []string{uuid.NewString()}}, http.StatusBadRequest, nil)
+
+	var labeled struct {
+		Labels []models.SubmissionLabel `json:"labels"`
+	}
+	admin.do(http.MethodPut, path, map[string]any{"label_ids": []string{label.ID.String(), other.ID.String()}}, http.StatusOK, &labeled)
+	if len(labeled.Labels) != 2 {
+		t.Fatalf("labels = %+v, want both", labeled.Labels)
+	}
+
+	// Filtering by label finds only this submission, with its labels
+	var list struct {
+		Submissions []models.SubmissionListItem `json:"submissions"`
+	}
+	admin.do(http.MethodGet, "/submissions?label_id="+label.ID.String(), nil, http.StatusOK, &list)
+	if len(list.Submissions) != 1 || list.Submissions[0].ID != created.Submission.ID || len(list.Submissions[0].Labels) != 2 {
+		t.Fatalf("filtered submissions = %+v, want the labeled one", list.Submissions)
+	}
+
+	// Students never see labels
+	student.do(http.MethodGet, "/submissions?label_id="+label.ID.String(), nil, http.StatusOK, &list)
+	if len(list.Submissions) != 1 || list.Submissions[0].Labels != nil {
+		t.Errorf("student submissions = %+v, want the thread without labels", list.Submissions)
+	}
+
+	admin.do(http.MethodPut, path, map[string]any{"label_ids": []string{other.ID.String()}}, http.StatusOK, &labeled)
+	if len(labeled.Labels) != 1 || labeled.Labels[0].ID != other.ID {
+		t.Errorf("labels = %+v, want only the other label", labeled.Labels)
+	}
+
+	// Deleting a label removes it from its submissions
+	admin.do(http.MethodDelete, "/submission-labels/"+other.ID.String(), nil, http.StatusOK, nil)
+	admin.do(http.MethodDelete, "/submission-labels/"+other.ID.String(), nil, http.StatusNotFound, nil)
+	admin.do(http.MethodPut, path, map[string]any{"label_ids": []string{}}, http.StatusOK, &labeled)
+	if len(labeled.Labels) != 0 {
+		t.Errorf("labels = %+v, want none", labeled.Labels)
+	}
+}
@@ -0,0 +1,560 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestSubmissionChatUnreadCounts(t *testing.T) {
+	student := newStudent(t)
+	admin := newAdmin(t)
+
+	var program models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Form Review",
+	}, http.StatusCreated, &program)
+
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{
+		"title": "Please check my stance",
+	}, http.StatusCreated, &created)
+	submissionID := created.Submission.ID.String()
+
+	student.do(http.MethodPost, "/submissions/"+submissionID+"/messages", map[string]any{
+		"content": "Here is my horse stance",
+	}, http.StatusCreated, nil)
+
+	// Own messages are never unread
+	var counts models.UnreadCounts
+	student.do(http.MethodGet, "/submissions/unread-count", nil, http.StatusOK, &counts)
+	if counts.Total != 0 {
+		t.Errorf("student unread total = %d, want 0", counts.Total)
+	}
+
+	// The instructor sees the student's message as unread. Other tests' submissions are
+	// visible to admins too, so only look at this one.
+	admin.do(http.MethodGet, "/submissions/unread-count", nil, http.StatusOK, &counts)
+	if got := counts.BySubmission[submissionID]; got != 1 {
+		t.Fatalf("admin unread for submission = %d, want 1", got)
+	}
+
+	var thread struct {
+		Messages []models.MessageWithAuthor `json:"messages"`
+	}
+	admin.do(http.MethodGet, "/submissions/"+submissionID+"/messages", nil, http.StatusOK, &thread)
+	if len(thread.Messages) != 1 || thread.Messages[0].IsRead {
+		t.Fatalf("admin messages = %+v, want one unread message", thread.Messages)
+	}
+	admin.do(http.MethodPut, "/messages/"+thread.Messages[0].ID.String()+"/read", nil, http.StatusOK, nil)
+
+	admin.do(http.MethodGet, "/submissions/unread-count", nil, http.StatusOK, &counts)
+	if got := counts.BySubmission[submissionID]; got != 0 {
+		t.Errorf("admin unread for submission after reading = %d, want 0", got)
+	}
+
+	// The instructor replies, which is unread for the student
+	admin.do(http.MethodPost, "/submissions/"+submissionID+"/messages", map[string]any{
+		"content": "Sink your hips a little lower",
+	}, http.StatusCreated, nil)
+
+	student.do(http.MethodGet, "/submissions/unread-count", nil, http.StatusOK, &counts)
+	if counts.Total != 1 || counts.ByProgram[program.ID.String()] != 1 {
+		t.Fatalf("student unread = %+v, want 1 for the program", counts)
+	}
+
+	student.do(http.MethodGet, "/submissions/"+submissionID+"/messages", nil, http.StatusOK, &thread)
+	if len(thread.Messages) != 2 {
+		t.Fatalf("student sees %d messages, want 2", len(thread.Messages))
+	}
+	reply := thread.Messages[1]
+	if reply.AuthorRole != models.RoleAdmin || reply.IsRead {
+		t.Fatalf("reply = %+v, want an unread admin message", reply)
+	}
+	student.do(http.MethodPut, "/messages/"+reply.ID.String()+"/read", nil, http.StatusOK, nil)
+
+	student.do(http.MethodGet, "/submissions/unread-count", nil, http.StatusOK, &counts)
+	if counts.Total != 0 {
+		t.Errorf("student unread total after reading = %d, want 0", counts.Total)
+	}
+
+	// Other students cannot read or join the conversation
+	other := newStudent(t)
+	other.do(http.MethodGet, "/submissions/"+submissionID, nil, http.StatusForbidden, nil)
+	other.do(http.MethodPost, "/submissions/"+submissionID+"/messages", map[string]any{
+		"content": "Hi",
+	}, http.StatusForbidden, nil)
+}
+
+func TestFeedbackSnippets(t *testing.T) {
+	student := newStudent(t)
+	admin := newAdmin(t)
+
+	var program models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Snippet Forms",
+	}, http.StatusCreated, &program)
+
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{
+		"title": "Knee check",
+	}, http.StatusCreated, &created)
+	submissionID := created.Submission.ID.String()
+
+	// Unknown placeholders are rejected when saving
+	admin.do(http.MethodPost, "/snippets", map[string]any{
+		"title": "Broken",
+		"body":  "Hi {{nickname}}",
+	}, http.StatusBadRequest, nil)
+
+	var snippet models.FeedbackSnippet
+	admin.do(http.MethodPost, "/snippets", map[string]any{
+		"title": "Knee alignment",
+		"body":  "{{student_first_name}}, keep your knees over your toes in {{program_name}}.",
+	}, http.StatusCreated, &snippet)
+
+	var reply struct {
+		Message models.SubmissionMessage `json:"message"`
+	}
+	admin.do(http.MethodPost, "/submissions/"+submissionID+"/messages", map[string]any{
+		"content":    "Good start.",
+		"snippet_id": snippet.ID.String(),
+	}, http.StatusCreated, &reply)
+
+	want := "Good start.\n\nE2E, keep your knees over your toes in E2E Snippet Forms."
+	if reply.Message.Content != want {
+		t.Errorf("message content = %q, want %q", reply.Message.Content, want)
+	}
+
+	// Snippets are private to their instructor and not available to students
+	otherAdmin := newAdmin(t)
+	otherAdmin.do(http.MethodGet, "/snippets/"+snippet.ID.String(), nil, http.StatusNotFound, nil)
+	otherAdmin.do(http.MethodPost, "/submissions/"+submissionID+"/messages", map[string]any{
+		"snippet_id": snippet.ID.String(),
+	}, http.StatusNotFound, nil)
+	student.do(http.MethodGet, "/snippets", nil, http.StatusForbidden, nil)
+
+	admin.do(http.MethodDelete, "/snippets/"+snippet.ID.String(), nil, http.StatusOK, nil)
+	admin.do(http.MethodGet, "/snippets/"+snippet.ID.String(), nil, http.StatusNotFound, nil)
+}
+
+func TestScheduledMessages(t *testing.T) {
+	student := newStudent(t)
+	admin := newAdmin(t)
+
+	var program models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{
+		"name": "E2E Scheduled Forms",
+	}, http.StatusCreated, &program)
+
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{
+		"title": "Stance check",
+	}, http.StatusCreated, &created)
+	submissionID := created.Submission.ID.String()
+
+	sendAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	admin.do(http.MethodPost, "/scheduled-messages", map[string]any{
+		"submission_id": submissionID,
+		"content":       "Too early",
+		"send_at":       time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+	}, http.StatusBadRequest, nil)
+
+	var scheduled, cancelled models.ScheduledMessage
+	admin.do(http.MethodPost, "/scheduled-messages", map[string]any{
+		"submission_id": submissionID,
+		"content":       "Let's review your stance tomorrow.",
+		"send_at":       sendAt,
+	}, http.StatusCreated, &scheduled)
+	admin.do(http.MethodPost, "/scheduled-messages", map[string]any{
+		"submission_id": submissionID,
+		"content":       "Never mind",
+		"send_at":       sendAt,
+	}, http.StatusCreated, &cancelled)
+
+	admin.do(http.MethodDelete, "/scheduled-messages/"+cancelled.ID.String(), nil, http.StatusOK, nil)
+	admin.do(http.MethodDelete, "/scheduled-messages/"+cancelled.ID.String(), nil, http.StatusConflict, nil)
+	student.do(http.MethodGet, "/scheduled-messages", nil, http.StatusForbidden, nil)
+
+	var pending struct {
+		ScheduledMessages []models.ScheduledMessage `json:"scheduled_messages"`
+	}
+	admin.do(http.MethodGet, "/scheduled-messages", nil, http.StatusOK, &pending)
+	if len(pending.ScheduledMessages) != 1 || pending.ScheduledMessages[0].ID != scheduled.ID {
+		t.Fatalf("pending scheduled messages = %+v, want only %s", pending.ScheduledMessages, scheduled.ID)
+	}
+
+	// Make the message due and run the delivery job
+	if _, err := pool.Exec(context.Background(), "UPDATE scheduled_messages SET send_at = $2 WHERE id = $1", scheduled.ID, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("Failed to backdate scheduled message: %v", err)
+	}
+	if _, err := api.ScheduledMessageService.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("DeliverDue() error = %v", err)
+	}
+
+	var thread struct {
+		Messages []models.MessageWithAuthor `json:"messages"`
+	}
+	student.do(http.MethodGet, "/submissions/"+submissionID+"/messages", nil, http.StatusOK, &thread)
+	if len(thread.Messages) != 1 || thread.Messages[0].Content != "Let's review your stance tomorrow." {
+		t.Errorf("thread messages = %+v, want the scheduled message", thread.Messages)
+	}
+
+	var notifications struct {
+		Notifications []models.Notification `json:"notifications"`
+	}
+	student.do(http.MethodGet, "/notifications", nil, http.StatusOK, &notifications)
+	if len(notifications.Notifications) != 1 || notifications.Notifications[0].Type != models.NotificationNewMessage {
+		t.Errorf("notifications = %+v, want one %s notification", notifications.Notifications, models.NotificationNewMessage)
+	}
+
+	admin.do(http.MethodDelete, "/scheduled-messages/"+scheduled.ID.String(), nil, http.StatusConflict, nil)
+}
+
+func TestMessageThrottling(t *testing.T) {
+	student := newStudent(t)
+
+	var program models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Throttled Thread"}, http.StatusCreated, &program)
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{"title": "Flaky connection"}, http.StatusCreated, &created)
+	path := "/submissions/" + created.Submission.ID.String() + "/messages"
+
+	var posted struct {
+		Message models.SubmissionMessage `json:"message"`
+	}
+	student.do(http.MethodPost, path, map[string]any{"content": "Is my stance right?"}, http.StatusCreated, &posted)
+
+	// A resent message is refused and points at the original
+	var failure struct {
+		Error struct {
+			Code    string         `json:"code"`
+			Details map[string]any `json:"details"`
+		} `json:"error"`
+	}
+	student.do(http.MethodPost, path, map[string]any{"content": "Is my stance right?"}, http.StatusConflict, &failure)
+	if failure.Error.Details["message_id"] != posted.Message.ID.String() {
+		t.Errorf("duplicate error = %+v, want the original message %s", failure.Error, posted.Message.ID)
+	}
+
+	// The default limit is 10 messages per minute
+	for i := 2; i <= 10; i++ {
+		student.do(http.MethodPost, path, map[string]any{"content": fmt.Sprintf("Follow-up %d", i)}, http.StatusCreated, nil)
+	}
+	student.do(http.MethodPost, path, map[string]any{"content": "One too many"}, http.StatusTooManyRequests, &failure)
+	if retry, _ := failure.Error.Details["retry_after_seconds"].(float64); failure.Error.Code != "RATE_LIMIT_EXCEEDED" || retry < 1 || retry > 60 {
+		t.Errorf("rate limit error = %+v, want a retry within a minute", failure.Error)
+	}
+}
+
+func TestMessageReadReceipts(t *testing.T) {
+	student := newStudent(t)
+	admin := newAdmin(t)
+
+	var program models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Read Receipts"}, http.StatusCreated, &program)
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{"title": "Did you see this?"}, http.StatusCreated, &created)
+	path := "/submissions/" + created.Submission.ID.String() + "/messages"
+
+	var posted struct {
+		Message models.SubmissionMessage `json:"message"`
+	}
+	student.do(http.MethodPost, path, map[string]any{"content": "My form video"}, http.StatusCreated, &posted)
+	student.do(http.MethodPut, "/messages/"+posted.Message.ID.String()+"/read", nil, http.StatusOK, nil)
+	admin.do(http.MethodPut, "/messages/"+posted.Message.ID.String()+"/read", nil, http.StatusOK, nil)
+	admin.do(http.MethodPost, path, map[string]any{"content": "Seen, looks good"}, http.StatusCreated, nil)
+
+	var thread struct {
+		Messages []models.MessageWithAuthor `json:"messages"`
+	}
+	student.do(http.MethodGet, path, nil, http.StatusOK, &thread)
+	if len(thread.Messages) != 2 {
+		t.Fatalf("messages = %+v, want 2", thread.Messages)
+	}
+	// The author sees who else read their message, but not who read the instructor's
+	if readBy := thread.Messages[0].ReadBy; len(readBy) != 1 || readBy[0].UserID != admin.user.ID || readBy[0].ReadAt.IsZero() {
+		t.Errorf("read_by = %+v, want only the instructor", readBy)
+	}
+	if readBy := thread.Messages[1].ReadBy; len(readBy) != 0 {
+		t.Errorf("read_by of the instructor's message = %+v, want it left out for the student", readBy)
+	}
+
+	admin.do(http.MethodGet, path, nil, http.StatusOK, &thread)
+	if readBy := thread.Messages[0].ReadBy; len(readBy) != 1 || readBy[0].UserID != admin.user.ID {
+		t.Errorf("instructor read_by = %+v, want the instructor", readBy)
+	}
+}
+
+func TestTypingIndicators(t *testing.T) {
+	student := newStudent(t)
+	admin := newAdmin(t)
+	outsider := newStudent(t)
+
+	var program models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Typing"}, http.StatusCreated, &program)
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{"title": "Live feedback"}, http.StatusCreated, &created)
+	path := "/submissions/" + created.Submission.ID.String()
+
+	var typing struct {
+		Typing []models.TypingUser `json:"typing"`
+	}
+	student.do(http.MethodPut, path+"/typing", map[string]any{"state": "typing"}, http.StatusBadRequest, nil)
+	outsider.do(http.MethodPut, path+"/typing", map[string]any{"state": "start"}, http.StatusForbidden, nil)
+
+	student.do(http.MethodPut, path+"/typing", map[string]any{"state": "start"}, http.StatusOK, &typing)
+	if len(typing.Typing) != 0 {
+		t.Errorf("typing = %+v, want nobody else", typing.Typing)
+	}
+	admin.do(http.MethodGet, path+"/typing", nil, http.StatusOK, &typing)
+	if len(typing.Typing) != 1 || typing.Typing[0].UserID != student.user.ID {
+		t.Fatalf("typing = %+v, want the student", typing.Typing)
+	}
+
+	// Posting a message ends the indicator
+	student.do(http.MethodPost, path+"/messages", map[string]any{"content": "Here's my question"}, http.StatusCreated, nil)
+	admin.do(http.MethodGet, path+"/typing", nil, http.StatusOK, &typing)
+	if len(typing.Typing) != 0 {
+		t.Errorf("typing after posting = %+v, want nobody", typing.Typing)
+	}
+
+	admin.do(http.MethodPut, path+"/typing", map[string]any{"state": "start"}, http.StatusOK, nil)
+	admin.do(http.MethodPut, path+"/typing", map[string]any{"state": "stop"}, http.StatusOK, nil)
+	student.do(http.MethodGet, path+"/typing", nil, http.StatusOK, &typing)
+	if len(typing.Typing) != 0 {
+		t.Errorf("typing after stop = %+v, want nobody", typing.Typing)
+	}
+}
+
+func TestInstructorPresence(t *testing.T) {
+	student := newStudent(t)
+	admin := newAdmin(t)
+	outsider := newStudent(t)
+
+	var program models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Presence"}, http.StatusCreated, &program)
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{"title": "Anyone there?"}, http.StatusCreated, &created)
+	path := "/submissions/" + created.Submission.ID.String() + "/presence"
+
+	outsider.do(http.MethodGet, path, nil, http.StatusForbidden, nil)
+	admin.do(http.MethodPost, "/presence/heartbeat", nil, http.StatusNoContent, nil)
+
+	// Activity is recorded in the background, so give the heartbeat a moment to land
+	var presence models.InstructorPresence
+	for attempt := 0; attempt < 40 && !presence.Online; attempt++ {
+		if attempt > 0 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		var resp struct {
+			Instructors []models.InstructorPresence `json:"instructors"`
+		}
+		student.do(http.MethodGet, path, nil, http.StatusOK, &resp)
+		for _, p := range resp.Instructors {
+			if p.UserID == admin.user.ID {
+				presence = p
+			}
+		}
+	}
+	if !presence.Online || presence.LastSeenAt == nil || presence.FullName == "" {
+		t.Errorf("presence = %+v, want the instructor online", presence)
+	}
+}
+
+func TestSubmissionArchiving(t *testing.T) {
+	student := newStudent(t)
+	admin := newAdmin(t)
+
+	var program models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Archiving"}, http.StatusCreated, &program)
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodPost, "/programs/"+program.ID.String()+"/submissions", map[string]any{"title": "Archivable snake creeps down"}, http.StatusCreated, &created)
+	submissionID := created.Submission.ID.String()
+	path := "/submissions/" + submissionID
+	student.do(http.MethodPost, path+"/messages", map[string]any{"content": "Is this low enough?"}, http.StatusCreated, nil)
+
+	admin.do(http.MethodPut, path+"/archive", nil, http.StatusOK, nil)
+	admin.do(http.MethodPut, path+"/archive", nil, http.StatusOK, nil)
+
+	listed := func(c *client, query string) (found, archived bool) {
+		t.Helper()
+		var resp struct {
+			Submissions []models.SubmissionListItem `json:"submissions"`
+		}
+		c.do(http.MethodGet, "/submissions?limit=100&program_id="+program.ID.String()+query, nil, http.StatusOK, &resp)
+		for _, s := range resp.Submissions {
+			if s.ID == created.Submission.ID {
+				return true, s.Archived
+			}
+		}
+		return false, false
+	}
+
+	// Archiving only affects the instructor's inbox
+	if found, _ := listed(admin, ""); found {
+		t.Error("archived submission is in the default list")
+	}
+	if found, archived := listed(admin, "&archived=true"); !found || !archived {
+		t.Error("archived submission is missing from the archive")
+	}
+	if found, _ := listed(student, ""); !found {
+		t.Error("submission left the student's list")
+	}
+
+	var counts models.UnreadCounts
+	admin.do(http.MethodGet, "/submissions/unread-count", nil, http.StatusOK, &counts)
+	if got := counts.BySubmission[submissionID]; got != 0 {
+		t.Errorf("admin unread for archived submission = %d, want 0", got)
+	}
+
+	var search struct {
+		Results []models.SubmissionSearchResult `json:"results"`
+	}
+	admin.do(http.MethodGet, "/submissions/search?q=archivable", nil, http.StatusOK, &search)
+	if len(search.Results) == 0 || search.Results[0].ID != created.Submission.ID || !search.Results[0].Archived {
+		t.Errorf("search results = %+v, want the archived submission", search.Results)
+	}
+
+	// A new message brings the thread back
+	student.do(http.MethodPost, path+"/messages", map[string]any{"content": "Any thoughts?"}, http.StatusCreated, nil)
+	if found, _ := listed(admin, ""); !found {
+		t.Error("submission is still archived after a new message")
+	}
+
+	student.do(http.MethodPut, path+"/archive", nil, http.StatusOK, nil)
+	student.do(http.MethodDelete, path+"/archive", nil, http.StatusOK, nil)
+	if found, _ := listed(student, ""); !found {
+		t.Error("unarchived submission is missing from the list")
+	}
+}
+
+func TestSubmissionTemplate(t *testing.T) {
+	student := newStudent(t)
+	admin := newAdmin(t)
+
+	var program models.ProgramCreateResult
+	student.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Templated"}, http.StatusCreated, &program)
+	path := "/programs/" + program.ID.String()
+
+	student.do(http.MethodGet, path+"/submission-template", nil, http.StatusNotFound, nil)
+	student.do(http.MethodPut, path+"/submission-template", map[string]any{"prompts": []string{"Film it"}}, http.StatusForbidden, nil)
+	admin.do(http.MethodPut, path+"/submission-template", map[string]any{"title_pattern": "Week ("}, http.StatusBadRequest, nil)
+	admin.do(http.MethodPut, path+"/submission-template", map[string]any{
+		"title_pattern": `Week \d+: .+`,
+		"title_example": "Horse stance",
+	}, http.StatusBadRequest, nil)
+
+	admin.do(http.MethodPut, path+"/submission-template", map[string]any{
+		"title_pattern": `Week \d+: .+`,
+		"title_example": "Week 3: Horse stance",
+		"prompts":       []string{"Film from the side", "2 minutes"},
+	}, http.StatusOK, nil)
+
+	var template models.SubmissionTemplate
+	student.do(http.MethodGet, path+"/submission-template", nil, http.StatusOK, &template)
+	if template.TitlePattern == nil || len(template.Prompts) != 2 || template.Prompts[0] != "Film from the side" {
+		t.Fatalf("template = %+v", template)
+	}
+
+	// The pattern has to match the whole title
+	var apiErr struct {
+		Error struct {
+			Details map[string]any `json:"details"`
+		} `json:"error"`
+	}
+	student.do(http.MethodPost, path+"/submissions", map[string]any{"title": "My Week 3: Horse stance"}, http.StatusBadRequest, &apiErr)
+	if apiErr.Error.Details["title_example"] != "Week 3: Horse stance" {
+		t.Errorf("details = %+v, want the title example", apiErr.Error.Details)
+	}
+	student.do(http.MethodPost, path+"/submissions", map[string]any{"title": "Week 3: Horse stance"}, http.StatusCreated, nil)
+
+	admin.do(http.MethodDelete, path+"/submission-template", nil, http.StatusOK, nil)
+	student.do(http.MethodPost, path+"/submissions", map[string]any{"title": "Anything goes"}, http.StatusCreated, nil)
+}
+
+func TestSubmissionRequiresAssignment(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Assigned Threads"}, http.StatusCreated, &program)
+	path := "/programs/" + program.ID.String() + "/submissions"
+
+	student.do(http.MethodPost, path, map[string]any{"title": "Not mine yet"}, http.StatusForbidden, nil)
+	student.do(http.MethodPost, "/programs/00000000-0000-0000-0000-000000000000/submissions", map[string]any{"title": "Nowhere"}, http.StatusNotFound, nil)
+	admin.do(http.MethodPost, path, map[string]any{"title": "Instructor notes"}, http.StatusCreated, nil)
+
+	admin.do(http.MethodPost, "/programs/"+program.ID.String()+"/assign", map[string]any{"user_ids": []string{student.user.ID.String()}}, http.StatusOK, nil)
+	student.do(http.MethodPost, path, map[string]any{"title": "Now it is mine"}, http.StatusCreated, nil)
+}
+
+func TestWelcomeThreadOnAssignment(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+	other := newStudent(t)
+
+	var program models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{"name": "E2E Welcome"}, http.StatusCreated, &program)
+	assignPath := "/programs/" + program.ID.String() + "/assign"
+
+	var report models.AssignmentReport
+	admin.do(http.MethodPost, assignPath, map[string]any{
+		"user_ids":        []string{student.user.ID.String()},
+		"welcome_message": "Glad to have you, post your first video here",
+	}, http.StatusOK, &report)
+	if len(report.Results) != 1 || report.Results[0].SubmissionID == nil {
+		t.Fatalf("report = %+v, want a welcome thread", report)
+	}
+	path := "/submissions/" + report.Results[0].SubmissionID.String()
+
+	var fetched struct {
+		Submission models.Submission `json:"submission"`
+	}
+	student.do(http.MethodGet, path, nil, http.StatusOK, &fetched)
+	if fetched.Submission.Title != "Welcome to E2E Welcome" || fetched.Submission.UserID != student.user.ID {
+		t.Errorf("submission = %+v, want the student's welcome thread", fetched.Submission)
+	}
+	var thread struct {
+		Messages []models.MessageWithAuthor `json:"messages"`
+	}
+	student.do(http.MethodGet, path+"/messages", nil, http.StatusOK, &thread)
+	if len(thread.Messages) != 1 || thread.Messages[0].UserID != admin.user.ID {
+		t.Errorf("messages = %+v, want the instructor's welcome", thread.Messages)
+	}
+
+	// Users who already have the program don't get another thread; without a message nobody does
+	admin.do(http.MethodPost, assignPath, map[string]any{
+		"user_ids":        []string{student.user.ID.String()},
+		"welcome_message": "Again",
+	}, http.StatusOK, &report)
+	if report.Results[0].SubmissionID != nil {
+		t.Errorf("report = %+v, want no thread for an existing assignment", report)
+	}
+	admin.do(http.MethodPost, assignPath, map[string]any{"user_ids": []string{other.user.ID.String()}}, http.StatusOK, &report)
+	if report.Results[0].SubmissionID != nil {
+		t.Errorf("report = %+v, want no thread without a welcome message", report)
+	}
+}
@@ -0,0 +1,92 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestSupportTickets(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+	other := newStudent(t)
+
+	student.do(http.MethodPost, "/support/tickets", map[string]any{"category": "gossip", "message": "Hi"}, http.StatusBadRequest, nil)
+
+	var ticket models.SupportTicketWithMessages
+	student.do(http.MethodPost, "/support/tickets", map[string]any{
+		"category": "technical",
+		"message":  "The timer stops when my screen locks.",
+	}, http.StatusCreated, &ticket)
+	if ticket.Status != models.SupportOpen || len(ticket.Messages) != 1 || ticket.MessageCount != 1 {
+		t.Errorf("ticket = %+v, want an open ticket with the first message", ticket)
+	}
+
+	ticketPath := "/support/tickets/" + ticket.ID.String()
+	other.do(http.MethodGet, ticketPath, nil, http.StatusNotFound, nil)
+	other.do(http.MethodPost, ticketPath+"/messages", map[string]any{"content": "Me too"}, http.StatusNotFound, nil)
+	student.do(http.MethodGet, "/admin/support-tickets", nil, http.StatusForbidden, nil)
+
+	var queue struct {
+		Tickets []models.SupportTicket `json:"tickets"`
+	}
+	admin.do(http.MethodGet, "/admin/support-tickets?category=technical&limit=100", nil, http.StatusOK, &queue)
+	if !containsTicket(queue.Tickets, ticket.ID) {
+		t.Errorf("queue = %+v, want the open ticket", queue.Tickets)
+	}
+
+	admin.do(http.MethodPost, ticketPath+"/messages", map[string]any{"content": "Turn off battery saver for the app."}, http.StatusCreated, nil)
+	student.do(http.MethodGet, ticketPath, nil, http.StatusOK, &ticket)
+	if ticket.Status != models.SupportAnswered || len(ticket.Messages) != 2 || ticket.Messages[1].AuthorRole != models.RoleAdmin {
+		t.Errorf("ticket = %+v, want it answered by the admin", ticket)
+	}
+	admin.do(http.MethodGet, "/admin/support-tickets?limit=100", nil, http.StatusOK, &queue)
+	if containsTicket(queue.Tickets, ticket.ID) {
+		t.Errorf("open queue = %+v, want the answered ticket gone", queue.Tickets)
+	}
+
+	var notifications struct {
+		Notifications []models.Notification `json:"notifications"`
+	}
+	student.do(http.MethodGet, "/notifications", nil, http.StatusOK, &notifications)
+	if len(notifications.Notifications) == 0 || notifications.Notifications[0].Type != models.NotificationSupportReply {
+		t.Errorf("notifications = %+v, want a support_reply", notifications.Notifications)
+	}
+
+	// Closed tickets reopen when the student writes again
+	var closed models.SupportTicket
+	admin.do(http.MethodPut, "/admin/support-tickets/"+ticket.ID.String(), map[string]any{"status": "closed"}, http.StatusOK, &closed)
+	if closed.Status != models.SupportClosed || closed.ClosedAt == nil {
+		t.Errorf("closed ticket = %+v, want it closed", closed)
+	}
+	student.do(http.MethodPost, ticketPath+"/messages", map[string]any{"content": "It happens again."}, http.StatusCreated, nil)
+	student.do(http.MethodGet, ticketPath, nil, http.StatusOK, &ticket)
+	if ticket.Status != models.SupportOpen || ticket.ClosedAt != nil {
+		t.Errorf("ticket = %+v, want it open again", ticket)
+	}
+
+	var mine struct {
+		Tickets []models.SupportTicket `json:"tickets"`
+	}
+	student.do(http.MethodGet, "/support/tickets", nil, http.StatusOK, &mine)
+	if len(mine.Tickets) != 1 || mine.Tickets[0].ID != ticket.ID {
+		t.Errorf("own tickets = %+v, want the one ticket", mine.Tickets)
+	}
+	other.do(http.MethodGet, "/support/tickets", nil, http.StatusOK, &mine)
+	if len(mine.Tickets) != 0 {
+		t.Errorf("other student's tickets = %+v, want none", mine.Tickets)
+	}
+}
+
+func containsTicket(tickets []models.SupportTicket, id uuid.UUID) bool {
+	for _, ticket := range tickets {
+		if ticket.ID == id {
+			return true
+		}
+	}
+	return false
+}
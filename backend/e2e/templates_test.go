@@ -0,0 +1,65 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestPublicTemplateCaching(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	resp := getPublicTemplates(t, student, "")
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" || !strings.HasPrefix(resp.Header.Get("Cache-Control"), "public,") {
+		t.Fatalf("listing = %d with headers %v, want a cacheable 200", resp.StatusCode, resp.Header)
+	}
+	if !strings.Contains(resp.Header.Get("Surrogate-Key"), "programs-public") {
+		t.Errorf("Surrogate-Key = %q, want the listing's key", resp.Header.Get("Surrogate-Key"))
+	}
+	if resp := getPublicTemplates(t, admin, etag); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("listing with a matching ETag = %d, want 304", resp.StatusCode)
+	}
+
+	// A new public template invalidates the cached listing
+	var created models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name":        "E2E Cached Template",
+		"is_template": true,
+		"is_public":   true,
+		"exercises":   []map[string]any{{"name": "Wuji", "order_index": 0, "exercise_type": "timed", "duration_seconds": 300}},
+	}, http.StatusCreated, &created)
+
+	resp = getPublicTemplates(t, student, etag)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == etag {
+		t.Fatalf("listing after a change = %d with ETag %q, want a fresh 200", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+	if !strings.Contains(resp.Header.Get("Surrogate-Key"), "program-"+created.Program.ID.String()) {
+		t.Errorf("Surrogate-Key = %q, want the new template's key", resp.Header.Get("Surrogate-Key"))
+	}
+}
+
+// getPublicTemplates fetches the public listing, optionally revalidating an ETag
+func getPublicTemplates(t *testing.T, c *client, ifNoneMatch string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, apiURL+"/programs?is_public=true", nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /programs failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp
+}
@@ -0,0 +1,41 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestTimestampsAreRFC3339UTC(t *testing.T) {
+	admin := newAdmin(t)
+	student := newStudent(t)
+
+	var created models.ProgramCreateResult
+	admin.do(http.MethodPost, "/programs", map[string]any{
+		"name":      "E2E Timestamp Routine",
+		"exercises": []map[string]any{{"name": "Zhan Zhuang", "order_index": 0, "exercise_type": "timed", "duration_seconds": 600}},
+	}, http.StatusCreated, &created)
+	admin.do(http.MethodPost, "/programs/"+created.ID.String()+"/assign", map[string]any{"user_ids": []string{student.user.ID.String()}}, http.StatusOK, nil)
+	var session models.PracticeSession
+	student.do(http.MethodPost, "/sessions/start", map[string]any{"program_id": created.ID}, http.StatusCreated, &session)
+	sessionPath := "/sessions/" + session.ID.String()
+
+	// Times without a zone are ambiguous and rejected
+	student.do(http.MethodPut, sessionPath+"/complete", map[string]any{"completed_at": "2026-03-14T10:30:00"}, http.StatusBadRequest, nil)
+	student.do(http.MethodPut, sessionPath+"/complete", map[string]any{"completed_at": "2026-03-14T10:30:00+01:00"}, http.StatusOK, nil)
+
+	var raw struct {
+		CompletedAt string `json:"completed_at"`
+		StartedAt   string `json:"started_at"`
+	}
+	student.do(http.MethodGet, sessionPath, nil, http.StatusOK, &raw)
+	if raw.CompletedAt != "2026-03-14T09:30:00Z" {
+		t.Errorf("completed_at = %q, want it in UTC", raw.CompletedAt)
+	}
+	if n := len(raw.StartedAt); n == 0 || raw.StartedAt[n-1] != 'Z' {
+		t.Errorf("started_at = %q, want it in UTC", raw.StartedAt)
+	}
+}
@@ -0,0 +1,98 @@
+// Package anonymize pseudonymizes personal data in analytics before it leaves the system.
+// User IDs are replaced by keyed hashes, so the same user keeps the same pseudonym across
+// dashboards and exports while the real ID can't be recovered without the key; names and
+// emails are removed.
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+)
+
+// Anonymizer pseudonymizes analytics when enabled and passes them through unchanged otherwise
+type Anonymizer struct {
+	key []byte
+}
+
+// New returns an anonymizer keyed with key, or a pass-through one when enabled is false
+func New(enabled bool, key string) *Anonymizer {
+	if !enabled {
+		return &Anonymizer{}
+	}
+	return &Anonymizer{key: []byte(key)}
+}
+
+// Enabled reports whether analytics are anonymized
+func (a *Anonymizer) Enabled() bool {
+	return len(a.key) > 0
+}
+
+// ID replaces a user ID with its pseudonym, itself formatted as a UUID
+func (a *Anonymizer) ID(id uuid.UUID) uuid.UUID {
+	if !a.Enabled() {
+		return id
+	}
+	return uuid.NewHash(hmac.New(sha256.New, a.key), uuid.Nil, []byte(id.String()), 8)
+}
+
+// IDString is ID for IDs in text form, as in report rows. Text that is not a UUID is hashed
+// as is, so it never passes through.
+func (a *Anonymizer) IDString(id string) string {
+	if !a.Enabled() {
+		return id
+	}
+	if parsed, err := uuid.Parse(id); err == nil {
+		return a.ID(parsed).String()
+	}
+	return uuid.NewHash(hmac.New(sha256.New, a.key), uuid.Nil, []byte(id), 8).String()
+}
+
+// Text removes personal text such as names and emails
+func (a *Anonymizer) Text(s string) string {
+	if !a.Enabled() {
+		return s
+	}
+	return ""
+}
+
+// Usage anonymizes per-user API usage in place
+func (a *Anonymizer) Usage(users []models.UserUsage) {
+	for i := range users {
+		users[i].UserID = a.ID(users[i].UserID)
+		users[i].Email = a.Text(users[i].Email)
+		users[i].FullName = a.Text(users[i].FullName)
+	}
+}
+
+// ReviewAnalytics anonymizes per-instructor review metrics in place
+func (a *Anonymizer) ReviewAnalytics(analytics *models.ReviewAnalytics) {
+	for i := range analytics.Instructors {
+		instructor := &analytics.Instructors[i]
+		instructor.InstructorID = a.ID(instructor.InstructorID)
+		instructor.Email = a.Text(instructor.Email)
+		instructor.FullName = a.Text(instructor.FullName)
+	}
+}
+
+// AttendanceReport anonymizes per-student attendance in place
+func (a *Anonymizer) AttendanceReport(report *models.AttendanceReport) {
+	for i := range report.Students {
+		student := &report.Students[i]
+		student.UserID = a.ID(student.UserID)
+		student.Email = a.Text(student.Email)
+		student.FullName = a.Text(student.FullName)
+	}
+}
+
+// GroupStats anonymizes per-member group stats in place
+func (a *Anonymizer) GroupStats(stats *models.GroupStats) {
+	for i := range stats.Members {
+		member := &stats.Members[i]
+		member.UserID = a.ID(member.UserID)
+		member.Email = a.Text(member.Email)
+		member.FullName = a.Text(member.FullName)
+	}
+}
@@ -0,0 +1,57 @@
+package anonymize
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+)
+
+const testKey = "test-analytics-key-with-32-chars!"
+
+func TestID(t *testing.T) {
+	id := uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+	a := New(true, testKey)
+
+	pseudonym := a.ID(id)
+	if pseudonym == id {
+		t.Fatal("ID() returned the real ID")
+	}
+	if a.ID(id) != pseudonym {
+		t.Error("ID() is not stable")
+	}
+	if a.IDString(id.String()) != pseudonym.String() {
+		t.Error("IDString() differs from ID()")
+	}
+	if New(true, testKey+"x").ID(id) == pseudonym {
+		t.Error("ID() does not depend on the key")
+	}
+	if a.IDString("not-a-uuid") == "not-a-uuid" {
+		t.Error("IDString() passed non-UUID text through")
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	id := uuid.New()
+	a := New(false, testKey)
+
+	if a.Enabled() {
+		t.Error("Enabled() = true, want false")
+	}
+	if a.ID(id) != id || a.IDString(id.String()) != id.String() || a.Text("Mei Lin") != "Mei Lin" {
+		t.Error("disabled anonymizer changed values")
+	}
+}
+
+func TestUsage(t *testing.T) {
+	id := uuid.New()
+	users := []models.UserUsage{{UserID: id, Email: "mei@example.com", FullName: "Mei Lin", RequestCount: 12}}
+
+	New(true, testKey).Usage(users)
+	if users[0].UserID == id || users[0].Email != "" || users[0].FullName != "" {
+		t.Errorf("usage = %+v, want pseudonymous ID without email and name", users[0])
+	}
+	if users[0].RequestCount != 12 {
+		t.Errorf("request count = %d, want it kept", users[0].RequestCount)
+	}
+}
@@ -1,27 +1,64 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/netip"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+	"github.com/xuangong/backend/pkg/fieldcrypt"
+	"github.com/xuangong/backend/pkg/secrets"
+	"github.com/xuangong/backend/pkg/semver"
 )
 
 type Config struct {
-	Server    ServerConfig
-	Database  DatabaseConfig
-	JWT       JWTConfig
-	CORS      CORSConfig
-	RateLimit RateLimitConfig
-	Upload    UploadConfig
-	Logging   LoggingConfig
+	Server        ServerConfig
+	Database      DatabaseConfig
+	JWT           JWTConfig
+	CORS          CORSConfig
+	RateLimit     RateLimitConfig
+	Upload        UploadConfig
+	Logging       LoggingConfig
+	TTS           TTSConfig
+	Sessions      SessionsConfig
+	Journal       JournalConfig
+	Streaks       StreaksConfig
+	Invites       InvitesConfig
+	Shares        SharesConfig
+	Embed         EmbedConfig
+	TemplateCache TemplateCacheConfig
+	Bookings      BookingsConfig
+	CheckIn       CheckInConfig
+	Mail          MailConfig
+	Digest        DigestConfig
+	Analytics     AnalyticsConfig
+	Moderation    ModerationConfig
+	ContentFilter ContentFilterConfig
+	Messages      MessagesConfig
+	Presence      PresenceConfig
+	Meetings      MeetingsConfig
+	Features      FeaturesConfig
+	Dependencies  DependenciesConfig
+	Clients       ClientsConfig
+	Integrations  IntegrationsConfig
+	AdminAccess   AdminAccessConfig
+	Secrets       SecretsConfig
+	Encryption    EncryptionConfig
+	LoginAlerts   LoginAlertsConfig
+	Retention     RetentionConfig
 }
 
 type ServerConfig struct {
-	Port       string
-	Env        string
-	APIVersion string
+	Port          string
+	Env           string
+	APIVersion    string
+	MaxBodySizeKB int
+	// Proxies whose X-Forwarded-For is believed for the client IP; unset believes none and uses
+	// the connection's address
+	TrustedProxies []string
 }
 
 type DatabaseConfig struct {
@@ -29,12 +66,59 @@ type DatabaseConfig struct {
 	MaxConnections     int
 	MaxIdleConnections int
 	MaxLifetimeMinutes int
+	// Retries for transient errors on idempotent queries; 1 attempt disables retrying
+	RetryMaxAttempts int
+	RetryBaseDelayMs int
+	RetryMaxDelayMs  int
+	// Queries slower than this are logged with their parameters redacted; 0 disables it
+	SlowQueryMs int
 }
 
 type JWTConfig struct {
 	Secret            string
 	ExpiryHours       int
 	RefreshExpiryDays int
+	// Older secrets tokens are still accepted with while clients move to the current one
+	PreviousSecrets []string
+	// How often JWT_SECRET is re-read from a secret store, so it can be rotated without a
+	// restart; 0 disables it. Not used with the env provider.
+	SecretRefreshSeconds int
+}
+
+// SecretsConfig selects where the values in SecretNames are read from. The provider's own
+// settings, such as the Vault token, always come from the environment.
+type SecretsConfig struct {
+	Provider   string // env (default), file, vault or aws
+	FileDir    string // Directory with a file per secret, e.g. /run/secrets
+	VaultAddr  string
+	VaultToken string
+	VaultPath  string // KV secret path, e.g. secret/data/xuangong
+	AWSRegion  string
+	AWSSecret  string // Secrets Manager secret ID holding a JSON object
+	AWS        secrets.AWSCredentials
+}
+
+// SecretNames are the settings read through the secrets provider. Secrets the provider does
+// not have fall back to the environment.
+var SecretNames = []string{
+	"DATABASE_URL",
+	"JWT_SECRET",
+	"JWT_PREVIOUS_SECRETS",
+	"TTS_API_KEY",
+	"SMTP_PASSWORD",
+	"ANALYTICS_HASH_KEY",
+	"CONTENT_FILTER_API_KEY",
+	"ZOOM_CLIENT_SECRET",
+	"ENCRYPTION_KEY",
+	"ENCRYPTION_PREVIOUS_KEYS",
+}
+
+// EncryptionConfig holds the keys sensitive columns are encrypted with, each written as
+// <id>:<base64 of 32 bytes>. Without a key those columns are stored in plain text.
+type EncryptionConfig struct {
+	Key string
+	// Older keys still read while rows are re-encrypted with cmd/encryption
+	PreviousKeys []string
 }
 
 type CORSConfig struct {
@@ -49,13 +133,202 @@ type RateLimitConfig struct {
 }
 
 type UploadConfig struct {
-	MaxSizeMB  int
-	UploadPath string
+	MaxSizeMB    int
+	UploadPath   string
+	MediaBaseURL string
+}
+
+type SessionsConfig struct {
+	RestoreWindowHours int
+	PurgeAfterDays     int
+	ReconcileHour      int // UTC hour the nightly repetitions_completed reconciliation runs
+}
+
+// JournalConfig limits the form-check media students upload to their progress journal
+type JournalConfig struct {
+	MaxPhotoMB int
+	MaxVideoMB int
+}
+
+// StreaksConfig is the deployment's streak policy; users can be given overrides
+type StreaksConfig struct {
+	RestDaysPerWeek int
+	MinMinutes      int
+	GraceHours      int
+}
+
+type FeaturesConfig struct {
+	// OpenRegistration allows self-signup via POST /auth/register without an invitation
+	OpenRegistration bool
+}
+
+type InvitesConfig struct {
+	// SignupURL is the frontend registration page; the token is appended as ?invite=
+	SignupURL   string
+	DefaultDays int
+}
+
+type SharesConfig struct {
+	// URL is the frontend page for shared programs; the token is appended as ?token=
+	URL         string
+	DefaultDays int
+}
+
+// EmbedConfig configures the public widget API for embedding program previews on other sites
+type EmbedConfig struct {
+	// PageURL is the frontend widget page loaded in oEmbed iframes; the token is appended as ?token=
+	PageURL   string
+	RateLimit RateLimitConfig
+}
+
+// TemplateCacheConfig controls caching of the public template listing (GET /programs?is_public=true),
+// in process and by browsers and CDNs in front of the API
+type TemplateCacheConfig struct {
+	// TTLSeconds is how long a rendered listing is served from memory; 0 disables the in-process cache
+	TTLSeconds                  int
+	MaxAgeSeconds               int // Cache-Control max-age, for browsers
+	SharedMaxAgeSeconds         int // Cache-Control s-maxage, for CDNs and proxies
+	StaleWhileRevalidateSeconds int
+	// SurrogateKeyHeader carries the cache tags CDNs purge by, e.g. Surrogate-Key (Fastly) or
+	// Cache-Tag (Cloudflare); empty leaves it out
+	SurrogateKeyHeader string
+}
+
+// ClientsConfig is the default client version policy per platform. Admins can override it per
+// platform at runtime; requests without X-App-Version are never gated.
+type ClientsConfig struct {
+	MinVersions map[string]string // Platform (ios, android, web) to oldest supported version; unset allows all
+	UpgradeURLs map[string]string // Platform to where users get the new version, e.g. the store page
+	// RefreshSeconds is how often admin overrides are reloaded, so changes made on one instance
+	// reach the others
+	RefreshSeconds int
+}
+
+// IntegrationsConfig covers server-to-server integrations that sign their requests with a key
+// instead of signing in as a user
+type IntegrationsConfig struct {
+	// Signed requests are refused when their timestamp is further than this from the server's
+	// clock. Nonces only need to be remembered for this long.
+	SignatureToleranceSeconds int
+}
+
+// AdminAccessConfig restricts admin routes to known networks, such as the school's office and VPN
+type AdminAccessConfig struct {
+	AllowedNetworks []string // CIDRs or single IPs; unset allows admin requests from anywhere
+	// ReportOnly logs requests from other networks without refusing them, for trying out a list
+	ReportOnly bool
+}
+
+// LoginAlertsConfig covers alerting users to sign-ins from devices they have not used before
+type LoginAlertsConfig struct {
+	Enabled bool
+	// RevokeURL is the frontend page of the "this wasn't me" link; the token is appended as ?token=
+	RevokeURL string
+	// How often instances reload revoked devices, whose access tokens they refuse
+	RevocationRefreshSeconds int
+}
+
+// RetentionConfig sets how long data is kept. A daily job applies each rule; 0 turns a rule off.
+type RetentionConfig struct {
+	SessionYears    int // Practice sessions are deleted this many years after they started
+	SoftDeletedDays int // Deleted submissions, discussions and programs are purged after this many days
+	DeviceInfoDays  int // Device details of sessions, access logs and check-ins are stripped after this many days
+	// DryRun lists rules, or "all", that the daily job only reports on without changing anything
+	DryRun []string
+	Hour   int // UTC hour the daily job runs
+}
+
+type BookingsConfig struct {
+	// Students can cancel a booking up to this many hours before it starts; instructors any time
+	CancelNoticeHours int
+	MaxSlotMinutes    int
+}
+
+type CheckInConfig struct {
+	// QRURL is the frontend check-in page encoded into QR codes; the token is appended as ?token=
+	QRURL        string
+	QRTTLSeconds int
+}
+
+// MailConfig configures outgoing email; an empty SMTPHost disables sending
+type MailConfig struct {
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+}
+
+// DigestConfig sets when the weekly progress email goes out, in each user's own time zone
+type DigestConfig struct {
+	SendWeekday string // e.g. "monday"
+	SendHour    int
+}
+
+// AnalyticsConfig controls pseudonymization of admin dashboards and reports, for sharing
+// usage data with third parties under a data-processing agreement
+type AnalyticsConfig struct {
+	Anonymize bool
+	// HashKey keys the user ID hashes; keep it stable so pseudonyms match across exports
+	HashKey string
+}
+
+// ModerationConfig controls how abuse reports are handled before an admin looks at them
+type ModerationConfig struct {
+	// AutoHideReports hides reported content once this many users reported it; 0 never hides
+	AutoHideReports int
+}
+
+// MessagesConfig throttles message posting, mainly against clients that resend in a loop
+type MessagesConfig struct {
+	// RateLimit is how many messages a user can post per RateLimitSeconds; 0 is unlimited
+	RateLimit        int
+	RateLimitSeconds int
+	// A message identical to one the user posted in the same submission this many seconds ago
+	// is refused as a duplicate; 0 allows duplicates
+	DuplicateWindowSeconds int
+}
+
+// PresenceConfig decides when users count as online, based on their last API request
+type PresenceConfig struct {
+	OnlineSeconds int
+}
+
+// ContentFilterConfig selects the filter that screens new messages and program descriptions
+type ContentFilterConfig struct {
+	Provider string   // "wordlist" (default), "api" or "none"
+	Action   string   // "flag" opens a moderation case, "reject" refuses the content
+	Words    []string // For the wordlist filter; an empty list lets everything through
+	APIURL   string
+	APIKey   string
+}
+
+// MeetingsConfig selects the video-conferencing provider for bookings; an empty Provider disables meeting links
+type MeetingsConfig struct {
+	Provider         string // "jitsi" or "zoom"
+	JitsiURL         string
+	ZoomAccountID    string
+	ZoomClientID     string
+	ZoomClientSecret string
+}
+
+type TTSConfig struct {
+	Provider string
+	URL      string
+	APIKey   string
+}
+
+type DependenciesConfig struct {
+	// BreakerFailureThreshold is the number of consecutive failures that opens a circuit
+	BreakerFailureThreshold int
+	BreakerCooldownSeconds  int
 }
 
 type LoggingConfig struct {
 	Level  string
 	Format string
+	// Requests slower than this are logged; 0 disables it
+	SlowRequestMs int
 }
 
 // Load reads configuration from environment variables and .env files
@@ -75,22 +348,49 @@ func Load() (*Config, error) {
 	// Set defaults
 	setDefaults()
 
+	// Secrets may live in a secret store rather than the environment
+	secretsConfig := SecretsConfig{
+		Provider:   viper.GetString("SECRETS_PROVIDER"),
+		FileDir:    viper.GetString("SECRETS_FILE_DIR"),
+		VaultAddr:  viper.GetString("VAULT_ADDR"),
+		VaultToken: viper.GetString("VAULT_TOKEN"),
+		VaultPath:  viper.GetString("VAULT_SECRET_PATH"),
+		AWSRegion:  viper.GetString("AWS_REGION"),
+		AWSSecret:  viper.GetString("AWS_SECRET_ID"),
+		AWS: secrets.AWSCredentials{
+			AccessKeyID:     viper.GetString("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: viper.GetString("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    viper.GetString("AWS_SESSION_TOKEN"),
+		},
+	}
+	if err := loadSecrets(&secretsConfig); err != nil {
+		return nil, err
+	}
+
 	config := &Config{
 		Server: ServerConfig{
-			Port:       viper.GetString("PORT"),
-			Env:        viper.GetString("ENV"),
-			APIVersion: viper.GetString("API_VERSION"),
+			Port:           viper.GetString("PORT"),
+			Env:            viper.GetString("ENV"),
+			APIVersion:     viper.GetString("API_VERSION"),
+			MaxBodySizeKB:  viper.GetInt("MAX_REQUEST_BODY_KB"),
+			TrustedProxies: splitList(viper.GetString("TRUSTED_PROXIES")),
 		},
 		Database: DatabaseConfig{
 			URL:                viper.GetString("DATABASE_URL"),
 			MaxConnections:     viper.GetInt("DB_MAX_CONNECTIONS"),
 			MaxIdleConnections: viper.GetInt("DB_MAX_IDLE_CONNECTIONS"),
 			MaxLifetimeMinutes: viper.GetInt("DB_MAX_LIFETIME_MINUTES"),
+			RetryMaxAttempts:   viper.GetInt("DB_RETRY_MAX_ATTEMPTS"),
+			RetryBaseDelayMs:   viper.GetInt("DB_RETRY_BASE_DELAY_MS"),
+			RetryMaxDelayMs:    viper.GetInt("DB_RETRY_MAX_DELAY_MS"),
+			SlowQueryMs:        viper.GetInt("DB_SLOW_QUERY_MS"),
 		},
 		JWT: JWTConfig{
-			Secret:            viper.GetString("JWT_SECRET"),
-			ExpiryHours:       viper.GetInt("JWT_EXPIRY_HOURS"),
-			RefreshExpiryDays: viper.GetInt("REFRESH_TOKEN_EXPIRY_DAYS"),
+			Secret:               viper.GetString("JWT_SECRET"),
+			ExpiryHours:          viper.GetInt("JWT_EXPIRY_HOURS"),
+			RefreshExpiryDays:    viper.GetInt("REFRESH_TOKEN_EXPIRY_DAYS"),
+			PreviousSecrets:      splitList(viper.GetString("JWT_PREVIOUS_SECRETS")),
+			SecretRefreshSeconds: viper.GetInt("JWT_SECRET_REFRESH_SECONDS"),
 		},
 		CORS: CORSConfig{
 			AllowedOrigins: strings.Split(viper.GetString("ALLOWED_ORIGINS"), ","),
@@ -102,12 +402,147 @@ func Load() (*Config, error) {
 			DurationMinutes: viper.GetInt("RATE_LIMIT_DURATION_MINUTES"),
 		},
 		Upload: UploadConfig{
-			MaxSizeMB:  viper.GetInt("MAX_UPLOAD_SIZE_MB"),
-			UploadPath: viper.GetString("UPLOAD_PATH"),
+			MaxSizeMB:    viper.GetInt("MAX_UPLOAD_SIZE_MB"),
+			UploadPath:   viper.GetString("UPLOAD_PATH"),
+			MediaBaseURL: viper.GetString("MEDIA_BASE_URL"),
 		},
 		Logging: LoggingConfig{
-			Level:  viper.GetString("LOG_LEVEL"),
-			Format: viper.GetString("LOG_FORMAT"),
+			Level:         viper.GetString("LOG_LEVEL"),
+			Format:        viper.GetString("LOG_FORMAT"),
+			SlowRequestMs: viper.GetInt("SLOW_REQUEST_MS"),
+		},
+		TTS: TTSConfig{
+			Provider: viper.GetString("TTS_PROVIDER"),
+			URL:      viper.GetString("TTS_URL"),
+			APIKey:   viper.GetString("TTS_API_KEY"),
+		},
+		Sessions: SessionsConfig{
+			RestoreWindowHours: viper.GetInt("SESSION_RESTORE_WINDOW_HOURS"),
+			PurgeAfterDays:     viper.GetInt("SESSION_PURGE_AFTER_DAYS"),
+			ReconcileHour:      viper.GetInt("SESSION_RECONCILE_HOUR"),
+		},
+		Journal: JournalConfig{
+			MaxPhotoMB: viper.GetInt("JOURNAL_MAX_PHOTO_MB"),
+			MaxVideoMB: viper.GetInt("JOURNAL_MAX_VIDEO_MB"),
+		},
+		Streaks: StreaksConfig{
+			RestDaysPerWeek: viper.GetInt("STREAK_REST_DAYS_PER_WEEK"),
+			MinMinutes:      viper.GetInt("STREAK_MIN_MINUTES"),
+			GraceHours:      viper.GetInt("STREAK_GRACE_HOURS"),
+		},
+		Invites: InvitesConfig{
+			SignupURL:   viper.GetString("INVITE_SIGNUP_URL"),
+			DefaultDays: viper.GetInt("INVITE_EXPIRY_DAYS"),
+		},
+		Shares: SharesConfig{
+			URL:         viper.GetString("PROGRAM_SHARE_URL"),
+			DefaultDays: viper.GetInt("PROGRAM_SHARE_EXPIRY_DAYS"),
+		},
+		Embed: EmbedConfig{
+			PageURL: viper.GetString("EMBED_PAGE_URL"),
+			RateLimit: RateLimitConfig{
+				Requests:        viper.GetInt("EMBED_RATE_LIMIT_REQUESTS"),
+				DurationMinutes: viper.GetInt("EMBED_RATE_LIMIT_DURATION_MINUTES"),
+			},
+		},
+		TemplateCache: TemplateCacheConfig{
+			TTLSeconds:                  viper.GetInt("TEMPLATE_CACHE_TTL_SECONDS"),
+			MaxAgeSeconds:               viper.GetInt("TEMPLATE_CACHE_MAX_AGE_SECONDS"),
+			SharedMaxAgeSeconds:         viper.GetInt("TEMPLATE_CACHE_S_MAXAGE_SECONDS"),
+			StaleWhileRevalidateSeconds: viper.GetInt("TEMPLATE_CACHE_STALE_WHILE_REVALIDATE_SECONDS"),
+			SurrogateKeyHeader:          viper.GetString("TEMPLATE_CACHE_SURROGATE_KEY_HEADER"),
+		},
+		Bookings: BookingsConfig{
+			CancelNoticeHours: viper.GetInt("BOOKING_CANCEL_NOTICE_HOURS"),
+			MaxSlotMinutes:    viper.GetInt("BOOKING_MAX_SLOT_MINUTES"),
+		},
+		CheckIn: CheckInConfig{
+			QRURL:        viper.GetString("QR_CHECK_IN_URL"),
+			QRTTLSeconds: viper.GetInt("QR_TOKEN_TTL_SECONDS"),
+		},
+		Mail: MailConfig{
+			SMTPHost:     viper.GetString("SMTP_HOST"),
+			SMTPPort:     viper.GetInt("SMTP_PORT"),
+			SMTPUsername: viper.GetString("SMTP_USERNAME"),
+			SMTPPassword: viper.GetString("SMTP_PASSWORD"),
+			From:         viper.GetString("MAIL_FROM"),
+		},
+		Digest: DigestConfig{
+			SendWeekday: viper.GetString("DIGEST_SEND_WEEKDAY"),
+			SendHour:    viper.GetInt("DIGEST_SEND_HOUR"),
+		},
+		Analytics: AnalyticsConfig{
+			Anonymize: viper.GetBool("ANALYTICS_ANONYMIZE"),
+			HashKey:   viper.GetString("ANALYTICS_HASH_KEY"),
+		},
+		Moderation: ModerationConfig{
+			AutoHideReports: viper.GetInt("MODERATION_AUTO_HIDE_REPORTS"),
+		},
+		Messages: MessagesConfig{
+			RateLimit:              viper.GetInt("MESSAGE_RATE_LIMIT"),
+			RateLimitSeconds:       viper.GetInt("MESSAGE_RATE_LIMIT_SECONDS"),
+			DuplicateWindowSeconds: viper.GetInt("MESSAGE_DUPLICATE_WINDOW_SECONDS"),
+		},
+		Presence: PresenceConfig{
+			OnlineSeconds: viper.GetInt("PRESENCE_ONLINE_SECONDS"),
+		},
+		ContentFilter: ContentFilterConfig{
+			Provider: viper.GetString("CONTENT_FILTER_PROVIDER"),
+			Action:   viper.GetString("CONTENT_FILTER_ACTION"),
+			Words:    splitList(viper.GetString("CONTENT_FILTER_WORDS")),
+			APIURL:   viper.GetString("CONTENT_FILTER_API_URL"),
+			APIKey:   viper.GetString("CONTENT_FILTER_API_KEY"),
+		},
+		Meetings: MeetingsConfig{
+			Provider:         viper.GetString("MEETING_PROVIDER"),
+			JitsiURL:         viper.GetString("JITSI_URL"),
+			ZoomAccountID:    viper.GetString("ZOOM_ACCOUNT_ID"),
+			ZoomClientID:     viper.GetString("ZOOM_CLIENT_ID"),
+			ZoomClientSecret: viper.GetString("ZOOM_CLIENT_SECRET"),
+		},
+		Features: FeaturesConfig{
+			OpenRegistration: viper.GetBool("OPEN_REGISTRATION"),
+		},
+		Dependencies: DependenciesConfig{
+			BreakerFailureThreshold: viper.GetInt("BREAKER_FAILURE_THRESHOLD"),
+			BreakerCooldownSeconds:  viper.GetInt("BREAKER_COOLDOWN_SECONDS"),
+		},
+		Clients: ClientsConfig{
+			MinVersions: map[string]string{
+				"ios":     viper.GetString("CLIENT_MIN_VERSION_IOS"),
+				"android": viper.GetString("CLIENT_MIN_VERSION_ANDROID"),
+				"web":     viper.GetString("CLIENT_MIN_VERSION_WEB"),
+			},
+			UpgradeURLs: map[string]string{
+				"ios":     viper.GetString("CLIENT_UPGRADE_URL_IOS"),
+				"android": viper.GetString("CLIENT_UPGRADE_URL_ANDROID"),
+				"web":     viper.GetString("CLIENT_UPGRADE_URL_WEB"),
+			},
+			RefreshSeconds: viper.GetInt("CLIENT_POLICY_REFRESH_SECONDS"),
+		},
+		Secrets: secretsConfig,
+		AdminAccess: AdminAccessConfig{
+			AllowedNetworks: splitList(viper.GetString("ADMIN_IP_ALLOWLIST")),
+			ReportOnly:      viper.GetBool("ADMIN_IP_ALLOWLIST_REPORT_ONLY"),
+		},
+		Encryption: EncryptionConfig{
+			Key:          viper.GetString("ENCRYPTION_KEY"),
+			PreviousKeys: splitList(viper.GetString("ENCRYPTION_PREVIOUS_KEYS")),
+		},
+		Integrations: IntegrationsConfig{
+			SignatureToleranceSeconds: viper.GetInt("INTEGRATION_SIGNATURE_TOLERANCE_SECONDS"),
+		},
+		LoginAlerts: LoginAlertsConfig{
+			Enabled:                  viper.GetBool("LOGIN_ALERTS_ENABLED"),
+			RevokeURL:                viper.GetString("LOGIN_ALERT_REVOKE_URL"),
+			RevocationRefreshSeconds: viper.GetInt("LOGIN_REVOCATION_REFRESH_SECONDS"),
+		},
+		Retention: RetentionConfig{
+			SessionYears:    viper.GetInt("RETENTION_SESSION_YEARS"),
+			SoftDeletedDays: viper.GetInt("RETENTION_SOFT_DELETED_DAYS"),
+			DeviceInfoDays:  viper.GetInt("RETENTION_DEVICE_INFO_DAYS"),
+			DryRun:          splitList(viper.GetString("RETENTION_DRY_RUN")),
+			Hour:            viper.GetInt("RETENTION_HOUR"),
 		},
 	}
 
@@ -122,20 +557,78 @@ func setDefaults() {
 	viper.SetDefault("PORT", "8080")
 	viper.SetDefault("ENV", "development")
 	viper.SetDefault("API_VERSION", "v1")
+	viper.SetDefault("MAX_REQUEST_BODY_KB", 1024)
 	viper.SetDefault("DB_MAX_CONNECTIONS", 25)
 	viper.SetDefault("DB_MAX_IDLE_CONNECTIONS", 5)
 	viper.SetDefault("DB_MAX_LIFETIME_MINUTES", 5)
+	viper.SetDefault("DB_RETRY_MAX_ATTEMPTS", 3)
+	viper.SetDefault("DB_RETRY_BASE_DELAY_MS", 50)
+	viper.SetDefault("DB_RETRY_MAX_DELAY_MS", 1000)
+	viper.SetDefault("DB_SLOW_QUERY_MS", 200)
 	viper.SetDefault("JWT_EXPIRY_HOURS", 336) // 14 days
 	viper.SetDefault("REFRESH_TOKEN_EXPIRY_DAYS", 7)
+	viper.SetDefault("JWT_SECRET_REFRESH_SECONDS", 300)
+	viper.SetDefault("SECRETS_PROVIDER", "env")
 	viper.SetDefault("ALLOWED_ORIGINS", "*")
 	viper.SetDefault("ALLOWED_METHODS", "GET,POST,PUT,DELETE,OPTIONS")
-	viper.SetDefault("ALLOWED_HEADERS", "Content-Type,Authorization")
+	viper.SetDefault("ALLOWED_HEADERS", "Content-Type,Authorization,X-App-Version,X-App-Platform")
 	viper.SetDefault("RATE_LIMIT_REQUESTS", 100)
 	viper.SetDefault("RATE_LIMIT_DURATION_MINUTES", 1)
 	viper.SetDefault("MAX_UPLOAD_SIZE_MB", 500)
 	viper.SetDefault("UPLOAD_PATH", "./uploads")
+	viper.SetDefault("MEDIA_BASE_URL", "/media")
+	viper.SetDefault("SESSION_RESTORE_WINDOW_HOURS", 24)
+	viper.SetDefault("SESSION_PURGE_AFTER_DAYS", 30)
+	viper.SetDefault("SESSION_RECONCILE_HOUR", 3)
+	viper.SetDefault("JOURNAL_MAX_PHOTO_MB", 10)
+	viper.SetDefault("JOURNAL_MAX_VIDEO_MB", 200)
+	viper.SetDefault("STREAK_REST_DAYS_PER_WEEK", 0)
+	viper.SetDefault("STREAK_MIN_MINUTES", 0)
+	viper.SetDefault("STREAK_GRACE_HOURS", 0)
+	viper.SetDefault("INVITE_EXPIRY_DAYS", 14)
+	viper.SetDefault("PROGRAM_SHARE_URL", "http://localhost:3000/shared")
+	viper.SetDefault("PROGRAM_SHARE_EXPIRY_DAYS", 30)
+	viper.SetDefault("EMBED_PAGE_URL", "http://localhost:3000/embed")
+	viper.SetDefault("EMBED_RATE_LIMIT_REQUESTS", 30)
+	viper.SetDefault("EMBED_RATE_LIMIT_DURATION_MINUTES", 1)
+	viper.SetDefault("TEMPLATE_CACHE_TTL_SECONDS", 300)
+	viper.SetDefault("TEMPLATE_CACHE_MAX_AGE_SECONDS", 60)
+	viper.SetDefault("TEMPLATE_CACHE_S_MAXAGE_SECONDS", 300)
+	viper.SetDefault("TEMPLATE_CACHE_STALE_WHILE_REVALIDATE_SECONDS", 600)
+	viper.SetDefault("TEMPLATE_CACHE_SURROGATE_KEY_HEADER", "Surrogate-Key")
+	viper.SetDefault("BOOKING_CANCEL_NOTICE_HOURS", 24)
+	viper.SetDefault("BOOKING_MAX_SLOT_MINUTES", 120)
+	viper.SetDefault("QR_CHECK_IN_URL", "http://localhost:3000/check-in")
+	viper.SetDefault("QR_TOKEN_TTL_SECONDS", 60)
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("DIGEST_SEND_WEEKDAY", "monday")
+	viper.SetDefault("DIGEST_SEND_HOUR", 8)
+	viper.SetDefault("ANALYTICS_ANONYMIZE", false)
+	viper.SetDefault("MODERATION_AUTO_HIDE_REPORTS", 3)
+	viper.SetDefault("CONTENT_FILTER_PROVIDER", "wordlist")
+	viper.SetDefault("MESSAGE_RATE_LIMIT", 10)
+	viper.SetDefault("MESSAGE_RATE_LIMIT_SECONDS", 60)
+	viper.SetDefault("MESSAGE_DUPLICATE_WINDOW_SECONDS", 60)
+	viper.SetDefault("PRESENCE_ONLINE_SECONDS", 300)
+	viper.SetDefault("CONTENT_FILTER_ACTION", "flag")
+	viper.SetDefault("JITSI_URL", "https://meet.jit.si")
+	viper.SetDefault("OPEN_REGISTRATION", true)
+	viper.SetDefault("BREAKER_FAILURE_THRESHOLD", 5)
+	viper.SetDefault("BREAKER_COOLDOWN_SECONDS", 30)
+	viper.SetDefault("CLIENT_POLICY_REFRESH_SECONDS", 30)
+	viper.SetDefault("INTEGRATION_SIGNATURE_TOLERANCE_SECONDS", 300)
+	viper.SetDefault("ADMIN_IP_ALLOWLIST_REPORT_ONLY", false)
+	viper.SetDefault("LOGIN_ALERTS_ENABLED", true)
+	viper.SetDefault("LOGIN_ALERT_REVOKE_URL", "http://localhost:3000/security/revoke")
+	viper.SetDefault("LOGIN_REVOCATION_REFRESH_SECONDS", 30)
+	viper.SetDefault("RETENTION_SESSION_YEARS", 0)
+	viper.SetDefault("RETENTION_SOFT_DELETED_DAYS", 0)
+	viper.SetDefault("RETENTION_DEVICE_INFO_DAYS", 90)
+	viper.SetDefault("RETENTION_DRY_RUN", "")
+	viper.SetDefault("RETENTION_HOUR", 4)
 	viper.SetDefault("LOG_LEVEL", "info")
 	viper.SetDefault("LOG_FORMAT", "json")
+	viper.SetDefault("SLOW_REQUEST_MS", 1000)
 }
 
 func validate(config *Config) error {
@@ -148,6 +641,84 @@ func validate(config *Config) error {
 	if len(config.JWT.Secret) < 32 {
 		return fmt.Errorf("JWT_SECRET must be at least 32 characters")
 	}
+	if config.JWT.SecretRefreshSeconds < 0 {
+		return fmt.Errorf("JWT_SECRET_REFRESH_SECONDS must not be negative")
+	}
+	if config.Sessions.ReconcileHour < 0 || config.Sessions.ReconcileHour > 23 {
+		return fmt.Errorf("SESSION_RECONCILE_HOUR must be between 0 and 23")
+	}
+	if config.Streaks.RestDaysPerWeek < 0 || config.Streaks.RestDaysPerWeek > 6 {
+		return fmt.Errorf("STREAK_REST_DAYS_PER_WEEK must be between 0 and 6")
+	}
+	if config.Streaks.MinMinutes < 0 || config.Streaks.MinMinutes > 1440 {
+		return fmt.Errorf("STREAK_MIN_MINUTES must be between 0 and 1440")
+	}
+	if config.Streaks.GraceHours < 0 || config.Streaks.GraceHours > 12 {
+		return fmt.Errorf("STREAK_GRACE_HOURS must be between 0 and 12")
+	}
+	if config.TemplateCache.TTLSeconds < 0 || config.TemplateCache.MaxAgeSeconds < 0 ||
+		config.TemplateCache.SharedMaxAgeSeconds < 0 || config.TemplateCache.StaleWhileRevalidateSeconds < 0 {
+		return fmt.Errorf("TEMPLATE_CACHE_* durations must not be negative")
+	}
+	if _, ok := parseWeekday(config.Digest.SendWeekday); !ok {
+		return fmt.Errorf("DIGEST_SEND_WEEKDAY must be a day of the week, got %q", config.Digest.SendWeekday)
+	}
+	if config.Digest.SendHour < 0 || config.Digest.SendHour > 23 {
+		return fmt.Errorf("DIGEST_SEND_HOUR must be between 0 and 23")
+	}
+	if config.Analytics.Anonymize && len(config.Analytics.HashKey) < 32 {
+		return fmt.Errorf("ANALYTICS_HASH_KEY must be at least 32 characters when ANALYTICS_ANONYMIZE is on")
+	}
+	if config.Moderation.AutoHideReports < 0 {
+		return fmt.Errorf("MODERATION_AUTO_HIDE_REPORTS must not be negative")
+	}
+	if config.Messages.RateLimit > 0 && config.Messages.RateLimitSeconds <= 0 {
+		return fmt.Errorf("MESSAGE_RATE_LIMIT_SECONDS must be positive when MESSAGE_RATE_LIMIT is set")
+	}
+	if config.Presence.OnlineSeconds <= 0 {
+		return fmt.Errorf("PRESENCE_ONLINE_SECONDS must be positive")
+	}
+	if config.ContentFilter.Action != "flag" && config.ContentFilter.Action != "reject" {
+		return fmt.Errorf("CONTENT_FILTER_ACTION must be flag or reject, got %q", config.ContentFilter.Action)
+	}
+	for platform, version := range config.Clients.MinVersions {
+		if _, err := semver.Parse(version); version != "" && err != nil {
+			return fmt.Errorf("CLIENT_MIN_VERSION_%s must be a version like 2.4.0, got %q", strings.ToUpper(platform), version)
+		}
+	}
+	if config.Clients.RefreshSeconds <= 0 {
+		return fmt.Errorf("CLIENT_POLICY_REFRESH_SECONDS must be positive")
+	}
+	for _, network := range config.AdminAccess.AllowedNetworks {
+		if _, err := parseNetwork(network); err != nil {
+			return fmt.Errorf("ADMIN_IP_ALLOWLIST must list CIDRs or IPs, got %q", network)
+		}
+	}
+	if _, err := config.Encryption.NewKeyring(); err != nil {
+		return fmt.Errorf("ENCRYPTION_KEY or ENCRYPTION_PREVIOUS_KEYS is invalid: %w", err)
+	}
+	for _, proxy := range config.Server.TrustedProxies {
+		if _, err := parseNetwork(proxy); err != nil {
+			return fmt.Errorf("TRUSTED_PROXIES must list CIDRs or IPs, got %q", proxy)
+		}
+	}
+	if config.Integrations.SignatureToleranceSeconds <= 0 {
+		return fmt.Errorf("INTEGRATION_SIGNATURE_TOLERANCE_SECONDS must be positive")
+	}
+	if config.LoginAlerts.RevocationRefreshSeconds <= 0 {
+		return fmt.Errorf("LOGIN_REVOCATION_REFRESH_SECONDS must be positive")
+	}
+	if config.Retention.SessionYears < 0 || config.Retention.SoftDeletedDays < 0 || config.Retention.DeviceInfoDays < 0 {
+		return fmt.Errorf("RETENTION_SESSION_YEARS, RETENTION_SOFT_DELETED_DAYS and RETENTION_DEVICE_INFO_DAYS must not be negative")
+	}
+	for _, rule := range config.Retention.DryRun {
+		if rule != "sessions" && rule != "soft_deleted" && rule != "device_info" && rule != "all" {
+			return fmt.Errorf("RETENTION_DRY_RUN must list sessions, soft_deleted, device_info or all, got %q", rule)
+		}
+	}
+	if config.Retention.Hour < 0 || config.Retention.Hour > 23 {
+		return fmt.Errorf("RETENTION_HOUR must be between 0 and 23")
+	}
 	return nil
 }
 
@@ -161,7 +732,223 @@ func (c *JWTConfig) GetRefreshExpiry() time.Duration {
 	return time.Duration(c.RefreshExpiryDays) * 24 * time.Hour
 }
 
+// GetSecretRefreshInterval returns how often JWT_SECRET is re-read from the secrets provider
+func (c *JWTConfig) GetSecretRefreshInterval() time.Duration {
+	return time.Duration(c.SecretRefreshSeconds) * time.Second
+}
+
 // GetRateLimitDuration returns rate limit duration
 func (c *RateLimitConfig) GetDuration() time.Duration {
 	return time.Duration(c.DurationMinutes) * time.Minute
 }
+
+// GetTTL returns how long a rendered template listing is served from memory
+func (c *TemplateCacheConfig) GetTTL() time.Duration {
+	return time.Duration(c.TTLSeconds) * time.Second
+}
+
+// GetRestoreWindow returns how long a student can undo a session deletion
+// GetMaxBodyBytes returns the size limit for non-upload request bodies
+func (c *ServerConfig) GetMaxBodyBytes() int64 {
+	return int64(c.MaxBodySizeKB) << 10
+}
+
+// GetMaxUploadBytes returns the size limit for multipart upload bodies
+func (c *UploadConfig) GetMaxUploadBytes() int64 {
+	return int64(c.MaxSizeMB) << 20
+}
+
+// GetMaxBytes returns the size limit for journal media of the given type
+func (c *JournalConfig) GetMaxBytes(video bool) int64 {
+	if video {
+		return int64(c.MaxVideoMB) << 20
+	}
+	return int64(c.MaxPhotoMB) << 20
+}
+
+func (c *SessionsConfig) GetRestoreWindow() time.Duration {
+	return time.Duration(c.RestoreWindowHours) * time.Hour
+}
+
+// GetPurgeAfter returns how long soft-deleted sessions are kept before being purged
+func (c *SessionsConfig) GetPurgeAfter() time.Duration {
+	return time.Duration(c.PurgeAfterDays) * 24 * time.Hour
+}
+
+// GetCancelNotice returns how long before a booking students can still cancel it
+func (c *BookingsConfig) GetCancelNotice() time.Duration {
+	return time.Duration(c.CancelNoticeHours) * time.Hour
+}
+
+// GetMaxSlotLength returns the longest availability slot instructors can publish
+func (c *BookingsConfig) GetMaxSlotLength() time.Duration {
+	return time.Duration(c.MaxSlotMinutes) * time.Minute
+}
+
+// GetQRTokenTTL returns how long a displayed QR check-in code stays valid
+func (c *CheckInConfig) GetQRTokenTTL() time.Duration {
+	return time.Duration(c.QRTTLSeconds) * time.Second
+}
+
+// GetSendWeekday returns the day of the week digests are sent on
+func (c *DigestConfig) GetSendWeekday() time.Weekday {
+	weekday, _ := parseWeekday(c.SendWeekday)
+	return weekday
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), name) {
+			return d, true
+		}
+	}
+	return time.Sunday, false
+}
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadSecrets reads SecretNames from the configured provider into viper, so they are picked up
+// like any other setting. With the env provider there is nothing to do.
+func loadSecrets(cfg *SecretsConfig) error {
+	provider, err := cfg.NewProvider()
+	if err != nil {
+		return err
+	}
+	if cfg.Provider == "env" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, name := range SecretNames {
+		value, err := provider.Get(ctx, name)
+		if errors.Is(err, secrets.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s from the %s secrets provider: %w", name, cfg.Provider, err)
+		}
+		viper.Set(name, value)
+	}
+	return nil
+}
+
+// NewProvider returns the secrets provider selected by SECRETS_PROVIDER
+func (c *SecretsConfig) NewProvider() (secrets.Provider, error) {
+	switch c.Provider {
+	case "env":
+		return secrets.NewEnv(func(name string) (string, bool) {
+			return viper.GetString(name), viper.IsSet(name)
+		}), nil
+	case "file":
+		if c.FileDir == "" {
+			return nil, fmt.Errorf("SECRETS_FILE_DIR is required for the file secrets provider")
+		}
+		return secrets.NewFile(c.FileDir), nil
+	case "vault":
+		if c.VaultAddr == "" || c.VaultToken == "" || c.VaultPath == "" {
+			return nil, fmt.Errorf("VAULT_ADDR, VAULT_TOKEN and VAULT_SECRET_PATH are required for the vault secrets provider")
+		}
+		return secrets.NewVault(c.VaultAddr, c.VaultToken, c.VaultPath), nil
+	case "aws":
+		if c.AWSRegion == "" || c.AWSSecret == "" || c.AWS.AccessKeyID == "" || c.AWS.SecretAccessKey == "" {
+			return nil, fmt.Errorf("AWS_REGION, AWS_SECRET_ID, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for the aws secrets provider")
+		}
+		return secrets.NewAWS(c.AWSRegion, c.AWSSecret, c.AWS), nil
+	default:
+		return nil, fmt.Errorf("SECRETS_PROVIDER must be env, file, vault or aws, got %q", c.Provider)
+	}
+}
+
+// parseNetwork parses a CIDR, or a single IP as the network of just that address
+func parseNetwork(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// GetAllowedNetworks returns the parsed ADMIN_IP_ALLOWLIST; entries were checked on load
+func (c *AdminAccessConfig) GetAllowedNetworks() []netip.Prefix {
+	networks := make([]netip.Prefix, 0, len(c.AllowedNetworks))
+	for _, network := range c.AllowedNetworks {
+		if prefix, err := parseNetwork(network); err == nil {
+			networks = append(networks, prefix)
+		}
+	}
+	return networks
+}
+
+// GetRateLimitWindow returns the window MESSAGE_RATE_LIMIT applies to
+func (c *MessagesConfig) GetRateLimitWindow() time.Duration {
+	return time.Duration(c.RateLimitSeconds) * time.Second
+}
+
+// GetDuplicateWindow returns how long an identical message counts as a duplicate
+func (c *MessagesConfig) GetDuplicateWindow() time.Duration {
+	return time.Duration(c.DuplicateWindowSeconds) * time.Second
+}
+
+// GetOnlineWindow returns how recently a user must have made a request to count as online
+func (c *PresenceConfig) GetOnlineWindow() time.Duration {
+	return time.Duration(c.OnlineSeconds) * time.Second
+}
+
+// GetBreakerCooldown returns how long an open circuit waits before letting a trial call through
+func (c *DependenciesConfig) GetBreakerCooldown() time.Duration {
+	return time.Duration(c.BreakerCooldownSeconds) * time.Second
+}
+
+// GetRefreshInterval returns how often admin overrides of the client version policy are reloaded
+func (c *ClientsConfig) GetRefreshInterval() time.Duration {
+	return time.Duration(c.RefreshSeconds) * time.Second
+}
+
+// GetRevocationRefreshInterval returns how often revoked login devices are reloaded
+func (c *LoginAlertsConfig) GetRevocationRefreshInterval() time.Duration {
+	return time.Duration(c.RevocationRefreshSeconds) * time.Second
+}
+
+// IsDryRun reports whether the daily job only reports what the rule would change
+func (c *RetentionConfig) IsDryRun(rule string) bool {
+	for _, name := range c.DryRun {
+		if name == rule || name == "all" {
+			return true
+		}
+	}
+	return false
+}
+
+// NewKeyring returns the keyring for encrypted columns, or nil if no key is configured
+func (c *EncryptionConfig) NewKeyring() (*fieldcrypt.Keyring, error) {
+	return fieldcrypt.NewKeyring(c.Key, c.PreviousKeys)
+}
+
+// GetSignatureTolerance returns how far a signed request's timestamp may be from now
+func (c *IntegrationsConfig) GetSignatureTolerance() time.Duration {
+	return time.Duration(c.SignatureToleranceSeconds) * time.Second
+}
+
+// GetSlowQueryThreshold returns the duration above which queries are logged as slow
+func (c *DatabaseConfig) GetSlowQueryThreshold() time.Duration {
+	return time.Duration(c.SlowQueryMs) * time.Millisecond
+}
+
+// GetSlowRequestThreshold returns the duration above which requests are logged as slow
+func (c *LoggingConfig) GetSlowRequestThreshold() time.Duration {
+	return time.Duration(c.SlowRequestMs) * time.Millisecond
+}
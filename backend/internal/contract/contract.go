@@ -0,0 +1,306 @@
+// Package contract generates a JSON Schema of the API response types from the models and
+// detects changes that would break existing mobile clients (removed, renamed or retyped fields).
+package contract
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/auth"
+)
+
+// Version of the response contract. Bump it when a breaking change is intentional, so
+// clients can tell which contract they were built against.
+const Version = 1
+
+// responseTypes lists the types returned by the API. Nested types are included automatically.
+var responseTypes = []any{
+	auth.TokenPair{},
+	models.UserResponse{},
+	models.Program{},
+	models.ProgramWithExercises{},
+	models.ProgramCreateResult{},
+	models.UserProgram{},
+	models.Exercise{},
+	models.ExerciseSubstitute{},
+	models.Limitations{},
+	models.AdjustedPlan{},
+	models.PlanReview{},
+	models.PlanReviewQueueItem{},
+	models.AssignmentReport{},
+	models.JournalEntry{},
+	models.DiaryEntry{},
+	models.DiarySearchResult{},
+	models.SupportTicket{},
+	models.SupportMessage{},
+	models.SupportTicketWithMessages{},
+	models.ChangelogEntry{},
+	models.ClientVersionPolicy{},
+	models.IntegrationKey{},
+	models.IntegrationProgram{},
+	models.LoginDevice{},
+	models.PracticeSession{},
+	models.SessionWithLogs{},
+	models.SessionStats{},
+	models.RepetitionProgress{},
+	models.ProgramAdoption{},
+	models.ProgramExperiment{},
+	models.ExperimentParticipant{},
+	models.ExperimentResults{},
+	models.RepetitionReconciliation{},
+	models.RetentionRule{},
+	models.RetentionRun{},
+	models.StudentOverview{},
+	models.StudentAppState{},
+	models.SessionNote{},
+	models.SessionEdit{},
+	models.BiometricSample{},
+	models.Submission{},
+	models.SubmissionWithMessages{},
+	models.SubmissionListItem{},
+	models.SubmissionSearchResult{},
+	models.MessageWithAuthor{},
+	models.SubmissionDraft{},
+	models.SubmissionTemplate{},
+	models.SessionQuestionnaire{},
+	models.QuestionnaireResults{},
+	models.TypingUser{},
+	models.InstructorPresence{},
+	models.SubmissionLabel{},
+	models.FeedbackSnippet{},
+	models.DiscussionTopic{},
+	models.DiscussionTopicWithReplies{},
+	models.AvailabilitySlot{},
+	models.Booking{},
+	models.Course{},
+	models.CourseEnrollment{},
+	models.CourseProgress{},
+	models.Quiz{},
+	models.QuizAttempt{},
+	models.Homework{},
+	models.HomeworkDetail{},
+	models.HomeworkReport{},
+	models.LiveClass{},
+	models.ClassAttendance{},
+	models.CheckInCode{},
+	models.AttendanceReport{},
+	models.QRCode{},
+	models.QRCheckInResult{},
+	models.ScheduledMessage{},
+	models.UnreadCounts{},
+	models.Notification{},
+	models.NotificationPreferences{},
+	models.WeeklyDigest{},
+	models.Invitation{},
+	models.DisplayToken{},
+	models.ProgramShareLink{},
+	models.SharedProgram{},
+	models.ProgramPreview{},
+	models.OEmbed{},
+	models.DisplayProgram{},
+	models.Group{},
+	models.GroupStats{},
+	models.Timeline{},
+	models.Translation{},
+	models.MetadataSchema{},
+	models.UserUsage{},
+	models.ReviewAnalytics{},
+	models.QuotaPlan{},
+	models.UserQuota{},
+	models.UserStreakPolicy{},
+	models.StatsRecompute{},
+	models.ModerationCase{},
+	models.ModerationReport{},
+}
+
+// Document is a JSON Schema (draft 2020-12) with one definition per response type
+type Document struct {
+	Schema  string             `json:"$schema"`
+	Title   string             `json:"title"`
+	Version int                `json:"version"`
+	Defs    map[string]*Schema `json:"$defs"`
+}
+
+// Schema is the subset of JSON Schema needed to describe Go types
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(uuid.UUID{})
+)
+
+// Generate builds the schema document for all response types
+func Generate() *Document {
+	doc := &Document{
+		Schema:  "https://json-schema.org/draft/2020-12/schema",
+		Title:   "Xuan Gong API response types",
+		Version: Version,
+		Defs:    make(map[string]*Schema),
+	}
+	for _, v := range responseTypes {
+		doc.define(reflect.TypeOf(v))
+	}
+	return doc
+}
+
+// define adds a struct type to $defs and returns a reference to it
+func (d *Document) define(t reflect.Type) *Schema {
+	ref := &Schema{Ref: "#/$defs/" + t.Name()}
+	if _, ok := d.Defs[t.Name()]; ok {
+		return ref
+	}
+
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	// Reserve the name first so self-referencing types terminate
+	d.Defs[t.Name()] = s
+	d.addFields(s, t)
+	sort.Strings(s.Required)
+	return ref
+}
+
+// addFields adds the JSON fields of struct t, flattening embedded structs like encoding/json does
+func (d *Document) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				d.addFields(s, embedded)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = d.schemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func (d *Document) schemaFor(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return &Schema{AnyOf: []*Schema{d.schemaFor(t.Elem()), {Type: "null"}}}
+	case reflect.Struct:
+		return d.define(t)
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaFor(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		// interface{} and anything else: any JSON value
+		return &Schema{}
+	}
+}
+
+// Breaking lists the changes from published to current that can break a client built against
+// published: removed types, removed or renamed fields, changed field types and fields that
+// are no longer always present. New types and fields are not breaking.
+func Breaking(published, current *Document) []string {
+	var changes []string
+	for _, name := range sortedKeys(published.Defs) {
+		old := published.Defs[name]
+		cur, ok := current.Defs[name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s: type removed", name))
+			continue
+		}
+
+		required := make(map[string]bool, len(cur.Required))
+		for _, field := range cur.Required {
+			required[field] = true
+		}
+
+		for _, field := range sortedKeys(old.Properties) {
+			newField, ok := cur.Properties[field]
+			if !ok {
+				changes = append(changes, fmt.Sprintf("%s.%s: field removed or renamed", name, field))
+				continue
+			}
+			if was, is := describe(old.Properties[field]), describe(newField); was != is {
+				changes = append(changes, fmt.Sprintf("%s.%s: type changed from %s to %s", name, field, was, is))
+			}
+		}
+		for _, field := range old.Required {
+			if _, ok := cur.Properties[field]; ok && !required[field] {
+				changes = append(changes, fmt.Sprintf("%s.%s: field is no longer always present", name, field))
+			}
+		}
+	}
+	return changes
+}
+
+// describe renders a schema as a short type expression, e.g. "array<#/$defs/Exercise>|null"
+func describe(s *Schema) string {
+	switch {
+	case s.Ref != "":
+		return s.Ref
+	case len(s.AnyOf) > 0:
+		parts := make([]string, len(s.AnyOf))
+		for i, alt := range s.AnyOf {
+			parts[i] = describe(alt)
+		}
+		return strings.Join(parts, "|")
+	case s.Type == "array":
+		return "array<" + describe(s.Items) + ">"
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		return "map<" + describe(s.AdditionalProperties) + ">"
+	case s.Format != "":
+		return s.Type + "(" + s.Format + ")"
+	case s.Type == "":
+		return "any"
+	default:
+		return s.Type
+	}
+}
+
+func sortedKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,124 @@
+package contract
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// publishedPath is the schema the mobile clients are built against
+const publishedPath = "../../contract/schema.json"
+
+var update = flag.Bool("update", false, "rewrite the published schema from the models")
+
+func TestPublishedContract(t *testing.T) {
+	current := Generate()
+
+	if *update {
+		data, err := json.MarshalIndent(current, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(publishedPath, append(data, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(publishedPath)
+	if err != nil {
+		t.Fatalf("Failed to read published schema: %v", err)
+	}
+	var published Document
+	if err := json.Unmarshal(data, &published); err != nil {
+		t.Fatalf("Failed to parse published schema: %v", err)
+	}
+
+	if changes := Breaking(&published, current); len(changes) > 0 && current.Version <= published.Version {
+		t.Fatalf("Response types changed in a way that breaks clients built against contract v%d:\n  %s\n"+
+			"Restore the fields, or bump contract.Version and run `go test ./internal/contract -update`.",
+			published.Version, strings.Join(changes, "\n  "))
+	}
+	// Compare as JSON so empty and missing collections are treated alike
+	want, _ := json.Marshal(current)
+	got, _ := json.Marshal(&published)
+	if string(got) != string(want) {
+		t.Fatal("Published schema is out of date; run `go test ./internal/contract -update` and commit contract/schema.json")
+	}
+}
+
+func TestBreaking(t *testing.T) {
+	published := &Document{Defs: map[string]*Schema{
+		"Session": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":         {Type: "string", Format: "uuid"},
+				"notes":      {Type: "string"},
+				"mood":       {AnyOf: []*Schema{{Type: "integer"}, {Type: "null"}}},
+				"started_at": {Type: "string", Format: "date-time"},
+			},
+			Required: []string{"id", "notes", "started_at"},
+		},
+		"Legacy": {Type: "object"},
+	}}
+	current := &Document{Defs: map[string]*Schema{
+		"Session": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":         {Type: "string", Format: "uuid"},
+				"note":       {Type: "string"},
+				"mood":       {Type: "integer"},
+				"started_at": {Type: "string", Format: "date-time"},
+				"energy":     {Type: "integer"},
+			},
+			Required: []string{"id", "note", "energy"},
+		},
+	}}
+
+	got := Breaking(published, current)
+	want := []string{
+		"Legacy: type removed",
+		"Session.mood: type changed from integer|null to integer",
+		"Session.notes: field removed or renamed",
+		"Session.started_at: field is no longer always present",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Breaking() =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+
+	if changes := Breaking(current, current); len(changes) != 0 {
+		t.Errorf("Breaking() on identical documents = %v, want none", changes)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	doc := Generate()
+
+	program, ok := doc.Defs["ProgramCreateResult"]
+	if !ok {
+		t.Fatal("ProgramCreateResult missing from schema")
+	}
+	// Embedded *Program fields are flattened, hidden fields are skipped
+	if _, ok := program.Properties["name"]; !ok {
+		t.Error("embedded Program fields should be flattened into ProgramCreateResult")
+	}
+	if _, ok := program.Properties["cover_image_key"]; ok {
+		t.Error(`fields tagged json:"-" should not be part of the schema`)
+	}
+	if got := describe(program.Properties["similar_programs"]); got != "array<#/$defs/SimilarProgram>" {
+		t.Errorf("similar_programs = %s, want array of SimilarProgram", got)
+	}
+
+	session := doc.Defs["PracticeSession"]
+	if got := describe(session.Properties["completed_at"]); got != "string(date-time)|null" {
+		t.Errorf("completed_at = %s, want nullable date-time", got)
+	}
+	for _, field := range session.Required {
+		if field == "completed_at" {
+			t.Error("omitempty fields should not be required")
+		}
+	}
+}
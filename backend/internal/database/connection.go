@@ -23,6 +23,9 @@ func NewPool(cfg *config.DatabaseConfig) (*pgxpool.Pool, error) {
 	poolConfig.MaxConnLifetime = time.Duration(cfg.MaxLifetimeMinutes) * time.Minute
 	poolConfig.MaxConnIdleTime = 30 * time.Minute
 	poolConfig.HealthCheckPeriod = 1 * time.Minute
+	if cfg.SlowQueryMs > 0 {
+		poolConfig.ConnConfig.Tracer = NewSlowQueryTracer(cfg.GetSlowQueryThreshold())
+	}
 
 	// Create pool
 	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
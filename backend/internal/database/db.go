@@ -0,0 +1,20 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+//go:generate go tool moq -skip-ensure -rm -out ../../pkg/testutil/mocks/db.go -pkg mocks . DB
+
+// DB is the subset of pgx used by repositories. It is satisfied by *pgxpool.Pool and by
+// pgx.Tx, so tests can run repositories inside a transaction that is rolled back afterwards.
+// Begin on a pgx.Tx starts a savepoint, so repository transactions nest correctly.
+type DB interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
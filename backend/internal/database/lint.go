@@ -0,0 +1,292 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LintBaseline is the last migration that was applied everywhere before the linter existed.
+// Older migrations are not linted; they cannot be changed anymore.
+const LintBaseline = 19
+
+// Lint severities. Errors stop migrations from running at boot; warnings are only logged.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// LintFinding is an unsafe operation found in a migration
+type LintFinding struct {
+	File     string
+	Line     int
+	Rule     string
+	Severity string
+	Message  string
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s [%s] %s", f.File, f.Line, f.Severity, f.Rule, f.Message)
+}
+
+var (
+	migrationFileRe   = regexp.MustCompile(`^(\d+)_.*\.up\.sql$`)
+	ignoreRe          = regexp.MustCompile(`(?i)--\s*lint:ignore\s+([a-z-]+)`)
+	createTableRe     = regexp.MustCompile(`^CREATE TABLE (?:IF NOT EXISTS )?"?(\w+)"?`)
+	alterTableRe      = regexp.MustCompile(`^ALTER TABLE (?:IF EXISTS )?(?:ONLY )?"?(\w+)"? (.*)$`)
+	createIndexRe     = regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX (CONCURRENTLY )?.*? ON (?:ONLY )?"?(\w+)"?`)
+	volatileDefaultRe = regexp.MustCompile(`DEFAULT (?:GEN_RANDOM_UUID|UUID_GENERATE_V4|RANDOM|CLOCK_TIMESTAMP)\(`)
+	dropTableRe       = regexp.MustCompile(`^DROP TABLE `)
+)
+
+// LintMigrations checks the up migrations in dir that are newer than baseline
+func LintMigrations(dir string, baseline uint) ([]LintFinding, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var findings []LintFinding
+	for _, entry := range entries {
+		m := migrationFileRe.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil || uint(version) <= baseline {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		findings = append(findings, LintSQL(entry.Name(), string(content))...)
+	}
+	return findings, nil
+}
+
+// LintSQL checks one up migration for operations that lock busy tables for a long time or break
+// the previous release while it is still running. Tables created in the same migration are
+// empty and not yet used, so they are exempt. A rule can be silenced for a whole file with a
+// "-- lint:ignore <rule> <reason>" comment.
+func LintSQL(file, sql string) []LintFinding {
+	ignored := make(map[string]bool)
+	for _, m := range ignoreRe.FindAllStringSubmatch(sql, -1) {
+		ignored[strings.ToLower(m[1])] = true
+	}
+
+	statements := splitStatements(sql)
+	created := make(map[string]bool)
+	for _, stmt := range statements {
+		if m := createTableRe.FindStringSubmatch(stmt.text); m != nil {
+			created[strings.ToLower(m[1])] = true
+		}
+	}
+
+	var findings []LintFinding
+	report := func(stmt statement, rule, severity, message string) {
+		if ignored[rule] {
+			return
+		}
+		findings = append(findings, LintFinding{File: file, Line: stmt.line, Rule: rule, Severity: severity, Message: message})
+	}
+
+	for _, stmt := range statements {
+		if m := alterTableRe.FindStringSubmatch(stmt.text); m != nil {
+			if created[strings.ToLower(m[1])] {
+				continue
+			}
+			for _, action := range splitTopLevel(m[2], ',') {
+				lintAlterAction(stmt, strings.TrimSpace(action), report)
+			}
+			continue
+		}
+
+		if m := createIndexRe.FindStringSubmatch(stmt.text); m != nil {
+			concurrent := m[1] != ""
+			switch {
+			case concurrent && len(statements) > 1:
+				report(stmt, "concurrent-index-in-transaction", LintError,
+					"CREATE INDEX CONCURRENTLY cannot run inside the migration's transaction; put it in a migration of its own")
+			case !concurrent && !created[strings.ToLower(m[2])]:
+				report(stmt, "index-not-concurrent", LintWarning,
+					"CREATE INDEX blocks writes to the table while it builds; use CREATE INDEX CONCURRENTLY in a migration of its own")
+			}
+			continue
+		}
+
+		if dropTableRe.MatchString(stmt.text) {
+			report(stmt, "destructive", LintWarning,
+				"DROP TABLE breaks the running release if it still uses the table; only drop in a contract migration")
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	return findings
+}
+
+func lintAlterAction(stmt statement, action string, report func(statement, string, string, string)) {
+	switch {
+	case strings.HasPrefix(action, "ADD ") && !isConstraint(action):
+		if strings.Contains(action, " NOT NULL") && !strings.Contains(action, " DEFAULT ") {
+			report(stmt, "not-null-without-default", LintError,
+				"adding a NOT NULL column without a DEFAULT fails on existing rows and breaks inserts from the running release; add it nullable or with a default")
+		}
+		if volatileDefaultRe.MatchString(action) {
+			report(stmt, "volatile-default", LintError,
+				"a volatile DEFAULT rewrites the whole table under an exclusive lock; add the column without it and backfill in batches")
+		}
+
+	case strings.HasPrefix(action, "ADD ") && strings.Contains(action, "USING INDEX"):
+		// Constraint on an index built CONCURRENTLY beforehand: cheap
+
+	case strings.HasPrefix(action, "ADD ") && (strings.Contains(action, "UNIQUE") || strings.Contains(action, "PRIMARY KEY")):
+		report(stmt, "constraint-builds-index", LintWarning,
+			"adding a UNIQUE or PRIMARY KEY constraint builds an index under lock; create the index CONCURRENTLY and add the constraint USING INDEX")
+
+	case strings.HasPrefix(action, "ADD ") && !strings.Contains(action, "NOT VALID"):
+		report(stmt, "constraint-not-valid", LintWarning,
+			"adding a constraint validates all rows while holding a lock; add it NOT VALID and run VALIDATE CONSTRAINT in a later migration")
+
+	case strings.HasPrefix(action, "ALTER COLUMN ") && strings.Contains(action, " TYPE "):
+		report(stmt, "alter-column-type", LintError,
+			"changing a column type rewrites the table under an exclusive lock; add a new column, backfill it and switch over (expand/contract)")
+
+	case strings.HasPrefix(action, "ALTER COLUMN ") && strings.Contains(action, " SET NOT NULL"):
+		report(stmt, "set-not-null", LintWarning,
+			"SET NOT NULL scans the table under an exclusive lock; add a CHECK (col IS NOT NULL) NOT VALID constraint and VALIDATE it first")
+
+	case strings.HasPrefix(action, "DROP COLUMN ") || strings.HasPrefix(action, "RENAME "):
+		report(stmt, "destructive", LintWarning,
+			"dropping or renaming breaks the running release if it still uses the column or table; only do it in a contract migration")
+	}
+}
+
+// isConstraint reports whether an ALTER TABLE ADD action adds a table constraint rather than a column
+func isConstraint(action string) bool {
+	for _, prefix := range []string{"ADD CONSTRAINT ", "ADD PRIMARY KEY", "ADD UNIQUE", "ADD FOREIGN KEY", "ADD CHECK", "ADD EXCLUDE"} {
+		if strings.HasPrefix(action, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type statement struct {
+	// text is the statement in upper case with comments removed and whitespace collapsed
+	text string
+	line int
+}
+
+// splitStatements splits SQL into statements, skipping comments and respecting quoted
+// strings and dollar-quoted function bodies
+func splitStatements(sql string) []statement {
+	var (
+		statements []statement
+		current    strings.Builder
+		line       = 1
+		startLine  = 0
+	)
+
+	flush := func() {
+		text := strings.Join(strings.Fields(strings.ToUpper(current.String())), " ")
+		if text != "" {
+			statements = append(statements, statement{text: text, line: startLine})
+		}
+		current.Reset()
+		startLine = 0
+	}
+	write := func(s string) {
+		if startLine == 0 && strings.TrimSpace(s) != "" {
+			startLine = line
+		}
+		current.WriteString(s)
+	}
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\n':
+			current.WriteByte(' ')
+			line++
+
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			// Line comment: skip to end of line
+			for i+1 < len(sql) && sql[i+1] != '\n' {
+				i++
+			}
+
+		case c == '\'':
+			end := strings.IndexByte(sql[i+1:], '\'')
+			if end < 0 {
+				end = len(sql) - i - 1
+			}
+			literal := sql[i : i+end+2]
+			write("''")
+			line += strings.Count(literal, "\n")
+			i += end + 1
+
+		case c == '$':
+			tag := dollarTag(sql[i:])
+			if tag == "" {
+				write("$")
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				end = len(sql) - i - len(tag)
+			}
+			body := sql[i : i+len(tag)+end]
+			write("$$ $$")
+			line += strings.Count(body, "\n")
+			i += len(tag) + end + len(tag) - 1
+
+		case c == ';':
+			flush()
+
+		default:
+			write(string(c))
+		}
+	}
+	flush()
+	return statements
+}
+
+// dollarTag returns the opening dollar quote ($$ or $tag$) at the start of s, if any
+func dollarTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		switch c := s[j]; {
+		case c == '$':
+			return s[:j+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || (j > 1 && c >= '0' && c <= '9'):
+			continue
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// splitTopLevel splits s on sep outside of parentheses
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
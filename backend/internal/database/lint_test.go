@@ -0,0 +1,171 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLintSQL(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "not null without default",
+			sql:  "ALTER TABLE users ADD COLUMN nickname VARCHAR(50) NOT NULL;",
+			want: []string{"not-null-without-default"},
+		},
+		{
+			name: "not null with constant default",
+			sql:  "ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT 'en';",
+		},
+		{
+			name: "volatile default",
+			sql:  "ALTER TABLE programs ADD COLUMN share_token UUID DEFAULT gen_random_uuid();",
+			want: []string{"volatile-default"},
+		},
+		{
+			name: "column type change",
+			sql:  "ALTER TABLE practice_sessions ALTER COLUMN notes TYPE TEXT;",
+			want: []string{"alter-column-type"},
+		},
+		{
+			name: "set not null and drop column in one statement",
+			sql:  "ALTER TABLE programs\n  ALTER COLUMN owned_by SET NOT NULL,\n  DROP COLUMN created_by;",
+			want: []string{"set-not-null", "destructive"},
+		},
+		{
+			name: "constraint with and without NOT VALID",
+			sql: `ALTER TABLE exercises ADD CONSTRAINT chk_reps CHECK (repetitions > 0);
+ALTER TABLE exercises ADD CONSTRAINT chk_sets CHECK (sets > 0) NOT VALID;
+ALTER TABLE exercises VALIDATE CONSTRAINT chk_sets;`,
+			want: []string{"constraint-not-valid"},
+		},
+		{
+			name: "unique constraint",
+			sql:  "ALTER TABLE users ADD CONSTRAINT users_nickname_key UNIQUE (nickname);",
+			want: []string{"constraint-builds-index"},
+		},
+		{
+			name: "unique constraint using existing index",
+			sql:  "ALTER TABLE users ADD CONSTRAINT users_nickname_key UNIQUE USING INDEX idx_users_nickname;",
+		},
+		{
+			name: "blocking index",
+			sql:  "CREATE INDEX idx_sessions_mood ON practice_sessions(mood);",
+			want: []string{"index-not-concurrent"},
+		},
+		{
+			name: "concurrent index on its own",
+			sql:  "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_sessions_mood ON practice_sessions(mood);",
+		},
+		{
+			name: "concurrent index next to other statements",
+			sql: `ALTER TABLE practice_sessions ADD COLUMN mood INTEGER;
+CREATE INDEX CONCURRENTLY idx_sessions_mood ON practice_sessions(mood);`,
+			want: []string{"concurrent-index-in-transaction"},
+		},
+		{
+			name: "new table is exempt",
+			sql: `CREATE TABLE IF NOT EXISTS streaks (id UUID PRIMARY KEY DEFAULT gen_random_uuid());
+ALTER TABLE streaks ADD COLUMN user_id UUID NOT NULL;
+CREATE INDEX idx_streaks_user ON streaks(user_id);`,
+		},
+		{
+			name: "drop table",
+			sql:  "DROP TABLE IF EXISTS video_submissions;",
+			want: []string{"destructive"},
+		},
+		{
+			name: "lint:ignore silences a rule",
+			sql: `-- lint:ignore index-not-concurrent table has a few hundred rows
+CREATE INDEX idx_groups_name ON groups(name);
+ALTER TABLE groups ADD COLUMN slug TEXT NOT NULL;`,
+			want: []string{"not-null-without-default"},
+		},
+		{
+			name: "comments, strings and function bodies are not parsed as statements",
+			sql: `-- ALTER TABLE users ADD COLUMN x INT NOT NULL;
+COMMENT ON TABLE users IS 'never ALTER TABLE users DROP COLUMN email; here';
+CREATE OR REPLACE FUNCTION touch() RETURNS trigger AS $fn$
+BEGIN
+    ALTER TABLE users ALTER COLUMN email TYPE TEXT;
+    RETURN NEW;
+END;
+$fn$ LANGUAGE plpgsql;`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range LintSQL("000020_test.up.sql", tt.sql) {
+				got = append(got, f.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LintSQL() rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintSQL_LineNumbers(t *testing.T) {
+	sql := `-- Add reminder settings
+ALTER TABLE users ADD COLUMN reminder_time TIME;
+
+COMMENT ON COLUMN users.reminder_time IS 'local time,
+in the user''s timezone';
+
+CREATE INDEX idx_users_reminder_time
+    ON users(reminder_time);`
+
+	findings := LintSQL("000020_add_reminders.up.sql", sql)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %v", findings)
+	}
+	if findings[0].Line != 7 {
+		t.Errorf("Line = %d, want 7", findings[0].Line)
+	}
+	if want := "000020_add_reminders.up.sql:7: warning [index-not-concurrent]"; findings[0].String()[:len(want)] != want {
+		t.Errorf("String() = %q, want prefix %q", findings[0].String(), want)
+	}
+}
+
+func TestLintMigrations_Baseline(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"000001_init.up.sql":    "ALTER TABLE users ADD COLUMN a INT NOT NULL;",
+		"000002_add_b.up.sql":   "ALTER TABLE users ADD COLUMN b INT NOT NULL;",
+		"000002_add_b.down.sql": "ALTER TABLE users DROP COLUMN b;",
+		"000003_add_c.up.sql":   "ALTER TABLE users ADD COLUMN c INT;",
+		"README.md":             "ALTER TABLE users ADD COLUMN d INT NOT NULL;",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	findings, err := LintMigrations(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].File != "000002_add_b.up.sql" {
+		t.Errorf("expected only 000002_add_b.up.sql to be flagged, got %v", findings)
+	}
+}
+
+func TestLintMigrations_Repository(t *testing.T) {
+	findings, err := LintMigrations("../../migrations", LintBaseline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range findings {
+		if f.Severity == LintError {
+			t.Errorf("migration would be refused at boot: %s", f)
+		}
+	}
+}
@@ -10,8 +10,13 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
-// RunMigrations runs all pending database migrations
+// RunMigrations runs all pending database migrations. Migrations run at boot while the
+// previous release still serves traffic, so they are linted first and refused if unsafe.
 func RunMigrations(databaseURL, migrationsPath string) error {
+	if err := checkMigrations(migrationsPath); err != nil {
+		return err
+	}
+
 	m, err := migrate.New(
 		fmt.Sprintf("file://%s", migrationsPath),
 		databaseURL,
@@ -73,3 +78,25 @@ func GetMigrationVersion(databaseURL, migrationsPath string) (uint, bool, error)
 
 	return version, dirty, nil
 }
+
+// checkMigrations logs lint warnings and fails if any migration has a lint error
+func checkMigrations(migrationsPath string) error {
+	findings, err := LintMigrations(migrationsPath, LintBaseline)
+	if err != nil {
+		return err
+	}
+
+	unsafe := 0
+	for _, f := range findings {
+		if f.Severity == LintError {
+			log.Printf("[ERROR] Unsafe migration: %s", f)
+			unsafe++
+			continue
+		}
+		log.Printf("[WARN] Migration: %s", f)
+	}
+	if unsafe > 0 {
+		return fmt.Errorf("refusing to migrate: %d unsafe operation(s) found, see `make migrate-lint`", unsafe)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/xuangong/backend/internal/config"
+)
+
+// RetryPolicy controls how transient errors are retried
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy is used until ConfigureRetry is called
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   50 * time.Millisecond,
+	MaxDelay:    time.Second,
+}
+
+// RetryStats counts retries for a single operation
+type RetryStats struct {
+	Operation string `json:"operation"`
+	// Retries is the number of extra attempts made after a transient error
+	Retries int64 `json:"retries"`
+	// Recovered counts calls that succeeded after at least one retry
+	Recovered int64 `json:"recovered"`
+	// Exhausted counts calls that still failed with a transient error after the last attempt
+	Exhausted int64 `json:"exhausted"`
+}
+
+type retryCounters struct {
+	retries   atomic.Int64
+	recovered atomic.Int64
+	exhausted atomic.Int64
+}
+
+var (
+	policy   atomic.Pointer[RetryPolicy]
+	counters sync.Map // operation name -> *retryCounters
+)
+
+// ConfigureRetry sets the retry policy used by Retry
+func ConfigureRetry(cfg *config.DatabaseConfig) {
+	p := RetryPolicy{
+		MaxAttempts: cfg.RetryMaxAttempts,
+		BaseDelay:   time.Duration(cfg.RetryBaseDelayMs) * time.Millisecond,
+		MaxDelay:    time.Duration(cfg.RetryMaxDelayMs) * time.Millisecond,
+	}
+	if p.MaxAttempts < 1 {
+		p.MaxAttempts = 1
+	}
+	policy.Store(&p)
+}
+
+func currentPolicy() RetryPolicy {
+	if p := policy.Load(); p != nil {
+		return *p
+	}
+	return DefaultRetryPolicy
+}
+
+// Retry runs fn and retries it with exponential backoff while it fails with a transient error.
+// Only use it for idempotent operations: reads, or writes that are safe to repeat such as a whole
+// transaction that was rolled back. op names the operation in retry metrics.
+func Retry(ctx context.Context, op string, fn func() error) error {
+	return retry(ctx, currentPolicy(), op, fn)
+}
+
+func retry(ctx context.Context, p RetryPolicy, op string, fn func() error) error {
+	var c *retryCounters
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			if c != nil {
+				c.recovered.Add(1)
+			}
+			return nil
+		}
+		if !IsTransient(err) || ctx.Err() != nil {
+			return err
+		}
+		if c == nil {
+			c = countersFor(op)
+		}
+		if attempt >= p.MaxAttempts {
+			c.exhausted.Add(1)
+			return err
+		}
+
+		c.retries.Add(1)
+		delay := backoff(p, attempt)
+		log.Printf("[WARN] Transient database error in %s (attempt %d/%d), retrying in %s: %v", op, attempt, p.MaxAttempts, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff doubles the delay per attempt up to MaxDelay, with jitter so concurrent
+// retries after a failover do not hit the database in lockstep
+func backoff(p RetryPolicy, attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// IsTransient reports whether err is worth retrying: serialization failures, deadlocks and
+// connection problems. Constraint violations, missing rows and cancelled contexts are not.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 40001 serialization_failure, 40P01 deadlock_detected, 53300 too_many_connections,
+		// 57P0x server shutting down or starting up, class 08 connection exceptions
+		switch pgErr.Code {
+		case "40001", "40P01", "53300", "57P01", "57P02", "57P03":
+			return true
+		}
+		return strings.HasPrefix(pgErr.Code, "08")
+	}
+
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func countersFor(op string) *retryCounters {
+	if c, ok := counters.Load(op); ok {
+		return c.(*retryCounters)
+	}
+	c, _ := counters.LoadOrStore(op, &retryCounters{})
+	return c.(*retryCounters)
+}
+
+// RetryMetrics returns retry counters for every operation that hit a transient error since startup
+func RetryMetrics() []RetryStats {
+	stats := make([]RetryStats, 0)
+	counters.Range(func(key, value any) bool {
+		c := value.(*retryCounters)
+		stats = append(stats, RetryStats{
+			Operation: key.(string),
+			Retries:   c.retries.Load(),
+			Recovered: c.recovered.Load(),
+			Exhausted: c.exhausted.Load(),
+		})
+		return true
+	})
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Operation < stats[j].Operation
+	})
+	return stats
+}
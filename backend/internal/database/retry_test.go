@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"no rows", pgx.ErrNoRows, false},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, true},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, true},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"wrapped serialization failure", fmt.Errorf("update: %w", &pgconn.PgError{Code: "40001"}), true},
+		{"unexpected eof", io.ErrUnexpectedEOF, true},
+		{"cancelled", context.Canceled, false},
+		{"deadline", context.DeadlineExceeded, false},
+		{"other", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("%s: IsTransient() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+	transient := &pgconn.PgError{Code: "40001"}
+
+	t.Run("recovers after transient errors", func(t *testing.T) {
+		calls := 0
+		err := retry(context.Background(), p, "test.recover", func() error {
+			calls++
+			if calls < 3 {
+				return transient
+			}
+			return nil
+		})
+		if err != nil || calls != 3 {
+			t.Fatalf("got err=%v after %d calls, want success after 3", err, calls)
+		}
+		c := countersFor("test.recover")
+		if c.retries.Load() != 2 || c.recovered.Load() != 1 || c.exhausted.Load() != 0 {
+			t.Errorf("unexpected counters: retries=%d recovered=%d exhausted=%d", c.retries.Load(), c.recovered.Load(), c.exhausted.Load())
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		err := retry(context.Background(), p, "test.exhaust", func() error {
+			calls++
+			return transient
+		})
+		if !errors.Is(err, transient) || calls != 3 {
+			t.Fatalf("got err=%v after %d calls, want transient error after 3", err, calls)
+		}
+		if countersFor("test.exhaust").exhausted.Load() != 1 {
+			t.Error("expected exhausted to be counted")
+		}
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		calls := 0
+		permanent := &pgconn.PgError{Code: "23505"}
+		err := retry(context.Background(), p, "test.permanent", func() error {
+			calls++
+			return permanent
+		})
+		if !errors.Is(err, permanent) || calls != 1 {
+			t.Fatalf("got err=%v after %d calls, want single attempt", err, calls)
+		}
+	})
+
+	t.Run("stops when context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		err := retry(ctx, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}, "test.cancel", func() error {
+			calls++
+			cancel()
+			return transient
+		})
+		if !errors.Is(err, transient) || calls != 1 {
+			t.Fatalf("got err=%v after %d calls, want single attempt", err, calls)
+		}
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	for attempt, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 8: 300 * time.Millisecond} {
+		d := backoff(p, attempt)
+		if d < max/2 || d > max {
+			t.Errorf("backoff(attempt %d) = %s, want between %s and %s", attempt, d, max/2, max)
+		}
+	}
+}
+
+func TestRetryMetrics(t *testing.T) {
+	_ = retry(context.Background(), RetryPolicy{MaxAttempts: 1}, "test.metrics", func() error {
+		return io.ErrUnexpectedEOF
+	})
+
+	for _, s := range RetryMetrics() {
+		if s.Operation == "test.metrics" {
+			if s.Exhausted != 1 {
+				t.Errorf("Exhausted = %d, want 1", s.Exhausted)
+			}
+			return
+		}
+	}
+	t.Error("expected metrics for test.metrics")
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/pkg/clock"
+)
+
+// SlowQueryTracer is a pgx tracer that logs queries taking longer than a threshold.
+// Only the SQL text and the number of arguments are logged, never the argument values,
+// which can contain emails, password hashes or message content.
+type SlowQueryTracer struct {
+	threshold time.Duration
+	clock     clock.Clock
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	at   time.Time
+	sql  string
+	args int
+}
+
+// NewSlowQueryTracer returns a tracer logging queries slower than threshold
+func NewSlowQueryTracer(threshold time.Duration) *SlowQueryTracer {
+	return &SlowQueryTracer{threshold: threshold, clock: clock.System}
+}
+
+// WithClock replaces the clock used to time queries, so tests can control time
+func (t *SlowQueryTracer) WithClock(c clock.Clock) *SlowQueryTracer {
+	t.clock = c
+	return t
+}
+
+func (t *SlowQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{at: t.clock.Now(), sql: data.SQL, args: len(data.Args)})
+}
+
+func (t *SlowQueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	elapsed := t.clock.Now().Sub(start.at)
+	if elapsed < t.threshold {
+		return
+	}
+
+	status := "ok"
+	if data.Err != nil {
+		status = "error"
+	}
+	log.Printf("[WARN] Slow query (%s, %dms, %d args redacted): %s",
+		status, elapsed.Milliseconds(), start.args, compactSQL(start.sql))
+}
+
+// compactSQL collapses the whitespace of multi-line queries so they fit on one log line
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
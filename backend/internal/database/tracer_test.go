@@ -0,0 +1,46 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/pkg/clock"
+)
+
+func TestSlowQueryTracer(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	clk := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	tracer := NewSlowQueryTracer(200 * time.Millisecond).WithClock(clk)
+	query := pgx.TraceQueryStartData{
+		SQL:  "SELECT id\n\t\tFROM users\n\t\tWHERE email = $1",
+		Args: []any{"secret@example.com"},
+	}
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, query)
+	clk.Advance(50 * time.Millisecond)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	if buf.Len() != 0 {
+		t.Fatalf("fast query should not be logged, got %q", buf.String())
+	}
+
+	ctx = tracer.TraceQueryStart(context.Background(), nil, query)
+	clk.Advance(250 * time.Millisecond)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	out := buf.String()
+	if !strings.Contains(out, "Slow query (ok, 250ms, 1 args redacted): SELECT id FROM users WHERE email = $1") {
+		t.Errorf("unexpected log output %q", out)
+	}
+	if strings.Contains(out, "secret@example.com") {
+		t.Error("query arguments must not be logged")
+	}
+}
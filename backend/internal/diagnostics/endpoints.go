@@ -0,0 +1,122 @@
+// Package diagnostics keeps rolling request latency statistics per endpoint for the admin
+// diagnostics endpoints.
+package diagnostics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is the number of most recent requests kept per endpoint
+const DefaultWindow = 200
+
+// EndpointStats records request latencies per endpoint over a rolling window of the most
+// recent requests. It is safe for concurrent use.
+type EndpointStats struct {
+	mu        sync.Mutex
+	window    int
+	endpoints map[string]*samples
+}
+
+type samples struct {
+	durations []time.Duration
+	next      int
+	total     int64
+}
+
+// EndpointLatency summarizes the recent latencies of one endpoint
+type EndpointLatency struct {
+	// Endpoint is the method and route template, e.g. "GET /api/v1/sessions/:id"
+	Endpoint string `json:"endpoint"`
+	// Requests counts all requests since startup; the latencies only cover the last Samples
+	Requests int64   `json:"requests"`
+	Samples  int     `json:"samples"`
+	AvgMs    float64 `json:"avg_ms"`
+	P50Ms    float64 `json:"p50_ms"`
+	P95Ms    float64 `json:"p95_ms"`
+	MaxMs    float64 `json:"max_ms"`
+}
+
+// NewEndpointStats keeps the last window requests per endpoint
+func NewEndpointStats(window int) *EndpointStats {
+	if window < 1 {
+		window = DefaultWindow
+	}
+	return &EndpointStats{window: window, endpoints: make(map[string]*samples)}
+}
+
+// Record adds a request latency for endpoint
+func (s *EndpointStats) Record(endpoint string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.endpoints[endpoint]
+	if !ok {
+		e = &samples{durations: make([]time.Duration, 0, s.window)}
+		s.endpoints[endpoint] = e
+	}
+	if len(e.durations) < s.window {
+		e.durations = append(e.durations, d)
+	} else {
+		e.durations[e.next] = d
+		e.next = (e.next + 1) % s.window
+	}
+	e.total++
+}
+
+// Slowest returns up to limit endpoints ordered by their 95th percentile latency, slowest first
+func (s *EndpointStats) Slowest(limit int) []EndpointLatency {
+	s.mu.Lock()
+	result := make([]EndpointLatency, 0, len(s.endpoints))
+	for endpoint, e := range s.endpoints {
+		result = append(result, summarize(endpoint, e))
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].P95Ms != result[j].P95Ms {
+			return result[i].P95Ms > result[j].P95Ms
+		}
+		return result[i].Endpoint < result[j].Endpoint
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+func summarize(endpoint string, e *samples) EndpointLatency {
+	sorted := make([]time.Duration, len(e.durations))
+	copy(sorted, e.durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+
+	return EndpointLatency{
+		Endpoint: endpoint,
+		Requests: e.total,
+		Samples:  len(sorted),
+		AvgMs:    ms(sum / time.Duration(len(sorted))),
+		P50Ms:    ms(percentile(sorted, 0.50)),
+		P95Ms:    ms(percentile(sorted, 0.95)),
+		MaxMs:    ms(sorted[len(sorted)-1]),
+	}
+}
+
+// percentile uses the nearest-rank method on sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func ms(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*100) / 100
+}
@@ -0,0 +1,45 @@
+package diagnostics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEndpointStats_Slowest(t *testing.T) {
+	stats := NewEndpointStats(10)
+	for i := 1; i <= 20; i++ {
+		stats.Record("GET /fast", time.Duration(i)*time.Millisecond)
+	}
+	for i := 1; i <= 4; i++ {
+		stats.Record("GET /slow", time.Duration(i)*100*time.Millisecond)
+	}
+
+	got := stats.Slowest(0)
+	if len(got) != 2 || got[0].Endpoint != "GET /slow" || got[1].Endpoint != "GET /fast" {
+		t.Fatalf("Slowest() = %+v, want slow before fast", got)
+	}
+
+	slow := got[0]
+	if slow.Requests != 4 || slow.Samples != 4 || slow.MaxMs != 400 || slow.P50Ms != 200 || slow.AvgMs != 250 {
+		t.Errorf("slow endpoint = %+v", slow)
+	}
+
+	// Only the last 10 of 20 requests are kept: 11ms..20ms
+	fast := got[1]
+	if fast.Requests != 20 || fast.Samples != 10 {
+		t.Errorf("fast endpoint requests=%d samples=%d, want 20 and 10", fast.Requests, fast.Samples)
+	}
+	if fast.P50Ms != 15 || fast.P95Ms != 20 || fast.MaxMs != 20 {
+		t.Errorf("fast endpoint p50=%v p95=%v max=%v, want 15, 20, 20", fast.P50Ms, fast.P95Ms, fast.MaxMs)
+	}
+
+	if got := stats.Slowest(1); len(got) != 1 || got[0].Endpoint != "GET /slow" {
+		t.Errorf("Slowest(1) = %+v, want only the slowest endpoint", got)
+	}
+}
+
+func TestEndpointStats_Empty(t *testing.T) {
+	if got := NewEndpointStats(0).Slowest(10); got == nil || len(got) != 0 {
+		t.Errorf("Slowest() on empty stats = %v, want empty slice", got)
+	}
+}
@@ -0,0 +1,144 @@
+// Package digest renders the weekly progress email and decides when it is due in each
+// user's time zone.
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+// Window returns the start of the send window containing now: hour o'clock on weekday in loc.
+// The window lasts until the end of that local day, so a digest missed at the start of the
+// window (e.g. during a deploy) still goes out later that day. ok is false outside the window.
+func Window(now time.Time, loc *time.Location, weekday time.Weekday, hour int) (start time.Time, ok bool) {
+	local := now.In(loc)
+	if local.Weekday() != weekday || local.Hour() < hour {
+		return time.Time{}, false
+	}
+	y, m, d := local.Date()
+	return time.Date(y, m, d, hour, 0, 0, 0, loc), true
+}
+
+// Location loads an IANA time zone, falling back to UTC for unknown names
+func Location(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// labels are the texts of the email in one language
+type labels struct {
+	Subject    string
+	Greeting   string
+	Intro      string
+	Sessions   string
+	Streak     string
+	Unread     string
+	Upcoming   string
+	Due        string
+	NoUpcoming string
+	Closing    string
+}
+
+var translations = map[string]labels{
+	"en": {
+		Subject:    "Your week of practice",
+		Greeting:   "Hello %s,",
+		Intro:      "here is your practice from %s to %s.",
+		Sessions:   "Sessions completed: %d (%d minutes)",
+		Streak:     "Current streak: %d days (longest: %d)",
+		Unread:     "Unread feedback from your instructors: %d messages",
+		Upcoming:   "Homework due this week:",
+		Due:        "due %s",
+		NoUpcoming: "No homework due this week.",
+		Closing:    "Keep up your daily practice.",
+	},
+	"de": {
+		Subject:    "Deine Trainingswoche",
+		Greeting:   "Hallo %s,",
+		Intro:      "hier ist dein Training vom %s bis %s.",
+		Sessions:   "Abgeschlossene Einheiten: %d (%d Minuten)",
+		Streak:     "Aktuelle Serie: %d Tage (längste: %d)",
+		Unread:     "Ungelesenes Feedback deiner Lehrer: %d Nachrichten",
+		Upcoming:   "Diese Woche fällige Hausaufgaben:",
+		Due:        "fällig am %s",
+		NoUpcoming: "Diese Woche sind keine Hausaufgaben fällig.",
+		Closing:    "Bleib bei deinem täglichen Training.",
+	},
+	"zh": {
+		Subject:    "您本周的练习",
+		Greeting:   "%s，您好：",
+		Intro:      "以下是您从 %s 到 %s 的练习情况。",
+		Sessions:   "完成的练习：%d 次（%d 分钟）",
+		Streak:     "当前连续天数：%d 天（最长：%d 天）",
+		Unread:     "老师的未读反馈：%d 条",
+		Upcoming:   "本周到期的作业：",
+		Due:        "截止 %s",
+		NoUpcoming: "本周没有到期的作业。",
+		Closing:    "请坚持每天练习。",
+	},
+}
+
+const dateLayout = "2006-01-02"
+
+var bodyTemplate = template.Must(template.New("digest").Parse(`{{.Line .L.Greeting .D.FullName}}
+
+{{.Line .L.Intro (.Date .D.PeriodStart) (.Date .D.PeriodEnd)}}
+
+{{.Line .L.Sessions .D.SessionsCompleted .D.PracticeMinutes}}
+{{.Line .L.Streak .D.CurrentStreak .D.LongestStreak}}
+{{- if .D.UnreadFeedback}}
+{{.Line .L.Unread .D.UnreadFeedback}}
+{{- end}}
+
+{{if .D.UpcomingHomework -}}
+{{.L.Upcoming}}
+{{- range .D.UpcomingHomework}}
+- {{.Title}} ({{.ProgramName}}), {{$.Line $.L.Due ($.DateTime .DueAt)}}
+{{- end}}
+{{- else -}}
+{{.L.NoUpcoming}}
+{{- end}}
+
+{{.L.Closing}}
+`))
+
+// view is the data the body template is executed with
+type view struct {
+	D   *models.WeeklyDigest
+	L   labels
+	loc *time.Location
+}
+
+func (v view) Line(format string, args ...any) string {
+	return fmt.Sprintf(format, args...)
+}
+
+func (v view) Date(t time.Time) string {
+	return t.In(v.loc).Format(dateLayout)
+}
+
+func (v view) DateTime(t time.Time) string {
+	return t.In(v.loc).Format(dateLayout + " 15:04")
+}
+
+// Render returns the subject and plain text body of a digest in the user's language
+// (English if unsupported), with dates in the user's time zone
+func Render(d *models.WeeklyDigest) (subject, body string, err error) {
+	l, ok := translations[d.Language]
+	if !ok {
+		l = translations["en"]
+	}
+
+	var b bytes.Buffer
+	if err := bodyTemplate.Execute(&b, view{D: d, L: l, loc: Location(d.Timezone)}); err != nil {
+		return "", "", fmt.Errorf("failed to render digest: %w", err)
+	}
+	return l.Subject, b.String(), nil
+}
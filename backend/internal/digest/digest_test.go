@@ -0,0 +1,141 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func TestWindow(t *testing.T) {
+	berlin := Location("Europe/Berlin")
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		loc       *time.Location
+		wantOK    bool
+		wantStart time.Time
+	}{
+		{
+			name:      "at the start of the window",
+			now:       time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC), // Monday
+			loc:       time.UTC,
+			wantOK:    true,
+			wantStart: time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "later the same day",
+			now:       time.Date(2026, 10, 12, 22, 30, 0, 0, time.UTC),
+			loc:       time.UTC,
+			wantOK:    true,
+			wantStart: time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "before the send hour",
+			now:    time.Date(2026, 10, 12, 7, 59, 0, 0, time.UTC),
+			loc:    time.UTC,
+			wantOK: false,
+		},
+		{
+			name:   "other weekday",
+			now:    time.Date(2026, 10, 13, 8, 0, 0, 0, time.UTC),
+			loc:    time.UTC,
+			wantOK: false,
+		},
+		{
+			// 06:30 UTC is already 08:30 in Berlin (CEST)
+			name:      "evaluated in the user's time zone",
+			now:       time.Date(2026, 10, 12, 6, 30, 0, 0, time.UTC),
+			loc:       berlin,
+			wantOK:    true,
+			wantStart: time.Date(2026, 10, 12, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			// 23:30 UTC on Sunday is Monday 01:30 in Berlin, before the send hour
+			name:   "local day differs from UTC",
+			now:    time.Date(2026, 10, 11, 23, 30, 0, 0, time.UTC),
+			loc:    berlin,
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, ok := Window(tt.now, tt.loc, time.Monday, 8)
+			if ok != tt.wantOK {
+				t.Fatalf("Window() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !start.Equal(tt.wantStart) {
+				t.Errorf("Window() start = %v, want %v", start.UTC(), tt.wantStart)
+			}
+		})
+	}
+}
+
+func TestLocation_FallsBackToUTC(t *testing.T) {
+	if loc := Location("Mars/Olympus_Mons"); loc != time.UTC {
+		t.Errorf("Location() = %v, want UTC", loc)
+	}
+}
+
+func TestRender(t *testing.T) {
+	d := &models.WeeklyDigest{
+		FullName:          "Mei Lin",
+		Language:          "en",
+		Timezone:          "Asia/Shanghai",
+		PeriodStart:       time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:         time.Date(2026, 10, 11, 20, 0, 0, 0, time.UTC),
+		SessionsCompleted: 5,
+		PracticeMinutes:   150,
+		CurrentStreak:     3,
+		LongestStreak:     12,
+		UnreadFeedback:    2,
+		UpcomingHomework: []models.Homework{
+			{Title: "Stand every day", ProgramName: "Zhan Zhuang", DueAt: time.Date(2026, 10, 14, 16, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	subject, body, err := Render(d)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if subject != "Your week of practice" {
+		t.Errorf("subject = %q", subject)
+	}
+	for _, want := range []string{
+		"Hello Mei Lin,",
+		"from 2026-10-05 to 2026-10-12", // period end in Shanghai time
+		"Sessions completed: 5 (150 minutes)",
+		"Current streak: 3 days (longest: 12)",
+		"Unread feedback from your instructors: 2 messages",
+		"- Stand every day (Zhan Zhuang), due 2026-10-15 00:00",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+
+	d.Language = "de"
+	d.UnreadFeedback = 0
+	d.UpcomingHomework = nil
+	subject, body, err = Render(d)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if subject != "Deine Trainingswoche" {
+		t.Errorf("subject = %q", subject)
+	}
+	if strings.Contains(body, "Ungelesenes") {
+		t.Errorf("body mentions unread feedback without any:\n%s", body)
+	}
+	if !strings.Contains(body, "Diese Woche sind keine Hausaufgaben fällig.") {
+		t.Errorf("body missing empty homework line:\n%s", body)
+	}
+
+	d.Language = "fr"
+	if subject, _, _ = Render(d); subject != "Your week of practice" {
+		t.Errorf("unsupported language subject = %q, want English", subject)
+	}
+}
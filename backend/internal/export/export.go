@@ -0,0 +1,139 @@
+// Package export renders submission threads into documents students can keep after a course
+// ends: Markdown for reuse in notes apps and PDF for printing. It also streams admin reports
+// as CSV or XLSX tables.
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/xuangong/backend/internal/models"
+)
+
+// Formats supported by the exporters
+const (
+	FormatMarkdown = "md"
+	FormatPDF      = "pdf"
+)
+
+// Thread is a submission with everything needed to render it on its own
+type Thread struct {
+	Submission  models.Submission
+	ProgramName string
+	StudentName string
+	Messages    []models.MessageWithAuthor
+	ExportedAt  time.Time
+}
+
+const timeLayout = "2006-01-02 15:04 UTC"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// Markdown renders the thread as a Markdown document. Message content is written as is,
+// so formatting the participants typed is kept.
+func Markdown(thread *Thread) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", thread.Submission.Title)
+	fmt.Fprintf(&b, "- **Program:** %s\n", thread.ProgramName)
+	fmt.Fprintf(&b, "- **Student:** %s\n", thread.StudentName)
+	fmt.Fprintf(&b, "- **Started:** %s\n", formatTime(thread.Submission.CreatedAt))
+	fmt.Fprintf(&b, "- **Exported:** %s\n", formatTime(thread.ExportedAt))
+
+	for _, m := range thread.Messages {
+		b.WriteString("\n---\n\n")
+		fmt.Fprintf(&b, "### %s%s · %s\n\n", m.AuthorName, roleSuffix(m.AuthorRole), formatTime(m.CreatedAt))
+		if content := strings.TrimSpace(m.Content); content != "" {
+			b.WriteString(content)
+			b.WriteString("\n")
+		}
+		if m.YouTubeURL != nil && *m.YouTubeURL != "" {
+			fmt.Fprintf(&b, "\nVideo: <%s>\n", *m.YouTubeURL)
+		}
+	}
+	if len(thread.Messages) == 0 {
+		b.WriteString("\n_No messages._\n")
+	}
+
+	return []byte(b.String())
+}
+
+// PDF renders the thread as an A4 PDF. The built-in fonts only cover Latin-1 (Windows-1252);
+// other characters, e.g. Chinese names, are replaced with "?". Use Markdown to keep them.
+func PDF(thread *Thread) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(thread.Submission.Title, true)
+	pdf.SetCreator("Xuan Gong", true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.MultiCell(0, 9, tr(thread.Submission.Title), "", "L", false)
+	pdf.Ln(2)
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(80, 80, 80)
+	for _, line := range []string{
+		"Program: " + thread.ProgramName,
+		"Student: " + thread.StudentName,
+		"Started: " + formatTime(thread.Submission.CreatedAt),
+		"Exported: " + formatTime(thread.ExportedAt),
+	} {
+		pdf.CellFormat(0, 5, tr(line), "", 1, "L", false, 0, "")
+	}
+	pdf.SetTextColor(0, 0, 0)
+
+	for _, m := range thread.Messages {
+		pdf.Ln(4)
+		pdf.SetDrawColor(200, 200, 200)
+		pdf.Line(20, pdf.GetY(), 190, pdf.GetY())
+		pdf.Ln(3)
+
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(0, 6, tr(m.AuthorName+roleSuffix(m.AuthorRole)+" - "+formatTime(m.CreatedAt)), "", 1, "L", false, 0, "")
+
+		pdf.SetFont("Helvetica", "", 11)
+		if content := strings.TrimSpace(m.Content); content != "" {
+			pdf.MultiCell(0, 5.5, tr(content), "", "L", false)
+		}
+		if m.YouTubeURL != nil && *m.YouTubeURL != "" {
+			pdf.Ln(1)
+			pdf.SetTextColor(20, 80, 180)
+			pdf.WriteLinkString(5.5, tr("Video: "+*m.YouTubeURL), *m.YouTubeURL)
+			pdf.SetTextColor(0, 0, 0)
+			pdf.Ln(5.5)
+		}
+	}
+	if len(thread.Messages) == 0 {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "I", 11)
+		pdf.CellFormat(0, 6, "No messages.", "", 1, "L", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func roleSuffix(role models.UserRole) string {
+	if role == models.RoleAdmin {
+		return " (Instructor)"
+	}
+	return ""
+}
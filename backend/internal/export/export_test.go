@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func testThread() *Thread {
+	start := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	video := "https://youtu.be/dQw4w9WgXcQ"
+	return &Thread{
+		Submission:  models.Submission{Title: "Horse stance check", CreatedAt: start},
+		ProgramName: "Zhan Zhuang",
+		StudentName: "Li Wei",
+		Messages: []models.MessageWithAuthor{
+			{
+				SubmissionMessage: models.SubmissionMessage{Content: "Here is my stance", YouTubeURL: &video, CreatedAt: start},
+				AuthorName:        "Li Wei",
+				AuthorRole:        models.RoleStudent,
+			},
+			{
+				SubmissionMessage: models.SubmissionMessage{Content: "Sink your hips.\nKnees over toes, 李老师 says.", CreatedAt: start.Add(2 * time.Hour)},
+				AuthorName:        "Stefan Müller",
+				AuthorRole:        models.RoleAdmin,
+			},
+		},
+		ExportedAt: start.Add(48 * time.Hour),
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	got := string(Markdown(testThread()))
+
+	for _, want := range []string{
+		"# Horse stance check\n",
+		"- **Program:** Zhan Zhuang\n",
+		"- **Exported:** 2025-03-03 09:30 UTC\n",
+		"### Li Wei · 2025-03-01 09:30 UTC\n\nHere is my stance\n\nVideo: <https://youtu.be/dQw4w9WgXcQ>\n",
+		"### Stefan Müller (Instructor) · 2025-03-01 11:30 UTC\n\nSink your hips.\nKnees over toes, 李老师 says.\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Markdown() missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "Li Wei ·") > strings.Index(got, "Stefan Müller") {
+		t.Error("messages should keep their order")
+	}
+}
+
+func TestMarkdown_NoMessages(t *testing.T) {
+	thread := testThread()
+	thread.Messages = nil
+	if got := string(Markdown(thread)); !strings.Contains(got, "_No messages._") {
+		t.Errorf("Markdown() = %q, want a no-messages note", got)
+	}
+}
+
+func TestPDF(t *testing.T) {
+	data, err := PDF(testThread())
+	if err != nil {
+		t.Fatalf("PDF() error = %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatalf("PDF() output does not start with a PDF header: %q", data[:min(len(data), 16)])
+	}
+	// The video link is clickable
+	if !bytes.Contains(data, []byte("https://youtu.be/dQw4w9WgXcQ")) {
+		t.Error("PDF() should contain the video link")
+	}
+}
@@ -0,0 +1,241 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats supported by the table writers
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// TableWriter streams rows of a table to an output. Values may be nil, strings, integers,
+// floats, booleans or times; times are written as RFC3339 in UTC. Close must be called to
+// finish the output.
+type TableWriter interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []any) error
+	Close() error
+}
+
+// NewTableWriter returns a writer for format, or an error for unknown formats
+func NewTableWriter(format, sheetName string, w io.Writer) (TableWriter, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatXLSX:
+		return NewXLSXWriter(w, sheetName), nil
+	default:
+		return nil, fmt.Errorf("unsupported table format %q", format)
+	}
+}
+
+// ContentType returns the MIME type of a table format
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// CSVWriter writes RFC 4180 CSV
+type CSVWriter struct {
+	w *csv.Writer
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (c *CSVWriter) WriteHeader(columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *CSVWriter) WriteRow(values []any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = formatValue(v)
+		if s, ok := v.(string); ok {
+			record[i] = csvSafe(s)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *CSVWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// csvSafe keeps spreadsheet apps from evaluating user-entered text, such as names, as formulas
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// XLSXWriter writes an Office Open XML workbook with a single sheet. Rows are streamed into
+// the sheet as they are written, so large tables are never held in memory. Strings are
+// stored inline, which keeps the writer free of a shared string table.
+type XLSXWriter struct {
+	zip    *zip.Writer
+	sheet  *bufio.Writer
+	name   string
+	row    int
+	err    error
+	opened bool
+}
+
+func NewXLSXWriter(w io.Writer, sheetName string) *XLSXWriter {
+	return &XLSXWriter{zip: zip.NewWriter(w), name: sheetTitle(sheetName)}
+}
+
+// sheetTitle trims a name to what Excel accepts as a sheet name
+func sheetTitle(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "Sheet1"
+	}
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	return name
+}
+
+// open writes the fixed workbook parts and starts the sheet
+func (x *XLSXWriter) open() error {
+	if x.opened {
+		return x.err
+	}
+	x.opened = true
+
+	var title strings.Builder
+	_ = xml.EscapeText(&title, []byte(x.name))
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="` + title.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+	}
+	for _, part := range parts {
+		f, err := x.zip.Create(part.name)
+		if err == nil {
+			_, err = io.WriteString(f, part.content)
+		}
+		if err != nil {
+			x.err = err
+			return err
+		}
+	}
+
+	// The sheet is the last entry, so it can stay open while rows arrive
+	f, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		x.err = err
+		return err
+	}
+	x.sheet = bufio.NewWriter(f)
+	_, x.err = x.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return x.err
+}
+
+func (x *XLSXWriter) WriteHeader(columns []string) error {
+	values := make([]any, len(columns))
+	for i, c := range columns {
+		values[i] = c
+	}
+	return x.WriteRow(values)
+}
+
+func (x *XLSXWriter) WriteRow(values []any) error {
+	if err := x.open(); err != nil {
+		return err
+	}
+	x.row++
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, x.row)
+	for i, v := range values {
+		ref := columnName(i) + strconv.Itoa(x.row)
+		switch v := v.(type) {
+		case nil:
+			continue
+		case bool:
+			value := "0"
+			if v {
+				value = "1"
+			}
+			fmt.Fprintf(&b, `<c r="%s" t="b"><v>%s</v></c>`, ref, value)
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, formatValue(v))
+		default:
+			fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			_ = xml.EscapeText(&b, []byte(formatValue(v)))
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+	b.WriteString(`</row>`)
+
+	_, x.err = x.sheet.WriteString(b.String())
+	return x.err
+}
+
+func (x *XLSXWriter) Close() error {
+	if err := x.open(); err != nil {
+		return err
+	}
+	if _, err := x.sheet.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// columnName converts a zero-based column index to its letters: 0 is A, 26 is AA
+func columnName(i int) string {
+	name := ""
+	for i >= 0 {
+		name = string(rune('A'+i%26)) + name
+		i = i/26 - 1
+	}
+	return name
+}
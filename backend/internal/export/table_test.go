@@ -0,0 +1,121 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+var tableRows = [][]any{
+	{"Li Wei", int64(12), 87.5, time.Date(2025, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600)), true},
+	{"=HYPERLINK(\"x\")", int64(0), nil, nil, false},
+}
+
+func writeTable(t *testing.T, tw TableWriter) {
+	t.Helper()
+	if err := tw.WriteHeader([]string{"name", "sessions", "completion_rate", "last_session_at", "active"}); err != nil {
+		t.Fatalf("WriteHeader() error = %v", err)
+	}
+	for _, row := range tableRows {
+		if err := tw.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writeTable(t, NewCSVWriter(&buf))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	want := [][]string{
+		{"name", "sessions", "completion_rate", "last_session_at", "active"},
+		{"Li Wei", "12", "87.5", "2025-03-01T08:30:00Z", "true"},
+		{`'=HYPERLINK("x")`, "0", "", "", "false"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("record %d = %q, want %q", i, records[i], want[i])
+		}
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writeTable(t, NewXLSXWriter(&buf, "user_activity"))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("output is not a zip archive: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(data)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("workbook is missing %s", name)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `<sheet name="user_activity"`) {
+		t.Errorf("workbook.xml = %s, want the sheet named user_activity", files["xl/workbook.xml"])
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr"><is><t xml:space="preserve">name</t></is></c>`,
+		`<c r="B2"><v>12</v></c>`,
+		`<c r="C2"><v>87.5</v></c>`,
+		`<c r="D2" t="inlineStr"><is><t xml:space="preserve">2025-03-01T08:30:00Z</t></is></c>`,
+		`<c r="E2" t="b"><v>1</v></c>`,
+		// Formulas are only evaluated in <f> elements, so text needs no prefix
+		`<t xml:space="preserve">=HYPERLINK(&#34;x&#34;)</t>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet missing %s:\n%s", want, sheet)
+		}
+	}
+	if strings.Contains(sheet, `r="C3"`) {
+		t.Errorf("sheet has a cell for a nil value:\n%s", sheet)
+	}
+	if !strings.HasSuffix(sheet, "</sheetData></worksheet>") {
+		t.Errorf("sheet is not closed:\n%s", sheet)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestSheetTitle(t *testing.T) {
+	if got := sheetTitle("a/b:c"); got != "a_b_c" {
+		t.Errorf("sheetTitle() = %q", got)
+	}
+	if got := sheetTitle(strings.Repeat("x", 40)); len(got) != 31 {
+		t.Errorf("sheetTitle() length = %d, want 31", len(got))
+	}
+}
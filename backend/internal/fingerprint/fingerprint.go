@@ -0,0 +1,77 @@
+// Package fingerprint derives stable keys from a program's name and exercise list so
+// imported or cloned templates can be matched against existing ones.
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+// NameKey normalizes a program name: case, punctuation and whitespace differences are ignored
+func NameKey(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// Exercises hashes the ordered exercise structure of a program. Descriptions and metadata
+// are ignored so that re-worded copies of the same routine still match. Returns an empty
+// string for programs without exercises.
+func Exercises(exercises []models.Exercise) string {
+	if len(exercises) == 0 {
+		return ""
+	}
+
+	ordered := make([]models.Exercise, len(exercises))
+	copy(ordered, exercises)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].OrderIndex < ordered[j].OrderIndex
+	})
+
+	h := sha256.New()
+	for _, ex := range ordered {
+		fmt.Fprintf(h, "%s|%s|%s|%s|%d|%t|%s",
+			NameKey(ex.Name),
+			ex.ExerciseType,
+			optional(ex.DurationSeconds),
+			optional(ex.Repetitions),
+			ex.RestAfterSeconds,
+			ex.HasSides,
+			optional(ex.SideDurationSeconds),
+		)
+		// Only paced exercises hash their pacing, so fingerprints stored before it existed still match
+		if ex.Tempo != nil {
+			fmt.Fprintf(h, "|%d|%d|%d", ex.Tempo.SecondsPerRep, ex.Tempo.PrepSeconds, ex.Tempo.TransitionSeconds)
+		}
+		if p := ex.BreathingPattern; p != nil {
+			fmt.Fprintf(h, "|breath:%d|%d|%d|%d", p.InhaleSeconds, p.HoldSeconds, p.ExhaleSeconds, p.Cycles)
+		}
+		h.Write([]byte("\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func optional(v *int) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", *v)
+}
@@ -0,0 +1,83 @@
+package fingerprint
+
+import (
+	"testing"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestNameKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Morning Routine", "morning routine"},
+		{"  morning   ROUTINE ", "morning routine"},
+		{"Morning-Routine!", "morning routine"},
+		{"Zhan Zhuang (Level 2)", "zhan zhuang level 2"},
+		{"站桩", "站桩"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NameKey(tt.name); got != tt.want {
+			t.Errorf("NameKey(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExercises(t *testing.T) {
+	base := []models.Exercise{
+		{Name: "Standing", OrderIndex: 0, ExerciseType: models.ExerciseTypeTimed, DurationSeconds: intPtr(300), RestAfterSeconds: 30},
+		{Name: "Silk Reeling", OrderIndex: 1, ExerciseType: models.ExerciseTypeRepetition, Repetitions: intPtr(10), HasSides: true},
+	}
+
+	if Exercises(nil) != "" {
+		t.Error("expected empty fingerprint for no exercises")
+	}
+
+	fp := Exercises(base)
+	if len(fp) != 64 {
+		t.Fatalf("expected hex sha256, got %q", fp)
+	}
+
+	reordered := []models.Exercise{base[1], base[0]}
+	if Exercises(reordered) != fp {
+		t.Error("slice order should not matter, only order_index")
+	}
+
+	reworded := []models.Exercise{base[0], base[1]}
+	reworded[0].Name = "  STANDING "
+	reworded[0].Description = "Relax the shoulders"
+	if Exercises(reworded) != fp {
+		t.Error("name formatting and description should not change the fingerprint")
+	}
+
+	changed := []models.Exercise{base[0], base[1]}
+	changed[0].DurationSeconds = intPtr(600)
+	if Exercises(changed) == fp {
+		t.Error("different duration should change the fingerprint")
+	}
+
+	paced := []models.Exercise{base[0], base[1]}
+	paced[1].Tempo = &models.ExerciseTempo{SecondsPerRep: 8}
+	if Exercises(paced) == fp {
+		t.Error("a tempo should change the fingerprint")
+	}
+
+	breathing := []models.Exercise{base[0], base[1]}
+	breathing[0].BreathingPattern = &models.BreathingPattern{InhaleSeconds: 4, ExhaleSeconds: 4}
+	if Exercises(breathing) == fp {
+		t.Error("a breathing pattern should change the fingerprint")
+	}
+
+	swapped := []models.Exercise{base[0], base[1]}
+	swapped[0].OrderIndex, swapped[1].OrderIndex = 1, 0
+	if Exercises(swapped) == fp {
+		t.Error("different exercise order should change the fingerprint")
+	}
+}
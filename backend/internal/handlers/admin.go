@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/anonymize"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/diagnostics"
+	"github.com/xuangong/backend/internal/export"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type AdminHandler struct {
+	usageService      *services.UsageService
+	submissionService *services.SubmissionService
+	homeworkService   *services.HomeworkService
+	classService      *services.LiveClassService
+	reportService     *services.ReportService
+	endpointStats     *diagnostics.EndpointStats
+	anonymizer        *anonymize.Anonymizer
+	overviewService   *services.StudentOverviewService
+	validate          *validator.Validate
+}
+
+func NewAdminHandler(usageService *services.UsageService, submissionService *services.SubmissionService, homeworkService *services.HomeworkService, classService *services.LiveClassService, reportService *services.ReportService, endpointStats *diagnostics.EndpointStats, anonymizer *anonymize.Anonymizer, overviewService *services.StudentOverviewService) *AdminHandler {
+	return &AdminHandler{
+		usageService:      usageService,
+		submissionService: submissionService,
+		homeworkService:   homeworkService,
+		classService:      classService,
+		reportService:     reportService,
+		endpointStats:     endpointStats,
+		anonymizer:        anonymizer,
+		overviewService:   overviewService,
+		validate:          validators.New(),
+	}
+}
+
+// GetUsage godoc
+// @Summary Get per-user API usage statistics (admin only)
+// @Description Request counts and active days within the window, plus last activity and device info per user
+// @Tags admin
+// @Produce json
+// @Param days query int false "Window in days (default 30)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/usage [get]
+// @Security BearerAuth
+func (h *AdminHandler) GetUsage(c *gin.Context) {
+	var query validators.UsageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+
+	// Set defaults
+	if query.Days == 0 {
+		query.Days = 30
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	usage, err := h.usageService.GetUsage(c.Request.Context(), query.Days)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+	h.anonymizer.Usage(usage)
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": usage,
+		"days":  query.Days,
+	})
+}
+
+// GetStudentOverview godoc
+// @Summary Get everything about a student in one call (admin only)
+// @Description Profile, practice stats with streaks, programs with progress, the latest sessions, submissions waiting for feedback and the latest instructor notes
+// @Tags admin
+// @Produce json
+// @Param id path string true "Student ID"
+// @Success 200 {object} models.StudentOverview
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/students/{id}/overview [get]
+// @Security BearerAuth
+func (h *AdminHandler) GetStudentOverview(c *gin.Context) {
+	studentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid student ID"))
+		return
+	}
+
+	overview, err := h.overviewService.Get(c.Request.Context(), studentID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, overview)
+}
+
+// GetStudentAppState godoc
+// @Summary See a student's app as they see it (admin only)
+// @Description Their programs as GET /my-programs returns them, their unread counts and their stats, fetched the same way the student's own requests are, without issuing a token for the student.
+// @Description Programs are localized to the student's language unless locale is given.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Student ID"
+// @Param locale query string false "Locale to localize programs to (default: the student's language)"
+// @Success 200 {object} models.StudentAppState
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/students/{id}/app-state [get]
+// @Security BearerAuth
+func (h *AdminHandler) GetStudentAppState(c *gin.Context) {
+	studentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid student ID"))
+		return
+	}
+
+	state, err := h.overviewService.AppState(c.Request.Context(), studentID, c.Query("locale"))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// GetReviewAnalytics godoc
+// @Summary Get per-instructor review analytics (admin only)
+// @Description Open threads, median first-response time and messages per week for each instructor, to balance review workload
+// @Tags admin
+// @Produce json
+// @Param days query int false "Window in days (default 30)"
+// @Success 200 {object} models.ReviewAnalytics
+// @Router /api/v1/admin/review-analytics [get]
+// @Security BearerAuth
+func (h *AdminHandler) GetReviewAnalytics(c *gin.Context) {
+	var query validators.ReviewAnalyticsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+
+	// Set defaults
+	if query.Days == 0 {
+		query.Days = 30
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	analytics, err := h.submissionService.GetReviewAnalytics(c.Request.Context(), query.Days)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+	h.anonymizer.ReviewAnalytics(analytics)
+
+	c.JSON(http.StatusOK, analytics)
+}
+
+// GetHomeworkReport godoc
+// @Summary Get homework completion per group (admin only)
+// @Description Pending, on-time, late and overdue counts for homework due in the window, per student group. Students in several groups count in each; students in none are listed with a null group.
+// @Tags admin
+// @Produce json
+// @Param program_id query string false "Only homework for this program"
+// @Param from query string false "RFC3339, defaults to 30 days before to"
+// @Param to query string false "RFC3339, defaults to now"
+// @Success 200 {object} models.HomeworkReport
+// @Router /api/v1/admin/homework-report [get]
+// @Security BearerAuth
+func (h *AdminHandler) GetHomeworkReport(c *gin.Context) {
+	var query validators.HomeworkReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, bindError(err, "Invalid query parameters"))
+		return
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	to := time.Now().UTC()
+	if query.To != nil {
+		to = query.To.Time
+	}
+	from := to.AddDate(0, 0, -30)
+	if query.From != nil {
+		from = query.From.Time
+	}
+
+	var programID *uuid.UUID
+	if query.ProgramID != nil {
+		id := uuid.MustParse(*query.ProgramID) // Checked by the validator
+		programID = &id
+	}
+
+	report, err := h.homeworkService.Report(c.Request.Context(), programID, from, to)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetAttendanceReport godoc
+// @Summary Get live class attendance next to solo practice per student (admin only)
+// @Description Classes attended, late, excused and missed, with class minutes, next to completed practice sessions and minutes in the window. With a group, lists all its members and counts classes for everyone or that group.
+// @Tags admin
+// @Produce json
+// @Param group_id query string false "Only members of this group"
+// @Param from query string false "RFC3339, defaults to 30 days before to"
+// @Param to query string false "RFC3339, defaults to now"
+// @Success 200 {object} models.AttendanceReport
+// @Router /api/v1/admin/attendance-report [get]
+// @Security BearerAuth
+func (h *AdminHandler) GetAttendanceReport(c *gin.Context) {
+	var query validators.AttendanceReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, bindError(err, "Invalid query parameters"))
+		return
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	to := time.Now().UTC()
+	if query.To != nil {
+		to = query.To.Time
+	}
+	from := to.AddDate(0, 0, -30)
+	if query.From != nil {
+		from = query.From.Time
+	}
+
+	report, err := h.classService.Report(c.Request.Context(), parseOptionalUUID(query.GroupID), from, to)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+	h.anonymizer.AttendanceReport(report)
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetReport godoc
+// @Summary Download a report as CSV or XLSX (admin only)
+// @Description Streams one of the reports: user_activity (practice, submissions and messages per user), program_adoption (assigned and practicing students, sessions and completion per program) submission_turnaround (time to the first reply per submission created in the range) or exercise_substitutions (students choosing and performing each exercise substitute)
+// @Tags admin
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param type path string true "user_activity, program_adoption, submission_turnaround or exercise_substitutions"
+// @Param from query string false "RFC3339, defaults to 30 days before to"
+// @Param to query string false "RFC3339, defaults to now"
+// @Param format query string false "csv (default) or xlsx"
+// @Success 200 {file} file
+// @Router /api/v1/admin/reports/{type} [get]
+// @Security BearerAuth
+func (h *AdminHandler) GetReport(c *gin.Context) {
+	var query validators.ReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, bindError(err, "Invalid query parameters"))
+		return
+	}
+
+	// Set defaults
+	if query.Format == "" {
+		query.Format = export.FormatCSV
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	to := time.Now().UTC()
+	if query.To != nil {
+		to = query.To.Time
+	}
+	from := to.AddDate(0, 0, -30)
+	if query.From != nil {
+		from = query.From.Time
+	}
+
+	name := c.Param("type")
+	if err := h.reportService.Check(name, query.Format, from, to); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s-%s.%s", name, from.Format("20060102"), to.Format("20060102"), query.Format)
+	c.Header("Content-Type", export.ContentType(query.Format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	if err := h.reportService.Write(c.Request.Context(), name, query.Format, from, to, c.Writer); err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			respondWithAppError(c, err)
+			return
+		}
+		// Rows are already on their way, so the download is cut short instead
+		log.Printf("[ERROR] %s %s - report stopped after output started: %v", c.Request.Method, c.Request.URL.Path, err)
+		c.Abort()
+	}
+}
+
+// GetDatabaseRetries godoc
+// @Summary Get database retry metrics (admin only)
+// @Description Per-operation counts of retried, recovered and exhausted calls after transient database errors since startup
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/db-retries [get]
+// @Security BearerAuth
+func (h *AdminHandler) GetDatabaseRetries(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"operations": database.RetryMetrics(),
+	})
+}
+
+// GetSlowEndpoints godoc
+// @Summary Get the slowest endpoints (admin only)
+// @Description Latency percentiles per route over the most recent requests to each route since startup, slowest p95 first
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Number of endpoints (default 10, max 100)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/slow-endpoints [get]
+// @Security BearerAuth
+func (h *AdminHandler) GetSlowEndpoints(c *gin.Context) {
+	var query validators.SlowEndpointsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+
+	// Set defaults
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"endpoints": h.endpointStats.Slowest(query.Limit),
+	})
+}
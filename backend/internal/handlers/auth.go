@@ -10,28 +10,34 @@ import (
 	"github.com/xuangong/backend/internal/models"
 	"github.com/xuangong/backend/internal/services"
 	"github.com/xuangong/backend/internal/validators"
+	"github.com/xuangong/backend/pkg/auth"
 	appErrors "github.com/xuangong/backend/pkg/errors"
 )
 
 type AuthHandler struct {
-	authService *services.AuthService
-	validate    *validator.Validate
+	authService       *services.AuthService
+	invitationService *services.InvitationService
+	validate          *validator.Validate
 }
 
-func NewAuthHandler(authService *services.AuthService) *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, invitationService *services.InvitationService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		validate:    validator.New(),
+		authService:       authService,
+		invitationService: invitationService,
+		validate:          validators.New(),
 	}
 }
 
 // Register godoc
 // @Summary Register a new user
+// @Description With invite_token, the invitation's role, group and programs are applied.
+// @Description When open registration is disabled (OPEN_REGISTRATION=false), only requests with a valid invite_token are accepted.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body validators.RegisterRequest true "Registration details"
 // @Success 201 {object} map[string]interface{}
+// @Failure 403 {object} errors.AppError "REGISTRATION_DISABLED: open registration is turned off"
 // @Router /api/v1/auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req validators.RegisterRequest
@@ -45,13 +51,32 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, tokens, err := h.authService.Register(
-		c.Request.Context(),
-		req.Email,
-		req.Password,
-		req.FullName,
-		models.RoleStudent, // Default role for registration
-	)
+	// Invitations keep working when open registration is disabled
+	if req.InviteToken == "" && !h.authService.RegistrationOpen() {
+		respondWithError(c, appErrors.NewRegistrationDisabledError())
+		return
+	}
+
+	var user *models.User
+	var tokens *auth.TokenPair
+	var err error
+	if req.InviteToken != "" {
+		user, tokens, err = h.invitationService.Register(
+			c.Request.Context(),
+			req.InviteToken,
+			req.Email,
+			req.Password,
+			req.FullName,
+		)
+	} else {
+		user, tokens, err = h.authService.Register(
+			c.Request.Context(),
+			req.Email,
+			req.Password,
+			req.FullName,
+			models.RoleStudent, // Default role for registration
+		)
+	}
 	if err != nil {
 		respondWithAppError(c, err)
 		return
@@ -65,9 +90,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 // Login godoc
 // @Summary Login user
+// @Description The tokens are bound to the device, recognized by user agent, X-Device-Info and network. Signing in from a device not used before notifies the user and emails them a link to sign it out.
 // @Tags auth
 // @Accept json
 // @Produce json
+// @Param X-Device-Info header string false "Client device description"
 // @Param request body validators.LoginRequest true "Login credentials"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/auth/login [post]
@@ -83,10 +110,12 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	clientIP, userAgent, device := middleware.ClientInfo(c)
 	user, tokens, err := h.authService.Login(
 		c.Request.Context(),
 		req.Email,
 		req.Password,
+		models.LoginClient{ClientIP: clientIP, UserAgent: userAgent, Device: device},
 	)
 	if err != nil {
 		respondWithAppError(c, err)
@@ -195,7 +224,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.UpdateProfile(c.Request.Context(), userID, req.Email, req.FullName, req.CountdownVolume, req.StartVolume, req.HalfwayVolume, req.FinishVolume); err != nil {
+	if err := h.authService.UpdateProfile(c.Request.Context(), userID, req.Email, req.FullName, req.CountdownVolume, req.StartVolume, req.HalfwayVolume, req.FinishVolume, req.Language); err != nil {
 		respondWithAppError(c, err)
 		return
 	}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/services"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+func TestAuthHandler_Register_DisabledRegistration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{Features: config.FeaturesConfig{OpenRegistration: false}}
+	// The handler must reject before touching the repository, so no database is needed
+	handler := NewAuthHandler(services.NewAuthService(nil, cfg), nil)
+
+	body, _ := json.Marshal(map[string]string{
+		"email":     "student@example.com",
+		"password":  "password123",
+		"full_name": "New Student",
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Register(c)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Expected status %d but got %d", http.StatusForbidden, w.Code)
+	}
+
+	var response struct {
+		Error struct {
+			Code appErrors.ErrorCode `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Error.Code != appErrors.ErrCodeRegistrationDisabled {
+		t.Errorf("Expected error code %s but got %s", appErrors.ErrCodeRegistrationDisabled, response.Error.Code)
+	}
+}
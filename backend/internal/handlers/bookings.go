@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/ical"
+)
+
+// defaultSlotRange is how far ahead slots are listed when no end is given
+const defaultSlotRange = 14 * 24 * time.Hour
+
+type BookingHandler struct {
+	bookingService *services.BookingService
+	validate       *validator.Validate
+}
+
+func NewBookingHandler(bookingService *services.BookingService) *BookingHandler {
+	return &BookingHandler{
+		bookingService: bookingService,
+		validate:       validators.New(),
+	}
+}
+
+// ListSlots godoc
+// @Summary List availability slots
+// @Description Lists open slots starting between from and to. Instructors filtering by themselves also see booked slots.
+// @Tags bookings
+// @Produce json
+// @Param from query string false "RFC3339, defaults to now"
+// @Param to query string false "RFC3339, defaults to two weeks after from"
+// @Param instructor_id query string false "Only slots of this instructor"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/bookings/slots [get]
+// @Security BearerAuth
+func (h *BookingHandler) ListSlots(c *gin.Context) {
+	var query validators.ListSlotsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, bindError(err, "Invalid query parameters"))
+		return
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	from := time.Now().UTC()
+	if query.From != nil {
+		from = query.From.Time
+	}
+	to := from.Add(defaultSlotRange)
+	if query.To != nil {
+		to = query.To.Time
+	}
+
+	var instructorID *uuid.UUID
+	if query.InstructorID != nil {
+		id := uuid.MustParse(*query.InstructorID) // Checked by the validator
+		instructorID = &id
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	slots, err := h.bookingService.ListSlots(c.Request.Context(), userID, from, to, instructorID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"slots": slots,
+	})
+}
+
+// PublishSlot godoc
+// @Summary Publish an availability slot (admin only)
+// @Description Slots may not overlap other slots of the same instructor
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param request body validators.PublishSlotRequest true "Slot"
+// @Success 201 {object} models.AvailabilitySlot
+// @Failure 409 {object} map[string]interface{} "Overlaps an existing slot"
+// @Router /api/v1/bookings/slots [post]
+// @Security BearerAuth
+func (h *BookingHandler) PublishSlot(c *gin.Context) {
+	var req validators.PublishSlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, bindError(err, "Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	slot, err := h.bookingService.PublishSlot(c.Request.Context(), userID, req.StartsAt.Time, req.EndsAt.Time)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, slot)
+}
+
+// DeleteSlot godoc
+// @Summary Withdraw one of your availability slots (admin only)
+// @Tags bookings
+// @Param id path string true "Slot ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Slot is booked"
+// @Router /api/v1/bookings/slots/{id} [delete]
+// @Security BearerAuth
+func (h *BookingHandler) DeleteSlot(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c, "Invalid slot ID")
+	if !ok {
+		return
+	}
+
+	if err := h.bookingService.DeleteSlot(c.Request.Context(), id, userID); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Slot deleted successfully",
+	})
+}
+
+// ListBookings godoc
+// @Summary List your bookings, as student or as instructor
+// @Tags bookings
+// @Produce json
+// @Param upcoming query bool false "Only confirmed bookings that have not ended"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/bookings [get]
+// @Security BearerAuth
+func (h *BookingHandler) ListBookings(c *gin.Context) {
+	var query validators.ListBookingsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	bookings, err := h.bookingService.ListBookings(c.Request.Context(), userID, query.Upcoming)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bookings": bookings,
+	})
+}
+
+// BookSlot godoc
+// @Summary Book a slot for a 1:1 video review of one of your programs
+// @Description The student and the instructor receive a confirmation email with a calendar invitation
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param request body validators.BookSlotRequest true "Booking"
+// @Success 201 {object} models.Booking
+// @Failure 409 {object} map[string]interface{} "Slot taken or overlapping booking"
+// @Router /api/v1/bookings [post]
+// @Security BearerAuth
+func (h *BookingHandler) BookSlot(c *gin.Context) {
+	var req validators.BookSlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	// IDs were checked by the validator
+	booking, err := h.bookingService.Book(c.Request.Context(), userID, middleware.IsAdmin(c), uuid.MustParse(req.SlotID), uuid.MustParse(req.ProgramID), req.Note)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, booking)
+}
+
+// GetBooking godoc
+// @Summary Get one of your bookings
+// @Tags bookings
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} models.Booking
+// @Router /api/v1/bookings/{id} [get]
+// @Security BearerAuth
+func (h *BookingHandler) GetBooking(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c, "Invalid booking ID")
+	if !ok {
+		return
+	}
+
+	booking, err := h.bookingService.GetBooking(c.Request.Context(), id, userID, middleware.IsAdmin(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
+// CancelBooking godoc
+// @Summary Cancel a booking
+// @Description Students can cancel up to BOOKING_CANCEL_NOTICE_HOURS before the start, instructors any time. Both receive a calendar cancellation.
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body validators.CancelBookingRequest false "Reason"
+// @Success 200 {object} models.Booking
+// @Failure 409 {object} map[string]interface{} "Already cancelled or too late"
+// @Router /api/v1/bookings/{id}/cancel [post]
+// @Security BearerAuth
+func (h *BookingHandler) CancelBooking(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c, "Invalid booking ID")
+	if !ok {
+		return
+	}
+
+	var req validators.CancelBookingRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+			return
+		}
+		if err := h.validate.Struct(req); err != nil {
+			respondWithValidationError(c, err)
+			return
+		}
+	}
+
+	booking, err := h.bookingService.Cancel(c.Request.Context(), id, userID, middleware.IsAdmin(c), req.Reason)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
+// DownloadCalendar godoc
+// @Summary Download a booking as an iCalendar file
+// @Tags bookings
+// @Produce text/calendar
+// @Param id path string true "Booking ID"
+// @Success 200 {file} file
+// @Router /api/v1/bookings/{id}/ical [get]
+// @Security BearerAuth
+func (h *BookingHandler) DownloadCalendar(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c, "Invalid booking ID")
+	if !ok {
+		return
+	}
+
+	data, err := h.bookingService.Calendar(c.Request.Context(), id, userID, middleware.IsAdmin(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="booking-%s.ics"`, id))
+	c.Data(http.StatusOK, ical.ContentType, data)
+}
+
+// parseIDs reads the ID from the path and the current user, responding on failure
+func (h *BookingHandler) parseIDs(c *gin.Context, invalidIDMessage string) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError(invalidIDMessage))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, userID, true
+}
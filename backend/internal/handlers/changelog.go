@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type ChangelogHandler struct {
+	changelogService *services.ChangelogService
+	validate         *validator.Validate
+}
+
+func NewChangelogHandler(changelogService *services.ChangelogService) *ChangelogHandler {
+	return &ChangelogHandler{
+		changelogService: changelogService,
+		validate:         validators.New(),
+	}
+}
+
+// ListChangelog godoc
+// @Summary List the changelog
+// @Description Entries for everyone and for the user's role, newest first. Admins see every audience.
+// @Tags changelog
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/changelog [get]
+// @Security BearerAuth
+func (h *ChangelogHandler) ListChangelog(c *gin.Context) {
+	var query validators.ListChangelogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	entries, err := h.changelogService.List(c.Request.Context(), middleware.IsAdmin(c), query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+	})
+}
+
+// ListUnseen godoc
+// @Summary List the changelog entries the current user has not dismissed
+// @Description For showing release notes after an app update. Entries written before the user signed up are left out.
+// @Tags changelog
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/changelog/unseen [get]
+// @Security BearerAuth
+func (h *ChangelogHandler) ListUnseen(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	entries, err := h.changelogService.Unseen(c.Request.Context(), userID, middleware.IsAdmin(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+	})
+}
+
+// Dismiss godoc
+// @Summary Dismiss changelog entries
+// @Description Marks the entries as seen on all of the user's devices. Pass the IDs that were shown, so entries published in the meantime stay unseen. Unknown IDs are ignored.
+// @Tags changelog
+// @Accept json
+// @Param request body validators.DismissChangelogRequest true "Entries"
+// @Success 204
+// @Router /api/v1/changelog/dismiss [post]
+// @Security BearerAuth
+func (h *ChangelogHandler) Dismiss(c *gin.Context) {
+	var req validators.DismissChangelogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	entryIDs := make([]uuid.UUID, len(req.EntryIDs))
+	for i, id := range req.EntryIDs {
+		entryIDs[i] = uuid.MustParse(id) // Checked by the validator
+	}
+	if err := h.changelogService.Dismiss(c.Request.Context(), userID, entryIDs); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateEntry godoc
+// @Summary Add a changelog entry (admin only)
+// @Description body is Markdown and returned rendered as rendered_html. audience is all (default), students or admins.
+// @Tags changelog
+// @Accept json
+// @Produce json
+// @Param request body validators.CreateChangelogEntryRequest true "Entry"
+// @Success 201 {object} models.ChangelogEntry
+// @Router /api/v1/changelog [post]
+// @Security BearerAuth
+func (h *ChangelogHandler) CreateEntry(c *gin.Context) {
+	var req validators.CreateChangelogEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	entry := &models.ChangelogEntry{
+		Version:   req.Version,
+		Title:     req.Title,
+		Body:      req.Body,
+		Audience:  models.ChangelogAudience(req.Audience),
+		CreatedBy: &userID,
+	}
+	if err := h.changelogService.Create(c.Request.Context(), entry); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateEntry godoc
+// @Summary Change a changelog entry (admin only)
+// @Description Omitted fields are left unchanged. Users who dismissed the entry are not shown it again.
+// @Tags changelog
+// @Accept json
+// @Produce json
+// @Param id path string true "Entry ID"
+// @Param request body validators.UpdateChangelogEntryRequest true "Changes"
+// @Success 200 {object} models.ChangelogEntry
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/changelog/{id} [put]
+// @Security BearerAuth
+func (h *ChangelogHandler) UpdateEntry(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid entry ID"))
+		return
+	}
+
+	var req validators.UpdateChangelogEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	update := &models.ChangelogEntryUpdate{
+		Version: req.Version,
+		Title:   req.Title,
+		Body:    req.Body,
+	}
+	if req.Audience != nil {
+		audience := models.ChangelogAudience(*req.Audience)
+		update.Audience = &audience
+	}
+	entry, err := h.changelogService.Update(c.Request.Context(), id, update)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteEntry godoc
+// @Summary Delete a changelog entry (admin only)
+// @Tags changelog
+// @Param id path string true "Entry ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/changelog/{id} [delete]
+// @Security BearerAuth
+func (h *ChangelogHandler) DeleteEntry(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid entry ID"))
+		return
+	}
+
+	if err := h.changelogService.Delete(c.Request.Context(), id); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type ClientVersionHandler struct {
+	clientVersionService *services.ClientVersionService
+	validate             *validator.Validate
+}
+
+func NewClientVersionHandler(clientVersionService *services.ClientVersionService) *ClientVersionHandler {
+	return &ClientVersionHandler{
+		clientVersionService: clientVersionService,
+		validate:             validators.New(),
+	}
+}
+
+// ListPolicies godoc
+// @Summary List the client version policy per platform (admin only)
+// @Description The oldest supported app version and upgrade URL for ios, android and web, and whether each comes from the configuration or an admin override
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/client-versions [get]
+// @Security BearerAuth
+func (h *ClientVersionHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.clientVersionService.List(c.Request.Context())
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"policies": policies,
+	})
+}
+
+// SetPolicy godoc
+// @Summary Override the client version policy of a platform (admin only)
+// @Description Clients older than min_version get 426 UPGRADE_REQUIRED with upgrade_url. An empty min_version allows every version.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param platform path string true "ios, android or web"
+// @Param request body validators.SetClientVersionPolicyRequest true "Policy"
+// @Success 200 {object} models.ClientVersionPolicy
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/client-versions/{platform} [put]
+// @Security BearerAuth
+func (h *ClientVersionHandler) SetPolicy(c *gin.Context) {
+	var req validators.SetClientVersionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	policy, err := h.clientVersionService.Set(c.Request.Context(), c.Param("platform"), req.MinVersion, req.UpgradeURL, userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// ResetPolicy godoc
+// @Summary Drop the admin override of a platform's client version policy (admin only)
+// @Description The configured policy applies again
+// @Tags admin
+// @Produce json
+// @Param platform path string true "ios, android or web"
+// @Success 200 {object} models.ClientVersionPolicy
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/client-versions/{platform} [delete]
+// @Security BearerAuth
+func (h *ClientVersionHandler) ResetPolicy(c *gin.Context) {
+	policy, err := h.clientVersionService.Reset(c.Request.Context(), c.Param("platform"))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/internal/contract"
+)
+
+type ContractHandler struct {
+	document *contract.Document
+}
+
+func NewContractHandler() *ContractHandler {
+	return &ContractHandler{
+		document: contract.Generate(),
+	}
+}
+
+// GetContract godoc
+// @Summary Response contract
+// @Description JSON Schema of all API response types. Clients can compare its version with the one they were built against.
+// @Tags contract
+// @Produce json
+// @Success 200 {object} contract.Document
+// @Router /api/v1/contract [get]
+func (h *ContractHandler) GetContract(c *gin.Context) {
+	c.JSON(http.StatusOK, h.document)
+}
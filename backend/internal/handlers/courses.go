@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type CourseHandler struct {
+	courseService *services.CourseService
+	validate      *validator.Validate
+}
+
+func NewCourseHandler(courseService *services.CourseService) *CourseHandler {
+	return &CourseHandler{
+		courseService: courseService,
+		validate:      validators.New(),
+	}
+}
+
+// ListCourses godoc
+// @Summary List courses
+// @Description Admins see all courses, students published courses and courses they are enrolled in
+// @Tags courses
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/courses [get]
+// @Security BearerAuth
+func (h *CourseHandler) ListCourses(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	courses, err := h.courseService.List(c.Request.Context(), userID, middleware.IsAdmin(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"courses": courses,
+	})
+}
+
+// GetCourse godoc
+// @Summary Get a course with its modules and programs
+// @Tags courses
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} models.Course
+// @Router /api/v1/courses/{id} [get]
+// @Security BearerAuth
+func (h *CourseHandler) GetCourse(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	course, err := h.courseService.Get(c.Request.Context(), id, userID, middleware.IsAdmin(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// CreateCourse godoc
+// @Summary Create a course (admin only)
+// @Description Modules are ordered as given and unlock immediately, after the previous modules are complete, or a number of days after enrollment
+// @Tags courses
+// @Accept json
+// @Produce json
+// @Param request body validators.CreateCourseRequest true "Course"
+// @Success 201 {object} models.Course
+// @Router /api/v1/courses [post]
+// @Security BearerAuth
+func (h *CourseHandler) CreateCourse(c *gin.Context) {
+	var req validators.CreateCourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	course, err := h.courseService.Create(c.Request.Context(), userID, req.Name, req.Description, req.IsPublished, toCourseModules(req.Modules))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, course)
+}
+
+// UpdateCourse godoc
+// @Summary Update a course (admin only)
+// @Description Passing modules replaces the whole curriculum
+// @Tags courses
+// @Accept json
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param request body validators.UpdateCourseRequest true "Fields to change"
+// @Success 200 {object} models.Course
+// @Router /api/v1/courses/{id} [put]
+// @Security BearerAuth
+func (h *CourseHandler) UpdateCourse(c *gin.Context) {
+	id, _, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req validators.UpdateCourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	var modules []models.CourseModule
+	if req.Modules != nil {
+		modules = toCourseModules(*req.Modules)
+	}
+
+	course, err := h.courseService.Update(c.Request.Context(), id, req.Name, req.Description, req.IsPublished, modules)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// DeleteCourse godoc
+// @Summary Delete a course (admin only)
+// @Description Enrolled students keep the programs already assigned to them
+// @Tags courses
+// @Param id path string true "Course ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/courses/{id} [delete]
+// @Security BearerAuth
+func (h *CourseHandler) DeleteCourse(c *gin.Context) {
+	id, _, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.courseService.Delete(c.Request.Context(), id); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Course deleted successfully",
+	})
+}
+
+// Enroll godoc
+// @Summary Enroll yourself in a published course
+// @Description Programs of modules that are already unlocked are assigned to you
+// @Tags courses
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} models.CourseProgress
+// @Router /api/v1/courses/{id}/enroll [post]
+// @Security BearerAuth
+func (h *CourseHandler) Enroll(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	progress, err := h.courseService.EnrollSelf(c.Request.Context(), id, userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// GetProgress godoc
+// @Summary Get progress through a course
+// @Description Computed from completed practice sessions. Programs of newly unlocked modules are assigned on the way.
+// @Tags courses
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param user_id query string false "Student to look at (admin only)"
+// @Success 200 {object} models.CourseProgress
+// @Router /api/v1/courses/{id}/progress [get]
+// @Security BearerAuth
+func (h *CourseHandler) GetProgress(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var query validators.CourseProgressQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+	if query.UserID != nil {
+		if !middleware.IsAdmin(c) {
+			respondWithError(c, appErrors.NewAuthorizationError("Only admins can view other students' progress"))
+			return
+		}
+		userID = uuid.MustParse(*query.UserID) // Checked by the validator
+	}
+
+	progress, err := h.courseService.Progress(c.Request.Context(), id, userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// ListEnrollments godoc
+// @Summary List the students enrolled in a course (admin only)
+// @Tags courses
+// @Produce json
+// @Param id path string true "Course ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/courses/{id}/enrollments [get]
+// @Security BearerAuth
+func (h *CourseHandler) ListEnrollments(c *gin.Context) {
+	id, _, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	enrollments, err := h.courseService.ListEnrollments(c.Request.Context(), id)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enrollments": enrollments,
+	})
+}
+
+// EnrollUsers godoc
+// @Summary Enroll students in a course (admin only)
+// @Tags courses
+// @Accept json
+// @Produce json
+// @Param id path string true "Course ID"
+// @Param request body validators.EnrollUsersRequest true "Students"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/courses/{id}/enrollments [post]
+// @Security BearerAuth
+func (h *CourseHandler) EnrollUsers(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req validators.EnrollUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userIDs := make([]uuid.UUID, len(req.UserIDs))
+	for i, s := range req.UserIDs {
+		userIDs[i] = uuid.MustParse(s) // Checked by the validator
+	}
+
+	enrolled, err := h.courseService.Enroll(c.Request.Context(), id, userID, userIDs)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enrolled": enrolled,
+	})
+}
+
+// UnenrollUser godoc
+// @Summary Remove a student from a course (admin only)
+// @Description Programs already assigned through the course stay assigned
+// @Tags courses
+// @Param id path string true "Course ID"
+// @Param userId path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/courses/{id}/enrollments/{userId} [delete]
+// @Security BearerAuth
+func (h *CourseHandler) UnenrollUser(c *gin.Context) {
+	id, _, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid user ID"))
+		return
+	}
+
+	if err := h.courseService.Unenroll(c.Request.Context(), id, userID); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Enrollment removed successfully",
+	})
+}
+
+// parseIDs reads the course ID from the path and the current user, responding on failure
+func (h *CourseHandler) parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid course ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, userID, true
+}
+
+// toCourseModules converts validated module requests to models
+func toCourseModules(reqs []validators.CourseModuleRequest) []models.CourseModule {
+	modules := make([]models.CourseModule, len(reqs))
+	for i, req := range reqs {
+		modules[i] = models.CourseModule{
+			Title:           req.Title,
+			Description:     req.Description,
+			UnlockRule:      models.UnlockRule(req.UnlockRule),
+			UnlockAfterDays: req.UnlockAfterDays,
+			Programs:        make([]models.ModuleProgram, len(req.Programs)),
+		}
+		for j, program := range req.Programs {
+			modules[i].Programs[j] = models.ModuleProgram{
+				ProgramID:        uuid.MustParse(program.ProgramID),
+				RequiredSessions: program.RequiredSessions,
+			}
+		}
+	}
+	return modules
+}
@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type DiaryHandler struct {
+	diaryService *services.DiaryService
+	validate     *validator.Validate
+}
+
+func NewDiaryHandler(diaryService *services.DiaryService) *DiaryHandler {
+	return &DiaryHandler{
+		diaryService: diaryService,
+		validate:     validators.New(),
+	}
+}
+
+// ListDiary godoc
+// @Summary List the current user's practice diary
+// @Description Entries are ordered by entry_date, newest first. from and to are inclusive.
+// @Tags diary
+// @Produce json
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param session_id query string false "Only entries linked to this session"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/diary [get]
+// @Security BearerAuth
+func (h *DiaryHandler) ListDiary(c *gin.Context) {
+	var query validators.ListDiaryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	filter := repositories.DiaryFilter{
+		From:      query.From,
+		To:        query.To,
+		SessionID: parseOptionalUUID(query.SessionID),
+	}
+	entries, err := h.diaryService.List(c.Request.Context(), userID, filter, query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+	})
+}
+
+// SearchDiary godoc
+// @Summary Search the current user's practice diary
+// @Description Full-text search over titles and content. Supports quoted phrases, OR and -exclusions. Matches are wrapped in <mark> in title_highlight and snippet.
+// @Tags diary
+// @Produce json
+// @Param q query string true "Search query"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/diary/search [get]
+// @Security BearerAuth
+func (h *DiaryHandler) SearchDiary(c *gin.Context) {
+	var query validators.SearchDiaryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	results, err := h.diaryService.Search(c.Request.Context(), userID, query.Q, query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"query":   query.Q,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+		"count":   len(results),
+	})
+}
+
+// CreateDiaryEntry godoc
+// @Summary Write a practice diary entry
+// @Description content is Markdown and returned rendered as rendered_html. Linked sessions must be the user's own.
+// @Tags diary
+// @Accept json
+// @Produce json
+// @Param request body validators.CreateDiaryEntryRequest true "Entry"
+// @Success 201 {object} models.DiaryEntry
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/diary [post]
+// @Security BearerAuth
+func (h *DiaryHandler) CreateDiaryEntry(c *gin.Context) {
+	var req validators.CreateDiaryEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	entry := &models.DiaryEntry{
+		UserID:     userID,
+		EntryDate:  req.EntryDate,
+		Title:      req.Title,
+		Content:    req.Content,
+		SessionIDs: parseSessionIDs(req.SessionIDs),
+		Shared:     req.Shared,
+	}
+	if err := h.diaryService.Create(c.Request.Context(), entry); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// GetDiaryEntry godoc
+// @Summary Get a practice diary entry
+// @Description Students see their own entries, instructors the ones students shared.
+// @Tags diary
+// @Produce json
+// @Param id path string true "Entry ID"
+// @Success 200 {object} models.DiaryEntry
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/diary/{id} [get]
+// @Security BearerAuth
+func (h *DiaryHandler) GetDiaryEntry(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.diaryService.Get(c.Request.Context(), id, userID, middleware.IsAdmin(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// UpdateDiaryEntry godoc
+// @Summary Change one of the current user's diary entries
+// @Description Omitted fields are left unchanged; session_ids replaces the linked sessions.
+// @Tags diary
+// @Accept json
+// @Produce json
+// @Param id path string true "Entry ID"
+// @Param request body validators.UpdateDiaryEntryRequest true "Changes"
+// @Success 200 {object} models.DiaryEntry
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/diary/{id} [put]
+// @Security BearerAuth
+func (h *DiaryHandler) UpdateDiaryEntry(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req validators.UpdateDiaryEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	update := &models.DiaryEntryUpdate{
+		EntryDate: req.EntryDate,
+		Title:     req.Title,
+		Content:   req.Content,
+		Shared:    req.Shared,
+	}
+	if req.SessionIDs != nil {
+		sessionIDs := parseSessionIDs(*req.SessionIDs)
+		update.SessionIDs = &sessionIDs
+	}
+	entry, err := h.diaryService.Update(c.Request.Context(), id, userID, update)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteDiaryEntry godoc
+// @Summary Delete one of the current user's diary entries
+// @Tags diary
+// @Param id path string true "Entry ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/diary/{id} [delete]
+// @Security BearerAuth
+func (h *DiaryHandler) DeleteDiaryEntry(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.diaryService.Delete(c.Request.Context(), id, userID); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListUserDiary godoc
+// @Summary List the diary entries a student shared (admin only)
+// @Tags diary
+// @Produce json
+// @Param id path string true "User ID"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param session_id query string false "Only entries linked to this session"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/users/{id}/diary [get]
+// @Security BearerAuth
+func (h *DiaryHandler) ListUserDiary(c *gin.Context) {
+	studentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid user ID"))
+		return
+	}
+
+	var query validators.ListDiaryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	filter := repositories.DiaryFilter{
+		From:      query.From,
+		To:        query.To,
+		SessionID: parseOptionalUUID(query.SessionID),
+	}
+	entries, err := h.diaryService.ListShared(c.Request.Context(), studentID, filter, query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+	})
+}
+
+// parseIDs reads the entry ID from the path and the current user, responding on failure
+func (h *DiaryHandler) parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid diary entry ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return id, userID, true
+}
+
+func parseSessionIDs(ids []string) []uuid.UUID {
+	sessionIDs := make([]uuid.UUID, len(ids))
+	for i, id := range ids {
+		sessionIDs[i] = uuid.MustParse(id) // Checked by the validator
+	}
+	return sessionIDs
+}
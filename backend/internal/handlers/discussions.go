@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type DiscussionHandler struct {
+	discussionService *services.DiscussionService
+	validate          *validator.Validate
+}
+
+func NewDiscussionHandler(discussionService *services.DiscussionService) *DiscussionHandler {
+	return &DiscussionHandler{
+		discussionService: discussionService,
+		validate:          validators.New(),
+	}
+}
+
+// ListTopics godoc
+// @Summary List the topics of a program's discussion board
+// @Description Pinned topics come first, then the most recently active. Visible to admins, the program owner and assigned students.
+// @Tags discussions
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param limit query int false "Page size (default 50, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/topics [get]
+// @Security BearerAuth
+func (h *DiscussionHandler) ListTopics(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	var query validators.ListTopicsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 50
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	topics, err := h.discussionService.ListTopics(c.Request.Context(), programID, userID, middleware.IsAdmin(c), query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"topics": topics,
+	})
+}
+
+// CreateTopic godoc
+// @Summary Open a topic on a program's discussion board
+// @Description Members can be mentioned with @[Name](user-id) and are notified
+// @Tags discussions
+// @Accept json
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param request body validators.CreateTopicRequest true "Topic"
+// @Success 201 {object} models.DiscussionTopic
+// @Router /api/v1/programs/{id}/topics [post]
+// @Security BearerAuth
+func (h *DiscussionHandler) CreateTopic(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	var req validators.CreateTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	topic, err := h.discussionService.CreateTopic(c.Request.Context(), programID, userID, middleware.IsAdmin(c), req.Title, req.Content, req.YouTubeURL)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, topic)
+}
+
+// GetTopic godoc
+// @Summary Get a discussion topic with its replies
+// @Tags discussions
+// @Produce json
+// @Param id path string true "Topic ID"
+// @Success 200 {object} models.DiscussionTopicWithReplies
+// @Router /api/v1/topics/{id} [get]
+// @Security BearerAuth
+func (h *DiscussionHandler) GetTopic(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	topic, err := h.discussionService.GetTopic(c.Request.Context(), id, userID, middleware.IsAdmin(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, topic)
+}
+
+// ModerateTopic godoc
+// @Summary Pin or lock a discussion topic (admin only)
+// @Description Locked topics only accept replies from admins
+// @Tags discussions
+// @Accept json
+// @Produce json
+// @Param id path string true "Topic ID"
+// @Param request body validators.ModerateTopicRequest true "Flags to change"
+// @Success 200 {object} models.DiscussionTopic
+// @Router /api/v1/topics/{id} [put]
+// @Security BearerAuth
+func (h *DiscussionHandler) ModerateTopic(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid topic ID"))
+		return
+	}
+
+	var req validators.ModerateTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	topic, err := h.discussionService.ModerateTopic(c.Request.Context(), id, req.IsPinned, req.IsLocked)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, topic)
+}
+
+// DeleteTopic godoc
+// @Summary Delete a discussion topic
+// @Description Students can delete their own topics, admins any topic
+// @Tags discussions
+// @Param id path string true "Topic ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/topics/{id} [delete]
+// @Security BearerAuth
+func (h *DiscussionHandler) DeleteTopic(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.discussionService.DeleteTopic(c.Request.Context(), id, userID, middleware.IsAdmin(c)); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Topic deleted successfully",
+	})
+}
+
+// CreateReply godoc
+// @Summary Reply to a discussion topic
+// @Description The topic author and mentioned members are notified
+// @Tags discussions
+// @Accept json
+// @Produce json
+// @Param id path string true "Topic ID"
+// @Param request body validators.CreateReplyRequest true "Reply"
+// @Success 201 {object} models.DiscussionReply
+// @Router /api/v1/topics/{id}/replies [post]
+// @Security BearerAuth
+func (h *DiscussionHandler) CreateReply(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req validators.CreateReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	reply, err := h.discussionService.CreateReply(c.Request.Context(), id, userID, middleware.IsAdmin(c), req.Content, req.YouTubeURL)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reply)
+}
+
+// DeleteReply godoc
+// @Summary Delete a reply to a discussion topic
+// @Description Students can delete their own replies, admins any reply
+// @Tags discussions
+// @Param id path string true "Topic ID"
+// @Param replyId path string true "Reply ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/topics/{id}/replies/{replyId} [delete]
+// @Security BearerAuth
+func (h *DiscussionHandler) DeleteReply(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+	replyID, err := uuid.Parse(c.Param("replyId"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid reply ID"))
+		return
+	}
+
+	if err := h.discussionService.DeleteReply(c.Request.Context(), id, replyID, userID, middleware.IsAdmin(c)); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reply deleted successfully",
+	})
+}
+
+// parseIDs reads the topic ID from the path and the current user, responding on failure
+func (h *DiscussionHandler) parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid topic ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, userID, true
+}
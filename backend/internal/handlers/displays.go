@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type DisplayHandler struct {
+	displayService *services.DisplayService
+	validate       *validator.Validate
+}
+
+func NewDisplayHandler(displayService *services.DisplayService) *DisplayHandler {
+	return &DisplayHandler{
+		displayService: displayService,
+		validate:       validators.New(),
+	}
+}
+
+// CreateDisplayToken godoc
+// @Summary Create a read-only display token for shared screens (admin only)
+// @Description The token is only returned in this response. It can read the listed programs' timers and nothing else.
+// @Tags displays
+// @Accept json
+// @Produce json
+// @Param request body validators.CreateDisplayTokenRequest true "Display details"
+// @Success 201 {object} models.DisplayToken
+// @Router /api/v1/display-tokens [post]
+// @Security BearerAuth
+func (h *DisplayHandler) CreateDisplayToken(c *gin.Context) {
+	var req validators.CreateDisplayTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	programIDs := make([]uuid.UUID, 0, len(req.ProgramIDs))
+	for _, idStr := range req.ProgramIDs {
+		programIDs = append(programIDs, uuid.MustParse(idStr)) // validated above
+	}
+
+	display, err := h.displayService.Create(c.Request.Context(), userID, req.Name, programIDs, req.ExpiresInDays)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, display)
+}
+
+// ListDisplayTokens godoc
+// @Summary List display tokens (admin only)
+// @Tags displays
+// @Produce json
+// @Param active_only query boolean false "Only tokens that are neither revoked nor expired"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/display-tokens [get]
+// @Security BearerAuth
+func (h *DisplayHandler) ListDisplayTokens(c *gin.Context) {
+	var query validators.ListDisplayTokensQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+
+	tokens, err := h.displayService.List(c.Request.Context(), query.ActiveOnly)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"display_tokens": tokens})
+}
+
+// RevokeDisplayToken godoc
+// @Summary Revoke a display token (admin only)
+// @Tags displays
+// @Produce json
+// @Param id path string true "Display token ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/display-tokens/{id} [delete]
+// @Security BearerAuth
+func (h *DisplayHandler) RevokeDisplayToken(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid display token ID"))
+		return
+	}
+
+	if err := h.displayService.Revoke(c.Request.Context(), id); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Display token revoked",
+	})
+}
+
+// ListDisplayPrograms godoc
+// @Summary List the programs a display can show
+// @Tags displays
+// @Produce json
+// @Param token query string false "Display token, if not sent as Bearer token"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/display/programs [get]
+// @Security BearerAuth
+func (h *DisplayHandler) ListDisplayPrograms(c *gin.Context) {
+	display, err := middleware.GetDisplayToken(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	programs, err := h.displayService.ListPrograms(c.Request.Context(), display)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"programs": programs})
+}
+
+// GetDisplayProgram godoc
+// @Summary Get a program's routine timer for a display
+// @Description Name, description and timeline in the default settings; no user data
+// @Tags displays
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param token query string false "Display token, if not sent as Bearer token"
+// @Success 200 {object} models.DisplayProgram
+// @Router /api/v1/display/programs/{id} [get]
+// @Security BearerAuth
+func (h *DisplayHandler) GetDisplayProgram(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	display, err := middleware.GetDisplayToken(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	program, err := h.displayService.GetProgram(c.Request.Context(), display, id)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, program)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type EmbedHandler struct {
+	embedService *services.EmbedService
+	validate     *validator.Validate
+}
+
+func NewEmbedHandler(embedService *services.EmbedService) *EmbedHandler {
+	return &EmbedHandler{
+		embedService: embedService,
+		validate:     validators.New(),
+	}
+}
+
+// GetEmbedProgram godoc
+// @Summary Get a shared program's preview for embedded widgets
+// @Description Public and readable from any origin; rate-limited per IP. The token is the program's share link token.
+// @Tags embed
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} models.ProgramPreview
+// @Router /api/v1/embed/programs/{token} [get]
+func (h *EmbedHandler) GetEmbedProgram(c *gin.Context) {
+	preview, err := h.embedService.Preview(c.Request.Context(), c.Param("token"), middleware.GetLocale(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// GetOEmbed godoc
+// @Summary oEmbed endpoint for shared program URLs
+// @Description Returns a "rich" oEmbed response with an iframe of the preview widget. Only JSON is supported.
+// @Tags embed
+// @Produce json
+// @Param url query string true "Share link or widget URL"
+// @Param maxwidth query int false "Maximum iframe width"
+// @Param maxheight query int false "Maximum iframe height"
+// @Param format query string false "json (xml is not implemented)"
+// @Success 200 {object} models.OEmbed
+// @Failure 501 {object} map[string]interface{} "format=xml"
+// @Router /api/v1/oembed [get]
+func (h *EmbedHandler) GetOEmbed(c *gin.Context) {
+	var query validators.OEmbedQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+	if query.Format == "xml" {
+		respondWithError(c, appErrors.NewNotImplementedError("Only the json format is supported"))
+		return
+	}
+
+	embed, err := h.embedService.OEmbed(c.Request.Context(), query.URL, query.MaxWidth, query.MaxHeight, middleware.GetLocale(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, embed)
+}
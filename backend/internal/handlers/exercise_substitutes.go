@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type ExerciseSubstituteHandler struct {
+	substituteService *services.ExerciseSubstituteService
+	validate          *validator.Validate
+}
+
+func NewExerciseSubstituteHandler(substituteService *services.ExerciseSubstituteService) *ExerciseSubstituteHandler {
+	return &ExerciseSubstituteHandler{
+		substituteService: substituteService,
+		validate:          validators.New(),
+	}
+}
+
+// ListSubstitutes godoc
+// @Summary List the substitutes of an exercise (admin only)
+// @Description Students see substitutes with the program's exercises
+// @Tags exercises
+// @Produce json
+// @Param id path string true "Exercise ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/exercises/{id}/substitutes [get]
+// @Security BearerAuth
+func (h *ExerciseSubstituteHandler) ListSubstitutes(c *gin.Context) {
+	exerciseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid exercise ID"))
+		return
+	}
+
+	substitutes, err := h.substituteService.List(c.Request.Context(), exerciseID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"substitutes": substitutes,
+	})
+}
+
+// CreateSubstitute godoc
+// @Summary Add a substitute to an exercise (admin only)
+// @Description A variant students can do instead, such as a seated version for knee injuries. Timing left out is taken from the exercise.
+// @Tags exercises
+// @Accept json
+// @Produce json
+// @Param id path string true "Exercise ID"
+// @Param request body validators.ExerciseSubstituteRequest true "Substitute"
+// @Success 201 {object} models.ExerciseSubstitute
+// @Router /api/v1/exercises/{id}/substitutes [post]
+// @Security BearerAuth
+func (h *ExerciseSubstituteHandler) CreateSubstitute(c *gin.Context) {
+	exerciseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid exercise ID"))
+		return
+	}
+
+	substitute, ok := h.bindSubstitute(c)
+	if !ok {
+		return
+	}
+
+	if err := h.substituteService.Create(c.Request.Context(), exerciseID, substitute); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, substitute)
+}
+
+// UpdateSubstitute godoc
+// @Summary Replace a substitute of an exercise (admin only)
+// @Tags exercises
+// @Accept json
+// @Produce json
+// @Param id path string true "Exercise ID"
+// @Param substituteId path string true "Substitute ID"
+// @Param request body validators.ExerciseSubstituteRequest true "Substitute"
+// @Success 200 {object} models.ExerciseSubstitute
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/exercises/{id}/substitutes/{substituteId} [put]
+// @Security BearerAuth
+func (h *ExerciseSubstituteHandler) UpdateSubstitute(c *gin.Context) {
+	exerciseID, substituteID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	substitute, ok := h.bindSubstitute(c)
+	if !ok {
+		return
+	}
+
+	if err := h.substituteService.Update(c.Request.Context(), exerciseID, substituteID, substitute); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, substitute)
+}
+
+// DeleteSubstitute godoc
+// @Summary Delete a substitute of an exercise (admin only)
+// @Description Students who chose it do the exercise again; logged sessions keep the exercise without the variant
+// @Tags exercises
+// @Param id path string true "Exercise ID"
+// @Param substituteId path string true "Substitute ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/exercises/{id}/substitutes/{substituteId} [delete]
+// @Security BearerAuth
+func (h *ExerciseSubstituteHandler) DeleteSubstitute(c *gin.Context) {
+	exerciseID, substituteID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.substituteService.Delete(c.Request.Context(), exerciseID, substituteID); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Substitute deleted successfully",
+	})
+}
+
+// parseIDs reads the exercise and substitute IDs from the path, responding on failure
+func (h *ExerciseSubstituteHandler) parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	exerciseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid exercise ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	substituteID, err := uuid.Parse(c.Param("substituteId"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid substitute ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return exerciseID, substituteID, true
+}
+
+// bindSubstitute reads and validates a substitute request, responding with the error if it fails
+func (h *ExerciseSubstituteHandler) bindSubstitute(c *gin.Context) (*models.ExerciseSubstitute, bool) {
+	var req validators.ExerciseSubstituteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return nil, false
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return nil, false
+	}
+
+	return &models.ExerciseSubstitute{
+		Name:                req.Name,
+		Description:         req.Description,
+		Reason:              req.Reason,
+		DurationSeconds:     req.DurationSeconds,
+		Repetitions:         req.Repetitions,
+		SideDurationSeconds: req.SideDurationSeconds,
+		Contraindications:   req.Contraindications,
+	}, true
+}
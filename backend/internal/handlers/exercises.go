@@ -1,32 +1,57 @@
 package handlers
 
 import (
-	"log"
+	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
 	"github.com/xuangong/backend/internal/models"
 	"github.com/xuangong/backend/internal/services"
 	"github.com/xuangong/backend/internal/validators"
 	appErrors "github.com/xuangong/backend/pkg/errors"
 )
 
+//go:generate go tool moq -skip-ensure -rm -out ../../pkg/testutil/mocks/exercise_service.go -pkg mocks . ExerciseService
+//go:generate go tool moq -skip-ensure -rm -out ../../pkg/testutil/mocks/localizer.go -pkg mocks . Localizer
+
+// ExerciseService is the exercise management used by ExerciseHandler, implemented by services.ExerciseService
+type ExerciseService interface {
+	ListByProgram(ctx context.Context, programID uuid.UUID) (*models.ProgramWithExercises, error)
+	Create(ctx context.Context, exercise *models.Exercise, userID uuid.UUID, userRole models.UserRole) error
+	Update(ctx context.Context, id uuid.UUID, updates *models.Exercise, userID uuid.UUID, userRole models.UserRole) error
+	Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID, userRole models.UserRole) error
+	ReorderExercises(ctx context.Context, programID uuid.UUID, exerciseIDs []uuid.UUID, userID uuid.UUID, userRole models.UserRole) error
+}
+
+var _ ExerciseService = (*services.ExerciseService)(nil)
+
+// Localizer translates program and exercise content, implemented by services.TranslationService
+type Localizer interface {
+	Localize(ctx context.Context, programs []models.ProgramWithExercises, locale string) error
+}
+
+var _ Localizer = (*services.TranslationService)(nil)
+
 type ExerciseHandler struct {
-	exerciseService *services.ExerciseService
-	validate        *validator.Validate
+	exerciseService    ExerciseService
+	translationService Localizer
+	validate           *validator.Validate
 }
 
-func NewExerciseHandler(exerciseService *services.ExerciseService) *ExerciseHandler {
+func NewExerciseHandler(exerciseService ExerciseService, translationService Localizer) *ExerciseHandler {
 	return &ExerciseHandler{
-		exerciseService: exerciseService,
-		validate:        validator.New(),
+		exerciseService:    exerciseService,
+		translationService: translationService,
+		validate:           validators.New(),
 	}
 }
 
 // ListExercises godoc
 // @Summary List exercises for a program
+// @Description Names and descriptions in the Accept-Language locale where translated. Programs hidden by moderation are only listed for their owner and admins.
 // @Tags exercises
 // @Produce json
 // @Param id path string true "Program ID"
@@ -40,49 +65,66 @@ func (h *ExerciseHandler) ListExercises(c *gin.Context) {
 		return
 	}
 
-	exercises, err := h.exerciseService.ListByProgram(c.Request.Context(), programID)
+	program, err := h.exerciseService.ListByProgram(c.Request.Context(), programID)
 	if err != nil {
 		respondWithAppError(c, err)
 		return
 	}
+	if program.Program.HiddenAt != nil && !middleware.IsAdmin(c) {
+		// Hidden by moderation: only the owner still sees it
+		userID, _ := middleware.GetUserID(c)
+		if program.Program.OwnedBy == nil || *program.Program.OwnedBy != userID {
+			respondWithError(c, appErrors.NewNotFoundError("Program"))
+			return
+		}
+	}
+
+	localized := []models.ProgramWithExercises{*program}
+	if err := h.translationService.Localize(c.Request.Context(), localized, middleware.GetLocale(c)); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"exercises": exercises,
+		"exercises": localized[0].Exercises,
 	})
 }
 
 // CreateExercise godoc
 // @Summary Create a new exercise
+// @Description Owner of the program or admin
 // @Tags exercises
 // @Accept json
 // @Produce json
+// @Param id path string true "Program ID"
 // @Param request body validators.CreateExerciseRequest true "Exercise details"
-// @Success 201 {object} map[string]interface{}
-// @Router /api/v1/exercises [post]
+// @Success 201 {object} models.Exercise
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/exercises [post]
 // @Security BearerAuth
 func (h *ExerciseHandler) CreateExercise(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	userID, userRole, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
 	var req validators.CreateExerciseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
 		return
 	}
 
-	// Debug logging
-	log.Printf("CreateExercise request: %+v", req)
-	log.Printf("OrderIndex value: %d", req.OrderIndex)
-
 	if err := h.validate.Struct(req); err != nil {
-		log.Printf("Validation error: %v", err)
 		respondWithValidationError(c, err)
 		return
 	}
 
-	programID, err := uuid.Parse(req.ProgramID)
-	if err != nil {
-		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
-		return
-	}
-
 	exercise := &models.Exercise{
 		ProgramID:           programID,
 		Name:                req.Name,
@@ -94,10 +136,13 @@ func (h *ExerciseHandler) CreateExercise(c *gin.Context) {
 		RestAfterSeconds:    req.RestAfterSeconds,
 		HasSides:            req.HasSides,
 		SideDurationSeconds: req.SideDurationSeconds,
+		Tempo:               exerciseTempo(req.Tempo),
+		BreathingPattern:    breathingPattern(req.BreathingPattern),
+		Contraindications:   req.Contraindications,
 		Metadata:            req.Metadata,
 	}
 
-	if err := h.exerciseService.Create(c.Request.Context(), exercise); err != nil {
+	if err := h.exerciseService.Create(c.Request.Context(), exercise, userID, userRole); err != nil {
 		respondWithAppError(c, err)
 		return
 	}
@@ -107,12 +152,14 @@ func (h *ExerciseHandler) CreateExercise(c *gin.Context) {
 
 // UpdateExercise godoc
 // @Summary Update an exercise
+// @Description Owner of the program or admin
 // @Tags exercises
 // @Accept json
 // @Produce json
 // @Param id path string true "Exercise ID"
 // @Param request body validators.UpdateExerciseRequest true "Updated exercise details"
 // @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Router /api/v1/exercises/{id} [put]
 // @Security BearerAuth
 func (h *ExerciseHandler) UpdateExercise(c *gin.Context) {
@@ -122,6 +169,11 @@ func (h *ExerciseHandler) UpdateExercise(c *gin.Context) {
 		return
 	}
 
+	userID, userRole, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
 	var req validators.UpdateExerciseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
@@ -165,11 +217,14 @@ func (h *ExerciseHandler) UpdateExercise(c *gin.Context) {
 	if req.SideDurationSeconds != nil {
 		exercise.SideDurationSeconds = req.SideDurationSeconds
 	}
+	exercise.Tempo = exerciseTempo(req.Tempo)
+	exercise.BreathingPattern = breathingPattern(req.BreathingPattern)
+	exercise.Contraindications = req.Contraindications
 	if req.Metadata != nil {
 		exercise.Metadata = req.Metadata
 	}
 
-	if err := h.exerciseService.Update(c.Request.Context(), id, exercise); err != nil {
+	if err := h.exerciseService.Update(c.Request.Context(), id, exercise, userID, userRole); err != nil {
 		respondWithAppError(c, err)
 		return
 	}
@@ -181,9 +236,11 @@ func (h *ExerciseHandler) UpdateExercise(c *gin.Context) {
 
 // DeleteExercise godoc
 // @Summary Delete an exercise
+// @Description Owner of the program or admin
 // @Tags exercises
 // @Param id path string true "Exercise ID"
 // @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Router /api/v1/exercises/{id} [delete]
 // @Security BearerAuth
 func (h *ExerciseHandler) DeleteExercise(c *gin.Context) {
@@ -193,7 +250,12 @@ func (h *ExerciseHandler) DeleteExercise(c *gin.Context) {
 		return
 	}
 
-	if err := h.exerciseService.Delete(c.Request.Context(), id); err != nil {
+	userID, userRole, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.exerciseService.Delete(c.Request.Context(), id, userID, userRole); err != nil {
 		respondWithAppError(c, err)
 		return
 	}
@@ -205,12 +267,14 @@ func (h *ExerciseHandler) DeleteExercise(c *gin.Context) {
 
 // ReorderExercises godoc
 // @Summary Reorder exercises in a program
+// @Description Owner of the program or admin. All exercise IDs of the program must be given.
 // @Tags exercises
 // @Accept json
 // @Produce json
 // @Param id path string true "Program ID"
 // @Param request body validators.ReorderExercisesRequest true "New exercise order"
 // @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Router /api/v1/programs/{id}/exercises/reorder [put]
 // @Security BearerAuth
 func (h *ExerciseHandler) ReorderExercises(c *gin.Context) {
@@ -220,6 +284,11 @@ func (h *ExerciseHandler) ReorderExercises(c *gin.Context) {
 		return
 	}
 
+	userID, userRole, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
 	var req validators.ReorderExercisesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
@@ -242,7 +311,7 @@ func (h *ExerciseHandler) ReorderExercises(c *gin.Context) {
 		exerciseIDs = append(exerciseIDs, id)
 	}
 
-	if err := h.exerciseService.ReorderExercises(c.Request.Context(), programID, exerciseIDs); err != nil {
+	if err := h.exerciseService.ReorderExercises(c.Request.Context(), programID, exerciseIDs, userID, userRole); err != nil {
 		respondWithAppError(c, err)
 		return
 	}
@@ -251,3 +320,27 @@ func (h *ExerciseHandler) ReorderExercises(c *gin.Context) {
 		"message": "Exercises reordered successfully",
 	})
 }
+
+// exerciseTempo converts a tempo request, returning nil when none or an empty one was given
+func exerciseTempo(req *validators.ExerciseTempoRequest) *models.ExerciseTempo {
+	if req == nil || *req == (validators.ExerciseTempoRequest{}) {
+		return nil
+	}
+	return &models.ExerciseTempo{
+		SecondsPerRep:     req.SecondsPerRep,
+		PrepSeconds:       req.PrepSeconds,
+		TransitionSeconds: req.TransitionSeconds,
+	}
+}
+
+func breathingPattern(req *validators.BreathingPatternRequest) *models.BreathingPattern {
+	if req == nil {
+		return nil
+	}
+	return &models.BreathingPattern{
+		InhaleSeconds: req.InhaleSeconds,
+		HoldSeconds:   req.HoldSeconds,
+		ExhaleSeconds: req.ExhaleSeconds,
+		Cycles:        req.Cycles,
+	}
+}
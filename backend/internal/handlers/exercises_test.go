@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/testutil/mocks"
+)
+
+func TestExerciseHandler_ListExercises(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ownerID := uuid.New()
+	programID := uuid.New()
+	exerciseID := uuid.New()
+	hiddenAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		hidden        bool
+		userID        uuid.UUID
+		role          models.UserRole
+		locale        string
+		wantStatus    int
+		wantExercise  string
+		wantLocalized bool
+	}{
+		{"visible_to_any_user", false, uuid.New(), models.RoleStudent, "", http.StatusOK, "Horse Stance", true},
+		{"hidden_from_other_students", true, uuid.New(), models.RoleStudent, "", http.StatusNotFound, "", false},
+		{"hidden_visible_to_owner", true, ownerID, models.RoleStudent, "", http.StatusOK, "Horse Stance", true},
+		{"hidden_visible_to_admin", true, uuid.New(), models.RoleAdmin, "", http.StatusOK, "Horse Stance", true},
+		{"localized_to_requested_locale", false, uuid.New(), models.RoleStudent, "de", http.StatusOK, "Pferdestand", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := models.Program{ID: programID, Name: "Routine", OwnedBy: &ownerID}
+			if tt.hidden {
+				program.HiddenAt = &hiddenAt
+			}
+			exerciseService := &mocks.ExerciseServiceMock{
+				ListByProgramFunc: func(ctx context.Context, id uuid.UUID) (*models.ProgramWithExercises, error) {
+					return &models.ProgramWithExercises{
+						Program:   program,
+						Exercises: []models.Exercise{{ID: exerciseID, ProgramID: id, Name: "Horse Stance"}},
+					}, nil
+				},
+			}
+			localizer := &mocks.LocalizerMock{
+				LocalizeFunc: func(ctx context.Context, programs []models.ProgramWithExercises, locale string) error {
+					if locale == "de" {
+						programs[0].Exercises[0].Name = "Pferdestand"
+					}
+					return nil
+				},
+			}
+			handler := NewExerciseHandler(exerciseService, localizer)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/programs/"+programID.String()+"/exercises", nil)
+			c.Params = gin.Params{gin.Param{Key: "id", Value: programID.String()}}
+			c.Set("user_id", tt.userID.String())
+			c.Set("user_role", string(tt.role))
+			if tt.locale != "" {
+				c.Set("locale", tt.locale)
+			}
+
+			handler.ListExercises(c)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if calls := localizer.LocalizeCalls(); tt.wantLocalized != (len(calls) == 1) {
+				t.Errorf("Localize called %d times, want localized = %v", len(calls), tt.wantLocalized)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body struct {
+				Exercises []models.Exercise `json:"exercises"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(body.Exercises) != 1 || body.Exercises[0].Name != tt.wantExercise {
+				t.Errorf("exercises = %+v, want %q", body.Exercises, tt.wantExercise)
+			}
+		})
+	}
+}
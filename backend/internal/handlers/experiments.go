@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type ExperimentHandler struct {
+	experimentService *services.ExperimentService
+	validate          *validator.Validate
+}
+
+func NewExperimentHandler(experimentService *services.ExperimentService) *ExperimentHandler {
+	return &ExperimentHandler{
+		experimentService: experimentService,
+		validate:          validators.New(),
+	}
+}
+
+// ListExperiments godoc
+// @Summary List A/B experiments between program variants (admin only)
+// @Tags experiments
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/experiments [get]
+// @Security BearerAuth
+func (h *ExperimentHandler) ListExperiments(c *gin.Context) {
+	experiments, err := h.experimentService.List(c.Request.Context())
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"experiments": experiments,
+	})
+}
+
+// CreateExperiment godoc
+// @Summary Create an A/B experiment between two programs (admin only)
+// @Description program_a_id and program_b_id are the variants, e.g. copies of a program with different rest times
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param request body validators.CreateExperimentRequest true "Experiment details"
+// @Success 201 {object} models.ProgramExperiment
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/experiments [post]
+// @Security BearerAuth
+func (h *ExperimentHandler) CreateExperiment(c *gin.Context) {
+	var req validators.CreateExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	experiment, err := h.experimentService.Create(c.Request.Context(), &models.ProgramExperiment{
+		Name:        req.Name,
+		Description: req.Description,
+		ProgramAID:  uuid.MustParse(req.ProgramAID), // Checked by the validator
+		ProgramBID:  uuid.MustParse(req.ProgramBID),
+		CreatedBy:   &userID,
+	})
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, experiment)
+}
+
+// GetExperiment godoc
+// @Summary Get an A/B experiment (admin only)
+// @Tags experiments
+// @Produce json
+// @Param id path string true "Experiment ID"
+// @Success 200 {object} models.ProgramExperiment
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/experiments/{id} [get]
+// @Security BearerAuth
+func (h *ExperimentHandler) GetExperiment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid experiment ID"))
+		return
+	}
+
+	experiment, err := h.experimentService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, experiment)
+}
+
+// AssignExperiment godoc
+// @Summary Assign students a random variant of an experiment (admin only)
+// @Description New participants are split evenly between the variants at random and assigned that variant's program. Students already in the experiment keep their variant.
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param id path string true "Experiment ID"
+// @Param request body validators.AssignExperimentRequest true "Students"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/experiments/{id}/assign [post]
+// @Security BearerAuth
+func (h *ExperimentHandler) AssignExperiment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid experiment ID"))
+		return
+	}
+
+	var req validators.AssignExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(req.UserIDs))
+	for _, idStr := range req.UserIDs {
+		userIDs = append(userIDs, uuid.MustParse(idStr)) // validated above
+	}
+
+	participants, err := h.experimentService.Assign(c.Request.Context(), id, adminID, userIDs)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"participants": participants,
+	})
+}
+
+// GetExperimentResults godoc
+// @Summary Compare the variants of an experiment (admin only)
+// @Description Per variant: participants, those who completed a session, sessions started and completed, the average completion rate and, as adherence, completed sessions per participant and week
+// @Tags experiments
+// @Produce json
+// @Param id path string true "Experiment ID"
+// @Success 200 {object} models.ExperimentResults
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/experiments/{id}/results [get]
+// @Security BearerAuth
+func (h *ExperimentHandler) GetExperimentResults(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid experiment ID"))
+		return
+	}
+
+	results, err := h.experimentService.Results(c.Request.Context(), id)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// NoRoute answers requests for paths the API doesn't have with the standard error envelope
+func NoRoute(c *gin.Context) {
+	respondWithError(c, appErrors.NewNotFoundError("Route"))
+}
+
+// NoMethod answers requests for a path that only exists under other methods. gin has already
+// listed those in the Allow header.
+func NoMethod(c *gin.Context) {
+	allowed := strings.Split(c.Writer.Header().Get("Allow"), ", ")
+	respondWithError(c, appErrors.NewMethodNotAllowedError(c.Request.Method, allowed))
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/internal/middleware"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// newFallbackServer mirrors the router's method handling around a single GET route
+func newFallbackServer(t *testing.T) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(NoRoute)
+	router.NoMethod(NoMethod)
+	router.Use(middleware.AllowHeader())
+	router.GET("/programs", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"programs": []string{}})
+	})
+	router.POST("/programs", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	ts := httptest.NewServer(middleware.HeadAsGet(router))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func sendFallbackRequest(t *testing.T, ts *httptest.Server, method, path string) (*http.Response, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, ts.URL+path, nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+	return resp, body
+}
+
+func errorCode(t *testing.T, body []byte) appErrors.ErrorCode {
+	t.Helper()
+
+	var response struct {
+		Error struct {
+			Code appErrors.ErrorCode `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("Failed to parse response %q: %v", body, err)
+	}
+	return response.Error.Code
+}
+
+func TestNoRoute(t *testing.T) {
+	ts := newFallbackServer(t)
+
+	resp, body := sendFallbackRequest(t, ts, http.MethodGet, "/nowhere")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status %d but got %d", http.StatusNotFound, resp.StatusCode)
+	}
+	if code := errorCode(t, body); code != appErrors.ErrCodeNotFound {
+		t.Errorf("Expected error code %s but got %s", appErrors.ErrCodeNotFound, code)
+	}
+}
+
+func TestNoMethod(t *testing.T) {
+	ts := newFallbackServer(t)
+
+	resp, body := sendFallbackRequest(t, ts, http.MethodDelete, "/programs")
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected status %d but got %d", http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+	if allow := resp.Header.Get("Allow"); allow != "GET, POST, HEAD, OPTIONS" {
+		t.Errorf("Expected Allow header %q but got %q", "GET, POST, HEAD, OPTIONS", allow)
+	}
+	if code := errorCode(t, body); code != appErrors.ErrCodeMethodNotAllowed {
+		t.Errorf("Expected error code %s but got %s", appErrors.ErrCodeMethodNotAllowed, code)
+	}
+}
+
+func TestHeadAsGet(t *testing.T) {
+	ts := newFallbackServer(t)
+
+	resp, body := sendFallbackRequest(t, ts, http.MethodHead, "/programs")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d but got %d", http.StatusOK, resp.StatusCode)
+	}
+	if resp.Header.Get("Content-Type") != "application/json; charset=utf-8" || len(body) != 0 {
+		t.Errorf("Expected JSON headers without a body but got %q with %q", resp.Header.Get("Content-Type"), body)
+	}
+}
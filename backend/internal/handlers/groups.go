@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/anonymize"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type GroupHandler struct {
+	groupService *services.GroupService
+	anonymizer   *anonymize.Anonymizer
+	validate     *validator.Validate
+}
+
+func NewGroupHandler(groupService *services.GroupService, anonymizer *anonymize.Anonymizer) *GroupHandler {
+	return &GroupHandler{
+		groupService: groupService,
+		anonymizer:   anonymizer,
+		validate:     validators.New(),
+	}
+}
+
+// ListGroups godoc
+// @Summary List student groups (admin only)
+// @Tags groups
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/groups [get]
+// @Security BearerAuth
+func (h *GroupHandler) ListGroups(c *gin.Context) {
+	groups, err := h.groupService.List(c.Request.Context())
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"groups": groups,
+	})
+}
+
+// CreateGroup godoc
+// @Summary Create a student group (admin only)
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param request body validators.CreateGroupRequest true "Group details"
+// @Success 201 {object} models.Group
+// @Router /api/v1/groups [post]
+// @Security BearerAuth
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	var req validators.CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	group, err := h.groupService.Create(c.Request.Context(), req.Name, req.Description, userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+// GetGroupStats godoc
+// @Summary Get the practice of a group's members (admin only)
+// @Description Sessions started and completed, practice minutes and days, completion and adherence (completed sessions per week) per member in the window, with a summary for the whole group. Streaks are current ones, whatever the window.
+// @Tags groups
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param from query string false "RFC3339, defaults to 7 days before to"
+// @Param to query string false "RFC3339, defaults to now"
+// @Success 200 {object} models.GroupStats
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/groups/{id}/stats [get]
+// @Security BearerAuth
+func (h *GroupHandler) GetGroupStats(c *gin.Context) {
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid group ID"))
+		return
+	}
+
+	var query validators.GroupStatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, bindError(err, "Invalid query parameters"))
+		return
+	}
+
+	to := time.Now().UTC()
+	if query.To != nil {
+		to = query.To.Time
+	}
+	from := to.AddDate(0, 0, -7)
+	if query.From != nil {
+		from = query.From.Time
+	}
+
+	stats, err := h.groupService.Stats(c.Request.Context(), groupID, from, to)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+	h.anonymizer.GroupStats(stats)
+
+	c.JSON(http.StatusOK, stats)
+}
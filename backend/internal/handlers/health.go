@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/pkg/dependency"
+)
+
+type HealthHandler struct {
+	registry *dependency.Registry
+	version  string
+}
+
+func NewHealthHandler(registry *dependency.Registry, version string) *HealthHandler {
+	return &HealthHandler{
+		registry: registry,
+		version:  version,
+	}
+}
+
+// GetHealth godoc
+// @Summary Health check
+// @Description Reports the status of each registered dependency and its circuit breaker.
+// @Description Returns 503 only when a critical dependency (the database) is down; optional ones only degrade the status.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /health [get]
+func (h *HealthHandler) GetHealth(c *gin.Context) {
+	report := h.registry.Status(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status == dependency.StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, gin.H{
+		"status":       report.Status,
+		"version":      h.version,
+		"dependencies": report.Dependencies,
+	})
+}
@@ -1,15 +1,26 @@
 package handlers
 
 import (
+	"errors"
 	"log"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/validators"
 	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/timestamp"
 )
 
 // respondWithError sends an error response
 func respondWithError(c *gin.Context, err *appErrors.AppError) {
+	// A body cut off by the size limit surfaces as a decode error; report the real cause
+	if limit, tooLarge := middleware.BodyTooLarge(c); tooLarge && err.HTTPStatus == http.StatusBadRequest {
+		err = appErrors.NewPayloadTooLargeError(limit)
+	}
+
 	// Log the full error including underlying error and request context
 	requestPath := c.Request.URL.Path
 	requestMethod := c.Request.Method
@@ -52,11 +63,19 @@ func respondWithValidationError(c *gin.Context, err error) {
 	}
 
 	details := make(map[string]interface{})
+	tooLarge := false
 	for _, fieldErr := range validationErrs {
 		details[fieldErr.Field()] = getValidationErrorMessage(fieldErr)
+		if fieldErr.Tag() == "jsonlimits" {
+			tooLarge = true
+		}
 	}
 
 	appErr := appErrors.NewValidationError("Validation failed")
+	if tooLarge {
+		// The request is well-formed but carries an oversized JSON object
+		appErr = appErrors.NewUnprocessableError("Validation failed")
+	}
 	appErr.Details = details
 	respondWithError(c, appErr)
 }
@@ -79,7 +98,31 @@ func getValidationErrorMessage(fe validator.FieldError) string {
 		return "Invalid URL format"
 	case "datetime":
 		return "Invalid date/time format"
+	case "jsonlimits":
+		if err := validators.CheckJSONLimits(fe.Value(), validators.DefaultJSONLimits); err != nil {
+			return "Object " + err.Error()
+		}
+		return "Object is too large"
 	default:
 		return "Validation failed"
 	}
 }
+
+// bindError reports a request that failed to bind. A malformed timestamp is named with the
+// expected format; anything else gets the generic message.
+func bindError(err error, message string) *appErrors.AppError {
+	var timestampErr *timestamp.ParseError
+	if errors.As(err, &timestampErr) {
+		return appErrors.NewBadRequestError(timestampErr.Error())
+	}
+	return appErrors.NewBadRequestError(message)
+}
+
+// parseOptionalUUID converts an optional ID already checked by the validator
+func parseOptionalUUID(value *string) *uuid.UUID {
+	if value == nil {
+		return nil
+	}
+	id := uuid.MustParse(*value)
+	return &id
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/validators"
+)
+
+func TestRequestSizeLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Requests are rejected before the service is used, so no dependencies are needed
+	handler := &SessionHandler{validate: validators.New()}
+	router := gin.New()
+	router.Use(middleware.BodySizeLimit(32<<10, 64<<10))
+	router.POST("/sessions/start", handler.StartSession)
+
+	bigDeviceInfo, _ := json.Marshal(map[string]interface{}{
+		"program_id":  "8a7b6c5d-4e3f-4a2b-9c1d-0e9f8a7b6c5d",
+		"device_info": map[string]string{"blob": strings.Repeat("x", validators.DefaultJSONLimits.MaxBytes)},
+	})
+	hugeBody := `{"program_id": "` + strings.Repeat("x", 40<<10) + `"}`
+
+	tests := []struct {
+		name       string
+		body       string
+		chunked    bool
+		wantStatus int
+	}{
+		{"content length over limit", hugeBody, false, http.StatusRequestEntityTooLarge},
+		{"chunked body over limit", hugeBody, true, http.StatusRequestEntityTooLarge},
+		{"oversized device_info", string(bigDeviceInfo), false, http.StatusUnprocessableEntity},
+		{"malformed body within limit", `{"program_id":`, false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/sessions/start", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d but got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
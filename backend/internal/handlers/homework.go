@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type HomeworkHandler struct {
+	homeworkService *services.HomeworkService
+	validate        *validator.Validate
+}
+
+func NewHomeworkHandler(homeworkService *services.HomeworkService) *HomeworkHandler {
+	return &HomeworkHandler{
+		homeworkService: homeworkService,
+		validate:        validators.New(),
+	}
+}
+
+// ListHomework godoc
+// @Summary List homework
+// @Description Admins see all homework, latest deadline first. Students see their own homework, soonest deadline first, with its status.
+// @Tags homework
+// @Produce json
+// @Param program_id query string false "Only homework for this program (admin only)"
+// @Param group_id query string false "Only homework set for this group (admin only)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/homework [get]
+// @Security BearerAuth
+func (h *HomeworkHandler) ListHomework(c *gin.Context) {
+	var query validators.ListHomeworkQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	var filter repositories.HomeworkFilter
+	if query.ProgramID != nil {
+		id := uuid.MustParse(*query.ProgramID) // Checked by the validator
+		filter.ProgramID = &id
+	}
+	if query.GroupID != nil {
+		id := uuid.MustParse(*query.GroupID)
+		filter.GroupID = &id
+	}
+
+	homework, err := h.homeworkService.List(c.Request.Context(), userID, middleware.IsAdmin(c), filter)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"homework": homework,
+	})
+}
+
+// CreateHomework godoc
+// @Summary Set homework (admin only)
+// @Description Requires either a number of practice sessions of the program or a submission for it by the deadline. Set for a group, whose members get the program assigned, or for every student assigned to the program.
+// @Tags homework
+// @Accept json
+// @Produce json
+// @Param request body validators.CreateHomeworkRequest true "Homework"
+// @Success 201 {object} models.Homework
+// @Router /api/v1/homework [post]
+// @Security BearerAuth
+func (h *HomeworkHandler) CreateHomework(c *gin.Context) {
+	var req validators.CreateHomeworkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, bindError(err, "Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	homework := &models.Homework{
+		ProgramID:        uuid.MustParse(req.ProgramID), // Checked by the validator
+		Title:            req.Title,
+		Instructions:     req.Instructions,
+		Requirement:      models.HomeworkRequirement(req.Requirement),
+		RequiredSessions: req.RequiredSessions,
+		DueAt:            req.DueAt.Time,
+		CreatedBy:        &userID,
+	}
+	if req.GroupID != nil {
+		groupID := uuid.MustParse(*req.GroupID)
+		homework.GroupID = &groupID
+	}
+
+	created, err := h.homeworkService.Create(c.Request.Context(), homework)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetHomework godoc
+// @Summary Get a homework
+// @Description Students can only get homework set for them, which includes their status
+// @Tags homework
+// @Produce json
+// @Param id path string true "Homework ID"
+// @Success 200 {object} models.Homework
+// @Router /api/v1/homework/{id} [get]
+// @Security BearerAuth
+func (h *HomeworkHandler) GetHomework(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	homework, err := h.homeworkService.Get(c.Request.Context(), id, userID, middleware.IsAdmin(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, homework)
+}
+
+// UpdateHomework godoc
+// @Summary Update a homework (admin only)
+// @Description Moving the deadline clears overdue marks; students are checked again at the new deadline
+// @Tags homework
+// @Accept json
+// @Produce json
+// @Param id path string true "Homework ID"
+// @Param request body validators.UpdateHomeworkRequest true "Fields to change"
+// @Success 200 {object} models.Homework
+// @Router /api/v1/homework/{id} [put]
+// @Security BearerAuth
+func (h *HomeworkHandler) UpdateHomework(c *gin.Context) {
+	id, _, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req validators.UpdateHomeworkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, bindError(err, "Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	homework, err := h.homeworkService.Update(c.Request.Context(), id, req.Title, req.Instructions, req.DueAt.Ptr())
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, homework)
+}
+
+// DeleteHomework godoc
+// @Summary Delete a homework (admin only)
+// @Tags homework
+// @Param id path string true "Homework ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/homework/{id} [delete]
+// @Security BearerAuth
+func (h *HomeworkHandler) DeleteHomework(c *gin.Context) {
+	id, _, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.homeworkService.Delete(c.Request.Context(), id); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Homework deleted successfully",
+	})
+}
+
+// GetHomeworkStudents godoc
+// @Summary Get the status of each student of a homework (admin only)
+// @Description Students are pending, on_time, late or overdue, with counts per status
+// @Tags homework
+// @Produce json
+// @Param id path string true "Homework ID"
+// @Success 200 {object} models.HomeworkDetail
+// @Router /api/v1/homework/{id}/students [get]
+// @Security BearerAuth
+func (h *HomeworkHandler) GetHomeworkStudents(c *gin.Context) {
+	id, _, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	detail, err := h.homeworkService.GetDetail(c.Request.Context(), id)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+func (h *HomeworkHandler) parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid homework ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, userID, true
+}
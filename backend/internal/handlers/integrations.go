@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type IntegrationHandler struct {
+	integrationService *services.IntegrationService
+	validate           *validator.Validate
+}
+
+func NewIntegrationHandler(integrationService *services.IntegrationService) *IntegrationHandler {
+	return &IntegrationHandler{
+		integrationService: integrationService,
+		validate:           validators.New(),
+	}
+}
+
+// CreateIntegrationKey godoc
+// @Summary Create a signing key for a server-to-server integration (admin only)
+// @Description The secret is only returned in this response. The integration signs its requests with it.
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param request body validators.CreateIntegrationKeyRequest true "Integration"
+// @Success 201 {object} models.IntegrationKey
+// @Router /api/v1/integration-keys [post]
+// @Security BearerAuth
+func (h *IntegrationHandler) CreateIntegrationKey(c *gin.Context) {
+	var req validators.CreateIntegrationKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	key, err := h.integrationService.CreateKey(c.Request.Context(), userID, req.Name)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// ListIntegrationKeys godoc
+// @Summary List integration keys with when they were last used (admin only)
+// @Tags integrations
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/integration-keys [get]
+// @Security BearerAuth
+func (h *IntegrationHandler) ListIntegrationKeys(c *gin.Context) {
+	keys, err := h.integrationService.ListKeys(c.Request.Context())
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"integration_keys": keys})
+}
+
+// RevokeIntegrationKey godoc
+// @Summary Revoke an integration key (admin only)
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Integration key ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/integration-keys/{id} [delete]
+// @Security BearerAuth
+func (h *IntegrationHandler) RevokeIntegrationKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid integration key ID"))
+		return
+	}
+
+	if err := h.integrationService.RevokeKey(c.Request.Context(), id); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Integration key revoked",
+	})
+}
+
+// ListIntegrationPrograms godoc
+// @Summary List public programs for an integration
+// @Description Newest first, without exercises. Requires a signed request.
+// @Tags integrations
+// @Produce json
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/integration/programs [get]
+func (h *IntegrationHandler) ListIntegrationPrograms(c *gin.Context) {
+	var query validators.ListIntegrationProgramsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	programs, err := h.integrationService.ListPrograms(c.Request.Context(), middleware.GetLocale(c), query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"programs": programs,
+		"limit":    query.Limit,
+		"offset":   query.Offset,
+	})
+}
+
+// GetIntegrationProgram godoc
+// @Summary Get a public program with its exercises for an integration
+// @Description Requires a signed request. Programs that are not public are reported as not found.
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Program ID"
+// @Success 200 {object} models.IntegrationProgram
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/integration/programs/{id} [get]
+func (h *IntegrationHandler) GetIntegrationProgram(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	program, err := h.integrationService.GetProgram(c.Request.Context(), id, middleware.GetLocale(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, program)
+}
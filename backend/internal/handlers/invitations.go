@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type InvitationHandler struct {
+	invitationService *services.InvitationService
+	validate          *validator.Validate
+}
+
+func NewInvitationHandler(invitationService *services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{
+		invitationService: invitationService,
+		validate:          validators.New(),
+	}
+}
+
+// CreateInvitation godoc
+// @Summary Create a single-use signup invitation (admin only)
+// @Description The token and signup_url are only returned in this response
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body validators.CreateInvitationRequest true "Invitation details"
+// @Success 201 {object} models.Invitation
+// @Router /api/v1/invitations [post]
+// @Security BearerAuth
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	var req validators.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	role := models.RoleStudent
+	if req.Role != "" {
+		role = models.UserRole(req.Role)
+	}
+
+	var groupID *uuid.UUID
+	if req.GroupID != nil {
+		parsed := uuid.MustParse(*req.GroupID) // validated above
+		groupID = &parsed
+	}
+
+	programIDs := make([]uuid.UUID, 0, len(req.ProgramIDs))
+	for _, idStr := range req.ProgramIDs {
+		programIDs = append(programIDs, uuid.MustParse(idStr)) // validated above
+	}
+
+	invitation, err := h.invitationService.Create(c.Request.Context(), userID, req.Email, role, groupID, programIDs, req.ExpiresInDays)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ListInvitations godoc
+// @Summary List invitations (admin only)
+// @Tags invitations
+// @Produce json
+// @Param pending_only query boolean false "Only invitations that are neither accepted nor revoked"
+// @Param limit query int false "Limit (default 20)"
+// @Param offset query int false "Offset (default 0)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/invitations [get]
+// @Security BearerAuth
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	var query validators.ListInvitationsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+
+	// Set defaults
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	invitations, err := h.invitationService.List(c.Request.Context(), query.PendingOnly, query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invitations": invitations,
+		"limit":       query.Limit,
+		"offset":      query.Offset,
+	})
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke a pending invitation (admin only)
+// @Tags invitations
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/invitations/{id} [delete]
+// @Security BearerAuth
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid invitation ID"))
+		return
+	}
+
+	if err := h.invitationService.Revoke(c.Request.Context(), id); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invitation revoked",
+	})
+}
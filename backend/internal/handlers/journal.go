@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type JournalHandler struct {
+	journalService *services.JournalService
+	validate       *validator.Validate
+}
+
+func NewJournalHandler(journalService *services.JournalService) *JournalHandler {
+	return &JournalHandler{
+		journalService: journalService,
+		validate:       validators.New(),
+	}
+}
+
+// ListJournal godoc
+// @Summary List the current user's progress journal
+// @Description Entries are ordered by recorded_at, most recent first. shared_with lists the instructors each entry is shared with.
+// @Tags journal
+// @Produce json
+// @Param program_id query string false "Only entries for this program"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/journal [get]
+// @Security BearerAuth
+func (h *JournalHandler) ListJournal(c *gin.Context) {
+	var query validators.ListJournalQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	entries, err := h.journalService.List(c.Request.Context(), userID, parseOptionalUUID(query.ProgramID), query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+	})
+}
+
+// UploadJournalEntry godoc
+// @Summary Add a photo or video to the current user's progress journal
+// @Description JPEG, PNG or WebP photos up to JOURNAL_MAX_PHOTO_MB and MP4 or WebM videos up to JOURNAL_MAX_VIDEO_MB, for a program assigned to or owned by the user. The media counts against the storage quota and is private until shared.
+// @Tags journal
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Photo or video"
+// @Param program_id formData string true "Program ID"
+// @Param exercise_id formData string false "Exercise of the program"
+// @Param caption formData string false "Caption"
+// @Param recorded_at formData string false "When the media was recorded (RFC3339), defaults to now"
+// @Success 201 {object} models.JournalEntry
+// @Failure 400 {object} map[string]interface{}
+// @Failure 413 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /api/v1/journal [post]
+// @Security BearerAuth
+func (h *JournalHandler) UploadJournalEntry(c *gin.Context) {
+	var req validators.UploadJournalEntryRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondWithError(c, bindError(err, "Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	entry := &models.JournalEntry{
+		ProgramID:  uuid.MustParse(req.ProgramID),
+		ExerciseID: parseOptionalUUID(req.ExerciseID),
+		Caption:    req.Caption,
+	}
+	if req.RecordedAt != nil {
+		entry.RecordedAt = req.RecordedAt.Time
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Media file is required"))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Failed to read media file"))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Failed to read media file"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+	entry.UserID = userID
+
+	if err := h.journalService.Upload(c.Request.Context(), entry, data); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// ListSharedJournal godoc
+// @Summary List journal entries students shared with the current instructor (admin only)
+// @Tags journal
+// @Produce json
+// @Param user_id query string false "Only entries of this student"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/journal/shared [get]
+// @Security BearerAuth
+func (h *JournalHandler) ListSharedJournal(c *gin.Context) {
+	var query validators.ListSharedJournalQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	instructorID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	entries, err := h.journalService.ListShared(c.Request.Context(), instructorID, parseOptionalUUID(query.UserID), query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+	})
+}
+
+// GetJournalEntry godoc
+// @Summary Get a journal entry
+// @Description Only the student and instructors the entry is shared with can see it; anyone else gets 404.
+// @Tags journal
+// @Produce json
+// @Param id path string true "Entry ID"
+// @Success 200 {object} models.JournalEntry
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/journal/{id} [get]
+// @Security BearerAuth
+func (h *JournalHandler) GetJournalEntry(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.journalService.Get(c.Request.Context(), id, userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// GetJournalMedia godoc
+// @Summary Download the photo or video of a journal entry
+// @Description Same access as the entry itself. Responses are never cached.
+// @Tags journal
+// @Produce octet-stream
+// @Param id path string true "Entry ID"
+// @Success 200 {file} binary
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/journal/{id}/media [get]
+// @Security BearerAuth
+func (h *JournalHandler) GetJournalMedia(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	entry, media, err := h.journalService.OpenMedia(c.Request.Context(), id, userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+	defer media.Close()
+
+	c.DataFromReader(http.StatusOK, entry.SizeBytes, entry.ContentType, media, map[string]string{
+		"Cache-Control":          "private, no-store",
+		"X-Content-Type-Options": "nosniff",
+		"Content-Length":         strconv.FormatInt(entry.SizeBytes, 10),
+	})
+}
+
+// DeleteJournalEntry godoc
+// @Summary Delete an entry from the current user's progress journal
+// @Tags journal
+// @Param id path string true "Entry ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/journal/{id} [delete]
+// @Security BearerAuth
+func (h *JournalHandler) DeleteJournalEntry(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.journalService.Delete(c.Request.Context(), id, userID); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ShareJournalEntry godoc
+// @Summary Share one of the current user's journal entries with an instructor
+// @Tags journal
+// @Produce json
+// @Param id path string true "Entry ID"
+// @Param instructorId path string true "Instructor user ID"
+// @Success 200 {object} models.JournalEntry
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/journal/{id}/shares/{instructorId} [put]
+// @Security BearerAuth
+func (h *JournalHandler) ShareJournalEntry(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+	instructorID, err := uuid.Parse(c.Param("instructorId"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid instructor ID"))
+		return
+	}
+
+	entry, err := h.journalService.Share(c.Request.Context(), id, userID, instructorID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// UnshareJournalEntry godoc
+// @Summary Stop sharing one of the current user's journal entries with an instructor
+// @Tags journal
+// @Param id path string true "Entry ID"
+// @Param instructorId path string true "Instructor user ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/journal/{id}/shares/{instructorId} [delete]
+// @Security BearerAuth
+func (h *JournalHandler) UnshareJournalEntry(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+	instructorID, err := uuid.Parse(c.Param("instructorId"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid instructor ID"))
+		return
+	}
+
+	if err := h.journalService.Unshare(c.Request.Context(), id, userID, instructorID); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RequestJournalShare godoc
+// @Summary Ask a student to share form-check media for a program (admin only)
+// @Description Sends the student a journal_request notification. The program must be assigned to them.
+// @Tags journal
+// @Accept json
+// @Param id path string true "User ID"
+// @Param request body validators.RequestJournalShareRequest true "Request"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/users/{id}/journal-requests [post]
+// @Security BearerAuth
+func (h *JournalHandler) RequestJournalShare(c *gin.Context) {
+	studentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid user ID"))
+		return
+	}
+
+	var req validators.RequestJournalShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	instructorID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	if err := h.journalService.RequestShare(c.Request.Context(), instructorID, studentID, uuid.MustParse(req.ProgramID), req.Message); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseIDs reads the entry ID from the path and the current user, responding on failure
+func (h *JournalHandler) parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid journal entry ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return id, userID, true
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type LimitationHandler struct {
+	limitationService *services.LimitationService
+	validate          *validator.Validate
+}
+
+func NewLimitationHandler(limitationService *services.LimitationService) *LimitationHandler {
+	return &LimitationHandler{
+		limitationService: limitationService,
+		validate:          validators.New(),
+	}
+}
+
+// GetMyLimitations godoc
+// @Summary Get the current user's injury and health profile
+// @Tags limitations
+// @Produce json
+// @Success 200 {object} models.Limitations
+// @Router /api/v1/auth/me/limitations [get]
+// @Security BearerAuth
+func (h *LimitationHandler) GetMyLimitations(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	profile, err := h.limitationService.GetLimitations(c.Request.Context(), userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateMyLimitations godoc
+// @Summary Replace the current user's injury and health profile
+// @Description Exercises contraindicated for a limitation are substituted or flagged in /my-programs and on timelines. Instructors review the adjusted programs again.
+// @Tags limitations
+// @Accept json
+// @Produce json
+// @Param request body validators.UpdateLimitationsRequest true "Limitations"
+// @Success 200 {object} models.Limitations
+// @Router /api/v1/auth/me/limitations [put]
+// @Security BearerAuth
+func (h *LimitationHandler) UpdateMyLimitations(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	var req validators.UpdateLimitationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	profile := &models.Limitations{
+		UserID:     userID,
+		Joints:     req.Joints,
+		Conditions: req.Conditions,
+		Notes:      req.Notes,
+	}
+	if err := h.limitationService.UpdateLimitations(c.Request.Context(), profile); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// GetAdjustedPlan godoc
+// @Summary Get a student's program as adjusted for their limitations (admin only)
+// @Description Exercises carry an adjustment when they were substituted or flagged. review_status is empty when nothing was adjusted.
+// @Tags limitations
+// @Produce json
+// @Param id path string true "User ID"
+// @Param programId path string true "Program ID"
+// @Success 200 {object} models.AdjustedPlan
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/users/{id}/programs/{programId}/plan [get]
+// @Security BearerAuth
+func (h *LimitationHandler) GetAdjustedPlan(c *gin.Context) {
+	userID, programID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	plan, err := h.limitationService.GetAdjustedPlan(c.Request.Context(), userID, programID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// ReviewPlan godoc
+// @Summary Review a student's adjusted program (admin only)
+// @Description Approves the adjustments or asks for changes. The review applies to the student's current limitations.
+// @Tags limitations
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param programId path string true "Program ID"
+// @Param request body validators.ReviewPlanRequest true "Review"
+// @Success 200 {object} models.PlanReview
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/users/{id}/programs/{programId}/plan-review [put]
+// @Security BearerAuth
+func (h *LimitationHandler) ReviewPlan(c *gin.Context) {
+	userID, programID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req validators.ReviewPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	reviewerID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	var note *string
+	if req.Note != "" {
+		note = &req.Note
+	}
+	review, err := h.limitationService.ReviewPlan(c.Request.Context(), reviewerID, userID, programID, models.PlanReviewStatus(req.Status), note)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// ListPendingReviews godoc
+// @Summary List adjusted programs waiting for review (admin only)
+// @Description Active assignments with an exercise contraindicated for the student, not reviewed since the student last changed their limitations
+// @Tags limitations
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/plan-reviews [get]
+// @Security BearerAuth
+func (h *LimitationHandler) ListPendingReviews(c *gin.Context) {
+	items, err := h.limitationService.ListPendingReviews(c.Request.Context())
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reviews": items,
+	})
+}
+
+// parseIDs reads the student and program IDs from the path, responding on failure
+func (h *LimitationHandler) parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid user ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	programID, err := uuid.Parse(c.Param("programId"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, programID, true
+}
@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// defaultCheckInValidity is how long a check-in code works unless the instructor says otherwise
+const defaultCheckInValidity = 15 * time.Minute
+
+type LiveClassHandler struct {
+	classService *services.LiveClassService
+	validate     *validator.Validate
+}
+
+func NewLiveClassHandler(classService *services.LiveClassService) *LiveClassHandler {
+	return &LiveClassHandler{
+		classService: classService,
+		validate:     validators.New(),
+	}
+}
+
+// ListClasses godoc
+// @Summary List live classes
+// @Description Classes by start time. Students see classes for everyone and for their groups, with their own attendance.
+// @Tags classes
+// @Produce json
+// @Param from query string false "RFC3339, classes still running at or starting after"
+// @Param to query string false "RFC3339, classes starting before"
+// @Param group_id query string false "Only classes for this group"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/classes [get]
+// @Security BearerAuth
+func (h *LiveClassHandler) ListClasses(c *gin.Context) {
+	var query validators.ListLiveClassesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, bindError(err, "Invalid query parameters"))
+		return
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	filter := repositories.LiveClassFilter{From: query.From.Ptr(), To: query.To.Ptr()}
+	if query.GroupID != nil {
+		id := uuid.MustParse(*query.GroupID) // Checked by the validator
+		filter.GroupID = &id
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	classes, err := h.classService.List(c.Request.Context(), userID, middleware.IsAdmin(c), filter)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"classes": classes,
+	})
+}
+
+// CreateClass godoc
+// @Summary Schedule a live class (admin only)
+// @Description Classes with a group are only visible to its members; you are the instructor
+// @Tags classes
+// @Accept json
+// @Produce json
+// @Param request body validators.CreateLiveClassRequest true "Class"
+// @Success 201 {object} models.LiveClass
+// @Router /api/v1/classes [post]
+// @Security BearerAuth
+func (h *LiveClassHandler) CreateClass(c *gin.Context) {
+	var req validators.CreateLiveClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, bindError(err, "Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	class := &models.LiveClass{
+		Title:        req.Title,
+		Description:  req.Description,
+		Location:     req.Location,
+		ProgramID:    parseOptionalUUID(req.ProgramID),
+		GroupID:      parseOptionalUUID(req.GroupID),
+		InstructorID: &userID,
+		StartsAt:     req.StartsAt.Time,
+		EndsAt:       req.EndsAt.Time,
+	}
+	created, err := h.classService.Create(c.Request.Context(), class)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetClass godoc
+// @Summary Get a live class
+// @Tags classes
+// @Produce json
+// @Param id path string true "Class ID"
+// @Success 200 {object} models.LiveClass
+// @Router /api/v1/classes/{id} [get]
+// @Security BearerAuth
+func (h *LiveClassHandler) GetClass(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	class, err := h.classService.Get(c.Request.Context(), id, userID, middleware.IsAdmin(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, class)
+}
+
+// UpdateClass godoc
+// @Summary Update a live class (admin only)
+// @Tags classes
+// @Accept json
+// @Produce json
+// @Param id path string true "Class ID"
+// @Param request body validators.UpdateLiveClassRequest true "Fields to change"
+// @Success 200 {object} models.LiveClass
+// @Router /api/v1/classes/{id} [put]
+// @Security BearerAuth
+func (h *LiveClassHandler) UpdateClass(c *gin.Context) {
+	id, _, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req validators.UpdateLiveClassRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, bindError(err, "Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	class, err := h.classService.Update(c.Request.Context(), id, req.Title, req.Description, req.Location,
+		parseOptionalUUID(req.ProgramID), parseOptionalUUID(req.GroupID), req.StartsAt.Ptr(), req.EndsAt.Ptr())
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, class)
+}
+
+// DeleteClass godoc
+// @Summary Delete a live class and its attendance (admin only)
+// @Tags classes
+// @Param id path string true "Class ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/classes/{id} [delete]
+// @Security BearerAuth
+func (h *LiveClassHandler) DeleteClass(c *gin.Context) {
+	id, _, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.classService.Delete(c.Request.Context(), id); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Class deleted successfully",
+	})
+}
+
+// CreateCheckInCode godoc
+// @Summary Open self check-in for a live class (admin only)
+// @Description Replaces any earlier code. Students checking in more than 10 minutes after the start are marked late.
+// @Tags classes
+// @Accept json
+// @Produce json
+// @Param id path string true "Class ID"
+// @Param request body validators.CreateCheckInCodeRequest false "How long the code works (default 15 minutes)"
+// @Success 201 {object} models.CheckInCode
+// @Router /api/v1/classes/{id}/check-in-code [post]
+// @Security BearerAuth
+func (h *LiveClassHandler) CreateCheckInCode(c *gin.Context) {
+	id, _, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req validators.CreateCheckInCodeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+			return
+		}
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	validFor := defaultCheckInValidity
+	if req.ValidMinutes > 0 {
+		validFor = time.Duration(req.ValidMinutes) * time.Minute
+	}
+
+	code, err := h.classService.CreateCheckInCode(c.Request.Context(), id, validFor)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, code)
+}
+
+// CheckIn godoc
+// @Summary Check in to a live class with its code
+// @Tags classes
+// @Accept json
+// @Produce json
+// @Param request body validators.CheckInRequest true "Check-in code"
+// @Success 200 {object} models.LiveClass
+// @Router /api/v1/classes/check-in [post]
+// @Security BearerAuth
+func (h *LiveClassHandler) CheckIn(c *gin.Context) {
+	var req validators.CheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	class, err := h.classService.CheckIn(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, class)
+}
+
+// ListAttendance godoc
+// @Summary List attendance of a live class (admin only)
+// @Tags classes
+// @Produce json
+// @Param id path string true "Class ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/classes/{id}/attendance [get]
+// @Security BearerAuth
+func (h *LiveClassHandler) ListAttendance(c *gin.Context) {
+	id, _, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	attendance, err := h.classService.ListAttendance(c.Request.Context(), id)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attendance": attendance,
+	})
+}
+
+// MarkAttendance godoc
+// @Summary Mark attendance of a live class (admin only)
+// @Description Sets present, late, excused or absent per student, overwriting earlier marks and check-ins
+// @Tags classes
+// @Accept json
+// @Produce json
+// @Param id path string true "Class ID"
+// @Param request body validators.MarkAttendanceRequest true "Attendance per student"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/classes/{id}/attendance [put]
+// @Security BearerAuth
+func (h *LiveClassHandler) MarkAttendance(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req validators.MarkAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	statuses := make(map[uuid.UUID]models.AttendanceStatus, len(req.Entries))
+	for _, entry := range req.Entries {
+		statuses[uuid.MustParse(entry.UserID)] = models.AttendanceStatus(entry.Status) // Checked by the validator
+	}
+
+	attendance, err := h.classService.MarkAttendance(c.Request.Context(), id, userID, statuses)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attendance": attendance,
+	})
+}
+
+// RemoveAttendance godoc
+// @Summary Remove a student's attendance mark (admin only)
+// @Tags classes
+// @Param id path string true "Class ID"
+// @Param userId path string true "Student ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/classes/{id}/attendance/{userId} [delete]
+// @Security BearerAuth
+func (h *LiveClassHandler) RemoveAttendance(c *gin.Context) {
+	id, _, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	studentID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid user ID"))
+		return
+	}
+
+	if err := h.classService.RemoveAttendance(c.Request.Context(), id, studentID); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attendance removed successfully",
+	})
+}
+
+func (h *LiveClassHandler) parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid class ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, userID, true
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type LoginDeviceHandler struct {
+	loginDeviceService *services.LoginDeviceService
+	validate           *validator.Validate
+}
+
+func NewLoginDeviceHandler(loginDeviceService *services.LoginDeviceService) *LoginDeviceHandler {
+	return &LoginDeviceHandler{
+		loginDeviceService: loginDeviceService,
+		validate:           validators.New(),
+	}
+}
+
+// ListMyDevices godoc
+// @Summary List the devices I signed in from
+// @Description Most recently used first. current marks the device of this request.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/me/devices [get]
+// @Security BearerAuth
+func (h *LoginDeviceHandler) ListMyDevices(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	devices, err := h.loginDeviceService.List(c.Request.Context(), userID, middleware.GetDeviceID(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"devices": devices,
+	})
+}
+
+// RevokeMyDevice godoc
+// @Summary Sign out one of my devices
+// @Description Its tokens stop working within LOGIN_REVOCATION_REFRESH_SECONDS and cannot be refreshed. Signing in again on the device counts as a new device.
+// @Tags auth
+// @Param id path string true "Device ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/auth/me/devices/{id} [delete]
+// @Security BearerAuth
+func (h *LoginDeviceHandler) RevokeMyDevice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid device ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	if err := h.loginDeviceService.Revoke(c.Request.Context(), userID, id); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeWithToken godoc
+// @Summary Sign out a device from a new device alert ("this wasn't me")
+// @Description For the page the alert email links to, which passes on the token from its ?token= parameter. No sign-in needed. Returns the device that was signed out; the link can be used more than once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body validators.RevokeLoginDeviceRequest true "Token from the link"
+// @Success 200 {object} models.LoginDevice
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/auth/devices/revoke [post]
+func (h *LoginDeviceHandler) RevokeWithToken(c *gin.Context) {
+	var req validators.RevokeLoginDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	device, err := h.loginDeviceService.RevokeByToken(c.Request.Context(), req.Token)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, device)
+}
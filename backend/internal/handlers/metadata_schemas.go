@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type MetadataSchemaHandler struct {
+	schemaService *services.MetadataSchemaService
+	validate      *validator.Validate
+}
+
+func NewMetadataSchemaHandler(schemaService *services.MetadataSchemaService) *MetadataSchemaHandler {
+	return &MetadataSchemaHandler{
+		schemaService: schemaService,
+		validate:      validators.New(),
+	}
+}
+
+// ListMetadataSchemas godoc
+// @Summary List metadata schemas
+// @Description Clients use these JSON Schemas to generate metadata forms for programs and exercises.
+// @Tags metadata-schemas
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/metadata-schemas [get]
+// @Security BearerAuth
+func (h *MetadataSchemaHandler) ListMetadataSchemas(c *gin.Context) {
+	schemas, err := h.schemaService.List(c.Request.Context())
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"schemas": schemas,
+	})
+}
+
+// GetMetadataSchema godoc
+// @Summary Get the metadata schema for an entity type
+// @Tags metadata-schemas
+// @Produce json
+// @Param entity_type path string true "program or exercise"
+// @Success 200 {object} models.MetadataSchema
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/metadata-schemas/{entity_type} [get]
+// @Security BearerAuth
+func (h *MetadataSchemaHandler) GetMetadataSchema(c *gin.Context) {
+	entityType, ok := parseMetadataEntityType(c)
+	if !ok {
+		return
+	}
+
+	schema, err := h.schemaService.Get(c.Request.Context(), entityType)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+// SetMetadataSchema godoc
+// @Summary Create or replace a metadata schema (admin only)
+// @Description Metadata saved afterwards must validate against the schema. Existing metadata is checked the next time it is saved.
+// @Tags metadata-schemas
+// @Accept json
+// @Produce json
+// @Param entity_type path string true "program or exercise"
+// @Param request body validators.SetMetadataSchemaRequest true "JSON Schema"
+// @Success 200 {object} models.MetadataSchema
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/metadata-schemas/{entity_type} [put]
+// @Security BearerAuth
+func (h *MetadataSchemaHandler) SetMetadataSchema(c *gin.Context) {
+	entityType, ok := parseMetadataEntityType(c)
+	if !ok {
+		return
+	}
+
+	var req validators.SetMetadataSchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	schema := &models.MetadataSchema{
+		EntityType: entityType,
+		Schema:     req.Schema,
+		UpdatedBy:  &userID,
+	}
+	if err := h.schemaService.Set(c.Request.Context(), schema); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+// DeleteMetadataSchema godoc
+// @Summary Delete a metadata schema (admin only)
+// @Tags metadata-schemas
+// @Param entity_type path string true "program or exercise"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/metadata-schemas/{entity_type} [delete]
+// @Security BearerAuth
+func (h *MetadataSchemaHandler) DeleteMetadataSchema(c *gin.Context) {
+	entityType, ok := parseMetadataEntityType(c)
+	if !ok {
+		return
+	}
+
+	if err := h.schemaService.Delete(c.Request.Context(), entityType); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Metadata schema deleted successfully",
+	})
+}
+
+func parseMetadataEntityType(c *gin.Context) (models.MetadataEntityType, bool) {
+	entityType := models.MetadataEntityType(c.Param("entity_type"))
+	switch entityType {
+	case models.MetadataEntityProgram, models.MetadataEntityExercise:
+		return entityType, true
+	default:
+		respondWithError(c, appErrors.NewBadRequestError("Entity type must be program or exercise"))
+		return "", false
+	}
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type ModerationHandler struct {
+	moderationService *services.ModerationService
+	validate          *validator.Validate
+}
+
+func NewModerationHandler(moderationService *services.ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+		validate:          validators.New(),
+	}
+}
+
+// ReportMessage godoc
+// @Summary Report a submission message as abusive
+// @Description Opens a moderation case or adds to the pending one. Once enough users reported it, the message is hidden until an admin decides.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path string true "Message ID"
+// @Param request body validators.ReportContentRequest true "Reason"
+// @Success 201 {object} models.ModerationReport
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Already reported"
+// @Router /api/v1/messages/{id}/report [post]
+// @Security BearerAuth
+func (h *ModerationHandler) ReportMessage(c *gin.Context) {
+	h.report(c, models.ModerationMessage, "Invalid message ID")
+}
+
+// ReportProgram godoc
+// @Summary Report a program as abusive
+// @Description Opens a moderation case or adds to the pending one. Once enough users reported it, the program is hidden until an admin decides.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param request body validators.ReportContentRequest true "Reason"
+// @Success 201 {object} models.ModerationReport
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Already reported"
+// @Router /api/v1/programs/{id}/report [post]
+// @Security BearerAuth
+func (h *ModerationHandler) ReportProgram(c *gin.Context) {
+	h.report(c, models.ModerationProgram, "Invalid program ID")
+}
+
+func (h *ModerationHandler) report(c *gin.Context, contentType models.ModerationContentType, invalidID string) {
+	contentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError(invalidID))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	var req validators.ReportContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	report, err := h.moderationService.Report(c.Request.Context(), contentType, contentID, userID, middleware.IsAdmin(c), models.ReportReason(req.Reason), req.Details)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// ListCases godoc
+// @Summary List the moderation queue (admin only)
+// @Description Most reported first. Pending cases may already be hidden for reaching the report threshold.
+// @Tags moderation
+// @Produce json
+// @Param status query string false "pending (default), resolved, hidden or all"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/moderation [get]
+// @Security BearerAuth
+func (h *ModerationHandler) ListCases(c *gin.Context) {
+	var query validators.ListModerationCasesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+	var status *models.ModerationStatus
+	switch query.Status {
+	case "":
+		pending := models.ModerationPending
+		status = &pending
+	case "all":
+	default:
+		s := models.ModerationStatus(query.Status)
+		status = &s
+	}
+
+	cases, err := h.moderationService.List(c.Request.Context(), status, query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cases":  cases,
+		"limit":  query.Limit,
+		"offset": query.Offset,
+	})
+}
+
+// GetCase godoc
+// @Summary Get a moderation case with its reports (admin only)
+// @Tags moderation
+// @Produce json
+// @Param id path string true "Case ID"
+// @Success 200 {object} models.ModerationCase
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/moderation/{id} [get]
+// @Security BearerAuth
+func (h *ModerationHandler) GetCase(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid case ID"))
+		return
+	}
+
+	moderationCase, err := h.moderationService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, moderationCase)
+}
+
+// ResolveCase godoc
+// @Summary Dismiss the reports on a case (admin only)
+// @Description Shows the content again. Works on pending cases and, to undo a decision, hidden ones.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path string true "Case ID"
+// @Param request body validators.ModerationDecisionRequest false "Note"
+// @Success 200 {object} models.ModerationCase
+// @Failure 409 {object} map[string]interface{} "Already resolved"
+// @Router /api/v1/admin/moderation/{id}/resolve [post]
+// @Security BearerAuth
+func (h *ModerationHandler) ResolveCase(c *gin.Context) {
+	h.decide(c, h.moderationService.Resolve)
+}
+
+// HideCase godoc
+// @Summary Hide the reported content (admin only)
+// @Description Closes a pending case. The content stays visible to its author and admins only.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path string true "Case ID"
+// @Param request body validators.ModerationDecisionRequest false "Note"
+// @Success 200 {object} models.ModerationCase
+// @Failure 409 {object} map[string]interface{} "Case is not pending"
+// @Router /api/v1/admin/moderation/{id}/hide [post]
+// @Security BearerAuth
+func (h *ModerationHandler) HideCase(c *gin.Context) {
+	h.decide(c, h.moderationService.Hide)
+}
+
+type moderationDecision func(ctx context.Context, id, adminID uuid.UUID, note *string) (*models.ModerationCase, error)
+
+func (h *ModerationHandler) decide(c *gin.Context, decide moderationDecision) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid case ID"))
+		return
+	}
+
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	var req validators.ModerationDecisionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+			return
+		}
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	moderationCase, err := decide(c.Request.Context(), id, adminID, req.Note)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, moderationCase)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+	digestService       *services.DigestService
+	validate            *validator.Validate
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService, digestService *services.DigestService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		digestService:       digestService,
+		validate:            validators.New(),
+	}
+}
+
+// ListNotifications godoc
+// @Summary List the current user's notifications
+// @Tags notifications
+// @Produce json
+// @Param unread_only query boolean false "Only unread notifications"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/notifications [get]
+// @Security BearerAuth
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	var query validators.ListNotificationsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+
+	// Set defaults
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	notifications, err := h.notificationService.List(c.Request.Context(), userID, query.UnreadOnly, query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"limit":         query.Limit,
+		"offset":        query.Offset,
+	})
+}
+
+// MarkAsRead godoc
+// @Summary Mark a notification as read
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Notification ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/notifications/{id}/read [put]
+// @Security BearerAuth
+func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid notification ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	if err := h.notificationService.MarkAsRead(c.Request.Context(), id, userID); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notification marked as read",
+	})
+}
+
+// GetPreferences godoc
+// @Summary Get the current user's notification preferences
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} models.NotificationPreferences
+// @Router /api/v1/notifications/preferences [get]
+// @Security BearerAuth
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	prefs, err := h.digestService.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdatePreferences godoc
+// @Summary Update the current user's notification preferences
+// @Description Turn the weekly email digest on or off and set the time zone its send window is evaluated in
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body validators.UpdateNotificationPreferencesRequest true "Preferences to change"
+// @Success 200 {object} models.NotificationPreferences
+// @Router /api/v1/notifications/preferences [put]
+// @Security BearerAuth
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	var req validators.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	prefs, err := h.digestService.UpdatePreferences(c.Request.Context(), userID, req.WeeklyDigest, req.Timezone)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// GetDigestPreview godoc
+// @Summary Preview the current user's weekly digest
+// @Description Compiles the digest email content for the past week as it would be sent now
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} models.WeeklyDigest
+// @Router /api/v1/notifications/digest [get]
+// @Security BearerAuth
+func (h *NotificationHandler) GetDigestPreview(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	digest, err := h.digestService.Preview(c.Request.Context(), userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, digest)
+}
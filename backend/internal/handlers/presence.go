@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type PresenceHandler struct {
+	presenceService *services.PresenceService
+}
+
+func NewPresenceHandler(presenceService *services.PresenceService) *PresenceHandler {
+	return &PresenceHandler{presenceService: presenceService}
+}
+
+// Heartbeat godoc
+// @Summary Mark the current user as online
+// @Description Clients send this every few minutes while the app is open and nothing else is requested. Like any authenticated request it is recorded as activity.
+// @Tags presence
+// @Success 204
+// @Router /api/v1/presence/heartbeat [post]
+// @Security BearerAuth
+func (h *PresenceHandler) Heartbeat(c *gin.Context) {
+	// The access log middleware records the request, which is all a heartbeat needs
+	c.Status(http.StatusNoContent)
+}
+
+// GetThreadPresence godoc
+// @Summary Get instructor online status for a submission thread
+// @Description Lists the active instructors with whether they are online and when they were last seen, online ones first
+// @Tags presence
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Success 200 {object} map[string][]models.InstructorPresence
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/submissions/{id}/presence [get]
+// @Security BearerAuth
+func (h *PresenceHandler) GetThreadPresence(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid submission ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+	isAdmin := middleware.IsAdmin(c)
+
+	instructors, err := h.presenceService.ThreadInstructors(c.Request.Context(), submissionID, userID, isAdmin)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"instructors": instructors,
+	})
+}
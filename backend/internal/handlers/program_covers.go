@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+const maxCoverImageBytes = 10 << 20
+
+// UploadProgramCover godoc
+// @Summary Upload a program cover image
+// @Description JPEG or PNG up to 10 MB. Thumbnails are generated in several sizes and returned as cover_thumbnails.
+// @Tags programs
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param file formData file true "Cover image"
+// @Success 200 {object} models.Program
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/cover [post]
+// @Security BearerAuth
+func (h *ProgramHandler) UploadProgramCover(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Image file is required"))
+		return
+	}
+	if fileHeader.Size > maxCoverImageBytes {
+		respondWithError(c, appErrors.NewBadRequestError("Image file is too large"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Failed to read image file"))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxCoverImageBytes))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Failed to read image file"))
+		return
+	}
+
+	userID, userRole, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	program, err := h.coverService.Upload(c.Request.Context(), programID, userID, userRole, data)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, program)
+}
+
+// SelectProgramCover godoc
+// @Summary Reuse another program's cover image
+// @Tags programs
+// @Accept json
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param request body validators.SelectCoverRequest true "Source program"
+// @Success 200 {object} models.Program
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/cover [put]
+// @Security BearerAuth
+func (h *ProgramHandler) SelectProgramCover(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	var req validators.SelectCoverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, userRole, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	sourceID := uuid.MustParse(req.FromProgramID) // validated above
+	program, err := h.coverService.SelectFrom(c.Request.Context(), programID, sourceID, userID, userRole)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, program)
+}
+
+// DeleteProgramCover godoc
+// @Summary Remove a program's cover image
+// @Tags programs
+// @Param id path string true "Program ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/cover [delete]
+// @Security BearerAuth
+func (h *ProgramHandler) DeleteProgramCover(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	userID, userRole, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.coverService.Remove(c.Request.Context(), programID, userID, userRole); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cover image removed",
+	})
+}
+
+// currentUser reads the authenticated user's ID and role, responding with an error if either is missing
+func currentUser(c *gin.Context) (uuid.UUID, models.UserRole, bool) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return uuid.Nil, "", false
+	}
+	userRoleStr, err := middleware.GetUserRole(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return uuid.Nil, "", false
+	}
+	return userID, models.UserRole(userRoleStr), true
+}
@@ -1,7 +1,15 @@
 package handlers
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -11,27 +19,60 @@ import (
 	"github.com/xuangong/backend/internal/services"
 	"github.com/xuangong/backend/internal/validators"
 	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/timestamp"
 )
 
+//go:generate go tool moq -skip-ensure -rm -out ../../pkg/testutil/mocks/program_service.go -pkg mocks . ProgramService
+
+// ProgramService is the program management used by ProgramHandler, implemented by services.ProgramService
+type ProgramService interface {
+	List(ctx context.Context, isTemplate, isPublic *bool, limit, offset int) ([]models.ProgramWithExercises, error)
+	GetByID(ctx context.Context, id uuid.UUID, includeExercises bool) (*models.ProgramWithExercises, error)
+	Create(ctx context.Context, program *models.Program, exercises []models.Exercise, ownedBy uuid.UUID, policy models.DuplicatePolicy) (*models.ProgramCreateResult, error)
+	Update(ctx context.Context, id uuid.UUID, updates *models.Program, exercises []models.Exercise, userID uuid.UUID) error
+	SoftDelete(ctx context.Context, id uuid.UUID, userID uuid.UUID, userRole models.UserRole) error
+	Purge(ctx context.Context, id uuid.UUID) error
+	AssignToUsers(ctx context.Context, programID, assignedBy uuid.UUID, userIDs []uuid.UUID) error
+	BulkAssign(ctx context.Context, programID, assignedBy uuid.UUID, targets []models.AssignmentTarget, inviteMissing bool, welcome *models.WelcomeThread) (*models.AssignmentReport, error)
+	GetUserPrograms(ctx context.Context, userID uuid.UUID) ([]models.ProgramWithExercises, error)
+	UpdateUserProgramSettings(ctx context.Context, userID, programID uuid.UUID, customSettings map[string]interface{}) (*models.UserProgram, error)
+}
+
+var _ ProgramService = (*services.ProgramService)(nil)
+
 type ProgramHandler struct {
-	programService *services.ProgramService
-	validate       *validator.Validate
+	programService     ProgramService
+	audioCueService    *services.AudioCueService
+	coverService       *services.CoverService
+	translationService *services.TranslationService
+	limitationService  *services.LimitationService
+	templateCache      *services.TemplateCache
+	validate           *validator.Validate
 }
 
-func NewProgramHandler(programService *services.ProgramService) *ProgramHandler {
+func NewProgramHandler(programService ProgramService, audioCueService *services.AudioCueService, coverService *services.CoverService, translationService *services.TranslationService, limitationService *services.LimitationService, templateCache *services.TemplateCache) *ProgramHandler {
 	return &ProgramHandler{
-		programService: programService,
-		validate:       validator.New(),
+		programService:     programService,
+		audioCueService:    audioCueService,
+		coverService:       coverService,
+		translationService: translationService,
+		limitationService:  limitationService,
+		templateCache:      templateCache,
+		validate:           validators.New(),
 	}
 }
 
 // ListPrograms godoc
 // @Summary List programs
+// @Description The public listing (is_public=true) is the same for every user and is served from a cache, with Cache-Control, ETag and surrogate key headers for browsers and CDNs.
 // @Tags programs
 // @Produce json
 // @Param is_template query boolean false "Filter by template status"
 // @Param is_public query boolean false "Filter by public status"
+// @Param Accept-Language header string false "Content locale (en, de, zh); falls back to en"
+// @Param If-None-Match header string false "ETag of a cached public listing"
 // @Success 200 {object} map[string]interface{}
+// @Success 304 "Public listing not modified"
 // @Router /api/v1/programs [get]
 // @Security BearerAuth
 func (h *ProgramHandler) ListPrograms(c *gin.Context) {
@@ -46,6 +87,11 @@ func (h *ProgramHandler) ListPrograms(c *gin.Context) {
 		query.Limit = 20
 	}
 
+	if query.IsPublic != nil && *query.IsPublic {
+		h.listPublicPrograms(c, query)
+		return
+	}
+
 	programs, err := h.programService.List(
 		c.Request.Context(),
 		query.IsTemplate,
@@ -58,6 +104,11 @@ func (h *ProgramHandler) ListPrograms(c *gin.Context) {
 		return
 	}
 
+	if err := h.translationService.Localize(c.Request.Context(), programs, middleware.GetLocale(c)); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"programs": programs,
 		"limit":    query.Limit,
@@ -65,11 +116,64 @@ func (h *ProgramHandler) ListPrograms(c *gin.Context) {
 	})
 }
 
+// listPublicPrograms serves the public listing from the template cache, rendering and caching
+// it on a miss
+func (h *ProgramHandler) listPublicPrograms(c *gin.Context, query validators.ListProgramsQuery) {
+	locale := middleware.GetLocale(c)
+	isTemplate := "any"
+	if query.IsTemplate != nil {
+		isTemplate = strconv.FormatBool(*query.IsTemplate)
+	}
+	key := fmt.Sprintf("%s|%d|%d|%s", isTemplate, query.Limit, query.Offset, locale)
+
+	listing := h.templateCache.Get(key)
+	if listing == nil {
+		programs, err := h.programService.List(c.Request.Context(), query.IsTemplate, query.IsPublic, query.Limit, query.Offset)
+		if err != nil {
+			respondWithAppError(c, err)
+			return
+		}
+		if err := h.translationService.Localize(c.Request.Context(), programs, locale); err != nil {
+			respondWithAppError(c, err)
+			return
+		}
+
+		body, err := json.Marshal(gin.H{
+			"programs": programs,
+			"limit":    query.Limit,
+			"offset":   query.Offset,
+		})
+		if err != nil {
+			respondWithError(c, appErrors.NewInternalError("Failed to render programs").WithError(err))
+			return
+		}
+		surrogateKeys := make([]string, 0, len(programs)+1)
+		surrogateKeys = append(surrogateKeys, "programs-public")
+		for _, program := range programs {
+			surrogateKeys = append(surrogateKeys, "program-"+program.Program.ID.String())
+		}
+		listing = h.templateCache.Put(key, body, surrogateKeys)
+	}
+
+	c.Header("Cache-Control", h.templateCache.CacheControl())
+	c.Header("ETag", listing.ETag)
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	if header := h.templateCache.SurrogateKeyHeader(); header != "" {
+		c.Header(header, strings.Join(listing.SurrogateKeys, " "))
+	}
+	if c.GetHeader("If-None-Match") == listing.ETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", listing.Body)
+}
+
 // GetProgram godoc
 // @Summary Get program by ID
 // @Tags programs
 // @Produce json
 // @Param id path string true "Program ID"
+// @Param Accept-Language header string false "Content locale (en, de, zh); falls back to en"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/programs/{id} [get]
 // @Security BearerAuth
@@ -85,23 +189,40 @@ func (h *ProgramHandler) GetProgram(c *gin.Context) {
 		respondWithAppError(c, err)
 		return
 	}
+	if program.Program.HiddenAt != nil && !middleware.IsAdmin(c) {
+		// Hidden by moderation: only the owner still sees it
+		userID, _ := middleware.GetUserID(c)
+		if program.Program.OwnedBy == nil || *program.Program.OwnedBy != userID {
+			respondWithError(c, appErrors.NewNotFoundError("Program"))
+			return
+		}
+	}
 
-	c.JSON(http.StatusOK, program)
+	localized := []models.ProgramWithExercises{*program}
+	if err := h.translationService.Localize(c.Request.Context(), localized, middleware.GetLocale(c)); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, localized[0])
 }
 
 // CreateProgram godoc
 // @Summary Create a new program
+// @Description Templates and public programs are checked for near-duplicates (same name or same exercises).
+// @Description Similar programs are listed in similar_programs; with on_duplicate=merge an exact duplicate is returned instead of creating a copy.
 // @Tags programs
 // @Accept json
 // @Produce json
 // @Param request body validators.CreateProgramRequest true "Program details"
-// @Success 201 {object} map[string]interface{}
+// @Success 200 {object} models.ProgramCreateResult "Merged into an existing program"
+// @Success 201 {object} models.ProgramCreateResult
 // @Router /api/v1/programs [post]
 // @Security BearerAuth
 func (h *ProgramHandler) CreateProgram(c *gin.Context) {
 	var req validators.CreateProgramRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		respondWithError(c, bindError(err, "Invalid request body"))
 		return
 	}
 
@@ -132,6 +253,12 @@ func (h *ProgramHandler) CreateProgram(c *gin.Context) {
 		ownedBy = parsedOwnerID
 	}
 
+	publishAt, unpublishAt, err := checkPublishSchedule(req.PublishAt, req.UnpublishAt)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
 	program := &models.Program{
 		Name:               req.Name,
 		Description:        req.Description,
@@ -140,6 +267,8 @@ func (h *ProgramHandler) CreateProgram(c *gin.Context) {
 		Tags:               req.Tags,
 		Metadata:           req.Metadata,
 		RepetitionsPlanned: req.RepetitionsPlanned,
+		PublishAt:          publishAt,
+		UnpublishAt:        unpublishAt,
 	}
 
 	// Convert ExerciseRequest to Exercise models
@@ -155,24 +284,37 @@ func (h *ProgramHandler) CreateProgram(c *gin.Context) {
 			RestAfterSeconds:    exReq.RestAfterSeconds,
 			HasSides:            exReq.HasSides,
 			SideDurationSeconds: exReq.SideDurationSeconds,
+			Tempo:               exerciseTempo(exReq.Tempo),
+			BreathingPattern:    breathingPattern(exReq.BreathingPattern),
+			Contraindications:   exReq.Contraindications,
 			Metadata:            exReq.Metadata,
 		}
 	}
 
-	if err := h.programService.Create(c.Request.Context(), program, exercises, ownedBy); err != nil {
+	policy := models.DuplicatePolicyWarn
+	if req.OnDuplicate != "" {
+		policy = models.DuplicatePolicy(req.OnDuplicate)
+	}
+
+	result, err := h.programService.Create(c.Request.Context(), program, exercises, ownedBy, policy)
+	if err != nil {
 		respondWithAppError(c, err)
 		return
 	}
 
 	// If created for another user, auto-assign to them
 	if req.OwnedByUserID != nil {
-		if err := h.programService.AssignToUsers(c.Request.Context(), program.ID, userID, []uuid.UUID{ownedBy}); err != nil {
+		if err := h.programService.AssignToUsers(c.Request.Context(), result.Program.ID, userID, []uuid.UUID{ownedBy}); err != nil {
 			respondWithAppError(c, err)
 			return
 		}
 	}
 
-	c.JSON(http.StatusCreated, program)
+	status := http.StatusCreated
+	if result.Merged {
+		status = http.StatusOK
+	}
+	c.JSON(status, result)
 }
 
 // UpdateProgram godoc
@@ -201,7 +343,7 @@ func (h *ProgramHandler) UpdateProgram(c *gin.Context) {
 
 	var req validators.UpdateProgramRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		respondWithError(c, bindError(err, "Invalid request body"))
 		return
 	}
 
@@ -233,6 +375,11 @@ func (h *ProgramHandler) UpdateProgram(c *gin.Context) {
 	if req.RepetitionsPlanned != nil {
 		program.RepetitionsPlanned = req.RepetitionsPlanned
 	}
+	program.PublishAt, program.UnpublishAt, err = checkPublishSchedule(req.PublishAt, req.UnpublishAt)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
 
 	// Convert ExerciseRequest to Exercise models
 	exercises := make([]models.Exercise, len(req.Exercises))
@@ -252,6 +399,9 @@ func (h *ProgramHandler) UpdateProgram(c *gin.Context) {
 			RestAfterSeconds:    exReq.RestAfterSeconds,
 			HasSides:            exReq.HasSides,
 			SideDurationSeconds: exReq.SideDurationSeconds,
+			Tempo:               exerciseTempo(exReq.Tempo),
+			BreathingPattern:    breathingPattern(exReq.BreathingPattern),
+			Contraindications:   exReq.Contraindications,
 			Metadata:            exReq.Metadata,
 		}
 	}
@@ -268,6 +418,7 @@ func (h *ProgramHandler) UpdateProgram(c *gin.Context) {
 
 // DeleteProgram godoc
 // @Summary Delete a program (soft delete)
+// @Description Owner or admin. The program disappears from listings and can no longer be opened; its practice history is kept until an admin purges it.
 // @Tags programs
 // @Param id path string true "Program ID"
 // @Success 200 {object} map[string]interface{}
@@ -306,14 +457,40 @@ func (h *ProgramHandler) DeleteProgram(c *gin.Context) {
 	})
 }
 
+// PurgeProgram godoc
+// @Summary Purge a deleted program (admin only)
+// @Description Removes the program for good, with its exercises, assignments, practice sessions and threads. The program must have been deleted first.
+// @Tags admin
+// @Param id path string true "Program ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /api/v1/admin/programs/{id} [delete]
+// @Security BearerAuth
+func (h *ProgramHandler) PurgeProgram(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	if err := h.programService.Purge(c.Request.Context(), id); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // AssignProgram godoc
 // @Summary Assign program to users
+// @Description Users can be given by ID and/or email. Returns a per-row report; duplicates and existing assignments are skipped.
 // @Tags programs
 // @Accept json
 // @Produce json
 // @Param id path string true "Program ID"
 // @Param request body validators.AssignProgramRequest true "Assignment details"
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} models.AssignmentReport
 // @Router /api/v1/programs/{id}/assign [post]
 // @Security BearerAuth
 func (h *ProgramHandler) AssignProgram(c *gin.Context) {
@@ -340,36 +517,148 @@ func (h *ProgramHandler) AssignProgram(c *gin.Context) {
 		return
 	}
 
-	// Parse user IDs
-	var userIDs []uuid.UUID
-	for _, idStr := range req.UserIDs {
-		id, err := uuid.Parse(idStr)
-		if err != nil {
-			respondWithError(c, appErrors.NewBadRequestError("Invalid user ID format"))
-			return
-		}
-		userIDs = append(userIDs, id)
+	var targets []models.AssignmentTarget
+	for _, identifier := range append(req.UserIDs, req.Emails...) {
+		targets = append(targets, models.AssignmentTarget{Row: len(targets) + 1, Identifier: identifier})
 	}
 
-	if err := h.programService.AssignToUsers(
-		c.Request.Context(),
-		programID,
-		userID,
-		userIDs,
-	); err != nil {
+	report, err := h.programService.BulkAssign(c.Request.Context(), programID, userID, targets, req.InviteMissing, welcomeThread(req.WelcomeThreadRequest))
+	if err != nil {
 		respondWithAppError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Program assigned successfully",
-	})
+	c.JSON(http.StatusOK, report)
+}
+
+// AssignProgramCSV godoc
+// @Summary Assign program to users from a CSV file
+// @Description The first column of each row holds a user ID or email. A header row ("email" or "user_id") is skipped.
+// @Tags programs
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param file formData file true "CSV file"
+// @Param invite_missing formData boolean false "Invite emails without an account"
+// @Param welcome_message formData string false "Open a thread with this message for each newly assigned user"
+// @Param welcome_title formData string false "Title of the welcome thread"
+// @Success 200 {object} models.AssignmentReport
+// @Router /api/v1/programs/{id}/assign/csv [post]
+// @Security BearerAuth
+func (h *ProgramHandler) AssignProgramCSV(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("CSV file is required"))
+		return
+	}
+	if fileHeader.Size > maxAssignmentCSVBytes {
+		respondWithError(c, appErrors.NewBadRequestError("CSV file is too large"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Failed to read CSV file"))
+		return
+	}
+	defer file.Close()
+
+	targets, err := parseAssignmentCSV(file)
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	inviteMissing := c.PostForm("invite_missing") == "true"
+
+	welcome := validators.WelcomeThreadRequest{
+		WelcomeMessage: c.PostForm("welcome_message"),
+		WelcomeTitle:   c.PostForm("welcome_title"),
+	}
+	if err := h.validate.Struct(welcome); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	report, err := h.programService.BulkAssign(c.Request.Context(), programID, userID, targets, inviteMissing, welcomeThread(welcome))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// welcomeThread returns the thread to open for newly assigned users, nil without a welcome message
+func welcomeThread(req validators.WelcomeThreadRequest) *models.WelcomeThread {
+	if strings.TrimSpace(req.WelcomeMessage) == "" {
+		return nil
+	}
+	return &models.WelcomeThread{Title: strings.TrimSpace(req.WelcomeTitle), Message: req.WelcomeMessage}
+}
+
+const (
+	maxAssignmentCSVBytes = 1 << 20
+	maxAssignmentCSVRows  = 1000
+)
+
+// parseAssignmentCSV reads the first column of each non-empty row. Rows are numbered as in the file.
+func parseAssignmentCSV(r io.Reader) ([]models.AssignmentTarget, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var targets []models.AssignmentTarget
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CSV: %v", err)
+		}
+
+		// The reader skips blank lines, so take the row number from the file position
+		row, _ := reader.FieldPos(0)
+		value := strings.TrimSpace(record[0])
+		if value == "" {
+			continue
+		}
+		if row == 1 && (strings.EqualFold(value, "email") || strings.EqualFold(value, "user_id")) {
+			continue
+		}
+
+		targets = append(targets, models.AssignmentTarget{Row: row, Identifier: value})
+		if len(targets) > maxAssignmentCSVRows {
+			return nil, fmt.Errorf("CSV file has more than %d rows", maxAssignmentCSVRows)
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("CSV file contains no users")
+	}
+
+	return targets, nil
 }
 
 // GetMyPrograms godoc
 // @Summary Get user's assigned programs
+// @Description Exercises contraindicated for the user's limitations are replaced by a safe substitute or flagged, and carry an adjustment. Adjusted programs have a plan_review_status.
 // @Tags programs
 // @Produce json
+// @Param Accept-Language header string false "Content locale (en, de, zh); falls back to en"
 // @Success 200 {object} map[string]interface{}
 // @Router /api/v1/my-programs [get]
 // @Security BearerAuth
@@ -386,7 +675,135 @@ func (h *ProgramHandler) GetMyPrograms(c *gin.Context) {
 		return
 	}
 
+	if err := h.translationService.Localize(c.Request.Context(), programs, middleware.GetLocale(c)); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	// After localizing, so substituted exercises keep the substitute's name
+	if err := h.limitationService.Adjust(c.Request.Context(), userID, programs); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"programs": programs,
 	})
 }
+
+// GetProgramTimeline godoc
+// @Summary Get the compiled cue timeline for a program
+// @Description Flattens the program into timestamped cues (exercise start, side switch, halfway, rest) with the current user's overrides applied.
+// @Description Cues include audio_url when a spoken clip has been generated in the user's language.
+// @Tags programs
+// @Produce json
+// @Param id path string true "Program ID"
+// @Success 200 {object} models.Timeline
+// @Router /api/v1/programs/{id}/timeline [get]
+// @Security BearerAuth
+func (h *ProgramHandler) GetProgramTimeline(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	tl, err := h.audioCueService.GetTimeline(c.Request.Context(), id, userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tl)
+}
+
+// UpdateProgramSettings godoc
+// @Summary Change your settings for an assigned program
+// @Description Replaces custom_settings, such as halfway_cues and exercise_overrides. An override's substitute_id swaps the exercise for one of its substitutes on the timeline.
+// @Tags programs
+// @Accept json
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param request body validators.UpdateProgramSettingsRequest true "Settings"
+// @Success 200 {object} models.UserProgram
+// @Router /api/v1/programs/{id}/settings [put]
+// @Security BearerAuth
+func (h *ProgramHandler) UpdateProgramSettings(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	var req validators.UpdateProgramSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	userProgram, err := h.programService.UpdateUserProgramSettings(c.Request.Context(), userID, id, req.CustomSettings)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, userProgram)
+}
+
+// GenerateProgramAudio godoc
+// @Summary Pre-generate spoken audio cues for a program
+// @Description Synthesizes exercise names, counts and cue phrases in the current user's language. Already cached clips are reused.
+// @Tags programs
+// @Produce json
+// @Param id path string true "Program ID"
+// @Success 200 {object} services.AudioCueGeneration
+// @Router /api/v1/programs/{id}/audio [post]
+// @Security BearerAuth
+func (h *ProgramHandler) GenerateProgramAudio(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	result, err := h.audioCueService.GenerateForProgram(c.Request.Context(), id, userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// checkPublishSchedule checks the order of optional publish/unpublish times
+func checkPublishSchedule(publishAt, unpublishAt *timestamp.Time) (*time.Time, *time.Time, error) {
+	publish, unpublish := publishAt.Ptr(), unpublishAt.Ptr()
+	if publish != nil && unpublish != nil && !unpublish.After(*publish) {
+		return nil, nil, appErrors.NewBadRequestError("unpublish_at must be after publish_at")
+	}
+
+	return publish, unpublish, nil
+}
@@ -5,61 +5,21 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/xuangong/backend/internal/models"
 	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/testutil/mocks"
+	"github.com/xuangong/backend/pkg/timestamp"
 )
 
-// MockProgramService wraps the testutil.MockProgramRepository to provide service-level mocking
-type MockProgramService struct {
-	SoftDeleteFunc func(ctx context.Context, id uuid.UUID, userID uuid.UUID, userRole models.UserRole) error
-	GetByIDFunc    func(ctx context.Context, id uuid.UUID, includeExercises bool) (*models.ProgramWithExercises, error)
-}
-
-func (m *MockProgramService) SoftDelete(ctx context.Context, id uuid.UUID, userID uuid.UUID, userRole models.UserRole) error {
-	if m.SoftDeleteFunc != nil {
-		return m.SoftDeleteFunc(ctx, id, userID, userRole)
-	}
-	return nil
-}
-
-func (m *MockProgramService) GetByID(ctx context.Context, id uuid.UUID, includeExercises bool) (*models.ProgramWithExercises, error) {
-	if m.GetByIDFunc != nil {
-		return m.GetByIDFunc(ctx, id, includeExercises)
-	}
-	return nil, nil
-}
-
-// Stub out other methods that ProgramHandler might need
-func (m *MockProgramService) Create(ctx context.Context, program *models.Program, exercises []models.Exercise, ownedBy uuid.UUID) error {
-	return nil
-}
-
-func (m *MockProgramService) List(ctx context.Context, isTemplate, isPublic *bool, limit, offset int) ([]models.ProgramWithExercises, error) {
-	return nil, nil
-}
-
-func (m *MockProgramService) Update(ctx context.Context, id uuid.UUID, updates *models.Program, exercises []models.Exercise, userID uuid.UUID) error {
-	return nil
-}
-
-func (m *MockProgramService) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
-	return nil
-}
-
-func (m *MockProgramService) AssignToUsers(ctx context.Context, programID, assignedBy uuid.UUID, userIDs []uuid.UUID) error {
-	return nil
-}
-
-func (m *MockProgramService) GetUserPrograms(ctx context.Context, userID uuid.UUID) ([]models.ProgramWithExercises, error) {
-	return nil, nil
-}
-
-func (m *MockProgramService) UpdateUserProgramSettings(ctx context.Context, userID, programID uuid.UUID, customSettings map[string]interface{}) error {
-	return nil
+// newTestProgramHandler returns a ProgramHandler on the mock, without the services the tested
+// endpoints do not reach
+func newTestProgramHandler(programService ProgramService) *ProgramHandler {
+	return NewProgramHandler(programService, nil, nil, nil, nil, nil)
 }
 
 func TestProgramHandler_SoftDeleteProgram(t *testing.T) {
@@ -70,7 +30,7 @@ func TestProgramHandler_SoftDeleteProgram(t *testing.T) {
 		programID          string
 		userID             uuid.UUID
 		userRole           models.UserRole
-		setupMockService   func(*MockProgramService)
+		setupMockService   func(*mocks.ProgramServiceMock)
 		expectedStatus     int
 		expectedErrCode    string
 		expectedErrMessage string
@@ -80,7 +40,7 @@ func TestProgramHandler_SoftDeleteProgram(t *testing.T) {
 			programID: uuid.New().String(),
 			userID:    uuid.New(),
 			userRole:  models.RoleAdmin,
-			setupMockService: func(mock *MockProgramService) {
+			setupMockService: func(mock *mocks.ProgramServiceMock) {
 				mock.SoftDeleteFunc = func(ctx context.Context, id uuid.UUID, userID uuid.UUID, userRole models.UserRole) error {
 					return nil
 				}
@@ -92,7 +52,7 @@ func TestProgramHandler_SoftDeleteProgram(t *testing.T) {
 			programID: uuid.New().String(),
 			userID:    uuid.New(),
 			userRole:  models.RoleStudent,
-			setupMockService: func(mock *MockProgramService) {
+			setupMockService: func(mock *mocks.ProgramServiceMock) {
 				mock.SoftDeleteFunc = func(ctx context.Context, id uuid.UUID, userID uuid.UUID, userRole models.UserRole) error {
 					return nil
 				}
@@ -104,8 +64,8 @@ func TestProgramHandler_SoftDeleteProgram(t *testing.T) {
 			programID: "invalid-uuid",
 			userID:    uuid.New(),
 			userRole:  models.RoleAdmin,
-			setupMockService: func(mock *MockProgramService) {
-				// Mock should not be called
+			setupMockService: func(mock *mocks.ProgramServiceMock) {
+				// Service should not be called
 			},
 			expectedStatus:     http.StatusBadRequest,
 			expectedErrCode:    "BAD_REQUEST",
@@ -116,7 +76,7 @@ func TestProgramHandler_SoftDeleteProgram(t *testing.T) {
 			programID: uuid.New().String(),
 			userID:    uuid.New(),
 			userRole:  models.RoleAdmin,
-			setupMockService: func(mock *MockProgramService) {
+			setupMockService: func(mock *mocks.ProgramServiceMock) {
 				mock.SoftDeleteFunc = func(ctx context.Context, id uuid.UUID, userID uuid.UUID, userRole models.UserRole) error {
 					return appErrors.NewNotFoundError("Program")
 				}
@@ -130,13 +90,13 @@ func TestProgramHandler_SoftDeleteProgram(t *testing.T) {
 			programID: uuid.New().String(),
 			userID:    uuid.New(),
 			userRole:  models.RoleStudent,
-			setupMockService: func(mock *MockProgramService) {
+			setupMockService: func(mock *mocks.ProgramServiceMock) {
 				mock.SoftDeleteFunc = func(ctx context.Context, id uuid.UUID, userID uuid.UUID, userRole models.UserRole) error {
 					return appErrors.NewAuthorizationError("You don't have permission to delete this program")
 				}
 			},
 			expectedStatus:     http.StatusForbidden,
-			expectedErrCode:    "FORBIDDEN",
+			expectedErrCode:    "AUTHORIZATION_ERROR",
 			expectedErrMessage: "You don't have permission to delete this program",
 		},
 		{
@@ -144,7 +104,7 @@ func TestProgramHandler_SoftDeleteProgram(t *testing.T) {
 			programID: uuid.New().String(),
 			userID:    uuid.New(),
 			userRole:  models.RoleAdmin,
-			setupMockService: func(mock *MockProgramService) {
+			setupMockService: func(mock *mocks.ProgramServiceMock) {
 				mock.SoftDeleteFunc = func(ctx context.Context, id uuid.UUID, userID uuid.UUID, userRole models.UserRole) error {
 					return appErrors.NewInternalError("Failed to delete program").WithError(errors.New("program already deleted"))
 				}
@@ -157,49 +117,32 @@ func TestProgramHandler_SoftDeleteProgram(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Setup mock service
-			mockService := &MockProgramService{}
+			mockService := &mocks.ProgramServiceMock{}
 			tt.setupMockService(mockService)
+			handler := newTestProgramHandler(mockService)
 
-			// Create handler - NOTE: This will fail in RED phase because ProgramHandler
-			// expects *services.ProgramService, not our mock interface
-			// In GREEN phase, we'll need to refactor ProgramHandler to use an interface
-			// For now, these tests document the expected behavior
-			//
-			// handler := NewProgramHandler(mockService)
-
-			// Setup router and context
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
-
-			// Setup request
-			req, _ := http.NewRequest(http.MethodDelete, "/api/v1/programs/"+tt.programID, nil)
-			c.Request = req
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/programs/"+tt.programID, nil)
 			c.Params = gin.Params{gin.Param{Key: "id", Value: tt.programID}}
 
 			// Set user context (simulating auth middleware)
 			c.Set("user_id", tt.userID.String())
 			c.Set("user_role", string(tt.userRole))
 
-			// Call handler - This is the method signature we expect to implement
-			// handler.DeleteProgram(c)  // This will be modified to call SoftDelete in GREEN phase
-
-			// For now, we'll test the expected behavior without actually calling the handler
-			// The tests will fail (RED phase) until we implement SoftDelete
+			handler.DeleteProgram(c)
 
-			// Assertions would go here:
-			// if w.Code != tt.expectedStatus {
-			//     t.Errorf("Expected status %d but got %d", tt.expectedStatus, w.Code)
-			// }
-
-			// For now, explicitly mark that this test needs implementation
 			if tt.expectedStatus == http.StatusOK {
-				// Success cases
-				t.Skip("RED phase: Handler implementation not yet created")
-			} else {
-				// Error cases
-				t.Skip("RED phase: Handler implementation not yet created")
+				if w.Code != http.StatusOK {
+					t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+				}
+				calls := mockService.SoftDeleteCalls()
+				if len(calls) != 1 || calls[0].ID.String() != tt.programID || calls[0].UserID != tt.userID || calls[0].UserRole != tt.userRole {
+					t.Errorf("SoftDelete calls = %+v, want one for the program by the requesting user", calls)
+				}
+				return
 			}
+			assertErrorResponse(t, w, tt.expectedStatus, tt.expectedErrCode, tt.expectedErrMessage)
 		})
 	}
 }
@@ -208,60 +151,49 @@ func TestProgramHandler_SoftDeleteProgram_Integration(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	t.Run("deleted_program_not_returned_by_get_endpoint", func(t *testing.T) {
-		// This test verifies end-to-end that after soft deleting a program,
-		// GET /programs/:id returns 404
-
+		// After soft deleting a program, GET /programs/:id returns 404
 		programID := uuid.New()
 		adminID := uuid.New()
 
-		_ = &MockProgramService{
+		deleted := false
+		mockService := &mocks.ProgramServiceMock{
 			SoftDeleteFunc: func(ctx context.Context, id uuid.UUID, userID uuid.UUID, userRole models.UserRole) error {
+				deleted = true
 				return nil
 			},
 			GetByIDFunc: func(ctx context.Context, id uuid.UUID, includeExercises bool) (*models.ProgramWithExercises, error) {
-				// After soft delete, GetByID should return not found
-				return nil, appErrors.NewNotFoundError("Program")
+				if deleted {
+					return nil, appErrors.NewNotFoundError("Program")
+				}
+				return &models.ProgramWithExercises{Program: models.Program{ID: id}}, nil
 			},
 		}
-
-		// handler := NewProgramHandler(mockService)
+		handler := newTestProgramHandler(mockService)
 
 		// 1. Soft delete the program
 		w1 := httptest.NewRecorder()
 		c1, _ := gin.CreateTestContext(w1)
-		req1, _ := http.NewRequest(http.MethodDelete, "/api/v1/programs/"+programID.String(), nil)
-		c1.Request = req1
+		c1.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/programs/"+programID.String(), nil)
 		c1.Params = gin.Params{gin.Param{Key: "id", Value: programID.String()}}
 		c1.Set("user_id", adminID.String())
 		c1.Set("user_role", string(models.RoleAdmin))
 
-		// handler.DeleteProgram(c1)
+		handler.DeleteProgram(c1)
 
 		// 2. Try to get the program
 		w2 := httptest.NewRecorder()
 		c2, _ := gin.CreateTestContext(w2)
-		req2, _ := http.NewRequest(http.MethodGet, "/api/v1/programs/"+programID.String(), nil)
-		c2.Request = req2
+		c2.Request = httptest.NewRequest(http.MethodGet, "/api/v1/programs/"+programID.String(), nil)
 		c2.Params = gin.Params{gin.Param{Key: "id", Value: programID.String()}}
 		c2.Set("user_id", adminID.String())
 		c2.Set("user_role", string(models.RoleAdmin))
 
-		// handler.GetProgram(c2)
-
-		// Assertions
-		// if w1.Code != http.StatusOK {
-		//     t.Errorf("Expected delete to succeed with status 200, got %d", w1.Code)
-		// }
-		// if w2.Code != http.StatusNotFound {
-		//     t.Errorf("Expected get to return 404 after soft delete, got %d", w2.Code)
-		// }
-
-		t.Skip("RED phase: Handler integration test not yet implemented")
-	})
+		handler.GetProgram(c2)
 
-	t.Run("soft_deleted_program_excluded_from_list", func(t *testing.T) {
-		// This test verifies that soft-deleted programs don't appear in list endpoints
-		t.Skip("RED phase: List endpoint filtering not yet implemented")
+		if w1.Code != http.StatusOK {
+			t.Errorf("Expected delete to succeed with status 200, got %d", w1.Code)
+		}
+		assertErrorResponse(t, w2, http.StatusNotFound, "NOT_FOUND", "Program not found")
 	})
 }
 
@@ -305,7 +237,7 @@ func TestProgramHandler_SoftDelete_RoleBasedAuthorization(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_ = &MockProgramService{
+			mockService := &mocks.ProgramServiceMock{
 				SoftDeleteFunc: func(ctx context.Context, id uuid.UUID, userID uuid.UUID, userRole models.UserRole) error {
 					// Simulate service-level authorization
 					if userRole == models.RoleAdmin {
@@ -317,23 +249,111 @@ func TestProgramHandler_SoftDelete_RoleBasedAuthorization(t *testing.T) {
 					return appErrors.NewAuthorizationError("You don't have permission to delete this program")
 				},
 			}
+			handler := newTestProgramHandler(mockService)
 
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
-			req, _ := http.NewRequest(http.MethodDelete, "/api/v1/programs/"+programID.String(), nil)
-			c.Request = req
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/programs/"+programID.String(), nil)
 			c.Params = gin.Params{gin.Param{Key: "id", Value: programID.String()}}
 			c.Set("user_id", tt.requestUserID.String())
 			c.Set("user_role", string(tt.requestUserRole))
 
-			// handler := NewProgramHandler(mockService)
-			// handler.DeleteProgram(c)
+			handler.DeleteProgram(c)
+
+			if w.Code != tt.expectStatus {
+				t.Errorf("Expected status %d but got %d", tt.expectStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestParseAssignmentCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectRows  []int
+		expectIDs   []string
+		expectError bool
+	}{
+		{
+			name:       "header row is skipped",
+			input:      "email,name\nalice@example.com,Alice\nbob@example.com,Bob\n",
+			expectRows: []int{2, 3},
+			expectIDs:  []string{"alice@example.com", "bob@example.com"},
+		},
+		{
+			name:       "no header and blank lines",
+			input:      "alice@example.com\n\n  bob@example.com\n",
+			expectRows: []int{1, 3},
+			expectIDs:  []string{"alice@example.com", "bob@example.com"},
+		},
+		{
+			name:        "empty file",
+			input:       "email\n",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := parseAssignmentCSV(strings.NewReader(tt.input))
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(targets) != len(tt.expectIDs) {
+				t.Fatalf("Expected %d targets but got %d", len(tt.expectIDs), len(targets))
+			}
+			for i, target := range targets {
+				if target.Row != tt.expectRows[i] || target.Identifier != tt.expectIDs[i] {
+					t.Errorf("Target %d: expected row %d %q, got row %d %q", i, tt.expectRows[i], tt.expectIDs[i], target.Row, target.Identifier)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckPublishSchedule(t *testing.T) {
+	at := func(s string) *timestamp.Time {
+		ts, err := timestamp.Parse(s)
+		if err != nil {
+			t.Fatalf("Invalid test timestamp %q: %v", s, err)
+		}
+		return &ts
+	}
 
-			// if w.Code != tt.expectStatus {
-			//     t.Errorf("Expected status %d but got %d", tt.expectStatus, w.Code)
-			// }
+	tests := []struct {
+		name        string
+		publishAt   *timestamp.Time
+		unpublishAt *timestamp.Time
+		expectError bool
+	}{
+		{name: "no schedule"},
+		{name: "publish only", publishAt: at("2026-12-01T08:00:00Z")},
+		{name: "publish and unpublish", publishAt: at("2026-12-01T08:00:00Z"), unpublishAt: at("2027-03-01T08:00:00+01:00")},
+		{name: "unpublish before publish", publishAt: at("2026-12-01T08:00:00Z"), unpublishAt: at("2026-11-01T08:00:00Z"), expectError: true},
+	}
 
-			t.Skip("RED phase: Role-based authorization test not yet implemented")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publish, unpublish, err := checkPublishSchedule(tt.publishAt, tt.unpublishAt)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (publish != nil) != (tt.publishAt != nil) || (unpublish != nil) != (tt.unpublishAt != nil) {
+				t.Errorf("Parsed schedule does not match input")
+			}
 		})
 	}
 }
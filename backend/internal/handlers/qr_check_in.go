@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type QRCheckInHandler struct {
+	qrService *services.QRCheckInService
+	validate  *validator.Validate
+}
+
+func NewQRCheckInHandler(qrService *services.QRCheckInService) *QRCheckInHandler {
+	return &QRCheckInHandler{
+		qrService: qrService,
+		validate:  validators.New(),
+	}
+}
+
+// GetClassQR godoc
+// @Summary Get a QR check-in code for a live class (admin only)
+// @Description Signed token valid for QR_TOKEN_TTL_SECONDS; fetch a new one before it expires. Encode url (or token) into the QR image.
+// @Tags classes
+// @Produce json
+// @Param id path string true "Class ID"
+// @Success 200 {object} models.QRCode
+// @Router /api/v1/classes/{id}/qr [get]
+// @Security BearerAuth
+func (h *QRCheckInHandler) GetClassQR(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid class ID"))
+		return
+	}
+
+	code, err := h.qrService.ClassCode(c.Request.Context(), id)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, code)
+}
+
+// GetProgramQR godoc
+// @Summary Get a QR code for starting a practice session of a program (admin only)
+// @Description Signed token valid for QR_TOKEN_TTL_SECONDS; fetch a new one before it expires. Encode url (or token) into the QR image.
+// @Tags programs
+// @Produce json
+// @Param id path string true "Program ID"
+// @Success 200 {object} models.QRCode
+// @Router /api/v1/programs/{id}/qr [get]
+// @Security BearerAuth
+func (h *QRCheckInHandler) GetProgramQR(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	code, err := h.qrService.ProgramCode(c.Request.Context(), id)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, code)
+}
+
+// ScanQR godoc
+// @Summary Check in with a scanned QR code
+// @Description A class code records attendance (present, or late after the grace period); a program code starts a practice session. Each code is accepted once per student.
+// @Tags check-in
+// @Accept json
+// @Produce json
+// @Param X-Device-Info header string false "Client device description"
+// @Param request body validators.QRCheckInRequest true "Scanned token"
+// @Success 201 {object} models.QRCheckInResult
+// @Failure 409 {object} map[string]interface{} "Code already scanned or attendance already recorded"
+// @Router /api/v1/check-in/qr [post]
+// @Security BearerAuth
+func (h *QRCheckInHandler) ScanQR(c *gin.Context) {
+	var req validators.QRCheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	clientIP, userAgent, device := middleware.ClientInfo(c)
+	result, err := h.qrService.Scan(c.Request.Context(), userID, req.Token, clientIP, userAgent, device)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type QuestionnaireHandler struct {
+	questionnaireService *services.QuestionnaireService
+	validate             *validator.Validate
+}
+
+func NewQuestionnaireHandler(questionnaireService *services.QuestionnaireService) *QuestionnaireHandler {
+	return &QuestionnaireHandler{
+		questionnaireService: questionnaireService,
+		validate:             validators.New(),
+	}
+}
+
+// GetQuestionnaire godoc
+// @Summary Get the post-session questionnaire of a program
+// @Description The questions to ask when a session of the program is completed, in place of the notes field
+// @Tags questionnaires
+// @Produce json
+// @Param id path string true "Program ID"
+// @Success 200 {object} models.SessionQuestionnaire
+// @Failure 404 {object} map[string]interface{} "Program or questionnaire not found"
+// @Router /api/v1/programs/{id}/questionnaire [get]
+// @Security BearerAuth
+func (h *QuestionnaireHandler) GetQuestionnaire(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	questionnaire, err := h.questionnaireService.Get(c.Request.Context(), programID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, questionnaire)
+}
+
+// SetQuestionnaire godoc
+// @Summary Create or replace the post-session questionnaire of a program (admin only)
+// @Description Answers refer to questions by id, so keep the id when rewording a question. Scales default to 1 to 5.
+// @Tags questionnaires
+// @Accept json
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param request body validators.SetQuestionnaireRequest true "Questions"
+// @Success 200 {object} models.SessionQuestionnaire
+// @Failure 400 {object} map[string]interface{} "Duplicate question or invalid scale"
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/questionnaire [put]
+// @Security BearerAuth
+func (h *QuestionnaireHandler) SetQuestionnaire(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	var req validators.SetQuestionnaireRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	questionnaire := &models.SessionQuestionnaire{
+		ProgramID: programID,
+		Questions: make([]models.QuestionnaireQuestion, len(req.Questions)),
+		UpdatedBy: &userID,
+	}
+	for i, q := range req.Questions {
+		questionnaire.Questions[i] = models.QuestionnaireQuestion{
+			ID:       q.ID,
+			Type:     q.Type,
+			Prompt:   q.Prompt,
+			Min:      q.Min,
+			Max:      q.Max,
+			MinLabel: q.MinLabel,
+			MaxLabel: q.MaxLabel,
+		}
+	}
+	if err := h.questionnaireService.Set(c.Request.Context(), questionnaire); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, questionnaire)
+}
+
+// DeleteQuestionnaire godoc
+// @Summary Delete the post-session questionnaire of a program (admin only)
+// @Description Answers already given stay with their sessions
+// @Tags questionnaires
+// @Param id path string true "Program ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/questionnaire [delete]
+// @Security BearerAuth
+func (h *QuestionnaireHandler) DeleteQuestionnaire(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	if err := h.questionnaireService.Delete(c.Request.Context(), programID); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Questionnaire deleted",
+	})
+}
+
+// GetQuestionnaireResults godoc
+// @Summary Aggregated answers to the questionnaire of a program (admin only)
+// @Description Per question in questionnaire order: the average and distribution of scale answers, the 20 newest text answers
+// @Tags questionnaires
+// @Produce json
+// @Param id path string true "Program ID"
+// @Success 200 {object} models.QuestionnaireResults
+// @Failure 404 {object} map[string]interface{} "Program or questionnaire not found"
+// @Router /api/v1/programs/{id}/questionnaire/results [get]
+// @Security BearerAuth
+func (h *QuestionnaireHandler) GetQuestionnaireResults(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	results, err := h.questionnaireService.Results(c.Request.Context(), programID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// AnswerQuestionnaire godoc
+// @Summary Answer the questionnaire of a completed session
+// @Description For answers not sent with the completion. Answering a question again replaces the earlier answer. Returns all answers about the session.
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body validators.AnswerQuestionnaireRequest true "Answers"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Session not completed or answers don't fit the questionnaire"
+// @Router /api/v1/sessions/{id}/answers [put]
+// @Security BearerAuth
+func (h *QuestionnaireHandler) AnswerQuestionnaire(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid session ID"))
+		return
+	}
+
+	var req validators.AnswerQuestionnaireRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	answers, err := h.questionnaireService.Answer(c.Request.Context(), sessionID, userID, sessionAnswers(req.Answers))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"answers": answers,
+	})
+}
+
+func sessionAnswers(reqs []validators.SessionAnswerRequest) []models.SessionAnswer {
+	answers := make([]models.SessionAnswer, len(reqs))
+	for i, req := range reqs {
+		answers[i] = models.SessionAnswer{
+			QuestionID: req.QuestionID,
+			Scale:      req.Scale,
+			Text:       req.Text,
+		}
+	}
+	return answers
+}
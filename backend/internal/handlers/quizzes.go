@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type QuizHandler struct {
+	quizService *services.QuizService
+	validate    *validator.Validate
+}
+
+func NewQuizHandler(quizService *services.QuizService) *QuizHandler {
+	return &QuizHandler{
+		quizService: quizService,
+		validate:    validators.New(),
+	}
+}
+
+// ListQuizzes godoc
+// @Summary List the quizzes attached to a program
+// @Tags quizzes
+// @Produce json
+// @Param id path string true "Program ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/quizzes [get]
+// @Security BearerAuth
+func (h *QuizHandler) ListQuizzes(c *gin.Context) {
+	programID, _, ok := h.parseIDs(c, "Invalid program ID")
+	if !ok {
+		return
+	}
+
+	quizzes, err := h.quizService.ListByProgram(c.Request.Context(), programID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quizzes": quizzes,
+	})
+}
+
+// CreateQuiz godoc
+// @Summary Attach a quiz to a program (admin only)
+// @Description Passing all quizzes of a program is required to complete it in a course
+// @Tags quizzes
+// @Accept json
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param request body validators.CreateQuizRequest true "Quiz"
+// @Success 201 {object} models.Quiz
+// @Router /api/v1/programs/{id}/quizzes [post]
+// @Security BearerAuth
+func (h *QuizHandler) CreateQuiz(c *gin.Context) {
+	programID, userID, ok := h.parseIDs(c, "Invalid program ID")
+	if !ok {
+		return
+	}
+
+	var req validators.CreateQuizRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	quiz, err := h.quizService.Create(c.Request.Context(), programID, userID, req.Title, req.Description, req.PassThreshold, req.MaxAttempts, toQuizQuestions(req.Questions))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, quiz)
+}
+
+// GetQuiz godoc
+// @Summary Get a quiz with its questions
+// @Description Correct answers and explanations are only included for admins
+// @Tags quizzes
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} models.Quiz
+// @Router /api/v1/quizzes/{id} [get]
+// @Security BearerAuth
+func (h *QuizHandler) GetQuiz(c *gin.Context) {
+	id, _, ok := h.parseIDs(c, "Invalid quiz ID")
+	if !ok {
+		return
+	}
+
+	quiz, err := h.quizService.Get(c.Request.Context(), id, middleware.IsAdmin(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quiz)
+}
+
+// UpdateQuiz godoc
+// @Summary Update a quiz (admin only)
+// @Description Passing questions replaces all of them; earlier attempts keep their scores
+// @Tags quizzes
+// @Accept json
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Param request body validators.UpdateQuizRequest true "Fields to change"
+// @Success 200 {object} models.Quiz
+// @Router /api/v1/quizzes/{id} [put]
+// @Security BearerAuth
+func (h *QuizHandler) UpdateQuiz(c *gin.Context) {
+	id, _, ok := h.parseIDs(c, "Invalid quiz ID")
+	if !ok {
+		return
+	}
+
+	var req validators.UpdateQuizRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	var questions []models.QuizQuestion
+	if req.Questions != nil {
+		questions = toQuizQuestions(*req.Questions)
+	}
+
+	quiz, err := h.quizService.Update(c.Request.Context(), id, req.Title, req.Description, req.PassThreshold, req.MaxAttempts, questions)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quiz)
+}
+
+// DeleteQuiz godoc
+// @Summary Delete a quiz and its attempts (admin only)
+// @Tags quizzes
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/quizzes/{id} [delete]
+// @Security BearerAuth
+func (h *QuizHandler) DeleteQuiz(c *gin.Context) {
+	id, _, ok := h.parseIDs(c, "Invalid quiz ID")
+	if !ok {
+		return
+	}
+
+	if err := h.quizService.Delete(c.Request.Context(), id); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Quiz deleted successfully",
+	})
+}
+
+// SubmitAttempt godoc
+// @Summary Submit answers to a quiz
+// @Description Returns the score and per-question results. Correct choices are revealed once the attempt passes.
+// @Tags quizzes
+// @Accept json
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Param request body validators.SubmitQuizRequest true "Answers"
+// @Success 201 {object} models.QuizAttempt
+// @Failure 409 {object} map[string]interface{} "No attempts left"
+// @Router /api/v1/quizzes/{id}/attempts [post]
+// @Security BearerAuth
+func (h *QuizHandler) SubmitAttempt(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c, "Invalid quiz ID")
+	if !ok {
+		return
+	}
+
+	var req validators.SubmitQuizRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	// IDs were checked by the validator
+	answers := make(map[uuid.UUID][]uuid.UUID, len(req.Answers))
+	for _, answer := range req.Answers {
+		choiceIDs := make([]uuid.UUID, len(answer.ChoiceIDs))
+		for i, choiceID := range answer.ChoiceIDs {
+			choiceIDs[i] = uuid.MustParse(choiceID)
+		}
+		answers[uuid.MustParse(answer.QuestionID)] = choiceIDs
+	}
+
+	attempt, err := h.quizService.Submit(c.Request.Context(), id, userID, middleware.IsAdmin(c), answers)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attempt)
+}
+
+// ListAttempts godoc
+// @Summary List your attempts at a quiz, newest first
+// @Tags quizzes
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Param user_id query string false "Student to look at (admin only)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/quizzes/{id}/attempts [get]
+// @Security BearerAuth
+func (h *QuizHandler) ListAttempts(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c, "Invalid quiz ID")
+	if !ok {
+		return
+	}
+
+	var query validators.ListQuizAttemptsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+	if query.UserID != nil {
+		if !middleware.IsAdmin(c) {
+			respondWithError(c, appErrors.NewAuthorizationError("Only admins can view other students' attempts"))
+			return
+		}
+		userID = uuid.MustParse(*query.UserID) // Checked by the validator
+	}
+
+	attempts, err := h.quizService.ListAttempts(c.Request.Context(), id, userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attempts": attempts,
+	})
+}
+
+// parseIDs reads the ID from the path and the current user, responding on failure
+func (h *QuizHandler) parseIDs(c *gin.Context, invalidIDMessage string) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError(invalidIDMessage))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, userID, true
+}
+
+// toQuizQuestions converts validated question requests to models
+func toQuizQuestions(reqs []validators.QuizQuestionRequest) []models.QuizQuestion {
+	questions := make([]models.QuizQuestion, len(reqs))
+	for i, req := range reqs {
+		questions[i] = models.QuizQuestion{
+			Prompt:      req.Prompt,
+			Explanation: req.Explanation,
+			Choices:     make([]models.QuizChoice, len(req.Choices)),
+		}
+		for j, choice := range req.Choices {
+			isCorrect := choice.IsCorrect
+			questions[i].Choices[j] = models.QuizChoice{
+				Text:      choice.Text,
+				IsCorrect: &isCorrect,
+			}
+		}
+	}
+	return questions
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+var quotaPlanName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+type QuotaHandler struct {
+	quotaService *services.QuotaService
+	validate     *validator.Validate
+}
+
+func NewQuotaHandler(quotaService *services.QuotaService) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService: quotaService,
+		validate:     validators.New(),
+	}
+}
+
+// GetMyQuota godoc
+// @Summary Get the current user's quota
+// @Description Limits on owned programs, submissions waiting for feedback and cover image storage, with current usage. Admins are exempt.
+// @Tags quotas
+// @Produce json
+// @Success 200 {object} models.UserQuota
+// @Router /api/v1/auth/me/quota [get]
+// @Security BearerAuth
+func (h *QuotaHandler) GetMyQuota(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	quota, err := h.quotaService.GetUserQuota(c.Request.Context(), userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quota)
+}
+
+// GetUserQuota godoc
+// @Summary Get a user's quota (admin only)
+// @Tags quotas
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.UserQuota
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/users/{id}/quota [get]
+// @Security BearerAuth
+func (h *QuotaHandler) GetUserQuota(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid user ID"))
+		return
+	}
+
+	quota, err := h.quotaService.GetUserQuota(c.Request.Context(), userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quota)
+}
+
+// SetUserQuota godoc
+// @Summary Set a user's quota plan and overrides (admin only)
+// @Description Replaces the plan and all overrides. Overrides left out fall back to the plan's limits.
+// @Tags quotas
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body validators.SetUserQuotaRequest true "Plan and overrides"
+// @Success 200 {object} models.UserQuota
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/users/{id}/quota [put]
+// @Security BearerAuth
+func (h *QuotaHandler) SetUserQuota(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid user ID"))
+		return
+	}
+
+	var req validators.SetUserQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	quota, err := h.quotaService.SetUserQuota(c.Request.Context(), userID, req.Plan, quotaLimits(req.QuotaLimitsRequest))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quota)
+}
+
+// ListQuotaPlans godoc
+// @Summary List quota plans (admin only)
+// @Tags quotas
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/quota-plans [get]
+// @Security BearerAuth
+func (h *QuotaHandler) ListQuotaPlans(c *gin.Context) {
+	plans, err := h.quotaService.ListPlans(c.Request.Context())
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"plans": plans,
+	})
+}
+
+// SaveQuotaPlan godoc
+// @Summary Create a quota plan or replace its limits (admin only)
+// @Description Limits left out are unlimited. Users without a plan are on the plan named default.
+// @Tags quotas
+// @Accept json
+// @Produce json
+// @Param name path string true "Plan name: lowercase letters, digits, - and _"
+// @Param request body validators.QuotaLimitsRequest true "Limits"
+// @Success 200 {object} models.QuotaPlan
+// @Router /api/v1/admin/quota-plans/{name} [put]
+// @Security BearerAuth
+func (h *QuotaHandler) SaveQuotaPlan(c *gin.Context) {
+	name := c.Param("name")
+	if !quotaPlanName.MatchString(name) {
+		respondWithError(c, appErrors.NewBadRequestError("Plan name must be up to 50 lowercase letters, digits, - and _"))
+		return
+	}
+
+	var req validators.QuotaLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	plan, err := h.quotaService.SavePlan(c.Request.Context(), name, quotaLimits(req))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// DeleteQuotaPlan godoc
+// @Summary Delete a quota plan (admin only)
+// @Description Users on the plan move to the default plan and keep their overrides. The default plan can't be deleted.
+// @Tags quotas
+// @Param name path string true "Plan name"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/quota-plans/{name} [delete]
+// @Security BearerAuth
+func (h *QuotaHandler) DeleteQuotaPlan(c *gin.Context) {
+	if err := h.quotaService.DeletePlan(c.Request.Context(), c.Param("name")); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func quotaLimits(req validators.QuotaLimitsRequest) models.QuotaLimits {
+	return models.QuotaLimits{
+		MaxPrograms:        req.MaxPrograms,
+		MaxOpenSubmissions: req.MaxOpenSubmissions,
+		MaxStorageBytes:    req.MaxStorageBytes,
+	}
+}
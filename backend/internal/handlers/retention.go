@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type RetentionHandler struct {
+	retentionService *services.RetentionService
+	validate         *validator.Validate
+}
+
+func NewRetentionHandler(retentionService *services.RetentionService) *RetentionHandler {
+	return &RetentionHandler{
+		retentionService: retentionService,
+		validate:         validators.New(),
+	}
+}
+
+// ListRetentionRules godoc
+// @Summary List the data retention rules (admin only)
+// @Description sessions deletes practice sessions after RETENTION_SESSION_YEARS, soft_deleted purges deleted submissions, discussions and programs after RETENTION_SOFT_DELETED_DAYS, device_info strips device details after RETENTION_DEVICE_INFO_DAYS. cutoff is unset for rules that are turned off.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/retention/rules [get]
+// @Security BearerAuth
+func (h *RetentionHandler) ListRetentionRules(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"rules": h.retentionService.Rules(),
+	})
+}
+
+// DryRunRetentionRule godoc
+// @Summary Report what a retention rule would change now (admin only)
+// @Description Counts the rows the rule would delete or strip per table, without changing anything. The report is kept with the runs of the daily job.
+// @Tags admin
+// @Produce json
+// @Param rule path string true "Rule name"
+// @Success 200 {object} models.RetentionRun
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/retention/rules/{rule}/dry-run [post]
+// @Security BearerAuth
+func (h *RetentionHandler) DryRunRetentionRule(c *gin.Context) {
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	run, err := h.retentionService.DryRun(c.Request.Context(), c.Param("rule"), adminID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// ListRetentionRuns godoc
+// @Summary List data retention reports (admin only)
+// @Description Runs of the daily job and dry runs requested by admins, newest first, with the rows affected per table
+// @Tags admin
+// @Produce json
+// @Param rule query string false "Only runs of this rule"
+// @Param limit query int false "Limit (default 20)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/retention/runs [get]
+// @Security BearerAuth
+func (h *RetentionHandler) ListRetentionRuns(c *gin.Context) {
+	var query validators.ListRetentionRunsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	runs, err := h.retentionService.ListRuns(c.Request.Context(), query.Rule, query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"runs":   runs,
+		"limit":  query.Limit,
+		"offset": query.Offset,
+	})
+}
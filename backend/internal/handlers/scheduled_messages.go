@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type ScheduledMessageHandler struct {
+	scheduledMessageService *services.ScheduledMessageService
+	validate                *validator.Validate
+}
+
+func NewScheduledMessageHandler(scheduledMessageService *services.ScheduledMessageService) *ScheduledMessageHandler {
+	return &ScheduledMessageHandler{
+		scheduledMessageService: scheduledMessageService,
+		validate:                validators.New(),
+	}
+}
+
+// ListScheduledMessages godoc
+// @Summary List the current instructor's scheduled messages (admin only)
+// @Tags scheduled-messages
+// @Produce json
+// @Param status query string false "pending (default), sent, cancelled or failed"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/scheduled-messages [get]
+// @Security BearerAuth
+func (h *ScheduledMessageHandler) ListScheduledMessages(c *gin.Context) {
+	var query validators.ListScheduledMessagesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+	if query.Status == "" {
+		query.Status = string(models.ScheduledMessagePending)
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	messages, err := h.scheduledMessageService.List(c.Request.Context(), userID, models.ScheduledMessageStatus(query.Status))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"scheduled_messages": messages,
+	})
+}
+
+// ScheduleMessage godoc
+// @Summary Schedule a submission message or program announcement (admin only)
+// @Description Set submission_id to post into a thread, or program_id and title to notify every student assigned to the program
+// @Tags scheduled-messages
+// @Accept json
+// @Produce json
+// @Param request body validators.ScheduleMessageRequest true "Scheduled message"
+// @Success 201 {object} models.ScheduledMessage
+// @Router /api/v1/scheduled-messages [post]
+// @Security BearerAuth
+func (h *ScheduledMessageHandler) ScheduleMessage(c *gin.Context) {
+	var req validators.ScheduleMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, bindError(err, "Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	message := &models.ScheduledMessage{
+		AuthorID:   userID,
+		Title:      req.Title,
+		Content:    req.Content,
+		YouTubeURL: req.YouTubeURL,
+		SendAt:     req.SendAt.Time,
+	}
+	// IDs were checked by the validator
+	if req.SubmissionID != nil {
+		id := uuid.MustParse(*req.SubmissionID)
+		message.SubmissionID = &id
+	}
+	if req.ProgramID != nil {
+		id := uuid.MustParse(*req.ProgramID)
+		message.ProgramID = &id
+	}
+
+	if err := h.scheduledMessageService.Schedule(c.Request.Context(), message); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+// CancelScheduledMessage godoc
+// @Summary Cancel a pending scheduled message (admin only)
+// @Tags scheduled-messages
+// @Param id path string true "Scheduled message ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Already sent or cancelled"
+// @Router /api/v1/scheduled-messages/{id} [delete]
+// @Security BearerAuth
+func (h *ScheduledMessageHandler) CancelScheduledMessage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid scheduled message ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	if err := h.scheduledMessageService.Cancel(c.Request.Context(), id, userID); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scheduled message cancelled successfully",
+	})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -14,15 +15,45 @@ import (
 	appErrors "github.com/xuangong/backend/pkg/errors"
 )
 
+//go:generate go tool moq -skip-ensure -rm -out ../../pkg/testutil/mocks/session_service.go -pkg mocks . SessionService
+
+// SessionService is the practice session tracking used by SessionHandler, implemented by services.SessionService
+type SessionService interface {
+	StartSession(ctx context.Context, userID uuid.UUID, role models.UserRole, programID uuid.UUID, deviceInfo map[string]interface{}) (*models.PracticeSession, error)
+	GetSession(ctx context.Context, sessionID, userID uuid.UUID, role models.UserRole) (*models.SessionWithLogs, error)
+	ListSessions(ctx context.Context, userID uuid.UUID, programID *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error)
+	GetUserSessions(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, programID *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error)
+	ListAllSessions(ctx context.Context, filter models.SessionFilter, limit, offset int) ([]models.PracticeSession, error)
+	LogExercise(ctx context.Context, sessionID, userID, exerciseID uuid.UUID, log *models.ExerciseLog) error
+	UpdateExerciseLog(ctx context.Context, logID, adminID uuid.UUID, update *models.ExerciseLogUpdate) (*models.ExerciseLog, error)
+	CompleteSession(ctx context.Context, sessionID, userID uuid.UUID, totalDuration int, completionRate float64, notes string, completedAt *time.Time, wellbeing *models.SessionWellbeing, answers []models.SessionAnswer) error
+	UpdateSession(ctx context.Context, sessionID, userID uuid.UUID, update *models.SessionUpdate) (*models.PracticeSession, error)
+	AdminUpdateSession(ctx context.Context, sessionID, adminID uuid.UUID, update *models.SessionUpdate) (*models.PracticeSession, error)
+	ListEdits(ctx context.Context, sessionID, userID uuid.UUID, role models.UserRole) ([]models.SessionEdit, error)
+	DeleteSession(ctx context.Context, sessionID, userID uuid.UUID) (*time.Time, error)
+	RestoreSession(ctx context.Context, sessionID, userID uuid.UUID, role models.UserRole) (*models.PracticeSession, error)
+	AddBiometrics(ctx context.Context, sessionID, userID uuid.UUID, startTime time.Time, interval time.Duration, heartRates []*int, hrvs []*float64) (*models.PracticeSession, int, error)
+	GetBiometrics(ctx context.Context, sessionID, userID uuid.UUID, role models.UserRole) ([]models.BiometricSample, error)
+	AddNote(ctx context.Context, sessionID, authorID uuid.UUID, authorRole models.UserRole, content string, visibility models.NoteVisibility) (*models.SessionNote, error)
+	ListNotes(ctx context.Context, sessionID, userID uuid.UUID, role models.UserRole) ([]models.SessionNote, error)
+	GetStats(ctx context.Context, userID uuid.UUID) (*models.SessionStats, error)
+	GetProgramProgress(ctx context.Context, userID, programID uuid.UUID) (*models.RepetitionProgress, error)
+	GetProgramAdoption(ctx context.Context, userID uuid.UUID, userRole models.UserRole, programID uuid.UUID) (*models.ProgramAdoption, error)
+	ReconcileRepetitions(ctx context.Context) (*models.RepetitionReconciliation, error)
+	ListReconciliations(ctx context.Context, limit, offset int) ([]models.RepetitionReconciliation, error)
+}
+
+var _ SessionService = (*services.SessionService)(nil)
+
 type SessionHandler struct {
-	sessionService *services.SessionService
+	sessionService SessionService
 	validate       *validator.Validate
 }
 
-func NewSessionHandler(sessionService *services.SessionService) *SessionHandler {
+func NewSessionHandler(sessionService SessionService) *SessionHandler {
 	return &SessionHandler{
 		sessionService: sessionService,
-		validate:       validator.New(),
+		validate:       validators.New(),
 	}
 }
 
@@ -62,25 +93,10 @@ func (h *SessionHandler) ListSessions(c *gin.Context) {
 		programID = &id
 	}
 
-	var startDate, endDate *time.Time
-	if query.StartDate != nil {
-		t, err := time.Parse("2006-01-02", *query.StartDate)
-		if err != nil {
-			respondWithError(c, appErrors.NewBadRequestError("Invalid start date format"))
-			return
-		}
-		// Start of day (00:00:00)
-		startDate = &t
-	}
-	if query.EndDate != nil {
-		t, err := time.Parse("2006-01-02", *query.EndDate)
-		if err != nil {
-			respondWithError(c, appErrors.NewBadRequestError("Invalid end date format"))
-			return
-		}
-		// End of day (23:59:59.999999999)
-		endOfDay := time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 999999999, t.Location())
-		endDate = &endOfDay
+	startDate, endDate, err := sessionDateRange(query)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
 	}
 
 	sessions, err := h.sessionService.ListSessions(
@@ -143,11 +159,14 @@ func (h *SessionHandler) GetSession(c *gin.Context) {
 
 // StartSession godoc
 // @Summary Start a new practice session
+// @Description The program must be owned by, assigned to or public for the user; admins can practice any program.
 // @Tags sessions
 // @Accept json
 // @Produce json
 // @Param request body validators.StartSessionRequest true "Session details"
 // @Success 201 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
 // @Router /api/v1/sessions/start [post]
 // @Security BearerAuth
 func (h *SessionHandler) StartSession(c *gin.Context) {
@@ -162,9 +181,8 @@ func (h *SessionHandler) StartSession(c *gin.Context) {
 		return
 	}
 
-	userID, err := middleware.GetUserID(c)
-	if err != nil {
-		respondWithAppError(c, err)
+	userID, role, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
@@ -177,6 +195,7 @@ func (h *SessionHandler) StartSession(c *gin.Context) {
 	session, err := h.sessionService.StartSession(
 		c.Request.Context(),
 		userID,
+		role,
 		programID,
 		req.DeviceInfo,
 	)
@@ -234,6 +253,12 @@ func (h *SessionHandler) LogExercise(c *gin.Context) {
 		notes = &req.Notes
 	}
 
+	var substituteID *uuid.UUID
+	if req.SubstituteID != "" {
+		id := uuid.MustParse(req.SubstituteID) // Checked by the validator
+		substituteID = &id
+	}
+
 	log := &models.ExerciseLog{
 		PlannedDurationSeconds: req.PlannedDurationSeconds,
 		ActualDurationSeconds:  req.ActualDurationSeconds,
@@ -241,6 +266,7 @@ func (h *SessionHandler) LogExercise(c *gin.Context) {
 		RepetitionsCompleted:   req.RepetitionsCompleted,
 		Skipped:                req.Skipped,
 		Notes:                  notes,
+		SubstituteID:           substituteID,
 	}
 
 	if err := h.sessionService.LogExercise(c.Request.Context(), sessionID, userID, exerciseID, log); err != nil {
@@ -255,6 +281,7 @@ func (h *SessionHandler) LogExercise(c *gin.Context) {
 
 // CompleteSession godoc
 // @Summary Complete a practice session
+// @Description answers takes the program's questionnaire, if it has one; notes is still accepted from older apps
 // @Tags sessions
 // @Accept json
 // @Produce json
@@ -272,7 +299,7 @@ func (h *SessionHandler) CompleteSession(c *gin.Context) {
 
 	var req validators.CompleteSessionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		respondWithError(c, bindError(err, "Invalid request body"))
 		return
 	}
 
@@ -287,33 +314,6 @@ func (h *SessionHandler) CompleteSession(c *gin.Context) {
 		return
 	}
 
-	// Parse the optional completed_at timestamp
-	var completedAt *time.Time
-	if req.CompletedAt != nil && *req.CompletedAt != "" {
-		// Try multiple formats
-		formats := []string{
-			time.RFC3339,
-			"2006-01-02T15:04:05.999999999",
-			"2006-01-02T15:04:05",
-			time.RFC3339Nano,
-		}
-
-		var parsedTime time.Time
-		var parseErr error
-		for _, format := range formats {
-			parsedTime, parseErr = time.Parse(format, *req.CompletedAt)
-			if parseErr == nil {
-				break
-			}
-		}
-
-		if parseErr != nil {
-			respondWithError(c, appErrors.NewBadRequestError("Invalid completed_at format. Expected ISO8601/RFC3339 format"))
-			return
-		}
-		completedAt = &parsedTime
-	}
-
 	// Get values or use defaults
 	totalDuration := 0
 	if req.TotalDurationSeconds != nil {
@@ -331,7 +331,14 @@ func (h *SessionHandler) CompleteSession(c *gin.Context) {
 		totalDuration,
 		completionRate,
 		req.Notes,
-		completedAt,
+		req.CompletedAt.Ptr(),
+		&models.SessionWellbeing{
+			Mood:      req.Mood,
+			Energy:    req.Energy,
+			PainFlags: req.PainFlags,
+			Tags:      req.Tags,
+		},
+		sessionAnswers(req.Answers),
 	); err != nil {
 		respondWithAppError(c, err)
 		return
@@ -342,6 +349,266 @@ func (h *SessionHandler) CompleteSession(c *gin.Context) {
 	})
 }
 
+// UpdateSession godoc
+// @Summary Correct a recorded practice session
+// @Description Edits notes, total duration, completion rate or completion time of the user's own session. Every change is recorded in the session's edit history. Admins correct other users' sessions through PUT /admin/sessions/{id}.
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body validators.UpdateSessionRequest true "Fields to correct"
+// @Success 200 {object} models.PracticeSession
+// @Router /api/v1/sessions/{id} [put]
+// @Security BearerAuth
+func (h *SessionHandler) UpdateSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid session ID"))
+		return
+	}
+
+	var req validators.UpdateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, bindError(err, "Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	session, err := h.sessionService.UpdateSession(c.Request.Context(), sessionID, userID, &models.SessionUpdate{
+		Notes:                req.Notes,
+		TotalDurationSeconds: req.TotalDurationSeconds,
+		CompletionRate:       req.CompletionRate,
+		CompletedAt:          req.CompletedAt.Ptr(),
+	})
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// ListAllSessions godoc
+// @Summary List the practice sessions of all users (admin only)
+// @Description Activity feed across all students, newest first, with user and program names. A session is abandoned when it was never completed and started more than a day ago.
+// @Tags admin
+// @Produce json
+// @Param user_id query string false "Filter by user ID"
+// @Param program_id query string false "Filter by program ID"
+// @Param start_date query string false "Filter by start date (YYYY-MM-DD)"
+// @Param end_date query string false "Filter by end date (YYYY-MM-DD)"
+// @Param status query string false "completed, in_progress or abandoned"
+// @Param limit query int false "Limit (default 20)"
+// @Param offset query int false "Offset (default 0)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/sessions [get]
+// @Security BearerAuth
+func (h *SessionHandler) ListAllSessions(c *gin.Context) {
+	var query validators.ListAdminSessionsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	var filter models.SessionFilter
+	if query.UserID != nil {
+		id, err := uuid.Parse(*query.UserID)
+		if err != nil {
+			respondWithError(c, appErrors.NewBadRequestError("Invalid user ID"))
+			return
+		}
+		filter.UserID = &id
+	}
+	if query.ProgramID != nil {
+		id, err := uuid.Parse(*query.ProgramID)
+		if err != nil {
+			respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+			return
+		}
+		filter.ProgramID = &id
+	}
+	if query.Status != "" {
+		status := models.SessionStatus(query.Status)
+		filter.Status = &status
+	}
+
+	var err error
+	filter.StartDate, filter.EndDate, err = sessionDateRange(query.ListSessionsQuery)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	sessions, err := h.sessionService.ListAllSessions(c.Request.Context(), filter, query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": sessions,
+		"limit":    query.Limit,
+		"offset":   query.Offset,
+	})
+}
+
+// AdminUpdateSession godoc
+// @Summary Correct a student's practice session (admin only)
+// @Description Fixes obviously wrong data such as a 10-hour duration. The change, the values before it, the admin and the reason are recorded in the session's edit history, and the session is flagged with edited_at and edited_by.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body validators.AdminUpdateSessionRequest true "Fields to correct and the reason"
+// @Success 200 {object} models.PracticeSession
+// @Router /api/v1/admin/sessions/{id} [put]
+// @Security BearerAuth
+func (h *SessionHandler) AdminUpdateSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid session ID"))
+		return
+	}
+
+	var req validators.AdminUpdateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, bindError(err, "Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	session, err := h.sessionService.AdminUpdateSession(c.Request.Context(), sessionID, adminID, &models.SessionUpdate{
+		Notes:                req.Notes,
+		TotalDurationSeconds: req.TotalDurationSeconds,
+		CompletionRate:       req.CompletionRate,
+		CompletedAt:          req.CompletedAt.Ptr(),
+		Reason:               &req.Reason,
+	})
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// AdminUpdateExerciseLog godoc
+// @Summary Correct a logged exercise (admin only)
+// @Description Edits the actual duration, completed repetitions, skipped flag or notes. The change is recorded in the edit history of the log's session, and the log is flagged with edited_at and edited_by.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Exercise log ID"
+// @Param request body validators.UpdateExerciseLogRequest true "Fields to correct and the reason"
+// @Success 200 {object} models.ExerciseLog
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/exercise-logs/{id} [put]
+// @Security BearerAuth
+func (h *SessionHandler) AdminUpdateExerciseLog(c *gin.Context) {
+	logID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid exercise log ID"))
+		return
+	}
+
+	var req validators.UpdateExerciseLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, bindError(err, "Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	log, err := h.sessionService.UpdateExerciseLog(c.Request.Context(), logID, adminID, &models.ExerciseLogUpdate{
+		ActualDurationSeconds: req.ActualDurationSeconds,
+		RepetitionsCompleted:  req.RepetitionsCompleted,
+		Skipped:               req.Skipped,
+		Notes:                 req.Notes,
+		Reason:                &req.Reason,
+	})
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, log)
+}
+
+// ListSessionEdits godoc
+// @Summary Edit history of a session
+// @Description Every correction of the session and its exercise logs, oldest first, with the editor, the values before and after and the reason given by admins
+// @Tags sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/sessions/{id}/edits [get]
+// @Security BearerAuth
+func (h *SessionHandler) ListSessionEdits(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid session ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	roleStr, err := middleware.GetUserRole(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	edits, err := h.sessionService.ListEdits(c.Request.Context(), sessionID, userID, models.UserRole(roleStr))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"edits": edits,
+	})
+}
+
 // GetStats godoc
 // @Summary Get practice statistics
 // @Tags sessions
@@ -365,8 +632,137 @@ func (h *SessionHandler) GetStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetMyProgramProgress godoc
+// @Summary Get progress through one of my programs
+// @Description Completed sessions against the program's planned repetitions, the completion date projected from the pace of the last four weeks, and how often each exercise was done, skipped or substituted
+// @Tags sessions
+// @Produce json
+// @Param id path string true "Program ID"
+// @Success 200 {object} models.RepetitionProgress
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/my-programs/{id}/progress [get]
+// @Security BearerAuth
+func (h *SessionHandler) GetMyProgramProgress(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	progress, err := h.sessionService.GetProgramProgress(c.Request.Context(), userID, programID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// GetProgramAdoption godoc
+// @Summary Get how students use a program (owner or admin)
+// @Description For template authors: active assignments, users practicing it, sessions started and completed, the average completion rate, and per exercise how many sessions reached or skipped it and the drop-off from the exercise before
+// @Tags programs
+// @Produce json
+// @Param id path string true "Program ID"
+// @Success 200 {object} models.ProgramAdoption
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/adoption [get]
+// @Security BearerAuth
+func (h *SessionHandler) GetProgramAdoption(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	userRole, err := middleware.GetUserRole(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	adoption, err := h.sessionService.GetProgramAdoption(c.Request.Context(), userID, models.UserRole(userRole), programID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, adoption)
+}
+
+// ListRepetitionReconciliations godoc
+// @Summary List repetition reconciliation reports (admin only)
+// @Description Nightly checks of each program's repetitions_completed against its completed sessions, with the programs whose count had drifted and was corrected
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Limit (default 20)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/repetition-reconciliations [get]
+// @Security BearerAuth
+func (h *SessionHandler) ListRepetitionReconciliations(c *gin.Context) {
+	var query validators.ListReconciliationsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	reports, err := h.sessionService.ListReconciliations(c.Request.Context(), query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reconciliations": reports,
+		"limit":           query.Limit,
+		"offset":          query.Offset,
+	})
+}
+
+// ReconcileRepetitions godoc
+// @Summary Reconcile program repetitions now (admin only)
+// @Description Runs the nightly reconciliation immediately and returns its report
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.RepetitionReconciliation
+// @Router /api/v1/admin/repetition-reconciliations [post]
+// @Security BearerAuth
+func (h *SessionHandler) ReconcileRepetitions(c *gin.Context) {
+	report, err := h.sessionService.ReconcileRepetitions(c.Request.Context())
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // DeleteSession godoc
 // @Summary Delete a practice session
+// @Description Soft-deletes the session. It can be restored via POST /sessions/{id}/restore until restore_until.
 // @Tags sessions
 // @Produce json
 // @Param id path string true "Session ID"
@@ -386,28 +782,67 @@ func (h *SessionHandler) DeleteSession(c *gin.Context) {
 		return
 	}
 
-	if err := h.sessionService.DeleteSession(c.Request.Context(), sessionID, userID); err != nil {
+	restoreUntil, err := h.sessionService.DeleteSession(c.Request.Context(), sessionID, userID)
+	if err != nil {
 		respondWithAppError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Session deleted successfully",
+		"message":       "Session deleted successfully",
+		"restore_until": restoreUntil,
 	})
 }
 
+// RestoreSession godoc
+// @Summary Restore a deleted practice session
+// @Description Students can undo their own deletions within the restore window; admins until the session is purged
+// @Tags sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} models.PracticeSession
+// @Router /api/v1/sessions/{id}/restore [post]
+// @Security BearerAuth
+func (h *SessionHandler) RestoreSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid session ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	roleStr, err := middleware.GetUserRole(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	session, err := h.sessionService.RestoreSession(c.Request.Context(), sessionID, userID, models.UserRole(roleStr))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
 // GetUserSessions godoc
 // @Summary Get sessions for a specific user (admin only, or own sessions)
 // @Tags sessions
 // @Produce json
-// @Param user_id path string true "User ID"
+// @Param id path string true "User ID"
 // @Param program_id query string false "Filter by program ID"
 // @Param start_date query string false "Filter by start date (YYYY-MM-DD)"
 // @Param end_date query string false "Filter by end date (YYYY-MM-DD)"
 // @Param limit query int false "Limit (default 20)"
 // @Param offset query int false "Offset (default 0)"
 // @Success 200 {object} map[string]interface{}
-// @Router /api/v1/users/{user_id}/sessions [get]
+// @Router /api/v1/users/{id}/sessions [get]
 // @Security BearerAuth
 func (h *SessionHandler) GetUserSessions(c *gin.Context) {
 	// Parse target user ID from URL path
@@ -454,24 +889,10 @@ func (h *SessionHandler) GetUserSessions(c *gin.Context) {
 		programID = &id
 	}
 
-	var startDate, endDate *time.Time
-	if query.StartDate != nil {
-		t, err := time.Parse("2006-01-02", *query.StartDate)
-		if err != nil {
-			respondWithError(c, appErrors.NewBadRequestError("Invalid start date format"))
-			return
-		}
-		startDate = &t
-	}
-	if query.EndDate != nil {
-		t, err := time.Parse("2006-01-02", *query.EndDate)
-		if err != nil {
-			respondWithError(c, appErrors.NewBadRequestError("Invalid end date format"))
-			return
-		}
-		// End of day (23:59:59.999999999)
-		endOfDay := time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 999999999, t.Location())
-		endDate = &endOfDay
+	startDate, endDate, err := sessionDateRange(query)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
 	}
 
 	// Call service with authorization
@@ -497,3 +918,210 @@ func (h *SessionHandler) GetUserSessions(c *gin.Context) {
 		"offset":   query.Offset,
 	})
 }
+
+// AddNote godoc
+// @Summary Add an instructor note to a session (admin only)
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body validators.CreateSessionNoteRequest true "Note details"
+// @Success 201 {object} models.SessionNote
+// @Router /api/v1/sessions/{id}/notes [post]
+// @Security BearerAuth
+func (h *SessionHandler) AddNote(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid session ID"))
+		return
+	}
+
+	var req validators.CreateSessionNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	roleStr, err := middleware.GetUserRole(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	note, err := h.sessionService.AddNote(
+		c.Request.Context(),
+		sessionID,
+		userID,
+		models.UserRole(roleStr),
+		req.Content,
+		models.NoteVisibility(req.Visibility),
+	)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+// ListNotes godoc
+// @Summary List notes on a session
+// @Tags sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/sessions/{id}/notes [get]
+// @Security BearerAuth
+func (h *SessionHandler) ListNotes(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid session ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	roleStr, err := middleware.GetUserRole(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	notes, err := h.sessionService.ListNotes(c.Request.Context(), sessionID, userID, models.UserRole(roleStr))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notes": notes,
+	})
+}
+
+// AddBiometrics godoc
+// @Summary Upload wearable heart-rate/HRV samples for a session
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param request body validators.AddBiometricsRequest true "Compact sample series"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/sessions/{id}/biometrics [post]
+// @Security BearerAuth
+func (h *SessionHandler) AddBiometrics(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid session ID"))
+		return
+	}
+
+	var req validators.AddBiometricsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, bindError(err, "Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	session, stored, err := h.sessionService.AddBiometrics(
+		c.Request.Context(),
+		sessionID,
+		userID,
+		req.StartTime.Time,
+		time.Duration(req.IntervalMs)*time.Millisecond,
+		req.HeartRate,
+		req.HRVMs,
+	)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"samples_stored": stored,
+		"session":        session,
+	})
+}
+
+// GetBiometrics godoc
+// @Summary Get raw wearable samples for a session
+// @Tags sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/sessions/{id}/biometrics [get]
+// @Security BearerAuth
+func (h *SessionHandler) GetBiometrics(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid session ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	roleStr, err := middleware.GetUserRole(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	samples, err := h.sessionService.GetBiometrics(c.Request.Context(), sessionID, userID, models.UserRole(roleStr))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"samples": samples,
+	})
+}
+
+// sessionDateRange parses the start_date and end_date filters as days. The end date covers the
+// whole day, so sessions started on its evening are included.
+func sessionDateRange(query validators.ListSessionsQuery) (startDate, endDate *time.Time, err error) {
+	if query.StartDate != nil {
+		t, err := time.Parse(time.DateOnly, *query.StartDate)
+		if err != nil {
+			return nil, nil, appErrors.NewBadRequestError("Invalid start date format")
+		}
+		startDate = &t
+	}
+	if query.EndDate != nil {
+		t, err := time.Parse(time.DateOnly, *query.EndDate)
+		if err != nil {
+			return nil, nil, appErrors.NewBadRequestError("Invalid end date format")
+		}
+		endOfDay := t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		endDate = &endOfDay
+	}
+	return startDate, endDate, nil
+}
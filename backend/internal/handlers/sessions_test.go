@@ -2,7 +2,7 @@ package handlers
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,50 +11,11 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/validators"
 	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/testutil/mocks"
 )
 
-// MockSessionService for testing
-type MockSessionService struct {
-	GetUserSessionsFunc func(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, programID *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error)
-}
-
-func (m *MockSessionService) GetUserSessions(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, programID *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error) {
-	if m.GetUserSessionsFunc != nil {
-		return m.GetUserSessionsFunc(ctx, requestingUserID, requestingRole, targetUserID, programID, startDate, endDate, limit, offset)
-	}
-	return nil, nil
-}
-
-// Stub methods for SessionService interface
-func (m *MockSessionService) StartSession(ctx context.Context, userID, programID uuid.UUID, deviceInfo map[string]interface{}) (*models.PracticeSession, error) {
-	return nil, nil
-}
-
-func (m *MockSessionService) GetSession(ctx context.Context, sessionID, userID uuid.UUID) (*models.SessionWithLogs, error) {
-	return nil, nil
-}
-
-func (m *MockSessionService) ListSessions(ctx context.Context, userID uuid.UUID, programID *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error) {
-	return nil, nil
-}
-
-func (m *MockSessionService) LogExercise(ctx context.Context, sessionID, userID, exerciseID uuid.UUID, log *models.ExerciseLog) error {
-	return nil
-}
-
-func (m *MockSessionService) CompleteSession(ctx context.Context, sessionID, userID uuid.UUID, totalDuration int, completionRate float64, notes string, completedAt *time.Time) error {
-	return nil
-}
-
-func (m *MockSessionService) GetStats(ctx context.Context, userID uuid.UUID) (*models.SessionStats, error) {
-	return nil, nil
-}
-
-func (m *MockSessionService) DeleteSession(ctx context.Context, sessionID, userID uuid.UUID) error {
-	return nil
-}
-
 func TestSessionHandler_GetUserSessions(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -62,13 +23,25 @@ func TestSessionHandler_GetUserSessions(t *testing.T) {
 	studentID := uuid.New()
 	programID := uuid.New()
 
+	// listSessions answers like the session service: admins see anyone's sessions, students only
+	// their own
+	listSessions := func(ctx context.Context, reqID uuid.UUID, role models.UserRole, targetID uuid.UUID, pid *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error) {
+		if role != models.RoleAdmin && reqID != targetID {
+			return nil, appErrors.NewAuthorizationError("You don't have permission to view these sessions")
+		}
+		return []models.SessionWithLogs{
+			{Session: models.PracticeSession{ID: uuid.New(), UserID: targetID}},
+		}, nil
+	}
+
 	tests := []struct {
 		name               string
 		userID             string // In URL path
 		requestingUserID   uuid.UUID
 		requestingRole     models.UserRole
 		queryParams        string
-		setupMockService   func(*MockSessionService)
+		callsService       bool
+		checkCall          func(t *testing.T, pid *uuid.UUID, startDate, endDate *time.Time, limit, offset int)
 		expectedStatus     int
 		expectedErrCode    string
 		expectedErrMessage string
@@ -78,209 +51,336 @@ func TestSessionHandler_GetUserSessions(t *testing.T) {
 			userID:           studentID.String(),
 			requestingUserID: adminID,
 			requestingRole:   models.RoleAdmin,
-			queryParams:      "",
-			setupMockService: func(mock *MockSessionService) {
-				mock.GetUserSessionsFunc = func(ctx context.Context, reqID uuid.UUID, role models.UserRole, targetID uuid.UUID, programID *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error) {
-					return []models.SessionWithLogs{
-						{Session: models.PracticeSession{ID: uuid.New(), UserID: studentID}},
-					}, nil
+			callsService:     true,
+			checkCall: func(t *testing.T, pid *uuid.UUID, startDate, endDate *time.Time, limit, offset int) {
+				if pid != nil || startDate != nil || endDate != nil {
+					t.Errorf("filters = %v, %v, %v, want none", pid, startDate, endDate)
 				}
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:             "student_gets_own_sessions",
+			userID:           studentID.String(),
+			requestingUserID: studentID,
+			requestingRole:   models.RoleStudent,
+			callsService:     true,
+			expectedStatus:   http.StatusOK,
+		},
+		{
+			name:               "student_cannot_view_other_sessions",
+			userID:             studentID.String(),
+			requestingUserID:   uuid.New(), // Different student
+			requestingRole:     models.RoleStudent,
+			callsService:       true,
+			expectedStatus:     http.StatusForbidden,
+			expectedErrCode:    "AUTHORIZATION_ERROR",
+			expectedErrMessage: "You don't have permission to view these sessions",
+		},
 		{
 			name:             "admin_filters_by_program",
 			userID:           studentID.String(),
 			requestingUserID: adminID,
 			requestingRole:   models.RoleAdmin,
 			queryParams:      "?program_id=" + programID.String(),
-			setupMockService: func(mock *MockSessionService) {
-				mock.GetUserSessionsFunc = func(ctx context.Context, reqID uuid.UUID, role models.UserRole, targetID uuid.UUID, pid *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error) {
-					// Verify program_id was parsed correctly
-					if pid == nil || *pid != programID {
-						return nil, errors.New("program_id not passed correctly")
-					}
-					return []models.SessionWithLogs{}, nil
+			callsService:     true,
+			checkCall: func(t *testing.T, pid *uuid.UUID, startDate, endDate *time.Time, limit, offset int) {
+				if pid == nil || *pid != programID {
+					t.Errorf("program ID = %v, want %s", pid, programID)
 				}
 			},
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name:             "admin_uses_date_filters",
+			name:             "end_date_includes_the_whole_day",
 			userID:           studentID.String(),
 			requestingUserID: adminID,
 			requestingRole:   models.RoleAdmin,
 			queryParams:      "?start_date=2024-01-01&end_date=2024-01-31",
-			setupMockService: func(mock *MockSessionService) {
-				mock.GetUserSessionsFunc = func(ctx context.Context, reqID uuid.UUID, role models.UserRole, targetID uuid.UUID, pid *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error) {
-					// Verify dates were parsed
-					if startDate == nil || endDate == nil {
-						return nil, errors.New("dates not parsed")
-					}
-					return []models.SessionWithLogs{}, nil
+			callsService:     true,
+			checkCall: func(t *testing.T, pid *uuid.UUID, startDate, endDate *time.Time, limit, offset int) {
+				if startDate == nil || endDate == nil {
+					t.Fatalf("dates = %v, %v, want both", startDate, endDate)
+				}
+				if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !startDate.Equal(want) {
+					t.Errorf("start = %v, want %v", startDate, want)
+				}
+				// A session late on the last day is in the range, one on the next day is not
+				if lateSession := time.Date(2024, 1, 31, 23, 30, 0, 0, time.UTC); endDate.Before(lateSession) {
+					t.Errorf("end = %v, want it to cover %v", endDate, lateSession)
+				}
+				if nextDay := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC); !endDate.Before(nextDay) {
+					t.Errorf("end = %v, want it before %v", endDate, nextDay)
 				}
 			},
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name:             "student_cannot_view_other_sessions",
+			name:             "pagination_parameters",
 			userID:           studentID.String(),
-			requestingUserID: uuid.New(), // Different student
-			requestingRole:   models.RoleStudent,
-			queryParams:      "",
-			setupMockService: func(mock *MockSessionService) {
-				mock.GetUserSessionsFunc = func(ctx context.Context, reqID uuid.UUID, role models.UserRole, targetID uuid.UUID, pid *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error) {
-					return nil, appErrors.NewAuthorizationError("You don't have permission to view these sessions")
+			requestingUserID: adminID,
+			requestingRole:   models.RoleAdmin,
+			queryParams:      "?limit=50&offset=10",
+			callsService:     true,
+			checkCall: func(t *testing.T, pid *uuid.UUID, startDate, endDate *time.Time, limit, offset int) {
+				if limit != 50 || offset != 10 {
+					t.Errorf("limit, offset = %d, %d, want 50, 10", limit, offset)
 				}
 			},
-			expectedStatus:     http.StatusForbidden,
-			expectedErrCode:    "FORBIDDEN",
-			expectedErrMessage: "You don't have permission to view these sessions",
+			expectedStatus: http.StatusOK,
 		},
 		{
-			name:             "invalid_user_id_returns_400",
-			userID:           "invalid-uuid",
+			name:             "default_limit_applied",
+			userID:           studentID.String(),
 			requestingUserID: adminID,
 			requestingRole:   models.RoleAdmin,
-			queryParams:      "",
-			setupMockService: func(mock *MockSessionService) {
-				// Service should not be called
+			callsService:     true,
+			checkCall: func(t *testing.T, pid *uuid.UUID, startDate, endDate *time.Time, limit, offset int) {
+				if limit != 20 || offset != 0 {
+					t.Errorf("limit, offset = %d, %d, want the default 20, 0", limit, offset)
+				}
 			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:               "invalid_user_id_returns_400",
+			userID:             "invalid-uuid",
+			requestingUserID:   adminID,
+			requestingRole:     models.RoleAdmin,
 			expectedStatus:     http.StatusBadRequest,
 			expectedErrCode:    "BAD_REQUEST",
 			expectedErrMessage: "Invalid user ID",
 		},
 		{
-			name:             "invalid_program_id_returns_400",
-			userID:           studentID.String(),
-			requestingUserID: adminID,
-			requestingRole:   models.RoleAdmin,
-			queryParams:      "?program_id=invalid-uuid",
-			setupMockService: func(mock *MockSessionService) {
-				// Service should not be called
-			},
+			name:               "invalid_program_id_returns_400",
+			userID:             studentID.String(),
+			requestingUserID:   adminID,
+			requestingRole:     models.RoleAdmin,
+			queryParams:        "?program_id=invalid-uuid",
 			expectedStatus:     http.StatusBadRequest,
 			expectedErrCode:    "BAD_REQUEST",
 			expectedErrMessage: "Invalid program ID",
 		},
 		{
-			name:             "invalid_date_format_returns_400",
-			userID:           studentID.String(),
-			requestingUserID: adminID,
-			requestingRole:   models.RoleAdmin,
-			queryParams:      "?start_date=not-a-date",
-			setupMockService: func(mock *MockSessionService) {
-				// Service should not be called
-			},
+			name:               "invalid_date_format_returns_400",
+			userID:             studentID.String(),
+			requestingUserID:   adminID,
+			requestingRole:     models.RoleAdmin,
+			queryParams:        "?start_date=not-a-date",
 			expectedStatus:     http.StatusBadRequest,
 			expectedErrCode:    "BAD_REQUEST",
 			expectedErrMessage: "Invalid start date format",
 		},
-		{
-			name:             "pagination_parameters",
-			userID:           studentID.String(),
-			requestingUserID: adminID,
-			requestingRole:   models.RoleAdmin,
-			queryParams:      "?limit=50&offset=10",
-			setupMockService: func(mock *MockSessionService) {
-				mock.GetUserSessionsFunc = func(ctx context.Context, reqID uuid.UUID, role models.UserRole, targetID uuid.UUID, pid *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error) {
-					if limit != 50 || offset != 10 {
-						return nil, errors.New("pagination not passed correctly")
-					}
-					return []models.SessionWithLogs{}, nil
-				}
-			},
-			expectedStatus: http.StatusOK,
-		},
-		{
-			name:             "default_limit_applied",
-			userID:           studentID.String(),
-			requestingUserID: adminID,
-			requestingRole:   models.RoleAdmin,
-			queryParams:      "",
-			setupMockService: func(mock *MockSessionService) {
-				mock.GetUserSessionsFunc = func(ctx context.Context, reqID uuid.UUID, role models.UserRole, targetID uuid.UUID, pid *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error) {
-					if limit != 20 { // Default limit
-						return nil, errors.New("default limit not applied")
-					}
-					return []models.SessionWithLogs{}, nil
-				}
-			},
-			expectedStatus: http.StatusOK,
-		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Setup mock service
-			mockService := &MockSessionService{}
-			tt.setupMockService(mockService)
+			service := &mocks.SessionServiceMock{GetUserSessionsFunc: listSessions}
+			handler := NewSessionHandler(service)
 
-			// Create handler - NOTE: This will fail in RED phase because SessionHandler
-			// doesn't have a method to use MockSessionService
-			// In GREEN phase, we'll add GetUserSessions method to SessionHandler
-			// handler := NewSessionHandler(mockService)
-
-			// Setup router and context
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
-
-			// Setup request
-			req, _ := http.NewRequest(http.MethodGet, "/api/v1/users/"+tt.userID+"/sessions"+tt.queryParams, nil)
-			c.Request = req
-			c.Params = gin.Params{gin.Param{Key: "user_id", Value: tt.userID}}
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/"+tt.userID+"/sessions"+tt.queryParams, nil)
+			c.Params = gin.Params{gin.Param{Key: "id", Value: tt.userID}}
 
 			// Set user context (simulating auth middleware)
 			c.Set("user_id", tt.requestingUserID.String())
 			c.Set("user_role", string(tt.requestingRole))
 
-			// Call handler - This method doesn't exist yet (RED phase)
-			// handler.GetUserSessions(c)
+			handler.GetUserSessions(c)
 
-			// For now, explicitly mark that this test needs implementation
-			if tt.expectedStatus == http.StatusOK {
-				t.Skip("RED phase: Handler implementation not yet created")
+			calls := service.GetUserSessionsCalls()
+			if !tt.callsService {
+				if len(calls) != 0 {
+					t.Errorf("GetUserSessions called %d times, want the request rejected before", len(calls))
+				}
+			} else if len(calls) != 1 {
+				t.Fatalf("GetUserSessions called %d times, want 1", len(calls))
 			} else {
-				t.Skip("RED phase: Handler implementation not yet created")
+				call := calls[0]
+				if call.RequestingUserID != tt.requestingUserID || call.RequestingRole != tt.requestingRole || call.TargetUserID.String() != tt.userID {
+					t.Errorf("GetUserSessions(%s, %s, %s), want the requesting user and the user in the path",
+						call.RequestingUserID, call.RequestingRole, call.TargetUserID)
+				}
+				if tt.checkCall != nil {
+					tt.checkCall(t, call.ProgramID, call.StartDate, call.EndDate, call.Limit, call.Offset)
+				}
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				if w.Code != http.StatusOK {
+					t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+				}
+				return
 			}
+			assertErrorResponse(t, w, tt.expectedStatus, tt.expectedErrCode, tt.expectedErrMessage)
 		})
 	}
 }
 
-func TestSessionHandler_GetUserSessions_ResponseFormat(t *testing.T) {
+func TestSessionHandler_GetSession(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Run("response_includes_sessions_array", func(t *testing.T) {
-		// This test verifies the response structure includes:
-		// - sessions: array of SessionWithLogs
-		// - limit: pagination limit
-		// - offset: pagination offset
-		t.Skip("RED phase: Handler implementation not yet created")
-	})
-
-	t.Run("sessions_include_exercise_logs", func(t *testing.T) {
-		// Verify SessionWithLogs structure includes exercise logs
-		t.Skip("RED phase: Handler implementation not yet created")
-	})
+	tests := []struct {
+		name      string
+		sessionID string
+		role      string
+		wantCode  int
+	}{
+		{"invalid_session_id_returns_400", "not-a-uuid", string(models.RoleAdmin), http.StatusBadRequest},
+		{"missing_role_returns_401", uuid.New().String(), "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &SessionHandler{}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+tt.sessionID, nil)
+			c.Params = gin.Params{gin.Param{Key: "id", Value: tt.sessionID}}
+			c.Set("user_id", uuid.New().String())
+			if tt.role != "" {
+				c.Set("user_role", tt.role)
+			}
+
+			handler.GetSession(c)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSessionHandler_ListAllSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unknown_status_returns_400", "status=paused"},
+		{"invalid_user_id_returns_400", "user_id=not-a-uuid"},
+		{"invalid_program_id_returns_400", "program_id=not-a-uuid"},
+		{"invalid_date_returns_400", "start_date=01/15/2024"},
+		{"limit_over_100_returns_400", "limit=500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSessionHandler(nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/sessions?"+tt.query, nil)
+
+			handler.ListAllSessions(c)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400: %s", w.Code, w.Body.String())
+			}
+		})
+	}
 }
 
-func TestSessionHandler_GetUserSessions_EndToEnd(t *testing.T) {
+func TestSessionDateRange(t *testing.T) {
+	day := func(s string) *string { return &s }
+
+	start, end, err := sessionDateRange(validators.ListSessionsQuery{StartDate: day("2024-01-01"), EndDate: day("2024-01-31")})
+	if err != nil {
+		t.Fatalf("sessionDateRange() error = %v", err)
+	}
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("start = %v, want %v", start, want)
+	}
+	// A session completed late on the last day is in the range, one on the next day is not
+	if lateSession := time.Date(2024, 1, 31, 23, 30, 0, 0, time.UTC); end.Before(lateSession) {
+		t.Errorf("end = %v, want it to cover %v", end, lateSession)
+	}
+	if nextDay := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC); !end.Before(nextDay) {
+		t.Errorf("end = %v, want it before %v", end, nextDay)
+	}
+
+	start, end, err = sessionDateRange(validators.ListSessionsQuery{})
+	if err != nil || start != nil || end != nil {
+		t.Errorf("sessionDateRange() without dates = %v, %v, %v, want no range", start, end, err)
+	}
+
+	for _, query := range []validators.ListSessionsQuery{{StartDate: day("01/31/2024")}, {EndDate: day("2024-02-30")}} {
+		if _, _, err := sessionDateRange(query); err == nil {
+			t.Errorf("sessionDateRange(%+v) succeeded, want an error", query)
+		}
+	}
+}
+
+// assertErrorResponse checks the status and, when given, the code and message of an error response
+func assertErrorResponse(t *testing.T, w *httptest.ResponseRecorder, wantStatus int, wantCode, wantMessage string) {
+	t.Helper()
+
+	if w.Code != wantStatus {
+		t.Fatalf("status = %d, want %d: %s", w.Code, wantStatus, w.Body.String())
+	}
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	if wantCode != "" && body.Error.Code != wantCode {
+		t.Errorf("code = %q, want %q", body.Error.Code, wantCode)
+	}
+	if wantMessage != "" && body.Error.Message != wantMessage {
+		t.Errorf("message = %q, want %q", body.Error.Message, wantMessage)
+	}
+}
+
+func TestSessionHandler_GetUserSessions_ResponseFormat(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Run("admin_workflow", func(t *testing.T) {
-		// Test end-to-end flow:
-		// 1. Admin calls GET /users/:user_id/sessions?program_id=...
-		// 2. Service checks authorization (admin = OK)
-		// 3. Repository fetches filtered sessions
-		// 4. Response returned with correct structure
-		t.Skip("RED phase: Handler implementation not yet created")
-	})
-
-	t.Run("student_blocked_workflow", func(t *testing.T) {
-		// Test end-to-end flow:
-		// 1. Student calls GET /users/:other_user_id/sessions
-		// 2. Service checks authorization (student != target = FORBIDDEN)
-		// 3. 403 error returned
-		t.Skip("RED phase: Handler implementation not yet created")
-	})
+	studentID := uuid.New()
+	sessionID := uuid.New()
+	exerciseID := uuid.New()
+	service := &mocks.SessionServiceMock{
+		GetUserSessionsFunc: func(ctx context.Context, reqID uuid.UUID, role models.UserRole, targetID uuid.UUID, pid *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error) {
+			return []models.SessionWithLogs{{
+				Session:      models.PracticeSession{ID: sessionID, UserID: targetID},
+				ExerciseLogs: []models.ExerciseLog{{SessionID: sessionID, ExerciseID: &exerciseID}},
+			}}, nil
+		},
+	}
+	handler := NewSessionHandler(service)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/"+studentID.String()+"/sessions?limit=5&offset=5", nil)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: studentID.String()}}
+	c.Set("user_id", studentID.String())
+	c.Set("user_role", string(models.RoleStudent))
+
+	handler.GetUserSessions(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Sessions []models.SessionWithLogs `json:"sessions"`
+		Limit    int                      `json:"limit"`
+		Offset   int                      `json:"offset"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Limit != 5 || body.Offset != 5 {
+		t.Errorf("limit, offset = %d, %d, want 5, 5", body.Limit, body.Offset)
+	}
+	if len(body.Sessions) != 1 || body.Sessions[0].Session.ID != sessionID {
+		t.Fatalf("sessions = %+v, want the session %s", body.Sessions, sessionID)
+	}
+	if logs := body.Sessions[0].ExerciseLogs; len(logs) != 1 || logs[0].ExerciseID == nil || *logs[0].ExerciseID != exerciseID {
+		t.Errorf("exercise logs = %+v, want the log of exercise %s", logs, exerciseID)
+	}
 }
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type ShareLinkHandler struct {
+	shareService *services.ShareLinkService
+	validate     *validator.Validate
+}
+
+func NewShareLinkHandler(shareService *services.ShareLinkService) *ShareLinkHandler {
+	return &ShareLinkHandler{
+		shareService: shareService,
+		validate:     validators.New(),
+	}
+}
+
+// CreateShareLink godoc
+// @Summary Create a public read-only link to a program (owner or admin)
+// @Description The token and url are only returned in this response. Without expires_in_days the link expires after PROGRAM_SHARE_EXPIRY_DAYS.
+// @Tags programs
+// @Accept json
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param request body validators.CreateShareLinkRequest false "Link validity"
+// @Success 201 {object} models.ProgramShareLink
+// @Router /api/v1/programs/{id}/share-link [post]
+// @Security BearerAuth
+func (h *ShareLinkHandler) CreateShareLink(c *gin.Context) {
+	programID, userID, role, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req validators.CreateShareLinkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+			return
+		}
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	link, err := h.shareService.Create(c.Request.Context(), programID, userID, role, req.ExpiresInDays)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// ListShareLinks godoc
+// @Summary List a program's share links with their view counts (owner or admin)
+// @Tags programs
+// @Produce json
+// @Param id path string true "Program ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/share-links [get]
+// @Security BearerAuth
+func (h *ShareLinkHandler) ListShareLinks(c *gin.Context) {
+	programID, userID, role, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	links, err := h.shareService.List(c.Request.Context(), programID, userID, role)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"share_links": links})
+}
+
+// RevokeShareLink godoc
+// @Summary Revoke a program share link (owner or admin)
+// @Tags programs
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param linkId path string true "Share link ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/share-links/{linkId} [delete]
+// @Security BearerAuth
+func (h *ShareLinkHandler) RevokeShareLink(c *gin.Context) {
+	programID, userID, role, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	linkID, err := uuid.Parse(c.Param("linkId"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid share link ID"))
+		return
+	}
+
+	if err := h.shareService.Revoke(c.Request.Context(), programID, linkID, userID, role); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Share link revoked",
+	})
+}
+
+// GetSharedProgram godoc
+// @Summary View a shared program
+// @Description Public, read-only view of the program behind an active share link. No authentication required.
+// @Tags programs
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} models.SharedProgram
+// @Router /api/v1/shared/programs/{token} [get]
+func (h *ShareLinkHandler) GetSharedProgram(c *gin.Context) {
+	program, err := h.shareService.View(c.Request.Context(), c.Param("token"), middleware.GetLocale(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, program)
+}
+
+func (h *ShareLinkHandler) parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, models.UserRole, bool) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	role, err := middleware.GetUserRole(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	return programID, userID, models.UserRole(role), true
+}
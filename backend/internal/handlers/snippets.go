@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type SnippetHandler struct {
+	snippetService *services.SnippetService
+	validate       *validator.Validate
+}
+
+func NewSnippetHandler(snippetService *services.SnippetService) *SnippetHandler {
+	return &SnippetHandler{
+		snippetService: snippetService,
+		validate:       validators.New(),
+	}
+}
+
+// ListSnippets godoc
+// @Summary List the current instructor's feedback snippets (admin only)
+// @Description Also returns the placeholders snippet bodies may use, e.g. {{student_first_name}}
+// @Tags snippets
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/snippets [get]
+// @Security BearerAuth
+func (h *SnippetHandler) ListSnippets(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	snippets, err := h.snippetService.List(c.Request.Context(), userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"snippets":     snippets,
+		"placeholders": models.SnippetPlaceholders,
+	})
+}
+
+// GetSnippet godoc
+// @Summary Get a feedback snippet (admin only)
+// @Tags snippets
+// @Produce json
+// @Param id path string true "Snippet ID"
+// @Success 200 {object} models.FeedbackSnippet
+// @Router /api/v1/snippets/{id} [get]
+// @Security BearerAuth
+func (h *SnippetHandler) GetSnippet(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	snippet, err := h.snippetService.Get(c.Request.Context(), id, userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, snippet)
+}
+
+// CreateSnippet godoc
+// @Summary Create a feedback snippet (admin only)
+// @Description Insert it into a message with snippet_id on POST /submissions/{id}/messages
+// @Tags snippets
+// @Accept json
+// @Produce json
+// @Param request body validators.CreateSnippetRequest true "Snippet"
+// @Success 201 {object} models.FeedbackSnippet
+// @Router /api/v1/snippets [post]
+// @Security BearerAuth
+func (h *SnippetHandler) CreateSnippet(c *gin.Context) {
+	var req validators.CreateSnippetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	snippet, err := h.snippetService.Create(c.Request.Context(), userID, req.Title, req.Body)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, snippet)
+}
+
+// UpdateSnippet godoc
+// @Summary Update a feedback snippet (admin only)
+// @Tags snippets
+// @Accept json
+// @Produce json
+// @Param id path string true "Snippet ID"
+// @Param request body validators.UpdateSnippetRequest true "Fields to change"
+// @Success 200 {object} models.FeedbackSnippet
+// @Router /api/v1/snippets/{id} [put]
+// @Security BearerAuth
+func (h *SnippetHandler) UpdateSnippet(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req validators.UpdateSnippetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	snippet, err := h.snippetService.Update(c.Request.Context(), id, userID, req.Title, req.Body)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, snippet)
+}
+
+// DeleteSnippet godoc
+// @Summary Delete a feedback snippet (admin only)
+// @Tags snippets
+// @Param id path string true "Snippet ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/snippets/{id} [delete]
+// @Security BearerAuth
+func (h *SnippetHandler) DeleteSnippet(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.snippetService.Delete(c.Request.Context(), id, userID); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Snippet deleted successfully",
+	})
+}
+
+// parseIDs reads the snippet ID from the path and the current user, responding on failure
+func (h *SnippetHandler) parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid snippet ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, userID, true
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type StreakHandler struct {
+	streakService    *services.StreakService
+	recomputeService *services.StatsRecomputeService
+	validate         *validator.Validate
+}
+
+func NewStreakHandler(streakService *services.StreakService, recomputeService *services.StatsRecomputeService) *StreakHandler {
+	return &StreakHandler{
+		streakService:    streakService,
+		recomputeService: recomputeService,
+		validate:         validators.New(),
+	}
+}
+
+// GetMyStreakPolicy godoc
+// @Summary Get the current user's streak policy
+// @Description How many rest days a week a streak allows, the minimum practice minutes for a day to count, and the grace hours after midnight that still count towards the previous day
+// @Tags sessions
+// @Produce json
+// @Success 200 {object} models.UserStreakPolicy
+// @Router /api/v1/auth/me/streak-policy [get]
+// @Security BearerAuth
+func (h *StreakHandler) GetMyStreakPolicy(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	policy, err := h.streakService.GetPolicy(c.Request.Context(), userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// GetUserStreakPolicy godoc
+// @Summary Get a user's streak policy (admin only)
+// @Tags sessions
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.UserStreakPolicy
+// @Router /api/v1/users/{id}/streak-policy [get]
+// @Security BearerAuth
+func (h *StreakHandler) GetUserStreakPolicy(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid user ID"))
+		return
+	}
+
+	policy, err := h.streakService.GetPolicy(c.Request.Context(), userID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// SetUserStreakPolicy godoc
+// @Summary Set a user's streak rule overrides (admin only)
+// @Description Replaces all overrides. Rules left out fall back to the deployment's policy.
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body validators.SetStreakRulesRequest true "Overrides"
+// @Success 200 {object} models.UserStreakPolicy
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/users/{id}/streak-policy [put]
+// @Security BearerAuth
+func (h *StreakHandler) SetUserStreakPolicy(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid user ID"))
+		return
+	}
+
+	var req validators.SetStreakRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	policy, err := h.streakService.SetPolicy(c.Request.Context(), userID, models.StreakRules{
+		RestDaysPerWeek: req.RestDaysPerWeek,
+		MinMinutes:      req.MinMinutes,
+		GraceHours:      req.GraceHours,
+	})
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// StartStatsRecompute godoc
+// @Summary Recompute stored stats (admin only)
+// @Description Queues a background job recomputing streaks and program completion counts for one user, or everyone if user_id is left out. Run it after changing the deployment's streak rules.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body validators.StartStatsRecomputeRequest false "User to recompute"
+// @Success 202 {object} models.StatsRecompute
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/stats-recomputes [post]
+// @Security BearerAuth
+func (h *StreakHandler) StartStatsRecompute(c *gin.Context) {
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	var req validators.StartStatsRecomputeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithValidationError(c, err)
+			return
+		}
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	job, err := h.recomputeService.Start(c.Request.Context(), parseOptionalUUID(req.UserID), &adminID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// ListStatsRecomputes godoc
+// @Summary List stats recomputes (admin only)
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Limit (default 20)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/stats-recomputes [get]
+// @Security BearerAuth
+func (h *StreakHandler) ListStatsRecomputes(c *gin.Context) {
+	var query validators.ListStatsRecomputesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	jobs, err := h.recomputeService.List(c.Request.Context(), query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recomputes": jobs,
+		"limit":      query.Limit,
+		"offset":     query.Offset,
+	})
+}
+
+// GetStatsRecompute godoc
+// @Summary Get a stats recompute with its progress (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Recompute ID"
+// @Success 200 {object} models.StatsRecompute
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/stats-recomputes/{id} [get]
+// @Security BearerAuth
+func (h *StreakHandler) GetStatsRecompute(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid recompute ID"))
+		return
+	}
+
+	job, err := h.recomputeService.Get(c.Request.Context(), id)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
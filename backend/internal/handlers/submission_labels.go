@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type SubmissionLabelHandler struct {
+	labelService *services.SubmissionLabelService
+	validate     *validator.Validate
+}
+
+func NewSubmissionLabelHandler(labelService *services.SubmissionLabelService) *SubmissionLabelHandler {
+	return &SubmissionLabelHandler{
+		labelService: labelService,
+		validate:     validators.New(),
+	}
+}
+
+// ListLabels godoc
+// @Summary List submission labels (admin only)
+// @Description All labels by name, each with the number of submissions carrying it
+// @Tags submission-labels
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/submission-labels [get]
+// @Security BearerAuth
+func (h *SubmissionLabelHandler) ListLabels(c *gin.Context) {
+	labels, err := h.labelService.List(c.Request.Context())
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"labels": labels,
+	})
+}
+
+// CreateLabel godoc
+// @Summary Create a submission label (admin only)
+// @Description Names are unique regardless of case. The color defaults to grey.
+// @Tags submission-labels
+// @Accept json
+// @Produce json
+// @Param request body validators.CreateSubmissionLabelRequest true "Label"
+// @Success 201 {object} models.SubmissionLabel
+// @Failure 409 {object} map[string]interface{} "Name taken"
+// @Router /api/v1/submission-labels [post]
+// @Security BearerAuth
+func (h *SubmissionLabelHandler) CreateLabel(c *gin.Context) {
+	var req validators.CreateSubmissionLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	label, err := h.labelService.Create(c.Request.Context(), userID, req.Name, req.Color)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, label)
+}
+
+// UpdateLabel godoc
+// @Summary Rename or recolor a submission label (admin only)
+// @Tags submission-labels
+// @Accept json
+// @Produce json
+// @Param id path string true "Label ID"
+// @Param request body validators.UpdateSubmissionLabelRequest true "Fields to change"
+// @Success 200 {object} models.SubmissionLabel
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Name taken"
+// @Router /api/v1/submission-labels/{id} [put]
+// @Security BearerAuth
+func (h *SubmissionLabelHandler) UpdateLabel(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid label ID"))
+		return
+	}
+
+	var req validators.UpdateSubmissionLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	label, err := h.labelService.Update(c.Request.Context(), id, req.Name, req.Color)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, label)
+}
+
+// DeleteLabel godoc
+// @Summary Delete a submission label (admin only)
+// @Description Also removes it from every submission
+// @Tags submission-labels
+// @Param id path string true "Label ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/submission-labels/{id} [delete]
+// @Security BearerAuth
+func (h *SubmissionLabelHandler) DeleteLabel(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid label ID"))
+		return
+	}
+
+	if err := h.labelService.Delete(c.Request.Context(), id); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Label deleted successfully",
+	})
+}
+
+// SetSubmissionLabels godoc
+// @Summary Replace the labels of a submission (admin only)
+// @Description An empty list removes all labels
+// @Tags submission-labels
+// @Accept json
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Param request body validators.SetSubmissionLabelsRequest true "Label IDs"
+// @Success 200 {object} map[string][]models.SubmissionLabel
+// @Failure 400 {object} map[string]interface{} "Unknown label"
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/submissions/{id}/labels [put]
+// @Security BearerAuth
+func (h *SubmissionLabelHandler) SetSubmissionLabels(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid submission ID"))
+		return
+	}
+
+	var req validators.SetSubmissionLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	labelIDs := make([]uuid.UUID, 0, len(req.LabelIDs))
+	for _, id := range req.LabelIDs {
+		labelIDs = append(labelIDs, uuid.MustParse(id)) // Checked by the validator
+	}
+
+	labels, err := h.labelService.SetSubmissionLabels(c.Request.Context(), submissionID, userID, labelIDs)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"labels": labels,
+	})
+}
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/export"
 	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
 	"github.com/xuangong/backend/internal/services"
 	"github.com/xuangong/backend/internal/validators"
 	appErrors "github.com/xuangong/backend/pkg/errors"
@@ -14,17 +17,19 @@ import (
 
 type SubmissionHandler struct {
 	submissionService *services.SubmissionService
+	exportService     *services.ExportService
 	validate          *validator.Validate
 }
 
-func NewSubmissionHandler(submissionService *services.SubmissionService) *SubmissionHandler {
+func NewSubmissionHandler(submissionService *services.SubmissionService, exportService *services.ExportService) *SubmissionHandler {
 	return &SubmissionHandler{
 		submissionService: submissionService,
-		validate:          validator.New(),
+		exportService:     exportService,
+		validate:          validators.New(),
 	}
 }
 
-// CreateSubmission creates a new submission for a program
+// CreateSubmission creates a new submission for a program the student owns or is assigned
 // POST /api/v1/programs/:id/submissions
 func (h *SubmissionHandler) CreateSubmission(c *gin.Context) {
 	programID, err := uuid.Parse(c.Param("id"))
@@ -44,9 +49,8 @@ func (h *SubmissionHandler) CreateSubmission(c *gin.Context) {
 		return
 	}
 
-	userID, err := middleware.GetUserID(c)
-	if err != nil {
-		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+	userID, userRole, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
@@ -54,6 +58,7 @@ func (h *SubmissionHandler) CreateSubmission(c *gin.Context) {
 		c.Request.Context(),
 		programID,
 		userID,
+		userRole,
 		req.Title,
 	)
 	if err != nil {
@@ -66,8 +71,111 @@ func (h *SubmissionHandler) CreateSubmission(c *gin.Context) {
 	})
 }
 
+// GetSubmissionTemplate godoc
+// @Summary Get the submission template of a program
+// @Description The title format new submissions must follow and prompts such as "film from the side, 2 minutes"
+// @Tags submissions
+// @Produce json
+// @Param id path string true "Program ID"
+// @Success 200 {object} models.SubmissionTemplate
+// @Failure 404 {object} map[string]interface{} "Program or template not found"
+// @Router /api/v1/programs/{id}/submission-template [get]
+// @Security BearerAuth
+func (h *SubmissionHandler) GetSubmissionTemplate(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	template, err := h.submissionService.GetTemplate(c.Request.Context(), programID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// SetSubmissionTemplate godoc
+// @Summary Create or replace the submission template of a program (admin only)
+// @Description title_pattern is a Go regular expression the whole title must match; title_example must match it
+// @Tags submissions
+// @Accept json
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param request body validators.SetSubmissionTemplateRequest true "Template"
+// @Success 200 {object} models.SubmissionTemplate
+// @Failure 400 {object} map[string]interface{} "Invalid pattern"
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/submission-template [put]
+// @Security BearerAuth
+func (h *SubmissionHandler) SetSubmissionTemplate(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	var req validators.SetSubmissionTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	template := &models.SubmissionTemplate{
+		ProgramID:    programID,
+		TitlePattern: req.TitlePattern,
+		TitleExample: req.TitleExample,
+		Prompts:      req.Prompts,
+		UpdatedBy:    &userID,
+	}
+	if err := h.submissionService.SetTemplate(c.Request.Context(), template); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteSubmissionTemplate godoc
+// @Summary Delete the submission template of a program (admin only)
+// @Tags submissions
+// @Param id path string true "Program ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/submission-template [delete]
+// @Security BearerAuth
+func (h *SubmissionHandler) DeleteSubmissionTemplate(c *gin.Context) {
+	programID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+		return
+	}
+
+	if err := h.submissionService.DeleteTemplate(c.Request.Context(), programID); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Submission template deleted",
+	})
+}
+
 // ListSubmissions lists submissions with filters
-// GET /api/v1/submissions
+// GET /api/v1/submissions?archived=true&label_id=
 func (h *SubmissionHandler) ListSubmissions(c *gin.Context) {
 	var query validators.ListSubmissionsQuery
 	if err := c.ShouldBindQuery(&query); err != nil {
@@ -98,11 +206,24 @@ func (h *SubmissionHandler) ListSubmissions(c *gin.Context) {
 	}
 	isAdmin := middleware.IsAdmin(c)
 
+	// Labels are only visible to instructors
+	var labelID *uuid.UUID
+	if query.LabelID != nil && isAdmin {
+		id, err := uuid.Parse(*query.LabelID)
+		if err != nil {
+			respondWithError(c, appErrors.NewBadRequestError("Invalid label ID"))
+			return
+		}
+		labelID = &id
+	}
+
 	submissions, err := h.submissionService.ListSubmissions(
 		c.Request.Context(),
 		programID,
+		labelID,
 		userID,
 		isAdmin,
+		query.Archived,
 		query.Limit,
 		query.Offset,
 	)
@@ -119,6 +240,66 @@ func (h *SubmissionHandler) ListSubmissions(c *gin.Context) {
 	})
 }
 
+// SearchSubmissions searches submission titles and messages
+// GET /api/v1/submissions/search?q=
+func (h *SubmissionHandler) SearchSubmissions(c *gin.Context) {
+	var query validators.SearchSubmissionsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+
+	// Set defaults
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	// Parse optional program ID
+	var programID *uuid.UUID
+	if query.ProgramID != nil {
+		id, err := uuid.Parse(*query.ProgramID)
+		if err != nil {
+			respondWithError(c, appErrors.NewBadRequestError("Invalid program ID"))
+			return
+		}
+		programID = &id
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+	isAdmin := middleware.IsAdmin(c)
+
+	results, err := h.submissionService.SearchSubmissions(
+		c.Request.Context(),
+		query.Q,
+		programID,
+		userID,
+		isAdmin,
+		query.Limit,
+		query.Offset,
+	)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"query":   query.Q,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+		"count":   len(results),
+	})
+}
+
 // GetSubmission retrieves a submission by ID
 // GET /api/v1/submissions/:id
 func (h *SubmissionHandler) GetSubmission(c *gin.Context) {
@@ -184,6 +365,52 @@ func (h *SubmissionHandler) GetMessages(c *gin.Context) {
 	})
 }
 
+// ExportSubmission downloads the full thread as a Markdown or PDF document
+// GET /api/v1/submissions/:id/export?format=md|pdf
+func (h *SubmissionHandler) ExportSubmission(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid submission ID"))
+		return
+	}
+
+	var query validators.ExportSubmissionQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return
+	}
+
+	// Set defaults
+	if query.Format == "" {
+		query.Format = export.FormatMarkdown
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+	isAdmin := middleware.IsAdmin(c)
+
+	data, err := h.exportService.ExportSubmission(c.Request.Context(), id, userID, isAdmin, query.Format)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	contentType := "text/markdown; charset=utf-8"
+	if query.Format == export.FormatPDF {
+		contentType = "application/pdf"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="submission-%s.%s"`, id, query.Format))
+	c.Data(http.StatusOK, contentType, data)
+}
+
 // CreateMessage adds a message to a submission
 // POST /api/v1/submissions/:id/messages
 func (h *SubmissionHandler) CreateMessage(c *gin.Context) {
@@ -211,6 +438,17 @@ func (h *SubmissionHandler) CreateMessage(c *gin.Context) {
 	}
 	isAdmin := middleware.IsAdmin(c)
 
+	// Parse optional snippet ID
+	var snippetID *uuid.UUID
+	if req.SnippetID != nil {
+		id, err := uuid.Parse(*req.SnippetID)
+		if err != nil {
+			respondWithError(c, appErrors.NewBadRequestError("Invalid snippet ID"))
+			return
+		}
+		snippetID = &id
+	}
+
 	message, err := h.submissionService.CreateMessage(
 		c.Request.Context(),
 		submissionID,
@@ -218,6 +456,7 @@ func (h *SubmissionHandler) CreateMessage(c *gin.Context) {
 		isAdmin,
 		req.Content,
 		req.YouTubeURL,
+		snippetID,
 	)
 	if err != nil {
 		respondWithAppError(c, err)
@@ -229,6 +468,202 @@ func (h *SubmissionHandler) CreateMessage(c *gin.Context) {
 	})
 }
 
+// GetDraft returns the current user's unsent message for a submission ({"draft": null} if none)
+// GET /api/v1/submissions/:id/draft
+func (h *SubmissionHandler) GetDraft(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid submission ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+	isAdmin := middleware.IsAdmin(c)
+
+	draft, err := h.submissionService.GetDraft(c.Request.Context(), submissionID, userID, isAdmin)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"draft": draft,
+	})
+}
+
+// SaveDraft autosaves the current user's unsent message; an empty draft is discarded.
+// The draft is cleared when the user posts a message to the submission.
+// PUT /api/v1/submissions/:id/draft
+func (h *SubmissionHandler) SaveDraft(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid submission ID"))
+		return
+	}
+
+	var req validators.SaveDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+	isAdmin := middleware.IsAdmin(c)
+
+	draft, err := h.submissionService.SaveDraft(c.Request.Context(), submissionID, userID, isAdmin, req.Content, req.YouTubeURL)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"draft": draft,
+	})
+}
+
+// ArchiveSubmission archives a submission for the current user
+// PUT /api/v1/submissions/:id/archive
+func (h *SubmissionHandler) ArchiveSubmission(c *gin.Context) {
+	h.setArchived(c, true)
+}
+
+// UnarchiveSubmission moves a submission back into the current user's inbox
+// DELETE /api/v1/submissions/:id/archive
+func (h *SubmissionHandler) UnarchiveSubmission(c *gin.Context) {
+	h.setArchived(c, false)
+}
+
+func (h *SubmissionHandler) setArchived(c *gin.Context, archive bool) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid submission ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+	isAdmin := middleware.IsAdmin(c)
+
+	if err := h.submissionService.ArchiveSubmission(c.Request.Context(), submissionID, userID, isAdmin, archive); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	message := "Submission archived"
+	if !archive {
+		message = "Submission unarchived"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+}
+
+// GetTyping returns who else is typing in a submission thread
+// GET /api/v1/submissions/:id/typing
+func (h *SubmissionHandler) GetTyping(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid submission ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+	isAdmin := middleware.IsAdmin(c)
+
+	typing, err := h.submissionService.GetTyping(c.Request.Context(), submissionID, userID, isAdmin)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"typing": typing,
+	})
+}
+
+// SetTyping starts or stops the current user's typing indicator in a submission thread
+// PUT /api/v1/submissions/:id/typing
+func (h *SubmissionHandler) SetTyping(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid submission ID"))
+		return
+	}
+
+	var req validators.TypingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+	isAdmin := middleware.IsAdmin(c)
+
+	typing, err := h.submissionService.SetTyping(c.Request.Context(), submissionID, userID, isAdmin, req.State == "start")
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"typing": typing,
+	})
+}
+
+// DeleteDraft discards the current user's unsent message
+// DELETE /api/v1/submissions/:id/draft
+func (h *SubmissionHandler) DeleteDraft(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid submission ID"))
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	if err := h.submissionService.DeleteDraft(c.Request.Context(), submissionID, userID); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Draft discarded",
+	})
+}
+
 // MarkMessageAsRead marks a message as read by the current user
 // PUT /api/v1/messages/:id/read
 func (h *SubmissionHandler) MarkMessageAsRead(c *gin.Context) {
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type SupportHandler struct {
+	supportService *services.SupportService
+	validate       *validator.Validate
+}
+
+func NewSupportHandler(supportService *services.SupportService) *SupportHandler {
+	return &SupportHandler{
+		supportService: supportService,
+		validate:       validators.New(),
+	}
+}
+
+// OpenTicket godoc
+// @Summary Open a support ticket
+// @Description Ask the admins something that is not about a program, such as app or account problems. The ticket stays open until an admin replies.
+// @Tags support
+// @Accept json
+// @Produce json
+// @Param request body validators.OpenSupportTicketRequest true "Ticket"
+// @Success 201 {object} models.SupportTicketWithMessages
+// @Router /api/v1/support/tickets [post]
+// @Security BearerAuth
+func (h *SupportHandler) OpenTicket(c *gin.Context) {
+	var req validators.OpenSupportTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	ticket, err := h.supportService.Open(c.Request.Context(), userID, models.SupportCategory(req.Category), req.Message)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ticket)
+}
+
+// ListMyTickets godoc
+// @Summary List my support tickets
+// @Description Most recently active first
+// @Tags support
+// @Produce json
+// @Param status query string false "open, answered, closed or all (default all)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/support/tickets [get]
+// @Security BearerAuth
+func (h *SupportHandler) ListMyTickets(c *gin.Context) {
+	query, ok := h.bindListQuery(c)
+	if !ok {
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return
+	}
+
+	var status *models.SupportStatus
+	if query.Status != "" && query.Status != "all" {
+		s := models.SupportStatus(query.Status)
+		status = &s
+	}
+
+	tickets, err := h.supportService.List(c.Request.Context(), userID, status, query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tickets": tickets,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+	})
+}
+
+// GetTicket godoc
+// @Summary Get a support ticket with its messages
+// @Description Your own tickets, or any ticket as admin
+// @Tags support
+// @Produce json
+// @Param id path string true "Ticket ID"
+// @Success 200 {object} models.SupportTicketWithMessages
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/support/tickets/{id} [get]
+// @Security BearerAuth
+func (h *SupportHandler) GetTicket(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	ticket, err := h.supportService.Get(c.Request.Context(), id, userID, middleware.IsAdmin(c))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
+
+// Reply godoc
+// @Summary Reply to a support ticket
+// @Description A reply from the student puts the ticket back in the queue as open, reopening it if it was closed. A reply from an admin marks it answered and sends the student a support_reply notification.
+// @Tags support
+// @Accept json
+// @Produce json
+// @Param id path string true "Ticket ID"
+// @Param request body validators.SupportReplyRequest true "Message"
+// @Success 201 {object} models.SupportMessage
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/support/tickets/{id}/messages [post]
+// @Security BearerAuth
+func (h *SupportHandler) Reply(c *gin.Context) {
+	id, userID, ok := h.parseIDs(c)
+	if !ok {
+		return
+	}
+
+	var req validators.SupportReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	message, err := h.supportService.Reply(c.Request.Context(), id, userID, middleware.IsAdmin(c), req.Content)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, message)
+}
+
+// ListQueue godoc
+// @Summary List the support queue (admin only)
+// @Description Everyone's tickets, the longest waiting first
+// @Tags support
+// @Produce json
+// @Param status query string false "open, answered, closed or all (default open)"
+// @Param category query string false "technical, account, billing or other"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/support-tickets [get]
+// @Security BearerAuth
+func (h *SupportHandler) ListQueue(c *gin.Context) {
+	query, ok := h.bindListQuery(c)
+	if !ok {
+		return
+	}
+
+	var status *models.SupportStatus
+	switch query.Status {
+	case "":
+		open := models.SupportOpen
+		status = &open
+	case "all":
+	default:
+		s := models.SupportStatus(query.Status)
+		status = &s
+	}
+	var category *models.SupportCategory
+	if query.Category != "" {
+		cat := models.SupportCategory(query.Category)
+		category = &cat
+	}
+
+	tickets, err := h.supportService.Queue(c.Request.Context(), status, category, query.Limit, query.Offset)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tickets": tickets,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+	})
+}
+
+// SetStatus godoc
+// @Summary Change a support ticket's status (admin only)
+// @Description Typically to close a settled ticket; the student reopens it by replying
+// @Tags support
+// @Accept json
+// @Produce json
+// @Param id path string true "Ticket ID"
+// @Param request body validators.SetSupportStatusRequest true "Status"
+// @Success 200 {object} models.SupportTicket
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/admin/support-tickets/{id} [put]
+// @Security BearerAuth
+func (h *SupportHandler) SetStatus(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid ticket ID"))
+		return
+	}
+
+	var req validators.SetSupportStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	ticket, err := h.supportService.SetStatus(c.Request.Context(), id, models.SupportStatus(req.Status))
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
+
+func (h *SupportHandler) bindListQuery(c *gin.Context) (validators.ListSupportTicketsQuery, bool) {
+	var query validators.ListSupportTicketsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid query parameters"))
+		return query, false
+	}
+	if query.Limit == 0 {
+		query.Limit = 20
+	}
+	if err := h.validate.Struct(query); err != nil {
+		respondWithValidationError(c, err)
+		return query, false
+	}
+	return query, true
+}
+
+func (h *SupportHandler) parseIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid ticket ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithError(c, appErrors.NewAuthenticationError("Invalid user"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, userID, true
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/internal/validators"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type TranslationHandler struct {
+	translationService *services.TranslationService
+	validate           *validator.Validate
+}
+
+func NewTranslationHandler(translationService *services.TranslationService) *TranslationHandler {
+	return &TranslationHandler{
+		translationService: translationService,
+		validate:           validators.New(),
+	}
+}
+
+// ListProgramTranslations godoc
+// @Summary List translations of a program (admin only)
+// @Tags translations
+// @Produce json
+// @Param id path string true "Program ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/translations [get]
+// @Security BearerAuth
+func (h *TranslationHandler) ListProgramTranslations(c *gin.Context) {
+	h.list(c, models.TranslatableProgram)
+}
+
+// SetProgramTranslation godoc
+// @Summary Create or replace a program translation (admin only)
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param locale path string true "Locale (de, zh)"
+// @Param request body validators.SetTranslationRequest true "Translated content"
+// @Success 200 {object} models.Translation
+// @Router /api/v1/programs/{id}/translations/{locale} [put]
+// @Security BearerAuth
+func (h *TranslationHandler) SetProgramTranslation(c *gin.Context) {
+	h.set(c, models.TranslatableProgram)
+}
+
+// DeleteProgramTranslation godoc
+// @Summary Delete a program translation (admin only)
+// @Tags translations
+// @Param id path string true "Program ID"
+// @Param locale path string true "Locale"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/programs/{id}/translations/{locale} [delete]
+// @Security BearerAuth
+func (h *TranslationHandler) DeleteProgramTranslation(c *gin.Context) {
+	h.delete(c, models.TranslatableProgram)
+}
+
+// ListExerciseTranslations godoc
+// @Summary List translations of an exercise (admin only)
+// @Tags translations
+// @Produce json
+// @Param id path string true "Exercise ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/exercises/{id}/translations [get]
+// @Security BearerAuth
+func (h *TranslationHandler) ListExerciseTranslations(c *gin.Context) {
+	h.list(c, models.TranslatableExercise)
+}
+
+// SetExerciseTranslation godoc
+// @Summary Create or replace an exercise translation (admin only)
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param id path string true "Exercise ID"
+// @Param locale path string true "Locale (de, zh)"
+// @Param request body validators.SetTranslationRequest true "Translated content"
+// @Success 200 {object} models.Translation
+// @Router /api/v1/exercises/{id}/translations/{locale} [put]
+// @Security BearerAuth
+func (h *TranslationHandler) SetExerciseTranslation(c *gin.Context) {
+	h.set(c, models.TranslatableExercise)
+}
+
+// DeleteExerciseTranslation godoc
+// @Summary Delete an exercise translation (admin only)
+// @Tags translations
+// @Param id path string true "Exercise ID"
+// @Param locale path string true "Locale"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/exercises/{id}/translations/{locale} [delete]
+// @Security BearerAuth
+func (h *TranslationHandler) DeleteExerciseTranslation(c *gin.Context) {
+	h.delete(c, models.TranslatableExercise)
+}
+
+func (h *TranslationHandler) list(c *gin.Context, entityType models.TranslatableType) {
+	entityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid ID"))
+		return
+	}
+
+	translations, err := h.translationService.List(c.Request.Context(), entityType, entityID)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"translations": translations,
+	})
+}
+
+func (h *TranslationHandler) set(c *gin.Context, entityType models.TranslatableType) {
+	entityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid ID"))
+		return
+	}
+
+	var req validators.SetTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		respondWithValidationError(c, err)
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	translation := &models.Translation{
+		EntityID:    entityID,
+		Locale:      c.Param("locale"),
+		Name:        req.Name,
+		Description: req.Description,
+		UpdatedBy:   &userID,
+	}
+	if err := h.translationService.Set(c.Request.Context(), entityType, translation); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, translation)
+}
+
+func (h *TranslationHandler) delete(c *gin.Context, entityType models.TranslatableType) {
+	entityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondWithError(c, appErrors.NewBadRequestError("Invalid ID"))
+		return
+	}
+
+	if err := h.translationService.Delete(c.Request.Context(), entityType, entityID, c.Param("locale")); err != nil {
+		respondWithAppError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Translation deleted successfully",
+	})
+}
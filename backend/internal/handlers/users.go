@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -12,15 +13,30 @@ import (
 	appErrors "github.com/xuangong/backend/pkg/errors"
 )
 
+//go:generate go tool moq -skip-ensure -rm -out ../../pkg/testutil/mocks/user_service.go -pkg mocks . UserService
+
+// UserService is the user management used by UserHandler, implemented by services.UserService
+type UserService interface {
+	List(ctx context.Context, limit, offset int) ([]models.UserResponse, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.UserResponse, error)
+	Create(ctx context.Context, email, password, fullName, role string) (*models.UserResponse, error)
+	Update(ctx context.Context, id uuid.UUID, fullName, email *string, password *string, isActive *bool) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetUserPrograms(ctx context.Context, userID uuid.UUID) ([]models.ProgramWithExercises, error)
+	UpdateUserRole(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, newRole models.UserRole) error
+}
+
+var _ UserService = (*services.UserService)(nil)
+
 type UserHandler struct {
-	userService *services.UserService
+	userService UserService
 	validate    *validator.Validate
 }
 
-func NewUserHandler(userService *services.UserService) *UserHandler {
+func NewUserHandler(userService UserService) *UserHandler {
 	return &UserHandler{
 		userService: userService,
-		validate:    validator.New(),
+		validate:    validators.New(),
 	}
 }
 
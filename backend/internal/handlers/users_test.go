@@ -9,156 +9,12 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/xuangong/backend/internal/models"
-	"github.com/xuangong/backend/internal/validators"
 	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/testutil/mocks"
 )
 
-// userServiceInterface defines the interface that UserHandler needs
-// This allows us to mock the service in tests
-type userServiceInterface interface {
-	UpdateUserRole(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, newRole models.UserRole) error
-	GetByID(ctx context.Context, id uuid.UUID) (*models.UserResponse, error)
-	List(ctx context.Context, limit, offset int) ([]models.UserResponse, error)
-	Create(ctx context.Context, email, password, fullName, role string) (*models.UserResponse, error)
-	Update(ctx context.Context, id uuid.UUID, fullName, email *string, password *string, isActive *bool) error
-	Delete(ctx context.Context, id uuid.UUID) error
-	GetUserPrograms(ctx context.Context, userID uuid.UUID) ([]models.ProgramWithExercises, error)
-}
-
-// MockUserService wraps service methods for handler-level testing
-type MockUserService struct {
-	UpdateUserRoleFunc  func(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, newRole models.UserRole) error
-	GetByIDFunc         func(ctx context.Context, id uuid.UUID) (*models.UserResponse, error)
-	ListFunc            func(ctx context.Context, limit, offset int) ([]models.UserResponse, error)
-	CreateFunc          func(ctx context.Context, email, password, fullName, role string) (*models.UserResponse, error)
-	UpdateFunc          func(ctx context.Context, id uuid.UUID, fullName, email *string, password *string, isActive *bool) error
-	DeleteFunc          func(ctx context.Context, id uuid.UUID) error
-	GetUserProgramsFunc func(ctx context.Context, userID uuid.UUID) ([]models.ProgramWithExercises, error)
-}
-
-func (m *MockUserService) UpdateUserRole(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, newRole models.UserRole) error {
-	if m.UpdateUserRoleFunc != nil {
-		return m.UpdateUserRoleFunc(ctx, requestingUserID, requestingRole, targetUserID, newRole)
-	}
-	return nil
-}
-
-func (m *MockUserService) GetByID(ctx context.Context, id uuid.UUID) (*models.UserResponse, error) {
-	if m.GetByIDFunc != nil {
-		return m.GetByIDFunc(ctx, id)
-	}
-	return nil, nil
-}
-
-func (m *MockUserService) List(ctx context.Context, limit, offset int) ([]models.UserResponse, error) {
-	if m.ListFunc != nil {
-		return m.ListFunc(ctx, limit, offset)
-	}
-	return nil, nil
-}
-
-func (m *MockUserService) Create(ctx context.Context, email, password, fullName, role string) (*models.UserResponse, error) {
-	if m.CreateFunc != nil {
-		return m.CreateFunc(ctx, email, password, fullName, role)
-	}
-	return nil, nil
-}
-
-func (m *MockUserService) Update(ctx context.Context, id uuid.UUID, fullName, email *string, password *string, isActive *bool) error {
-	if m.UpdateFunc != nil {
-		return m.UpdateFunc(ctx, id, fullName, email, password, isActive)
-	}
-	return nil
-}
-
-func (m *MockUserService) Delete(ctx context.Context, id uuid.UUID) error {
-	if m.DeleteFunc != nil {
-		return m.DeleteFunc(ctx, id)
-	}
-	return nil
-}
-
-func (m *MockUserService) GetUserPrograms(ctx context.Context, userID uuid.UUID) ([]models.ProgramWithExercises, error) {
-	if m.GetUserProgramsFunc != nil {
-		return m.GetUserProgramsFunc(ctx, userID)
-	}
-	return nil, nil
-}
-
-// testUserHandler is a test version of UserHandler that uses the interface
-type testUserHandler struct {
-	userService userServiceInterface
-	validate    *validator.Validate
-}
-
-// UpdateUserRole wraps the real handler implementation with our test-friendly service interface
-func (h *testUserHandler) UpdateUserRole(c *gin.Context) {
-	// Create a temporary UserHandler that wraps our mock service
-	// We'll call the real handler logic but with our mock service
-	// This is a bit of a hack but allows us to test the handler with mocks
-
-	// Parse target user ID from URL parameter
-	targetUserID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		respondWithError(c, appErrors.NewBadRequestError("Invalid user ID"))
-		return
-	}
-
-	// Parse request body
-	var req validators.UpdateUserRoleRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		respondWithError(c, appErrors.NewBadRequestError("Invalid request body"))
-		return
-	}
-
-	// Validate request
-	if err := h.validate.Struct(req); err != nil {
-		respondWithValidationError(c, err)
-		return
-	}
-
-	// Get requesting user ID and role from middleware context
-	requestingUserIDStr, exists := c.Get("user_id")
-	if !exists {
-		respondWithError(c, appErrors.NewAuthenticationError("User not authenticated"))
-		return
-	}
-
-	requestingUserID, err := uuid.Parse(requestingUserIDStr.(string))
-	if err != nil {
-		respondWithError(c, appErrors.NewAuthenticationError("Invalid user ID in token"))
-		return
-	}
-
-	requestingRoleStr, exists := c.Get("user_role")
-	if !exists {
-		respondWithError(c, appErrors.NewAuthenticationError("User role not found in token"))
-		return
-	}
-
-	requestingRole := models.UserRole(requestingRoleStr.(string))
-	newRole := models.UserRole(req.Role)
-
-	// Call service to update role
-	if err := h.userService.UpdateUserRole(
-		c.Request.Context(),
-		requestingUserID,
-		requestingRole,
-		targetUserID,
-		newRole,
-	); err != nil {
-		respondWithAppError(c, err)
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "User role updated successfully",
-	})
-}
-
 func TestUserHandler_UpdateUserRole(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -171,7 +27,7 @@ func TestUserHandler_UpdateUserRole(t *testing.T) {
 		requestBody        map[string]string
 		userID             uuid.UUID
 		userRole           models.UserRole
-		setupMockService   func(*MockUserService)
+		setupMockService   func(*mocks.UserServiceMock)
 		expectedStatus     int
 		expectedErrCode    string
 		expectedErrMessage string
@@ -182,7 +38,7 @@ func TestUserHandler_UpdateUserRole(t *testing.T) {
 			requestBody:  map[string]string{"role": "admin"},
 			userID:       adminID,
 			userRole:     models.RoleAdmin,
-			setupMockService: func(mock *MockUserService) {
+			setupMockService: func(mock *mocks.UserServiceMock) {
 				mock.UpdateUserRoleFunc = func(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, newRole models.UserRole) error {
 					return nil
 				}
@@ -195,7 +51,7 @@ func TestUserHandler_UpdateUserRole(t *testing.T) {
 			requestBody:  map[string]string{"role": "student"},
 			userID:       adminID,
 			userRole:     models.RoleAdmin,
-			setupMockService: func(mock *MockUserService) {
+			setupMockService: func(mock *mocks.UserServiceMock) {
 				mock.UpdateUserRoleFunc = func(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, newRole models.UserRole) error {
 					return nil
 				}
@@ -208,7 +64,7 @@ func TestUserHandler_UpdateUserRole(t *testing.T) {
 			requestBody:  map[string]string{"role": "admin"},
 			userID:       studentID,
 			userRole:     models.RoleStudent,
-			setupMockService: func(mock *MockUserService) {
+			setupMockService: func(mock *mocks.UserServiceMock) {
 				mock.UpdateUserRoleFunc = func(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, newRole models.UserRole) error {
 					return appErrors.NewAuthorizationError("Only admins can update user roles")
 				}
@@ -223,7 +79,7 @@ func TestUserHandler_UpdateUserRole(t *testing.T) {
 			requestBody:  map[string]string{"role": "student"},
 			userID:       adminID,
 			userRole:     models.RoleAdmin,
-			setupMockService: func(mock *MockUserService) {
+			setupMockService: func(mock *mocks.UserServiceMock) {
 				mock.UpdateUserRoleFunc = func(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, newRole models.UserRole) error {
 					return appErrors.NewBadRequestError("Cannot demote the last admin")
 				}
@@ -238,7 +94,7 @@ func TestUserHandler_UpdateUserRole(t *testing.T) {
 			requestBody:  map[string]string{"role": "admin"},
 			userID:       adminID,
 			userRole:     models.RoleAdmin,
-			setupMockService: func(mock *MockUserService) {
+			setupMockService: func(mock *mocks.UserServiceMock) {
 				// Service should not be called
 			},
 			expectedStatus:     http.StatusBadRequest,
@@ -251,7 +107,7 @@ func TestUserHandler_UpdateUserRole(t *testing.T) {
 			requestBody:  map[string]string{"role": "superuser"},
 			userID:       adminID,
 			userRole:     models.RoleAdmin,
-			setupMockService: func(mock *MockUserService) {
+			setupMockService: func(mock *mocks.UserServiceMock) {
 				// Service won't be called because validation fails first
 			},
 			expectedStatus:     http.StatusBadRequest,
@@ -264,7 +120,7 @@ func TestUserHandler_UpdateUserRole(t *testing.T) {
 			requestBody:  map[string]string{"role": "admin"},
 			userID:       adminID,
 			userRole:     models.RoleAdmin,
-			setupMockService: func(mock *MockUserService) {
+			setupMockService: func(mock *mocks.UserServiceMock) {
 				mock.UpdateUserRoleFunc = func(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, newRole models.UserRole) error {
 					return appErrors.NewNotFoundError("User")
 				}
@@ -279,7 +135,7 @@ func TestUserHandler_UpdateUserRole(t *testing.T) {
 			requestBody:  map[string]string{},
 			userID:       adminID,
 			userRole:     models.RoleAdmin,
-			setupMockService: func(mock *MockUserService) {
+			setupMockService: func(mock *mocks.UserServiceMock) {
 				// Service should not be called - validation fails first
 			},
 			expectedStatus:     http.StatusBadRequest,
@@ -292,7 +148,7 @@ func TestUserHandler_UpdateUserRole(t *testing.T) {
 			requestBody:  nil, // Will send invalid JSON
 			userID:       adminID,
 			userRole:     models.RoleAdmin,
-			setupMockService: func(mock *MockUserService) {
+			setupMockService: func(mock *mocks.UserServiceMock) {
 				// Service should not be called
 			},
 			expectedStatus:     http.StatusBadRequest,
@@ -305,7 +161,7 @@ func TestUserHandler_UpdateUserRole(t *testing.T) {
 			requestBody:  map[string]string{"role": "admin"},
 			userID:       adminID,
 			userRole:     models.RoleAdmin,
-			setupMockService: func(mock *MockUserService) {
+			setupMockService: func(mock *mocks.UserServiceMock) {
 				mock.UpdateUserRoleFunc = func(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, newRole models.UserRole) error {
 					return appErrors.NewInternalError("Failed to update user role")
 				}
@@ -319,15 +175,10 @@ func TestUserHandler_UpdateUserRole(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup mock service
-			mockService := &MockUserService{}
+			mockService := &mocks.UserServiceMock{}
 			tt.setupMockService(mockService)
 
-			// Create handler directly with mock
-			// Note: In GREEN phase, we'll refactor UserHandler to use userServiceInterface
-			handler := &testUserHandler{
-				userService: mockService,
-				validate:    validator.New(),
-			}
+			handler := NewUserHandler(mockService)
 
 			// Setup Gin router and context
 			router := gin.New()
@@ -476,7 +327,7 @@ func TestUserHandler_UpdateUserRole_AuthorizationMatrix(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockService := &MockUserService{
+			mockService := &mocks.UserServiceMock{
 				UpdateUserRoleFunc: func(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, newRole models.UserRole) error {
 					if tt.expectAllowed {
 						return nil
@@ -485,10 +336,7 @@ func TestUserHandler_UpdateUserRole_AuthorizationMatrix(t *testing.T) {
 				},
 			}
 
-			handler := &testUserHandler{
-				userService: mockService,
-				validate:    validator.New(),
-			}
+			handler := NewUserHandler(mockService)
 
 			router := gin.New()
 			router.PUT("/api/v1/users/:id/role", func(c *gin.Context) {
@@ -559,7 +407,7 @@ func TestUserHandler_UpdateUserRole_LastAdminProtection(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockService := &MockUserService{
+			mockService := &mocks.UserServiceMock{
 				UpdateUserRoleFunc: func(ctx context.Context, requestingUserID uuid.UUID, requestingRole models.UserRole, targetUserID uuid.UUID, newRole models.UserRole) error {
 					if tt.shouldFail {
 						return appErrors.NewBadRequestError("Cannot demote the last admin")
@@ -568,10 +416,7 @@ func TestUserHandler_UpdateUserRole_LastAdminProtection(t *testing.T) {
 				},
 			}
 
-			handler := &testUserHandler{
-				userService: mockService,
-				validate:    validator.New(),
-			}
+			handler := NewUserHandler(mockService)
 
 			router := gin.New()
 			router.PUT("/api/v1/users/:id/role", func(c *gin.Context) {
@@ -0,0 +1,40 @@
+// Package hydrate fills in the items of a list page concurrently, such as the exercises of each
+// program or the logs of each session, without letting a long page take over the connection pool.
+package hydrate
+
+import (
+	"context"
+
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// Concurrency bounds how many items of a list page are hydrated at once, so a long page
+// doesn't take over the connection pool
+const Concurrency = 8
+
+// Each calls fn for every index of a list of n items, at most Concurrency at a time.
+// fn fills in slot i of a result slice sized up front, so order is kept. It stops at the first
+// error, and gives up on the remaining items once ctx is cancelled.
+func Each(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(Concurrency)
+	for i := 0; i < n && gctx.Err() == nil; i++ {
+		g.Go(func() error {
+			if err := gctx.Err(); err != nil {
+				return err
+			}
+			return fn(gctx, i)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return appErrors.NewInternalError("Request cancelled").WithError(ctx.Err())
+		}
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return appErrors.NewInternalError("Request cancelled").WithError(err)
+	}
+	return nil
+}
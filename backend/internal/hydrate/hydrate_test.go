@@ -0,0 +1,120 @@
+package hydrate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEach_LimitsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	err := Each(context.Background(), 50, func(ctx context.Context, i int) error {
+		now := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if now <= old || peak.CompareAndSwap(old, now) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Each() error = %v", err)
+	}
+	if got := peak.Load(); got > Concurrency {
+		t.Errorf("%d calls ran at once, want at most %d", got, Concurrency)
+	}
+	if got := peak.Load(); got < 2 {
+		t.Errorf("%d calls ran at once, want them to run concurrently", got)
+	}
+}
+
+func TestEach_KeepsOrder(t *testing.T) {
+	results := make([]int, 100)
+	err := Each(context.Background(), len(results), func(ctx context.Context, i int) error {
+		// Later items finish first
+		time.Sleep(time.Duration(len(results)-i) * 50 * time.Microsecond)
+		results[i] = i * i
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Each() error = %v", err)
+	}
+	for i, got := range results {
+		if got != i*i {
+			t.Fatalf("results[%d] = %d, want %d", i, got, i*i)
+		}
+	}
+}
+
+func TestEach_FirstErrorCancelsRest(t *testing.T) {
+	errFailed := errors.New("item 3 failed")
+	var calls atomic.Int32
+	var cancelled atomic.Int32
+	err := Each(context.Background(), 100, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		if i == 3 {
+			return errFailed
+		}
+		select {
+		case <-ctx.Done():
+			cancelled.Add(1)
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("Each() error = %v, want %v", err, errFailed)
+	}
+	if got := calls.Load(); got > Concurrency+1 {
+		t.Errorf("fn was called %d times, want the remaining items skipped after the error", got)
+	}
+	if got := cancelled.Load(); got == 0 {
+		t.Error("no running call saw its context cancelled")
+	}
+}
+
+func TestEach_StopsOnCancelledContext(t *testing.T) {
+	t.Run("already_cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var calls atomic.Int32
+		err := Each(ctx, 10, func(ctx context.Context, i int) error {
+			calls.Add(1)
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Each() error = %v, want it to wrap context.Canceled", err)
+		}
+		if got := calls.Load(); got != 0 {
+			t.Errorf("fn was called %d times, want none", got)
+		}
+	})
+
+	t.Run("cancelled_midway", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var calls atomic.Int32
+		var once sync.Once
+		err := Each(ctx, 100, func(ctx context.Context, i int) error {
+			calls.Add(1)
+			once.Do(cancel)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Each() error = %v, want it to wrap context.Canceled", err)
+		}
+		if got := calls.Load(); got > Concurrency {
+			t.Errorf("fn was called %d times, want the remaining items skipped after cancellation", got)
+		}
+	})
+}
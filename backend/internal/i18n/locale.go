@@ -0,0 +1,72 @@
+// Package i18n resolves the content locale for a request. The school teaches in
+// English, German and Chinese; English is the language programs are authored in.
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is the language program content is authored in
+const DefaultLocale = "en"
+
+// SupportedLocales lists the locales content can be translated into
+var SupportedLocales = []string{"en", "de", "zh"}
+
+// IsSupported reports whether locale is one of SupportedLocales
+func IsSupported(locale string) bool {
+	for _, supported := range SupportedLocales {
+		if supported == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate picks the best supported locale from an Accept-Language header,
+// falling back to DefaultLocale. Region subtags are ignored (de-AT matches de).
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		locale string
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err != nil {
+					parsed = 0
+				}
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		base, _, _ := strings.Cut(tag, "-")
+		candidates = append(candidates, candidate{locale: base, q: q})
+	}
+
+	// Stable sort keeps header order for equal weights
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	for _, c := range candidates {
+		if IsSupported(c.locale) {
+			return c.locale
+		}
+	}
+	return DefaultLocale
+}
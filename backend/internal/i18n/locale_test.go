@@ -0,0 +1,28 @@
+package i18n
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "en"},
+		{"de", "de"},
+		{"de-AT,de;q=0.9,en;q=0.8", "de"},
+		{"zh-CN", "zh"},
+		{"fr-FR,fr;q=0.9", "en"},
+		{"fr,de;q=0.5", "de"},
+		{"en;q=0.4, zh;q=0.8", "zh"},
+		{"de;q=0, zh", "zh"},
+		{"*", "en"},
+		{"ZH-tw", "zh"},
+		{"de;q=abc, en;q=0.1", "en"},
+	}
+
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Package limitations adjusts a program to a student's injury and health profile. Exercises
+// contraindicated for one of the student's limitations are replaced by their first substitute
+// that is safe for the student, or flagged for the instructor when none is.
+package limitations
+
+import (
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/timeline"
+)
+
+// Conflicts returns the student's limitations that the contraindications rule out
+func Conflicts(limitations, contraindications []string) []string {
+	var conflicts []string
+	for _, limitation := range limitations {
+		for _, contraindication := range contraindications {
+			if limitation == contraindication {
+				conflicts = append(conflicts, limitation)
+				break
+			}
+		}
+	}
+	return conflicts
+}
+
+// Plan decides how each contraindicated exercise is adjusted for the student. Exercises that are
+// safe are left out.
+func Plan(profile *models.Limitations, exercises []models.Exercise) map[uuid.UUID]models.ExerciseAdjustment {
+	plan := make(map[uuid.UUID]models.ExerciseAdjustment)
+	all := profile.All()
+	if len(all) == 0 {
+		return plan
+	}
+
+	for _, ex := range exercises {
+		conflicts := Conflicts(all, ex.Contraindications)
+		if len(conflicts) == 0 {
+			continue
+		}
+
+		adjustment := models.ExerciseAdjustment{
+			Action:      models.AdjustmentFlagged,
+			Limitations: conflicts,
+		}
+		for _, substitute := range ex.Substitutes {
+			if len(Conflicts(all, substitute.Contraindications)) == 0 {
+				id := substitute.ID
+				adjustment.Action = models.AdjustmentSubstituted
+				adjustment.SubstituteID = &id
+				adjustment.OriginalName = ex.Name
+				break
+			}
+		}
+		plan[ex.ID] = adjustment
+	}
+	return plan
+}
+
+// Apply renders the exercises for the student in place: substituted exercises take the
+// substitute's name, description and timing, and every adjusted exercise carries its
+// Adjustment. It reports whether any exercise was adjusted.
+func Apply(profile *models.Limitations, exercises []models.Exercise) bool {
+	plan := Plan(profile, exercises)
+	for i := range exercises {
+		adjustment, ok := plan[exercises[i].ID]
+		if !ok {
+			continue
+		}
+		if adjustment.SubstituteID != nil {
+			substitute(&exercises[i], *adjustment.SubstituteID)
+		}
+		exercises[i].Adjustment = &adjustment
+	}
+	return len(plan) > 0
+}
+
+// ApplyToTimeline substitutes contraindicated exercises on the student's timeline. A substitute
+// the student chose in their settings is kept.
+func ApplyToTimeline(profile *models.Limitations, exercises []models.Exercise, opts timeline.Options) {
+	for exerciseID, adjustment := range Plan(profile, exercises) {
+		override := opts.Exercises[exerciseID]
+		if adjustment.SubstituteID == nil || override.SubstituteID != nil {
+			continue
+		}
+		override.SubstituteID = adjustment.SubstituteID
+		opts.Exercises[exerciseID] = override
+	}
+}
+
+// substitute puts the substitute in the exercise's place, keeping the exercise's ID so sessions
+// log it, and its timing where the substitute sets none
+func substitute(ex *models.Exercise, substituteID uuid.UUID) {
+	for _, s := range ex.Substitutes {
+		if s.ID != substituteID {
+			continue
+		}
+		ex.Name = s.Name
+		ex.Description = s.Description
+		ex.RenderedHTML = s.RenderedHTML
+		if s.DurationSeconds != nil {
+			ex.DurationSeconds = s.DurationSeconds
+		}
+		if s.Repetitions != nil {
+			ex.Repetitions = s.Repetitions
+		}
+		if s.SideDurationSeconds != nil {
+			ex.SideDurationSeconds = s.SideDurationSeconds
+		}
+		return
+	}
+}
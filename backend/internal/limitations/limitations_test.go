@@ -0,0 +1,90 @@
+package limitations
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/timeline"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestApply(t *testing.T) {
+	kneeSafe := models.ExerciseSubstitute{
+		ID:              uuid.New(),
+		Name:            "Seated Meditation",
+		DurationSeconds: intPtr(90),
+	}
+	stillLow := models.ExerciseSubstitute{
+		ID:                uuid.New(),
+		Name:              "High Horse Stance",
+		Contraindications: []string{"knees"},
+	}
+	horse := models.Exercise{
+		ID:                uuid.New(),
+		Name:              "Horse Stance",
+		DurationSeconds:   intPtr(300),
+		Contraindications: []string{"knees", "hypertension"},
+		Substitutes:       []models.ExerciseSubstitute{stillLow, kneeSafe},
+	}
+	inversion := models.Exercise{
+		ID:                uuid.New(),
+		Name:              "Headstand",
+		Contraindications: []string{"hypertension", "neck"},
+	}
+	circles := models.Exercise{
+		ID:                uuid.New(),
+		Name:              "Arm Circles",
+		Contraindications: []string{"shoulders"},
+	}
+	profile := &models.Limitations{Joints: []string{"knees"}, Conditions: []string{"hypertension"}}
+
+	t.Run("no limitations", func(t *testing.T) {
+		exercises := []models.Exercise{horse, inversion}
+		if Apply(&models.Limitations{}, exercises) || Apply(nil, exercises) {
+			t.Error("adjusted exercises without limitations")
+		}
+	})
+
+	t.Run("substitutes or flags contraindicated exercises", func(t *testing.T) {
+		exercises := []models.Exercise{horse, inversion, circles}
+		if !Apply(profile, exercises) {
+			t.Fatal("no exercise adjusted")
+		}
+
+		substituted := exercises[0]
+		if substituted.ID != horse.ID || substituted.Name != "Seated Meditation" || *substituted.DurationSeconds != 90 {
+			t.Errorf("horse stance = %+v, want the seated substitute under the exercise's ID", substituted)
+		}
+		if a := substituted.Adjustment; a == nil || a.Action != models.AdjustmentSubstituted || *a.SubstituteID != kneeSafe.ID ||
+			a.OriginalName != "Horse Stance" || len(a.Limitations) != 2 {
+			t.Errorf("horse stance adjustment = %+v, want substituted for knees and hypertension", a)
+		}
+
+		if a := exercises[1].Adjustment; a == nil || a.Action != models.AdjustmentFlagged || a.SubstituteID != nil ||
+			len(a.Limitations) != 1 || a.Limitations[0] != "hypertension" {
+			t.Errorf("headstand adjustment = %+v, want flagged for hypertension", a)
+		}
+		if exercises[2].Adjustment != nil {
+			t.Errorf("arm circles adjustment = %+v, want none", exercises[2].Adjustment)
+		}
+	})
+
+	t.Run("timeline keeps the student's own choice", func(t *testing.T) {
+		opts := timeline.DefaultOptions()
+		ApplyToTimeline(profile, []models.Exercise{horse}, opts)
+		if id := opts.Exercises[horse.ID].SubstituteID; id == nil || *id != kneeSafe.ID {
+			t.Errorf("substitute = %v, want %s", id, kneeSafe.ID)
+		}
+
+		chosen := stillLow.ID
+		opts.Exercises[horse.ID] = timeline.ExerciseOverride{SubstituteID: &chosen}
+		ApplyToTimeline(profile, []models.Exercise{horse}, opts)
+		if id := opts.Exercises[horse.ID].SubstituteID; *id != stillLow.ID {
+			t.Errorf("substitute = %s, want the chosen %s", id, stillLow.ID)
+		}
+	})
+}
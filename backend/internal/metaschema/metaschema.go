@@ -0,0 +1,93 @@
+// Package metaschema compiles admin-defined JSON Schemas and validates metadata maps against them.
+package metaschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Schema is a compiled metadata schema
+type Schema struct {
+	compiled *jsonschema.Schema
+}
+
+// Compile checks that definition is a valid JSON Schema describing an object.
+// Remote references are not resolved, so schemas must be self-contained.
+func Compile(definition map[string]interface{}) (*Schema, error) {
+	if t, ok := definition["type"]; ok && t != "object" {
+		return nil, fmt.Errorf("metadata schema must describe an object, got type %v", t)
+	}
+
+	raw, err := json.Marshal(definition)
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.LoadURL = func(url string) (_ io.ReadCloser, err error) {
+		return nil, fmt.Errorf("remote schema references are not allowed: %s", url)
+	}
+	if err := compiler.AddResource("metadata.json", bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+	compiled, err := compiler.Compile("metadata.json")
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{compiled: compiled}, nil
+}
+
+// Validate checks metadata against the schema and returns one message per violation,
+// prefixed with the JSON pointer of the offending value. A nil map is validated as empty.
+func (s *Schema) Validate(metadata map[string]interface{}) ([]string, error) {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+
+	// Round-trip through JSON so numbers and nested values have the types the validator expects
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	err = s.compiled.Validate(doc)
+	if err == nil {
+		return nil, nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, err
+	}
+
+	var violations []string
+	collect(validationErr, &violations)
+	sort.Strings(violations)
+	return violations, nil
+}
+
+// collect gathers the leaf errors, which carry the specific reason for each violation
+func collect(err *jsonschema.ValidationError, violations *[]string) {
+	if len(err.Causes) == 0 {
+		location := err.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		*violations = append(*violations, fmt.Sprintf("%s: %s", location, err.Message))
+		return
+	}
+	for _, cause := range err.Causes {
+		collect(cause, violations)
+	}
+}
@@ -0,0 +1,80 @@
+package metaschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	if _, err := Compile(map[string]interface{}{"type": "array"}); err == nil {
+		t.Error("expected non-object schema to be rejected")
+	}
+	if _, err := Compile(map[string]interface{}{"type": "object", "properties": "nope"}); err == nil {
+		t.Error("expected invalid schema to be rejected")
+	}
+	if _, err := Compile(map[string]interface{}{"$ref": "https://example.com/schema.json"}); err == nil {
+		t.Error("expected remote reference to be rejected")
+	}
+	if _, err := Compile(map[string]interface{}{}); err != nil {
+		t.Errorf("empty schema should compile: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema, err := Compile(map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"level"},
+		"properties": map[string]interface{}{
+			"level":       map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 5},
+			"style":       map[string]interface{}{"enum": []interface{}{"chen", "yang"}},
+			"youtube_url": map[string]interface{}{"type": "string"},
+		},
+		"additionalProperties": false,
+	})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		want     []string
+	}{
+		{
+			name:     "valid",
+			metadata: map[string]interface{}{"level": float64(3), "style": "chen"},
+		},
+		{
+			name:     "go int values",
+			metadata: map[string]interface{}{"level": 2},
+		},
+		{
+			name:     "nil is validated as empty object",
+			metadata: nil,
+			want:     []string{"/: missing properties: 'level'"},
+		},
+		{
+			name:     "wrong types and extra keys",
+			metadata: map[string]interface{}{"level": 9, "style": "wudang", "color": "red"},
+			want:     []string{"/level", "/style", "color"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := schema.Validate(tt.metadata)
+			if err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if len(tt.want) == 0 && len(violations) > 0 {
+				t.Fatalf("expected no violations, got %v", violations)
+			}
+			joined := strings.Join(violations, "\n")
+			for _, w := range tt.want {
+				if !strings.Contains(joined, w) {
+					t.Errorf("violations %v missing %q", violations, w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/internal/models"
+)
+
+const (
+	// accessLogBatchSize is the most entries written in one insert
+	accessLogBatchSize = 200
+	// accessLogFlushInterval is how long an entry waits at most for its batch to fill up
+	accessLogFlushInterval = time.Second
+	// accessLogWriteTimeout bounds the insert of one batch
+	accessLogWriteTimeout = 5 * time.Second
+)
+
+// AccessLogStore stores batches of access log entries, implemented by services.UsageService
+type AccessLogStore interface {
+	RecordBatch(ctx context.Context, entries []models.AccessLog) error
+}
+
+// AccessLogWriter writes access log entries in batches from a single goroutine, so logging takes
+// at most one database connection however busy the API is. Entries are buffered in a bounded
+// channel; when it is full they are dropped and counted rather than slowing down requests.
+type AccessLogWriter struct {
+	store   AccessLogStore
+	entries chan models.AccessLog
+	dropped atomic.Int64
+
+	mu     sync.RWMutex // Guards closing entries against concurrent Record calls
+	closed bool
+	done   chan struct{}
+}
+
+// NewAccessLogWriter buffers up to bufferSize entries; call Start to begin writing them
+func NewAccessLogWriter(store AccessLogStore, bufferSize int) *AccessLogWriter {
+	return &AccessLogWriter{
+		store:   store,
+		entries: make(chan models.AccessLog, bufferSize),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start writes buffered entries in the background until Close
+func (w *AccessLogWriter) Start() {
+	go w.run()
+}
+
+// Record queues an entry without blocking. It returns false and counts the entry as dropped if
+// the buffer is full or the writer is closed.
+func (w *AccessLogWriter) Record(entry models.AccessLog) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if !w.closed {
+		select {
+		case w.entries <- entry:
+			return true
+		default:
+		}
+	}
+	w.dropped.Add(1)
+	return false
+}
+
+// Dropped returns how many entries were dropped so far
+func (w *AccessLogWriter) Dropped() int64 {
+	return w.dropped.Load()
+}
+
+// Close stops accepting entries and waits until the buffered ones are written. Call it after
+// the HTTP server has shut down.
+func (w *AccessLogWriter) Close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.entries)
+	}
+	w.mu.Unlock()
+	<-w.done
+}
+
+func (w *AccessLogWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(accessLogFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.AccessLog, 0, accessLogBatchSize)
+	var reportedDrops int64
+	flush := func() {
+		if drops := w.Dropped(); drops > reportedDrops {
+			log.Printf("[WARN] Dropped %d access log entries because the buffer was full", drops-reportedDrops)
+			reportedDrops = drops
+		}
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), accessLogWriteTimeout)
+		defer cancel()
+		if err := w.store.RecordBatch(ctx, batch); err != nil {
+			log.Printf("[WARN] Failed to record %d access log entries: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-w.entries:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) == accessLogBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// AccessLog records authenticated requests for usage statistics.
+// Must run after Auth. Entries are handed to the writer so logging never delays the response.
+func AccessLog(writer *AccessLogWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		userID, err := GetUserID(c)
+		if err != nil {
+			return
+		}
+
+		// Use the route template so /sessions/:id aggregates across IDs
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		clientIP, userAgent, device := ClientInfo(c)
+		writer.Record(models.AccessLog{
+			UserID:    userID,
+			Method:    c.Request.Method,
+			Route:     route,
+			Status:    c.Writer.Status(),
+			ClientIP:  clientIP,
+			UserAgent: userAgent,
+			Device:    device,
+			CreatedAt: time.Now().UTC(),
+		})
+	}
+}
+
+// ClientInfo returns the client IP, user agent and client-reported X-Device-Info header of the
+// request, each nil if missing
+func ClientInfo(c *gin.Context) (clientIP, userAgent, device *string) {
+	return optionalString(c.ClientIP()),
+		optionalString(c.Request.UserAgent()),
+		optionalString(truncate(c.GetHeader("X-Device-Info"), maxDeviceLength))
+}
+
+// maxDeviceLength matches the access_logs.device column
+const maxDeviceLength = 255
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
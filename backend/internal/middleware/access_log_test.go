@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+)
+
+// recordingStore keeps the batches written to it
+type recordingStore struct {
+	mu      sync.Mutex
+	batches [][]models.AccessLog
+}
+
+func (s *recordingStore) RecordBatch(ctx context.Context, entries []models.AccessLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, append([]models.AccessLog(nil), entries...))
+	return nil
+}
+
+func (s *recordingStore) entries() []models.AccessLog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var all []models.AccessLog
+	for _, batch := range s.batches {
+		all = append(all, batch...)
+	}
+	return all
+}
+
+func TestAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	store := &recordingStore{}
+	writer := NewAccessLogWriter(store, 10)
+	writer.Start()
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	authenticated := router.Group("")
+	authenticated.Use(func(c *gin.Context) {
+		// Stands in for Auth, which sets the user of valid tokens
+		if c.GetHeader("Authorization") != "" {
+			c.Set("user_id", userID.String())
+		}
+	})
+	authenticated.Use(AccessLog(writer))
+	authenticated.PUT("/api/v1/sessions/:id", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	send := func(authorized bool) {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/sessions/"+uuid.NewString(), nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		req.Header.Set("User-Agent", "XuanGong/2.1 (iOS)")
+		req.Header.Set("X-Device-Info", "iPhone 15")
+		if authorized {
+			req.Header.Set("Authorization", "Bearer token")
+		}
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	send(true)
+	send(false) // Requests without a user are not logged
+	writer.Close()
+
+	entries := store.entries()
+	if len(entries) != 1 {
+		t.Fatalf("entries = %+v, want only the authenticated request", entries)
+	}
+	entry := entries[0]
+	if entry.UserID != userID || entry.Method != http.MethodPut || entry.Route != "/api/v1/sessions/:id" || entry.Status != http.StatusCreated {
+		t.Errorf("entry = %+v, want the user's PUT of the session route template with status 201", entry)
+	}
+	if entry.ClientIP == nil || *entry.ClientIP != "203.0.113.7" ||
+		entry.UserAgent == nil || *entry.UserAgent != "XuanGong/2.1 (iOS)" ||
+		entry.Device == nil || *entry.Device != "iPhone 15" {
+		t.Errorf("client info = %v, %v, %v, want the address, user agent and device", entry.ClientIP, entry.UserAgent, entry.Device)
+	}
+	if entry.CreatedAt.IsZero() {
+		t.Error("CreatedAt is not set")
+	}
+}
+
+func TestAccessLogWriter_DropsWhenFull(t *testing.T) {
+	store := &recordingStore{}
+	writer := NewAccessLogWriter(store, 2)
+
+	// Nothing is written before Start, so the buffer fills up
+	for i := 0; i < 5; i++ {
+		queued := writer.Record(models.AccessLog{UserID: uuid.New(), Status: i})
+		if want := i < 2; queued != want {
+			t.Errorf("Record(%d) = %v, want %v", i, queued, want)
+		}
+	}
+	if dropped := writer.Dropped(); dropped != 3 {
+		t.Errorf("Dropped() = %d, want 3", dropped)
+	}
+
+	writer.Start()
+	writer.Close()
+	if entries := store.entries(); len(entries) != 2 || entries[0].Status != 0 || entries[1].Status != 1 {
+		t.Errorf("entries = %+v, want the two buffered entries", entries)
+	}
+
+	if writer.Record(models.AccessLog{UserID: uuid.New()}) || writer.Dropped() != 4 {
+		t.Errorf("Record after Close was queued or not counted as dropped")
+	}
+}
+
+func TestAccessLogWriter_WritesInBatches(t *testing.T) {
+	store := &recordingStore{}
+	writer := NewAccessLogWriter(store, 1000)
+	for i := 0; i < 450; i++ {
+		writer.Record(models.AccessLog{UserID: uuid.New(), Status: i})
+	}
+	writer.Start()
+	writer.Close()
+
+	entries := store.entries()
+	if len(entries) != 450 {
+		t.Fatalf("wrote %d entries, want all 450 drained on Close", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Status != i {
+			t.Fatalf("entry %d has status %d, want the entries in order", i, entry.Status)
+		}
+	}
+	for _, batch := range store.batches {
+		if len(batch) > accessLogBatchSize {
+			t.Errorf("batch of %d entries, want at most %d", len(batch), accessLogBatchSize)
+		}
+	}
+}
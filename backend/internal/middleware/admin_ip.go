@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"log"
+	"net/netip"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/internal/config"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// AdminIPAllowlist refuses admin requests from outside the configured networks, as a second line
+// of defense behind the admin role check. Refused requests are logged with the user and route;
+// in report-only mode they are logged and let through. Without a list every network is allowed.
+func AdminIPAllowlist(cfg *config.AdminAccessConfig) gin.HandlerFunc {
+	networks := cfg.GetAllowedNetworks()
+	if len(networks) == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		if addr, err := netip.ParseAddr(c.ClientIP()); err == nil && containsAddr(networks, addr.Unmap()) {
+			c.Next()
+			return
+		}
+
+		userID, _ := c.Get("user_id")
+		if cfg.ReportOnly {
+			log.Printf("[WARN] Admin request from %s outside ADMIN_IP_ALLOWLIST (report only): user=%v %s %s", c.ClientIP(), userID, c.Request.Method, c.FullPath())
+			c.Next()
+			return
+		}
+		log.Printf("[WARN] Blocked admin request from %s outside ADMIN_IP_ALLOWLIST: user=%v %s %s", c.ClientIP(), userID, c.Request.Method, c.FullPath())
+		respondWithError(c, appErrors.NewAuthorizationError("Admin access is not allowed from this network"))
+	}
+}
+
+func containsAddr(networks []netip.Prefix, addr netip.Addr) bool {
+	for _, network := range networks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
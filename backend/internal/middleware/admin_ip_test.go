@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/internal/config"
+)
+
+func TestAdminIPAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		cfg            config.AdminAccessConfig
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		wantStatus     int
+	}{
+		{
+			name:       "address_in_cidr_allowed",
+			cfg:        config.AdminAccessConfig{AllowedNetworks: []string{"10.0.0.0/8"}},
+			remoteAddr: "10.1.2.3:4000",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "single_ip_allowed",
+			cfg:        config.AdminAccessConfig{AllowedNetworks: []string{"10.0.0.0/8", "203.0.113.7"}},
+			remoteAddr: "203.0.113.7:4000",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "address_outside_blocked",
+			cfg:        config.AdminAccessConfig{AllowedNetworks: []string{"10.0.0.0/8", "203.0.113.7"}},
+			remoteAddr: "203.0.113.8:4000",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "ipv4_mapped_ipv6_matches_ipv4_network",
+			cfg:        config.AdminAccessConfig{AllowedNetworks: []string{"10.0.0.0/8"}},
+			remoteAddr: "[::ffff:10.1.2.3]:4000",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "ipv6_network",
+			cfg:        config.AdminAccessConfig{AllowedNetworks: []string{"2001:db8::/32"}},
+			remoteAddr: "[2001:db8::1]:4000",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:         "spoofed_forwarded_for_blocked",
+			cfg:          config.AdminAccessConfig{AllowedNetworks: []string{"10.0.0.0/8"}},
+			remoteAddr:   "198.51.100.20:4000",
+			forwardedFor: "10.1.2.3",
+			wantStatus:   http.StatusForbidden,
+		},
+		{
+			name:           "forwarded_for_from_trusted_proxy_allowed",
+			cfg:            config.AdminAccessConfig{AllowedNetworks: []string{"10.0.0.0/8"}},
+			trustedProxies: []string{"192.0.2.1"},
+			remoteAddr:     "192.0.2.1:4000",
+			forwardedFor:   "10.1.2.3",
+			wantStatus:     http.StatusOK,
+		},
+		{
+			name:       "report_only_lets_outside_address_through",
+			cfg:        config.AdminAccessConfig{AllowedNetworks: []string{"10.0.0.0/8"}, ReportOnly: true},
+			remoteAddr: "198.51.100.20:4000",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "no_list_allows_everyone",
+			remoteAddr: "198.51.100.20:4000",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Trusts the proxies the way the router does, none unless configured
+			router := gin.New()
+			if err := router.SetTrustedProxies(tt.trustedProxies); err != nil {
+				t.Fatal(err)
+			}
+			router.GET("/admin", AdminIPAllowlist(&tt.cfg), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
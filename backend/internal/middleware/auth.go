@@ -25,7 +25,7 @@ func Auth(authService *services.AuthService) gin.HandlerFunc {
 			return
 		}
 
-		claims, err := authService.ValidateAccessToken(token)
+		claims, err := authService.ValidateAccessToken(c.Request.Context(), token)
 		if err != nil {
 			respondWithError(c, appErrors.NewAuthenticationError("Invalid or expired token"))
 			return
@@ -35,6 +35,7 @@ func Auth(authService *services.AuthService) gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_role", claims.Role)
+		c.Set("device_id", claims.DeviceID)
 
 		c.Next()
 	}
@@ -73,6 +74,11 @@ func GetUserID(c *gin.Context) (uuid.UUID, error) {
 	return userID, nil
 }
 
+// GetDeviceID returns the login device the request's token was issued to, or "" if it has none
+func GetDeviceID(c *gin.Context) string {
+	return c.GetString("device_id")
+}
+
 // GetUserRole extracts user role from context
 func GetUserRole(c *gin.Context) (string, error) {
 	userRole, exists := c.Get("user_role")
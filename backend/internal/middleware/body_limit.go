@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+const bodyTooLargeKey = "body_too_large"
+
+// BodySizeLimit rejects request bodies larger than maxBytes, or maxUploadBytes for multipart uploads.
+// Bodies without a Content-Length are cut off while reading; see BodyTooLarge.
+func BodySizeLimit(maxBytes, maxUploadBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := maxBytes
+		if strings.HasPrefix(c.ContentType(), "multipart/") {
+			limit = maxUploadBytes
+		}
+
+		if c.Request.ContentLength > limit {
+			respondWithError(c, appErrors.NewPayloadTooLargeError(limit))
+			return
+		}
+
+		if c.Request.Body != nil {
+			c.Request.Body = &limitedBody{
+				ReadCloser: http.MaxBytesReader(c.Writer, c.Request.Body, limit),
+				c:          c,
+			}
+		}
+		c.Next()
+	}
+}
+
+// BodyTooLarge reports whether reading the request body hit the BodySizeLimit, and the limit that applied.
+// Handlers use it to turn the resulting decode error into a 413 response.
+func BodyTooLarge(c *gin.Context) (int64, bool) {
+	limit, ok := c.Get(bodyTooLargeKey)
+	if !ok {
+		return 0, false
+	}
+	return limit.(int64), true
+}
+
+// limitedBody records on the context when the underlying MaxBytesReader trips
+type limitedBody struct {
+	io.ReadCloser
+	c *gin.Context
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		b.c.Set(bodyTooLargeKey, maxErr.Limit)
+	}
+	return n, err
+}
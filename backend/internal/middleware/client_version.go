@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/internal/services"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// ClientVersion refuses requests from app versions older than the oldest still supported on
+// their platform with 426 UPGRADE_REQUIRED and the upgrade URL. Clients identify themselves with
+// X-App-Version and X-App-Platform; requests without them, such as from scripts, are let through.
+func ClientVersion(clientVersionService *services.ClientVersionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := c.GetHeader("X-App-Version")
+		platform := strings.ToLower(strings.TrimSpace(c.GetHeader("X-App-Platform")))
+		if version == "" || platform == "" {
+			c.Next()
+			return
+		}
+
+		if err := clientVersionService.Check(c.Request.Context(), platform, version); err != nil {
+			var appErr *appErrors.AppError
+			if !errors.As(err, &appErr) {
+				appErr = appErrors.NewInternalError("Failed to check client version")
+			}
+			respondWithError(c, appErr)
+			return
+		}
+		c.Next()
+	}
+}
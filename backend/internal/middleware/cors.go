@@ -65,3 +65,15 @@ func joinStrings(slice []string) string {
 	}
 	return result
 }
+
+// OpenCORS lets any site read the responses of public, embeddable endpoints. It overrides the
+// global CORS headers and never allows credentials, so browsers send no cookies of the embedding site.
+func OpenCORS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("Access-Control-Allow-Origin", "*")
+		header.Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		header.Del("Access-Control-Allow-Credentials")
+		c.Next()
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/pkg/auth"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// DisplayAuth validates read-only display tokens, sent as a Bearer token or, for kiosk
+// browsers that can only open a URL, as the token query parameter
+func DisplayAuth(displayService *services.DisplayService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			var err error
+			token, err = auth.ExtractTokenFromHeader(authHeader)
+			if err != nil {
+				respondWithError(c, appErrors.NewAuthenticationError("Invalid authorization header format"))
+				return
+			}
+		}
+		if token == "" {
+			respondWithError(c, appErrors.NewAuthenticationError("Display token required"))
+			return
+		}
+
+		display, err := displayService.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			var appErr *appErrors.AppError
+			if !errors.As(err, &appErr) {
+				appErr = appErrors.NewInternalError("Failed to check display token")
+			}
+			respondWithError(c, appErr)
+			return
+		}
+
+		c.Set("display_token", display)
+		c.Next()
+	}
+}
+
+// GetDisplayToken extracts the display token authenticated by DisplayAuth from context
+func GetDisplayToken(c *gin.Context) (*models.DisplayToken, error) {
+	display, exists := c.Get("display_token")
+	if !exists {
+		return nil, appErrors.NewAuthenticationError("Display not authenticated")
+	}
+	return display.(*models.DisplayToken), nil
+}
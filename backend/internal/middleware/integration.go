@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/internal/services"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// maxNonceLength matches the integration_nonces column
+const maxNonceLength = 100
+
+// IntegrationAuth validates HMAC-signed requests from server-to-server integrations. The key ID,
+// Unix timestamp, a unique nonce and the signature are sent as X-Integration-Key, X-Timestamp,
+// X-Nonce and X-Signature.
+func IntegrationAuth(integrationService *services.IntegrationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := services.SignedRequest{
+			KeyID:      c.GetHeader("X-Integration-Key"),
+			Timestamp:  c.GetHeader("X-Timestamp"),
+			Nonce:      c.GetHeader("X-Nonce"),
+			Signature:  c.GetHeader("X-Signature"),
+			Method:     c.Request.Method,
+			RequestURI: c.Request.URL.RequestURI(),
+		}
+		if req.KeyID == "" || req.Timestamp == "" || req.Nonce == "" || req.Signature == "" {
+			respondWithError(c, appErrors.NewAuthenticationError("Signed request required: X-Integration-Key, X-Timestamp, X-Nonce and X-Signature"))
+			return
+		}
+		if len(req.Nonce) > maxNonceLength {
+			respondWithError(c, appErrors.NewAuthenticationError("X-Nonce is too long"))
+			return
+		}
+
+		// The body is part of the signature; put it back for the handler
+		if c.Request.Body != nil {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				respondWithError(c, appErrors.NewBadRequestError("Failed to read request body"))
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			req.Body = body
+		}
+
+		if _, err := integrationService.Authenticate(c.Request.Context(), req); err != nil {
+			var appErr *appErrors.AppError
+			if !errors.As(err, &appErr) {
+				appErr = appErrors.NewInternalError("Failed to check request signature")
+			}
+			respondWithError(c, appErr)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/internal/i18n"
+)
+
+// Locale negotiates the content locale from the Accept-Language header
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Set("locale", locale)
+		c.Header("Content-Language", locale)
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// GetLocale returns the negotiated content locale, or the default if Locale did not run
+func GetLocale(c *gin.Context) string {
+	if locale, ok := c.Get("locale"); ok {
+		return locale.(string)
+	}
+	return i18n.DefaultLocale
+}
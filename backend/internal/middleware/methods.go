@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AllowHeader completes the Allow header gin sets when a path exists under other methods:
+// GET routes also answer HEAD, and every route answers OPTIONS
+func AllowHeader() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		if allow := header.Get("Allow"); allow != "" {
+			methods := strings.Split(allow, ", ")
+			if slices.Contains(methods, http.MethodGet) && !slices.Contains(methods, http.MethodHead) {
+				methods = append(methods, http.MethodHead)
+			}
+			if !slices.Contains(methods, http.MethodOptions) {
+				methods = append(methods, http.MethodOptions)
+			}
+			header.Set("Allow", strings.Join(methods, ", "))
+		}
+		c.Next()
+	}
+}
+
+// HeadAsGet serves HEAD requests with the GET route of the path. The server still sees the
+// original HEAD request and drops the response body, so handlers don't need to know.
+func HeadAsGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			r = r.Clone(r.Context())
+			r.Method = http.MethodGet
+		}
+		next.ServeHTTP(w, r)
+	})
+}
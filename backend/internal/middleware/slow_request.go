@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/internal/diagnostics"
+)
+
+// SlowRequests records the latency of every routed request in stats and logs requests slower
+// than threshold (0 disables logging). The log uses the route template and redacts query values,
+// so IDs, emails and tokens in the URL do not end up in the logs.
+func SlowRequests(stats *diagnostics.EndpointStats, threshold time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		// Unmatched paths are skipped so random URLs cannot grow the stats without bound
+		route := c.FullPath()
+		if route == "" {
+			return
+		}
+
+		latency := time.Since(start)
+		endpoint := c.Request.Method + " " + route
+		stats.Record(endpoint, latency)
+
+		if threshold > 0 && latency >= threshold {
+			log.Printf("[WARN] Slow request: %s%s status=%d latency_ms=%d",
+				endpoint, redactQuery(c.Request.URL.Query()), c.Writer.Status(), latency.Milliseconds())
+		}
+	}
+}
+
+// redactQuery keeps the parameter names and replaces their values
+func redactQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key+"=REDACTED")
+	}
+	sort.Strings(keys)
+	return "?" + strings.Join(keys, "&")
+}
@@ -0,0 +1,52 @@
+package models
+
+import "github.com/google/uuid"
+
+type AssignmentStatus string
+
+const (
+	AssignmentAssigned        AssignmentStatus = "assigned"
+	AssignmentAlreadyAssigned AssignmentStatus = "already_assigned"
+	AssignmentDuplicate       AssignmentStatus = "duplicate"
+	AssignmentNotFound        AssignmentStatus = "not_found"
+	AssignmentInvited         AssignmentStatus = "invited"
+	AssignmentInvalid         AssignmentStatus = "invalid"
+	AssignmentFailed          AssignmentStatus = "failed"
+)
+
+// AssignmentTarget identifies a user to assign by user ID or email.
+// Row is the 1-based position in the request or CSV file, echoed back in the report.
+type AssignmentTarget struct {
+	Row        int
+	Identifier string
+}
+
+// WelcomeThread is a submission thread opened for each newly assigned user, starting with a
+// message from the assigning instructor
+type WelcomeThread struct {
+	Title   string // Defaults to "Welcome to <program name>"
+	Message string
+}
+
+// AssignmentResult is the outcome for a single target of a bulk assignment
+type AssignmentResult struct {
+	Row        int              `json:"row"`
+	Identifier string           `json:"identifier"`
+	Email      string           `json:"email,omitempty"`
+	UserID     *uuid.UUID       `json:"user_id,omitempty"`
+	Status     AssignmentStatus `json:"status"`
+	Message    string           `json:"message,omitempty"`
+	// Invitation is set when an unknown email was invited instead of assigned
+	Invitation *Invitation `json:"invitation,omitempty"`
+	// SubmissionID is the welcome thread opened for the newly assigned user
+	SubmissionID *uuid.UUID `json:"submission_id,omitempty"`
+}
+
+// AssignmentReport summarizes a bulk assignment with per-row results
+type AssignmentReport struct {
+	Assigned int                `json:"assigned"`
+	Invited  int                `json:"invited"`
+	Skipped  int                `json:"skipped"`
+	Failed   int                `json:"failed"`
+	Results  []AssignmentResult `json:"results"`
+}
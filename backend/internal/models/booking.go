@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AvailabilitySlot is a time an instructor offers for a 1:1 video review
+type AvailabilitySlot struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	InstructorID   uuid.UUID `json:"instructor_id" db:"instructor_id"`
+	InstructorName string    `json:"instructor_name" db:"instructor_name"`
+	StartsAt       time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt         time.Time `json:"ends_at" db:"ends_at"`
+	IsBooked       bool      `json:"is_booked" db:"is_booked"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+type BookingStatus string
+
+const (
+	BookingConfirmed BookingStatus = "confirmed"
+	BookingCancelled BookingStatus = "cancelled"
+)
+
+// Booking is a student's reservation of an availability slot for one of their programs
+type Booking struct {
+	ID              uuid.UUID     `json:"id" db:"id"`
+	SlotID          uuid.UUID     `json:"slot_id" db:"slot_id"`
+	StudentID       uuid.UUID     `json:"student_id" db:"student_id"`
+	StudentName     string        `json:"student_name" db:"student_name"`
+	StudentEmail    string        `json:"-" db:"student_email"`
+	InstructorID    uuid.UUID     `json:"instructor_id" db:"instructor_id"`
+	InstructorName  string        `json:"instructor_name" db:"instructor_name"`
+	InstructorEmail string        `json:"-" db:"instructor_email"`
+	ProgramID       uuid.UUID     `json:"program_id" db:"program_id"`
+	ProgramName     string        `json:"program_name" db:"program_name"`
+	StartsAt        time.Time     `json:"starts_at" db:"starts_at"`
+	EndsAt          time.Time     `json:"ends_at" db:"ends_at"`
+	Note            *string       `json:"note,omitempty" db:"note"`
+	Status          BookingStatus `json:"status" db:"status"`
+	CancelledBy     *uuid.UUID    `json:"cancelled_by,omitempty" db:"cancelled_by"`
+	CancelReason    *string       `json:"cancel_reason,omitempty" db:"cancel_reason"`
+	CancelledAt     *time.Time    `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	MeetingProvider *string       `json:"meeting_provider,omitempty" db:"meeting_provider"`
+	MeetingID       *string       `json:"-" db:"meeting_id"`
+	MeetingURL      *string       `json:"meeting_url,omitempty" db:"meeting_url"` // Join link for the video review
+	CreatedAt       time.Time     `json:"created_at" db:"created_at"`
+}
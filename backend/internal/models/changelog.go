@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ChangelogAudience string
+
+const (
+	ChangelogAll      ChangelogAudience = "all"
+	ChangelogStudents ChangelogAudience = "students"
+	ChangelogAdmins   ChangelogAudience = "admins"
+)
+
+// ChangelogEntry is a release note for the apps, written in Markdown
+type ChangelogEntry struct {
+	ID           uuid.UUID         `json:"id" db:"id"`
+	Version      string            `json:"version" db:"version"` // App version the note belongs to, e.g. 2.4.0
+	Title        string            `json:"title" db:"title"`
+	Body         string            `json:"body" db:"body"`
+	RenderedHTML string            `json:"rendered_html" db:"-"` // sanitized HTML rendered from Body
+	Audience     ChangelogAudience `json:"audience" db:"audience"`
+	CreatedBy    *uuid.UUID        `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// ChangelogEntryUpdate holds changes to a changelog entry. Nil fields are left unchanged.
+type ChangelogEntryUpdate struct {
+	Version  *string
+	Title    *string
+	Body     *string
+	Audience *ChangelogAudience
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClientPlatforms are the platforms clients report in X-App-Platform
+var ClientPlatforms = []string{"ios", "android", "web"}
+
+// ClientVersionPolicy is the oldest app version still supported on a platform
+type ClientVersionPolicy struct {
+	Platform   string     `json:"platform" db:"platform"`
+	MinVersion string     `json:"min_version" db:"min_version"` // Empty: every version is supported
+	UpgradeURL string     `json:"upgrade_url" db:"upgrade_url"`
+	Source     string     `json:"source" db:"-"` // "config", or "admin" when overridden at runtime
+	UpdatedBy  *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
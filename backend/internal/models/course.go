@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UnlockRule decides when a course module opens for an enrolled student
+type UnlockRule string
+
+const (
+	UnlockImmediate     UnlockRule = "immediate"      // open on enrollment
+	UnlockAfterPrevious UnlockRule = "after_previous" // open once all earlier modules are complete
+	UnlockAfterDays     UnlockRule = "after_days"     // open UnlockAfterDays days after enrollment
+)
+
+// Course groups programs into an ordered curriculum of modules
+type Course struct {
+	ID            uuid.UUID      `json:"id" db:"id"`
+	Name          string         `json:"name" db:"name"`
+	Description   *string        `json:"description,omitempty" db:"description"`
+	IsPublished   bool           `json:"is_published" db:"is_published"`
+	CreatedBy     *uuid.UUID     `json:"created_by,omitempty" db:"created_by"`
+	ModuleCount   int            `json:"module_count" db:"module_count"`
+	EnrolledCount int            `json:"enrolled_count" db:"enrolled_count"`
+	Modules       []CourseModule `json:"modules,omitempty" db:"-"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// CourseModule is one step of a course, holding the programs to practice in it
+type CourseModule struct {
+	ID              uuid.UUID       `json:"id" db:"id"`
+	Position        int             `json:"position" db:"position"`
+	Title           string          `json:"title" db:"title"`
+	Description     *string         `json:"description,omitempty" db:"description"`
+	UnlockRule      UnlockRule      `json:"unlock_rule" db:"unlock_rule"`
+	UnlockAfterDays *int            `json:"unlock_after_days,omitempty" db:"unlock_after_days"`
+	Programs        []ModuleProgram `json:"programs" db:"-"`
+}
+
+// ModuleProgram is a program in a module and how many completed sessions count it as done
+type ModuleProgram struct {
+	ProgramID        uuid.UUID `json:"program_id" db:"program_id"`
+	ProgramName      string    `json:"program_name" db:"program_name"`
+	RequiredSessions int       `json:"required_sessions" db:"required_sessions"`
+}
+
+// CourseEnrollment is a student's enrollment in a course
+type CourseEnrollment struct {
+	CourseID   uuid.UUID  `json:"course_id" db:"course_id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	UserName   string     `json:"user_name" db:"user_name"`
+	EnrolledBy *uuid.UUID `json:"enrolled_by,omitempty" db:"enrolled_by"`
+	EnrolledAt time.Time  `json:"enrolled_at" db:"enrolled_at"`
+}
+
+// CourseProgress is a student's progress through a course, computed from completed practice sessions
+type CourseProgress struct {
+	CourseID   uuid.UUID        `json:"course_id"`
+	UserID     uuid.UUID        `json:"user_id"`
+	EnrolledAt time.Time        `json:"enrolled_at"`
+	Percent    float64          `json:"percent"` // 0-100, completed sessions and passed quizzes over required ones
+	Completed  bool             `json:"completed"`
+	Modules    []ModuleProgress `json:"modules"`
+}
+
+type ModuleProgress struct {
+	ModuleID  uuid.UUID         `json:"module_id"`
+	Position  int               `json:"position"`
+	Title     string            `json:"title"`
+	Unlocked  bool              `json:"unlocked"`
+	UnlocksAt *time.Time        `json:"unlocks_at,omitempty"` // Set for after_days modules that are still locked
+	Completed bool              `json:"completed"`
+	Programs  []ProgramProgress `json:"programs"`
+}
+
+type ProgramProgress struct {
+	ProgramID         uuid.UUID `json:"program_id"`
+	ProgramName       string    `json:"program_name"`
+	RequiredSessions  int       `json:"required_sessions"`
+	CompletedSessions int       `json:"completed_sessions"`
+	QuizCount         int       `json:"quiz_count"` // Quizzes attached to the program, all of which must be passed
+	QuizzesPassed     int       `json:"quizzes_passed"`
+	Completed         bool      `json:"completed"`
+}
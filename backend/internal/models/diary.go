@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DiaryEntry is a student's free-form reflection on a day of practice, written in Markdown.
+// Entries are private unless shared, which makes them readable by instructors.
+type DiaryEntry struct {
+	ID           uuid.UUID   `json:"id" db:"id"`
+	UserID       uuid.UUID   `json:"user_id" db:"user_id"`
+	EntryDate    string      `json:"entry_date" db:"entry_date"` // YYYY-MM-DD
+	Title        string      `json:"title" db:"title"`
+	Content      string      `json:"content" db:"content"`
+	RenderedHTML string      `json:"rendered_html" db:"-"` // sanitized HTML rendered from Content
+	SessionIDs   []uuid.UUID `json:"session_ids" db:"-"`   // Practice sessions the entry reflects on
+	Shared       bool        `json:"shared" db:"shared"`
+	CreatedAt    time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// DiarySearchResult is a diary entry matching a search, with the matching passage highlighted
+type DiarySearchResult struct {
+	DiaryEntry
+	TitleHighlight string  `json:"title_highlight" db:"title_highlight"`
+	Snippet        string  `json:"snippet" db:"snippet"`
+	Rank           float64 `json:"rank" db:"rank"`
+}
+
+// DiaryEntryUpdate holds changes to a diary entry. Nil fields are left unchanged.
+type DiaryEntryUpdate struct {
+	EntryDate  *string
+	Title      *string
+	Content    *string
+	SessionIDs *[]uuid.UUID
+	Shared     *bool
+}
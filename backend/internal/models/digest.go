@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationPreferences controls the notifications a user receives outside the app
+type NotificationPreferences struct {
+	WeeklyDigest bool `json:"weekly_digest" db:"weekly_digest"`
+	// Timezone is the IANA zone the digest send window is evaluated in
+	Timezone     string     `json:"timezone" db:"timezone"`
+	LastDigestAt *time.Time `json:"last_digest_at,omitempty" db:"last_digest_at"`
+}
+
+// WeeklyDigest summarizes a user's past week of practice for the weekly email
+type WeeklyDigest struct {
+	UserID            uuid.UUID `json:"user_id"`
+	FullName          string    `json:"full_name"`
+	Language          string    `json:"language"`
+	Timezone          string    `json:"timezone"`
+	PeriodStart       time.Time `json:"period_start"`
+	PeriodEnd         time.Time `json:"period_end"`
+	SessionsCompleted int       `json:"sessions_completed"`
+	PracticeMinutes   int       `json:"practice_minutes"`
+	CurrentStreak     int       `json:"current_streak"`
+	LongestStreak     int       `json:"longest_streak"`
+	// UnreadFeedback counts unread messages in the user's submission threads
+	UnreadFeedback int `json:"unread_feedback"`
+	// UpcomingHomework is unfinished homework due within the next week, soonest first
+	UpcomingHomework []Homework `json:"upcoming_homework"`
+}
+
+// IsEmpty reports whether there is nothing worth emailing
+func (d *WeeklyDigest) IsEmpty() bool {
+	return d.SessionsCompleted == 0 && d.CurrentStreak == 0 && d.UnreadFeedback == 0 && len(d.UpcomingHomework) == 0
+}
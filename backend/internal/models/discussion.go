@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DiscussionTopic is a thread on a program's discussion board
+type DiscussionTopic struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	ProgramID      uuid.UUID `json:"program_id" db:"program_id"`
+	AuthorID       uuid.UUID `json:"author_id" db:"author_id"`
+	AuthorName     string    `json:"author_name" db:"author_name"`
+	AuthorRole     UserRole  `json:"author_role" db:"author_role"`
+	Title          string    `json:"title" db:"title"`
+	Content        string    `json:"content" db:"content"`
+	YouTubeURL     *string   `json:"youtube_url,omitempty" db:"youtube_url"`
+	IsPinned       bool      `json:"is_pinned" db:"is_pinned"`
+	IsLocked       bool      `json:"is_locked" db:"is_locked"` // Only admins can reply
+	ReplyCount     int       `json:"reply_count" db:"reply_count"`
+	LastActivityAt time.Time `json:"last_activity_at" db:"last_activity_at"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// DiscussionReply is an answer to a discussion topic
+type DiscussionReply struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	TopicID    uuid.UUID `json:"topic_id" db:"topic_id"`
+	AuthorID   uuid.UUID `json:"author_id" db:"author_id"`
+	AuthorName string    `json:"author_name" db:"author_name"`
+	AuthorRole UserRole  `json:"author_role" db:"author_role"`
+	Content    string    `json:"content" db:"content"`
+	YouTubeURL *string   `json:"youtube_url,omitempty" db:"youtube_url"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// DiscussionTopicWithReplies is a topic with its replies, oldest first
+type DiscussionTopicWithReplies struct {
+	DiscussionTopic
+	Replies []DiscussionReply `json:"replies"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DisplayToken is a read-only credential for a shared screen, scoped to a set of programs.
+// The token is only exposed once, when it is created.
+type DisplayToken struct {
+	ID         uuid.UUID   `json:"id" db:"id"`
+	Token      string      `json:"token,omitempty"`
+	TokenHash  string      `json:"-" db:"token_hash"`
+	Name       string      `json:"name" db:"name"`
+	ProgramIDs []uuid.UUID `json:"program_ids" db:"program_ids"`
+	CreatedBy  *uuid.UUID  `json:"created_by,omitempty" db:"created_by"`
+	ExpiresAt  *time.Time  `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt *time.Time  `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time  `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+}
+
+// DisplayProgram is what a display shows of a program: no owner, students or progress,
+// only what is needed to run the routine timer. Timeline is omitted in lists.
+type DisplayProgram struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Timeline    *Timeline `json:"timeline,omitempty"`
+}
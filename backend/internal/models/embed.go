@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// ProgramPreview is the summary of a shared program shown in embedded widgets
+type ProgramPreview struct {
+	Name                 string            `json:"name"`
+	Description          string            `json:"description"`
+	Tags                 []string          `json:"tags"`
+	CoverURL             *string           `json:"cover_url,omitempty"`
+	CoverThumbnails      map[string]string `json:"cover_thumbnails,omitempty"`
+	ExerciseCount        int               `json:"exercise_count"`
+	TotalDurationSeconds int               `json:"total_duration_seconds"`
+	// URL links to the full shared program, if a share page is configured
+	URL       string    `json:"url,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// OEmbed is an oEmbed 1.0 "rich" response embedding the program preview widget in an iframe
+type OEmbed struct {
+	Type         string `json:"type"`
+	Version      string `json:"version"`
+	Title        string `json:"title"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url,omitempty"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	CacheAge     int    `json:"cache_age,omitempty"`
+}
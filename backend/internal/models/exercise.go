@@ -18,7 +18,8 @@ type Exercise struct {
 	ID                  uuid.UUID              `json:"id" db:"id"`
 	ProgramID           uuid.UUID              `json:"program_id" db:"program_id"`
 	Name                string                 `json:"name" db:"name"`
-	Description         string                 `json:"description" db:"description"`
+	Description         string                 `json:"description" db:"description"` // Markdown source
+	RenderedHTML        string                 `json:"rendered_html" db:"-"`         // sanitized HTML rendered from Description
 	OrderIndex          int                    `json:"order_index" db:"order_index"`
 	ExerciseType        ExerciseType           `json:"exercise_type" db:"exercise_type"`
 	DurationSeconds     *int                   `json:"duration_seconds" db:"duration_seconds"`
@@ -26,6 +27,53 @@ type Exercise struct {
 	RestAfterSeconds    int                    `json:"rest_after_seconds" db:"rest_after_seconds"`
 	HasSides            bool                   `json:"has_sides" db:"has_sides"`
 	SideDurationSeconds *int                   `json:"side_duration_seconds" db:"side_duration_seconds"`
+	Tempo               *ExerciseTempo         `json:"tempo" db:"tempo"` // Only for exercises with repetitions
+	BreathingPattern    *BreathingPattern      `json:"breathing_pattern" db:"breathing_pattern"`
+	Contraindications   []string               `json:"contraindications" db:"contraindications"` // Limitations the exercise is unsafe with
 	Metadata            map[string]interface{} `json:"metadata" db:"metadata"`
 	CreatedAt           time.Time              `json:"created_at" db:"created_at"`
+	// Substitutes are filled in when exercises are fetched
+	Substitutes []ExerciseSubstitute `json:"substitutes,omitempty" db:"-"`
+	// Adjustment is set when the exercise was substituted or flagged for a student's limitations
+	Adjustment *ExerciseAdjustment `json:"adjustment,omitempty" db:"-"`
+}
+
+// ExerciseSubstitute is a variant students can do instead of an exercise, such as a seated
+// version for knee injuries. Timing left unset is taken from the exercise.
+type ExerciseSubstitute struct {
+	ID                  uuid.UUID `json:"id" db:"id"`
+	ExerciseID          uuid.UUID `json:"exercise_id" db:"exercise_id"`
+	Name                string    `json:"name" db:"name"`
+	Description         string    `json:"description" db:"description"` // Markdown source
+	RenderedHTML        string    `json:"rendered_html" db:"-"`
+	Reason              *string   `json:"reason,omitempty" db:"reason"` // Who it is for, e.g. "Knee injuries"
+	DurationSeconds     *int      `json:"duration_seconds,omitempty" db:"duration_seconds"`
+	Repetitions         *int      `json:"repetitions,omitempty" db:"repetitions"`
+	SideDurationSeconds *int      `json:"side_duration_seconds,omitempty" db:"side_duration_seconds"`
+	Contraindications   []string  `json:"contraindications" db:"contraindications"`
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ExerciseTempo paces a repetition exercise. With SecondsPerRep set, the exercise is timed on the
+// program timeline as its repetitions plus the pauses between them, and the client metronome
+// ticks at that rate.
+type ExerciseTempo struct {
+	SecondsPerRep     int `json:"seconds_per_rep,omitempty"`
+	PrepSeconds       int `json:"prep_seconds,omitempty"`       // Before the first repetition
+	TransitionSeconds int `json:"transition_seconds,omitempty"` // Between repetitions
+}
+
+// BreathingPattern defines an exercise by breath counts. Without a duration, repetitions tempo or
+// sides, the exercise is timed on the program timeline as Cycles full breaths.
+type BreathingPattern struct {
+	InhaleSeconds int `json:"inhale_seconds"`
+	HoldSeconds   int `json:"hold_seconds"` // After inhaling
+	ExhaleSeconds int `json:"exhale_seconds"`
+	Cycles        int `json:"cycles,omitempty"`
+}
+
+// CycleSeconds is the length of one full breath
+func (p BreathingPattern) CycleSeconds() int {
+	return p.InhaleSeconds + p.HoldSeconds + p.ExhaleSeconds
 }
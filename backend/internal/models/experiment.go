@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Experiment variants
+const (
+	VariantA = "a"
+	VariantB = "b"
+)
+
+// ProgramExperiment compares two variants of a program, e.g. with different rest times, by
+// assigning each student one of them at random
+type ProgramExperiment struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	Name         string     `json:"name" db:"name"`
+	Description  *string    `json:"description,omitempty" db:"description"`
+	ProgramAID   uuid.UUID  `json:"program_a_id" db:"program_a_id"`
+	ProgramBID   uuid.UUID  `json:"program_b_id" db:"program_b_id"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	Participants int        `json:"participants"`
+}
+
+// ProgramID returns the program of the variant
+func (e *ProgramExperiment) ProgramID(variant string) uuid.UUID {
+	if variant == VariantB {
+		return e.ProgramBID
+	}
+	return e.ProgramAID
+}
+
+// ExperimentParticipant is a student's variant in an experiment
+type ExperimentParticipant struct {
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	Variant    string    `json:"variant" db:"variant"`
+	ProgramID  uuid.UUID `json:"program_id"` // The variant's program, which the student is assigned
+	AssignedAt time.Time `json:"assigned_at" db:"assigned_at"`
+}
+
+// ExperimentResults compares an experiment's variants
+type ExperimentResults struct {
+	ExperimentID uuid.UUID       `json:"experiment_id"`
+	Variants     []VariantResult `json:"variants"`
+}
+
+// VariantResult is the completion and adherence of one variant, from the sessions its
+// participants practiced it in
+type VariantResult struct {
+	Variant            string    `json:"variant"`
+	ProgramID          uuid.UUID `json:"program_id"`
+	Participants       int       `json:"participants"`
+	ActiveParticipants int       `json:"active_participants"` // Participants who completed a session of it
+	SessionsStarted    int       `json:"sessions_started"`
+	SessionsCompleted  int       `json:"sessions_completed"`
+	// Average completion_rate of the completed sessions; nil before the first one
+	AverageCompletionRate *float64 `json:"average_completion_rate,omitempty"`
+	// Adherence: completed sessions per participant and week since they were assigned, counting
+	// at least one week
+	SessionsPerWeek float64 `json:"sessions_per_week"`
+}
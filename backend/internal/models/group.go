@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Group is a named set of students, such as a class or cohort
+type Group struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	Description *string    `json:"description,omitempty" db:"description"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	MemberCount int        `json:"member_count" db:"member_count"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// GroupStats sums up the practice of a group's members over a window, for reviewing the class
+type GroupStats struct {
+	GroupID   uuid.UUID          `json:"group_id"`
+	GroupName string             `json:"group_name"`
+	From      time.Time          `json:"from"`
+	To        time.Time          `json:"to"`
+	Summary   GroupStatsSummary  `json:"summary"`
+	Members   []GroupMemberStats `json:"members"`
+}
+
+// GroupStatsSummary sums up the whole group in a stats window
+type GroupStatsSummary struct {
+	Members           int `json:"members"`
+	ActiveMembers     int `json:"active_members"` // Members who completed a session
+	SessionsStarted   int `json:"sessions_started"`
+	SessionsCompleted int `json:"sessions_completed"`
+	PracticeMinutes   int `json:"practice_minutes"`
+	// Average completion_rate of the completed sessions; nil before the first one
+	AverageCompletionRate *float64 `json:"average_completion_rate,omitempty"`
+	// Adherence: completed sessions per member and week
+	SessionsPerWeek      float64 `json:"sessions_per_week"`
+	MembersOnStreak      int     `json:"members_on_streak"` // Members with a current streak
+	AverageCurrentStreak float64 `json:"average_current_streak"`
+}
+
+// GroupMemberStats sums up one member's practice in a stats window. Streaks are as of now,
+// whatever the window.
+type GroupMemberStats struct {
+	UserID                uuid.UUID `json:"user_id"`
+	FullName              string    `json:"full_name"`
+	Email                 string    `json:"email"`
+	SessionsStarted       int       `json:"sessions_started"`
+	SessionsCompleted     int       `json:"sessions_completed"`
+	PracticeMinutes       int       `json:"practice_minutes"` // Of the completed sessions
+	PracticeDays          int       `json:"practice_days"`    // Days with a completed session
+	AverageCompletionRate *float64  `json:"average_completion_rate,omitempty"`
+	RatedSessions         int       `json:"-"`                 // Completed sessions with a completion rate, which the average is over
+	SessionsPerWeek       float64   `json:"sessions_per_week"` // Completed sessions per week of the window
+	CurrentStreak         int       `json:"current_streak"`
+	LongestStreak         int       `json:"longest_streak"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HomeworkRequirement string
+
+const (
+	// HomeworkSessions is met by completing RequiredSessions practice sessions of the program
+	HomeworkSessions HomeworkRequirement = "sessions"
+	// HomeworkSubmission is met by opening a submission for the program
+	HomeworkSubmission HomeworkRequirement = "submission"
+)
+
+type HomeworkStatus string
+
+const (
+	HomeworkPending HomeworkStatus = "pending" // Not done yet, deadline still ahead
+	HomeworkOnTime  HomeworkStatus = "on_time"
+	HomeworkLate    HomeworkStatus = "late"    // Done after the deadline
+	HomeworkOverdue HomeworkStatus = "overdue" // Deadline passed and still not done
+)
+
+// Homework is program work due by a deadline. The students are fixed when it is set: the members
+// of the group, or everyone assigned to the program. Only work done after it was set counts.
+type Homework struct {
+	ID               uuid.UUID           `json:"id" db:"id"`
+	ProgramID        uuid.UUID           `json:"program_id" db:"program_id"`
+	ProgramName      string              `json:"program_name" db:"program_name"`
+	GroupID          *uuid.UUID          `json:"group_id,omitempty" db:"group_id"`
+	Title            string              `json:"title" db:"title"`
+	Instructions     *string             `json:"instructions,omitempty" db:"instructions"`
+	Requirement      HomeworkRequirement `json:"requirement" db:"requirement"`
+	RequiredSessions int                 `json:"required_sessions" db:"required_sessions"`
+	DueAt            time.Time           `json:"due_at" db:"due_at"`
+	CreatedBy        *uuid.UUID          `json:"created_by,omitempty" db:"created_by"`
+	StudentCount     int                 `json:"student_count" db:"student_count"`
+	CreatedAt        time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at" db:"updated_at"`
+	// Set for a student's own homework
+	Status      HomeworkStatus `json:"status,omitempty"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+}
+
+// HomeworkStudent is one student's standing on a homework
+type HomeworkStudent struct {
+	UserID      uuid.UUID      `json:"user_id" db:"user_id"`
+	FullName    string         `json:"full_name" db:"full_name"`
+	Email       string         `json:"email" db:"email"`
+	Status      HomeworkStatus `json:"status"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty" db:"completed_at"`
+	// When the student was marked overdue and notified
+	OverdueAt *time.Time `json:"overdue_at,omitempty" db:"overdue_at"`
+}
+
+// HomeworkStatusCounts tallies students by homework status
+type HomeworkStatusCounts struct {
+	Assigned int `json:"assigned"`
+	Pending  int `json:"pending"`
+	OnTime   int `json:"on_time"`
+	Late     int `json:"late"`
+	Overdue  int `json:"overdue"`
+}
+
+// HomeworkDetail is a homework with the standing of each of its students
+type HomeworkDetail struct {
+	Homework
+	Counts   HomeworkStatusCounts `json:"counts"`
+	Students []HomeworkStudent    `json:"students"`
+}
+
+// HomeworkReport breaks down homework completion by student group
+type HomeworkReport struct {
+	ProgramID *uuid.UUID            `json:"program_id,omitempty"`
+	From      time.Time             `json:"from"`
+	To        time.Time             `json:"to"`
+	Groups    []GroupHomeworkCounts `json:"groups"`
+}
+
+// GroupHomeworkCounts are the homework tallies of one group's members, counting each
+// student once per homework. GroupID is nil for students in no group.
+type GroupHomeworkCounts struct {
+	GroupID   *uuid.UUID `json:"group_id"`
+	GroupName *string    `json:"group_name"`
+	HomeworkStatusCounts
+	// Share of finished homework (on time or late) that was on time; nil when none is finished
+	OnTimeRate *float64 `json:"on_time_rate"`
+}
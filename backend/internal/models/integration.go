@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IntegrationKey lets another server, such as the school website, call the integration API by
+// signing its requests. The secret is only exposed once, when the key is created.
+type IntegrationKey struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	Secret     string     `json:"secret,omitempty" db:"secret"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IntegrationProgram is a public program as integrations see it: no owner, students or
+// progress. Exercises are omitted in lists.
+type IntegrationProgram struct {
+	ID                   uuid.UUID         `json:"id"`
+	Name                 string            `json:"name"`
+	Description          string            `json:"description"`
+	Tags                 []string          `json:"tags"`
+	CoverURL             *string           `json:"cover_url,omitempty"`
+	CoverThumbnails      map[string]string `json:"cover_thumbnails,omitempty"`
+	TotalDurationSeconds int               `json:"total_duration_seconds"`
+	Exercises            []Exercise        `json:"exercises,omitempty"`
+}
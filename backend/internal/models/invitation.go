@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation is a single-use signup link. The token is only exposed once, when the invitation is created.
+type Invitation struct {
+	ID         uuid.UUID   `json:"id" db:"id"`
+	Token      string      `json:"token,omitempty"`
+	SignupURL  string      `json:"signup_url,omitempty"`
+	TokenHash  string      `json:"-" db:"token_hash"`
+	Email      *string     `json:"email,omitempty" db:"email"`
+	Role       UserRole    `json:"role" db:"role"`
+	GroupID    *uuid.UUID  `json:"group_id,omitempty" db:"group_id"`
+	ProgramIDs []uuid.UUID `json:"program_ids" db:"program_ids"`
+	CreatedBy  *uuid.UUID  `json:"created_by,omitempty" db:"created_by"`
+	ExpiresAt  time.Time   `json:"expires_at" db:"expires_at"`
+	AcceptedAt *time.Time  `json:"accepted_at,omitempty" db:"accepted_at"`
+	AcceptedBy *uuid.UUID  `json:"accepted_by,omitempty" db:"accepted_by"`
+	RevokedAt  *time.Time  `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JournalMediaType string
+
+const (
+	JournalPhoto JournalMediaType = "photo"
+	JournalVideo JournalMediaType = "video"
+)
+
+// JournalEntry is a form-check photo or video in a student's private progress journal. The media
+// is downloaded through the journal API, which only serves it to the student and to instructors
+// the entry is shared with.
+type JournalEntry struct {
+	ID          uuid.UUID        `json:"id" db:"id"`
+	UserID      uuid.UUID        `json:"user_id" db:"user_id"`
+	ProgramID   uuid.UUID        `json:"program_id" db:"program_id"`
+	ExerciseID  *uuid.UUID       `json:"exercise_id,omitempty" db:"exercise_id"`
+	Caption     string           `json:"caption" db:"caption"`
+	MediaKey    string           `json:"-" db:"media_key"`
+	MediaType   JournalMediaType `json:"media_type" db:"media_type"`
+	ContentType string           `json:"content_type" db:"content_type"`
+	SizeBytes   int64            `json:"size_bytes" db:"size_bytes"`
+	RecordedAt  time.Time        `json:"recorded_at" db:"recorded_at"` // Position on the journal timeline
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	// SharedWith lists the instructors the entry is shared with; only shown to the student
+	SharedWith []uuid.UUID `json:"shared_with,omitempty" db:"-"`
+}
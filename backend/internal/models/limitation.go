@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Limitations is a student's injury and health profile. Joints and conditions use the same
+// names as exercise contraindications, e.g. "knees" or "hypertension".
+type Limitations struct {
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	Joints     []string  `json:"joints" db:"joints"`
+	Conditions []string  `json:"conditions" db:"conditions"`
+	Notes      string    `json:"notes" db:"notes"` // Details for the instructor
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// All returns the student's joints and conditions together
+func (l *Limitations) All() []string {
+	if l == nil {
+		return nil
+	}
+	all := make([]string, 0, len(l.Joints)+len(l.Conditions))
+	all = append(all, l.Joints...)
+	return append(all, l.Conditions...)
+}
+
+type AdjustmentAction string
+
+const (
+	// AdjustmentSubstituted replaced the exercise with a substitute that is safe for the student
+	AdjustmentSubstituted AdjustmentAction = "substituted"
+	// AdjustmentFlagged kept the exercise because none of its substitutes is safe
+	AdjustmentFlagged AdjustmentAction = "flagged"
+)
+
+// ExerciseAdjustment records how a contraindicated exercise was changed for a student
+type ExerciseAdjustment struct {
+	Action AdjustmentAction `json:"action"`
+	// Limitations are the student's limitations the exercise is contraindicated for
+	Limitations  []string   `json:"limitations"`
+	SubstituteID *uuid.UUID `json:"substitute_id,omitempty"`
+	OriginalName string     `json:"original_name,omitempty"` // Name of the replaced exercise
+}
+
+type PlanReviewStatus string
+
+const (
+	PlanReviewPending          PlanReviewStatus = "pending"
+	PlanReviewApproved         PlanReviewStatus = "approved"
+	PlanReviewChangesRequested PlanReviewStatus = "changes_requested"
+)
+
+// PlanReview is an instructor's review of a student's adjusted program. It applies to the
+// profile as of LimitationsUpdatedAt; a changed profile needs a new review.
+type PlanReview struct {
+	UserID               uuid.UUID        `json:"user_id" db:"user_id"`
+	ProgramID            uuid.UUID        `json:"program_id" db:"program_id"`
+	Status               PlanReviewStatus `json:"status" db:"status"`
+	Note                 *string          `json:"note,omitempty" db:"note"`
+	LimitationsUpdatedAt time.Time        `json:"limitations_updated_at" db:"limitations_updated_at"`
+	ReviewedBy           *uuid.UUID       `json:"reviewed_by" db:"reviewed_by"`
+	ReviewedAt           time.Time        `json:"reviewed_at" db:"reviewed_at"`
+}
+
+// AdjustedPlan is a student's program as rendered with their limitations
+type AdjustedPlan struct {
+	UserID      uuid.UUID    `json:"user_id"`
+	ProgramID   uuid.UUID    `json:"program_id"`
+	Limitations *Limitations `json:"limitations"`
+	Exercises   []Exercise   `json:"exercises"`
+	// ReviewStatus is empty when no exercise was adjusted
+	ReviewStatus PlanReviewStatus `json:"review_status,omitempty"`
+	Review       *PlanReview      `json:"review,omitempty"` // Latest review, possibly of an older profile
+}
+
+// PlanReviewQueueItem is an adjusted plan waiting for an instructor's review
+type PlanReviewQueueItem struct {
+	UserID               uuid.UUID `json:"user_id" db:"user_id"`
+	UserName             string    `json:"user_name" db:"user_name"`
+	UserEmail            string    `json:"user_email" db:"user_email"`
+	ProgramID            uuid.UUID `json:"program_id" db:"program_id"`
+	ProgramName          string    `json:"program_name" db:"program_name"`
+	LimitationsUpdatedAt time.Time `json:"limitations_updated_at" db:"limitations_updated_at"`
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AttendanceStatus string
+
+const (
+	AttendancePresent AttendanceStatus = "present"
+	AttendanceLate    AttendanceStatus = "late"
+	AttendanceExcused AttendanceStatus = "excused"
+	AttendanceAbsent  AttendanceStatus = "absent"
+)
+
+// Attended reports whether the status counts as having taken part in the class
+func (s AttendanceStatus) Attended() bool {
+	return s == AttendancePresent || s == AttendanceLate
+}
+
+type AttendanceMethod string
+
+const (
+	AttendanceManual AttendanceMethod = "manual" // Marked by an instructor
+	AttendanceCode   AttendanceMethod = "code"   // Student checked in with the class code
+	AttendanceQR     AttendanceMethod = "qr"     // Student scanned the class QR code
+)
+
+// LiveClass is a scheduled lesson taught in person or online. Classes with a group are
+// only visible to its members.
+type LiveClass struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	Title        string     `json:"title" db:"title"`
+	Description  *string    `json:"description,omitempty" db:"description"`
+	Location     *string    `json:"location,omitempty" db:"location"`
+	ProgramID    *uuid.UUID `json:"program_id,omitempty" db:"program_id"`
+	GroupID      *uuid.UUID `json:"group_id,omitempty" db:"group_id"`
+	InstructorID *uuid.UUID `json:"instructor_id,omitempty" db:"instructor_id"`
+	StartsAt     time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt       time.Time  `json:"ends_at" db:"ends_at"`
+	// Attendees counts students marked present or late
+	Attendees int `json:"attendees" db:"attendees"`
+	// Only shown to admins
+	CheckInCode      *string    `json:"check_in_code,omitempty" db:"check_in_code"`
+	CheckInExpiresAt *time.Time `json:"check_in_expires_at,omitempty" db:"check_in_expires_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+	// The current student's attendance, if recorded
+	MyAttendance *AttendanceStatus `json:"my_attendance,omitempty"`
+}
+
+// ClassAttendance is one student's attendance of a class
+type ClassAttendance struct {
+	ClassID  uuid.UUID        `json:"class_id" db:"class_id"`
+	UserID   uuid.UUID        `json:"user_id" db:"user_id"`
+	FullName string           `json:"full_name" db:"full_name"`
+	Email    string           `json:"email" db:"email"`
+	Status   AttendanceStatus `json:"status" db:"status"`
+	Method   AttendanceMethod `json:"method" db:"method"`
+	MarkedBy *uuid.UUID       `json:"marked_by,omitempty" db:"marked_by"`
+	MarkedAt time.Time        `json:"marked_at" db:"marked_at"`
+}
+
+// CheckInCode is a short-lived code students enter to check themselves in to a class
+type CheckInCode struct {
+	ClassID   uuid.UUID `json:"class_id"`
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AttendanceReport lists each student's class attendance next to their solo practice over a window
+type AttendanceReport struct {
+	From     time.Time           `json:"from"`
+	To       time.Time           `json:"to"`
+	GroupID  *uuid.UUID          `json:"group_id,omitempty"`
+	Classes  int                 `json:"classes"` // Classes held in the window
+	Students []StudentAttendance `json:"students"`
+}
+
+// StudentAttendance sums up one student's live classes and practice sessions in a report window
+type StudentAttendance struct {
+	UserID           uuid.UUID `json:"user_id"`
+	FullName         string    `json:"full_name"`
+	Email            string    `json:"email"`
+	ClassesAttended  int       `json:"classes_attended"` // Present or late
+	ClassesLate      int       `json:"classes_late"`
+	ClassesExcused   int       `json:"classes_excused"`
+	ClassesMissed    int       `json:"classes_missed"` // Marked absent
+	ClassMinutes     int       `json:"class_minutes"`
+	PracticeSessions int       `json:"practice_sessions"` // Completed solo sessions
+	PracticeMinutes  int       `json:"practice_minutes"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginDevice is a device a user has signed in from. Tokens issued on a sign-in carry the
+// device's ID, so revoking the device signs it out.
+type LoginDevice struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	Fingerprint     string     `json:"-" db:"fingerprint"`
+	ClientIP        *string    `json:"client_ip,omitempty" db:"client_ip"`
+	UserAgent       *string    `json:"user_agent,omitempty" db:"user_agent"`
+	Device          *string    `json:"device,omitempty" db:"device"`
+	RevokeTokenHash string     `json:"-" db:"revoke_token_hash"`
+	FirstSeenAt     time.Time  `json:"first_seen_at" db:"first_seen_at"`
+	LastSeenAt      time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	Current         bool       `json:"current"` // Whether the request was made from this device
+}
+
+// LoginClient describes where a sign-in came from
+type LoginClient struct {
+	ClientIP  *string
+	UserAgent *string
+	Device    *string // Client-reported X-Device-Info
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MetadataEntityType identifies which metadata maps a schema applies to
+type MetadataEntityType string
+
+const (
+	MetadataEntityProgram  MetadataEntityType = "program"
+	MetadataEntityExercise MetadataEntityType = "exercise"
+)
+
+// MetadataSchema is an admin-defined JSON Schema for the metadata of one entity type
+type MetadataSchema struct {
+	EntityType MetadataEntityType     `json:"entity_type" db:"entity_type"`
+	Schema     map[string]interface{} `json:"schema" db:"schema"`
+	UpdatedBy  *uuid.UUID             `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt  time.Time              `json:"updated_at" db:"updated_at"`
+}
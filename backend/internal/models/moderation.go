@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ModerationContentType string
+
+const (
+	ModerationMessage ModerationContentType = "message" // A message in a submission thread
+	ModerationProgram ModerationContentType = "program"
+)
+
+type ModerationStatus string
+
+const (
+	ModerationPending  ModerationStatus = "pending"  // Waiting for an admin
+	ModerationResolved ModerationStatus = "resolved" // Reports dismissed; the content is shown again
+	ModerationHidden   ModerationStatus = "hidden"   // The content stays hidden
+)
+
+type ReportReason string
+
+const (
+	ReportSpam          ReportReason = "spam"
+	ReportHarassment    ReportReason = "harassment"
+	ReportInappropriate ReportReason = "inappropriate"
+	ReportOther         ReportReason = "other"
+)
+
+// ModerationCase collects the abuse reports on one piece of content until an admin
+// resolves it or hides the content
+type ModerationCase struct {
+	ID          uuid.UUID             `json:"id" db:"id"`
+	ContentType ModerationContentType `json:"content_type" db:"content_type"`
+	ContentID   uuid.UUID             `json:"content_id" db:"content_id"`
+	Status      ModerationStatus      `json:"status" db:"status"`
+	ReportCount int                   `json:"report_count" db:"report_count"`
+	// AutoHidden is set when the content was hidden for reaching the report threshold
+	AutoHidden bool `json:"auto_hidden" db:"auto_hidden"`
+	// FlaggedTerms lists what the content filter matched when it flagged the content
+	FlaggedTerms []string `json:"flagged_terms" db:"flagged_terms"` // null for cases opened by users
+	// The reported content as it is now: message text or program name, and its author.
+	// Empty once the content is deleted.
+	Excerpt        string     `json:"excerpt" db:"excerpt"`
+	AuthorID       *uuid.UUID `json:"author_id,omitempty" db:"author_id"`
+	AuthorName     *string    `json:"author_name,omitempty" db:"author_name"`
+	ContentHidden  bool       `json:"content_hidden" db:"content_hidden"`
+	ResolvedBy     *uuid.UUID `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	ResolutionNote *string    `json:"resolution_note,omitempty" db:"resolution_note"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	// Only filled in for a single case
+	Reports []ModerationReport `json:"reports,omitempty"`
+}
+
+// ModerationReport is one user's report of a piece of content
+type ModerationReport struct {
+	ID           uuid.UUID    `json:"id" db:"id"`
+	CaseID       uuid.UUID    `json:"case_id" db:"case_id"`
+	ReporterID   uuid.UUID    `json:"reporter_id" db:"reporter_id"`
+	ReporterName string       `json:"reporter_name" db:"reporter_name"`
+	Reason       ReportReason `json:"reason" db:"reason"`
+	Details      *string      `json:"details,omitempty" db:"details"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+}
+
+// ReportedContent is what the moderation service needs to know about reported content
+type ReportedContent struct {
+	AuthorID *uuid.UUID
+	Hidden   bool
+	// SubmissionID is the thread a message belongs to, for checking the reporter's access
+	SubmissionID *uuid.UUID
+	// Public is set for programs listed for everyone; other programs are only reported by users
+	// who may access them
+	Public bool
+}
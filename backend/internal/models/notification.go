@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationType string
+
+const (
+	NotificationSessionNote      NotificationType = "session_note"
+	NotificationMessageMention   NotificationType = "message_mention"
+	NotificationNewMessage       NotificationType = "submission_message"
+	NotificationAnnouncement     NotificationType = "announcement"
+	NotificationDiscussionReply  NotificationType = "discussion_reply"
+	NotificationBookingConfirmed NotificationType = "booking_confirmed"
+	NotificationHomeworkOverdue  NotificationType = "homework_overdue"
+	NotificationJournalRequest   NotificationType = "journal_request"
+	NotificationSupportReply     NotificationType = "support_reply"
+	NotificationNewLogin         NotificationType = "new_login"
+)
+
+// Notification is an in-app notification addressed to a single user
+type Notification struct {
+	ID        uuid.UUID              `json:"id" db:"id"`
+	UserID    uuid.UUID              `json:"user_id" db:"user_id"`
+	Type      NotificationType       `json:"type" db:"type"`
+	Title     string                 `json:"title" db:"title"`
+	Body      *string                `json:"body,omitempty" db:"body"`
+	Payload   map[string]interface{} `json:"payload" db:"payload"`
+	ReadAt    *time.Time             `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time              `json:"created_at" db:"created_at"`
+}
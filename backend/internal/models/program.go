@@ -18,14 +18,49 @@ type Program struct {
 	RepetitionsCompleted *int                   `json:"repetitions_completed,omitempty" db:"repetitions_completed"`
 	Tags                 []string               `json:"tags" db:"tags"`
 	Metadata             map[string]interface{} `json:"metadata" db:"metadata"`
+	PublishAt            *time.Time             `json:"publish_at,omitempty" db:"publish_at"`
+	UnpublishAt          *time.Time             `json:"unpublish_at,omitempty" db:"unpublish_at"`
+	CoverImageKey        *string                `json:"-" db:"cover_image_key"`
+	CoverURL             *string                `json:"cover_url,omitempty" db:"-"`
+	CoverThumbnails      map[string]string      `json:"cover_thumbnails,omitempty" db:"-"`  // size name -> URL
+	HiddenAt             *time.Time             `json:"hidden_at,omitempty" db:"hidden_at"` // Hidden by moderation
 	CreatedAt            time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time              `json:"updated_at" db:"updated_at"`
 	DeletedAt            *time.Time             `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
+// DuplicatePolicy controls what happens when a new template matches an existing one
+type DuplicatePolicy string
+
+const (
+	DuplicatePolicyWarn  DuplicatePolicy = "warn"  // create anyway and report similar programs
+	DuplicatePolicyMerge DuplicatePolicy = "merge" // reuse an exact duplicate instead of creating
+)
+
+// SimilarProgram is an existing template or public program that resembles a new one
+type SimilarProgram struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	OwnedBy       *uuid.UUID `json:"owned_by"`
+	IsTemplate    bool       `json:"is_template"`
+	IsPublic      bool       `json:"is_public"`
+	SameName      bool       `json:"same_name"`
+	SameExercises bool       `json:"same_exercises"`
+}
+
+// ProgramCreateResult is the outcome of creating a program with duplicate detection.
+// The program fields are inlined so existing clients keep reading the same response.
+type ProgramCreateResult struct {
+	*Program
+	SimilarPrograms []SimilarProgram `json:"similar_programs"`
+	Merged          bool             `json:"merged"`
+}
+
 type ProgramWithExercises struct {
 	Program   Program    `json:"program"`
 	Exercises []Exercise `json:"exercises"`
+	// PlanReviewStatus is set in a student's own programs when exercises were adjusted for their limitations
+	PlanReviewStatus PlanReviewStatus `json:"plan_review_status,omitempty"`
 }
 
 type UserProgram struct {
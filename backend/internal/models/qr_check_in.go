@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QRCheckInKind tells what scanning a QR check-in code recorded
+type QRCheckInKind string
+
+const (
+	QRCheckInAttendance QRCheckInKind = "attendance" // Attendance of a live class
+	QRCheckInSession    QRCheckInKind = "session"    // A practice session of a program
+)
+
+// QRCode is a signed, short-lived check-in token for a class or program, shown as a QR code
+// at in-person sessions. Clients encode URL (or the bare token if URL is empty) into the image.
+type QRCode struct {
+	ClassID   *uuid.UUID `json:"class_id,omitempty"`
+	ProgramID *uuid.UUID `json:"program_id,omitempty"`
+	Token     string     `json:"token"`
+	URL       string     `json:"url,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"`
+}
+
+// QRCheckIn records a student's scan of a QR code; each token is accepted once per student
+type QRCheckIn struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	TokenID   uuid.UUID  `json:"token_id" db:"token_id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	ClassID   *uuid.UUID `json:"class_id,omitempty" db:"class_id"`
+	ProgramID *uuid.UUID `json:"program_id,omitempty" db:"program_id"`
+	SessionID *uuid.UUID `json:"session_id,omitempty" db:"session_id"`
+	ClientIP  *string    `json:"client_ip,omitempty" db:"client_ip"`
+	UserAgent *string    `json:"user_agent,omitempty" db:"user_agent"`
+	Device    *string    `json:"device,omitempty" db:"device"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// QRCheckInResult is what a scan recorded: the class attended or the practice session started
+type QRCheckInResult struct {
+	Kind    QRCheckInKind    `json:"kind"`
+	Class   *LiveClass       `json:"class,omitempty"`
+	Session *PracticeSession `json:"session,omitempty"`
+}
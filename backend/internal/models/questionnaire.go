@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Questionnaire question types
+const (
+	QuestionScale = "scale" // A number from Min to Max
+	QuestionText  = "text"  // Free text
+)
+
+// SessionQuestionnaire is what students of a program are asked after a session, in place of
+// free-form notes
+type SessionQuestionnaire struct {
+	ProgramID uuid.UUID               `json:"program_id" db:"program_id"`
+	Questions []QuestionnaireQuestion `json:"questions" db:"questions"`
+	UpdatedBy *uuid.UUID              `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt time.Time               `json:"updated_at" db:"updated_at"`
+}
+
+// QuestionnaireQuestion is one question of a questionnaire. Min, Max and the labels only apply
+// to scale questions.
+type QuestionnaireQuestion struct {
+	ID       string  `json:"id"` // Answers refer to the question by it, so keep it when rewording
+	Type     string  `json:"type"`
+	Prompt   string  `json:"prompt"`
+	Min      int     `json:"min,omitempty"`
+	Max      int     `json:"max,omitempty"`
+	MinLabel *string `json:"min_label,omitempty"` // e.g. "Not at all"
+	MaxLabel *string `json:"max_label,omitempty"` // e.g. "Completely"
+}
+
+// Question returns the question with the ID, or nil
+func (q *SessionQuestionnaire) Question(id string) *QuestionnaireQuestion {
+	for i := range q.Questions {
+		if q.Questions[i].ID == id {
+			return &q.Questions[i]
+		}
+	}
+	return nil
+}
+
+// SessionAnswer is a student's answer to one question about a session
+type SessionAnswer struct {
+	QuestionID string    `json:"question_id" db:"question_id"`
+	Scale      *int      `json:"scale,omitempty" db:"scale_value"`
+	Text       *string   `json:"text,omitempty" db:"text_value"`
+	AnsweredAt time.Time `json:"answered_at" db:"answered_at"`
+}
+
+// QuestionnaireResults aggregates the answers to a program's questionnaire for instructors
+type QuestionnaireResults struct {
+	ProgramID        uuid.UUID                     `json:"program_id"`
+	SessionsAnswered int                           `json:"sessions_answered"`
+	Questions        []QuestionnaireQuestionResult `json:"questions"`
+}
+
+// QuestionnaireQuestionResult aggregates the answers to one question: the average and distribution of a
+// scale, the latest answers to a text question
+type QuestionnaireQuestionResult struct {
+	QuestionID   string       `json:"question_id"`
+	Type         string       `json:"type"`
+	Prompt       string       `json:"prompt"`
+	Responses    int          `json:"responses"`
+	Average      *float64     `json:"average,omitempty"`
+	Distribution []ScaleCount `json:"distribution,omitempty"`
+	Latest       []TextAnswer `json:"latest,omitempty"` // Newest first
+}
+
+// ScaleCount is how many answers gave a value on a scale
+type ScaleCount struct {
+	Value int `json:"value"`
+	Count int `json:"count"`
+}
+
+// TextAnswer is a free-text answer with the session it was given for
+type TextAnswer struct {
+	SessionID  uuid.UUID `json:"session_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	UserName   string    `json:"user_name"`
+	Text       string    `json:"text"`
+	AnsweredAt time.Time `json:"answered_at"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Quiz is a knowledge check attached to a program, e.g. on qi gong principles
+type Quiz struct {
+	ID            uuid.UUID      `json:"id" db:"id"`
+	ProgramID     uuid.UUID      `json:"program_id" db:"program_id"`
+	Title         string         `json:"title" db:"title"`
+	Description   *string        `json:"description,omitempty" db:"description"`
+	PassThreshold int            `json:"pass_threshold" db:"pass_threshold"` // Minimum score in percent
+	MaxAttempts   *int           `json:"max_attempts,omitempty" db:"max_attempts"`
+	CreatedBy     *uuid.UUID     `json:"created_by,omitempty" db:"created_by"`
+	QuestionCount int            `json:"question_count" db:"question_count"`
+	Questions     []QuizQuestion `json:"questions,omitempty" db:"-"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+type QuizQuestion struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Position    int       `json:"position" db:"position"`
+	Prompt      string    `json:"prompt" db:"prompt"`
+	Explanation *string   `json:"explanation,omitempty" db:"explanation"` // Shown to students after answering
+	// MultipleAnswers tells students to select every correct choice
+	MultipleAnswers bool         `json:"multiple_answers" db:"-"`
+	Choices         []QuizChoice `json:"choices" db:"-"`
+}
+
+type QuizChoice struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	Position  int       `json:"position" db:"position"`
+	Text      string    `json:"text" db:"text"`
+	IsCorrect *bool     `json:"is_correct,omitempty" db:"is_correct"` // Only included for admins
+}
+
+// QuizAttempt is a scored submission of answers. Results are only returned when the attempt is submitted.
+type QuizAttempt struct {
+	ID        uuid.UUID                 `json:"id" db:"id"`
+	QuizID    uuid.UUID                 `json:"quiz_id" db:"quiz_id"`
+	UserID    uuid.UUID                 `json:"user_id" db:"user_id"`
+	Answers   map[uuid.UUID][]uuid.UUID `json:"answers" db:"answers"` // Question ID -> selected choice IDs
+	Score     float64                   `json:"score" db:"score"`     // Percent of questions answered correctly
+	Passed    bool                      `json:"passed" db:"passed"`
+	Results   []QuestionResult          `json:"results,omitempty" db:"-"`
+	CreatedAt time.Time                 `json:"created_at" db:"created_at"`
+}
+
+// QuestionResult tells a student whether they answered a question correctly. The correct
+// choices are only revealed once the attempt passes.
+type QuestionResult struct {
+	QuestionID       uuid.UUID   `json:"question_id"`
+	Correct          bool        `json:"correct"`
+	CorrectChoiceIDs []uuid.UUID `json:"correct_choice_ids,omitempty"`
+	Explanation      *string     `json:"explanation,omitempty"`
+}
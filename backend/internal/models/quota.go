@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultQuotaPlan applies to users without a plan of their own
+const DefaultQuotaPlan = "default"
+
+// QuotaLimits are soft limits on user-generated content; nil is unlimited
+type QuotaLimits struct {
+	MaxPrograms        *int   `json:"max_programs" db:"max_programs"`
+	MaxOpenSubmissions *int   `json:"max_open_submissions" db:"max_open_submissions"`
+	MaxStorageBytes    *int64 `json:"max_storage_bytes" db:"max_storage_bytes"`
+}
+
+// QuotaPlan is a named set of limits shared by the users on it
+type QuotaPlan struct {
+	Name string `json:"name" db:"name"`
+	QuotaLimits
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// QuotaUsage is what currently counts against a user's quota
+type QuotaUsage struct {
+	Programs int `json:"programs"` // Owned programs that aren't deleted
+	// Submissions that have no reply yet or where the student wrote last
+	OpenSubmissions int `json:"open_submissions"`
+	// Uploaded bytes of the cover images of the user's programs
+	StorageBytes int64 `json:"storage_bytes"`
+}
+
+// UserQuota is a user's plan, their per-user overrides and the limits that result.
+// Admins are exempt from quotas.
+type UserQuota struct {
+	UserID    uuid.UUID   `json:"user_id"`
+	Plan      string      `json:"plan"`
+	Overrides QuotaLimits `json:"overrides"` // nil falls back to the plan
+	Limits    QuotaLimits `json:"limits"`
+	Usage     QuotaUsage  `json:"usage"`
+	Exempt    bool        `json:"exempt"`
+}
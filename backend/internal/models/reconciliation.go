@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RepetitionDrift is a program whose stored completed count didn't match its completed sessions
+type RepetitionDrift struct {
+	ProgramID   uuid.UUID `json:"program_id"`
+	ProgramName string    `json:"program_name"`
+	Stored      *int      `json:"stored"` // The count before the correction
+	Actual      int       `json:"actual"`
+}
+
+// RepetitionReconciliation reports one run of the repetitions_completed reconciliation
+type RepetitionReconciliation struct {
+	ID              uuid.UUID         `json:"id" db:"id"`
+	ProgramsChecked int               `json:"programs_checked" db:"programs_checked"`
+	Drifted         []RepetitionDrift `json:"drifted" db:"drifted"`
+	RanAt           time.Time         `json:"ran_at" db:"ran_at"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RepetitionProgress is a student's progress through the planned repetitions of a program
+type RepetitionProgress struct {
+	ProgramID            uuid.UUID `json:"program_id"`
+	RepetitionsPlanned   *int      `json:"repetitions_planned,omitempty"` // Nil for open-ended programs
+	RepetitionsCompleted int       `json:"repetitions_completed"`         // The student's completed sessions of the program
+	PercentComplete      *float64  `json:"percent_complete,omitempty"`    // Capped at 100; nil without a plan
+	// Completed sessions per week over the last four weeks
+	SessionsPerWeek float64 `json:"sessions_per_week"`
+	// Date (YYYY-MM-DD) the plan is done at the recent pace. Nil without a plan, once it is done,
+	// or without practice in the last four weeks.
+	ProjectedCompletionDate *string            `json:"projected_completion_date,omitempty"`
+	LastPracticedAt         *time.Time         `json:"last_practiced_at,omitempty"`
+	Exercises               []ExerciseProgress `json:"exercises"`
+}
+
+// ExerciseProgress counts how often a student did one exercise across their completed sessions
+type ExerciseProgress struct {
+	ExerciseID       uuid.UUID  `json:"exercise_id"`
+	Name             string     `json:"name"`
+	TimesCompleted   int        `json:"times_completed"`   // Sessions it was done in, including as a substitute
+	TimesSkipped     int        `json:"times_skipped"`     // Sessions it was skipped in
+	TimesSubstituted int        `json:"times_substituted"` // Sessions a substitute was done instead
+	CompletionRate   float64    `json:"completion_rate"`   // Percent of completed sessions it was done in
+	LastCompletedAt  *time.Time `json:"last_completed_at,omitempty"`
+}
+
+// ProgramAdoption shows a program's author how students use it, across everyone practicing it
+type ProgramAdoption struct {
+	ProgramID         uuid.UUID `json:"program_id"`
+	AssignedUsers     int       `json:"assigned_users"`   // Active assignments
+	PracticingUsers   int       `json:"practicing_users"` // Users who started a session of it
+	SessionsStarted   int       `json:"sessions_started"` // Including the completed ones
+	SessionsCompleted int       `json:"sessions_completed"`
+	// Average completion_rate of the completed sessions; nil before the first one
+	AverageCompletionRate *float64           `json:"average_completion_rate,omitempty"`
+	Exercises             []ExerciseAdoption `json:"exercises"`
+}
+
+// ExerciseAdoption shows where in a program students stop: how many sessions reached an
+// exercise, and how many of those reaching the one before did not
+type ExerciseAdoption struct {
+	ExerciseID   uuid.UUID `json:"exercise_id"`
+	Name         string    `json:"name"`
+	TimesReached int       `json:"times_reached"` // Sessions that logged it, done or skipped
+	TimesSkipped int       `json:"times_skipped"` // Sessions it was skipped in
+	DropOffRate  float64   `json:"drop_off_rate"` // Percent of the sessions reaching the previous exercise (all started sessions for the first) that stopped before this one
+}
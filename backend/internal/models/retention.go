@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Data retention rules
+const (
+	RetentionRuleSessions    = "sessions"     // Delete practice sessions older than RETENTION_SESSION_YEARS
+	RetentionRuleSoftDeleted = "soft_deleted" // Purge items deleted longer than RETENTION_SOFT_DELETED_DAYS ago
+	RetentionRuleDeviceInfo  = "device_info"  // Strip device details older than RETENTION_DEVICE_INFO_DAYS
+)
+
+// RetentionRules lists the rules in the order the daily job runs them
+var RetentionRules = []string{RetentionRuleSessions, RetentionRuleSoftDeleted, RetentionRuleDeviceInfo}
+
+// RetentionRule is a rule with its current configuration
+type RetentionRule struct {
+	Name    string     `json:"name"`
+	Enabled bool       `json:"enabled"`
+	DryRun  bool       `json:"dry_run"`          // The daily job only reports what the rule would change
+	Cutoff  *time.Time `json:"cutoff,omitempty"` // Data from before this is affected; nil when disabled
+}
+
+// RetentionRun reports one run of a retention rule: the rows it deleted or stripped per table,
+// or would have on a dry run
+type RetentionRun struct {
+	ID          uuid.UUID        `json:"id" db:"id"`
+	Rule        string           `json:"rule" db:"rule"`
+	DryRun      bool             `json:"dry_run" db:"dry_run"`
+	Scheduled   bool             `json:"scheduled" db:"scheduled"`
+	Cutoff      time.Time        `json:"cutoff" db:"cutoff"`
+	Affected    map[string]int64 `json:"affected" db:"affected"`
+	TriggeredBy *uuid.UUID       `json:"triggered_by,omitempty" db:"triggered_by"` // Admin who requested a dry run
+	RanAt       time.Time        `json:"ran_at" db:"ran_at"`
+}
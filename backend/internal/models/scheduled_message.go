@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ScheduledMessageStatus string
+
+const (
+	ScheduledMessagePending   ScheduledMessageStatus = "pending"
+	ScheduledMessageSent      ScheduledMessageStatus = "sent"
+	ScheduledMessageCancelled ScheduledMessageStatus = "cancelled"
+	ScheduledMessageFailed    ScheduledMessageStatus = "failed"
+)
+
+// ScheduledMessage is a submission message or program announcement delivered at SendAt.
+// Exactly one of SubmissionID and ProgramID is set.
+type ScheduledMessage struct {
+	ID           uuid.UUID              `json:"id" db:"id"`
+	AuthorID     uuid.UUID              `json:"author_id" db:"author_id"`
+	SubmissionID *uuid.UUID             `json:"submission_id,omitempty" db:"submission_id"`
+	ProgramID    *uuid.UUID             `json:"program_id,omitempty" db:"program_id"`
+	Title        *string                `json:"title,omitempty" db:"title"` // Announcements only
+	Content      string                 `json:"content" db:"content"`
+	YouTubeURL   *string                `json:"youtube_url,omitempty" db:"youtube_url"`
+	SendAt       time.Time              `json:"send_at" db:"send_at"`
+	Status       ScheduledMessageStatus `json:"status" db:"status"`
+	MessageID    *uuid.UUID             `json:"message_id,omitempty" db:"message_id"` // Posted submission message
+	Recipients   *int                   `json:"recipients,omitempty" db:"recipients"` // Users notified on delivery
+	Error        *string                `json:"error,omitempty" db:"error"`
+	SentAt       *time.Time             `json:"sent_at,omitempty" db:"sent_at"`
+	CreatedAt    time.Time              `json:"created_at" db:"created_at"`
+}
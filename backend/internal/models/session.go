@@ -11,12 +11,98 @@ type PracticeSession struct {
 	UserID               uuid.UUID              `json:"user_id" db:"user_id"`
 	ProgramID            uuid.UUID              `json:"program_id" db:"program_id"`
 	ProgramName          *string                `json:"program_name,omitempty"`
+	UserName             *string                `json:"user_name,omitempty"` // Only set in the admin activity feed
 	StartedAt            time.Time              `json:"started_at" db:"started_at"`
 	CompletedAt          *time.Time             `json:"completed_at,omitempty" db:"completed_at"`
 	TotalDurationSeconds *int                   `json:"total_duration_seconds,omitempty" db:"total_duration_seconds"`
 	CompletionRate       *float64               `json:"completion_rate,omitempty" db:"completion_rate"`
-	Notes                *string                `json:"notes,omitempty" db:"notes"`
+	Notes                *string                `json:"notes,omitempty" db:"notes"` // Superseded by questionnaire answers; still accepted from older apps
 	DeviceInfo           map[string]interface{} `json:"device_info,omitempty" db:"device_info"`
+	Mood                 *int                   `json:"mood,omitempty" db:"mood"`
+	Energy               *int                   `json:"energy,omitempty" db:"energy"`
+	PainFlags            []string               `json:"pain_flags,omitempty" db:"pain_flags"`
+	Tags                 []string               `json:"tags,omitempty" db:"tags"`
+	HeartRateMin         *int                   `json:"heart_rate_min,omitempty" db:"heart_rate_min"`
+	HeartRateAvg         *float64               `json:"heart_rate_avg,omitempty" db:"heart_rate_avg"`
+	HeartRateMax         *int                   `json:"heart_rate_max,omitempty" db:"heart_rate_max"`
+	HRVAvg               *float64               `json:"hrv_avg,omitempty" db:"hrv_avg"`
+	DeletedAt            *time.Time             `json:"deleted_at,omitempty" db:"deleted_at"`
+	ExperimentID         *uuid.UUID             `json:"experiment_id,omitempty" db:"experiment_id"` // Set when the program was an experiment variant for the student
+	Variant              *string                `json:"variant,omitempty" db:"variant"`
+	EditedAt             *time.Time             `json:"edited_at,omitempty" db:"edited_at"` // Set once the session was corrected after it was recorded
+	EditedBy             *uuid.UUID             `json:"edited_by,omitempty" db:"edited_by"`
+}
+
+// BiometricSample is a single wearable reading taken during a session
+type BiometricSample struct {
+	RecordedAt time.Time `json:"recorded_at" db:"recorded_at"`
+	HeartRate  *int      `json:"heart_rate,omitempty" db:"heart_rate"`
+	HRVMs      *float64  `json:"hrv_ms,omitempty" db:"hrv_ms"`
+}
+
+// SessionWellbeing holds the subjective fields a student reports when completing a session
+type SessionWellbeing struct {
+	Mood      *int     `json:"mood,omitempty"`
+	Energy    *int     `json:"energy,omitempty"`
+	PainFlags []string `json:"pain_flags,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+// SessionStatus is a session's outcome, for filtering the admin activity feed
+type SessionStatus string
+
+const (
+	SessionCompleted  SessionStatus = "completed"
+	SessionInProgress SessionStatus = "in_progress" // Not completed, started less than SessionAbandonedAfter ago
+	SessionAbandoned  SessionStatus = "abandoned"   // Never completed and started longer ago
+)
+
+// SessionAbandonedAfter is how long after its start an unfinished session counts as abandoned
+const SessionAbandonedAfter = 24 * time.Hour
+
+// SessionFilter narrows down the admin activity feed. Nil fields don't filter.
+type SessionFilter struct {
+	UserID    *uuid.UUID
+	ProgramID *uuid.UUID
+	StartDate *time.Time
+	EndDate   *time.Time
+	Status    *SessionStatus
+}
+
+// SessionUpdate holds corrections to a recorded session. Nil fields are left unchanged.
+type SessionUpdate struct {
+	Notes                *string
+	TotalDurationSeconds *int
+	CompletionRate       *float64
+	CompletedAt          *time.Time
+	Reason               *string // Why it was corrected, for the edit history
+}
+
+// ExerciseLogUpdate holds corrections to a logged exercise. Nil fields are left unchanged.
+type ExerciseLogUpdate struct {
+	ActualDurationSeconds *int
+	RepetitionsCompleted  *int
+	Skipped               *bool
+	Notes                 *string
+	Reason                *string // Why it was corrected, for the edit history
+}
+
+// FieldChange records a single field's value before and after an edit
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// SessionEdit is an audit entry for a correction made to a session or one of its exercise logs
+type SessionEdit struct {
+	ID            uuid.UUID              `json:"id" db:"id"`
+	SessionID     uuid.UUID              `json:"session_id" db:"session_id"`
+	ExerciseLogID *uuid.UUID             `json:"exercise_log_id,omitempty" db:"exercise_log_id"`
+	EditorID      *uuid.UUID             `json:"editor_id,omitempty" db:"editor_id"` // Nil once the editor's account is deleted
+	EditorName    *string                `json:"editor_name,omitempty"`
+	Changes       map[string]FieldChange `json:"changes" db:"changes"`
+	Reason        *string                `json:"reason,omitempty" db:"reason"`
+	CreatedAt     time.Time              `json:"created_at" db:"created_at"`
 }
 
 type ExerciseLog struct {
@@ -31,18 +117,59 @@ type ExerciseLog struct {
 	RepetitionsCompleted   *int       `json:"repetitions_completed,omitempty" db:"repetitions_completed"`
 	Skipped                bool       `json:"skipped" db:"skipped"`
 	Notes                  *string    `json:"notes,omitempty" db:"notes"`
+	// SubstituteID is the variant done instead of the exercise, if any
+	SubstituteID *uuid.UUID `json:"substitute_id,omitempty" db:"substitute_id"`
+	// EditedAt is set once the log was corrected after it was recorded
+	EditedAt *time.Time `json:"edited_at,omitempty" db:"edited_at"`
+	EditedBy *uuid.UUID `json:"edited_by,omitempty" db:"edited_by"`
+}
+
+type NoteVisibility string
+
+const (
+	NoteVisibilityPrivate NoteVisibility = "private"
+	NoteVisibilityShared  NoteVisibility = "shared"
+)
+
+// SessionNote is an instructor comment attached to a practice session.
+// Private notes are only visible to admins, shared notes also to the session owner.
+type SessionNote struct {
+	ID         uuid.UUID      `json:"id" db:"id"`
+	SessionID  uuid.UUID      `json:"session_id" db:"session_id"`
+	AuthorID   uuid.UUID      `json:"author_id" db:"author_id"`
+	AuthorName string         `json:"author_name" db:"author_name"`
+	Content    string         `json:"content" db:"content"`
+	Visibility NoteVisibility `json:"visibility" db:"visibility"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
 }
 
 type SessionWithLogs struct {
 	Session      PracticeSession `json:"session"`
 	ExerciseLogs []ExerciseLog   `json:"exercise_logs"`
+	Notes        []SessionNote   `json:"notes,omitempty"`
+	Answers      []SessionAnswer `json:"answers,omitempty"` // To the program's questionnaire
 }
 
 type SessionStats struct {
-	TotalSessions         int     `json:"total_sessions"`
-	CompletedSessions     int     `json:"completed_sessions"`
-	TotalDurationMinutes  int     `json:"total_duration_minutes"`
+	TotalSessions         int             `json:"total_sessions"`
+	CompletedSessions     int             `json:"completed_sessions"`
+	TotalDurationMinutes  int             `json:"total_duration_minutes"`
+	AverageCompletionRate float64         `json:"average_completion_rate"`
+	CurrentStreak         int             `json:"current_streak"`
+	LongestStreak         int             `json:"longest_streak"`
+	AverageHeartRate      *float64        `json:"average_heart_rate,omitempty"`
+	PeakHeartRate         *int            `json:"peak_heart_rate,omitempty"`
+	ByMood                []WellbeingStat `json:"by_mood"`
+	ByEnergy              []WellbeingStat `json:"by_energy"`
+	// Live classes marked present or late, next to the solo practice above
+	ClassesAttended int `json:"classes_attended"`
+	ClassMinutes    int `json:"class_minutes"`
+}
+
+// WellbeingStat aggregates completed sessions for a single mood or energy level
+type WellbeingStat struct {
+	Level                 int     `json:"level"`
+	Sessions              int     `json:"sessions"`
 	AverageCompletionRate float64 `json:"average_completion_rate"`
-	CurrentStreak         int     `json:"current_streak"`
-	LongestStreak         int     `json:"longest_streak"`
+	AverageDurationMin    float64 `json:"average_duration_minutes"`
 }
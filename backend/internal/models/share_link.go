@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProgramShareLink is an expiring, revocable public link to a read-only view of a program.
+// The token and URL are only exposed once, when the link is created.
+type ProgramShareLink struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	ProgramID    uuid.UUID  `json:"program_id" db:"program_id"`
+	Token        string     `json:"token,omitempty"`
+	URL          string     `json:"url,omitempty"`
+	TokenHash    string     `json:"-" db:"token_hash"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	ViewCount    int        `json:"view_count" db:"view_count"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty" db:"last_viewed_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// SharedProgram is the public view of a program behind a share link: its content, without
+// owner, assignments or progress
+type SharedProgram struct {
+	Name                 string            `json:"name"`
+	Description          string            `json:"description"`
+	Tags                 []string          `json:"tags"`
+	CoverURL             *string           `json:"cover_url,omitempty"`
+	CoverThumbnails      map[string]string `json:"cover_thumbnails,omitempty"`
+	TotalDurationSeconds int               `json:"total_duration_seconds"`
+	Exercises            []Exercise        `json:"exercises"`
+	ExpiresAt            time.Time         `json:"expires_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FeedbackSnippet is a reusable block of feedback an instructor inserts into submission messages
+type FeedbackSnippet struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	OwnerID   uuid.UUID `json:"owner_id" db:"owner_id"`
+	Title     string    `json:"title" db:"title"`
+	Body      string    `json:"body" db:"body"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Placeholders available in snippet bodies, filled in from the submission the snippet is inserted into
+const (
+	SnippetStudentName      = "student_name"
+	SnippetStudentFirstName = "student_first_name"
+	SnippetProgramName      = "program_name"
+	SnippetSubmissionTitle  = "submission_title"
+	SnippetInstructorName   = "instructor_name"
+)
+
+// SnippetPlaceholders lists all placeholders a snippet body may use
+var SnippetPlaceholders = []string{
+	SnippetStudentName,
+	SnippetStudentFirstName,
+	SnippetProgramName,
+	SnippetSubmissionTitle,
+	SnippetInstructorName,
+}
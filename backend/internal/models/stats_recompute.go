@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StatsRecomputeStatus string
+
+const (
+	StatsRecomputePending   StatsRecomputeStatus = "pending" // Waiting for the background job
+	StatsRecomputeRunning   StatsRecomputeStatus = "running"
+	StatsRecomputeCompleted StatsRecomputeStatus = "completed"
+	StatsRecomputeFailed    StatsRecomputeStatus = "failed"
+)
+
+// StatsRecompute recomputes stored stats (streak snapshots and program completion counts) for
+// one user, or for everyone when UserID is nil
+type StatsRecompute struct {
+	ID          uuid.UUID            `json:"id" db:"id"`
+	UserID      *uuid.UUID           `json:"user_id,omitempty" db:"user_id"`
+	RequestedBy *uuid.UUID           `json:"requested_by,omitempty" db:"requested_by"` // Nil when started from the CLI
+	Status      StatsRecomputeStatus `json:"status" db:"status"`
+	Total       int                  `json:"total" db:"total"` // Users to process
+	Processed   int                  `json:"processed" db:"processed"`
+	Cursor      *uuid.UUID           `json:"-" db:"cursor"` // Last user processed
+	Error       *string              `json:"error,omitempty" db:"error"`
+	CreatedAt   time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at" db:"updated_at"`
+	CompletedAt *time.Time           `json:"completed_at,omitempty" db:"completed_at"`
+}
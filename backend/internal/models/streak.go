@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StreakPolicy decides which days count towards a practice streak
+type StreakPolicy struct {
+	// Days per week (Monday to Sunday) a streak may skip without breaking; skipped days don't add to it
+	RestDaysPerWeek int `json:"rest_days_per_week"`
+	// Completed practice a day needs to count; 0 counts any completed session
+	MinMinutes int `json:"min_minutes"`
+	// Sessions started this many hours after midnight still count for the day before
+	GraceHours int `json:"grace_hours"`
+}
+
+// StreakRules are per-user overrides of the deployment's streak policy; nil falls back to it
+type StreakRules struct {
+	RestDaysPerWeek *int `json:"rest_days_per_week" db:"rest_days_per_week"`
+	MinMinutes      *int `json:"min_minutes" db:"min_minutes"`
+	GraceHours      *int `json:"grace_hours" db:"grace_hours"`
+}
+
+// UserStreakPolicy is a user's overrides and the streak policy that results
+type UserStreakPolicy struct {
+	UserID    uuid.UUID    `json:"user_id"`
+	Overrides StreakRules  `json:"overrides"`
+	Policy    StreakPolicy `json:"policy"`
+}
+
+// StreakSnapshot is a user's streaks as computed for one day
+type StreakSnapshot struct {
+	CurrentStreak int       `db:"current_streak"`
+	LongestStreak int       `db:"longest_streak"`
+	AsOf          time.Time `db:"as_of"` // Midnight UTC of the day computed for
+}
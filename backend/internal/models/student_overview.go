@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StudentOverview is everything an instructor needs on a student's detail page
+type StudentOverview struct {
+	Profile         UserResponse      `json:"profile"`
+	Stats           SessionStats      `json:"stats"` // Includes the current and longest streak
+	Programs        []StudentProgram  `json:"programs"`
+	RecentSessions  []PracticeSession `json:"recent_sessions"`
+	OpenSubmissions []OpenSubmission  `json:"open_submissions"`
+	Notes           []SessionNote     `json:"notes"` // Latest instructor notes on their sessions, private ones included
+}
+
+// StudentProgram is one of a student's programs with their progress through it
+type StudentProgram struct {
+	Program  Program            `json:"program"`
+	Progress RepetitionProgress `json:"progress"`
+}
+
+// StudentAppState is what a student's app loads on launch, as the student's own requests would
+// return it, so support staff can see what the student sees without signing in as them
+type StudentAppState struct {
+	Locale       string                 `json:"locale"`        // The student's language the programs were localized to
+	Programs     []ProgramWithExercises `json:"programs"`      // As GET /my-programs returns them
+	UnreadCounts UnreadCounts           `json:"unread_counts"` // As GET /submissions/unread-count returns them
+	Stats        SessionStats           `json:"stats"`         // As GET /sessions/stats returns them
+}
+
+// OpenSubmission is a submission thread waiting for instructor feedback
+type OpenSubmission struct {
+	ID           uuid.UUID `json:"id"`
+	ProgramID    uuid.UUID `json:"program_id"`
+	ProgramName  string    `json:"program_name"`
+	Title        string    `json:"title"`
+	CreatedAt    time.Time `json:"created_at"`
+	WaitingSince time.Time `json:"waiting_since"` // The student's last message, or the submission itself
+}
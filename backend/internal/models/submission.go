@@ -19,12 +19,42 @@ type Submission struct {
 
 // SubmissionMessage represents an individual message in a submission conversation
 type SubmissionMessage struct {
-	ID           uuid.UUID `json:"id" db:"id"`
+	ID           uuid.UUID   `json:"id" db:"id"`
+	SubmissionID uuid.UUID   `json:"submission_id" db:"submission_id"`
+	UserID       uuid.UUID   `json:"user_id" db:"user_id"` // Author (student or instructor)
+	Content      string      `json:"content" db:"content"`
+	YouTubeURL   *string     `json:"youtube_url,omitempty" db:"youtube_url"`
+	Mentions     []uuid.UUID `json:"mentions,omitempty"` // Users @mentioned in the content
+	// Hidden by moderation; hidden messages are only shown to their author and admins
+	HiddenAt  *time.Time `json:"hidden_at,omitempty" db:"hidden_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// SubmissionDraft is a user's unsent message in a submission, saved while they type
+type SubmissionDraft struct {
 	SubmissionID uuid.UUID `json:"submission_id" db:"submission_id"`
-	UserID       uuid.UUID `json:"user_id" db:"user_id"` // Author (student or instructor)
+	UserID       uuid.UUID `json:"user_id" db:"user_id"`
 	Content      string    `json:"content" db:"content"`
 	YouTubeURL   *string   `json:"youtube_url,omitempty" db:"youtube_url"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TypingUser is someone currently typing in a submission thread. Typing is ephemeral and
+// expires unless the client keeps sending typing starts.
+type TypingUser struct {
+	UserID    uuid.UUID `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SubmissionTemplate tells students how to submit for a program. New submissions must have a
+// title matching TitlePattern, if set; the prompts are guidance only.
+type SubmissionTemplate struct {
+	ProgramID    uuid.UUID  `json:"program_id" db:"program_id"`
+	TitlePattern *string    `json:"title_pattern,omitempty" db:"title_pattern"` // Go regular expression matching the whole title
+	TitleExample *string    `json:"title_example,omitempty" db:"title_example"` // A title matching the pattern, shown to students
+	Prompts      []string   `json:"prompts" db:"prompts"`                       // e.g. "Film from the side, 2 minutes"
+	UpdatedBy    *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // MessageReadStatus tracks which users have read which messages
@@ -51,6 +81,9 @@ type SubmissionListItem struct {
 	LastMessageAt   time.Time `json:"last_message_at" db:"last_message_at"`
 	LastMessageText string    `json:"last_message_text" db:"last_message_text"`
 	LastMessageFrom string    `json:"last_message_from" db:"last_message_from"`
+	Archived        bool      `json:"archived"` // Archived by the current user
+	// Labels attached by instructors; only shown to instructors
+	Labels []SubmissionLabel `json:"labels,omitempty"`
 }
 
 // MessageWithAuthor includes message with author details
@@ -60,6 +93,15 @@ type MessageWithAuthor struct {
 	AuthorEmail string   `json:"author_email" db:"author_email"`
 	AuthorRole  UserRole `json:"author_role" db:"author_role"`
 	IsRead      bool     `json:"is_read" db:"is_read"` // For current user
+	// Who else read the message and when, oldest first. Only shown to the author and instructors.
+	ReadBy []MessageReader `json:"read_by,omitempty"`
+}
+
+// MessageReader is a user who read a message
+type MessageReader struct {
+	UserID   uuid.UUID `json:"user_id" db:"user_id"`
+	FullName string    `json:"full_name" db:"full_name"`
+	ReadAt   time.Time `json:"read_at" db:"read_at"`
 }
 
 // UnreadCounts holds unread message counts at various levels
@@ -68,3 +110,46 @@ type UnreadCounts struct {
 	ByProgram    map[string]int `json:"by_program"`
 	BySubmission map[string]int `json:"by_submission"`
 }
+
+// SubmissionSearchResult is a submission matching a search query. Highlights are plain text
+// with the matched terms wrapped in <mark></mark>; the text itself is not HTML-escaped.
+type SubmissionSearchResult struct {
+	Submission
+	ProgramName    string                  `json:"program_name" db:"program_name"`
+	StudentName    string                  `json:"student_name" db:"student_name"`
+	TitleHighlight string                  `json:"title_highlight" db:"title_highlight"`
+	Message        *SubmissionMessageMatch `json:"message,omitempty"`      // Best matching message, if any
+	Archived       bool                    `json:"archived" db:"archived"` // Archived by the current user
+	Rank           float64                 `json:"rank" db:"rank"`
+}
+
+// SubmissionMessageMatch is the best matching message of a search result
+type SubmissionMessageMatch struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	AuthorName string    `json:"author_name" db:"author_name"`
+	Snippet    string    `json:"snippet" db:"snippet"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// ReviewAnalytics summarizes how submission reviews are spread across instructors
+type ReviewAnalytics struct {
+	Days              int                     `json:"days"`
+	UnansweredThreads int                     `json:"unanswered_threads"` // Waiting for a reply, no instructor has answered yet
+	Instructors       []InstructorReviewStats `json:"instructors"`
+}
+
+// InstructorReviewStats are one instructor's review metrics. Counts and response times cover
+// the analytics window; open threads are the current state.
+type InstructorReviewStats struct {
+	InstructorID uuid.UUID `json:"instructor_id"`
+	FullName     string    `json:"full_name"`
+	Email        string    `json:"email"`
+	// Threads the instructor has answered in whose latest message is from the student
+	OpenThreads     int     `json:"open_threads"`
+	ThreadsReviewed int     `json:"threads_reviewed"`
+	Messages        int     `json:"messages"`
+	MessagesPerWeek float64 `json:"messages_per_week"`
+	// Time from a student message to the first instructor reply, for replies by this instructor
+	MedianFirstResponseMinutes *float64 `json:"median_first_response_minutes"`
+	Responses                  int      `json:"responses"`
+}
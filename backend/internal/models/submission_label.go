@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultLabelColor is used for labels created without a color
+const DefaultLabelColor = "#9e9e9e"
+
+// SubmissionLabel is an instructor-defined label such as "needs-follow-up" for organizing the
+// review queue. Labels are shared by all instructors and hidden from students.
+type SubmissionLabel struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	Name            string     `json:"name" db:"name"`
+	Color           string     `json:"color" db:"color"` // #rrggbb
+	CreatedBy       *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	SubmissionCount int        `json:"submission_count" db:"submission_count"` // Only filled in when listing labels
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
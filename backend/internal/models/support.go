@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SupportCategory string
+
+const (
+	SupportTechnical SupportCategory = "technical" // The app, logins, video playback
+	SupportAccount   SupportCategory = "account"
+	SupportBilling   SupportCategory = "billing"
+	SupportOther     SupportCategory = "other"
+)
+
+type SupportStatus string
+
+const (
+	SupportOpen     SupportStatus = "open"     // Waiting for an admin
+	SupportAnswered SupportStatus = "answered" // Waiting for the student
+	SupportClosed   SupportStatus = "closed"
+)
+
+// SupportTicket is a question to the admins that is not about a program
+type SupportTicket struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	UserID         uuid.UUID       `json:"user_id" db:"user_id"` // Who opened it
+	UserName       string          `json:"user_name" db:"user_name"`
+	Category       SupportCategory `json:"category" db:"category"`
+	Status         SupportStatus   `json:"status" db:"status"`
+	MessageCount   int             `json:"message_count" db:"message_count"`
+	LastActivityAt time.Time       `json:"last_activity_at" db:"last_activity_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	ClosedAt       *time.Time      `json:"closed_at,omitempty" db:"closed_at"`
+}
+
+// SupportMessage is a message in a support ticket, from the student or an admin
+type SupportMessage struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	TicketID   uuid.UUID `json:"ticket_id" db:"ticket_id"`
+	AuthorID   uuid.UUID `json:"author_id" db:"author_id"`
+	AuthorName string    `json:"author_name" db:"author_name"`
+	AuthorRole UserRole  `json:"author_role" db:"author_role"`
+	Content    string    `json:"content" db:"content"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// SupportTicketWithMessages is a ticket with its messages, oldest first
+type SupportTicketWithMessages struct {
+	SupportTicket
+	Messages []SupportMessage `json:"messages"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "github.com/google/uuid"
+
+type CueType string
+
+const (
+	CuePrepStart     CueType = "prep_start" // Getting into position before a paced exercise
+	CueExerciseStart CueType = "exercise_start"
+	CueSideSwitch    CueType = "side_switch"
+	CueHalfway       CueType = "halfway"
+	CueExerciseEnd   CueType = "exercise_end"
+	CueRestStart     CueType = "rest_start"
+	CueRestEnd       CueType = "rest_end"
+	CueSessionEnd    CueType = "session_end"
+)
+
+// Cue is a single event on a program timeline.
+// AtSeconds is the offset from the start of the session.
+type Cue struct {
+	AtSeconds    int        `json:"at_seconds"`
+	Type         CueType    `json:"type"`
+	ExerciseID   *uuid.UUID `json:"exercise_id,omitempty"`
+	ExerciseName string     `json:"exercise_name,omitempty"`
+	// SubstituteID is set when the student does a substitute instead; ExerciseName is then its name
+	SubstituteID    *uuid.UUID `json:"substitute_id,omitempty"`
+	Side            string     `json:"side,omitempty"`
+	DurationSeconds int        `json:"duration_seconds,omitempty"`
+	Repetitions     *int       `json:"repetitions,omitempty"`
+	// SecondsPerRep and TransitionSeconds pace the metronome of a repetition exercise
+	SecondsPerRep     int `json:"seconds_per_rep,omitempty"`
+	TransitionSeconds int `json:"transition_seconds,omitempty"`
+	// BreathingPattern paces the breath guide of an exercise defined by breath counts
+	BreathingPattern *BreathingPattern `json:"breathing_pattern,omitempty"`
+	// AwaitsCompletion marks untimed exercises the student confirms manually.
+	// The clock does not advance for them, so later offsets assume immediate confirmation.
+	AwaitsCompletion bool `json:"awaits_completion,omitempty"`
+	// AudioURL points to the pre-generated spoken clip, if one exists in the user's language
+	AudioURL string `json:"audio_url,omitempty"`
+}
+
+// Timeline is the canonical, flattened cue sequence for practicing a program
+type Timeline struct {
+	ProgramID            uuid.UUID `json:"program_id"`
+	TotalDurationSeconds int       `json:"total_duration_seconds"`
+	Cues                 []Cue     `json:"cues"`
+	// CountAudioURLs maps repetition counts to spoken number clips
+	CountAudioURLs map[int]string `json:"count_audio_urls,omitempty"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TranslatableType identifies the kind of content a translation belongs to
+type TranslatableType string
+
+const (
+	TranslatableProgram  TranslatableType = "program"
+	TranslatableExercise TranslatableType = "exercise"
+)
+
+// Translation holds the localized name and description of a program or exercise
+type Translation struct {
+	EntityID    uuid.UUID  `json:"entity_id" db:"entity_id"`
+	Locale      string     `json:"locale" db:"locale"`
+	Name        string     `json:"name" db:"name"`
+	Description string     `json:"description" db:"description"`
+	UpdatedBy   *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessLog is a single authenticated API request
+type AccessLog struct {
+	ID        int64     `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Method    string    `json:"method" db:"method"`
+	Route     string    `json:"route" db:"route"`
+	Status    int       `json:"status" db:"status"`
+	ClientIP  *string   `json:"client_ip,omitempty" db:"client_ip"`
+	UserAgent *string   `json:"user_agent,omitempty" db:"user_agent"`
+	Device    *string   `json:"device,omitempty" db:"device"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// InstructorPresence tells students whether an instructor is around to reply
+type InstructorPresence struct {
+	UserID   uuid.UUID `json:"user_id"`
+	FullName string    `json:"full_name"`
+	// Online means the instructor used the app within PRESENCE_ONLINE_SECONDS
+	Online     bool       `json:"online"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// UserUsage aggregates a user's API activity over a time window
+type UserUsage struct {
+	UserID        uuid.UUID  `json:"user_id"`
+	Email         string     `json:"email"`
+	FullName      string     `json:"full_name"`
+	Role          UserRole   `json:"role"`
+	RequestCount  int        `json:"request_count"`
+	ActiveDays    int        `json:"active_days"`
+	LastActiveAt  *time.Time `json:"last_active_at,omitempty"`
+	LastUserAgent *string    `json:"last_user_agent,omitempty"`
+	LastDevice    *string    `json:"last_device,omitempty"`
+	Devices       []string   `json:"devices"`
+}
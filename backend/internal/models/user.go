@@ -24,6 +24,7 @@ type User struct {
 	StartVolume     int       `json:"start_volume" db:"start_volume"`
 	HalfwayVolume   int       `json:"halfway_volume" db:"halfway_volume"`
 	FinishVolume    int       `json:"finish_volume" db:"finish_volume"`
+	Language        string    `json:"language" db:"language"`
 	CreatedAt       time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -39,6 +40,7 @@ type UserResponse struct {
 	StartVolume     int       `json:"start_volume"`
 	HalfwayVolume   int       `json:"halfway_volume"`
 	FinishVolume    int       `json:"finish_volume"`
+	Language        string    `json:"language"`
 	CreatedAt       time.Time `json:"created_at"`
 }
 
@@ -53,6 +55,7 @@ func (u *User) ToResponse() *UserResponse {
 		StartVolume:     u.StartVolume,
 		HalfwayVolume:   u.HalfwayVolume,
 		FinishVolume:    u.FinishVolume,
+		Language:        u.Language,
 		CreatedAt:       u.CreatedAt,
 	}
 }
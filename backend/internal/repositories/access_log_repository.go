@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+type AccessLogRepository struct {
+	db database.DB
+}
+
+func NewAccessLogRepository(db database.DB) *AccessLogRepository {
+	return &AccessLogRepository{db: db}
+}
+
+// CreateBatch stores access log entries in one statement, with the time each request was made
+func (r *AccessLogRepository) CreateBatch(ctx context.Context, entries []models.AccessLog) error {
+	userIDs := make([]uuid.UUID, len(entries))
+	methods := make([]string, len(entries))
+	routes := make([]string, len(entries))
+	statuses := make([]int, len(entries))
+	clientIPs := make([]*string, len(entries))
+	userAgents := make([]*string, len(entries))
+	devices := make([]*string, len(entries))
+	createdAt := make([]time.Time, len(entries))
+	for i, entry := range entries {
+		userIDs[i] = entry.UserID
+		methods[i] = entry.Method
+		routes[i] = entry.Route
+		statuses[i] = entry.Status
+		clientIPs[i] = entry.ClientIP
+		userAgents[i] = entry.UserAgent
+		devices[i] = entry.Device
+		createdAt[i] = entry.CreatedAt
+	}
+
+	query := `
+		INSERT INTO access_logs (user_id, method, route, status, client_ip, user_agent, device, created_at)
+		SELECT * FROM unnest($1::uuid[], $2::text[], $3::text[], $4::smallint[], $5::text[], $6::text[], $7::text[], $8::timestamp[])
+	`
+	_, err := r.db.Exec(ctx, query, userIDs, methods, routes, statuses, clientIPs, userAgents, devices, createdAt)
+	return err
+}
+
+// InstructorsLastSeen returns the active instructors with the time of their latest request,
+// most recently seen first
+func (r *AccessLogRepository) InstructorsLastSeen(ctx context.Context) ([]models.InstructorPresence, error) {
+	query := `
+		SELECT u.id, u.full_name, l.created_at
+		FROM users u
+		LEFT JOIN LATERAL (
+			SELECT created_at
+			FROM access_logs
+			WHERE user_id = u.id
+			ORDER BY created_at DESC
+			LIMIT 1
+		) l ON true
+		WHERE u.role = 'admin' AND u.is_active = true
+		ORDER BY l.created_at DESC NULLS LAST, u.full_name
+	`
+	return collectWithRetry(ctx, r.db, "access_logs.InstructorsLastSeen", query, func(row pgx.CollectableRow) (models.InstructorPresence, error) {
+		var p models.InstructorPresence
+		err := row.Scan(&p.UserID, &p.FullName, &p.LastSeenAt)
+		return p, err
+	})
+}
+
+// GetUsage aggregates request counts per user since the given time.
+// Last activity and client info come from the user's most recent request regardless of the window.
+func (r *AccessLogRepository) GetUsage(ctx context.Context, since time.Time) ([]models.UserUsage, error) {
+	query := `
+		SELECT
+			u.id, u.email, u.full_name, u.role,
+			COALESCE(a.request_count, 0),
+			COALESCE(a.active_days, 0),
+			l.created_at, l.user_agent, l.device,
+			COALESCE(a.devices, '{}')
+		FROM users u
+		LEFT JOIN (
+			SELECT
+				user_id,
+				COUNT(*) AS request_count,
+				COUNT(DISTINCT DATE(created_at)) AS active_days,
+				ARRAY_AGG(DISTINCT COALESCE(device, user_agent))
+					FILTER (WHERE COALESCE(device, user_agent) IS NOT NULL) AS devices
+			FROM access_logs
+			WHERE created_at >= $1
+			GROUP BY user_id
+		) a ON a.user_id = u.id
+		LEFT JOIN LATERAL (
+			SELECT created_at, user_agent, device
+			FROM access_logs
+			WHERE user_id = u.id
+			ORDER BY created_at DESC
+			LIMIT 1
+		) l ON true
+		ORDER BY l.created_at DESC NULLS LAST, u.email
+	`
+	rows, err := r.db.Query(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := make([]models.UserUsage, 0)
+	for rows.Next() {
+		var u models.UserUsage
+		err := rows.Scan(
+			&u.UserID,
+			&u.Email,
+			&u.FullName,
+			&u.Role,
+			&u.RequestCount,
+			&u.ActiveDays,
+			&u.LastActiveAt,
+			&u.LastUserAgent,
+			&u.LastDevice,
+			&u.Devices,
+		)
+		if err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+
+	return usage, rows.Err()
+}
@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/testutil"
+)
+
+// Benchmarks for the hottest read queries. Like the other repository tests they need the
+// test database; run them with `make bench`. Each benchmark seeds a fixed dataset inside a
+// rolled-back transaction, so results are comparable between branches. The savepoint the
+// test transaction wraps around every statement adds a constant overhead, so compare
+// numbers with each other rather than with production latencies.
+
+const (
+	benchStudents              = 50
+	benchSubmissionsPerStudent = 10
+	benchMessagesPerSubmission = 8
+	benchSessions              = 1000
+)
+
+type submissionDataset struct {
+	admin   *models.User
+	student *models.User
+}
+
+// seedSubmissions creates benchStudents students with benchSubmissionsPerStudent submissions
+// each. Messages alternate between student and admin, and the admin has read the first half.
+func seedSubmissions(b *testing.B, db database.DB) submissionDataset {
+	b.Helper()
+
+	admin := testutil.NewUserBuilder().AsAdmin().Create(b, db)
+	program := testutil.NewProgramBuilder().OwnedBy(admin).Create(b, db)
+
+	studentIDs := make([]string, benchStudents)
+	var first *models.User
+	for i := range studentIDs {
+		student := testutil.NewUserBuilder().Create(b, db)
+		if first == nil {
+			first = student
+		}
+		studentIDs[i] = student.ID.String()
+	}
+
+	testutil.ExecuteSQL(b, db, `
+		INSERT INTO submissions (program_id, user_id, title, created_at, updated_at)
+		SELECT $1, u.id, 'Submission ' || n, now() - n * interval '1 hour', now() - n * interval '1 hour'
+		FROM unnest($2::uuid[]) AS u(id)
+		CROSS JOIN generate_series(1, $3) AS n
+	`, program.ID, studentIDs, benchSubmissionsPerStudent)
+
+	testutil.ExecuteSQL(b, db, `
+		INSERT INTO submission_messages (submission_id, user_id, content, created_at)
+		SELECT s.id, CASE WHEN n % 2 = 0 THEN $2 ELSE s.user_id END, 'Message ' || n, s.created_at + n * interval '1 minute'
+		FROM submissions s
+		CROSS JOIN generate_series(1, $3) AS n
+		WHERE s.program_id = $1
+	`, program.ID, admin.ID, benchMessagesPerSubmission)
+
+	testutil.ExecuteSQL(b, db, `
+		INSERT INTO message_read_status (user_id, message_id)
+		SELECT $2, sm.id
+		FROM submission_messages sm
+		JOIN submissions s ON s.id = sm.submission_id
+		WHERE s.program_id = $1 AND sm.user_id != $2
+		  AND sm.created_at <= s.created_at + $3 * interval '1 minute'
+	`, program.ID, admin.ID, benchMessagesPerSubmission/2)
+
+	return submissionDataset{admin: admin, student: first}
+}
+
+func BenchmarkSubmissionRepository_List(b *testing.B) {
+	db := testutil.SetupTestTx(b)
+	data := seedSubmissions(b, db)
+	repo := NewSubmissionRepository(db)
+	ctx := context.Background()
+
+	b.Run("admin", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := repo.List(ctx, nil, nil, data.admin.ID, true, false, 20, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("student", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := repo.List(ctx, nil, nil, data.student.ID, false, false, 20, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkSubmissionRepository_GetUnreadCount(b *testing.B) {
+	db := testutil.SetupTestTx(b)
+	data := seedSubmissions(b, db)
+	repo := NewSubmissionRepository(db)
+	ctx := context.Background()
+
+	b.Run("admin", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := repo.GetUnreadCount(ctx, data.admin.ID, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("student", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := repo.GetUnreadCount(ctx, data.student.ID, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkSessionRepository_GetStats(b *testing.B) {
+	db := testutil.SetupTestTx(b)
+	student := testutil.NewUserBuilder().Create(b, db)
+	program := testutil.NewProgramBuilder().Create(b, db)
+
+	// One session a day, with a gap every 30 days so streaks have to be computed
+	testutil.ExecuteSQL(b, db, `
+		INSERT INTO practice_sessions (
+			user_id, program_id, started_at, completed_at,
+			total_duration_seconds, completion_rate, mood, energy
+		)
+		SELECT $1, $2, now() - n * interval '1 day', now() - n * interval '1 day' + interval '30 minutes',
+		       1800, 100, 1 + n % 5, 1 + (n / 2) % 5
+		FROM generate_series(0, $3 - 1) AS n
+		WHERE n % 30 != 29
+	`, student.ID, program.ID, benchSessions)
+
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := repo.GetStats(ctx, student.ID); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
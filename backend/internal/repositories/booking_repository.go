@@ -0,0 +1,259 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/clock"
+)
+
+// ErrSlotTaken is returned when a slot already has a confirmed booking
+var ErrSlotTaken = errors.New("slot is already booked")
+
+const slotSelect = `
+	SELECT s.id, s.instructor_id, u.full_name, s.starts_at, s.ends_at,
+	       EXISTS(SELECT 1 FROM bookings b WHERE b.slot_id = s.id AND b.status = 'confirmed'),
+	       s.created_at
+	FROM availability_slots s
+	JOIN users u ON u.id = s.instructor_id
+`
+
+const bookingSelect = `
+	SELECT b.id, b.slot_id, b.student_id, su.full_name, su.email, s.instructor_id, iu.full_name, iu.email,
+	       b.program_id, p.name, s.starts_at, s.ends_at, b.note, b.status, b.cancelled_by, b.cancel_reason,
+	       b.cancelled_at, b.meeting_provider, b.meeting_id, b.meeting_url, b.created_at
+	FROM bookings b
+	JOIN availability_slots s ON s.id = b.slot_id
+	JOIN users su ON su.id = b.student_id
+	JOIN users iu ON iu.id = s.instructor_id
+	JOIN programs p ON p.id = b.program_id
+`
+
+type BookingRepository struct {
+	db    database.DB
+	clock clock.Clock
+}
+
+func NewBookingRepository(db database.DB) *BookingRepository {
+	return &BookingRepository{db: db, clock: clock.System}
+}
+
+// WithClock replaces the clock used for timestamps, so tests can control time
+func (r *BookingRepository) WithClock(c clock.Clock) *BookingRepository {
+	r.clock = c
+	return r
+}
+
+func (r *BookingRepository) CreateSlot(ctx context.Context, slot *models.AvailabilitySlot) error {
+	query := `
+		INSERT INTO availability_slots (instructor_id, starts_at, ends_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(ctx, query, slot.InstructorID, slot.StartsAt, slot.EndsAt).Scan(&slot.ID, &slot.CreatedAt)
+}
+
+// SlotOverlaps reports whether the instructor already has a slot overlapping [start, end)
+func (r *BookingRepository) SlotOverlaps(ctx context.Context, instructorID uuid.UUID, start, end time.Time) (bool, error) {
+	var overlaps bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM availability_slots
+			WHERE instructor_id = $1 AND starts_at < $3 AND ends_at > $2
+		)
+	`
+	err := r.db.QueryRow(ctx, query, instructorID, start, end).Scan(&overlaps)
+	return overlaps, err
+}
+
+// GetSlot returns a slot by ID, nil if it does not exist
+func (r *BookingRepository) GetSlot(ctx context.Context, id uuid.UUID) (*models.AvailabilitySlot, error) {
+	slot, err := scanSlot(r.db.QueryRow(ctx, slotSelect+`WHERE s.id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return slot, nil
+}
+
+// ListSlots returns slots starting in [from, to), optionally for one instructor.
+// Booked slots are only included if includeBooked is set.
+func (r *BookingRepository) ListSlots(ctx context.Context, from, to time.Time, instructorID *uuid.UUID, includeBooked bool) ([]models.AvailabilitySlot, error) {
+	query := slotSelect + `
+		WHERE s.starts_at >= $1 AND s.starts_at < $2
+		  AND ($3::uuid IS NULL OR s.instructor_id = $3)
+		  AND ($4 OR NOT EXISTS(SELECT 1 FROM bookings b WHERE b.slot_id = s.id AND b.status = 'confirmed'))
+		ORDER BY s.starts_at, u.full_name
+	`
+	rows, err := r.db.Query(ctx, query, from, to, instructorID, includeBooked)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slots := make([]models.AvailabilitySlot, 0)
+	for rows.Next() {
+		slot, err := scanSlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, *slot)
+	}
+	return slots, rows.Err()
+}
+
+// DeleteSlot removes a slot and its cancelled bookings
+func (r *BookingRepository) DeleteSlot(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM availability_slots WHERE id = $1`, id)
+	return err
+}
+
+// CreateBooking books a slot, returning ErrSlotTaken if it already has a confirmed booking
+func (r *BookingRepository) CreateBooking(ctx context.Context, booking *models.Booking) error {
+	query := `
+		INSERT INTO bookings (slot_id, student_id, program_id, note, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, created_at
+	`
+	err := r.db.QueryRow(ctx, query,
+		booking.SlotID,
+		booking.StudentID,
+		booking.ProgramID,
+		booking.Note,
+		r.clock.Now(),
+	).Scan(&booking.ID, &booking.Status, &booking.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrSlotTaken
+	}
+	return err
+}
+
+// StudentHasOverlap reports whether the student has a confirmed booking overlapping [start, end)
+func (r *BookingRepository) StudentHasOverlap(ctx context.Context, studentID uuid.UUID, start, end time.Time) (bool, error) {
+	var overlaps bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM bookings b
+			JOIN availability_slots s ON s.id = b.slot_id
+			WHERE b.student_id = $1 AND b.status = 'confirmed' AND s.starts_at < $3 AND s.ends_at > $2
+		)
+	`
+	err := r.db.QueryRow(ctx, query, studentID, start, end).Scan(&overlaps)
+	return overlaps, err
+}
+
+// GetBooking returns a booking by ID, nil if it does not exist
+func (r *BookingRepository) GetBooking(ctx context.Context, id uuid.UUID) (*models.Booking, error) {
+	booking, err := scanBooking(r.db.QueryRow(ctx, bookingSelect+`WHERE b.id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// ListBookings returns the bookings the user made or received as instructor, soonest first.
+// With upcomingOnly, only confirmed bookings that have not ended are returned.
+func (r *BookingRepository) ListBookings(ctx context.Context, userID uuid.UUID, upcomingOnly bool) ([]models.Booking, error) {
+	query := bookingSelect + `
+		WHERE (b.student_id = $1 OR s.instructor_id = $1)
+		  AND ($2 = false OR (b.status = 'confirmed' AND s.ends_at > $3))
+		ORDER BY s.starts_at
+	`
+	rows, err := r.db.Query(ctx, query, userID, upcomingOnly, r.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := make([]models.Booking, 0)
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, *booking)
+	}
+	return bookings, rows.Err()
+}
+
+// CancelBooking cancels a confirmed booking and reports whether it was still confirmed
+func (r *BookingRepository) CancelBooking(ctx context.Context, id, cancelledBy uuid.UUID, reason *string) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE bookings SET status = 'cancelled', cancelled_by = $2, cancel_reason = $3, cancelled_at = $4
+		WHERE id = $1 AND status = 'confirmed'
+	`, id, cancelledBy, reason, r.clock.Now())
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// SetMeeting stores the video meeting created for a booking
+func (r *BookingRepository) SetMeeting(ctx context.Context, id uuid.UUID, provider, meetingID, meetingURL string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE bookings SET meeting_provider = $2, meeting_id = $3, meeting_url = $4
+		WHERE id = $1
+	`, id, provider, meetingID, meetingURL)
+	return err
+}
+
+func scanSlot(row pgx.Row) (*models.AvailabilitySlot, error) {
+	var slot models.AvailabilitySlot
+	err := row.Scan(
+		&slot.ID,
+		&slot.InstructorID,
+		&slot.InstructorName,
+		&slot.StartsAt,
+		&slot.EndsAt,
+		&slot.IsBooked,
+		&slot.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &slot, nil
+}
+
+func scanBooking(row pgx.Row) (*models.Booking, error) {
+	var booking models.Booking
+	err := row.Scan(
+		&booking.ID,
+		&booking.SlotID,
+		&booking.StudentID,
+		&booking.StudentName,
+		&booking.StudentEmail,
+		&booking.InstructorID,
+		&booking.InstructorName,
+		&booking.InstructorEmail,
+		&booking.ProgramID,
+		&booking.ProgramName,
+		&booking.StartsAt,
+		&booking.EndsAt,
+		&booking.Note,
+		&booking.Status,
+		&booking.CancelledBy,
+		&booking.CancelReason,
+		&booking.CancelledAt,
+		&booking.MeetingProvider,
+		&booking.MeetingID,
+		&booking.MeetingURL,
+		&booking.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/richtext"
+)
+
+type ChangelogRepository struct {
+	db database.DB
+}
+
+func NewChangelogRepository(db database.DB) *ChangelogRepository {
+	return &ChangelogRepository{db: db}
+}
+
+const changelogColumns = `e.id, e.version, e.title, e.body, e.audience, e.created_by, e.created_at, e.updated_at`
+
+func scanChangelogEntry(row pgx.Row, entry *models.ChangelogEntry) error {
+	err := row.Scan(
+		&entry.ID,
+		&entry.Version,
+		&entry.Title,
+		&entry.Body,
+		&entry.Audience,
+		&entry.CreatedBy,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	entry.RenderedHTML = richtext.Render(entry.Body)
+	return nil
+}
+
+func (r *ChangelogRepository) Create(ctx context.Context, entry *models.ChangelogEntry) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO changelog_entries (version, title, body, audience, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`, entry.Version, entry.Title, entry.Body, entry.Audience, entry.CreatedBy).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	entry.RenderedHTML = richtext.Render(entry.Body)
+	return nil
+}
+
+// GetByID returns the entry, or nil if it does not exist
+func (r *ChangelogRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ChangelogEntry, error) {
+	query := `SELECT ` + changelogColumns + ` FROM changelog_entries e WHERE e.id = $1`
+
+	var entry models.ChangelogEntry
+	err := database.Retry(ctx, "changelog_entries.GetByID", func() error {
+		return scanChangelogEntry(r.db.QueryRow(ctx, query, id), &entry)
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// List returns entries for the given audiences, or all entries if audiences is nil, newest first
+func (r *ChangelogRepository) List(ctx context.Context, audiences []models.ChangelogAudience, limit, offset int) ([]models.ChangelogEntry, error) {
+	query := `
+		SELECT ` + changelogColumns + `
+		FROM changelog_entries e
+		WHERE $1::varchar[] IS NULL OR e.audience = ANY($1)
+		ORDER BY e.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	return collectWithRetry(ctx, r.db, "changelog_entries.List", query, changelogEntryRow, audienceValues(audiences), limit, offset)
+}
+
+// ListUnseen returns the entries for the given audiences the user has not dismissed, newest
+// first. Entries written before the user signed up are left out; they describe changes the
+// user never saw the app without.
+func (r *ChangelogRepository) ListUnseen(ctx context.Context, userID uuid.UUID, audiences []models.ChangelogAudience) ([]models.ChangelogEntry, error) {
+	query := `
+		SELECT ` + changelogColumns + `
+		FROM changelog_entries e
+		JOIN users u ON u.id = $1
+		WHERE e.audience = ANY($2)
+		  AND e.created_at >= u.created_at
+		  AND NOT EXISTS (
+			SELECT 1 FROM changelog_dismissals d WHERE d.user_id = $1 AND d.entry_id = e.id
+		  )
+		ORDER BY e.created_at DESC
+	`
+	return collectWithRetry(ctx, r.db, "changelog_entries.ListUnseen", query, changelogEntryRow, userID, audienceValues(audiences))
+}
+
+func (r *ChangelogRepository) Update(ctx context.Context, entry *models.ChangelogEntry) error {
+	err := r.db.QueryRow(ctx, `
+		UPDATE changelog_entries
+		SET version = $2, title = $3, body = $4, audience = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING updated_at
+	`, entry.ID, entry.Version, entry.Title, entry.Body, entry.Audience).Scan(&entry.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	entry.RenderedHTML = richtext.Render(entry.Body)
+	return nil
+}
+
+// Delete removes the entry and reports whether it existed
+func (r *ChangelogRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM changelog_entries WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// Dismiss marks entries as seen by the user. Unknown and already dismissed entries are ignored.
+func (r *ChangelogRepository) Dismiss(ctx context.Context, userID uuid.UUID, entryIDs []uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO changelog_dismissals (user_id, entry_id)
+		SELECT $1, e.id FROM changelog_entries e WHERE e.id = ANY($2)
+		ON CONFLICT DO NOTHING
+	`, userID, entryIDs)
+	return err
+}
+
+// audienceValues converts audiences to strings for a varchar[] parameter, keeping nil as NULL
+func audienceValues(audiences []models.ChangelogAudience) []string {
+	if audiences == nil {
+		return nil
+	}
+	values := make([]string, len(audiences))
+	for i, audience := range audiences {
+		values[i] = string(audience)
+	}
+	return values
+}
+
+func changelogEntryRow(row pgx.CollectableRow) (models.ChangelogEntry, error) {
+	var entry models.ChangelogEntry
+	err := scanChangelogEntry(row, &entry)
+	return entry, err
+}
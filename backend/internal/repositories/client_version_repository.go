@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+type ClientVersionRepository struct {
+	db database.DB
+}
+
+func NewClientVersionRepository(db database.DB) *ClientVersionRepository {
+	return &ClientVersionRepository{db: db}
+}
+
+// List returns the platforms whose policy admins have overridden
+func (r *ClientVersionRepository) List(ctx context.Context) ([]models.ClientVersionPolicy, error) {
+	query := `
+		SELECT platform, min_version, upgrade_url, updated_by, updated_at
+		FROM client_version_policies
+		ORDER BY platform
+	`
+	policies, err := collectWithRetry(ctx, r.db, "client_versions.List", query, func(row pgx.CollectableRow) (models.ClientVersionPolicy, error) {
+		var policy models.ClientVersionPolicy
+		err := row.Scan(&policy.Platform, &policy.MinVersion, &policy.UpgradeURL, &policy.UpdatedBy, &policy.UpdatedAt)
+		return policy, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list client version policies: %w", err)
+	}
+	return policies, nil
+}
+
+// Save overrides the platform's policy
+func (r *ClientVersionRepository) Save(ctx context.Context, policy *models.ClientVersionPolicy) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO client_version_policies (platform, min_version, upgrade_url, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (platform) DO UPDATE
+		SET min_version = EXCLUDED.min_version, upgrade_url = EXCLUDED.upgrade_url,
+		    updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`, policy.Platform, policy.MinVersion, policy.UpgradeURL, policy.UpdatedBy).Scan(&policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save client version policy: %w", err)
+	}
+	return nil
+}
+
+// Delete drops the platform's override and reports whether there was one
+func (r *ClientVersionRepository) Delete(ctx context.Context, platform string) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM client_version_policies WHERE platform = $1`, platform)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete client version policy: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
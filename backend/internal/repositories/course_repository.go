@@ -0,0 +1,367 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+const courseSelect = `
+	SELECT c.id, c.name, c.description, c.is_published, c.created_by,
+	       (SELECT COUNT(*) FROM course_modules m WHERE m.course_id = c.id),
+	       (SELECT COUNT(*) FROM course_enrollments e WHERE e.course_id = c.id),
+	       c.created_at, c.updated_at
+	FROM courses c
+`
+
+type CourseRepository struct {
+	db database.DB
+}
+
+func NewCourseRepository(db database.DB) *CourseRepository {
+	return &CourseRepository{db: db}
+}
+
+// Create stores the course with its modules
+func (r *CourseRepository) Create(ctx context.Context, course *models.Course) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO courses (name, description, is_published, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`, course.Name, course.Description, course.IsPublished, course.CreatedBy).Scan(&course.ID, &course.CreatedAt, &course.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if err := insertModules(ctx, tx, course.ID, course.Modules); err != nil {
+		return err
+	}
+	course.ModuleCount = len(course.Modules)
+
+	return tx.Commit(ctx)
+}
+
+// GetByID returns the course with its modules and programs, or nil if it does not exist
+func (r *CourseRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Course, error) {
+	course, err := scanCourse(r.db.QueryRow(ctx, courseSelect+`WHERE c.id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	modules, err := r.getModules(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	course.Modules = modules
+	return course, nil
+}
+
+// List returns courses by name without their modules. With a user, only published courses
+// and courses the user is enrolled in are returned.
+func (r *CourseRepository) List(ctx context.Context, userID *uuid.UUID) ([]models.Course, error) {
+	query := courseSelect + `
+		WHERE $1::uuid IS NULL
+		   OR c.is_published = true
+		   OR EXISTS (SELECT 1 FROM course_enrollments e WHERE e.course_id = c.id AND e.user_id = $1)
+		ORDER BY c.name
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	courses := make([]models.Course, 0)
+	for rows.Next() {
+		course, err := scanCourse(rows)
+		if err != nil {
+			return nil, err
+		}
+		courses = append(courses, *course)
+	}
+	return courses, rows.Err()
+}
+
+// Update saves the course details and, if replaceModules is set, replaces its modules
+func (r *CourseRepository) Update(ctx context.Context, course *models.Course, replaceModules bool) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE courses SET name = $2, description = $3, is_published = $4
+		WHERE id = $1
+		RETURNING updated_at
+	`, course.ID, course.Name, course.Description, course.IsPublished).Scan(&course.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if replaceModules {
+		if _, err := tx.Exec(ctx, `DELETE FROM course_modules WHERE course_id = $1`, course.ID); err != nil {
+			return err
+		}
+		if err := insertModules(ctx, tx, course.ID, course.Modules); err != nil {
+			return err
+		}
+		course.ModuleCount = len(course.Modules)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Delete removes the course and reports whether it existed. Enrolled students keep their program assignments.
+func (r *CourseRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM courses WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// Enroll enrolls the user and reports whether they were not enrolled yet
+func (r *CourseRepository) Enroll(ctx context.Context, courseID, userID uuid.UUID, enrolledBy *uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		INSERT INTO course_enrollments (course_id, user_id, enrolled_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (course_id, user_id) DO NOTHING
+	`, courseID, userID, enrolledBy)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// Unenroll removes the enrollment and reports whether it existed
+func (r *CourseRepository) Unenroll(ctx context.Context, courseID, userID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM course_enrollments WHERE course_id = $1 AND user_id = $2`, courseID, userID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// GetEnrollment returns the user's enrollment, or nil if they are not enrolled
+func (r *CourseRepository) GetEnrollment(ctx context.Context, courseID, userID uuid.UUID) (*models.CourseEnrollment, error) {
+	enrollment, err := scanEnrollment(r.db.QueryRow(ctx, `
+		SELECT e.course_id, e.user_id, u.full_name, e.enrolled_by, e.enrolled_at
+		FROM course_enrollments e
+		JOIN users u ON u.id = e.user_id
+		WHERE e.course_id = $1 AND e.user_id = $2
+	`, courseID, userID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return enrollment, err
+}
+
+func (r *CourseRepository) ListEnrollments(ctx context.Context, courseID uuid.UUID) ([]models.CourseEnrollment, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT e.course_id, e.user_id, u.full_name, e.enrolled_by, e.enrolled_at
+		FROM course_enrollments e
+		JOIN users u ON u.id = e.user_id
+		WHERE e.course_id = $1
+		ORDER BY e.enrolled_at, u.full_name
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	enrollments := make([]models.CourseEnrollment, 0)
+	for rows.Next() {
+		enrollment, err := scanEnrollment(rows)
+		if err != nil {
+			return nil, err
+		}
+		enrollments = append(enrollments, *enrollment)
+	}
+	return enrollments, rows.Err()
+}
+
+// CompletedSessionCounts returns how many completed, non-deleted sessions the user has per program
+func (r *CourseRepository) CompletedSessionCounts(ctx context.Context, userID uuid.UUID, programIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT program_id, COUNT(*)
+		FROM practice_sessions
+		WHERE user_id = $1 AND program_id = ANY($2::uuid[])
+		  AND completed_at IS NOT NULL AND deleted_at IS NULL
+		GROUP BY program_id
+	`, userID, programIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var programID uuid.UUID
+		var count int
+		if err := rows.Scan(&programID, &count); err != nil {
+			return nil, err
+		}
+		counts[programID] = count
+	}
+	return counts, rows.Err()
+}
+
+// QuizCount is how many quizzes a program has and how many of them a user passed
+type QuizCount struct {
+	Total  int
+	Passed int
+}
+
+// QuizCounts returns the quiz tally per program for the user; programs without quizzes are left out
+func (r *CourseRepository) QuizCounts(ctx context.Context, userID uuid.UUID, programIDs []uuid.UUID) (map[uuid.UUID]QuizCount, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT q.program_id, COUNT(*),
+		       COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM quiz_attempts a WHERE a.quiz_id = q.id AND a.user_id = $1 AND a.passed = true
+		       ))
+		FROM quizzes q
+		WHERE q.program_id = ANY($2::uuid[])
+		GROUP BY q.program_id
+	`, userID, programIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[uuid.UUID]QuizCount)
+	for rows.Next() {
+		var programID uuid.UUID
+		var count QuizCount
+		if err := rows.Scan(&programID, &count.Total, &count.Passed); err != nil {
+			return nil, err
+		}
+		counts[programID] = count
+	}
+	return counts, rows.Err()
+}
+
+func (r *CourseRepository) getModules(ctx context.Context, courseID uuid.UUID) ([]models.CourseModule, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT m.id, m.position, m.title, m.description, m.unlock_rule, m.unlock_after_days,
+		       mp.program_id, p.name, mp.required_sessions
+		FROM course_modules m
+		LEFT JOIN course_module_programs mp ON mp.module_id = m.id
+		LEFT JOIN programs p ON p.id = mp.program_id
+		WHERE m.course_id = $1
+		ORDER BY m.position, mp.position
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	modules := make([]models.CourseModule, 0)
+	for rows.Next() {
+		var module models.CourseModule
+		var programID *uuid.UUID
+		var programName *string
+		var requiredSessions *int
+		err := rows.Scan(
+			&module.ID,
+			&module.Position,
+			&module.Title,
+			&module.Description,
+			&module.UnlockRule,
+			&module.UnlockAfterDays,
+			&programID,
+			&programName,
+			&requiredSessions,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Rows are ordered by module, so a module's programs are consecutive
+		if n := len(modules); n == 0 || modules[n-1].ID != module.ID {
+			module.Programs = make([]models.ModuleProgram, 0)
+			modules = append(modules, module)
+		}
+		if programID != nil {
+			last := &modules[len(modules)-1]
+			last.Programs = append(last.Programs, models.ModuleProgram{
+				ProgramID:        *programID,
+				ProgramName:      *programName,
+				RequiredSessions: *requiredSessions,
+			})
+		}
+	}
+	return modules, rows.Err()
+}
+
+// insertModules stores modules and their programs in order, setting module IDs and positions
+func insertModules(ctx context.Context, tx pgx.Tx, courseID uuid.UUID, modules []models.CourseModule) error {
+	for i := range modules {
+		module := &modules[i]
+		module.Position = i + 1
+		err := tx.QueryRow(ctx, `
+			INSERT INTO course_modules (course_id, position, title, description, unlock_rule, unlock_after_days)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`, courseID, module.Position, module.Title, module.Description, module.UnlockRule, module.UnlockAfterDays).Scan(&module.ID)
+		if err != nil {
+			return err
+		}
+
+		for j, program := range module.Programs {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO course_module_programs (module_id, program_id, position, required_sessions)
+				VALUES ($1, $2, $3, $4)
+			`, module.ID, program.ProgramID, j+1, program.RequiredSessions)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func scanCourse(row pgx.Row) (*models.Course, error) {
+	var course models.Course
+	err := row.Scan(
+		&course.ID,
+		&course.Name,
+		&course.Description,
+		&course.IsPublished,
+		&course.CreatedBy,
+		&course.ModuleCount,
+		&course.EnrolledCount,
+		&course.CreatedAt,
+		&course.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &course, nil
+}
+
+func scanEnrollment(row pgx.Row) (*models.CourseEnrollment, error) {
+	var enrollment models.CourseEnrollment
+	err := row.Scan(
+		&enrollment.CourseID,
+		&enrollment.UserID,
+		&enrollment.UserName,
+		&enrollment.EnrolledBy,
+		&enrollment.EnrolledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/richtext"
+)
+
+type DiaryRepository struct {
+	db database.DB
+}
+
+func NewDiaryRepository(db database.DB) *DiaryRepository {
+	return &DiaryRepository{db: db}
+}
+
+const diaryColumns = `
+	d.id, d.user_id, to_char(d.entry_date, 'YYYY-MM-DD'), d.title, d.content,
+	ARRAY(SELECT ds.session_id FROM diary_entry_sessions ds WHERE ds.entry_id = d.id ORDER BY ds.session_id),
+	d.shared, d.created_at, d.updated_at`
+
+func scanDiaryEntry(row pgx.Row, entry *models.DiaryEntry, extra ...any) error {
+	dest := []any{
+		&entry.ID,
+		&entry.UserID,
+		&entry.EntryDate,
+		&entry.Title,
+		&entry.Content,
+		&entry.SessionIDs,
+		&entry.Shared,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	entry.RenderedHTML = richtext.Render(entry.Content)
+	return nil
+}
+
+// Create stores the entry with its linked sessions
+func (r *DiaryRepository) Create(ctx context.Context, entry *models.DiaryEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO diary_entries (user_id, entry_date, title, content, shared)
+		VALUES ($1, $2::date, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`, entry.UserID, entry.EntryDate, entry.Title, entry.Content, entry.Shared).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if err := insertDiarySessions(ctx, tx, entry.ID, entry.SessionIDs); err != nil {
+		return err
+	}
+	entry.RenderedHTML = richtext.Render(entry.Content)
+
+	return tx.Commit(ctx)
+}
+
+// GetByID returns the entry, or nil if it does not exist
+func (r *DiaryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DiaryEntry, error) {
+	query := `SELECT ` + diaryColumns + ` FROM diary_entries d WHERE d.id = $1`
+
+	var entry models.DiaryEntry
+	err := database.Retry(ctx, "diary_entries.GetByID", func() error {
+		return scanDiaryEntry(r.db.QueryRow(ctx, query, id), &entry)
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// DiaryFilter narrows down a student's diary. Dates are YYYY-MM-DD and inclusive.
+type DiaryFilter struct {
+	From       *string
+	To         *string
+	SessionID  *uuid.UUID
+	SharedOnly bool
+}
+
+// List returns the student's entries, newest day first
+func (r *DiaryRepository) List(ctx context.Context, userID uuid.UUID, filter DiaryFilter, limit, offset int) ([]models.DiaryEntry, error) {
+	query := `
+		SELECT ` + diaryColumns + `
+		FROM diary_entries d
+		WHERE d.user_id = $1
+		  AND ($2::date IS NULL OR d.entry_date >= $2::date)
+		  AND ($3::date IS NULL OR d.entry_date <= $3::date)
+		  AND ($4::uuid IS NULL OR EXISTS (
+			SELECT 1 FROM diary_entry_sessions ds WHERE ds.entry_id = d.id AND ds.session_id = $4
+		  ))
+		  AND ($5 = false OR d.shared = true)
+		ORDER BY d.entry_date DESC, d.created_at DESC
+		LIMIT $6 OFFSET $7
+	`
+	return collectWithRetry(ctx, r.db, "diary_entries.List", query, func(row pgx.CollectableRow) (models.DiaryEntry, error) {
+		var entry models.DiaryEntry
+		err := scanDiaryEntry(row, &entry)
+		return entry, err
+	}, userID, filter.From, filter.To, filter.SessionID, filter.SharedOnly, limit, offset)
+}
+
+// Search finds the student's entries whose title or content match a web-style query,
+// best match first
+func (r *DiaryRepository) Search(ctx context.Context, userID uuid.UUID, q string, limit, offset int) ([]models.DiarySearchResult, error) {
+	query := `
+		WITH q AS (
+			SELECT websearch_to_tsquery('simple', $2) AS query
+		)
+		SELECT ` + diaryColumns + `,
+			ts_headline('simple', d.title, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS title_highlight,
+			ts_headline('simple', d.content, q.query, 'StartSel=<mark>, StopSel=</mark>, MinWords=8, MaxWords=25, MaxFragments=2') AS snippet,
+			ts_rank(to_tsvector('simple', d.title || ' ' || d.content), q.query) AS rank
+		FROM diary_entries d
+		CROSS JOIN q
+		WHERE d.user_id = $1
+		  AND to_tsvector('simple', d.title || ' ' || d.content) @@ q.query
+		ORDER BY rank DESC, d.entry_date DESC
+		LIMIT $3 OFFSET $4
+	`
+	return collectWithRetry(ctx, r.db, "diary_entries.Search", query, func(row pgx.CollectableRow) (models.DiarySearchResult, error) {
+		var result models.DiarySearchResult
+		err := scanDiaryEntry(row, &result.DiaryEntry, &result.TitleHighlight, &result.Snippet, &result.Rank)
+		return result, err
+	}, userID, q, limit, offset)
+}
+
+// Update saves the entry and replaces its linked sessions
+func (r *DiaryRepository) Update(ctx context.Context, entry *models.DiaryEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE diary_entries
+		SET entry_date = $2::date, title = $3, content = $4, shared = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING updated_at
+	`, entry.ID, entry.EntryDate, entry.Title, entry.Content, entry.Shared).Scan(&entry.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM diary_entry_sessions WHERE entry_id = $1`, entry.ID); err != nil {
+		return err
+	}
+	if err := insertDiarySessions(ctx, tx, entry.ID, entry.SessionIDs); err != nil {
+		return err
+	}
+	entry.RenderedHTML = richtext.Render(entry.Content)
+
+	return tx.Commit(ctx)
+}
+
+// Delete removes the entry and reports whether it existed
+func (r *DiaryRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM diary_entries WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func insertDiarySessions(ctx context.Context, tx pgx.Tx, entryID uuid.UUID, sessionIDs []uuid.UUID) error {
+	for _, sessionID := range sessionIDs {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO diary_entry_sessions (entry_id, session_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, entryID, sessionID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
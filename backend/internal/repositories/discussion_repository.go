@@ -0,0 +1,251 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/clock"
+)
+
+const discussionTopicSelect = `
+	SELECT t.id, t.program_id, t.author_id, u.full_name, u.role, t.title, t.content, t.youtube_url,
+	       t.is_pinned, t.is_locked,
+	       (SELECT COUNT(*) FROM discussion_replies r WHERE r.topic_id = t.id AND r.deleted_at IS NULL),
+	       t.last_activity_at, t.created_at
+	FROM discussion_topics t
+	JOIN users u ON u.id = t.author_id
+`
+
+type DiscussionRepository struct {
+	db    database.DB
+	clock clock.Clock
+}
+
+func NewDiscussionRepository(db database.DB) *DiscussionRepository {
+	return &DiscussionRepository{db: db, clock: clock.System}
+}
+
+// WithClock replaces the clock used for timestamps, so tests can control time
+func (r *DiscussionRepository) WithClock(c clock.Clock) *DiscussionRepository {
+	r.clock = c
+	return r
+}
+
+// BoardMembers returns the IDs among ids that can see the program's board: active admins,
+// the program owner and students with an active assignment
+func (r *DiscussionRepository) BoardMembers(ctx context.Context, programID uuid.UUID, ids []uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT u.id
+		FROM users u
+		WHERE u.id = ANY($2::uuid[])
+		  AND u.is_active = true
+		  AND (
+			u.role = 'admin'
+			OR u.id = (SELECT owned_by FROM programs WHERE id = $1)
+			OR EXISTS (
+				SELECT 1 FROM user_programs up
+				WHERE up.user_id = u.id AND up.program_id = $1 AND up.is_active = true
+			)
+		  )
+	`
+	rows, err := r.db.Query(ctx, query, programID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		members = append(members, id)
+	}
+	return members, rows.Err()
+}
+
+func (r *DiscussionRepository) CreateTopic(ctx context.Context, topic *models.DiscussionTopic) error {
+	now := r.clock.Now()
+	query := `
+		INSERT INTO discussion_topics (program_id, author_id, title, content, youtube_url, last_activity_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING id, last_activity_at, created_at
+	`
+	return r.db.QueryRow(ctx, query,
+		topic.ProgramID,
+		topic.AuthorID,
+		topic.Title,
+		topic.Content,
+		topic.YouTubeURL,
+		now,
+	).Scan(&topic.ID, &topic.LastActivityAt, &topic.CreatedAt)
+}
+
+// GetTopic returns a topic that has not been deleted, nil otherwise
+func (r *DiscussionRepository) GetTopic(ctx context.Context, id uuid.UUID) (*models.DiscussionTopic, error) {
+	query := discussionTopicSelect + `WHERE t.id = $1 AND t.deleted_at IS NULL`
+	topic, err := scanDiscussionTopic(r.db.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return topic, nil
+}
+
+// ListTopics returns a program's topics, pinned first and then by latest activity
+func (r *DiscussionRepository) ListTopics(ctx context.Context, programID uuid.UUID, limit, offset int) ([]models.DiscussionTopic, error) {
+	query := discussionTopicSelect + `
+		WHERE t.program_id = $1 AND t.deleted_at IS NULL
+		ORDER BY t.is_pinned DESC, t.last_activity_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(ctx, query, programID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	topics := make([]models.DiscussionTopic, 0)
+	for rows.Next() {
+		topic, err := scanDiscussionTopic(rows)
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, *topic)
+	}
+	return topics, rows.Err()
+}
+
+// SetTopicFlags pins/unpins and locks/unlocks a topic
+func (r *DiscussionRepository) SetTopicFlags(ctx context.Context, id uuid.UUID, isPinned, isLocked bool) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE discussion_topics SET is_pinned = $2, is_locked = $3
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id, isPinned, isLocked)
+	return err
+}
+
+// DeleteTopic soft deletes a topic, hiding it and its replies
+func (r *DiscussionRepository) DeleteTopic(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE discussion_topics SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`, id, r.clock.Now())
+	return err
+}
+
+// CreateReply adds a reply and bumps the topic's last activity
+func (r *DiscussionRepository) CreateReply(ctx context.Context, reply *models.DiscussionReply) error {
+	now := r.clock.Now()
+	query := `
+		WITH bumped AS (
+			UPDATE discussion_topics SET last_activity_at = $5 WHERE id = $1
+		)
+		INSERT INTO discussion_replies (topic_id, author_id, content, youtube_url, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(ctx, query,
+		reply.TopicID,
+		reply.AuthorID,
+		reply.Content,
+		reply.YouTubeURL,
+		now,
+	).Scan(&reply.ID, &reply.CreatedAt)
+}
+
+// GetReply returns a reply of the topic that has not been deleted, nil otherwise
+func (r *DiscussionRepository) GetReply(ctx context.Context, topicID, id uuid.UUID) (*models.DiscussionReply, error) {
+	query := `
+		SELECT r.id, r.topic_id, r.author_id, u.full_name, u.role, r.content, r.youtube_url, r.created_at
+		FROM discussion_replies r
+		JOIN users u ON u.id = r.author_id
+		WHERE r.id = $1 AND r.topic_id = $2 AND r.deleted_at IS NULL
+	`
+	var reply models.DiscussionReply
+	err := r.db.QueryRow(ctx, query, id, topicID).Scan(
+		&reply.ID,
+		&reply.TopicID,
+		&reply.AuthorID,
+		&reply.AuthorName,
+		&reply.AuthorRole,
+		&reply.Content,
+		&reply.YouTubeURL,
+		&reply.CreatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// ListReplies returns a topic's replies, oldest first
+func (r *DiscussionRepository) ListReplies(ctx context.Context, topicID uuid.UUID) ([]models.DiscussionReply, error) {
+	query := `
+		SELECT r.id, r.topic_id, r.author_id, u.full_name, u.role, r.content, r.youtube_url, r.created_at
+		FROM discussion_replies r
+		JOIN users u ON u.id = r.author_id
+		WHERE r.topic_id = $1 AND r.deleted_at IS NULL
+		ORDER BY r.created_at ASC
+	`
+	rows, err := r.db.Query(ctx, query, topicID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	replies := make([]models.DiscussionReply, 0)
+	for rows.Next() {
+		var reply models.DiscussionReply
+		err := rows.Scan(
+			&reply.ID,
+			&reply.TopicID,
+			&reply.AuthorID,
+			&reply.AuthorName,
+			&reply.AuthorRole,
+			&reply.Content,
+			&reply.YouTubeURL,
+			&reply.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, reply)
+	}
+	return replies, rows.Err()
+}
+
+// DeleteReply soft deletes a reply
+func (r *DiscussionRepository) DeleteReply(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE discussion_replies SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`, id, r.clock.Now())
+	return err
+}
+
+func scanDiscussionTopic(row pgx.Row) (*models.DiscussionTopic, error) {
+	var topic models.DiscussionTopic
+	err := row.Scan(
+		&topic.ID,
+		&topic.ProgramID,
+		&topic.AuthorID,
+		&topic.AuthorName,
+		&topic.AuthorRole,
+		&topic.Title,
+		&topic.Content,
+		&topic.YouTubeURL,
+		&topic.IsPinned,
+		&topic.IsLocked,
+		&topic.ReplyCount,
+		&topic.LastActivityAt,
+		&topic.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &topic, nil
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/clock"
+)
+
+type DisplayTokenRepository struct {
+	db    database.DB
+	clock clock.Clock
+}
+
+func NewDisplayTokenRepository(db database.DB) *DisplayTokenRepository {
+	return &DisplayTokenRepository{db: db, clock: clock.System}
+}
+
+// WithClock replaces the clock used for timestamps, so tests can control time
+func (r *DisplayTokenRepository) WithClock(c clock.Clock) *DisplayTokenRepository {
+	r.clock = c
+	return r
+}
+
+const displayTokenColumns = `
+	id, token_hash, name, program_ids, created_by, expires_at, last_used_at, revoked_at, created_at
+`
+
+func scanDisplayToken(row pgx.Row) (*models.DisplayToken, error) {
+	var t models.DisplayToken
+	err := row.Scan(
+		&t.ID,
+		&t.TokenHash,
+		&t.Name,
+		&t.ProgramIDs,
+		&t.CreatedBy,
+		&t.ExpiresAt,
+		&t.LastUsedAt,
+		&t.RevokedAt,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *DisplayTokenRepository) Create(ctx context.Context, t *models.DisplayToken) error {
+	query := `
+		INSERT INTO display_tokens (token_hash, name, program_ids, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(ctx, query, t.TokenHash, t.Name, t.ProgramIDs, t.CreatedBy, t.ExpiresAt).Scan(&t.ID, &t.CreatedAt)
+}
+
+// GetActiveByTokenHash returns the display token if it is neither revoked nor expired
+func (r *DisplayTokenRepository) GetActiveByTokenHash(ctx context.Context, tokenHash string) (*models.DisplayToken, error) {
+	query := `SELECT ` + displayTokenColumns + `
+		FROM display_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+	`
+	t, err := scanDisplayToken(r.db.QueryRow(ctx, query, tokenHash, r.clock.Now()))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (r *DisplayTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DisplayToken, error) {
+	query := `SELECT ` + displayTokenColumns + ` FROM display_tokens WHERE id = $1`
+	t, err := scanDisplayToken(r.db.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+// List returns display tokens, newest first. activeOnly excludes revoked and expired ones.
+func (r *DisplayTokenRepository) List(ctx context.Context, activeOnly bool) ([]models.DisplayToken, error) {
+	query := `SELECT ` + displayTokenColumns + `
+		FROM display_tokens
+		WHERE ($1 = false OR (revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)))
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, activeOnly, r.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]models.DisplayToken, 0)
+	for rows.Next() {
+		t, err := scanDisplayToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *t)
+	}
+
+	return tokens, rows.Err()
+}
+
+// TouchLastUsed records that the display used its token
+func (r *DisplayTokenRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE display_tokens SET last_used_at = $2 WHERE id = $1`, id, r.clock.Now())
+	return err
+}
+
+// Revoke invalidates a display token and reports whether it was still active
+func (r *DisplayTokenRepository) Revoke(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE display_tokens
+		SET revoked_at = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id, r.clock.Now())
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
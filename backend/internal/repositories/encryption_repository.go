@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/pkg/fieldcrypt"
+)
+
+// EncryptedColumn is a text column whose values are encrypted with the keyring, keyed by a
+// UUID column. The table, column and row ID are authenticated with each value.
+type EncryptedColumn struct {
+	Table  string
+	Key    string
+	Column string
+}
+
+func (c EncryptedColumn) String() string {
+	return c.Table + "." + c.Column
+}
+
+// context returns the encryption context of the column's value in a row
+func (c EncryptedColumn) context(id uuid.UUID) string {
+	return c.String() + ":" + id.String()
+}
+
+// encryptJSON encodes v as JSON and encrypts it as the column's value in the row
+func (c EncryptedColumn) encryptJSON(keyring *fieldcrypt.Keyring, id uuid.UUID, v any) (string, error) {
+	plaintext, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	value, err := keyring.Encrypt(string(plaintext), c.context(id))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt %s: %w", c, err)
+	}
+	return value, nil
+}
+
+// decryptJSON decrypts the column's value in the row and decodes it into v. An empty value
+// leaves v unchanged.
+func (c EncryptedColumn) decryptJSON(keyring *fieldcrypt.Keyring, id uuid.UUID, value string, v any) error {
+	plaintext, err := keyring.Decrypt(value, c.context(id))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", c, err)
+	}
+	if plaintext == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(plaintext), v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", c, err)
+	}
+	return nil
+}
+
+var (
+	limitationNotesColumn      = EncryptedColumn{Table: "user_limitations", Key: "user_id", Column: "notes"}
+	limitationJointsColumn     = EncryptedColumn{Table: "user_limitations", Key: "user_id", Column: "joints"}
+	limitationConditionsColumn = EncryptedColumn{Table: "user_limitations", Key: "user_id", Column: "conditions"}
+	sessionBiometricsColumn    = EncryptedColumn{Table: "session_biometric_samples", Key: "session_id", Column: "samples"}
+	integrationKeySecretColumn = EncryptedColumn{Table: "integration_keys", Key: "id", Column: "secret"}
+)
+
+// EncryptedColumns are all columns stored encrypted, for re-encrypting them after a key rotation
+var EncryptedColumns = []EncryptedColumn{
+	limitationNotesColumn,
+	limitationJointsColumn,
+	limitationConditionsColumn,
+	sessionBiometricsColumn,
+	integrationKeySecretColumn,
+}
+
+// EncryptionRepository rewrites encrypted columns, e.g. under a new key
+type EncryptionRepository struct {
+	db      database.DB
+	keyring *fieldcrypt.Keyring
+}
+
+func NewEncryptionRepository(db database.DB, keyring *fieldcrypt.Keyring) *EncryptionRepository {
+	return &EncryptionRepository{db: db, keyring: keyring}
+}
+
+const rewriteBatchSize = 500
+
+// Rewrite re-encrypts the column's values that are plain text or encrypted with a previous key,
+// and returns how many rows it changed. With decrypt set, encrypted values are written back as
+// plain text instead, for turning encryption off. Rows changed concurrently are skipped; their
+// new value is already written with the current key.
+func (r *EncryptionRepository) Rewrite(ctx context.Context, column EncryptedColumn, decrypt bool) (int64, error) {
+	query := fmt.Sprintf(`SELECT %[1]s, %[2]s FROM %[3]s WHERE %[1]s > $1 ORDER BY %[1]s LIMIT $2`,
+		column.Key, column.Column, column.Table)
+	update := fmt.Sprintf(`UPDATE %[3]s SET %[2]s = $3 WHERE %[1]s = $1 AND %[2]s = $2`,
+		column.Key, column.Column, column.Table)
+
+	var rewritten int64
+	after := uuid.Nil
+	for {
+		type row struct {
+			id    uuid.UUID
+			value string
+		}
+		batch, err := collectWithRetry(ctx, r.db, column.String()+".Rewrite", query, func(cr pgx.CollectableRow) (row, error) {
+			var rw row
+			err := cr.Scan(&rw.id, &rw.value)
+			return rw, err
+		}, after, rewriteBatchSize)
+		if err != nil {
+			return rewritten, fmt.Errorf("failed to list %s: %w", column, err)
+		}
+		if len(batch) == 0 {
+			return rewritten, nil
+		}
+
+		for _, rw := range batch {
+			_, encrypted := fieldcrypt.KeyID(rw.value)
+			if decrypt && !encrypted || !decrypt && !r.keyring.NeedsRotation(rw.value) {
+				continue
+			}
+			plaintext, err := r.keyring.Decrypt(rw.value, column.context(rw.id))
+			if err != nil {
+				return rewritten, fmt.Errorf("failed to decrypt %s of %s: %w", column, rw.id, err)
+			}
+			value := plaintext
+			if !decrypt {
+				if value, err = r.keyring.Encrypt(plaintext, column.context(rw.id)); err != nil {
+					return rewritten, err
+				}
+			}
+			result, err := r.db.Exec(ctx, update, rw.id, rw.value, value)
+			if err != nil {
+				return rewritten, fmt.Errorf("failed to rewrite %s of %s: %w", column, rw.id, err)
+			}
+			rewritten += result.RowsAffected()
+		}
+		after = batch[len(batch)-1].id
+	}
+}
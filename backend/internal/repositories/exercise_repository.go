@@ -5,15 +5,16 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/xuangong/backend/internal/database"
 	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/richtext"
 )
 
 type ExerciseRepository struct {
-	db *pgxpool.Pool
+	db database.DB
 }
 
-func NewExerciseRepository(db *pgxpool.Pool) *ExerciseRepository {
+func NewExerciseRepository(db database.DB) *ExerciseRepository {
 	return &ExerciseRepository{db: db}
 }
 
@@ -22,9 +23,9 @@ func (r *ExerciseRepository) Create(ctx context.Context, exercise *models.Exerci
 		INSERT INTO exercises (
 			program_id, name, description, order_index, exercise_type,
 			duration_seconds, repetitions, rest_after_seconds,
-			has_sides, side_duration_seconds, metadata
+			has_sides, side_duration_seconds, tempo, breathing_pattern, contraindications, metadata
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, COALESCE($13::text[], '{}'), $14)
 		RETURNING id, created_at
 	`
 	return r.db.QueryRow(ctx, query,
@@ -38,6 +39,9 @@ func (r *ExerciseRepository) Create(ctx context.Context, exercise *models.Exerci
 		exercise.RestAfterSeconds,
 		exercise.HasSides,
 		exercise.SideDurationSeconds,
+		exercise.Tempo,
+		exercise.BreathingPattern,
+		exercise.Contraindications,
 		exercise.Metadata,
 	).Scan(&exercise.ID, &exercise.CreatedAt)
 }
@@ -47,53 +51,59 @@ func (r *ExerciseRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	query := `
 		SELECT id, program_id, name, description, order_index, exercise_type,
 		       duration_seconds, repetitions, rest_after_seconds,
-		       has_sides, side_duration_seconds, metadata, created_at
+		       has_sides, side_duration_seconds, tempo, breathing_pattern, contraindications, metadata, created_at
 		FROM exercises
 		WHERE id = $1
 	`
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&exercise.ID,
-		&exercise.ProgramID,
-		&exercise.Name,
-		&exercise.Description,
-		&exercise.OrderIndex,
-		&exercise.ExerciseType,
-		&exercise.DurationSeconds,
-		&exercise.Repetitions,
-		&exercise.RestAfterSeconds,
-		&exercise.HasSides,
-		&exercise.SideDurationSeconds,
-		&exercise.Metadata,
-		&exercise.CreatedAt,
-	)
+	err := database.Retry(ctx, "exercises.GetByID", func() error {
+		return r.db.QueryRow(ctx, query, id).Scan(
+			&exercise.ID,
+			&exercise.ProgramID,
+			&exercise.Name,
+			&exercise.Description,
+			&exercise.OrderIndex,
+			&exercise.ExerciseType,
+			&exercise.DurationSeconds,
+			&exercise.Repetitions,
+			&exercise.RestAfterSeconds,
+			&exercise.HasSides,
+			&exercise.SideDurationSeconds,
+			&exercise.Tempo,
+			&exercise.BreathingPattern,
+			&exercise.Contraindications,
+			&exercise.Metadata,
+			&exercise.CreatedAt,
+		)
+	})
 	if err == pgx.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	exercise.RenderedHTML = richtext.Render(exercise.Description)
+
+	substitutes, err := substitutesForExercises(ctx, r.db, []uuid.UUID{exercise.ID})
+	if err != nil {
+		return nil, err
+	}
+	exercise.Substitutes = substitutes[exercise.ID]
 	return &exercise, nil
 }
 
+// ListByProgramID returns the program's exercises in order, with their substitutes
 func (r *ExerciseRepository) ListByProgramID(ctx context.Context, programID uuid.UUID) ([]models.Exercise, error) {
 	query := `
 		SELECT id, program_id, name, description, order_index, exercise_type,
 		       duration_seconds, repetitions, rest_after_seconds,
-		       has_sides, side_duration_seconds, metadata, created_at
+		       has_sides, side_duration_seconds, tempo, breathing_pattern, contraindications, metadata, created_at
 		FROM exercises
 		WHERE program_id = $1
 		ORDER BY order_index ASC
 	`
-	rows, err := r.db.Query(ctx, query, programID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	exercises := make([]models.Exercise, 0)
-	for rows.Next() {
+	exercises, err := collectWithRetry(ctx, r.db, "exercises.ListByProgramID", query, func(row pgx.CollectableRow) (models.Exercise, error) {
 		var exercise models.Exercise
-		err := rows.Scan(
+		err := row.Scan(
 			&exercise.ID,
 			&exercise.ProgramID,
 			&exercise.Name,
@@ -105,16 +115,34 @@ func (r *ExerciseRepository) ListByProgramID(ctx context.Context, programID uuid
 			&exercise.RestAfterSeconds,
 			&exercise.HasSides,
 			&exercise.SideDurationSeconds,
+			&exercise.Tempo,
+			&exercise.BreathingPattern,
+			&exercise.Contraindications,
 			&exercise.Metadata,
 			&exercise.CreatedAt,
 		)
-		if err != nil {
-			return nil, err
-		}
-		exercises = append(exercises, exercise)
+		exercise.RenderedHTML = richtext.Render(exercise.Description)
+		return exercise, err
+	}, programID)
+	if err != nil {
+		return nil, err
+	}
+	if len(exercises) == 0 {
+		return exercises, nil
 	}
 
-	return exercises, rows.Err()
+	ids := make([]uuid.UUID, len(exercises))
+	for i, exercise := range exercises {
+		ids[i] = exercise.ID
+	}
+	substitutes, err := substitutesForExercises(ctx, r.db, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range exercises {
+		exercises[i].Substitutes = substitutes[exercises[i].ID]
+	}
+	return exercises, nil
 }
 
 func (r *ExerciseRepository) Update(ctx context.Context, exercise *models.Exercise) error {
@@ -122,8 +150,9 @@ func (r *ExerciseRepository) Update(ctx context.Context, exercise *models.Exerci
 		UPDATE exercises
 		SET name = $1, description = $2, order_index = $3, exercise_type = $4,
 		    duration_seconds = $5, repetitions = $6, rest_after_seconds = $7,
-		    has_sides = $8, side_duration_seconds = $9, metadata = $10
-		WHERE id = $11
+		    has_sides = $8, side_duration_seconds = $9, tempo = $10, breathing_pattern = $11,
+		    contraindications = COALESCE($12::text[], '{}'), metadata = $13
+		WHERE id = $14
 	`
 	_, err := r.db.Exec(ctx, query,
 		exercise.Name,
@@ -135,6 +164,9 @@ func (r *ExerciseRepository) Update(ctx context.Context, exercise *models.Exerci
 		exercise.RestAfterSeconds,
 		exercise.HasSides,
 		exercise.SideDurationSeconds,
+		exercise.Tempo,
+		exercise.BreathingPattern,
+		exercise.Contraindications,
 		exercise.Metadata,
 		exercise.ID,
 	)
@@ -148,19 +180,22 @@ func (r *ExerciseRepository) Delete(ctx context.Context, id uuid.UUID) error {
 }
 
 func (r *ExerciseRepository) Reorder(ctx context.Context, programID uuid.UUID, exerciseIDs []uuid.UUID) error {
-	tx, err := r.db.Begin(ctx)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback(ctx)
-
-	query := `UPDATE exercises SET order_index = $1 WHERE id = $2 AND program_id = $3`
-	for i, id := range exerciseIDs {
-		_, err := tx.Exec(ctx, query, i, id, programID)
+	// Setting absolute positions is idempotent, so the whole transaction can be retried
+	return database.Retry(ctx, "exercises.Reorder", func() error {
+		tx, err := r.db.Begin(ctx)
 		if err != nil {
 			return err
 		}
-	}
+		defer tx.Rollback(ctx)
+
+		query := `UPDATE exercises SET order_index = $1 WHERE id = $2 AND program_id = $3`
+		for i, id := range exerciseIDs {
+			_, err := tx.Exec(ctx, query, i, id, programID)
+			if err != nil {
+				return err
+			}
+		}
 
-	return tx.Commit(ctx)
+		return tx.Commit(ctx)
+	})
 }
@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/richtext"
+)
+
+type ExerciseSubstituteRepository struct {
+	db database.DB
+}
+
+func NewExerciseSubstituteRepository(db database.DB) *ExerciseSubstituteRepository {
+	return &ExerciseSubstituteRepository{db: db}
+}
+
+func (r *ExerciseSubstituteRepository) Create(ctx context.Context, substitute *models.ExerciseSubstitute) error {
+	query := `
+		INSERT INTO exercise_substitutes (
+			exercise_id, name, description, reason,
+			duration_seconds, repetitions, side_duration_seconds, contraindications
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8::text[], '{}'))
+		RETURNING id, created_at, updated_at
+	`
+	return r.db.QueryRow(ctx, query,
+		substitute.ExerciseID,
+		substitute.Name,
+		substitute.Description,
+		substitute.Reason,
+		substitute.DurationSeconds,
+		substitute.Repetitions,
+		substitute.SideDurationSeconds,
+		substitute.Contraindications,
+	).Scan(&substitute.ID, &substitute.CreatedAt, &substitute.UpdatedAt)
+}
+
+func (r *ExerciseSubstituteRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ExerciseSubstitute, error) {
+	var substitute models.ExerciseSubstitute
+	query := `
+		SELECT id, exercise_id, name, description, reason,
+		       duration_seconds, repetitions, side_duration_seconds, contraindications, created_at, updated_at
+		FROM exercise_substitutes
+		WHERE id = $1
+	`
+	err := database.Retry(ctx, "exercise_substitutes.GetByID", func() error {
+		return r.db.QueryRow(ctx, query, id).Scan(
+			&substitute.ID,
+			&substitute.ExerciseID,
+			&substitute.Name,
+			&substitute.Description,
+			&substitute.Reason,
+			&substitute.DurationSeconds,
+			&substitute.Repetitions,
+			&substitute.SideDurationSeconds,
+			&substitute.Contraindications,
+			&substitute.CreatedAt,
+			&substitute.UpdatedAt,
+		)
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	substitute.RenderedHTML = richtext.Render(substitute.Description)
+	return &substitute, nil
+}
+
+// ListByExercise returns the exercise's substitutes in the order they were added
+func (r *ExerciseSubstituteRepository) ListByExercise(ctx context.Context, exerciseID uuid.UUID) ([]models.ExerciseSubstitute, error) {
+	substitutes, err := substitutesForExercises(ctx, r.db, []uuid.UUID{exerciseID})
+	if err != nil {
+		return nil, err
+	}
+	if substitutes[exerciseID] == nil {
+		return []models.ExerciseSubstitute{}, nil
+	}
+	return substitutes[exerciseID], nil
+}
+
+func (r *ExerciseSubstituteRepository) Update(ctx context.Context, substitute *models.ExerciseSubstitute) error {
+	query := `
+		UPDATE exercise_substitutes
+		SET name = $1, description = $2, reason = $3,
+		    duration_seconds = $4, repetitions = $5, side_duration_seconds = $6,
+		    contraindications = COALESCE($7::text[], '{}'), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8
+		RETURNING updated_at
+	`
+	return r.db.QueryRow(ctx, query,
+		substitute.Name,
+		substitute.Description,
+		substitute.Reason,
+		substitute.DurationSeconds,
+		substitute.Repetitions,
+		substitute.SideDurationSeconds,
+		substitute.Contraindications,
+		substitute.ID,
+	).Scan(&substitute.UpdatedAt)
+}
+
+// Delete removes the substitute and reports whether it existed. Session logs keep the
+// exercise but lose the variant.
+func (r *ExerciseSubstituteRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM exercise_substitutes WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// substitutesForExercises returns the substitutes of each exercise, in the order they were added
+func substitutesForExercises(ctx context.Context, db database.DB, exerciseIDs []uuid.UUID) (map[uuid.UUID][]models.ExerciseSubstitute, error) {
+	query := `
+		SELECT id, exercise_id, name, description, reason,
+		       duration_seconds, repetitions, side_duration_seconds, contraindications, created_at, updated_at
+		FROM exercise_substitutes
+		WHERE exercise_id = ANY($1::uuid[])
+		ORDER BY created_at, id
+	`
+	rows, err := collectWithRetry(ctx, db, "exercise_substitutes.ForExercises", query, func(row pgx.CollectableRow) (models.ExerciseSubstitute, error) {
+		var substitute models.ExerciseSubstitute
+		err := row.Scan(
+			&substitute.ID,
+			&substitute.ExerciseID,
+			&substitute.Name,
+			&substitute.Description,
+			&substitute.Reason,
+			&substitute.DurationSeconds,
+			&substitute.Repetitions,
+			&substitute.SideDurationSeconds,
+			&substitute.Contraindications,
+			&substitute.CreatedAt,
+			&substitute.UpdatedAt,
+		)
+		substitute.RenderedHTML = richtext.Render(substitute.Description)
+		return substitute, err
+	}, exerciseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	substitutes := make(map[uuid.UUID][]models.ExerciseSubstitute)
+	for _, substitute := range rows {
+		substitutes[substitute.ExerciseID] = append(substitutes[substitute.ExerciseID], substitute)
+	}
+	return substitutes, nil
+}
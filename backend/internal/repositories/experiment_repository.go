@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+type ExperimentRepository struct {
+	db database.DB
+}
+
+func NewExperimentRepository(db database.DB) *ExperimentRepository {
+	return &ExperimentRepository{db: db}
+}
+
+const experimentColumns = `e.id, e.name, e.description, e.program_a_id, e.program_b_id, e.created_by, e.created_at,
+	(SELECT COUNT(*) FROM experiment_participants p WHERE p.experiment_id = e.id)`
+
+func scanExperiment(row pgx.Row) (*models.ProgramExperiment, error) {
+	var e models.ProgramExperiment
+	err := row.Scan(&e.ID, &e.Name, &e.Description, &e.ProgramAID, &e.ProgramBID, &e.CreatedBy, &e.CreatedAt, &e.Participants)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *ExperimentRepository) Create(ctx context.Context, e *models.ProgramExperiment) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO program_experiments (name, description, program_a_id, program_b_id, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, e.Name, e.Description, e.ProgramAID, e.ProgramBID, e.CreatedBy).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create experiment: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns the experiment, or nil if it does not exist
+func (r *ExperimentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ProgramExperiment, error) {
+	query := `SELECT ` + experimentColumns + ` FROM program_experiments e WHERE e.id = $1`
+
+	var e *models.ProgramExperiment
+	err := database.Retry(ctx, "program_experiments.GetByID", func() error {
+		var err error
+		e, err = scanExperiment(r.db.QueryRow(ctx, query, id))
+		return err
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experiment: %w", err)
+	}
+	return e, nil
+}
+
+// List returns all experiments, newest first
+func (r *ExperimentRepository) List(ctx context.Context) ([]models.ProgramExperiment, error) {
+	query := `SELECT ` + experimentColumns + ` FROM program_experiments e ORDER BY e.created_at DESC`
+	experiments, err := collectWithRetry(ctx, r.db, "program_experiments.List", query, func(row pgx.CollectableRow) (models.ProgramExperiment, error) {
+		e, err := scanExperiment(row)
+		if err != nil {
+			return models.ProgramExperiment{}, err
+		}
+		return *e, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list experiments: %w", err)
+	}
+	return experiments, nil
+}
+
+// AddParticipant puts the user in the variant and returns their participation. A user already
+// in the experiment keeps their variant, which is returned instead.
+func (r *ExperimentRepository) AddParticipant(ctx context.Context, experimentID, userID uuid.UUID, variant string) (*models.ExperimentParticipant, error) {
+	var p models.ExperimentParticipant
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO experiment_participants (experiment_id, user_id, variant)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (experiment_id, user_id) DO UPDATE SET variant = experiment_participants.variant
+		RETURNING user_id, variant, assigned_at
+	`, experimentID, userID, variant).Scan(&p.UserID, &p.Variant, &p.AssignedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add experiment participant: %w", err)
+	}
+	return &p, nil
+}
+
+// CountByVariant returns how many participants each variant has
+func (r *ExperimentRepository) CountByVariant(ctx context.Context, experimentID uuid.UUID) (map[string]int, error) {
+	type variantCount struct {
+		variant string
+		n       int
+	}
+	rows, err := collectWithRetry(ctx, r.db, "experiment_participants.CountByVariant", `
+		SELECT variant, COUNT(*) FROM experiment_participants WHERE experiment_id = $1 GROUP BY variant
+	`, func(row pgx.CollectableRow) (variantCount, error) {
+		var c variantCount
+		err := row.Scan(&c.variant, &c.n)
+		return c, err
+	}, experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count experiment participants: %w", err)
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, c := range rows {
+		counts[c.variant] = c.n
+	}
+	return counts, nil
+}
+
+// FindVariant returns the experiment and variant the program is for the user, or nil if the
+// user practices it outside an experiment. The latest experiment wins if there are several.
+func (r *ExperimentRepository) FindVariant(ctx context.Context, userID, programID uuid.UUID) (*uuid.UUID, *string, error) {
+	var experimentID uuid.UUID
+	var variant string
+	err := database.Retry(ctx, "experiment_participants.FindVariant", func() error {
+		return r.db.QueryRow(ctx, `
+			SELECT e.id, p.variant
+			FROM experiment_participants p
+			JOIN program_experiments e ON e.id = p.experiment_id
+			WHERE p.user_id = $1
+			  AND ((p.variant = 'a' AND e.program_a_id = $2) OR (p.variant = 'b' AND e.program_b_id = $2))
+			ORDER BY p.assigned_at DESC
+			LIMIT 1
+		`, userID, programID).Scan(&experimentID, &variant)
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find experiment variant: %w", err)
+	}
+	return &experimentID, &variant, nil
+}
+
+// Results returns the variants that have participants, with the completion and adherence of
+// the sessions practiced in them. ProgramID is left for the caller.
+func (r *ExperimentRepository) Results(ctx context.Context, experimentID uuid.UUID) ([]models.VariantResult, error) {
+	query := `
+		SELECT p.variant,
+		       COUNT(DISTINCT p.user_id),
+		       COUNT(DISTINCT s.user_id) FILTER (WHERE s.completed_at IS NOT NULL),
+		       COUNT(s.id),
+		       COUNT(s.completed_at),
+		       AVG(s.completion_rate) FILTER (WHERE s.completed_at IS NOT NULL)::float8,
+		       (COUNT(s.completed_at) / (
+		           SELECT SUM(GREATEST(EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - w.assigned_at)) / 604800, 1))
+		           FROM experiment_participants w
+		           WHERE w.experiment_id = $1 AND w.variant = p.variant
+		       ))::float8
+		FROM experiment_participants p
+		LEFT JOIN practice_sessions s ON s.experiment_id = p.experiment_id AND s.user_id = p.user_id
+		     AND s.variant = p.variant AND s.deleted_at IS NULL
+		WHERE p.experiment_id = $1
+		GROUP BY p.variant
+		ORDER BY p.variant
+	`
+	results, err := collectWithRetry(ctx, r.db, "program_experiments.Results", query, func(row pgx.CollectableRow) (models.VariantResult, error) {
+		var v models.VariantResult
+		err := row.Scan(&v.Variant, &v.Participants, &v.ActiveParticipants, &v.SessionsStarted, &v.SessionsCompleted, &v.AverageCompletionRate, &v.SessionsPerWeek)
+		return v, err
+	}, experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare experiment variants: %w", err)
+	}
+	return results, nil
+}
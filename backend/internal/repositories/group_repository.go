@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+type GroupRepository struct {
+	db database.DB
+}
+
+func NewGroupRepository(db database.DB) *GroupRepository {
+	return &GroupRepository{db: db}
+}
+
+func (r *GroupRepository) Create(ctx context.Context, group *models.Group) error {
+	query := `
+		INSERT INTO groups (name, description, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(ctx, query,
+		group.Name,
+		group.Description,
+		group.CreatedBy,
+	).Scan(&group.ID, &group.CreatedAt)
+}
+
+func (r *GroupRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Group, error) {
+	var group models.Group
+	query := `
+		SELECT g.id, g.name, g.description, g.created_by, g.created_at,
+		       (SELECT COUNT(*) FROM group_members gm WHERE gm.group_id = g.id) as member_count
+		FROM groups g
+		WHERE g.id = $1
+	`
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&group.ID,
+		&group.Name,
+		&group.Description,
+		&group.CreatedBy,
+		&group.CreatedAt,
+		&group.MemberCount,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *GroupRepository) List(ctx context.Context) ([]models.Group, error) {
+	query := `
+		SELECT g.id, g.name, g.description, g.created_by, g.created_at,
+		       COUNT(gm.user_id) as member_count
+		FROM groups g
+		LEFT JOIN group_members gm ON gm.group_id = g.id
+		GROUP BY g.id
+		ORDER BY g.name
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make([]models.Group, 0)
+	for rows.Next() {
+		var group models.Group
+		err := rows.Scan(
+			&group.ID,
+			&group.Name,
+			&group.Description,
+			&group.CreatedBy,
+			&group.CreatedAt,
+			&group.MemberCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, rows.Err()
+}
+
+func (r *GroupRepository) NameExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM groups WHERE name = $1)`
+	err := r.db.QueryRow(ctx, query, name).Scan(&exists)
+	return exists, err
+}
+
+// AddMember adds a user to a group. Adding an existing member is a no-op.
+func (r *GroupRepository) AddMember(ctx context.Context, groupID, userID uuid.UUID) error {
+	query := `
+		INSERT INTO group_members (group_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (group_id, user_id) DO NOTHING
+	`
+	_, err := r.db.Exec(ctx, query, groupID, userID)
+	return err
+}
+
+// ListMemberIDs returns the active users in the group
+func (r *GroupRepository) ListMemberIDs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT gm.user_id
+		FROM group_members gm
+		JOIN users u ON u.id = gm.user_id
+		WHERE gm.group_id = $1 AND u.is_active = true
+		ORDER BY gm.added_at
+	`
+	rows, err := r.db.Query(ctx, query, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
+
+// MemberStats sums up the sessions of the group's active members, started or completed in
+// [from, to), ordered by name. Members without sessions are listed with zeros; streaks and
+// sessions per week are left for the caller.
+func (r *GroupRepository) MemberStats(ctx context.Context, groupID uuid.UUID, from, to time.Time) ([]models.GroupMemberStats, error) {
+	query := `
+		SELECT u.id, u.full_name, u.email,
+		       COUNT(s.id) FILTER (WHERE s.started_at >= $2 AND s.started_at < $3),
+		       COUNT(s.id) FILTER (WHERE s.completed_at >= $2 AND s.completed_at < $3),
+		       COALESCE(SUM(s.total_duration_seconds) FILTER (WHERE s.completed_at >= $2 AND s.completed_at < $3), 0) / 60,
+		       COUNT(DISTINCT s.completed_at::date) FILTER (WHERE s.completed_at >= $2 AND s.completed_at < $3),
+		       AVG(s.completion_rate) FILTER (WHERE s.completed_at >= $2 AND s.completed_at < $3)::float8,
+		       COUNT(s.completion_rate) FILTER (WHERE s.completed_at >= $2 AND s.completed_at < $3)
+		FROM group_members gm
+		JOIN users u ON u.id = gm.user_id
+		LEFT JOIN practice_sessions s ON s.user_id = u.id AND s.deleted_at IS NULL
+		     AND ((s.started_at >= $2 AND s.started_at < $3) OR (s.completed_at >= $2 AND s.completed_at < $3))
+		WHERE gm.group_id = $1 AND u.is_active = true
+		GROUP BY u.id, u.full_name, u.email
+		ORDER BY u.full_name
+	`
+	return collectWithRetry(ctx, r.db, "groups.MemberStats", query, func(row pgx.CollectableRow) (models.GroupMemberStats, error) {
+		var m models.GroupMemberStats
+		err := row.Scan(&m.UserID, &m.FullName, &m.Email,
+			&m.SessionsStarted, &m.SessionsCompleted, &m.PracticeMinutes, &m.PracticeDays, &m.AverageCompletionRate, &m.RatedSessions)
+		return m, err
+	}, groupID, from, to)
+}
@@ -0,0 +1,344 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+const homeworkSelect = `
+	SELECT h.id, h.program_id, p.name, h.group_id, h.title, h.instructions, h.requirement,
+	       h.required_sessions, h.due_at, h.created_by,
+	       (SELECT COUNT(*) FROM homework_students s WHERE s.homework_id = h.id),
+	       h.created_at, h.updated_at
+	FROM homework h
+	JOIN programs p ON p.id = h.program_id
+`
+
+// homeworkCompletedAt is when student hs.user_id met the requirement of homework h, or NULL.
+// Only sessions and submissions from after the homework was set count.
+const homeworkCompletedAt = `
+	CASE h.requirement
+	WHEN 'sessions' THEN (
+		SELECT ps.completed_at FROM practice_sessions ps
+		WHERE ps.user_id = hs.user_id AND ps.program_id = h.program_id
+		  AND ps.completed_at >= h.created_at AND ps.deleted_at IS NULL
+		ORDER BY ps.completed_at
+		OFFSET h.required_sessions - 1 LIMIT 1
+	)
+	ELSE (
+		SELECT MIN(sub.created_at) FROM submissions sub
+		WHERE sub.user_id = hs.user_id AND sub.program_id = h.program_id
+		  AND sub.created_at >= h.created_at AND sub.deleted_at IS NULL
+	)
+	END`
+
+// HomeworkFilter narrows homework lists; nil fields match everything
+type HomeworkFilter struct {
+	ProgramID *uuid.UUID
+	GroupID   *uuid.UUID
+}
+
+// HomeworkGroupCounts are the status counts of one group's students, nil group for students in none
+type HomeworkGroupCounts struct {
+	GroupID   *uuid.UUID
+	GroupName *string
+	Counts    models.HomeworkStatusCounts
+}
+
+type HomeworkRepository struct {
+	db database.DB
+}
+
+func NewHomeworkRepository(db database.DB) *HomeworkRepository {
+	return &HomeworkRepository{db: db}
+}
+
+// Create stores the homework for the given students
+func (r *HomeworkRepository) Create(ctx context.Context, homework *models.Homework, studentIDs []uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO homework (program_id, group_id, title, instructions, requirement, required_sessions, due_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`, homework.ProgramID, homework.GroupID, homework.Title, homework.Instructions, homework.Requirement,
+		homework.RequiredSessions, homework.DueAt, homework.CreatedBy,
+	).Scan(&homework.ID, &homework.CreatedAt, &homework.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO homework_students (homework_id, user_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING
+	`, homework.ID, studentIDs)
+	if err != nil {
+		return err
+	}
+	homework.StudentCount = len(studentIDs)
+
+	return tx.Commit(ctx)
+}
+
+// GetByID returns the homework, or nil if it does not exist
+func (r *HomeworkRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Homework, error) {
+	homework, err := scanHomework(r.db.QueryRow(ctx, homeworkSelect+`WHERE h.id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return homework, err
+}
+
+// List returns homework by deadline, latest first
+func (r *HomeworkRepository) List(ctx context.Context, filter HomeworkFilter) ([]models.Homework, error) {
+	query := homeworkSelect + `
+		WHERE ($1::uuid IS NULL OR h.program_id = $1)
+		  AND ($2::uuid IS NULL OR h.group_id = $2)
+		ORDER BY h.due_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, filter.ProgramID, filter.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	homework := make([]models.Homework, 0)
+	for rows.Next() {
+		item, err := scanHomework(rows)
+		if err != nil {
+			return nil, err
+		}
+		homework = append(homework, *item)
+	}
+	return homework, rows.Err()
+}
+
+// ListForStudent returns the student's homework by deadline, soonest first, with when each was completed
+func (r *HomeworkRepository) ListForStudent(ctx context.Context, userID uuid.UUID) ([]models.Homework, error) {
+	query := `
+		SELECT h.id, h.program_id, p.name, h.group_id, h.title, h.instructions, h.requirement,
+		       h.required_sessions, h.due_at, h.created_by,
+		       (SELECT COUNT(*) FROM homework_students s WHERE s.homework_id = h.id),
+		       h.created_at, h.updated_at, ` + homeworkCompletedAt + `
+		FROM homework_students hs
+		JOIN homework h ON h.id = hs.homework_id
+		JOIN programs p ON p.id = h.program_id
+		WHERE hs.user_id = $1
+		ORDER BY h.due_at
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	homework := make([]models.Homework, 0)
+	for rows.Next() {
+		var h models.Homework
+		err := rows.Scan(
+			&h.ID, &h.ProgramID, &h.ProgramName, &h.GroupID, &h.Title, &h.Instructions, &h.Requirement,
+			&h.RequiredSessions, &h.DueAt, &h.CreatedBy, &h.StudentCount, &h.CreatedAt, &h.UpdatedAt,
+			&h.CompletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		homework = append(homework, h)
+	}
+	return homework, rows.Err()
+}
+
+// Update saves the title, instructions and deadline. With resetOverdue the homework is
+// checked for overdue students again at its new deadline.
+func (r *HomeworkRepository) Update(ctx context.Context, homework *models.Homework, resetOverdue bool) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE homework SET title = $2, instructions = $3, due_at = $4,
+		       overdue_checked_at = CASE WHEN $5 THEN NULL ELSE overdue_checked_at END
+		WHERE id = $1
+		RETURNING updated_at
+	`, homework.ID, homework.Title, homework.Instructions, homework.DueAt, resetOverdue).Scan(&homework.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if resetOverdue {
+		if _, err := tx.Exec(ctx, `UPDATE homework_students SET overdue_at = NULL WHERE homework_id = $1`, homework.ID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *HomeworkRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM homework WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// ListStudents returns the homework's students by name with when each completed it.
+// The status is left for the caller to derive.
+func (r *HomeworkRepository) ListStudents(ctx context.Context, homeworkID uuid.UUID) ([]models.HomeworkStudent, error) {
+	query := `
+		SELECT hs.user_id, u.full_name, u.email, ` + homeworkCompletedAt + `, hs.overdue_at
+		FROM homework_students hs
+		JOIN homework h ON h.id = hs.homework_id
+		JOIN users u ON u.id = hs.user_id
+		WHERE hs.homework_id = $1
+		ORDER BY u.full_name
+	`
+	rows, err := r.db.Query(ctx, query, homeworkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	students := make([]models.HomeworkStudent, 0)
+	for rows.Next() {
+		var s models.HomeworkStudent
+		if err := rows.Scan(&s.UserID, &s.FullName, &s.Email, &s.CompletedAt, &s.OverdueAt); err != nil {
+			return nil, err
+		}
+		students = append(students, s)
+	}
+	return students, rows.Err()
+}
+
+// ClaimPastDue marks up to limit homework whose deadline passed as checked and returns it.
+// Claiming first means students are never notified twice, even with several API instances.
+func (r *HomeworkRepository) ClaimPastDue(ctx context.Context, now time.Time, limit int) ([]models.Homework, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE homework SET overdue_checked_at = $1
+			WHERE id IN (
+				SELECT id FROM homework
+				WHERE overdue_checked_at IS NULL AND due_at <= $1
+				ORDER BY due_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT h.id, h.program_id, p.name, h.group_id, h.title, h.instructions, h.requirement,
+		       h.required_sessions, h.due_at, h.created_by,
+		       (SELECT COUNT(*) FROM homework_students s WHERE s.homework_id = h.id),
+		       h.created_at, h.updated_at
+		FROM claimed h
+		JOIN programs p ON p.id = h.program_id
+		ORDER BY h.due_at
+	`
+	rows, err := r.db.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var homework []models.Homework
+	for rows.Next() {
+		item, err := scanHomework(rows)
+		if err != nil {
+			return nil, err
+		}
+		homework = append(homework, *item)
+	}
+	return homework, rows.Err()
+}
+
+// MarkOverdue marks the students who did not complete the homework by its deadline as overdue
+// and returns them. Students already marked are skipped.
+func (r *HomeworkRepository) MarkOverdue(ctx context.Context, homeworkID uuid.UUID, now time.Time) ([]uuid.UUID, error) {
+	query := `
+		UPDATE homework_students hs SET overdue_at = $2
+		FROM homework h
+		WHERE h.id = hs.homework_id AND hs.homework_id = $1 AND hs.overdue_at IS NULL
+		  AND COALESCE((` + homeworkCompletedAt + `) <= h.due_at, false) = false
+		RETURNING hs.user_id
+	`
+	rows, err := r.db.Query(ctx, query, homeworkID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
+
+// GroupCounts tallies the status of every student of homework due between from and to, per
+// group the student is a member of. Students in no group are counted under a nil group.
+func (r *HomeworkRepository) GroupCounts(ctx context.Context, programID *uuid.UUID, from, to, now time.Time) ([]HomeworkGroupCounts, error) {
+	query := `
+		WITH statuses AS (
+			SELECT hs.user_id, h.due_at, ` + homeworkCompletedAt + ` AS completed_at
+			FROM homework_students hs
+			JOIN homework h ON h.id = hs.homework_id
+			WHERE h.due_at >= $2 AND h.due_at < $3
+			  AND ($1::uuid IS NULL OR h.program_id = $1)
+		)
+		SELECT g.id, g.name,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE st.completed_at IS NULL AND st.due_at > $4),
+		       COUNT(*) FILTER (WHERE st.completed_at <= st.due_at),
+		       COUNT(*) FILTER (WHERE st.completed_at > st.due_at),
+		       COUNT(*) FILTER (WHERE st.completed_at IS NULL AND st.due_at <= $4)
+		FROM statuses st
+		LEFT JOIN group_members gm ON gm.user_id = st.user_id
+		LEFT JOIN groups g ON g.id = gm.group_id
+		GROUP BY g.id, g.name
+		ORDER BY g.name NULLS LAST
+	`
+	rows, err := r.db.Query(ctx, query, programID, from, to, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []HomeworkGroupCounts
+	for rows.Next() {
+		var g HomeworkGroupCounts
+		err := rows.Scan(&g.GroupID, &g.GroupName,
+			&g.Counts.Assigned, &g.Counts.Pending, &g.Counts.OnTime, &g.Counts.Late, &g.Counts.Overdue)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+func scanHomework(row pgx.Row) (*models.Homework, error) {
+	var h models.Homework
+	err := row.Scan(
+		&h.ID, &h.ProgramID, &h.ProgramName, &h.GroupID, &h.Title, &h.Instructions, &h.Requirement,
+		&h.RequiredSessions, &h.DueAt, &h.CreatedBy, &h.StudentCount, &h.CreatedAt, &h.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/clock"
+	"github.com/xuangong/backend/pkg/fieldcrypt"
+)
+
+type IntegrationKeyRepository struct {
+	db      database.DB
+	clock   clock.Clock
+	keyring *fieldcrypt.Keyring
+}
+
+func NewIntegrationKeyRepository(db database.DB) *IntegrationKeyRepository {
+	return &IntegrationKeyRepository{db: db, clock: clock.System}
+}
+
+// WithClock replaces the clock used for timestamps, so tests can control time
+func (r *IntegrationKeyRepository) WithClock(c clock.Clock) *IntegrationKeyRepository {
+	r.clock = c
+	return r
+}
+
+// WithKeyring encrypts the signing secrets with the keyring
+func (r *IntegrationKeyRepository) WithKeyring(keyring *fieldcrypt.Keyring) *IntegrationKeyRepository {
+	r.keyring = keyring
+	return r
+}
+
+const integrationKeyColumns = `id, name, secret, created_by, last_used_at, revoked_at, created_at`
+
+func (r *IntegrationKeyRepository) scan(row pgx.Row) (*models.IntegrationKey, error) {
+	var k models.IntegrationKey
+	err := row.Scan(
+		&k.ID,
+		&k.Name,
+		&k.Secret,
+		&k.CreatedBy,
+		&k.LastUsedAt,
+		&k.RevokedAt,
+		&k.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if k.Secret, err = r.keyring.Decrypt(k.Secret, integrationKeySecretColumn.context(k.ID)); err != nil {
+		return nil, fmt.Errorf("failed to decrypt integration key secret: %w", err)
+	}
+	return &k, nil
+}
+
+func (r *IntegrationKeyRepository) Create(ctx context.Context, k *models.IntegrationKey) error {
+	// The ID is part of the encryption context, so it is chosen before the insert
+	id := uuid.New()
+	secret, err := r.keyring.Encrypt(k.Secret, integrationKeySecretColumn.context(id))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt integration key secret: %w", err)
+	}
+	err = r.db.QueryRow(ctx, `
+		INSERT INTO integration_keys (id, name, secret, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, id, k.Name, secret, k.CreatedBy).Scan(&k.ID, &k.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create integration key: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns the key, or nil if it does not exist
+func (r *IntegrationKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.IntegrationKey, error) {
+	query := `SELECT ` + integrationKeyColumns + ` FROM integration_keys WHERE id = $1`
+
+	var k *models.IntegrationKey
+	err := database.Retry(ctx, "integration_keys.GetByID", func() error {
+		var err error
+		k, err = r.scan(r.db.QueryRow(ctx, query, id))
+		return err
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integration key: %w", err)
+	}
+	return k, nil
+}
+
+// List returns all keys, newest first
+func (r *IntegrationKeyRepository) List(ctx context.Context) ([]models.IntegrationKey, error) {
+	query := `SELECT ` + integrationKeyColumns + ` FROM integration_keys ORDER BY created_at DESC`
+	keys, err := collectWithRetry(ctx, r.db, "integration_keys.List", query, func(row pgx.CollectableRow) (models.IntegrationKey, error) {
+		k, err := r.scan(row)
+		if err != nil {
+			return models.IntegrationKey{}, err
+		}
+		return *k, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list integration keys: %w", err)
+	}
+	return keys, nil
+}
+
+// TouchLastUsed records that the integration used its key
+func (r *IntegrationKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE integration_keys SET last_used_at = $2 WHERE id = $1`, id, r.clock.Now())
+	return err
+}
+
+// Revoke invalidates a key and reports whether it was still active
+func (r *IntegrationKeyRepository) Revoke(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE integration_keys
+		SET revoked_at = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id, r.clock.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke integration key: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// UseNonce records the nonce of a signed request and reports whether it was new for the key.
+// A nonce seen before means the request is a replay.
+func (r *IntegrationKeyRepository) UseNonce(ctx context.Context, keyID uuid.UUID, nonce string) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		INSERT INTO integration_nonces (key_id, nonce, seen_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`, keyID, nonce, r.clock.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to record nonce: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// PurgeNonces deletes nonces seen before the cutoff and returns how many were removed
+func (r *IntegrationKeyRepository) PurgeNonces(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM integration_nonces WHERE seen_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge nonces: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/clock"
+)
+
+type InvitationRepository struct {
+	db    database.DB
+	clock clock.Clock
+}
+
+func NewInvitationRepository(db database.DB) *InvitationRepository {
+	return &InvitationRepository{db: db, clock: clock.System}
+}
+
+// WithClock replaces the clock used for timestamps, so tests can control time
+func (r *InvitationRepository) WithClock(c clock.Clock) *InvitationRepository {
+	r.clock = c
+	return r
+}
+
+const invitationColumns = `
+	id, token_hash, email, role, group_id, program_ids, created_by,
+	expires_at, accepted_at, accepted_by, revoked_at, created_at
+`
+
+func scanInvitation(row pgx.Row) (*models.Invitation, error) {
+	var inv models.Invitation
+	err := row.Scan(
+		&inv.ID,
+		&inv.TokenHash,
+		&inv.Email,
+		&inv.Role,
+		&inv.GroupID,
+		&inv.ProgramIDs,
+		&inv.CreatedBy,
+		&inv.ExpiresAt,
+		&inv.AcceptedAt,
+		&inv.AcceptedBy,
+		&inv.RevokedAt,
+		&inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (r *InvitationRepository) Create(ctx context.Context, inv *models.Invitation) error {
+	query := `
+		INSERT INTO invitations (token_hash, email, role, group_id, program_ids, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(ctx, query,
+		inv.TokenHash,
+		inv.Email,
+		inv.Role,
+		inv.GroupID,
+		inv.ProgramIDs,
+		inv.CreatedBy,
+		inv.ExpiresAt,
+	).Scan(&inv.ID, &inv.CreatedAt)
+}
+
+func (r *InvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE token_hash = $1`
+	inv, err := scanInvitation(r.db.QueryRow(ctx, query, tokenHash))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return inv, err
+}
+
+func (r *InvitationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM invitations WHERE id = $1`
+	inv, err := scanInvitation(r.db.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return inv, err
+}
+
+// List returns invitations, newest first. pendingOnly excludes accepted and revoked ones.
+func (r *InvitationRepository) List(ctx context.Context, pendingOnly bool, limit, offset int) ([]models.Invitation, error) {
+	query := `SELECT ` + invitationColumns + `
+		FROM invitations
+		WHERE ($1 = false OR (accepted_at IS NULL AND revoked_at IS NULL))
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(ctx, query, pendingOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := make([]models.Invitation, 0)
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, *inv)
+	}
+
+	return invitations, rows.Err()
+}
+
+// Claim atomically marks a pending, unexpired invitation as accepted so it cannot be used twice.
+// Returns false if the invitation was already used, revoked or has expired.
+func (r *InvitationRepository) Claim(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE invitations
+		SET accepted_at = $2
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+	`, id, r.clock.Now())
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// Release undoes a claim when registration fails after the invitation was claimed
+func (r *InvitationRepository) Release(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE invitations SET accepted_at = NULL WHERE id = $1 AND accepted_by IS NULL`, id)
+	return err
+}
+
+// SetAcceptedBy records the user created from a claimed invitation
+func (r *InvitationRepository) SetAcceptedBy(ctx context.Context, id, userID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE invitations SET accepted_by = $2 WHERE id = $1`, id, userID)
+	return err
+}
+
+// Revoke invalidates a pending invitation
+func (r *InvitationRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `
+		UPDATE invitations
+		SET revoked_at = $2
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+	`, id, r.clock.Now())
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
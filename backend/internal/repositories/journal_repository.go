@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+type JournalRepository struct {
+	db database.DB
+}
+
+func NewJournalRepository(db database.DB) *JournalRepository {
+	return &JournalRepository{db: db}
+}
+
+const journalColumns = `
+	j.id, j.user_id, j.program_id, j.exercise_id, j.caption, j.media_key, j.media_type,
+	j.content_type, j.size_bytes, j.recorded_at, j.created_at`
+
+func scanJournalEntry(row pgx.Row, entry *models.JournalEntry) error {
+	return row.Scan(
+		&entry.ID,
+		&entry.UserID,
+		&entry.ProgramID,
+		&entry.ExerciseID,
+		&entry.Caption,
+		&entry.MediaKey,
+		&entry.MediaType,
+		&entry.ContentType,
+		&entry.SizeBytes,
+		&entry.RecordedAt,
+		&entry.CreatedAt,
+	)
+}
+
+func (r *JournalRepository) Create(ctx context.Context, entry *models.JournalEntry) error {
+	query := `
+		INSERT INTO journal_entries (
+			user_id, program_id, exercise_id, caption, media_key, media_type,
+			content_type, size_bytes, recorded_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(ctx, query,
+		entry.UserID,
+		entry.ProgramID,
+		entry.ExerciseID,
+		entry.Caption,
+		entry.MediaKey,
+		entry.MediaType,
+		entry.ContentType,
+		entry.SizeBytes,
+		entry.RecordedAt,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+func (r *JournalRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.JournalEntry, error) {
+	query := `SELECT ` + journalColumns + ` FROM journal_entries j WHERE j.id = $1`
+
+	var entry models.JournalEntry
+	err := database.Retry(ctx, "journal_entries.GetByID", func() error {
+		return scanJournalEntry(r.db.QueryRow(ctx, query, id), &entry)
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ListByUser returns the student's entries, most recently recorded first, optionally for one program
+func (r *JournalRepository) ListByUser(ctx context.Context, userID uuid.UUID, programID *uuid.UUID, limit, offset int) ([]models.JournalEntry, error) {
+	query := `
+		SELECT ` + journalColumns + `
+		FROM journal_entries j
+		WHERE j.user_id = $1 AND ($2::uuid IS NULL OR j.program_id = $2)
+		ORDER BY j.recorded_at DESC, j.id
+		LIMIT $3 OFFSET $4
+	`
+	return r.list(ctx, "journal_entries.ListByUser", query, userID, programID, limit, offset)
+}
+
+// ListSharedWith returns entries shared with the instructor, most recently recorded first,
+// optionally of one student
+func (r *JournalRepository) ListSharedWith(ctx context.Context, instructorID uuid.UUID, studentID *uuid.UUID, limit, offset int) ([]models.JournalEntry, error) {
+	query := `
+		SELECT ` + journalColumns + `
+		FROM journal_entries j
+		JOIN journal_shares js ON js.entry_id = j.id
+		WHERE js.instructor_id = $1 AND ($2::uuid IS NULL OR j.user_id = $2)
+		ORDER BY j.recorded_at DESC, j.id
+		LIMIT $3 OFFSET $4
+	`
+	return r.list(ctx, "journal_entries.ListSharedWith", query, instructorID, studentID, limit, offset)
+}
+
+func (r *JournalRepository) list(ctx context.Context, op, query string, args ...interface{}) ([]models.JournalEntry, error) {
+	return collectWithRetry(ctx, r.db, op, query, func(row pgx.CollectableRow) (models.JournalEntry, error) {
+		var entry models.JournalEntry
+		err := scanJournalEntry(row, &entry)
+		return entry, err
+	}, args...)
+}
+
+// Delete removes the entry and its shares, reporting whether it existed
+func (r *JournalRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM journal_entries WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// Share gives the instructor access to the entry. Sharing twice is not an error.
+func (r *JournalRepository) Share(ctx context.Context, entryID, instructorID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO journal_shares (entry_id, instructor_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, entryID, instructorID)
+	return err
+}
+
+// Unshare revokes the instructor's access and reports whether the entry was shared with them
+func (r *JournalRepository) Unshare(ctx context.Context, entryID, instructorID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM journal_shares WHERE entry_id = $1 AND instructor_id = $2`, entryID, instructorID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// IsSharedWith reports whether the entry is shared with the instructor
+func (r *JournalRepository) IsSharedWith(ctx context.Context, entryID, instructorID uuid.UUID) (bool, error) {
+	var shared bool
+	err := database.Retry(ctx, "journal_shares.IsSharedWith", func() error {
+		return r.db.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM journal_shares WHERE entry_id = $1 AND instructor_id = $2)
+		`, entryID, instructorID).Scan(&shared)
+	})
+	return shared, err
+}
+
+// SharesForEntries returns the instructors each entry is shared with, in the order they were added
+func (r *JournalRepository) SharesForEntries(ctx context.Context, entryIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	query := `
+		SELECT entry_id, instructor_id
+		FROM journal_shares
+		WHERE entry_id = ANY($1::uuid[])
+		ORDER BY shared_at, instructor_id
+	`
+	type share struct {
+		entryID, instructorID uuid.UUID
+	}
+	rows, err := collectWithRetry(ctx, r.db, "journal_shares.ForEntries", query, func(row pgx.CollectableRow) (share, error) {
+		var s share
+		err := row.Scan(&s.entryID, &s.instructorID)
+		return s, err
+	}, entryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make(map[uuid.UUID][]uuid.UUID)
+	for _, s := range rows {
+		shares[s.entryID] = append(shares[s.entryID], s.instructorID)
+	}
+	return shares, nil
+}
@@ -0,0 +1,263 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/fieldcrypt"
+)
+
+type LimitationRepository struct {
+	db      database.DB
+	keyring *fieldcrypt.Keyring
+}
+
+func NewLimitationRepository(db database.DB) *LimitationRepository {
+	return &LimitationRepository{db: db}
+}
+
+// WithKeyring encrypts the joints, conditions and notes, which describe the student's health,
+// with the keyring
+func (r *LimitationRepository) WithKeyring(keyring *fieldcrypt.Keyring) *LimitationRepository {
+	r.keyring = keyring
+	return r
+}
+
+// GetByUser returns the student's limitations, or nil if they never recorded any
+func (r *LimitationRepository) GetByUser(ctx context.Context, userID uuid.UUID) (*models.Limitations, error) {
+	query := `
+		SELECT user_id, joints, conditions, notes, updated_at
+		FROM user_limitations
+		WHERE user_id = $1
+	`
+	var limitations models.Limitations
+	var joints, conditions string
+	err := database.Retry(ctx, "user_limitations.GetByUser", func() error {
+		return r.db.QueryRow(ctx, query, userID).Scan(
+			&limitations.UserID,
+			&joints,
+			&conditions,
+			&limitations.Notes,
+			&limitations.UpdatedAt,
+		)
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	limitations.Joints = []string{}
+	if err := limitationJointsColumn.decryptJSON(r.keyring, userID, joints, &limitations.Joints); err != nil {
+		return nil, err
+	}
+	limitations.Conditions = []string{}
+	if err := limitationConditionsColumn.decryptJSON(r.keyring, userID, conditions, &limitations.Conditions); err != nil {
+		return nil, err
+	}
+	if limitations.Notes, err = r.keyring.Decrypt(limitations.Notes, limitationNotesColumn.context(userID)); err != nil {
+		return nil, fmt.Errorf("failed to decrypt limitation notes: %w", err)
+	}
+	return &limitations, nil
+}
+
+// Save creates or replaces the student's limitations
+func (r *LimitationRepository) Save(ctx context.Context, limitations *models.Limitations) error {
+	query := `
+		INSERT INTO user_limitations (user_id, joints, conditions, notes)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id)
+		DO UPDATE SET joints = EXCLUDED.joints, conditions = EXCLUDED.conditions,
+			notes = EXCLUDED.notes, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`
+	joints, err := limitationJointsColumn.encryptJSON(r.keyring, limitations.UserID, nonNil(limitations.Joints))
+	if err != nil {
+		return err
+	}
+	conditions, err := limitationConditionsColumn.encryptJSON(r.keyring, limitations.UserID, nonNil(limitations.Conditions))
+	if err != nil {
+		return err
+	}
+	notes, err := r.keyring.Encrypt(limitations.Notes, limitationNotesColumn.context(limitations.UserID))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt limitation notes: %w", err)
+	}
+	return r.db.QueryRow(ctx, query,
+		limitations.UserID,
+		joints,
+		conditions,
+		notes,
+	).Scan(&limitations.UpdatedAt)
+}
+
+// GetReview returns the latest review of the student's adjusted program, or nil if there is none
+func (r *LimitationRepository) GetReview(ctx context.Context, userID, programID uuid.UUID) (*models.PlanReview, error) {
+	query := `
+		SELECT user_id, program_id, status, note, limitations_updated_at, reviewed_by, reviewed_at
+		FROM plan_reviews
+		WHERE user_id = $1 AND program_id = $2
+	`
+	var review models.PlanReview
+	err := database.Retry(ctx, "plan_reviews.GetReview", func() error {
+		return r.db.QueryRow(ctx, query, userID, programID).Scan(
+			&review.UserID,
+			&review.ProgramID,
+			&review.Status,
+			&review.Note,
+			&review.LimitationsUpdatedAt,
+			&review.ReviewedBy,
+			&review.ReviewedAt,
+		)
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetReviews returns the student's latest reviews by program
+func (r *LimitationRepository) GetReviews(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]models.PlanReview, error) {
+	query := `
+		SELECT user_id, program_id, status, note, limitations_updated_at, reviewed_by, reviewed_at
+		FROM plan_reviews
+		WHERE user_id = $1
+	`
+	rows, err := collectWithRetry(ctx, r.db, "plan_reviews.GetReviews", query, func(row pgx.CollectableRow) (models.PlanReview, error) {
+		var review models.PlanReview
+		err := row.Scan(
+			&review.UserID,
+			&review.ProgramID,
+			&review.Status,
+			&review.Note,
+			&review.LimitationsUpdatedAt,
+			&review.ReviewedBy,
+			&review.ReviewedAt,
+		)
+		return review, err
+	}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make(map[uuid.UUID]models.PlanReview, len(rows))
+	for _, review := range rows {
+		reviews[review.ProgramID] = review
+	}
+	return reviews, nil
+}
+
+// SaveReview creates or replaces the review of the student's adjusted program
+func (r *LimitationRepository) SaveReview(ctx context.Context, review *models.PlanReview) error {
+	query := `
+		INSERT INTO plan_reviews (user_id, program_id, status, note, limitations_updated_at, reviewed_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, program_id)
+		DO UPDATE SET status = EXCLUDED.status, note = EXCLUDED.note,
+			limitations_updated_at = EXCLUDED.limitations_updated_at,
+			reviewed_by = EXCLUDED.reviewed_by, reviewed_at = CURRENT_TIMESTAMP
+		RETURNING reviewed_at
+	`
+	return r.db.QueryRow(ctx, query,
+		review.UserID,
+		review.ProgramID,
+		review.Status,
+		review.Note,
+		review.LimitationsUpdatedAt,
+		review.ReviewedBy,
+	).Scan(&review.ReviewedAt)
+}
+
+// ListPendingReviews returns active assignments with an exercise contraindicated for the
+// student whose plan was not reviewed since the student last changed their limitations,
+// oldest profile change first. The joints and conditions are encrypted, so the query lists the
+// assignments of students with limitations to programs with any contraindication, and they are
+// matched here.
+func (r *LimitationRepository) ListPendingReviews(ctx context.Context) ([]models.PlanReviewQueueItem, error) {
+	query := `
+		SELECT u.id, u.full_name, u.email, p.id, p.name, ul.updated_at, ul.joints, ul.conditions,
+		       ARRAY(
+				SELECT DISTINCT c FROM exercises e, unnest(e.contraindications) c
+				WHERE e.program_id = up.program_id
+		       )
+		FROM user_programs up
+		JOIN user_limitations ul ON ul.user_id = up.user_id
+		JOIN users u ON u.id = up.user_id
+		JOIN programs p ON p.id = up.program_id
+		LEFT JOIN plan_reviews pr ON pr.user_id = up.user_id AND pr.program_id = up.program_id
+		WHERE up.is_active = true
+		  AND p.deleted_at IS NULL
+		  AND EXISTS (
+			SELECT 1 FROM exercises e
+			WHERE e.program_id = up.program_id
+			  AND cardinality(e.contraindications) > 0
+		  )
+		  AND (pr.user_id IS NULL OR pr.limitations_updated_at <> ul.updated_at)
+		ORDER BY ul.updated_at, u.id, p.id
+	`
+	type candidate struct {
+		item              models.PlanReviewQueueItem
+		joints            string
+		conditions        string
+		contraindications []string
+	}
+	candidates, err := collectWithRetry(ctx, r.db, "plan_reviews.ListPending", query, func(row pgx.CollectableRow) (candidate, error) {
+		var c candidate
+		err := row.Scan(
+			&c.item.UserID,
+			&c.item.UserName,
+			&c.item.UserEmail,
+			&c.item.ProgramID,
+			&c.item.ProgramName,
+			&c.item.LimitationsUpdatedAt,
+			&c.joints,
+			&c.conditions,
+			&c.contraindications,
+		)
+		return c, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.PlanReviewQueueItem, 0)
+	for _, c := range candidates {
+		var limitations models.Limitations
+		if err := limitationJointsColumn.decryptJSON(r.keyring, c.item.UserID, c.joints, &limitations.Joints); err != nil {
+			return nil, err
+		}
+		if err := limitationConditionsColumn.decryptJSON(r.keyring, c.item.UserID, c.conditions, &limitations.Conditions); err != nil {
+			return nil, err
+		}
+		if overlaps(limitations.All(), c.contraindications) {
+			items = append(items, c.item)
+		}
+	}
+	return items, nil
+}
+
+// nonNil returns values, or an empty slice if it is nil, so it is stored as an empty JSON array
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// overlaps reports whether a and b have a value in common
+func overlaps(a, b []string) bool {
+	for _, v := range a {
+		if slices.Contains(b, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,320 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+// ErrCheckInCodeTaken is returned when another class already uses a check-in code
+var ErrCheckInCodeTaken = errors.New("check-in code is already in use")
+
+const liveClassSelect = `
+	SELECT c.id, c.title, c.description, c.location, c.program_id, c.group_id, c.instructor_id,
+	       c.starts_at, c.ends_at,
+	       (SELECT COUNT(*) FROM class_attendance a WHERE a.class_id = c.id AND a.status IN ('present', 'late')),
+	       c.check_in_code, c.check_in_expires_at, c.created_at, c.updated_at
+	FROM live_classes c
+`
+
+// LiveClassFilter narrows class lists; nil fields match everything
+type LiveClassFilter struct {
+	From    *time.Time
+	To      *time.Time
+	GroupID *uuid.UUID
+	// VisibleTo limits the list to classes without a group or of a group the user is in
+	VisibleTo *uuid.UUID
+}
+
+type LiveClassRepository struct {
+	db database.DB
+}
+
+func NewLiveClassRepository(db database.DB) *LiveClassRepository {
+	return &LiveClassRepository{db: db}
+}
+
+func (r *LiveClassRepository) Create(ctx context.Context, class *models.LiveClass) error {
+	query := `
+		INSERT INTO live_classes (title, description, location, program_id, group_id, instructor_id, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+	return r.db.QueryRow(ctx, query,
+		class.Title,
+		class.Description,
+		class.Location,
+		class.ProgramID,
+		class.GroupID,
+		class.InstructorID,
+		class.StartsAt,
+		class.EndsAt,
+	).Scan(&class.ID, &class.CreatedAt, &class.UpdatedAt)
+}
+
+// GetByID returns the class, or nil if it does not exist
+func (r *LiveClassRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.LiveClass, error) {
+	class, err := scanLiveClass(r.db.QueryRow(ctx, liveClassSelect+`WHERE c.id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return class, err
+}
+
+// GetByCheckInCode returns the class whose check-in code is still valid at now, or nil
+func (r *LiveClassRepository) GetByCheckInCode(ctx context.Context, code string, now time.Time) (*models.LiveClass, error) {
+	class, err := scanLiveClass(r.db.QueryRow(ctx, liveClassSelect+`WHERE c.check_in_code = $1 AND c.check_in_expires_at > $2`, code, now))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return class, err
+}
+
+// List returns classes by start time
+func (r *LiveClassRepository) List(ctx context.Context, filter LiveClassFilter) ([]models.LiveClass, error) {
+	query := liveClassSelect + `
+		WHERE ($1::timestamp IS NULL OR c.ends_at >= $1)
+		  AND ($2::timestamp IS NULL OR c.starts_at < $2)
+		  AND ($3::uuid IS NULL OR c.group_id = $3)
+		  AND ($4::uuid IS NULL OR c.group_id IS NULL
+		       OR EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = c.group_id AND gm.user_id = $4))
+		ORDER BY c.starts_at
+	`
+	rows, err := r.db.Query(ctx, query, filter.From, filter.To, filter.GroupID, filter.VisibleTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	classes := make([]models.LiveClass, 0)
+	for rows.Next() {
+		class, err := scanLiveClass(rows)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, *class)
+	}
+	return classes, rows.Err()
+}
+
+func (r *LiveClassRepository) Update(ctx context.Context, class *models.LiveClass) error {
+	query := `
+		UPDATE live_classes
+		SET title = $2, description = $3, location = $4, program_id = $5, group_id = $6, starts_at = $7, ends_at = $8
+		WHERE id = $1
+		RETURNING updated_at
+	`
+	return r.db.QueryRow(ctx, query,
+		class.ID,
+		class.Title,
+		class.Description,
+		class.Location,
+		class.ProgramID,
+		class.GroupID,
+		class.StartsAt,
+		class.EndsAt,
+	).Scan(&class.UpdatedAt)
+}
+
+func (r *LiveClassRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM live_classes WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// SetCheckInCode replaces the class's check-in code, returning ErrCheckInCodeTaken if another class has it
+func (r *LiveClassRepository) SetCheckInCode(ctx context.Context, id uuid.UUID, code string, expiresAt time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE live_classes SET check_in_code = $2, check_in_expires_at = $3
+		WHERE id = $1
+	`, id, code, expiresAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrCheckInCodeTaken
+	}
+	return err
+}
+
+// IsVisibleTo reports whether the user can see the class: it has no group, or the user is in it
+func (r *LiveClassRepository) IsVisibleTo(ctx context.Context, class *models.LiveClass, userID uuid.UUID) (bool, error) {
+	if class.GroupID == nil {
+		return true, nil
+	}
+	var member bool
+	query := `SELECT EXISTS(SELECT 1 FROM group_members WHERE group_id = $1 AND user_id = $2)`
+	err := r.db.QueryRow(ctx, query, class.GroupID, userID).Scan(&member)
+	return member, err
+}
+
+// MarkAttendance records attendance for the given students, overwriting earlier marks
+func (r *LiveClassRepository) MarkAttendance(ctx context.Context, entries []models.ClassAttendance) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, entry := range entries {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO class_attendance (class_id, user_id, status, method, marked_by, marked_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (class_id, user_id) DO UPDATE
+			SET status = $3, method = $4, marked_by = $5, marked_at = $6
+		`, entry.ClassID, entry.UserID, entry.Status, entry.Method, entry.MarkedBy, entry.MarkedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CheckIn records the student as present unless their attendance was already marked,
+// and reports whether it was recorded
+func (r *LiveClassRepository) CheckIn(ctx context.Context, attendance *models.ClassAttendance) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		INSERT INTO class_attendance (class_id, user_id, status, method, marked_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (class_id, user_id) DO NOTHING
+	`, attendance.ClassID, attendance.UserID, attendance.Status, attendance.Method, attendance.MarkedAt)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// RemoveAttendance deletes a student's attendance mark and reports whether there was one
+func (r *LiveClassRepository) RemoveAttendance(ctx context.Context, classID, userID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM class_attendance WHERE class_id = $1 AND user_id = $2`, classID, userID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// GetAttendanceStatus returns the student's attendance of the class, or nil if not marked
+func (r *LiveClassRepository) GetAttendanceStatus(ctx context.Context, classID, userID uuid.UUID) (*models.AttendanceStatus, error) {
+	var status models.AttendanceStatus
+	err := r.db.QueryRow(ctx, `SELECT status FROM class_attendance WHERE class_id = $1 AND user_id = $2`, classID, userID).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// ListAttendance returns the class's attendance marks by student name
+func (r *LiveClassRepository) ListAttendance(ctx context.Context, classID uuid.UUID) ([]models.ClassAttendance, error) {
+	query := `
+		SELECT a.class_id, a.user_id, u.full_name, u.email, a.status, a.method, a.marked_by, a.marked_at
+		FROM class_attendance a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.class_id = $1
+		ORDER BY u.full_name
+	`
+	rows, err := r.db.Query(ctx, query, classID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attendance := make([]models.ClassAttendance, 0)
+	for rows.Next() {
+		var a models.ClassAttendance
+		if err := rows.Scan(&a.ClassID, &a.UserID, &a.FullName, &a.Email, &a.Status, &a.Method, &a.MarkedBy, &a.MarkedAt); err != nil {
+			return nil, err
+		}
+		attendance = append(attendance, a)
+	}
+	return attendance, rows.Err()
+}
+
+// Report sums up class attendance and completed practice sessions per student for classes
+// starting and sessions completed in [from, to). With a group, all its members are listed and
+// only classes for everyone or that group count; otherwise only students with any activity.
+func (r *LiveClassRepository) Report(ctx context.Context, groupID *uuid.UUID, from, to time.Time) (int, []models.StudentAttendance, error) {
+	var classes int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM live_classes
+		WHERE starts_at >= $1 AND starts_at < $2
+		  AND ($3::uuid IS NULL OR group_id IS NULL OR group_id = $3)
+	`, from, to, groupID).Scan(&classes)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	query := `
+		WITH attendance AS (
+			SELECT a.user_id,
+			       COUNT(*) FILTER (WHERE a.status IN ('present', 'late')) AS attended,
+			       COUNT(*) FILTER (WHERE a.status = 'late') AS late,
+			       COUNT(*) FILTER (WHERE a.status = 'excused') AS excused,
+			       COUNT(*) FILTER (WHERE a.status = 'absent') AS missed,
+			       COALESCE(SUM(EXTRACT(EPOCH FROM (c.ends_at - c.starts_at))) FILTER (WHERE a.status IN ('present', 'late')), 0)::int / 60 AS minutes
+			FROM class_attendance a
+			JOIN live_classes c ON c.id = a.class_id
+			WHERE c.starts_at >= $1 AND c.starts_at < $2
+			  AND ($3::uuid IS NULL OR c.group_id IS NULL OR c.group_id = $3)
+			GROUP BY a.user_id
+		),
+		practice AS (
+			SELECT user_id, COUNT(*) AS sessions, COALESCE(SUM(total_duration_seconds), 0) / 60 AS minutes
+			FROM practice_sessions
+			WHERE completed_at >= $1 AND completed_at < $2 AND deleted_at IS NULL
+			GROUP BY user_id
+		)
+		SELECT u.id, u.full_name, u.email,
+		       COALESCE(a.attended, 0), COALESCE(a.late, 0), COALESCE(a.excused, 0), COALESCE(a.missed, 0),
+		       COALESCE(a.minutes, 0), COALESCE(p.sessions, 0), COALESCE(p.minutes, 0)
+		FROM users u
+		LEFT JOIN attendance a ON a.user_id = u.id
+		LEFT JOIN practice p ON p.user_id = u.id
+		WHERE u.role = 'student' AND u.is_active = true
+		  AND CASE WHEN $3::uuid IS NULL THEN a.user_id IS NOT NULL OR p.user_id IS NOT NULL
+		           ELSE EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = $3 AND gm.user_id = u.id)
+		      END
+		ORDER BY u.full_name
+	`
+	rows, err := r.db.Query(ctx, query, from, to, groupID)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	students := make([]models.StudentAttendance, 0)
+	for rows.Next() {
+		var s models.StudentAttendance
+		err := rows.Scan(&s.UserID, &s.FullName, &s.Email,
+			&s.ClassesAttended, &s.ClassesLate, &s.ClassesExcused, &s.ClassesMissed,
+			&s.ClassMinutes, &s.PracticeSessions, &s.PracticeMinutes)
+		if err != nil {
+			return 0, nil, err
+		}
+		students = append(students, s)
+	}
+	return classes, students, rows.Err()
+}
+
+func scanLiveClass(row pgx.Row) (*models.LiveClass, error) {
+	var c models.LiveClass
+	err := row.Scan(
+		&c.ID, &c.Title, &c.Description, &c.Location, &c.ProgramID, &c.GroupID, &c.InstructorID,
+		&c.StartsAt, &c.EndsAt, &c.Attendees,
+		&c.CheckInCode, &c.CheckInExpiresAt, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
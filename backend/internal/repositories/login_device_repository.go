@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/clock"
+)
+
+type LoginDeviceRepository struct {
+	db    database.DB
+	clock clock.Clock
+}
+
+func NewLoginDeviceRepository(db database.DB) *LoginDeviceRepository {
+	return &LoginDeviceRepository{db: db, clock: clock.System}
+}
+
+// WithClock replaces the clock used for timestamps, so tests can control time
+func (r *LoginDeviceRepository) WithClock(c clock.Clock) *LoginDeviceRepository {
+	r.clock = c
+	return r
+}
+
+const loginDeviceColumns = `id, user_id, fingerprint, client_ip, user_agent, device, revoke_token_hash, first_seen_at, last_seen_at, revoked_at`
+
+func scanLoginDevice(row pgx.Row) (*models.LoginDevice, error) {
+	var d models.LoginDevice
+	err := row.Scan(
+		&d.ID,
+		&d.UserID,
+		&d.Fingerprint,
+		&d.ClientIP,
+		&d.UserAgent,
+		&d.Device,
+		&d.RevokeTokenHash,
+		&d.FirstSeenAt,
+		&d.LastSeenAt,
+		&d.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Create records a new device. A revoked device with the same fingerprint is replaced, so
+// signing in from it again is treated like a new device.
+func (r *LoginDeviceRepository) Create(ctx context.Context, d *models.LoginDevice) error {
+	now := r.clock.Now()
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO login_devices (user_id, fingerprint, client_ip, user_agent, device, revoke_token_hash, first_seen_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		ON CONFLICT (user_id, fingerprint) DO UPDATE
+		SET client_ip = EXCLUDED.client_ip, user_agent = EXCLUDED.user_agent, device = EXCLUDED.device,
+			revoke_token_hash = EXCLUDED.revoke_token_hash, first_seen_at = EXCLUDED.first_seen_at,
+			last_seen_at = EXCLUDED.last_seen_at, revoked_at = NULL
+		RETURNING id, first_seen_at, last_seen_at
+	`, d.UserID, d.Fingerprint, d.ClientIP, d.UserAgent, d.Device, d.RevokeTokenHash, now).Scan(&d.ID, &d.FirstSeenAt, &d.LastSeenAt)
+	if err != nil {
+		return fmt.Errorf("failed to create login device: %w", err)
+	}
+	return nil
+}
+
+// GetByFingerprint returns the user's device with the fingerprint, or nil if there is none
+func (r *LoginDeviceRepository) GetByFingerprint(ctx context.Context, userID uuid.UUID, fingerprint string) (*models.LoginDevice, error) {
+	query := `SELECT ` + loginDeviceColumns + ` FROM login_devices WHERE user_id = $1 AND fingerprint = $2`
+
+	var d *models.LoginDevice
+	err := database.Retry(ctx, "login_devices.GetByFingerprint", func() error {
+		var err error
+		d, err = scanLoginDevice(r.db.QueryRow(ctx, query, userID, fingerprint))
+		return err
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login device: %w", err)
+	}
+	return d, nil
+}
+
+// GetByID returns the device, or nil if it does not exist
+func (r *LoginDeviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.LoginDevice, error) {
+	query := `SELECT ` + loginDeviceColumns + ` FROM login_devices WHERE id = $1`
+
+	var d *models.LoginDevice
+	err := database.Retry(ctx, "login_devices.GetByID", func() error {
+		var err error
+		d, err = scanLoginDevice(r.db.QueryRow(ctx, query, id))
+		return err
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login device: %w", err)
+	}
+	return d, nil
+}
+
+// HasAny reports whether the user has signed in from any device before
+func (r *LoginDeviceRepository) HasAny(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := database.Retry(ctx, "login_devices.HasAny", func() error {
+		return r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM login_devices WHERE user_id = $1)`, userID).Scan(&exists)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check login devices: %w", err)
+	}
+	return exists, nil
+}
+
+// ListByUser returns the user's devices, most recently used first
+func (r *LoginDeviceRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.LoginDevice, error) {
+	query := `SELECT ` + loginDeviceColumns + ` FROM login_devices WHERE user_id = $1 ORDER BY last_seen_at DESC`
+	devices, err := collectWithRetry(ctx, r.db, "login_devices.ListByUser", query, func(row pgx.CollectableRow) (models.LoginDevice, error) {
+		d, err := scanLoginDevice(row)
+		if err != nil {
+			return models.LoginDevice{}, err
+		}
+		return *d, nil
+	}, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list login devices: %w", err)
+	}
+	return devices, nil
+}
+
+// Touch records another sign-in from a known device
+func (r *LoginDeviceRepository) Touch(ctx context.Context, id uuid.UUID, clientIP *string) error {
+	_, err := r.db.Exec(ctx, `UPDATE login_devices SET last_seen_at = $2, client_ip = $3 WHERE id = $1`, id, r.clock.Now(), clientIP)
+	return err
+}
+
+// Revoke signs out a device and reports whether it was still active
+func (r *LoginDeviceRepository) Revoke(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE login_devices
+		SET revoked_at = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id, r.clock.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke login device: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// RevokeByToken signs out the device the revocation link was sent for and returns it, or nil
+// if the token is unknown. Using a link again keeps the original revocation time.
+func (r *LoginDeviceRepository) RevokeByToken(ctx context.Context, tokenHash string) (*models.LoginDevice, error) {
+	d, err := scanLoginDevice(r.db.QueryRow(ctx, `
+		UPDATE login_devices
+		SET revoked_at = COALESCE(revoked_at, $2)
+		WHERE revoke_token_hash = $1
+		RETURNING `+loginDeviceColumns,
+		tokenHash, r.clock.Now()))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke login device: %w", err)
+	}
+	return d, nil
+}
+
+// ListRevokedSince returns the IDs of devices revoked at or after since
+func (r *LoginDeviceRepository) ListRevokedSince(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	ids, err := collectWithRetry(ctx, r.db, "login_devices.ListRevokedSince", `SELECT id FROM login_devices WHERE revoked_at >= $1`, pgx.RowTo[uuid.UUID], since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revoked login devices: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+type MetadataSchemaRepository struct {
+	db database.DB
+}
+
+func NewMetadataSchemaRepository(db database.DB) *MetadataSchemaRepository {
+	return &MetadataSchemaRepository{db: db}
+}
+
+func (r *MetadataSchemaRepository) Get(ctx context.Context, entityType models.MetadataEntityType) (*models.MetadataSchema, error) {
+	var schema models.MetadataSchema
+	query := `
+		SELECT entity_type, schema, updated_by, updated_at
+		FROM metadata_schemas
+		WHERE entity_type = $1
+	`
+	err := r.db.QueryRow(ctx, query, entityType).Scan(
+		&schema.EntityType,
+		&schema.Schema,
+		&schema.UpdatedBy,
+		&schema.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (r *MetadataSchemaRepository) List(ctx context.Context) ([]models.MetadataSchema, error) {
+	query := `
+		SELECT entity_type, schema, updated_by, updated_at
+		FROM metadata_schemas
+		ORDER BY entity_type
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schemas := make([]models.MetadataSchema, 0)
+	for rows.Next() {
+		var schema models.MetadataSchema
+		if err := rows.Scan(&schema.EntityType, &schema.Schema, &schema.UpdatedBy, &schema.UpdatedAt); err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, rows.Err()
+}
+
+// Upsert creates or replaces the schema for an entity type
+func (r *MetadataSchemaRepository) Upsert(ctx context.Context, schema *models.MetadataSchema) error {
+	query := `
+		INSERT INTO metadata_schemas (entity_type, schema, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (entity_type) DO UPDATE
+		SET schema = EXCLUDED.schema, updated_by = EXCLUDED.updated_by, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`
+	return r.db.QueryRow(ctx, query, schema.EntityType, schema.Schema, schema.UpdatedBy).Scan(&schema.UpdatedAt)
+}
+
+// Delete removes the schema for an entity type, reporting whether one existed
+func (r *MetadataSchemaRepository) Delete(ctx context.Context, entityType models.MetadataEntityType) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM metadata_schemas WHERE entity_type = $1`, entityType)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
@@ -0,0 +1,247 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+var ErrAlreadyReported = errors.New("content was already reported by this user")
+
+type ModerationRepository struct {
+	db database.DB
+}
+
+func NewModerationRepository(db database.DB) *ModerationRepository {
+	return &ModerationRepository{db: db}
+}
+
+// contentTables maps reportable content to its table; both have user content and hidden_at
+var contentTables = map[models.ModerationContentType]string{
+	models.ModerationMessage: "submission_messages",
+	models.ModerationProgram: "programs",
+}
+
+// GetContent returns the author and visibility of reportable content, or nil if there is none
+func (r *ModerationRepository) GetContent(ctx context.Context, contentType models.ModerationContentType, id uuid.UUID) (*models.ReportedContent, error) {
+	var query string
+	switch contentType {
+	case models.ModerationMessage:
+		query = `SELECT user_id, hidden_at IS NOT NULL, submission_id, false FROM submission_messages WHERE id = $1`
+	case models.ModerationProgram:
+		query = `SELECT owned_by, hidden_at IS NOT NULL, NULL::uuid, is_public FROM programs WHERE id = $1 AND deleted_at IS NULL`
+	default:
+		return nil, fmt.Errorf("unknown content type %q", contentType)
+	}
+
+	var content models.ReportedContent
+	err := r.db.QueryRow(ctx, query, id).Scan(&content.AuthorID, &content.Hidden, &content.SubmissionID, &content.Public)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reported content: %w", err)
+	}
+	return &content, nil
+}
+
+// Report adds the report to the content's pending case, opening one if needed, and hides the
+// content once the case reaches autoHideAt reports (0 never hides). Returns ErrAlreadyReported
+// if the reporter already reported the content in this case, and whether the content was hidden.
+func (r *ModerationRepository) Report(ctx context.Context, contentType models.ModerationContentType, contentID uuid.UUID, report *models.ModerationReport, autoHideAt int) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO moderation_cases (content_type, content_id)
+		VALUES ($1, $2)
+		ON CONFLICT (content_type, content_id) WHERE status = 'pending'
+		DO UPDATE SET report_count = moderation_cases.report_count
+		RETURNING id
+	`, contentType, contentID).Scan(&report.CaseID)
+	if err != nil {
+		return false, fmt.Errorf("failed to open moderation case: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO moderation_reports (case_id, reporter_id, reason, details)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (case_id, reporter_id) DO NOTHING
+		RETURNING id, created_at
+	`, report.CaseID, report.ReporterID, report.Reason, report.Details).Scan(&report.ID, &report.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, ErrAlreadyReported
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to save report: %w", err)
+	}
+
+	var hide bool
+	err = tx.QueryRow(ctx, `
+		UPDATE moderation_cases
+		SET report_count = report_count + 1,
+		    auto_hidden = auto_hidden OR ($2 > 0 AND report_count + 1 >= $2)
+		WHERE id = $1
+		RETURNING auto_hidden
+	`, report.CaseID, autoHideAt).Scan(&hide)
+	if err != nil {
+		return false, fmt.Errorf("failed to count report: %w", err)
+	}
+	if hide {
+		if err := setHidden(ctx, tx, contentType, contentID, true); err != nil {
+			return false, err
+		}
+	}
+
+	return hide, tx.Commit(ctx)
+}
+
+// Flag opens a pending case for content the content filter matched, or sets the matched terms
+// on the open one
+func (r *ModerationRepository) Flag(ctx context.Context, contentType models.ModerationContentType, contentID uuid.UUID, terms []string) error {
+	if terms == nil {
+		terms = []string{} // NULL means the filter didn't flag the content
+	}
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO moderation_cases (content_type, content_id, flagged_terms)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (content_type, content_id) WHERE status = 'pending'
+		DO UPDATE SET flagged_terms = EXCLUDED.flagged_terms
+	`, contentType, contentID, terms)
+	return err
+}
+
+// caseSelect reads cases with a preview of the reported content as it is now
+const caseSelect = `
+	SELECT
+		c.id, c.content_type, c.content_id, c.status, c.report_count, c.auto_hidden, c.flagged_terms,
+		COALESCE(sm.content, p.name, '') AS excerpt,
+		COALESCE(sm.user_id, p.owned_by) AS author_id,
+		a.full_name AS author_name,
+		COALESCE(sm.hidden_at, p.hidden_at) IS NOT NULL AS content_hidden,
+		c.resolved_by, c.resolved_at, c.resolution_note, c.created_at, c.updated_at
+	FROM moderation_cases c
+	LEFT JOIN submission_messages sm ON c.content_type = 'message' AND sm.id = c.content_id
+	LEFT JOIN programs p ON c.content_type = 'program' AND p.id = c.content_id AND p.deleted_at IS NULL
+	LEFT JOIN users a ON a.id = COALESCE(sm.user_id, p.owned_by)`
+
+func scanCase(row pgx.Row) (*models.ModerationCase, error) {
+	var c models.ModerationCase
+	err := row.Scan(
+		&c.ID, &c.ContentType, &c.ContentID, &c.Status, &c.ReportCount, &c.AutoHidden, &c.FlaggedTerms,
+		&c.Excerpt, &c.AuthorID, &c.AuthorName, &c.ContentHidden,
+		&c.ResolvedBy, &c.ResolvedAt, &c.ResolutionNote, &c.CreatedAt, &c.UpdatedAt,
+	)
+	return &c, err
+}
+
+// List returns cases with the status (all if nil), most reported first, then oldest first
+func (r *ModerationRepository) List(ctx context.Context, status *models.ModerationStatus, limit, offset int) ([]models.ModerationCase, error) {
+	query := caseSelect + `
+		WHERE ($1::varchar IS NULL OR c.status = $1)
+		ORDER BY c.report_count DESC, c.created_at ASC
+		LIMIT $2 OFFSET $3
+	`
+	cases, err := collectWithRetry(ctx, r.db, "moderation.List", query, func(row pgx.CollectableRow) (models.ModerationCase, error) {
+		c, err := scanCase(row)
+		if err != nil {
+			return models.ModerationCase{}, err
+		}
+		return *c, nil
+	}, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list moderation cases: %w", err)
+	}
+	return cases, nil
+}
+
+// GetByID returns a case with its reports, or nil if there is none
+func (r *ModerationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ModerationCase, error) {
+	c, err := scanCase(r.db.QueryRow(ctx, caseSelect+` WHERE c.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation case: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT mr.id, mr.case_id, mr.reporter_id, u.full_name, mr.reason, mr.details, mr.created_at
+		FROM moderation_reports mr
+		JOIN users u ON u.id = mr.reporter_id
+		WHERE mr.case_id = $1
+		ORDER BY mr.created_at
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moderation reports: %w", err)
+	}
+	defer rows.Close()
+
+	c.Reports = make([]models.ModerationReport, 0)
+	for rows.Next() {
+		var report models.ModerationReport
+		if err := rows.Scan(&report.ID, &report.CaseID, &report.ReporterID, &report.ReporterName, &report.Reason, &report.Details, &report.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan moderation report: %w", err)
+		}
+		c.Reports = append(c.Reports, report)
+	}
+	return c, rows.Err()
+}
+
+// Decide moves a case in one of the from statuses to status and hides or shows its content to
+// match. Returns false if the case isn't in one of the from statuses.
+func (r *ModerationRepository) Decide(ctx context.Context, id uuid.UUID, from []models.ModerationStatus, status models.ModerationStatus, adminID uuid.UUID, note *string) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	fromStatuses := make([]string, len(from))
+	for i, s := range from {
+		fromStatuses[i] = string(s)
+	}
+
+	var contentType models.ModerationContentType
+	var contentID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE moderation_cases
+		SET status = $2, resolved_by = $3, resolved_at = CURRENT_TIMESTAMP, resolution_note = $4
+		WHERE id = $1 AND status = ANY($5)
+		RETURNING content_type, content_id
+	`, id, status, adminID, note, fromStatuses).Scan(&contentType, &contentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update moderation case: %w", err)
+	}
+
+	if err := setHidden(ctx, tx, contentType, contentID, status == models.ModerationHidden); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+func setHidden(ctx context.Context, tx pgx.Tx, contentType models.ModerationContentType, id uuid.UUID, hidden bool) error {
+	table, ok := contentTables[contentType]
+	if !ok {
+		return fmt.Errorf("unknown content type %q", contentType)
+	}
+	query := `UPDATE ` + table + ` SET hidden_at = NULL WHERE id = $1`
+	if hidden {
+		query = `UPDATE ` + table + ` SET hidden_at = CURRENT_TIMESTAMP WHERE id = $1 AND hidden_at IS NULL`
+	}
+	if _, err := tx.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to update %s visibility: %w", contentType, err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type NotificationRepository struct {
+	db database.DB
+}
+
+func NewNotificationRepository(db database.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	query := `
+		INSERT INTO notifications (user_id, type, title, body, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(ctx, query,
+		notification.UserID,
+		notification.Type,
+		notification.Title,
+		notification.Body,
+		notification.Payload,
+	).Scan(&notification.ID, &notification.CreatedAt)
+}
+
+// ListByUser retrieves a user's notifications, newest first
+func (r *NotificationRepository) ListByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, error) {
+	query := `
+		SELECT id, user_id, type, title, body, payload, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1
+		AND ($2 = false OR read_at IS NULL)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := make([]models.Notification, 0)
+	for rows.Next() {
+		var n models.Notification
+		err := rows.Scan(
+			&n.ID,
+			&n.UserID,
+			&n.Type,
+			&n.Title,
+			&n.Body,
+			&n.Payload,
+			&n.ReadAt,
+			&n.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// MarkAsRead marks a notification as read, scoped to its recipient
+func (r *NotificationRepository) MarkAsRead(ctx context.Context, id, userID uuid.UUID) error {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		WHERE id = $1 AND user_id = $2
+	`
+	result, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// DigestRecipient is an active user who wants the weekly digest
+type DigestRecipient struct {
+	UserID       uuid.UUID
+	Email        string
+	FullName     string
+	Language     string
+	Timezone     string
+	LastDigestAt *time.Time
+}
+
+// GetPreferences returns a user's notification preferences, or the defaults if never saved
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	query := `
+		SELECT weekly_digest, timezone, last_digest_at
+		FROM notification_preferences
+		WHERE user_id = $1
+	`
+	prefs := models.NotificationPreferences{WeeklyDigest: true, Timezone: "UTC"}
+	err := database.Retry(ctx, "notifications.GetPreferences", func() error {
+		return r.db.QueryRow(ctx, query, userID).Scan(&prefs.WeeklyDigest, &prefs.Timezone, &prefs.LastDigestAt)
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// SavePreferences stores a user's notification preferences
+func (r *NotificationRepository) SavePreferences(ctx context.Context, userID uuid.UUID, prefs *models.NotificationPreferences) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, weekly_digest, timezone)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET weekly_digest = EXCLUDED.weekly_digest, timezone = EXCLUDED.timezone
+	`
+	_, err := r.db.Exec(ctx, query, userID, prefs.WeeklyDigest, prefs.Timezone)
+	return err
+}
+
+// ListDigestRecipients returns the active users who have not turned the weekly digest off
+func (r *NotificationRepository) ListDigestRecipients(ctx context.Context) ([]DigestRecipient, error) {
+	query := `
+		SELECT u.id, u.email, u.full_name, u.language,
+		       COALESCE(np.timezone, 'UTC'), np.last_digest_at
+		FROM users u
+		LEFT JOIN notification_preferences np ON np.user_id = u.id
+		WHERE u.is_active = true AND COALESCE(np.weekly_digest, true)
+		ORDER BY u.id
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipients []DigestRecipient
+	for rows.Next() {
+		var d DigestRecipient
+		if err := rows.Scan(&d.UserID, &d.Email, &d.FullName, &d.Language, &d.Timezone, &d.LastDigestAt); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, d)
+	}
+	return recipients, rows.Err()
+}
+
+// ClaimDigest records that the user's digest for the send window starting at windowStart is
+// being sent. It returns false if it was already claimed, so even with several API instances
+// a user gets at most one digest per window.
+func (r *NotificationRepository) ClaimDigest(ctx context.Context, userID uuid.UUID, windowStart, now time.Time) (bool, error) {
+	query := `
+		INSERT INTO notification_preferences (user_id, last_digest_at)
+		VALUES ($1, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET last_digest_at = EXCLUDED.last_digest_at
+		WHERE notification_preferences.last_digest_at IS NULL OR notification_preferences.last_digest_at < $2
+		RETURNING user_id
+	`
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, query, userID, windowStart, now).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -2,25 +2,34 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/xuangong/backend/internal/database"
 	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/clock"
 )
 
 type ProgramRepository struct {
-	db *pgxpool.Pool
+	db    database.DB
+	clock clock.Clock
 }
 
-func NewProgramRepository(db *pgxpool.Pool) *ProgramRepository {
-	return &ProgramRepository{db: db}
+func NewProgramRepository(db database.DB) *ProgramRepository {
+	return &ProgramRepository{db: db, clock: clock.System}
+}
+
+// WithClock replaces the clock used for timestamps, so tests can control time
+func (r *ProgramRepository) WithClock(c clock.Clock) *ProgramRepository {
+	r.clock = c
+	return r
 }
 
 func (r *ProgramRepository) Create(ctx context.Context, program *models.Program) error {
 	query := `
-		INSERT INTO programs (name, description, owned_by, is_template, is_public, tags, metadata, repetitions_planned)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO programs (name, description, owned_by, is_template, is_public, tags, metadata, repetitions_planned, publish_at, unpublish_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at, updated_at
 	`
 	return r.db.QueryRow(ctx, query,
@@ -32,31 +41,39 @@ func (r *ProgramRepository) Create(ctx context.Context, program *models.Program)
 		program.Tags,
 		program.Metadata,
 		program.RepetitionsPlanned,
+		program.PublishAt,
+		program.UnpublishAt,
 	).Scan(&program.ID, &program.CreatedAt, &program.UpdatedAt)
 }
 
 func (r *ProgramRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Program, error) {
 	var program models.Program
 	query := `
-		SELECT id, name, description, owned_by, is_template, is_public, repetitions_planned, repetitions_completed, tags, metadata, created_at, updated_at, deleted_at
+		SELECT id, name, description, owned_by, is_template, is_public, repetitions_planned, repetitions_completed, tags, metadata, publish_at, unpublish_at, cover_image_key, hidden_at, created_at, updated_at, deleted_at
 		FROM programs
 		WHERE id = $1 AND deleted_at IS NULL
 	`
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&program.ID,
-		&program.Name,
-		&program.Description,
-		&program.OwnedBy,
-		&program.IsTemplate,
-		&program.IsPublic,
-		&program.RepetitionsPlanned,
-		&program.RepetitionsCompleted,
-		&program.Tags,
-		&program.Metadata,
-		&program.CreatedAt,
-		&program.UpdatedAt,
-		&program.DeletedAt,
-	)
+	err := database.Retry(ctx, "programs.GetByID", func() error {
+		return r.db.QueryRow(ctx, query, id).Scan(
+			&program.ID,
+			&program.Name,
+			&program.Description,
+			&program.OwnedBy,
+			&program.IsTemplate,
+			&program.IsPublic,
+			&program.RepetitionsPlanned,
+			&program.RepetitionsCompleted,
+			&program.Tags,
+			&program.Metadata,
+			&program.PublishAt,
+			&program.UnpublishAt,
+			&program.CoverImageKey,
+			&program.HiddenAt,
+			&program.CreatedAt,
+			&program.UpdatedAt,
+			&program.DeletedAt,
+		)
+	})
 	if err == pgx.ErrNoRows {
 		return nil, nil
 	}
@@ -70,7 +87,7 @@ func (r *ProgramRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.
 func (r *ProgramRepository) GetByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*models.Program, error) {
 	var program models.Program
 	query := `
-		SELECT id, name, description, owned_by, is_template, is_public, repetitions_planned, repetitions_completed, tags, metadata, created_at, updated_at, deleted_at
+		SELECT id, name, description, owned_by, is_template, is_public, repetitions_planned, repetitions_completed, tags, metadata, publish_at, unpublish_at, cover_image_key, hidden_at, created_at, updated_at, deleted_at
 		FROM programs
 		WHERE id = $1
 	`
@@ -85,6 +102,10 @@ func (r *ProgramRepository) GetByIDIncludingDeleted(ctx context.Context, id uuid
 		&program.RepetitionsCompleted,
 		&program.Tags,
 		&program.Metadata,
+		&program.PublishAt,
+		&program.UnpublishAt,
+		&program.CoverImageKey,
+		&program.HiddenAt,
 		&program.CreatedAt,
 		&program.UpdatedAt,
 		&program.DeletedAt,
@@ -101,25 +122,19 @@ func (r *ProgramRepository) GetByIDIncludingDeleted(ctx context.Context, id uuid
 func (r *ProgramRepository) List(ctx context.Context, isTemplate, isPublic *bool, limit, offset int) ([]models.Program, error) {
 	query := `
 		SELECT p.id, p.name, p.description, p.owned_by, u.full_name as creator_name,
-		       p.is_template, p.is_public, p.repetitions_planned, p.repetitions_completed, p.tags, p.metadata, p.created_at, p.updated_at
+		       p.is_template, p.is_public, p.repetitions_planned, p.repetitions_completed, p.tags, p.metadata, p.publish_at, p.unpublish_at, p.cover_image_key, p.created_at, p.updated_at
 		FROM programs p
 		LEFT JOIN users u ON p.owned_by = u.id
 		WHERE ($1::boolean IS NULL OR p.is_template = $1)
 		AND ($2::boolean IS NULL OR p.is_public = $2)
 		AND p.deleted_at IS NULL
+		AND p.hidden_at IS NULL
 		ORDER BY p.created_at DESC
 		LIMIT $3 OFFSET $4
 	`
-	rows, err := r.db.Query(ctx, query, isTemplate, isPublic, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	programs := make([]models.Program, 0)
-	for rows.Next() {
+	return collectWithRetry(ctx, r.db, "programs.List", query, func(row pgx.CollectableRow) (models.Program, error) {
 		var program models.Program
-		err := rows.Scan(
+		err := row.Scan(
 			&program.ID,
 			&program.Name,
 			&program.Description,
@@ -131,22 +146,20 @@ func (r *ProgramRepository) List(ctx context.Context, isTemplate, isPublic *bool
 			&program.RepetitionsCompleted,
 			&program.Tags,
 			&program.Metadata,
+			&program.PublishAt,
+			&program.UnpublishAt,
+			&program.CoverImageKey,
 			&program.CreatedAt,
 			&program.UpdatedAt,
 		)
-		if err != nil {
-			return nil, err
-		}
-		programs = append(programs, program)
-	}
-
-	return programs, rows.Err()
+		return program, err
+	}, isTemplate, isPublic, limit, offset)
 }
 
 // GetByOwner retrieves all programs owned by a specific user (excluding soft-deleted)
 func (r *ProgramRepository) GetByOwner(ctx context.Context, ownerID uuid.UUID) ([]models.Program, error) {
 	query := `
-		SELECT id, name, description, owned_by, is_template, is_public, repetitions_planned, repetitions_completed, tags, metadata, created_at, updated_at
+		SELECT id, name, description, owned_by, is_template, is_public, repetitions_planned, repetitions_completed, tags, metadata, publish_at, unpublish_at, cover_image_key, created_at, updated_at
 		FROM programs
 		WHERE owned_by = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -171,6 +184,9 @@ func (r *ProgramRepository) GetByOwner(ctx context.Context, ownerID uuid.UUID) (
 			&program.RepetitionsCompleted,
 			&program.Tags,
 			&program.Metadata,
+			&program.PublishAt,
+			&program.UnpublishAt,
+			&program.CoverImageKey,
 			&program.CreatedAt,
 			&program.UpdatedAt,
 		)
@@ -186,8 +202,9 @@ func (r *ProgramRepository) GetByOwner(ctx context.Context, ownerID uuid.UUID) (
 func (r *ProgramRepository) Update(ctx context.Context, program *models.Program) error {
 	query := `
 		UPDATE programs
-		SET name = $1, description = $2, is_template = $3, is_public = $4, tags = $5, metadata = $6, repetitions_planned = $7
-		WHERE id = $8
+		SET name = $1, description = $2, is_template = $3, is_public = $4, tags = $5, metadata = $6, repetitions_planned = $7,
+		    publish_at = $8, unpublish_at = $9
+		WHERE id = $10
 		RETURNING updated_at
 	`
 	return r.db.QueryRow(ctx, query,
@@ -198,10 +215,46 @@ func (r *ProgramRepository) Update(ctx context.Context, program *models.Program)
 		program.Tags,
 		program.Metadata,
 		program.RepetitionsPlanned,
+		program.PublishAt,
+		program.UnpublishAt,
 		program.ID,
 	).Scan(&program.UpdatedAt)
 }
 
+// ApplyPublishSchedule publishes programs whose publish_at has passed and unpublishes those
+// whose unpublish_at has passed. Applied timestamps are cleared so manual changes afterwards stick.
+func (r *ProgramRepository) ApplyPublishSchedule(ctx context.Context, now time.Time) (published, unpublished int64, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE programs
+		SET is_public = true, publish_at = NULL
+		WHERE publish_at IS NOT NULL AND publish_at <= $1 AND deleted_at IS NULL
+	`, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	published = result.RowsAffected()
+
+	result, err = tx.Exec(ctx, `
+		UPDATE programs
+		SET is_public = false, unpublish_at = NULL
+		WHERE unpublish_at IS NOT NULL AND unpublish_at <= $1 AND deleted_at IS NULL
+	`, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	unpublished = result.RowsAffected()
+
+	return published, unpublished, tx.Commit(ctx)
+}
+
+// Delete removes a program and everything referencing it. Programs are soft deleted with
+// SoftDelete; this is for purging them.
 func (r *ProgramRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM programs WHERE id = $1`
 	_, err := r.db.Exec(ctx, query, id)
@@ -226,21 +279,80 @@ func (r *ProgramRepository) AssignToUser(ctx context.Context, userProgram *model
 
 func (r *ProgramRepository) GetUserPrograms(ctx context.Context, userID uuid.UUID, activeOnly bool) ([]models.UserProgram, error) {
 	query := `
-		SELECT id, user_id, program_id, assigned_by, assigned_at, is_active, custom_settings
-		FROM user_programs
-		WHERE user_id = $1 AND ($2 = false OR is_active = true)
-		ORDER BY assigned_at DESC
+		SELECT up.id, up.user_id, up.program_id, up.assigned_by, up.assigned_at, up.is_active, up.custom_settings
+		FROM user_programs up
+		JOIN programs p ON p.id = up.program_id
+		WHERE up.user_id = $1 AND ($2 = false OR up.is_active = true)
+		  AND p.deleted_at IS NULL
+		ORDER BY up.assigned_at DESC
 	`
-	rows, err := r.db.Query(ctx, query, userID, activeOnly)
+	return collectWithRetry(ctx, r.db, "programs.GetUserPrograms", query, func(row pgx.CollectableRow) (models.UserProgram, error) {
+		var up models.UserProgram
+		err := row.Scan(
+			&up.ID,
+			&up.UserID,
+			&up.ProgramID,
+			&up.AssignedBy,
+			&up.AssignedAt,
+			&up.IsActive,
+			&up.CustomSettings,
+		)
+		return up, err
+	}, userID, activeOnly)
+}
+
+// ListAssignedUserIDs returns the active users with an active assignment of the program
+func (r *ProgramRepository) ListAssignedUserIDs(ctx context.Context, programID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT up.user_id
+		FROM user_programs up
+		JOIN users u ON u.id = up.user_id
+		WHERE up.program_id = $1 AND up.is_active = true AND u.is_active = true
+		ORDER BY up.assigned_at
+	`
+	rows, err := r.db.Query(ctx, query, programID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	userPrograms := make([]models.UserProgram, 0)
+	var userIDs []uuid.UUID
 	for rows.Next() {
-		var up models.UserProgram
-		err := rows.Scan(
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
+
+// CanAccess reports whether the user owns the program or has it actively assigned
+func (r *ProgramRepository) CanAccess(ctx context.Context, userID, programID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM programs WHERE id = $2 AND owned_by = $1
+			UNION ALL
+			SELECT 1 FROM user_programs WHERE user_id = $1 AND program_id = $2 AND is_active = true
+		)
+	`
+	var ok bool
+	err := database.Retry(ctx, "programs.CanAccess", func() error {
+		return r.db.QueryRow(ctx, query, userID, programID).Scan(&ok)
+	})
+	return ok, err
+}
+
+// GetUserProgram retrieves a single assignment of a program to a user
+func (r *ProgramRepository) GetUserProgram(ctx context.Context, userID, programID uuid.UUID) (*models.UserProgram, error) {
+	var up models.UserProgram
+	query := `
+		SELECT id, user_id, program_id, assigned_by, assigned_at, is_active, custom_settings
+		FROM user_programs
+		WHERE user_id = $1 AND program_id = $2
+	`
+	err := database.Retry(ctx, "programs.GetUserProgram", func() error {
+		return r.db.QueryRow(ctx, query, userID, programID).Scan(
 			&up.ID,
 			&up.UserID,
 			&up.ProgramID,
@@ -249,13 +361,14 @@ func (r *ProgramRepository) GetUserPrograms(ctx context.Context, userID uuid.UUI
 			&up.IsActive,
 			&up.CustomSettings,
 		)
-		if err != nil {
-			return nil, err
-		}
-		userPrograms = append(userPrograms, up)
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
 	}
-
-	return userPrograms, rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	return &up, nil
 }
 
 func (r *ProgramRepository) UpdateUserProgramSettings(ctx context.Context, userID, programID uuid.UUID, customSettings map[string]interface{}) error {
@@ -271,7 +384,7 @@ func (r *ProgramRepository) UpdateUserProgramSettings(ctx context.Context, userI
 func (r *ProgramRepository) GetUserProgramsWithDetails(ctx context.Context, userID uuid.UUID, activeOnly bool) ([]models.Program, error) {
 	query := `
 		SELECT DISTINCT p.id, p.name, p.description, p.owned_by, u.full_name as creator_name,
-		       p.is_template, p.is_public, p.repetitions_planned, p.repetitions_completed, p.tags, p.metadata, p.created_at, p.updated_at
+		       p.is_template, p.is_public, p.repetitions_planned, p.repetitions_completed, p.tags, p.metadata, p.publish_at, p.unpublish_at, p.cover_image_key, p.created_at, p.updated_at
 		FROM programs p
 		LEFT JOIN user_programs up ON p.id = up.program_id AND up.user_id = $1
 		LEFT JOIN users u ON p.owned_by = u.id
@@ -279,18 +392,12 @@ func (r *ProgramRepository) GetUserProgramsWithDetails(ctx context.Context, user
 		   OR (p.owned_by = $1))
 		   AND p.is_template = false
 		   AND p.deleted_at IS NULL
+		   AND (p.hidden_at IS NULL OR p.owned_by = $1)
 		ORDER BY p.created_at DESC
 	`
-	rows, err := r.db.Query(ctx, query, userID, activeOnly)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	programs := make([]models.Program, 0)
-	for rows.Next() {
+	return collectWithRetry(ctx, r.db, "programs.GetUserProgramsWithDetails", query, func(row pgx.CollectableRow) (models.Program, error) {
 		var program models.Program
-		err := rows.Scan(
+		err := row.Scan(
 			&program.ID,
 			&program.Name,
 			&program.Description,
@@ -302,16 +409,14 @@ func (r *ProgramRepository) GetUserProgramsWithDetails(ctx context.Context, user
 			&program.RepetitionsCompleted,
 			&program.Tags,
 			&program.Metadata,
+			&program.PublishAt,
+			&program.UnpublishAt,
+			&program.CoverImageKey,
 			&program.CreatedAt,
 			&program.UpdatedAt,
 		)
-		if err != nil {
-			return nil, err
-		}
-		programs = append(programs, program)
-	}
-
-	return programs, rows.Err()
+		return program, err
+	}, userID, activeOnly)
 }
 
 // SoftDelete marks a program as deleted by setting the deleted_at timestamp
@@ -333,10 +438,10 @@ func (r *ProgramRepository) SoftDelete(ctx context.Context, id uuid.UUID) error
 	// Perform soft delete
 	query := `
 		UPDATE programs
-		SET deleted_at = CURRENT_TIMESTAMP
+		SET deleted_at = $2
 		WHERE id = $1 AND deleted_at IS NULL
 	`
-	result, err := r.db.Exec(ctx, query, id)
+	result, err := r.db.Exec(ctx, query, id, r.clock.Now())
 	if err != nil {
 		return err
 	}
@@ -349,6 +454,139 @@ func (r *ProgramRepository) SoftDelete(ctx context.Context, id uuid.UUID) error
 	return nil
 }
 
+// SetCoverImage sets or clears the storage key prefix of a program's cover image
+func (r *ProgramRepository) SetCoverImage(ctx context.Context, id uuid.UUID, key *string, sizeBytes *int64) error {
+	query := `UPDATE programs SET cover_image_key = $1, cover_size_bytes = $2 WHERE id = $3 AND deleted_at IS NULL`
+	_, err := r.db.Exec(ctx, query, key, sizeBytes, id)
+	return err
+}
+
+// SetFingerprint stores the duplicate detection keys for a program
+func (r *ProgramRepository) SetFingerprint(ctx context.Context, id uuid.UUID, nameKey, exerciseFingerprint string) error {
+	query := `
+		UPDATE programs
+		SET name_key = $1, exercise_fingerprint = NULLIF($2, ''), fingerprinted_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`
+	_, err := r.db.Exec(ctx, query, nameKey, exerciseFingerprint, id)
+	return err
+}
+
+// ReconcileRepetitionsCompleted recomputes every program's completed count from its sessions and
+// returns how many programs were checked and the ones whose stored count had drifted
+func (r *ProgramRepository) ReconcileRepetitionsCompleted(ctx context.Context) (int, []models.RepetitionDrift, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var checked int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM programs`).Scan(&checked); err != nil {
+		return 0, nil, err
+	}
+
+	query := `
+		WITH actual AS (
+			SELECT p.id, p.repetitions_completed AS stored, COUNT(s.id)::int AS actual
+			FROM programs p
+			LEFT JOIN practice_sessions s
+			       ON s.program_id = p.id AND s.completed_at IS NOT NULL AND s.deleted_at IS NULL
+			GROUP BY p.id
+		)
+		UPDATE programs p
+		SET repetitions_completed = a.actual
+		FROM actual a
+		WHERE p.id = a.id AND p.repetitions_completed IS DISTINCT FROM a.actual
+		RETURNING p.id, p.name, a.stored, a.actual
+	`
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return 0, nil, err
+	}
+	drifted := make([]models.RepetitionDrift, 0)
+	for rows.Next() {
+		var d models.RepetitionDrift
+		if err := rows.Scan(&d.ProgramID, &d.ProgramName, &d.Stored, &d.Actual); err != nil {
+			rows.Close()
+			return 0, nil, err
+		}
+		drifted = append(drifted, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	return checked, drifted, tx.Commit(ctx)
+}
+
+// InvalidateFingerprint marks a program's fingerprint as stale so the backfill job recomputes it
+func (r *ProgramRepository) InvalidateFingerprint(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE programs SET fingerprinted_at = NULL WHERE id = $1`
+	_, err := r.db.Exec(ctx, query, id)
+	return err
+}
+
+// ListStaleFingerprints returns IDs of programs whose fingerprint is missing or outdated
+func (r *ProgramRepository) ListStaleFingerprints(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM programs
+		WHERE fingerprinted_at IS NULL AND deleted_at IS NULL
+		ORDER BY created_at ASC
+		LIMIT $1
+	`
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// FindSimilar returns template or public programs sharing the name key or exercise fingerprint.
+// Exact duplicates (both match) come first, oldest first.
+func (r *ProgramRepository) FindSimilar(ctx context.Context, nameKey, exerciseFingerprint string, excludeID uuid.UUID, limit int) ([]models.SimilarProgram, error) {
+	query := `
+		SELECT id, name, owned_by, is_template, is_public,
+		       COALESCE(name_key = $1, false) AS same_name,
+		       COALESCE(exercise_fingerprint = NULLIF($2, ''), false) AS same_exercises
+		FROM programs
+		WHERE deleted_at IS NULL
+		  AND (is_template = true OR is_public = true)
+		  AND id <> $3
+		  AND (name_key = $1 OR exercise_fingerprint = NULLIF($2, ''))
+		ORDER BY (COALESCE(name_key = $1, false) AND COALESCE(exercise_fingerprint = NULLIF($2, ''), false)) DESC, created_at ASC
+		LIMIT $4
+	`
+	rows, err := r.db.Query(ctx, query, nameKey, exerciseFingerprint, excludeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	similar := make([]models.SimilarProgram, 0)
+	for rows.Next() {
+		var p models.SimilarProgram
+		if err := rows.Scan(&p.ID, &p.Name, &p.OwnedBy, &p.IsTemplate, &p.IsPublic, &p.SameName, &p.SameExercises); err != nil {
+			return nil, err
+		}
+		similar = append(similar, p)
+	}
+
+	return similar, rows.Err()
+}
+
 // UpdateRepetitionsCompleted updates the repetitions_completed count for a program
 // by counting the number of completed sessions for that program
 func (r *ProgramRepository) UpdateRepetitionsCompleted(ctx context.Context, programID uuid.UUID) error {
@@ -357,7 +595,7 @@ func (r *ProgramRepository) UpdateRepetitionsCompleted(ctx context.Context, prog
 		SET repetitions_completed = (
 			SELECT COUNT(*)
 			FROM practice_sessions
-			WHERE program_id = $1 AND completed_at IS NOT NULL
+			WHERE program_id = $1 AND completed_at IS NOT NULL AND deleted_at IS NULL
 		)
 		WHERE id = $1
 	`
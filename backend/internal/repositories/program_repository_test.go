@@ -6,16 +6,16 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/xuangong/backend/pkg/clock"
 	"github.com/xuangong/backend/pkg/testutil"
 )
 
 var _ = time.Now // prevent unused import error
 
 func TestProgramRepository_SoftDelete(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewProgramRepository(pool)
+	repo := NewProgramRepository(db)
 	ctx := context.Background()
 
 	tests := []struct {
@@ -26,8 +26,8 @@ func TestProgramRepository_SoftDelete(t *testing.T) {
 		{
 			name: "soft_delete_existing_program",
 			setup: func() uuid.UUID {
-				admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-				program := testutil.CreateTestProgram(t, pool, admin.ID, "Test Program")
+				admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+				program := testutil.NewProgramBuilder().WithName("Test Program").OwnedBy(admin).Create(t, db)
 				return program.ID
 			},
 			wantErr: false,
@@ -42,8 +42,8 @@ func TestProgramRepository_SoftDelete(t *testing.T) {
 		{
 			name: "soft_delete_already_deleted_program",
 			setup: func() uuid.UUID {
-				admin := testutil.CreateTestAdmin(t, pool, "admin2@test.com")
-				program := testutil.CreateTestProgram(t, pool, admin.ID, "Deleted Program")
+				admin := testutil.NewUserBuilder().WithEmail("admin2@test.com").AsAdmin().Create(t, db)
+				program := testutil.NewProgramBuilder().WithName("Deleted Program").OwnedBy(admin).Create(t, db)
 				// Soft delete it first
 				err := repo.SoftDelete(ctx, program.ID)
 				if err != nil {
@@ -84,14 +84,13 @@ func TestProgramRepository_SoftDelete(t *testing.T) {
 }
 
 func TestProgramRepository_GetByID_ExcludesDeleted(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewProgramRepository(pool)
+	repo := NewProgramRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Active Program")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Active Program").OwnedBy(admin).Create(t, db)
 
 	// Soft delete the program
 	err := repo.SoftDelete(ctx, program.ID)
@@ -110,10 +109,9 @@ func TestProgramRepository_GetByID_ExcludesDeleted(t *testing.T) {
 }
 
 func TestProgramRepository_GetByIDIncludingDeleted(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewProgramRepository(pool)
+	repo := NewProgramRepository(db)
 	ctx := context.Background()
 
 	tests := []struct {
@@ -124,8 +122,8 @@ func TestProgramRepository_GetByIDIncludingDeleted(t *testing.T) {
 		{
 			name: "find_active_program",
 			setup: func() uuid.UUID {
-				admin := testutil.CreateTestAdmin(t, pool, "admin1@test.com")
-				program := testutil.CreateTestProgram(t, pool, admin.ID, "Active")
+				admin := testutil.NewUserBuilder().WithEmail("admin1@test.com").AsAdmin().Create(t, db)
+				program := testutil.NewProgramBuilder().WithName("Active").OwnedBy(admin).Create(t, db)
 				return program.ID
 			},
 			wantFound: true,
@@ -133,8 +131,8 @@ func TestProgramRepository_GetByIDIncludingDeleted(t *testing.T) {
 		{
 			name: "find_deleted_program",
 			setup: func() uuid.UUID {
-				admin := testutil.CreateTestAdmin(t, pool, "admin2@test.com")
-				program := testutil.CreateTestProgram(t, pool, admin.ID, "Deleted")
+				admin := testutil.NewUserBuilder().WithEmail("admin2@test.com").AsAdmin().Create(t, db)
+				program := testutil.NewProgramBuilder().WithName("Deleted").OwnedBy(admin).Create(t, db)
 				repo.SoftDelete(ctx, program.ID)
 				return program.ID
 			},
@@ -169,18 +167,17 @@ func TestProgramRepository_GetByIDIncludingDeleted(t *testing.T) {
 }
 
 func TestProgramRepository_List_ExcludesDeleted(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewProgramRepository(pool)
+	repo := NewProgramRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
 
 	// Create 3 programs: 2 active, 1 deleted
-	program1 := testutil.CreateTestProgram(t, pool, admin.ID, "Active 1")
-	program2 := testutil.CreateTestProgram(t, pool, admin.ID, "Active 2")
-	program3 := testutil.CreateTestProgram(t, pool, admin.ID, "Deleted")
+	program1 := testutil.NewProgramBuilder().WithName("Active 1").OwnedBy(admin).Create(t, db)
+	program2 := testutil.NewProgramBuilder().WithName("Active 2").OwnedBy(admin).Create(t, db)
+	program3 := testutil.NewProgramBuilder().WithName("Deleted").OwnedBy(admin).Create(t, db)
 
 	// Soft delete program3
 	err := repo.SoftDelete(ctx, program3.ID)
@@ -221,17 +218,16 @@ func TestProgramRepository_List_ExcludesDeleted(t *testing.T) {
 }
 
 func TestProgramRepository_GetByOwner_ExcludesDeleted(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewProgramRepository(pool)
+	repo := NewProgramRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
 
 	// Create 2 programs for this admin
-	program1 := testutil.CreateTestProgram(t, pool, admin.ID, "Owner Program 1")
-	program2 := testutil.CreateTestProgram(t, pool, admin.ID, "Owner Program 2")
+	program1 := testutil.NewProgramBuilder().WithName("Owner Program 1").OwnedBy(admin).Create(t, db)
+	program2 := testutil.NewProgramBuilder().WithName("Owner Program 2").OwnedBy(admin).Create(t, db)
 
 	// Soft delete one
 	err := repo.SoftDelete(ctx, program2.ID)
@@ -255,21 +251,20 @@ func TestProgramRepository_GetByOwner_ExcludesDeleted(t *testing.T) {
 }
 
 func TestProgramRepository_GetUserPrograms_ExcludesDeleted(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewProgramRepository(pool)
+	repo := NewProgramRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student := testutil.CreateTestStudent(t, pool, "student@test.com")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
 
 	// Create 2 programs and assign to student
-	program1 := testutil.CreateTestProgram(t, pool, admin.ID, "Assigned 1")
-	program2 := testutil.CreateTestProgram(t, pool, admin.ID, "Assigned 2")
+	program1 := testutil.NewProgramBuilder().WithName("Assigned 1").OwnedBy(admin).Create(t, db)
+	program2 := testutil.NewProgramBuilder().WithName("Assigned 2").OwnedBy(admin).Create(t, db)
 
-	testutil.AssignProgramToUser(t, pool, student.ID, program1.ID, admin.ID)
-	testutil.AssignProgramToUser(t, pool, student.ID, program2.ID, admin.ID)
+	testutil.AssignProgramToUser(t, db, student.ID, program1.ID, admin.ID)
+	testutil.AssignProgramToUser(t, db, student.ID, program2.ID, admin.ID)
 
 	// Soft delete one program
 	err := repo.SoftDelete(ctx, program2.ID)
@@ -292,19 +287,56 @@ func TestProgramRepository_GetUserPrograms_ExcludesDeleted(t *testing.T) {
 	}
 }
 
+func TestProgramRepository_CanAccess(t *testing.T) {
+	db := testutil.SetupTestTx(t)
+
+	repo := NewProgramRepository(db)
+	ctx := context.Background()
+
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+
+	assigned := testutil.NewProgramBuilder().WithName("Assigned").OwnedBy(admin).Create(t, db)
+	unassigned := testutil.NewProgramBuilder().WithName("Unassigned").OwnedBy(admin).Create(t, db)
+	own := testutil.NewProgramBuilder().WithName("Own").OwnedBy(student).Create(t, db)
+	testutil.AssignProgramToUser(t, db, student.ID, assigned.ID, admin.ID)
+
+	tests := []struct {
+		name      string
+		programID uuid.UUID
+		want      bool
+	}{
+		{"assigned_program", assigned.ID, true},
+		{"own_program", own.ID, true},
+		{"unassigned_program", unassigned.ID, false},
+		{"non_existent_program", uuid.New(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.CanAccess(ctx, student.ID, tt.programID)
+			if err != nil {
+				t.Fatalf("CanAccess() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CanAccess() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestProgramRepository_Sessions_PreservedAfterSoftDelete(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewProgramRepository(pool)
+	repo := NewProgramRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student := testutil.CreateTestStudent(t, pool, "student@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Program with Sessions")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Program with Sessions").OwnedBy(admin).Create(t, db)
 
 	// Create a session for this program
-	session := testutil.CreateTestSession(t, pool, student.ID, program.ID)
+	session := testutil.NewSessionBuilder().ForUser(student).ForProgram(program).Create(t, db)
 
 	// Soft delete the program
 	err := repo.SoftDelete(ctx, program.ID)
@@ -313,24 +345,24 @@ func TestProgramRepository_Sessions_PreservedAfterSoftDelete(t *testing.T) {
 	}
 
 	// Verify session still exists in database
-	testutil.AssertRowCount(t, pool, "sessions", 1)
+	testutil.AssertRowCount(t, db, "sessions", 1)
 
 	// Verify we can still get session data (sessions are preserved)
-	row := testutil.QueryRow(t, pool, "SELECT id FROM sessions WHERE id = $1", session.ID)
+	row := testutil.QueryRow(t, db, "SELECT id FROM sessions WHERE id = $1", session.ID)
 	if row["id"] == nil {
 		t.Error("Session should still exist after program soft delete")
 	}
 }
 
 func TestProgramRepository_SoftDelete_Idempotent(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewProgramRepository(pool)
+	clk := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	repo := NewProgramRepository(db).WithClock(clk)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Idempotent Test")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Idempotent Test").OwnedBy(admin).Create(t, db)
 
 	// First soft delete
 	err := repo.SoftDelete(ctx, program.ID)
@@ -342,8 +374,8 @@ func TestProgramRepository_SoftDelete_Idempotent(t *testing.T) {
 	result1, _ := repo.GetByIDIncludingDeleted(ctx, program.ID)
 	firstDeletedAt := result1.DeletedAt
 
-	// Wait a tiny bit to ensure timestamps would be different
-	time.Sleep(10 * time.Millisecond)
+	// Move the clock forward so a second update would produce a different timestamp
+	clk.Advance(time.Hour)
 
 	// Second soft delete should fail or not update timestamp
 	err = repo.SoftDelete(ctx, program.ID)
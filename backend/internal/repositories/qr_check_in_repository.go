@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+// ErrQRTokenUsed is returned when the student already scanned the QR token
+var ErrQRTokenUsed = errors.New("QR token was already used")
+
+type QRCheckInRepository struct {
+	db database.DB
+}
+
+func NewQRCheckInRepository(db database.DB) *QRCheckInRepository {
+	return &QRCheckInRepository{db: db}
+}
+
+// Create records a scan, returning ErrQRTokenUsed if the student scanned the token before
+func (r *QRCheckInRepository) Create(ctx context.Context, checkIn *models.QRCheckIn) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO qr_check_ins (token_id, user_id, class_id, program_id, client_ip, user_agent, device)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, checkIn.TokenID, checkIn.UserID, checkIn.ClassID, checkIn.ProgramID,
+		checkIn.ClientIP, checkIn.UserAgent, checkIn.Device,
+	).Scan(&checkIn.ID, &checkIn.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrQRTokenUsed
+	}
+	return err
+}
+
+// SetSession links the practice session a program scan started
+func (r *QRCheckInRepository) SetSession(ctx context.Context, id, sessionID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE qr_check_ins SET session_id = $2 WHERE id = $1`, id, sessionID)
+	return err
+}
+
+// Delete removes a scan whose check-in failed, so the token can be scanned again
+func (r *QRCheckInRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM qr_check_ins WHERE id = $1`, id)
+	return err
+}
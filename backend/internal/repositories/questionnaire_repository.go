@@ -0,0 +1,208 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/clock"
+)
+
+type QuestionnaireRepository struct {
+	db    database.DB
+	clock clock.Clock
+}
+
+func NewQuestionnaireRepository(db database.DB) *QuestionnaireRepository {
+	return &QuestionnaireRepository{db: db, clock: clock.System}
+}
+
+// WithClock replaces the clock used for timestamps, so tests can control time
+func (r *QuestionnaireRepository) WithClock(c clock.Clock) *QuestionnaireRepository {
+	r.clock = c
+	return r
+}
+
+// Get returns the program's questionnaire, or nil if it has none
+func (r *QuestionnaireRepository) Get(ctx context.Context, programID uuid.UUID) (*models.SessionQuestionnaire, error) {
+	query := `
+		SELECT program_id, questions, updated_by, updated_at
+		FROM session_questionnaires
+		WHERE program_id = $1
+	`
+
+	var q models.SessionQuestionnaire
+	err := database.Retry(ctx, "session_questionnaires.Get", func() error {
+		return r.db.QueryRow(ctx, query, programID).Scan(&q.ProgramID, &q.Questions, &q.UpdatedBy, &q.UpdatedAt)
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get questionnaire: %w", err)
+	}
+	return &q, nil
+}
+
+// Save creates or replaces the program's questionnaire
+func (r *QuestionnaireRepository) Save(ctx context.Context, q *models.SessionQuestionnaire) error {
+	query := `
+		INSERT INTO session_questionnaires (program_id, questions, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (program_id)
+		DO UPDATE SET questions = EXCLUDED.questions, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`
+
+	q.UpdatedAt = r.clock.Now()
+	if _, err := r.db.Exec(ctx, query, q.ProgramID, q.Questions, q.UpdatedBy, q.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save questionnaire: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the program's questionnaire and reports whether it had one. Answers already
+// given are kept.
+func (r *QuestionnaireRepository) Delete(ctx context.Context, programID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM session_questionnaires WHERE program_id = $1`, programID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete questionnaire: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// SaveAnswers records answers about a session, replacing earlier answers to the same questions
+func (r *QuestionnaireRepository) SaveAnswers(ctx context.Context, sessionID uuid.UUID, answers []models.SessionAnswer) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := r.clock.Now()
+	for i := range answers {
+		answers[i].AnsweredAt = now
+		_, err := tx.Exec(ctx, `
+			INSERT INTO session_answers (session_id, question_id, scale_value, text_value, answered_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (session_id, question_id) DO UPDATE
+			SET scale_value = $3, text_value = $4, answered_at = $5
+		`, sessionID, answers[i].QuestionID, answers[i].Scale, answers[i].Text, now)
+		if err != nil {
+			return fmt.Errorf("failed to save answer: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ListAnswers returns the answers given about a session
+func (r *QuestionnaireRepository) ListAnswers(ctx context.Context, sessionID uuid.UUID) ([]models.SessionAnswer, error) {
+	query := `
+		SELECT question_id, scale_value, text_value, answered_at
+		FROM session_answers
+		WHERE session_id = $1
+		ORDER BY question_id
+	`
+	answers, err := collectWithRetry(ctx, r.db, "session_answers.ListAnswers", query, func(row pgx.CollectableRow) (models.SessionAnswer, error) {
+		var a models.SessionAnswer
+		err := row.Scan(&a.QuestionID, &a.Scale, &a.Text, &a.AnsweredAt)
+		return a, err
+	}, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list answers: %w", err)
+	}
+	return answers, nil
+}
+
+// CountAnsweredSessions returns how many of the program's sessions have any answers
+func (r *QuestionnaireRepository) CountAnsweredSessions(ctx context.Context, programID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT a.session_id)
+		FROM session_answers a
+		JOIN practice_sessions s ON s.id = a.session_id
+		WHERE s.program_id = $1 AND s.deleted_at IS NULL
+	`
+
+	var count int
+	err := database.Retry(ctx, "session_answers.CountAnsweredSessions", func() error {
+		return r.db.QueryRow(ctx, query, programID).Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count answered sessions: %w", err)
+	}
+	return count, nil
+}
+
+// ScaleCounts returns, per question, how many answers gave each value on the program's scales
+func (r *QuestionnaireRepository) ScaleCounts(ctx context.Context, programID uuid.UUID) (map[string][]models.ScaleCount, error) {
+	query := `
+		SELECT a.question_id, a.scale_value, COUNT(*)
+		FROM session_answers a
+		JOIN practice_sessions s ON s.id = a.session_id
+		WHERE s.program_id = $1 AND s.deleted_at IS NULL AND a.scale_value IS NOT NULL
+		GROUP BY a.question_id, a.scale_value
+		ORDER BY a.question_id, a.scale_value
+	`
+	type scaleCount struct {
+		questionID string
+		count      models.ScaleCount
+	}
+	rows, err := collectWithRetry(ctx, r.db, "session_answers.ScaleCounts", query, func(row pgx.CollectableRow) (scaleCount, error) {
+		var sc scaleCount
+		err := row.Scan(&sc.questionID, &sc.count.Value, &sc.count.Count)
+		return sc, err
+	}, programID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count scale answers: %w", err)
+	}
+
+	counts := make(map[string][]models.ScaleCount)
+	for _, sc := range rows {
+		counts[sc.questionID] = append(counts[sc.questionID], sc.count)
+	}
+	return counts, nil
+}
+
+// TextAnswers returns, per question, how many text answers the program's sessions have and the
+// newest ones up to limit
+func (r *QuestionnaireRepository) TextAnswers(ctx context.Context, programID uuid.UUID, limit int) (map[string]int, map[string][]models.TextAnswer, error) {
+	query := `
+		SELECT question_id, session_id, user_id, user_name, text_value, answered_at, total
+		FROM (
+			SELECT a.question_id, a.session_id, s.user_id, COALESCE(u.full_name, '') AS user_name,
+				a.text_value, a.answered_at,
+				COUNT(*) OVER (PARTITION BY a.question_id) AS total,
+				ROW_NUMBER() OVER (PARTITION BY a.question_id ORDER BY a.answered_at DESC) AS rank
+			FROM session_answers a
+			JOIN practice_sessions s ON s.id = a.session_id
+			LEFT JOIN users u ON u.id = s.user_id
+			WHERE s.program_id = $1 AND s.deleted_at IS NULL AND a.text_value IS NOT NULL
+		) answers
+		WHERE rank <= $2
+		ORDER BY question_id, answered_at DESC
+	`
+	type textAnswer struct {
+		questionID string
+		answer     models.TextAnswer
+		total      int
+	}
+	rows, err := collectWithRetry(ctx, r.db, "session_answers.TextAnswers", query, func(row pgx.CollectableRow) (textAnswer, error) {
+		var ta textAnswer
+		a := &ta.answer
+		err := row.Scan(&ta.questionID, &a.SessionID, &a.UserID, &a.UserName, &a.Text, &a.AnsweredAt, &ta.total)
+		return ta, err
+	}, programID, limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list text answers: %w", err)
+	}
+
+	totals := make(map[string]int)
+	latest := make(map[string][]models.TextAnswer)
+	for _, ta := range rows {
+		totals[ta.questionID] = ta.total
+		latest[ta.questionID] = append(latest[ta.questionID], ta.answer)
+	}
+	return totals, latest, nil
+}
@@ -0,0 +1,284 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+const quizSelect = `
+	SELECT q.id, q.program_id, q.title, q.description, q.pass_threshold, q.max_attempts, q.created_by,
+	       (SELECT COUNT(*) FROM quiz_questions qq WHERE qq.quiz_id = q.id),
+	       q.created_at, q.updated_at
+	FROM quizzes q
+`
+
+type QuizRepository struct {
+	db database.DB
+}
+
+func NewQuizRepository(db database.DB) *QuizRepository {
+	return &QuizRepository{db: db}
+}
+
+// Create stores the quiz with its questions and choices
+func (r *QuizRepository) Create(ctx context.Context, quiz *models.Quiz) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO quizzes (program_id, title, description, pass_threshold, max_attempts, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, quiz.ProgramID, quiz.Title, quiz.Description, quiz.PassThreshold, quiz.MaxAttempts, quiz.CreatedBy).Scan(&quiz.ID, &quiz.CreatedAt, &quiz.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if err := insertQuestions(ctx, tx, quiz.ID, quiz.Questions); err != nil {
+		return err
+	}
+	quiz.QuestionCount = len(quiz.Questions)
+
+	return tx.Commit(ctx)
+}
+
+// GetByID returns the quiz with its questions and choices, including the correct answers,
+// or nil if it does not exist
+func (r *QuizRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Quiz, error) {
+	quiz, err := scanQuiz(r.db.QueryRow(ctx, quizSelect+`WHERE q.id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := r.getQuestions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	quiz.Questions = questions
+	return quiz, nil
+}
+
+// ListByProgram returns the program's quizzes without their questions
+func (r *QuizRepository) ListByProgram(ctx context.Context, programID uuid.UUID) ([]models.Quiz, error) {
+	rows, err := r.db.Query(ctx, quizSelect+`WHERE q.program_id = $1 ORDER BY q.created_at`, programID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quizzes := make([]models.Quiz, 0)
+	for rows.Next() {
+		quiz, err := scanQuiz(rows)
+		if err != nil {
+			return nil, err
+		}
+		quizzes = append(quizzes, *quiz)
+	}
+	return quizzes, rows.Err()
+}
+
+// Update saves the quiz settings and, if replaceQuestions is set, replaces its questions
+func (r *QuizRepository) Update(ctx context.Context, quiz *models.Quiz, replaceQuestions bool) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE quizzes SET title = $2, description = $3, pass_threshold = $4, max_attempts = $5
+		WHERE id = $1
+		RETURNING updated_at
+	`, quiz.ID, quiz.Title, quiz.Description, quiz.PassThreshold, quiz.MaxAttempts).Scan(&quiz.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if replaceQuestions {
+		if _, err := tx.Exec(ctx, `DELETE FROM quiz_questions WHERE quiz_id = $1`, quiz.ID); err != nil {
+			return err
+		}
+		if err := insertQuestions(ctx, tx, quiz.ID, quiz.Questions); err != nil {
+			return err
+		}
+		quiz.QuestionCount = len(quiz.Questions)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Delete removes the quiz with its attempts and reports whether it existed
+func (r *QuizRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM quizzes WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *QuizRepository) CreateAttempt(ctx context.Context, attempt *models.QuizAttempt) error {
+	query := `
+		INSERT INTO quiz_attempts (quiz_id, user_id, answers, score, passed)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(ctx, query,
+		attempt.QuizID,
+		attempt.UserID,
+		attempt.Answers,
+		attempt.Score,
+		attempt.Passed,
+	).Scan(&attempt.ID, &attempt.CreatedAt)
+}
+
+func (r *QuizRepository) CountAttempts(ctx context.Context, quizID, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM quiz_attempts WHERE quiz_id = $1 AND user_id = $2`, quizID, userID).Scan(&count)
+	return count, err
+}
+
+// ListAttempts returns the user's attempts at the quiz, newest first
+func (r *QuizRepository) ListAttempts(ctx context.Context, quizID, userID uuid.UUID) ([]models.QuizAttempt, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, quiz_id, user_id, answers, score, passed, created_at
+		FROM quiz_attempts
+		WHERE quiz_id = $1 AND user_id = $2
+		ORDER BY created_at DESC
+	`, quizID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := make([]models.QuizAttempt, 0)
+	for rows.Next() {
+		var attempt models.QuizAttempt
+		err := rows.Scan(
+			&attempt.ID,
+			&attempt.QuizID,
+			&attempt.UserID,
+			&attempt.Answers,
+			&attempt.Score,
+			&attempt.Passed,
+			&attempt.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+	return attempts, rows.Err()
+}
+
+func (r *QuizRepository) getQuestions(ctx context.Context, quizID uuid.UUID) ([]models.QuizQuestion, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT qq.id, qq.position, qq.prompt, qq.explanation, c.id, c.position, c.text, c.is_correct
+		FROM quiz_questions qq
+		JOIN quiz_choices c ON c.question_id = qq.id
+		WHERE qq.quiz_id = $1
+		ORDER BY qq.position, c.position
+	`, quizID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	questions := make([]models.QuizQuestion, 0)
+	for rows.Next() {
+		var question models.QuizQuestion
+		var choice models.QuizChoice
+		err := rows.Scan(
+			&question.ID,
+			&question.Position,
+			&question.Prompt,
+			&question.Explanation,
+			&choice.ID,
+			&choice.Position,
+			&choice.Text,
+			&choice.IsCorrect,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Rows are ordered by question, so a question's choices are consecutive
+		if n := len(questions); n == 0 || questions[n-1].ID != question.ID {
+			questions = append(questions, question)
+		}
+		last := &questions[len(questions)-1]
+		last.Choices = append(last.Choices, choice)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range questions {
+		correct := 0
+		for _, choice := range questions[i].Choices {
+			if *choice.IsCorrect {
+				correct++
+			}
+		}
+		questions[i].MultipleAnswers = correct > 1
+	}
+	return questions, nil
+}
+
+// insertQuestions stores questions and their choices in order, setting IDs and positions
+func insertQuestions(ctx context.Context, tx pgx.Tx, quizID uuid.UUID, questions []models.QuizQuestion) error {
+	for i := range questions {
+		question := &questions[i]
+		question.Position = i + 1
+		err := tx.QueryRow(ctx, `
+			INSERT INTO quiz_questions (quiz_id, position, prompt, explanation)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, quizID, question.Position, question.Prompt, question.Explanation).Scan(&question.ID)
+		if err != nil {
+			return err
+		}
+
+		for j := range question.Choices {
+			choice := &question.Choices[j]
+			choice.Position = j + 1
+			err := tx.QueryRow(ctx, `
+				INSERT INTO quiz_choices (question_id, position, text, is_correct)
+				VALUES ($1, $2, $3, $4)
+				RETURNING id
+			`, question.ID, choice.Position, choice.Text, choice.IsCorrect != nil && *choice.IsCorrect).Scan(&choice.ID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func scanQuiz(row pgx.Row) (*models.Quiz, error) {
+	var quiz models.Quiz
+	err := row.Scan(
+		&quiz.ID,
+		&quiz.ProgramID,
+		&quiz.Title,
+		&quiz.Description,
+		&quiz.PassThreshold,
+		&quiz.MaxAttempts,
+		&quiz.CreatedBy,
+		&quiz.QuestionCount,
+		&quiz.CreatedAt,
+		&quiz.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &quiz, nil
+}
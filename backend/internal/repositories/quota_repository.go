@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+type QuotaRepository struct {
+	db database.DB
+}
+
+func NewQuotaRepository(db database.DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+// ListPlans returns all quota plans by name
+func (r *QuotaRepository) ListPlans(ctx context.Context) ([]models.QuotaPlan, error) {
+	query := `
+		SELECT name, max_programs, max_open_submissions, max_storage_bytes, created_at, updated_at
+		FROM quota_plans
+		ORDER BY name
+	`
+	plans, err := collectWithRetry(ctx, r.db, "quotas.ListPlans", query, func(row pgx.CollectableRow) (models.QuotaPlan, error) {
+		var p models.QuotaPlan
+		err := row.Scan(&p.Name, &p.MaxPrograms, &p.MaxOpenSubmissions, &p.MaxStorageBytes, &p.CreatedAt, &p.UpdatedAt)
+		return p, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quota plans: %w", err)
+	}
+	return plans, nil
+}
+
+// PlanExists reports whether a quota plan with the name exists
+func (r *QuotaRepository) PlanExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM quota_plans WHERE name = $1)`, name).Scan(&exists)
+	return exists, err
+}
+
+// SavePlan creates the plan or replaces its limits
+func (r *QuotaRepository) SavePlan(ctx context.Context, plan *models.QuotaPlan) error {
+	query := `
+		INSERT INTO quota_plans (name, max_programs, max_open_submissions, max_storage_bytes)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO UPDATE
+		SET max_programs = EXCLUDED.max_programs,
+		    max_open_submissions = EXCLUDED.max_open_submissions,
+		    max_storage_bytes = EXCLUDED.max_storage_bytes
+		RETURNING created_at, updated_at
+	`
+	return r.db.QueryRow(ctx, query, plan.Name, plan.MaxPrograms, plan.MaxOpenSubmissions, plan.MaxStorageBytes).
+		Scan(&plan.CreatedAt, &plan.UpdatedAt)
+}
+
+// DeletePlan removes a plan; its users fall back to the default plan. Returns false if there was none.
+func (r *QuotaRepository) DeletePlan(ctx context.Context, name string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM quota_plans WHERE name = $1`, name)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetUserQuota returns the user's plan, overrides and effective limits. Usage is left empty.
+func (r *QuotaRepository) GetUserQuota(ctx context.Context, userID uuid.UUID) (*models.UserQuota, error) {
+	query := `
+		SELECT
+			p.name,
+			uq.max_programs, uq.max_open_submissions, uq.max_storage_bytes,
+			COALESCE(uq.max_programs, p.max_programs),
+			COALESCE(uq.max_open_submissions, p.max_open_submissions),
+			COALESCE(uq.max_storage_bytes, p.max_storage_bytes)
+		FROM (SELECT $1::uuid AS user_id) u
+		LEFT JOIN user_quotas uq ON uq.user_id = u.user_id
+		JOIN quota_plans p ON p.name = COALESCE(uq.plan, $2)
+	`
+	q := &models.UserQuota{UserID: userID}
+	err := r.db.QueryRow(ctx, query, userID, models.DefaultQuotaPlan).Scan(
+		&q.Plan,
+		&q.Overrides.MaxPrograms, &q.Overrides.MaxOpenSubmissions, &q.Overrides.MaxStorageBytes,
+		&q.Limits.MaxPrograms, &q.Limits.MaxOpenSubmissions, &q.Limits.MaxStorageBytes,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The default plan was removed by hand; treat everyone as unlimited
+		q.Plan = models.DefaultQuotaPlan
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user quota: %w", err)
+	}
+	return q, nil
+}
+
+// SaveUserQuota sets the user's plan (nil for the default plan) and overrides
+func (r *QuotaRepository) SaveUserQuota(ctx context.Context, userID uuid.UUID, plan *string, overrides models.QuotaLimits) error {
+	query := `
+		INSERT INTO user_quotas (user_id, plan, max_programs, max_open_submissions, max_storage_bytes)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET plan = EXCLUDED.plan,
+		    max_programs = EXCLUDED.max_programs,
+		    max_open_submissions = EXCLUDED.max_open_submissions,
+		    max_storage_bytes = EXCLUDED.max_storage_bytes
+	`
+	_, err := r.db.Exec(ctx, query, userID, plan, overrides.MaxPrograms, overrides.MaxOpenSubmissions, overrides.MaxStorageBytes)
+	return err
+}
+
+// storageBytesQuery sums the distinct covers of user $1's programs other than program $2, and
+// the user's journal media. Covers are content-addressed, so a cover shared by several programs
+// counts once.
+const storageBytesQuery = `
+	SELECT (
+		SELECT COALESCE(SUM(cover_size_bytes), 0)
+		FROM (
+			SELECT DISTINCT cover_image_key, cover_size_bytes
+			FROM programs
+			WHERE owned_by = $1 AND id <> $2 AND deleted_at IS NULL AND cover_image_key IS NOT NULL
+		) covers
+	) + (
+		SELECT COALESCE(SUM(size_bytes), 0) FROM journal_entries WHERE user_id = $1
+	)::bigint`
+
+// GetUsage counts what the user currently has against their quota
+func (r *QuotaRepository) GetUsage(ctx context.Context, userID uuid.UUID) (*models.QuotaUsage, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM programs WHERE owned_by = $1 AND deleted_at IS NULL),
+			(SELECT COUNT(*)
+			 FROM submissions s
+			 WHERE s.user_id = $1 AND s.deleted_at IS NULL
+			   AND COALESCE((
+			       SELECT sm.user_id = s.user_id
+			       FROM submission_messages sm
+			       WHERE sm.submission_id = s.id
+			       ORDER BY sm.created_at DESC
+			       LIMIT 1
+			   ), true)),
+			(` + storageBytesQuery + `)
+	`
+	var usage models.QuotaUsage
+	if err := r.db.QueryRow(ctx, query, userID, uuid.Nil).Scan(&usage.Programs, &usage.OpenSubmissions, &usage.StorageBytes); err != nil {
+		return nil, fmt.Errorf("failed to get quota usage: %w", err)
+	}
+	return &usage, nil
+}
+
+// GetStorageBytes is the storage usage without the cover of exceptProgramID, for checking a replacement cover
+func (r *QuotaRepository) GetStorageBytes(ctx context.Context, userID, exceptProgramID uuid.UUID) (int64, error) {
+	var bytes int64
+	err := r.db.QueryRow(ctx, storageBytesQuery, userID, exceptProgramID).Scan(&bytes)
+	return bytes, err
+}
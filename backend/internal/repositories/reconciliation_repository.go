@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+type ReconciliationRepository struct {
+	db database.DB
+}
+
+func NewReconciliationRepository(db database.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+// Create records a reconciliation run
+func (r *ReconciliationRepository) Create(ctx context.Context, report *models.RepetitionReconciliation) error {
+	query := `
+		INSERT INTO repetition_reconciliations (programs_checked, drifted)
+		VALUES ($1, $2)
+		RETURNING id, ran_at
+	`
+	return r.db.QueryRow(ctx, query, report.ProgramsChecked, report.Drifted).Scan(&report.ID, &report.RanAt)
+}
+
+// List returns reconciliation runs, newest first
+func (r *ReconciliationRepository) List(ctx context.Context, limit, offset int) ([]models.RepetitionReconciliation, error) {
+	query := `
+		SELECT id, programs_checked, drifted, ran_at
+		FROM repetition_reconciliations
+		ORDER BY ran_at DESC
+		LIMIT $1 OFFSET $2
+	`
+	return collectWithRetry(ctx, r.db, "repetition_reconciliations.List", query, func(row pgx.CollectableRow) (models.RepetitionReconciliation, error) {
+		var report models.RepetitionReconciliation
+		err := row.Scan(&report.ID, &report.ProgramsChecked, &report.Drifted, &report.RanAt)
+		return report, err
+	}, limit, offset)
+}
+
+// LastRunAt returns when the latest reconciliation ran, or nil if none has
+func (r *ReconciliationRepository) LastRunAt(ctx context.Context) (*time.Time, error) {
+	var ranAt time.Time
+	err := database.Retry(ctx, "repetition_reconciliations.LastRunAt", func() error {
+		return r.db.QueryRow(ctx, `SELECT ran_at FROM repetition_reconciliations ORDER BY ran_at DESC LIMIT 1`).Scan(&ranAt)
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ranAt, nil
+}
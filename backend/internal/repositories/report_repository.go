@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xuangong/backend/internal/database"
+)
+
+// Report is a fixed aggregation offered as an admin download. Its query takes the start of
+// the range as $1 (inclusive) and the end as $2 (exclusive) and returns one value per column,
+// cast to types that map onto plain Go values: text, bigint, float8, boolean or timestamp.
+type Report struct {
+	Name    string
+	Columns []string
+	// UserIDColumns and PersonalColumns name the columns pseudonymized or removed when
+	// analytics are anonymized
+	UserIDColumns   []string
+	PersonalColumns []string
+	query           string
+}
+
+// completedSessionsCTE is the practice sessions completed within the report range
+const completedSessionsCTE = `
+	completed AS (
+		SELECT user_id, program_id, completed_at, total_duration_seconds, completion_rate
+		FROM practice_sessions
+		WHERE deleted_at IS NULL AND completed_at >= $1 AND completed_at < $2
+	)`
+
+// firstRepliesCTE is the first message in each thread from someone other than the student
+const firstRepliesCTE = `
+	first_replies AS (
+		SELECT s.id AS submission_id, MIN(sm.created_at) AS replied_at
+		FROM submissions s
+		JOIN submission_messages sm ON sm.submission_id = s.id AND sm.user_id <> s.user_id
+		WHERE s.deleted_at IS NULL
+		GROUP BY s.id
+	)`
+
+var reports = []Report{
+	{
+		Name:            "user_activity",
+		Columns:         []string{"user_id", "full_name", "email", "role", "is_active", "sessions", "practice_minutes", "active_days", "submissions", "messages", "last_session_at"},
+		UserIDColumns:   []string{"user_id"},
+		PersonalColumns: []string{"full_name", "email"},
+		query: `
+			WITH ` + completedSessionsCTE + `
+			SELECT
+				u.id::text, u.full_name, u.email, u.role, COALESCE(u.is_active, false),
+				COALESCE(c.sessions, 0),
+				COALESCE(c.minutes, 0),
+				COALESCE(c.active_days, 0),
+				COALESCE(s.submissions, 0),
+				COALESCE(m.messages, 0),
+				c.last_session_at
+			FROM users u
+			LEFT JOIN (
+				SELECT user_id, COUNT(*) AS sessions,
+					COALESCE(SUM(total_duration_seconds), 0)::bigint / 60 AS minutes,
+					COUNT(DISTINCT completed_at::date) AS active_days,
+					MAX(completed_at) AS last_session_at
+				FROM completed
+				GROUP BY user_id
+			) c ON c.user_id = u.id
+			LEFT JOIN (
+				SELECT user_id, COUNT(*) AS submissions
+				FROM submissions
+				WHERE deleted_at IS NULL AND created_at >= $1 AND created_at < $2
+				GROUP BY user_id
+			) s ON s.user_id = u.id
+			LEFT JOIN (
+				SELECT sm.user_id, COUNT(*) AS messages
+				FROM submission_messages sm
+				JOIN submissions sub ON sub.id = sm.submission_id
+				WHERE sub.deleted_at IS NULL AND sm.created_at >= $1 AND sm.created_at < $2
+				GROUP BY sm.user_id
+			) m ON m.user_id = u.id
+			ORDER BY u.full_name, u.id
+		`,
+	},
+	{
+		Name:            "program_adoption",
+		Columns:         []string{"program_id", "name", "owner", "assigned_students", "practicing_students", "sessions", "practice_minutes", "avg_completion_rate"},
+		PersonalColumns: []string{"owner"},
+		query: `
+			WITH ` + completedSessionsCTE + `
+			SELECT
+				p.id::text, p.name, o.full_name,
+				COALESCE(a.assigned, 0),
+				COALESCE(c.practicing, 0),
+				COALESCE(c.sessions, 0),
+				COALESCE(c.minutes, 0),
+				c.avg_completion_rate
+			FROM programs p
+			LEFT JOIN users o ON o.id = p.owned_by
+			LEFT JOIN (
+				SELECT program_id, COUNT(*) AS assigned
+				FROM user_programs
+				WHERE is_active = true
+				GROUP BY program_id
+			) a ON a.program_id = p.id
+			LEFT JOIN (
+				SELECT program_id, COUNT(DISTINCT user_id) AS practicing, COUNT(*) AS sessions,
+					COALESCE(SUM(total_duration_seconds), 0)::bigint / 60 AS minutes,
+					ROUND(AVG(completion_rate), 2)::float8 AS avg_completion_rate
+				FROM completed
+				GROUP BY program_id
+			) c ON c.program_id = p.id
+			WHERE p.deleted_at IS NULL
+			ORDER BY COALESCE(c.sessions, 0) DESC, p.name, p.id
+		`,
+	},
+	{
+		Name:            "submission_turnaround",
+		Columns:         []string{"submission_id", "title", "program", "student", "created_at", "first_reply_at", "hours_to_first_reply"},
+		PersonalColumns: []string{"title", "student"}, // Students often name themselves in titles
+		query: `
+			WITH ` + firstRepliesCTE + `
+			SELECT
+				s.id::text, s.title, p.name, u.full_name, s.created_at, fr.replied_at,
+				ROUND((EXTRACT(EPOCH FROM fr.replied_at - s.created_at) / 3600)::numeric, 2)::float8
+			FROM submissions s
+			JOIN programs p ON p.id = s.program_id
+			JOIN users u ON u.id = s.user_id
+			LEFT JOIN first_replies fr ON fr.submission_id = s.id
+			WHERE s.deleted_at IS NULL AND s.created_at >= $1 AND s.created_at < $2
+			ORDER BY s.created_at, s.id
+		`,
+	},
+	{
+		Name:    "exercise_substitutions",
+		Columns: []string{"substitute_id", "program", "exercise", "substitute", "reason", "students_choosing", "times_performed", "students_performing"},
+		query: `
+			SELECT
+				es.id::text, p.name, e.name, es.name, es.reason,
+				chosen.students,
+				COALESCE(l.performed, 0),
+				COALESCE(l.students, 0)
+			FROM exercise_substitutes es
+			JOIN exercises e ON e.id = es.exercise_id
+			JOIN programs p ON p.id = e.program_id
+			-- Students who currently do the substitute instead of the exercise
+			CROSS JOIN LATERAL (
+				SELECT COUNT(*) AS students
+				FROM user_programs up
+				WHERE up.program_id = e.program_id AND up.is_active = true
+					AND up.custom_settings->'exercise_overrides'->(e.id::text)->>'substitute_id' = es.id::text
+			) chosen
+			LEFT JOIN (
+				SELECT el.substitute_id, COUNT(*) AS performed, COUNT(DISTINCT ps.user_id) AS students
+				FROM exercise_logs el
+				JOIN practice_sessions ps ON ps.id = el.session_id
+				WHERE el.substitute_id IS NOT NULL AND NOT el.skipped AND ps.deleted_at IS NULL
+					AND el.started_at >= $1 AND el.started_at < $2
+				GROUP BY el.substitute_id
+			) l ON l.substitute_id = es.id
+			WHERE p.deleted_at IS NULL
+			ORDER BY COALESCE(l.performed, 0) DESC, p.name, e.name, es.name, es.id
+		`,
+	},
+}
+
+type ReportRepository struct {
+	db database.DB
+}
+
+func NewReportRepository(db database.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// Get returns the report with the given name, or false if there is none
+func (r *ReportRepository) Get(name string) (Report, bool) {
+	for _, report := range reports {
+		if report.Name == name {
+			return report, true
+		}
+	}
+	return Report{}, false
+}
+
+// Stream runs a report over [from, to) and passes each row to fn as it is read, so large
+// reports are never held in memory. An error from fn stops the report and is returned.
+// Transient errors are retried only until the first row has reached fn; after that a retry
+// would pass rows to fn twice.
+func (r *ReportRepository) Stream(ctx context.Context, report Report, from, to time.Time, fn func(values []any) error) error {
+	var streamErr error
+	err := database.Retry(ctx, "reports."+report.Name, func() error {
+		rows, err := r.db.Query(ctx, report.query, from, to)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		streamed := false
+		for rows.Next() {
+			values, err := rows.Values()
+			if err != nil {
+				streamErr = fmt.Errorf("failed to read report %s: %w", report.Name, err)
+				return nil
+			}
+			streamed = true
+			if err := fn(values); err != nil {
+				streamErr = err
+				return nil
+			}
+		}
+		if err := rows.Err(); err != nil && streamed {
+			streamErr = fmt.Errorf("failed to read report %s: %w", report.Name, err)
+			return nil
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to run report %s: %w", report.Name, err)
+	}
+	return streamErr
+}
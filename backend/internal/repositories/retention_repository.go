@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+// retentionTarget is one table a retention rule applies to. Rows matching where, with $1 the
+// cutoff, are deleted, or updated with set when it is given.
+type retentionTarget struct {
+	table string
+	where string
+	set   string
+}
+
+// retentionTargets lists the tables of each rule, in the order they are applied
+var retentionTargets = map[string][]retentionTarget{
+	// Exercise logs, notes, biometrics and diary entries go with their session (ON DELETE CASCADE)
+	models.RetentionRuleSessions: {
+		{table: "practice_sessions", where: "started_at < $1"},
+	},
+	// Programs cascade to everything assigned to them, so only programs nothing of a student's
+	// still refers to are purged: no sessions, and no submissions that are kept. Sessions have
+	// their own purge (SESSION_PURGE_AFTER_DAYS).
+	models.RetentionRuleSoftDeleted: {
+		{table: "submissions", where: "deleted_at < $1"},
+		{table: "discussion_replies", where: "deleted_at < $1"},
+		{table: "discussion_topics", where: "deleted_at < $1"},
+		{table: "programs", where: `deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM practice_sessions ps WHERE ps.program_id = programs.id AND ps.deleted_at IS NULL)
+			AND NOT EXISTS (SELECT 1 FROM submissions s WHERE s.program_id = programs.id AND (s.deleted_at IS NULL OR s.deleted_at >= $1))`},
+	},
+	models.RetentionRuleDeviceInfo: {
+		{table: "practice_sessions", where: "started_at < $1 AND device_info IS NOT NULL", set: "device_info = NULL"},
+		{table: "access_logs", where: "created_at < $1 AND (device IS NOT NULL OR user_agent IS NOT NULL)", set: "device = NULL, user_agent = NULL"},
+		{table: "qr_check_ins", where: "created_at < $1 AND (device IS NOT NULL OR user_agent IS NOT NULL)", set: "device = NULL, user_agent = NULL"},
+	},
+}
+
+type RetentionRepository struct {
+	db database.DB
+}
+
+func NewRetentionRepository(db database.DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// Apply deletes or strips the rule's data from before the cutoff and returns the rows affected
+// per table. On a dry run the rows are only counted.
+func (r *RetentionRepository) Apply(ctx context.Context, rule string, cutoff time.Time, dryRun bool) (map[string]int64, error) {
+	targets, ok := retentionTargets[rule]
+	if !ok {
+		return nil, fmt.Errorf("unknown retention rule %q", rule)
+	}
+
+	affected := make(map[string]int64, len(targets))
+	for _, target := range targets {
+		var n int64
+		var err error
+		if dryRun {
+			query := `SELECT COUNT(*) FROM ` + target.table + ` WHERE ` + target.where
+			err = database.Retry(ctx, "retention.Count", func() error {
+				return r.db.QueryRow(ctx, query, cutoff).Scan(&n)
+			})
+		} else {
+			query := `DELETE FROM ` + target.table + ` WHERE ` + target.where
+			if target.set != "" {
+				query = `UPDATE ` + target.table + ` SET ` + target.set + ` WHERE ` + target.where
+			}
+			result, execErr := r.db.Exec(ctx, query, cutoff)
+			n, err = result.RowsAffected(), execErr
+		}
+		if err != nil {
+			return affected, fmt.Errorf("failed to apply retention to %s: %w", target.table, err)
+		}
+		affected[target.table] += n
+	}
+	return affected, nil
+}
+
+// CreateRun records a run of a rule
+func (r *RetentionRepository) CreateRun(ctx context.Context, run *models.RetentionRun) error {
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO retention_runs (rule, dry_run, scheduled, cutoff, affected, triggered_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, ran_at
+	`, run.Rule, run.DryRun, run.Scheduled, run.Cutoff, run.Affected, run.TriggeredBy).Scan(&run.ID, &run.RanAt)
+	if err != nil {
+		return fmt.Errorf("failed to record retention run: %w", err)
+	}
+	return nil
+}
+
+// ListRuns returns runs, newest first, optionally of one rule only
+func (r *RetentionRepository) ListRuns(ctx context.Context, rule string, limit, offset int) ([]models.RetentionRun, error) {
+	query := `
+		SELECT id, rule, dry_run, scheduled, cutoff, affected, triggered_by, ran_at
+		FROM retention_runs
+		WHERE ($1 = '' OR rule = $1)
+		ORDER BY ran_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	runs, err := collectWithRetry(ctx, r.db, "retention_runs.List", query, func(row pgx.CollectableRow) (models.RetentionRun, error) {
+		var run models.RetentionRun
+		err := row.Scan(&run.ID, &run.Rule, &run.DryRun, &run.Scheduled, &run.Cutoff, &run.Affected, &run.TriggeredBy, &run.RanAt)
+		return run, err
+	}, rule, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention runs: %w", err)
+	}
+	return runs, nil
+}
+
+// LastScheduledRunAt returns when the daily job last ran the rule, or nil if it never has
+func (r *RetentionRepository) LastScheduledRunAt(ctx context.Context, rule string) (*time.Time, error) {
+	var ranAt time.Time
+	err := database.Retry(ctx, "retention_runs.LastScheduledRunAt", func() error {
+		return r.db.QueryRow(ctx, `
+			SELECT ran_at FROM retention_runs
+			WHERE rule = $1 AND scheduled
+			ORDER BY ran_at DESC
+			LIMIT 1
+		`, rule).Scan(&ranAt)
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last retention run: %w", err)
+	}
+	return &ranAt, nil
+}
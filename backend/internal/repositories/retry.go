@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+)
+
+// collectWithRetry runs a read-only query and scans every row with scan, retrying transient
+// errors. pgx reports errors that happen while the query executes, such as serialization
+// failures and deadlocks, through rows.Err() after Query has returned, so the rows are read
+// inside the retry and a retry reads the whole result again.
+func collectWithRetry[T any](ctx context.Context, db database.DB, op, query string, scan pgx.RowToFunc[T], args ...interface{}) ([]T, error) {
+	var items []T
+	err := database.Retry(ctx, op, func() error {
+		rows, err := db.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		items, err = pgx.CollectRows(rows, scan)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/xuangong/backend/pkg/testutil/mocks"
+)
+
+// fakeRows returns values one row at a time and then reports err from Err, the way pgx reports
+// errors raised while the query executes
+type fakeRows struct {
+	values [][]any
+	err    error
+	next   int
+}
+
+func (r *fakeRows) Close()                                       {}
+func (r *fakeRows) Err() error                                   { return r.err }
+func (r *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *fakeRows) RawValues() [][]byte                          { return nil }
+func (r *fakeRows) Conn() *pgx.Conn                              { return nil }
+
+func (r *fakeRows) Next() bool {
+	if r.next >= len(r.values) {
+		return false
+	}
+	r.next++
+	return true
+}
+
+func (r *fakeRows) Values() ([]any, error) {
+	return r.values[r.next-1], nil
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	for i, v := range r.values[r.next-1] {
+		*dest[i].(*uuid.UUID) = v.(uuid.UUID)
+	}
+	return nil
+}
+
+func TestCollectWithRetry_RetriesRowsErr(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	calls := 0
+	db := &mocks.DBMock{
+		QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			calls++
+			if calls == 1 {
+				// The first row arrives before the serialization failure
+				return &fakeRows{values: [][]any{{ids[0]}}, err: &pgconn.PgError{Code: "40001"}}, nil
+			}
+			return &fakeRows{values: [][]any{{ids[0]}, {ids[1]}}}, nil
+		},
+	}
+
+	got, err := NewSessionRepository(db).ListProgramIDs(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatalf("ListProgramIDs() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Query called %d times, want 2", calls)
+	}
+	if len(got) != 2 || got[0] != ids[0] || got[1] != ids[1] {
+		t.Errorf("ListProgramIDs() = %v, want %v without rows of the failed attempt", got, ids)
+	}
+}
+
+func TestCollectWithRetry_DoesNotRetryPermanentRowsErr(t *testing.T) {
+	calls := 0
+	db := &mocks.DBMock{
+		QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			calls++
+			return &fakeRows{err: &pgconn.PgError{Code: "22012"}}, nil
+		},
+	}
+
+	_, err := NewSessionRepository(db).ListProgramIDs(context.Background(), uuid.New())
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "22012" {
+		t.Fatalf("ListProgramIDs() error = %v, want the division by zero", err)
+	}
+	if calls != 1 {
+		t.Errorf("Query called %d times, want 1", calls)
+	}
+}
+
+func TestReportStream_RetriesOnlyBeforeFirstRow(t *testing.T) {
+	report := reports[0]
+	id := uuid.New()
+
+	t.Run("retries a failure before any row", func(t *testing.T) {
+		calls := 0
+		db := &mocks.DBMock{
+			QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+				calls++
+				if calls == 1 {
+					return &fakeRows{err: &pgconn.PgError{Code: "40P01"}}, nil
+				}
+				return &fakeRows{values: [][]any{{id}}}, nil
+			},
+		}
+
+		var streamed int
+		err := NewReportRepository(db).Stream(context.Background(), report, time.Now(), time.Now(), func(values []any) error {
+			streamed++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		if calls != 2 || streamed != 1 {
+			t.Errorf("Query called %d times and streamed %d rows, want 2 and 1", calls, streamed)
+		}
+	})
+
+	t.Run("does not replay rows already streamed", func(t *testing.T) {
+		calls := 0
+		db := &mocks.DBMock{
+			QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+				calls++
+				return &fakeRows{values: [][]any{{id}}, err: &pgconn.PgError{Code: "40001"}}, nil
+			},
+		}
+
+		var streamed int
+		err := NewReportRepository(db).Stream(context.Background(), report, time.Now(), time.Now(), func(values []any) error {
+			streamed++
+			return nil
+		})
+		if err == nil {
+			t.Fatal("Stream() error = nil, want the serialization failure")
+		}
+		if calls != 1 || streamed != 1 {
+			t.Errorf("Query called %d times and streamed %d rows, want 1 and 1", calls, streamed)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+const scheduledMessageColumns = `id, author_id, submission_id, program_id, title, content, youtube_url, send_at,
+	status, message_id, recipients, error, sent_at, created_at`
+
+type ScheduledMessageRepository struct {
+	db database.DB
+}
+
+func NewScheduledMessageRepository(db database.DB) *ScheduledMessageRepository {
+	return &ScheduledMessageRepository{db: db}
+}
+
+func (r *ScheduledMessageRepository) Create(ctx context.Context, message *models.ScheduledMessage) error {
+	query := `
+		INSERT INTO scheduled_messages (author_id, submission_id, program_id, title, content, youtube_url, send_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, status, created_at
+	`
+	return r.db.QueryRow(ctx, query,
+		message.AuthorID,
+		message.SubmissionID,
+		message.ProgramID,
+		message.Title,
+		message.Content,
+		message.YouTubeURL,
+		message.SendAt,
+	).Scan(&message.ID, &message.Status, &message.CreatedAt)
+}
+
+// GetByID returns the scheduled message if it belongs to the author, nil otherwise
+func (r *ScheduledMessageRepository) GetByID(ctx context.Context, id, authorID uuid.UUID) (*models.ScheduledMessage, error) {
+	query := `SELECT ` + scheduledMessageColumns + ` FROM scheduled_messages WHERE id = $1 AND author_id = $2`
+	message, err := scanScheduledMessage(r.db.QueryRow(ctx, query, id, authorID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// ListByAuthor returns the author's scheduled messages with the given status, next due first
+func (r *ScheduledMessageRepository) ListByAuthor(ctx context.Context, authorID uuid.UUID, status models.ScheduledMessageStatus) ([]models.ScheduledMessage, error) {
+	query := `
+		SELECT ` + scheduledMessageColumns + `
+		FROM scheduled_messages
+		WHERE author_id = $1 AND status = $2
+		ORDER BY send_at, created_at
+	`
+	rows, err := r.db.Query(ctx, query, authorID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]models.ScheduledMessage, 0)
+	for rows.Next() {
+		message, err := scanScheduledMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *message)
+	}
+	return messages, rows.Err()
+}
+
+// Cancel marks the author's pending message as cancelled and reports whether it was still pending
+func (r *ScheduledMessageRepository) Cancel(ctx context.Context, id, authorID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE scheduled_messages SET status = 'cancelled'
+		WHERE id = $1 AND author_id = $2 AND status = 'pending'
+	`, id, authorID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// ClaimDue marks up to limit pending messages due at now as sent and returns them for delivery.
+// Claiming before delivering means a message is never posted twice, even with several API instances.
+func (r *ScheduledMessageRepository) ClaimDue(ctx context.Context, now time.Time, limit int) ([]models.ScheduledMessage, error) {
+	query := `
+		UPDATE scheduled_messages SET status = 'sent', sent_at = $1
+		WHERE id IN (
+			SELECT id FROM scheduled_messages
+			WHERE status = 'pending' AND send_at <= $1
+			ORDER BY send_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + scheduledMessageColumns
+	rows, err := r.db.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.ScheduledMessage
+	for rows.Next() {
+		message, err := scanScheduledMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *message)
+	}
+	return messages, rows.Err()
+}
+
+// MarkDelivered records the posted message and how many users were notified
+func (r *ScheduledMessageRepository) MarkDelivered(ctx context.Context, id uuid.UUID, messageID *uuid.UUID, recipients int) error {
+	_, err := r.db.Exec(ctx, `UPDATE scheduled_messages SET message_id = $2, recipients = $3 WHERE id = $1`, id, messageID, recipients)
+	return err
+}
+
+// MarkFailed records why a claimed message could not be delivered
+func (r *ScheduledMessageRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.db.Exec(ctx, `UPDATE scheduled_messages SET status = 'failed', error = $2 WHERE id = $1`, id, reason)
+	return err
+}
+
+func scanScheduledMessage(row pgx.Row) (*models.ScheduledMessage, error) {
+	var message models.ScheduledMessage
+	err := row.Scan(
+		&message.ID,
+		&message.AuthorID,
+		&message.SubmissionID,
+		&message.ProgramID,
+		&message.Title,
+		&message.Content,
+		&message.YouTubeURL,
+		&message.SendAt,
+		&message.Status,
+		&message.MessageID,
+		&message.Recipients,
+		&message.Error,
+		&message.SentAt,
+		&message.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
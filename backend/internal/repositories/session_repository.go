@@ -3,54 +3,102 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/xuangong/backend/internal/database"
 	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/streaks"
+	"github.com/xuangong/backend/pkg/clock"
+	"github.com/xuangong/backend/pkg/fieldcrypt"
 )
 
 type SessionRepository struct {
-	db *pgxpool.Pool
+	db      database.DB
+	clock   clock.Clock
+	keyring *fieldcrypt.Keyring
 }
 
-func NewSessionRepository(db *pgxpool.Pool) *SessionRepository {
-	return &SessionRepository{db: db}
+func NewSessionRepository(db database.DB) *SessionRepository {
+	return &SessionRepository{db: db, clock: clock.System}
+}
+
+// WithClock replaces the clock used for timestamps, so tests can control time
+func (r *SessionRepository) WithClock(c clock.Clock) *SessionRepository {
+	r.clock = c
+	return r
+}
+
+// WithKeyring encrypts the raw biometric samples, which are health data, with the keyring
+func (r *SessionRepository) WithKeyring(keyring *fieldcrypt.Keyring) *SessionRepository {
+	r.keyring = keyring
+	return r
 }
 
 func (r *SessionRepository) Create(ctx context.Context, session *models.PracticeSession) error {
 	query := `
-		INSERT INTO practice_sessions (user_id, program_id, device_info)
-		VALUES ($1, $2, $3)
+		INSERT INTO practice_sessions (user_id, program_id, device_info, experiment_id, variant)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, started_at
 	`
 	return r.db.QueryRow(ctx, query,
 		session.UserID,
 		session.ProgramID,
 		session.DeviceInfo,
+		session.ExperimentID,
+		session.Variant,
 	).Scan(&session.ID, &session.StartedAt)
 }
 
 func (r *SessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PracticeSession, error) {
+	return r.getByID(ctx, id, false)
+}
+
+// GetDeletedByID retrieves a soft-deleted session, e.g. to restore it
+func (r *SessionRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*models.PracticeSession, error) {
+	return r.getByID(ctx, id, true)
+}
+
+func (r *SessionRepository) getByID(ctx context.Context, id uuid.UUID, deleted bool) (*models.PracticeSession, error) {
 	var session models.PracticeSession
 	query := `
 		SELECT id, user_id, program_id, started_at, completed_at,
-		       total_duration_seconds, completion_rate, notes, device_info
+		       total_duration_seconds, completion_rate, notes, device_info,
+		       mood, energy, pain_flags, tags,
+		       heart_rate_min, heart_rate_avg, heart_rate_max, hrv_avg, deleted_at,
+		       experiment_id, variant, edited_at, edited_by
 		FROM practice_sessions
-		WHERE id = $1
+		WHERE id = $1 AND (deleted_at IS NOT NULL) = $2
 	`
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&session.ID,
-		&session.UserID,
-		&session.ProgramID,
-		&session.StartedAt,
-		&session.CompletedAt,
-		&session.TotalDurationSeconds,
-		&session.CompletionRate,
-		&session.Notes,
-		&session.DeviceInfo,
-	)
+	err := database.Retry(ctx, "sessions.GetByID", func() error {
+		return r.db.QueryRow(ctx, query, id, deleted).Scan(
+			&session.ID,
+			&session.UserID,
+			&session.ProgramID,
+			&session.StartedAt,
+			&session.CompletedAt,
+			&session.TotalDurationSeconds,
+			&session.CompletionRate,
+			&session.Notes,
+			&session.DeviceInfo,
+			&session.Mood,
+			&session.Energy,
+			&session.PainFlags,
+			&session.Tags,
+			&session.HeartRateMin,
+			&session.HeartRateAvg,
+			&session.HeartRateMax,
+			&session.HRVAvg,
+			&session.DeletedAt,
+			&session.ExperimentID,
+			&session.Variant,
+			&session.EditedAt,
+			&session.EditedBy,
+		)
+	})
 	if err == pgx.ErrNoRows {
 		return nil, nil
 	}
@@ -63,27 +111,24 @@ func (r *SessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.
 func (r *SessionRepository) List(ctx context.Context, userID uuid.UUID, programID *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.PracticeSession, error) {
 	query := `
 		SELECT ps.id, ps.user_id, ps.program_id, p.name as program_name, ps.started_at, ps.completed_at,
-		       ps.total_duration_seconds, ps.completion_rate, ps.notes, ps.device_info
+		       ps.total_duration_seconds, ps.completion_rate, ps.notes, ps.device_info,
+		       ps.mood, ps.energy, ps.pain_flags, ps.tags,
+		       ps.heart_rate_min, ps.heart_rate_avg, ps.heart_rate_max, ps.hrv_avg,
+		       ps.experiment_id, ps.variant, ps.edited_at, ps.edited_by
 		FROM practice_sessions ps
 		LEFT JOIN programs p ON ps.program_id = p.id
 		WHERE ps.user_id = $1
+		AND ps.deleted_at IS NULL
 		AND ($2::uuid IS NULL OR ps.program_id = $2)
 		AND ($3::timestamp IS NULL OR ps.started_at >= $3)
 		AND ($4::timestamp IS NULL OR ps.started_at <= $4)
 		ORDER BY ps.started_at DESC
 		LIMIT $5 OFFSET $6
 	`
-	rows, err := r.db.Query(ctx, query, userID, programID, startDate, endDate, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	sessions := make([]models.PracticeSession, 0)
-	for rows.Next() {
+	return collectWithRetry(ctx, r.db, "sessions.List", query, func(row pgx.CollectableRow) (models.PracticeSession, error) {
 		var session models.PracticeSession
 		var programName sql.NullString
-		err := rows.Scan(
+		err := row.Scan(
 			&session.ID,
 			&session.UserID,
 			&session.ProgramID,
@@ -94,42 +139,153 @@ func (r *SessionRepository) List(ctx context.Context, userID uuid.UUID, programI
 			&session.CompletionRate,
 			&session.Notes,
 			&session.DeviceInfo,
+			&session.Mood,
+			&session.Energy,
+			&session.PainFlags,
+			&session.Tags,
+			&session.HeartRateMin,
+			&session.HeartRateAvg,
+			&session.HeartRateMax,
+			&session.HRVAvg,
+			&session.ExperimentID,
+			&session.Variant,
+			&session.EditedAt,
+			&session.EditedBy,
 		)
-		if err != nil {
-			return nil, err
-		}
 		if programName.Valid {
 			session.ProgramName = &programName.String
 		}
-		sessions = append(sessions, session)
+		return session, err
+	}, userID, programID, startDate, endDate, limit, offset)
+}
+
+func (r *SessionRepository) Complete(ctx context.Context, sessionID uuid.UUID, totalDuration int, completionRate float64, notes string, completedAt *time.Time, wellbeing *models.SessionWellbeing) error {
+	if wellbeing == nil {
+		wellbeing = &models.SessionWellbeing{}
 	}
 
-	return sessions, rows.Err()
+	// Use the provided completion time, falling back to the current timestamp
+	query := `
+		UPDATE practice_sessions
+		SET completed_at = COALESCE($1::timestamp, CURRENT_TIMESTAMP),
+		    total_duration_seconds = $2, completion_rate = $3, notes = $4,
+		    mood = $5, energy = $6, pain_flags = COALESCE($7, '{}'), tags = COALESCE($8, '{}')
+		WHERE id = $9
+	`
+	_, err := r.db.Exec(ctx, query,
+		completedAt,
+		totalDuration,
+		completionRate,
+		notes,
+		wellbeing.Mood,
+		wellbeing.Energy,
+		wellbeing.PainFlags,
+		wellbeing.Tags,
+		sessionID,
+	)
+	return err
 }
 
-func (r *SessionRepository) Complete(ctx context.Context, sessionID uuid.UUID, totalDuration int, completionRate float64, notes string, completedAt *time.Time) error {
-	var query string
-	var err error
-
-	if completedAt != nil {
-		// Use the provided completion time
-		query = `
-			UPDATE practice_sessions
-			SET completed_at = $1, total_duration_seconds = $2, completion_rate = $3, notes = $4
-			WHERE id = $5
-		`
-		_, err = r.db.Exec(ctx, query, completedAt, totalDuration, completionRate, notes, sessionID)
-	} else {
-		// Use current timestamp
-		query = `
-			UPDATE practice_sessions
-			SET completed_at = CURRENT_TIMESTAMP, total_duration_seconds = $1, completion_rate = $2, notes = $3
-			WHERE id = $4
-		`
-		_, err = r.db.Exec(ctx, query, totalDuration, completionRate, notes, sessionID)
+// Update applies corrections to a session, flags it as edited and records the audit entry in
+// the same transaction
+func (r *SessionRepository) Update(ctx context.Context, sessionID uuid.UUID, update *models.SessionUpdate, edit *models.SessionEdit) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback(ctx)
 
-	return err
+	updateQuery := `
+		UPDATE practice_sessions
+		SET notes = COALESCE($1, notes),
+		    total_duration_seconds = COALESCE($2, total_duration_seconds),
+		    completion_rate = COALESCE($3, completion_rate),
+		    completed_at = COALESCE($4, completed_at),
+		    edited_at = $6,
+		    edited_by = $7
+		WHERE id = $5 AND deleted_at IS NULL
+	`
+	result, err := tx.Exec(ctx, updateQuery,
+		update.Notes,
+		update.TotalDurationSeconds,
+		update.CompletionRate,
+		update.CompletedAt,
+		sessionID,
+		r.clock.Now(),
+		edit.EditorID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if err := r.createEdit(ctx, tx, edit); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UpdateExerciseLog applies corrections to a logged exercise, flags it as edited and records
+// the audit entry on its session in the same transaction
+func (r *SessionRepository) UpdateExerciseLog(ctx context.Context, logID uuid.UUID, update *models.ExerciseLogUpdate, edit *models.SessionEdit) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE exercise_logs
+		SET actual_duration_seconds = COALESCE($2, actual_duration_seconds),
+		    repetitions_completed = COALESCE($3, repetitions_completed),
+		    skipped = COALESCE($4, skipped),
+		    notes = COALESCE($5, notes),
+		    edited_at = $6,
+		    edited_by = $7
+		WHERE id = $1
+	`, logID, update.ActualDurationSeconds, update.RepetitionsCompleted, update.Skipped, update.Notes, r.clock.Now(), edit.EditorID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if err := r.createEdit(ctx, tx, edit); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *SessionRepository) createEdit(ctx context.Context, tx pgx.Tx, edit *models.SessionEdit) error {
+	editQuery := `
+		INSERT INTO session_edits (session_id, exercise_log_id, editor_id, changes, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	return tx.QueryRow(ctx, editQuery, edit.SessionID, edit.ExerciseLogID, edit.EditorID, edit.Changes, edit.Reason, r.clock.Now()).Scan(&edit.ID, &edit.CreatedAt)
+}
+
+// ListEdits returns the edit history of a session and its exercise logs, oldest first
+func (r *SessionRepository) ListEdits(ctx context.Context, sessionID uuid.UUID) ([]models.SessionEdit, error) {
+	query := `
+		SELECT e.id, e.session_id, e.exercise_log_id, e.editor_id, u.full_name, e.changes, e.reason, e.created_at
+		FROM session_edits e
+		LEFT JOIN users u ON u.id = e.editor_id
+		WHERE e.session_id = $1
+		ORDER BY e.created_at ASC
+	`
+	edits, err := collectWithRetry(ctx, r.db, "sessions.ListEdits", query, func(row pgx.CollectableRow) (models.SessionEdit, error) {
+		var e models.SessionEdit
+		err := row.Scan(&e.ID, &e.SessionID, &e.ExerciseLogID, &e.EditorID, &e.EditorName, &e.Changes, &e.Reason, &e.CreatedAt)
+		return e, err
+	}, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session edits: %w", err)
+	}
+	return edits, nil
 }
 
 func (r *SessionRepository) CreateExerciseLog(ctx context.Context, log *models.ExerciseLog) error {
@@ -137,9 +293,9 @@ func (r *SessionRepository) CreateExerciseLog(ctx context.Context, log *models.E
 		INSERT INTO exercise_logs (
 			session_id, exercise_id, started_at, completed_at,
 			planned_duration_seconds, actual_duration_seconds,
-			repetitions_planned, repetitions_completed, skipped, notes
+			repetitions_planned, repetitions_completed, skipped, notes, substitute_id
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id
 	`
 	return r.db.QueryRow(ctx, query,
@@ -153,18 +309,40 @@ func (r *SessionRepository) CreateExerciseLog(ctx context.Context, log *models.E
 		log.RepetitionsCompleted,
 		log.Skipped,
 		log.Notes,
+		log.SubstituteID,
 	).Scan(&log.ID)
 }
 
+const exerciseLogColumns = `id, session_id, exercise_id, started_at, completed_at,
+	planned_duration_seconds, actual_duration_seconds,
+	repetitions_planned, repetitions_completed, skipped, notes, substitute_id, edited_at, edited_by`
+
+func scanExerciseLog(row pgx.Row) (*models.ExerciseLog, error) {
+	var log models.ExerciseLog
+	err := row.Scan(
+		&log.ID,
+		&log.SessionID,
+		&log.ExerciseID,
+		&log.StartedAt,
+		&log.CompletedAt,
+		&log.PlannedDurationSeconds,
+		&log.ActualDurationSeconds,
+		&log.RepetitionsPlanned,
+		&log.RepetitionsCompleted,
+		&log.Skipped,
+		&log.Notes,
+		&log.SubstituteID,
+		&log.EditedAt,
+		&log.EditedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
 func (r *SessionRepository) GetExerciseLogs(ctx context.Context, sessionID uuid.UUID) ([]models.ExerciseLog, error) {
-	query := `
-		SELECT id, session_id, exercise_id, started_at, completed_at,
-		       planned_duration_seconds, actual_duration_seconds,
-		       repetitions_planned, repetitions_completed, skipped, notes
-		FROM exercise_logs
-		WHERE session_id = $1
-		ORDER BY started_at ASC
-	`
+	query := `SELECT ` + exerciseLogColumns + ` FROM exercise_logs WHERE session_id = $1 ORDER BY started_at ASC`
 	rows, err := r.db.Query(ctx, query, sessionID)
 	if err != nil {
 		return nil, err
@@ -173,29 +351,35 @@ func (r *SessionRepository) GetExerciseLogs(ctx context.Context, sessionID uuid.
 
 	logs := make([]models.ExerciseLog, 0)
 	for rows.Next() {
-		var log models.ExerciseLog
-		err := rows.Scan(
-			&log.ID,
-			&log.SessionID,
-			&log.ExerciseID,
-			&log.StartedAt,
-			&log.CompletedAt,
-			&log.PlannedDurationSeconds,
-			&log.ActualDurationSeconds,
-			&log.RepetitionsPlanned,
-			&log.RepetitionsCompleted,
-			&log.Skipped,
-			&log.Notes,
-		)
+		log, err := scanExerciseLog(rows)
 		if err != nil {
 			return nil, err
 		}
-		logs = append(logs, log)
+		logs = append(logs, *log)
 	}
 
 	return logs, rows.Err()
 }
 
+// GetExerciseLog returns a logged exercise, or nil if it does not exist
+func (r *SessionRepository) GetExerciseLog(ctx context.Context, id uuid.UUID) (*models.ExerciseLog, error) {
+	query := `SELECT ` + exerciseLogColumns + ` FROM exercise_logs WHERE id = $1`
+
+	var log *models.ExerciseLog
+	err := database.Retry(ctx, "sessions.GetExerciseLog", func() error {
+		var err error
+		log, err = scanExerciseLog(r.db.QueryRow(ctx, query, id))
+		return err
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exercise log: %w", err)
+	}
+	return log, nil
+}
+
 func (r *SessionRepository) GetStats(ctx context.Context, userID uuid.UUID) (*models.SessionStats, error) {
 	var stats models.SessionStats
 
@@ -205,49 +389,41 @@ func (r *SessionRepository) GetStats(ctx context.Context, userID uuid.UUID) (*mo
 			COUNT(*) as total_sessions,
 			COUNT(completed_at) as completed_sessions,
 			COALESCE(SUM(total_duration_seconds), 0) / 60 as total_duration_minutes,
-			COALESCE(AVG(completion_rate), 0) as avg_completion_rate
+			COALESCE(AVG(completion_rate), 0) as avg_completion_rate,
+			AVG(heart_rate_avg) as avg_heart_rate,
+			MAX(heart_rate_max)::int as peak_heart_rate
 		FROM practice_sessions
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 	`
 	err := r.db.QueryRow(ctx, query, userID).Scan(
 		&stats.TotalSessions,
 		&stats.CompletedSessions,
 		&stats.TotalDurationMinutes,
 		&stats.AverageCompletionRate,
+		&stats.AverageHeartRate,
+		&stats.PeakHeartRate,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate current and longest streak
-	streakQuery := `
-		WITH daily_sessions AS (
-			SELECT DISTINCT DATE(started_at) as session_date
-			FROM practice_sessions
-			WHERE user_id = $1 AND completed_at IS NOT NULL
-			ORDER BY session_date DESC
-		),
-		streak_groups AS (
-			SELECT
-				session_date,
-				session_date - (ROW_NUMBER() OVER (ORDER BY session_date))::int AS streak_group
-			FROM daily_sessions
-		),
-		streaks AS (
-			SELECT COUNT(*) as streak_length
-			FROM streak_groups
-			GROUP BY streak_group
-			ORDER BY MIN(session_date) DESC
-		)
-		SELECT
-			COALESCE((SELECT streak_length FROM streaks LIMIT 1), 0) as current_streak,
-			COALESCE(MAX(streak_length), 0) as longest_streak
-		FROM streaks
+	// Live classes count separately from solo practice
+	classQuery := `
+		SELECT COUNT(*),
+		       COALESCE(SUM(EXTRACT(EPOCH FROM (c.ends_at - c.starts_at))), 0)::int / 60
+		FROM class_attendance a
+		JOIN live_classes c ON c.id = a.class_id
+		WHERE a.user_id = $1 AND a.status IN ('present', 'late')
 	`
-	err = r.db.QueryRow(ctx, streakQuery, userID).Scan(
-		&stats.CurrentStreak,
-		&stats.LongestStreak,
-	)
+	if err := r.db.QueryRow(ctx, classQuery, userID).Scan(&stats.ClassesAttended, &stats.ClassMinutes); err != nil {
+		return nil, err
+	}
+
+	stats.ByMood, err = r.getWellbeingStats(ctx, userID, "mood")
+	if err != nil {
+		return nil, err
+	}
+	stats.ByEnergy, err = r.getWellbeingStats(ctx, userID, "energy")
 	if err != nil {
 		return nil, err
 	}
@@ -255,16 +431,202 @@ func (r *SessionRepository) GetStats(ctx context.Context, userID uuid.UUID) (*mo
 	return &stats, nil
 }
 
-func (r *SessionRepository) Delete(ctx context.Context, sessionID uuid.UUID) error {
-	// Delete exercise logs first (foreign key constraint)
-	_, err := r.db.Exec(ctx, `DELETE FROM exercise_logs WHERE session_id = $1`, sessionID)
+// GetPracticeDays returns the minutes of completed practice on each day the user completed a
+// session, oldest first. Sessions started within graceHours after midnight count for the day before.
+func (r *SessionRepository) GetPracticeDays(ctx context.Context, userID uuid.UUID, graceHours int) ([]streaks.Day, error) {
+	query := `
+		SELECT DATE(started_at - make_interval(hours => $2)) AS practice_date,
+		       COALESCE(SUM(total_duration_seconds), 0) / 60 AS minutes
+		FROM practice_sessions
+		WHERE user_id = $1 AND completed_at IS NOT NULL AND deleted_at IS NULL
+		GROUP BY practice_date
+		ORDER BY practice_date
+	`
+	return collectWithRetry(ctx, r.db, "sessions.GetPracticeDays", query, func(row pgx.CollectableRow) (streaks.Day, error) {
+		var day streaks.Day
+		err := row.Scan(&day.Date, &day.Minutes)
+		return day, err
+	}, userID, graceHours)
+}
+
+// ListProgramIDs returns the programs the user has sessions of, including deleted ones
+func (r *SessionRepository) ListProgramIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return collectWithRetry(ctx, r.db, "sessions.ListProgramIDs", `SELECT DISTINCT program_id FROM practice_sessions WHERE user_id = $1 AND program_id IS NOT NULL`, pgx.RowTo[uuid.UUID], userID)
+}
+
+// GetProgramTotals counts the user's completed sessions of a program, overall and since the
+// given time, and returns when they last completed one
+func (r *SessionRepository) GetProgramTotals(ctx context.Context, userID, programID uuid.UUID, since time.Time) (completed, recent int, lastCompletedAt *time.Time, err error) {
+	query := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE completed_at >= $3), MAX(completed_at)
+		FROM practice_sessions
+		WHERE user_id = $1 AND program_id = $2 AND completed_at IS NOT NULL AND deleted_at IS NULL
+	`
+	err = database.Retry(ctx, "sessions.GetProgramTotals", func() error {
+		return r.db.QueryRow(ctx, query, userID, programID, since).Scan(&completed, &recent, &lastCompletedAt)
+	})
+	return completed, recent, lastCompletedAt, err
+}
+
+// GetExerciseProgress counts, for each exercise of the program, the user's completed sessions
+// it was done, skipped or substituted in
+func (r *SessionRepository) GetExerciseProgress(ctx context.Context, userID, programID uuid.UUID) ([]models.ExerciseProgress, error) {
+	query := `
+		SELECT e.id, e.name,
+		       COUNT(DISTINCT l.session_id) FILTER (WHERE NOT l.skipped),
+		       COUNT(DISTINCT l.session_id) FILTER (WHERE l.skipped),
+		       COUNT(DISTINCT l.session_id) FILTER (WHERE NOT l.skipped AND l.substitute_id IS NOT NULL),
+		       MAX(l.completed_at) FILTER (WHERE NOT l.skipped)
+		FROM exercises e
+		LEFT JOIN exercise_logs l ON l.exercise_id = e.id AND l.session_id IN (
+			SELECT id FROM practice_sessions
+			WHERE user_id = $1 AND program_id = $2 AND completed_at IS NOT NULL AND deleted_at IS NULL
+		)
+		WHERE e.program_id = $2
+		GROUP BY e.id, e.name, e.order_index
+		ORDER BY e.order_index
+	`
+	return collectWithRetry(ctx, r.db, "sessions.GetExerciseProgress", query, func(row pgx.CollectableRow) (models.ExerciseProgress, error) {
+		var e models.ExerciseProgress
+		err := row.Scan(&e.ExerciseID, &e.Name, &e.TimesCompleted, &e.TimesSkipped, &e.TimesSubstituted, &e.LastCompletedAt)
+		return e, err
+	}, userID, programID)
+}
+
+// GetProgramAdoption counts the program's active assignments and, across all users, its
+// practicing users, started and completed sessions and the completed sessions' average rate
+func (r *SessionRepository) GetProgramAdoption(ctx context.Context, programID uuid.UUID) (*models.ProgramAdoption, error) {
+	query := `
+		SELECT (SELECT COUNT(*) FROM user_programs WHERE program_id = $1 AND is_active),
+		       COUNT(DISTINCT user_id), COUNT(*), COUNT(*) FILTER (WHERE completed_at IS NOT NULL),
+		       AVG(completion_rate) FILTER (WHERE completed_at IS NOT NULL)
+		FROM practice_sessions
+		WHERE program_id = $1 AND deleted_at IS NULL
+	`
+	adoption := &models.ProgramAdoption{ProgramID: programID}
+	err := database.Retry(ctx, "sessions.GetProgramAdoption", func() error {
+		return r.db.QueryRow(ctx, query, programID).Scan(
+			&adoption.AssignedUsers,
+			&adoption.PracticingUsers,
+			&adoption.SessionsStarted,
+			&adoption.SessionsCompleted,
+			&adoption.AverageCompletionRate,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adoption, nil
+}
+
+// GetExerciseAdoption counts, for each exercise of the program in order, the sessions of all
+// users that reached it and that skipped it. DropOffRate is left for the caller.
+func (r *SessionRepository) GetExerciseAdoption(ctx context.Context, programID uuid.UUID) ([]models.ExerciseAdoption, error) {
+	query := `
+		SELECT e.id, e.name,
+		       COUNT(DISTINCT l.session_id),
+		       COUNT(DISTINCT l.session_id) FILTER (WHERE l.skipped)
+		FROM exercises e
+		LEFT JOIN exercise_logs l ON l.exercise_id = e.id AND l.session_id IN (
+			SELECT id FROM practice_sessions WHERE program_id = $1 AND deleted_at IS NULL
+		)
+		WHERE e.program_id = $1
+		GROUP BY e.id, e.name, e.order_index
+		ORDER BY e.order_index
+	`
+	return collectWithRetry(ctx, r.db, "sessions.GetExerciseAdoption", query, func(row pgx.CollectableRow) (models.ExerciseAdoption, error) {
+		var e models.ExerciseAdoption
+		err := row.Scan(&e.ExerciseID, &e.Name, &e.TimesReached, &e.TimesSkipped)
+		return e, err
+	}, programID)
+}
+
+// GetPeriodTotals counts a user's sessions completed in [from, to) and their total minutes
+func (r *SessionRepository) GetPeriodTotals(ctx context.Context, userID uuid.UUID, from, to time.Time) (sessions, minutes int, err error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(total_duration_seconds), 0) / 60
+		FROM practice_sessions
+		WHERE user_id = $1 AND deleted_at IS NULL
+		  AND completed_at >= $2 AND completed_at < $3
+	`
+	err = r.db.QueryRow(ctx, query, userID, from, to).Scan(&sessions, &minutes)
+	return sessions, minutes, err
+}
+
+// getWellbeingStats groups a user's completed sessions by a self-reported level column.
+// column must be one of the fixed wellbeing columns, it is never user input.
+func (r *SessionRepository) getWellbeingStats(ctx context.Context, userID uuid.UUID, column string) ([]models.WellbeingStat, error) {
+	query := fmt.Sprintf(`
+		SELECT %[1]s as level,
+		       COUNT(*) as sessions,
+		       COALESCE(AVG(completion_rate), 0) as avg_completion_rate,
+		       COALESCE(AVG(total_duration_seconds), 0) / 60 as avg_duration_minutes
+		FROM practice_sessions
+		WHERE user_id = $1 AND completed_at IS NOT NULL AND deleted_at IS NULL AND %[1]s IS NOT NULL
+		GROUP BY %[1]s
+		ORDER BY %[1]s
+	`, column)
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]models.WellbeingStat, 0)
+	for rows.Next() {
+		var stat models.WellbeingStat
+		if err := rows.Scan(&stat.Level, &stat.Sessions, &stat.AverageCompletionRate, &stat.AverageDurationMin); err != nil {
+			return nil, err
+		}
+		result = append(result, stat)
+	}
+
+	return result, rows.Err()
+}
+
+// SoftDelete marks a session as deleted. Exercise logs are kept so the session can be restored.
+func (r *SessionRepository) SoftDelete(ctx context.Context, sessionID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `
+		UPDATE practice_sessions
+		SET deleted_at = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`, sessionID, r.clock.Now())
 	if err != nil {
 		return err
 	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
 
-	// Delete session
-	_, err = r.db.Exec(ctx, `DELETE FROM practice_sessions WHERE id = $1`, sessionID)
-	return err
+// Restore clears the deleted_at timestamp of a soft-deleted session
+func (r *SessionRepository) Restore(ctx context.Context, sessionID uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `
+		UPDATE practice_sessions
+		SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`, sessionID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// PurgeDeleted permanently removes sessions soft-deleted before the given time.
+// Exercise logs, notes and biometrics are removed by ON DELETE CASCADE.
+func (r *SessionRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, `
+		DELETE FROM practice_sessions
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+	`, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 // ListByUserID retrieves sessions for a specific user with optional filtering
@@ -272,10 +634,14 @@ func (r *SessionRepository) Delete(ctx context.Context, sessionID uuid.UUID) err
 func (r *SessionRepository) ListByUserID(ctx context.Context, userID uuid.UUID, programID *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.PracticeSession, error) {
 	query := `
 		SELECT ps.id, ps.user_id, ps.program_id, p.name as program_name, ps.started_at, ps.completed_at,
-		       ps.total_duration_seconds, ps.completion_rate, ps.notes, ps.device_info
+		       ps.total_duration_seconds, ps.completion_rate, ps.notes, ps.device_info,
+		       ps.mood, ps.energy, ps.pain_flags, ps.tags,
+		       ps.heart_rate_min, ps.heart_rate_avg, ps.heart_rate_max, ps.hrv_avg,
+		       ps.experiment_id, ps.variant, ps.edited_at, ps.edited_by
 		FROM practice_sessions ps
 		LEFT JOIN programs p ON ps.program_id = p.id
 		WHERE ps.user_id = $1
+		AND ps.deleted_at IS NULL
 		AND ($2::uuid IS NULL OR ps.program_id = $2)
 		AND ($3::timestamp IS NULL OR ps.started_at >= $3)
 		AND ($4::timestamp IS NULL OR ps.started_at <= $4)
@@ -303,6 +669,18 @@ func (r *SessionRepository) ListByUserID(ctx context.Context, userID uuid.UUID,
 			&session.CompletionRate,
 			&session.Notes,
 			&session.DeviceInfo,
+			&session.Mood,
+			&session.Energy,
+			&session.PainFlags,
+			&session.Tags,
+			&session.HeartRateMin,
+			&session.HeartRateAvg,
+			&session.HeartRateMax,
+			&session.HRVAvg,
+			&session.ExperimentID,
+			&session.Variant,
+			&session.EditedAt,
+			&session.EditedBy,
 		)
 		if err != nil {
 			return nil, err
@@ -315,3 +693,275 @@ func (r *SessionRepository) ListByUserID(ctx context.Context, userID uuid.UUID,
 
 	return sessions, rows.Err()
 }
+
+// ListAll retrieves the sessions of all users, newest first, with the names of their user and
+// program, for the admin activity feed
+func (r *SessionRepository) ListAll(ctx context.Context, filter models.SessionFilter, limit, offset int) ([]models.PracticeSession, error) {
+	query := `
+		SELECT ps.id, ps.user_id, u.full_name, ps.program_id, p.name as program_name, ps.started_at, ps.completed_at,
+		       ps.total_duration_seconds, ps.completion_rate, ps.notes, ps.device_info,
+		       ps.mood, ps.energy, ps.pain_flags, ps.tags,
+		       ps.heart_rate_min, ps.heart_rate_avg, ps.heart_rate_max, ps.hrv_avg,
+		       ps.experiment_id, ps.variant, ps.edited_at, ps.edited_by
+		FROM practice_sessions ps
+		JOIN users u ON ps.user_id = u.id
+		LEFT JOIN programs p ON ps.program_id = p.id
+		WHERE ps.deleted_at IS NULL
+		AND ($1::uuid IS NULL OR ps.user_id = $1)
+		AND ($2::uuid IS NULL OR ps.program_id = $2)
+		AND ($3::timestamp IS NULL OR ps.started_at >= $3)
+		AND ($4::timestamp IS NULL OR ps.started_at <= $4)
+		AND CASE $5::text
+			WHEN 'completed' THEN ps.completed_at IS NOT NULL
+			WHEN 'in_progress' THEN ps.completed_at IS NULL AND ps.started_at > $6
+			WHEN 'abandoned' THEN ps.completed_at IS NULL AND ps.started_at <= $6
+			ELSE TRUE
+		END
+		ORDER BY ps.started_at DESC, ps.id
+		LIMIT $7 OFFSET $8
+	`
+	var status *string
+	if filter.Status != nil {
+		s := string(*filter.Status)
+		status = &s
+	}
+	abandonedBefore := r.clock.Now().Add(-models.SessionAbandonedAfter)
+
+	return collectWithRetry(ctx, r.db, "sessions.ListAll", query, func(row pgx.CollectableRow) (models.PracticeSession, error) {
+		var session models.PracticeSession
+		var userName string
+		var programName sql.NullString
+		err := row.Scan(
+			&session.ID,
+			&session.UserID,
+			&userName,
+			&session.ProgramID,
+			&programName,
+			&session.StartedAt,
+			&session.CompletedAt,
+			&session.TotalDurationSeconds,
+			&session.CompletionRate,
+			&session.Notes,
+			&session.DeviceInfo,
+			&session.Mood,
+			&session.Energy,
+			&session.PainFlags,
+			&session.Tags,
+			&session.HeartRateMin,
+			&session.HeartRateAvg,
+			&session.HeartRateMax,
+			&session.HRVAvg,
+			&session.ExperimentID,
+			&session.Variant,
+			&session.EditedAt,
+			&session.EditedBy,
+		)
+		session.UserName = &userName
+		if programName.Valid {
+			session.ProgramName = &programName.String
+		}
+		return session, err
+	}, filter.UserID, filter.ProgramID, filter.StartDate, filter.EndDate, status, abandonedBefore, limit, offset)
+}
+
+// CreateNote attaches an instructor note to a session
+func (r *SessionRepository) CreateNote(ctx context.Context, note *models.SessionNote) error {
+	query := `
+		INSERT INTO session_notes (session_id, author_id, content, visibility)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(ctx, query,
+		note.SessionID,
+		note.AuthorID,
+		note.Content,
+		note.Visibility,
+	).Scan(&note.ID, &note.CreatedAt)
+}
+
+// ListNotesByUser returns the latest instructor notes on any of the user's sessions, newest first
+func (r *SessionRepository) ListNotesByUser(ctx context.Context, userID uuid.UUID, limit int) ([]models.SessionNote, error) {
+	query := `
+		SELECT n.id, n.session_id, n.author_id, u.full_name as author_name,
+		       n.content, n.visibility, n.created_at
+		FROM session_notes n
+		JOIN practice_sessions s ON s.id = n.session_id
+		JOIN users u ON n.author_id = u.id
+		WHERE s.user_id = $1 AND s.deleted_at IS NULL
+		ORDER BY n.created_at DESC
+		LIMIT $2
+	`
+	return collectWithRetry(ctx, r.db, "sessions.ListNotesByUser", query, func(row pgx.CollectableRow) (models.SessionNote, error) {
+		var note models.SessionNote
+		err := row.Scan(&note.ID, &note.SessionID, &note.AuthorID, &note.AuthorName, &note.Content, &note.Visibility, &note.CreatedAt)
+		return note, err
+	}, userID, limit)
+}
+
+// ListNotes retrieves notes for a session, optionally restricted to shared notes
+func (r *SessionRepository) ListNotes(ctx context.Context, sessionID uuid.UUID, sharedOnly bool) ([]models.SessionNote, error) {
+	query := `
+		SELECT n.id, n.session_id, n.author_id, u.full_name as author_name,
+		       n.content, n.visibility, n.created_at
+		FROM session_notes n
+		JOIN users u ON n.author_id = u.id
+		WHERE n.session_id = $1
+		AND ($2 = false OR n.visibility = 'shared')
+		ORDER BY n.created_at ASC
+	`
+	rows, err := r.db.Query(ctx, query, sessionID, sharedOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := make([]models.SessionNote, 0)
+	for rows.Next() {
+		var note models.SessionNote
+		err := rows.Scan(
+			&note.ID,
+			&note.SessionID,
+			&note.AuthorID,
+			&note.AuthorName,
+			&note.Content,
+			&note.Visibility,
+			&note.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+
+	return notes, rows.Err()
+}
+
+// AddBiometrics stores wearable samples for a session and refreshes the
+// min/avg/max summary on the session. Re-sent samples are ignored.
+// The samples are stored encrypted as one list per session, so the list is
+// locked, merged and summarized here; only the summary stays queryable.
+func (r *SessionRepository) AddBiometrics(ctx context.Context, sessionID uuid.UUID, samples []models.BiometricSample) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	lockQuery := `
+		INSERT INTO session_biometric_samples (session_id) VALUES ($1)
+		ON CONFLICT (session_id) DO UPDATE SET session_id = EXCLUDED.session_id
+		RETURNING samples
+	`
+	var value string
+	if err := tx.QueryRow(ctx, lockQuery, sessionID).Scan(&value); err != nil {
+		return 0, err
+	}
+	var stored []models.BiometricSample
+	if err := sessionBiometricsColumn.decryptJSON(r.keyring, sessionID, value, &stored); err != nil {
+		return 0, err
+	}
+
+	merged, added := mergeBiometrics(stored, samples)
+	if value, err = sessionBiometricsColumn.encryptJSON(r.keyring, sessionID, merged); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE session_biometric_samples SET samples = $2 WHERE session_id = $1`, sessionID, value); err != nil {
+		return 0, err
+	}
+
+	summary := summarizeBiometrics(merged)
+	summaryQuery := `
+		UPDATE practice_sessions
+		SET heart_rate_min = $2, heart_rate_avg = $3, heart_rate_max = $4, hrv_avg = $5
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, summaryQuery, sessionID,
+		summary.HeartRateMin, summary.HeartRateAvg, summary.HeartRateMax, summary.HRVAvg); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return added, nil
+}
+
+// mergeBiometrics adds the samples not yet stored by time, in UTC, and returns all samples in
+// chronological order with the number added
+func mergeBiometrics(stored, samples []models.BiometricSample) ([]models.BiometricSample, int) {
+	seen := make(map[int64]bool, len(stored)+len(samples))
+	for _, sample := range stored {
+		seen[sample.RecordedAt.UnixMicro()] = true
+	}
+	merged := stored
+	for _, sample := range samples {
+		sample.RecordedAt = sample.RecordedAt.UTC().Truncate(time.Microsecond)
+		if seen[sample.RecordedAt.UnixMicro()] {
+			continue
+		}
+		seen[sample.RecordedAt.UnixMicro()] = true
+		merged = append(merged, sample)
+	}
+	slices.SortFunc(merged, func(a, b models.BiometricSample) int {
+		return a.RecordedAt.Compare(b.RecordedAt)
+	})
+	return merged, len(merged) - len(stored)
+}
+
+// biometricSummary is the min/avg/max of a session's samples, nil without samples
+type biometricSummary struct {
+	HeartRateMin *int
+	HeartRateAvg *float64
+	HeartRateMax *int
+	HRVAvg       *float64
+}
+
+func summarizeBiometrics(samples []models.BiometricSample) biometricSummary {
+	var summary biometricSummary
+	var hrSum, hrvSum float64
+	var hrCount, hrvCount int
+	for _, sample := range samples {
+		if hr := sample.HeartRate; hr != nil {
+			if summary.HeartRateMin == nil || *hr < *summary.HeartRateMin {
+				summary.HeartRateMin = hr
+			}
+			if summary.HeartRateMax == nil || *hr > *summary.HeartRateMax {
+				summary.HeartRateMax = hr
+			}
+			hrSum += float64(*hr)
+			hrCount++
+		}
+		if sample.HRVMs != nil {
+			hrvSum += *sample.HRVMs
+			hrvCount++
+		}
+	}
+	if hrCount > 0 {
+		avg := hrSum / float64(hrCount)
+		summary.HeartRateAvg = &avg
+	}
+	if hrvCount > 0 {
+		avg := hrvSum / float64(hrvCount)
+		summary.HRVAvg = &avg
+	}
+	return summary
+}
+
+// GetBiometrics retrieves the raw samples for a session in chronological order
+func (r *SessionRepository) GetBiometrics(ctx context.Context, sessionID uuid.UUID) ([]models.BiometricSample, error) {
+	query := `SELECT samples FROM session_biometric_samples WHERE session_id = $1`
+	var value string
+	err := database.Retry(ctx, "session_biometric_samples.Get", func() error {
+		return r.db.QueryRow(ctx, query, sessionID).Scan(&value)
+	})
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, err
+	}
+
+	samples := make([]models.BiometricSample, 0)
+	if err := sessionBiometricsColumn.decryptJSON(r.keyring, sessionID, value, &samples); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
@@ -1,38 +1,42 @@
 package repositories
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/fieldcrypt"
 	"github.com/xuangong/backend/pkg/testutil"
 )
 
 var _ = time.Now // prevent unused import error
 
 func TestSessionRepository_ListByUserID(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSessionRepository(pool)
+	repo := NewSessionRepository(db)
 	ctx := context.Background()
 
 	// Setup test data
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student1 := testutil.CreateTestStudent(t, pool, "student1@test.com")
-	student2 := testutil.CreateTestStudent(t, pool, "student2@test.com")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student1 := testutil.NewUserBuilder().WithEmail("student1@test.com").Create(t, db)
+	student2 := testutil.NewUserBuilder().WithEmail("student2@test.com").Create(t, db)
 
-	program1 := testutil.CreateTestProgram(t, pool, admin.ID, "Program 1")
-	program2 := testutil.CreateTestProgram(t, pool, admin.ID, "Program 2")
+	program1 := testutil.NewProgramBuilder().WithName("Program 1").OwnedBy(admin).Create(t, db)
+	program2 := testutil.NewProgramBuilder().WithName("Program 2").OwnedBy(admin).Create(t, db)
 
 	// Create sessions for student1
-	session1 := testutil.CreateTestSession(t, pool, student1.ID, program1.ID)
-	session2 := testutil.CreateTestSession(t, pool, student1.ID, program2.ID)
-	testutil.CreateTestCompletedSession(t, pool, student1.ID, program1.ID)
+	session1 := testutil.NewSessionBuilder().ForUser(student1).ForProgram(program1).Create(t, db)
+	session2 := testutil.NewSessionBuilder().ForUser(student1).ForProgram(program2).Create(t, db)
+	testutil.NewSessionBuilder().ForUser(student1).ForProgram(program1).StartedAt(time.Now().Add(-30*time.Minute)).Completed(30*time.Minute).Create(t, db)
 
 	// Create sessions for student2 (should not appear in student1 results)
-	testutil.CreateTestSession(t, pool, student2.ID, program1.ID)
+	testutil.NewSessionBuilder().ForUser(student2).ForProgram(program1).Create(t, db)
 
 	tests := []struct {
 		name          string
@@ -110,15 +114,14 @@ func TestSessionRepository_ListByUserID(t *testing.T) {
 }
 
 func TestSessionRepository_ListByUserID_DateFiltering(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSessionRepository(pool)
+	repo := NewSessionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student := testutil.CreateTestStudent(t, pool, "student@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Test Program")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Test Program").OwnedBy(admin).Create(t, db)
 
 	// Create sessions at different times
 	now := time.Now()
@@ -126,19 +129,19 @@ func TestSessionRepository_ListByUserID_DateFiltering(t *testing.T) {
 	twoDaysAgo := now.Add(-48 * time.Hour)
 
 	// Session from 2 days ago
-	testutil.CreateTestSession(t, pool, student.ID, program.ID)
-	testutil.ExecuteSQL(t, pool,
+	testutil.NewSessionBuilder().ForUser(student).ForProgram(program).Create(t, db)
+	testutil.ExecuteSQL(t, db,
 		"UPDATE practice_sessions SET started_at = $1 WHERE user_id = $2 AND started_at = (SELECT MAX(started_at) FROM practice_sessions WHERE user_id = $2)",
 		twoDaysAgo, student.ID)
 
 	// Session from yesterday
-	testutil.CreateTestSession(t, pool, student.ID, program.ID)
-	testutil.ExecuteSQL(t, pool,
+	testutil.NewSessionBuilder().ForUser(student).ForProgram(program).Create(t, db)
+	testutil.ExecuteSQL(t, db,
 		"UPDATE practice_sessions SET started_at = $1 WHERE user_id = $2 AND started_at = (SELECT MAX(started_at) FROM practice_sessions WHERE user_id = $2)",
 		yesterday, student.ID)
 
 	// Session from today
-	testutil.CreateTestSession(t, pool, student.ID, program.ID)
+	testutil.NewSessionBuilder().ForUser(student).ForProgram(program).Create(t, db)
 
 	tests := []struct {
 		name          string
@@ -187,20 +190,19 @@ func TestSessionRepository_ListByUserID_DateFiltering(t *testing.T) {
 }
 
 func TestSessionRepository_ListByUserID_Pagination(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSessionRepository(pool)
+	repo := NewSessionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student := testutil.CreateTestStudent(t, pool, "student@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Test Program")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Test Program").OwnedBy(admin).Create(t, db)
 
-	// Create 10 sessions
+	// Create 10 sessions, a minute apart
+	start := time.Now().Add(-time.Hour)
 	for i := 0; i < 10; i++ {
-		testutil.CreateTestSession(t, pool, student.ID, program.ID)
-		time.Sleep(1 * time.Millisecond) // Ensure different timestamps
+		testutil.NewSessionBuilder().ForUser(student).ForProgram(program).StartedAt(start.Add(time.Duration(i)*time.Minute)).Create(t, db)
 	}
 
 	tests := []struct {
@@ -250,20 +252,19 @@ func TestSessionRepository_ListByUserID_Pagination(t *testing.T) {
 }
 
 func TestSessionRepository_ListByUserID_OrderedByDate(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSessionRepository(pool)
+	repo := NewSessionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student := testutil.CreateTestStudent(t, pool, "student@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Test Program")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Test Program").OwnedBy(admin).Create(t, db)
 
-	// Create sessions
+	// Create sessions, a minute apart
+	start := time.Now().Add(-time.Hour)
 	for i := 0; i < 5; i++ {
-		testutil.CreateTestSession(t, pool, student.ID, program.ID)
-		time.Sleep(2 * time.Millisecond) // Ensure different timestamps
+		testutil.NewSessionBuilder().ForUser(student).ForProgram(program).StartedAt(start.Add(time.Duration(i)*time.Minute)).Create(t, db)
 	}
 
 	sessions, err := repo.ListByUserID(ctx, student.ID, nil, nil, nil, 100, 0)
@@ -280,17 +281,16 @@ func TestSessionRepository_ListByUserID_OrderedByDate(t *testing.T) {
 }
 
 func TestSessionRepository_ListByUserID_IncludesProgramName(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSessionRepository(pool)
+	repo := NewSessionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student := testutil.CreateTestStudent(t, pool, "student@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "My Test Program")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("My Test Program").OwnedBy(admin).Create(t, db)
 
-	testutil.CreateTestSession(t, pool, student.ID, program.ID)
+	testutil.NewSessionBuilder().ForUser(student).ForProgram(program).Create(t, db)
 
 	sessions, err := repo.ListByUserID(ctx, student.ID, nil, nil, nil, 100, 0)
 	if err != nil {
@@ -307,3 +307,124 @@ func TestSessionRepository_ListByUserID_IncludesProgramName(t *testing.T) {
 		t.Errorf("Expected program name 'My Test Program', got '%s'", *sessions[0].ProgramName)
 	}
 }
+
+func TestSessionRepository_ListAll(t *testing.T) {
+	db := testutil.SetupTestTx(t)
+
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student1 := testutil.NewUserBuilder().WithEmail("student1@test.com").WithName("First Student").Create(t, db)
+	student2 := testutil.NewUserBuilder().WithEmail("student2@test.com").Create(t, db)
+	program1 := testutil.NewProgramBuilder().WithName("Program 1").OwnedBy(admin).Create(t, db)
+	program2 := testutil.NewProgramBuilder().WithName("Program 2").OwnedBy(admin).Create(t, db)
+
+	now := time.Now()
+	completed := testutil.NewSessionBuilder().ForUser(student1).ForProgram(program1).StartedAt(now.Add(-time.Hour)).Completed(30*time.Minute).Create(t, db)
+	inProgress := testutil.NewSessionBuilder().ForUser(student1).ForProgram(program2).StartedAt(now.Add(-10*time.Minute)).Create(t, db)
+	abandoned := testutil.NewSessionBuilder().ForUser(student2).ForProgram(program1).StartedAt(now.Add(-72*time.Hour)).Create(t, db)
+
+	status := func(s models.SessionStatus) *models.SessionStatus { return &s }
+	weekAgo := now.Add(-7 * 24 * time.Hour)
+	dayAgo := now.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		filter models.SessionFilter
+		want   []uuid.UUID
+	}{
+		{"all_users_newest_first", models.SessionFilter{}, []uuid.UUID{inProgress.ID, completed.ID, abandoned.ID}},
+		{"filter_by_user", models.SessionFilter{UserID: &student2.ID}, []uuid.UUID{abandoned.ID}},
+		{"filter_by_program", models.SessionFilter{ProgramID: &program1.ID}, []uuid.UUID{completed.ID, abandoned.ID}},
+		{"filter_by_date_range", models.SessionFilter{StartDate: &weekAgo, EndDate: &dayAgo}, []uuid.UUID{abandoned.ID}},
+		{"completed", models.SessionFilter{Status: status(models.SessionCompleted)}, []uuid.UUID{completed.ID}},
+		{"in_progress", models.SessionFilter{Status: status(models.SessionInProgress)}, []uuid.UUID{inProgress.ID}},
+		{"abandoned", models.SessionFilter{Status: status(models.SessionAbandoned)}, []uuid.UUID{abandoned.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions, err := repo.ListAll(ctx, tt.filter, 100, 0)
+			if err != nil {
+				t.Fatalf("ListAll() error = %v", err)
+			}
+			if len(sessions) != len(tt.want) {
+				t.Fatalf("Expected %d sessions, got %d", len(tt.want), len(sessions))
+			}
+			for i, s := range sessions {
+				if s.ID != tt.want[i] {
+					t.Errorf("Session %d = %s, want %s", i, s.ID, tt.want[i])
+				}
+			}
+		})
+	}
+
+	sessions, err := repo.ListAll(ctx, models.SessionFilter{UserID: &student1.ID}, 1, 1)
+	if err != nil {
+		t.Fatalf("ListAll() error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != completed.ID {
+		t.Fatalf("Second page = %+v, want the completed session", sessions)
+	}
+	if s := sessions[0]; s.UserName == nil || *s.UserName != "First Student" || s.ProgramName == nil || *s.ProgramName != "Program 1" {
+		t.Errorf("Expected user and program names, got %v and %v", s.UserName, s.ProgramName)
+	}
+}
+
+func TestSessionRepository_Biometrics_Encrypted(t *testing.T) {
+	db := testutil.SetupTestTx(t)
+
+	keyring, err := fieldcrypt.NewKeyring("k1:"+base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{'a'}, 32)), nil)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	repo := NewSessionRepository(db).WithKeyring(keyring)
+	ctx := context.Background()
+
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().OwnedBy(admin).Create(t, db)
+	session := testutil.NewSessionBuilder().ForUser(student).ForProgram(program).Create(t, db)
+
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	hr := func(v int) *int { return &v }
+	hrv := func(v float64) *float64 { return &v }
+	samples := []models.BiometricSample{
+		{RecordedAt: start, HeartRate: hr(80), HRVMs: hrv(40)},
+		{RecordedAt: start.Add(time.Minute), HeartRate: hr(100)},
+	}
+	if added, err := repo.AddBiometrics(ctx, session.ID, samples); err != nil || added != 2 {
+		t.Fatalf("AddBiometrics() = %d, %v, want 2", added, err)
+	}
+	// Re-sent samples are ignored
+	resent := []models.BiometricSample{samples[1], {RecordedAt: start.Add(2 * time.Minute), HeartRate: hr(120), HRVMs: hrv(20)}}
+	if added, err := repo.AddBiometrics(ctx, session.ID, resent); err != nil || added != 1 {
+		t.Fatalf("AddBiometrics() = %d, %v, want 1", added, err)
+	}
+
+	var stored string
+	if err := db.QueryRow(ctx, `SELECT samples FROM session_biometric_samples WHERE session_id = $1`, session.ID).Scan(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stored, "enc:") {
+		t.Errorf("Stored samples = %q, want them encrypted", stored)
+	}
+
+	got, err := repo.GetBiometrics(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetBiometrics() error = %v", err)
+	}
+	if len(got) != 3 || !got[0].RecordedAt.Equal(start) || *got[2].HeartRate != 120 {
+		t.Errorf("GetBiometrics() = %+v, want the 3 samples in order", got)
+	}
+
+	updated, err := repo.GetByID(ctx, session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *updated.HeartRateMin != 80 || *updated.HeartRateMax != 120 || *updated.HeartRateAvg != 100 || *updated.HRVAvg != 30 {
+		t.Errorf("Summary = %d/%v/%d HRV %v, want 80/100/120 HRV 30",
+			*updated.HeartRateMin, *updated.HeartRateAvg, *updated.HeartRateMax, *updated.HRVAvg)
+	}
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/clock"
+)
+
+type ShareLinkRepository struct {
+	db    database.DB
+	clock clock.Clock
+}
+
+func NewShareLinkRepository(db database.DB) *ShareLinkRepository {
+	return &ShareLinkRepository{db: db, clock: clock.System}
+}
+
+// WithClock replaces the clock used for timestamps, so tests can control time
+func (r *ShareLinkRepository) WithClock(c clock.Clock) *ShareLinkRepository {
+	r.clock = c
+	return r
+}
+
+const shareLinkColumns = `
+	id, program_id, token_hash, created_by, expires_at, revoked_at, view_count, last_viewed_at, created_at
+`
+
+func scanShareLink(row pgx.Row) (*models.ProgramShareLink, error) {
+	var link models.ProgramShareLink
+	err := row.Scan(
+		&link.ID,
+		&link.ProgramID,
+		&link.TokenHash,
+		&link.CreatedBy,
+		&link.ExpiresAt,
+		&link.RevokedAt,
+		&link.ViewCount,
+		&link.LastViewedAt,
+		&link.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *ShareLinkRepository) Create(ctx context.Context, link *models.ProgramShareLink) error {
+	query := `
+		INSERT INTO program_share_links (program_id, token_hash, created_by, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(ctx, query, link.ProgramID, link.TokenHash, link.CreatedBy, link.ExpiresAt).Scan(&link.ID, &link.CreatedAt)
+}
+
+// RecordView counts a view of an active link and returns it, or nil if the link does not
+// exist, was revoked or has expired
+func (r *ShareLinkRepository) RecordView(ctx context.Context, tokenHash string) (*models.ProgramShareLink, error) {
+	now := r.clock.Now()
+	query := `
+		UPDATE program_share_links
+		SET view_count = view_count + 1, last_viewed_at = $2
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > $2
+		RETURNING ` + shareLinkColumns
+	link, err := scanShareLink(r.db.QueryRow(ctx, query, tokenHash, now))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return link, err
+}
+
+func (r *ShareLinkRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ProgramShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + ` FROM program_share_links WHERE id = $1`
+	link, err := scanShareLink(r.db.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return link, err
+}
+
+// ListByProgram returns the program's share links, newest first
+func (r *ShareLinkRepository) ListByProgram(ctx context.Context, programID uuid.UUID) ([]models.ProgramShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + `
+		FROM program_share_links
+		WHERE program_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, programID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]models.ProgramShareLink, 0)
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *link)
+	}
+
+	return links, rows.Err()
+}
+
+// Revoke invalidates a share link and reports whether it was still unrevoked
+func (r *ShareLinkRepository) Revoke(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+		UPDATE program_share_links
+		SET revoked_at = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id, r.clock.Now())
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
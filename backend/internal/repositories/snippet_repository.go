@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+type SnippetRepository struct {
+	db database.DB
+}
+
+func NewSnippetRepository(db database.DB) *SnippetRepository {
+	return &SnippetRepository{db: db}
+}
+
+func (r *SnippetRepository) Create(ctx context.Context, snippet *models.FeedbackSnippet) error {
+	query := `
+		INSERT INTO feedback_snippets (owner_id, title, body)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+	return r.db.QueryRow(ctx, query,
+		snippet.OwnerID,
+		snippet.Title,
+		snippet.Body,
+	).Scan(&snippet.ID, &snippet.CreatedAt, &snippet.UpdatedAt)
+}
+
+// GetByID returns the snippet if it belongs to the owner, nil otherwise
+func (r *SnippetRepository) GetByID(ctx context.Context, id, ownerID uuid.UUID) (*models.FeedbackSnippet, error) {
+	var snippet models.FeedbackSnippet
+	query := `
+		SELECT id, owner_id, title, body, created_at, updated_at
+		FROM feedback_snippets
+		WHERE id = $1 AND owner_id = $2
+	`
+	err := r.db.QueryRow(ctx, query, id, ownerID).Scan(
+		&snippet.ID,
+		&snippet.OwnerID,
+		&snippet.Title,
+		&snippet.Body,
+		&snippet.CreatedAt,
+		&snippet.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snippet, nil
+}
+
+func (r *SnippetRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]models.FeedbackSnippet, error) {
+	query := `
+		SELECT id, owner_id, title, body, created_at, updated_at
+		FROM feedback_snippets
+		WHERE owner_id = $1
+		ORDER BY title
+	`
+	rows, err := r.db.Query(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snippets := make([]models.FeedbackSnippet, 0)
+	for rows.Next() {
+		var snippet models.FeedbackSnippet
+		err := rows.Scan(
+			&snippet.ID,
+			&snippet.OwnerID,
+			&snippet.Title,
+			&snippet.Body,
+			&snippet.CreatedAt,
+			&snippet.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		snippets = append(snippets, snippet)
+	}
+	return snippets, rows.Err()
+}
+
+// TitleExists reports whether the owner has another snippet with this title
+func (r *SnippetRepository) TitleExists(ctx context.Context, ownerID uuid.UUID, title string, excludeID *uuid.UUID) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM feedback_snippets
+			WHERE owner_id = $1 AND title = $2 AND ($3::uuid IS NULL OR id != $3)
+		)
+	`
+	err := r.db.QueryRow(ctx, query, ownerID, title, excludeID).Scan(&exists)
+	return exists, err
+}
+
+func (r *SnippetRepository) Update(ctx context.Context, snippet *models.FeedbackSnippet) error {
+	query := `
+		UPDATE feedback_snippets
+		SET title = $1, body = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND owner_id = $4
+		RETURNING updated_at
+	`
+	return r.db.QueryRow(ctx, query,
+		snippet.Title,
+		snippet.Body,
+		snippet.ID,
+		snippet.OwnerID,
+	).Scan(&snippet.UpdatedAt)
+}
+
+// Delete removes the owner's snippet and reports whether it existed
+func (r *SnippetRepository) Delete(ctx context.Context, id, ownerID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM feedback_snippets WHERE id = $1 AND owner_id = $2`, id, ownerID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
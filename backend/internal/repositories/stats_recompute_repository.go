@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+type StatsRecomputeRepository struct {
+	db database.DB
+}
+
+func NewStatsRecomputeRepository(db database.DB) *StatsRecomputeRepository {
+	return &StatsRecomputeRepository{db: db}
+}
+
+const recomputeColumns = `
+	id, user_id, requested_by, status, total, processed, cursor, error, created_at, updated_at, completed_at`
+
+func scanRecompute(row pgx.Row) (*models.StatsRecompute, error) {
+	var job models.StatsRecompute
+	err := row.Scan(
+		&job.ID, &job.UserID, &job.RequestedBy, &job.Status, &job.Total, &job.Processed, &job.Cursor,
+		&job.Error, &job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+	)
+	return &job, err
+}
+
+// Create queues a recompute, counting the users it will process
+func (r *StatsRecomputeRepository) Create(ctx context.Context, job *models.StatsRecompute) error {
+	query := `
+		INSERT INTO stats_recomputes (user_id, requested_by, total)
+		VALUES ($1, $2, CASE WHEN $1::uuid IS NULL THEN (SELECT COUNT(*) FROM users) ELSE 1 END)
+		RETURNING` + recomputeColumns
+	created, err := scanRecompute(r.db.QueryRow(ctx, query, job.UserID, job.RequestedBy))
+	if err != nil {
+		return fmt.Errorf("failed to create stats recompute: %w", err)
+	}
+	*job = *created
+	return nil
+}
+
+// GetByID returns a recompute, or nil if there is none
+func (r *StatsRecomputeRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.StatsRecompute, error) {
+	var job *models.StatsRecompute
+	err := database.Retry(ctx, "stats_recomputes.GetByID", func() error {
+		var err error
+		job, err = scanRecompute(r.db.QueryRow(ctx, `SELECT`+recomputeColumns+` FROM stats_recomputes WHERE id = $1`, id))
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats recompute: %w", err)
+	}
+	return job, nil
+}
+
+// List returns recomputes, newest first
+func (r *StatsRecomputeRepository) List(ctx context.Context, limit, offset int) ([]models.StatsRecompute, error) {
+	query := `SELECT` + recomputeColumns + `
+		FROM stats_recomputes
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+	jobs, err := collectWithRetry(ctx, r.db, "stats_recomputes.List", query, func(row pgx.CollectableRow) (models.StatsRecompute, error) {
+		job, err := scanRecompute(row)
+		return *job, err
+	}, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stats recomputes: %w", err)
+	}
+	return jobs, nil
+}
+
+// Claim locks the oldest unfinished recompute (or the given one) that nobody else holds for
+// lease and marks it running. A recompute whose holder stopped renewing its lease can be claimed
+// again and resumes from its cursor. Returns nil if there is nothing to claim.
+func (r *StatsRecomputeRepository) Claim(ctx context.Context, id *uuid.UUID, lease time.Duration) (*models.StatsRecompute, error) {
+	query := `
+		UPDATE stats_recomputes
+		SET status = 'running', locked_until = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		WHERE id = (
+			SELECT id FROM stats_recomputes
+			WHERE status IN ('pending', 'running')
+			  AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+			  AND ($1::uuid IS NULL OR id = $1)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING` + recomputeColumns
+	job, err := scanRecompute(r.db.QueryRow(ctx, query, id, lease.Seconds()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim stats recompute: %w", err)
+	}
+	return job, nil
+}
+
+// ListUserIDs returns the next users of a recompute after the cursor, in id order
+func (r *StatsRecomputeRepository) ListUserIDs(ctx context.Context, job *models.StatsRecompute, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM users
+		WHERE ($1::uuid IS NULL OR id = $1)
+		  AND ($2::uuid IS NULL OR id > $2)
+		ORDER BY id
+		LIMIT $3
+	`
+	return collectWithRetry(ctx, r.db, "stats_recomputes.ListUserIDs", query, pgx.RowTo[uuid.UUID], job.UserID, job.Cursor, limit)
+}
+
+// SaveProgress records the last user processed and renews the lease
+func (r *StatsRecomputeRepository) SaveProgress(ctx context.Context, job *models.StatsRecompute, lease time.Duration) error {
+	query := `
+		UPDATE stats_recomputes
+		SET cursor = $2, processed = $3, locked_until = CURRENT_TIMESTAMP + make_interval(secs => $4)
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(ctx, query, job.ID, job.Cursor, job.Processed, lease.Seconds())
+	return err
+}
+
+// Finish marks a recompute completed or failed and releases it
+func (r *StatsRecomputeRepository) Finish(ctx context.Context, id uuid.UUID, status models.StatsRecomputeStatus, errMsg *string) error {
+	query := `
+		UPDATE stats_recomputes
+		SET status = $2, error = $3, locked_until = NULL, completed_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(ctx, query, id, status, errMsg)
+	return err
+}
+
+// Release gives up the lease on an unfinished recompute so it can be resumed right away
+func (r *StatsRecomputeRepository) Release(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE stats_recomputes SET locked_until = NULL WHERE id = $1`, id)
+	return err
+}
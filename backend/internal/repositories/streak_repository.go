@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+type StreakRepository struct {
+	db database.DB
+}
+
+func NewStreakRepository(db database.DB) *StreakRepository {
+	return &StreakRepository{db: db}
+}
+
+// GetRules returns the user's streak overrides, all nil if they have none
+func (r *StreakRepository) GetRules(ctx context.Context, userID uuid.UUID) (models.StreakRules, error) {
+	query := `
+		SELECT rest_days_per_week, min_minutes, grace_hours
+		FROM user_streak_rules
+		WHERE user_id = $1
+	`
+	var rules models.StreakRules
+	err := database.Retry(ctx, "user_streak_rules.GetRules", func() error {
+		return r.db.QueryRow(ctx, query, userID).Scan(&rules.RestDaysPerWeek, &rules.MinMinutes, &rules.GraceHours)
+	})
+	if err == pgx.ErrNoRows {
+		return models.StreakRules{}, nil
+	}
+	return rules, err
+}
+
+// SaveRules replaces the user's streak overrides
+func (r *StreakRepository) SaveRules(ctx context.Context, userID uuid.UUID, rules models.StreakRules) error {
+	query := `
+		INSERT INTO user_streak_rules (user_id, rest_days_per_week, min_minutes, grace_hours)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET rest_days_per_week = EXCLUDED.rest_days_per_week,
+		    min_minutes = EXCLUDED.min_minutes,
+		    grace_hours = EXCLUDED.grace_hours
+	`
+	_, err := r.db.Exec(ctx, query, userID, rules.RestDaysPerWeek, rules.MinMinutes, rules.GraceHours)
+	return err
+}
+
+// GetSnapshot returns the user's stored streaks, or nil if none are stored
+func (r *StreakRepository) GetSnapshot(ctx context.Context, userID uuid.UUID) (*models.StreakSnapshot, error) {
+	query := `
+		SELECT current_streak, longest_streak, as_of
+		FROM user_streaks
+		WHERE user_id = $1
+	`
+	var snapshot models.StreakSnapshot
+	err := database.Retry(ctx, "user_streaks.GetSnapshot", func() error {
+		return r.db.QueryRow(ctx, query, userID).Scan(&snapshot.CurrentStreak, &snapshot.LongestStreak, &snapshot.AsOf)
+	})
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// SaveSnapshot stores the user's streaks, replacing any earlier ones
+func (r *StreakRepository) SaveSnapshot(ctx context.Context, userID uuid.UUID, snapshot models.StreakSnapshot) error {
+	query := `
+		INSERT INTO user_streaks (user_id, current_streak, longest_streak, as_of)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET current_streak = EXCLUDED.current_streak,
+		    longest_streak = EXCLUDED.longest_streak,
+		    as_of = EXCLUDED.as_of,
+		    computed_at = CURRENT_TIMESTAMP
+	`
+	_, err := r.db.Exec(ctx, query, userID, snapshot.CurrentStreak, snapshot.LongestStreak, snapshot.AsOf)
+	return err
+}
+
+// DeleteSnapshot drops the user's stored streaks so they are computed again on the next read
+func (r *StreakRepository) DeleteSnapshot(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM user_streaks WHERE user_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+type SubmissionLabelRepository struct {
+	db database.DB
+}
+
+func NewSubmissionLabelRepository(db database.DB) *SubmissionLabelRepository {
+	return &SubmissionLabelRepository{db: db}
+}
+
+func (r *SubmissionLabelRepository) Create(ctx context.Context, label *models.SubmissionLabel) error {
+	query := `
+		INSERT INTO submission_labels (name, color, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+	return r.db.QueryRow(ctx, query,
+		label.Name,
+		label.Color,
+		label.CreatedBy,
+	).Scan(&label.ID, &label.CreatedAt, &label.UpdatedAt)
+}
+
+func (r *SubmissionLabelRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SubmissionLabel, error) {
+	var label models.SubmissionLabel
+	query := `
+		SELECT l.id, l.name, l.color, l.created_by, l.created_at, l.updated_at,
+		       (SELECT COUNT(*) FROM submission_label_assignments sla
+		        JOIN submissions s ON s.id = sla.submission_id
+		        WHERE sla.label_id = l.id AND s.deleted_at IS NULL) AS submission_count
+		FROM submission_labels l
+		WHERE l.id = $1
+	`
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&label.ID,
+		&label.Name,
+		&label.Color,
+		&label.CreatedBy,
+		&label.CreatedAt,
+		&label.UpdatedAt,
+		&label.SubmissionCount,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// List returns all labels by name with the number of live submissions carrying each
+func (r *SubmissionLabelRepository) List(ctx context.Context) ([]models.SubmissionLabel, error) {
+	query := `
+		SELECT l.id, l.name, l.color, l.created_by, l.created_at, l.updated_at,
+		       COUNT(s.id) AS submission_count
+		FROM submission_labels l
+		LEFT JOIN submission_label_assignments sla ON sla.label_id = l.id
+		LEFT JOIN submissions s ON s.id = sla.submission_id AND s.deleted_at IS NULL
+		GROUP BY l.id
+		ORDER BY LOWER(l.name)
+	`
+	return collectWithRetry(ctx, r.db, "submission_labels.List", query, func(row pgx.CollectableRow) (models.SubmissionLabel, error) {
+		var label models.SubmissionLabel
+		err := row.Scan(
+			&label.ID,
+			&label.Name,
+			&label.Color,
+			&label.CreatedBy,
+			&label.CreatedAt,
+			&label.UpdatedAt,
+			&label.SubmissionCount,
+		)
+		return label, err
+	})
+}
+
+// NameExists reports whether another label has this name, ignoring case
+func (r *SubmissionLabelRepository) NameExists(ctx context.Context, name string, excludeID *uuid.UUID) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM submission_labels
+			WHERE LOWER(name) = LOWER($1) AND ($2::uuid IS NULL OR id != $2)
+		)
+	`
+	err := r.db.QueryRow(ctx, query, name, excludeID).Scan(&exists)
+	return exists, err
+}
+
+func (r *SubmissionLabelRepository) Update(ctx context.Context, label *models.SubmissionLabel) error {
+	query := `
+		UPDATE submission_labels
+		SET name = $1, color = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING updated_at
+	`
+	return r.db.QueryRow(ctx, query, label.Name, label.Color, label.ID).Scan(&label.UpdatedAt)
+}
+
+// Delete removes the label from all submissions and reports whether it existed
+func (r *SubmissionLabelRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM submission_labels WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// CountExisting returns how many of the IDs belong to existing labels
+func (r *SubmissionLabelRepository) CountExisting(ctx context.Context, ids []uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM submission_labels WHERE id = ANY($1::uuid[])`, ids).Scan(&count)
+	return count, err
+}
+
+// SetForSubmission replaces the submission's labels. Labels it already had keep their
+// original assignment.
+func (r *SubmissionLabelRepository) SetForSubmission(ctx context.Context, submissionID uuid.UUID, labelIDs []uuid.UUID, assignedBy uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM submission_label_assignments
+		WHERE submission_id = $1 AND NOT (label_id = ANY($2::uuid[]))
+	`, submissionID, labelIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO submission_label_assignments (submission_id, label_id, assigned_by)
+		SELECT $1, unnest($2::uuid[]), $3
+		ON CONFLICT (submission_id, label_id) DO NOTHING
+	`, submissionID, labelIDs, assignedBy)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListForSubmission returns the submission's labels by name
+func (r *SubmissionLabelRepository) ListForSubmission(ctx context.Context, submissionID uuid.UUID) ([]models.SubmissionLabel, error) {
+	labels, err := labelsForSubmissions(ctx, r.db, []uuid.UUID{submissionID})
+	if err != nil {
+		return nil, err
+	}
+	if labels[submissionID] == nil {
+		return []models.SubmissionLabel{}, nil
+	}
+	return labels[submissionID], nil
+}
+
+// labelsForSubmissions returns the labels of each of the submissions by name
+func labelsForSubmissions(ctx context.Context, db database.DB, submissionIDs []uuid.UUID) (map[uuid.UUID][]models.SubmissionLabel, error) {
+	query := `
+		SELECT sla.submission_id, l.id, l.name, l.color, l.created_by, l.created_at, l.updated_at
+		FROM submission_label_assignments sla
+		JOIN submission_labels l ON l.id = sla.label_id
+		WHERE sla.submission_id = ANY($1::uuid[])
+		ORDER BY LOWER(l.name)
+	`
+	rows, err := db.Query(ctx, query, submissionIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	labels := make(map[uuid.UUID][]models.SubmissionLabel)
+	for rows.Next() {
+		var submissionID uuid.UUID
+		var label models.SubmissionLabel
+		err := rows.Scan(
+			&submissionID,
+			&label.ID,
+			&label.Name,
+			&label.Color,
+			&label.CreatedBy,
+			&label.CreatedAt,
+			&label.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		labels[submissionID] = append(labels[submissionID], label)
+	}
+	return labels, rows.Err()
+}
@@ -8,8 +8,9 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/xuangong/backend/internal/database"
 	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/clock"
 )
 
 // Sentinel errors for better error handling
@@ -21,11 +22,18 @@ var (
 )
 
 type SubmissionRepository struct {
-	db *pgxpool.Pool
+	db    database.DB
+	clock clock.Clock
 }
 
-func NewSubmissionRepository(db *pgxpool.Pool) *SubmissionRepository {
-	return &SubmissionRepository{db: db}
+func NewSubmissionRepository(db database.DB) *SubmissionRepository {
+	return &SubmissionRepository{db: db, clock: clock.System}
+}
+
+// WithClock replaces the clock used for timestamps, so tests can control time
+func (r *SubmissionRepository) WithClock(c clock.Clock) *SubmissionRepository {
+	r.clock = c
+	return r
 }
 
 // Create creates a new submission
@@ -41,8 +49,8 @@ func (r *SubmissionRepository) Create(ctx context.Context, programID, userID uui
 		ProgramID: programID,
 		UserID:    userID,
 		Title:     title,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: r.clock.Now(),
+		UpdatedAt: r.clock.Now(),
 	}
 
 	err := r.db.QueryRow(ctx, query,
@@ -103,8 +111,9 @@ func (r *SubmissionRepository) GetByID(ctx context.Context, id, userID uuid.UUID
 	return &submission, nil
 }
 
-// List retrieves submissions with filters and access control
-func (r *SubmissionRepository) List(ctx context.Context, programID *uuid.UUID, userID uuid.UUID, isAdmin bool, limit, offset int) ([]models.SubmissionListItem, error) {
+// List retrieves submissions with filters and access control. It lists either the threads the
+// user archived or, by default, the others. Admins also get the submissions' labels.
+func (r *SubmissionRepository) List(ctx context.Context, programID, labelID *uuid.UUID, userID uuid.UUID, isAdmin, archived bool, limit, offset int) ([]models.SubmissionListItem, error) {
 	// Optimized query using LATERAL join instead of subqueries for better performance
 	query := `
 		SELECT
@@ -121,24 +130,30 @@ func (r *SubmissionRepository) List(ctx context.Context, programID *uuid.UUID, u
 		JOIN programs p ON s.program_id = p.id
 		JOIN users u ON s.user_id = u.id
 		LEFT JOIN submission_messages sm ON s.id = sm.submission_id
+			AND (sm.hidden_at IS NULL OR sm.user_id = $1 OR $3)
 		LEFT JOIN message_read_status mrs ON sm.id = mrs.message_id AND mrs.user_id = $1
 		LEFT JOIN LATERAL (
 			SELECT sm2.content, u2.full_name as author_name
 			FROM submission_messages sm2
 			JOIN users u2 ON sm2.user_id = u2.id
 			WHERE sm2.submission_id = s.id
+				AND (sm2.hidden_at IS NULL OR sm2.user_id = $1 OR $3)
 			ORDER BY sm2.created_at DESC
 			LIMIT 1
 		) lm ON true
 		WHERE s.deleted_at IS NULL
 			AND ($2::uuid IS NULL OR s.program_id = $2)
 			AND ($3 = true OR s.user_id = $1)
+			AND EXISTS(SELECT 1 FROM submission_archives sa WHERE sa.submission_id = s.id AND sa.user_id = $1) = $6
+			AND ($7::uuid IS NULL OR EXISTS(
+				SELECT 1 FROM submission_label_assignments sla WHERE sla.submission_id = s.id AND sla.label_id = $7
+			))
 		GROUP BY s.id, p.name, u.full_name, u.email, lm.content, lm.author_name
 		ORDER BY last_message_at DESC
 		LIMIT $4 OFFSET $5
 	`
 
-	rows, err := r.db.Query(ctx, query, userID, programID, isAdmin, limit, offset)
+	rows, err := r.db.Query(ctx, query, userID, programID, isAdmin, limit, offset, archived, labelID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list submissions: %w", err)
 	}
@@ -146,7 +161,7 @@ func (r *SubmissionRepository) List(ctx context.Context, programID *uuid.UUID, u
 
 	var submissions []models.SubmissionListItem
 	for rows.Next() {
-		var item models.SubmissionListItem
+		item := models.SubmissionListItem{Archived: archived}
 		err := rows.Scan(
 			&item.ID,
 			&item.ProgramID,
@@ -174,12 +189,168 @@ func (r *SubmissionRepository) List(ctx context.Context, programID *uuid.UUID, u
 		return nil, fmt.Errorf("error iterating submissions: %w", err)
 	}
 
+	if isAdmin && len(submissions) > 0 {
+		ids := make([]uuid.UUID, len(submissions))
+		for i, item := range submissions {
+			ids[i] = item.ID
+		}
+		labels, err := labelsForSubmissions(ctx, r.db, ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get submission labels: %w", err)
+		}
+		for i := range submissions {
+			submissions[i].Labels = labels[submissions[i].ID]
+		}
+	}
+
 	return submissions, nil
 }
 
-// CreateMessage adds a message to a submission
-func (r *SubmissionRepository) CreateMessage(ctx context.Context, submissionID, userID uuid.UUID, content string, youtubeURL *string) (*models.SubmissionMessage, error) {
+// ListOpen returns the student's submissions waiting for feedback: nobody replied yet, or the
+// student wrote last. Oldest wait first.
+func (r *SubmissionRepository) ListOpen(ctx context.Context, studentID uuid.UUID, limit int) ([]models.OpenSubmission, error) {
+	query := `
+		SELECT s.id, s.program_id, p.name, s.title, s.created_at, COALESCE(lm.created_at, s.created_at) AS waiting_since
+		FROM submissions s
+		JOIN programs p ON p.id = s.program_id
+		LEFT JOIN LATERAL (
+			SELECT sm.user_id, sm.created_at
+			FROM submission_messages sm
+			WHERE sm.submission_id = s.id
+			ORDER BY sm.created_at DESC
+			LIMIT 1
+		) lm ON true
+		WHERE s.user_id = $1 AND s.deleted_at IS NULL
+		  AND COALESCE(lm.user_id = s.user_id, true)
+		ORDER BY waiting_since ASC
+		LIMIT $2
+	`
+	submissions, err := collectWithRetry(ctx, r.db, "submissions.ListOpen", query, func(row pgx.CollectableRow) (models.OpenSubmission, error) {
+		var s models.OpenSubmission
+		err := row.Scan(&s.ID, &s.ProgramID, &s.ProgramName, &s.Title, &s.CreatedAt, &s.WaitingSince)
+		return s, err
+	}, studentID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open submissions: %w", err)
+	}
+	return submissions, nil
+}
+
+// Search finds submissions whose title or messages match a web-style query ("knee alignment",
+// "stance -horse", "\"sink the qi\""), best match first. Students only search their own threads.
+// Snippets are built after paging, so ts_headline only runs on the rows returned.
+func (r *SubmissionRepository) Search(ctx context.Context, q string, programID *uuid.UUID, userID uuid.UUID, isAdmin bool, limit, offset int) ([]models.SubmissionSearchResult, error) {
+	query := `
+		WITH q AS (
+			SELECT websearch_to_tsquery('simple', $1) AS query
+		),
+		message_hits AS (
+			SELECT DISTINCT ON (sm.submission_id)
+				sm.submission_id, sm.id, sm.user_id, sm.content, sm.created_at,
+				ts_rank(to_tsvector('simple', sm.content), q.query) AS rank
+			FROM submission_messages sm, q
+			WHERE to_tsvector('simple', sm.content) @@ q.query
+				AND (sm.hidden_at IS NULL OR sm.user_id = $3 OR $4)
+			ORDER BY sm.submission_id, rank DESC, sm.created_at DESC
+		),
+		page AS (
+			SELECT
+				s.id, s.program_id, s.user_id, s.title, s.created_at, s.updated_at, s.deleted_at,
+				mh.id AS message_id, mh.user_id AS message_user_id, mh.content AS message_content,
+				mh.created_at AS message_created_at,
+				EXISTS(SELECT 1 FROM submission_archives sa WHERE sa.submission_id = s.id AND sa.user_id = $3) AS archived,
+				GREATEST(ts_rank(to_tsvector('simple', s.title), q.query), COALESCE(mh.rank, 0)) AS rank
+			FROM submissions s
+			CROSS JOIN q
+			LEFT JOIN message_hits mh ON mh.submission_id = s.id
+			WHERE s.deleted_at IS NULL
+				AND ($2::uuid IS NULL OR s.program_id = $2)
+				AND ($4 = true OR s.user_id = $3)
+				AND (to_tsvector('simple', s.title) @@ q.query OR mh.id IS NOT NULL)
+			ORDER BY rank DESC, s.updated_at DESC
+			LIMIT $5 OFFSET $6
+		)
+		SELECT
+			page.id, page.program_id, page.user_id, page.title, page.created_at, page.updated_at, page.deleted_at,
+			p.name AS program_name,
+			u.full_name AS student_name,
+			ts_headline('simple', page.title, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS title_highlight,
+			page.message_id,
+			COALESCE(author.full_name, '') AS author_name,
+			COALESCE(ts_headline('simple', page.message_content, q.query, 'StartSel=<mark>, StopSel=</mark>, MinWords=8, MaxWords=25, MaxFragments=2'), '') AS snippet,
+			page.message_created_at,
+			page.archived,
+			page.rank
+		FROM page
+		CROSS JOIN q
+		JOIN programs p ON page.program_id = p.id
+		JOIN users u ON page.user_id = u.id
+		LEFT JOIN users author ON page.message_user_id = author.id
+		ORDER BY page.rank DESC, page.updated_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, q, programID, userID, isAdmin, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search submissions: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.SubmissionSearchResult{}
+	for rows.Next() {
+		var (
+			item             models.SubmissionSearchResult
+			messageID        *uuid.UUID
+			match            models.SubmissionMessageMatch
+			messageCreatedAt *time.Time
+		)
+		err := rows.Scan(
+			&item.ID,
+			&item.ProgramID,
+			&item.UserID,
+			&item.Title,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+			&item.DeletedAt,
+			&item.ProgramName,
+			&item.StudentName,
+			&item.TitleHighlight,
+			&messageID,
+			&match.AuthorName,
+			&match.Snippet,
+			&messageCreatedAt,
+			&item.Archived,
+			&item.Rank,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		if messageID != nil {
+			match.ID = *messageID
+			match.CreatedAt = *messageCreatedAt
+			item.Message = &match
+		}
+		results = append(results, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	return results, nil
+}
+
+// CreateMessage adds a message to a submission, records its mentions, clears the author's draft
+// and unarchives the thread for everyone
+func (r *SubmissionRepository) CreateMessage(ctx context.Context, submissionID, userID uuid.UUID, content string, youtubeURL *string, mentions []uuid.UUID) (*models.SubmissionMessage, error) {
 	query := `
+		WITH cleared_draft AS (
+			DELETE FROM submission_drafts WHERE submission_id = $2 AND user_id = $3
+		), unarchived AS (
+			DELETE FROM submission_archives WHERE submission_id = $2
+		), mentioned AS (
+			INSERT INTO submission_message_mentions (message_id, user_id)
+			SELECT $1, unnest($7::uuid[])
+		)
 		INSERT INTO submission_messages (id, submission_id, user_id, content, youtube_url, created_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, submission_id, user_id, content, youtube_url, created_at
@@ -191,7 +362,8 @@ func (r *SubmissionRepository) CreateMessage(ctx context.Context, submissionID,
 		UserID:       userID,
 		Content:      content,
 		YouTubeURL:   youtubeURL,
-		CreatedAt:    time.Now(),
+		Mentions:     mentions,
+		CreatedAt:    r.clock.Now(),
 	}
 
 	err := r.db.QueryRow(ctx, query,
@@ -201,6 +373,7 @@ func (r *SubmissionRepository) CreateMessage(ctx context.Context, submissionID,
 		message.Content,
 		message.YouTubeURL,
 		message.CreatedAt,
+		mentions,
 	).Scan(
 		&message.ID,
 		&message.SubmissionID,
@@ -215,11 +388,210 @@ func (r *SubmissionRepository) CreateMessage(ctx context.Context, submissionID,
 	}
 
 	// Update submission's updated_at timestamp
-	_, _ = r.db.Exec(ctx, `UPDATE submissions SET updated_at = $1 WHERE id = $2`, time.Now(), submissionID)
+	_, _ = r.db.Exec(ctx, `UPDATE submissions SET updated_at = $1 WHERE id = $2`, r.clock.Now(), submissionID)
 
 	return message, nil
 }
 
+// FindDuplicateMessage returns the ID of the user's newest message in the submission since the
+// time with the same content and YouTube URL, or nil if there is none
+func (r *SubmissionRepository) FindDuplicateMessage(ctx context.Context, submissionID, userID uuid.UUID, content string, youtubeURL *string, since time.Time) (*uuid.UUID, error) {
+	query := `
+		SELECT id
+		FROM submission_messages
+		WHERE user_id = $1 AND submission_id = $2 AND created_at > $3
+			AND content = $4 AND youtube_url IS NOT DISTINCT FROM $5
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, query, userID, submissionID, since, content, youtubeURL).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicate message: %w", err)
+	}
+	return &id, nil
+}
+
+// CountMessagesSince counts the user's messages in all submissions since the time, and returns
+// when the oldest of them was posted
+func (r *SubmissionRepository) CountMessagesSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, *time.Time, error) {
+	var count int
+	var oldest *time.Time
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*), MIN(created_at)
+		FROM submission_messages
+		WHERE user_id = $1 AND created_at > $2
+	`, userID, since).Scan(&count, &oldest)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to count recent messages: %w", err)
+	}
+	return count, oldest, nil
+}
+
+// GetTemplate returns the program's submission template, or nil if it has none
+func (r *SubmissionRepository) GetTemplate(ctx context.Context, programID uuid.UUID) (*models.SubmissionTemplate, error) {
+	query := `
+		SELECT program_id, title_pattern, title_example, prompts, updated_by, updated_at
+		FROM submission_templates
+		WHERE program_id = $1
+	`
+
+	var template models.SubmissionTemplate
+	err := r.db.QueryRow(ctx, query, programID).Scan(
+		&template.ProgramID,
+		&template.TitlePattern,
+		&template.TitleExample,
+		&template.Prompts,
+		&template.UpdatedBy,
+		&template.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submission template: %w", err)
+	}
+	return &template, nil
+}
+
+// SaveTemplate creates or replaces the program's submission template
+func (r *SubmissionRepository) SaveTemplate(ctx context.Context, template *models.SubmissionTemplate) error {
+	query := `
+		INSERT INTO submission_templates (program_id, title_pattern, title_example, prompts, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (program_id)
+		DO UPDATE SET title_pattern = EXCLUDED.title_pattern, title_example = EXCLUDED.title_example,
+			prompts = EXCLUDED.prompts, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`
+
+	template.UpdatedAt = r.clock.Now()
+	_, err := r.db.Exec(ctx, query,
+		template.ProgramID,
+		template.TitlePattern,
+		template.TitleExample,
+		template.Prompts,
+		template.UpdatedBy,
+		template.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save submission template: %w", err)
+	}
+	return nil
+}
+
+// DeleteTemplate removes the program's submission template and reports whether it had one
+func (r *SubmissionRepository) DeleteTemplate(ctx context.Context, programID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM submission_templates WHERE program_id = $1`, programID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete submission template: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// SaveDraft creates or replaces the user's draft for a submission
+func (r *SubmissionRepository) SaveDraft(ctx context.Context, draft *models.SubmissionDraft) error {
+	query := `
+		INSERT INTO submission_drafts (submission_id, user_id, content, youtube_url, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (submission_id, user_id)
+		DO UPDATE SET content = EXCLUDED.content, youtube_url = EXCLUDED.youtube_url, updated_at = EXCLUDED.updated_at
+	`
+
+	draft.UpdatedAt = r.clock.Now()
+	_, err := r.db.Exec(ctx, query, draft.SubmissionID, draft.UserID, draft.Content, draft.YouTubeURL, draft.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save draft: %w", err)
+	}
+	return nil
+}
+
+// GetDraft returns the user's draft for a submission, or nil if there is none
+func (r *SubmissionRepository) GetDraft(ctx context.Context, submissionID, userID uuid.UUID) (*models.SubmissionDraft, error) {
+	query := `
+		SELECT submission_id, user_id, content, youtube_url, updated_at
+		FROM submission_drafts
+		WHERE submission_id = $1 AND user_id = $2
+	`
+
+	var draft models.SubmissionDraft
+	err := r.db.QueryRow(ctx, query, submissionID, userID).Scan(
+		&draft.SubmissionID,
+		&draft.UserID,
+		&draft.Content,
+		&draft.YouTubeURL,
+		&draft.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft: %w", err)
+	}
+	return &draft, nil
+}
+
+// DeleteDraft removes the user's draft for a submission, if any
+func (r *SubmissionRepository) DeleteDraft(ctx context.Context, submissionID, userID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM submission_drafts WHERE submission_id = $1 AND user_id = $2`, submissionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	return nil
+}
+
+// Archive archives the submission for the user; archiving it again keeps the original time
+func (r *SubmissionRepository) Archive(ctx context.Context, submissionID, userID uuid.UUID) error {
+	query := `
+		INSERT INTO submission_archives (submission_id, user_id, archived_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, submission_id) DO NOTHING
+	`
+	if _, err := r.db.Exec(ctx, query, submissionID, userID, r.clock.Now()); err != nil {
+		return fmt.Errorf("failed to archive submission: %w", err)
+	}
+	return nil
+}
+
+// Unarchive moves the submission back into the user's inbox
+func (r *SubmissionRepository) Unarchive(ctx context.Context, submissionID, userID uuid.UUID) error {
+	query := `DELETE FROM submission_archives WHERE submission_id = $1 AND user_id = $2`
+	if _, err := r.db.Exec(ctx, query, submissionID, userID); err != nil {
+		return fmt.Errorf("failed to unarchive submission: %w", err)
+	}
+	return nil
+}
+
+// MentionTargets returns the IDs among ids that may be mentioned in the submission's thread:
+// the submission owner and active instructors
+func (r *SubmissionRepository) MentionTargets(ctx context.Context, submissionID uuid.UUID, ids []uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT u.id
+		FROM users u
+		WHERE u.id = ANY($2::uuid[])
+		  AND u.is_active = true
+		  AND (u.role = 'admin' OR u.id = (SELECT user_id FROM submissions WHERE id = $1))
+	`
+
+	rows, err := r.db.Query(ctx, query, submissionID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check mentions: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan mention: %w", err)
+		}
+		targets = append(targets, id)
+	}
+	return targets, rows.Err()
+}
+
 // GetMessages retrieves all messages for a submission with access control and read status
 func (r *SubmissionRepository) GetMessages(ctx context.Context, submissionID, userID uuid.UUID, isAdmin bool) ([]models.MessageWithAuthor, error) {
 	// First check access
@@ -233,7 +605,8 @@ func (r *SubmissionRepository) GetMessages(ctx context.Context, submissionID, us
 
 	query := `
 		SELECT
-			sm.id, sm.submission_id, sm.user_id, sm.content, sm.youtube_url, sm.created_at,
+			sm.id, sm.submission_id, sm.user_id, sm.content, sm.youtube_url, sm.hidden_at, sm.created_at,
+			ARRAY(SELECT smm.user_id FROM submission_message_mentions smm WHERE smm.message_id = sm.id) as mentions,
 			u.full_name as author_name,
 			u.email as author_email,
 			u.role as author_role,
@@ -242,10 +615,11 @@ func (r *SubmissionRepository) GetMessages(ctx context.Context, submissionID, us
 		JOIN users u ON sm.user_id = u.id
 		LEFT JOIN message_read_status mrs ON sm.id = mrs.message_id AND mrs.user_id = $2
 		WHERE sm.submission_id = $1
+			AND (sm.hidden_at IS NULL OR sm.user_id = $2 OR $3)
 		ORDER BY sm.created_at ASC
 	`
 
-	rows, err := r.db.Query(ctx, query, submissionID, userID)
+	rows, err := r.db.Query(ctx, query, submissionID, userID, isAdmin)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get messages: %w", err)
 	}
@@ -260,7 +634,9 @@ func (r *SubmissionRepository) GetMessages(ctx context.Context, submissionID, us
 			&msg.UserID,
 			&msg.Content,
 			&msg.YouTubeURL,
+			&msg.HiddenAt,
 			&msg.CreatedAt,
+			&msg.Mentions,
 			&msg.AuthorName,
 			&msg.AuthorEmail,
 			&msg.AuthorRole,
@@ -276,9 +652,55 @@ func (r *SubmissionRepository) GetMessages(ctx context.Context, submissionID, us
 		return nil, fmt.Errorf("error iterating messages: %w", err)
 	}
 
+	if err := r.attachReaders(ctx, messages, submissionID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
 	return messages, nil
 }
 
+// attachReaders fills in who read each message the user wrote, or each message for admins,
+// with one query for the whole thread
+func (r *SubmissionRepository) attachReaders(ctx context.Context, messages []models.MessageWithAuthor, submissionID, userID uuid.UUID, isAdmin bool) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	query := `
+		SELECT mrs.message_id, mrs.user_id, u.full_name, mrs.read_at
+		FROM message_read_status mrs
+		JOIN submission_messages sm ON sm.id = mrs.message_id
+		JOIN users u ON u.id = mrs.user_id
+		WHERE sm.submission_id = $1
+			AND mrs.user_id <> sm.user_id
+			AND (sm.user_id = $2 OR $3)
+		ORDER BY mrs.read_at
+	`
+	rows, err := r.db.Query(ctx, query, submissionID, userID, isAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to get message readers: %w", err)
+	}
+	defer rows.Close()
+
+	readers := make(map[uuid.UUID][]models.MessageReader)
+	for rows.Next() {
+		var messageID uuid.UUID
+		var reader models.MessageReader
+		if err := rows.Scan(&messageID, &reader.UserID, &reader.FullName, &reader.ReadAt); err != nil {
+			return fmt.Errorf("failed to scan message reader: %w", err)
+		}
+		readers[messageID] = append(readers[messageID], reader)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating message readers: %w", err)
+	}
+
+	for i := range messages {
+		messages[i].ReadBy = readers[messages[i].ID]
+	}
+	return nil
+}
+
 // MarkMessageAsRead marks a message as read by a user
 func (r *SubmissionRepository) MarkMessageAsRead(ctx context.Context, userID, messageID uuid.UUID) error {
 	// First check if message exists
@@ -297,7 +719,7 @@ func (r *SubmissionRepository) MarkMessageAsRead(ctx context.Context, userID, me
 		ON CONFLICT (user_id, message_id) DO NOTHING
 	`
 
-	_, err = r.db.Exec(ctx, query, userID, messageID, time.Now())
+	_, err = r.db.Exec(ctx, query, userID, messageID, r.clock.Now())
 	if err != nil {
 		return fmt.Errorf("failed to mark message as read: %w", err)
 	}
@@ -305,7 +727,8 @@ func (r *SubmissionRepository) MarkMessageAsRead(ctx context.Context, userID, me
 	return nil
 }
 
-// GetUnreadCount returns unread message counts at various levels
+// GetUnreadCount returns unread message counts at various levels, leaving out threads the user
+// archived
 func (r *SubmissionRepository) GetUnreadCount(ctx context.Context, userID uuid.UUID, programID *uuid.UUID) (*models.UnreadCounts, error) {
 	query := `
 		SELECT
@@ -320,6 +743,7 @@ func (r *SubmissionRepository) GetUnreadCount(ctx context.Context, userID uuid.U
 			AND mrs.user_id IS NULL
 			AND ($2::uuid IS NULL OR s.program_id = $2)
 			AND (s.user_id = $1 OR EXISTS(SELECT 1 FROM users WHERE id = $1 AND role = 'admin'))
+			AND NOT EXISTS(SELECT 1 FROM submission_archives sa WHERE sa.submission_id = s.id AND sa.user_id = $1)
 		GROUP BY s.program_id, s.id
 	`
 
@@ -355,6 +779,131 @@ func (r *SubmissionRepository) GetUnreadCount(ctx context.Context, userID uuid.U
 	return counts, nil
 }
 
+// reviewMessagesCTE tags every message of a live thread with whether the thread's student wrote it
+const reviewMessagesCTE = `
+	msgs AS (
+		SELECT
+			sm.submission_id, sm.user_id, sm.created_at,
+			sm.user_id = s.user_id AS from_student,
+			LAG(sm.user_id = s.user_id) OVER (PARTITION BY sm.submission_id ORDER BY sm.created_at) AS prev_from_student
+		FROM submission_messages sm
+		JOIN submissions s ON s.id = sm.submission_id
+		WHERE s.deleted_at IS NULL
+	),
+	open_threads AS (
+		SELECT submission_id
+		FROM (
+			SELECT DISTINCT ON (submission_id) submission_id, from_student
+			FROM msgs
+			ORDER BY submission_id, created_at DESC
+		) latest
+		WHERE from_student
+	)`
+
+// GetReviewAnalytics computes per-instructor review metrics since the given time.
+// A first response is the earliest instructor message after a student message that followed an
+// instructor message (or opened the thread); follow-up student messages don't restart the clock.
+func (r *SubmissionRepository) GetReviewAnalytics(ctx context.Context, since time.Time) (*models.ReviewAnalytics, error) {
+	query := `
+		WITH ` + reviewMessagesCTE + `,
+		waits AS (
+			SELECT submission_id, created_at AS asked_at
+			FROM msgs
+			WHERE from_student AND prev_from_student IS DISTINCT FROM true
+		),
+		responses AS (
+			SELECT reply.user_id AS instructor_id, EXTRACT(EPOCH FROM reply.created_at - w.asked_at)::float8 AS seconds
+			FROM waits w
+			JOIN LATERAL (
+				SELECT m.user_id, m.created_at
+				FROM msgs m
+				WHERE m.submission_id = w.submission_id AND NOT m.from_student AND m.created_at > w.asked_at
+				ORDER BY m.created_at
+				LIMIT 1
+			) reply ON true
+			WHERE reply.created_at >= $1
+		)
+		SELECT
+			u.id, u.full_name, u.email,
+			COALESCE(o.open_threads, 0),
+			COALESCE(a.threads, 0),
+			COALESCE(a.messages, 0),
+			rt.median_seconds,
+			COALESCE(rt.responses, 0)
+		FROM users u
+		LEFT JOIN (
+			SELECT user_id, COUNT(*) AS messages, COUNT(DISTINCT submission_id) AS threads
+			FROM msgs
+			WHERE NOT from_student AND created_at >= $1
+			GROUP BY user_id
+		) a ON a.user_id = u.id
+		LEFT JOIN (
+			SELECT m.user_id, COUNT(DISTINCT m.submission_id) AS open_threads
+			FROM msgs m
+			JOIN open_threads ot ON ot.submission_id = m.submission_id
+			WHERE NOT m.from_student
+			GROUP BY m.user_id
+		) o ON o.user_id = u.id
+		LEFT JOIN (
+			SELECT instructor_id, COUNT(*) AS responses,
+				PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY seconds) AS median_seconds
+			FROM responses
+			GROUP BY instructor_id
+		) rt ON rt.instructor_id = u.id
+		WHERE u.role = 'admin' AND u.is_active = true
+		ORDER BY COALESCE(o.open_threads, 0) DESC, u.full_name
+	`
+
+	rows, err := r.db.Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review analytics: %w", err)
+	}
+	defer rows.Close()
+
+	analytics := &models.ReviewAnalytics{Instructors: []models.InstructorReviewStats{}}
+	for rows.Next() {
+		var (
+			stats         models.InstructorReviewStats
+			medianSeconds *float64
+		)
+		err := rows.Scan(
+			&stats.InstructorID,
+			&stats.FullName,
+			&stats.Email,
+			&stats.OpenThreads,
+			&stats.ThreadsReviewed,
+			&stats.Messages,
+			&medianSeconds,
+			&stats.Responses,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review analytics: %w", err)
+		}
+		if medianSeconds != nil {
+			minutes := *medianSeconds / 60
+			stats.MedianFirstResponseMinutes = &minutes
+		}
+		analytics.Instructors = append(analytics.Instructors, stats)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating review analytics: %w", err)
+	}
+
+	unansweredQuery := `
+		WITH ` + reviewMessagesCTE + `
+		SELECT COUNT(*)
+		FROM open_threads ot
+		WHERE NOT EXISTS (
+			SELECT 1 FROM msgs m WHERE m.submission_id = ot.submission_id AND NOT m.from_student
+		)
+	`
+	if err := r.db.QueryRow(ctx, unansweredQuery).Scan(&analytics.UnansweredThreads); err != nil {
+		return nil, fmt.Errorf("failed to count unanswered threads: %w", err)
+	}
+
+	return analytics, nil
+}
+
 // SoftDelete soft deletes a submission
 func (r *SubmissionRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
 	query := `
@@ -363,7 +912,7 @@ func (r *SubmissionRepository) SoftDelete(ctx context.Context, id uuid.UUID) err
 		WHERE id = $2 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.Exec(ctx, query, time.Now(), id)
+	result, err := r.db.Exec(ctx, query, r.clock.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to soft delete submission: %w", err)
 	}
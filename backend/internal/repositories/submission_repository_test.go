@@ -2,7 +2,9 @@ package repositories
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/xuangong/backend/internal/models"
@@ -10,15 +12,14 @@ import (
 )
 
 func TestSubmissionRepository_Create(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSubmissionRepository(pool)
+	repo := NewSubmissionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student := testutil.CreateTestStudent(t, pool, "student@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Test Program")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Test Program").OwnedBy(admin).Create(t, db)
 
 	tests := []struct {
 		name    string
@@ -73,17 +74,16 @@ func TestSubmissionRepository_Create(t *testing.T) {
 }
 
 func TestSubmissionRepository_GetByID(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSubmissionRepository(pool)
+	repo := NewSubmissionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student1 := testutil.CreateTestStudent(t, pool, "student1@test.com")
-	student2 := testutil.CreateTestStudent(t, pool, "student2@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Test Program")
-	submission := testutil.CreateTestSubmission(t, pool, program.ID, student1.ID, "Test Submission")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student1 := testutil.NewUserBuilder().WithEmail("student1@test.com").Create(t, db)
+	student2 := testutil.NewUserBuilder().WithEmail("student2@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Test Program").OwnedBy(admin).Create(t, db)
+	submission := testutil.NewSubmissionBuilder().ForProgram(program).By(student1).WithTitle("Test Submission").Create(t, db)
 
 	tests := []struct {
 		name    string
@@ -133,16 +133,15 @@ func TestSubmissionRepository_GetByID(t *testing.T) {
 }
 
 func TestSubmissionRepository_GetByID_ExcludesDeleted(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSubmissionRepository(pool)
+	repo := NewSubmissionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student := testutil.CreateTestStudent(t, pool, "student@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Test Program")
-	submission := testutil.CreateTestSubmission(t, pool, program.ID, student.ID, "Deleted Submission")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Test Program").OwnedBy(admin).Create(t, db)
+	submission := testutil.NewSubmissionBuilder().ForProgram(program).By(student).WithTitle("Deleted Submission").Create(t, db)
 
 	// Soft delete the submission
 	err := repo.SoftDelete(ctx, submission.ID)
@@ -161,22 +160,21 @@ func TestSubmissionRepository_GetByID_ExcludesDeleted(t *testing.T) {
 }
 
 func TestSubmissionRepository_List(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSubmissionRepository(pool)
+	repo := NewSubmissionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student1 := testutil.CreateTestStudent(t, pool, "student1@test.com")
-	student2 := testutil.CreateTestStudent(t, pool, "student2@test.com")
-	program1 := testutil.CreateTestProgram(t, pool, admin.ID, "Program 1")
-	program2 := testutil.CreateTestProgram(t, pool, admin.ID, "Program 2")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student1 := testutil.NewUserBuilder().WithEmail("student1@test.com").Create(t, db)
+	student2 := testutil.NewUserBuilder().WithEmail("student2@test.com").Create(t, db)
+	program1 := testutil.NewProgramBuilder().WithName("Program 1").OwnedBy(admin).Create(t, db)
+	program2 := testutil.NewProgramBuilder().WithName("Program 2").OwnedBy(admin).Create(t, db)
 
 	// Create submissions
-	sub1 := testutil.CreateTestSubmission(t, pool, program1.ID, student1.ID, "Student1 Program1")
-	sub2 := testutil.CreateTestSubmission(t, pool, program1.ID, student2.ID, "Student2 Program1")
-	sub3 := testutil.CreateTestSubmission(t, pool, program2.ID, student1.ID, "Student1 Program2")
+	sub1 := testutil.NewSubmissionBuilder().ForProgram(program1).By(student1).WithTitle("Student1 Program1").Create(t, db)
+	sub2 := testutil.NewSubmissionBuilder().ForProgram(program1).By(student2).WithTitle("Student2 Program1").Create(t, db)
+	sub3 := testutil.NewSubmissionBuilder().ForProgram(program2).By(student1).WithTitle("Student1 Program2").Create(t, db)
 
 	tests := []struct {
 		name        string
@@ -218,7 +216,7 @@ func TestSubmissionRepository_List(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results, err := repo.List(ctx, tt.programID, tt.userID, tt.isAdmin, 50, 0)
+			results, err := repo.List(ctx, tt.programID, nil, tt.userID, tt.isAdmin, false, 50, 0)
 			if err != nil {
 				t.Fatalf("List() error = %v", err)
 			}
@@ -249,17 +247,184 @@ func TestSubmissionRepository_List(t *testing.T) {
 	}
 }
 
+func TestSubmissionRepository_Search(t *testing.T) {
+	db := testutil.SetupTestTx(t)
+
+	repo := NewSubmissionRepository(db)
+	ctx := context.Background()
+
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	liWei := testutil.NewUserBuilder().WithEmail("liwei@test.com").WithName("Li Wei").Create(t, db)
+	other := testutil.NewUserBuilder().WithEmail("other@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Standing Forms").OwnedBy(admin).Create(t, db)
+
+	knee := testutil.NewSubmissionBuilder().ForProgram(program).By(liWei).WithTitle("Horse stance check").
+		WithMessage(liWei, "My knees hurt after ten minutes").
+		WithMessage(admin, "Watch your knee alignment: the knees point over the toes, never inwards").
+		CreateWithMessages(t, db)
+	titleOnly := testutil.NewSubmissionBuilder().ForProgram(program).By(other).WithTitle("Knee alignment in bow stance").
+		WithMessage(other, "Is this correct?").
+		CreateWithMessages(t, db)
+	testutil.NewSubmissionBuilder().ForProgram(program).By(other).WithTitle("Breathing").
+		WithMessage(admin, "Sink the qi to the dantian").
+		CreateWithMessages(t, db)
+
+	t.Run("admin_finds_title_and_message_matches", func(t *testing.T) {
+		results, err := repo.Search(ctx, "knee alignment", nil, admin.ID, true, 20, 0)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(results) != 2 {
+			t.Fatalf("Expected 2 results, got %d", len(results))
+		}
+
+		byID := make(map[uuid.UUID]models.SubmissionSearchResult)
+		for _, r := range results {
+			byID[r.ID] = r
+		}
+
+		hit, ok := byID[knee.Submission.ID]
+		if !ok || hit.Message == nil {
+			t.Fatalf("Expected a message match for %v, got %+v", knee.Submission.ID, hit)
+		}
+		if hit.Message.AuthorName != admin.FullName || hit.StudentName != "Li Wei" {
+			t.Errorf("Unexpected names in result: %+v", hit)
+		}
+		if !strings.Contains(hit.Message.Snippet, "<mark>alignment</mark>") {
+			t.Errorf("Snippet %q should highlight the match", hit.Message.Snippet)
+		}
+
+		titleHit, ok := byID[titleOnly.Submission.ID]
+		if !ok {
+			t.Fatal("Expected the title match in the results")
+		}
+		if titleHit.Message != nil {
+			t.Errorf("Title-only match should not include a message, got %+v", titleHit.Message)
+		}
+		if titleHit.TitleHighlight != "<mark>Knee</mark> <mark>alignment</mark> in bow stance" {
+			t.Errorf("TitleHighlight = %q", titleHit.TitleHighlight)
+		}
+	})
+
+	t.Run("student_only_searches_own_threads", func(t *testing.T) {
+		results, err := repo.Search(ctx, "knee alignment", nil, liWei.ID, false, 20, 0)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(results) != 1 || results[0].ID != knee.Submission.ID {
+			t.Errorf("Expected only the student's own submission, got %+v", results)
+		}
+	})
+
+	t.Run("excluded_terms_and_no_matches", func(t *testing.T) {
+		results, err := repo.Search(ctx, "knee -bow", nil, admin.ID, true, 20, 0)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(results) != 1 || results[0].ID != knee.Submission.ID {
+			t.Errorf("Expected only the horse stance thread, got %+v", results)
+		}
+
+		results, err = repo.Search(ctx, "fajin", nil, admin.ID, true, 20, 0)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("Expected no results, got %d", len(results))
+		}
+	})
+}
+
+func TestSubmissionRepository_GetReviewAnalytics(t *testing.T) {
+	db := testutil.SetupTestTx(t)
+
+	repo := NewSubmissionRepository(db)
+	ctx := context.Background()
+
+	alice := testutil.NewUserBuilder().WithEmail("alice@test.com").AsAdmin().Create(t, db)
+	bob := testutil.NewUserBuilder().WithEmail("bob@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().OwnedBy(alice).Create(t, db)
+
+	start := time.Now().Add(-24 * time.Hour)
+	post := func(sub *models.Submission, author *models.User, after time.Duration) {
+		testutil.NewMessageBuilder().InSubmission(sub).By(author).At(start.Add(after)).Create(t, db)
+	}
+
+	// Alice answers after an hour; the student's follow-up doesn't restart the clock.
+	// The student then replies again, so the thread is open for Alice.
+	open := testutil.NewSubmissionBuilder().ForProgram(program).By(student).Create(t, db)
+	post(open, student, 0)
+	post(open, student, 10*time.Minute)
+	post(open, alice, time.Hour)
+	post(open, student, 2*time.Hour)
+
+	// Bob answers after 30 minutes and has the last word
+	closed := testutil.NewSubmissionBuilder().ForProgram(program).By(student).Create(t, db)
+	post(closed, student, 0)
+	post(closed, bob, 30*time.Minute)
+
+	// Nobody has answered yet
+	unanswered := testutil.NewSubmissionBuilder().ForProgram(program).By(student).Create(t, db)
+	post(unanswered, student, 0)
+
+	analytics, err := repo.GetReviewAnalytics(ctx, start.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("GetReviewAnalytics() error = %v", err)
+	}
+
+	if analytics.UnansweredThreads != 1 {
+		t.Errorf("UnansweredThreads = %d, want 1", analytics.UnansweredThreads)
+	}
+
+	stats := make(map[uuid.UUID]models.InstructorReviewStats)
+	for _, s := range analytics.Instructors {
+		stats[s.InstructorID] = s
+	}
+	if _, ok := stats[student.ID]; ok {
+		t.Error("Students should not be listed as instructors")
+	}
+
+	tests := []struct {
+		name          string
+		instructor    *models.User
+		openThreads   int
+		messages      int
+		medianMinutes float64
+	}{
+		{name: "alice", instructor: alice, openThreads: 1, messages: 1, medianMinutes: 60},
+		{name: "bob", instructor: bob, openThreads: 0, messages: 1, medianMinutes: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ok := stats[tt.instructor.ID]
+			if !ok {
+				t.Fatal("Instructor missing from analytics")
+			}
+			if s.OpenThreads != tt.openThreads {
+				t.Errorf("OpenThreads = %d, want %d", s.OpenThreads, tt.openThreads)
+			}
+			if s.Messages != tt.messages || s.ThreadsReviewed != 1 {
+				t.Errorf("Messages = %d, ThreadsReviewed = %d, want %d and 1", s.Messages, s.ThreadsReviewed, tt.messages)
+			}
+			if s.Responses != 1 || s.MedianFirstResponseMinutes == nil || *s.MedianFirstResponseMinutes != tt.medianMinutes {
+				t.Errorf("Responses = %d, MedianFirstResponseMinutes = %v, want 1 and %v", s.Responses, s.MedianFirstResponseMinutes, tt.medianMinutes)
+			}
+		})
+	}
+}
+
 func TestSubmissionRepository_CreateMessage(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSubmissionRepository(pool)
+	repo := NewSubmissionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student := testutil.CreateTestStudent(t, pool, "student@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Test Program")
-	submission := testutil.CreateTestSubmission(t, pool, program.ID, student.ID, "Test Submission")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Test Program").OwnedBy(admin).Create(t, db)
+	submission := testutil.NewSubmissionBuilder().ForProgram(program).By(student).WithTitle("Test Submission").Create(t, db)
 
 	youtubeURL := "https://youtube.com/watch?v=test123"
 
@@ -271,21 +436,21 @@ func TestSubmissionRepository_CreateMessage(t *testing.T) {
 		{
 			name: "create_text_message",
 			setup: func() (*models.SubmissionMessage, error) {
-				return repo.CreateMessage(ctx, submission.ID, student.ID, "Hello instructor!", nil)
+				return repo.CreateMessage(ctx, submission.ID, student.ID, "Hello instructor!", nil, nil)
 			},
 			wantErr: false,
 		},
 		{
 			name: "create_message_with_youtube_url",
 			setup: func() (*models.SubmissionMessage, error) {
-				return repo.CreateMessage(ctx, submission.ID, admin.ID, "Check this video", &youtubeURL)
+				return repo.CreateMessage(ctx, submission.ID, admin.ID, "Check this video", &youtubeURL, nil)
 			},
 			wantErr: false,
 		},
 		{
 			name: "create_message_with_invalid_submission",
 			setup: func() (*models.SubmissionMessage, error) {
-				return repo.CreateMessage(ctx, uuid.New(), student.ID, "Invalid", nil)
+				return repo.CreateMessage(ctx, uuid.New(), student.ID, "Invalid", nil, nil)
 			},
 			wantErr: true,
 		},
@@ -312,27 +477,110 @@ func TestSubmissionRepository_CreateMessage(t *testing.T) {
 	}
 }
 
+func TestSubmissionRepository_Drafts(t *testing.T) {
+	db := testutil.SetupTestTx(t)
+
+	repo := NewSubmissionRepository(db)
+	ctx := context.Background()
+
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	submission := testutil.NewSubmissionBuilder().By(student).Create(t, db)
+
+	draft, err := repo.GetDraft(ctx, submission.ID, admin.ID)
+	if err != nil || draft != nil {
+		t.Fatalf("GetDraft() = %v, %v, want no draft", draft, err)
+	}
+
+	for _, content := range []string{"Your stance is", "Your stance is much better"} {
+		if err := repo.SaveDraft(ctx, &models.SubmissionDraft{SubmissionID: submission.ID, UserID: admin.ID, Content: content}); err != nil {
+			t.Fatalf("SaveDraft() error = %v", err)
+		}
+	}
+	if err := repo.SaveDraft(ctx, &models.SubmissionDraft{SubmissionID: submission.ID, UserID: student.ID, Content: "Thanks"}); err != nil {
+		t.Fatalf("SaveDraft() error = %v", err)
+	}
+
+	draft, err = repo.GetDraft(ctx, submission.ID, admin.ID)
+	if err != nil {
+		t.Fatalf("GetDraft() error = %v", err)
+	}
+	if draft == nil || draft.Content != "Your stance is much better" {
+		t.Fatalf("GetDraft() = %+v, want the latest save", draft)
+	}
+
+	// Posting clears only the author's draft
+	if _, err := repo.CreateMessage(ctx, submission.ID, admin.ID, draft.Content, nil, nil); err != nil {
+		t.Fatalf("CreateMessage() error = %v", err)
+	}
+	if draft, _ := repo.GetDraft(ctx, submission.ID, admin.ID); draft != nil {
+		t.Errorf("Draft should be cleared after posting, got %+v", draft)
+	}
+	if draft, _ := repo.GetDraft(ctx, submission.ID, student.ID); draft == nil {
+		t.Error("Other users' drafts should be kept")
+	}
+
+	if err := repo.DeleteDraft(ctx, submission.ID, student.ID); err != nil {
+		t.Fatalf("DeleteDraft() error = %v", err)
+	}
+	if draft, _ := repo.GetDraft(ctx, submission.ID, student.ID); draft != nil {
+		t.Errorf("Draft should be deleted, got %+v", draft)
+	}
+}
+
+func TestSubmissionRepository_Mentions(t *testing.T) {
+	db := testutil.SetupTestTx(t)
+
+	repo := NewSubmissionRepository(db)
+	ctx := context.Background()
+
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	coInstructor := testutil.NewUserBuilder().WithEmail("co@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	other := testutil.NewUserBuilder().WithEmail("other@test.com").Create(t, db)
+	submission := testutil.NewSubmissionBuilder().By(student).Create(t, db)
+
+	targets, err := repo.MentionTargets(ctx, submission.ID, []uuid.UUID{coInstructor.ID, student.ID, other.ID})
+	if err != nil {
+		t.Fatalf("MentionTargets() error = %v", err)
+	}
+	if len(targets) != 2 {
+		t.Errorf("MentionTargets() = %v, want the co-instructor and the student", targets)
+	}
+
+	if _, err := repo.CreateMessage(ctx, submission.ID, admin.ID, "Please take a look", nil, []uuid.UUID{coInstructor.ID}); err != nil {
+		t.Fatalf("CreateMessage() error = %v", err)
+	}
+
+	messages, err := repo.GetMessages(ctx, submission.ID, admin.ID, true)
+	if err != nil {
+		t.Fatalf("GetMessages() error = %v", err)
+	}
+	if len(messages) != 1 || len(messages[0].Mentions) != 1 || messages[0].Mentions[0] != coInstructor.ID {
+		t.Errorf("GetMessages() mentions = %+v, want the co-instructor", messages)
+	}
+}
+
 func TestSubmissionRepository_GetMessages(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSubmissionRepository(pool)
+	repo := NewSubmissionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student1 := testutil.CreateTestStudent(t, pool, "student1@test.com")
-	student2 := testutil.CreateTestStudent(t, pool, "student2@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Test Program")
-	submission := testutil.CreateTestSubmission(t, pool, program.ID, student1.ID, "Test Submission")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student1 := testutil.NewUserBuilder().WithEmail("student1@test.com").Create(t, db)
+	student2 := testutil.NewUserBuilder().WithEmail("student2@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Test Program").OwnedBy(admin).Create(t, db)
+	submission := testutil.NewSubmissionBuilder().ForProgram(program).By(student1).WithTitle("Test Submission").Create(t, db)
 
 	// Create messages
-	msg1 := testutil.CreateTestMessage(t, pool, submission.ID, student1.ID, "First message", nil)
-	msg2 := testutil.CreateTestMessage(t, pool, submission.ID, admin.ID, "Admin reply", nil)
-	_msg3 := testutil.CreateTestMessage(t, pool, submission.ID, student1.ID, "Thanks!", nil)
+	msg1 := testutil.NewMessageBuilder().InSubmission(submission).By(student1).WithContent("First message").Create(t, db)
+	msg2 := testutil.NewMessageBuilder().InSubmission(submission).By(admin).WithContent("Admin reply").Create(t, db)
+	_msg3 := testutil.NewMessageBuilder().InSubmission(submission).By(student1).WithContent("Thanks!").Create(t, db)
 	_ = _msg3 // Used for test setup
 
 	// Mark msg2 as read by student1
-	testutil.MarkMessageAsRead(t, pool, student1.ID, msg2.ID)
+	testutil.MarkMessageAsRead(t, db, student1.ID, msg2.ID)
 
 	tests := []struct {
 		name            string
@@ -400,17 +648,16 @@ func TestSubmissionRepository_GetMessages(t *testing.T) {
 }
 
 func TestSubmissionRepository_MarkMessageAsRead(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSubmissionRepository(pool)
+	repo := NewSubmissionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student := testutil.CreateTestStudent(t, pool, "student@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Test Program")
-	submission := testutil.CreateTestSubmission(t, pool, program.ID, student.ID, "Test Submission")
-	message := testutil.CreateTestMessage(t, pool, submission.ID, admin.ID, "Instructor message", nil)
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Test Program").OwnedBy(admin).Create(t, db)
+	submission := testutil.NewSubmissionBuilder().ForProgram(program).By(student).WithTitle("Test Submission").Create(t, db)
+	message := testutil.NewMessageBuilder().InSubmission(submission).By(admin).WithContent("Instructor message").Create(t, db)
 
 	tests := []struct {
 		name    string
@@ -450,28 +697,27 @@ func TestSubmissionRepository_MarkMessageAsRead(t *testing.T) {
 }
 
 func TestSubmissionRepository_GetUnreadCount(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSubmissionRepository(pool)
+	repo := NewSubmissionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student := testutil.CreateTestStudent(t, pool, "student@test.com")
-	program1 := testutil.CreateTestProgram(t, pool, admin.ID, "Program 1")
-	program2 := testutil.CreateTestProgram(t, pool, admin.ID, "Program 2")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program1 := testutil.NewProgramBuilder().WithName("Program 1").OwnedBy(admin).Create(t, db)
+	program2 := testutil.NewProgramBuilder().WithName("Program 2").OwnedBy(admin).Create(t, db)
 
 	// Create submissions
-	sub1 := testutil.CreateTestSubmission(t, pool, program1.ID, student.ID, "Sub 1")
-	sub2 := testutil.CreateTestSubmission(t, pool, program2.ID, student.ID, "Sub 2")
+	sub1 := testutil.NewSubmissionBuilder().ForProgram(program1).By(student).WithTitle("Sub 1").Create(t, db)
+	sub2 := testutil.NewSubmissionBuilder().ForProgram(program2).By(student).WithTitle("Sub 2").Create(t, db)
 
 	// Create messages from admin (unread for student)
-	msg1 := testutil.CreateTestMessage(t, pool, sub1.ID, admin.ID, "Message 1", nil)
-	_ = testutil.CreateTestMessage(t, pool, sub1.ID, admin.ID, "Message 2", nil) // msg2 - unread
-	_ = testutil.CreateTestMessage(t, pool, sub2.ID, admin.ID, "Message 3", nil) // msg3 - unread
+	msg1 := testutil.NewMessageBuilder().InSubmission(sub1).By(admin).WithContent("Message 1").Create(t, db)
+	_ = testutil.NewMessageBuilder().InSubmission(sub1).By(admin).WithContent("Message 2").Create(t, db) // msg2 - unread
+	_ = testutil.NewMessageBuilder().InSubmission(sub2).By(admin).WithContent("Message 3").Create(t, db) // msg3 - unread
 
 	// Mark msg1 as read by student
-	testutil.MarkMessageAsRead(t, pool, student.ID, msg1.ID)
+	testutil.MarkMessageAsRead(t, db, student.ID, msg1.ID)
 
 	tests := []struct {
 		name                 string
@@ -537,15 +783,14 @@ func TestSubmissionRepository_GetUnreadCount(t *testing.T) {
 }
 
 func TestSubmissionRepository_SoftDelete(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSubmissionRepository(pool)
+	repo := NewSubmissionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student := testutil.CreateTestStudent(t, pool, "student@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Test Program")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Test Program").OwnedBy(admin).Create(t, db)
 
 	tests := []struct {
 		name    string
@@ -555,7 +800,7 @@ func TestSubmissionRepository_SoftDelete(t *testing.T) {
 		{
 			name: "soft_delete_existing_submission",
 			setup: func() uuid.UUID {
-				sub := testutil.CreateTestSubmission(t, pool, program.ID, student.ID, "To Delete")
+				sub := testutil.NewSubmissionBuilder().ForProgram(program).By(student).WithTitle("To Delete").Create(t, db)
 				return sub.ID
 			},
 			wantErr: false,
@@ -592,23 +837,22 @@ func TestSubmissionRepository_SoftDelete(t *testing.T) {
 }
 
 func TestSubmissionRepository_List_EnrichedMetadata(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewSubmissionRepository(pool)
+	repo := NewSubmissionRepository(db)
 	ctx := context.Background()
 
-	admin := testutil.CreateTestAdmin(t, pool, "admin@test.com")
-	student := testutil.CreateTestStudent(t, pool, "student@test.com")
-	program := testutil.CreateTestProgram(t, pool, admin.ID, "Test Program")
-	submission := testutil.CreateTestSubmission(t, pool, program.ID, student.ID, "Test Submission")
+	admin := testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
+	student := testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
+	program := testutil.NewProgramBuilder().WithName("Test Program").OwnedBy(admin).Create(t, db)
+	submission := testutil.NewSubmissionBuilder().ForProgram(program).By(student).WithTitle("Test Submission").Create(t, db)
 
 	// Add messages
-	testutil.CreateTestMessage(t, pool, submission.ID, student.ID, "Student message", nil)
-	testutil.CreateTestMessage(t, pool, submission.ID, admin.ID, "Admin reply", nil)
+	testutil.NewMessageBuilder().InSubmission(submission).By(student).WithContent("Student message").Create(t, db)
+	testutil.NewMessageBuilder().InSubmission(submission).By(admin).WithContent("Admin reply").Create(t, db)
 
 	// List should return enriched data
-	results, err := repo.List(ctx, nil, admin.ID, true, 50, 0)
+	results, err := repo.List(ctx, nil, nil, admin.ID, true, false, 50, 0)
 	if err != nil {
 		t.Fatalf("List() error = %v", err)
 	}
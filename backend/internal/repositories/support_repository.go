@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/pkg/clock"
+)
+
+const supportTicketSelect = `
+	SELECT t.id, t.user_id, u.full_name, t.category, t.status,
+	       (SELECT COUNT(*) FROM support_messages m WHERE m.ticket_id = t.id),
+	       t.last_activity_at, t.created_at, t.closed_at
+	FROM support_tickets t
+	JOIN users u ON u.id = t.user_id
+`
+
+type SupportRepository struct {
+	db    database.DB
+	clock clock.Clock
+}
+
+func NewSupportRepository(db database.DB) *SupportRepository {
+	return &SupportRepository{db: db, clock: clock.System}
+}
+
+// WithClock replaces the clock used for timestamps, so tests can control time
+func (r *SupportRepository) WithClock(c clock.Clock) *SupportRepository {
+	r.clock = c
+	return r
+}
+
+// Create opens a ticket with its first message and returns the ticket's ID
+func (r *SupportRepository) Create(ctx context.Context, userID uuid.UUID, category models.SupportCategory, content string) (uuid.UUID, error) {
+	query := `
+		WITH ticket AS (
+			INSERT INTO support_tickets (user_id, category, status, last_activity_at, created_at)
+			VALUES ($1, $2, 'open', $4, $4)
+			RETURNING id
+		)
+		INSERT INTO support_messages (ticket_id, author_id, content, created_at)
+		SELECT id, $1, $3, $4 FROM ticket
+		RETURNING ticket_id
+	`
+	var id uuid.UUID
+	if err := r.db.QueryRow(ctx, query, userID, category, content, r.clock.Now()).Scan(&id); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create support ticket: %w", err)
+	}
+	return id, nil
+}
+
+// GetByID returns a ticket, or nil if there is none
+func (r *SupportRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SupportTicket, error) {
+	ticket, err := scanSupportTicket(r.db.QueryRow(ctx, supportTicketSelect+` WHERE t.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get support ticket: %w", err)
+	}
+	return ticket, nil
+}
+
+// ListByUser returns the user's tickets, most recently active first
+func (r *SupportRepository) ListByUser(ctx context.Context, userID uuid.UUID, status *models.SupportStatus, limit, offset int) ([]models.SupportTicket, error) {
+	query := supportTicketSelect + `
+		WHERE t.user_id = $1 AND ($2::varchar IS NULL OR t.status = $2)
+		ORDER BY t.last_activity_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	tickets, err := collectWithRetry(ctx, r.db, "support.ListByUser", query, supportTicketRow, userID, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list support tickets: %w", err)
+	}
+	return tickets, nil
+}
+
+// ListQueue returns everyone's tickets, the longest waiting first
+func (r *SupportRepository) ListQueue(ctx context.Context, status *models.SupportStatus, category *models.SupportCategory, limit, offset int) ([]models.SupportTicket, error) {
+	query := supportTicketSelect + `
+		WHERE ($1::varchar IS NULL OR t.status = $1)
+		  AND ($2::varchar IS NULL OR t.category = $2)
+		ORDER BY t.last_activity_at ASC
+		LIMIT $3 OFFSET $4
+	`
+	tickets, err := collectWithRetry(ctx, r.db, "support.ListQueue", query, supportTicketRow, status, category, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list support queue: %w", err)
+	}
+	return tickets, nil
+}
+
+// ListMessages returns a ticket's messages, oldest first
+func (r *SupportRepository) ListMessages(ctx context.Context, ticketID uuid.UUID) ([]models.SupportMessage, error) {
+	query := `
+		SELECT m.id, m.ticket_id, m.author_id, u.full_name, u.role, m.content, m.created_at
+		FROM support_messages m
+		JOIN users u ON u.id = m.author_id
+		WHERE m.ticket_id = $1
+		ORDER BY m.created_at ASC
+	`
+	messages, err := collectWithRetry(ctx, r.db, "support.ListMessages", query, func(row pgx.CollectableRow) (models.SupportMessage, error) {
+		var message models.SupportMessage
+		err := row.Scan(
+			&message.ID,
+			&message.TicketID,
+			&message.AuthorID,
+			&message.AuthorName,
+			&message.AuthorRole,
+			&message.Content,
+			&message.CreatedAt,
+		)
+		return message, err
+	}, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list support messages: %w", err)
+	}
+	return messages, nil
+}
+
+// CreateMessage adds a message to a ticket and moves the ticket to status, reopening it if it was closed
+func (r *SupportRepository) CreateMessage(ctx context.Context, message *models.SupportMessage, status models.SupportStatus) error {
+	query := `
+		WITH bumped AS (
+			UPDATE support_tickets SET status = $4, last_activity_at = $5, closed_at = NULL WHERE id = $1
+		)
+		INSERT INTO support_messages (ticket_id, author_id, content, created_at)
+		VALUES ($1, $2, $3, $5)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRow(ctx, query,
+		message.TicketID,
+		message.AuthorID,
+		message.Content,
+		status,
+		r.clock.Now(),
+	).Scan(&message.ID, &message.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create support message: %w", err)
+	}
+	return nil
+}
+
+// SetStatus moves a ticket to status, recording when it was closed
+func (r *SupportRepository) SetStatus(ctx context.Context, id uuid.UUID, status models.SupportStatus) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE support_tickets
+		SET status = $2, closed_at = CASE WHEN $2 = 'closed' THEN COALESCE(closed_at, $3) END
+		WHERE id = $1
+	`, id, status, r.clock.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set support ticket status: %w", err)
+	}
+	return nil
+}
+
+func supportTicketRow(row pgx.CollectableRow) (models.SupportTicket, error) {
+	ticket, err := scanSupportTicket(row)
+	if err != nil {
+		return models.SupportTicket{}, err
+	}
+	return *ticket, nil
+}
+
+func scanSupportTicket(row pgx.Row) (*models.SupportTicket, error) {
+	var ticket models.SupportTicket
+	err := row.Scan(
+		&ticket.ID,
+		&ticket.UserID,
+		&ticket.UserName,
+		&ticket.Category,
+		&ticket.Status,
+		&ticket.MessageCount,
+		&ticket.LastActivityAt,
+		&ticket.CreatedAt,
+		&ticket.ClosedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
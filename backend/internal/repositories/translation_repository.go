@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+)
+
+// translationTables maps each translatable type to its table and foreign key column.
+// Values are constants, never user input, so they are safe to interpolate.
+var translationTables = map[models.TranslatableType]struct{ table, idColumn string }{
+	models.TranslatableProgram:  {"program_translations", "program_id"},
+	models.TranslatableExercise: {"exercise_translations", "exercise_id"},
+}
+
+type TranslationRepository struct {
+	db database.DB
+}
+
+func NewTranslationRepository(db database.DB) *TranslationRepository {
+	return &TranslationRepository{db: db}
+}
+
+func tableFor(entityType models.TranslatableType) (string, string, error) {
+	t, ok := translationTables[entityType]
+	if !ok {
+		return "", "", fmt.Errorf("unknown translatable type %q", entityType)
+	}
+	return t.table, t.idColumn, nil
+}
+
+// Upsert creates or replaces the translation for an entity and locale
+func (r *TranslationRepository) Upsert(ctx context.Context, entityType models.TranslatableType, translation *models.Translation) error {
+	table, idColumn, err := tableFor(entityType)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (%[2]s, locale, name, description, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (%[2]s, locale) DO UPDATE
+		SET name = EXCLUDED.name, description = EXCLUDED.description,
+		    updated_by = EXCLUDED.updated_by, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`, table, idColumn)
+	return r.db.QueryRow(ctx, query,
+		translation.EntityID,
+		translation.Locale,
+		translation.Name,
+		translation.Description,
+		translation.UpdatedBy,
+	).Scan(&translation.UpdatedAt)
+}
+
+// Delete removes a translation, reporting whether one existed
+func (r *TranslationRepository) Delete(ctx context.Context, entityType models.TranslatableType, entityID uuid.UUID, locale string) (bool, error) {
+	table, idColumn, err := tableFor(entityType)
+	if err != nil {
+		return false, err
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND locale = $2`, table, idColumn)
+	result, err := r.db.Exec(ctx, query, entityID, locale)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// List returns all translations of an entity ordered by locale
+func (r *TranslationRepository) List(ctx context.Context, entityType models.TranslatableType, entityID uuid.UUID) ([]models.Translation, error) {
+	table, idColumn, err := tableFor(entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %[2]s, locale, name, description, updated_by, updated_at
+		FROM %[1]s
+		WHERE %[2]s = $1
+		ORDER BY locale
+	`, table, idColumn)
+	return r.query(ctx, query, entityID)
+}
+
+// GetForLocale returns the translations of the given entities in one locale, keyed by entity ID
+func (r *TranslationRepository) GetForLocale(ctx context.Context, entityType models.TranslatableType, entityIDs []uuid.UUID, locale string) (map[uuid.UUID]models.Translation, error) {
+	table, idColumn, err := tableFor(entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %[2]s, locale, name, description, updated_by, updated_at
+		FROM %[1]s
+		WHERE %[2]s = ANY($1) AND locale = $2
+	`, table, idColumn)
+	translations, err := r.query(ctx, query, entityIDs, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]models.Translation, len(translations))
+	for _, t := range translations {
+		byID[t.EntityID] = t
+	}
+	return byID, nil
+}
+
+func (r *TranslationRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.Translation, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make([]models.Translation, 0)
+	for rows.Next() {
+		var t models.Translation
+		if err := rows.Scan(&t.EntityID, &t.Locale, &t.Name, &t.Description, &t.UpdatedBy, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		translations = append(translations, t)
+	}
+
+	return translations, rows.Err()
+}
@@ -6,15 +6,15 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/xuangong/backend/internal/database"
 	"github.com/xuangong/backend/internal/models"
 )
 
 type UserRepository struct {
-	db *pgxpool.Pool
+	db database.DB
 }
 
-func NewUserRepository(db *pgxpool.Pool) *UserRepository {
+func NewUserRepository(db database.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
@@ -22,7 +22,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	query := `
 		INSERT INTO users (email, password_hash, full_name, role, is_active)
 		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, created_at, updated_at
+		RETURNING id, language, created_at, updated_at
 	`
 	return r.db.QueryRow(ctx, query,
 		user.Email,
@@ -30,7 +30,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 		user.FullName,
 		user.Role,
 		user.IsActive,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.Language, &user.CreatedAt, &user.UpdatedAt)
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
@@ -38,24 +38,27 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 	query := `
 		SELECT id, email, password_hash, full_name, role, is_active,
 		       countdown_volume, start_volume, halfway_volume, finish_volume,
-		       created_at, updated_at
+		       language, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.FullName,
-		&user.Role,
-		&user.IsActive,
-		&user.CountdownVolume,
-		&user.StartVolume,
-		&user.HalfwayVolume,
-		&user.FinishVolume,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err := database.Retry(ctx, "users.GetByID", func() error {
+		return r.db.QueryRow(ctx, query, id).Scan(
+			&user.ID,
+			&user.Email,
+			&user.PasswordHash,
+			&user.FullName,
+			&user.Role,
+			&user.IsActive,
+			&user.CountdownVolume,
+			&user.StartVolume,
+			&user.HalfwayVolume,
+			&user.FinishVolume,
+			&user.Language,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+	})
 	if err == pgx.ErrNoRows {
 		return nil, nil
 	}
@@ -70,24 +73,27 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 	query := `
 		SELECT id, email, password_hash, full_name, role, is_active,
 		       countdown_volume, start_volume, halfway_volume, finish_volume,
-		       created_at, updated_at
+		       language, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
-	err := r.db.QueryRow(ctx, query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.FullName,
-		&user.Role,
-		&user.IsActive,
-		&user.CountdownVolume,
-		&user.StartVolume,
-		&user.HalfwayVolume,
-		&user.FinishVolume,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err := database.Retry(ctx, "users.GetByEmail", func() error {
+		return r.db.QueryRow(ctx, query, email).Scan(
+			&user.ID,
+			&user.Email,
+			&user.PasswordHash,
+			&user.FullName,
+			&user.Role,
+			&user.IsActive,
+			&user.CountdownVolume,
+			&user.StartVolume,
+			&user.HalfwayVolume,
+			&user.FinishVolume,
+			&user.Language,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+	})
 	if err == pgx.ErrNoRows {
 		return nil, nil
 	}
@@ -101,7 +107,7 @@ func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]models.
 	query := `
 		SELECT id, email, password_hash, full_name, role, is_active,
 		       countdown_volume, start_volume, halfway_volume, finish_volume,
-		       created_at, updated_at
+		       language, created_at, updated_at
 		FROM users
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
@@ -126,6 +132,7 @@ func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]models.
 			&user.StartVolume,
 			&user.HalfwayVolume,
 			&user.FinishVolume,
+			&user.Language,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
@@ -142,8 +149,9 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	query := `
 		UPDATE users
 		SET email = $1, full_name = $2, role = $3, is_active = $4,
-		    countdown_volume = $5, start_volume = $6, halfway_volume = $7, finish_volume = $8
-		WHERE id = $9
+		    countdown_volume = $5, start_volume = $6, halfway_volume = $7, finish_volume = $8,
+		    language = $9
+		WHERE id = $10
 		RETURNING updated_at
 	`
 	return r.db.QueryRow(ctx, query,
@@ -155,6 +163,7 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 		user.StartVolume,
 		user.HalfwayVolume,
 		user.FinishVolume,
+		user.Language,
 		user.ID,
 	).Scan(&user.UpdatedAt)
 }
@@ -4,54 +4,51 @@ import (
 	"context"
 	"testing"
 
+	"github.com/xuangong/backend/internal/database"
 	"github.com/xuangong/backend/internal/models"
 	"github.com/xuangong/backend/pkg/testutil"
 )
 
 func TestUserRepository_CountAdmins(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
-
-	repo := NewUserRepository(pool)
 	ctx := context.Background()
 
 	tests := []struct {
 		name          string
-		setup         func()
+		setup         func(db database.DB)
 		expectedCount int
 	}{
 		{
 			name: "no_admins",
-			setup: func() {
+			setup: func(db database.DB) {
 				// Create only students
-				testutil.CreateTestStudent(t, pool, "student1@test.com")
-				testutil.CreateTestStudent(t, pool, "student2@test.com")
+				testutil.NewUserBuilder().WithEmail("student1@test.com").Create(t, db)
+				testutil.NewUserBuilder().WithEmail("student2@test.com").Create(t, db)
 			},
 			expectedCount: 0,
 		},
 		{
 			name: "one_admin",
-			setup: func() {
-				testutil.CreateTestAdmin(t, pool, "admin1@test.com")
-				testutil.CreateTestStudent(t, pool, "student1@test.com")
+			setup: func(db database.DB) {
+				testutil.NewUserBuilder().WithEmail("admin1@test.com").AsAdmin().Create(t, db)
+				testutil.NewUserBuilder().WithEmail("student1@test.com").Create(t, db)
 			},
 			expectedCount: 1,
 		},
 		{
 			name: "multiple_admins",
-			setup: func() {
-				testutil.CreateTestAdmin(t, pool, "admin1@test.com")
-				testutil.CreateTestAdmin(t, pool, "admin2@test.com")
-				testutil.CreateTestAdmin(t, pool, "admin3@test.com")
-				testutil.CreateTestStudent(t, pool, "student1@test.com")
+			setup: func(db database.DB) {
+				testutil.NewUserBuilder().WithEmail("admin1@test.com").AsAdmin().Create(t, db)
+				testutil.NewUserBuilder().WithEmail("admin2@test.com").AsAdmin().Create(t, db)
+				testutil.NewUserBuilder().WithEmail("admin3@test.com").AsAdmin().Create(t, db)
+				testutil.NewUserBuilder().WithEmail("student1@test.com").Create(t, db)
 			},
 			expectedCount: 3,
 		},
 		{
 			name: "all_admins",
-			setup: func() {
-				testutil.CreateTestAdmin(t, pool, "admin1@test.com")
-				testutil.CreateTestAdmin(t, pool, "admin2@test.com")
+			setup: func(db database.DB) {
+				testutil.NewUserBuilder().WithEmail("admin1@test.com").AsAdmin().Create(t, db)
+				testutil.NewUserBuilder().WithEmail("admin2@test.com").AsAdmin().Create(t, db)
 			},
 			expectedCount: 2,
 		},
@@ -59,10 +56,11 @@ func TestUserRepository_CountAdmins(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Clear users table before each test
-			testutil.TruncateTables(t, pool)
+			// Each case gets its own transaction, so it starts without users
+			db := testutil.SetupTestTx(t)
+			repo := NewUserRepository(db)
 
-			tt.setup()
+			tt.setup(db)
 
 			count, err := repo.CountAdmins(ctx)
 			if err != nil {
@@ -77,17 +75,16 @@ func TestUserRepository_CountAdmins(t *testing.T) {
 }
 
 func TestUserRepository_CountAdmins_ActiveOnly(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
+	db := testutil.SetupTestTx(t)
 
-	repo := NewUserRepository(pool)
+	repo := NewUserRepository(db)
 	ctx := context.Background()
 
 	// Create active admin
-	_ = testutil.CreateTestAdmin(t, pool, "active@test.com")
+	_ = testutil.NewUserBuilder().WithEmail("active@test.com").AsAdmin().Create(t, db)
 
 	// Create inactive admin
-	inactiveAdmin := testutil.CreateTestAdmin(t, pool, "inactive@test.com")
+	inactiveAdmin := testutil.NewUserBuilder().WithEmail("inactive@test.com").AsAdmin().Create(t, db)
 	inactiveAdmin.IsActive = false
 	if err := repo.Update(ctx, inactiveAdmin); err != nil {
 		t.Fatalf("Failed to update admin: %v", err)
@@ -105,38 +102,34 @@ func TestUserRepository_CountAdmins_ActiveOnly(t *testing.T) {
 }
 
 func TestUserRepository_UpdateRole(t *testing.T) {
-	pool := testutil.SetupTestDB(t)
-	defer testutil.TeardownTestDB(t, pool)
-
-	repo := NewUserRepository(pool)
 	ctx := context.Background()
 
 	tests := []struct {
 		name     string
-		setup    func() *models.User
+		setup    func(db database.DB) *models.User
 		newRole  models.UserRole
 		wantErr  bool
 	}{
 		{
 			name: "promote_student_to_admin",
-			setup: func() *models.User {
-				return testutil.CreateTestStudent(t, pool, "student@test.com")
+			setup: func(db database.DB) *models.User {
+				return testutil.NewUserBuilder().WithEmail("student@test.com").Create(t, db)
 			},
 			newRole: models.RoleAdmin,
 			wantErr: false,
 		},
 		{
 			name: "demote_admin_to_student",
-			setup: func() *models.User {
-				return testutil.CreateTestAdmin(t, pool, "admin@test.com")
+			setup: func(db database.DB) *models.User {
+				return testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
 			},
 			newRole: models.RoleStudent,
 			wantErr: false,
 		},
 		{
 			name: "role_remains_unchanged",
-			setup: func() *models.User {
-				return testutil.CreateTestAdmin(t, pool, "admin@test.com")
+			setup: func(db database.DB) *models.User {
+				return testutil.NewUserBuilder().WithEmail("admin@test.com").AsAdmin().Create(t, db)
 			},
 			newRole: models.RoleAdmin,
 			wantErr: false,
@@ -145,9 +138,10 @@ func TestUserRepository_UpdateRole(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			testutil.TruncateTables(t, pool)
+			db := testutil.SetupTestTx(t)
+			repo := NewUserRepository(db)
 
-			user := tt.setup()
+			user := tt.setup(db)
 			originalRole := user.Role
 
 			// Update role
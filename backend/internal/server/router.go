@@ -0,0 +1,639 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/diagnostics"
+	"github.com/xuangong/backend/internal/handlers"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/pkg/storage"
+)
+
+func newRouter(
+	cfg *config.Config,
+	mediaStore *storage.LocalStore,
+	authService *services.AuthService,
+	accessLogWriter *middleware.AccessLogWriter,
+	displayService *services.DisplayService,
+	clientVersionService *services.ClientVersionService,
+	integrationService *services.IntegrationService,
+	endpointStats *diagnostics.EndpointStats,
+	authHandler *handlers.AuthHandler,
+	programHandler *handlers.ProgramHandler,
+	exerciseHandler *handlers.ExerciseHandler,
+	shareLinkHandler *handlers.ShareLinkHandler,
+	embedHandler *handlers.EmbedHandler,
+	sessionHandler *handlers.SessionHandler,
+	userHandler *handlers.UserHandler,
+	submissionHandler *handlers.SubmissionHandler,
+	submissionLabelHandler *handlers.SubmissionLabelHandler,
+	snippetHandler *handlers.SnippetHandler,
+	scheduledMessageHandler *handlers.ScheduledMessageHandler,
+	discussionHandler *handlers.DiscussionHandler,
+	bookingHandler *handlers.BookingHandler,
+	courseHandler *handlers.CourseHandler,
+	quizHandler *handlers.QuizHandler,
+	homeworkHandler *handlers.HomeworkHandler,
+	liveClassHandler *handlers.LiveClassHandler,
+	qrCheckInHandler *handlers.QRCheckInHandler,
+	notificationHandler *handlers.NotificationHandler,
+	adminHandler *handlers.AdminHandler,
+	invitationHandler *handlers.InvitationHandler,
+	displayHandler *handlers.DisplayHandler,
+	groupHandler *handlers.GroupHandler,
+	experimentHandler *handlers.ExperimentHandler,
+	questionnaireHandler *handlers.QuestionnaireHandler,
+	translationHandler *handlers.TranslationHandler,
+	exerciseSubstituteHandler *handlers.ExerciseSubstituteHandler,
+	limitationHandler *handlers.LimitationHandler,
+	journalHandler *handlers.JournalHandler,
+	diaryHandler *handlers.DiaryHandler,
+	supportHandler *handlers.SupportHandler,
+	changelogHandler *handlers.ChangelogHandler,
+	clientVersionHandler *handlers.ClientVersionHandler,
+	integrationHandler *handlers.IntegrationHandler,
+	loginDeviceHandler *handlers.LoginDeviceHandler,
+	retentionHandler *handlers.RetentionHandler,
+	streakHandler *handlers.StreakHandler,
+	metadataSchemaHandler *handlers.MetadataSchemaHandler,
+	quotaHandler *handlers.QuotaHandler,
+	moderationHandler *handlers.ModerationHandler,
+	presenceHandler *handlers.PresenceHandler,
+	healthHandler *handlers.HealthHandler,
+	contractHandler *handlers.ContractHandler,
+) *gin.Engine {
+	// Set gin mode
+	if cfg.Server.Env == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	router := gin.New()
+	// Checked when the configuration was loaded. Without proxies gin would believe any sender's
+	// X-Forwarded-For, which would let clients pick their IP for the admin allowlist and rate limits.
+	_ = router.SetTrustedProxies(cfg.Server.TrustedProxies)
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(handlers.NoRoute)
+	router.NoMethod(handlers.NoMethod)
+
+	// Global middleware
+	router.Use(gin.Recovery())
+	router.Use(middleware.AllowHeader())
+	router.Use(middleware.Logger())
+	router.Use(middleware.SlowRequests(endpointStats, cfg.Logging.GetSlowRequestThreshold()))
+	router.Use(middleware.CORS(&cfg.CORS))
+	router.Use(middleware.RateLimit(&cfg.RateLimit))
+	router.Use(middleware.BodySizeLimit(cfg.Server.GetMaxBodyBytes(), cfg.Upload.GetMaxUploadBytes()))
+	router.Use(middleware.Locale())
+
+	// Health check endpoint
+	router.GET("/health", healthHandler.GetHealth)
+
+	// Generated media (audio cues). Skipped when served from an external base URL.
+	if strings.HasPrefix(cfg.Upload.MediaBaseURL, "/") {
+		router.Static(cfg.Upload.MediaBaseURL, mediaStore.Root())
+	}
+
+	// Route groups restricted to admins, limited to ADMIN_IP_ALLOWLIST when set
+	adminOnly := []gin.HandlerFunc{middleware.RequireRole("admin"), middleware.AdminIPAllowlist(&cfg.AdminAccess)}
+
+	// API routes
+	api := router.Group(fmt.Sprintf("/api/%s", cfg.Server.APIVersion))
+	api.Use(middleware.ClientVersion(clientVersionService)) // 426 for app versions below the platform's minimum
+
+	// Response schema for client contract checks
+	api.GET("/contract", contractHandler.GetContract)
+
+	// Public routes (no auth required)
+	auth := api.Group("/auth")
+	{
+		auth.POST("/register", authHandler.Register)
+		auth.POST("/login", authHandler.Login)
+		auth.POST("/refresh", authHandler.RefreshToken)
+		auth.POST("/devices/revoke", loginDeviceHandler.RevokeWithToken) // "This wasn't me" link of new device alerts
+	}
+
+	// Public read-only program views behind share links
+	api.GET("/shared/programs/:token", shareLinkHandler.GetSharedProgram)
+
+	// Embeddable previews of shared programs for other sites, open to any origin with a stricter rate limit
+	embed := api.Group("")
+	embed.Use(middleware.OpenCORS())
+	embed.Use(middleware.RateLimit(&cfg.Embed.RateLimit))
+	{
+		embed.GET("/embed/programs/:token", embedHandler.GetEmbedProgram)
+		embed.GET("/oembed", embedHandler.GetOEmbed)
+	}
+
+	// Shared screens, authenticated with a read-only display token instead of a user
+	display := api.Group("/display")
+	display.Use(middleware.DisplayAuth(displayService))
+	{
+		display.GET("/programs", displayHandler.ListDisplayPrograms)
+		display.GET("/programs/:id", displayHandler.GetDisplayProgram)
+	}
+
+	// Server-to-server integrations, authenticated with HMAC-signed requests instead of a user
+	integration := api.Group("/integration")
+	integration.Use(middleware.IntegrationAuth(integrationService))
+	{
+		integration.GET("/programs", integrationHandler.ListIntegrationPrograms)
+		integration.GET("/programs/:id", integrationHandler.GetIntegrationProgram)
+	}
+
+	// Protected routes (require authentication)
+	protected := api.Group("")
+	protected.Use(middleware.Auth(authService))
+	protected.Use(middleware.AccessLog(accessLogWriter))
+	{
+		// Auth
+		protected.POST("/auth/logout", authHandler.Logout)
+		protected.GET("/auth/me", authHandler.GetProfile)
+		protected.PUT("/auth/me", authHandler.UpdateProfile)
+		protected.GET("/auth/me/quota", quotaHandler.GetMyQuota)
+		protected.GET("/auth/me/streak-policy", streakHandler.GetMyStreakPolicy)
+		protected.GET("/auth/me/limitations", limitationHandler.GetMyLimitations)
+		protected.PUT("/auth/me/limitations", limitationHandler.UpdateMyLimitations)
+		protected.GET("/auth/me/devices", loginDeviceHandler.ListMyDevices)
+		protected.DELETE("/auth/me/devices/:id", loginDeviceHandler.RevokeMyDevice)
+		protected.PUT("/auth/change-password", authHandler.ChangePassword)
+
+		// Impersonate (admin only)
+		authAdmin := protected.Group("/auth")
+		authAdmin.Use(adminOnly...)
+		{
+			authAdmin.POST("/impersonate/:userId", authHandler.Impersonate)
+		}
+
+		// Programs
+		programs := protected.Group("/programs")
+		{
+			programs.GET("", programHandler.ListPrograms)
+			programs.GET("/:id", programHandler.GetProgram)
+			programs.GET("/:id/timeline", programHandler.GetProgramTimeline)
+			programs.GET("/:id/adoption", sessionHandler.GetProgramAdoption)    // Owner or admin, checked in service
+			programs.PUT("/:id/settings", programHandler.UpdateProgramSettings) // Your own settings for an assigned program
+			programs.POST("/:id/audio", programHandler.GenerateProgramAudio)
+			programs.POST("", programHandler.CreateProgram)       // All users can create programs
+			programs.PUT("/:id", programHandler.UpdateProgram)    // Authorization check in handler
+			programs.DELETE("/:id", programHandler.DeleteProgram) // Soft delete, owner or admin, checked in service
+			programs.POST("/:id/cover", programHandler.UploadProgramCover)
+			programs.PUT("/:id/cover", programHandler.SelectProgramCover)
+			programs.DELETE("/:id/cover", programHandler.DeleteProgramCover)
+			programs.GET("/:id/topics", discussionHandler.ListTopics)   // Discussion board, assigned students and admins
+			programs.POST("/:id/topics", discussionHandler.CreateTopic) // Open a topic on the board
+			programs.GET("/:id/exercises", exerciseHandler.ListExercises)
+			programs.POST("/:id/exercises", exerciseHandler.CreateExercise)          // Owner or admin, checked in service
+			programs.PUT("/:id/exercises/reorder", exerciseHandler.ReorderExercises) // Owner or admin, checked in service
+			programs.GET("/:id/quizzes", quizHandler.ListQuizzes)
+			programs.POST("/:id/share-link", shareLinkHandler.CreateShareLink) // Owner or admin, checked in service
+			programs.GET("/:id/share-links", shareLinkHandler.ListShareLinks)
+			programs.DELETE("/:id/share-links/:linkId", shareLinkHandler.RevokeShareLink)
+			programs.POST("/:id/report", moderationHandler.ReportProgram) // Abuse report for the moderation queue
+			programs.GET("/:id/submission-template", submissionHandler.GetSubmissionTemplate)
+			programs.GET("/:id/questionnaire", questionnaireHandler.GetQuestionnaire) // Asked when completing a session
+
+			// Admin only
+			adminPrograms := programs.Group("")
+			adminPrograms.Use(adminOnly...)
+			{
+				adminPrograms.POST("/:id/assign", programHandler.AssignProgram)
+				adminPrograms.POST("/:id/assign/csv", programHandler.AssignProgramCSV)
+				adminPrograms.GET("/:id/translations", translationHandler.ListProgramTranslations)
+				adminPrograms.PUT("/:id/translations/:locale", translationHandler.SetProgramTranslation)
+				adminPrograms.DELETE("/:id/translations/:locale", translationHandler.DeleteProgramTranslation)
+				adminPrograms.POST("/:id/quizzes", quizHandler.CreateQuiz)
+				adminPrograms.GET("/:id/qr", qrCheckInHandler.GetProgramQR) // Starts a practice session when scanned
+				adminPrograms.PUT("/:id/submission-template", submissionHandler.SetSubmissionTemplate)
+				adminPrograms.DELETE("/:id/submission-template", submissionHandler.DeleteSubmissionTemplate)
+				adminPrograms.PUT("/:id/questionnaire", questionnaireHandler.SetQuestionnaire)
+				adminPrograms.DELETE("/:id/questionnaire", questionnaireHandler.DeleteQuestionnaire)
+				adminPrograms.GET("/:id/questionnaire/results", questionnaireHandler.GetQuestionnaireResults)
+			}
+		}
+
+		// My programs (student view)
+		protected.GET("/my-programs", programHandler.GetMyPrograms)
+		protected.GET("/my-programs/:id/progress", sessionHandler.GetMyProgramProgress)
+
+		// Sessions
+		sessions := protected.Group("/sessions")
+		{
+			sessions.GET("", sessionHandler.ListSessions)
+			sessions.GET("/stats", sessionHandler.GetStats)
+			sessions.GET("/:id", sessionHandler.GetSession)
+			sessions.POST("/start", sessionHandler.StartSession)
+			sessions.PUT("/:id/exercise/:exercise_id", sessionHandler.LogExercise)
+			sessions.PUT("/:id", sessionHandler.UpdateSession)
+			sessions.GET("/:id/edits", sessionHandler.ListSessionEdits) // Owner or admin, checked in service
+			sessions.PUT("/:id/complete", sessionHandler.CompleteSession)
+			sessions.PUT("/:id/answers", questionnaireHandler.AnswerQuestionnaire) // Questionnaire answers after completing
+			sessions.DELETE("/:id", sessionHandler.DeleteSession)
+			sessions.POST("/:id/restore", sessionHandler.RestoreSession)
+			sessions.GET("/:id/notes", sessionHandler.ListNotes)
+			sessions.GET("/:id/biometrics", sessionHandler.GetBiometrics)
+			sessions.POST("/:id/biometrics", sessionHandler.AddBiometrics)
+
+			adminSessions := sessions.Group("")
+			adminSessions.Use(adminOnly...)
+			{
+				adminSessions.POST("/:id/notes", sessionHandler.AddNote) // Instructor note on a student's session
+			}
+		}
+
+		// Users (admin only)
+		users := protected.Group("/users")
+		users.Use(adminOnly...)
+		{
+			users.GET("", userHandler.ListUsers)
+			users.GET("/:id", userHandler.GetUser)
+			users.POST("", userHandler.CreateUser)
+			users.PUT("/:id", userHandler.UpdateUser)
+			users.DELETE("/:id", userHandler.DeleteUser)
+			users.GET("/:id/programs", userHandler.GetUserPrograms)
+			users.GET("/:id/sessions", sessionHandler.GetUserSessions)
+			users.PUT("/:id/role", userHandler.UpdateUserRole)
+			users.GET("/:id/quota", quotaHandler.GetUserQuota)
+			users.PUT("/:id/quota", quotaHandler.SetUserQuota)
+			users.GET("/:id/streak-policy", streakHandler.GetUserStreakPolicy)
+			users.PUT("/:id/streak-policy", streakHandler.SetUserStreakPolicy)
+			users.GET("/:id/programs/:programId/plan", limitationHandler.GetAdjustedPlan) // As adjusted for the student's limitations
+			users.PUT("/:id/programs/:programId/plan-review", limitationHandler.ReviewPlan)
+			users.POST("/:id/journal-requests", journalHandler.RequestJournalShare) // Ask the student to share form-check media
+			users.GET("/:id/diary", diaryHandler.ListUserDiary)                     // Entries the student shared
+		}
+
+		// Submissions
+		submissions := protected.Group("/submissions")
+		{
+			submissions.GET("", submissionHandler.ListSubmissions)                    // List with filters
+			submissions.GET("/unread-count", submissionHandler.GetUnreadCount)        // Get unread counts
+			submissions.GET("/search", submissionHandler.SearchSubmissions)           // Full-text search in titles and messages
+			submissions.GET("/:id", submissionHandler.GetSubmission)                  // Get single submission
+			submissions.GET("/:id/messages", submissionHandler.GetMessages)           // Get messages for submission
+			submissions.GET("/:id/export", submissionHandler.ExportSubmission)        // Download thread as Markdown or PDF
+			submissions.POST("/:id/messages", submissionHandler.CreateMessage)        // Add message to submission
+			submissions.GET("/:id/draft", submissionHandler.GetDraft)                 // Get own unsent message
+			submissions.PUT("/:id/draft", submissionHandler.SaveDraft)                // Autosave own unsent message
+			submissions.DELETE("/:id/draft", submissionHandler.DeleteDraft)           // Discard own unsent message
+			submissions.PUT("/:id/archive", submissionHandler.ArchiveSubmission)      // Archive for the current user only
+			submissions.DELETE("/:id/archive", submissionHandler.UnarchiveSubmission) // Move back into the inbox
+			submissions.GET("/:id/typing", submissionHandler.GetTyping)               // Who else is typing
+			submissions.PUT("/:id/typing", submissionHandler.SetTyping)               // Start or stop own typing indicator
+			submissions.GET("/:id/presence", presenceHandler.GetThreadPresence)       // Which instructors are online
+
+			adminSubmissions := submissions.Group("")
+			adminSubmissions.Use(adminOnly...)
+			{
+				adminSubmissions.DELETE("/:id", submissionHandler.DeleteSubmission)             // Soft delete
+				adminSubmissions.PUT("/:id/labels", submissionLabelHandler.SetSubmissionLabels) // Replace labels
+			}
+		}
+
+		// Create submission for a program
+		protected.POST("/programs/:id/submissions", submissionHandler.CreateSubmission)
+
+		// Mark message as read
+		protected.PUT("/messages/:id/read", submissionHandler.MarkMessageAsRead)
+		protected.POST("/presence/heartbeat", presenceHandler.Heartbeat)
+
+		// Report a message for the moderation queue (needs access to its submission)
+		protected.POST("/messages/:id/report", moderationHandler.ReportMessage)
+
+		// Discussion topics (access checked in service)
+		topics := protected.Group("/topics")
+		{
+			topics.GET("/:id", discussionHandler.GetTopic)
+			topics.DELETE("/:id", discussionHandler.DeleteTopic) // Own topic, or any as admin
+			topics.POST("/:id/replies", discussionHandler.CreateReply)
+			topics.DELETE("/:id/replies/:replyId", discussionHandler.DeleteReply) // Own reply, or any as admin
+
+			// Moderation (admin only)
+			moderatedTopics := topics.Group("")
+			moderatedTopics.Use(adminOnly...)
+			{
+				moderatedTopics.PUT("/:id", discussionHandler.ModerateTopic) // Pin or lock
+			}
+		}
+
+		// Quizzes (answers hidden from students in service)
+		quizzes := protected.Group("/quizzes")
+		{
+			quizzes.GET("/:id", quizHandler.GetQuiz)
+			quizzes.POST("/:id/attempts", quizHandler.SubmitAttempt) // Students need the program assigned
+			quizzes.GET("/:id/attempts", quizHandler.ListAttempts)   // Own attempts, any student's as admin
+
+			// Editing (admin only)
+			managedQuizzes := quizzes.Group("")
+			managedQuizzes.Use(adminOnly...)
+			{
+				managedQuizzes.PUT("/:id", quizHandler.UpdateQuiz)
+				managedQuizzes.DELETE("/:id", quizHandler.DeleteQuiz)
+			}
+		}
+
+		// Courses (visibility checked in service)
+		courses := protected.Group("/courses")
+		{
+			courses.GET("", courseHandler.ListCourses)
+			courses.GET("/:id", courseHandler.GetCourse)
+			courses.POST("/:id/enroll", courseHandler.Enroll)       // Self-enrollment, published courses only
+			courses.GET("/:id/progress", courseHandler.GetProgress) // Own progress, any student's as admin
+
+			// Curriculum and enrollment management (admin only)
+			managedCourses := courses.Group("")
+			managedCourses.Use(adminOnly...)
+			{
+				managedCourses.POST("", courseHandler.CreateCourse)
+				managedCourses.PUT("/:id", courseHandler.UpdateCourse)
+				managedCourses.DELETE("/:id", courseHandler.DeleteCourse)
+				managedCourses.GET("/:id/enrollments", courseHandler.ListEnrollments)
+				managedCourses.POST("/:id/enrollments", courseHandler.EnrollUsers)
+				managedCourses.DELETE("/:id/enrollments/:userId", courseHandler.UnenrollUser)
+			}
+		}
+
+		// Homework (students see their own, checked in service)
+		homework := protected.Group("/homework")
+		{
+			homework.GET("", homeworkHandler.ListHomework)
+			homework.GET("/:id", homeworkHandler.GetHomework)
+
+			// Setting and tracking homework (admin only)
+			managedHomework := homework.Group("")
+			managedHomework.Use(adminOnly...)
+			{
+				managedHomework.POST("", homeworkHandler.CreateHomework)
+				managedHomework.PUT("/:id", homeworkHandler.UpdateHomework)
+				managedHomework.DELETE("/:id", homeworkHandler.DeleteHomework)
+				managedHomework.GET("/:id/students", homeworkHandler.GetHomeworkStudents)
+			}
+		}
+
+		// Live classes (group visibility checked in service)
+		classes := protected.Group("/classes")
+		{
+			classes.GET("", liveClassHandler.ListClasses)
+			classes.POST("/check-in", liveClassHandler.CheckIn) // Self check-in with the class code
+			classes.GET("/:id", liveClassHandler.GetClass)
+
+			// Scheduling and attendance (admin only)
+			managedClasses := classes.Group("")
+			managedClasses.Use(adminOnly...)
+			{
+				managedClasses.POST("", liveClassHandler.CreateClass)
+				managedClasses.PUT("/:id", liveClassHandler.UpdateClass)
+				managedClasses.DELETE("/:id", liveClassHandler.DeleteClass)
+				managedClasses.POST("/:id/check-in-code", liveClassHandler.CreateCheckInCode)
+				managedClasses.GET("/:id/qr", qrCheckInHandler.GetClassQR)
+				managedClasses.GET("/:id/attendance", liveClassHandler.ListAttendance)
+				managedClasses.PUT("/:id/attendance", liveClassHandler.MarkAttendance)
+				managedClasses.DELETE("/:id/attendance/:userId", liveClassHandler.RemoveAttendance)
+			}
+		}
+
+		// Scanning a class or program QR code shown at an in-person session
+		protected.POST("/check-in/qr", qrCheckInHandler.ScanQR)
+
+		// Office-hours bookings (access checked in service)
+		bookings := protected.Group("/bookings")
+		{
+			bookings.GET("", bookingHandler.ListBookings) // Own bookings, as student or instructor
+			bookings.POST("", bookingHandler.BookSlot)
+			bookings.GET("/slots", bookingHandler.ListSlots)
+			bookings.GET("/:id", bookingHandler.GetBooking)
+			bookings.POST("/:id/cancel", bookingHandler.CancelBooking) // Students only before the cancellation notice
+			bookings.GET("/:id/ical", bookingHandler.DownloadCalendar)
+
+			// Publishing availability (admin only, each instructor manages their own)
+			slots := bookings.Group("/slots")
+			slots.Use(adminOnly...)
+			{
+				slots.POST("", bookingHandler.PublishSlot)
+				slots.DELETE("/:id", bookingHandler.DeleteSlot)
+			}
+		}
+
+		// Scheduled messages and announcements (admin only, each instructor sees their own)
+		scheduled := protected.Group("/scheduled-messages")
+		scheduled.Use(adminOnly...)
+		{
+			scheduled.GET("", scheduledMessageHandler.ListScheduledMessages)
+			scheduled.POST("", scheduledMessageHandler.ScheduleMessage)
+			scheduled.DELETE("/:id", scheduledMessageHandler.CancelScheduledMessage)
+		}
+
+		// Submission labels (admin only, shared by all instructors)
+		submissionLabels := protected.Group("/submission-labels")
+		submissionLabels.Use(adminOnly...)
+		{
+			submissionLabels.GET("", submissionLabelHandler.ListLabels)
+			submissionLabels.POST("", submissionLabelHandler.CreateLabel)
+			submissionLabels.PUT("/:id", submissionLabelHandler.UpdateLabel)
+			submissionLabels.DELETE("/:id", submissionLabelHandler.DeleteLabel)
+		}
+
+		// Feedback snippets (admin only, each instructor sees their own)
+		snippets := protected.Group("/snippets")
+		snippets.Use(adminOnly...)
+		{
+			snippets.GET("", snippetHandler.ListSnippets)
+			snippets.POST("", snippetHandler.CreateSnippet)
+			snippets.GET("/:id", snippetHandler.GetSnippet)
+			snippets.PUT("/:id", snippetHandler.UpdateSnippet)
+			snippets.DELETE("/:id", snippetHandler.DeleteSnippet)
+		}
+
+		// Progress journal, private to the student unless they share an entry
+		journal := protected.Group("/journal")
+		{
+			journal.GET("", journalHandler.ListJournal)
+			journal.POST("", journalHandler.UploadJournalEntry)
+			journal.GET("/:id", journalHandler.GetJournalEntry)
+			journal.GET("/:id/media", journalHandler.GetJournalMedia)
+			journal.DELETE("/:id", journalHandler.DeleteJournalEntry)
+			journal.PUT("/:id/shares/:instructorId", journalHandler.ShareJournalEntry)
+			journal.DELETE("/:id/shares/:instructorId", journalHandler.UnshareJournalEntry)
+
+			// Admin only
+			sharedJournal := journal.Group("")
+			sharedJournal.Use(adminOnly...)
+			{
+				sharedJournal.GET("/shared", journalHandler.ListSharedJournal)
+			}
+		}
+
+		// Practice diary, private to the student unless they share an entry
+		diary := protected.Group("/diary")
+		{
+			diary.GET("", diaryHandler.ListDiary)
+			diary.POST("", diaryHandler.CreateDiaryEntry)
+			diary.GET("/search", diaryHandler.SearchDiary)
+			diary.GET("/:id", diaryHandler.GetDiaryEntry)
+			diary.PUT("/:id", diaryHandler.UpdateDiaryEntry)
+			diary.DELETE("/:id", diaryHandler.DeleteDiaryEntry)
+		}
+
+		// Support tickets, visible to the student who opened them and to admins
+		support := protected.Group("/support/tickets")
+		{
+			support.GET("", supportHandler.ListMyTickets)
+			support.POST("", supportHandler.OpenTicket)
+			support.GET("/:id", supportHandler.GetTicket)
+			support.POST("/:id/messages", supportHandler.Reply) // Student replies reopen the ticket
+		}
+
+		// Changelog, filtered by the user's role
+		changelog := protected.Group("/changelog")
+		{
+			changelog.GET("", changelogHandler.ListChangelog)
+			changelog.GET("/unseen", changelogHandler.ListUnseen)
+			changelog.POST("/dismiss", changelogHandler.Dismiss) // Seen on all devices
+
+			// Writing release notes (admin only)
+			managedChangelog := changelog.Group("")
+			managedChangelog.Use(adminOnly...)
+			{
+				managedChangelog.POST("", changelogHandler.CreateEntry)
+				managedChangelog.PUT("/:id", changelogHandler.UpdateEntry)
+				managedChangelog.DELETE("/:id", changelogHandler.DeleteEntry)
+			}
+		}
+
+		// Notifications
+		notifications := protected.Group("/notifications")
+		{
+			notifications.GET("", notificationHandler.ListNotifications)
+			notifications.GET("/preferences", notificationHandler.GetPreferences)
+			notifications.PUT("/preferences", notificationHandler.UpdatePreferences)
+			notifications.GET("/digest", notificationHandler.GetDigestPreview)
+			notifications.PUT("/:id/read", notificationHandler.MarkAsRead)
+		}
+
+		// Admin
+		admin := protected.Group("/admin")
+		admin.Use(adminOnly...)
+		{
+			admin.GET("/usage", adminHandler.GetUsage)
+			admin.GET("/students/:id/overview", adminHandler.GetStudentOverview)
+			admin.GET("/students/:id/app-state", adminHandler.GetStudentAppState) // What the student's app shows, without signing in as them
+			admin.GET("/review-analytics", adminHandler.GetReviewAnalytics)
+			admin.GET("/homework-report", adminHandler.GetHomeworkReport)
+			admin.GET("/attendance-report", adminHandler.GetAttendanceReport)
+			admin.GET("/reports/:type", adminHandler.GetReport) // CSV or XLSX download
+			admin.GET("/db-retries", adminHandler.GetDatabaseRetries)
+			admin.GET("/slow-endpoints", adminHandler.GetSlowEndpoints)
+			admin.GET("/quota-plans", quotaHandler.ListQuotaPlans)
+			admin.PUT("/quota-plans/:name", quotaHandler.SaveQuotaPlan)
+			admin.DELETE("/quota-plans/:name", quotaHandler.DeleteQuotaPlan)
+			admin.GET("/plan-reviews", limitationHandler.ListPendingReviews) // Adjusted programs waiting for review
+			admin.GET("/stats-recomputes", streakHandler.ListStatsRecomputes)
+			admin.POST("/stats-recomputes", streakHandler.StartStatsRecompute) // Runs in the background; poll for progress
+			admin.GET("/stats-recomputes/:id", streakHandler.GetStatsRecompute)
+			admin.DELETE("/programs/:id", programHandler.PurgeProgram)    // Removes a deleted program for good
+			admin.GET("/sessions", sessionHandler.ListAllSessions)        // Activity feed across all students
+			admin.PUT("/sessions/:id", sessionHandler.AdminUpdateSession) // Corrections with a reason, kept in the edit history
+			admin.PUT("/exercise-logs/:id", sessionHandler.AdminUpdateExerciseLog)
+			admin.GET("/repetition-reconciliations", sessionHandler.ListRepetitionReconciliations)
+			admin.POST("/repetition-reconciliations", sessionHandler.ReconcileRepetitions)
+			admin.GET("/retention/rules", retentionHandler.ListRetentionRules)
+			admin.POST("/retention/rules/:rule/dry-run", retentionHandler.DryRunRetentionRule) // Counts only; the daily job applies the rules
+			admin.GET("/retention/runs", retentionHandler.ListRetentionRuns)
+			admin.GET("/moderation", moderationHandler.ListCases)
+			admin.GET("/moderation/:id", moderationHandler.GetCase)
+			admin.POST("/moderation/:id/resolve", moderationHandler.ResolveCase) // Dismiss and show the content again
+			admin.POST("/moderation/:id/hide", moderationHandler.HideCase)
+			admin.GET("/support-tickets", supportHandler.ListQueue) // Longest waiting first
+			admin.PUT("/support-tickets/:id", supportHandler.SetStatus)
+			admin.GET("/client-versions", clientVersionHandler.ListPolicies)
+			admin.PUT("/client-versions/:platform", clientVersionHandler.SetPolicy) // Overrides the configured minimum
+			admin.DELETE("/client-versions/:platform", clientVersionHandler.ResetPolicy)
+		}
+
+		// Invitations (admin only)
+		invitations := protected.Group("/invitations")
+		invitations.Use(adminOnly...)
+		{
+			invitations.GET("", invitationHandler.ListInvitations)
+			invitations.POST("", invitationHandler.CreateInvitation)
+			invitations.DELETE("/:id", invitationHandler.RevokeInvitation)
+		}
+
+		// Display tokens (admin only)
+		displayTokens := protected.Group("/display-tokens")
+		displayTokens.Use(adminOnly...)
+		{
+			displayTokens.GET("", displayHandler.ListDisplayTokens)
+			displayTokens.POST("", displayHandler.CreateDisplayToken)
+			displayTokens.DELETE("/:id", displayHandler.RevokeDisplayToken)
+		}
+
+		// Integration keys (admin only)
+		integrationKeys := protected.Group("/integration-keys")
+		integrationKeys.Use(adminOnly...)
+		{
+			integrationKeys.GET("", integrationHandler.ListIntegrationKeys)
+			integrationKeys.POST("", integrationHandler.CreateIntegrationKey) // The secret is only returned here
+			integrationKeys.DELETE("/:id", integrationHandler.RevokeIntegrationKey)
+		}
+
+		// Groups (admin only)
+		groups := protected.Group("/groups")
+		groups.Use(adminOnly...)
+		{
+			groups.GET("", groupHandler.ListGroups)
+			groups.POST("", groupHandler.CreateGroup)
+			groups.GET("/:id/stats", groupHandler.GetGroupStats) // Members' practice for the class review
+		}
+
+		// A/B experiments between program variants (admin only)
+		experiments := protected.Group("/experiments")
+		experiments.Use(adminOnly...)
+		{
+			experiments.GET("", experimentHandler.ListExperiments)
+			experiments.POST("", experimentHandler.CreateExperiment)
+			experiments.GET("/:id", experimentHandler.GetExperiment)
+			experiments.POST("/:id/assign", experimentHandler.AssignExperiment) // Random, balanced variants
+			experiments.GET("/:id/results", experimentHandler.GetExperimentResults)
+		}
+
+		// Metadata schemas (readable by all users for form generation)
+		metadataSchemas := protected.Group("/metadata-schemas")
+		{
+			metadataSchemas.GET("", metadataSchemaHandler.ListMetadataSchemas)
+			metadataSchemas.GET("/:entity_type", metadataSchemaHandler.GetMetadataSchema)
+
+			adminMetadataSchemas := metadataSchemas.Group("")
+			adminMetadataSchemas.Use(adminOnly...)
+			{
+				adminMetadataSchemas.PUT("/:entity_type", metadataSchemaHandler.SetMetadataSchema)
+				adminMetadataSchemas.DELETE("/:entity_type", metadataSchemaHandler.DeleteMetadataSchema)
+			}
+		}
+
+		// Exercises
+		exercises := protected.Group("/exercises")
+		{
+			exercises.PUT("/:id", exerciseHandler.UpdateExercise)    // Owner or admin, checked in service
+			exercises.DELETE("/:id", exerciseHandler.DeleteExercise) // Owner or admin, checked in service
+
+			// Translations and substitutes (admin only)
+			adminExercises := exercises.Group("")
+			adminExercises.Use(adminOnly...)
+			{
+				adminExercises.GET("/:id/translations", translationHandler.ListExerciseTranslations)
+				adminExercises.PUT("/:id/translations/:locale", translationHandler.SetExerciseTranslation)
+				adminExercises.DELETE("/:id/translations/:locale", translationHandler.DeleteExerciseTranslation)
+				adminExercises.GET("/:id/substitutes", exerciseSubstituteHandler.ListSubstitutes)
+				adminExercises.POST("/:id/substitutes", exerciseSubstituteHandler.CreateSubstitute)
+				adminExercises.PUT("/:id/substitutes/:substituteId", exerciseSubstituteHandler.UpdateSubstitute)
+				adminExercises.DELETE("/:id/substitutes/:substituteId", exerciseSubstituteHandler.DeleteSubstitute)
+			}
+		}
+	}
+
+	return router
+}
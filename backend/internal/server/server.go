@@ -0,0 +1,275 @@
+// Package server wires repositories, services and handlers into the HTTP router.
+// It is shared by the API binary and the end-to-end tests, so both run the same stack.
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/xuangong/backend/internal/anonymize"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/diagnostics"
+	"github.com/xuangong/backend/internal/handlers"
+	"github.com/xuangong/backend/internal/middleware"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/internal/services"
+	"github.com/xuangong/backend/pkg/contentfilter"
+	"github.com/xuangong/backend/pkg/dependency"
+	"github.com/xuangong/backend/pkg/mail"
+	"github.com/xuangong/backend/pkg/meeting"
+	"github.com/xuangong/backend/pkg/storage"
+	"github.com/xuangong/backend/pkg/tts"
+)
+
+// Server holds the router and the services needed by background jobs
+type Server struct {
+	Router *gin.Engine
+	// Handler serves the router over HTTP, answering HEAD requests with the GET routes
+	Handler                 http.Handler
+	SessionService          *services.SessionService
+	ProgramService          *services.ProgramService
+	ScheduledMessageService *services.ScheduledMessageService
+	HomeworkService         *services.HomeworkService
+	DigestService           *services.DigestService
+	StatsRecomputeService   *services.StatsRecomputeService
+	IntegrationService      *services.IntegrationService
+	AuthService             *services.AuthService
+	RetentionService        *services.RetentionService
+	// AccessLogWriter writes access logs in the background; close it after the HTTP server
+	AccessLogWriter *middleware.AccessLogWriter
+}
+
+// accessLogBufferSize is how many access log entries wait for the writer before new ones are
+// dropped, a few seconds of peak traffic
+const accessLogBufferSize = 10000
+
+// New builds the full application on top of an open, migrated connection pool
+func New(cfg *config.Config, pool *pgxpool.Pool) (*Server, error) {
+	// External dependencies: the database is critical, everything else degrades gracefully
+	dependencies := dependency.NewRegistry(cfg.Dependencies.BreakerFailureThreshold, cfg.Dependencies.GetBreakerCooldown())
+	dependencies.Register("database", true, func(ctx context.Context) error {
+		return pool.Ping(ctx)
+	})
+
+	// Sensitive columns are encrypted when a key is configured
+	keyring, err := cfg.Encryption.NewKeyring()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize encryption: %w", err)
+	}
+
+	// Initialize repositories
+	userRepo := repositories.NewUserRepository(pool)
+	programRepo := repositories.NewProgramRepository(pool)
+	exerciseRepo := repositories.NewExerciseRepository(pool)
+	sessionRepo := repositories.NewSessionRepository(pool).WithKeyring(keyring)
+	submissionRepo := repositories.NewSubmissionRepository(pool)
+	notificationRepo := repositories.NewNotificationRepository(pool)
+	accessLogRepo := repositories.NewAccessLogRepository(pool)
+	groupRepo := repositories.NewGroupRepository(pool)
+	invitationRepo := repositories.NewInvitationRepository(pool)
+	displayTokenRepo := repositories.NewDisplayTokenRepository(pool)
+	shareLinkRepo := repositories.NewShareLinkRepository(pool)
+	translationRepo := repositories.NewTranslationRepository(pool)
+	metadataSchemaRepo := repositories.NewMetadataSchemaRepository(pool)
+	snippetRepo := repositories.NewSnippetRepository(pool)
+	submissionLabelRepo := repositories.NewSubmissionLabelRepository(pool)
+	scheduledMessageRepo := repositories.NewScheduledMessageRepository(pool)
+	discussionRepo := repositories.NewDiscussionRepository(pool)
+	bookingRepo := repositories.NewBookingRepository(pool)
+	courseRepo := repositories.NewCourseRepository(pool)
+	quizRepo := repositories.NewQuizRepository(pool)
+	homeworkRepo := repositories.NewHomeworkRepository(pool)
+	liveClassRepo := repositories.NewLiveClassRepository(pool)
+	qrCheckInRepo := repositories.NewQRCheckInRepository(pool)
+	reportRepo := repositories.NewReportRepository(pool)
+	quotaRepo := repositories.NewQuotaRepository(pool)
+	moderationRepo := repositories.NewModerationRepository(pool)
+	exerciseSubstituteRepo := repositories.NewExerciseSubstituteRepository(pool)
+	limitationRepo := repositories.NewLimitationRepository(pool).WithKeyring(keyring)
+	journalRepo := repositories.NewJournalRepository(pool)
+	diaryRepo := repositories.NewDiaryRepository(pool)
+	supportRepo := repositories.NewSupportRepository(pool)
+	changelogRepo := repositories.NewChangelogRepository(pool)
+	clientVersionRepo := repositories.NewClientVersionRepository(pool)
+	integrationKeyRepo := repositories.NewIntegrationKeyRepository(pool).WithKeyring(keyring)
+	loginDeviceRepo := repositories.NewLoginDeviceRepository(pool)
+	retentionRepo := repositories.NewRetentionRepository(pool)
+	experimentRepo := repositories.NewExperimentRepository(pool)
+	questionnaireRepo := repositories.NewQuestionnaireRepository(pool)
+	streakRepo := repositories.NewStreakRepository(pool)
+	statsRecomputeRepo := repositories.NewStatsRecomputeRepository(pool)
+	reconciliationRepo := repositories.NewReconciliationRepository(pool)
+
+	// Initialize services
+	secretsProvider, err := cfg.Secrets.NewProvider()
+	if err != nil {
+		return nil, err
+	}
+	authService := services.NewAuthService(userRepo, cfg).WithSecrets(secretsProvider)
+	notificationService := services.NewNotificationService(notificationRepo)
+	usageService := services.NewUsageService(accessLogRepo)
+	accessLogWriter := middleware.NewAccessLogWriter(usageService, accessLogBufferSize)
+	accessLogWriter.Start()
+	quotaService := services.NewQuotaService(quotaRepo, userRepo)
+	filter, err := contentfilter.NewFilter(cfg.ContentFilter.Provider, cfg.ContentFilter.Words, cfg.ContentFilter.APIURL, cfg.ContentFilter.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize content filter: %w", err)
+	}
+	if cfg.ContentFilter.Provider == "api" {
+		// Like TTS, health follows the breaker on real calls
+		filter = contentfilter.WithBreaker(filter, dependencies.Register("content_filter", false, nil))
+	}
+	contentFilterService := services.NewContentFilterService(filter, moderationRepo, userRepo, &cfg.ContentFilter)
+	anonymizer := anonymize.New(cfg.Analytics.Anonymize, cfg.Analytics.HashKey)
+	reportService := services.NewReportService(reportRepo, anonymizer)
+	experimentService := services.NewExperimentService(experimentRepo, programRepo, userRepo)
+	questionnaireService := services.NewQuestionnaireService(questionnaireRepo, sessionRepo, programRepo)
+	invitationService := services.NewInvitationService(invitationRepo, groupRepo, programRepo, authService, &cfg.Invites)
+	displayService := services.NewDisplayService(displayTokenRepo, programRepo, exerciseRepo)
+	mediaStore, err := storage.NewLocalStore(filepath.Join(cfg.Upload.UploadPath, "media"), cfg.Upload.MediaBaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize media storage: %w", err)
+	}
+	dependencies.Register("media_storage", false, func(ctx context.Context) error {
+		_, err := os.Stat(mediaStore.Root())
+		return err
+	})
+	templateCache := services.NewTemplateCache(&cfg.TemplateCache)
+	coverService := services.NewCoverService(mediaStore, programRepo, quotaService, templateCache)
+	// Journal media is never served statically; it is streamed by the journal API after an access check
+	privateStore, err := storage.NewLocalStore(filepath.Join(cfg.Upload.UploadPath, "private"), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize private storage: %w", err)
+	}
+	journalService := services.NewJournalService(journalRepo, programRepo, exerciseRepo, userRepo, privateStore, quotaService, notificationService, &cfg.Journal)
+	metadataSchemaService := services.NewMetadataSchemaService(metadataSchemaRepo)
+	translationService := services.NewTranslationService(translationRepo, programRepo, exerciseRepo, templateCache)
+	exerciseService := services.NewExerciseService(exerciseRepo, programRepo, metadataSchemaService, templateCache)
+	exerciseSubstituteService := services.NewExerciseSubstituteService(exerciseSubstituteRepo, exerciseRepo)
+	limitationService := services.NewLimitationService(limitationRepo, programRepo, exerciseRepo)
+	snippetService := services.NewSnippetService(snippetRepo, userRepo, programRepo)
+	submissionService := services.NewSubmissionService(submissionRepo, programRepo, snippetService, notificationService, quotaService, contentFilterService, &cfg.Messages)
+	programService := services.NewProgramService(programRepo, exerciseRepo, userRepo, invitationService, coverService, metadataSchemaService, quotaService, contentFilterService, submissionService, limitationService, templateCache)
+	shareLinkService := services.NewShareLinkService(shareLinkRepo, programService, translationService, &cfg.Shares)
+	embedService := services.NewEmbedService(shareLinkService, &cfg.Shares, &cfg.Embed)
+
+	ttsProvider, err := tts.NewProvider(cfg.TTS.Provider, cfg.TTS.URL, cfg.TTS.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize TTS provider: %w", err)
+	}
+	if cfg.TTS.Provider != "" {
+		// No cheap probe for TTS providers; health follows the breaker on real generation calls
+		ttsProvider = tts.WithBreaker(ttsProvider, dependencies.Register("tts", false, nil))
+	}
+	audioCueService := services.NewAudioCueService(ttsProvider, mediaStore, userRepo, programService)
+	streakService := services.NewStreakService(streakRepo, sessionRepo, userRepo, &cfg.Streaks)
+	groupService := services.NewGroupService(groupRepo, streakService)
+	statsRecomputeService := services.NewStatsRecomputeService(statsRecomputeRepo, userRepo, sessionRepo, programRepo, streakService)
+	sessionService := services.NewSessionService(sessionRepo, programRepo, exerciseSubstituteRepo, notificationService, streakService, reconciliationRepo, &cfg.Sessions)
+	sessionService.WithExperiments(experimentRepo)
+	sessionService.WithQuestionnaires(questionnaireService)
+	diaryService := services.NewDiaryService(diaryRepo, sessionRepo)
+	supportService := services.NewSupportService(supportRepo, contentFilterService, notificationService)
+	changelogService := services.NewChangelogService(changelogRepo)
+	clientVersionService := services.NewClientVersionService(clientVersionRepo, &cfg.Clients)
+	integrationService := services.NewIntegrationService(integrationKeyRepo, programService, translationService, &cfg.Integrations)
+	userService := services.NewUserService(userRepo, programRepo, exerciseRepo, coverService)
+	studentOverviewService := services.NewStudentOverviewService(userService, sessionService, programRepo, sessionRepo, submissionRepo, programService, submissionService, translationService, limitationService)
+	submissionLabelService := services.NewSubmissionLabelService(submissionLabelRepo, submissionRepo)
+	exportService := services.NewExportService(submissionService, programRepo, userRepo)
+	scheduledMessageService := services.NewScheduledMessageService(scheduledMessageRepo, programRepo, submissionService, notificationService)
+	discussionService := services.NewDiscussionService(discussionRepo, programRepo, notificationService)
+	courseService := services.NewCourseService(courseRepo, programRepo)
+	quizService := services.NewQuizService(quizRepo, programRepo)
+	homeworkService := services.NewHomeworkService(homeworkRepo, programRepo, groupRepo, notificationService)
+	liveClassService := services.NewLiveClassService(liveClassRepo, programRepo, groupRepo, userRepo)
+	qrCheckInService := services.NewQRCheckInService(qrCheckInRepo, liveClassRepo, programRepo, liveClassService, sessionService, authService.SigningKeys(), &cfg.CheckIn)
+
+	mailer, err := mail.NewSender(cfg.Mail.SMTPHost, cfg.Mail.SMTPPort, cfg.Mail.SMTPUsername, cfg.Mail.SMTPPassword, cfg.Mail.From)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize mail sender: %w", err)
+	}
+	meetings, err := meeting.NewProvider(cfg.Meetings.Provider, cfg.Meetings.JitsiURL, cfg.Meetings.ZoomAccountID, cfg.Meetings.ZoomClientID, cfg.Meetings.ZoomClientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize meeting provider: %w", err)
+	}
+	if cfg.Meetings.Provider != "" {
+		// Like TTS, health follows the breaker on real calls
+		meetings = meeting.WithBreaker(meetings, dependencies.Register("video_meetings", false, nil))
+	}
+	bookingService := services.NewBookingService(bookingRepo, programRepo, mailer, meetings, notificationService, &cfg.Bookings)
+	presenceService := services.NewPresenceService(accessLogRepo, submissionService, &cfg.Presence)
+	moderationService := services.NewModerationService(moderationRepo, submissionRepo, programRepo, templateCache, &cfg.Moderation)
+	digestService := services.NewDigestService(notificationRepo, userRepo, sessionRepo, submissionRepo, homeworkRepo, streakService, mailer, &cfg.Digest)
+	loginDeviceService := services.NewLoginDeviceService(loginDeviceRepo, notificationService, mailer, &cfg.LoginAlerts, cfg.JWT.GetJWTExpiry())
+	authService.WithDevices(loginDeviceService)
+	retentionService := services.NewRetentionService(retentionRepo, &cfg.Retention)
+
+	// Initialize handlers
+	authHandler := handlers.NewAuthHandler(authService, invitationService)
+	programHandler := handlers.NewProgramHandler(programService, audioCueService, coverService, translationService, limitationService, templateCache)
+	shareLinkHandler := handlers.NewShareLinkHandler(shareLinkService)
+	embedHandler := handlers.NewEmbedHandler(embedService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	userHandler := handlers.NewUserHandler(userService)
+	submissionHandler := handlers.NewSubmissionHandler(submissionService, exportService)
+	snippetHandler := handlers.NewSnippetHandler(snippetService)
+	submissionLabelHandler := handlers.NewSubmissionLabelHandler(submissionLabelService)
+	scheduledMessageHandler := handlers.NewScheduledMessageHandler(scheduledMessageService)
+	discussionHandler := handlers.NewDiscussionHandler(discussionService)
+	bookingHandler := handlers.NewBookingHandler(bookingService)
+	courseHandler := handlers.NewCourseHandler(courseService)
+	quizHandler := handlers.NewQuizHandler(quizService)
+	homeworkHandler := handlers.NewHomeworkHandler(homeworkService)
+	liveClassHandler := handlers.NewLiveClassHandler(liveClassService)
+	qrCheckInHandler := handlers.NewQRCheckInHandler(qrCheckInService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService, digestService)
+	endpointStats := diagnostics.NewEndpointStats(diagnostics.DefaultWindow)
+	adminHandler := handlers.NewAdminHandler(usageService, submissionService, homeworkService, liveClassService, reportService, endpointStats, anonymizer, studentOverviewService)
+	invitationHandler := handlers.NewInvitationHandler(invitationService)
+	displayHandler := handlers.NewDisplayHandler(displayService)
+	groupHandler := handlers.NewGroupHandler(groupService, anonymizer)
+	experimentHandler := handlers.NewExperimentHandler(experimentService)
+	questionnaireHandler := handlers.NewQuestionnaireHandler(questionnaireService)
+	translationHandler := handlers.NewTranslationHandler(translationService)
+	exerciseHandler := handlers.NewExerciseHandler(exerciseService, translationService)
+	exerciseSubstituteHandler := handlers.NewExerciseSubstituteHandler(exerciseSubstituteService)
+	limitationHandler := handlers.NewLimitationHandler(limitationService)
+	journalHandler := handlers.NewJournalHandler(journalService)
+	diaryHandler := handlers.NewDiaryHandler(diaryService)
+	supportHandler := handlers.NewSupportHandler(supportService)
+	changelogHandler := handlers.NewChangelogHandler(changelogService)
+	clientVersionHandler := handlers.NewClientVersionHandler(clientVersionService)
+	integrationHandler := handlers.NewIntegrationHandler(integrationService)
+	loginDeviceHandler := handlers.NewLoginDeviceHandler(loginDeviceService)
+	retentionHandler := handlers.NewRetentionHandler(retentionService)
+	streakHandler := handlers.NewStreakHandler(streakService, statsRecomputeService)
+	metadataSchemaHandler := handlers.NewMetadataSchemaHandler(metadataSchemaService)
+	quotaHandler := handlers.NewQuotaHandler(quotaService)
+	moderationHandler := handlers.NewModerationHandler(moderationService)
+	presenceHandler := handlers.NewPresenceHandler(presenceService)
+	healthHandler := handlers.NewHealthHandler(dependencies, cfg.Server.APIVersion)
+	contractHandler := handlers.NewContractHandler()
+
+	router := newRouter(cfg, mediaStore, authService, accessLogWriter, displayService, clientVersionService, integrationService, endpointStats, authHandler, programHandler, exerciseHandler, shareLinkHandler, embedHandler, sessionHandler, userHandler, submissionHandler, submissionLabelHandler, snippetHandler, scheduledMessageHandler, discussionHandler, bookingHandler, courseHandler, quizHandler, homeworkHandler, liveClassHandler, qrCheckInHandler, notificationHandler, adminHandler, invitationHandler, displayHandler, groupHandler, experimentHandler, questionnaireHandler, translationHandler, exerciseSubstituteHandler, limitationHandler, journalHandler, diaryHandler, supportHandler, changelogHandler, clientVersionHandler, integrationHandler, loginDeviceHandler, retentionHandler, streakHandler, metadataSchemaHandler, quotaHandler, moderationHandler, presenceHandler, healthHandler, contractHandler)
+
+	return &Server{
+		Router:                  router,
+		Handler:                 middleware.HeadAsGet(router),
+		SessionService:          sessionService,
+		ProgramService:          programService,
+		ScheduledMessageService: scheduledMessageService,
+		HomeworkService:         homeworkService,
+		DigestService:           digestService,
+		StatsRecomputeService:   statsRecomputeService,
+		IntegrationService:      integrationService,
+		AuthService:             authService,
+		RetentionService:        retentionService,
+		AccessLogWriter:         accessLogWriter,
+	}, nil
+}
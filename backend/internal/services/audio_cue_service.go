@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/dependency"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/storage"
+	"github.com/xuangong/backend/pkg/tts"
+)
+
+// maxCountClips caps how many spoken counts are generated for repetition exercises
+const maxCountClips = 100
+
+// cuePhrases holds the spoken text for generic cues per supported language
+var cuePhrases = map[string]map[models.CueType]string{
+	"en": {
+		models.CuePrepStart:   "Get into position",
+		models.CueSideSwitch:  "Switch sides",
+		models.CueHalfway:     "Halfway",
+		models.CueExerciseEnd: "Done",
+		models.CueRestStart:   "Rest",
+		models.CueRestEnd:     "Get ready",
+		models.CueSessionEnd:  "Session complete",
+	},
+	"de": {
+		models.CuePrepStart:   "In Position gehen",
+		models.CueSideSwitch:  "Seite wechseln",
+		models.CueHalfway:     "Halbzeit",
+		models.CueExerciseEnd: "Fertig",
+		models.CueRestStart:   "Pause",
+		models.CueRestEnd:     "Bereit machen",
+		models.CueSessionEnd:  "Übung beendet",
+	},
+	"zh": {
+		models.CuePrepStart:   "预备",
+		models.CueSideSwitch:  "换边",
+		models.CueHalfway:     "一半",
+		models.CueExerciseEnd: "完成",
+		models.CueRestStart:   "休息",
+		models.CueRestEnd:     "准备",
+		models.CueSessionEnd:  "练习结束",
+	},
+}
+
+// AudioCueGeneration summarizes a pre-generation run
+type AudioCueGeneration struct {
+	Language  string `json:"language"`
+	Generated int    `json:"generated"`
+	Cached    int    `json:"cached"`
+	Failed    int    `json:"failed"`
+}
+
+// AudioCueService pre-generates spoken cue clips and attaches them to timelines.
+// Clips are content-addressed by language and text, so identical phrases are shared across programs.
+type AudioCueService struct {
+	provider       tts.Provider
+	store          storage.ObjectStore
+	userRepo       *repositories.UserRepository
+	programService *ProgramService
+}
+
+func NewAudioCueService(provider tts.Provider, store storage.ObjectStore, userRepo *repositories.UserRepository, programService *ProgramService) *AudioCueService {
+	return &AudioCueService{
+		provider:       provider,
+		store:          store,
+		userRepo:       userRepo,
+		programService: programService,
+	}
+}
+
+// GetTimeline returns the user's program timeline with URLs for any cue audio already generated in their language
+func (s *AudioCueService) GetTimeline(ctx context.Context, programID, userID uuid.UUID) (*models.Timeline, error) {
+	tl, err := s.programService.GetTimeline(ctx, programID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	language, err := s.userLanguage(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachAudio(ctx, tl, language); err != nil {
+		// Audio is optional; the timeline is still usable without it
+		log.Printf("[WARN] Failed to attach audio cues to timeline for program %s: %v", programID, err)
+	}
+
+	return tl, nil
+}
+
+// GenerateForProgram synthesizes all missing cue clips for a program in the user's language
+func (s *AudioCueService) GenerateForProgram(ctx context.Context, programID, userID uuid.UUID) (*AudioCueGeneration, error) {
+	tl, err := s.programService.GetTimeline(ctx, programID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	language, err := s.userLanguage(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AudioCueGeneration{Language: language}
+	phrases := s.phrasesFor(tl, language)
+	for i, text := range phrases {
+		key := audioKey(language, text)
+
+		exists, err := s.store.Exists(ctx, key)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to check audio cache").WithError(err)
+		}
+		if exists {
+			result.Cached++
+			continue
+		}
+
+		audio, err := s.provider.Synthesize(ctx, text, language)
+		if errors.Is(err, tts.ErrDisabled) {
+			return nil, appErrors.NewBadRequestError("Text-to-speech is not configured")
+		}
+		if errors.Is(err, dependency.ErrOpen) {
+			// The provider is known to be down; skip the rest instead of failing each phrase
+			log.Printf("[WARN] Skipping audio cue generation for program %s: %v", programID, err)
+			result.Failed += len(phrases) - i
+			break
+		}
+		if err != nil {
+			log.Printf("[WARN] Failed to synthesize %q (%s): %v", text, language, err)
+			result.Failed++
+			continue
+		}
+
+		if err := s.store.Put(ctx, key, audio.Data, audio.ContentType); err != nil {
+			return nil, appErrors.NewInternalError("Failed to store audio cue").WithError(err)
+		}
+		result.Generated++
+	}
+
+	return result, nil
+}
+
+func (s *AudioCueService) userLanguage(ctx context.Context, userID uuid.UUID) (string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", appErrors.NewInternalError("Failed to fetch user").WithError(err)
+	}
+	if user == nil {
+		return "", appErrors.NewNotFoundError("User")
+	}
+	if _, ok := cuePhrases[user.Language]; !ok {
+		return "en", nil
+	}
+	return user.Language, nil
+}
+
+// attachAudio sets AudioURL on cues whose clips are already cached, and fills the count clip table
+func (s *AudioCueService) attachAudio(ctx context.Context, tl *models.Timeline, language string) error {
+	urls := make(map[string]string)
+	lookup := func(text string) (string, error) {
+		if url, ok := urls[text]; ok {
+			return url, nil
+		}
+		key := audioKey(language, text)
+		exists, err := s.store.Exists(ctx, key)
+		if err != nil {
+			return "", err
+		}
+		url := ""
+		if exists {
+			url = s.store.URL(key)
+		}
+		urls[text] = url
+		return url, nil
+	}
+
+	for i := range tl.Cues {
+		text := cueText(tl.Cues[i], language)
+		if text == "" {
+			continue
+		}
+		url, err := lookup(text)
+		if err != nil {
+			return err
+		}
+		tl.Cues[i].AudioURL = url
+	}
+
+	for n := 1; n <= maxRepetitions(tl); n++ {
+		url, err := lookup(strconv.Itoa(n))
+		if err != nil {
+			return err
+		}
+		if url == "" {
+			continue
+		}
+		if tl.CountAudioURLs == nil {
+			tl.CountAudioURLs = make(map[int]string)
+		}
+		tl.CountAudioURLs[n] = url
+	}
+
+	return nil
+}
+
+// phrasesFor returns the distinct texts to synthesize for a timeline
+func (s *AudioCueService) phrasesFor(tl *models.Timeline, language string) []string {
+	seen := make(map[string]bool)
+	var phrases []string
+	add := func(text string) {
+		if text == "" || seen[text] {
+			return
+		}
+		seen[text] = true
+		phrases = append(phrases, text)
+	}
+
+	for _, cue := range tl.Cues {
+		add(cueText(cue, language))
+	}
+	for n := 1; n <= maxRepetitions(tl); n++ {
+		add(strconv.Itoa(n))
+	}
+
+	return phrases
+}
+
+// cueText returns what is spoken for a cue: the exercise name when it starts, a fixed phrase otherwise
+func cueText(cue models.Cue, language string) string {
+	if cue.Type == models.CueExerciseStart {
+		return cue.ExerciseName
+	}
+	return cuePhrases[language][cue.Type]
+}
+
+func maxRepetitions(tl *models.Timeline) int {
+	max := 0
+	for _, cue := range tl.Cues {
+		if cue.Type == models.CueExerciseStart && cue.Repetitions != nil && *cue.Repetitions > max {
+			max = *cue.Repetitions
+		}
+	}
+	if max > maxCountClips {
+		return maxCountClips
+	}
+	return max
+}
+
+func audioKey(language, text string) string {
+	sum := sha256.Sum256([]byte(language + "\x00" + text))
+	return fmt.Sprintf("audio/cues/%s/%s.mp3", language, hex.EncodeToString(sum[:16]))
+}
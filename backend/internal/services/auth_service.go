@@ -2,28 +2,98 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/xuangong/backend/internal/config"
 	"github.com/xuangong/backend/internal/models"
 	"github.com/xuangong/backend/internal/repositories"
 	"github.com/xuangong/backend/pkg/auth"
+	"github.com/xuangong/backend/pkg/clock"
 	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/secrets"
 )
 
 type AuthService struct {
 	userRepo *repositories.UserRepository
 	cfg      *config.Config
+	clock    clock.Clock
+	keys     *auth.SigningKeys
+	secrets  secrets.Provider
+	devices  *LoginDeviceService
 }
 
 func NewAuthService(userRepo *repositories.UserRepository, cfg *config.Config) *AuthService {
+	// A rotated-out secret keeps verifying until every token signed with it has expired
+	retainFor := max(cfg.JWT.GetJWTExpiry(), cfg.JWT.GetRefreshExpiry())
 	return &AuthService{
 		userRepo: userRepo,
 		cfg:      cfg,
+		clock:    clock.System,
+		keys:     auth.NewSigningKeys(cfg.JWT.Secret, cfg.JWT.PreviousSecrets, retainFor),
 	}
 }
 
+// WithSecrets sets the provider RefreshSigningKeys re-reads the JWT secrets from
+func (s *AuthService) WithSecrets(provider secrets.Provider) *AuthService {
+	s.secrets = provider
+	return s
+}
+
+// WithDevices records the device of each sign-in and binds the tokens to it, so revoking the
+// device signs it out
+func (s *AuthService) WithDevices(devices *LoginDeviceService) *AuthService {
+	s.devices = devices
+	return s
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *AuthService) WithClock(c clock.Clock) *AuthService {
+	s.clock = c
+	return s
+}
+
+// SigningKeys returns the secrets tokens are signed and verified with, shared with the other
+// services issuing JWTs
+func (s *AuthService) SigningKeys() *auth.SigningKeys {
+	return s.keys
+}
+
+// RefreshSigningKeys re-reads JWT_SECRET and JWT_PREVIOUS_SECRETS from the secrets provider and
+// reports whether the signing secret changed. Tokens signed with the old secret stay valid
+// until they expire.
+func (s *AuthService) RefreshSigningKeys(ctx context.Context) (bool, error) {
+	if s.secrets == nil {
+		return false, nil
+	}
+
+	current, err := s.secrets.Get(ctx, "JWT_SECRET")
+	if err != nil {
+		return false, fmt.Errorf("failed to read JWT_SECRET: %w", err)
+	}
+	if len(current) < 32 {
+		return false, fmt.Errorf("JWT_SECRET must be at least 32 characters, keeping the current secret")
+	}
+
+	var previous []string
+	value, err := s.secrets.Get(ctx, "JWT_PREVIOUS_SECRETS")
+	switch {
+	case errors.Is(err, secrets.ErrNotFound):
+	case err != nil:
+		return false, fmt.Errorf("failed to read JWT_PREVIOUS_SECRETS: %w", err)
+	default:
+		for _, secret := range strings.Split(value, ",") {
+			if secret = strings.TrimSpace(secret); secret != "" {
+				previous = append(previous, secret)
+			}
+		}
+	}
+
+	return s.keys.Rotate(current, previous, s.clock.Now()), nil
+}
+
 func (s *AuthService) Register(ctx context.Context, email, password, fullName string, role models.UserRole) (*models.User, *auth.TokenPair, error) {
 	// Check if email already exists
 	exists, err := s.userRepo.EmailExists(ctx, email)
@@ -54,7 +124,7 @@ func (s *AuthService) Register(ctx context.Context, email, password, fullName st
 	}
 
 	// Generate tokens
-	tokens, err := s.generateTokens(user)
+	tokens, err := s.generateTokens(user, "")
 	if err != nil {
 		return nil, nil, err
 	}
@@ -62,7 +132,14 @@ func (s *AuthService) Register(ctx context.Context, email, password, fullName st
 	return user, tokens, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, email, password string) (*models.User, *auth.TokenPair, error) {
+// RegistrationOpen reports whether users may sign up without an invitation
+func (s *AuthService) RegistrationOpen() bool {
+	return s.cfg.Features.OpenRegistration
+}
+
+// Login checks the credentials and issues tokens bound to the client's device. Signing in from
+// a new device alerts the user.
+func (s *AuthService) Login(ctx context.Context, email, password string, client models.LoginClient) (*models.User, *auth.TokenPair, error) {
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
@@ -82,8 +159,17 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (*model
 		return nil, nil, appErrors.NewAuthenticationError("Invalid email or password")
 	}
 
+	var deviceID string
+	if s.devices != nil {
+		id, err := s.devices.SignIn(ctx, user, client)
+		if err != nil {
+			return nil, nil, appErrors.NewInternalError("Failed to record login device").WithError(err)
+		}
+		deviceID = id.String()
+	}
+
 	// Generate tokens
-	tokens, err := s.generateTokens(user)
+	tokens, err := s.generateTokens(user, deviceID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -93,7 +179,8 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (*model
 
 func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*auth.TokenPair, error) {
 	// Validate refresh token
-	claims, err := auth.ValidateToken(refreshToken, s.cfg.JWT.Secret, auth.RefreshToken)
+	now := s.clock.Now()
+	claims, err := auth.ValidateTokenWithSecrets(refreshToken, s.keys.Verification(now), auth.RefreshToken, now)
 	if err != nil {
 		return nil, appErrors.NewAuthenticationError("Invalid refresh token")
 	}
@@ -112,8 +199,15 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*a
 		return nil, appErrors.NewAuthenticationError("User not found or inactive")
 	}
 
-	// Generate new token pair
-	tokens, err := s.generateTokens(user)
+	// Revoked devices cannot stay signed in
+	if s.devices != nil {
+		if err := s.devices.CheckActive(ctx, claims.DeviceID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Generate new token pair for the same device
+	tokens, err := s.generateTokens(user, claims.DeviceID)
 	if err != nil {
 		return nil, err
 	}
@@ -132,12 +226,15 @@ func (s *AuthService) GetUserByID(ctx context.Context, id uuid.UUID) (*models.Us
 	return user, nil
 }
 
-func (s *AuthService) generateTokens(user *models.User) (*auth.TokenPair, error) {
-	tokens, err := auth.GenerateTokenPair(
+// generateTokens issues a token pair, bound to the login device if deviceID is set
+func (s *AuthService) generateTokens(user *models.User, deviceID string) (*auth.TokenPair, error) {
+	tokens, err := auth.GenerateDeviceTokenPair(
 		user.ID.String(),
 		user.Email,
 		string(user.Role),
-		s.cfg.JWT.Secret,
+		deviceID,
+		s.keys.Current(),
+		s.clock.Now(),
 		s.cfg.JWT.GetJWTExpiry(),
 		s.cfg.JWT.GetRefreshExpiry(),
 	)
@@ -147,7 +244,7 @@ func (s *AuthService) generateTokens(user *models.User) (*auth.TokenPair, error)
 	return tokens, nil
 }
 
-func (s *AuthService) UpdateProfile(ctx context.Context, userID uuid.UUID, email, fullName *string, countdownVolume, startVolume, halfwayVolume, finishVolume *int) error {
+func (s *AuthService) UpdateProfile(ctx context.Context, userID uuid.UUID, email, fullName *string, countdownVolume, startVolume, halfwayVolume, finishVolume *int, language *string) error {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return appErrors.NewInternalError("Failed to fetch user").WithError(err)
@@ -186,6 +283,9 @@ func (s *AuthService) UpdateProfile(ctx context.Context, userID uuid.UUID, email
 	if finishVolume != nil {
 		user.FinishVolume = *finishVolume
 	}
+	if language != nil {
+		user.Language = *language
+	}
 
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return appErrors.NewInternalError("Failed to update profile").WithError(err)
@@ -223,11 +323,15 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, curr
 	return nil
 }
 
-func (s *AuthService) ValidateAccessToken(token string) (*auth.Claims, error) {
-	claims, err := auth.ValidateToken(token, s.cfg.JWT.Secret, auth.AccessToken)
+func (s *AuthService) ValidateAccessToken(ctx context.Context, token string) (*auth.Claims, error) {
+	now := s.clock.Now()
+	claims, err := auth.ValidateTokenWithSecrets(token, s.keys.Verification(now), auth.AccessToken, now)
 	if err != nil {
 		return nil, fmt.Errorf("invalid access token: %w", err)
 	}
+	if s.devices != nil && s.devices.IsRevoked(ctx, claims.DeviceID) {
+		return nil, fmt.Errorf("invalid access token: device %s was signed out", claims.DeviceID)
+	}
 	return claims, nil
 }
 
@@ -252,7 +356,7 @@ func (s *AuthService) Impersonate(ctx context.Context, adminID, targetUserID uui
 	}
 
 	// Generate tokens for the target user
-	tokens, err := s.generateTokens(targetUser)
+	tokens, err := s.generateTokens(targetUser, "")
 	if err != nil {
 		return nil, nil, err
 	}
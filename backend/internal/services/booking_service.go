@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/ical"
+	"github.com/xuangong/backend/pkg/mail"
+	"github.com/xuangong/backend/pkg/meeting"
+)
+
+// BookingService manages office hours: instructors publish availability slots and students
+// book them for 1:1 video reviews of one of their programs
+type BookingService struct {
+	bookingRepo         *repositories.BookingRepository
+	programRepo         *repositories.ProgramRepository
+	mailer              mail.Sender
+	meetings            meeting.Provider
+	notificationService *NotificationService
+	cfg                 *config.BookingsConfig
+	clock               clock.Clock
+}
+
+func NewBookingService(bookingRepo *repositories.BookingRepository, programRepo *repositories.ProgramRepository, mailer mail.Sender, meetings meeting.Provider, notificationService *NotificationService, cfg *config.BookingsConfig) *BookingService {
+	return &BookingService{
+		bookingRepo:         bookingRepo,
+		programRepo:         programRepo,
+		mailer:              mailer,
+		meetings:            meetings,
+		notificationService: notificationService,
+		cfg:                 cfg,
+		clock:               clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *BookingService) WithClock(c clock.Clock) *BookingService {
+	s.clock = c
+	return s
+}
+
+// PublishSlot offers a future time window for booking. Slots of the same instructor may not overlap.
+func (s *BookingService) PublishSlot(ctx context.Context, instructorID uuid.UUID, startsAt, endsAt time.Time) (*models.AvailabilitySlot, error) {
+	if !startsAt.After(s.clock.Now()) {
+		return nil, appErrors.NewBadRequestError("starts_at must be in the future")
+	}
+	if !endsAt.After(startsAt) {
+		return nil, appErrors.NewBadRequestError("ends_at must be after starts_at")
+	}
+	if endsAt.Sub(startsAt) > s.cfg.GetMaxSlotLength() {
+		return nil, appErrors.NewBadRequestError(fmt.Sprintf("Slots can be at most %d minutes long", s.cfg.MaxSlotMinutes))
+	}
+
+	overlaps, err := s.bookingRepo.SlotOverlaps(ctx, instructorID, startsAt, endsAt)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to check for overlapping slots").WithError(err)
+	}
+	if overlaps {
+		return nil, appErrors.NewConflictError("This slot overlaps one of your existing slots")
+	}
+
+	slot := &models.AvailabilitySlot{
+		InstructorID: instructorID,
+		StartsAt:     startsAt,
+		EndsAt:       endsAt,
+	}
+	if err := s.bookingRepo.CreateSlot(ctx, slot); err != nil {
+		return nil, appErrors.NewInternalError("Failed to publish slot").WithError(err)
+	}
+	return s.getSlot(ctx, slot.ID)
+}
+
+// ListSlots returns slots starting in [from, to). Open slots are listed for everyone; instructors
+// see their own booked slots too when filtering by themselves.
+func (s *BookingService) ListSlots(ctx context.Context, userID uuid.UUID, from, to time.Time, instructorID *uuid.UUID) ([]models.AvailabilitySlot, error) {
+	if !to.After(from) {
+		return nil, appErrors.NewBadRequestError("to must be after from")
+	}
+
+	includeBooked := instructorID != nil && *instructorID == userID
+	slots, err := s.bookingRepo.ListSlots(ctx, from, to, instructorID, includeBooked)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to list slots").WithError(err)
+	}
+	return slots, nil
+}
+
+// DeleteSlot withdraws one of the instructor's slots. Booked slots must be cancelled first
+// so the student is told.
+func (s *BookingService) DeleteSlot(ctx context.Context, id, instructorID uuid.UUID) error {
+	slot, err := s.getSlot(ctx, id)
+	if err != nil {
+		return err
+	}
+	if slot.InstructorID != instructorID {
+		return appErrors.NewAuthorizationError("You can only delete your own slots")
+	}
+	if slot.IsBooked {
+		return appErrors.NewConflictError("This slot is booked; cancel the booking first")
+	}
+
+	if err := s.bookingRepo.DeleteSlot(ctx, id); err != nil {
+		return appErrors.NewInternalError("Failed to delete slot").WithError(err)
+	}
+	return nil
+}
+
+// Book reserves a slot for a review of one of the student's assigned programs, creates a video
+// meeting for it and sends the join link with a calendar invitation to the student and the instructor
+func (s *BookingService) Book(ctx context.Context, studentID uuid.UUID, isAdmin bool, slotID, programID uuid.UUID, note *string) (*models.Booking, error) {
+	slot, err := s.getSlot(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+	if !slot.StartsAt.After(s.clock.Now()) {
+		return nil, appErrors.NewConflictError("This slot has already started")
+	}
+	if slot.InstructorID == studentID {
+		return nil, appErrors.NewBadRequestError("You cannot book your own slot")
+	}
+	if slot.IsBooked {
+		return nil, appErrors.NewConflictError("This slot is already booked")
+	}
+
+	if !isAdmin {
+		assignment, err := s.programRepo.GetUserProgram(ctx, studentID, programID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to verify program assignment").WithError(err)
+		}
+		if assignment == nil || !assignment.IsActive {
+			return nil, appErrors.NewAuthorizationError("This program is not assigned to you")
+		}
+	}
+
+	overlaps, err := s.bookingRepo.StudentHasOverlap(ctx, studentID, slot.StartsAt, slot.EndsAt)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to check for overlapping bookings").WithError(err)
+	}
+	if overlaps {
+		return nil, appErrors.NewConflictError("You already have a booking at this time")
+	}
+
+	booking := &models.Booking{
+		SlotID:    slotID,
+		StudentID: studentID,
+		ProgramID: programID,
+		Note:      note,
+	}
+	if err := s.bookingRepo.CreateBooking(ctx, booking); err != nil {
+		if errors.Is(err, repositories.ErrSlotTaken) {
+			return nil, appErrors.NewConflictError("This slot is already booked")
+		}
+		return nil, appErrors.NewInternalError("Failed to book slot").WithError(err)
+	}
+
+	s.createMeeting(ctx, booking.ID, slot)
+
+	created, err := s.getBooking(ctx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.sendInvitation(ctx, created, ical.MethodRequest)
+	s.notifyConfirmed(ctx, created)
+	return created, nil
+}
+
+// ListBookings returns the user's bookings, as student or as instructor
+func (s *BookingService) ListBookings(ctx context.Context, userID uuid.UUID, upcomingOnly bool) ([]models.Booking, error) {
+	bookings, err := s.bookingRepo.ListBookings(ctx, userID, upcomingOnly)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to list bookings").WithError(err)
+	}
+	return bookings, nil
+}
+
+// GetBooking returns a booking the user made or received as instructor
+func (s *BookingService) GetBooking(ctx context.Context, id, userID uuid.UUID, isAdmin bool) (*models.Booking, error) {
+	booking, err := s.getBooking(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && booking.StudentID != userID && booking.InstructorID != userID {
+		return nil, appErrors.NewNotFoundError("Booking")
+	}
+	return booking, nil
+}
+
+// Cancel cancels a confirmed booking, freeing the slot, and emails a calendar cancellation.
+// Students must cancel at least the configured notice before the start; instructors and
+// admins can cancel any time.
+func (s *BookingService) Cancel(ctx context.Context, id, userID uuid.UUID, isAdmin bool, reason *string) (*models.Booking, error) {
+	booking, err := s.GetBooking(ctx, id, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if booking.Status != models.BookingConfirmed {
+		return nil, appErrors.NewConflictError("This booking is already cancelled")
+	}
+
+	isStudent := booking.StudentID == userID && !isAdmin
+	if isStudent && booking.StartsAt.Sub(s.clock.Now()) < s.cfg.GetCancelNotice() {
+		return nil, appErrors.NewConflictError(fmt.Sprintf("Bookings can only be cancelled up to %d hours before they start", s.cfg.CancelNoticeHours))
+	}
+
+	cancelled, err := s.bookingRepo.CancelBooking(ctx, id, userID, reason)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to cancel booking").WithError(err)
+	}
+	if !cancelled {
+		return nil, appErrors.NewConflictError("This booking is already cancelled")
+	}
+
+	booking, err = s.getBooking(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.deleteMeeting(ctx, booking)
+	s.sendInvitation(ctx, booking, ical.MethodCancel)
+	return booking, nil
+}
+
+// Calendar returns the booking as an iCalendar file
+func (s *BookingService) Calendar(ctx context.Context, id, userID uuid.UUID, isAdmin bool) ([]byte, error) {
+	booking, err := s.GetBooking(ctx, id, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	return ical.Calendar("", s.clock.Now(), bookingEvent(booking)), nil
+}
+
+func (s *BookingService) getSlot(ctx context.Context, id uuid.UUID) (*models.AvailabilitySlot, error) {
+	slot, err := s.bookingRepo.GetSlot(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch slot").WithError(err)
+	}
+	if slot == nil {
+		return nil, appErrors.NewNotFoundError("Slot")
+	}
+	return slot, nil
+}
+
+func (s *BookingService) getBooking(ctx context.Context, id uuid.UUID) (*models.Booking, error) {
+	booking, err := s.bookingRepo.GetBooking(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch booking").WithError(err)
+	}
+	if booking == nil {
+		return nil, appErrors.NewNotFoundError("Booking")
+	}
+	return booking, nil
+}
+
+// createMeeting creates the video meeting for a new booking. The booking stands without a
+// link if no provider is configured or the provider fails.
+func (s *BookingService) createMeeting(ctx context.Context, bookingID uuid.UUID, slot *models.AvailabilitySlot) {
+	m, err := s.meetings.Create(ctx, meeting.Request{
+		Topic:    "Xuan Gong video review",
+		Start:    slot.StartsAt,
+		Duration: slot.EndsAt.Sub(slot.StartsAt),
+	})
+	if errors.Is(err, meeting.ErrDisabled) {
+		return
+	}
+	if err != nil {
+		log.Printf("[WARN] Failed to create meeting for booking %s: %v", bookingID, err)
+		return
+	}
+	if err := s.bookingRepo.SetMeeting(ctx, bookingID, m.Provider, m.ID, m.JoinURL); err != nil {
+		log.Printf("[WARN] Failed to store meeting for booking %s: %v", bookingID, err)
+	}
+}
+
+// deleteMeeting removes the video meeting of a cancelled booking at the provider
+func (s *BookingService) deleteMeeting(ctx context.Context, booking *models.Booking) {
+	if booking.MeetingID == nil {
+		return
+	}
+	if err := s.meetings.Delete(ctx, *booking.MeetingID); err != nil && !errors.Is(err, meeting.ErrDisabled) {
+		log.Printf("[WARN] Failed to delete meeting for booking %s: %v", booking.ID, err)
+	}
+}
+
+// notifyConfirmed tells the student and the instructor about a new booking, with the join link if there is one
+func (s *BookingService) notifyConfirmed(ctx context.Context, booking *models.Booking) {
+	title := fmt.Sprintf("Video review booked: %s", booking.ProgramName)
+	body := fmt.Sprintf("%s with %s on %s (UTC)", booking.ProgramName, booking.InstructorName, booking.StartsAt.UTC().Format("Monday, 2 January 2006 15:04"))
+	payload := map[string]interface{}{
+		"booking_id": booking.ID.String(),
+	}
+	if booking.MeetingURL != nil {
+		payload["meeting_url"] = *booking.MeetingURL
+	}
+
+	for _, userID := range []uuid.UUID{booking.StudentID, booking.InstructorID} {
+		if _, err := s.notificationService.Notify(ctx, userID, models.NotificationBookingConfirmed, title, &body, payload); err != nil {
+			log.Printf("[WARN] Failed to notify user %s about booking %s: %v", userID, booking.ID, err)
+		}
+	}
+}
+
+// sendInvitation emails the student and the instructor a calendar invitation or cancellation.
+// The booking is already stored, so failures are only logged.
+func (s *BookingService) sendInvitation(ctx context.Context, booking *models.Booking, method string) {
+	when := booking.StartsAt.UTC().Format("Monday, 2 January 2006 15:04")
+	subject := fmt.Sprintf("Video review booked: %s", booking.ProgramName)
+	body := fmt.Sprintf("Your 1:1 video review of %s with %s is confirmed for %s (UTC).", booking.ProgramName, booking.InstructorName, when)
+	if booking.MeetingURL != nil {
+		body += "\n\nJoin the video call: " + *booking.MeetingURL
+	}
+	body += "\n\nThe calendar invitation is attached."
+	if method == ical.MethodCancel {
+		subject = fmt.Sprintf("Video review cancelled: %s", booking.ProgramName)
+		body = fmt.Sprintf("The 1:1 video review of %s with %s on %s (UTC) has been cancelled.", booking.ProgramName, booking.InstructorName, when)
+		if booking.CancelReason != nil && *booking.CancelReason != "" {
+			body += "\n\nReason: " + *booking.CancelReason
+		}
+	}
+
+	msg := mail.Message{
+		To:      []string{booking.StudentEmail, booking.InstructorEmail},
+		Subject: subject,
+		Body:    body,
+		Attachments: []mail.Attachment{{
+			Filename:    "invite.ics",
+			ContentType: ical.ContentType + "; method=" + method,
+			Data:        ical.Calendar(method, s.clock.Now(), bookingEvent(booking)),
+		}},
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil && !errors.Is(err, mail.ErrDisabled) {
+		log.Printf("[WARN] Failed to email booking %s: %v", booking.ID, err)
+	}
+}
+
+// bookingEvent describes a booking as a calendar event. Cancellations reuse the UID with a
+// higher sequence so calendars update the original entry.
+func bookingEvent(booking *models.Booking) ical.Event {
+	event := ical.Event{
+		UID:       booking.ID.String() + "@xuangong",
+		Start:     booking.StartsAt,
+		End:       booking.EndsAt,
+		Summary:   fmt.Sprintf("Video review: %s", booking.ProgramName),
+		Organizer: ical.Person{Name: booking.InstructorName, Email: booking.InstructorEmail},
+		Attendees: []ical.Person{{Name: booking.StudentName, Email: booking.StudentEmail}},
+	}
+	if booking.Note != nil {
+		event.Description = *booking.Note
+	}
+	if booking.MeetingURL != nil {
+		event.Location = *booking.MeetingURL
+		event.URL = *booking.MeetingURL
+	}
+	if booking.Status == models.BookingCancelled {
+		event.Sequence = 1
+		event.Cancelled = true
+	}
+	return event
+}
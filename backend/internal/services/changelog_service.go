@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// ChangelogService manages the release notes admins write for the apps. Users see the entries
+// for everyone and for their role; admins manage all of them.
+type ChangelogService struct {
+	changelogRepo *repositories.ChangelogRepository
+}
+
+func NewChangelogService(changelogRepo *repositories.ChangelogRepository) *ChangelogService {
+	return &ChangelogService{
+		changelogRepo: changelogRepo,
+	}
+}
+
+// Create adds an entry; it is unseen for every user in its audience
+func (s *ChangelogService) Create(ctx context.Context, entry *models.ChangelogEntry) error {
+	if entry.Audience == "" {
+		entry.Audience = models.ChangelogAll
+	}
+	if err := s.changelogRepo.Create(ctx, entry); err != nil {
+		return appErrors.NewInternalError("Failed to create changelog entry").WithError(err)
+	}
+	return nil
+}
+
+// List returns the entries the user may see, newest first. Admins see every audience.
+func (s *ChangelogService) List(ctx context.Context, isAdmin bool, limit, offset int) ([]models.ChangelogEntry, error) {
+	var audiences []models.ChangelogAudience
+	if !isAdmin {
+		audiences = audiencesFor(false)
+	}
+	entries, err := s.changelogRepo.List(ctx, audiences, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch changelog").WithError(err)
+	}
+	return entries, nil
+}
+
+// Unseen returns the entries for the user's role they have not dismissed yet, newest first
+func (s *ChangelogService) Unseen(ctx context.Context, userID uuid.UUID, isAdmin bool) ([]models.ChangelogEntry, error) {
+	entries, err := s.changelogRepo.ListUnseen(ctx, userID, audiencesFor(isAdmin))
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch changelog").WithError(err)
+	}
+	return entries, nil
+}
+
+// Dismiss marks entries as seen by the user on all their devices
+func (s *ChangelogService) Dismiss(ctx context.Context, userID uuid.UUID, entryIDs []uuid.UUID) error {
+	if err := s.changelogRepo.Dismiss(ctx, userID, entryIDs); err != nil {
+		return appErrors.NewInternalError("Failed to dismiss changelog entries").WithError(err)
+	}
+	return nil
+}
+
+// Update changes an entry. Users who dismissed it are not shown it again.
+func (s *ChangelogService) Update(ctx context.Context, id uuid.UUID, update *models.ChangelogEntryUpdate) (*models.ChangelogEntry, error) {
+	entry, err := s.changelogRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch changelog entry").WithError(err)
+	}
+	if entry == nil {
+		return nil, appErrors.NewNotFoundError("Changelog entry")
+	}
+
+	if update.Version != nil {
+		entry.Version = *update.Version
+	}
+	if update.Title != nil {
+		entry.Title = *update.Title
+	}
+	if update.Body != nil {
+		entry.Body = *update.Body
+	}
+	if update.Audience != nil {
+		entry.Audience = *update.Audience
+	}
+
+	if err := s.changelogRepo.Update(ctx, entry); err != nil {
+		return nil, appErrors.NewInternalError("Failed to update changelog entry").WithError(err)
+	}
+	return entry, nil
+}
+
+// Delete removes an entry
+func (s *ChangelogService) Delete(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.changelogRepo.Delete(ctx, id)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete changelog entry").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Changelog entry")
+	}
+	return nil
+}
+
+// audiencesFor returns the audiences whose entries a user of the role is shown
+func audiencesFor(isAdmin bool) []models.ChangelogAudience {
+	if isAdmin {
+		return []models.ChangelogAudience{models.ChangelogAll, models.ChangelogAdmins}
+	}
+	return []models.ChangelogAudience{models.ChangelogAll, models.ChangelogStudents}
+}
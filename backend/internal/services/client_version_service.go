@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/semver"
+)
+
+// ClientVersionService decides which app versions are still supported. The configured policy
+// applies unless an admin overrides it for a platform. Overrides are kept in memory, since
+// every request is checked, and reloaded periodically so changes reach all instances.
+type ClientVersionService struct {
+	clientVersionRepo *repositories.ClientVersionRepository
+	cfg               *config.ClientsConfig
+	clock             clock.Clock
+
+	mu        sync.Mutex
+	overrides map[string]models.ClientVersionPolicy
+	loadedAt  time.Time
+}
+
+func NewClientVersionService(clientVersionRepo *repositories.ClientVersionRepository, cfg *config.ClientsConfig) *ClientVersionService {
+	return &ClientVersionService{
+		clientVersionRepo: clientVersionRepo,
+		cfg:               cfg,
+		clock:             clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *ClientVersionService) WithClock(c clock.Clock) *ClientVersionService {
+	s.clock = c
+	return s
+}
+
+// Check returns UPGRADE_REQUIRED if version is older than the oldest supported on the platform.
+// Unknown platforms are not gated; an unreadable version is a bad request.
+func (s *ClientVersionService) Check(ctx context.Context, platform, version string) error {
+	if !slices.Contains(models.ClientPlatforms, platform) {
+		return nil
+	}
+	policy := s.policy(ctx, platform)
+	if policy.MinVersion == "" {
+		return nil
+	}
+
+	current, err := semver.Parse(version)
+	if err != nil {
+		return appErrors.NewBadRequestError("Invalid X-App-Version, expected a version like 2.4.0")
+	}
+	// Checked when the policy was set
+	minimum, _ := semver.Parse(policy.MinVersion)
+	if current.Less(minimum) {
+		return appErrors.NewUpgradeRequiredError(platform, policy.MinVersion, policy.UpgradeURL)
+	}
+	return nil
+}
+
+// List returns the policy in effect for every platform
+func (s *ClientVersionService) List(ctx context.Context) ([]models.ClientVersionPolicy, error) {
+	if err := s.reload(ctx); err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch client version policies").WithError(err)
+	}
+	policies := make([]models.ClientVersionPolicy, len(models.ClientPlatforms))
+	for i, platform := range models.ClientPlatforms {
+		policies[i] = s.policy(ctx, platform)
+	}
+	return policies, nil
+}
+
+// Set overrides the configured policy for a platform
+func (s *ClientVersionService) Set(ctx context.Context, platform, minVersion, upgradeURL string, adminID uuid.UUID) (*models.ClientVersionPolicy, error) {
+	if !slices.Contains(models.ClientPlatforms, platform) {
+		return nil, appErrors.NewNotFoundError("Platform")
+	}
+	if minVersion != "" {
+		parsed, err := semver.Parse(minVersion)
+		if err != nil {
+			return nil, appErrors.NewBadRequestError("min_version must be a version like 2.4.0")
+		}
+		minVersion = parsed.String()
+	}
+
+	policy := &models.ClientVersionPolicy{
+		Platform:   platform,
+		MinVersion: minVersion,
+		UpgradeURL: upgradeURL,
+		Source:     "admin",
+		UpdatedBy:  &adminID,
+	}
+	if err := s.clientVersionRepo.Save(ctx, policy); err != nil {
+		return nil, appErrors.NewInternalError("Failed to save client version policy").WithError(err)
+	}
+	s.invalidate()
+	return policy, nil
+}
+
+// Reset drops the admin override for a platform, so the configured policy applies again
+func (s *ClientVersionService) Reset(ctx context.Context, platform string) (*models.ClientVersionPolicy, error) {
+	if !slices.Contains(models.ClientPlatforms, platform) {
+		return nil, appErrors.NewNotFoundError("Platform")
+	}
+	if _, err := s.clientVersionRepo.Delete(ctx, platform); err != nil {
+		return nil, appErrors.NewInternalError("Failed to reset client version policy").WithError(err)
+	}
+	s.invalidate()
+
+	policy := s.policy(ctx, platform)
+	return &policy, nil
+}
+
+// policy returns the platform's override, or the configured policy without one
+func (s *ClientVersionService) policy(ctx context.Context, platform string) models.ClientVersionPolicy {
+	s.mu.Lock()
+	stale := s.loadedAt.IsZero() || s.clock.Now().Sub(s.loadedAt) >= s.cfg.GetRefreshInterval()
+	s.mu.Unlock()
+	if stale {
+		// Gating keeps working with the last known overrides, or the configuration, while the
+		// database is unavailable
+		if err := s.reload(ctx); err != nil {
+			log.Printf("[WARN] Failed to reload client version policies: %v", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if override, ok := s.overrides[platform]; ok {
+		return override
+	}
+	return models.ClientVersionPolicy{
+		Platform:   platform,
+		MinVersion: s.cfg.MinVersions[platform],
+		UpgradeURL: s.cfg.UpgradeURLs[platform],
+		Source:     "config",
+	}
+}
+
+// reload fetches the admin overrides from the database
+func (s *ClientVersionService) reload(ctx context.Context) error {
+	policies, err := s.clientVersionRepo.List(ctx)
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		// Try again after the refresh interval rather than on every request
+		s.loadedAt = now
+		return err
+	}
+	s.overrides = make(map[string]models.ClientVersionPolicy, len(policies))
+	for _, policy := range policies {
+		policy.Source = "admin"
+		s.overrides[policy.Platform] = policy
+	}
+	s.loadedAt = now
+	return nil
+}
+
+// invalidate makes the next check reload the overrides
+func (s *ClientVersionService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = time.Time{}
+}
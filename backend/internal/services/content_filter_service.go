@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/contentfilter"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// ContentFilterService screens new user-written text with the configured filter. Depending on
+// the configured action, flagged text is refused or stored and put in the moderation queue.
+// Text by admins is never filtered.
+type ContentFilterService struct {
+	filter         contentfilter.Filter
+	moderationRepo *repositories.ModerationRepository
+	userRepo       *repositories.UserRepository
+	cfg            *config.ContentFilterConfig
+}
+
+func NewContentFilterService(filter contentfilter.Filter, moderationRepo *repositories.ModerationRepository, userRepo *repositories.UserRepository, cfg *config.ContentFilterConfig) *ContentFilterService {
+	return &ContentFilterService{
+		filter:         filter,
+		moderationRepo: moderationRepo,
+		userRepo:       userRepo,
+		cfg:            cfg,
+	}
+}
+
+// Screen checks text the author is about to store as field. With the reject action flagged
+// text fails with CONTENT_REJECTED; otherwise the verdict is returned for Flag once the content
+// is stored, nil if there is nothing to flag. Text is let through if the filter is unavailable.
+func (s *ContentFilterService) Screen(ctx context.Context, authorID uuid.UUID, field, text string) (*contentfilter.Result, error) {
+	author, err := s.userRepo.GetByID(ctx, authorID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch user").WithError(err)
+	}
+	if author != nil && author.Role == models.RoleAdmin {
+		return nil, nil
+	}
+
+	result, err := s.filter.Check(ctx, text)
+	if err != nil {
+		log.Printf("[WARN] Content filter unavailable, letting %s by %s through: %v", field, authorID, err)
+		return nil, nil
+	}
+	if !result.Flagged {
+		return nil, nil
+	}
+	if s.cfg.Action == "reject" {
+		return nil, appErrors.NewContentRejectedError(field, result.Matches)
+	}
+	return &result, nil
+}
+
+// Flag puts stored content that Screen flagged in the moderation queue. The content is already
+// stored, so failures are only logged.
+func (s *ContentFilterService) Flag(ctx context.Context, contentType models.ModerationContentType, contentID uuid.UUID, result *contentfilter.Result) {
+	if result == nil {
+		return
+	}
+	if err := s.moderationRepo.Flag(ctx, contentType, contentID, result.Matches); err != nil {
+		log.Printf("[WARN] Failed to flag %s %s for moderation: %v", contentType, contentID, err)
+	}
+}
@@ -0,0 +1,381 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// CourseService manages courses: ordered modules of programs that unlock for enrolled students
+// by rule. Programs of unlocked modules are assigned to the student automatically.
+type CourseService struct {
+	courseRepo  *repositories.CourseRepository
+	programRepo *repositories.ProgramRepository
+	clock       clock.Clock
+}
+
+func NewCourseService(courseRepo *repositories.CourseRepository, programRepo *repositories.ProgramRepository) *CourseService {
+	return &CourseService{
+		courseRepo:  courseRepo,
+		programRepo: programRepo,
+		clock:       clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *CourseService) WithClock(c clock.Clock) *CourseService {
+	s.clock = c
+	return s
+}
+
+func (s *CourseService) Create(ctx context.Context, createdBy uuid.UUID, name string, description *string, isPublished bool, modules []models.CourseModule) (*models.Course, error) {
+	if err := s.validateModules(ctx, modules); err != nil {
+		return nil, err
+	}
+
+	course := &models.Course{
+		Name:        name,
+		Description: description,
+		IsPublished: isPublished,
+		CreatedBy:   &createdBy,
+		Modules:     modules,
+	}
+	if err := s.courseRepo.Create(ctx, course); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create course").WithError(err)
+	}
+
+	return s.getCourse(ctx, course.ID)
+}
+
+// List returns all courses to admins, and published or enrolled courses to students
+func (s *CourseService) List(ctx context.Context, userID uuid.UUID, isAdmin bool) ([]models.Course, error) {
+	var filter *uuid.UUID
+	if !isAdmin {
+		filter = &userID
+	}
+	courses, err := s.courseRepo.List(ctx, filter)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch courses").WithError(err)
+	}
+	return courses, nil
+}
+
+// Get returns a course with its curriculum. Students only see published courses and courses they are enrolled in.
+func (s *CourseService) Get(ctx context.Context, id, userID uuid.UUID, isAdmin bool) (*models.Course, error) {
+	course, err := s.getCourse(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if isAdmin || course.IsPublished {
+		return course, nil
+	}
+
+	enrollment, err := s.courseRepo.GetEnrollment(ctx, id, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch enrollment").WithError(err)
+	}
+	if enrollment == nil {
+		return nil, appErrors.NewNotFoundError("Course")
+	}
+	return course, nil
+}
+
+// Update changes course details. Non-nil modules replace the whole curriculum; progress is
+// computed from sessions, so enrolled students keep what they completed.
+func (s *CourseService) Update(ctx context.Context, id uuid.UUID, name, description *string, isPublished *bool, modules []models.CourseModule) (*models.Course, error) {
+	course, err := s.getCourse(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != nil {
+		course.Name = *name
+	}
+	if description != nil {
+		course.Description = description
+	}
+	if isPublished != nil {
+		course.IsPublished = *isPublished
+	}
+	if modules != nil {
+		if err := s.validateModules(ctx, modules); err != nil {
+			return nil, err
+		}
+		course.Modules = modules
+	}
+
+	if err := s.courseRepo.Update(ctx, course, modules != nil); err != nil {
+		return nil, appErrors.NewInternalError("Failed to update course").WithError(err)
+	}
+
+	return s.getCourse(ctx, id)
+}
+
+func (s *CourseService) Delete(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.courseRepo.Delete(ctx, id)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete course").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Course")
+	}
+	return nil
+}
+
+// EnrollSelf enrolls a student in a published course
+func (s *CourseService) EnrollSelf(ctx context.Context, courseID, userID uuid.UUID) (*models.CourseProgress, error) {
+	course, err := s.getCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if !course.IsPublished {
+		return nil, appErrors.NewNotFoundError("Course")
+	}
+
+	if _, err := s.courseRepo.Enroll(ctx, courseID, userID, nil); err != nil {
+		return nil, appErrors.NewInternalError("Failed to enroll in course").WithError(err)
+	}
+	return s.progress(ctx, course, userID)
+}
+
+// Enroll enrolls students on behalf of an admin and reports how many were newly enrolled
+func (s *CourseService) Enroll(ctx context.Context, courseID, enrolledBy uuid.UUID, userIDs []uuid.UUID) (int, error) {
+	course, err := s.getCourse(ctx, courseID)
+	if err != nil {
+		return 0, err
+	}
+
+	enrolled := 0
+	for _, userID := range userIDs {
+		created, err := s.courseRepo.Enroll(ctx, courseID, userID, &enrolledBy)
+		if err != nil {
+			return enrolled, appErrors.NewInternalError("Failed to enroll user").WithError(err)
+		}
+		if created {
+			enrolled++
+		}
+		// Assigns the programs of modules that are open right away
+		if _, err := s.progress(ctx, course, userID); err != nil {
+			return enrolled, err
+		}
+	}
+	return enrolled, nil
+}
+
+// Unenroll removes a student from a course. Programs already assigned stay assigned.
+func (s *CourseService) Unenroll(ctx context.Context, courseID, userID uuid.UUID) error {
+	removed, err := s.courseRepo.Unenroll(ctx, courseID, userID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to unenroll user").WithError(err)
+	}
+	if !removed {
+		return appErrors.NewNotFoundError("Enrollment")
+	}
+	return nil
+}
+
+func (s *CourseService) ListEnrollments(ctx context.Context, courseID uuid.UUID) ([]models.CourseEnrollment, error) {
+	if _, err := s.getCourse(ctx, courseID); err != nil {
+		return nil, err
+	}
+	enrollments, err := s.courseRepo.ListEnrollments(ctx, courseID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch enrollments").WithError(err)
+	}
+	return enrollments, nil
+}
+
+// Progress returns a student's progress through a course and assigns the programs of
+// modules that have unlocked since the last check
+func (s *CourseService) Progress(ctx context.Context, courseID, userID uuid.UUID) (*models.CourseProgress, error) {
+	course, err := s.getCourse(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+	return s.progress(ctx, course, userID)
+}
+
+func (s *CourseService) progress(ctx context.Context, course *models.Course, userID uuid.UUID) (*models.CourseProgress, error) {
+	enrollment, err := s.courseRepo.GetEnrollment(ctx, course.ID, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch enrollment").WithError(err)
+	}
+	if enrollment == nil {
+		return nil, appErrors.NewNotFoundError("Enrollment")
+	}
+
+	var programIDs []uuid.UUID
+	for _, module := range course.Modules {
+		for _, program := range module.Programs {
+			programIDs = append(programIDs, program.ProgramID)
+		}
+	}
+	counts, err := s.courseRepo.CompletedSessionCounts(ctx, userID, programIDs)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to count completed sessions").WithError(err)
+	}
+	quizzes, err := s.courseRepo.QuizCounts(ctx, userID, programIDs)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to count passed quizzes").WithError(err)
+	}
+
+	progress := computeCourseProgress(course, enrollment, counts, quizzes, s.clock.Now())
+	s.assignUnlocked(ctx, course, enrollment, progress)
+	return progress, nil
+}
+
+// assignUnlocked assigns the programs of unlocked modules the student does not have yet.
+// Deactivated assignments are left alone, and failures only delay the assignment to the next check.
+func (s *CourseService) assignUnlocked(ctx context.Context, course *models.Course, enrollment *models.CourseEnrollment, progress *models.CourseProgress) {
+	assignedBy := enrollment.EnrolledBy
+	if assignedBy == nil {
+		assignedBy = course.CreatedBy
+	}
+
+	for _, module := range progress.Modules {
+		if !module.Unlocked {
+			continue
+		}
+		for _, program := range module.Programs {
+			existing, err := s.programRepo.GetUserProgram(ctx, enrollment.UserID, program.ProgramID)
+			if err != nil {
+				log.Printf("[WARN] Failed to check assignment of program %s for course %s: %v", program.ProgramID, course.ID, err)
+				continue
+			}
+			if existing != nil {
+				continue
+			}
+			err = s.programRepo.AssignToUser(ctx, &models.UserProgram{
+				UserID:         enrollment.UserID,
+				ProgramID:      program.ProgramID,
+				AssignedBy:     assignedBy,
+				IsActive:       true,
+				CustomSettings: make(map[string]interface{}),
+			})
+			if err != nil {
+				log.Printf("[WARN] Failed to assign program %s for course %s: %v", program.ProgramID, course.ID, err)
+			}
+		}
+	}
+}
+
+// computeCourseProgress evaluates unlock rules in module order. A module is complete when it
+// is unlocked and every program has its required number of completed sessions and all its
+// quizzes passed.
+func computeCourseProgress(course *models.Course, enrollment *models.CourseEnrollment, counts map[uuid.UUID]int, quizzes map[uuid.UUID]repositories.QuizCount, now time.Time) *models.CourseProgress {
+	progress := &models.CourseProgress{
+		CourseID:   course.ID,
+		UserID:     enrollment.UserID,
+		EnrolledAt: enrollment.EnrolledAt,
+		Modules:    make([]models.ModuleProgress, 0, len(course.Modules)),
+	}
+
+	var required, done int
+	previousComplete := true
+	for _, module := range course.Modules {
+		mp := models.ModuleProgress{
+			ModuleID: module.ID,
+			Position: module.Position,
+			Title:    module.Title,
+			Programs: make([]models.ProgramProgress, 0, len(module.Programs)),
+		}
+
+		switch module.UnlockRule {
+		case models.UnlockAfterPrevious:
+			mp.Unlocked = previousComplete
+		case models.UnlockAfterDays:
+			unlocksAt := enrollment.EnrolledAt.AddDate(0, 0, *module.UnlockAfterDays)
+			mp.Unlocked = !now.Before(unlocksAt)
+			if !mp.Unlocked {
+				mp.UnlocksAt = &unlocksAt
+			}
+		default:
+			mp.Unlocked = true
+		}
+
+		mp.Completed = mp.Unlocked
+		for _, program := range module.Programs {
+			completed := counts[program.ProgramID]
+			quiz := quizzes[program.ProgramID]
+			pp := models.ProgramProgress{
+				ProgramID:         program.ProgramID,
+				ProgramName:       program.ProgramName,
+				RequiredSessions:  program.RequiredSessions,
+				CompletedSessions: completed,
+				QuizCount:         quiz.Total,
+				QuizzesPassed:     quiz.Passed,
+				Completed:         completed >= program.RequiredSessions && quiz.Passed >= quiz.Total,
+			}
+			mp.Completed = mp.Completed && pp.Completed
+			mp.Programs = append(mp.Programs, pp)
+
+			// Each required session and each quiz counts as one step towards the course
+			required += program.RequiredSessions + quiz.Total
+			done += min(completed, program.RequiredSessions) + quiz.Passed
+		}
+
+		previousComplete = previousComplete && mp.Completed
+		progress.Modules = append(progress.Modules, mp)
+	}
+
+	progress.Completed = previousComplete
+	if required > 0 {
+		progress.Percent = math.Round(float64(done)/float64(required)*1000) / 10
+	}
+	return progress
+}
+
+// validateModules checks unlock rules and that every program exists, filling in defaults
+func (s *CourseService) validateModules(ctx context.Context, modules []models.CourseModule) error {
+	for i := range modules {
+		module := &modules[i]
+		if module.UnlockRule == "" {
+			module.UnlockRule = models.UnlockImmediate
+		}
+		if module.UnlockRule != models.UnlockAfterDays {
+			module.UnlockAfterDays = nil
+		} else if module.UnlockAfterDays == nil {
+			return appErrors.NewBadRequestError(fmt.Sprintf("Module %d: unlock_after_days is required for the after_days rule", i+1))
+		}
+
+		seen := make(map[uuid.UUID]bool)
+		for j := range module.Programs {
+			program := &module.Programs[j]
+			if seen[program.ProgramID] {
+				return appErrors.NewBadRequestError(fmt.Sprintf("Module %d lists program %s twice", i+1, program.ProgramID))
+			}
+			seen[program.ProgramID] = true
+			if program.RequiredSessions == 0 {
+				program.RequiredSessions = 1
+			}
+
+			existing, err := s.programRepo.GetByID(ctx, program.ProgramID)
+			if err != nil {
+				return appErrors.NewInternalError("Failed to fetch program").WithError(err)
+			}
+			if existing == nil {
+				return appErrors.NewBadRequestError(fmt.Sprintf("Module %d: program %s not found", i+1, program.ProgramID))
+			}
+		}
+	}
+	return nil
+}
+
+func (s *CourseService) getCourse(ctx context.Context, id uuid.UUID) (*models.Course, error) {
+	course, err := s.courseRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch course").WithError(err)
+	}
+	if course == nil {
+		return nil, appErrors.NewNotFoundError("Course")
+	}
+	return course, nil
+}
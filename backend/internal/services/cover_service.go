@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/storage"
+	"github.com/xuangong/backend/pkg/thumbnail"
+)
+
+// coverSizes are the thumbnail variants generated for every cover image.
+// The largest one is exposed as cover_url.
+var coverSizes = []thumbnail.Size{
+	{Name: "small", Width: 160},
+	{Name: "medium", Width: 480},
+	{Name: "large", Width: 1200},
+}
+
+// CoverService manages program cover images.
+// Covers are content-addressed, so identical uploads and selected covers share storage.
+type CoverService struct {
+	store         storage.ObjectStore
+	programRepo   *repositories.ProgramRepository
+	quotaService  *QuotaService
+	templateCache *TemplateCache
+}
+
+func NewCoverService(store storage.ObjectStore, programRepo *repositories.ProgramRepository, quotaService *QuotaService, templateCache *TemplateCache) *CoverService {
+	return &CoverService{
+		store:         store,
+		programRepo:   programRepo,
+		quotaService:  quotaService,
+		templateCache: templateCache,
+	}
+}
+
+// Upload generates thumbnails for an image and sets it as the program's cover. The upload
+// counts against the program owner's storage quota.
+func (s *CoverService) Upload(ctx context.Context, programID, userID uuid.UUID, userRole models.UserRole, data []byte) (*models.Program, error) {
+	program, err := s.editableProgram(ctx, programID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	size := int64(len(data))
+	if program.OwnedBy != nil {
+		if err := s.quotaService.CheckStorage(ctx, *program.OwnedBy, program.ID, size); err != nil {
+			return nil, err
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	prefix := "covers/" + hex.EncodeToString(sum[:16])
+
+	exists, err := s.store.Exists(ctx, coverKey(prefix, coverSizes[len(coverSizes)-1].Name))
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to check cover image").WithError(err)
+	}
+	if !exists {
+		variants, err := thumbnail.Generate(data, coverSizes)
+		if errors.Is(err, thumbnail.ErrUnsupportedFormat) || errors.Is(err, thumbnail.ErrTooLarge) {
+			return nil, appErrors.NewBadRequestError(err.Error())
+		}
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to generate thumbnails").WithError(err)
+		}
+		// coverSizes is ordered by width, so the largest variant is written last and marks a complete set
+		for _, size := range coverSizes {
+			if err := s.store.Put(ctx, coverKey(prefix, size.Name), variants[size.Name], thumbnail.ContentType); err != nil {
+				return nil, appErrors.NewInternalError("Failed to store cover image").WithError(err)
+			}
+		}
+	}
+
+	return s.setCover(ctx, program, &prefix, &size)
+}
+
+// SelectFrom reuses the cover of another program the user can see. Nothing is uploaded, so
+// it doesn't count against the storage quota.
+func (s *CoverService) SelectFrom(ctx context.Context, programID, sourceID, userID uuid.UUID, userRole models.UserRole) (*models.Program, error) {
+	program, err := s.editableProgram(ctx, programID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.programRepo.GetByID(ctx, sourceID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if source == nil {
+		return nil, appErrors.NewNotFoundError("Program")
+	}
+	isOwner := source.OwnedBy != nil && *source.OwnedBy == userID
+	if !source.IsPublic && !source.IsTemplate && !isOwner && userRole != models.RoleAdmin {
+		return nil, appErrors.NewAuthorizationError("You don't have access to this program")
+	}
+	if source.CoverImageKey == nil {
+		return nil, appErrors.NewBadRequestError("Source program has no cover image")
+	}
+
+	return s.setCover(ctx, program, source.CoverImageKey, nil)
+}
+
+// Remove clears the program's cover. Stored variants are kept since other programs may share them.
+func (s *CoverService) Remove(ctx context.Context, programID, userID uuid.UUID, userRole models.UserRole) error {
+	program, err := s.editableProgram(ctx, programID, userID, userRole)
+	if err != nil {
+		return err
+	}
+	_, err = s.setCover(ctx, program, nil, nil)
+	return err
+}
+
+// Attach fills in cover_url and cover_thumbnails from the stored cover key
+func (s *CoverService) Attach(program *models.Program) {
+	if program.CoverImageKey == nil {
+		program.CoverURL = nil
+		program.CoverThumbnails = nil
+		return
+	}
+
+	program.CoverThumbnails = make(map[string]string, len(coverSizes))
+	for _, size := range coverSizes {
+		program.CoverThumbnails[size.Name] = s.store.URL(coverKey(*program.CoverImageKey, size.Name))
+	}
+	coverURL := program.CoverThumbnails[coverSizes[len(coverSizes)-1].Name]
+	program.CoverURL = &coverURL
+}
+
+func (s *CoverService) setCover(ctx context.Context, program *models.Program, key *string, sizeBytes *int64) (*models.Program, error) {
+	if err := s.programRepo.SetCoverImage(ctx, program.ID, key, sizeBytes); err != nil {
+		return nil, appErrors.NewInternalError("Failed to update cover image").WithError(err)
+	}
+	program.CoverImageKey = key
+	s.Attach(program)
+	s.templateCache.Invalidate()
+	return program, nil
+}
+
+// editableProgram loads a program the user may change: admins can edit any program, owners their own
+func (s *CoverService) editableProgram(ctx context.Context, programID, userID uuid.UUID, userRole models.UserRole) (*models.Program, error) {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return nil, appErrors.NewNotFoundError("Program")
+	}
+
+	isOwner := program.OwnedBy != nil && *program.OwnedBy == userID
+	if userRole != models.RoleAdmin && !isOwner {
+		return nil, appErrors.NewAuthorizationError("You don't have permission to edit this program")
+	}
+	return program, nil
+}
+
+func coverKey(prefix, size string) string {
+	return prefix + "/" + size + ".jpg"
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// DiaryService manages students' practice diaries. Entries belong to the student; instructors
+// can only read the ones the student shared.
+type DiaryService struct {
+	diaryRepo   *repositories.DiaryRepository
+	sessionRepo *repositories.SessionRepository
+}
+
+func NewDiaryService(diaryRepo *repositories.DiaryRepository, sessionRepo *repositories.SessionRepository) *DiaryService {
+	return &DiaryService{
+		diaryRepo:   diaryRepo,
+		sessionRepo: sessionRepo,
+	}
+}
+
+// Create adds an entry to the student's diary, dated today unless a date is given
+func (s *DiaryService) Create(ctx context.Context, entry *models.DiaryEntry) error {
+	if entry.EntryDate == "" {
+		entry.EntryDate = time.Now().UTC().Format("2006-01-02")
+	}
+	if entry.SessionIDs == nil {
+		entry.SessionIDs = []uuid.UUID{}
+	}
+	if err := s.checkSessions(ctx, entry.UserID, entry.SessionIDs); err != nil {
+		return err
+	}
+
+	if err := s.diaryRepo.Create(ctx, entry); err != nil {
+		return appErrors.NewInternalError("Failed to create diary entry").WithError(err)
+	}
+	return nil
+}
+
+// List returns the student's own entries, newest day first
+func (s *DiaryService) List(ctx context.Context, userID uuid.UUID, filter repositories.DiaryFilter, limit, offset int) ([]models.DiaryEntry, error) {
+	entries, err := s.diaryRepo.List(ctx, userID, filter, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch diary entries").WithError(err)
+	}
+	return entries, nil
+}
+
+// ListShared returns the entries a student shared with their instructors
+func (s *DiaryService) ListShared(ctx context.Context, studentID uuid.UUID, filter repositories.DiaryFilter, limit, offset int) ([]models.DiaryEntry, error) {
+	filter.SharedOnly = true
+	return s.List(ctx, studentID, filter, limit, offset)
+}
+
+// Search finds the student's own entries matching a query
+func (s *DiaryService) Search(ctx context.Context, userID uuid.UUID, q string, limit, offset int) ([]models.DiarySearchResult, error) {
+	results, err := s.diaryRepo.Search(ctx, userID, q, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to search diary entries").WithError(err)
+	}
+	return results, nil
+}
+
+// Get returns an entry the user may read: their own, or a shared one for instructors
+func (s *DiaryService) Get(ctx context.Context, id, userID uuid.UUID, isAdmin bool) (*models.DiaryEntry, error) {
+	entry, err := s.diaryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch diary entry").WithError(err)
+	}
+	if entry == nil || (entry.UserID != userID && !(isAdmin && entry.Shared)) {
+		return nil, appErrors.NewNotFoundError("Diary entry")
+	}
+	return entry, nil
+}
+
+// Update changes one of the student's entries
+func (s *DiaryService) Update(ctx context.Context, id, userID uuid.UUID, update *models.DiaryEntryUpdate) (*models.DiaryEntry, error) {
+	entry, err := s.ownEntry(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.EntryDate != nil {
+		entry.EntryDate = *update.EntryDate
+	}
+	if update.Title != nil {
+		entry.Title = *update.Title
+	}
+	if update.Content != nil {
+		entry.Content = *update.Content
+	}
+	if update.Shared != nil {
+		entry.Shared = *update.Shared
+	}
+	if update.SessionIDs != nil {
+		if err := s.checkSessions(ctx, userID, *update.SessionIDs); err != nil {
+			return nil, err
+		}
+		entry.SessionIDs = *update.SessionIDs
+	}
+
+	if err := s.diaryRepo.Update(ctx, entry); err != nil {
+		return nil, appErrors.NewInternalError("Failed to update diary entry").WithError(err)
+	}
+	return entry, nil
+}
+
+// Delete removes one of the student's entries
+func (s *DiaryService) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	entry, err := s.ownEntry(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.diaryRepo.Delete(ctx, entry.ID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete diary entry").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Diary entry")
+	}
+	return nil
+}
+
+// ownEntry loads one of the user's own entries; other users' entries are reported as missing
+func (s *DiaryService) ownEntry(ctx context.Context, id, userID uuid.UUID) (*models.DiaryEntry, error) {
+	entry, err := s.diaryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch diary entry").WithError(err)
+	}
+	if entry == nil || entry.UserID != userID {
+		return nil, appErrors.NewNotFoundError("Diary entry")
+	}
+	return entry, nil
+}
+
+// checkSessions ensures entries only link to the student's own practice sessions
+func (s *DiaryService) checkSessions(ctx context.Context, userID uuid.UUID, sessionIDs []uuid.UUID) error {
+	for _, sessionID := range sessionIDs {
+		session, err := s.sessionRepo.GetByID(ctx, sessionID)
+		if err != nil {
+			return appErrors.NewInternalError("Failed to fetch session").WithError(err)
+		}
+		if session == nil || session.UserID != userID {
+			return appErrors.NewBadRequestError("Diary entries can only be linked to your own practice sessions")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/digest"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/mail"
+)
+
+// digestPeriod is how far back a digest looks at practice and how far ahead at homework
+const digestPeriod = 7 * 24 * time.Hour
+
+// DigestService compiles each user's weekly progress email and sends it during the configured
+// send window in the user's time zone
+type DigestService struct {
+	notificationRepo *repositories.NotificationRepository
+	userRepo         *repositories.UserRepository
+	sessionRepo      *repositories.SessionRepository
+	submissionRepo   *repositories.SubmissionRepository
+	homeworkRepo     *repositories.HomeworkRepository
+	streakService    *StreakService
+	mailer           mail.Sender
+	cfg              *config.DigestConfig
+	clock            clock.Clock
+}
+
+func NewDigestService(notificationRepo *repositories.NotificationRepository, userRepo *repositories.UserRepository, sessionRepo *repositories.SessionRepository, submissionRepo *repositories.SubmissionRepository, homeworkRepo *repositories.HomeworkRepository, streakService *StreakService, mailer mail.Sender, cfg *config.DigestConfig) *DigestService {
+	return &DigestService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		submissionRepo:   submissionRepo,
+		homeworkRepo:     homeworkRepo,
+		streakService:    streakService,
+		mailer:           mailer,
+		cfg:              cfg,
+		clock:            clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *DigestService) WithClock(c clock.Clock) *DigestService {
+	s.clock = c
+	return s
+}
+
+// GetPreferences returns the user's notification preferences
+func (s *DigestService) GetPreferences(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	prefs, err := s.notificationRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch notification preferences").WithError(err)
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences changes the given preferences and keeps the others
+func (s *DigestService) UpdatePreferences(ctx context.Context, userID uuid.UUID, weeklyDigest *bool, timezone *string) (*models.NotificationPreferences, error) {
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if weeklyDigest != nil {
+		prefs.WeeklyDigest = *weeklyDigest
+	}
+	if timezone != nil {
+		if _, err := time.LoadLocation(*timezone); err != nil || *timezone == "" || *timezone == "Local" {
+			return nil, appErrors.NewBadRequestError("timezone must be an IANA time zone such as Europe/Berlin")
+		}
+		prefs.Timezone = *timezone
+	}
+
+	if err := s.notificationRepo.SavePreferences(ctx, userID, prefs); err != nil {
+		return nil, appErrors.NewInternalError("Failed to save notification preferences").WithError(err)
+	}
+	return prefs, nil
+}
+
+// Preview compiles the digest the user would receive now
+func (s *DigestService) Preview(ctx context.Context, userID uuid.UUID) (*models.WeeklyDigest, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch user").WithError(err)
+	}
+	if user == nil {
+		return nil, appErrors.NewNotFoundError("User")
+	}
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := s.compile(ctx, user.ID, user.FullName, user.Language, prefs.Timezone, s.clock.Now())
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to compile digest").WithError(err)
+	}
+	return d, nil
+}
+
+// SendDue emails the digest to every user whose send window is open and who has not received
+// it in this window yet. Users are claimed before sending, so a failed email is not retried
+// until the next week; users with nothing to report are skipped. Returns the number sent.
+func (s *DigestService) SendDue(ctx context.Context) (int, error) {
+	recipients, err := s.notificationRepo.ListDigestRecipients(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.clock.Now()
+	weekday := s.cfg.GetSendWeekday()
+	sent := 0
+	for _, r := range recipients {
+		windowStart, ok := digest.Window(now, digest.Location(r.Timezone), weekday, s.cfg.SendHour)
+		if !ok || (r.LastDigestAt != nil && !r.LastDigestAt.Before(windowStart)) {
+			continue
+		}
+		claimed, err := s.notificationRepo.ClaimDigest(ctx, r.UserID, windowStart.UTC(), now.UTC())
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+
+		d, err := s.compile(ctx, r.UserID, r.FullName, r.Language, r.Timezone, now)
+		if err != nil {
+			log.Printf("[WARN] Failed to compile digest for user %s: %v", r.UserID, err)
+			continue
+		}
+		if d.IsEmpty() {
+			continue
+		}
+		subject, body, err := digest.Render(d)
+		if err != nil {
+			log.Printf("[WARN] Failed to render digest for user %s: %v", r.UserID, err)
+			continue
+		}
+		if err := s.mailer.Send(ctx, mail.Message{To: []string{r.Email}, Subject: subject, Body: body}); err != nil {
+			log.Printf("[WARN] Failed to email digest to user %s: %v", r.UserID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// compile gathers the week before now: completed sessions, streaks, unread feedback and
+// unfinished homework due in the week ahead
+func (s *DigestService) compile(ctx context.Context, userID uuid.UUID, fullName, language, timezone string, now time.Time) (*models.WeeklyDigest, error) {
+	d := &models.WeeklyDigest{
+		UserID:           userID,
+		FullName:         fullName,
+		Language:         language,
+		Timezone:         timezone,
+		PeriodStart:      now.Add(-digestPeriod),
+		PeriodEnd:        now,
+		UpcomingHomework: make([]models.Homework, 0),
+	}
+
+	var err error
+	d.SessionsCompleted, d.PracticeMinutes, err = s.sessionRepo.GetPeriodTotals(ctx, userID, d.PeriodStart, d.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	d.CurrentStreak, d.LongestStreak, err = s.streakService.Streaks(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.submissionRepo.GetUnreadCount(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	d.UnreadFeedback = unread.Total
+
+	homework, err := s.homeworkRepo.ListForStudent(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range homework {
+		if h.CompletedAt == nil && h.DueAt.After(now) && h.DueAt.Before(now.Add(digestPeriod)) {
+			h.Status = models.HomeworkPending
+			d.UpcomingHomework = append(d.UpcomingHomework, h)
+		}
+	}
+
+	return d, nil
+}
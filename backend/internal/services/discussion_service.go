@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/mention"
+	"github.com/xuangong/backend/pkg/youtube"
+)
+
+// DiscussionService manages program discussion boards. Boards are visible to admins, the
+// program owner and students assigned to the program; admins moderate them.
+type DiscussionService struct {
+	discussionRepo      *repositories.DiscussionRepository
+	programRepo         *repositories.ProgramRepository
+	notificationService *NotificationService
+}
+
+func NewDiscussionService(discussionRepo *repositories.DiscussionRepository, programRepo *repositories.ProgramRepository, notificationService *NotificationService) *DiscussionService {
+	return &DiscussionService{
+		discussionRepo:      discussionRepo,
+		programRepo:         programRepo,
+		notificationService: notificationService,
+	}
+}
+
+// ListTopics returns the program's topics, pinned first and then by latest activity
+func (s *DiscussionService) ListTopics(ctx context.Context, programID, userID uuid.UUID, isAdmin bool, limit, offset int) ([]models.DiscussionTopic, error) {
+	if err := s.checkAccess(ctx, programID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	topics, err := s.discussionRepo.ListTopics(ctx, programID, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to list topics").WithError(err)
+	}
+	return topics, nil
+}
+
+// CreateTopic opens a topic on the program's board and notifies mentioned members
+func (s *DiscussionService) CreateTopic(ctx context.Context, programID, userID uuid.UUID, isAdmin bool, title, content string, youtubeURL *string) (*models.DiscussionTopic, error) {
+	if err := validateYouTubeURL(youtubeURL); err != nil {
+		return nil, err
+	}
+	if err := s.checkAccess(ctx, programID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+	mentions, err := s.resolveMentions(ctx, programID, userID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	topic := &models.DiscussionTopic{
+		ProgramID:  programID,
+		AuthorID:   userID,
+		Title:      title,
+		Content:    content,
+		YouTubeURL: youtubeURL,
+	}
+	if err := s.discussionRepo.CreateTopic(ctx, topic); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create topic").WithError(err)
+	}
+
+	s.notify(ctx, mentions, models.NotificationMessageMention, fmt.Sprintf("You were mentioned in \"%s\"", title), content, topic)
+
+	return s.getTopic(ctx, topic.ID)
+}
+
+// GetTopic returns a topic with its replies
+func (s *DiscussionService) GetTopic(ctx context.Context, id, userID uuid.UUID, isAdmin bool) (*models.DiscussionTopicWithReplies, error) {
+	topic, err := s.getAccessibleTopic(ctx, id, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := s.discussionRepo.ListReplies(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch replies").WithError(err)
+	}
+
+	return &models.DiscussionTopicWithReplies{
+		DiscussionTopic: *topic,
+		Replies:         replies,
+	}, nil
+}
+
+// ModerateTopic pins or locks a topic. Nil values are left unchanged. Admin only, checked by the router.
+func (s *DiscussionService) ModerateTopic(ctx context.Context, id uuid.UUID, isPinned, isLocked *bool) (*models.DiscussionTopic, error) {
+	topic, err := s.getTopic(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if isPinned != nil {
+		topic.IsPinned = *isPinned
+	}
+	if isLocked != nil {
+		topic.IsLocked = *isLocked
+	}
+	if err := s.discussionRepo.SetTopicFlags(ctx, id, topic.IsPinned, topic.IsLocked); err != nil {
+		return nil, appErrors.NewInternalError("Failed to update topic").WithError(err)
+	}
+	return topic, nil
+}
+
+// DeleteTopic removes a topic. Students can only delete their own topics.
+func (s *DiscussionService) DeleteTopic(ctx context.Context, id, userID uuid.UUID, isAdmin bool) error {
+	topic, err := s.getAccessibleTopic(ctx, id, userID, isAdmin)
+	if err != nil {
+		return err
+	}
+	if !isAdmin && topic.AuthorID != userID {
+		return appErrors.NewAuthorizationError("You can only delete your own topics")
+	}
+
+	if err := s.discussionRepo.DeleteTopic(ctx, id); err != nil {
+		return appErrors.NewInternalError("Failed to delete topic").WithError(err)
+	}
+	return nil
+}
+
+// CreateReply answers a topic and notifies its author and mentioned members.
+// Locked topics only accept replies from admins.
+func (s *DiscussionService) CreateReply(ctx context.Context, topicID, userID uuid.UUID, isAdmin bool, content string, youtubeURL *string) (*models.DiscussionReply, error) {
+	if err := validateYouTubeURL(youtubeURL); err != nil {
+		return nil, err
+	}
+	topic, err := s.getAccessibleTopic(ctx, topicID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if topic.IsLocked && !isAdmin {
+		return nil, appErrors.NewAuthorizationError("This topic is locked")
+	}
+	mentions, err := s.resolveMentions(ctx, topic.ProgramID, userID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &models.DiscussionReply{
+		TopicID:    topicID,
+		AuthorID:   userID,
+		Content:    content,
+		YouTubeURL: youtubeURL,
+	}
+	if err := s.discussionRepo.CreateReply(ctx, reply); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create reply").WithError(err)
+	}
+
+	s.notify(ctx, mentions, models.NotificationMessageMention, fmt.Sprintf("You were mentioned in \"%s\"", topic.Title), content, topic)
+	if topic.AuthorID != userID && !slices.Contains(mentions, topic.AuthorID) {
+		s.notify(ctx, []uuid.UUID{topic.AuthorID}, models.NotificationDiscussionReply, fmt.Sprintf("New reply in \"%s\"", topic.Title), content, topic)
+	}
+
+	created, err := s.discussionRepo.GetReply(ctx, topicID, reply.ID)
+	if err != nil || created == nil {
+		return nil, appErrors.NewInternalError("Failed to fetch reply").WithError(err)
+	}
+	return created, nil
+}
+
+// DeleteReply removes a reply. Students can only delete their own replies.
+func (s *DiscussionService) DeleteReply(ctx context.Context, topicID, replyID, userID uuid.UUID, isAdmin bool) error {
+	if _, err := s.getAccessibleTopic(ctx, topicID, userID, isAdmin); err != nil {
+		return err
+	}
+
+	reply, err := s.discussionRepo.GetReply(ctx, topicID, replyID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch reply").WithError(err)
+	}
+	if reply == nil {
+		return appErrors.NewNotFoundError("Reply")
+	}
+	if !isAdmin && reply.AuthorID != userID {
+		return appErrors.NewAuthorizationError("You can only delete your own replies")
+	}
+
+	if err := s.discussionRepo.DeleteReply(ctx, replyID); err != nil {
+		return appErrors.NewInternalError("Failed to delete reply").WithError(err)
+	}
+	return nil
+}
+
+// checkAccess verifies that the program exists and the user may see its board
+func (s *DiscussionService) checkAccess(ctx context.Context, programID, userID uuid.UUID, isAdmin bool) error {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return appErrors.NewNotFoundError("Program")
+	}
+	if isAdmin {
+		return nil
+	}
+
+	members, err := s.discussionRepo.BoardMembers(ctx, programID, []uuid.UUID{userID})
+	if err != nil {
+		return appErrors.NewInternalError("Failed to verify program access").WithError(err)
+	}
+	if len(members) == 0 {
+		return appErrors.NewAuthorizationError("You are not assigned to this program")
+	}
+	return nil
+}
+
+func (s *DiscussionService) getTopic(ctx context.Context, id uuid.UUID) (*models.DiscussionTopic, error) {
+	topic, err := s.discussionRepo.GetTopic(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch topic").WithError(err)
+	}
+	if topic == nil {
+		return nil, appErrors.NewNotFoundError("Topic")
+	}
+	return topic, nil
+}
+
+func (s *DiscussionService) getAccessibleTopic(ctx context.Context, id, userID uuid.UUID, isAdmin bool) (*models.DiscussionTopic, error) {
+	topic, err := s.getTopic(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAccess(ctx, topic.ProgramID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+	return topic, nil
+}
+
+// resolveMentions returns the users mentioned in content, excluding the author, and rejects
+// mentions of anyone who cannot see the board
+func (s *DiscussionService) resolveMentions(ctx context.Context, programID, authorID uuid.UUID, content string) ([]uuid.UUID, error) {
+	var mentions []uuid.UUID
+	for _, id := range mention.Parse(content) {
+		if id != authorID {
+			mentions = append(mentions, id)
+		}
+	}
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+
+	members, err := s.discussionRepo.BoardMembers(ctx, programID, mentions)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to verify mentions").WithError(err)
+	}
+	if len(members) != len(mentions) {
+		return nil, appErrors.NewBadRequestError("Only members of the program can be mentioned")
+	}
+	return mentions, nil
+}
+
+// notify sends a notification about a topic to each user. The post is already stored,
+// so failures are only logged.
+func (s *DiscussionService) notify(ctx context.Context, userIDs []uuid.UUID, notificationType models.NotificationType, title, body string, topic *models.DiscussionTopic) {
+	payload := map[string]interface{}{
+		"program_id": topic.ProgramID.String(),
+		"topic_id":   topic.ID.String(),
+	}
+	for _, userID := range userIDs {
+		if _, err := s.notificationService.Notify(ctx, userID, notificationType, title, &body, payload); err != nil {
+			log.Printf("[WARN] Failed to notify user %s about topic %s: %v", userID, topic.ID, err)
+		}
+	}
+}
+
+func validateYouTubeURL(youtubeURL *string) error {
+	if youtubeURL != nil && *youtubeURL != "" {
+		if _, err := youtube.ValidateURL(*youtubeURL); err != nil {
+			return appErrors.NewBadRequestError(fmt.Sprintf("Invalid YouTube URL: %v", err))
+		}
+	}
+	return nil
+}
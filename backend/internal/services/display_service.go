@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/internal/timeline"
+	"github.com/xuangong/backend/pkg/auth"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// DisplayService manages read-only display tokens and serves the program timers shared
+// screens show. Displays see no user data, only the programs their token is scoped to.
+type DisplayService struct {
+	displayRepo  *repositories.DisplayTokenRepository
+	programRepo  *repositories.ProgramRepository
+	exerciseRepo *repositories.ExerciseRepository
+	clock        clock.Clock
+}
+
+func NewDisplayService(displayRepo *repositories.DisplayTokenRepository, programRepo *repositories.ProgramRepository, exerciseRepo *repositories.ExerciseRepository) *DisplayService {
+	return &DisplayService{
+		displayRepo:  displayRepo,
+		programRepo:  programRepo,
+		exerciseRepo: exerciseRepo,
+		clock:        clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *DisplayService) WithClock(c clock.Clock) *DisplayService {
+	s.clock = c
+	return s
+}
+
+// Create issues a display token for the programs. The plain token is only returned here.
+// expiresInDays of 0 creates a token that stays valid until revoked.
+func (s *DisplayService) Create(ctx context.Context, createdBy uuid.UUID, name string, programIDs []uuid.UUID, expiresInDays int) (*models.DisplayToken, error) {
+	for _, programID := range programIDs {
+		program, err := s.programRepo.GetByID(ctx, programID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+		}
+		if program == nil {
+			return nil, appErrors.NewNotFoundError("Program")
+		}
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to generate display token").WithError(err)
+	}
+
+	display := &models.DisplayToken{
+		TokenHash:  auth.HashOpaqueToken(token),
+		Name:       name,
+		ProgramIDs: programIDs,
+		CreatedBy:  &createdBy,
+	}
+	if expiresInDays > 0 {
+		expiresAt := s.clock.Now().Add(time.Duration(expiresInDays) * 24 * time.Hour)
+		display.ExpiresAt = &expiresAt
+	}
+
+	if err := s.displayRepo.Create(ctx, display); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create display token").WithError(err)
+	}
+
+	display.Token = token
+	return display, nil
+}
+
+func (s *DisplayService) List(ctx context.Context, activeOnly bool) ([]models.DisplayToken, error) {
+	tokens, err := s.displayRepo.List(ctx, activeOnly)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch display tokens").WithError(err)
+	}
+	return tokens, nil
+}
+
+func (s *DisplayService) Revoke(ctx context.Context, id uuid.UUID) error {
+	display, err := s.displayRepo.GetByID(ctx, id)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch display token").WithError(err)
+	}
+	if display == nil {
+		return appErrors.NewNotFoundError("Display token")
+	}
+
+	revoked, err := s.displayRepo.Revoke(ctx, id)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to revoke display token").WithError(err)
+	}
+	if !revoked {
+		return appErrors.NewBadRequestError("Display token is already revoked")
+	}
+	return nil
+}
+
+// Authenticate returns the active display token for the plain token, recording its use
+func (s *DisplayService) Authenticate(ctx context.Context, token string) (*models.DisplayToken, error) {
+	display, err := s.displayRepo.GetActiveByTokenHash(ctx, auth.HashOpaqueToken(token))
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch display token").WithError(err)
+	}
+	if display == nil {
+		return nil, appErrors.NewAuthenticationError("Invalid or expired display token")
+	}
+
+	if err := s.displayRepo.TouchLastUsed(ctx, display.ID); err != nil {
+		log.Printf("[WARN] Failed to record use of display token %s: %v", display.ID, err)
+	}
+	return display, nil
+}
+
+// ListPrograms returns the programs the display may show, without timelines.
+// Programs deleted since the token was created are left out.
+func (s *DisplayService) ListPrograms(ctx context.Context, display *models.DisplayToken) ([]models.DisplayProgram, error) {
+	programs := make([]models.DisplayProgram, 0, len(display.ProgramIDs))
+	for _, programID := range display.ProgramIDs {
+		program, err := s.programRepo.GetByID(ctx, programID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+		}
+		if program == nil {
+			continue
+		}
+		programs = append(programs, models.DisplayProgram{
+			ID:          program.ID,
+			Name:        program.Name,
+			Description: program.Description,
+		})
+	}
+	return programs, nil
+}
+
+// GetProgram returns a program with its timeline in the default settings. Programs outside
+// the token's scope are reported as not found.
+func (s *DisplayService) GetProgram(ctx context.Context, display *models.DisplayToken, programID uuid.UUID) (*models.DisplayProgram, error) {
+	if !slices.Contains(display.ProgramIDs, programID) {
+		return nil, appErrors.NewNotFoundError("Program")
+	}
+
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return nil, appErrors.NewNotFoundError("Program")
+	}
+
+	exercises, err := s.exerciseRepo.ListByProgramID(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch exercises").WithError(err)
+	}
+
+	return &models.DisplayProgram{
+		ID:          program.ID,
+		Name:        program.Name,
+		Description: program.Description,
+		Timeline:    timeline.Build(programID, exercises, timeline.DefaultOptions()),
+	}, nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+const (
+	embedDefaultWidth  = 480
+	embedDefaultHeight = 360
+	// embedCacheAge is how long consumers may cache oEmbed responses, in seconds
+	embedCacheAge = 3600
+)
+
+// EmbedService serves previews of shared programs for embedding on other sites, such as the
+// school's website, either as JSON for custom widgets or as oEmbed iframes
+type EmbedService struct {
+	shareService *ShareLinkService
+	sharesCfg    *config.SharesConfig
+	embedCfg     *config.EmbedConfig
+}
+
+func NewEmbedService(shareService *ShareLinkService, sharesCfg *config.SharesConfig, embedCfg *config.EmbedConfig) *EmbedService {
+	return &EmbedService{
+		shareService: shareService,
+		sharesCfg:    sharesCfg,
+		embedCfg:     embedCfg,
+	}
+}
+
+// Preview summarizes the program behind an active share link
+func (s *EmbedService) Preview(ctx context.Context, token, locale string) (*models.ProgramPreview, error) {
+	shared, err := s.shareService.View(ctx, token, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ProgramPreview{
+		Name:                 shared.Name,
+		Description:          shared.Description,
+		Tags:                 shared.Tags,
+		CoverURL:             shared.CoverURL,
+		CoverThumbnails:      shared.CoverThumbnails,
+		ExerciseCount:        len(shared.Exercises),
+		TotalDurationSeconds: shared.TotalDurationSeconds,
+		URL:                  tokenURL(s.sharesCfg.URL, token),
+		ExpiresAt:            shared.ExpiresAt,
+	}, nil
+}
+
+// OEmbed returns the oEmbed response for a share or widget page URL, sized to fit
+// maxWidth and maxHeight (0 for no limit)
+func (s *EmbedService) OEmbed(ctx context.Context, rawURL string, maxWidth, maxHeight int, locale string) (*models.OEmbed, error) {
+	token, ok := s.tokenFromURL(rawURL)
+	if !ok {
+		return nil, appErrors.NewNotFoundError("Shared program")
+	}
+
+	shared, err := s.shareService.View(ctx, token, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := embedDefaultWidth, embedDefaultHeight
+	if maxWidth > 0 && maxWidth < width {
+		width = maxWidth
+	}
+	if maxHeight > 0 && maxHeight < height {
+		height = maxHeight
+	}
+
+	src := tokenURL(s.embedCfg.PageURL, token)
+	if src == "" {
+		return nil, appErrors.NewNotFoundError("Embed page")
+	}
+
+	return &models.OEmbed{
+		Type:         "rich",
+		Version:      "1.0",
+		Title:        shared.Name,
+		ProviderName: "Xuan Gong",
+		ProviderURL:  origin(s.sharesCfg.URL),
+		HTML: fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" title="%s" style="border:0" loading="lazy"></iframe>`,
+			html.EscapeString(src), width, height, html.EscapeString(shared.Name)),
+		Width:    width,
+		Height:   height,
+		CacheAge: embedCacheAge,
+	}, nil
+}
+
+// tokenFromURL extracts the share token from a share page or widget page URL. URLs of other
+// pages are rejected, so the endpoint only answers for content it serves.
+func (s *EmbedService) tokenFromURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	token := u.Query().Get("token")
+	if token == "" {
+		return "", false
+	}
+	for _, page := range []string{s.sharesCfg.URL, s.embedCfg.PageURL} {
+		if page != "" && samePage(u, page) {
+			return token, true
+		}
+	}
+	return "", false
+}
+
+// samePage reports whether u points to the page at base, ignoring the query
+func samePage(u *url.URL, base string) bool {
+	b, err := url.Parse(base)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == b.Scheme && u.Host == b.Host && u.Path == b.Path
+}
+
+// origin returns the scheme and host of a URL, or "" if it cannot be parsed
+func origin(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
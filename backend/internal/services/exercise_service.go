@@ -2,28 +2,37 @@ package services
 
 import (
 	"context"
+	"log"
 
 	"github.com/google/uuid"
 	"github.com/xuangong/backend/internal/models"
 	"github.com/xuangong/backend/internal/repositories"
 	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/richtext"
 	"github.com/xuangong/backend/pkg/youtube"
 )
 
 type ExerciseService struct {
-	exerciseRepo *repositories.ExerciseRepository
-	programRepo  *repositories.ProgramRepository
+	exerciseRepo  *repositories.ExerciseRepository
+	programRepo   *repositories.ProgramRepository
+	schemaService *MetadataSchemaService
+	templateCache *TemplateCache
 }
 
-func NewExerciseService(exerciseRepo *repositories.ExerciseRepository, programRepo *repositories.ProgramRepository) *ExerciseService {
+func NewExerciseService(exerciseRepo *repositories.ExerciseRepository, programRepo *repositories.ProgramRepository, schemaService *MetadataSchemaService, templateCache *TemplateCache) *ExerciseService {
 	return &ExerciseService{
-		exerciseRepo: exerciseRepo,
-		programRepo:  programRepo,
+		exerciseRepo:  exerciseRepo,
+		programRepo:   programRepo,
+		schemaService: schemaService,
+		templateCache: templateCache,
 	}
 }
 
-// validateMetadata validates the metadata field, specifically checking YouTube URLs if present
-func (s *ExerciseService) validateMetadata(metadata map[string]interface{}) error {
+// validateMetadata validates the metadata field against the exercise schema and checks YouTube URLs if present
+func (s *ExerciseService) validateMetadata(ctx context.Context, metadata map[string]interface{}) error {
+	if err := s.schemaService.Validate(ctx, models.MetadataEntityExercise, metadata); err != nil {
+		return err
+	}
 	if metadata == nil {
 		return nil
 	}
@@ -54,14 +63,33 @@ func (s *ExerciseService) validateMetadata(metadata map[string]interface{}) erro
 	return nil
 }
 
-func (s *ExerciseService) Create(ctx context.Context, exercise *models.Exercise) error {
-	// Verify program exists
-	program, err := s.programRepo.GetByID(ctx, exercise.ProgramID)
-	if err != nil {
-		return appErrors.NewInternalError("Failed to verify program").WithError(err)
+// validatePacing checks that a tempo is only set on exercises with repetitions to pace, and
+// that repetitions aren't paced by both a tempo and breaths
+func validatePacing(exercise *models.Exercise) error {
+	if exercise.BreathingPattern != nil && exercise.Tempo != nil && exercise.Tempo.SecondsPerRep > 0 {
+		return appErrors.NewBadRequestError("An exercise can't have both seconds per repetition and a breathing pattern").
+			WithDetails("exercise", exercise.Name)
 	}
-	if program == nil {
-		return appErrors.NewNotFoundError("Program")
+
+	tempo := exercise.Tempo
+	if tempo == nil {
+		return nil
+	}
+	if exercise.ExerciseType == models.ExerciseTypeTimed || exercise.Repetitions == nil || *exercise.Repetitions <= 0 {
+		return appErrors.NewBadRequestError("Tempo is only supported for exercises with repetitions").
+			WithDetails("exercise", exercise.Name)
+	}
+	if tempo.TransitionSeconds > 0 && tempo.SecondsPerRep == 0 {
+		return appErrors.NewBadRequestError("Transition time requires seconds per repetition").
+			WithDetails("exercise", exercise.Name)
+	}
+	return nil
+}
+
+// Create adds an exercise to a program the user owns, or any program for admins
+func (s *ExerciseService) Create(ctx context.Context, exercise *models.Exercise, userID uuid.UUID, userRole models.UserRole) error {
+	if err := s.ensureCanEdit(ctx, exercise.ProgramID, userID, userRole); err != nil {
+		return err
 	}
 
 	// Validate exercise type and required fields
@@ -88,14 +116,20 @@ func (s *ExerciseService) Create(ctx context.Context, exercise *models.Exercise)
 		}
 	}
 
+	if err := validatePacing(exercise); err != nil {
+		return err
+	}
+
 	// Validate metadata (YouTube URL, etc.)
-	if err := s.validateMetadata(exercise.Metadata); err != nil {
+	if err := s.validateMetadata(ctx, exercise.Metadata); err != nil {
 		return err
 	}
 
 	if err := s.exerciseRepo.Create(ctx, exercise); err != nil {
 		return appErrors.NewInternalError("Failed to create exercise").WithError(err)
 	}
+	exercise.RenderedHTML = richtext.Render(exercise.Description)
+	s.programChanged(ctx, exercise.ProgramID)
 	return nil
 }
 
@@ -110,8 +144,9 @@ func (s *ExerciseService) GetByID(ctx context.Context, id uuid.UUID) (*models.Ex
 	return exercise, nil
 }
 
-func (s *ExerciseService) ListByProgram(ctx context.Context, programID uuid.UUID) ([]models.Exercise, error) {
-	// Verify program exists
+// ListByProgram returns the program with its exercises; callers decide who may see a program
+// hidden by moderation
+func (s *ExerciseService) ListByProgram(ctx context.Context, programID uuid.UUID) (*models.ProgramWithExercises, error) {
 	program, err := s.programRepo.GetByID(ctx, programID)
 	if err != nil {
 		return nil, appErrors.NewInternalError("Failed to verify program").WithError(err)
@@ -124,10 +159,11 @@ func (s *ExerciseService) ListByProgram(ctx context.Context, programID uuid.UUID
 	if err != nil {
 		return nil, appErrors.NewInternalError("Failed to list exercises").WithError(err)
 	}
-	return exercises, nil
+	return &models.ProgramWithExercises{Program: *program, Exercises: exercises}, nil
 }
 
-func (s *ExerciseService) Update(ctx context.Context, id uuid.UUID, updates *models.Exercise) error {
+// Update replaces an exercise of a program the user owns, or any program for admins
+func (s *ExerciseService) Update(ctx context.Context, id uuid.UUID, updates *models.Exercise, userID uuid.UUID, userRole models.UserRole) error {
 	// Verify exercise exists
 	existing, err := s.exerciseRepo.GetByID(ctx, id)
 	if err != nil {
@@ -136,6 +172,9 @@ func (s *ExerciseService) Update(ctx context.Context, id uuid.UUID, updates *mod
 	if existing == nil {
 		return appErrors.NewNotFoundError("Exercise")
 	}
+	if err := s.ensureCanEdit(ctx, existing.ProgramID, userID, userRole); err != nil {
+		return err
+	}
 
 	// Preserve program ID and created at
 	updates.ID = id
@@ -161,18 +200,24 @@ func (s *ExerciseService) Update(ctx context.Context, id uuid.UUID, updates *mod
 		}
 	}
 
+	if err := validatePacing(updates); err != nil {
+		return err
+	}
+
 	// Validate metadata (YouTube URL, etc.)
-	if err := s.validateMetadata(updates.Metadata); err != nil {
+	if err := s.validateMetadata(ctx, updates.Metadata); err != nil {
 		return err
 	}
 
 	if err := s.exerciseRepo.Update(ctx, updates); err != nil {
 		return appErrors.NewInternalError("Failed to update exercise").WithError(err)
 	}
+	s.programChanged(ctx, existing.ProgramID)
 	return nil
 }
 
-func (s *ExerciseService) Delete(ctx context.Context, id uuid.UUID) error {
+// Delete removes an exercise from a program the user owns, or any program for admins
+func (s *ExerciseService) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID, userRole models.UserRole) error {
 	// Verify exercise exists
 	existing, err := s.exerciseRepo.GetByID(ctx, id)
 	if err != nil {
@@ -181,21 +226,22 @@ func (s *ExerciseService) Delete(ctx context.Context, id uuid.UUID) error {
 	if existing == nil {
 		return appErrors.NewNotFoundError("Exercise")
 	}
+	if err := s.ensureCanEdit(ctx, existing.ProgramID, userID, userRole); err != nil {
+		return err
+	}
 
 	if err := s.exerciseRepo.Delete(ctx, id); err != nil {
 		return appErrors.NewInternalError("Failed to delete exercise").WithError(err)
 	}
+	s.programChanged(ctx, existing.ProgramID)
 	return nil
 }
 
-func (s *ExerciseService) ReorderExercises(ctx context.Context, programID uuid.UUID, exerciseIDs []uuid.UUID) error {
-	// Verify program exists
-	program, err := s.programRepo.GetByID(ctx, programID)
-	if err != nil {
-		return appErrors.NewInternalError("Failed to verify program").WithError(err)
-	}
-	if program == nil {
-		return appErrors.NewNotFoundError("Program")
+// ReorderExercises sets the order of all exercises in a program the user owns, or any program
+// for admins
+func (s *ExerciseService) ReorderExercises(ctx context.Context, programID uuid.UUID, exerciseIDs []uuid.UUID, userID uuid.UUID, userRole models.UserRole) error {
+	if err := s.ensureCanEdit(ctx, programID, userID, userRole); err != nil {
+		return err
 	}
 
 	// Verify all exercises belong to the program
@@ -222,5 +268,35 @@ func (s *ExerciseService) ReorderExercises(ctx context.Context, programID uuid.U
 	if err := s.exerciseRepo.Reorder(ctx, programID, exerciseIDs); err != nil {
 		return appErrors.NewInternalError("Failed to reorder exercises").WithError(err)
 	}
+	s.programChanged(ctx, programID)
 	return nil
 }
+
+// ensureCanEdit checks that the program exists and that the user may change its exercises:
+// admins can edit any program, others only their own. Programs without an owner are admin only.
+func (s *ExerciseService) ensureCanEdit(ctx context.Context, programID, userID uuid.UUID, userRole models.UserRole) error {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to verify program").WithError(err)
+	}
+	if program == nil {
+		return appErrors.NewNotFoundError("Program")
+	}
+
+	isAdmin := userRole == models.RoleAdmin
+	isOwner := program.OwnedBy != nil && *program.OwnedBy == userID
+	if !isAdmin && !isOwner {
+		return appErrors.NewAuthorizationError("You don't have permission to edit this program")
+	}
+	return nil
+}
+
+// programChanged drops the cached public listing and marks the program's duplicate fingerprint
+// stale after its exercises change. The backfill job recomputes the fingerprint, so a failure
+// here only delays detection.
+func (s *ExerciseService) programChanged(ctx context.Context, programID uuid.UUID) {
+	s.templateCache.Invalidate()
+	if err := s.programRepo.InvalidateFingerprint(ctx, programID); err != nil {
+		log.Printf("[WARN] Failed to invalidate fingerprint for program %s: %v", programID, err)
+	}
+}
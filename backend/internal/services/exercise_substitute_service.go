@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/richtext"
+)
+
+// ExerciseSubstituteService manages the variants students can do instead of an exercise.
+// Students pick one per exercise in their program settings.
+type ExerciseSubstituteService struct {
+	substituteRepo *repositories.ExerciseSubstituteRepository
+	exerciseRepo   *repositories.ExerciseRepository
+}
+
+func NewExerciseSubstituteService(substituteRepo *repositories.ExerciseSubstituteRepository, exerciseRepo *repositories.ExerciseRepository) *ExerciseSubstituteService {
+	return &ExerciseSubstituteService{
+		substituteRepo: substituteRepo,
+		exerciseRepo:   exerciseRepo,
+	}
+}
+
+func (s *ExerciseSubstituteService) List(ctx context.Context, exerciseID uuid.UUID) ([]models.ExerciseSubstitute, error) {
+	if _, err := s.ensureExercise(ctx, exerciseID); err != nil {
+		return nil, err
+	}
+
+	substitutes, err := s.substituteRepo.ListByExercise(ctx, exerciseID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch substitutes").WithError(err)
+	}
+	return substitutes, nil
+}
+
+func (s *ExerciseSubstituteService) Create(ctx context.Context, exerciseID uuid.UUID, substitute *models.ExerciseSubstitute) error {
+	if _, err := s.ensureExercise(ctx, exerciseID); err != nil {
+		return err
+	}
+
+	substitute.ExerciseID = exerciseID
+	if err := s.substituteRepo.Create(ctx, substitute); err != nil {
+		return appErrors.NewInternalError("Failed to create substitute").WithError(err)
+	}
+	substitute.RenderedHTML = richtext.Render(substitute.Description)
+	return nil
+}
+
+// Update replaces the substitute's fields with those of updates
+func (s *ExerciseSubstituteService) Update(ctx context.Context, exerciseID, substituteID uuid.UUID, updates *models.ExerciseSubstitute) error {
+	existing, err := s.get(ctx, exerciseID, substituteID)
+	if err != nil {
+		return err
+	}
+
+	updates.ID = existing.ID
+	updates.ExerciseID = existing.ExerciseID
+	updates.CreatedAt = existing.CreatedAt
+	if err := s.substituteRepo.Update(ctx, updates); err != nil {
+		return appErrors.NewInternalError("Failed to update substitute").WithError(err)
+	}
+	updates.RenderedHTML = richtext.Render(updates.Description)
+	return nil
+}
+
+func (s *ExerciseSubstituteService) Delete(ctx context.Context, exerciseID, substituteID uuid.UUID) error {
+	if _, err := s.get(ctx, exerciseID, substituteID); err != nil {
+		return err
+	}
+
+	if _, err := s.substituteRepo.Delete(ctx, substituteID); err != nil {
+		return appErrors.NewInternalError("Failed to delete substitute").WithError(err)
+	}
+	return nil
+}
+
+// get returns a substitute of the exercise; substitutes of other exercises are not found
+func (s *ExerciseSubstituteService) get(ctx context.Context, exerciseID, substituteID uuid.UUID) (*models.ExerciseSubstitute, error) {
+	substitute, err := s.substituteRepo.GetByID(ctx, substituteID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch substitute").WithError(err)
+	}
+	if substitute == nil || substitute.ExerciseID != exerciseID {
+		return nil, appErrors.NewNotFoundError("Substitute")
+	}
+	return substitute, nil
+}
+
+func (s *ExerciseSubstituteService) ensureExercise(ctx context.Context, exerciseID uuid.UUID) (*models.Exercise, error) {
+	exercise, err := s.exerciseRepo.GetByID(ctx, exerciseID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch exercise").WithError(err)
+	}
+	if exercise == nil {
+		return nil, appErrors.NewNotFoundError("Exercise")
+	}
+	return exercise, nil
+}
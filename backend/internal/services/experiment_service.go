@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"math/rand/v2"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// ExperimentService runs A/B experiments between two variants of a program. Students are
+// assigned a variant at random, keeping the variants the same size, and are assigned that
+// variant's program. Sessions of it are tagged with the variant when they start.
+type ExperimentService struct {
+	experimentRepo *repositories.ExperimentRepository
+	programRepo    *repositories.ProgramRepository
+	userRepo       *repositories.UserRepository
+}
+
+func NewExperimentService(experimentRepo *repositories.ExperimentRepository, programRepo *repositories.ProgramRepository, userRepo *repositories.UserRepository) *ExperimentService {
+	return &ExperimentService{
+		experimentRepo: experimentRepo,
+		programRepo:    programRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// Create sets up an experiment between two existing programs
+func (s *ExperimentService) Create(ctx context.Context, experiment *models.ProgramExperiment) (*models.ProgramExperiment, error) {
+	if experiment.ProgramAID == experiment.ProgramBID {
+		return nil, appErrors.NewBadRequestError("The variants must be different programs")
+	}
+	for _, programID := range []uuid.UUID{experiment.ProgramAID, experiment.ProgramBID} {
+		program, err := s.programRepo.GetByID(ctx, programID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+		}
+		if program == nil {
+			return nil, appErrors.NewNotFoundError("Program")
+		}
+	}
+
+	if err := s.experimentRepo.Create(ctx, experiment); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create experiment").WithError(err)
+	}
+	return experiment, nil
+}
+
+func (s *ExperimentService) List(ctx context.Context) ([]models.ProgramExperiment, error) {
+	experiments, err := s.experimentRepo.List(ctx)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch experiments").WithError(err)
+	}
+	return experiments, nil
+}
+
+func (s *ExperimentService) Get(ctx context.Context, id uuid.UUID) (*models.ProgramExperiment, error) {
+	experiment, err := s.experimentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch experiment").WithError(err)
+	}
+	if experiment == nil {
+		return nil, appErrors.NewNotFoundError("Experiment")
+	}
+	return experiment, nil
+}
+
+// Assign puts the users in the experiment and assigns each their variant's program. New
+// participants are shuffled and dealt out so the variants stay the same size; users already in
+// the experiment keep their variant.
+func (s *ExperimentService) Assign(ctx context.Context, experimentID, assignedBy uuid.UUID, userIDs []uuid.UUID) ([]models.ExperimentParticipant, error) {
+	experiment, err := s.Get(ctx, experimentID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uuid.UUID]bool, len(userIDs))
+	unique := make([]uuid.UUID, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch user").WithError(err)
+		}
+		if user == nil {
+			return nil, appErrors.NewNotFoundError("User")
+		}
+		unique = append(unique, userID)
+	}
+
+	counts, err := s.experimentRepo.CountByVariant(ctx, experimentID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to count experiment participants").WithError(err)
+	}
+	rand.Shuffle(len(unique), func(i, j int) { unique[i], unique[j] = unique[j], unique[i] })
+
+	participants := make([]models.ExperimentParticipant, 0, len(unique))
+	for _, userID := range unique {
+		variant := models.VariantA
+		if counts[models.VariantB] < counts[models.VariantA] || (counts[models.VariantB] == counts[models.VariantA] && rand.IntN(2) == 1) {
+			variant = models.VariantB
+		}
+		participant, err := s.experimentRepo.AddParticipant(ctx, experimentID, userID, variant)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to add experiment participant").WithError(err)
+		}
+		if participant.Variant == variant {
+			counts[variant]++
+		}
+		participant.ProgramID = experiment.ProgramID(participant.Variant)
+
+		userProgram := &models.UserProgram{
+			UserID:         userID,
+			ProgramID:      participant.ProgramID,
+			AssignedBy:     &assignedBy,
+			IsActive:       true,
+			CustomSettings: make(map[string]interface{}),
+		}
+		if err := s.programRepo.AssignToUser(ctx, userProgram); err != nil {
+			return nil, appErrors.NewInternalError("Failed to assign program to user").WithError(err)
+		}
+		participants = append(participants, *participant)
+	}
+	return participants, nil
+}
+
+// Results compares the variants' completion and adherence
+func (s *ExperimentService) Results(ctx context.Context, id uuid.UUID) (*models.ExperimentResults, error) {
+	experiment, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	measured, err := s.experimentRepo.Results(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to compare experiment variants").WithError(err)
+	}
+
+	// Both variants are listed, also before they have participants
+	results := &models.ExperimentResults{ExperimentID: id, Variants: make([]models.VariantResult, 0, 2)}
+	for _, variant := range []string{models.VariantA, models.VariantB} {
+		result := models.VariantResult{Variant: variant}
+		for _, m := range measured {
+			if m.Variant == variant {
+				result = m
+			}
+		}
+		result.ProgramID = experiment.ProgramID(variant)
+		results.Variants = append(results.Variants, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/export"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type ExportService struct {
+	submissionService *SubmissionService
+	programRepo       *repositories.ProgramRepository
+	userRepo          *repositories.UserRepository
+	clock             clock.Clock
+}
+
+func NewExportService(submissionService *SubmissionService, programRepo *repositories.ProgramRepository, userRepo *repositories.UserRepository) *ExportService {
+	return &ExportService{
+		submissionService: submissionService,
+		programRepo:       programRepo,
+		userRepo:          userRepo,
+		clock:             clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the export timestamp, so tests can control time
+func (s *ExportService) WithClock(c clock.Clock) *ExportService {
+	s.clock = c
+	return s
+}
+
+// ExportSubmission renders a submission thread the user can access as Markdown or PDF
+func (s *ExportService) ExportSubmission(ctx context.Context, submissionID, userID uuid.UUID, isAdmin bool, format string) ([]byte, error) {
+	submission, err := s.submissionService.GetSubmission(ctx, submissionID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.submissionService.GetMessages(ctx, submissionID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := &export.Thread{
+		Submission: *submission,
+		Messages:   messages,
+		ExportedAt: s.clock.Now(),
+	}
+
+	// Keep exports working after a course's program is deleted
+	program, err := s.programRepo.GetByIDIncludingDeleted(ctx, submission.ProgramID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program != nil {
+		thread.ProgramName = program.Name
+	}
+	student, err := s.userRepo.GetByID(ctx, submission.UserID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch student").WithError(err)
+	}
+	if student != nil {
+		thread.StudentName = student.FullName
+	}
+
+	switch format {
+	case export.FormatMarkdown:
+		return export.Markdown(thread), nil
+	case export.FormatPDF:
+		data, err := export.PDF(thread)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to render PDF").WithError(err)
+		}
+		return data, nil
+	default:
+		return nil, appErrors.NewBadRequestError("Unsupported export format")
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/hydrate"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type GroupService struct {
+	groupRepo     *repositories.GroupRepository
+	streakService *StreakService
+	clock         clock.Clock
+}
+
+func NewGroupService(groupRepo *repositories.GroupRepository, streakService *StreakService) *GroupService {
+	return &GroupService{
+		groupRepo:     groupRepo,
+		streakService: streakService,
+		clock:         clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *GroupService) WithClock(c clock.Clock) *GroupService {
+	s.clock = c
+	return s
+}
+
+func (s *GroupService) Create(ctx context.Context, name string, description *string, createdBy uuid.UUID) (*models.Group, error) {
+	exists, err := s.groupRepo.NameExists(ctx, name)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to check group name").WithError(err)
+	}
+	if exists {
+		return nil, appErrors.NewConflictError("A group with this name already exists")
+	}
+
+	group := &models.Group{
+		Name:        name,
+		Description: description,
+		CreatedBy:   &createdBy,
+	}
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create group").WithError(err)
+	}
+
+	return group, nil
+}
+
+func (s *GroupService) List(ctx context.Context) ([]models.Group, error) {
+	groups, err := s.groupRepo.List(ctx)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch groups").WithError(err)
+	}
+	return groups, nil
+}
+
+// Stats sums up the practice of the group's members in [from, to), per member and for the
+// whole group, with their streaks as of now
+func (s *GroupService) Stats(ctx context.Context, groupID uuid.UUID, from, to time.Time) (*models.GroupStats, error) {
+	if !to.After(from) {
+		return nil, appErrors.NewBadRequestError("to must be after from")
+	}
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch group").WithError(err)
+	}
+	if group == nil {
+		return nil, appErrors.NewNotFoundError("Group")
+	}
+
+	members, err := s.groupRepo.MemberStats(ctx, groupID, from, to)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to compute group stats").WithError(err)
+	}
+	now := s.clock.Now()
+	err = hydrate.Each(ctx, len(members), func(ctx context.Context, i int) error {
+		var err error
+		members[i].CurrentStreak, members[i].LongestStreak, err = s.streakService.Streaks(ctx, members[i].UserID, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GroupStats{
+		GroupID:   group.ID,
+		GroupName: group.Name,
+		From:      from,
+		To:        to,
+		Summary:   summarizeGroup(members, to.Sub(from)),
+		Members:   members,
+	}, nil
+}
+
+// summarizeGroup sums up the members' stats and fills in their sessions per week. Windows
+// shorter than a week count as one, so a single day doesn't inflate adherence. The average
+// completion rate is over the sessions that have one, like each member's.
+func summarizeGroup(members []models.GroupMemberStats, window time.Duration) models.GroupStatsSummary {
+	weeks := math.Max(window.Hours()/(7*24), 1)
+	summary := models.GroupStatsSummary{Members: len(members)}
+	var rateSum float64
+	var rated, streakSum int
+	for i := range members {
+		m := &members[i]
+		m.SessionsPerWeek = float64(m.SessionsCompleted) / weeks
+		if m.SessionsCompleted > 0 {
+			summary.ActiveMembers++
+		}
+		summary.SessionsStarted += m.SessionsStarted
+		summary.SessionsCompleted += m.SessionsCompleted
+		summary.PracticeMinutes += m.PracticeMinutes
+		if m.AverageCompletionRate != nil {
+			rateSum += *m.AverageCompletionRate * float64(m.RatedSessions)
+			rated += m.RatedSessions
+		}
+		if m.CurrentStreak > 0 {
+			summary.MembersOnStreak++
+		}
+		streakSum += m.CurrentStreak
+	}
+	if rated > 0 {
+		rate := rateSum / float64(rated)
+		summary.AverageCompletionRate = &rate
+	}
+	if len(members) > 0 {
+		summary.SessionsPerWeek = float64(summary.SessionsCompleted) / float64(len(members)) / weeks
+		summary.AverageCurrentStreak = float64(streakSum) / float64(len(members))
+	}
+	return summary
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// homeworkOverdueBatch caps how much past-due homework one overdue run claims
+const homeworkOverdueBatch = 100
+
+// HomeworkService sets homework with deadlines and tracks who completed it on time, late, or not at all
+type HomeworkService struct {
+	homeworkRepo        *repositories.HomeworkRepository
+	programRepo         *repositories.ProgramRepository
+	groupRepo           *repositories.GroupRepository
+	notificationService *NotificationService
+	clock               clock.Clock
+}
+
+func NewHomeworkService(homeworkRepo *repositories.HomeworkRepository, programRepo *repositories.ProgramRepository, groupRepo *repositories.GroupRepository, notificationService *NotificationService) *HomeworkService {
+	return &HomeworkService{
+		homeworkRepo:        homeworkRepo,
+		programRepo:         programRepo,
+		groupRepo:           groupRepo,
+		notificationService: notificationService,
+		clock:               clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *HomeworkService) WithClock(c clock.Clock) *HomeworkService {
+	s.clock = c
+	return s
+}
+
+// Create sets homework for the members of homework.GroupID, who get the program assigned if they
+// do not have it yet, or else for every student assigned to the program
+func (s *HomeworkService) Create(ctx context.Context, homework *models.Homework) (*models.Homework, error) {
+	if !homework.DueAt.After(s.clock.Now()) {
+		return nil, appErrors.NewBadRequestError("due_at must be in the future")
+	}
+	if homework.Requirement == models.HomeworkSubmission || homework.RequiredSessions == 0 {
+		homework.RequiredSessions = 1
+	}
+
+	program, err := s.programRepo.GetByID(ctx, homework.ProgramID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return nil, appErrors.NewNotFoundError("Program")
+	}
+
+	var studentIDs []uuid.UUID
+	if homework.GroupID != nil {
+		group, err := s.groupRepo.GetByID(ctx, *homework.GroupID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch group").WithError(err)
+		}
+		if group == nil {
+			return nil, appErrors.NewNotFoundError("Group")
+		}
+		studentIDs, err = s.groupRepo.ListMemberIDs(ctx, group.ID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch group members").WithError(err)
+		}
+	} else {
+		studentIDs, err = s.programRepo.ListAssignedUserIDs(ctx, homework.ProgramID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch program students").WithError(err)
+		}
+	}
+	if len(studentIDs) == 0 {
+		return nil, appErrors.NewBadRequestError("There are no students to set the homework for")
+	}
+
+	if homework.GroupID != nil {
+		if err := s.assignProgram(ctx, homework, studentIDs); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.homeworkRepo.Create(ctx, homework, studentIDs); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create homework").WithError(err)
+	}
+
+	return s.getHomework(ctx, homework.ID)
+}
+
+// assignProgram assigns the homework's program to the students who do not have it yet.
+// Deactivated assignments are left alone.
+func (s *HomeworkService) assignProgram(ctx context.Context, homework *models.Homework, studentIDs []uuid.UUID) error {
+	for _, studentID := range studentIDs {
+		existing, err := s.programRepo.GetUserProgram(ctx, studentID, homework.ProgramID)
+		if err != nil {
+			return appErrors.NewInternalError("Failed to check program assignment").WithError(err)
+		}
+		if existing != nil {
+			continue
+		}
+		err = s.programRepo.AssignToUser(ctx, &models.UserProgram{
+			UserID:         studentID,
+			ProgramID:      homework.ProgramID,
+			AssignedBy:     homework.CreatedBy,
+			IsActive:       true,
+			CustomSettings: make(map[string]interface{}),
+		})
+		if err != nil {
+			return appErrors.NewInternalError("Failed to assign program").WithError(err)
+		}
+	}
+	return nil
+}
+
+// List returns homework matching the filter to admins, and their own homework with its status to students
+func (s *HomeworkService) List(ctx context.Context, userID uuid.UUID, isAdmin bool, filter repositories.HomeworkFilter) ([]models.Homework, error) {
+	if isAdmin {
+		homework, err := s.homeworkRepo.List(ctx, filter)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch homework").WithError(err)
+		}
+		return homework, nil
+	}
+
+	homework, err := s.homeworkRepo.ListForStudent(ctx, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch homework").WithError(err)
+	}
+	now := s.clock.Now()
+	for i := range homework {
+		homework[i].Status = homeworkStatus(homework[i].DueAt, homework[i].CompletedAt, now)
+	}
+	return homework, nil
+}
+
+// Get returns a homework to admins, and to the students it was set for along with their status
+func (s *HomeworkService) Get(ctx context.Context, id, userID uuid.UUID, isAdmin bool) (*models.Homework, error) {
+	if isAdmin {
+		return s.getHomework(ctx, id)
+	}
+
+	homework, err := s.homeworkRepo.ListForStudent(ctx, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch homework").WithError(err)
+	}
+	for _, h := range homework {
+		if h.ID == id {
+			h.Status = homeworkStatus(h.DueAt, h.CompletedAt, s.clock.Now())
+			return &h, nil
+		}
+	}
+	return nil, appErrors.NewNotFoundError("Homework")
+}
+
+// Update changes a homework's details. Moving the deadline clears overdue marks so
+// students are checked again at the new deadline.
+func (s *HomeworkService) Update(ctx context.Context, id uuid.UUID, title, instructions *string, dueAt *time.Time) (*models.Homework, error) {
+	homework, err := s.getHomework(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if title != nil {
+		homework.Title = *title
+	}
+	if instructions != nil {
+		homework.Instructions = instructions
+	}
+	dueChanged := dueAt != nil && !dueAt.Equal(homework.DueAt)
+	if dueChanged {
+		if !dueAt.After(s.clock.Now()) {
+			return nil, appErrors.NewBadRequestError("due_at must be in the future")
+		}
+		homework.DueAt = *dueAt
+	}
+
+	if err := s.homeworkRepo.Update(ctx, homework, dueChanged); err != nil {
+		return nil, appErrors.NewInternalError("Failed to update homework").WithError(err)
+	}
+	return homework, nil
+}
+
+func (s *HomeworkService) Delete(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.homeworkRepo.Delete(ctx, id)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete homework").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Homework")
+	}
+	return nil
+}
+
+// GetDetail returns a homework with the status of each of its students
+func (s *HomeworkService) GetDetail(ctx context.Context, id uuid.UUID) (*models.HomeworkDetail, error) {
+	homework, err := s.getHomework(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := s.homeworkRepo.ListStudents(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch homework students").WithError(err)
+	}
+
+	detail := &models.HomeworkDetail{Homework: *homework, Students: students}
+	now := s.clock.Now()
+	for i := range students {
+		students[i].Status = homeworkStatus(homework.DueAt, students[i].CompletedAt, now)
+		detail.Counts.Assigned++
+		switch students[i].Status {
+		case models.HomeworkPending:
+			detail.Counts.Pending++
+		case models.HomeworkOnTime:
+			detail.Counts.OnTime++
+		case models.HomeworkLate:
+			detail.Counts.Late++
+		case models.HomeworkOverdue:
+			detail.Counts.Overdue++
+		}
+	}
+	return detail, nil
+}
+
+// MarkOverdue marks the students who missed the deadline of past-due homework as overdue
+// and notifies them. Returns how many students were marked.
+func (s *HomeworkService) MarkOverdue(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+	homework, err := s.homeworkRepo.ClaimPastDue(ctx, now, homeworkOverdueBatch)
+	if err != nil {
+		return 0, appErrors.NewInternalError("Failed to claim past-due homework").WithError(err)
+	}
+
+	marked := 0
+	for _, h := range homework {
+		studentIDs, err := s.homeworkRepo.MarkOverdue(ctx, h.ID, now)
+		if err != nil {
+			return marked, appErrors.NewInternalError("Failed to mark overdue students").WithError(err)
+		}
+		marked += len(studentIDs)
+
+		title := fmt.Sprintf("Homework overdue: %s", h.Title)
+		body := fmt.Sprintf("The deadline for %s was %s. You can still complete it.", h.ProgramName, h.DueAt.Format("Jan 2, 2006 15:04 MST"))
+		payload := map[string]interface{}{
+			"homework_id": h.ID.String(),
+			"program_id":  h.ProgramID.String(),
+		}
+		for _, studentID := range studentIDs {
+			if _, err := s.notificationService.Notify(ctx, studentID, models.NotificationHomeworkOverdue, title, &body, payload); err != nil {
+				log.Printf("[WARN] Failed to notify user %s about overdue homework %s: %v", studentID, h.ID, err)
+			}
+		}
+	}
+	return marked, nil
+}
+
+// Report breaks down the status of homework due between from and to by student group
+func (s *HomeworkService) Report(ctx context.Context, programID *uuid.UUID, from, to time.Time) (*models.HomeworkReport, error) {
+	if !to.After(from) {
+		return nil, appErrors.NewBadRequestError("to must be after from")
+	}
+
+	counts, err := s.homeworkRepo.GroupCounts(ctx, programID, from, to, s.clock.Now())
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to compute homework report").WithError(err)
+	}
+
+	report := &models.HomeworkReport{
+		ProgramID: programID,
+		From:      from,
+		To:        to,
+		Groups:    make([]models.GroupHomeworkCounts, len(counts)),
+	}
+	for i, c := range counts {
+		report.Groups[i] = models.GroupHomeworkCounts{
+			GroupID:              c.GroupID,
+			GroupName:            c.GroupName,
+			HomeworkStatusCounts: c.Counts,
+		}
+		if finished := c.Counts.OnTime + c.Counts.Late; finished > 0 {
+			rate := math.Round(float64(c.Counts.OnTime)/float64(finished)*1000) / 1000
+			report.Groups[i].OnTimeRate = &rate
+		}
+	}
+	return report, nil
+}
+
+func (s *HomeworkService) getHomework(ctx context.Context, id uuid.UUID) (*models.Homework, error) {
+	homework, err := s.homeworkRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch homework").WithError(err)
+	}
+	if homework == nil {
+		return nil, appErrors.NewNotFoundError("Homework")
+	}
+	return homework, nil
+}
+
+// homeworkStatus derives a student's status from the deadline and when they completed the homework
+func homeworkStatus(dueAt time.Time, completedAt *time.Time, now time.Time) models.HomeworkStatus {
+	switch {
+	case completedAt != nil && !completedAt.After(dueAt):
+		return models.HomeworkOnTime
+	case completedAt != nil:
+		return models.HomeworkLate
+	case !now.Before(dueAt):
+		return models.HomeworkOverdue
+	default:
+		return models.HomeworkPending
+	}
+}
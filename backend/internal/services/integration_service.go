@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/internal/timeline"
+	"github.com/xuangong/backend/pkg/auth"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// IntegrationService manages the keys of server-to-server integrations, verifies their signed
+// requests and serves the public program data they may read
+type IntegrationService struct {
+	keyRepo            *repositories.IntegrationKeyRepository
+	programService     *ProgramService
+	translationService *TranslationService
+	cfg                *config.IntegrationsConfig
+	clock              clock.Clock
+}
+
+func NewIntegrationService(keyRepo *repositories.IntegrationKeyRepository, programService *ProgramService, translationService *TranslationService, cfg *config.IntegrationsConfig) *IntegrationService {
+	return &IntegrationService{
+		keyRepo:            keyRepo,
+		programService:     programService,
+		translationService: translationService,
+		cfg:                cfg,
+		clock:              clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *IntegrationService) WithClock(c clock.Clock) *IntegrationService {
+	s.clock = c
+	return s
+}
+
+// SignedRequest is what an integration sends to authenticate a request
+type SignedRequest struct {
+	KeyID      string
+	Timestamp  string // Unix seconds
+	Nonce      string
+	Signature  string
+	Method     string
+	RequestURI string
+	Body       []byte
+}
+
+// CreateKey issues a signing key. The secret is only returned here.
+func (s *IntegrationService) CreateKey(ctx context.Context, createdBy uuid.UUID, name string) (*models.IntegrationKey, error) {
+	secret, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to generate integration key").WithError(err)
+	}
+
+	key := &models.IntegrationKey{
+		Name:      name,
+		Secret:    secret,
+		CreatedBy: &createdBy,
+	}
+	if err := s.keyRepo.Create(ctx, key); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create integration key").WithError(err)
+	}
+	return key, nil
+}
+
+// ListKeys returns all keys, newest first, without their secrets
+func (s *IntegrationService) ListKeys(ctx context.Context) ([]models.IntegrationKey, error) {
+	keys, err := s.keyRepo.List(ctx)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch integration keys").WithError(err)
+	}
+	for i := range keys {
+		keys[i].Secret = ""
+	}
+	return keys, nil
+}
+
+func (s *IntegrationService) RevokeKey(ctx context.Context, id uuid.UUID) error {
+	key, err := s.keyRepo.GetByID(ctx, id)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch integration key").WithError(err)
+	}
+	if key == nil {
+		return appErrors.NewNotFoundError("Integration key")
+	}
+
+	revoked, err := s.keyRepo.Revoke(ctx, id)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to revoke integration key").WithError(err)
+	}
+	if !revoked {
+		return appErrors.NewBadRequestError("Integration key is already revoked")
+	}
+	return nil
+}
+
+// Authenticate verifies a signed request and returns its key, recording the key's use. The
+// timestamp must be within the tolerance of the server's clock and the nonce unused, so a
+// captured request cannot be replayed. The nonce is only recorded once the signature checks
+// out, so nobody else can use up an integration's nonces.
+func (s *IntegrationService) Authenticate(ctx context.Context, req SignedRequest) (*models.IntegrationKey, error) {
+	keyID, err := uuid.Parse(req.KeyID)
+	if err != nil {
+		return nil, appErrors.NewAuthenticationError("Invalid or revoked integration key")
+	}
+
+	unix, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return nil, appErrors.NewAuthenticationError("Invalid request timestamp, expected Unix seconds")
+	}
+	skew := s.clock.Now().Sub(time.Unix(unix, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > s.cfg.GetSignatureTolerance() {
+		return nil, appErrors.NewAuthenticationError("Request timestamp is too far from the server time")
+	}
+
+	key, err := s.keyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch integration key").WithError(err)
+	}
+	if key == nil || key.RevokedAt != nil {
+		return nil, appErrors.NewAuthenticationError("Invalid or revoked integration key")
+	}
+
+	if !auth.VerifyRequestSignature(req.Signature, key.Secret, req.Method, req.RequestURI, req.Timestamp, req.Nonce, req.Body) {
+		return nil, appErrors.NewAuthenticationError("Invalid request signature")
+	}
+
+	fresh, err := s.keyRepo.UseNonce(ctx, key.ID, req.Nonce)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to check request nonce").WithError(err)
+	}
+	if !fresh {
+		return nil, appErrors.NewAuthenticationError("Request nonce was already used")
+	}
+
+	if err := s.keyRepo.TouchLastUsed(ctx, key.ID); err != nil {
+		log.Printf("[WARN] Failed to record use of integration key %s: %v", key.ID, err)
+	}
+	key.Secret = ""
+	return key, nil
+}
+
+// PurgeNonces forgets nonces older than the timestamp tolerance; requests that old are
+// refused by their timestamp
+func (s *IntegrationService) PurgeNonces(ctx context.Context) (int64, error) {
+	return s.keyRepo.PurgeNonces(ctx, s.clock.Now().Add(-s.cfg.GetSignatureTolerance()))
+}
+
+// ListPrograms returns the public programs in the locale, newest first, without exercises
+func (s *IntegrationService) ListPrograms(ctx context.Context, locale string, limit, offset int) ([]models.IntegrationProgram, error) {
+	isPublic := true
+	programs, err := s.programService.List(ctx, nil, &isPublic, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.translationService.Localize(ctx, programs, locale); err != nil {
+		return nil, err
+	}
+
+	result := make([]models.IntegrationProgram, len(programs))
+	for i, p := range programs {
+		result[i] = integrationProgram(p)
+		result[i].Exercises = nil
+	}
+	return result, nil
+}
+
+// GetProgram returns a public program with its exercises in the locale. Programs that are not
+// public are reported as not found.
+func (s *IntegrationService) GetProgram(ctx context.Context, id uuid.UUID, locale string) (*models.IntegrationProgram, error) {
+	program, err := s.programService.GetByID(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if !program.Program.IsPublic || program.Program.HiddenAt != nil {
+		return nil, appErrors.NewNotFoundError("Program")
+	}
+
+	localized := []models.ProgramWithExercises{*program}
+	if err := s.translationService.Localize(ctx, localized, locale); err != nil {
+		return nil, err
+	}
+	result := integrationProgram(localized[0])
+	return &result, nil
+}
+
+func integrationProgram(p models.ProgramWithExercises) models.IntegrationProgram {
+	return models.IntegrationProgram{
+		ID:                   p.Program.ID,
+		Name:                 p.Program.Name,
+		Description:          p.Program.Description,
+		Tags:                 p.Program.Tags,
+		CoverURL:             p.Program.CoverURL,
+		CoverThumbnails:      p.Program.CoverThumbnails,
+		TotalDurationSeconds: timeline.Build(p.Program.ID, p.Exercises, timeline.DefaultOptions()).TotalDurationSeconds,
+		Exercises:            p.Exercises,
+	}
+}
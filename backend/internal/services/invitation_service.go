@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/auth"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type InvitationService struct {
+	invitationRepo *repositories.InvitationRepository
+	groupRepo      *repositories.GroupRepository
+	programRepo    *repositories.ProgramRepository
+	authService    *AuthService
+	cfg            *config.InvitesConfig
+	clock          clock.Clock
+}
+
+func NewInvitationService(invitationRepo *repositories.InvitationRepository, groupRepo *repositories.GroupRepository, programRepo *repositories.ProgramRepository, authService *AuthService, cfg *config.InvitesConfig) *InvitationService {
+	return &InvitationService{
+		invitationRepo: invitationRepo,
+		groupRepo:      groupRepo,
+		programRepo:    programRepo,
+		authService:    authService,
+		cfg:            cfg,
+		clock:          clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *InvitationService) WithClock(c clock.Clock) *InvitationService {
+	s.clock = c
+	return s
+}
+
+// Create issues a single-use invitation. The plain token and signup URL are only returned here.
+// expiresInDays of 0 uses the configured default.
+func (s *InvitationService) Create(ctx context.Context, createdBy uuid.UUID, email *string, role models.UserRole, groupID *uuid.UUID, programIDs []uuid.UUID, expiresInDays int) (*models.Invitation, error) {
+	if groupID != nil {
+		group, err := s.groupRepo.GetByID(ctx, *groupID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch group").WithError(err)
+		}
+		if group == nil {
+			return nil, appErrors.NewNotFoundError("Group")
+		}
+	}
+
+	for _, programID := range programIDs {
+		program, err := s.programRepo.GetByID(ctx, programID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+		}
+		if program == nil {
+			return nil, appErrors.NewNotFoundError("Program")
+		}
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to generate invitation token").WithError(err)
+	}
+
+	if expiresInDays == 0 {
+		expiresInDays = s.cfg.DefaultDays
+	}
+	if programIDs == nil {
+		programIDs = []uuid.UUID{}
+	}
+
+	inv := &models.Invitation{
+		TokenHash:  auth.HashOpaqueToken(token),
+		Email:      email,
+		Role:       role,
+		GroupID:    groupID,
+		ProgramIDs: programIDs,
+		CreatedBy:  &createdBy,
+		ExpiresAt:  s.clock.Now().AddDate(0, 0, expiresInDays),
+	}
+	if err := s.invitationRepo.Create(ctx, inv); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create invitation").WithError(err)
+	}
+
+	inv.Token = token
+	inv.SignupURL = s.signupURL(token)
+	return inv, nil
+}
+
+func (s *InvitationService) List(ctx context.Context, pendingOnly bool, limit, offset int) ([]models.Invitation, error) {
+	invitations, err := s.invitationRepo.List(ctx, pendingOnly, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch invitations").WithError(err)
+	}
+	return invitations, nil
+}
+
+func (s *InvitationService) Revoke(ctx context.Context, id uuid.UUID) error {
+	inv, err := s.invitationRepo.GetByID(ctx, id)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch invitation").WithError(err)
+	}
+	if inv == nil {
+		return appErrors.NewNotFoundError("Invitation")
+	}
+	if inv.AcceptedAt != nil || inv.RevokedAt != nil {
+		return appErrors.NewBadRequestError("Invitation is no longer pending")
+	}
+
+	if err := s.invitationRepo.Revoke(ctx, id); err != nil {
+		return appErrors.NewInternalError("Failed to revoke invitation").WithError(err)
+	}
+	return nil
+}
+
+// Register creates an account from an invitation token, bypassing open registration.
+// The invitation's role is applied, and the user is added to its group and assigned its programs.
+func (s *InvitationService) Register(ctx context.Context, token, email, password, fullName string) (*models.User, *auth.TokenPair, error) {
+	inv, err := s.invitationRepo.GetByTokenHash(ctx, auth.HashOpaqueToken(token))
+	if err != nil {
+		return nil, nil, appErrors.NewInternalError("Failed to fetch invitation").WithError(err)
+	}
+	if inv == nil {
+		return nil, nil, appErrors.NewBadRequestError("Invalid invitation token")
+	}
+	if inv.Email != nil && !strings.EqualFold(*inv.Email, email) {
+		return nil, nil, appErrors.NewBadRequestError("This invitation was issued for a different email address")
+	}
+
+	// Claim before creating the user so concurrent registrations cannot reuse the token
+	claimed, err := s.invitationRepo.Claim(ctx, inv.ID)
+	if err != nil {
+		return nil, nil, appErrors.NewInternalError("Failed to claim invitation").WithError(err)
+	}
+	if !claimed {
+		return nil, nil, appErrors.NewBadRequestError("Invitation has expired or was already used")
+	}
+
+	user, tokens, err := s.authService.Register(ctx, email, password, fullName, inv.Role)
+	if err != nil {
+		if releaseErr := s.invitationRepo.Release(ctx, inv.ID); releaseErr != nil {
+			log.Printf("[WARN] Failed to release invitation %s: %v", inv.ID, releaseErr)
+		}
+		return nil, nil, err
+	}
+
+	// The account exists at this point; failures below are logged so the admin can fix them up
+	if err := s.invitationRepo.SetAcceptedBy(ctx, inv.ID, user.ID); err != nil {
+		log.Printf("[WARN] Failed to record acceptance of invitation %s: %v", inv.ID, err)
+	}
+	if inv.GroupID != nil {
+		if err := s.groupRepo.AddMember(ctx, *inv.GroupID, user.ID); err != nil {
+			log.Printf("[WARN] Failed to add user %s to group %s: %v", user.ID, *inv.GroupID, err)
+		}
+	}
+	for _, programID := range inv.ProgramIDs {
+		userProgram := &models.UserProgram{
+			UserID:         user.ID,
+			ProgramID:      programID,
+			AssignedBy:     inv.CreatedBy,
+			IsActive:       true,
+			CustomSettings: make(map[string]interface{}),
+		}
+		if err := s.programRepo.AssignToUser(ctx, userProgram); err != nil {
+			log.Printf("[WARN] Failed to assign program %s to invited user %s: %v", programID, user.ID, err)
+		}
+	}
+
+	return user, tokens, nil
+}
+
+func (s *InvitationService) signupURL(token string) string {
+	if s.cfg.SignupURL == "" {
+		return ""
+	}
+	u, err := url.Parse(s.cfg.SignupURL)
+	if err != nil {
+		return ""
+	}
+	q := u.Query()
+	q.Set("invite", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
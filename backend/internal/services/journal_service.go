@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/storage"
+)
+
+// journalMedia maps the accepted media content types to their type and file extension
+var journalMedia = map[string]struct {
+	mediaType models.JournalMediaType
+	ext       string
+}{
+	"image/jpeg": {models.JournalPhoto, ".jpg"},
+	"image/png":  {models.JournalPhoto, ".png"},
+	"image/webp": {models.JournalPhoto, ".webp"},
+	"video/mp4":  {models.JournalVideo, ".mp4"},
+	"video/webm": {models.JournalVideo, ".webm"},
+}
+
+// JournalService manages students' private progress journals. Media lives in a private store
+// and is only handed out to the student and to instructors they shared the entry with.
+type JournalService struct {
+	journalRepo         *repositories.JournalRepository
+	programRepo         *repositories.ProgramRepository
+	exerciseRepo        *repositories.ExerciseRepository
+	userRepo            *repositories.UserRepository
+	store               storage.PrivateStore
+	quotaService        *QuotaService
+	notificationService *NotificationService
+	cfg                 *config.JournalConfig
+}
+
+func NewJournalService(
+	journalRepo *repositories.JournalRepository,
+	programRepo *repositories.ProgramRepository,
+	exerciseRepo *repositories.ExerciseRepository,
+	userRepo *repositories.UserRepository,
+	store storage.PrivateStore,
+	quotaService *QuotaService,
+	notificationService *NotificationService,
+	cfg *config.JournalConfig,
+) *JournalService {
+	return &JournalService{
+		journalRepo:         journalRepo,
+		programRepo:         programRepo,
+		exerciseRepo:        exerciseRepo,
+		userRepo:            userRepo,
+		store:               store,
+		quotaService:        quotaService,
+		notificationService: notificationService,
+		cfg:                 cfg,
+	}
+}
+
+// Upload stores a photo or video in the student's journal. The program must be assigned to or
+// owned by the student, and the media counts against their storage quota.
+func (s *JournalService) Upload(ctx context.Context, entry *models.JournalEntry, data []byte) error {
+	if err := s.ensureJournalProgram(ctx, entry.UserID, entry.ProgramID); err != nil {
+		return err
+	}
+	if entry.ExerciseID != nil {
+		exercise, err := s.exerciseRepo.GetByID(ctx, *entry.ExerciseID)
+		if err != nil {
+			return appErrors.NewInternalError("Failed to fetch exercise").WithError(err)
+		}
+		if exercise == nil || exercise.ProgramID != entry.ProgramID {
+			return appErrors.NewBadRequestError("Exercise does not belong to the program")
+		}
+	}
+
+	now := time.Now().UTC()
+	if entry.RecordedAt.IsZero() {
+		entry.RecordedAt = now
+	}
+	if entry.RecordedAt.After(now) {
+		return appErrors.NewBadRequestError("recorded_at cannot be in the future")
+	}
+
+	contentType := http.DetectContentType(data)
+	media, ok := journalMedia[contentType]
+	if !ok {
+		return appErrors.NewBadRequestError("Unsupported media type. Upload a JPEG, PNG or WebP photo or an MP4 or WebM video")
+	}
+	size := int64(len(data))
+	if limit := s.cfg.GetMaxBytes(media.mediaType == models.JournalVideo); size > limit {
+		return appErrors.NewPayloadTooLargeError(limit)
+	}
+	if err := s.quotaService.CheckStorage(ctx, entry.UserID, uuid.Nil, size); err != nil {
+		return err
+	}
+
+	entry.MediaKey = fmt.Sprintf("journal/%s/%s%s", entry.UserID, uuid.New(), media.ext)
+	entry.MediaType = media.mediaType
+	entry.ContentType = contentType
+	entry.SizeBytes = size
+	if err := s.store.Put(ctx, entry.MediaKey, data, contentType); err != nil {
+		return appErrors.NewInternalError("Failed to store journal media").WithError(err)
+	}
+	if err := s.journalRepo.Create(ctx, entry); err != nil {
+		if delErr := s.store.Delete(ctx, entry.MediaKey); delErr != nil {
+			log.Printf("[WARN] Failed to remove journal media %s: %v", entry.MediaKey, delErr)
+		}
+		return appErrors.NewInternalError("Failed to create journal entry").WithError(err)
+	}
+	return nil
+}
+
+// List returns the student's journal timeline with the instructors each entry is shared with
+func (s *JournalService) List(ctx context.Context, userID uuid.UUID, programID *uuid.UUID, limit, offset int) ([]models.JournalEntry, error) {
+	entries, err := s.journalRepo.ListByUser(ctx, userID, programID, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch journal entries").WithError(err)
+	}
+	if err := s.attachShares(ctx, entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ListShared returns entries students shared with the instructor, optionally of one student
+func (s *JournalService) ListShared(ctx context.Context, instructorID uuid.UUID, studentID *uuid.UUID, limit, offset int) ([]models.JournalEntry, error) {
+	entries, err := s.journalRepo.ListSharedWith(ctx, instructorID, studentID, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch shared journal entries").WithError(err)
+	}
+	return entries, nil
+}
+
+// Get returns an entry the user may see: their own, or one shared with them. Other entries
+// don't exist as far as the user is concerned, whatever their role.
+func (s *JournalService) Get(ctx context.Context, id, userID uuid.UUID) (*models.JournalEntry, error) {
+	entry, err := s.journalRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch journal entry").WithError(err)
+	}
+	if entry == nil {
+		return nil, appErrors.NewNotFoundError("Journal entry")
+	}
+	if entry.UserID == userID {
+		entries := []models.JournalEntry{*entry}
+		if err := s.attachShares(ctx, entries); err != nil {
+			return nil, err
+		}
+		return &entries[0], nil
+	}
+
+	shared, err := s.journalRepo.IsSharedWith(ctx, entry.ID, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to check journal access").WithError(err)
+	}
+	if !shared {
+		return nil, appErrors.NewNotFoundError("Journal entry")
+	}
+	return entry, nil
+}
+
+// OpenMedia returns the entry with a reader for its media, subject to the same access as Get.
+// The caller closes the reader.
+func (s *JournalService) OpenMedia(ctx context.Context, id, userID uuid.UUID) (*models.JournalEntry, io.ReadCloser, error) {
+	entry, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	media, err := s.store.Open(ctx, entry.MediaKey)
+	if err != nil {
+		return nil, nil, appErrors.NewInternalError("Failed to open journal media").WithError(err)
+	}
+	return entry, media, nil
+}
+
+// Delete removes one of the student's entries along with its media
+func (s *JournalService) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	entry, err := s.ownEntry(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.journalRepo.Delete(ctx, entry.ID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete journal entry").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Journal entry")
+	}
+	if err := s.store.Delete(ctx, entry.MediaKey); err != nil {
+		log.Printf("[WARN] Failed to remove journal media %s: %v", entry.MediaKey, err)
+	}
+	return nil
+}
+
+// Share gives an instructor access to one of the student's entries
+func (s *JournalService) Share(ctx context.Context, id, userID, instructorID uuid.UUID) (*models.JournalEntry, error) {
+	entry, err := s.ownEntry(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	instructor, err := s.userRepo.GetByID(ctx, instructorID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch instructor").WithError(err)
+	}
+	if instructor == nil || instructor.Role != models.RoleAdmin {
+		return nil, appErrors.NewBadRequestError("Journal entries can only be shared with instructors")
+	}
+	if err := s.journalRepo.Share(ctx, entry.ID, instructorID); err != nil {
+		return nil, appErrors.NewInternalError("Failed to share journal entry").WithError(err)
+	}
+	return s.Get(ctx, id, userID)
+}
+
+// Unshare revokes an instructor's access to one of the student's entries
+func (s *JournalService) Unshare(ctx context.Context, id, userID, instructorID uuid.UUID) error {
+	entry, err := s.ownEntry(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	removed, err := s.journalRepo.Unshare(ctx, entry.ID, instructorID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to unshare journal entry").WithError(err)
+	}
+	if !removed {
+		return appErrors.NewNotFoundError("Journal share")
+	}
+	return nil
+}
+
+// RequestShare asks a student to share form-check media for a program assigned to them. The
+// student is notified; nothing becomes visible until they share an entry.
+func (s *JournalService) RequestShare(ctx context.Context, instructorID, studentID, programID uuid.UUID, message string) error {
+	userProgram, err := s.programRepo.GetUserProgram(ctx, studentID, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch program assignment").WithError(err)
+	}
+	if userProgram == nil {
+		return appErrors.NewNotFoundError("Program assignment")
+	}
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return appErrors.NewNotFoundError("Program")
+	}
+
+	title := fmt.Sprintf("Your instructor asked for a form check: %s", program.Name)
+	var body *string
+	if message != "" {
+		body = &message
+	}
+	payload := map[string]interface{}{
+		"program_id":    programID.String(),
+		"instructor_id": instructorID.String(),
+	}
+	if _, err := s.notificationService.Notify(ctx, studentID, models.NotificationJournalRequest, title, body, payload); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ensureJournalProgram checks that the student may keep a journal for the program
+func (s *JournalService) ensureJournalProgram(ctx context.Context, userID, programID uuid.UUID) error {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return appErrors.NewNotFoundError("Program")
+	}
+	if program.OwnedBy != nil && *program.OwnedBy == userID {
+		return nil
+	}
+	userProgram, err := s.programRepo.GetUserProgram(ctx, userID, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch program assignment").WithError(err)
+	}
+	if userProgram == nil {
+		return appErrors.NewNotFoundError("Program")
+	}
+	return nil
+}
+
+// ownEntry loads one of the user's own entries; other users' entries are reported as missing
+func (s *JournalService) ownEntry(ctx context.Context, id, userID uuid.UUID) (*models.JournalEntry, error) {
+	entry, err := s.journalRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch journal entry").WithError(err)
+	}
+	if entry == nil || entry.UserID != userID {
+		return nil, appErrors.NewNotFoundError("Journal entry")
+	}
+	return entry, nil
+}
+
+func (s *JournalService) attachShares(ctx context.Context, entries []models.JournalEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(entries))
+	for i := range entries {
+		ids[i] = entries[i].ID
+	}
+	shares, err := s.journalRepo.SharesForEntries(ctx, ids)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch journal shares").WithError(err)
+	}
+	for i := range entries {
+		entries[i].SharedWith = shares[entries[i].ID]
+	}
+	return nil
+}
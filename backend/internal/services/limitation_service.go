@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/limitations"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/internal/timeline"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// LimitationService keeps students' injury and health profiles and adjusts their programs to
+// them. Instructors review each adjusted program; a changed profile needs a new review.
+type LimitationService struct {
+	limitationRepo *repositories.LimitationRepository
+	programRepo    *repositories.ProgramRepository
+	exerciseRepo   *repositories.ExerciseRepository
+}
+
+func NewLimitationService(limitationRepo *repositories.LimitationRepository, programRepo *repositories.ProgramRepository, exerciseRepo *repositories.ExerciseRepository) *LimitationService {
+	return &LimitationService{
+		limitationRepo: limitationRepo,
+		programRepo:    programRepo,
+		exerciseRepo:   exerciseRepo,
+	}
+}
+
+// GetLimitations returns the student's profile, empty if they never recorded one
+func (s *LimitationService) GetLimitations(ctx context.Context, userID uuid.UUID) (*models.Limitations, error) {
+	profile, err := s.limitationRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch limitations").WithError(err)
+	}
+	if profile == nil {
+		return &models.Limitations{UserID: userID, Joints: []string{}, Conditions: []string{}}, nil
+	}
+	return profile, nil
+}
+
+// UpdateLimitations replaces the student's profile. Reviews of their adjusted programs become pending.
+func (s *LimitationService) UpdateLimitations(ctx context.Context, profile *models.Limitations) error {
+	if profile.Joints == nil {
+		profile.Joints = []string{}
+	}
+	if profile.Conditions == nil {
+		profile.Conditions = []string{}
+	}
+	if err := s.limitationRepo.Save(ctx, profile); err != nil {
+		return appErrors.NewInternalError("Failed to save limitations").WithError(err)
+	}
+	return nil
+}
+
+// Adjust renders the student's programs with their limitations: contraindicated exercises are
+// substituted or flagged, and adjusted programs get the status of the instructor's review.
+func (s *LimitationService) Adjust(ctx context.Context, userID uuid.UUID, programs []models.ProgramWithExercises) error {
+	profile, err := s.limitationRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch limitations").WithError(err)
+	}
+	if len(profile.All()) == 0 {
+		return nil
+	}
+
+	reviews, err := s.limitationRepo.GetReviews(ctx, userID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch plan reviews").WithError(err)
+	}
+	for i := range programs {
+		if !limitations.Apply(profile, programs[i].Exercises) {
+			continue
+		}
+		var review *models.PlanReview
+		if r, ok := reviews[programs[i].Program.ID]; ok {
+			review = &r
+		}
+		programs[i].PlanReviewStatus = reviewStatus(review, profile)
+	}
+	return nil
+}
+
+// ApplyToTimeline substitutes exercises contraindicated for the student on their timeline,
+// unless they chose a substitute themselves
+func (s *LimitationService) ApplyToTimeline(ctx context.Context, userID uuid.UUID, exercises []models.Exercise, opts timeline.Options) error {
+	profile, err := s.limitationRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch limitations").WithError(err)
+	}
+	limitations.ApplyToTimeline(profile, exercises, opts)
+	return nil
+}
+
+// GetAdjustedPlan returns a program assigned to the student as rendered with their limitations
+func (s *LimitationService) GetAdjustedPlan(ctx context.Context, userID, programID uuid.UUID) (*models.AdjustedPlan, error) {
+	if err := s.ensureAssigned(ctx, userID, programID); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.GetLimitations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	exercises, err := s.exerciseRepo.ListByProgramID(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch exercises").WithError(err)
+	}
+	review, err := s.limitationRepo.GetReview(ctx, userID, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch plan review").WithError(err)
+	}
+
+	plan := &models.AdjustedPlan{
+		UserID:      userID,
+		ProgramID:   programID,
+		Limitations: profile,
+		Exercises:   exercises,
+		Review:      review,
+	}
+	if limitations.Apply(profile, exercises) {
+		plan.ReviewStatus = reviewStatus(review, profile)
+	}
+	return plan, nil
+}
+
+// ReviewPlan records the instructor's review of the student's adjusted program for their
+// current limitations
+func (s *LimitationService) ReviewPlan(ctx context.Context, reviewerID, userID, programID uuid.UUID, status models.PlanReviewStatus, note *string) (*models.PlanReview, error) {
+	if err := s.ensureAssigned(ctx, userID, programID); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.limitationRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch limitations").WithError(err)
+	}
+	if len(profile.All()) == 0 {
+		return nil, appErrors.NewConflictError("The student has no limitations, so the program is not adjusted")
+	}
+
+	review := &models.PlanReview{
+		UserID:               userID,
+		ProgramID:            programID,
+		Status:               status,
+		Note:                 note,
+		LimitationsUpdatedAt: profile.UpdatedAt,
+		ReviewedBy:           &reviewerID,
+	}
+	if err := s.limitationRepo.SaveReview(ctx, review); err != nil {
+		return nil, appErrors.NewInternalError("Failed to save plan review").WithError(err)
+	}
+	return review, nil
+}
+
+// ListPendingReviews returns adjusted programs that need an instructor's review
+func (s *LimitationService) ListPendingReviews(ctx context.Context) ([]models.PlanReviewQueueItem, error) {
+	items, err := s.limitationRepo.ListPendingReviews(ctx)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch pending plan reviews").WithError(err)
+	}
+	return items, nil
+}
+
+func (s *LimitationService) ensureAssigned(ctx context.Context, userID, programID uuid.UUID) error {
+	userProgram, err := s.programRepo.GetUserProgram(ctx, userID, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch program assignment").WithError(err)
+	}
+	if userProgram == nil {
+		return appErrors.NewNotFoundError("Program assignment")
+	}
+	return nil
+}
+
+// reviewStatus is the review's status if it applies to the student's current profile
+func reviewStatus(review *models.PlanReview, profile *models.Limitations) models.PlanReviewStatus {
+	if review == nil || !review.LimitationsUpdatedAt.Equal(profile.UpdatedAt) {
+		return models.PlanReviewPending
+	}
+	return review.Status
+}
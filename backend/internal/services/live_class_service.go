@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+const (
+	// checkInCodeAlphabet leaves out characters that are easily confused, like 0/O and 1/I
+	checkInCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	checkInCodeLength   = 6
+	// checkInGracePeriod is how long after the start a self check-in still counts as present
+	checkInGracePeriod = 10 * time.Minute
+)
+
+// LiveClassService schedules live classes and records who attended them, either marked by an
+// instructor or by students checking in with a short-lived code
+type LiveClassService struct {
+	classRepo   *repositories.LiveClassRepository
+	programRepo *repositories.ProgramRepository
+	groupRepo   *repositories.GroupRepository
+	userRepo    *repositories.UserRepository
+	clock       clock.Clock
+}
+
+func NewLiveClassService(classRepo *repositories.LiveClassRepository, programRepo *repositories.ProgramRepository, groupRepo *repositories.GroupRepository, userRepo *repositories.UserRepository) *LiveClassService {
+	return &LiveClassService{
+		classRepo:   classRepo,
+		programRepo: programRepo,
+		groupRepo:   groupRepo,
+		userRepo:    userRepo,
+		clock:       clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *LiveClassService) WithClock(c clock.Clock) *LiveClassService {
+	s.clock = c
+	return s
+}
+
+func (s *LiveClassService) Create(ctx context.Context, class *models.LiveClass) (*models.LiveClass, error) {
+	if err := s.validateClass(ctx, class); err != nil {
+		return nil, err
+	}
+	if err := s.classRepo.Create(ctx, class); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create class").WithError(err)
+	}
+	return class, nil
+}
+
+// List returns classes in the filter's window. Students only see classes for everyone or their
+// groups, with their own attendance but without check-in codes.
+func (s *LiveClassService) List(ctx context.Context, userID uuid.UUID, isAdmin bool, filter repositories.LiveClassFilter) ([]models.LiveClass, error) {
+	if !isAdmin {
+		filter.VisibleTo = &userID
+	}
+	classes, err := s.classRepo.List(ctx, filter)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch classes").WithError(err)
+	}
+	if isAdmin {
+		return classes, nil
+	}
+
+	for i := range classes {
+		if err := s.prepareForStudent(ctx, &classes[i], userID); err != nil {
+			return nil, err
+		}
+	}
+	return classes, nil
+}
+
+// Get returns a class. Students only get classes visible to them.
+func (s *LiveClassService) Get(ctx context.Context, id, userID uuid.UUID, isAdmin bool) (*models.LiveClass, error) {
+	class, err := s.getClass(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if isAdmin {
+		return class, nil
+	}
+
+	visible, err := s.classRepo.IsVisibleTo(ctx, class, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to check group membership").WithError(err)
+	}
+	if !visible {
+		return nil, appErrors.NewNotFoundError("Class")
+	}
+	if err := s.prepareForStudent(ctx, class, userID); err != nil {
+		return nil, err
+	}
+	return class, nil
+}
+
+func (s *LiveClassService) Update(ctx context.Context, id uuid.UUID, title, description, location *string, programID, groupID *uuid.UUID, startsAt, endsAt *time.Time) (*models.LiveClass, error) {
+	class, err := s.getClass(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if title != nil {
+		class.Title = *title
+	}
+	if description != nil {
+		class.Description = description
+	}
+	if location != nil {
+		class.Location = location
+	}
+	if programID != nil {
+		class.ProgramID = programID
+	}
+	if groupID != nil {
+		class.GroupID = groupID
+	}
+	if startsAt != nil {
+		class.StartsAt = *startsAt
+	}
+	if endsAt != nil {
+		class.EndsAt = *endsAt
+	}
+	if err := s.validateClass(ctx, class); err != nil {
+		return nil, err
+	}
+
+	if err := s.classRepo.Update(ctx, class); err != nil {
+		return nil, appErrors.NewInternalError("Failed to update class").WithError(err)
+	}
+	return class, nil
+}
+
+func (s *LiveClassService) Delete(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.classRepo.Delete(ctx, id)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete class").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Class")
+	}
+	return nil
+}
+
+// CreateCheckInCode replaces the class's check-in code with a new one valid for validFor.
+// Codes can be opened until the class ends.
+func (s *LiveClassService) CreateCheckInCode(ctx context.Context, id uuid.UUID, validFor time.Duration) (*models.CheckInCode, error) {
+	class, err := s.getClass(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	if !class.EndsAt.After(now) {
+		return nil, appErrors.NewBadRequestError("The class has already ended")
+	}
+
+	expiresAt := now.Add(validFor)
+	for attempt := 0; attempt < 3; attempt++ {
+		code, err := generateCheckInCode()
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to generate check-in code").WithError(err)
+		}
+		err = s.classRepo.SetCheckInCode(ctx, id, code, expiresAt)
+		if errors.Is(err, repositories.ErrCheckInCodeTaken) {
+			continue
+		}
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to save check-in code").WithError(err)
+		}
+		return &models.CheckInCode{ClassID: id, Code: code, ExpiresAt: expiresAt}, nil
+	}
+	return nil, appErrors.NewInternalError("Failed to find a free check-in code")
+}
+
+// CheckIn marks the student present in the class with the code, or late after the grace period.
+// Attendance an instructor already marked is kept.
+func (s *LiveClassService) CheckIn(ctx context.Context, userID uuid.UUID, code string) (*models.LiveClass, error) {
+	now := s.clock.Now()
+	class, err := s.classRepo.GetByCheckInCode(ctx, strings.ToUpper(strings.TrimSpace(code)), now)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to look up check-in code").WithError(err)
+	}
+	if class == nil {
+		return nil, appErrors.NewBadRequestError("Invalid or expired check-in code")
+	}
+
+	return s.recordCheckIn(ctx, class, userID, models.AttendanceCode, now)
+}
+
+// CheckInWithQR is CheckIn for a student who scanned the class QR code, whose token was
+// already verified. The class must not have ended yet.
+func (s *LiveClassService) CheckInWithQR(ctx context.Context, classID, userID uuid.UUID) (*models.LiveClass, error) {
+	class, err := s.getClass(ctx, classID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	if !class.EndsAt.After(now) {
+		return nil, appErrors.NewBadRequestError("The class has already ended")
+	}
+	return s.recordCheckIn(ctx, class, userID, models.AttendanceQR, now)
+}
+
+func (s *LiveClassService) recordCheckIn(ctx context.Context, class *models.LiveClass, userID uuid.UUID, method models.AttendanceMethod, now time.Time) (*models.LiveClass, error) {
+	visible, err := s.classRepo.IsVisibleTo(ctx, class, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to check group membership").WithError(err)
+	}
+	if !visible {
+		return nil, appErrors.NewAuthorizationError("This class is for another group")
+	}
+
+	status := models.AttendancePresent
+	if now.After(class.StartsAt.Add(checkInGracePeriod)) {
+		status = models.AttendanceLate
+	}
+	recorded, err := s.classRepo.CheckIn(ctx, &models.ClassAttendance{
+		ClassID:  class.ID,
+		UserID:   userID,
+		Status:   status,
+		Method:   method,
+		MarkedAt: now,
+	})
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to check in").WithError(err)
+	}
+	if !recorded {
+		return nil, appErrors.NewConflictError("Your attendance of this class is already recorded")
+	}
+
+	class.Attendees++
+	if err := s.prepareForStudent(ctx, class, userID); err != nil {
+		return nil, err
+	}
+	return class, nil
+}
+
+// MarkAttendance records the given statuses per student, overwriting earlier marks and check-ins
+func (s *LiveClassService) MarkAttendance(ctx context.Context, classID, markedBy uuid.UUID, statuses map[uuid.UUID]models.AttendanceStatus) ([]models.ClassAttendance, error) {
+	if _, err := s.getClass(ctx, classID); err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	entries := make([]models.ClassAttendance, 0, len(statuses))
+	for userID, status := range statuses {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch user").WithError(err)
+		}
+		if user == nil {
+			return nil, appErrors.NewBadRequestError(fmt.Sprintf("User %s does not exist", userID))
+		}
+		entries = append(entries, models.ClassAttendance{
+			ClassID:  classID,
+			UserID:   userID,
+			Status:   status,
+			Method:   models.AttendanceManual,
+			MarkedBy: &markedBy,
+			MarkedAt: now,
+		})
+	}
+	if err := s.classRepo.MarkAttendance(ctx, entries); err != nil {
+		return nil, appErrors.NewInternalError("Failed to mark attendance").WithError(err)
+	}
+
+	return s.ListAttendance(ctx, classID)
+}
+
+func (s *LiveClassService) ListAttendance(ctx context.Context, classID uuid.UUID) ([]models.ClassAttendance, error) {
+	if _, err := s.getClass(ctx, classID); err != nil {
+		return nil, err
+	}
+	attendance, err := s.classRepo.ListAttendance(ctx, classID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch attendance").WithError(err)
+	}
+	return attendance, nil
+}
+
+func (s *LiveClassService) RemoveAttendance(ctx context.Context, classID, userID uuid.UUID) error {
+	removed, err := s.classRepo.RemoveAttendance(ctx, classID, userID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to remove attendance").WithError(err)
+	}
+	if !removed {
+		return appErrors.NewNotFoundError("Attendance")
+	}
+	return nil
+}
+
+// Report lists class attendance next to solo practice per student for [from, to)
+func (s *LiveClassService) Report(ctx context.Context, groupID *uuid.UUID, from, to time.Time) (*models.AttendanceReport, error) {
+	if !to.After(from) {
+		return nil, appErrors.NewBadRequestError("to must be after from")
+	}
+	if groupID != nil {
+		group, err := s.groupRepo.GetByID(ctx, *groupID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch group").WithError(err)
+		}
+		if group == nil {
+			return nil, appErrors.NewNotFoundError("Group")
+		}
+	}
+
+	classes, students, err := s.classRepo.Report(ctx, groupID, from, to)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to compute attendance report").WithError(err)
+	}
+	return &models.AttendanceReport{
+		From:     from,
+		To:       to,
+		GroupID:  groupID,
+		Classes:  classes,
+		Students: students,
+	}, nil
+}
+
+// validateClass checks the times and that the linked program and group exist
+func (s *LiveClassService) validateClass(ctx context.Context, class *models.LiveClass) error {
+	if !class.EndsAt.After(class.StartsAt) {
+		return appErrors.NewBadRequestError("ends_at must be after starts_at")
+	}
+	if class.ProgramID != nil {
+		program, err := s.programRepo.GetByID(ctx, *class.ProgramID)
+		if err != nil {
+			return appErrors.NewInternalError("Failed to fetch program").WithError(err)
+		}
+		if program == nil {
+			return appErrors.NewNotFoundError("Program")
+		}
+	}
+	if class.GroupID != nil {
+		group, err := s.groupRepo.GetByID(ctx, *class.GroupID)
+		if err != nil {
+			return appErrors.NewInternalError("Failed to fetch group").WithError(err)
+		}
+		if group == nil {
+			return appErrors.NewNotFoundError("Group")
+		}
+	}
+	return nil
+}
+
+// prepareForStudent hides the check-in code and fills in the student's own attendance
+func (s *LiveClassService) prepareForStudent(ctx context.Context, class *models.LiveClass, userID uuid.UUID) error {
+	class.CheckInCode = nil
+	class.CheckInExpiresAt = nil
+	status, err := s.classRepo.GetAttendanceStatus(ctx, class.ID, userID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch attendance").WithError(err)
+	}
+	class.MyAttendance = status
+	return nil
+}
+
+func (s *LiveClassService) getClass(ctx context.Context, id uuid.UUID) (*models.LiveClass, error) {
+	class, err := s.classRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch class").WithError(err)
+	}
+	if class == nil {
+		return nil, appErrors.NewNotFoundError("Class")
+	}
+	return class, nil
+}
+
+// generateCheckInCode returns a random code that is easy to read out and type
+func generateCheckInCode() (string, error) {
+	buf := make([]byte, checkInCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = checkInCodeAlphabet[int(b)%len(checkInCodeAlphabet)]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/netip"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/auth"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/mail"
+)
+
+// LoginDeviceService keeps track of the devices users sign in from. A sign-in from a device the
+// user has not used before sends them a notification and an email with a link to revoke it.
+// Tokens carry their device, so revoked devices cannot refresh their tokens, and their access
+// tokens are refused. Revocations are kept in memory, since every request is checked, and
+// reloaded periodically so revocations reach all instances.
+type LoginDeviceService struct {
+	deviceRepo          *repositories.LoginDeviceRepository
+	notificationService *NotificationService
+	mailer              mail.Sender
+	cfg                 *config.LoginAlertsConfig
+	accessExpiry        time.Duration
+	clock               clock.Clock
+
+	mu       sync.Mutex
+	revoked  map[uuid.UUID]struct{}
+	loadedAt time.Time
+}
+
+// NewLoginDeviceService creates the service; accessExpiry is how long access tokens are valid,
+// after which a revoked device's tokens no longer need to be refused by ID
+func NewLoginDeviceService(deviceRepo *repositories.LoginDeviceRepository, notificationService *NotificationService, mailer mail.Sender, cfg *config.LoginAlertsConfig, accessExpiry time.Duration) *LoginDeviceService {
+	return &LoginDeviceService{
+		deviceRepo:          deviceRepo,
+		notificationService: notificationService,
+		mailer:              mailer,
+		cfg:                 cfg,
+		accessExpiry:        accessExpiry,
+		clock:               clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *LoginDeviceService) WithClock(c clock.Clock) *LoginDeviceService {
+	s.clock = c
+	return s
+}
+
+// SignIn records the device of a sign-in and returns its ID for the tokens. The user is alerted
+// about new devices, except on their first sign-in.
+func (s *LoginDeviceService) SignIn(ctx context.Context, user *models.User, client models.LoginClient) (uuid.UUID, error) {
+	fingerprint := deviceFingerprint(client)
+	device, err := s.deviceRepo.GetByFingerprint(ctx, user.ID, fingerprint)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if device != nil && device.RevokedAt == nil {
+		if err := s.deviceRepo.Touch(ctx, device.ID, client.ClientIP); err != nil {
+			return uuid.Nil, err
+		}
+		return device.ID, nil
+	}
+
+	known, err := s.deviceRepo.HasAny(ctx, user.ID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to generate revocation token: %w", err)
+	}
+	device = &models.LoginDevice{
+		UserID:          user.ID,
+		Fingerprint:     fingerprint,
+		ClientIP:        client.ClientIP,
+		UserAgent:       client.UserAgent,
+		Device:          client.Device,
+		RevokeTokenHash: auth.HashOpaqueToken(token),
+	}
+	if err := s.deviceRepo.Create(ctx, device); err != nil {
+		return uuid.Nil, err
+	}
+	// A device signed in again after being revoked is new again
+	s.forget(device.ID)
+
+	if known && s.cfg.Enabled {
+		// Sent in the background so a slow mail server does not delay the sign-in
+		go func(user models.User, device models.LoginDevice) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			s.alert(ctx, &user, &device, token)
+		}(*user, *device)
+	}
+	return device.ID, nil
+}
+
+// CheckActive returns an authentication error if the device a refresh token was issued to has
+// been revoked. Tokens without a device are not checked.
+func (s *LoginDeviceService) CheckActive(ctx context.Context, deviceID string) error {
+	if deviceID == "" {
+		return nil
+	}
+	id, err := uuid.Parse(deviceID)
+	if err != nil {
+		return appErrors.NewAuthenticationError("Invalid device in token")
+	}
+	device, err := s.deviceRepo.GetByID(ctx, id)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch login device").WithError(err)
+	}
+	if device == nil || device.RevokedAt != nil {
+		return appErrors.NewAuthenticationError("This device was signed out")
+	}
+	return nil
+}
+
+// IsRevoked reports whether access tokens issued to the device must be refused
+func (s *LoginDeviceService) IsRevoked(ctx context.Context, deviceID string) bool {
+	if deviceID == "" {
+		return false
+	}
+	id, err := uuid.Parse(deviceID)
+	if err != nil {
+		return true
+	}
+
+	s.mu.Lock()
+	stale := s.loadedAt.IsZero() || s.clock.Now().Sub(s.loadedAt) >= s.cfg.GetRevocationRefreshInterval()
+	s.mu.Unlock()
+	if stale {
+		// Keep checking against the last known revocations while the database is unavailable
+		if err := s.reload(ctx); err != nil {
+			log.Printf("[WARN] Failed to reload revoked login devices: %v", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, revoked := s.revoked[id]
+	return revoked
+}
+
+// List returns the user's devices, most recently used first, marking the one of the request
+func (s *LoginDeviceService) List(ctx context.Context, userID uuid.UUID, currentDeviceID string) ([]models.LoginDevice, error) {
+	devices, err := s.deviceRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch login devices").WithError(err)
+	}
+	for i := range devices {
+		devices[i].Current = devices[i].ID.String() == currentDeviceID
+	}
+	return devices, nil
+}
+
+// Revoke signs out one of the user's devices
+func (s *LoginDeviceService) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	device, err := s.deviceRepo.GetByID(ctx, id)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch login device").WithError(err)
+	}
+	if device == nil || device.UserID != userID {
+		return appErrors.NewNotFoundError("Login device")
+	}
+	if _, err := s.deviceRepo.Revoke(ctx, id); err != nil {
+		return appErrors.NewInternalError("Failed to revoke login device").WithError(err)
+	}
+	s.remember(id)
+	return nil
+}
+
+// RevokeByToken signs out the device a new device alert was sent for and returns it. The link
+// can be used again, e.g. when opened twice.
+func (s *LoginDeviceService) RevokeByToken(ctx context.Context, token string) (*models.LoginDevice, error) {
+	device, err := s.deviceRepo.RevokeByToken(ctx, auth.HashOpaqueToken(token))
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to revoke login device").WithError(err)
+	}
+	if device == nil {
+		return nil, appErrors.NewNotFoundError("Login device")
+	}
+	s.remember(device.ID)
+	return device, nil
+}
+
+// alert tells the user about a sign-in from a new device, in the app and by email. The sign-in
+// already succeeded, so failures are only logged.
+func (s *LoginDeviceService) alert(ctx context.Context, user *models.User, device *models.LoginDevice, token string) {
+	when := device.FirstSeenAt.UTC().Format("Monday, 2 January 2006 15:04")
+	from := describeDevice(device)
+	title := "New sign-in to your account"
+	body := fmt.Sprintf("Someone signed in to your account from %s on %s (UTC).", from, when)
+
+	payload := map[string]interface{}{"device_id": device.ID}
+	if _, err := s.notificationService.Notify(ctx, user.ID, models.NotificationNewLogin, title, &body, payload); err != nil {
+		log.Printf("[WARN] Failed to notify user %s about login device %s: %v", user.ID, device.ID, err)
+	}
+
+	msg := mail.Message{
+		To:      []string{user.Email},
+		Subject: title,
+		Body: body + "\n\nIf this was you, there is nothing to do." +
+			"\n\nIf this wasn't you, sign out that device and then change your password:\n" +
+			s.cfg.RevokeURL + "?token=" + url.QueryEscape(token),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil && !errors.Is(err, mail.ErrDisabled) {
+		log.Printf("[WARN] Failed to email user %s about login device %s: %v", user.ID, device.ID, err)
+	}
+}
+
+// reload fetches the devices revoked within the access token lifetime
+func (s *LoginDeviceService) reload(ctx context.Context) error {
+	now := s.clock.Now()
+	ids, err := s.deviceRepo.ListRevokedSince(ctx, now.Add(-s.accessExpiry))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Try again after the refresh interval rather than on every request
+	s.loadedAt = now
+	if err != nil {
+		return err
+	}
+	s.revoked = make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		s.revoked[id] = struct{}{}
+	}
+	return nil
+}
+
+// remember refuses the device's tokens on this instance right away, before the next reload
+func (s *LoginDeviceService) remember(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.revoked == nil {
+		s.revoked = make(map[uuid.UUID]struct{})
+	}
+	s.revoked[id] = struct{}{}
+}
+
+func (s *LoginDeviceService) forget(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.revoked, id)
+}
+
+// deviceFingerprint identifies a device by its user agent, reported device and network. The
+// network rather than the address is used, so a new address from the same provider is not a
+// new device.
+func deviceFingerprint(client models.LoginClient) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s", valueOr(client.UserAgent, ""), valueOr(client.Device, ""), clientNetwork(client.ClientIP))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// clientNetwork returns the /24 of an IPv4 or the /48 of an IPv6 address
+func clientNetwork(clientIP *string) string {
+	if clientIP == nil {
+		return ""
+	}
+	addr, err := netip.ParseAddr(*clientIP)
+	if err != nil {
+		return *clientIP
+	}
+	bits := 48
+	if addr.Unmap().Is4() {
+		addr, bits = addr.Unmap(), 24
+	}
+	prefix, _ := addr.Prefix(bits)
+	return prefix.String()
+}
+
+// describeDevice names a device for alerts: the reported device, else the user agent, with the
+// address
+func describeDevice(device *models.LoginDevice) string {
+	name := valueOr(device.Device, valueOr(device.UserAgent, "an unknown device"))
+	if device.ClientIP != nil {
+		name += " at " + *device.ClientIP
+	}
+	return name
+}
+
+func valueOr(s *string, fallback string) string {
+	if s == nil || *s == "" {
+		return fallback
+	}
+	return *s
+}
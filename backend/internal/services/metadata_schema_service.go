@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/xuangong/backend/internal/metaschema"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// compiledSchema caches a compiled schema together with the version it was compiled from
+type compiledSchema struct {
+	schema    *metaschema.Schema
+	updatedAt time.Time
+}
+
+// MetadataSchemaService manages admin-defined metadata schemas and validates metadata against them.
+// Entity types without a schema accept any metadata.
+type MetadataSchemaService struct {
+	schemaRepo *repositories.MetadataSchemaRepository
+
+	mu       sync.Mutex
+	compiled map[models.MetadataEntityType]compiledSchema
+}
+
+func NewMetadataSchemaService(schemaRepo *repositories.MetadataSchemaRepository) *MetadataSchemaService {
+	return &MetadataSchemaService{
+		schemaRepo: schemaRepo,
+		compiled:   make(map[models.MetadataEntityType]compiledSchema),
+	}
+}
+
+func (s *MetadataSchemaService) List(ctx context.Context) ([]models.MetadataSchema, error) {
+	schemas, err := s.schemaRepo.List(ctx)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to list metadata schemas").WithError(err)
+	}
+	return schemas, nil
+}
+
+func (s *MetadataSchemaService) Get(ctx context.Context, entityType models.MetadataEntityType) (*models.MetadataSchema, error) {
+	schema, err := s.schemaRepo.Get(ctx, entityType)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch metadata schema").WithError(err)
+	}
+	if schema == nil {
+		return nil, appErrors.NewNotFoundError("Metadata schema")
+	}
+	return schema, nil
+}
+
+// Set replaces the schema for an entity type after checking that it compiles.
+// Existing metadata is not re-validated; it is checked the next time it is saved.
+func (s *MetadataSchemaService) Set(ctx context.Context, schema *models.MetadataSchema) error {
+	compiled, err := metaschema.Compile(schema.Schema)
+	if err != nil {
+		return appErrors.NewBadRequestError("Invalid JSON Schema: " + err.Error())
+	}
+
+	if err := s.schemaRepo.Upsert(ctx, schema); err != nil {
+		return appErrors.NewInternalError("Failed to save metadata schema").WithError(err)
+	}
+
+	s.mu.Lock()
+	s.compiled[schema.EntityType] = compiledSchema{schema: compiled, updatedAt: schema.UpdatedAt}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MetadataSchemaService) Delete(ctx context.Context, entityType models.MetadataEntityType) error {
+	deleted, err := s.schemaRepo.Delete(ctx, entityType)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete metadata schema").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Metadata schema")
+	}
+
+	s.mu.Lock()
+	delete(s.compiled, entityType)
+	s.mu.Unlock()
+	return nil
+}
+
+// Validate checks each metadata map against the schema for entityType, if one is defined.
+// Violations are returned as a validation error with one message per offending value.
+func (s *MetadataSchemaService) Validate(ctx context.Context, entityType models.MetadataEntityType, metadata ...map[string]interface{}) error {
+	schema, err := s.schemaFor(ctx, entityType)
+	if err != nil {
+		return err
+	}
+	if schema == nil {
+		return nil
+	}
+
+	for _, m := range metadata {
+		violations, err := schema.Validate(m)
+		if err != nil {
+			return appErrors.NewBadRequestError("Invalid metadata").WithError(err)
+		}
+		if len(violations) > 0 {
+			return appErrors.NewValidationError("Metadata does not match the "+string(entityType)+" schema").
+				WithDetails("metadata", violations)
+		}
+	}
+	return nil
+}
+
+// schemaFor returns the compiled schema, recompiling only when the stored version changed.
+// The row is read on every call so schema changes on other instances take effect immediately.
+func (s *MetadataSchemaService) schemaFor(ctx context.Context, entityType models.MetadataEntityType) (*metaschema.Schema, error) {
+	stored, err := s.schemaRepo.Get(ctx, entityType)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch metadata schema").WithError(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored == nil {
+		delete(s.compiled, entityType)
+		return nil, nil
+	}
+	if cached, ok := s.compiled[entityType]; ok && cached.updatedAt.Equal(stored.UpdatedAt) {
+		return cached.schema, nil
+	}
+
+	compiled, err := metaschema.Compile(stored.Schema)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Stored metadata schema is invalid").WithError(err)
+	}
+	s.compiled[entityType] = compiledSchema{schema: compiled, updatedAt: stored.UpdatedAt}
+	return compiled, nil
+}
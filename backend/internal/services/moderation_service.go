@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// ModerationService takes abuse reports on messages and programs and lets admins decide on them.
+// Content reported by enough users is hidden until an admin looks at it.
+type ModerationService struct {
+	moderationRepo *repositories.ModerationRepository
+	submissionRepo *repositories.SubmissionRepository
+	programRepo    *repositories.ProgramRepository
+	templateCache  *TemplateCache
+	cfg            *config.ModerationConfig
+}
+
+func NewModerationService(moderationRepo *repositories.ModerationRepository, submissionRepo *repositories.SubmissionRepository, programRepo *repositories.ProgramRepository, templateCache *TemplateCache, cfg *config.ModerationConfig) *ModerationService {
+	return &ModerationService{
+		moderationRepo: moderationRepo,
+		submissionRepo: submissionRepo,
+		programRepo:    programRepo,
+		templateCache:  templateCache,
+		cfg:            cfg,
+	}
+}
+
+// Report files a user's report of a message or program. Users can report content they can see
+// but didn't write, once per pending case.
+func (s *ModerationService) Report(ctx context.Context, contentType models.ModerationContentType, contentID, reporterID uuid.UUID, isAdmin bool, reason models.ReportReason, details *string) (*models.ModerationReport, error) {
+	resource := "Program"
+	if contentType == models.ModerationMessage {
+		resource = "Message"
+	}
+
+	content, err := s.moderationRepo.GetContent(ctx, contentType, contentID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch reported content").WithError(err)
+	}
+	if content == nil || content.Hidden {
+		return nil, appErrors.NewNotFoundError(resource)
+	}
+	if content.SubmissionID != nil {
+		_, err := s.submissionRepo.GetByID(ctx, *content.SubmissionID, reporterID, isAdmin)
+		if errors.Is(err, repositories.ErrAccessDenied) || errors.Is(err, repositories.ErrSubmissionNotFound) {
+			return nil, appErrors.NewNotFoundError(resource)
+		}
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to verify access").WithError(err)
+		}
+	}
+	if contentType == models.ModerationProgram && !isAdmin && !content.Public {
+		// Same access as starting a session; a private program is not found for anyone else
+		allowed, err := s.programRepo.CanAccess(ctx, reporterID, contentID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to verify access").WithError(err)
+		}
+		if !allowed {
+			return nil, appErrors.NewNotFoundError(resource)
+		}
+	}
+	if content.AuthorID != nil && *content.AuthorID == reporterID {
+		return nil, appErrors.NewBadRequestError("You can't report your own content")
+	}
+
+	report := &models.ModerationReport{
+		ReporterID: reporterID,
+		Reason:     reason,
+		Details:    details,
+	}
+	if _, err := s.moderationRepo.Report(ctx, contentType, contentID, report, s.cfg.AutoHideReports); err != nil {
+		if errors.Is(err, repositories.ErrAlreadyReported) {
+			return nil, appErrors.NewConflictError("You already reported this content")
+		}
+		return nil, appErrors.NewInternalError("Failed to save report").WithError(err)
+	}
+	if contentType == models.ModerationProgram {
+		// The report may have hidden it
+		s.templateCache.Invalidate()
+	}
+	return report, nil
+}
+
+// List returns the moderation queue; status nil lists all cases
+func (s *ModerationService) List(ctx context.Context, status *models.ModerationStatus, limit, offset int) ([]models.ModerationCase, error) {
+	cases, err := s.moderationRepo.List(ctx, status, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to list moderation cases").WithError(err)
+	}
+	return cases, nil
+}
+
+// Get returns a case with its reports
+func (s *ModerationService) Get(ctx context.Context, id uuid.UUID) (*models.ModerationCase, error) {
+	c, err := s.moderationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch moderation case").WithError(err)
+	}
+	if c == nil {
+		return nil, appErrors.NewNotFoundError("Moderation case")
+	}
+	return c, nil
+}
+
+// Resolve dismisses the reports and shows the content again. Hidden cases can be resolved
+// to undo the decision.
+func (s *ModerationService) Resolve(ctx context.Context, id, adminID uuid.UUID, note *string) (*models.ModerationCase, error) {
+	return s.decide(ctx, id, []models.ModerationStatus{models.ModerationPending, models.ModerationHidden}, models.ModerationResolved, adminID, note)
+}
+
+// Hide closes a pending case and keeps the content hidden from everyone but its author and admins
+func (s *ModerationService) Hide(ctx context.Context, id, adminID uuid.UUID, note *string) (*models.ModerationCase, error) {
+	return s.decide(ctx, id, []models.ModerationStatus{models.ModerationPending}, models.ModerationHidden, adminID, note)
+}
+
+func (s *ModerationService) decide(ctx context.Context, id uuid.UUID, from []models.ModerationStatus, status models.ModerationStatus, adminID uuid.UUID, note *string) (*models.ModerationCase, error) {
+	c, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	decided, err := s.moderationRepo.Decide(ctx, id, from, status, adminID, note)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to update moderation case").WithError(err)
+	}
+	if !decided {
+		return nil, appErrors.NewConflictError("Moderation case is already " + string(c.Status))
+	}
+	if c.ContentType == models.ModerationProgram {
+		s.templateCache.Invalidate()
+	}
+	return s.Get(ctx, id)
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type NotificationService struct {
+	notificationRepo *repositories.NotificationRepository
+}
+
+func NewNotificationService(notificationRepo *repositories.NotificationRepository) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+	}
+}
+
+// Notify delivers an in-app notification to a user
+func (s *NotificationService) Notify(ctx context.Context, userID uuid.UUID, notificationType models.NotificationType, title string, body *string, payload map[string]interface{}) (*models.Notification, error) {
+	if payload == nil {
+		payload = make(map[string]interface{})
+	}
+
+	notification := &models.Notification{
+		UserID:  userID,
+		Type:    notificationType,
+		Title:   title,
+		Body:    body,
+		Payload: payload,
+	}
+
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create notification").WithError(err)
+	}
+
+	return notification, nil
+}
+
+// List returns the current user's notifications
+func (s *NotificationService) List(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, error) {
+	notifications, err := s.notificationRepo.ListByUser(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to list notifications").WithError(err)
+	}
+	return notifications, nil
+}
+
+// MarkAsRead marks one of the current user's notifications as read
+func (s *NotificationService) MarkAsRead(ctx context.Context, id, userID uuid.UUID) error {
+	if err := s.notificationRepo.MarkAsRead(ctx, id, userID); err != nil {
+		if errors.Is(err, repositories.ErrNotificationNotFound) {
+			return appErrors.NewNotFoundError("Notification")
+		}
+		return appErrors.NewInternalError("Failed to mark notification as read").WithError(err)
+	}
+	return nil
+}
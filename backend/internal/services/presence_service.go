@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// PresenceService tells who is online from the access log: every authenticated request counts
+// as activity, and clients with nothing else to fetch send heartbeats
+type PresenceService struct {
+	accessLogRepo     *repositories.AccessLogRepository
+	submissionService *SubmissionService
+	cfg               *config.PresenceConfig
+	clock             clock.Clock
+}
+
+func NewPresenceService(accessLogRepo *repositories.AccessLogRepository, submissionService *SubmissionService, cfg *config.PresenceConfig) *PresenceService {
+	return &PresenceService{
+		accessLogRepo:     accessLogRepo,
+		submissionService: submissionService,
+		cfg:               cfg,
+		clock:             clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *PresenceService) WithClock(c clock.Clock) *PresenceService {
+	s.clock = c
+	return s
+}
+
+// ThreadInstructors returns the instructors who could answer in a submission thread, online
+// ones first
+func (s *PresenceService) ThreadInstructors(ctx context.Context, submissionID, userID uuid.UUID, isAdmin bool) ([]models.InstructorPresence, error) {
+	if _, err := s.submissionService.GetSubmission(ctx, submissionID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	instructors, err := s.accessLogRepo.InstructorsLastSeen(ctx)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch instructor presence").WithError(err)
+	}
+
+	// Instructors come most recently seen first, so the online ones are already at the top
+	onlineSince := s.clock.Now().Add(-s.cfg.GetOnlineWindow())
+	for i := range instructors {
+		lastSeen := instructors[i].LastSeenAt
+		instructors[i].Online = lastSeen != nil && lastSeen.After(onlineSince)
+	}
+	return instructors, nil
+}
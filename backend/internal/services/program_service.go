@@ -2,40 +2,151 @@ package services
 
 import (
 	"context"
+	"log"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/fingerprint"
+	"github.com/xuangong/backend/internal/hydrate"
 	"github.com/xuangong/backend/internal/models"
 	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/internal/timeline"
+	"github.com/xuangong/backend/pkg/clock"
 	appErrors "github.com/xuangong/backend/pkg/errors"
 )
 
 type ProgramService struct {
-	programRepo  *repositories.ProgramRepository
-	exerciseRepo *repositories.ExerciseRepository
+	programRepo       *repositories.ProgramRepository
+	exerciseRepo      *repositories.ExerciseRepository
+	userRepo          *repositories.UserRepository
+	invitationService *InvitationService
+	coverService      *CoverService
+	schemaService     *MetadataSchemaService
+	quotaService      *QuotaService
+	contentFilter     *ContentFilterService
+	submissionService *SubmissionService
+	limitationService *LimitationService
+	templateCache     *TemplateCache
+	clock             clock.Clock
 }
 
-func NewProgramService(programRepo *repositories.ProgramRepository, exerciseRepo *repositories.ExerciseRepository) *ProgramService {
+func NewProgramService(programRepo *repositories.ProgramRepository, exerciseRepo *repositories.ExerciseRepository, userRepo *repositories.UserRepository, invitationService *InvitationService, coverService *CoverService, schemaService *MetadataSchemaService, quotaService *QuotaService, contentFilter *ContentFilterService, submissionService *SubmissionService, limitationService *LimitationService, templateCache *TemplateCache) *ProgramService {
 	return &ProgramService{
-		programRepo:  programRepo,
-		exerciseRepo: exerciseRepo,
+		programRepo:       programRepo,
+		exerciseRepo:      exerciseRepo,
+		userRepo:          userRepo,
+		invitationService: invitationService,
+		coverService:      coverService,
+		schemaService:     schemaService,
+		quotaService:      quotaService,
+		contentFilter:     contentFilter,
+		submissionService: submissionService,
+		limitationService: limitationService,
+		templateCache:     templateCache,
+		clock:             clock.System,
 	}
 }
 
-func (s *ProgramService) Create(ctx context.Context, program *models.Program, exercises []models.Exercise, ownedBy uuid.UUID) error {
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *ProgramService) WithClock(c clock.Clock) *ProgramService {
+	s.clock = c
+	return s
+}
+
+// maxSimilarPrograms caps how many near-duplicates are reported on create
+const maxSimilarPrograms = 10
+
+// Create creates a program with its exercises. Templates and public programs are compared
+// against existing ones; with DuplicatePolicyMerge an exact duplicate is reused instead.
+func (s *ProgramService) Create(ctx context.Context, program *models.Program, exercises []models.Exercise, ownedBy uuid.UUID, policy models.DuplicatePolicy) (*models.ProgramCreateResult, error) {
+	if err := s.validateMetadata(ctx, program, exercises); err != nil {
+		return nil, err
+	}
+
+	nameKey := fingerprint.NameKey(program.Name)
+	exerciseFingerprint := fingerprint.Exercises(exercises)
+
+	result := &models.ProgramCreateResult{Program: program, SimilarPrograms: []models.SimilarProgram{}}
+	if program.IsTemplate || program.IsPublic || program.PublishAt != nil {
+		similar, err := s.programRepo.FindSimilar(ctx, nameKey, exerciseFingerprint, uuid.Nil, maxSimilarPrograms)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to check for similar programs").WithError(err)
+		}
+		result.SimilarPrograms = similar
+
+		if policy == models.DuplicatePolicyMerge && len(similar) > 0 && similar[0].SameName && similar[0].SameExercises {
+			existing, err := s.programRepo.GetByID(ctx, similar[0].ID)
+			if err != nil {
+				return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+			}
+			if existing != nil {
+				s.coverService.Attach(existing)
+				result.Program = existing
+				result.SimilarPrograms = similar[1:]
+				result.Merged = true
+				return result, nil
+			}
+		}
+	}
+
+	if err := s.quotaService.CheckPrograms(ctx, ownedBy); err != nil {
+		return nil, err
+	}
+	flagged, err := s.contentFilter.Screen(ctx, ownedBy, "program", programText(program))
+	if err != nil {
+		return nil, err
+	}
+
 	program.OwnedBy = &ownedBy
 	if err := s.programRepo.Create(ctx, program); err != nil {
-		return appErrors.NewInternalError("Failed to create program").WithError(err)
+		return nil, appErrors.NewInternalError("Failed to create program").WithError(err)
 	}
+	defer s.templateCache.Invalidate()
+	s.contentFilter.Flag(ctx, models.ModerationProgram, program.ID, flagged)
 
 	// Create exercises
 	for _, exercise := range exercises {
 		exercise.ProgramID = program.ID
 		if err := s.exerciseRepo.Create(ctx, &exercise); err != nil {
-			return appErrors.NewInternalError("Failed to create exercise").WithError(err)
+			return nil, appErrors.NewInternalError("Failed to create exercise").WithError(err)
 		}
 	}
 
-	return nil
+	// A missing fingerprint is picked up by the backfill job
+	if err := s.programRepo.SetFingerprint(ctx, program.ID, nameKey, exerciseFingerprint); err != nil {
+		log.Printf("[WARN] Failed to fingerprint program %s: %v", program.ID, err)
+	}
+
+	return result, nil
+}
+
+// programText is what the content filter screens of a program
+func programText(program *models.Program) string {
+	return program.Name + "\n" + program.Description
+}
+
+// validateMetadata checks program and exercise metadata against the admin-defined schemas, and
+// exercise pacing
+func (s *ProgramService) validateMetadata(ctx context.Context, program *models.Program, exercises []models.Exercise) error {
+	for i := range exercises {
+		if err := validatePacing(&exercises[i]); err != nil {
+			return err
+		}
+	}
+	if err := s.schemaService.Validate(ctx, models.MetadataEntityProgram, program.Metadata); err != nil {
+		return err
+	}
+	if len(exercises) == 0 {
+		return nil
+	}
+
+	exerciseMetadata := make([]map[string]interface{}, len(exercises))
+	for i, exercise := range exercises {
+		exerciseMetadata[i] = exercise.Metadata
+	}
+	return s.schemaService.Validate(ctx, models.MetadataEntityExercise, exerciseMetadata...)
 }
 
 func (s *ProgramService) GetByID(ctx context.Context, id uuid.UUID, includeExercises bool) (*models.ProgramWithExercises, error) {
@@ -47,6 +158,7 @@ func (s *ProgramService) GetByID(ctx context.Context, id uuid.UUID, includeExerc
 		return nil, appErrors.NewNotFoundError("Program")
 	}
 
+	s.coverService.Attach(program)
 	result := &models.ProgramWithExercises{
 		Program: *program,
 	}
@@ -70,15 +182,21 @@ func (s *ProgramService) List(ctx context.Context, isTemplate, isPublic *bool, l
 
 	// Fetch exercises for each program
 	result := make([]models.ProgramWithExercises, len(programs))
-	for i, program := range programs {
+	err = hydrate.Each(ctx, len(programs), func(ctx context.Context, i int) error {
+		program := programs[i]
 		exercises, err := s.exerciseRepo.ListByProgramID(ctx, program.ID)
 		if err != nil {
-			return nil, appErrors.NewInternalError("Failed to fetch exercises").WithError(err)
+			return appErrors.NewInternalError("Failed to fetch exercises").WithError(err)
 		}
+		s.coverService.Attach(&program)
 		result[i] = models.ProgramWithExercises{
 			Program:   program,
 			Exercises: exercises,
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return result, nil
@@ -98,10 +216,21 @@ func (s *ProgramService) Update(ctx context.Context, id uuid.UUID, updates *mode
 		return appErrors.NewAuthorizationError("You don't have permission to edit this program")
 	}
 
+	if err := s.validateMetadata(ctx, updates, exercises); err != nil {
+		return err
+	}
+	flagged, err := s.contentFilter.Screen(ctx, userID, "program", programText(updates))
+	if err != nil {
+		return err
+	}
+
 	updates.ID = id
 	if err := s.programRepo.Update(ctx, updates); err != nil {
 		return appErrors.NewInternalError("Failed to update program").WithError(err)
 	}
+	// Also covers exercise changes below, even if one of them fails
+	defer s.templateCache.Invalidate()
+	s.contentFilter.Flag(ctx, models.ModerationProgram, id, flagged)
 
 	// Fetch existing exercises
 	existingExercises, err := s.exerciseRepo.ListByProgramID(ctx, id)
@@ -148,25 +277,30 @@ func (s *ProgramService) Update(ctx context.Context, id uuid.UUID, updates *mode
 		}
 	}
 
+	if err := s.refreshFingerprint(ctx, id); err != nil {
+		log.Printf("[WARN] Failed to fingerprint program %s: %v", id, err)
+	}
+
 	return nil
 }
 
-func (s *ProgramService) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
-	existing, err := s.programRepo.GetByID(ctx, id)
+// Purge removes a deleted program for good, with everything that belongs to it: exercises,
+// assignments, practice sessions and threads. Only deleted programs can be purged, so a program
+// still in use can't be lost by mistake. Admin only; the route checks the role.
+func (s *ProgramService) Purge(ctx context.Context, id uuid.UUID) error {
+	existing, err := s.programRepo.GetByIDIncludingDeleted(ctx, id)
 	if err != nil {
 		return appErrors.NewInternalError("Failed to fetch program").WithError(err)
 	}
 	if existing == nil {
 		return appErrors.NewNotFoundError("Program")
 	}
-
-	// Authorization check: only the owner can delete their program
-	if existing.OwnedBy != nil && *existing.OwnedBy != userID {
-		return appErrors.NewAuthorizationError("You don't have permission to delete this program")
+	if existing.DeletedAt == nil {
+		return appErrors.NewConflictError("Delete the program before purging it")
 	}
 
 	if err := s.programRepo.Delete(ctx, id); err != nil {
-		return appErrors.NewInternalError("Failed to delete program").WithError(err)
+		return appErrors.NewInternalError("Failed to purge program").WithError(err)
 	}
 	return nil
 }
@@ -195,6 +329,7 @@ func (s *ProgramService) SoftDelete(ctx context.Context, id uuid.UUID, userID uu
 	if err := s.programRepo.SoftDelete(ctx, id); err != nil {
 		return appErrors.NewInternalError("Failed to delete program").WithError(err)
 	}
+	s.templateCache.Invalidate()
 
 	return nil
 }
@@ -226,6 +361,198 @@ func (s *ProgramService) AssignToUsers(ctx context.Context, programID, assignedB
 	return nil
 }
 
+// BulkAssign assigns a program to users identified by ID or email and reports the outcome per target.
+// Duplicate targets and users who already have the program active are skipped.
+// With inviteMissing, unknown emails receive an invitation that assigns the program on signup.
+// With a welcome thread, each newly assigned user gets a submission thread opened by the assigner.
+func (s *ProgramService) BulkAssign(ctx context.Context, programID, assignedBy uuid.UUID, targets []models.AssignmentTarget, inviteMissing bool, welcome *models.WelcomeThread) (*models.AssignmentReport, error) {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return nil, appErrors.NewNotFoundError("Program")
+	}
+
+	report := &models.AssignmentReport{Results: make([]models.AssignmentResult, 0, len(targets))}
+	seen := make(map[string]bool)
+
+	for _, target := range targets {
+		result := s.assignTarget(ctx, programID, assignedBy, target, seen)
+		if result.Status == models.AssignmentNotFound && inviteMissing && result.Email != "" {
+			result = s.inviteTarget(ctx, programID, assignedBy, result)
+		}
+		if result.Status == models.AssignmentAssigned && welcome != nil {
+			result = s.openWelcomeThread(ctx, program, assignedBy, *welcome, result)
+		}
+
+		switch result.Status {
+		case models.AssignmentAssigned:
+			report.Assigned++
+		case models.AssignmentInvited:
+			report.Invited++
+		case models.AssignmentAlreadyAssigned, models.AssignmentDuplicate:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
+
+// assignTarget resolves and assigns a single target. seen tracks user IDs and emails already handled in this batch.
+func (s *ProgramService) assignTarget(ctx context.Context, programID, assignedBy uuid.UUID, target models.AssignmentTarget, seen map[string]bool) models.AssignmentResult {
+	identifier := strings.TrimSpace(target.Identifier)
+	result := models.AssignmentResult{
+		Row:        target.Row,
+		Identifier: identifier,
+	}
+
+	var user *models.User
+	var err error
+	if id, parseErr := uuid.Parse(identifier); parseErr == nil {
+		user, err = s.userRepo.GetByID(ctx, id)
+	} else if addr, parseErr := mail.ParseAddress(identifier); parseErr == nil && addr.Address == identifier {
+		result.Email = identifier
+		user, err = s.userRepo.GetByEmail(ctx, identifier)
+	} else {
+		result.Status = models.AssignmentInvalid
+		result.Message = "Not a valid user ID or email"
+		return result
+	}
+	if err != nil {
+		result.Status = models.AssignmentFailed
+		result.Message = "Failed to look up user"
+		return result
+	}
+	if user == nil {
+		if result.Email != "" && seen[strings.ToLower(result.Email)] {
+			result.Status = models.AssignmentDuplicate
+			return result
+		}
+		seen[strings.ToLower(result.Email)] = true
+		result.Status = models.AssignmentNotFound
+		return result
+	}
+
+	result.UserID = &user.ID
+	result.Email = user.Email
+
+	if seen[user.ID.String()] {
+		result.Status = models.AssignmentDuplicate
+		return result
+	}
+	seen[user.ID.String()] = true
+
+	existing, err := s.programRepo.GetUserProgram(ctx, user.ID, programID)
+	if err != nil {
+		result.Status = models.AssignmentFailed
+		result.Message = "Failed to check existing assignment"
+		return result
+	}
+	if existing != nil && existing.IsActive {
+		result.Status = models.AssignmentAlreadyAssigned
+		return result
+	}
+
+	userProgram := &models.UserProgram{
+		UserID:         user.ID,
+		ProgramID:      programID,
+		AssignedBy:     &assignedBy,
+		IsActive:       true,
+		CustomSettings: make(map[string]interface{}),
+	}
+	if err := s.programRepo.AssignToUser(ctx, userProgram); err != nil {
+		result.Status = models.AssignmentFailed
+		result.Message = "Failed to assign program"
+		return result
+	}
+
+	result.Status = models.AssignmentAssigned
+	return result
+}
+
+// openWelcomeThread opens the welcome thread for a newly assigned user. The assignment stands
+// if that fails.
+func (s *ProgramService) openWelcomeThread(ctx context.Context, program *models.Program, assignedBy uuid.UUID, welcome models.WelcomeThread, result models.AssignmentResult) models.AssignmentResult {
+	title := welcome.Title
+	if title == "" {
+		title = "Welcome to " + program.Name
+		if utf8.RuneCountInString(title) > 255 {
+			title = program.Name
+		}
+	}
+
+	submission, err := s.submissionService.OpenThread(ctx, program.ID, *result.UserID, assignedBy, title, welcome.Message)
+	if err != nil {
+		log.Printf("[WARN] Failed to open welcome thread for user %s in program %s: %v", *result.UserID, program.ID, err)
+		result.Message = "Assigned, but failed to open the welcome thread"
+		return result
+	}
+
+	result.SubmissionID = &submission.ID
+	return result
+}
+
+// inviteTarget sends a student invitation for an unknown email that assigns the program on signup
+func (s *ProgramService) inviteTarget(ctx context.Context, programID, assignedBy uuid.UUID, result models.AssignmentResult) models.AssignmentResult {
+	email := result.Email
+	inv, err := s.invitationService.Create(ctx, assignedBy, &email, models.RoleStudent, nil, []uuid.UUID{programID}, 0)
+	if err != nil {
+		result.Status = models.AssignmentFailed
+		result.Message = "Failed to create invitation"
+		return result
+	}
+
+	result.Status = models.AssignmentInvited
+	result.Invitation = inv
+	return result
+}
+
+// ApplyPublishSchedule publishes and unpublishes programs whose scheduled times have passed
+func (s *ProgramService) ApplyPublishSchedule(ctx context.Context) (published, unpublished int64, err error) {
+	published, unpublished, err = s.programRepo.ApplyPublishSchedule(ctx, s.clock.Now())
+	if err != nil {
+		return 0, 0, appErrors.NewInternalError("Failed to apply publish schedule").WithError(err)
+	}
+	if published > 0 || unpublished > 0 {
+		s.templateCache.Invalidate()
+	}
+	return published, unpublished, nil
+}
+
+// RefreshStaleFingerprints recomputes up to limit missing or outdated program fingerprints
+func (s *ProgramService) RefreshStaleFingerprints(ctx context.Context, limit int) (int, error) {
+	ids, err := s.programRepo.ListStaleFingerprints(ctx, limit)
+	if err != nil {
+		return 0, appErrors.NewInternalError("Failed to list stale fingerprints").WithError(err)
+	}
+
+	refreshed := 0
+	for _, id := range ids {
+		if err := s.refreshFingerprint(ctx, id); err != nil {
+			return refreshed, appErrors.NewInternalError("Failed to fingerprint program").WithError(err)
+		}
+		refreshed++
+	}
+	return refreshed, nil
+}
+
+// refreshFingerprint recomputes the duplicate detection keys from the stored program and exercises
+func (s *ProgramService) refreshFingerprint(ctx context.Context, id uuid.UUID) error {
+	program, err := s.programRepo.GetByID(ctx, id)
+	if err != nil || program == nil {
+		return err
+	}
+	exercises, err := s.exerciseRepo.ListByProgramID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.programRepo.SetFingerprint(ctx, id, fingerprint.NameKey(program.Name), fingerprint.Exercises(exercises))
+}
+
 func (s *ProgramService) GetUserPrograms(ctx context.Context, userID uuid.UUID) ([]models.ProgramWithExercises, error) {
 	programs, err := s.programRepo.GetUserProgramsWithDetails(ctx, userID, true)
 	if err != nil {
@@ -234,23 +561,114 @@ func (s *ProgramService) GetUserPrograms(ctx context.Context, userID uuid.UUID)
 
 	// Fetch exercises for each program
 	result := make([]models.ProgramWithExercises, len(programs))
-	for i, program := range programs {
+	err = hydrate.Each(ctx, len(programs), func(ctx context.Context, i int) error {
+		program := programs[i]
 		exercises, err := s.exerciseRepo.ListByProgramID(ctx, program.ID)
 		if err != nil {
-			return nil, appErrors.NewInternalError("Failed to fetch exercises").WithError(err)
+			return appErrors.NewInternalError("Failed to fetch exercises").WithError(err)
 		}
+		s.coverService.Attach(&program)
 		result[i] = models.ProgramWithExercises{
 			Program:   program,
 			Exercises: exercises,
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
 
-func (s *ProgramService) UpdateUserProgramSettings(ctx context.Context, userID, programID uuid.UUID, customSettings map[string]interface{}) error {
+// UpdateUserProgramSettings replaces the user's settings for a program assigned to them, such as
+// the timeline's exercise overrides. A substitute chosen for an exercise must be one of its own.
+func (s *ProgramService) UpdateUserProgramSettings(ctx context.Context, userID, programID uuid.UUID, customSettings map[string]interface{}) (*models.UserProgram, error) {
+	userProgram, err := s.programRepo.GetUserProgram(ctx, userID, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program settings").WithError(err)
+	}
+	if userProgram == nil {
+		return nil, appErrors.NewNotFoundError("Program assignment")
+	}
+
+	if err := s.checkSubstitutes(ctx, programID, timeline.OptionsFromSettings(customSettings)); err != nil {
+		return nil, err
+	}
+
+	if customSettings == nil {
+		customSettings = make(map[string]interface{})
+	}
 	if err := s.programRepo.UpdateUserProgramSettings(ctx, userID, programID, customSettings); err != nil {
-		return appErrors.NewInternalError("Failed to update program settings").WithError(err)
+		return nil, appErrors.NewInternalError("Failed to update program settings").WithError(err)
+	}
+	userProgram.CustomSettings = customSettings
+	return userProgram, nil
+}
+
+// checkSubstitutes checks that each chosen substitute belongs to the program exercise it replaces
+func (s *ProgramService) checkSubstitutes(ctx context.Context, programID uuid.UUID, opts timeline.Options) error {
+	chosen := false
+	for _, override := range opts.Exercises {
+		chosen = chosen || override.SubstituteID != nil
+	}
+	if !chosen {
+		return nil
+	}
+
+	exercises, err := s.exerciseRepo.ListByProgramID(ctx, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch exercises").WithError(err)
+	}
+	substitutes := make(map[uuid.UUID]uuid.UUID) // Substitute ID to exercise ID
+	for _, exercise := range exercises {
+		for _, substitute := range exercise.Substitutes {
+			substitutes[substitute.ID] = exercise.ID
+		}
+	}
+
+	for exerciseID, override := range opts.Exercises {
+		if override.SubstituteID == nil {
+			continue
+		}
+		if substitutes[*override.SubstituteID] != exerciseID {
+			return appErrors.NewBadRequestError("Substitute doesn't belong to the exercise").
+				WithDetails("exercise_id", exerciseID.String()).
+				WithDetails("substitute_id", override.SubstituteID.String())
+		}
 	}
 	return nil
 }
+
+// GetTimeline compiles a program into a flat cue timeline, applying the
+// user's per-program overrides from their assignment's custom settings and
+// substituting exercises contraindicated for their limitations
+func (s *ProgramService) GetTimeline(ctx context.Context, programID, userID uuid.UUID) (*models.Timeline, error) {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return nil, appErrors.NewNotFoundError("Program")
+	}
+
+	exercises, err := s.exerciseRepo.ListByProgramID(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch exercises").WithError(err)
+	}
+
+	userProgram, err := s.programRepo.GetUserProgram(ctx, userID, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program settings").WithError(err)
+	}
+
+	opts := timeline.DefaultOptions()
+	if userProgram != nil {
+		opts = timeline.OptionsFromSettings(userProgram.CustomSettings)
+	}
+	if err := s.limitationService.ApplyToTimeline(ctx, userID, exercises, opts); err != nil {
+		return nil, err
+	}
+
+	return timeline.Build(programID, exercises, opts), nil
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/auth"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// QRCheckInService issues the signed QR codes shown at in-person sessions and records the
+// students scanning them, either as class attendance or as a started practice session
+type QRCheckInService struct {
+	qrRepo           *repositories.QRCheckInRepository
+	classRepo        *repositories.LiveClassRepository
+	programRepo      *repositories.ProgramRepository
+	liveClassService *LiveClassService
+	sessionService   *SessionService
+	keys             *auth.SigningKeys
+	cfg              *config.CheckInConfig
+	clock            clock.Clock
+}
+
+func NewQRCheckInService(qrRepo *repositories.QRCheckInRepository, classRepo *repositories.LiveClassRepository, programRepo *repositories.ProgramRepository, liveClassService *LiveClassService, sessionService *SessionService, keys *auth.SigningKeys, cfg *config.CheckInConfig) *QRCheckInService {
+	return &QRCheckInService{
+		qrRepo:           qrRepo,
+		classRepo:        classRepo,
+		programRepo:      programRepo,
+		liveClassService: liveClassService,
+		sessionService:   sessionService,
+		keys:             keys,
+		cfg:              cfg,
+		clock:            clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *QRCheckInService) WithClock(c clock.Clock) *QRCheckInService {
+	s.clock = c
+	return s
+}
+
+// ClassCode issues a QR code for checking in to the class until it ends
+func (s *QRCheckInService) ClassCode(ctx context.Context, classID uuid.UUID) (*models.QRCode, error) {
+	class, err := s.classRepo.GetByID(ctx, classID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch class").WithError(err)
+	}
+	if class == nil {
+		return nil, appErrors.NewNotFoundError("Class")
+	}
+	if !class.EndsAt.After(s.clock.Now()) {
+		return nil, appErrors.NewBadRequestError("The class has already ended")
+	}
+
+	code, err := s.issue(classID.String(), "")
+	if err != nil {
+		return nil, err
+	}
+	code.ClassID = &classID
+	return code, nil
+}
+
+// ProgramCode issues a QR code for starting a practice session of the program
+func (s *QRCheckInService) ProgramCode(ctx context.Context, programID uuid.UUID) (*models.QRCode, error) {
+	if err := s.requireProgram(ctx, programID); err != nil {
+		return nil, err
+	}
+
+	code, err := s.issue("", programID.String())
+	if err != nil {
+		return nil, err
+	}
+	code.ProgramID = &programID
+	return code, nil
+}
+
+// Scan verifies a scanned QR token and checks the student in to its class, or starts a
+// practice session of its program. A token is accepted once per student; if the check-in
+// fails, the scan is released so the student can retry while the token is still valid.
+func (s *QRCheckInService) Scan(ctx context.Context, userID uuid.UUID, token string, clientIP, userAgent, device *string) (*models.QRCheckInResult, error) {
+	now := s.clock.Now()
+	claims, err := auth.ValidateCheckInTokenWithSecrets(token, s.keys.Verification(now), now)
+	if err != nil {
+		return nil, appErrors.NewBadRequestError("Invalid or expired QR code")
+	}
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, appErrors.NewBadRequestError("Invalid or expired QR code")
+	}
+
+	checkIn := &models.QRCheckIn{
+		TokenID:   tokenID,
+		UserID:    userID,
+		ClassID:   parseClaimID(claims.ClassID),
+		ProgramID: parseClaimID(claims.ProgramID),
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+		Device:    device,
+	}
+	if checkIn.ClassID == nil && checkIn.ProgramID == nil {
+		return nil, appErrors.NewBadRequestError("Invalid or expired QR code")
+	}
+
+	err = s.qrRepo.Create(ctx, checkIn)
+	if errors.Is(err, repositories.ErrQRTokenUsed) {
+		return nil, appErrors.NewConflictError("This QR code was already scanned")
+	}
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to record check-in").WithError(err)
+	}
+
+	result, err := s.checkIn(ctx, checkIn)
+	if err != nil {
+		if delErr := s.qrRepo.Delete(ctx, checkIn.ID); delErr != nil {
+			log.Printf("[WARN] Failed to release QR check-in %s: %v", checkIn.ID, delErr)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *QRCheckInService) checkIn(ctx context.Context, checkIn *models.QRCheckIn) (*models.QRCheckInResult, error) {
+	if checkIn.ClassID != nil {
+		class, err := s.liveClassService.CheckInWithQR(ctx, *checkIn.ClassID, checkIn.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.QRCheckInResult{Kind: models.QRCheckInAttendance, Class: class}, nil
+	}
+
+	if err := s.requireProgram(ctx, *checkIn.ProgramID); err != nil {
+		return nil, err
+	}
+	deviceInfo := map[string]interface{}{"check_in": "qr"}
+	if checkIn.Device != nil {
+		deviceInfo["device"] = *checkIn.Device
+	}
+	if checkIn.UserAgent != nil {
+		deviceInfo["user_agent"] = *checkIn.UserAgent
+	}
+	// The signed code is the instructor's invitation, so the program needn't be assigned
+	session, err := s.sessionService.startSession(ctx, checkIn.UserID, *checkIn.ProgramID, deviceInfo)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.qrRepo.SetSession(ctx, checkIn.ID, session.ID); err != nil {
+		log.Printf("[WARN] Failed to link session %s to QR check-in %s: %v", session.ID, checkIn.ID, err)
+	}
+	return &models.QRCheckInResult{Kind: models.QRCheckInSession, Session: session}, nil
+}
+
+func (s *QRCheckInService) issue(classID, programID string) (*models.QRCode, error) {
+	now := s.clock.Now()
+	ttl := s.cfg.GetQRTokenTTL()
+	token, err := auth.GenerateCheckInToken(classID, programID, s.keys.Current(), now, ttl)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to generate QR code").WithError(err)
+	}
+	return &models.QRCode{Token: token, URL: s.checkInURL(token), ExpiresAt: now.Add(ttl)}, nil
+}
+
+func (s *QRCheckInService) requireProgram(ctx context.Context, programID uuid.UUID) error {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return appErrors.NewNotFoundError("Program")
+	}
+	return nil
+}
+
+func (s *QRCheckInService) checkInURL(token string) string {
+	if s.cfg.QRURL == "" {
+		return ""
+	}
+	u, err := url.Parse(s.cfg.QRURL)
+	if err != nil {
+		return ""
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// parseClaimID returns the ID from a token claim, or nil if it is empty or malformed
+func parseClaimID(value string) *uuid.UUID {
+	if value == "" {
+		return nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// latestTextAnswers is how many answers to each text question results show
+const latestTextAnswers = 20
+
+// QuestionnaireService manages the questionnaires students answer after a session and
+// aggregates their answers for instructors
+type QuestionnaireService struct {
+	questionnaireRepo *repositories.QuestionnaireRepository
+	sessionRepo       *repositories.SessionRepository
+	programRepo       *repositories.ProgramRepository
+}
+
+func NewQuestionnaireService(questionnaireRepo *repositories.QuestionnaireRepository, sessionRepo *repositories.SessionRepository, programRepo *repositories.ProgramRepository) *QuestionnaireService {
+	return &QuestionnaireService{
+		questionnaireRepo: questionnaireRepo,
+		sessionRepo:       sessionRepo,
+		programRepo:       programRepo,
+	}
+}
+
+// Get returns the questionnaire of a program
+func (s *QuestionnaireService) Get(ctx context.Context, programID uuid.UUID) (*models.SessionQuestionnaire, error) {
+	if err := s.ensureProgram(ctx, programID); err != nil {
+		return nil, err
+	}
+
+	q, err := s.questionnaireRepo.Get(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch questionnaire").WithError(err)
+	}
+	if q == nil {
+		return nil, appErrors.NewNotFoundError("Questionnaire")
+	}
+	return q, nil
+}
+
+// Set creates or replaces the questionnaire of a program. Scales default to 1 to 5.
+func (s *QuestionnaireService) Set(ctx context.Context, q *models.SessionQuestionnaire) error {
+	if err := s.ensureProgram(ctx, q.ProgramID); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(q.Questions))
+	for i := range q.Questions {
+		question := &q.Questions[i]
+		if seen[question.ID] {
+			return appErrors.NewBadRequestError(fmt.Sprintf("Question %q is in the questionnaire twice", question.ID))
+		}
+		seen[question.ID] = true
+
+		if question.Type == models.QuestionText {
+			question.Min, question.Max, question.MinLabel, question.MaxLabel = 0, 0, nil, nil
+			continue
+		}
+		if question.Min == 0 && question.Max == 0 {
+			question.Min, question.Max = 1, 5
+		}
+		if question.Min >= question.Max {
+			return appErrors.NewBadRequestError(fmt.Sprintf("Question %q: min must be below max", question.ID))
+		}
+	}
+
+	if err := s.questionnaireRepo.Save(ctx, q); err != nil {
+		return appErrors.NewInternalError("Failed to save questionnaire").WithError(err)
+	}
+	return nil
+}
+
+// Delete removes the questionnaire of a program. Answers already given stay with their sessions.
+func (s *QuestionnaireService) Delete(ctx context.Context, programID uuid.UUID) error {
+	deleted, err := s.questionnaireRepo.Delete(ctx, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete questionnaire").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Questionnaire")
+	}
+	return nil
+}
+
+// Check rejects answers that don't fit the program's questionnaire: unknown questions, a value
+// of the wrong type or outside the scale, or two answers to one question
+func (s *QuestionnaireService) Check(ctx context.Context, programID uuid.UUID, answers []models.SessionAnswer) error {
+	if len(answers) == 0 {
+		return nil
+	}
+
+	q, err := s.questionnaireRepo.Get(ctx, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch questionnaire").WithError(err)
+	}
+	if q == nil {
+		return appErrors.NewBadRequestError("This program has no questionnaire")
+	}
+
+	seen := make(map[string]bool, len(answers))
+	for i := range answers {
+		a := &answers[i]
+		question := q.Question(a.QuestionID)
+		if question == nil {
+			return appErrors.NewBadRequestError(fmt.Sprintf("Unknown question %q", a.QuestionID))
+		}
+		if seen[a.QuestionID] {
+			return appErrors.NewBadRequestError(fmt.Sprintf("Question %q is answered twice", a.QuestionID))
+		}
+		seen[a.QuestionID] = true
+
+		switch question.Type {
+		case models.QuestionScale:
+			if a.Scale == nil || a.Text != nil {
+				return appErrors.NewBadRequestError(fmt.Sprintf("Question %q takes a scale value", a.QuestionID))
+			}
+			if *a.Scale < question.Min || *a.Scale > question.Max {
+				return appErrors.NewBadRequestError(fmt.Sprintf("Question %q takes a value from %d to %d", a.QuestionID, question.Min, question.Max))
+			}
+		case models.QuestionText:
+			if a.Text == nil || a.Scale != nil {
+				return appErrors.NewBadRequestError(fmt.Sprintf("Question %q takes a text answer", a.QuestionID))
+			}
+			if strings.TrimSpace(*a.Text) == "" {
+				return appErrors.NewBadRequestError(fmt.Sprintf("The answer to question %q is empty", a.QuestionID))
+			}
+		}
+	}
+	return nil
+}
+
+// Save records answers that passed Check
+func (s *QuestionnaireService) Save(ctx context.Context, sessionID uuid.UUID, answers []models.SessionAnswer) error {
+	if len(answers) == 0 {
+		return nil
+	}
+	if err := s.questionnaireRepo.SaveAnswers(ctx, sessionID, answers); err != nil {
+		return appErrors.NewInternalError("Failed to save answers").WithError(err)
+	}
+	return nil
+}
+
+// Answer records answers to the questionnaire after the session was completed, replacing earlier
+// answers to the same questions, and returns all answers about the session
+func (s *QuestionnaireService) Answer(ctx context.Context, sessionID, userID uuid.UUID, answers []models.SessionAnswer) ([]models.SessionAnswer, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch session").WithError(err)
+	}
+	if session == nil {
+		return nil, appErrors.NewNotFoundError("Session")
+	}
+	if session.UserID != userID {
+		return nil, appErrors.NewAuthorizationError("You don't have access to this session")
+	}
+	if session.CompletedAt == nil {
+		return nil, appErrors.NewBadRequestError("Answer the questionnaire when completing the session")
+	}
+
+	if err := s.Check(ctx, session.ProgramID, answers); err != nil {
+		return nil, err
+	}
+	if err := s.Save(ctx, sessionID, answers); err != nil {
+		return nil, err
+	}
+	return s.ListAnswers(ctx, sessionID)
+}
+
+// ListAnswers returns the answers given about a session
+func (s *QuestionnaireService) ListAnswers(ctx context.Context, sessionID uuid.UUID) ([]models.SessionAnswer, error) {
+	answers, err := s.questionnaireRepo.ListAnswers(ctx, sessionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch answers").WithError(err)
+	}
+	return answers, nil
+}
+
+// Results aggregates the answers to a program's questionnaire, in the order of its questions.
+// Answers to questions since removed from the questionnaire are left out.
+func (s *QuestionnaireService) Results(ctx context.Context, programID uuid.UUID) (*models.QuestionnaireResults, error) {
+	q, err := s.Get(ctx, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.questionnaireRepo.CountAnsweredSessions(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to count answered sessions").WithError(err)
+	}
+	scales, err := s.questionnaireRepo.ScaleCounts(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to aggregate answers").WithError(err)
+	}
+	totals, latest, err := s.questionnaireRepo.TextAnswers(ctx, programID, latestTextAnswers)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch answers").WithError(err)
+	}
+
+	results := &models.QuestionnaireResults{
+		ProgramID:        programID,
+		SessionsAnswered: sessions,
+		Questions:        make([]models.QuestionnaireQuestionResult, 0, len(q.Questions)),
+	}
+	for _, question := range q.Questions {
+		result := models.QuestionnaireQuestionResult{
+			QuestionID: question.ID,
+			Type:       question.Type,
+			Prompt:     question.Prompt,
+		}
+		if question.Type == models.QuestionText {
+			result.Responses = totals[question.ID]
+			result.Latest = latest[question.ID]
+		} else {
+			result.Distribution = scales[question.ID]
+			sum := 0
+			for _, c := range result.Distribution {
+				result.Responses += c.Count
+				sum += c.Value * c.Count
+			}
+			if result.Responses > 0 {
+				average := math.Round(float64(sum)/float64(result.Responses)*100) / 100
+				result.Average = &average
+			}
+		}
+		results.Questions = append(results.Questions, result)
+	}
+	return results, nil
+}
+
+func (s *QuestionnaireService) ensureProgram(ctx context.Context, programID uuid.UUID) error {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return appErrors.NewNotFoundError("Program")
+	}
+	return nil
+}
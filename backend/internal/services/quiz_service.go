@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// DefaultPassThreshold is the score in percent a quiz attempt needs unless the quiz sets its own
+const DefaultPassThreshold = 70
+
+// QuizService manages knowledge checks attached to programs and scores students' attempts
+type QuizService struct {
+	quizRepo    *repositories.QuizRepository
+	programRepo *repositories.ProgramRepository
+}
+
+func NewQuizService(quizRepo *repositories.QuizRepository, programRepo *repositories.ProgramRepository) *QuizService {
+	return &QuizService{
+		quizRepo:    quizRepo,
+		programRepo: programRepo,
+	}
+}
+
+// Create attaches a quiz to a program. Each question needs at least one correct choice.
+func (s *QuizService) Create(ctx context.Context, programID, createdBy uuid.UUID, title string, description *string, passThreshold, maxAttempts *int, questions []models.QuizQuestion) (*models.Quiz, error) {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return nil, appErrors.NewNotFoundError("Program")
+	}
+	if err := validateQuestions(questions); err != nil {
+		return nil, err
+	}
+
+	quiz := &models.Quiz{
+		ProgramID:     programID,
+		Title:         title,
+		Description:   description,
+		PassThreshold: DefaultPassThreshold,
+		MaxAttempts:   maxAttempts,
+		CreatedBy:     &createdBy,
+		Questions:     questions,
+	}
+	if passThreshold != nil {
+		quiz.PassThreshold = *passThreshold
+	}
+	if err := s.quizRepo.Create(ctx, quiz); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create quiz").WithError(err)
+	}
+
+	return s.getQuiz(ctx, quiz.ID)
+}
+
+func (s *QuizService) ListByProgram(ctx context.Context, programID uuid.UUID) ([]models.Quiz, error) {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return nil, appErrors.NewNotFoundError("Program")
+	}
+
+	quizzes, err := s.quizRepo.ListByProgram(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch quizzes").WithError(err)
+	}
+	return quizzes, nil
+}
+
+// Get returns a quiz with its questions. Correct answers and explanations are only included for admins.
+func (s *QuizService) Get(ctx context.Context, id uuid.UUID, isAdmin bool) (*models.Quiz, error) {
+	quiz, err := s.getQuiz(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		for i := range quiz.Questions {
+			question := &quiz.Questions[i]
+			question.Explanation = nil
+			for j := range question.Choices {
+				question.Choices[j].IsCorrect = nil
+			}
+		}
+	}
+	return quiz, nil
+}
+
+// Update changes quiz settings. A maxAttempts of 0 removes the limit, and non-nil questions
+// replace all questions; earlier attempts keep their scores.
+func (s *QuizService) Update(ctx context.Context, id uuid.UUID, title, description *string, passThreshold, maxAttempts *int, questions []models.QuizQuestion) (*models.Quiz, error) {
+	quiz, err := s.getQuiz(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if title != nil {
+		quiz.Title = *title
+	}
+	if description != nil {
+		quiz.Description = description
+	}
+	if passThreshold != nil {
+		quiz.PassThreshold = *passThreshold
+	}
+	if maxAttempts != nil {
+		quiz.MaxAttempts = maxAttempts
+		if *maxAttempts == 0 {
+			quiz.MaxAttempts = nil
+		}
+	}
+	if questions != nil {
+		if err := validateQuestions(questions); err != nil {
+			return nil, err
+		}
+		quiz.Questions = questions
+	}
+
+	if err := s.quizRepo.Update(ctx, quiz, questions != nil); err != nil {
+		return nil, appErrors.NewInternalError("Failed to update quiz").WithError(err)
+	}
+
+	return s.getQuiz(ctx, id)
+}
+
+func (s *QuizService) Delete(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.quizRepo.Delete(ctx, id)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete quiz").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Quiz")
+	}
+	return nil
+}
+
+// Submit scores an attempt. A question counts as correct when exactly its correct choices
+// are selected; unanswered questions count as wrong. Students need the program assigned.
+func (s *QuizService) Submit(ctx context.Context, quizID, userID uuid.UUID, isAdmin bool, answers map[uuid.UUID][]uuid.UUID) (*models.QuizAttempt, error) {
+	quiz, err := s.getQuiz(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isAdmin {
+		assignment, err := s.programRepo.GetUserProgram(ctx, userID, quiz.ProgramID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to verify program assignment").WithError(err)
+		}
+		if assignment == nil || !assignment.IsActive {
+			return nil, appErrors.NewAuthorizationError("This program is not assigned to you")
+		}
+	}
+
+	if quiz.MaxAttempts != nil {
+		count, err := s.quizRepo.CountAttempts(ctx, quizID, userID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to count attempts").WithError(err)
+		}
+		if count >= *quiz.MaxAttempts {
+			return nil, appErrors.NewConflictError(fmt.Sprintf("You have used all %d attempts for this quiz", *quiz.MaxAttempts))
+		}
+	}
+
+	attempt, err := scoreAttempt(quiz, answers)
+	if err != nil {
+		return nil, err
+	}
+	attempt.UserID = userID
+	if err := s.quizRepo.CreateAttempt(ctx, attempt); err != nil {
+		return nil, appErrors.NewInternalError("Failed to save attempt").WithError(err)
+	}
+
+	return attempt, nil
+}
+
+// ListAttempts returns the user's attempt history for a quiz, newest first
+func (s *QuizService) ListAttempts(ctx context.Context, quizID, userID uuid.UUID) ([]models.QuizAttempt, error) {
+	if _, err := s.getQuiz(ctx, quizID); err != nil {
+		return nil, err
+	}
+	attempts, err := s.quizRepo.ListAttempts(ctx, quizID, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch attempts").WithError(err)
+	}
+	return attempts, nil
+}
+
+// scoreAttempt grades the answers against the quiz. The correct choices are revealed only
+// when the attempt passes, so failed attempts cannot simply be corrected and resubmitted.
+func scoreAttempt(quiz *models.Quiz, answers map[uuid.UUID][]uuid.UUID) (*models.QuizAttempt, error) {
+	questions := make(map[uuid.UUID]*models.QuizQuestion, len(quiz.Questions))
+	for i := range quiz.Questions {
+		questions[quiz.Questions[i].ID] = &quiz.Questions[i]
+	}
+	for questionID, choiceIDs := range answers {
+		question, ok := questions[questionID]
+		if !ok {
+			return nil, appErrors.NewBadRequestError(fmt.Sprintf("Question %s is not part of this quiz", questionID))
+		}
+		for _, choiceID := range choiceIDs {
+			if !slices.ContainsFunc(question.Choices, func(c models.QuizChoice) bool { return c.ID == choiceID }) {
+				return nil, appErrors.NewBadRequestError(fmt.Sprintf("Choice %s does not belong to question %s", choiceID, questionID))
+			}
+		}
+	}
+
+	attempt := &models.QuizAttempt{
+		QuizID:  quiz.ID,
+		Answers: answers,
+		Results: make([]models.QuestionResult, 0, len(quiz.Questions)),
+	}
+	correct := 0
+	for _, question := range quiz.Questions {
+		var correctIDs []uuid.UUID
+		for _, choice := range question.Choices {
+			if *choice.IsCorrect {
+				correctIDs = append(correctIDs, choice.ID)
+			}
+		}
+
+		selected := slices.Clone(answers[question.ID])
+		slices.SortFunc(selected, compareUUIDs)
+		selected = slices.Compact(selected)
+		slices.SortFunc(correctIDs, compareUUIDs)
+
+		result := models.QuestionResult{
+			QuestionID:       question.ID,
+			Correct:          slices.Equal(selected, correctIDs),
+			CorrectChoiceIDs: correctIDs,
+			Explanation:      question.Explanation,
+		}
+		if result.Correct {
+			correct++
+		}
+		attempt.Results = append(attempt.Results, result)
+	}
+
+	if len(quiz.Questions) > 0 {
+		attempt.Score = math.Round(float64(correct)/float64(len(quiz.Questions))*10000) / 100
+	}
+	attempt.Passed = attempt.Score >= float64(quiz.PassThreshold)
+	if !attempt.Passed {
+		for i := range attempt.Results {
+			attempt.Results[i].CorrectChoiceIDs = nil
+		}
+	}
+	return attempt, nil
+}
+
+func compareUUIDs(a, b uuid.UUID) int {
+	return slices.Compare(a[:], b[:])
+}
+
+// validateQuestions checks that every question can be answered correctly
+func validateQuestions(questions []models.QuizQuestion) error {
+	for i, question := range questions {
+		hasCorrect := slices.ContainsFunc(question.Choices, func(c models.QuizChoice) bool {
+			return c.IsCorrect != nil && *c.IsCorrect
+		})
+		if !hasCorrect {
+			return appErrors.NewBadRequestError(fmt.Sprintf("Question %d needs at least one correct choice", i+1))
+		}
+	}
+	return nil
+}
+
+func (s *QuizService) getQuiz(ctx context.Context, id uuid.UUID) (*models.Quiz, error) {
+	quiz, err := s.quizRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch quiz").WithError(err)
+	}
+	if quiz == nil {
+		return nil, appErrors.NewNotFoundError("Quiz")
+	}
+	return quiz, nil
+}
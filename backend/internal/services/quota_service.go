@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// QuotaService manages quota plans and enforces the soft limits on user-generated content.
+// Limits are checked before content is created, so concurrent requests can overshoot slightly.
+type QuotaService struct {
+	quotaRepo *repositories.QuotaRepository
+	userRepo  *repositories.UserRepository
+}
+
+func NewQuotaService(quotaRepo *repositories.QuotaRepository, userRepo *repositories.UserRepository) *QuotaService {
+	return &QuotaService{
+		quotaRepo: quotaRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// ListPlans returns all quota plans
+func (s *QuotaService) ListPlans(ctx context.Context) ([]models.QuotaPlan, error) {
+	plans, err := s.quotaRepo.ListPlans(ctx)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to list quota plans").WithError(err)
+	}
+	return plans, nil
+}
+
+// SavePlan creates a plan or replaces its limits
+func (s *QuotaService) SavePlan(ctx context.Context, name string, limits models.QuotaLimits) (*models.QuotaPlan, error) {
+	plan := &models.QuotaPlan{Name: name, QuotaLimits: limits}
+	if err := s.quotaRepo.SavePlan(ctx, plan); err != nil {
+		return nil, appErrors.NewInternalError("Failed to save quota plan").WithError(err)
+	}
+	return plan, nil
+}
+
+// DeletePlan removes a plan and moves its users to the default plan
+func (s *QuotaService) DeletePlan(ctx context.Context, name string) error {
+	if name == models.DefaultQuotaPlan {
+		return appErrors.NewBadRequestError("The default plan can't be deleted")
+	}
+	deleted, err := s.quotaRepo.DeletePlan(ctx, name)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete quota plan").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Quota plan")
+	}
+	return nil
+}
+
+// GetUserQuota returns a user's plan, overrides, effective limits and current usage
+func (s *QuotaService) GetUserQuota(ctx context.Context, userID uuid.UUID) (*models.UserQuota, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch user").WithError(err)
+	}
+	if user == nil {
+		return nil, appErrors.NewNotFoundError("User")
+	}
+	return s.quotaFor(ctx, user)
+}
+
+// SetUserQuota puts a user on a plan (nil for the default plan) with per-user overrides
+func (s *QuotaService) SetUserQuota(ctx context.Context, userID uuid.UUID, plan *string, overrides models.QuotaLimits) (*models.UserQuota, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch user").WithError(err)
+	}
+	if user == nil {
+		return nil, appErrors.NewNotFoundError("User")
+	}
+
+	if plan != nil {
+		exists, err := s.quotaRepo.PlanExists(ctx, *plan)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch quota plan").WithError(err)
+		}
+		if !exists {
+			return nil, appErrors.NewNotFoundError("Quota plan")
+		}
+		if *plan == models.DefaultQuotaPlan {
+			plan = nil // Follow the default plan even if it is renamed
+		}
+	}
+
+	if err := s.quotaRepo.SaveUserQuota(ctx, userID, plan, overrides); err != nil {
+		return nil, appErrors.NewInternalError("Failed to save user quota").WithError(err)
+	}
+	return s.quotaFor(ctx, user)
+}
+
+// CheckPrograms fails when the user may not own another program
+func (s *QuotaService) CheckPrograms(ctx context.Context, userID uuid.UUID) error {
+	quota, err := s.checkedQuota(ctx, userID)
+	if err != nil || quota == nil || quota.Limits.MaxPrograms == nil {
+		return err
+	}
+	limit, used := int64(*quota.Limits.MaxPrograms), int64(quota.Usage.Programs)
+	if used >= limit {
+		return appErrors.NewQuotaExceededError("programs",
+			fmt.Sprintf("You can own at most %d programs. Delete a program to create a new one.", limit), limit, used)
+	}
+	return nil
+}
+
+// CheckOpenSubmissions fails when the user may not open another submission
+func (s *QuotaService) CheckOpenSubmissions(ctx context.Context, userID uuid.UUID) error {
+	quota, err := s.checkedQuota(ctx, userID)
+	if err != nil || quota == nil || quota.Limits.MaxOpenSubmissions == nil {
+		return err
+	}
+	limit, used := int64(*quota.Limits.MaxOpenSubmissions), int64(quota.Usage.OpenSubmissions)
+	if used >= limit {
+		return appErrors.NewQuotaExceededError("open_submissions",
+			fmt.Sprintf("You can have at most %d submissions waiting for feedback. Wait for a reply before opening a new one.", limit), limit, used)
+	}
+	return nil
+}
+
+// CheckStorage fails when an upload of size bytes would put the user over their storage quota.
+// For a cover, the program's current cover doesn't count, since the new one replaces it; other
+// uploads pass uuid.Nil.
+func (s *QuotaService) CheckStorage(ctx context.Context, userID, programID uuid.UUID, size int64) error {
+	quota, err := s.checkedQuota(ctx, userID)
+	if err != nil || quota == nil || quota.Limits.MaxStorageBytes == nil {
+		return err
+	}
+	used, err := s.quotaRepo.GetStorageBytes(ctx, userID, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to check storage quota").WithError(err)
+	}
+	limit := *quota.Limits.MaxStorageBytes
+	if used+size > limit {
+		return appErrors.NewQuotaExceededError("storage_bytes",
+			fmt.Sprintf("This upload needs %d bytes but only %d of your %d bytes of storage are left.", size, max(limit-used, 0), limit), limit, used)
+	}
+	return nil
+}
+
+// checkedQuota returns the quota to check content against, or nil for exempt users
+func (s *QuotaService) checkedQuota(ctx context.Context, userID uuid.UUID) (*models.UserQuota, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch user").WithError(err)
+	}
+	if user == nil || user.Role == models.RoleAdmin {
+		return nil, nil
+	}
+	return s.quotaFor(ctx, user)
+}
+
+func (s *QuotaService) quotaFor(ctx context.Context, user *models.User) (*models.UserQuota, error) {
+	quota, err := s.quotaRepo.GetUserQuota(ctx, user.ID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch quota").WithError(err)
+	}
+	usage, err := s.quotaRepo.GetUsage(ctx, user.ID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch quota usage").WithError(err)
+	}
+	quota.Usage = *usage
+	quota.Exempt = user.Role == models.RoleAdmin
+	return quota, nil
+}
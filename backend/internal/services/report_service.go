@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/xuangong/backend/internal/anonymize"
+	"github.com/xuangong/backend/internal/export"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// ReportService streams the admin reports as CSV or XLSX downloads
+type ReportService struct {
+	reportRepo *repositories.ReportRepository
+	anonymizer *anonymize.Anonymizer
+}
+
+func NewReportService(reportRepo *repositories.ReportRepository, anonymizer *anonymize.Anonymizer) *ReportService {
+	return &ReportService{reportRepo: reportRepo, anonymizer: anonymizer}
+}
+
+// Check validates a report request before any output is written
+func (s *ReportService) Check(name, format string, from, to time.Time) error {
+	if _, ok := s.reportRepo.Get(name); !ok {
+		return appErrors.NewNotFoundError("Report")
+	}
+	if format != export.FormatCSV && format != export.FormatXLSX {
+		return appErrors.NewBadRequestError("Unsupported report format")
+	}
+	if !from.Before(to) {
+		return appErrors.NewBadRequestError("from must be before to")
+	}
+	return nil
+}
+
+// Write runs a report over [from, to) and streams it to w in the given format
+func (s *ReportService) Write(ctx context.Context, name, format string, from, to time.Time, w io.Writer) error {
+	if err := s.Check(name, format, from, to); err != nil {
+		return err
+	}
+	report, _ := s.reportRepo.Get(name)
+
+	table, err := export.NewTableWriter(format, report.Name, w)
+	if err != nil {
+		return appErrors.NewBadRequestError("Unsupported report format")
+	}
+	if err := table.WriteHeader(report.Columns); err != nil {
+		return err
+	}
+	writeRow := table.WriteRow
+	if s.anonymizer.Enabled() {
+		writeRow = s.anonymizedRows(report, table.WriteRow)
+	}
+	if err := s.reportRepo.Stream(ctx, report, from, to, writeRow); err != nil {
+		return appErrors.NewInternalError("Failed to run report").WithError(err)
+	}
+	return table.Close()
+}
+
+// anonymizedRows wraps write to pseudonymize the report's user IDs and drop its personal columns
+func (s *ReportService) anonymizedRows(report repositories.Report, write func([]any) error) func([]any) error {
+	ids := columnIndexes(report.Columns, report.UserIDColumns)
+	personal := columnIndexes(report.Columns, report.PersonalColumns)
+	return func(values []any) error {
+		for _, i := range ids {
+			if id, ok := values[i].(string); ok {
+				values[i] = s.anonymizer.IDString(id)
+			}
+		}
+		for _, i := range personal {
+			values[i] = nil
+		}
+		return write(values)
+	}
+}
+
+func columnIndexes(columns, names []string) []int {
+	indexes := make([]int, 0, len(names))
+	for i, column := range columns {
+		for _, name := range names {
+			if column == name {
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	return indexes
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// RetentionService applies the data retention rules. A daily job runs each enabled rule, or only
+// reports what it would change for rules configured as dry runs. Admins can request a dry run
+// of any enabled rule to check it before it deletes anything.
+type RetentionService struct {
+	retentionRepo *repositories.RetentionRepository
+	cfg           *config.RetentionConfig
+	clock         clock.Clock
+}
+
+func NewRetentionService(retentionRepo *repositories.RetentionRepository, cfg *config.RetentionConfig) *RetentionService {
+	return &RetentionService{
+		retentionRepo: retentionRepo,
+		cfg:           cfg,
+		clock:         clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *RetentionService) WithClock(c clock.Clock) *RetentionService {
+	s.clock = c
+	return s
+}
+
+// Rules returns every rule with its configuration and current cutoff
+func (s *RetentionService) Rules() []models.RetentionRule {
+	rules := make([]models.RetentionRule, 0, len(models.RetentionRules))
+	for _, name := range models.RetentionRules {
+		cutoff := s.cutoff(name)
+		rules = append(rules, models.RetentionRule{
+			Name:    name,
+			Enabled: cutoff != nil,
+			DryRun:  s.cfg.IsDryRun(name),
+			Cutoff:  cutoff,
+		})
+	}
+	return rules
+}
+
+// DryRun reports what the rule would change now, without changing anything
+func (s *RetentionService) DryRun(ctx context.Context, rule string, adminID uuid.UUID) (*models.RetentionRun, error) {
+	if !isRetentionRule(rule) {
+		return nil, appErrors.NewNotFoundError("Retention rule")
+	}
+	cutoff := s.cutoff(rule)
+	if cutoff == nil {
+		return nil, appErrors.NewBadRequestError("Retention rule " + rule + " is turned off")
+	}
+	return s.run(ctx, &models.RetentionRun{Rule: rule, DryRun: true, Cutoff: *cutoff, TriggeredBy: &adminID})
+}
+
+// RunDue runs each enabled rule once RETENTION_HOUR (UTC) has passed today, unless the daily job
+// already ran it since. Returns the runs made.
+func (s *RetentionService) RunDue(ctx context.Context) ([]models.RetentionRun, error) {
+	now := s.clock.Now().UTC()
+	due := time.Date(now.Year(), now.Month(), now.Day(), s.cfg.Hour, 0, 0, 0, time.UTC)
+	if now.Before(due) {
+		return nil, nil
+	}
+
+	runs := make([]models.RetentionRun, 0)
+	for _, rule := range models.RetentionRules {
+		cutoff := s.cutoff(rule)
+		if cutoff == nil {
+			continue
+		}
+		lastRunAt, err := s.retentionRepo.LastScheduledRunAt(ctx, rule)
+		if err != nil {
+			return runs, appErrors.NewInternalError("Failed to fetch last retention run").WithError(err)
+		}
+		if lastRunAt != nil && !lastRunAt.Before(due) {
+			continue
+		}
+		run, err := s.run(ctx, &models.RetentionRun{Rule: rule, DryRun: s.cfg.IsDryRun(rule), Scheduled: true, Cutoff: *cutoff})
+		if err != nil {
+			return runs, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, nil
+}
+
+// ListRuns returns run reports, newest first; rule is optional
+func (s *RetentionService) ListRuns(ctx context.Context, rule string, limit, offset int) ([]models.RetentionRun, error) {
+	if rule != "" && !isRetentionRule(rule) {
+		return nil, appErrors.NewNotFoundError("Retention rule")
+	}
+	runs, err := s.retentionRepo.ListRuns(ctx, rule, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch retention runs").WithError(err)
+	}
+	return runs, nil
+}
+
+// run applies the rule and records the report. A rule that fails part way is still recorded
+// with what it changed before the error.
+func (s *RetentionService) run(ctx context.Context, run *models.RetentionRun) (*models.RetentionRun, error) {
+	affected, applyErr := s.retentionRepo.Apply(ctx, run.Rule, run.Cutoff, run.DryRun)
+	run.Affected = affected
+	if applyErr != nil && (run.DryRun || len(affected) == 0) {
+		return nil, appErrors.NewInternalError("Failed to apply retention rule").WithError(applyErr)
+	}
+	if err := s.retentionRepo.CreateRun(ctx, run); err != nil {
+		return nil, appErrors.NewInternalError("Failed to save retention report").WithError(err)
+	}
+	if applyErr != nil {
+		return nil, appErrors.NewInternalError("Failed to apply retention rule").WithError(applyErr)
+	}
+	return run, nil
+}
+
+// cutoff returns the time before which the rule applies, or nil if the rule is turned off
+func (s *RetentionService) cutoff(rule string) *time.Time {
+	now := s.clock.Now()
+	var cutoff time.Time
+	switch rule {
+	case models.RetentionRuleSessions:
+		if s.cfg.SessionYears == 0 {
+			return nil
+		}
+		cutoff = now.AddDate(-s.cfg.SessionYears, 0, 0)
+	case models.RetentionRuleSoftDeleted:
+		if s.cfg.SoftDeletedDays == 0 {
+			return nil
+		}
+		cutoff = now.AddDate(0, 0, -s.cfg.SoftDeletedDays)
+	case models.RetentionRuleDeviceInfo:
+		if s.cfg.DeviceInfoDays == 0 {
+			return nil
+		}
+		cutoff = now.AddDate(0, 0, -s.cfg.DeviceInfoDays)
+	default:
+		return nil
+	}
+	return &cutoff
+}
+
+func isRetentionRule(rule string) bool {
+	for _, name := range models.RetentionRules {
+		if name == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/youtube"
+)
+
+// scheduledMessageBatch caps how many due messages one delivery run claims
+const scheduledMessageBatch = 100
+
+type ScheduledMessageService struct {
+	scheduledRepo       *repositories.ScheduledMessageRepository
+	programRepo         *repositories.ProgramRepository
+	submissionService   *SubmissionService
+	notificationService *NotificationService
+	clock               clock.Clock
+}
+
+func NewScheduledMessageService(scheduledRepo *repositories.ScheduledMessageRepository, programRepo *repositories.ProgramRepository, submissionService *SubmissionService, notificationService *NotificationService) *ScheduledMessageService {
+	return &ScheduledMessageService{
+		scheduledRepo:       scheduledRepo,
+		programRepo:         programRepo,
+		submissionService:   submissionService,
+		notificationService: notificationService,
+		clock:               clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *ScheduledMessageService) WithClock(c clock.Clock) *ScheduledMessageService {
+	s.clock = c
+	return s
+}
+
+// Schedule queues a submission message or, if message.ProgramID is set, an announcement to the
+// program's students. Targets and mentions are checked now so mistakes surface to the author.
+func (s *ScheduledMessageService) Schedule(ctx context.Context, message *models.ScheduledMessage) error {
+	if !message.SendAt.After(s.clock.Now()) {
+		return appErrors.NewBadRequestError("send_at must be in the future")
+	}
+	if message.YouTubeURL != nil && *message.YouTubeURL != "" {
+		if _, err := youtube.ValidateURL(*message.YouTubeURL); err != nil {
+			return appErrors.NewBadRequestError(fmt.Sprintf("Invalid YouTube URL: %v", err))
+		}
+	}
+
+	switch {
+	case message.SubmissionID != nil:
+		if _, err := s.submissionService.GetSubmission(ctx, *message.SubmissionID, message.AuthorID, true); err != nil {
+			return err
+		}
+		if _, err := s.submissionService.resolveMentions(ctx, *message.SubmissionID, message.AuthorID, message.Content); err != nil {
+			return err
+		}
+		message.Title = nil
+	case message.ProgramID != nil:
+		program, err := s.programRepo.GetByID(ctx, *message.ProgramID)
+		if err != nil {
+			return appErrors.NewInternalError("Failed to fetch program").WithError(err)
+		}
+		if program == nil {
+			return appErrors.NewNotFoundError("Program")
+		}
+		if message.Title == nil || *message.Title == "" {
+			return appErrors.NewBadRequestError("Announcements need a title")
+		}
+	default:
+		return appErrors.NewBadRequestError("Either submission_id or program_id is required")
+	}
+
+	if err := s.scheduledRepo.Create(ctx, message); err != nil {
+		return appErrors.NewInternalError("Failed to schedule message").WithError(err)
+	}
+	return nil
+}
+
+// List returns the author's scheduled messages with the given status
+func (s *ScheduledMessageService) List(ctx context.Context, authorID uuid.UUID, status models.ScheduledMessageStatus) ([]models.ScheduledMessage, error) {
+	messages, err := s.scheduledRepo.ListByAuthor(ctx, authorID, status)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to list scheduled messages").WithError(err)
+	}
+	return messages, nil
+}
+
+// Cancel stops one of the author's pending messages from being delivered
+func (s *ScheduledMessageService) Cancel(ctx context.Context, id, authorID uuid.UUID) error {
+	message, err := s.scheduledRepo.GetByID(ctx, id, authorID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch scheduled message").WithError(err)
+	}
+	if message == nil {
+		return appErrors.NewNotFoundError("Scheduled message")
+	}
+
+	cancelled, err := s.scheduledRepo.Cancel(ctx, id, authorID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to cancel scheduled message").WithError(err)
+	}
+	if !cancelled {
+		return appErrors.NewConflictError(fmt.Sprintf("Scheduled message is already %s", message.Status))
+	}
+	return nil
+}
+
+// DeliverDue posts all messages whose send_at has passed and notifies their recipients.
+// Messages that cannot be delivered are marked failed with the reason. Returns the number delivered.
+func (s *ScheduledMessageService) DeliverDue(ctx context.Context) (int, error) {
+	due, err := s.scheduledRepo.ClaimDue(ctx, s.clock.Now(), scheduledMessageBatch)
+	if err != nil {
+		return 0, appErrors.NewInternalError("Failed to claim scheduled messages").WithError(err)
+	}
+
+	delivered := 0
+	for _, message := range due {
+		messageID, recipients, err := s.deliver(ctx, &message)
+		if err != nil {
+			log.Printf("[WARN] Failed to deliver scheduled message %s: %v", message.ID, err)
+			if err := s.scheduledRepo.MarkFailed(ctx, message.ID, err.Error()); err != nil {
+				return delivered, appErrors.NewInternalError("Failed to mark scheduled message as failed").WithError(err)
+			}
+			continue
+		}
+		if err := s.scheduledRepo.MarkDelivered(ctx, message.ID, messageID, recipients); err != nil {
+			return delivered, appErrors.NewInternalError("Failed to mark scheduled message as sent").WithError(err)
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+func (s *ScheduledMessageService) deliver(ctx context.Context, message *models.ScheduledMessage) (*uuid.UUID, int, error) {
+	if message.SubmissionID != nil {
+		posted, recipients, err := s.submissionService.PostScheduledMessage(ctx, *message.SubmissionID, message.AuthorID, message.Content, message.YouTubeURL)
+		if err != nil {
+			return nil, 0, err
+		}
+		return &posted.ID, recipients, nil
+	}
+
+	studentIDs, err := s.programRepo.ListAssignedUserIDs(ctx, *message.ProgramID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list program students: %w", err)
+	}
+
+	payload := map[string]interface{}{
+		"program_id":           message.ProgramID.String(),
+		"scheduled_message_id": message.ID.String(),
+	}
+	recipients := 0
+	for _, studentID := range studentIDs {
+		if _, err := s.notificationService.Notify(ctx, studentID, models.NotificationAnnouncement, *message.Title, &message.Content, payload); err != nil {
+			log.Printf("[WARN] Failed to notify user %s about announcement %s: %v", studentID, message.ID, err)
+			continue
+		}
+		recipients++
+	}
+	return nil, recipients, nil
+}
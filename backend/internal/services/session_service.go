@@ -2,32 +2,107 @@ package services
 
 import (
 	"context"
+	"log"
+	"math"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/hydrate"
 	"github.com/xuangong/backend/internal/models"
 	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
 	appErrors "github.com/xuangong/backend/pkg/errors"
 )
 
 type SessionService struct {
-	sessionRepo *repositories.SessionRepository
-	programRepo *repositories.ProgramRepository
+	sessionRepo         *repositories.SessionRepository
+	programRepo         *repositories.ProgramRepository
+	substituteRepo      *repositories.ExerciseSubstituteRepository
+	notificationService *NotificationService
+	streakService       *StreakService
+	reconciliationRepo  *repositories.ReconciliationRepository
+	experimentRepo      *repositories.ExperimentRepository
+	questionnaires      *QuestionnaireService
+	cfg                 *config.SessionsConfig
+	clock               clock.Clock
+
+	// Programs whose completed count failed to update, retried by RetryRecounts
+	recountMu sync.Mutex
+	recounts  map[uuid.UUID]bool
 }
 
-func NewSessionService(sessionRepo *repositories.SessionRepository, programRepo *repositories.ProgramRepository) *SessionService {
+func NewSessionService(sessionRepo *repositories.SessionRepository, programRepo *repositories.ProgramRepository, substituteRepo *repositories.ExerciseSubstituteRepository, notificationService *NotificationService, streakService *StreakService, reconciliationRepo *repositories.ReconciliationRepository, cfg *config.SessionsConfig) *SessionService {
 	return &SessionService{
-		sessionRepo: sessionRepo,
-		programRepo: programRepo,
+		sessionRepo:         sessionRepo,
+		programRepo:         programRepo,
+		substituteRepo:      substituteRepo,
+		notificationService: notificationService,
+		streakService:       streakService,
+		reconciliationRepo:  reconciliationRepo,
+		cfg:                 cfg,
+		clock:               clock.System,
+		recounts:            make(map[uuid.UUID]bool),
 	}
 }
 
-func (s *SessionService) StartSession(ctx context.Context, userID, programID uuid.UUID, deviceInfo map[string]interface{}) (*models.PracticeSession, error) {
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *SessionService) WithClock(c clock.Clock) *SessionService {
+	s.clock = c
+	return s
+}
+
+// WithExperiments tags sessions of programs that are an experiment variant for the student
+func (s *SessionService) WithExperiments(experimentRepo *repositories.ExperimentRepository) *SessionService {
+	s.experimentRepo = experimentRepo
+	return s
+}
+
+// WithQuestionnaires takes answers to the program's questionnaire when sessions are completed
+func (s *SessionService) WithQuestionnaires(questionnaireService *QuestionnaireService) *SessionService {
+	s.questionnaires = questionnaireService
+	return s
+}
+
+// StartSession starts a session of a program the user may practice: one they own, have assigned
+// or that is public. Admins can practice any program.
+func (s *SessionService) StartSession(ctx context.Context, userID uuid.UUID, role models.UserRole, programID uuid.UUID, deviceInfo map[string]interface{}) (*models.PracticeSession, error) {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return nil, appErrors.NewNotFoundError("Program")
+	}
+	if role != models.RoleAdmin && (!program.IsPublic || program.HiddenAt != nil) {
+		allowed, err := s.programRepo.CanAccess(ctx, userID, programID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to verify program assignment").WithError(err)
+		}
+		if !allowed {
+			return nil, appErrors.NewAuthorizationError("This program is not assigned to you")
+		}
+	}
+	return s.startSession(ctx, userID, programID, deviceInfo)
+}
+
+// startSession starts a session without checking access to the program, for check-ins with a
+// code an instructor handed out
+func (s *SessionService) startSession(ctx context.Context, userID, programID uuid.UUID, deviceInfo map[string]interface{}) (*models.PracticeSession, error) {
 	session := &models.PracticeSession{
 		UserID:     userID,
 		ProgramID:  programID,
 		DeviceInfo: deviceInfo,
 	}
+	if s.experimentRepo != nil {
+		experimentID, variant, err := s.experimentRepo.FindVariant(ctx, userID, programID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch experiment variant").WithError(err)
+		}
+		session.ExperimentID, session.Variant = experimentID, variant
+	}
 
 	if err := s.sessionRepo.Create(ctx, session); err != nil {
 		return nil, appErrors.NewInternalError("Failed to start session").WithError(err)
@@ -56,10 +131,16 @@ func (s *SessionService) GetSession(ctx context.Context, sessionID, userID uuid.
 		return nil, appErrors.NewInternalError("Failed to fetch exercise logs").WithError(err)
 	}
 
-	return &models.SessionWithLogs{
+	result := &models.SessionWithLogs{
 		Session:      *session,
 		ExerciseLogs: logs,
-	}, nil
+	}
+	if s.questionnaires != nil {
+		if result.Answers, err = s.questionnaires.ListAnswers(ctx, sessionID); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
 }
 
 func (s *SessionService) ListSessions(ctx context.Context, userID uuid.UUID, programID *uuid.UUID, startDate, endDate *time.Time, limit, offset int) ([]models.SessionWithLogs, error) {
@@ -69,21 +150,27 @@ func (s *SessionService) ListSessions(ctx context.Context, userID uuid.UUID, pro
 	}
 
 	// Convert to SessionWithLogs by fetching exercise logs for each session
-	sessionsWithLogs := make([]models.SessionWithLogs, 0, len(sessions))
-	for _, session := range sessions {
-		logs, err := s.sessionRepo.GetExerciseLogs(ctx, session.ID)
+	sessionsWithLogs := make([]models.SessionWithLogs, len(sessions))
+	err = hydrate.Each(ctx, len(sessions), func(ctx context.Context, i int) error {
+		logs, err := s.sessionRepo.GetExerciseLogs(ctx, sessions[i].ID)
 		if err != nil {
-			return nil, appErrors.NewInternalError("Failed to fetch exercise logs").WithError(err)
+			return appErrors.NewInternalError("Failed to fetch exercise logs").WithError(err)
 		}
-		sessionsWithLogs = append(sessionsWithLogs, models.SessionWithLogs{
-			Session:      session,
+		sessionsWithLogs[i] = models.SessionWithLogs{
+			Session:      sessions[i],
 			ExerciseLogs: logs,
-		})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return sessionsWithLogs, nil
 }
 
+// LogExercise records an exercise done in the session. A substitute in the log must be one of
+// the exercise's own.
 func (s *SessionService) LogExercise(ctx context.Context, sessionID, userID, exerciseID uuid.UUID, log *models.ExerciseLog) error {
 	// Verify session exists and belongs to user
 	session, err := s.sessionRepo.GetByID(ctx, sessionID)
@@ -97,12 +184,22 @@ func (s *SessionService) LogExercise(ctx context.Context, sessionID, userID, exe
 		return appErrors.NewAuthorizationError("You don't have access to this session")
 	}
 
+	if log.SubstituteID != nil {
+		substitute, err := s.substituteRepo.GetByID(ctx, *log.SubstituteID)
+		if err != nil {
+			return appErrors.NewInternalError("Failed to fetch substitute").WithError(err)
+		}
+		if substitute == nil || substitute.ExerciseID != exerciseID {
+			return appErrors.NewBadRequestError("Substitute doesn't belong to the exercise")
+		}
+	}
+
 	// Set session and exercise IDs
 	log.SessionID = sessionID
 	log.ExerciseID = &exerciseID
 
 	// Set timestamps if not provided
-	now := time.Now()
+	now := s.clock.Now()
 	if log.StartedAt == nil {
 		log.StartedAt = &now
 	}
@@ -117,7 +214,9 @@ func (s *SessionService) LogExercise(ctx context.Context, sessionID, userID, exe
 	return nil
 }
 
-func (s *SessionService) CompleteSession(ctx context.Context, sessionID, userID uuid.UUID, totalDuration int, completionRate float64, notes string, completedAt *time.Time) error {
+// CompleteSession ends a session. Answers to the program's questionnaire are checked before and
+// saved after the session is completed.
+func (s *SessionService) CompleteSession(ctx context.Context, sessionID, userID uuid.UUID, totalDuration int, completionRate float64, notes string, completedAt *time.Time, wellbeing *models.SessionWellbeing, answers []models.SessionAnswer) error {
 	// Verify session exists and belongs to user
 	session, err := s.sessionRepo.GetByID(ctx, sessionID)
 	if err != nil {
@@ -134,54 +233,595 @@ func (s *SessionService) CompleteSession(ctx context.Context, sessionID, userID
 		return appErrors.NewBadRequestError("Session already completed")
 	}
 
-	if err := s.sessionRepo.Complete(ctx, sessionID, totalDuration, completionRate, notes, completedAt); err != nil {
+	if len(answers) > 0 {
+		if s.questionnaires == nil {
+			return appErrors.NewBadRequestError("This program has no questionnaire")
+		}
+		if err := s.questionnaires.Check(ctx, session.ProgramID, answers); err != nil {
+			return err
+		}
+	}
+
+	if err := s.sessionRepo.Complete(ctx, sessionID, totalDuration, completionRate, notes, completedAt, wellbeing); err != nil {
 		return appErrors.NewInternalError("Failed to complete session").WithError(err)
 	}
 
-	// Update program repetitions_completed count
-	if err := s.programRepo.UpdateRepetitionsCompleted(ctx, session.ProgramID); err != nil {
-		// Log error but don't fail the request
-		// The session completion is more important than the count update
+	if len(answers) > 0 {
+		// The session is completed either way; the answers can be sent again to /sessions/:id/answers
+		if err := s.questionnaires.Save(ctx, sessionID, answers); err != nil {
+			return err
+		}
+	}
+
+	s.refreshRollups(ctx, session)
+
+	return nil
+}
+
+// UpdateSession applies bounded corrections to the user's own session and records an audit
+// entry. Admins correct other users' sessions through AdminUpdateSession.
+func (s *SessionService) UpdateSession(ctx context.Context, sessionID, userID uuid.UUID, update *models.SessionUpdate) (*models.PracticeSession, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch session").WithError(err)
+	}
+	if session == nil {
+		return nil, appErrors.NewNotFoundError("Session")
+	}
+	if session.UserID != userID {
+		return nil, appErrors.NewAuthorizationError("You don't have access to this session")
+	}
+	return s.updateSession(ctx, session, userID, update)
+}
+
+// AdminUpdateSession corrects any user's session for an admin, with the reason in the update
+func (s *SessionService) AdminUpdateSession(ctx context.Context, sessionID, adminID uuid.UUID, update *models.SessionUpdate) (*models.PracticeSession, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch session").WithError(err)
+	}
+	if session == nil {
+		return nil, appErrors.NewNotFoundError("Session")
+	}
+	return s.updateSession(ctx, session, adminID, update)
+}
+
+// updateSession validates and applies the corrections of the editor, who may change the session
+func (s *SessionService) updateSession(ctx context.Context, session *models.PracticeSession, editorID uuid.UUID, update *models.SessionUpdate) (*models.PracticeSession, error) {
+	sessionID := session.ID
+	if err := validateSessionUpdate(session, update, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	changes := sessionChanges(session, update)
+	if len(changes) == 0 {
+		return session, nil
+	}
+
+	edit := &models.SessionEdit{
+		SessionID: sessionID,
+		EditorID:  &editorID,
+		Changes:   changes,
+		Reason:    update.Reason,
+	}
+	if err := s.sessionRepo.Update(ctx, sessionID, update, edit); err != nil {
+		return nil, appErrors.NewInternalError("Failed to update session").WithError(err)
+	}
+
+	// Stats are computed on read; only the program rollup and streak snapshot are stored
+	s.refreshRollups(ctx, session)
+
+	updated, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch session").WithError(err)
+	}
+	return updated, nil
+}
+
+// UpdateExerciseLog corrects a logged exercise, e.g. an impossible duration, for admins. The
+// change is recorded in the edit history of the log's session and the log is flagged as edited.
+func (s *SessionService) UpdateExerciseLog(ctx context.Context, logID, adminID uuid.UUID, update *models.ExerciseLogUpdate) (*models.ExerciseLog, error) {
+	log, err := s.sessionRepo.GetExerciseLog(ctx, logID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch exercise log").WithError(err)
+	}
+	if log == nil {
+		return nil, appErrors.NewNotFoundError("Exercise log")
+	}
+	session, err := s.sessionRepo.GetByID(ctx, log.SessionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch session").WithError(err)
+	}
+	if session == nil {
+		return nil, appErrors.NewNotFoundError("Exercise log")
+	}
+
+	changes := exerciseLogChanges(log, update)
+	if len(changes) == 0 {
+		return log, nil
+	}
+
+	edit := &models.SessionEdit{
+		SessionID:     log.SessionID,
+		ExerciseLogID: &logID,
+		EditorID:      &adminID,
+		Changes:       changes,
+		Reason:        update.Reason,
+	}
+	if err := s.sessionRepo.UpdateExerciseLog(ctx, logID, update, edit); err != nil {
+		return nil, appErrors.NewInternalError("Failed to update exercise log").WithError(err)
+	}
+
+	updated, err := s.sessionRepo.GetExerciseLog(ctx, logID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch exercise log").WithError(err)
+	}
+	return updated, nil
+}
+
+// ListEdits returns the edit history of a session and its exercise logs, with who made each
+// change and the values before it. Owners see their own sessions' history, admins any.
+func (s *SessionService) ListEdits(ctx context.Context, sessionID, userID uuid.UUID, role models.UserRole) ([]models.SessionEdit, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch session").WithError(err)
+	}
+	if session == nil {
+		return nil, appErrors.NewNotFoundError("Session")
+	}
+	if session.UserID != userID && role != models.RoleAdmin {
+		return nil, appErrors.NewAuthorizationError("You don't have access to this session")
+	}
+
+	edits, err := s.sessionRepo.ListEdits(ctx, sessionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch session edits").WithError(err)
+	}
+	return edits, nil
+}
+
+// validateSessionUpdate checks edits against the recorded session.
+// Duration, completion rate and completion time can only be corrected on completed sessions.
+func validateSessionUpdate(session *models.PracticeSession, update *models.SessionUpdate, now time.Time) error {
+	if session.CompletedAt == nil && (update.TotalDurationSeconds != nil || update.CompletionRate != nil || update.CompletedAt != nil) {
+		return appErrors.NewBadRequestError("Only notes can be edited before the session is completed")
+	}
+
+	if update.CompletedAt != nil {
+		if update.CompletedAt.Before(session.StartedAt) {
+			return appErrors.NewBadRequestError("completed_at cannot be before the session start")
+		}
+		if update.CompletedAt.After(now) {
+			return appErrors.NewBadRequestError("completed_at cannot be in the future")
+		}
+	}
+
+	if update.TotalDurationSeconds != nil {
+		completedAt := session.CompletedAt
+		if update.CompletedAt != nil {
+			completedAt = update.CompletedAt
+		}
+		if elapsed := completedAt.Sub(session.StartedAt); time.Duration(*update.TotalDurationSeconds)*time.Second > elapsed {
+			return appErrors.NewBadRequestError("total_duration_seconds cannot exceed the time between start and completion")
+		}
 	}
 
 	return nil
 }
 
+// sessionChanges returns the fields an update actually changes, with old and new values
+func sessionChanges(session *models.PracticeSession, update *models.SessionUpdate) map[string]models.FieldChange {
+	changes := make(map[string]models.FieldChange)
+
+	if update.Notes != nil && (session.Notes == nil || *session.Notes != *update.Notes) {
+		changes["notes"] = models.FieldChange{From: session.Notes, To: *update.Notes}
+	}
+	if update.TotalDurationSeconds != nil && (session.TotalDurationSeconds == nil || *session.TotalDurationSeconds != *update.TotalDurationSeconds) {
+		changes["total_duration_seconds"] = models.FieldChange{From: session.TotalDurationSeconds, To: *update.TotalDurationSeconds}
+	}
+	if update.CompletionRate != nil && (session.CompletionRate == nil || *session.CompletionRate != *update.CompletionRate) {
+		changes["completion_rate"] = models.FieldChange{From: session.CompletionRate, To: *update.CompletionRate}
+	}
+	if update.CompletedAt != nil && (session.CompletedAt == nil || !session.CompletedAt.Equal(*update.CompletedAt)) {
+		changes["completed_at"] = models.FieldChange{From: session.CompletedAt, To: *update.CompletedAt}
+	}
+
+	return changes
+}
+
+// exerciseLogChanges returns the fields an update actually changes, with old and new values
+func exerciseLogChanges(log *models.ExerciseLog, update *models.ExerciseLogUpdate) map[string]models.FieldChange {
+	changes := make(map[string]models.FieldChange)
+
+	if update.ActualDurationSeconds != nil && (log.ActualDurationSeconds == nil || *log.ActualDurationSeconds != *update.ActualDurationSeconds) {
+		changes["actual_duration_seconds"] = models.FieldChange{From: log.ActualDurationSeconds, To: *update.ActualDurationSeconds}
+	}
+	if update.RepetitionsCompleted != nil && (log.RepetitionsCompleted == nil || *log.RepetitionsCompleted != *update.RepetitionsCompleted) {
+		changes["repetitions_completed"] = models.FieldChange{From: log.RepetitionsCompleted, To: *update.RepetitionsCompleted}
+	}
+	if update.Skipped != nil && log.Skipped != *update.Skipped {
+		changes["skipped"] = models.FieldChange{From: log.Skipped, To: *update.Skipped}
+	}
+	if update.Notes != nil && (log.Notes == nil || *log.Notes != *update.Notes) {
+		changes["notes"] = models.FieldChange{From: log.Notes, To: *update.Notes}
+	}
+
+	return changes
+}
+
+// AddBiometrics expands compact wearable samples and stores them for a session owned by the user
+func (s *SessionService) AddBiometrics(ctx context.Context, sessionID, userID uuid.UUID, startTime time.Time, interval time.Duration, heartRates []*int, hrvs []*float64) (*models.PracticeSession, int, error) {
+	samples, err := expandBiometricSamples(startTime, interval, heartRates, hrvs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, 0, appErrors.NewInternalError("Failed to fetch session").WithError(err)
+	}
+	if session == nil {
+		return nil, 0, appErrors.NewNotFoundError("Session")
+	}
+	if session.UserID != userID {
+		return nil, 0, appErrors.NewAuthorizationError("You don't have access to this session")
+	}
+
+	stored, err := s.sessionRepo.AddBiometrics(ctx, sessionID, samples)
+	if err != nil {
+		return nil, 0, appErrors.NewInternalError("Failed to store biometrics").WithError(err)
+	}
+
+	// Re-read the session to return the refreshed summary
+	session, err = s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, 0, appErrors.NewInternalError("Failed to fetch session").WithError(err)
+	}
+
+	return session, stored, nil
+}
+
+// GetBiometrics returns the raw samples for a session (owner or admin)
+func (s *SessionService) GetBiometrics(ctx context.Context, sessionID, userID uuid.UUID, role models.UserRole) ([]models.BiometricSample, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch session").WithError(err)
+	}
+	if session == nil {
+		return nil, appErrors.NewNotFoundError("Session")
+	}
+	if role != models.RoleAdmin && session.UserID != userID {
+		return nil, appErrors.NewAuthorizationError("You don't have access to this session")
+	}
+
+	samples, err := s.sessionRepo.GetBiometrics(ctx, sessionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch biometrics").WithError(err)
+	}
+	return samples, nil
+}
+
+// expandBiometricSamples turns parallel, evenly spaced series into timestamped samples.
+// Entries that are null in every series are dropped.
+func expandBiometricSamples(startTime time.Time, interval time.Duration, heartRates []*int, hrvs []*float64) ([]models.BiometricSample, error) {
+	if len(heartRates) == 0 && len(hrvs) == 0 {
+		return nil, appErrors.NewBadRequestError("At least one heart_rate or hrv_ms sample is required")
+	}
+	if len(heartRates) > 0 && len(hrvs) > 0 && len(heartRates) != len(hrvs) {
+		return nil, appErrors.NewBadRequestError("heart_rate and hrv_ms must have the same length")
+	}
+
+	count := len(heartRates)
+	if len(hrvs) > count {
+		count = len(hrvs)
+	}
+
+	samples := make([]models.BiometricSample, 0, count)
+	for i := 0; i < count; i++ {
+		sample := models.BiometricSample{
+			RecordedAt: startTime.Add(time.Duration(i) * interval),
+		}
+		if i < len(heartRates) && heartRates[i] != nil {
+			if *heartRates[i] < 20 || *heartRates[i] > 250 {
+				return nil, appErrors.NewBadRequestError("Heart rate must be between 20 and 250 bpm").WithDetails("index", i)
+			}
+			sample.HeartRate = heartRates[i]
+		}
+		if i < len(hrvs) && hrvs[i] != nil {
+			if *hrvs[i] < 0 {
+				return nil, appErrors.NewBadRequestError("HRV must not be negative").WithDetails("index", i)
+			}
+			sample.HRVMs = hrvs[i]
+		}
+		if sample.HeartRate == nil && sample.HRVMs == nil {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+
+	if len(samples) == 0 {
+		return nil, appErrors.NewBadRequestError("All samples are empty")
+	}
+
+	return samples, nil
+}
+
 func (s *SessionService) GetStats(ctx context.Context, userID uuid.UUID) (*models.SessionStats, error) {
 	stats, err := s.sessionRepo.GetStats(ctx, userID)
 	if err != nil {
 		return nil, appErrors.NewInternalError("Failed to fetch session stats").WithError(err)
 	}
+	stats.CurrentStreak, stats.LongestStreak, err = s.streakService.Streaks(ctx, userID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
 	return stats, nil
 }
 
-func (s *SessionService) DeleteSession(ctx context.Context, sessionID, userID uuid.UUID) error {
+// progressWindow is how far back the pace projecting a program's completion looks
+const progressWindow = 28 * 24 * time.Hour
+
+// GetProgramProgress returns the user's completed repetitions of one of their programs against
+// the planned ones, the completion date projected from their recent pace, and how often they did
+// each exercise
+func (s *SessionService) GetProgramProgress(ctx context.Context, userID, programID uuid.UUID) (*models.RepetitionProgress, error) {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return nil, appErrors.NewNotFoundError("Program")
+	}
+	if program.OwnedBy == nil || *program.OwnedBy != userID {
+		userProgram, err := s.programRepo.GetUserProgram(ctx, userID, programID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch program assignment").WithError(err)
+		}
+		if userProgram == nil {
+			return nil, appErrors.NewNotFoundError("Program")
+		}
+	}
+
+	now := s.clock.Now()
+	completed, recent, lastCompletedAt, err := s.sessionRepo.GetProgramTotals(ctx, userID, programID, now.Add(-progressWindow))
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program progress").WithError(err)
+	}
+	exercises, err := s.sessionRepo.GetExerciseProgress(ctx, userID, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch exercise progress").WithError(err)
+	}
+	for i := range exercises {
+		if completed > 0 {
+			exercises[i].CompletionRate = float64(exercises[i].TimesCompleted) * 100 / float64(completed)
+		}
+	}
+
+	perDay := float64(recent) / (progressWindow.Hours() / 24)
+	progress := &models.RepetitionProgress{
+		ProgramID:            programID,
+		RepetitionsPlanned:   program.RepetitionsPlanned,
+		RepetitionsCompleted: completed,
+		SessionsPerWeek:      perDay * 7,
+		LastPracticedAt:      lastCompletedAt,
+		Exercises:            exercises,
+	}
+	if planned := program.RepetitionsPlanned; planned != nil && *planned > 0 {
+		percent := min(float64(completed)*100/float64(*planned), 100)
+		progress.PercentComplete = &percent
+
+		if remaining := *planned - completed; remaining > 0 && recent > 0 {
+			projected := now.UTC().AddDate(0, 0, int(math.Ceil(float64(remaining)/perDay))).Format("2006-01-02")
+			progress.ProjectedCompletionDate = &projected
+		}
+	}
+	return progress, nil
+}
+
+// GetProgramAdoption shows the program's owner, or an admin, how all students use it: its
+// assignments, sessions and completion rates, and where in the program sessions stop
+func (s *SessionService) GetProgramAdoption(ctx context.Context, userID uuid.UUID, userRole models.UserRole, programID uuid.UUID) (*models.ProgramAdoption, error) {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return nil, appErrors.NewNotFoundError("Program")
+	}
+	isOwner := program.OwnedBy != nil && *program.OwnedBy == userID
+	if userRole != models.RoleAdmin && !isOwner {
+		return nil, appErrors.NewAuthorizationError("You don't have permission to view this program's statistics")
+	}
+
+	adoption, err := s.sessionRepo.GetProgramAdoption(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch program adoption").WithError(err)
+	}
+	exercises, err := s.sessionRepo.GetExerciseAdoption(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch exercise adoption").WithError(err)
+	}
+
+	// Logs needn't follow the program order, e.g. after a skip ahead, so a later exercise can be
+	// reached more often than the one before; that is no drop-off
+	previous := adoption.SessionsStarted
+	for i := range exercises {
+		if previous > 0 && exercises[i].TimesReached < previous {
+			exercises[i].DropOffRate = float64(previous-exercises[i].TimesReached) * 100 / float64(previous)
+		}
+		previous = exercises[i].TimesReached
+	}
+	adoption.Exercises = exercises
+	return adoption, nil
+}
+
+// DeleteSession soft-deletes a session. It disappears from lists and stats immediately,
+// can be restored within the restore window, and is purged after the retention period.
+func (s *SessionService) DeleteSession(ctx context.Context, sessionID, userID uuid.UUID) (*time.Time, error) {
 	// Verify session exists and belongs to user
 	session, err := s.sessionRepo.GetByID(ctx, sessionID)
 	if err != nil {
-		return appErrors.NewInternalError("Failed to fetch session").WithError(err)
+		return nil, appErrors.NewInternalError("Failed to fetch session").WithError(err)
 	}
 	if session == nil {
-		return appErrors.NewNotFoundError("Session")
+		return nil, appErrors.NewNotFoundError("Session")
 	}
 	if session.UserID != userID {
-		return appErrors.NewAuthorizationError("You don't have access to this session")
+		return nil, appErrors.NewAuthorizationError("You don't have access to this session")
+	}
+
+	if err := s.sessionRepo.SoftDelete(ctx, sessionID); err != nil {
+		return nil, appErrors.NewInternalError("Failed to delete session").WithError(err)
 	}
 
-	// Store programID before deleting the session
-	programID := session.ProgramID
+	s.refreshRollups(ctx, session)
+
+	restoreUntil := s.clock.Now().Add(s.cfg.GetRestoreWindow())
+	return &restoreUntil, nil
+}
+
+// RestoreSession undoes a session deletion. Students can restore their own sessions
+// within the restore window, admins can restore any session until it is purged.
+func (s *SessionService) RestoreSession(ctx context.Context, sessionID, userID uuid.UUID, role models.UserRole) (*models.PracticeSession, error) {
+	session, err := s.sessionRepo.GetDeletedByID(ctx, sessionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch session").WithError(err)
+	}
+	if session == nil {
+		return nil, appErrors.NewNotFoundError("Deleted session")
+	}
 
-	if err := s.sessionRepo.Delete(ctx, sessionID); err != nil {
-		return appErrors.NewInternalError("Failed to delete session").WithError(err)
+	if role != models.RoleAdmin {
+		if session.UserID != userID {
+			return nil, appErrors.NewAuthorizationError("You don't have access to this session")
+		}
+		if time.Since(*session.DeletedAt) > s.cfg.GetRestoreWindow() {
+			return nil, appErrors.NewBadRequestError("The restore window for this session has expired")
+		}
 	}
 
-	// Update program repetitions_completed count
-	if err := s.programRepo.UpdateRepetitionsCompleted(ctx, programID); err != nil {
-		// Log error but don't fail the request
-		// The session deletion is more important than the count update
+	if err := s.sessionRepo.Restore(ctx, sessionID); err != nil {
+		return nil, appErrors.NewInternalError("Failed to restore session").WithError(err)
 	}
 
-	return nil
+	s.refreshRollups(ctx, session)
+
+	session.DeletedAt = nil
+	return session, nil
+}
+
+// PurgeDeletedSessions permanently removes sessions deleted longer ago than the retention period
+func (s *SessionService) PurgeDeletedSessions(ctx context.Context) (int64, error) {
+	cutoff := s.clock.Now().Add(-s.cfg.GetPurgeAfter())
+
+	purged, err := s.sessionRepo.PurgeDeleted(ctx, cutoff)
+	if err != nil {
+		return 0, appErrors.NewInternalError("Failed to purge deleted sessions").WithError(err)
+	}
+
+	return purged, nil
+}
+
+// refreshRollups refreshes the program's completed count and drops the user's streak snapshot.
+// Errors are logged but not returned; the session change itself is more important. A failed
+// count update is queued for RetryRecounts, and the nightly reconciliation catches the rest.
+func (s *SessionService) refreshRollups(ctx context.Context, session *models.PracticeSession) {
+	if err := s.programRepo.UpdateRepetitionsCompleted(ctx, session.ProgramID); err != nil {
+		log.Printf("[WARN] Failed to update repetitions for program %s, retrying later: %v", session.ProgramID, err)
+		s.queueRecount(session.ProgramID)
+	}
+	s.streakService.Invalidate(ctx, session.UserID)
+}
+
+func (s *SessionService) queueRecount(programID uuid.UUID) {
+	s.recountMu.Lock()
+	defer s.recountMu.Unlock()
+	s.recounts[programID] = true
+}
+
+// RetryRecounts updates the completed counts that failed to update after a session change.
+// Programs that fail again stay queued. Returns the number of programs updated.
+func (s *SessionService) RetryRecounts(ctx context.Context) (int, error) {
+	s.recountMu.Lock()
+	programIDs := make([]uuid.UUID, 0, len(s.recounts))
+	for programID := range s.recounts {
+		programIDs = append(programIDs, programID)
+	}
+	clear(s.recounts)
+	s.recountMu.Unlock()
+
+	updated := 0
+	var firstErr error
+	for _, programID := range programIDs {
+		if err := s.programRepo.UpdateRepetitionsCompleted(ctx, programID); err != nil {
+			s.queueRecount(programID)
+			if firstErr == nil {
+				firstErr = appErrors.NewInternalError("Failed to update program repetitions").WithError(err)
+			}
+			continue
+		}
+		updated++
+	}
+	return updated, firstErr
+}
+
+// ReconcileRepetitions recomputes every program's completed count from its sessions, correcting
+// counts that drifted, and records a report of the corrections
+func (s *SessionService) ReconcileRepetitions(ctx context.Context) (*models.RepetitionReconciliation, error) {
+	checked, drifted, err := s.programRepo.ReconcileRepetitionsCompleted(ctx)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to reconcile program repetitions").WithError(err)
+	}
+
+	report := &models.RepetitionReconciliation{ProgramsChecked: checked, Drifted: drifted}
+	if err := s.reconciliationRepo.Create(ctx, report); err != nil {
+		return nil, appErrors.NewInternalError("Failed to save reconciliation report").WithError(err)
+	}
+	for _, d := range drifted {
+		stored := "none"
+		if d.Stored != nil {
+			stored = strconv.Itoa(*d.Stored)
+		}
+		log.Printf("[WARN] Program %s repetitions_completed drifted: stored %s, actual %d", d.ProgramID, stored, d.Actual)
+	}
+	return report, nil
+}
+
+// ReconcileIfDue runs the nightly reconciliation once SESSION_RECONCILE_HOUR (UTC) has passed
+// today, unless it already ran since. Returns nil if it wasn't due.
+func (s *SessionService) ReconcileIfDue(ctx context.Context) (*models.RepetitionReconciliation, error) {
+	now := s.clock.Now().UTC()
+	due := time.Date(now.Year(), now.Month(), now.Day(), s.cfg.ReconcileHour, 0, 0, 0, time.UTC)
+	if now.Before(due) {
+		return nil, nil
+	}
+
+	lastRunAt, err := s.reconciliationRepo.LastRunAt(ctx)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch last reconciliation").WithError(err)
+	}
+	if lastRunAt != nil && !lastRunAt.Before(due) {
+		return nil, nil
+	}
+	return s.ReconcileRepetitions(ctx)
+}
+
+// ListReconciliations returns reconciliation reports, newest first
+func (s *SessionService) ListReconciliations(ctx context.Context, limit, offset int) ([]models.RepetitionReconciliation, error) {
+	reports, err := s.reconciliationRepo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch reconciliation reports").WithError(err)
+	}
+	return reports, nil
+}
+
+// ListAllSessions lists the sessions of all users for the admin activity feed
+func (s *SessionService) ListAllSessions(ctx context.Context, filter models.SessionFilter, limit, offset int) ([]models.PracticeSession, error) {
+	sessions, err := s.sessionRepo.ListAll(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to list sessions").WithError(err)
+	}
+	return sessions, nil
 }
 
 // GetUserSessions retrieves sessions for a specific user with role-based authorization
@@ -202,17 +842,96 @@ func (s *SessionService) GetUserSessions(ctx context.Context, requestingUserID u
 	}
 
 	// Convert to SessionWithLogs by fetching exercise logs for each session
-	sessionsWithLogs := make([]models.SessionWithLogs, 0, len(sessions))
-	for _, session := range sessions {
-		logs, err := s.sessionRepo.GetExerciseLogs(ctx, session.ID)
+	sessionsWithLogs := make([]models.SessionWithLogs, len(sessions))
+	err = hydrate.Each(ctx, len(sessions), func(ctx context.Context, i int) error {
+		logs, err := s.sessionRepo.GetExerciseLogs(ctx, sessions[i].ID)
+		if err != nil {
+			return appErrors.NewInternalError("Failed to fetch exercise logs").WithError(err)
+		}
+		// Students only see notes their instructor chose to share
+		notes, err := s.sessionRepo.ListNotes(ctx, sessions[i].ID, !isAdmin)
 		if err != nil {
-			return nil, appErrors.NewInternalError("Failed to fetch exercise logs").WithError(err)
+			return appErrors.NewInternalError("Failed to fetch session notes").WithError(err)
 		}
-		sessionsWithLogs = append(sessionsWithLogs, models.SessionWithLogs{
-			Session:      session,
+		sessionsWithLogs[i] = models.SessionWithLogs{
+			Session:      sessions[i],
 			ExerciseLogs: logs,
-		})
+			Notes:        notes,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return sessionsWithLogs, nil
 }
+
+// AddNote attaches an instructor note to a session (admin only).
+// The session owner is notified when the note is shared.
+func (s *SessionService) AddNote(ctx context.Context, sessionID, authorID uuid.UUID, authorRole models.UserRole, content string, visibility models.NoteVisibility) (*models.SessionNote, error) {
+	if authorRole != models.RoleAdmin {
+		return nil, appErrors.NewAuthorizationError("Only instructors can add session notes")
+	}
+	if content == "" {
+		return nil, appErrors.NewBadRequestError("Note content cannot be empty")
+	}
+	if visibility == "" {
+		visibility = models.NoteVisibilityPrivate
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch session").WithError(err)
+	}
+	if session == nil {
+		return nil, appErrors.NewNotFoundError("Session")
+	}
+
+	note := &models.SessionNote{
+		SessionID:  sessionID,
+		AuthorID:   authorID,
+		Content:    content,
+		Visibility: visibility,
+	}
+	if err := s.sessionRepo.CreateNote(ctx, note); err != nil {
+		return nil, appErrors.NewInternalError("Failed to add session note").WithError(err)
+	}
+
+	if visibility == models.NoteVisibilityShared && session.UserID != authorID {
+		payload := map[string]interface{}{
+			"session_id": sessionID.String(),
+			"note_id":    note.ID.String(),
+		}
+		// The note is already stored, a failed notification should not fail the request
+		if _, err := s.notificationService.Notify(ctx, session.UserID, models.NotificationSessionNote, "Your instructor commented on a session", &content, payload); err != nil {
+			log.Printf("[WARN] Failed to notify user %s about session note %s: %v", session.UserID, note.ID, err)
+		}
+	}
+
+	return note, nil
+}
+
+// ListNotes returns the notes on a session visible to the requesting user.
+// Admins see all notes, the session owner only sees shared notes.
+func (s *SessionService) ListNotes(ctx context.Context, sessionID, userID uuid.UUID, role models.UserRole) ([]models.SessionNote, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch session").WithError(err)
+	}
+	if session == nil {
+		return nil, appErrors.NewNotFoundError("Session")
+	}
+
+	isAdmin := role == models.RoleAdmin
+	if !isAdmin && session.UserID != userID {
+		return nil, appErrors.NewAuthorizationError("You don't have access to this session")
+	}
+
+	notes, err := s.sessionRepo.ListNotes(ctx, sessionID, !isAdmin)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch session notes").WithError(err)
+	}
+
+	return notes, nil
+}
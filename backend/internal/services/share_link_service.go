@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/internal/timeline"
+	"github.com/xuangong/backend/pkg/auth"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// ShareLinkService manages public links to read-only views of programs, so instructors can
+// show routines to prospective students without an account
+type ShareLinkService struct {
+	shareRepo          *repositories.ShareLinkRepository
+	programService     *ProgramService
+	translationService *TranslationService
+	cfg                *config.SharesConfig
+	clock              clock.Clock
+}
+
+func NewShareLinkService(shareRepo *repositories.ShareLinkRepository, programService *ProgramService, translationService *TranslationService, cfg *config.SharesConfig) *ShareLinkService {
+	return &ShareLinkService{
+		shareRepo:          shareRepo,
+		programService:     programService,
+		translationService: translationService,
+		cfg:                cfg,
+		clock:              clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *ShareLinkService) WithClock(c clock.Clock) *ShareLinkService {
+	s.clock = c
+	return s
+}
+
+// Create issues a share link for the program. The plain token and URL are only returned here.
+// expiresInDays of 0 uses the configured default.
+func (s *ShareLinkService) Create(ctx context.Context, programID, userID uuid.UUID, role models.UserRole, expiresInDays int) (*models.ProgramShareLink, error) {
+	if err := s.authorize(ctx, programID, userID, role); err != nil {
+		return nil, err
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to generate share token").WithError(err)
+	}
+
+	if expiresInDays == 0 {
+		expiresInDays = s.cfg.DefaultDays
+	}
+
+	link := &models.ProgramShareLink{
+		ProgramID: programID,
+		TokenHash: auth.HashOpaqueToken(token),
+		CreatedBy: &userID,
+		ExpiresAt: s.clock.Now().Add(time.Duration(expiresInDays) * 24 * time.Hour),
+	}
+	if err := s.shareRepo.Create(ctx, link); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create share link").WithError(err)
+	}
+
+	link.Token = token
+	link.URL = s.shareURL(token)
+	return link, nil
+}
+
+func (s *ShareLinkService) List(ctx context.Context, programID, userID uuid.UUID, role models.UserRole) ([]models.ProgramShareLink, error) {
+	if err := s.authorize(ctx, programID, userID, role); err != nil {
+		return nil, err
+	}
+
+	links, err := s.shareRepo.ListByProgram(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch share links").WithError(err)
+	}
+	return links, nil
+}
+
+func (s *ShareLinkService) Revoke(ctx context.Context, programID, linkID, userID uuid.UUID, role models.UserRole) error {
+	if err := s.authorize(ctx, programID, userID, role); err != nil {
+		return err
+	}
+
+	link, err := s.shareRepo.GetByID(ctx, linkID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch share link").WithError(err)
+	}
+	if link == nil || link.ProgramID != programID {
+		return appErrors.NewNotFoundError("Share link")
+	}
+
+	revoked, err := s.shareRepo.Revoke(ctx, linkID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to revoke share link").WithError(err)
+	}
+	if !revoked {
+		return appErrors.NewBadRequestError("Share link is already revoked")
+	}
+	return nil
+}
+
+// View returns the public view of the program behind an active share link in the locale,
+// counting the view
+func (s *ShareLinkService) View(ctx context.Context, token, locale string) (*models.SharedProgram, error) {
+	link, err := s.shareRepo.RecordView(ctx, auth.HashOpaqueToken(token))
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch share link").WithError(err)
+	}
+	if link == nil {
+		return nil, appErrors.NewNotFoundError("Share link")
+	}
+
+	program, err := s.programService.GetByID(ctx, link.ProgramID, true)
+	if err != nil {
+		return nil, err
+	}
+	if program.Program.HiddenAt != nil {
+		return nil, appErrors.NewNotFoundError("Share link")
+	}
+	localized := []models.ProgramWithExercises{*program}
+	if err := s.translationService.Localize(ctx, localized, locale); err != nil {
+		return nil, err
+	}
+	p := localized[0]
+
+	return &models.SharedProgram{
+		Name:                 p.Program.Name,
+		Description:          p.Program.Description,
+		Tags:                 p.Program.Tags,
+		CoverURL:             p.Program.CoverURL,
+		CoverThumbnails:      p.Program.CoverThumbnails,
+		TotalDurationSeconds: timeline.Build(link.ProgramID, p.Exercises, timeline.DefaultOptions()).TotalDurationSeconds,
+		Exercises:            p.Exercises,
+		ExpiresAt:            link.ExpiresAt,
+	}, nil
+}
+
+// authorize lets admins and the program's owner manage its share links
+func (s *ShareLinkService) authorize(ctx context.Context, programID, userID uuid.UUID, role models.UserRole) error {
+	program, err := s.programService.GetByID(ctx, programID, false)
+	if err != nil {
+		return err
+	}
+	isOwner := program.Program.OwnedBy != nil && *program.Program.OwnedBy == userID
+	if role != models.RoleAdmin && !isOwner {
+		return appErrors.NewAuthorizationError("You don't have permission to share this program")
+	}
+	return nil
+}
+
+func (s *ShareLinkService) shareURL(token string) string {
+	return tokenURL(s.cfg.URL, token)
+}
+
+// tokenURL appends the token to a frontend page URL as ?token=, or returns "" if none is configured
+func tokenURL(base, token string) string {
+	if base == "" {
+		return ""
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/placeholder"
+)
+
+type SnippetService struct {
+	snippetRepo *repositories.SnippetRepository
+	userRepo    *repositories.UserRepository
+	programRepo *repositories.ProgramRepository
+}
+
+func NewSnippetService(snippetRepo *repositories.SnippetRepository, userRepo *repositories.UserRepository, programRepo *repositories.ProgramRepository) *SnippetService {
+	return &SnippetService{
+		snippetRepo: snippetRepo,
+		userRepo:    userRepo,
+		programRepo: programRepo,
+	}
+}
+
+func (s *SnippetService) Create(ctx context.Context, ownerID uuid.UUID, title, body string) (*models.FeedbackSnippet, error) {
+	if err := validateSnippetBody(body); err != nil {
+		return nil, err
+	}
+	if err := s.checkTitle(ctx, ownerID, title, nil); err != nil {
+		return nil, err
+	}
+
+	snippet := &models.FeedbackSnippet{
+		OwnerID: ownerID,
+		Title:   title,
+		Body:    body,
+	}
+	if err := s.snippetRepo.Create(ctx, snippet); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create snippet").WithError(err)
+	}
+
+	return snippet, nil
+}
+
+// Get returns one of the owner's snippets
+func (s *SnippetService) Get(ctx context.Context, id, ownerID uuid.UUID) (*models.FeedbackSnippet, error) {
+	snippet, err := s.snippetRepo.GetByID(ctx, id, ownerID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch snippet").WithError(err)
+	}
+	if snippet == nil {
+		return nil, appErrors.NewNotFoundError("Snippet")
+	}
+	return snippet, nil
+}
+
+func (s *SnippetService) List(ctx context.Context, ownerID uuid.UUID) ([]models.FeedbackSnippet, error) {
+	snippets, err := s.snippetRepo.ListByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch snippets").WithError(err)
+	}
+	return snippets, nil
+}
+
+func (s *SnippetService) Update(ctx context.Context, id, ownerID uuid.UUID, title, body *string) (*models.FeedbackSnippet, error) {
+	snippet, err := s.Get(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if title != nil && *title != snippet.Title {
+		if err := s.checkTitle(ctx, ownerID, *title, &id); err != nil {
+			return nil, err
+		}
+		snippet.Title = *title
+	}
+	if body != nil {
+		if err := validateSnippetBody(*body); err != nil {
+			return nil, err
+		}
+		snippet.Body = *body
+	}
+
+	if err := s.snippetRepo.Update(ctx, snippet); err != nil {
+		return nil, appErrors.NewInternalError("Failed to update snippet").WithError(err)
+	}
+
+	return snippet, nil
+}
+
+func (s *SnippetService) Delete(ctx context.Context, id, ownerID uuid.UUID) error {
+	deleted, err := s.snippetRepo.Delete(ctx, id, ownerID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete snippet").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Snippet")
+	}
+	return nil
+}
+
+// Expand fills in one of the author's snippets for a message in the given submission
+func (s *SnippetService) Expand(ctx context.Context, id, authorID uuid.UUID, submission *models.Submission) (string, error) {
+	snippet, err := s.Get(ctx, id, authorID)
+	if err != nil {
+		return "", err
+	}
+
+	student, err := s.userRepo.GetByID(ctx, submission.UserID)
+	if err != nil {
+		return "", appErrors.NewInternalError("Failed to fetch student").WithError(err)
+	}
+	instructor, err := s.userRepo.GetByID(ctx, authorID)
+	if err != nil {
+		return "", appErrors.NewInternalError("Failed to fetch instructor").WithError(err)
+	}
+	program, err := s.programRepo.GetByIDIncludingDeleted(ctx, submission.ProgramID)
+	if err != nil {
+		return "", appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+
+	values := map[string]string{
+		models.SnippetSubmissionTitle: submission.Title,
+	}
+	if student != nil {
+		values[models.SnippetStudentName] = student.FullName
+		values[models.SnippetStudentFirstName] = firstName(student.FullName)
+	}
+	if instructor != nil {
+		values[models.SnippetInstructorName] = instructor.FullName
+	}
+	if program != nil {
+		values[models.SnippetProgramName] = program.Name
+	}
+
+	return placeholder.Expand(snippet.Body, values), nil
+}
+
+func (s *SnippetService) checkTitle(ctx context.Context, ownerID uuid.UUID, title string, excludeID *uuid.UUID) error {
+	exists, err := s.snippetRepo.TitleExists(ctx, ownerID, title, excludeID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to check snippet title").WithError(err)
+	}
+	if exists {
+		return appErrors.NewConflictError("You already have a snippet with this title")
+	}
+	return nil
+}
+
+func validateSnippetBody(body string) error {
+	if unknown := placeholder.Unknown(body, models.SnippetPlaceholders); len(unknown) > 0 {
+		return appErrors.NewBadRequestError(fmt.Sprintf("Unknown placeholder(s) %s; available: %s",
+			strings.Join(unknown, ", "), strings.Join(models.SnippetPlaceholders, ", ")))
+	}
+	return nil
+}
+
+// firstName returns the first word of a full name
+func firstName(fullName string) string {
+	if fields := strings.Fields(fullName); len(fields) > 0 {
+		return fields[0]
+	}
+	return fullName
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+const (
+	// Users recomputed between progress saves
+	statsRecomputeBatch = 100
+	// How long a claimed recompute stays locked without progress before another process resumes it
+	statsRecomputeLease = 5 * time.Minute
+)
+
+// StatsRecomputeService recomputes stored stats (streak snapshots and program completion counts)
+// after streak rules change, as resumable background jobs
+type StatsRecomputeService struct {
+	recomputeRepo *repositories.StatsRecomputeRepository
+	userRepo      *repositories.UserRepository
+	sessionRepo   *repositories.SessionRepository
+	programRepo   *repositories.ProgramRepository
+	streakService *StreakService
+	clock         clock.Clock
+}
+
+func NewStatsRecomputeService(recomputeRepo *repositories.StatsRecomputeRepository, userRepo *repositories.UserRepository, sessionRepo *repositories.SessionRepository, programRepo *repositories.ProgramRepository, streakService *StreakService) *StatsRecomputeService {
+	return &StatsRecomputeService{
+		recomputeRepo: recomputeRepo,
+		userRepo:      userRepo,
+		sessionRepo:   sessionRepo,
+		programRepo:   programRepo,
+		streakService: streakService,
+		clock:         clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *StatsRecomputeService) WithClock(c clock.Clock) *StatsRecomputeService {
+	s.clock = c
+	return s
+}
+
+// Start queues a recompute for one user, or everyone if userID is nil
+func (s *StatsRecomputeService) Start(ctx context.Context, userID, requestedBy *uuid.UUID) (*models.StatsRecompute, error) {
+	if userID != nil {
+		user, err := s.userRepo.GetByID(ctx, *userID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to fetch user").WithError(err)
+		}
+		if user == nil {
+			return nil, appErrors.NewNotFoundError("User")
+		}
+	}
+
+	job := &models.StatsRecompute{UserID: userID, RequestedBy: requestedBy}
+	if err := s.recomputeRepo.Create(ctx, job); err != nil {
+		return nil, appErrors.NewInternalError("Failed to queue stats recompute").WithError(err)
+	}
+	return job, nil
+}
+
+// Get returns a recompute with its progress
+func (s *StatsRecomputeService) Get(ctx context.Context, id uuid.UUID) (*models.StatsRecompute, error) {
+	job, err := s.recomputeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch stats recompute").WithError(err)
+	}
+	if job == nil {
+		return nil, appErrors.NewNotFoundError("Stats recompute")
+	}
+	return job, nil
+}
+
+// List returns recomputes, newest first
+func (s *StatsRecomputeService) List(ctx context.Context, limit, offset int) ([]models.StatsRecompute, error) {
+	jobs, err := s.recomputeRepo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch stats recomputes").WithError(err)
+	}
+	return jobs, nil
+}
+
+// RunPending claims and runs unfinished recomputes until none are left, resuming interrupted ones
+// from where they stopped. Returns the number of recomputes finished.
+func (s *StatsRecomputeService) RunPending(ctx context.Context) (int, error) {
+	finished := 0
+	for ctx.Err() == nil {
+		job, err := s.recomputeRepo.Claim(ctx, nil, statsRecomputeLease)
+		if err != nil {
+			return finished, appErrors.NewInternalError("Failed to claim stats recompute").WithError(err)
+		}
+		if job == nil {
+			break
+		}
+		if err := s.run(ctx, job, nil); err != nil {
+			return finished, err
+		}
+		finished++
+	}
+	return finished, nil
+}
+
+// Run claims one recompute and runs it to the end, reporting progress after each batch
+func (s *StatsRecomputeService) Run(ctx context.Context, id uuid.UUID, progress func(*models.StatsRecompute)) error {
+	job, err := s.recomputeRepo.Claim(ctx, &id, statsRecomputeLease)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to claim stats recompute").WithError(err)
+	}
+	if job == nil {
+		return appErrors.NewConflictError("Stats recompute is finished or running elsewhere")
+	}
+	return s.run(ctx, job, progress)
+}
+
+// run processes a claimed recompute batch by batch. When ctx is cancelled the recompute is
+// released unfinished, to be resumed from its cursor.
+func (s *StatsRecomputeService) run(ctx context.Context, job *models.StatsRecompute, progress func(*models.StatsRecompute)) error {
+	for {
+		userIDs, err := s.recomputeRepo.ListUserIDs(ctx, job, statsRecomputeBatch)
+		if err != nil {
+			return s.stop(ctx, job, err)
+		}
+		if len(userIDs) == 0 {
+			break
+		}
+
+		for _, userID := range userIDs {
+			if err := s.recomputeUser(ctx, userID); err != nil {
+				return s.stop(ctx, job, err)
+			}
+			job.Cursor = &userID
+			job.Processed++
+		}
+		if err := s.recomputeRepo.SaveProgress(ctx, job, statsRecomputeLease); err != nil {
+			return s.stop(ctx, job, err)
+		}
+		if progress != nil {
+			progress(job)
+		}
+	}
+
+	if err := s.recomputeRepo.Finish(ctx, job.ID, models.StatsRecomputeCompleted, nil); err != nil {
+		return appErrors.NewInternalError("Failed to finish stats recompute").WithError(err)
+	}
+	job.Status = models.StatsRecomputeCompleted
+	log.Printf("[INFO] Stats recompute %s completed for %d users", job.ID, job.Processed)
+	return nil
+}
+
+// stop ends a run that hit an error: the recompute fails, unless ctx was cancelled, in which
+// case it is released to resume later
+func (s *StatsRecomputeService) stop(ctx context.Context, job *models.StatsRecompute, err error) error {
+	if ctx.Err() != nil {
+		if releaseErr := s.recomputeRepo.Release(context.WithoutCancel(ctx), job.ID); releaseErr != nil {
+			log.Printf("[WARN] Failed to release stats recompute %s: %v", job.ID, releaseErr)
+		}
+		return ctx.Err()
+	}
+
+	msg := err.Error()
+	if finishErr := s.recomputeRepo.Finish(ctx, job.ID, models.StatsRecomputeFailed, &msg); finishErr != nil {
+		log.Printf("[WARN] Failed to mark stats recompute %s failed: %v", job.ID, finishErr)
+	}
+	job.Status = models.StatsRecomputeFailed
+	job.Error = &msg
+	return appErrors.NewInternalError("Stats recompute failed").WithError(err)
+}
+
+// recomputeUser refreshes the user's streak snapshot and the completion counts of the programs
+// they practiced
+func (s *StatsRecomputeService) recomputeUser(ctx context.Context, userID uuid.UUID) error {
+	if err := s.streakService.Refresh(ctx, userID, s.clock.Now()); err != nil {
+		return err
+	}
+	programIDs, err := s.sessionRepo.ListProgramIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, programID := range programIDs {
+		if err := s.programRepo.UpdateRepetitionsCompleted(ctx, programID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
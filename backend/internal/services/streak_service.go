@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/internal/streaks"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// StreakService computes practice streaks under the deployment's streak policy and the
+// overrides admins set for individual users. Computed streaks are stored as a snapshot for the
+// day, dropped whenever the user's sessions or overrides change.
+type StreakService struct {
+	streakRepo  *repositories.StreakRepository
+	sessionRepo *repositories.SessionRepository
+	userRepo    *repositories.UserRepository
+	cfg         *config.StreaksConfig
+}
+
+func NewStreakService(streakRepo *repositories.StreakRepository, sessionRepo *repositories.SessionRepository, userRepo *repositories.UserRepository, cfg *config.StreaksConfig) *StreakService {
+	return &StreakService{
+		streakRepo:  streakRepo,
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		cfg:         cfg,
+	}
+}
+
+// GetPolicy returns the user's overrides and the streak policy that applies to them
+func (s *StreakService) GetPolicy(ctx context.Context, userID uuid.UUID) (*models.UserStreakPolicy, error) {
+	rules, err := s.streakRepo.GetRules(ctx, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch streak rules").WithError(err)
+	}
+	return &models.UserStreakPolicy{
+		UserID:    userID,
+		Overrides: rules,
+		Policy:    s.resolve(rules),
+	}, nil
+}
+
+// SetPolicy replaces the user's overrides. Overrides left nil fall back to the deployment's policy.
+func (s *StreakService) SetPolicy(ctx context.Context, userID uuid.UUID, rules models.StreakRules) (*models.UserStreakPolicy, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch user").WithError(err)
+	}
+	if user == nil {
+		return nil, appErrors.NewNotFoundError("User")
+	}
+
+	if err := s.streakRepo.SaveRules(ctx, userID, rules); err != nil {
+		return nil, appErrors.NewInternalError("Failed to save streak rules").WithError(err)
+	}
+	s.Invalidate(ctx, userID)
+	return &models.UserStreakPolicy{
+		UserID:    userID,
+		Overrides: rules,
+		Policy:    s.resolve(rules),
+	}, nil
+}
+
+// Streaks returns the user's current and longest streak as of now under their policy
+func (s *StreakService) Streaks(ctx context.Context, userID uuid.UUID, now time.Time) (current, longest int, err error) {
+	snapshot, err := s.streakRepo.GetSnapshot(ctx, userID)
+	if err != nil {
+		return 0, 0, appErrors.NewInternalError("Failed to fetch streaks").WithError(err)
+	}
+	policy, err := s.GetPolicy(ctx, userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	today := streakDay(now, policy.Policy)
+	if snapshot != nil && snapshot.AsOf.Equal(today) {
+		return snapshot.CurrentStreak, snapshot.LongestStreak, nil
+	}
+
+	computed, err := s.compute(ctx, userID, today, policy.Policy)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := s.streakRepo.SaveSnapshot(ctx, userID, *computed); err != nil {
+		log.Printf("[WARN] Failed to store streaks for user %s: %v", userID, err)
+	}
+	return computed.CurrentStreak, computed.LongestStreak, nil
+}
+
+// Refresh recomputes and stores the user's streaks as of now, replacing the day's snapshot
+func (s *StreakService) Refresh(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	policy, err := s.GetPolicy(ctx, userID)
+	if err != nil {
+		return err
+	}
+	computed, err := s.compute(ctx, userID, streakDay(now, policy.Policy), policy.Policy)
+	if err != nil {
+		return err
+	}
+	if err := s.streakRepo.SaveSnapshot(ctx, userID, *computed); err != nil {
+		return appErrors.NewInternalError("Failed to store streaks").WithError(err)
+	}
+	return nil
+}
+
+// Invalidate drops the user's stored streaks so the next read computes them again.
+// Errors are logged but not returned; a stale snapshot only lasts until the day ends or the
+// stats are recomputed.
+func (s *StreakService) Invalidate(ctx context.Context, userID uuid.UUID) {
+	if err := s.streakRepo.DeleteSnapshot(ctx, userID); err != nil {
+		log.Printf("[WARN] Failed to invalidate streaks for user %s: %v", userID, err)
+	}
+}
+
+func (s *StreakService) compute(ctx context.Context, userID uuid.UUID, today time.Time, policy models.StreakPolicy) (*models.StreakSnapshot, error) {
+	days, err := s.sessionRepo.GetPracticeDays(ctx, userID, policy.GraceHours)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch practice days").WithError(err)
+	}
+	current, longest := streaks.Compute(days, today, policy)
+	return &models.StreakSnapshot{CurrentStreak: current, LongestStreak: longest, AsOf: today}, nil
+}
+
+// streakDay returns midnight UTC of the day now counts towards; the day only ends once its
+// grace period is over
+func streakDay(now time.Time, policy models.StreakPolicy) time.Time {
+	t := now.UTC().Add(-time.Duration(policy.GraceHours) * time.Hour)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// resolve applies the user's overrides to the deployment's policy
+func (s *StreakService) resolve(rules models.StreakRules) models.StreakPolicy {
+	policy := models.StreakPolicy{
+		RestDaysPerWeek: s.cfg.RestDaysPerWeek,
+		MinMinutes:      s.cfg.MinMinutes,
+		GraceHours:      s.cfg.GraceHours,
+	}
+	if rules.RestDaysPerWeek != nil {
+		policy.RestDaysPerWeek = *rules.RestDaysPerWeek
+	}
+	if rules.MinMinutes != nil {
+		policy.MinMinutes = *rules.MinMinutes
+	}
+	if rules.GraceHours != nil {
+		policy.GraceHours = *rules.GraceHours
+	}
+	return policy
+}
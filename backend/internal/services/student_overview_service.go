@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/i18n"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	overviewRecentSessions  = 10
+	overviewOpenSubmissions = 20
+	overviewNotes           = 10
+)
+
+// StudentOverviewService assembles a student's detail page and app state for instructors in one call
+type StudentOverviewService struct {
+	userService        *UserService
+	sessionService     *SessionService
+	programRepo        *repositories.ProgramRepository
+	sessionRepo        *repositories.SessionRepository
+	submissionRepo     *repositories.SubmissionRepository
+	programService     *ProgramService
+	submissionService  *SubmissionService
+	translationService *TranslationService
+	limitationService  *LimitationService
+}
+
+func NewStudentOverviewService(userService *UserService, sessionService *SessionService, programRepo *repositories.ProgramRepository, sessionRepo *repositories.SessionRepository, submissionRepo *repositories.SubmissionRepository, programService *ProgramService, submissionService *SubmissionService, translationService *TranslationService, limitationService *LimitationService) *StudentOverviewService {
+	return &StudentOverviewService{
+		userService:        userService,
+		sessionService:     sessionService,
+		programRepo:        programRepo,
+		sessionRepo:        sessionRepo,
+		submissionRepo:     submissionRepo,
+		programService:     programService,
+		submissionService:  submissionService,
+		translationService: translationService,
+		limitationService:  limitationService,
+	}
+}
+
+// Get returns the student's profile, stats, programs with progress, recent sessions, open
+// submissions and latest instructor notes. The parts are fetched concurrently.
+func (s *StudentOverviewService) Get(ctx context.Context, studentID uuid.UUID) (*models.StudentOverview, error) {
+	profile, err := s.userService.GetByID(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	overview := &models.StudentOverview{Profile: *profile}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		stats, err := s.sessionService.GetStats(ctx, studentID)
+		if err != nil {
+			return err
+		}
+		overview.Stats = *stats
+		return nil
+	})
+	g.Go(func() error {
+		programs, err := s.programs(ctx, studentID)
+		overview.Programs = programs
+		return err
+	})
+	g.Go(func() error {
+		sessions, err := s.sessionRepo.ListByUserID(ctx, studentID, nil, nil, nil, overviewRecentSessions, 0)
+		if err != nil {
+			return appErrors.NewInternalError("Failed to fetch sessions").WithError(err)
+		}
+		overview.RecentSessions = sessions
+		return nil
+	})
+	g.Go(func() error {
+		submissions, err := s.submissionRepo.ListOpen(ctx, studentID, overviewOpenSubmissions)
+		if err != nil {
+			return appErrors.NewInternalError("Failed to fetch open submissions").WithError(err)
+		}
+		overview.OpenSubmissions = submissions
+		return nil
+	})
+	g.Go(func() error {
+		notes, err := s.sessionRepo.ListNotesByUser(ctx, studentID, overviewNotes)
+		if err != nil {
+			return appErrors.NewInternalError("Failed to fetch session notes").WithError(err)
+		}
+		overview.Notes = notes
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return overview, nil
+}
+
+// AppState returns what the student's app would get from /my-programs, the unread counts and
+// their stats, through the same services those endpoints use. Programs are localized to locale,
+// or to the student's own language when it is empty. Nothing is recorded as the student; reading
+// it marks no messages as read.
+func (s *StudentOverviewService) AppState(ctx context.Context, studentID uuid.UUID, locale string) (*models.StudentAppState, error) {
+	profile, err := s.userService.GetByID(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if locale == "" {
+		locale = profile.Language
+	}
+	state := &models.StudentAppState{Locale: i18n.Negotiate(locale)}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		programs, err := s.programService.GetUserPrograms(ctx, studentID)
+		if err != nil {
+			return err
+		}
+		if err := s.translationService.Localize(ctx, programs, state.Locale); err != nil {
+			return err
+		}
+		// After localizing, so substituted exercises keep the substitute's name
+		if err := s.limitationService.Adjust(ctx, studentID, programs); err != nil {
+			return err
+		}
+		state.Programs = programs
+		return nil
+	})
+	g.Go(func() error {
+		counts, err := s.submissionService.GetUnreadCount(ctx, studentID, nil)
+		if err != nil {
+			return err
+		}
+		state.UnreadCounts = *counts
+		return nil
+	})
+	g.Go(func() error {
+		stats, err := s.sessionService.GetStats(ctx, studentID)
+		if err != nil {
+			return err
+		}
+		state.Stats = *stats
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// programs returns the student's active programs with their progress
+func (s *StudentOverviewService) programs(ctx context.Context, studentID uuid.UUID) ([]models.StudentProgram, error) {
+	programs, err := s.programRepo.GetUserProgramsWithDetails(ctx, studentID, true)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch user programs").WithError(err)
+	}
+
+	result := make([]models.StudentProgram, len(programs))
+	for i, program := range programs {
+		progress, err := s.sessionService.GetProgramProgress(ctx, studentID, program.ID)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = models.StudentProgram{Program: program, Progress: *progress}
+	}
+	return result, nil
+}
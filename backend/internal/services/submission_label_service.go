@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// SubmissionLabelService manages the labels instructors use to organize the review queue
+type SubmissionLabelService struct {
+	labelRepo      *repositories.SubmissionLabelRepository
+	submissionRepo *repositories.SubmissionRepository
+}
+
+func NewSubmissionLabelService(labelRepo *repositories.SubmissionLabelRepository, submissionRepo *repositories.SubmissionRepository) *SubmissionLabelService {
+	return &SubmissionLabelService{
+		labelRepo:      labelRepo,
+		submissionRepo: submissionRepo,
+	}
+}
+
+func (s *SubmissionLabelService) Create(ctx context.Context, createdBy uuid.UUID, name string, color *string) (*models.SubmissionLabel, error) {
+	name = strings.TrimSpace(name)
+	if err := s.checkName(ctx, name, nil); err != nil {
+		return nil, err
+	}
+
+	label := &models.SubmissionLabel{
+		Name:      name,
+		Color:     models.DefaultLabelColor,
+		CreatedBy: &createdBy,
+	}
+	if color != nil {
+		label.Color = strings.ToLower(*color)
+	}
+	if err := s.labelRepo.Create(ctx, label); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create label").WithError(err)
+	}
+
+	return label, nil
+}
+
+func (s *SubmissionLabelService) Get(ctx context.Context, id uuid.UUID) (*models.SubmissionLabel, error) {
+	label, err := s.labelRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch label").WithError(err)
+	}
+	if label == nil {
+		return nil, appErrors.NewNotFoundError("Label")
+	}
+	return label, nil
+}
+
+func (s *SubmissionLabelService) List(ctx context.Context) ([]models.SubmissionLabel, error) {
+	labels, err := s.labelRepo.List(ctx)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch labels").WithError(err)
+	}
+	return labels, nil
+}
+
+func (s *SubmissionLabelService) Update(ctx context.Context, id uuid.UUID, name, color *string) (*models.SubmissionLabel, error) {
+	label, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if !strings.EqualFold(trimmed, label.Name) {
+			if err := s.checkName(ctx, trimmed, &id); err != nil {
+				return nil, err
+			}
+		}
+		label.Name = trimmed
+	}
+	if color != nil {
+		label.Color = strings.ToLower(*color)
+	}
+
+	if err := s.labelRepo.Update(ctx, label); err != nil {
+		return nil, appErrors.NewInternalError("Failed to update label").WithError(err)
+	}
+
+	return label, nil
+}
+
+// Delete removes the label, also from every submission carrying it
+func (s *SubmissionLabelService) Delete(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.labelRepo.Delete(ctx, id)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete label").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Label")
+	}
+	return nil
+}
+
+// SetSubmissionLabels replaces the labels of a submission and returns them
+func (s *SubmissionLabelService) SetSubmissionLabels(ctx context.Context, submissionID, userID uuid.UUID, labelIDs []uuid.UUID) ([]models.SubmissionLabel, error) {
+	if _, err := s.submissionRepo.GetByID(ctx, submissionID, userID, true); err != nil {
+		if errors.Is(err, repositories.ErrSubmissionNotFound) {
+			return nil, appErrors.NewNotFoundError("Submission")
+		}
+		return nil, appErrors.NewInternalError("Failed to fetch submission").WithError(err)
+	}
+
+	if labelIDs == nil {
+		labelIDs = []uuid.UUID{} // A nil slice would be NULL and remove nothing
+	}
+	count, err := s.labelRepo.CountExisting(ctx, labelIDs)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to check labels").WithError(err)
+	}
+	if count != len(labelIDs) {
+		return nil, appErrors.NewBadRequestError("Unknown label")
+	}
+
+	if err := s.labelRepo.SetForSubmission(ctx, submissionID, labelIDs, userID); err != nil {
+		return nil, appErrors.NewInternalError("Failed to label submission").WithError(err)
+	}
+
+	labels, err := s.labelRepo.ListForSubmission(ctx, submissionID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch labels").WithError(err)
+	}
+	return labels, nil
+}
+
+// checkName rejects a label name another label already uses, ignoring case
+func (s *SubmissionLabelService) checkName(ctx context.Context, name string, excludeID *uuid.UUID) error {
+	if name == "" {
+		return appErrors.NewBadRequestError("Label name cannot be empty")
+	}
+	exists, err := s.labelRepo.NameExists(ctx, name, excludeID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to check label name").WithError(err)
+	}
+	if exists {
+		return appErrors.NewConflictError("A label with this name already exists")
+	}
+	return nil
+}
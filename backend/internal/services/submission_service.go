@@ -4,28 +4,58 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/config"
 	"github.com/xuangong/backend/internal/models"
 	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
 	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/mention"
 	"github.com/xuangong/backend/pkg/youtube"
 )
 
 type SubmissionService struct {
-	submissionRepo *repositories.SubmissionRepository
-	programRepo    *repositories.ProgramRepository
+	submissionRepo      *repositories.SubmissionRepository
+	programRepo         *repositories.ProgramRepository
+	snippetService      *SnippetService
+	notificationService *NotificationService
+	quotaService        *QuotaService
+	contentFilter       *ContentFilterService
+	cfg                 *config.MessagesConfig
+	typing              *typingTracker
+	clock               clock.Clock
 }
 
-func NewSubmissionService(submissionRepo *repositories.SubmissionRepository, programRepo *repositories.ProgramRepository) *SubmissionService {
+func NewSubmissionService(submissionRepo *repositories.SubmissionRepository, programRepo *repositories.ProgramRepository, snippetService *SnippetService, notificationService *NotificationService, quotaService *QuotaService, contentFilter *ContentFilterService, cfg *config.MessagesConfig) *SubmissionService {
 	return &SubmissionService{
-		submissionRepo: submissionRepo,
-		programRepo:    programRepo,
+		submissionRepo:      submissionRepo,
+		programRepo:         programRepo,
+		snippetService:      snippetService,
+		notificationService: notificationService,
+		quotaService:        quotaService,
+		contentFilter:       contentFilter,
+		cfg:                 cfg,
+		typing:              newTypingTracker(),
+		clock:               clock.System,
 	}
 }
 
-// CreateSubmission creates a new submission for a program
-func (s *SubmissionService) CreateSubmission(ctx context.Context, programID, userID uuid.UUID, title string) (*models.Submission, error) {
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *SubmissionService) WithClock(c clock.Clock) *SubmissionService {
+	s.clock = c
+	return s
+}
+
+// CreateSubmission creates a new submission for a program. Students need to own the program or
+// have it assigned; admins can open threads on any program.
+func (s *SubmissionService) CreateSubmission(ctx context.Context, programID, userID uuid.UUID, userRole models.UserRole, title string) (*models.Submission, error) {
 	// Validate title
 	if title == "" {
 		return nil, appErrors.NewBadRequestError("Title cannot be empty")
@@ -39,6 +69,23 @@ func (s *SubmissionService) CreateSubmission(ctx context.Context, programID, use
 	if program == nil {
 		return nil, appErrors.NewNotFoundError("Program")
 	}
+	if userRole != models.RoleAdmin {
+		allowed, err := s.programRepo.CanAccess(ctx, userID, programID)
+		if err != nil {
+			return nil, appErrors.NewInternalError("Failed to verify program assignment").WithError(err)
+		}
+		if !allowed {
+			return nil, appErrors.NewAuthorizationError("This program is not assigned to you")
+		}
+	}
+
+	if err := s.checkTemplateTitle(ctx, programID, title); err != nil {
+		return nil, err
+	}
+
+	if err := s.quotaService.CheckOpenSubmissions(ctx, userID); err != nil {
+		return nil, err
+	}
 
 	// Create submission
 	submission, err := s.submissionRepo.Create(ctx, programID, userID, title)
@@ -49,6 +96,107 @@ func (s *SubmissionService) CreateSubmission(ctx context.Context, programID, use
 	return submission, nil
 }
 
+// GetTemplate returns the submission template of a program
+func (s *SubmissionService) GetTemplate(ctx context.Context, programID uuid.UUID) (*models.SubmissionTemplate, error) {
+	if err := s.ensureProgram(ctx, programID); err != nil {
+		return nil, err
+	}
+
+	template, err := s.submissionRepo.GetTemplate(ctx, programID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch submission template").WithError(err)
+	}
+	if template == nil {
+		return nil, appErrors.NewNotFoundError("Submission template")
+	}
+	return template, nil
+}
+
+// SetTemplate creates or replaces the submission template of a program. The title pattern must
+// compile and the example, if given, must match it.
+func (s *SubmissionService) SetTemplate(ctx context.Context, template *models.SubmissionTemplate) error {
+	if err := s.ensureProgram(ctx, template.ProgramID); err != nil {
+		return err
+	}
+
+	if template.TitlePattern != nil && *template.TitlePattern == "" {
+		template.TitlePattern = nil
+	}
+	if template.TitleExample != nil && strings.TrimSpace(*template.TitleExample) == "" {
+		template.TitleExample = nil
+	}
+	if template.TitlePattern != nil {
+		pattern, err := compileTitlePattern(*template.TitlePattern)
+		if err != nil {
+			return appErrors.NewBadRequestError("Invalid title pattern").WithDetails("error", err.Error())
+		}
+		if template.TitleExample != nil && !pattern.MatchString(*template.TitleExample) {
+			return appErrors.NewBadRequestError("The title example doesn't match the title pattern")
+		}
+	}
+	if template.Prompts == nil {
+		template.Prompts = []string{}
+	}
+
+	if err := s.submissionRepo.SaveTemplate(ctx, template); err != nil {
+		return appErrors.NewInternalError("Failed to save submission template").WithError(err)
+	}
+	return nil
+}
+
+// DeleteTemplate removes the submission template of a program
+func (s *SubmissionService) DeleteTemplate(ctx context.Context, programID uuid.UUID) error {
+	deleted, err := s.submissionRepo.DeleteTemplate(ctx, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete submission template").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Submission template")
+	}
+	return nil
+}
+
+// checkTemplateTitle rejects a title that doesn't match the program's title pattern
+func (s *SubmissionService) checkTemplateTitle(ctx context.Context, programID uuid.UUID, title string) error {
+	template, err := s.submissionRepo.GetTemplate(ctx, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch submission template").WithError(err)
+	}
+	if template == nil || template.TitlePattern == nil {
+		return nil
+	}
+
+	pattern, err := compileTitlePattern(*template.TitlePattern)
+	if err != nil {
+		return appErrors.NewInternalError("Invalid stored title pattern").WithError(err)
+	}
+	if !pattern.MatchString(title) {
+		appErr := appErrors.NewBadRequestError("The title doesn't follow the format for this program").
+			WithDetails("title_pattern", *template.TitlePattern)
+		if template.TitleExample != nil {
+			appErr = appErr.WithDetails("title_example", *template.TitleExample)
+		}
+		return appErr
+	}
+	return nil
+}
+
+// compileTitlePattern compiles a title pattern so that it has to match the whole title
+func compileTitlePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+func (s *SubmissionService) ensureProgram(ctx context.Context, programID uuid.UUID) error {
+	program, err := s.programRepo.GetByID(ctx, programID)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch program").WithError(err)
+	}
+	if program == nil {
+		return appErrors.NewNotFoundError("Program")
+	}
+	return nil
+}
+
 // GetSubmission retrieves a submission by ID with access control
 func (s *SubmissionService) GetSubmission(ctx context.Context, id, userID uuid.UUID, isAdmin bool) (*models.Submission, error) {
 	submission, err := s.submissionRepo.GetByID(ctx, id, userID, isAdmin)
@@ -65,8 +213,9 @@ func (s *SubmissionService) GetSubmission(ctx context.Context, id, userID uuid.U
 	return submission, nil
 }
 
-// ListSubmissions retrieves submissions with filters and access control
-func (s *SubmissionService) ListSubmissions(ctx context.Context, programID *uuid.UUID, userID uuid.UUID, isAdmin bool, limit, offset int) ([]models.SubmissionListItem, error) {
+// ListSubmissions retrieves submissions with filters and access control. Archived threads are
+// listed separately.
+func (s *SubmissionService) ListSubmissions(ctx context.Context, programID, labelID *uuid.UUID, userID uuid.UUID, isAdmin, archived bool, limit, offset int) ([]models.SubmissionListItem, error) {
 	// Validate pagination
 	if limit <= 0 || limit > 100 {
 		limit = 50
@@ -75,7 +224,7 @@ func (s *SubmissionService) ListSubmissions(ctx context.Context, programID *uuid
 		offset = 0
 	}
 
-	submissions, err := s.submissionRepo.List(ctx, programID, userID, isAdmin, limit, offset)
+	submissions, err := s.submissionRepo.List(ctx, programID, labelID, userID, isAdmin, archived, limit, offset)
 	if err != nil {
 		return nil, appErrors.NewInternalError("Failed to list submissions").WithError(err)
 	}
@@ -83,10 +232,36 @@ func (s *SubmissionService) ListSubmissions(ctx context.Context, programID *uuid
 	return submissions, nil
 }
 
-// CreateMessage adds a message to a submission
-func (s *SubmissionService) CreateMessage(ctx context.Context, submissionID, userID uuid.UUID, isAdmin bool, content string, youtubeURL *string) (*models.SubmissionMessage, error) {
+// SearchSubmissions finds submissions by title and message content within the threads the user can access
+func (s *SubmissionService) SearchSubmissions(ctx context.Context, q string, programID *uuid.UUID, userID uuid.UUID, isAdmin bool, limit, offset int) ([]models.SubmissionSearchResult, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, appErrors.NewBadRequestError("Search query cannot be empty")
+	}
+
+	// Validate pagination
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	results, err := s.submissionRepo.Search(ctx, q, programID, userID, isAdmin, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to search submissions").WithError(err)
+	}
+
+	return results, nil
+}
+
+// CreateMessage adds a message to a submission. If snippetID is set, the author's snippet is
+// expanded for this submission and appended to the content. Users @mentioned in the content
+// must be participants of the thread and are notified once the message is stored. The content
+// filter screens the final content.
+func (s *SubmissionService) CreateMessage(ctx context.Context, submissionID, userID uuid.UUID, isAdmin bool, content string, youtubeURL *string, snippetID *uuid.UUID) (*models.SubmissionMessage, error) {
 	// Validate content
-	if content == "" {
+	if content == "" && snippetID == nil {
 		return nil, appErrors.NewBadRequestError("Message content cannot be empty")
 	}
 
@@ -112,15 +287,273 @@ func (s *SubmissionService) CreateMessage(ctx context.Context, submissionID, use
 		return nil, appErrors.NewNotFoundError("Submission")
 	}
 
+	if snippetID != nil {
+		expanded, err := s.snippetService.Expand(ctx, *snippetID, userID, submission)
+		if err != nil {
+			return nil, err
+		}
+		content = strings.TrimSpace(strings.Join([]string{content, expanded}, "\n\n"))
+	}
+
+	if err := s.checkMessageRate(ctx, submission.ID, userID, content, youtubeURL); err != nil {
+		return nil, err
+	}
+
+	flagged, err := s.contentFilter.Screen(ctx, userID, "message", content)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.postMessage(ctx, submission, userID, content, youtubeURL)
+	if err != nil {
+		return nil, err
+	}
+	s.contentFilter.Flag(ctx, models.ModerationMessage, message.ID, flagged)
+	s.typing.stop(submissionID, userID)
+
+	return message, nil
+}
+
+// checkMessageRate refuses a message identical to one the user just posted in the submission,
+// and messages beyond the user's rate limit. Concurrent requests can slip through, which is
+// fine for stopping clients that resend in a loop.
+func (s *SubmissionService) checkMessageRate(ctx context.Context, submissionID, userID uuid.UUID, content string, youtubeURL *string) error {
+	now := s.clock.Now()
+
+	if window := s.cfg.GetDuplicateWindow(); window > 0 {
+		duplicate, err := s.submissionRepo.FindDuplicateMessage(ctx, submissionID, userID, content, youtubeURL, now.Add(-window))
+		if err != nil {
+			return appErrors.NewInternalError("Failed to check for duplicate messages").WithError(err)
+		}
+		if duplicate != nil {
+			return appErrors.NewConflictError("You just posted the same message").
+				WithDetails("message_id", duplicate.String())
+		}
+	}
+
+	if s.cfg.RateLimit > 0 {
+		window := s.cfg.GetRateLimitWindow()
+		count, oldest, err := s.submissionRepo.CountMessagesSince(ctx, userID, now.Add(-window))
+		if err != nil {
+			return appErrors.NewInternalError("Failed to check message rate").WithError(err)
+		}
+		if count >= s.cfg.RateLimit {
+			// The next message is allowed once the oldest one leaves the window
+			retryAfter := window
+			if oldest != nil {
+				retryAfter = oldest.Add(window).Sub(now)
+			}
+			return appErrors.NewRateLimitError().
+				WithDetails("retry_after_seconds", int(math.Ceil(max(retryAfter, time.Second).Seconds())))
+		}
+	}
+
+	return nil
+}
+
+// PostScheduledMessage posts an instructor's scheduled message and notifies the student, who
+// may not expect a reply at this time. Returns the message and the number of users notified.
+func (s *SubmissionService) PostScheduledMessage(ctx context.Context, submissionID, authorID uuid.UUID, content string, youtubeURL *string) (*models.SubmissionMessage, int, error) {
+	submission, err := s.submissionRepo.GetByID(ctx, submissionID, authorID, true)
+	if err != nil && !errors.Is(err, repositories.ErrSubmissionNotFound) {
+		return nil, 0, appErrors.NewInternalError("Failed to fetch submission").WithError(err)
+	}
+	if submission == nil {
+		return nil, 0, appErrors.NewNotFoundError("Submission")
+	}
+
+	message, err := s.postMessage(ctx, submission, authorID, content, youtubeURL)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	notified := len(message.Mentions)
+	if s.notifyOwner(ctx, submission, message) {
+		notified++
+	}
+
+	return message, notified, nil
+}
+
+// OpenThread opens a submission thread for a student on behalf of an instructor, starting with
+// the instructor's message. Quotas and the program's title format don't apply.
+func (s *SubmissionService) OpenThread(ctx context.Context, programID, studentID, instructorID uuid.UUID, title, content string) (*models.Submission, error) {
+	submission, err := s.submissionRepo.Create(ctx, programID, studentID, title)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to create submission").WithError(err)
+	}
+
+	message, err := s.postMessage(ctx, submission, instructorID, content, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyOwner(ctx, submission, message)
+
+	return submission, nil
+}
+
+// notifyOwner tells the student about a new message in their submission unless they wrote it or
+// were already notified of a mention. It reports whether a notification was sent.
+func (s *SubmissionService) notifyOwner(ctx context.Context, submission *models.Submission, message *models.SubmissionMessage) bool {
+	if submission.UserID == message.UserID || slices.Contains(message.Mentions, submission.UserID) {
+		return false
+	}
+
+	payload := map[string]interface{}{
+		"submission_id": submission.ID.String(),
+		"message_id":    message.ID.String(),
+	}
+	if _, err := s.notificationService.Notify(ctx, submission.UserID, models.NotificationNewMessage, fmt.Sprintf("New message in \"%s\"", submission.Title), &message.Content, payload); err != nil {
+		log.Printf("[WARN] Failed to notify user %s about message %s: %v", submission.UserID, message.ID, err)
+		return false
+	}
+	return true
+}
+
+// postMessage stores a message in a submission the author has access to and notifies mentioned users
+func (s *SubmissionService) postMessage(ctx context.Context, submission *models.Submission, authorID uuid.UUID, content string, youtubeURL *string) (*models.SubmissionMessage, error) {
+	mentions, err := s.resolveMentions(ctx, submission.ID, authorID, content)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create message
-	message, err := s.submissionRepo.CreateMessage(ctx, submissionID, userID, content, youtubeURL)
+	message, err := s.submissionRepo.CreateMessage(ctx, submission.ID, authorID, content, youtubeURL, mentions)
 	if err != nil {
 		return nil, appErrors.NewInternalError("Failed to create message").WithError(err)
 	}
 
+	for _, mentioned := range mentions {
+		payload := map[string]interface{}{
+			"submission_id": submission.ID.String(),
+			"message_id":    message.ID.String(),
+		}
+		// The message is already stored, a failed notification should not fail the request
+		if _, err := s.notificationService.Notify(ctx, mentioned, models.NotificationMessageMention, fmt.Sprintf("You were mentioned in \"%s\"", submission.Title), &content, payload); err != nil {
+			log.Printf("[WARN] Failed to notify user %s about mention in message %s: %v", mentioned, message.ID, err)
+		}
+	}
+
 	return message, nil
 }
 
+// resolveMentions returns the users mentioned in content, excluding the author, and rejects
+// mentions of anyone who is not the submission owner or an instructor
+func (s *SubmissionService) resolveMentions(ctx context.Context, submissionID, authorID uuid.UUID, content string) ([]uuid.UUID, error) {
+	var mentions []uuid.UUID
+	for _, id := range mention.Parse(content) {
+		if id != authorID {
+			mentions = append(mentions, id)
+		}
+	}
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+
+	targets, err := s.submissionRepo.MentionTargets(ctx, submissionID, mentions)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to verify mentions").WithError(err)
+	}
+	if len(targets) != len(mentions) {
+		return nil, appErrors.NewBadRequestError("Only the student and instructors can be mentioned in a submission")
+	}
+
+	return mentions, nil
+}
+
+// ArchiveSubmission archives or unarchives a thread for the user only. Archived threads leave
+// the default list and unread counts until someone posts in them.
+func (s *SubmissionService) ArchiveSubmission(ctx context.Context, submissionID, userID uuid.UUID, isAdmin, archive bool) error {
+	if _, err := s.GetSubmission(ctx, submissionID, userID, isAdmin); err != nil {
+		return err
+	}
+
+	if archive {
+		if err := s.submissionRepo.Archive(ctx, submissionID, userID); err != nil {
+			return appErrors.NewInternalError("Failed to archive submission").WithError(err)
+		}
+		return nil
+	}
+	if err := s.submissionRepo.Unarchive(ctx, submissionID, userID); err != nil {
+		return appErrors.NewInternalError("Failed to unarchive submission").WithError(err)
+	}
+	return nil
+}
+
+// SaveDraft stores the user's unsent message for a submission. Saving an empty draft discards it.
+func (s *SubmissionService) SaveDraft(ctx context.Context, submissionID, userID uuid.UUID, isAdmin bool, content string, youtubeURL *string) (*models.SubmissionDraft, error) {
+	if _, err := s.GetSubmission(ctx, submissionID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(content) == "" && (youtubeURL == nil || *youtubeURL == "") {
+		if err := s.submissionRepo.DeleteDraft(ctx, submissionID, userID); err != nil {
+			return nil, appErrors.NewInternalError("Failed to discard draft").WithError(err)
+		}
+		return nil, nil
+	}
+
+	draft := &models.SubmissionDraft{
+		SubmissionID: submissionID,
+		UserID:       userID,
+		Content:      content,
+		YouTubeURL:   youtubeURL,
+	}
+	if err := s.submissionRepo.SaveDraft(ctx, draft); err != nil {
+		return nil, appErrors.NewInternalError("Failed to save draft").WithError(err)
+	}
+
+	return draft, nil
+}
+
+// GetDraft returns the user's draft for a submission, or nil if there is none
+func (s *SubmissionService) GetDraft(ctx context.Context, submissionID, userID uuid.UUID, isAdmin bool) (*models.SubmissionDraft, error) {
+	if _, err := s.GetSubmission(ctx, submissionID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	draft, err := s.submissionRepo.GetDraft(ctx, submissionID, userID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch draft").WithError(err)
+	}
+
+	return draft, nil
+}
+
+// DeleteDraft discards the user's draft for a submission
+func (s *SubmissionService) DeleteDraft(ctx context.Context, submissionID, userID uuid.UUID) error {
+	if err := s.submissionRepo.DeleteDraft(ctx, submissionID, userID); err != nil {
+		return appErrors.NewInternalError("Failed to discard draft").WithError(err)
+	}
+	return nil
+}
+
+// SetTyping starts or stops the user's typing indicator in a submission. A start lasts a few
+// seconds, so clients resend it while the user keeps typing; posting a message stops it. It
+// returns who else is typing, so typing clients don't need to poll separately.
+func (s *SubmissionService) SetTyping(ctx context.Context, submissionID, userID uuid.UUID, isAdmin, typing bool) ([]models.TypingUser, error) {
+	if _, err := s.GetSubmission(ctx, submissionID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	if typing {
+		s.typing.start(submissionID, userID, now.Add(typingTimeout))
+	} else {
+		s.typing.stop(submissionID, userID)
+	}
+	return s.typing.list(submissionID, userID, now), nil
+}
+
+// GetTyping returns who else is typing in a submission
+func (s *SubmissionService) GetTyping(ctx context.Context, submissionID, userID uuid.UUID, isAdmin bool) ([]models.TypingUser, error) {
+	if _, err := s.GetSubmission(ctx, submissionID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	return s.typing.list(submissionID, userID, s.clock.Now()), nil
+}
+
 // GetMessages retrieves all messages for a submission with access control
 func (s *SubmissionService) GetMessages(ctx context.Context, submissionID, userID uuid.UUID, isAdmin bool) ([]models.MessageWithAuthor, error) {
 	messages, err := s.submissionRepo.GetMessages(ctx, submissionID, userID, isAdmin)
@@ -177,3 +610,30 @@ func (s *SubmissionService) SoftDeleteSubmission(ctx context.Context, id, userID
 
 	return nil
 }
+
+// GetReviewAnalytics returns per-instructor review workload and turnaround over the last given number of days
+func (s *SubmissionService) GetReviewAnalytics(ctx context.Context, days int) (*models.ReviewAnalytics, error) {
+	since := s.clock.Now().AddDate(0, 0, -days)
+
+	analytics, err := s.submissionRepo.GetReviewAnalytics(ctx, since)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch review analytics").WithError(err)
+	}
+
+	analytics.Days = days
+	weeks := float64(days) / 7
+	for i := range analytics.Instructors {
+		stats := &analytics.Instructors[i]
+		stats.MessagesPerWeek = roundTenth(float64(stats.Messages) / weeks)
+		if stats.MedianFirstResponseMinutes != nil {
+			median := roundTenth(*stats.MedianFirstResponseMinutes)
+			stats.MedianFirstResponseMinutes = &median
+		}
+	}
+
+	return analytics, nil
+}
+
+func roundTenth(v float64) float64 {
+	return math.Round(v*10) / 10
+}
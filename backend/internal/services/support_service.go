@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+// SupportService manages support tickets: questions from students to the admins that are not
+// about a program. Tickets are only visible to the student who opened them and to admins.
+// Messages go through the content filter like submission messages, and students are notified
+// of admin replies.
+type SupportService struct {
+	supportRepo         *repositories.SupportRepository
+	contentFilter       *ContentFilterService
+	notificationService *NotificationService
+}
+
+func NewSupportService(supportRepo *repositories.SupportRepository, contentFilter *ContentFilterService, notificationService *NotificationService) *SupportService {
+	return &SupportService{
+		supportRepo:         supportRepo,
+		contentFilter:       contentFilter,
+		notificationService: notificationService,
+	}
+}
+
+// Open opens a ticket with the user's first message. It waits in the admins' queue until one replies.
+func (s *SupportService) Open(ctx context.Context, userID uuid.UUID, category models.SupportCategory, content string) (*models.SupportTicketWithMessages, error) {
+	if err := s.screen(ctx, userID, content); err != nil {
+		return nil, err
+	}
+
+	id, err := s.supportRepo.Create(ctx, userID, category, content)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to open support ticket").WithError(err)
+	}
+	return s.Get(ctx, id, userID, false)
+}
+
+// List returns the user's own tickets, most recently active first
+func (s *SupportService) List(ctx context.Context, userID uuid.UUID, status *models.SupportStatus, limit, offset int) ([]models.SupportTicket, error) {
+	tickets, err := s.supportRepo.ListByUser(ctx, userID, status, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to list support tickets").WithError(err)
+	}
+	return tickets, nil
+}
+
+// Queue returns everyone's tickets for admins, the longest waiting first
+func (s *SupportService) Queue(ctx context.Context, status *models.SupportStatus, category *models.SupportCategory, limit, offset int) ([]models.SupportTicket, error) {
+	tickets, err := s.supportRepo.ListQueue(ctx, status, category, limit, offset)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to list support tickets").WithError(err)
+	}
+	return tickets, nil
+}
+
+// Get returns a ticket with its messages
+func (s *SupportService) Get(ctx context.Context, id, userID uuid.UUID, isAdmin bool) (*models.SupportTicketWithMessages, error) {
+	ticket, err := s.getAccessible(ctx, id, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.supportRepo.ListMessages(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch support messages").WithError(err)
+	}
+	return &models.SupportTicketWithMessages{SupportTicket: *ticket, Messages: messages}, nil
+}
+
+// Reply adds a message to a ticket. A message from the student puts the ticket back in the
+// queue, reopening it if it was closed; a reply from an admin marks it answered and notifies
+// the student.
+func (s *SupportService) Reply(ctx context.Context, id, userID uuid.UUID, isAdmin bool, content string) (*models.SupportMessage, error) {
+	ticket, err := s.getAccessible(ctx, id, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.screen(ctx, userID, content); err != nil {
+		return nil, err
+	}
+
+	status := models.SupportAnswered
+	if userID == ticket.UserID {
+		status = models.SupportOpen
+	}
+	message := &models.SupportMessage{TicketID: id, AuthorID: userID, Content: content}
+	if err := s.supportRepo.CreateMessage(ctx, message, status); err != nil {
+		return nil, appErrors.NewInternalError("Failed to create support message").WithError(err)
+	}
+
+	if userID != ticket.UserID {
+		payload := map[string]interface{}{
+			"ticket_id":  id.String(),
+			"message_id": message.ID.String(),
+		}
+		// The message is already stored, a failed notification should not fail the request
+		if _, err := s.notificationService.Notify(ctx, ticket.UserID, models.NotificationSupportReply, "New reply to your support ticket", &content, payload); err != nil {
+			log.Printf("[WARN] Failed to notify user %s about support message %s: %v", ticket.UserID, message.ID, err)
+		}
+	}
+	return message, nil
+}
+
+// SetStatus moves a ticket to a status, e.g. closes it once the question is settled
+func (s *SupportService) SetStatus(ctx context.Context, id uuid.UUID, status models.SupportStatus) (*models.SupportTicket, error) {
+	ticket, err := s.supportRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch support ticket").WithError(err)
+	}
+	if ticket == nil {
+		return nil, appErrors.NewNotFoundError("Support ticket")
+	}
+
+	if err := s.supportRepo.SetStatus(ctx, id, status); err != nil {
+		return nil, appErrors.NewInternalError("Failed to update support ticket").WithError(err)
+	}
+	ticket, err = s.supportRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch support ticket").WithError(err)
+	}
+	return ticket, nil
+}
+
+// getAccessible loads a ticket the user may see: their own, or any for admins. Other users'
+// tickets are reported as missing.
+func (s *SupportService) getAccessible(ctx context.Context, id, userID uuid.UUID, isAdmin bool) (*models.SupportTicket, error) {
+	ticket, err := s.supportRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch support ticket").WithError(err)
+	}
+	if ticket == nil || (ticket.UserID != userID && !isAdmin) {
+		return nil, appErrors.NewNotFoundError("Support ticket")
+	}
+	return ticket, nil
+}
+
+// screen runs a message through the content filter. Only admins read support tickets, so
+// flagged messages are not put in the moderation queue; with the reject action they fail.
+func (s *SupportService) screen(ctx context.Context, userID uuid.UUID, content string) error {
+	_, err := s.contentFilter.Screen(ctx, userID, "message", content)
+	return err
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xuangong/backend/internal/config"
+	"github.com/xuangong/backend/pkg/clock"
+)
+
+// templateCacheMaxEntries caps the number of cached listings; pages and locales multiply quickly
+// when clients page deep, so the cache starts over rather than growing without bound
+const templateCacheMaxEntries = 256
+
+// TemplateListing is a rendered page of the public template listing
+type TemplateListing struct {
+	Body []byte
+	ETag string
+	// SurrogateKeys tag the response for CDN purges: the listing itself and every program on it
+	SurrogateKeys []string
+}
+
+type templateCacheEntry struct {
+	listing *TemplateListing
+	expires time.Time
+}
+
+// TemplateCache keeps rendered pages of the public template listing in memory. Any change to a
+// program drops every page; they are few and cheap to rebuild. Counters on the listing, such
+// as completed repetitions, may lag by up to the TTL.
+type TemplateCache struct {
+	mu      sync.Mutex
+	entries map[string]templateCacheEntry
+	cfg     *config.TemplateCacheConfig
+	clock   clock.Clock
+}
+
+func NewTemplateCache(cfg *config.TemplateCacheConfig) *TemplateCache {
+	return &TemplateCache{
+		entries: make(map[string]templateCacheEntry),
+		cfg:     cfg,
+		clock:   clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (c *TemplateCache) WithClock(clk clock.Clock) *TemplateCache {
+	c.clock = clk
+	return c
+}
+
+// Get returns the cached listing for key, or nil if there is none or it expired
+func (c *TemplateCache) Get(key string) *TemplateListing {
+	if c.cfg.TTLSeconds <= 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !c.clock.Now().Before(entry.expires) {
+		return nil
+	}
+	return entry.listing
+}
+
+// Put renders body into a listing and caches it under key. The listing is returned even when
+// the cache is disabled, so callers can serve it with the same headers.
+func (c *TemplateCache) Put(key string, body []byte, surrogateKeys []string) *TemplateListing {
+	sum := sha256.Sum256(body)
+	listing := &TemplateListing{
+		Body:          body,
+		ETag:          `"` + hex.EncodeToString(sum[:16]) + `"`,
+		SurrogateKeys: surrogateKeys,
+	}
+	if c.cfg.TTLSeconds <= 0 {
+		return listing
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= templateCacheMaxEntries {
+		c.entries = make(map[string]templateCacheEntry)
+	}
+	c.entries[key] = templateCacheEntry{
+		listing: listing,
+		expires: c.clock.Now().Add(c.cfg.GetTTL()),
+	}
+	return listing
+}
+
+// Invalidate drops every cached listing. Called whenever a program, its exercises, cover,
+// translations or visibility change.
+func (c *TemplateCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) > 0 {
+		c.entries = make(map[string]templateCacheEntry)
+	}
+}
+
+// CacheControl is the Cache-Control header for the public template listing. The listing is
+// the same for every user, so shared caches may store it despite the Authorization header.
+func (c *TemplateCache) CacheControl() string {
+	return fmt.Sprintf("public, max-age=%d, s-maxage=%d, stale-while-revalidate=%d",
+		c.cfg.MaxAgeSeconds, c.cfg.SharedMaxAgeSeconds, c.cfg.StaleWhileRevalidateSeconds)
+}
+
+// SurrogateKeyHeader names the header to send surrogate keys in; empty if they are left out
+func (c *TemplateCache) SurrogateKeyHeader() string {
+	return c.cfg.SurrogateKeyHeader
+}
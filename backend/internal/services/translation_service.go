@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/i18n"
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+	"github.com/xuangong/backend/pkg/richtext"
+)
+
+// TranslationService manages localized program and exercise content.
+// The program and exercise rows hold the default locale; translations override name and description.
+type TranslationService struct {
+	translationRepo *repositories.TranslationRepository
+	programRepo     *repositories.ProgramRepository
+	exerciseRepo    *repositories.ExerciseRepository
+	templateCache   *TemplateCache
+}
+
+func NewTranslationService(translationRepo *repositories.TranslationRepository, programRepo *repositories.ProgramRepository, exerciseRepo *repositories.ExerciseRepository, templateCache *TemplateCache) *TranslationService {
+	return &TranslationService{
+		translationRepo: translationRepo,
+		programRepo:     programRepo,
+		exerciseRepo:    exerciseRepo,
+		templateCache:   templateCache,
+	}
+}
+
+// List returns all translations of a program or exercise
+func (s *TranslationService) List(ctx context.Context, entityType models.TranslatableType, entityID uuid.UUID) ([]models.Translation, error) {
+	if err := s.ensureExists(ctx, entityType, entityID); err != nil {
+		return nil, err
+	}
+
+	translations, err := s.translationRepo.List(ctx, entityType, entityID)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to list translations").WithError(err)
+	}
+	return translations, nil
+}
+
+// Set creates or replaces the translation of a program or exercise in a locale
+func (s *TranslationService) Set(ctx context.Context, entityType models.TranslatableType, translation *models.Translation) error {
+	if err := validateTranslationLocale(translation.Locale); err != nil {
+		return err
+	}
+	if err := s.ensureExists(ctx, entityType, translation.EntityID); err != nil {
+		return err
+	}
+
+	if err := s.translationRepo.Upsert(ctx, entityType, translation); err != nil {
+		return appErrors.NewInternalError("Failed to save translation").WithError(err)
+	}
+	s.templateCache.Invalidate()
+	return nil
+}
+
+// Delete removes the translation of a program or exercise in a locale
+func (s *TranslationService) Delete(ctx context.Context, entityType models.TranslatableType, entityID uuid.UUID, locale string) error {
+	deleted, err := s.translationRepo.Delete(ctx, entityType, entityID, locale)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to delete translation").WithError(err)
+	}
+	if !deleted {
+		return appErrors.NewNotFoundError("Translation")
+	}
+	s.templateCache.Invalidate()
+	return nil
+}
+
+// Localize replaces program and exercise names and descriptions with their translations in locale.
+// Content without a translation keeps the default language.
+func (s *TranslationService) Localize(ctx context.Context, programs []models.ProgramWithExercises, locale string) error {
+	if locale == i18n.DefaultLocale || len(programs) == 0 {
+		return nil
+	}
+
+	programIDs := make([]uuid.UUID, 0, len(programs))
+	exerciseIDs := make([]uuid.UUID, 0)
+	for _, p := range programs {
+		programIDs = append(programIDs, p.Program.ID)
+		for _, ex := range p.Exercises {
+			exerciseIDs = append(exerciseIDs, ex.ID)
+		}
+	}
+
+	programTranslations, err := s.translationRepo.GetForLocale(ctx, models.TranslatableProgram, programIDs, locale)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch translations").WithError(err)
+	}
+	exerciseTranslations, err := s.translationRepo.GetForLocale(ctx, models.TranslatableExercise, exerciseIDs, locale)
+	if err != nil {
+		return appErrors.NewInternalError("Failed to fetch translations").WithError(err)
+	}
+
+	for i := range programs {
+		if t, ok := programTranslations[programs[i].Program.ID]; ok {
+			programs[i].Program.Name = t.Name
+			programs[i].Program.Description = t.Description
+		}
+		for j := range programs[i].Exercises {
+			ex := &programs[i].Exercises[j]
+			if t, ok := exerciseTranslations[ex.ID]; ok {
+				ex.Name = t.Name
+				ex.Description = t.Description
+				ex.RenderedHTML = richtext.Render(t.Description)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *TranslationService) ensureExists(ctx context.Context, entityType models.TranslatableType, entityID uuid.UUID) error {
+	switch entityType {
+	case models.TranslatableProgram:
+		program, err := s.programRepo.GetByID(ctx, entityID)
+		if err != nil {
+			return appErrors.NewInternalError("Failed to fetch program").WithError(err)
+		}
+		if program == nil {
+			return appErrors.NewNotFoundError("Program")
+		}
+	case models.TranslatableExercise:
+		exercise, err := s.exerciseRepo.GetByID(ctx, entityID)
+		if err != nil {
+			return appErrors.NewInternalError("Failed to fetch exercise").WithError(err)
+		}
+		if exercise == nil {
+			return appErrors.NewNotFoundError("Exercise")
+		}
+	default:
+		return appErrors.NewBadRequestError("Unknown content type")
+	}
+	return nil
+}
+
+func validateTranslationLocale(locale string) error {
+	if !i18n.IsSupported(locale) {
+		return appErrors.NewBadRequestError("Unsupported locale")
+	}
+	if locale == i18n.DefaultLocale {
+		return appErrors.NewBadRequestError("Edit the content itself to change the default language")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+)
+
+// typingTimeout is how long a typing indicator lasts after the last typing start. Clients
+// resend start every few seconds while the user keeps typing.
+const typingTimeout = 6 * time.Second
+
+// typingTracker keeps who is typing in which submission. The state is ephemeral and only held
+// in memory, so it is lost on restart and not shared between instances.
+type typingTracker struct {
+	mu      sync.Mutex
+	typists map[uuid.UUID]map[uuid.UUID]time.Time // Submission -> user -> expiry
+}
+
+func newTypingTracker() *typingTracker {
+	return &typingTracker{typists: make(map[uuid.UUID]map[uuid.UUID]time.Time)}
+}
+
+// start marks the user as typing until the given time, extending an earlier start
+func (t *typingTracker) start(submissionID, userID uuid.UUID, until time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	users, ok := t.typists[submissionID]
+	if !ok {
+		users = make(map[uuid.UUID]time.Time)
+		t.typists[submissionID] = users
+	}
+	users[userID] = until
+}
+
+func (t *typingTracker) stop(submissionID, userID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if users, ok := t.typists[submissionID]; ok {
+		delete(users, userID)
+		if len(users) == 0 {
+			delete(t.typists, submissionID)
+		}
+	}
+}
+
+// list returns who else is typing in the submission, soonest to expire first, and forgets
+// expired indicators on the way
+func (t *typingTracker) list(submissionID, exclude uuid.UUID, now time.Time) []models.TypingUser {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	typing := []models.TypingUser{}
+	for userID, until := range t.typists[submissionID] {
+		if !until.After(now) {
+			delete(t.typists[submissionID], userID)
+			continue
+		}
+		if userID != exclude {
+			typing = append(typing, models.TypingUser{UserID: userID, ExpiresAt: until})
+		}
+	}
+	if len(t.typists[submissionID]) == 0 {
+		delete(t.typists, submissionID)
+	}
+
+	sort.Slice(typing, func(i, j int) bool { return typing[i].ExpiresAt.Before(typing[j].ExpiresAt) })
+	return typing
+}
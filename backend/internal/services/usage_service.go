@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+
+	"github.com/xuangong/backend/internal/models"
+	"github.com/xuangong/backend/internal/repositories"
+	"github.com/xuangong/backend/pkg/clock"
+	appErrors "github.com/xuangong/backend/pkg/errors"
+)
+
+type UsageService struct {
+	accessLogRepo *repositories.AccessLogRepository
+	clock         clock.Clock
+}
+
+func NewUsageService(accessLogRepo *repositories.AccessLogRepository) *UsageService {
+	return &UsageService{
+		accessLogRepo: accessLogRepo,
+		clock:         clock.System,
+	}
+}
+
+// WithClock replaces the clock used for the current time, so tests can control time
+func (s *UsageService) WithClock(c clock.Clock) *UsageService {
+	s.clock = c
+	return s
+}
+
+// RecordBatch stores access log entries
+func (s *UsageService) RecordBatch(ctx context.Context, entries []models.AccessLog) error {
+	return s.accessLogRepo.CreateBatch(ctx, entries)
+}
+
+// GetUsage returns per-user API usage over the last given number of days
+func (s *UsageService) GetUsage(ctx context.Context, days int) ([]models.UserUsage, error) {
+	since := s.clock.Now().AddDate(0, 0, -days)
+
+	usage, err := s.accessLogRepo.GetUsage(ctx, since)
+	if err != nil {
+		return nil, appErrors.NewInternalError("Failed to fetch usage statistics").WithError(err)
+	}
+
+	return usage, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/hydrate"
 	"github.com/xuangong/backend/internal/models"
 	"github.com/xuangong/backend/internal/repositories"
 	"github.com/xuangong/backend/pkg/auth"
@@ -14,13 +15,15 @@ type UserService struct {
 	userRepo     *repositories.UserRepository
 	programRepo  *repositories.ProgramRepository
 	exerciseRepo *repositories.ExerciseRepository
+	coverService *CoverService
 }
 
-func NewUserService(userRepo *repositories.UserRepository, programRepo *repositories.ProgramRepository, exerciseRepo *repositories.ExerciseRepository) *UserService {
+func NewUserService(userRepo *repositories.UserRepository, programRepo *repositories.ProgramRepository, exerciseRepo *repositories.ExerciseRepository, coverService *CoverService) *UserService {
 	return &UserService{
 		userRepo:     userRepo,
 		programRepo:  programRepo,
 		exerciseRepo: exerciseRepo,
+		coverService: coverService,
 	}
 }
 
@@ -188,15 +191,21 @@ func (s *UserService) GetUserPrograms(ctx context.Context, userID uuid.UUID) ([]
 
 	// Fetch exercises for each program
 	result := make([]models.ProgramWithExercises, len(programs))
-	for i, program := range programs {
+	err = hydrate.Each(ctx, len(programs), func(ctx context.Context, i int) error {
+		program := programs[i]
 		exercises, err := s.exerciseRepo.ListByProgramID(ctx, program.ID)
 		if err != nil {
-			return nil, appErrors.NewInternalError("Failed to fetch exercises").WithError(err)
+			return appErrors.NewInternalError("Failed to fetch exercises").WithError(err)
 		}
+		s.coverService.Attach(&program)
 		result[i] = models.ProgramWithExercises{
 			Program:   program,
 			Exercises: exercises,
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return result, nil
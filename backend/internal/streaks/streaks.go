@@ -0,0 +1,68 @@
+// Package streaks computes practice streaks under a configurable policy: how much practice a day
+// needs to count, and how many days a week a streak may skip before it breaks.
+package streaks
+
+import (
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+// Day is the completed practice of one calendar day
+type Day struct {
+	Date    time.Time // Midnight UTC
+	Minutes int
+}
+
+// Compute returns the current and longest streak in counted days. days must be in ascending
+// order. Today never breaks a streak, since there is still time to practice.
+func Compute(days []Day, today time.Time, policy models.StreakPolicy) (current, longest int) {
+	today = truncate(today)
+	counted := make(map[time.Time]bool, len(days))
+	var first time.Time
+	for _, day := range days {
+		if day.Minutes < policy.MinMinutes {
+			continue
+		}
+		date := truncate(day.Date)
+		counted[date] = true
+		if first.IsZero() || date.Before(first) {
+			first = date
+		}
+	}
+	if first.IsZero() {
+		return 0, 0
+	}
+
+	streak, rested := 0, 0
+	var restWeek time.Time
+	for date := first; !date.After(today); date = date.AddDate(0, 0, 1) {
+		if counted[date] {
+			streak++
+			longest = max(longest, streak)
+			continue
+		}
+		if streak == 0 || date.Equal(today) {
+			continue
+		}
+
+		if week := weekStart(date); !week.Equal(restWeek) {
+			restWeek, rested = week, 0
+		}
+		rested++
+		if rested > policy.RestDaysPerWeek {
+			streak, rested = 0, 0
+		}
+	}
+	return streak, longest
+}
+
+func truncate(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// weekStart returns the Monday of the date's week
+func weekStart(date time.Time) time.Time {
+	return date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+}
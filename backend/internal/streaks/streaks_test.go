@@ -0,0 +1,51 @@
+package streaks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xuangong/backend/internal/models"
+)
+
+// practiced builds days of the given minutes from dates in March 2026, where the 2nd is a Monday
+func practiced(minutes int, dates ...int) []Day {
+	days := make([]Day, len(dates))
+	for i, d := range dates {
+		days[i] = Day{Date: time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC), Minutes: minutes}
+	}
+	return days
+}
+
+func march(d int) time.Time {
+	return time.Date(2026, 3, d, 18, 30, 0, 0, time.UTC)
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name             string
+		days             []Day
+		today            time.Time
+		policy           models.StreakPolicy
+		current, longest int
+	}{
+		{"no practice", nil, march(10), models.StreakPolicy{}, 0, 0},
+		{"practiced today", practiced(10, 8, 9, 10), march(10), models.StreakPolicy{}, 3, 3},
+		{"today still open", practiced(10, 8, 9), march(10), models.StreakPolicy{}, 2, 2},
+		{"missed yesterday", practiced(10, 7, 8), march(10), models.StreakPolicy{}, 0, 2},
+		{"longest before a gap", practiced(10, 2, 3, 4, 5, 7, 8, 9), march(10), models.StreakPolicy{}, 3, 4},
+		{"rest day bridges", practiced(10, 2, 3, 5, 6), march(6), models.StreakPolicy{RestDaysPerWeek: 1}, 4, 4},
+		{"second rest day in a week breaks", practiced(10, 2, 4, 6), march(6), models.StreakPolicy{RestDaysPerWeek: 1}, 1, 2},
+		{"rest days reset each week", practiced(10, 4, 6, 7, 8, 10, 11, 12), march(12), models.StreakPolicy{RestDaysPerWeek: 1}, 7, 7},
+		{"trailing rest day keeps it current", practiced(10, 7, 8), march(10), models.StreakPolicy{RestDaysPerWeek: 1}, 2, 2},
+		{"short days don't count", append(practiced(20, 7, 8), practiced(5, 9)...), march(10), models.StreakPolicy{MinMinutes: 15}, 0, 2},
+		{"long enough days count", practiced(15, 8, 9), march(10), models.StreakPolicy{MinMinutes: 15}, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, longest := Compute(tt.days, tt.today, tt.policy)
+			if current != tt.current || longest != tt.longest {
+				t.Errorf("Compute() = %d, %d, want %d, %d", current, longest, tt.current, tt.longest)
+			}
+		})
+	}
+}
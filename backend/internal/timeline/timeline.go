@@ -0,0 +1,258 @@
+// Package timeline compiles a program's exercises into a flat, timestamped cue list
+// that the client timer and audio cue generation consume.
+package timeline
+
+import (
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+)
+
+// ExerciseOverride adjusts a single exercise for one student
+type ExerciseOverride struct {
+	DurationSeconds     *int
+	SideDurationSeconds *int
+	RestAfterSeconds    *int
+	Skip                bool
+	// SubstituteID replaces the exercise with one of its substitutes
+	SubstituteID *uuid.UUID
+}
+
+// Options are per-user settings applied while compiling a timeline
+type Options struct {
+	HalfwayCues bool
+	Exercises   map[uuid.UUID]ExerciseOverride
+}
+
+// DefaultOptions returns the options used when a student has no custom settings
+func DefaultOptions() Options {
+	return Options{
+		HalfwayCues: true,
+		Exercises:   make(map[uuid.UUID]ExerciseOverride),
+	}
+}
+
+// OptionsFromSettings reads timeline options from user_programs.custom_settings.
+// Recognised keys:
+//
+//	"halfway_cues": false
+//	"exercise_overrides": {"<exercise id>": {"duration_seconds": 90, "side_duration_seconds": 45, "rest_after_seconds": 10, "skip": true, "substitute_id": "<substitute id>"}}
+//
+// Unknown keys and malformed values are ignored.
+func OptionsFromSettings(settings map[string]interface{}) Options {
+	opts := DefaultOptions()
+	if settings == nil {
+		return opts
+	}
+
+	if halfway, ok := settings["halfway_cues"].(bool); ok {
+		opts.HalfwayCues = halfway
+	}
+
+	overrides, ok := settings["exercise_overrides"].(map[string]interface{})
+	if !ok {
+		return opts
+	}
+	for key, raw := range overrides {
+		id, err := uuid.Parse(key)
+		if err != nil {
+			continue
+		}
+		values, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var override ExerciseOverride
+		override.DurationSeconds = positiveInt(values["duration_seconds"])
+		override.SideDurationSeconds = positiveInt(values["side_duration_seconds"])
+		if rest, ok := values["rest_after_seconds"].(float64); ok && rest >= 0 {
+			r := int(rest)
+			override.RestAfterSeconds = &r
+		}
+		if skip, ok := values["skip"].(bool); ok {
+			override.Skip = skip
+		}
+		if raw, ok := values["substitute_id"].(string); ok {
+			if substituteID, err := uuid.Parse(raw); err == nil {
+				override.SubstituteID = &substituteID
+			}
+		}
+		opts.Exercises[id] = override
+	}
+
+	return opts
+}
+
+// positiveInt converts a decoded JSON number to an int pointer, ignoring non-positive values
+func positiveInt(v interface{}) *int {
+	f, ok := v.(float64)
+	if !ok || f <= 0 {
+		return nil
+	}
+	i := int(f)
+	return &i
+}
+
+// Build compiles exercises (in order_index order) into a timeline.
+// Prep time of a paced exercise comes before its start cue; no rest is scheduled after the last exercise.
+func Build(programID uuid.UUID, exercises []models.Exercise, opts Options) *models.Timeline {
+	active := make([]models.Exercise, 0, len(exercises))
+	for _, ex := range exercises {
+		if opts.Exercises[ex.ID].Skip {
+			continue
+		}
+		override := opts.Exercises[ex.ID]
+		active = append(active, applyOverride(applySubstitute(ex, override), override))
+	}
+
+	cues := make([]models.Cue, 0, len(active)*4+1)
+	at := 0
+	for i, ex := range active {
+		exerciseID := ex.ID
+		base := models.Cue{
+			ExerciseID:   &exerciseID,
+			ExerciseName: ex.Name,
+		}
+		if substitute := substituteFor(ex, opts.Exercises[ex.ID]); substitute != nil {
+			base.SubstituteID = &substitute.ID
+		}
+
+		if ex.Tempo != nil && ex.Tempo.PrepSeconds > 0 {
+			prep := base
+			prep.AtSeconds = at
+			prep.Type = models.CuePrepStart
+			prep.DurationSeconds = ex.Tempo.PrepSeconds
+			cues = append(cues, prep)
+			at += ex.Tempo.PrepSeconds
+		}
+
+		duration := exerciseDuration(ex)
+		start := base
+		start.AtSeconds = at
+		start.Type = models.CueExerciseStart
+		start.DurationSeconds = duration
+		start.Repetitions = ex.Repetitions
+		start.AwaitsCompletion = duration == 0
+		if ex.Tempo != nil {
+			start.SecondsPerRep = ex.Tempo.SecondsPerRep
+			start.TransitionSeconds = ex.Tempo.TransitionSeconds
+		}
+		start.BreathingPattern = ex.BreathingPattern
+
+		if ex.HasSides && ex.SideDurationSeconds != nil && *ex.SideDurationSeconds > 0 {
+			side := *ex.SideDurationSeconds
+			start.Side = "left"
+			cues = append(cues, start)
+
+			switchCue := base
+			switchCue.AtSeconds = at + side
+			switchCue.Type = models.CueSideSwitch
+			switchCue.Side = "right"
+			switchCue.DurationSeconds = side
+			cues = append(cues, switchCue)
+		} else {
+			cues = append(cues, start)
+			if opts.HalfwayCues && duration >= 2 {
+				halfway := base
+				halfway.AtSeconds = at + duration/2
+				halfway.Type = models.CueHalfway
+				cues = append(cues, halfway)
+			}
+		}
+
+		at += duration
+		end := base
+		end.AtSeconds = at
+		end.Type = models.CueExerciseEnd
+		cues = append(cues, end)
+
+		if i < len(active)-1 && ex.RestAfterSeconds > 0 {
+			cues = append(cues, models.Cue{
+				AtSeconds:       at,
+				Type:            models.CueRestStart,
+				DurationSeconds: ex.RestAfterSeconds,
+			})
+			at += ex.RestAfterSeconds
+			cues = append(cues, models.Cue{
+				AtSeconds: at,
+				Type:      models.CueRestEnd,
+			})
+		}
+	}
+
+	cues = append(cues, models.Cue{
+		AtSeconds: at,
+		Type:      models.CueSessionEnd,
+	})
+
+	return &models.Timeline{
+		ProgramID:            programID,
+		TotalDurationSeconds: at,
+		Cues:                 cues,
+	}
+}
+
+// substituteFor returns the exercise's substitute chosen in the override, if it still exists
+func substituteFor(ex models.Exercise, override ExerciseOverride) *models.ExerciseSubstitute {
+	if override.SubstituteID == nil {
+		return nil
+	}
+	for i := range ex.Substitutes {
+		if ex.Substitutes[i].ID == *override.SubstituteID {
+			return &ex.Substitutes[i]
+		}
+	}
+	return nil
+}
+
+// applySubstitute puts the chosen substitute in the exercise's place, keeping the exercise's
+// timing where the substitute sets none
+func applySubstitute(ex models.Exercise, override ExerciseOverride) models.Exercise {
+	substitute := substituteFor(ex, override)
+	if substitute == nil {
+		return ex
+	}
+	ex.Name = substitute.Name
+	if substitute.DurationSeconds != nil {
+		ex.DurationSeconds = substitute.DurationSeconds
+	}
+	if substitute.Repetitions != nil {
+		ex.Repetitions = substitute.Repetitions
+	}
+	if substitute.SideDurationSeconds != nil {
+		ex.SideDurationSeconds = substitute.SideDurationSeconds
+	}
+	return ex
+}
+
+func applyOverride(ex models.Exercise, override ExerciseOverride) models.Exercise {
+	if override.DurationSeconds != nil {
+		ex.DurationSeconds = override.DurationSeconds
+	}
+	if override.SideDurationSeconds != nil {
+		ex.SideDurationSeconds = override.SideDurationSeconds
+	}
+	if override.RestAfterSeconds != nil {
+		ex.RestAfterSeconds = *override.RestAfterSeconds
+	}
+	return ex
+}
+
+// exerciseDuration returns the timed length of an exercise, or 0 if it is untimed.
+// A paced repetition exercise lasts its repetitions plus the transitions between them; an
+// exercise defined by breath counts lasts its breathing cycles.
+func exerciseDuration(ex models.Exercise) int {
+	if ex.HasSides && ex.SideDurationSeconds != nil && *ex.SideDurationSeconds > 0 {
+		return *ex.SideDurationSeconds * 2
+	}
+	if ex.DurationSeconds != nil && *ex.DurationSeconds > 0 {
+		return *ex.DurationSeconds
+	}
+	if ex.Tempo != nil && ex.Tempo.SecondsPerRep > 0 && ex.Repetitions != nil && *ex.Repetitions > 0 {
+		reps := *ex.Repetitions
+		return reps*ex.Tempo.SecondsPerRep + (reps-1)*ex.Tempo.TransitionSeconds
+	}
+	if ex.BreathingPattern != nil && ex.BreathingPattern.Cycles > 0 {
+		return ex.BreathingPattern.Cycles * ex.BreathingPattern.CycleSeconds()
+	}
+	return 0
+}
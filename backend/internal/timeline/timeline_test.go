@@ -0,0 +1,190 @@
+package timeline
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/models"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func cueTypes(tl *models.Timeline) []models.CueType {
+	types := make([]models.CueType, len(tl.Cues))
+	for i, cue := range tl.Cues {
+		types[i] = cue.Type
+	}
+	return types
+}
+
+func TestBuild(t *testing.T) {
+	standing := models.Exercise{
+		ID:               uuid.New(),
+		Name:             "Zhan Zhuang",
+		ExerciseType:     models.ExerciseTypeTimed,
+		DurationSeconds:  intPtr(60),
+		RestAfterSeconds: 10,
+	}
+	sided := models.Exercise{
+		ID:                  uuid.New(),
+		Name:                "Single Whip",
+		ExerciseType:        models.ExerciseTypeTimed,
+		HasSides:            true,
+		SideDurationSeconds: intPtr(30),
+		RestAfterSeconds:    15,
+	}
+	reps := models.Exercise{
+		ID:           uuid.New(),
+		Name:         "Silk Reeling",
+		ExerciseType: models.ExerciseTypeRepetition,
+		Repetitions:  intPtr(12),
+	}
+
+	t.Run("timed_sided_and_untimed_exercises", func(t *testing.T) {
+		tl := Build(uuid.New(), []models.Exercise{standing, sided, reps}, DefaultOptions())
+
+		want := []models.CueType{
+			models.CueExerciseStart, models.CueHalfway, models.CueExerciseEnd,
+			models.CueRestStart, models.CueRestEnd,
+			models.CueExerciseStart, models.CueSideSwitch, models.CueExerciseEnd,
+			models.CueRestStart, models.CueRestEnd,
+			models.CueExerciseStart, models.CueExerciseEnd,
+			models.CueSessionEnd,
+		}
+		got := cueTypes(tl)
+		if len(got) != len(want) {
+			t.Fatalf("got %d cues %v, want %d", len(got), got, len(want))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("cue %d: got %s, want %s", i, got[i], want[i])
+			}
+		}
+
+		// 60 + 10 rest + 2*30 + 15 rest + untimed
+		if tl.TotalDurationSeconds != 145 {
+			t.Errorf("TotalDurationSeconds = %d, want 145", tl.TotalDurationSeconds)
+		}
+		if tl.Cues[1].AtSeconds != 30 {
+			t.Errorf("halfway cue at %d, want 30", tl.Cues[1].AtSeconds)
+		}
+		if tl.Cues[6].AtSeconds != 100 {
+			t.Errorf("side switch at %d, want 100", tl.Cues[6].AtSeconds)
+		}
+		if !tl.Cues[10].AwaitsCompletion {
+			t.Error("repetition exercise should await completion")
+		}
+	})
+
+	t.Run("no_rest_after_last_exercise", func(t *testing.T) {
+		tl := Build(uuid.New(), []models.Exercise{standing}, DefaultOptions())
+		if tl.TotalDurationSeconds != 60 {
+			t.Errorf("TotalDurationSeconds = %d, want 60", tl.TotalDurationSeconds)
+		}
+	})
+
+	t.Run("user_overrides", func(t *testing.T) {
+		settings := map[string]interface{}{
+			"halfway_cues": false,
+			"exercise_overrides": map[string]interface{}{
+				standing.ID.String(): map[string]interface{}{"duration_seconds": float64(120), "rest_after_seconds": float64(0)},
+				sided.ID.String():    map[string]interface{}{"skip": true},
+			},
+		}
+		tl := Build(uuid.New(), []models.Exercise{standing, sided, reps}, OptionsFromSettings(settings))
+
+		for _, cue := range tl.Cues {
+			if cue.Type == models.CueHalfway || cue.Type == models.CueRestStart {
+				t.Errorf("unexpected %s cue", cue.Type)
+			}
+			if cue.ExerciseID != nil && *cue.ExerciseID == sided.ID {
+				t.Error("skipped exercise should not appear on the timeline")
+			}
+		}
+		if tl.TotalDurationSeconds != 120 {
+			t.Errorf("TotalDurationSeconds = %d, want 120", tl.TotalDurationSeconds)
+		}
+	})
+
+	t.Run("substitute", func(t *testing.T) {
+		seated := models.ExerciseSubstitute{ID: uuid.New(), ExerciseID: standing.ID, Name: "Seated Meditation", DurationSeconds: intPtr(90)}
+		withSubstitute := standing
+		withSubstitute.Substitutes = []models.ExerciseSubstitute{seated}
+		settings := map[string]interface{}{
+			"exercise_overrides": map[string]interface{}{
+				standing.ID.String(): map[string]interface{}{"substitute_id": seated.ID.String()},
+			},
+		}
+		tl := Build(uuid.New(), []models.Exercise{withSubstitute}, OptionsFromSettings(settings))
+
+		start := tl.Cues[0]
+		if start.ExerciseName != "Seated Meditation" || start.SubstituteID == nil || *start.SubstituteID != seated.ID || *start.ExerciseID != standing.ID {
+			t.Errorf("start cue = %+v, want the seated substitute of the exercise", start)
+		}
+		if tl.TotalDurationSeconds != 90 {
+			t.Errorf("TotalDurationSeconds = %d, want 90", tl.TotalDurationSeconds)
+		}
+
+		// A substitute that no longer exists is ignored
+		tl = Build(uuid.New(), []models.Exercise{standing}, OptionsFromSettings(settings))
+		if tl.Cues[0].SubstituteID != nil || tl.TotalDurationSeconds != 60 {
+			t.Errorf("timeline = %+v, want the exercise itself", tl)
+		}
+	})
+
+	t.Run("paced_repetitions", func(t *testing.T) {
+		paced := reps
+		paced.Tempo = &models.ExerciseTempo{SecondsPerRep: 8, PrepSeconds: 10, TransitionSeconds: 2}
+		tl := Build(uuid.New(), []models.Exercise{standing, paced}, OptionsFromSettings(map[string]interface{}{"halfway_cues": false}))
+
+		want := []models.CueType{
+			models.CueExerciseStart, models.CueExerciseEnd,
+			models.CueRestStart, models.CueRestEnd,
+			models.CuePrepStart, models.CueExerciseStart, models.CueExerciseEnd,
+			models.CueSessionEnd,
+		}
+		got := cueTypes(tl)
+		if len(got) != len(want) {
+			t.Fatalf("got %d cues %v, want %d", len(got), got, len(want))
+		}
+		// 60 + 10 rest + 10 prep + 12 reps of 8s with 11 transitions of 2s
+		if tl.TotalDurationSeconds != 198 {
+			t.Errorf("TotalDurationSeconds = %d, want 198", tl.TotalDurationSeconds)
+		}
+		prep, start := tl.Cues[4], tl.Cues[5]
+		if prep.AtSeconds != 70 || prep.DurationSeconds != 10 {
+			t.Errorf("prep cue = %+v, want 10s at 70", prep)
+		}
+		if start.AtSeconds != 80 || start.DurationSeconds != 118 || start.AwaitsCompletion ||
+			start.SecondsPerRep != 8 || start.TransitionSeconds != 2 {
+			t.Errorf("start cue = %+v, want a paced 118s exercise at 80", start)
+		}
+	})
+
+	t.Run("breathing_pattern", func(t *testing.T) {
+		breathing := models.Exercise{
+			ID:               uuid.New(),
+			Name:             "Embracing the Tree",
+			ExerciseType:     models.ExerciseTypeRepetition,
+			BreathingPattern: &models.BreathingPattern{InhaleSeconds: 4, HoldSeconds: 2, ExhaleSeconds: 6, Cycles: 9},
+		}
+		tl := Build(uuid.New(), []models.Exercise{breathing}, DefaultOptions())
+
+		// 9 breaths of 4 + 2 + 6 seconds
+		if tl.TotalDurationSeconds != 108 {
+			t.Errorf("TotalDurationSeconds = %d, want 108", tl.TotalDurationSeconds)
+		}
+		start := tl.Cues[0]
+		if start.AwaitsCompletion || start.BreathingPattern == nil || start.BreathingPattern.Cycles != 9 {
+			t.Errorf("start cue = %+v, want a timed exercise with its breathing pattern", start)
+		}
+
+		// A set duration wins over the breath count
+		breathing.DurationSeconds = intPtr(60)
+		if tl := Build(uuid.New(), []models.Exercise{breathing}, DefaultOptions()); tl.TotalDurationSeconds != 60 {
+			t.Errorf("TotalDurationSeconds = %d, want 60", tl.TotalDurationSeconds)
+		}
+	})
+}
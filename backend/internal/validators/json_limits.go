@@ -0,0 +1,93 @@
+package validators
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// JSONLimits bounds free-form JSON objects such as metadata and device_info
+type JSONLimits struct {
+	MaxBytes int // encoded size
+	MaxKeys  int // keys across all nested objects
+	MaxDepth int // nesting of objects and arrays; a flat object has depth 1
+}
+
+// DefaultJSONLimits apply to every field tagged with `jsonlimits`
+var DefaultJSONLimits = JSONLimits{
+	MaxBytes: 16 << 10,
+	MaxKeys:  200,
+	MaxDepth: 5,
+}
+
+// New returns a validator with the repo's custom tags registered
+func New() *validator.Validate {
+	v := validator.New()
+	_ = v.RegisterValidation("jsonlimits", func(fl validator.FieldLevel) bool {
+		return CheckJSONLimits(fl.Field().Interface(), DefaultJSONLimits) == nil
+	})
+	_ = v.RegisterValidation("joint", func(fl validator.FieldLevel) bool {
+		return contains(Joints, fl.Field().String())
+	})
+	_ = v.RegisterValidation("condition", func(fl validator.FieldLevel) bool {
+		return contains(Conditions, fl.Field().String())
+	})
+	_ = v.RegisterValidation("limitation", func(fl validator.FieldLevel) bool {
+		return contains(Joints, fl.Field().String()) || contains(Conditions, fl.Field().String())
+	})
+	return v
+}
+
+// CheckJSONLimits reports the first limit value exceeds, or nil if it is within bounds
+func CheckJSONLimits(value interface{}, limits JSONLimits) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("must be valid JSON: %w", err)
+	}
+	if len(encoded) > limits.MaxBytes {
+		return fmt.Errorf("must be at most %d bytes when encoded, got %d", limits.MaxBytes, len(encoded))
+	}
+
+	// Walk the decoded form so maps of any Go type are measured the same way
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return fmt.Errorf("must be valid JSON: %w", err)
+	}
+	keys, depth := measure(decoded)
+	if keys > limits.MaxKeys {
+		return fmt.Errorf("must have at most %d keys, got %d", limits.MaxKeys, keys)
+	}
+	if depth > limits.MaxDepth {
+		return fmt.Errorf("must be nested at most %d levels deep, got %d", limits.MaxDepth, depth)
+	}
+	return nil
+}
+
+// measure returns the total key count and nesting depth of a decoded JSON value
+func measure(value interface{}) (keys, depth int) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		maxChild := 0
+		for _, child := range v {
+			childKeys, childDepth := measure(child)
+			keys += childKeys
+			if childDepth > maxChild {
+				maxChild = childDepth
+			}
+		}
+		return keys + len(v), maxChild + 1
+	case []interface{}:
+		maxChild := 0
+		for _, child := range v {
+			childKeys, childDepth := measure(child)
+			keys += childKeys
+			if childDepth > maxChild {
+				maxChild = childDepth
+			}
+		}
+		return keys, maxChild + 1
+	default:
+		return 0, 0
+	}
+}
@@ -0,0 +1,63 @@
+package validators
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckJSONLimits(t *testing.T) {
+	limits := JSONLimits{MaxBytes: 200, MaxKeys: 5, MaxDepth: 3}
+
+	nested := func(levels int) map[string]interface{} {
+		m := map[string]interface{}{"leaf": true}
+		for i := 1; i < levels; i++ {
+			m = map[string]interface{}{"n": m}
+		}
+		return m
+	}
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		wantErr string
+	}{
+		{"nil map", map[string]interface{}(nil), ""},
+		{"flat", map[string]interface{}{"a": 1, "b": "two"}, ""},
+		{"at depth limit", nested(3), ""},
+		{"too deep", nested(4), "levels deep"},
+		{"arrays count as levels", map[string]interface{}{"a": []interface{}{[]interface{}{1}}}, ""},
+		{"arrays too deep", map[string]interface{}{"a": []interface{}{[]interface{}{map[string]interface{}{}}}}, "levels deep"},
+		{"too many keys", map[string]interface{}{"a": 1, "b": 2, "c": 3, "d": map[string]interface{}{"e": 1, "f": 2}}, "at most 5 keys"},
+		{"too large", map[string]interface{}{"a": strings.Repeat("x", 300)}, "at most 200 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckJSONLimits(tt.value, limits)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestJSONLimitsTag(t *testing.T) {
+	type request struct {
+		Metadata map[string]interface{} `validate:"omitempty,jsonlimits"`
+	}
+
+	v := New()
+	if err := v.Struct(request{Metadata: map[string]interface{}{"ok": true}}); err != nil {
+		t.Errorf("expected small metadata to pass: %v", err)
+	}
+	big := map[string]interface{}{"blob": strings.Repeat("x", DefaultJSONLimits.MaxBytes)}
+	if err := v.Struct(request{Metadata: big}); err == nil {
+		t.Error("expected oversized metadata to fail")
+	}
+}
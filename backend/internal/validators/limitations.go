@@ -0,0 +1,17 @@
+package validators
+
+// Joints and Conditions are the limitations students can record in their profile. Exercises and
+// substitutes list the ones they are contraindicated for.
+var (
+	Joints     = []string{"neck", "shoulders", "elbows", "wrists", "back", "hips", "knees", "ankles"}
+	Conditions = []string{"hypertension", "heart_condition", "pregnancy", "vertigo", "asthma", "osteoporosis"}
+)
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package validators
+
+import "testing"
+
+func TestLimitationTags(t *testing.T) {
+	v := New()
+
+	tests := []struct {
+		name  string
+		req   interface{}
+		valid bool
+	}{
+		{"empty profile", UpdateLimitationsRequest{}, true},
+		{"known joints and conditions", UpdateLimitationsRequest{Joints: []string{"knees", "wrists"}, Conditions: []string{"hypertension"}}, true},
+		{"condition as joint", UpdateLimitationsRequest{Joints: []string{"hypertension"}}, false},
+		{"unknown joint", UpdateLimitationsRequest{Joints: []string{"toes"}}, false},
+		{"contraindications mix both", ExerciseSubstituteRequest{Name: "Seated", Contraindications: []string{"knees", "pregnancy"}}, true},
+		{"unknown contraindication", ExerciseSubstituteRequest{Name: "Seated", Contraindications: []string{"bad_knees"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Struct(tt.req)
+			if (err == nil) != tt.valid {
+				t.Errorf("Struct() error = %v, want valid = %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -1,10 +1,14 @@
 package validators
 
+import "github.com/xuangong/backend/pkg/timestamp"
+
 // Auth requests
 type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
 	FullName string `json:"full_name" validate:"required,min=2"`
+	// InviteToken registers through an invitation, applying its role, group and programs
+	InviteToken string `json:"invite_token"`
 }
 
 // User management requests (admin only)
@@ -35,6 +39,11 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+// RevokeLoginDeviceRequest carries the token of a new device alert's "this wasn't me" link
+type RevokeLoginDeviceRequest struct {
+	Token string `json:"token" validate:"required,max=100"`
+}
+
 // Profile management requests (user self-service)
 type UpdateProfileRequest struct {
 	Email           *string `json:"email" validate:"omitempty,email"`
@@ -43,6 +52,7 @@ type UpdateProfileRequest struct {
 	StartVolume     *int    `json:"start_volume" validate:"omitempty,oneof=0 25 50 75 100"`
 	HalfwayVolume   *int    `json:"halfway_volume" validate:"omitempty,oneof=0 25 50 75 100"`
 	FinishVolume    *int    `json:"finish_volume" validate:"omitempty,oneof=0 25 50 75 100"`
+	Language        *string `json:"language" validate:"omitempty,oneof=en de zh"`
 }
 
 type ChangePasswordRequest struct {
@@ -50,6 +60,77 @@ type ChangePasswordRequest struct {
 	NewPassword     string `json:"new_password" validate:"required,min=8"`
 }
 
+// UpdateLimitationsRequest replaces the student's injury and health profile
+type UpdateLimitationsRequest struct {
+	Joints     []string `json:"joints" validate:"omitempty,max=8,dive,joint"`
+	Conditions []string `json:"conditions" validate:"omitempty,max=6,dive,condition"`
+	Notes      string   `json:"notes" validate:"max=2000"`
+}
+
+// ReviewPlanRequest records an instructor's review of a student's adjusted program
+type ReviewPlanRequest struct {
+	Status string `json:"status" validate:"required,oneof=approved changes_requested"`
+	Note   string `json:"note" validate:"omitempty,max=5000"`
+}
+
+// Progress journal requests
+type ListJournalQuery struct {
+	ProgramID *string `form:"program_id" validate:"omitempty,uuid"`
+	Limit     int     `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset    int     `form:"offset" validate:"omitempty,gte=0"`
+}
+
+type ListSharedJournalQuery struct {
+	UserID *string `form:"user_id" validate:"omitempty,uuid"` // Only entries of this student
+	Limit  int     `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset int     `form:"offset" validate:"omitempty,gte=0"`
+}
+
+// UploadJournalEntryRequest holds the form fields sent along with the journal media file
+type UploadJournalEntryRequest struct {
+	ProgramID  string          `form:"program_id" validate:"required,uuid"`
+	ExerciseID *string         `form:"exercise_id" validate:"omitempty,uuid"`
+	Caption    string          `form:"caption" validate:"max=2000"`
+	RecordedAt *timestamp.Time `form:"recorded_at"` // Defaults to now
+}
+
+// RequestJournalShareRequest asks a student to share form-check media for a program
+type RequestJournalShareRequest struct {
+	ProgramID string `json:"program_id" validate:"required,uuid"`
+	Message   string `json:"message" validate:"max=2000"`
+}
+
+// Practice diary requests
+type CreateDiaryEntryRequest struct {
+	EntryDate  string   `json:"entry_date" validate:"omitempty,datetime=2006-01-02"` // Defaults to today
+	Title      string   `json:"title" validate:"max=255"`
+	Content    string   `json:"content" validate:"required,max=20000"` // Markdown
+	SessionIDs []string `json:"session_ids" validate:"max=10,dive,uuid"`
+	Shared     bool     `json:"shared"` // Readable by instructors
+}
+
+type UpdateDiaryEntryRequest struct {
+	EntryDate  *string   `json:"entry_date" validate:"omitempty,datetime=2006-01-02"`
+	Title      *string   `json:"title" validate:"omitempty,max=255"`
+	Content    *string   `json:"content" validate:"omitempty,min=1,max=20000"`
+	SessionIDs *[]string `json:"session_ids" validate:"omitempty,max=10,dive,uuid"` // Replaces the linked sessions
+	Shared     *bool     `json:"shared"`
+}
+
+type ListDiaryQuery struct {
+	From      *string `form:"from" validate:"omitempty,datetime=2006-01-02"`
+	To        *string `form:"to" validate:"omitempty,datetime=2006-01-02"`
+	SessionID *string `form:"session_id" validate:"omitempty,uuid"`
+	Limit     int     `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset    int     `form:"offset" validate:"omitempty,gte=0"`
+}
+
+type SearchDiaryQuery struct {
+	Q      string `form:"q" validate:"required,min=2,max=200"`
+	Limit  int    `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset int    `form:"offset" validate:"omitempty,gte=0"`
+}
+
 // Program requests
 type CreateProgramRequest struct {
 	Name               string                 `json:"name" validate:"required,min=3,max=255"`
@@ -57,9 +138,12 @@ type CreateProgramRequest struct {
 	IsTemplate         bool                   `json:"is_template"`
 	IsPublic           bool                   `json:"is_public"`
 	Tags               []string               `json:"tags"`
-	Metadata           map[string]interface{} `json:"metadata"`
+	Metadata           map[string]interface{} `json:"metadata" validate:"omitempty,jsonlimits"`
 	RepetitionsPlanned *int                   `json:"repetitions_planned" validate:"omitempty,gte=1"`
-	OwnedByUserID      *string                `json:"owned_by_user_id" validate:"omitempty,uuid"` // Admin can specify owner
+	PublishAt          *timestamp.Time        `json:"publish_at"`                                         // Make public automatically at this time
+	UnpublishAt        *timestamp.Time        `json:"unpublish_at"`                                       // Stop being public at this time
+	OwnedByUserID      *string                `json:"owned_by_user_id" validate:"omitempty,uuid"`         // Admin can specify owner
+	OnDuplicate        string                 `json:"on_duplicate" validate:"omitempty,oneof=warn merge"` // warn (default) or merge into an exact duplicate
 	Exercises          []ExerciseRequest      `json:"exercises" validate:"dive"`
 }
 
@@ -68,17 +152,284 @@ type CreateSubmissionRequest struct {
 	Title string `json:"title" validate:"required,min=3,max=255"`
 }
 
+// SetSubmissionTemplateRequest replaces a program's submission template. The title pattern is a
+// Go regular expression the whole title must match.
+type SetSubmissionTemplateRequest struct {
+	TitlePattern *string  `json:"title_pattern" validate:"omitempty,min=1,max=500"`
+	TitleExample *string  `json:"title_example" validate:"omitempty,min=3,max=255"`
+	Prompts      []string `json:"prompts" validate:"max=20,dive,required,max=500"`
+}
+
 type CreateMessageRequest struct {
-	Content    string  `json:"content" validate:"required,min=1"`
+	Content    string  `json:"content" validate:"required_without=SnippetID"`
 	YouTubeURL *string `json:"youtube_url" validate:"omitempty,url"`
+	SnippetID  *string `json:"snippet_id" validate:"omitempty,uuid"` // Appended to content, placeholders filled in
 }
 
 type ListSubmissionsQuery struct {
 	ProgramID *string `form:"program_id" validate:"omitempty,uuid"`
+	LabelID   *string `form:"label_id" validate:"omitempty,uuid"` // Instructors only
+	Archived  bool    `form:"archived"`                           // List the threads the user archived instead
 	Limit     int     `form:"limit" validate:"omitempty,gte=1,lte=100"`
 	Offset    int     `form:"offset" validate:"omitempty,gte=0"`
 }
 
+// SaveDraftRequest holds an unsent message. The YouTube URL is only checked when the message is posted.
+type SaveDraftRequest struct {
+	Content    string  `json:"content" validate:"max=20000"`
+	YouTubeURL *string `json:"youtube_url" validate:"omitempty,max=500"`
+}
+
+// TypingRequest starts or stops the user's typing indicator in a submission
+type TypingRequest struct {
+	State string `json:"state" validate:"required,oneof=start stop"`
+}
+
+type SearchSubmissionsQuery struct {
+	Q         string  `form:"q" validate:"required,min=2,max=200"`
+	ProgramID *string `form:"program_id" validate:"omitempty,uuid"`
+	Limit     int     `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset    int     `form:"offset" validate:"omitempty,gte=0"`
+}
+
+type ExportSubmissionQuery struct {
+	Format string `form:"format" validate:"oneof=md pdf"`
+}
+
+// ScheduleMessageRequest queues a submission message (submission_id) or a program
+// announcement (program_id and title) for delivery at send_at
+type ScheduleMessageRequest struct {
+	SubmissionID *string         `json:"submission_id" validate:"required_without=ProgramID,excluded_with=ProgramID,omitempty,uuid"`
+	ProgramID    *string         `json:"program_id" validate:"omitempty,uuid"`
+	Title        *string         `json:"title" validate:"required_with=ProgramID,omitempty,min=1,max=255"`
+	Content      string          `json:"content" validate:"required,max=20000"`
+	YouTubeURL   *string         `json:"youtube_url" validate:"omitempty,url"`
+	SendAt       *timestamp.Time `json:"send_at" validate:"required"`
+}
+
+type ListScheduledMessagesQuery struct {
+	Status string `form:"status" validate:"oneof=pending sent cancelled failed"`
+}
+
+// Discussion board requests
+type ListTopicsQuery struct {
+	Limit  int `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset int `form:"offset" validate:"omitempty,gte=0"`
+}
+
+type CreateTopicRequest struct {
+	Title      string  `json:"title" validate:"required,min=1,max=255"`
+	Content    string  `json:"content" validate:"required,max=20000"`
+	YouTubeURL *string `json:"youtube_url" validate:"omitempty,url"`
+}
+
+type CreateReplyRequest struct {
+	Content    string  `json:"content" validate:"required,max=20000"`
+	YouTubeURL *string `json:"youtube_url" validate:"omitempty,url"`
+}
+
+type ModerateTopicRequest struct {
+	IsPinned *bool `json:"is_pinned"`
+	IsLocked *bool `json:"is_locked"`
+}
+
+// Course requests
+type CourseModuleRequest struct {
+	Title           string                 `json:"title" validate:"required,min=1,max=255"`
+	Description     *string                `json:"description" validate:"omitempty,max=5000"`
+	UnlockRule      string                 `json:"unlock_rule" validate:"omitempty,oneof=immediate after_previous after_days"` // Defaults to immediate
+	UnlockAfterDays *int                   `json:"unlock_after_days" validate:"required_if=UnlockRule after_days,omitempty,min=0,max=3650"`
+	Programs        []ModuleProgramRequest `json:"programs" validate:"required,min=1,max=50,dive"`
+}
+
+type ModuleProgramRequest struct {
+	ProgramID        string `json:"program_id" validate:"required,uuid"`
+	RequiredSessions int    `json:"required_sessions" validate:"omitempty,min=1,max=1000"` // Defaults to 1
+}
+
+type CreateCourseRequest struct {
+	Name        string                `json:"name" validate:"required,min=3,max=255"`
+	Description *string               `json:"description" validate:"omitempty,max=5000"`
+	IsPublished bool                  `json:"is_published"`
+	Modules     []CourseModuleRequest `json:"modules" validate:"required,min=1,max=50,dive"`
+}
+
+// UpdateCourseRequest changes course details; Modules, if given, replaces the whole curriculum
+type UpdateCourseRequest struct {
+	Name        *string                `json:"name" validate:"omitempty,min=3,max=255"`
+	Description *string                `json:"description" validate:"omitempty,max=5000"`
+	IsPublished *bool                  `json:"is_published"`
+	Modules     *[]CourseModuleRequest `json:"modules" validate:"omitempty,min=1,max=50,dive"`
+}
+
+type EnrollUsersRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required,min=1,max=1000,dive,uuid"`
+}
+
+type CourseProgressQuery struct {
+	UserID *string `form:"user_id" validate:"omitempty,uuid"` // Admins only, defaults to the current user
+}
+
+// Quiz requests
+type QuizChoiceRequest struct {
+	Text      string `json:"text" validate:"required,max=1000"`
+	IsCorrect bool   `json:"is_correct"`
+}
+
+type QuizQuestionRequest struct {
+	Prompt      string              `json:"prompt" validate:"required,max=2000"`
+	Explanation *string             `json:"explanation" validate:"omitempty,max=5000"` // Shown after answering
+	Choices     []QuizChoiceRequest `json:"choices" validate:"required,min=2,max=10,dive"`
+}
+
+type CreateQuizRequest struct {
+	Title         string                `json:"title" validate:"required,min=3,max=255"`
+	Description   *string               `json:"description" validate:"omitempty,max=5000"`
+	PassThreshold *int                  `json:"pass_threshold" validate:"omitempty,min=0,max=100"` // Percent, defaults to 70
+	MaxAttempts   *int                  `json:"max_attempts" validate:"omitempty,min=1,max=100"`   // Unlimited if omitted
+	Questions     []QuizQuestionRequest `json:"questions" validate:"required,min=1,max=100,dive"`
+}
+
+// UpdateQuizRequest changes quiz settings; Questions, if given, replaces all questions
+type UpdateQuizRequest struct {
+	Title         *string                `json:"title" validate:"omitempty,min=3,max=255"`
+	Description   *string                `json:"description" validate:"omitempty,max=5000"`
+	PassThreshold *int                   `json:"pass_threshold" validate:"omitempty,min=0,max=100"`
+	MaxAttempts   *int                   `json:"max_attempts" validate:"omitempty,min=0,max=100"` // 0 removes the limit
+	Questions     *[]QuizQuestionRequest `json:"questions" validate:"omitempty,min=1,max=100,dive"`
+}
+
+type QuizAnswerRequest struct {
+	QuestionID string   `json:"question_id" validate:"required,uuid"`
+	ChoiceIDs  []string `json:"choice_ids" validate:"max=10,dive,uuid"`
+}
+
+type SubmitQuizRequest struct {
+	Answers []QuizAnswerRequest `json:"answers" validate:"required,max=100,dive"`
+}
+
+type ListQuizAttemptsQuery struct {
+	UserID *string `form:"user_id" validate:"omitempty,uuid"` // Admins only, defaults to the current user
+}
+
+// Homework requests
+type CreateHomeworkRequest struct {
+	ProgramID        string          `json:"program_id" validate:"required,uuid"`
+	GroupID          *string         `json:"group_id" validate:"omitempty,uuid"` // Defaults to every student assigned to the program
+	Title            string          `json:"title" validate:"required,min=1,max=255"`
+	Instructions     *string         `json:"instructions" validate:"omitempty,max=5000"`
+	Requirement      string          `json:"requirement" validate:"required,oneof=sessions submission"`
+	RequiredSessions int             `json:"required_sessions" validate:"omitempty,min=1,max=100"` // Sessions requirement only, defaults to 1
+	DueAt            *timestamp.Time `json:"due_at" validate:"required"`
+}
+
+type UpdateHomeworkRequest struct {
+	Title        *string         `json:"title" validate:"omitempty,min=1,max=255"`
+	Instructions *string         `json:"instructions" validate:"omitempty,max=5000"`
+	DueAt        *timestamp.Time `json:"due_at"`
+}
+
+type ListHomeworkQuery struct {
+	ProgramID *string `form:"program_id" validate:"omitempty,uuid"` // Admins only
+	GroupID   *string `form:"group_id" validate:"omitempty,uuid"`   // Admins only
+}
+
+// Live class requests
+type CreateLiveClassRequest struct {
+	Title       string          `json:"title" validate:"required,min=1,max=255"`
+	Description *string         `json:"description" validate:"omitempty,max=5000"`
+	Location    *string         `json:"location" validate:"omitempty,max=255"`
+	ProgramID   *string         `json:"program_id" validate:"omitempty,uuid"`
+	GroupID     *string         `json:"group_id" validate:"omitempty,uuid"` // Only members of the group see the class
+	StartsAt    *timestamp.Time `json:"starts_at" validate:"required"`
+	EndsAt      *timestamp.Time `json:"ends_at" validate:"required"`
+}
+
+type UpdateLiveClassRequest struct {
+	Title       *string         `json:"title" validate:"omitempty,min=1,max=255"`
+	Description *string         `json:"description" validate:"omitempty,max=5000"`
+	Location    *string         `json:"location" validate:"omitempty,max=255"`
+	ProgramID   *string         `json:"program_id" validate:"omitempty,uuid"`
+	GroupID     *string         `json:"group_id" validate:"omitempty,uuid"`
+	StartsAt    *timestamp.Time `json:"starts_at"`
+	EndsAt      *timestamp.Time `json:"ends_at"`
+}
+
+type ListLiveClassesQuery struct {
+	From    *timestamp.Time `form:"from"` // Classes still running or starting after
+	To      *timestamp.Time `form:"to"`   // Classes starting before
+	GroupID *string         `form:"group_id" validate:"omitempty,uuid"`
+}
+
+type CreateCheckInCodeRequest struct {
+	ValidMinutes int `json:"valid_minutes" validate:"omitempty,min=1,max=240"` // Defaults to 15
+}
+
+type CheckInRequest struct {
+	Code string `json:"code" validate:"required,min=4,max=12"`
+}
+
+type AttendanceEntryRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+	Status string `json:"status" validate:"required,oneof=present late excused absent"`
+}
+
+type MarkAttendanceRequest struct {
+	Entries []AttendanceEntryRequest `json:"entries" validate:"required,min=1,max=500,dive"`
+}
+
+// Office-hours booking requests
+type PublishSlotRequest struct {
+	StartsAt *timestamp.Time `json:"starts_at" validate:"required"`
+	EndsAt   *timestamp.Time `json:"ends_at" validate:"required"`
+}
+
+type ListSlotsQuery struct {
+	From         *timestamp.Time `form:"from"` // Defaults to now
+	To           *timestamp.Time `form:"to"`   // Defaults to two weeks after from
+	InstructorID *string         `form:"instructor_id" validate:"omitempty,uuid"`
+}
+
+type BookSlotRequest struct {
+	SlotID    string  `json:"slot_id" validate:"required,uuid"`
+	ProgramID string  `json:"program_id" validate:"required,uuid"`
+	Note      *string `json:"note" validate:"omitempty,max=2000"` // What the student wants reviewed
+}
+
+type ListBookingsQuery struct {
+	Upcoming bool `form:"upcoming"`
+}
+
+type CancelBookingRequest struct {
+	Reason *string `json:"reason" validate:"omitempty,max=1000"`
+}
+
+// Feedback snippet requests
+type CreateSnippetRequest struct {
+	Title string `json:"title" validate:"required,min=1,max=100"`
+	Body  string `json:"body" validate:"required,min=1,max=5000"`
+}
+
+type UpdateSnippetRequest struct {
+	Title *string `json:"title" validate:"omitempty,min=1,max=100"`
+	Body  *string `json:"body" validate:"omitempty,min=1,max=5000"`
+}
+
+type CreateSubmissionLabelRequest struct {
+	Name  string  `json:"name" validate:"required,min=1,max=50"`
+	Color *string `json:"color" validate:"omitempty,len=7,hexcolor"` // #rrggbb
+}
+
+type UpdateSubmissionLabelRequest struct {
+	Name  *string `json:"name" validate:"omitempty,min=1,max=50"`
+	Color *string `json:"color" validate:"omitempty,len=7,hexcolor"`
+}
+
+// SetSubmissionLabelsRequest replaces a submission's labels; an empty list removes them all
+type SetSubmissionLabelsRequest struct {
+	LabelIDs []string `json:"label_ids" validate:"max=20,unique,dive,uuid"`
+}
+
 type MarkMessageReadRequest struct {
 	MessageID string `json:"message_id" validate:"required,uuid"`
 }
@@ -89,56 +440,124 @@ type UpdateProgramRequest struct {
 	IsTemplate         *bool                  `json:"is_template"`
 	IsPublic           *bool                  `json:"is_public"`
 	Tags               []string               `json:"tags"`
-	Metadata           map[string]interface{} `json:"metadata"`
+	Metadata           map[string]interface{} `json:"metadata" validate:"omitempty,jsonlimits"`
 	RepetitionsPlanned *int                   `json:"repetitions_planned" validate:"omitempty,gte=1"`
+	PublishAt          *timestamp.Time        `json:"publish_at"`
+	UnpublishAt        *timestamp.Time        `json:"unpublish_at"`
 	Exercises          []ExerciseRequest      `json:"exercises" validate:"dive"`
 }
 
 // ExerciseRequest is used for exercises within program requests
 type ExerciseRequest struct {
-	ID                  string                 `json:"id" validate:"omitempty,uuid"`
-	Name                string                 `json:"name" validate:"required,min=3,max=255"`
-	Description         string                 `json:"description"`
-	OrderIndex          int                    `json:"order_index" validate:"gte=0"`
-	ExerciseType        string                 `json:"exercise_type" validate:"required,oneof=timed repetition combined"`
-	DurationSeconds     *int                   `json:"duration_seconds" validate:"omitempty,min=1"`
-	Repetitions         *int                   `json:"repetitions" validate:"omitempty,min=1"`
-	RestAfterSeconds    int                    `json:"rest_after_seconds" validate:"gte=0"`
-	HasSides            bool                   `json:"has_sides"`
-	SideDurationSeconds *int                   `json:"side_duration_seconds" validate:"omitempty,min=1"`
-	Metadata            map[string]interface{} `json:"metadata"`
+	ID                  string                   `json:"id" validate:"omitempty,uuid"`
+	Name                string                   `json:"name" validate:"required,min=3,max=255"`
+	Description         string                   `json:"description" validate:"omitempty,max=5000"`
+	OrderIndex          int                      `json:"order_index" validate:"gte=0"`
+	ExerciseType        string                   `json:"exercise_type" validate:"required,oneof=timed repetition combined"`
+	DurationSeconds     *int                     `json:"duration_seconds" validate:"omitempty,min=1"`
+	Repetitions         *int                     `json:"repetitions" validate:"omitempty,min=1"`
+	RestAfterSeconds    int                      `json:"rest_after_seconds" validate:"gte=0"`
+	HasSides            bool                     `json:"has_sides"`
+	SideDurationSeconds *int                     `json:"side_duration_seconds" validate:"omitempty,min=1"`
+	Tempo               *ExerciseTempoRequest    `json:"tempo"`
+	BreathingPattern    *BreathingPatternRequest `json:"breathing_pattern"`
+	Contraindications   []string                 `json:"contraindications" validate:"omitempty,max=20,dive,limitation"`
+	Metadata            map[string]interface{}   `json:"metadata" validate:"omitempty,jsonlimits"`
 }
 
+// AssignProgramRequest assigns a program by user IDs, emails, or both
 type AssignProgramRequest struct {
-	UserIDs []string `json:"user_ids" validate:"required,min=1"`
+	UserIDs []string `json:"user_ids" validate:"required_without=Emails,max=1000"`
+	Emails  []string `json:"emails" validate:"required_without=UserIDs,max=1000"`
+	// InviteMissing sends invitations to emails without an account
+	InviteMissing bool `json:"invite_missing"`
+	WelcomeThreadRequest
+}
+
+// WelcomeThreadRequest opens a submission thread with a message from the assigning instructor
+// for each newly assigned user. The title defaults to "Welcome to <program name>".
+type WelcomeThreadRequest struct {
+	WelcomeMessage string `json:"welcome_message" form:"welcome_message" validate:"omitempty,max=20000"`
+	WelcomeTitle   string `json:"welcome_title" form:"welcome_title" validate:"omitempty,min=3,max=255"`
+}
+
+// SetTranslationRequest sets the localized name and description of a program or exercise
+type SetTranslationRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=255"`
+	Description string `json:"description" validate:"omitempty,max=5000"`
+}
+
+// SetMetadataSchemaRequest replaces the JSON Schema for program or exercise metadata
+type SetMetadataSchemaRequest struct {
+	Schema map[string]interface{} `json:"schema" validate:"required"`
+}
+
+// SelectCoverRequest reuses the cover image of another program
+type SelectCoverRequest struct {
+	FromProgramID string `json:"from_program_id" validate:"required,uuid"`
 }
 
 // Exercise requests
 type CreateExerciseRequest struct {
-	ProgramID           string                 `json:"program_id" validate:"required,uuid"`
-	Name                string                 `json:"name" validate:"required,min=3,max=255"`
-	Description         string                 `json:"description"`
-	OrderIndex          int                    `json:"order_index" validate:"gte=0"`
-	ExerciseType        string                 `json:"exercise_type" validate:"required,oneof=timed repetition combined"`
-	DurationSeconds     *int                   `json:"duration_seconds" validate:"omitempty,min=1"`
-	Repetitions         *int                   `json:"repetitions" validate:"omitempty,min=1"`
-	RestAfterSeconds    int                    `json:"rest_after_seconds" validate:"gte=0"`
-	HasSides            bool                   `json:"has_sides"`
-	SideDurationSeconds *int                   `json:"side_duration_seconds" validate:"omitempty,min=1"`
-	Metadata            map[string]interface{} `json:"metadata"`
+	Name                string                   `json:"name" validate:"required,min=3,max=255"`
+	Description         string                   `json:"description" validate:"omitempty,max=5000"`
+	OrderIndex          int                      `json:"order_index" validate:"gte=0"`
+	ExerciseType        string                   `json:"exercise_type" validate:"required,oneof=timed repetition combined"`
+	DurationSeconds     *int                     `json:"duration_seconds" validate:"omitempty,min=1"`
+	Repetitions         *int                     `json:"repetitions" validate:"omitempty,min=1"`
+	RestAfterSeconds    int                      `json:"rest_after_seconds" validate:"gte=0"`
+	HasSides            bool                     `json:"has_sides"`
+	SideDurationSeconds *int                     `json:"side_duration_seconds" validate:"omitempty,min=1"`
+	Tempo               *ExerciseTempoRequest    `json:"tempo"`
+	BreathingPattern    *BreathingPatternRequest `json:"breathing_pattern"`
+	Contraindications   []string                 `json:"contraindications" validate:"omitempty,max=20,dive,limitation"`
+	Metadata            map[string]interface{}   `json:"metadata" validate:"omitempty,jsonlimits"`
 }
 
 type UpdateExerciseRequest struct {
-	Name                *string                `json:"name" validate:"omitempty,min=3,max=255"`
-	Description         *string                `json:"description"`
-	OrderIndex          *int                   `json:"order_index" validate:"omitempty,min=0"`
-	ExerciseType        *string                `json:"exercise_type" validate:"omitempty,oneof=timed repetition combined"`
-	DurationSeconds     *int                   `json:"duration_seconds" validate:"omitempty,min=1"`
-	Repetitions         *int                   `json:"repetitions" validate:"omitempty,min=1"`
-	RestAfterSeconds    *int                   `json:"rest_after_seconds" validate:"omitempty,min=0"`
-	HasSides            *bool                  `json:"has_sides"`
-	SideDurationSeconds *int                   `json:"side_duration_seconds" validate:"omitempty,min=1"`
-	Metadata            map[string]interface{} `json:"metadata"`
+	Name                *string                  `json:"name" validate:"omitempty,min=3,max=255"`
+	Description         *string                  `json:"description" validate:"omitempty,max=5000"`
+	OrderIndex          *int                     `json:"order_index" validate:"omitempty,min=0"`
+	ExerciseType        *string                  `json:"exercise_type" validate:"omitempty,oneof=timed repetition combined"`
+	DurationSeconds     *int                     `json:"duration_seconds" validate:"omitempty,min=1"`
+	Repetitions         *int                     `json:"repetitions" validate:"omitempty,min=1"`
+	RestAfterSeconds    *int                     `json:"rest_after_seconds" validate:"omitempty,min=0"`
+	HasSides            *bool                    `json:"has_sides"`
+	SideDurationSeconds *int                     `json:"side_duration_seconds" validate:"omitempty,min=1"`
+	Tempo               *ExerciseTempoRequest    `json:"tempo"`
+	BreathingPattern    *BreathingPatternRequest `json:"breathing_pattern"`
+	Contraindications   []string                 `json:"contraindications" validate:"omitempty,max=20,dive,limitation"`
+	Metadata            map[string]interface{}   `json:"metadata" validate:"omitempty,jsonlimits"`
+}
+
+// ExerciseSubstituteRequest creates or replaces a variant of an exercise. Timing left out is
+// taken from the exercise.
+type ExerciseSubstituteRequest struct {
+	Name                string  `json:"name" validate:"required,min=3,max=255"`
+	Description         string  `json:"description" validate:"omitempty,max=5000"`
+	Reason              *string `json:"reason" validate:"omitempty,max=255"`
+	DurationSeconds     *int    `json:"duration_seconds" validate:"omitempty,min=1"`
+	Repetitions         *int    `json:"repetitions" validate:"omitempty,min=1"`
+	SideDurationSeconds *int    `json:"side_duration_seconds" validate:"omitempty,min=1"`
+	// Contraindications left out make the substitute safe for every limitation
+	Contraindications []string `json:"contraindications" validate:"omitempty,max=20,dive,limitation"`
+}
+
+// ExerciseTempoRequest paces a repetition exercise. All fields are optional; an empty tempo is the
+// same as none.
+type ExerciseTempoRequest struct {
+	SecondsPerRep     int `json:"seconds_per_rep" validate:"omitempty,min=1,max=600"`
+	PrepSeconds       int `json:"prep_seconds" validate:"gte=0,max=600"`
+	TransitionSeconds int `json:"transition_seconds" validate:"gte=0,max=600"`
+}
+
+// BreathingPatternRequest defines an exercise by breath counts. Cycles may be left out for
+// exercises timed otherwise.
+type BreathingPatternRequest struct {
+	InhaleSeconds int `json:"inhale_seconds" validate:"required,min=1,max=60"`
+	HoldSeconds   int `json:"hold_seconds" validate:"gte=0,max=60"`
+	ExhaleSeconds int `json:"exhale_seconds" validate:"required,min=1,max=60"`
+	Cycles        int `json:"cycles" validate:"omitempty,min=1,max=1000"`
 }
 
 type ReorderExercisesRequest struct {
@@ -148,7 +567,7 @@ type ReorderExercisesRequest struct {
 // Session requests
 type StartSessionRequest struct {
 	ProgramID  string                 `json:"program_id" validate:"required,uuid"`
-	DeviceInfo map[string]interface{} `json:"device_info"`
+	DeviceInfo map[string]interface{} `json:"device_info" validate:"omitempty,jsonlimits"`
 }
 
 type LogExerciseRequest struct {
@@ -158,18 +577,92 @@ type LogExerciseRequest struct {
 	RepetitionsCompleted   *int   `json:"repetitions_completed" validate:"omitempty,min=0"`
 	Skipped                bool   `json:"skipped"`
 	Notes                  string `json:"notes"`
+	// SubstituteID records that a substitute of the exercise was done instead
+	SubstituteID string `json:"substitute_id" validate:"omitempty,uuid"`
 }
 
 type CompleteSessionRequest struct {
-	TotalDurationSeconds *int     `json:"total_duration_seconds" validate:"omitempty,min=0"`
-	CompletionRate       *float64 `json:"completion_rate" validate:"omitempty,min=0,max=100"`
-	Notes                string   `json:"notes"`
-	CompletedAt          *string  `json:"completed_at"`
+	TotalDurationSeconds *int                   `json:"total_duration_seconds" validate:"omitempty,min=0"`
+	CompletionRate       *float64               `json:"completion_rate" validate:"omitempty,min=0,max=100"`
+	Notes                string                 `json:"notes"`
+	CompletedAt          *timestamp.Time        `json:"completed_at"`
+	Mood                 *int                   `json:"mood" validate:"omitempty,min=1,max=5"`
+	Energy               *int                   `json:"energy" validate:"omitempty,min=1,max=5"`
+	PainFlags            []string               `json:"pain_flags" validate:"omitempty,max=20,dive,min=1,max=50"`
+	Tags                 []string               `json:"tags" validate:"omitempty,max=20,dive,min=1,max=50"`
+	Answers              []SessionAnswerRequest `json:"answers" validate:"omitempty,max=50,dive"` // To the program's questionnaire, in place of notes
+}
+
+// SessionAnswerRequest answers one question of a questionnaire: scale questions take a scale
+// value, text questions a text
+type SessionAnswerRequest struct {
+	QuestionID string  `json:"question_id" validate:"required,max=50"`
+	Scale      *int    `json:"scale" validate:"required_without=Text,excluded_with=Text"`
+	Text       *string `json:"text" validate:"required_without=Scale,omitempty,max=5000"`
+}
+
+// AnswerQuestionnaireRequest answers the questionnaire after the session was completed
+type AnswerQuestionnaireRequest struct {
+	Answers []SessionAnswerRequest `json:"answers" validate:"required,min=1,max=50,dive"`
+}
+
+// SetQuestionnaireRequest replaces a program's post-session questionnaire
+type SetQuestionnaireRequest struct {
+	Questions []QuestionnaireQuestionRequest `json:"questions" validate:"required,min=1,max=20,dive"`
+}
+
+// QuestionnaireQuestionRequest is one question of a questionnaire. Scales default to 1 to 5.
+type QuestionnaireQuestionRequest struct {
+	ID       string  `json:"id" validate:"required,max=50"`
+	Type     string  `json:"type" validate:"required,oneof=scale text"`
+	Prompt   string  `json:"prompt" validate:"required,max=500"`
+	Min      int     `json:"min" validate:"min=0,max=100"`
+	Max      int     `json:"max" validate:"min=0,max=100"`
+	MinLabel *string `json:"min_label" validate:"omitempty,max=100"`
+	MaxLabel *string `json:"max_label" validate:"omitempty,max=100"`
+}
+
+// UpdateSessionRequest corrects a recorded session. Omitted fields are left unchanged.
+type UpdateSessionRequest struct {
+	Notes                *string         `json:"notes" validate:"omitempty,max=5000"`
+	TotalDurationSeconds *int            `json:"total_duration_seconds" validate:"omitempty,min=0,max=86400"`
+	CompletionRate       *float64        `json:"completion_rate" validate:"omitempty,min=0,max=100"`
+	CompletedAt          *timestamp.Time `json:"completed_at"`
+}
+
+// AdminUpdateSessionRequest corrects a student's session. The reason is kept in its edit history.
+type AdminUpdateSessionRequest struct {
+	UpdateSessionRequest
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// UpdateExerciseLogRequest corrects a logged exercise. Omitted fields are left unchanged; the
+// reason is kept in the session's edit history.
+type UpdateExerciseLogRequest struct {
+	ActualDurationSeconds *int    `json:"actual_duration_seconds" validate:"omitempty,min=0,max=86400"`
+	RepetitionsCompleted  *int    `json:"repetitions_completed" validate:"omitempty,min=0,max=100000"`
+	Skipped               *bool   `json:"skipped"`
+	Notes                 *string `json:"notes" validate:"omitempty,max=5000"`
+	Reason                string  `json:"reason" validate:"required,max=500"`
+}
+
+type CreateSessionNoteRequest struct {
+	Content    string `json:"content" validate:"required,min=1"`
+	Visibility string `json:"visibility" validate:"omitempty,oneof=private shared"`
+}
+
+// AddBiometricsRequest carries evenly spaced wearable samples in a compact form.
+// Sample i was recorded at start_time + i*interval_ms; null entries mark gaps.
+type AddBiometricsRequest struct {
+	StartTime  *timestamp.Time `json:"start_time" validate:"required"`
+	IntervalMs int             `json:"interval_ms" validate:"required,min=100,max=600000"`
+	HeartRate  []*int          `json:"heart_rate" validate:"max=20000"`
+	HRVMs      []*float64      `json:"hrv_ms" validate:"max=20000"`
 }
 
 // Update settings request
 type UpdateProgramSettingsRequest struct {
-	CustomSettings map[string]interface{} `json:"custom_settings"`
+	CustomSettings map[string]interface{} `json:"custom_settings" validate:"omitempty,jsonlimits"`
 }
 
 // Query parameters
@@ -188,3 +681,233 @@ type ListSessionsQuery struct {
 	Limit     int     `form:"limit" validate:"min=1,max=100"`
 	Offset    int     `form:"offset" validate:"min=0"`
 }
+
+// ListAdminSessionsQuery filters the admin activity feed across all users
+type ListAdminSessionsQuery struct {
+	ListSessionsQuery
+	UserID *string `form:"user_id" validate:"omitempty,uuid"`
+	Status string  `form:"status" validate:"omitempty,oneof=completed in_progress abandoned"`
+}
+
+type ListNotificationsQuery struct {
+	UnreadOnly bool `form:"unread_only"`
+	Limit      int  `form:"limit" validate:"min=1,max=100"`
+	Offset     int  `form:"offset" validate:"min=0"`
+}
+
+// UpdateNotificationPreferencesRequest changes the given preferences; omitted fields are kept
+type UpdateNotificationPreferencesRequest struct {
+	WeeklyDigest *bool   `json:"weekly_digest"`
+	Timezone     *string `json:"timezone" validate:"omitempty,max=64"`
+}
+
+// UsageQuery represents query parameters for admin usage statistics
+type UsageQuery struct {
+	Days int `form:"days" validate:"min=1,max=365"`
+}
+
+// ReviewAnalyticsQuery represents query parameters for instructor review analytics
+type ReviewAnalyticsQuery struct {
+	Days int `form:"days" validate:"min=1,max=365"`
+}
+
+type HomeworkReportQuery struct {
+	ProgramID *string         `form:"program_id" validate:"omitempty,uuid"`
+	From      *timestamp.Time `form:"from"` // Defaults to 30 days before to
+	To        *timestamp.Time `form:"to"`   // Defaults to now
+}
+
+type AttendanceReportQuery struct {
+	GroupID *string         `form:"group_id" validate:"omitempty,uuid"`
+	From    *timestamp.Time `form:"from"` // Defaults to 30 days before to
+	To      *timestamp.Time `form:"to"`   // Defaults to now
+}
+
+type ReportQuery struct {
+	From   *timestamp.Time `form:"from"` // Defaults to 30 days before to
+	To     *timestamp.Time `form:"to"`   // Defaults to now
+	Format string          `form:"format" validate:"oneof=csv xlsx"`
+}
+
+// Quota requests. A null or missing limit is unlimited for plans, and falls back to the
+// plan's limit for user overrides.
+type QuotaLimitsRequest struct {
+	MaxPrograms        *int   `json:"max_programs" validate:"omitempty,min=0"`
+	MaxOpenSubmissions *int   `json:"max_open_submissions" validate:"omitempty,min=0"`
+	MaxStorageBytes    *int64 `json:"max_storage_bytes" validate:"omitempty,min=0"`
+}
+
+type SetUserQuotaRequest struct {
+	Plan *string `json:"plan" validate:"omitempty,min=1,max=50"` // Defaults to the default plan
+	QuotaLimitsRequest
+}
+
+// Streak rule overrides. A null or missing rule falls back to the deployment's policy.
+type SetStreakRulesRequest struct {
+	RestDaysPerWeek *int `json:"rest_days_per_week" validate:"omitempty,min=0,max=6"`
+	MinMinutes      *int `json:"min_minutes" validate:"omitempty,min=0,max=1440"`
+	GraceHours      *int `json:"grace_hours" validate:"omitempty,min=0,max=12"`
+}
+
+type StartStatsRecomputeRequest struct {
+	UserID *string `json:"user_id" validate:"omitempty,uuid"` // Everyone if missing
+}
+
+type ListStatsRecomputesQuery struct {
+	Limit  int `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset int `form:"offset" validate:"omitempty,gte=0"`
+}
+
+type ListReconciliationsQuery struct {
+	Limit  int `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset int `form:"offset" validate:"omitempty,gte=0"`
+}
+
+// Data retention requests
+
+type ListRetentionRunsQuery struct {
+	Rule   string `form:"rule" validate:"omitempty,max=32"`
+	Limit  int    `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset int    `form:"offset" validate:"omitempty,gte=0"`
+}
+
+// Moderation requests
+type ReportContentRequest struct {
+	Reason  string  `json:"reason" validate:"required,oneof=spam harassment inappropriate other"`
+	Details *string `json:"details" validate:"omitempty,max=1000"`
+}
+
+type ListModerationCasesQuery struct {
+	Status string `form:"status" validate:"omitempty,oneof=pending resolved hidden all"` // Defaults to pending
+	Limit  int    `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset int    `form:"offset" validate:"omitempty,gte=0"`
+}
+
+type ModerationDecisionRequest struct {
+	Note *string `json:"note" validate:"omitempty,max=1000"`
+}
+
+// Changelog requests
+type CreateChangelogEntryRequest struct {
+	Version  string `json:"version" validate:"required,max=50"`
+	Title    string `json:"title" validate:"required,max=255"`
+	Body     string `json:"body" validate:"required,max=20000"`                      // Markdown
+	Audience string `json:"audience" validate:"omitempty,oneof=all students admins"` // Defaults to all
+}
+
+type UpdateChangelogEntryRequest struct {
+	Version  *string `json:"version" validate:"omitempty,min=1,max=50"`
+	Title    *string `json:"title" validate:"omitempty,min=1,max=255"`
+	Body     *string `json:"body" validate:"omitempty,min=1,max=20000"`
+	Audience *string `json:"audience" validate:"omitempty,oneof=all students admins"`
+}
+
+type ListChangelogQuery struct {
+	Limit  int `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset int `form:"offset" validate:"omitempty,gte=0"`
+}
+
+type DismissChangelogRequest struct {
+	EntryIDs []string `json:"entry_ids" validate:"required,min=1,max=100,dive,uuid"`
+}
+
+// Support requests
+type OpenSupportTicketRequest struct {
+	Category string `json:"category" validate:"required,oneof=technical account billing other"`
+	Message  string `json:"message" validate:"required,max=20000"`
+}
+
+type SupportReplyRequest struct {
+	Content string `json:"content" validate:"required,max=20000"`
+}
+
+type ListSupportTicketsQuery struct {
+	Status   string `form:"status" validate:"omitempty,oneof=open answered closed all"`
+	Category string `form:"category" validate:"omitempty,oneof=technical account billing other"` // Admin queue only
+	Limit    int    `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset   int    `form:"offset" validate:"omitempty,gte=0"`
+}
+
+type SetSupportStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=open answered closed"`
+}
+
+type SetClientVersionPolicyRequest struct {
+	MinVersion string `json:"min_version" validate:"max=50"` // Empty allows every version
+	UpgradeURL string `json:"upgrade_url" validate:"omitempty,url,max=2000"`
+}
+
+type SlowEndpointsQuery struct {
+	Limit int `form:"limit" validate:"min=1,max=100"`
+}
+
+type CreateInvitationRequest struct {
+	Email         *string  `json:"email" validate:"omitempty,email"`
+	Role          string   `json:"role" validate:"omitempty,oneof=admin student"`
+	GroupID       *string  `json:"group_id" validate:"omitempty,uuid"`
+	ProgramIDs    []string `json:"program_ids" validate:"omitempty,max=50,dive,uuid"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=90"`
+}
+
+type ListInvitationsQuery struct {
+	PendingOnly bool `form:"pending_only"`
+	Limit       int  `form:"limit" validate:"min=1,max=100"`
+	Offset      int  `form:"offset" validate:"min=0"`
+}
+
+type CreateGroupRequest struct {
+	Name        string  `json:"name" validate:"required,min=1,max=255"`
+	Description *string `json:"description"`
+}
+
+type GroupStatsQuery struct {
+	From *timestamp.Time `form:"from"` // Defaults to 7 days before to
+	To   *timestamp.Time `form:"to"`   // Defaults to now
+}
+
+// Experiment requests
+
+type CreateExperimentRequest struct {
+	Name        string  `json:"name" validate:"required,min=1,max=255"`
+	Description *string `json:"description" validate:"omitempty,max=2000"`
+	ProgramAID  string  `json:"program_a_id" validate:"required,uuid"`
+	ProgramBID  string  `json:"program_b_id" validate:"required,uuid,nefield=ProgramAID"`
+}
+
+type AssignExperimentRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required,min=1,max=1000,dive,uuid"`
+}
+
+type QRCheckInRequest struct {
+	Token string `json:"token" validate:"required,max=2048"`
+}
+
+type CreateDisplayTokenRequest struct {
+	Name          string   `json:"name" validate:"required,min=1,max=100"`
+	ProgramIDs    []string `json:"program_ids" validate:"required,min=1,max=50,dive,uuid"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+type ListDisplayTokensQuery struct {
+	ActiveOnly bool `form:"active_only"`
+}
+
+type CreateIntegrationKeyRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+type ListIntegrationProgramsQuery struct {
+	Limit  int `form:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset int `form:"offset" validate:"omitempty,gte=0"`
+}
+
+type CreateShareLinkRequest struct {
+	ExpiresInDays int `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+type OEmbedQuery struct {
+	URL       string `form:"url" validate:"required,url,max=2048"`
+	MaxWidth  int    `form:"maxwidth" validate:"min=0"`
+	MaxHeight int    `form:"maxheight" validate:"min=0"`
+	Format    string `form:"format" validate:"omitempty,oneof=json xml"`
+}
@@ -0,0 +1,98 @@
+// Load-testing profile for the hot read paths: submission list, unread counts and
+// practice stats. Run against a local server with `make loadtest`, for example
+//
+//   RATE_LIMIT_REQUESTS=1000000 OPEN_REGISTRATION=true make run
+//   make loadtest BASE_URL=http://localhost:8080 VUS=20 DURATION=1m
+//
+// setup() registers a fresh student and an existing admin (ADMIN_EMAIL/ADMIN_PASSWORD)
+// and gives them a conversation and some practice history, so the queries have data to
+// work on. Without admin credentials only the student scenario runs.
+import http from 'k6/http';
+import { check, fail } from 'k6';
+
+const BASE_URL = __ENV.BASE_URL || 'http://localhost:8080';
+const API = `${BASE_URL}/api/${__ENV.API_VERSION || 'v1'}`;
+const SEED_SESSIONS = parseInt(__ENV.SEED_SESSIONS || '30', 10);
+const SEED_MESSAGES = parseInt(__ENV.SEED_MESSAGES || '10', 10);
+
+export const options = {
+  scenarios: {
+    reads: {
+      executor: 'constant-vus',
+      vus: parseInt(__ENV.VUS || '10', 10),
+      duration: __ENV.DURATION || '30s',
+    },
+  },
+  thresholds: {
+    http_req_failed: ['rate<0.01'],
+    'http_req_duration{endpoint:submissions}': ['p(95)<200'],
+    'http_req_duration{endpoint:unread_count}': ['p(95)<100'],
+    'http_req_duration{endpoint:stats}': ['p(95)<200'],
+  },
+};
+
+function request(method, path, token, body, endpoint) {
+  const params = {
+    headers: { 'Content-Type': 'application/json' },
+    tags: { endpoint: endpoint || 'setup' },
+  };
+  if (token) {
+    params.headers.Authorization = `Bearer ${token}`;
+  }
+  const res = http.request(method, `${API}${path}`, body ? JSON.stringify(body) : null, params);
+  if (res.status >= 400 && !endpoint) {
+    fail(`${method} ${path} failed with ${res.status}: ${res.body}`);
+  }
+  return res;
+}
+
+function login(email, password) {
+  return request('POST', '/auth/login', null, { email, password }).json('tokens.access_token');
+}
+
+export function setup() {
+  const email = `loadtest-${Date.now()}@test.com`;
+  const password = 'loadtest-password';
+  request('POST', '/auth/register', null, { email, password, full_name: 'Load Test Student' });
+  const student = login(email, password);
+  const admin = __ENV.ADMIN_EMAIL ? login(__ENV.ADMIN_EMAIL, __ENV.ADMIN_PASSWORD) : null;
+
+  const program = request('POST', '/programs', student, {
+    name: 'Load Test Program',
+    exercises: [{ name: 'Standing Meditation', order_index: 0, exercise_type: 'timed', duration_seconds: 300 }],
+  }).json();
+
+  for (let i = 0; i < SEED_SESSIONS; i++) {
+    const session = request('POST', '/sessions/start', student, { program_id: program.id }).json();
+    request('PUT', `/sessions/${session.id}/complete`, student, {
+      total_duration_seconds: 600,
+      completion_rate: 100,
+      mood: 1 + (i % 5),
+    });
+  }
+
+  const submission = request('POST', `/programs/${program.id}/submissions`, student, {
+    title: 'Load test submission',
+  }).json('submission');
+  for (let i = 0; i < SEED_MESSAGES; i++) {
+    const author = admin && i % 2 === 1 ? admin : student;
+    request('POST', `/submissions/${submission.id}/messages`, author, { content: `Message ${i}` });
+  }
+
+  return { student, admin };
+}
+
+export default function (data) {
+  const tokens = data.admin ? [data.student, data.admin] : [data.student];
+  const token = tokens[__ITER % tokens.length];
+
+  check(request('GET', '/submissions?limit=20', token, null, 'submissions'), {
+    'submissions 200': (r) => r.status === 200,
+  });
+  check(request('GET', '/submissions/unread-count', token, null, 'unread_count'), {
+    'unread count 200': (r) => r.status === 200,
+  });
+  check(request('GET', '/sessions/stats', data.student, null, 'stats'), {
+    'stats 200': (r) => r.status === 200,
+  });
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notifications CASCADE;
+DROP TABLE IF EXISTS session_notes CASCADE;
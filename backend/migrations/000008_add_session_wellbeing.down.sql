@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_sessions_tags;
+
+ALTER TABLE practice_sessions
+DROP COLUMN IF EXISTS mood,
+DROP COLUMN IF EXISTS energy,
+DROP COLUMN IF EXISTS pain_flags,
+DROP COLUMN IF EXISTS tags;
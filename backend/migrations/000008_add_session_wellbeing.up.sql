@@ -0,0 +1,13 @@
+-- Subjective wellbeing fields recorded when completing a practice session
+ALTER TABLE practice_sessions
+ADD COLUMN mood SMALLINT CHECK (mood BETWEEN 1 AND 5),
+ADD COLUMN energy SMALLINT CHECK (energy BETWEEN 1 AND 5),
+ADD COLUMN pain_flags TEXT[] DEFAULT '{}',
+ADD COLUMN tags TEXT[] DEFAULT '{}';
+
+CREATE INDEX idx_sessions_tags ON practice_sessions USING GIN (tags);
+
+COMMENT ON COLUMN practice_sessions.mood IS 'Self-reported mood after practice (1-5)';
+COMMENT ON COLUMN practice_sessions.energy IS 'Self-reported energy after practice (1-5)';
+COMMENT ON COLUMN practice_sessions.pain_flags IS 'Body areas reported as painful, e.g. knee, lower_back';
+COMMENT ON COLUMN practice_sessions.tags IS 'Free-form tags set by the student';
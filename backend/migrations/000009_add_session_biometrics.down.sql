@@ -0,0 +1,7 @@
+ALTER TABLE practice_sessions
+DROP COLUMN IF EXISTS heart_rate_min,
+DROP COLUMN IF EXISTS heart_rate_avg,
+DROP COLUMN IF EXISTS heart_rate_max,
+DROP COLUMN IF EXISTS hrv_avg;
+
+DROP TABLE IF EXISTS session_biometrics CASCADE;
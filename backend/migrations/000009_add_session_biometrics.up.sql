@@ -0,0 +1,17 @@
+-- Wearable biometric samples, one row per sample.
+-- Keyed by (session_id, recorded_at) without a surrogate id so the table
+-- can be converted to a TimescaleDB hypertable on recorded_at if needed.
+CREATE TABLE session_biometrics (
+    session_id UUID NOT NULL REFERENCES practice_sessions(id) ON DELETE CASCADE,
+    recorded_at TIMESTAMP NOT NULL,
+    heart_rate SMALLINT CHECK (heart_rate BETWEEN 20 AND 250),
+    hrv_ms DOUBLE PRECISION CHECK (hrv_ms >= 0),
+    PRIMARY KEY (session_id, recorded_at)
+);
+
+-- Summary of the samples, kept on the session for cheap list and stats queries
+ALTER TABLE practice_sessions
+ADD COLUMN heart_rate_min SMALLINT,
+ADD COLUMN heart_rate_avg DOUBLE PRECISION,
+ADD COLUMN heart_rate_max SMALLINT,
+ADD COLUMN hrv_avg DOUBLE PRECISION;
@@ -0,0 +1,5 @@
+-- Preferred language for audio cues and localized content
+ALTER TABLE users
+ADD COLUMN language VARCHAR(10) NOT NULL DEFAULT 'en';
+
+COMMENT ON COLUMN users.language IS 'Preferred language code (en, de, zh)';
@@ -0,0 +1,18 @@
+-- Access logs: one row per authenticated API request, used for per-user usage statistics
+CREATE TABLE access_logs (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    status SMALLINT NOT NULL,
+    client_ip VARCHAR(64),
+    user_agent TEXT,
+    device VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_access_logs_user_id ON access_logs(user_id, created_at DESC);
+CREATE INDEX idx_access_logs_created_at ON access_logs(created_at);
+
+COMMENT ON COLUMN access_logs.route IS 'Matched route template (e.g. /api/v1/sessions/:id), not the raw path';
+COMMENT ON COLUMN access_logs.device IS 'Client-reported X-Device-Info header, if sent';
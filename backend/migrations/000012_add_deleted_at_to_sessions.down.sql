@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_practice_sessions_deleted_at;
+
+-- Soft-deleted sessions would reappear otherwise
+DELETE FROM practice_sessions WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS deleted_at;
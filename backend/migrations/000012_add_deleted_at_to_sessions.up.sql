@@ -0,0 +1,6 @@
+-- Soft delete for practice sessions so deletions can be undone and do not rewrite history immediately
+ALTER TABLE practice_sessions ADD COLUMN deleted_at TIMESTAMP DEFAULT NULL;
+
+CREATE INDEX idx_practice_sessions_deleted_at ON practice_sessions(deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN practice_sessions.deleted_at IS 'Timestamp when session was soft deleted. NULL means active. Purged after the retention period.';
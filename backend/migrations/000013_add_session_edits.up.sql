@@ -0,0 +1,12 @@
+-- Session edits: audit trail of corrections made to practice sessions after the fact
+CREATE TABLE session_edits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES practice_sessions(id) ON DELETE CASCADE,
+    editor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_session_edits_session_id ON session_edits(session_id, created_at);
+
+COMMENT ON COLUMN session_edits.changes IS 'Map of field name to {"from": old, "to": new}';
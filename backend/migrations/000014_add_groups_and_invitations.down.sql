@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS invitations CASCADE;
+DROP TABLE IF EXISTS group_members CASCADE;
+DROP TABLE IF EXISTS groups CASCADE;
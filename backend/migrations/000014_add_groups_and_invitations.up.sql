@@ -0,0 +1,37 @@
+-- Groups: named sets of students (e.g. a class or cohort)
+CREATE TABLE groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE group_members (
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, user_id)
+);
+
+-- Invitations: single-use signup tokens with pre-assigned role, group and programs
+CREATE TABLE invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    email VARCHAR(255),
+    role VARCHAR(20) NOT NULL DEFAULT 'student' CHECK (role IN ('admin', 'student')),
+    group_id UUID REFERENCES groups(id) ON DELETE SET NULL,
+    program_ids UUID[] NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    accepted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_group_members_user_id ON group_members(user_id);
+CREATE INDEX idx_invitations_pending ON invitations(created_at DESC) WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+COMMENT ON COLUMN invitations.token_hash IS 'SHA-256 of the signup token; the token itself is only returned once on creation';
+COMMENT ON COLUMN invitations.email IS 'If set, the invitation can only be redeemed with this email';
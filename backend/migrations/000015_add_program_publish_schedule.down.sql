@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_programs_unpublish_at;
+DROP INDEX IF EXISTS idx_programs_publish_at;
+
+ALTER TABLE programs DROP COLUMN IF EXISTS unpublish_at;
+ALTER TABLE programs DROP COLUMN IF EXISTS publish_at;
@@ -0,0 +1,9 @@
+-- Scheduled publishing: a background job flips is_public when these times pass
+ALTER TABLE programs ADD COLUMN publish_at TIMESTAMP DEFAULT NULL;
+ALTER TABLE programs ADD COLUMN unpublish_at TIMESTAMP DEFAULT NULL;
+
+CREATE INDEX idx_programs_publish_at ON programs(publish_at) WHERE publish_at IS NOT NULL;
+CREATE INDEX idx_programs_unpublish_at ON programs(unpublish_at) WHERE unpublish_at IS NOT NULL;
+
+COMMENT ON COLUMN programs.publish_at IS 'When set, the program becomes public at this time. Cleared once applied.';
+COMMENT ON COLUMN programs.unpublish_at IS 'When set, the program stops being public at this time. Cleared once applied.';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_programs_exercise_fingerprint;
+DROP INDEX IF EXISTS idx_programs_name_key;
+
+ALTER TABLE programs DROP COLUMN IF EXISTS fingerprinted_at;
+ALTER TABLE programs DROP COLUMN IF EXISTS exercise_fingerprint;
+ALTER TABLE programs DROP COLUMN IF EXISTS name_key;
@@ -0,0 +1,11 @@
+-- Duplicate detection: normalized name and exercise structure hash, computed by the API
+ALTER TABLE programs ADD COLUMN name_key TEXT DEFAULT NULL;
+ALTER TABLE programs ADD COLUMN exercise_fingerprint VARCHAR(64) DEFAULT NULL;
+ALTER TABLE programs ADD COLUMN fingerprinted_at TIMESTAMP DEFAULT NULL;
+
+CREATE INDEX idx_programs_name_key ON programs(name_key) WHERE deleted_at IS NULL;
+CREATE INDEX idx_programs_exercise_fingerprint ON programs(exercise_fingerprint) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN programs.name_key IS 'Normalized program name used to detect near-duplicate templates.';
+COMMENT ON COLUMN programs.exercise_fingerprint IS 'SHA-256 over the ordered exercise structure. NULL until computed.';
+COMMENT ON COLUMN programs.fingerprinted_at IS 'When the fingerprint was last computed. NULL means it needs (re)computing.';
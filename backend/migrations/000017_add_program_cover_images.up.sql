@@ -0,0 +1,4 @@
+-- Program cover images; thumbnails live in object storage under this key prefix
+ALTER TABLE programs ADD COLUMN cover_image_key TEXT DEFAULT NULL;
+
+COMMENT ON COLUMN programs.cover_image_key IS 'Storage key prefix of the cover image, e.g. covers/<hash>. Variants are stored as <prefix>/<size>.jpg.';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS exercise_translations;
+DROP TABLE IF EXISTS program_translations;
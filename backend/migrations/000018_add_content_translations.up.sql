@@ -0,0 +1,23 @@
+-- Translations of program and exercise content; the program row holds the default language
+CREATE TABLE program_translations (
+    program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    locale VARCHAR(10) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (program_id, locale)
+);
+
+CREATE TABLE exercise_translations (
+    exercise_id UUID NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    locale VARCHAR(10) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (exercise_id, locale)
+);
+
+CREATE INDEX idx_program_translations_locale ON program_translations(locale);
+CREATE INDEX idx_exercise_translations_locale ON exercise_translations(locale);
@@ -0,0 +1,9 @@
+-- Admin-defined JSON Schemas that program and exercise metadata must satisfy
+CREATE TABLE metadata_schemas (
+    entity_type VARCHAR(20) PRIMARY KEY CHECK (entity_type IN ('program', 'exercise')),
+    schema JSONB NOT NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE metadata_schemas IS 'JSON Schema per entity type. Without a row, metadata is not validated.';
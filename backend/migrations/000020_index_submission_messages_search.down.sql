@@ -0,0 +1 @@
+DROP INDEX CONCURRENTLY IF EXISTS idx_submission_messages_content_search;
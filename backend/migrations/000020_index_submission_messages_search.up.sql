@@ -0,0 +1,4 @@
+-- Full-text search over submission messages. The 'simple' configuration does not stem, so
+-- German, English and romanized Chinese terms all match as typed.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_submission_messages_content_search
+    ON submission_messages USING GIN (to_tsvector('simple', content));
@@ -0,0 +1 @@
+DROP INDEX CONCURRENTLY IF EXISTS idx_submissions_title_search;
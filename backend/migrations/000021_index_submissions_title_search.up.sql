@@ -0,0 +1,3 @@
+-- Full-text search over submission titles, see 000020
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_submissions_title_search
+    ON submissions USING GIN (to_tsvector('simple', title));
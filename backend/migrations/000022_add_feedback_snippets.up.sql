@@ -0,0 +1,12 @@
+-- Reusable feedback blocks instructors insert into submission messages
+CREATE TABLE feedback_snippets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(100) NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (owner_id, title)
+);
+
+COMMENT ON COLUMN feedback_snippets.body IS 'Text with placeholders like {{student_first_name}}, expanded when the snippet is inserted into a message';
@@ -0,0 +1,9 @@
+-- Unsent message drafts, one per user and submission, autosaved by the clients
+CREATE TABLE submission_drafts (
+    submission_id UUID NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    youtube_url TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (submission_id, user_id)
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS submission_message_mentions;
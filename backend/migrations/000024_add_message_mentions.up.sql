@@ -0,0 +1,8 @@
+-- Users @mentioned in submission messages
+CREATE TABLE submission_message_mentions (
+    message_id UUID NOT NULL REFERENCES submission_messages(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (message_id, user_id)
+);
+
+CREATE INDEX idx_submission_message_mentions_user_id ON submission_message_mentions(user_id);
@@ -0,0 +1,24 @@
+-- Submission messages and program announcements queued by instructors for later delivery
+CREATE TABLE scheduled_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    submission_id UUID REFERENCES submissions(id) ON DELETE CASCADE,
+    program_id UUID REFERENCES programs(id) ON DELETE CASCADE,
+    title VARCHAR(255),
+    content TEXT NOT NULL,
+    youtube_url TEXT,
+    send_at TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'cancelled', 'failed')),
+    message_id UUID REFERENCES submission_messages(id) ON DELETE SET NULL,
+    recipients INTEGER,
+    error TEXT,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((submission_id IS NULL) <> (program_id IS NULL))
+);
+
+CREATE INDEX idx_scheduled_messages_due ON scheduled_messages(send_at) WHERE status = 'pending';
+CREATE INDEX idx_scheduled_messages_author_id ON scheduled_messages(author_id, send_at);
+
+COMMENT ON COLUMN scheduled_messages.program_id IS 'Set for announcements, which notify every student assigned to the program';
+COMMENT ON COLUMN scheduled_messages.recipients IS 'Number of users notified on delivery';
@@ -0,0 +1,29 @@
+-- Program discussion boards: topics and replies visible to everyone assigned to the program
+CREATE TABLE discussion_topics (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    youtube_url TEXT,
+    is_pinned BOOLEAN NOT NULL DEFAULT false,
+    is_locked BOOLEAN NOT NULL DEFAULT false,
+    last_activity_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE TABLE discussion_replies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    topic_id UUID NOT NULL REFERENCES discussion_topics(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    youtube_url TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_discussion_topics_program_id ON discussion_topics(program_id, is_pinned DESC, last_activity_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_discussion_replies_topic_id ON discussion_replies(topic_id, created_at);
+
+COMMENT ON COLUMN discussion_topics.is_locked IS 'Locked topics only accept replies from admins';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bookings;
+DROP TABLE IF EXISTS availability_slots;
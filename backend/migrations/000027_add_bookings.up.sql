@@ -0,0 +1,29 @@
+-- Office hours: instructors publish availability slots, students book them for 1:1 video reviews
+CREATE TABLE availability_slots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    instructor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE TABLE bookings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    slot_id UUID NOT NULL REFERENCES availability_slots(id) ON DELETE CASCADE,
+    student_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    note TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'confirmed' CHECK (status IN ('confirmed', 'cancelled')),
+    cancelled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    cancel_reason TEXT,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_availability_slots_instructor_id ON availability_slots(instructor_id, starts_at);
+CREATE INDEX idx_availability_slots_starts_at ON availability_slots(starts_at);
+CREATE UNIQUE INDEX idx_bookings_slot_confirmed ON bookings(slot_id) WHERE status = 'confirmed';
+CREATE INDEX idx_bookings_student_id ON bookings(student_id, status);
+
+COMMENT ON INDEX idx_bookings_slot_confirmed IS 'A slot has at most one confirmed booking; cancelled bookings free it again';
@@ -0,0 +1,3 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS meeting_url;
+ALTER TABLE bookings DROP COLUMN IF EXISTS meeting_id;
+ALTER TABLE bookings DROP COLUMN IF EXISTS meeting_provider;
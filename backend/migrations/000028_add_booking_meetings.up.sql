@@ -0,0 +1,7 @@
+-- Video meeting created for a booking by the configured provider (Jitsi, Zoom)
+ALTER TABLE bookings ADD COLUMN meeting_provider VARCHAR(20) DEFAULT NULL;
+ALTER TABLE bookings ADD COLUMN meeting_id VARCHAR(255) DEFAULT NULL;
+ALTER TABLE bookings ADD COLUMN meeting_url TEXT DEFAULT NULL;
+
+COMMENT ON COLUMN bookings.meeting_id IS 'Meeting identifier at the provider, used to delete the meeting when the booking is cancelled.';
+COMMENT ON COLUMN bookings.meeting_url IS 'Join link shared with the student and instructor. NULL when no provider is configured or creation failed.';
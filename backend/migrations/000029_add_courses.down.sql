@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS course_enrollments;
+DROP TABLE IF EXISTS course_module_programs;
+DROP TABLE IF EXISTS course_modules;
+DROP TABLE IF EXISTS courses;
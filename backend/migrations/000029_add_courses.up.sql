@@ -0,0 +1,46 @@
+-- Courses group programs into an ordered curriculum of modules that unlock over time
+CREATE TABLE courses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    is_published BOOLEAN NOT NULL DEFAULT false,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE course_modules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    course_id UUID NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    unlock_rule VARCHAR(20) NOT NULL DEFAULT 'immediate' CHECK (unlock_rule IN ('immediate', 'after_previous', 'after_days')),
+    unlock_after_days INTEGER CHECK (unlock_after_days >= 0),
+    UNIQUE (course_id, position)
+);
+
+CREATE TABLE course_module_programs (
+    module_id UUID NOT NULL REFERENCES course_modules(id) ON DELETE CASCADE,
+    program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    required_sessions INTEGER NOT NULL DEFAULT 1 CHECK (required_sessions > 0),
+    PRIMARY KEY (module_id, program_id)
+);
+
+CREATE TABLE course_enrollments (
+    course_id UUID NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    enrolled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    enrolled_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (course_id, user_id)
+);
+
+CREATE INDEX idx_course_module_programs_program_id ON course_module_programs(program_id);
+CREATE INDEX idx_course_enrollments_user_id ON course_enrollments(user_id);
+
+CREATE TRIGGER update_courses_updated_at BEFORE UPDATE ON courses
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON COLUMN course_modules.unlock_rule IS 'immediate: open on enrollment; after_previous: once all earlier modules are complete; after_days: unlock_after_days after enrollment.';
+COMMENT ON COLUMN course_module_programs.required_sessions IS 'Completed practice sessions of the program needed to count it as done.';
@@ -0,0 +1,50 @@
+-- Knowledge checks attached to programs; passing them counts towards course progress
+CREATE TABLE quizzes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    pass_threshold INTEGER NOT NULL DEFAULT 70 CHECK (pass_threshold BETWEEN 0 AND 100),
+    max_attempts INTEGER CHECK (max_attempts > 0),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE quiz_questions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    quiz_id UUID NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    prompt TEXT NOT NULL,
+    explanation TEXT,
+    UNIQUE (quiz_id, position)
+);
+
+CREATE TABLE quiz_choices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    question_id UUID NOT NULL REFERENCES quiz_questions(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    is_correct BOOLEAN NOT NULL DEFAULT false,
+    UNIQUE (question_id, position)
+);
+
+CREATE TABLE quiz_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    quiz_id UUID NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    answers JSONB NOT NULL DEFAULT '{}',
+    score DECIMAL(5,2) NOT NULL,
+    passed BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_quizzes_program_id ON quizzes(program_id);
+CREATE INDEX idx_quiz_attempts_quiz_user ON quiz_attempts(quiz_id, user_id, created_at);
+CREATE INDEX idx_quiz_attempts_user_passed ON quiz_attempts(user_id) WHERE passed = true;
+
+CREATE TRIGGER update_quizzes_updated_at BEFORE UPDATE ON quizzes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON COLUMN quizzes.pass_threshold IS 'Minimum score in percent for an attempt to pass.';
+COMMENT ON COLUMN quiz_attempts.answers IS 'Selected choice IDs per question ID. Kept as submitted so history survives quiz edits.';
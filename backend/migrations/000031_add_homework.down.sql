@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS homework_students;
+DROP TABLE IF EXISTS homework;
@@ -0,0 +1,33 @@
+-- Homework: program work due by a deadline, for a group or every student assigned to the program
+CREATE TABLE homework (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    group_id UUID REFERENCES groups(id) ON DELETE SET NULL,
+    title VARCHAR(255) NOT NULL,
+    instructions TEXT,
+    requirement VARCHAR(20) NOT NULL CHECK (requirement IN ('sessions', 'submission')),
+    required_sessions INTEGER NOT NULL DEFAULT 1 CHECK (required_sessions > 0),
+    due_at TIMESTAMP NOT NULL,
+    overdue_checked_at TIMESTAMP,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE homework_students (
+    homework_id UUID NOT NULL REFERENCES homework(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    overdue_at TIMESTAMP,
+    PRIMARY KEY (homework_id, user_id)
+);
+
+CREATE INDEX idx_homework_program_id ON homework(program_id);
+CREATE INDEX idx_homework_overdue_check ON homework(due_at) WHERE overdue_checked_at IS NULL;
+CREATE INDEX idx_homework_students_user_id ON homework_students(user_id);
+
+CREATE TRIGGER update_homework_updated_at BEFORE UPDATE ON homework
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON COLUMN homework.requirement IS 'sessions: required_sessions completed practice sessions of the program; submission: a new submission for the program. Only work done after the homework was set counts.';
+COMMENT ON COLUMN homework.overdue_checked_at IS 'When the overdue job processed the homework after its deadline; reset when the deadline moves.';
+COMMENT ON COLUMN homework_students.overdue_at IS 'When the student was marked overdue and notified for missing the deadline.';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS class_attendance;
+DROP TABLE IF EXISTS live_classes;
@@ -0,0 +1,38 @@
+-- Live classes: scheduled group lessons with attendance, next to solo practice sessions
+CREATE TABLE live_classes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    location VARCHAR(255),
+    program_id UUID REFERENCES programs(id) ON DELETE SET NULL,
+    group_id UUID REFERENCES groups(id) ON DELETE SET NULL,
+    instructor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    check_in_code VARCHAR(12),
+    check_in_expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE TABLE class_attendance (
+    class_id UUID NOT NULL REFERENCES live_classes(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('present', 'late', 'excused', 'absent')),
+    method VARCHAR(20) NOT NULL CHECK (method IN ('manual', 'code')),
+    marked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    marked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (class_id, user_id)
+);
+
+CREATE INDEX idx_live_classes_starts_at ON live_classes(starts_at);
+CREATE UNIQUE INDEX idx_live_classes_check_in_code ON live_classes(check_in_code) WHERE check_in_code IS NOT NULL;
+CREATE INDEX idx_class_attendance_user_id ON class_attendance(user_id);
+
+CREATE TRIGGER update_live_classes_updated_at BEFORE UPDATE ON live_classes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON COLUMN live_classes.group_id IS 'If set, only members of the group see the class and can check in';
+COMMENT ON COLUMN live_classes.check_in_code IS 'Short-lived code students enter to check themselves in, valid until check_in_expires_at';
+COMMENT ON COLUMN class_attendance.method IS 'manual: marked by an instructor; code: student checked in with the class code';
@@ -0,0 +1,8 @@
+-- Revert add_qr_check_ins
+UPDATE class_attendance SET method = 'code' WHERE method = 'qr';
+ALTER TABLE class_attendance DROP CONSTRAINT class_attendance_method_check;
+ALTER TABLE class_attendance ADD CONSTRAINT class_attendance_method_check CHECK (method IN ('manual', 'code'));
+
+COMMENT ON COLUMN class_attendance.method IS 'manual: marked by an instructor; code: student checked in with the class code';
+
+DROP TABLE IF EXISTS qr_check_ins;
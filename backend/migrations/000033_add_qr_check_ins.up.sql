@@ -0,0 +1,27 @@
+-- QR check-in: every scan of a signed QR token is recorded once per student, which rejects
+-- replays and keeps the device the student scanned with
+CREATE TABLE qr_check_ins (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    token_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    class_id UUID REFERENCES live_classes(id) ON DELETE CASCADE,
+    program_id UUID REFERENCES programs(id) ON DELETE CASCADE,
+    session_id UUID REFERENCES practice_sessions(id) ON DELETE SET NULL,
+    client_ip VARCHAR(64),
+    user_agent TEXT,
+    device VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (token_id, user_id),
+    CHECK ((class_id IS NULL) <> (program_id IS NULL))
+);
+
+CREATE INDEX idx_qr_check_ins_created_at ON qr_check_ins(created_at);
+
+ALTER TABLE class_attendance DROP CONSTRAINT class_attendance_method_check;
+ALTER TABLE class_attendance ADD CONSTRAINT class_attendance_method_check
+    CHECK (method IN ('manual', 'code', 'qr')) NOT VALID;
+ALTER TABLE class_attendance VALIDATE CONSTRAINT class_attendance_method_check;
+
+COMMENT ON COLUMN qr_check_ins.token_id IS 'jti of the scanned QR token; a token is accepted once per student';
+COMMENT ON COLUMN qr_check_ins.device IS 'Client-reported X-Device-Info header, if sent';
+COMMENT ON COLUMN class_attendance.method IS 'manual: marked by an instructor; code: student checked in with the class code; qr: student scanned the class QR code';
@@ -0,0 +1,2 @@
+-- Revert add_display_tokens
+DROP TABLE IF EXISTS display_tokens;
@@ -0,0 +1,17 @@
+-- Display tokens: read-only credentials for shared screens (e.g. a studio wall display) that can
+-- only read the timers of the programs they are scoped to
+CREATE TABLE display_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    program_ids UUID[] NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN display_tokens.token_hash IS 'SHA-256 of the display token; the token itself is only returned once on creation';
+COMMENT ON COLUMN display_tokens.program_ids IS 'Programs the display may read';
+COMMENT ON COLUMN display_tokens.expires_at IS 'NULL for displays that stay valid until revoked';
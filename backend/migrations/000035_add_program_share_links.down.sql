@@ -0,0 +1,2 @@
+-- Revert add_program_share_links
+DROP TABLE IF EXISTS program_share_links;
@@ -0,0 +1,16 @@
+-- Program share links: expiring, revocable public URLs to a read-only view of a program
+CREATE TABLE program_share_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    view_count INTEGER NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_program_share_links_program_id ON program_share_links(program_id);
+
+COMMENT ON COLUMN program_share_links.token_hash IS 'SHA-256 of the share token; the token itself is only returned once on creation';
@@ -0,0 +1,2 @@
+-- Revert add_notification_preferences
+DROP TABLE IF EXISTS notification_preferences;
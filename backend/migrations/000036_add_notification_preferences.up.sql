@@ -0,0 +1,15 @@
+-- Per-user settings for notifications sent outside the app, such as the weekly email digest.
+-- Users without a row get the defaults.
+CREATE TABLE notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    weekly_digest BOOLEAN NOT NULL DEFAULT true,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    last_digest_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_notification_preferences_updated_at BEFORE UPDATE ON notification_preferences
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON COLUMN notification_preferences.timezone IS 'IANA time zone the digest send window is evaluated in, e.g. Europe/Berlin';
+COMMENT ON COLUMN notification_preferences.last_digest_at IS 'When the digest job last claimed the user; at most one digest is sent per send window.';
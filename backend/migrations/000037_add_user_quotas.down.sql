@@ -0,0 +1,4 @@
+-- Revert add_user_quotas
+ALTER TABLE programs DROP COLUMN IF EXISTS cover_size_bytes;
+DROP TABLE IF EXISTS user_quotas;
+DROP TABLE IF EXISTS quota_plans;
@@ -0,0 +1,34 @@
+-- Soft limits on user-generated content. A limit of NULL is unlimited.
+CREATE TABLE quota_plans (
+    name VARCHAR(50) PRIMARY KEY,
+    max_programs INTEGER CHECK (max_programs >= 0),
+    max_open_submissions INTEGER CHECK (max_open_submissions >= 0),
+    max_storage_bytes BIGINT CHECK (max_storage_bytes >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Users without a plan are on the default plan, which starts out unlimited
+INSERT INTO quota_plans (name) VALUES ('default');
+
+-- A user's plan and per-user overrides. A NULL override falls back to the plan's limit.
+CREATE TABLE user_quotas (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    plan VARCHAR(50) REFERENCES quota_plans(name) ON UPDATE CASCADE ON DELETE SET NULL,
+    max_programs INTEGER CHECK (max_programs >= 0),
+    max_open_submissions INTEGER CHECK (max_open_submissions >= 0),
+    max_storage_bytes BIGINT CHECK (max_storage_bytes >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_quotas_plan ON user_quotas(plan);
+
+CREATE TRIGGER update_quota_plans_updated_at BEFORE UPDATE ON quota_plans
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_user_quotas_updated_at BEFORE UPDATE ON user_quotas
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Size of the stored cover variants, counted against the owner's storage quota
+ALTER TABLE programs ADD COLUMN cover_size_bytes BIGINT;
+
+COMMENT ON COLUMN programs.cover_size_bytes IS 'Total bytes of the cover thumbnails at upload. NULL for covers set before quotas were tracked.';
@@ -0,0 +1,5 @@
+-- Revert add_moderation
+DROP TABLE IF EXISTS moderation_reports;
+DROP TABLE IF EXISTS moderation_cases;
+ALTER TABLE programs DROP COLUMN IF EXISTS hidden_at;
+ALTER TABLE submission_messages DROP COLUMN IF EXISTS hidden_at;
@@ -0,0 +1,38 @@
+-- Content hidden by moderation stays visible to its author and admins
+ALTER TABLE submission_messages ADD COLUMN hidden_at TIMESTAMP;
+ALTER TABLE programs ADD COLUMN hidden_at TIMESTAMP;
+
+-- A moderation case collects the abuse reports on one piece of content until an admin decides.
+-- resolved dismisses the reports, hidden keeps the content hidden.
+CREATE TABLE moderation_cases (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    content_type VARCHAR(20) NOT NULL CHECK (content_type IN ('message', 'program')),
+    content_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'resolved', 'hidden')),
+    report_count INTEGER NOT NULL DEFAULT 0,
+    auto_hidden BOOLEAN NOT NULL DEFAULT false,
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    resolution_note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- New reports join the open case; content reported again after a decision gets a new case
+CREATE UNIQUE INDEX idx_moderation_cases_pending ON moderation_cases(content_type, content_id) WHERE status = 'pending';
+CREATE INDEX idx_moderation_cases_status ON moderation_cases(status, updated_at DESC);
+
+CREATE TRIGGER update_moderation_cases_updated_at BEFORE UPDATE ON moderation_cases
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE moderation_reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    case_id UUID NOT NULL REFERENCES moderation_cases(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('spam', 'harassment', 'inappropriate', 'other')),
+    details TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (case_id, reporter_id)
+);
+
+CREATE INDEX idx_moderation_reports_reporter ON moderation_reports(reporter_id);
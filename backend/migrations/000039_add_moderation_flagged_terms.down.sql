@@ -0,0 +1,2 @@
+-- Revert add_moderation_flagged_terms
+ALTER TABLE moderation_cases DROP COLUMN IF EXISTS flagged_terms;
@@ -0,0 +1,3 @@
+-- Terms the content filter matched, for cases it opened. Users can still report the content;
+-- their reports join the same case.
+ALTER TABLE moderation_cases ADD COLUMN flagged_terms TEXT[];
@@ -0,0 +1,2 @@
+-- Revert index_submission_messages_user_recent
+DROP INDEX CONCURRENTLY IF EXISTS idx_submission_messages_user_created;
@@ -0,0 +1,3 @@
+-- A user's recent messages, for message throttling and duplicate detection
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_submission_messages_user_created
+    ON submission_messages (user_id, created_at DESC);
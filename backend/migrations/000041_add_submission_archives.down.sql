@@ -0,0 +1,2 @@
+-- Revert add_submission_archives
+DROP TABLE IF EXISTS submission_archives;
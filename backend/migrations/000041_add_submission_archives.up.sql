@@ -0,0 +1,10 @@
+-- Threads a user archived to clear their inbox. Archiving is per user, unlike soft delete, and a
+-- new message in the thread unarchives it for everyone.
+CREATE TABLE submission_archives (
+    submission_id UUID NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, submission_id)
+);
+
+CREATE INDEX idx_submission_archives_submission ON submission_archives(submission_id);
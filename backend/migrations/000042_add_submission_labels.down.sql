@@ -0,0 +1,3 @@
+-- Revert add_submission_labels
+DROP TABLE IF EXISTS submission_label_assignments;
+DROP TABLE IF EXISTS submission_labels;
@@ -0,0 +1,22 @@
+-- Labels instructors attach to submissions to organize the review queue. They are shared by
+-- all instructors and never shown to students.
+CREATE TABLE submission_labels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '#9e9e9e' CHECK (color ~ '^#[0-9a-f]{6}$'),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_submission_labels_name ON submission_labels(LOWER(name));
+
+CREATE TABLE submission_label_assignments (
+    submission_id UUID NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    label_id UUID NOT NULL REFERENCES submission_labels(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (submission_id, label_id)
+);
+
+CREATE INDEX idx_submission_label_assignments_label ON submission_label_assignments(label_id);
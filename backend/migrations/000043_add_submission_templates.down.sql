@@ -0,0 +1,2 @@
+-- Revert add_submission_templates
+DROP TABLE IF EXISTS submission_templates;
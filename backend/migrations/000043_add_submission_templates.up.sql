@@ -0,0 +1,12 @@
+-- What a submission for a program should look like: a required title format and prompts such as
+-- "film from the side, 2 minutes" shown to students before they start a thread
+CREATE TABLE submission_templates (
+    program_id UUID PRIMARY KEY REFERENCES programs(id) ON DELETE CASCADE,
+    title_pattern TEXT,
+    title_example VARCHAR(255),
+    prompts TEXT[] NOT NULL DEFAULT '{}',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN submission_templates.title_pattern IS 'Go regular expression the whole title must match';
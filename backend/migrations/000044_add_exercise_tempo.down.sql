@@ -0,0 +1,2 @@
+-- Revert add_exercise_tempo
+ALTER TABLE exercises DROP COLUMN IF EXISTS tempo;
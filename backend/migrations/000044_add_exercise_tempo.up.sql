@@ -0,0 +1,3 @@
+-- Pacing for repetition exercises: seconds per rep, prep time before the first rep and the pause
+-- between reps, e.g. {"seconds_per_rep": 8, "prep_seconds": 10, "transition_seconds": 2}
+ALTER TABLE exercises ADD COLUMN tempo JSONB;
@@ -0,0 +1,2 @@
+-- Revert add_exercise_breathing_pattern
+ALTER TABLE exercises DROP COLUMN IF EXISTS breathing_pattern;
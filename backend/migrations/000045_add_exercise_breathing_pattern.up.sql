@@ -0,0 +1,3 @@
+-- Breath counts for exercises defined by breathing rather than wall time, e.g.
+-- {"inhale_seconds": 4, "hold_seconds": 2, "exhale_seconds": 6, "cycles": 9}
+ALTER TABLE exercises ADD COLUMN breathing_pattern JSONB;
@@ -0,0 +1,3 @@
+-- Revert add_exercise_substitutes
+ALTER TABLE exercise_logs DROP COLUMN IF EXISTS substitute_id;
+DROP TABLE IF EXISTS exercise_substitutes;
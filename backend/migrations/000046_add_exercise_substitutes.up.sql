@@ -0,0 +1,20 @@
+-- Variants students can do instead of an exercise, such as a seated version for knee injuries.
+-- Timing left NULL is taken from the exercise.
+CREATE TABLE exercise_substitutes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    exercise_id UUID NOT NULL REFERENCES exercises(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    reason VARCHAR(255),
+    duration_seconds INTEGER CHECK (duration_seconds > 0),
+    repetitions INTEGER CHECK (repetitions > 0),
+    side_duration_seconds INTEGER CHECK (side_duration_seconds > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_exercise_substitutes_exercise ON exercise_substitutes(exercise_id);
+
+-- The variant performed, if the student did a substitute
+ALTER TABLE exercise_logs
+ADD COLUMN substitute_id UUID REFERENCES exercise_substitutes(id) ON DELETE SET NULL;
@@ -0,0 +1,5 @@
+-- Revert add_limitations
+DROP TABLE IF EXISTS plan_reviews;
+ALTER TABLE exercise_substitutes DROP COLUMN IF EXISTS contraindications;
+ALTER TABLE exercises DROP COLUMN IF EXISTS contraindications;
+DROP TABLE IF EXISTS user_limitations;
@@ -0,0 +1,26 @@
+-- Injury and health profiles of students. Exercises and substitutes list the limitations they
+-- are contraindicated for; contraindicated exercises are substituted or flagged in the student's
+-- programs, and instructors review the adjusted plan.
+CREATE TABLE user_limitations (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    joints TEXT[] NOT NULL DEFAULT '{}',
+    conditions TEXT[] NOT NULL DEFAULT '{}',
+    notes TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE exercises ADD COLUMN contraindications TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE exercise_substitutes ADD COLUMN contraindications TEXT[] NOT NULL DEFAULT '{}';
+
+-- An instructor's review of a student's adjusted program. The review applies to the profile as
+-- of limitations_updated_at; changing the profile makes the plan pending again.
+CREATE TABLE plan_reviews (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('approved', 'changes_requested')),
+    note TEXT,
+    limitations_updated_at TIMESTAMP NOT NULL,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, program_id)
+);
@@ -0,0 +1,3 @@
+-- Revert add_progress_journal
+DROP TABLE IF EXISTS journal_shares;
+DROP TABLE IF EXISTS journal_entries;
@@ -0,0 +1,27 @@
+-- Private progress journal: form-check photos and videos students record for a program. Media
+-- lives in private storage and is only served to the student and instructors it is shared with.
+CREATE TABLE journal_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    program_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    exercise_id UUID REFERENCES exercises(id) ON DELETE SET NULL,
+    caption TEXT NOT NULL DEFAULT '',
+    media_key VARCHAR(255) NOT NULL,
+    media_type VARCHAR(10) NOT NULL CHECK (media_type IN ('photo', 'video')),
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_journal_entries_user_recorded ON journal_entries(user_id, recorded_at DESC);
+
+-- Instructors a student shared an entry with
+CREATE TABLE journal_shares (
+    entry_id UUID NOT NULL REFERENCES journal_entries(id) ON DELETE CASCADE,
+    instructor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    shared_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entry_id, instructor_id)
+);
+
+CREATE INDEX idx_journal_shares_instructor ON journal_shares(instructor_id);
@@ -0,0 +1,3 @@
+-- Revert add_practice_diary
+DROP TABLE IF EXISTS diary_entry_sessions;
+DROP TABLE IF EXISTS diary_entries;
@@ -0,0 +1,25 @@
+-- Practice diary: free-form daily reflections in Markdown, separate from session notes.
+-- Entries are private unless the student shares them with their instructors.
+CREATE TABLE diary_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    entry_date DATE NOT NULL,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    shared BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_diary_entries_user_date ON diary_entries(user_id, entry_date DESC);
+CREATE INDEX idx_diary_entries_search ON diary_entries
+    USING GIN (to_tsvector('simple', title || ' ' || content));
+
+-- Practice sessions an entry reflects on
+CREATE TABLE diary_entry_sessions (
+    entry_id UUID NOT NULL REFERENCES diary_entries(id) ON DELETE CASCADE,
+    session_id UUID NOT NULL REFERENCES practice_sessions(id) ON DELETE CASCADE,
+    PRIMARY KEY (entry_id, session_id)
+);
+
+CREATE INDEX idx_diary_entry_sessions_session ON diary_entry_sessions(session_id);
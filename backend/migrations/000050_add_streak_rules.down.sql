@@ -0,0 +1,2 @@
+-- Revert add_streak_rules
+DROP TABLE IF EXISTS user_streak_rules;
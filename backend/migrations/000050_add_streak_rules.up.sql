@@ -0,0 +1,11 @@
+-- Per-user overrides of the deployment's streak policy. A NULL override falls back to it.
+CREATE TABLE user_streak_rules (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    rest_days_per_week INTEGER CHECK (rest_days_per_week BETWEEN 0 AND 6),
+    min_minutes INTEGER CHECK (min_minutes BETWEEN 0 AND 1440),
+    grace_hours INTEGER CHECK (grace_hours BETWEEN 0 AND 12),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_user_streak_rules_updated_at BEFORE UPDATE ON user_streak_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+-- Revert add_stats_recompute
+DROP TABLE IF EXISTS stats_recomputes;
+DROP TABLE IF EXISTS user_streaks;
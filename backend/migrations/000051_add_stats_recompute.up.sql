@@ -0,0 +1,33 @@
+-- Streaks as last computed for each user. A snapshot is valid for the day it was computed on
+-- until the user's sessions or streak rules change.
+CREATE TABLE user_streaks (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    current_streak INTEGER NOT NULL,
+    longest_streak INTEGER NOT NULL,
+    as_of DATE NOT NULL,
+    computed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Background recomputation of stored stats for one user or everyone. Users are processed in
+-- id order and the cursor saved after each batch, so an interrupted job resumes where it stopped.
+CREATE TABLE stats_recomputes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    total INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    cursor UUID,
+    error TEXT,
+    locked_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX idx_stats_recomputes_open ON stats_recomputes(created_at)
+    WHERE status IN ('pending', 'running');
+
+CREATE TRIGGER update_stats_recomputes_updated_at BEFORE UPDATE ON stats_recomputes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
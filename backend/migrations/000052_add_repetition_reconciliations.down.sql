@@ -0,0 +1,2 @@
+-- Revert add_repetition_reconciliations
+DROP TABLE IF EXISTS repetition_reconciliations;
@@ -0,0 +1,10 @@
+-- Nightly checks of programs.repetitions_completed against the completed sessions. Each run
+-- records the programs whose stored count had drifted and was corrected.
+CREATE TABLE repetition_reconciliations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    programs_checked INTEGER NOT NULL,
+    drifted JSONB NOT NULL DEFAULT '[]',
+    ran_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_repetition_reconciliations_ran_at ON repetition_reconciliations(ran_at DESC);
@@ -0,0 +1,3 @@
+-- Revert add_support_tickets
+DROP TABLE IF EXISTS support_messages;
+DROP TABLE IF EXISTS support_tickets;
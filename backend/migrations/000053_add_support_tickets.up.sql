@@ -0,0 +1,25 @@
+-- Support tickets: questions to the admins that are not about a program, kept out of
+-- submission threads. A ticket is open while it waits for an admin and answered while it
+-- waits for the student.
+CREATE TABLE support_tickets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category VARCHAR(20) NOT NULL CHECK (category IN ('technical', 'account', 'billing', 'other')),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'answered', 'closed')),
+    last_activity_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP
+);
+
+CREATE INDEX idx_support_tickets_user ON support_tickets(user_id, last_activity_at DESC);
+CREATE INDEX idx_support_tickets_queue ON support_tickets(status, last_activity_at);
+
+CREATE TABLE support_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticket_id UUID NOT NULL REFERENCES support_tickets(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_support_messages_ticket ON support_messages(ticket_id, created_at);
@@ -0,0 +1,3 @@
+-- Revert add_changelog
+DROP TABLE IF EXISTS changelog_dismissals;
+DROP TABLE IF EXISTS changelog_entries;
@@ -0,0 +1,21 @@
+-- Changelog: release notes admins write for the apps. Users dismiss entries once they have
+-- seen them, so every device shows them only once.
+CREATE TABLE changelog_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    version VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    audience VARCHAR(20) NOT NULL DEFAULT 'all' CHECK (audience IN ('all', 'students', 'admins')),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_changelog_entries_created ON changelog_entries(created_at DESC);
+
+CREATE TABLE changelog_dismissals (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    entry_id UUID NOT NULL REFERENCES changelog_entries(id) ON DELETE CASCADE,
+    dismissed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, entry_id)
+);
@@ -0,0 +1,2 @@
+-- Revert add_client_version_policies
+DROP TABLE IF EXISTS client_version_policies;
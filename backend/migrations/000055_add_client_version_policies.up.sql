@@ -0,0 +1,8 @@
+-- Admin overrides of the configured client version policy, per platform
+CREATE TABLE client_version_policies (
+    platform VARCHAR(20) PRIMARY KEY CHECK (platform IN ('ios', 'android', 'web')),
+    min_version VARCHAR(50) NOT NULL, -- Empty allows every version
+    upgrade_url TEXT NOT NULL DEFAULT '',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
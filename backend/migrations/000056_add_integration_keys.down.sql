@@ -0,0 +1,3 @@
+-- Revert add_integration_keys
+DROP TABLE IF EXISTS integration_nonces;
+DROP TABLE IF EXISTS integration_keys;
@@ -0,0 +1,24 @@
+-- Keys for server-to-server integrations (e.g. the school website pulling public programs),
+-- which sign each request with HMAC-SHA256 instead of signing in as a user
+CREATE TABLE integration_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN integration_keys.secret IS 'HMAC signing secret; kept in full since signatures are verified with it, only returned once on creation';
+
+-- Nonces of accepted signed requests, so a captured request cannot be replayed. Only kept for
+-- the timestamp tolerance; older requests are refused by their timestamp anyway.
+CREATE TABLE integration_nonces (
+    key_id UUID NOT NULL REFERENCES integration_keys(id) ON DELETE CASCADE,
+    nonce VARCHAR(100) NOT NULL,
+    seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (key_id, nonce)
+);
+
+CREATE INDEX idx_integration_nonces_seen_at ON integration_nonces(seen_at);
@@ -0,0 +1,5 @@
+-- Revert encrypt_sensitive_columns
+-- Encrypted secrets do not fit the old column; decrypt them first with cmd/encryption
+COMMENT ON COLUMN user_limitations.notes IS NULL;
+COMMENT ON COLUMN integration_keys.secret IS 'HMAC signing secret; kept in full since signatures are verified with it, only returned once on creation';
+ALTER TABLE integration_keys ALTER COLUMN secret TYPE VARCHAR(64);
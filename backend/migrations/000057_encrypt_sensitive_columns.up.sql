@@ -0,0 +1,7 @@
+-- Sensitive columns may hold values encrypted by the application (enc:<key id>:<base64>), which
+-- are longer than the plain text
+-- lint:ignore alter-column-type varchar to text is binary compatible, Postgres does not rewrite the table
+ALTER TABLE integration_keys ALTER COLUMN secret TYPE TEXT;
+
+COMMENT ON COLUMN integration_keys.secret IS 'HMAC signing secret, encrypted when ENCRYPTION_KEY is set; only returned once on creation';
+COMMENT ON COLUMN user_limitations.notes IS 'Health details for the instructor, encrypted when ENCRYPTION_KEY is set';
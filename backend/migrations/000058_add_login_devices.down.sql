@@ -0,0 +1,2 @@
+-- Revert add_login_devices
+DROP TABLE IF EXISTS login_devices;
@@ -0,0 +1,20 @@
+-- Devices users have signed in from, recognized by user agent and network. A sign-in from an
+-- unknown device alerts the user, with a link to revoke that device if it wasn't them.
+CREATE TABLE login_devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    fingerprint VARCHAR(64) NOT NULL,
+    client_ip VARCHAR(45),
+    user_agent TEXT,
+    device VARCHAR(255),
+    revoke_token_hash VARCHAR(64) NOT NULL UNIQUE,
+    first_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP,
+    UNIQUE (user_id, fingerprint)
+);
+
+CREATE INDEX idx_login_devices_revoked_at ON login_devices(revoked_at) WHERE revoked_at IS NOT NULL;
+
+COMMENT ON COLUMN login_devices.fingerprint IS 'SHA-256 of the user agent, X-Device-Info and client network';
+COMMENT ON COLUMN login_devices.revoke_token_hash IS 'SHA-256 of the token in the "this wasn''t me" link of the new device alert';
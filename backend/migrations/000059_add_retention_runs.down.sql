@@ -0,0 +1,2 @@
+-- Revert add_retention_runs
+DROP TABLE IF EXISTS retention_runs;
@@ -0,0 +1,19 @@
+-- Data retention: each run of a retention rule records how many rows it deleted or stripped per
+-- table, or would have on a dry run, so admins can check a rule before letting it delete.
+CREATE TABLE retention_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rule VARCHAR(32) NOT NULL,
+    dry_run BOOLEAN NOT NULL,
+    scheduled BOOLEAN NOT NULL,
+    cutoff TIMESTAMP NOT NULL,
+    affected JSONB NOT NULL DEFAULT '{}',
+    triggered_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    ran_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_retention_runs_rule ON retention_runs(rule, ran_at DESC);
+CREATE INDEX idx_retention_runs_scheduled ON retention_runs(rule, ran_at DESC) WHERE scheduled;
+
+COMMENT ON COLUMN retention_runs.affected IS 'Rows per table, e.g. {"practice_sessions": 12}';
+COMMENT ON COLUMN retention_runs.triggered_by IS 'Admin who requested the dry run; NULL for scheduled runs';
+COMMENT ON COLUMN retention_runs.scheduled IS 'Run by the daily job rather than requested by an admin; the job runs each rule once a day';
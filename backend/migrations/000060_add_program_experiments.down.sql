@@ -0,0 +1,5 @@
+-- Revert add_program_experiments
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS variant;
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS experiment_id;
+DROP TABLE IF EXISTS experiment_participants;
+DROP TABLE IF EXISTS program_experiments;
@@ -0,0 +1,29 @@
+-- A/B experiments: two variants of a program, e.g. with different rest times. Students are
+-- assigned one variant at random and their sessions of it are tagged with the variant, so the
+-- variants' completion and adherence can be compared.
+CREATE TABLE program_experiments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    program_a_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    program_b_id UUID NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (program_a_id <> program_b_id)
+);
+
+CREATE TABLE experiment_participants (
+    experiment_id UUID NOT NULL REFERENCES program_experiments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    variant CHAR(1) NOT NULL CHECK (variant IN ('a', 'b')),
+    assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (experiment_id, user_id)
+);
+
+CREATE INDEX idx_experiment_participants_user_id ON experiment_participants(user_id);
+
+ALTER TABLE practice_sessions ADD COLUMN experiment_id UUID REFERENCES program_experiments(id) ON DELETE SET NULL;
+ALTER TABLE practice_sessions ADD COLUMN variant CHAR(1);
+
+COMMENT ON COLUMN practice_sessions.experiment_id IS 'Experiment the session''s program was a variant of for the student when it started';
+COMMENT ON COLUMN practice_sessions.variant IS 'a or b, the variant of experiment_id the session was practiced in';
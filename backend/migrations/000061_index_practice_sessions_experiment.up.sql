@@ -0,0 +1,3 @@
+-- Sessions of an experiment's variants, for comparing them
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_practice_sessions_experiment
+    ON practice_sessions (experiment_id, variant) WHERE experiment_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Revert add_session_questionnaires
+DROP TABLE IF EXISTS session_answers;
+DROP TABLE IF EXISTS session_questionnaires;
@@ -0,0 +1,22 @@
+-- Self-assessment after a session: a program's questionnaire of scale and free-text questions,
+-- answered when the session is completed or afterwards. Replaces the single notes field for
+-- programs that have one.
+CREATE TABLE session_questionnaires (
+    program_id UUID PRIMARY KEY REFERENCES programs(id) ON DELETE CASCADE,
+    questions JSONB NOT NULL DEFAULT '[]',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE session_answers (
+    session_id UUID NOT NULL REFERENCES practice_sessions(id) ON DELETE CASCADE,
+    question_id VARCHAR(50) NOT NULL,
+    scale_value SMALLINT,
+    text_value TEXT,
+    answered_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (session_id, question_id),
+    CHECK ((scale_value IS NULL) <> (text_value IS NULL))
+);
+
+COMMENT ON COLUMN session_questionnaires.questions IS 'Ordered questions: id, type (scale or text), prompt, and min, max and their labels for scales';
+COMMENT ON COLUMN session_answers.question_id IS 'id of the question in the questionnaire of the session''s program';
@@ -0,0 +1,12 @@
+-- Revert add_record_corrections
+ALTER TABLE session_edits
+    DROP COLUMN IF EXISTS reason,
+    DROP COLUMN IF EXISTS exercise_log_id;
+
+ALTER TABLE exercise_logs
+    DROP COLUMN IF EXISTS edited_by,
+    DROP COLUMN IF EXISTS edited_at;
+
+ALTER TABLE practice_sessions
+    DROP COLUMN IF EXISTS edited_by,
+    DROP COLUMN IF EXISTS edited_at;
@@ -0,0 +1,16 @@
+-- Corrections of recorded practice data: sessions and exercise logs are flagged once edited,
+-- and the session's edit history also covers its exercise logs, with the reason for admin
+-- corrections
+ALTER TABLE practice_sessions
+    ADD COLUMN edited_at TIMESTAMP,
+    ADD COLUMN edited_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE exercise_logs
+    ADD COLUMN edited_at TIMESTAMP,
+    ADD COLUMN edited_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE session_edits
+    ADD COLUMN exercise_log_id UUID REFERENCES exercise_logs(id) ON DELETE CASCADE,
+    ADD COLUMN reason TEXT;
+
+COMMENT ON COLUMN session_edits.exercise_log_id IS 'Set when the edit corrected one of the session''s exercise logs rather than the session';
@@ -0,0 +1,2 @@
+-- Revert index_practice_sessions_program
+DROP INDEX CONCURRENTLY IF EXISTS idx_practice_sessions_program;
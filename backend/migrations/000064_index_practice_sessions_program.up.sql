@@ -0,0 +1,3 @@
+-- Sessions of a program, newest first, for the admin activity feed
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_practice_sessions_program
+    ON practice_sessions (program_id, started_at DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,31 @@
+-- Revert encrypt_health_details
+-- Encrypted values cannot be read here; decrypt them first with cmd/encryption
+CREATE TABLE session_biometrics (
+    session_id UUID NOT NULL REFERENCES practice_sessions(id) ON DELETE CASCADE,
+    recorded_at TIMESTAMP NOT NULL,
+    heart_rate SMALLINT CHECK (heart_rate BETWEEN 20 AND 250),
+    hrv_ms DOUBLE PRECISION CHECK (hrv_ms >= 0),
+    PRIMARY KEY (session_id, recorded_at)
+);
+
+INSERT INTO session_biometrics (session_id, recorded_at, heart_rate, hrv_ms)
+SELECT b.session_id,
+       (s->>'recorded_at')::timestamptz AT TIME ZONE 'UTC',
+       (s->>'heart_rate')::smallint,
+       (s->>'hrv_ms')::double precision
+FROM session_biometric_samples b, json_array_elements(b.samples::json) s
+WHERE b.samples <> '';
+
+DROP TABLE session_biometric_samples;
+
+ALTER TABLE user_limitations
+    ADD COLUMN joints_list TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN conditions_list TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE user_limitations SET
+    joints_list = ARRAY(SELECT json_array_elements_text(joints::json)),
+    conditions_list = ARRAY(SELECT json_array_elements_text(conditions::json));
+
+ALTER TABLE user_limitations DROP COLUMN joints, DROP COLUMN conditions;
+ALTER TABLE user_limitations RENAME COLUMN joints_list TO joints;
+ALTER TABLE user_limitations RENAME COLUMN conditions_list TO conditions;
@@ -0,0 +1,36 @@
+-- Raw wearable samples and the joints and conditions of limitations are health data, encrypted by
+-- the application like the limitation notes. Only the biometric summary on practice_sessions
+-- stays in plain text for stats queries.
+-- lint:ignore destructive the samples are copied to session_biometric_samples before session_biometrics is dropped
+-- lint:ignore alter-column-type user_limitations has at most one small row per student
+
+-- A session's samples as one JSON array ([{"recorded_at", "heart_rate", "hrv_ms"}, ...] by time),
+-- encrypted when ENCRYPTION_KEY is set
+CREATE TABLE session_biometric_samples (
+    session_id UUID PRIMARY KEY REFERENCES practice_sessions(id) ON DELETE CASCADE,
+    samples TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO session_biometric_samples (session_id, samples)
+SELECT session_id,
+       json_agg(json_build_object(
+           'recorded_at', recorded_at AT TIME ZONE 'UTC',
+           'heart_rate', heart_rate,
+           'hrv_ms', hrv_ms
+       ) ORDER BY recorded_at)::text
+FROM session_biometrics
+GROUP BY session_id;
+
+DROP TABLE session_biometrics;
+
+-- Joints and conditions as JSON arrays, which can be encrypted
+ALTER TABLE user_limitations
+    ALTER COLUMN joints DROP DEFAULT,
+    ALTER COLUMN joints TYPE TEXT USING array_to_json(joints)::text,
+    ALTER COLUMN joints SET DEFAULT '[]',
+    ALTER COLUMN conditions DROP DEFAULT,
+    ALTER COLUMN conditions TYPE TEXT USING array_to_json(conditions)::text,
+    ALTER COLUMN conditions SET DEFAULT '[]';
+
+COMMENT ON COLUMN user_limitations.joints IS 'JSON array of affected joints, encrypted when ENCRYPTION_KEY is set';
+COMMENT ON COLUMN user_limitations.conditions IS 'JSON array of health conditions, encrypted when ENCRYPTION_KEY is set';
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// CheckInToken is the type of the short-lived tokens shown as QR codes at in-person sessions
+const CheckInToken TokenType = "check_in"
+
+// CheckInClaims represents the claims of a QR check-in token, which is for either a class or a program
+type CheckInClaims struct {
+	ClassID   string    `json:"class_id,omitempty"`
+	ProgramID string    `json:"program_id,omitempty"`
+	TokenType TokenType `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// GenerateCheckInToken signs a check-in token issued at now for a class or a program
+func GenerateCheckInToken(classID, programID, secret string, now time.Time, expiry time.Duration) (string, error) {
+	claims := &CheckInClaims{
+		ClassID:   classID,
+		ProgramID: programID,
+		TokenType: CheckInToken,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "xuangong-api",
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
+
+// ValidateCheckInToken validates a check-in token and returns its claims. Expiry is checked against now.
+func ValidateCheckInToken(tokenString, secret string, now time.Time) (*CheckInClaims, error) {
+	return ValidateCheckInTokenWithSecrets(tokenString, []string{secret}, now)
+}
+
+// ValidateCheckInTokenWithSecrets is ValidateCheckInToken accepting a token signed with any of
+// the secrets, for tokens issued before the signing secret was rotated
+func ValidateCheckInTokenWithSecrets(tokenString string, secrets []string, now time.Time) (*CheckInClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &CheckInClaims{}, hmacKeys(secrets), jwt.WithTimeFunc(func() time.Time { return now }))
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*CheckInClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	if claims.TokenType != CheckInToken {
+		return nil, fmt.Errorf("invalid token type: expected %s, got %s", CheckInToken, claims.TokenType)
+	}
+	if (claims.ClassID == "") == (claims.ProgramID == "") {
+		return nil, fmt.Errorf("check-in token must be for either a class or a program")
+	}
+
+	return claims, nil
+}
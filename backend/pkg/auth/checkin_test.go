@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateCheckInToken(t *testing.T) {
+	issued := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	token, err := GenerateCheckInToken("class-1", "", "secret", issued, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateCheckInToken() error = %v", err)
+	}
+
+	claims, err := ValidateCheckInToken(token, "secret", issued.Add(30*time.Second))
+	if err != nil {
+		t.Fatalf("check-in token should be valid before expiry: %v", err)
+	}
+	if claims.ClassID != "class-1" || claims.ID == "" {
+		t.Errorf("claims = %+v, want class-1 with a token id", claims)
+	}
+	if _, err := ValidateCheckInToken(token, "secret", issued.Add(2*time.Minute)); err == nil {
+		t.Error("check-in token should be expired after a minute")
+	}
+	if _, err := ValidateCheckInToken(token, "other-secret", issued); err == nil {
+		t.Error("check-in token signed with another secret should be rejected")
+	}
+	if _, err := ValidateToken(token, "secret", AccessToken, issued); err == nil {
+		t.Error("check-in token should be rejected as access token")
+	}
+
+	pair, err := GenerateTokenPair("user-1", "user@test.com", "student", "secret", issued, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+	if _, err := ValidateCheckInToken(pair.AccessToken, "secret", issued); err == nil {
+		t.Error("access token should be rejected as check-in token")
+	}
+}
@@ -21,6 +21,9 @@ type Claims struct {
 	Email     string    `json:"email"`
 	Role      string    `json:"role"`
 	TokenType TokenType `json:"token_type"`
+	// The login device the token was issued to; empty for tokens not issued on a sign-in, such
+	// as on registration or impersonation
+	DeviceID string `json:"device_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -30,16 +33,21 @@ type TokenPair struct {
 	ExpiresIn    int64  `json:"expires_in"`
 }
 
-// GenerateTokenPair creates both access and refresh tokens
-func GenerateTokenPair(userID, email, role, secret string, accessExpiry, refreshExpiry time.Duration) (*TokenPair, error) {
+// GenerateTokenPair creates both access and refresh tokens issued at now
+func GenerateTokenPair(userID, email, role, secret string, now time.Time, accessExpiry, refreshExpiry time.Duration) (*TokenPair, error) {
+	return GenerateDeviceTokenPair(userID, email, role, "", secret, now, accessExpiry, refreshExpiry)
+}
+
+// GenerateDeviceTokenPair is GenerateTokenPair for tokens bound to a login device
+func GenerateDeviceTokenPair(userID, email, role, deviceID, secret string, now time.Time, accessExpiry, refreshExpiry time.Duration) (*TokenPair, error) {
 	// Generate access token
-	accessToken, err := generateToken(userID, email, role, secret, accessExpiry, AccessToken)
+	accessToken, err := generateToken(userID, email, role, deviceID, secret, now, accessExpiry, AccessToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	// Generate refresh token
-	refreshToken, err := generateToken(userID, email, role, secret, refreshExpiry, RefreshToken)
+	refreshToken, err := generateToken(userID, email, role, deviceID, secret, now, refreshExpiry, RefreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -51,13 +59,13 @@ func GenerateTokenPair(userID, email, role, secret string, accessExpiry, refresh
 	}, nil
 }
 
-func generateToken(userID, email, role, secret string, expiry time.Duration, tokenType TokenType) (string, error) {
-	now := time.Now()
+func generateToken(userID, email, role, deviceID, secret string, now time.Time, expiry time.Duration, tokenType TokenType) (string, error) {
 	claims := &Claims{
 		UserID:    userID,
 		Email:     email,
 		Role:      role,
 		TokenType: tokenType,
+		DeviceID:  deviceID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        uuid.New().String(),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -76,15 +84,15 @@ func generateToken(userID, email, role, secret string, expiry time.Duration, tok
 	return tokenString, nil
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func ValidateToken(tokenString, secret string, expectedType TokenType) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secret), nil
-	})
+// ValidateToken validates a JWT token and returns the claims. Expiry is checked against now.
+func ValidateToken(tokenString, secret string, expectedType TokenType, now time.Time) (*Claims, error) {
+	return ValidateTokenWithSecrets(tokenString, []string{secret}, expectedType, now)
+}
+
+// ValidateTokenWithSecrets is ValidateToken accepting a token signed with any of the secrets,
+// for tokens issued before the signing secret was rotated
+func ValidateTokenWithSecrets(tokenString string, secrets []string, expectedType TokenType, now time.Time) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, hmacKeys(secrets), jwt.WithTimeFunc(func() time.Time { return now }))
 
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
@@ -103,6 +111,21 @@ func ValidateToken(tokenString, secret string, expectedType TokenType) (*Claims,
 	return claims, nil
 }
 
+// hmacKeys returns a key function accepting HMAC signatures with any of the secrets
+func hmacKeys(secrets []string) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		// Verify signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		keys := jwt.VerificationKeySet{Keys: make([]jwt.VerificationKey, len(secrets))}
+		for i, secret := range secrets {
+			keys.Keys[i] = []byte(secret)
+		}
+		return keys, nil
+	}
+}
+
 // ExtractTokenFromHeader extracts the token from Authorization header
 func ExtractTokenFromHeader(authHeader string) (string, error) {
 	if authHeader == "" {
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateToken_Expiry(t *testing.T) {
+	issued := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	pair, err := GenerateTokenPair("user-1", "user@test.com", "student", "secret", issued, 15*time.Minute, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	if _, err := ValidateToken(pair.AccessToken, "secret", AccessToken, issued.Add(14*time.Minute)); err != nil {
+		t.Errorf("access token should be valid before expiry: %v", err)
+	}
+	if _, err := ValidateToken(pair.AccessToken, "secret", AccessToken, issued.Add(16*time.Minute)); err == nil {
+		t.Error("access token should be expired after 15 minutes")
+	}
+	if _, err := ValidateToken(pair.RefreshToken, "secret", RefreshToken, issued.Add(16*time.Minute)); err != nil {
+		t.Errorf("refresh token should still be valid: %v", err)
+	}
+	if _, err := ValidateToken(pair.AccessToken, "secret", RefreshToken, issued); err == nil {
+		t.Error("access token should be rejected as refresh token")
+	}
+}
+
+func TestGenerateDeviceTokenPair(t *testing.T) {
+	issued := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	pair, err := GenerateDeviceTokenPair("user-1", "user@test.com", "student", "device-1", "secret", issued, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateDeviceTokenPair() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		token     string
+		tokenType TokenType
+	}{{pair.AccessToken, AccessToken}, {pair.RefreshToken, RefreshToken}} {
+		claims, err := ValidateToken(tt.token, "secret", tt.tokenType, issued)
+		if err != nil {
+			t.Fatalf("ValidateToken() error = %v", err)
+		}
+		if claims.DeviceID != "device-1" {
+			t.Errorf("%s token DeviceID = %q, want device-1", tt.tokenType, claims.DeviceID)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// SigningKeys holds the secret new tokens are signed with and the secrets tokens are still
+// accepted with. When the signing secret is rotated, the old one keeps verifying tokens for
+// retainFor, so tokens issued before the rotation stay valid until they expire.
+type SigningKeys struct {
+	mu        sync.RWMutex
+	current   string
+	previous  []string // Configured explicitly, accepted until removed
+	retired   []retiredKey
+	retainFor time.Duration
+}
+
+type retiredKey struct {
+	secret    string
+	retiredAt time.Time
+}
+
+func NewSigningKeys(current string, previous []string, retainFor time.Duration) *SigningKeys {
+	return &SigningKeys{
+		current:   current,
+		previous:  previous,
+		retainFor: retainFor,
+	}
+}
+
+// Current returns the secret to sign new tokens with
+func (k *SigningKeys) Current() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current
+}
+
+// Verification returns the secrets tokens are accepted with at now, the current one first
+func (k *SigningKeys) Verification(now time.Time) []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	secrets := append([]string{k.current}, k.previous...)
+	for _, r := range k.retired {
+		if now.Sub(r.retiredAt) < k.retainFor {
+			secrets = append(secrets, r.secret)
+		}
+	}
+	return secrets
+}
+
+// Rotate makes current the signing secret and replaces the configured previous secrets. It
+// reports whether the signing secret changed; the old one is then retired at now.
+func (k *SigningKeys) Rotate(current string, previous []string, now time.Time) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.previous = previous
+	// Drop retired secrets that no longer verify anything
+	k.retired = slices.DeleteFunc(k.retired, func(r retiredKey) bool {
+		return now.Sub(r.retiredAt) >= k.retainFor || r.secret == current
+	})
+	if current == k.current {
+		return false
+	}
+	k.retired = append(k.retired, retiredKey{secret: k.current, retiredAt: now})
+	k.current = current
+	return true
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSigningKeysRotate(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	keys := NewSigningKeys("first", nil, time.Hour)
+
+	pair, err := GenerateTokenPair("user-1", "user@test.com", "student", keys.Current(), start, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	if keys.Rotate("first", nil, start) {
+		t.Error("Rotate() with the same secret should report no change")
+	}
+	if !keys.Rotate("second", nil, start.Add(10*time.Minute)) || keys.Current() != "second" {
+		t.Fatalf("Rotate() should switch signing to the new secret, current = %q", keys.Current())
+	}
+
+	if _, err := ValidateTokenWithSecrets(pair.AccessToken, keys.Verification(start.Add(30*time.Minute)), AccessToken, start.Add(30*time.Minute)); err != nil {
+		t.Errorf("token signed before the rotation should stay valid: %v", err)
+	}
+	if _, err := ValidateTokenWithSecrets(pair.AccessToken, keys.Verification(start.Add(80*time.Minute)), AccessToken, start.Add(30*time.Minute)); err == nil {
+		t.Error("retired secret should stop verifying after the retention period")
+	}
+
+	keys.Rotate("second", []string{"first"}, start.Add(80*time.Minute))
+	if _, err := ValidateTokenWithSecrets(pair.AccessToken, keys.Verification(start.Add(80*time.Minute)), AccessToken, start.Add(30*time.Minute)); err != nil {
+		t.Errorf("explicitly configured previous secret should verify: %v", err)
+	}
+}
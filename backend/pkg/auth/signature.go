@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignRequest returns the hex HMAC-SHA256 of a request under secret. The signed string is the
+// method, the path with its query string, the timestamp, the nonce and the hex SHA-256 of the
+// body, joined by newlines, so none of them can be changed without invalidating the signature.
+func SignRequest(secret, method, requestURI, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	payload := strings.Join([]string{
+		strings.ToUpper(method),
+		requestURI,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyRequestSignature reports whether signature is the request's signature under secret,
+// comparing in constant time
+func VerifyRequestSignature(signature, secret, method, requestURI, timestamp, nonce string, body []byte) bool {
+	expected := SignRequest(secret, method, requestURI, timestamp, nonce, body)
+	return hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected))
+}
@@ -0,0 +1,30 @@
+package auth
+
+import "testing"
+
+func TestVerifyRequestSignature(t *testing.T) {
+	body := []byte(`{"limit":10}`)
+	signature := SignRequest("secret", "GET", "/api/v1/integration/programs?limit=10", "1735732800", "n-1", body)
+
+	if !VerifyRequestSignature(signature, "secret", "get", "/api/v1/integration/programs?limit=10", "1735732800", "n-1", body) {
+		t.Fatal("signature should verify with the same request and secret")
+	}
+
+	tests := []struct {
+		name                                  string
+		secret, method, uri, timestamp, nonce string
+		body                                  []byte
+	}{
+		{"other secret", "other", "GET", "/api/v1/integration/programs?limit=10", "1735732800", "n-1", body},
+		{"other method", "secret", "POST", "/api/v1/integration/programs?limit=10", "1735732800", "n-1", body},
+		{"other query", "secret", "GET", "/api/v1/integration/programs?limit=100", "1735732800", "n-1", body},
+		{"other timestamp", "secret", "GET", "/api/v1/integration/programs?limit=10", "1735732801", "n-1", body},
+		{"other nonce", "secret", "GET", "/api/v1/integration/programs?limit=10", "1735732800", "n-2", body},
+		{"other body", "secret", "GET", "/api/v1/integration/programs?limit=10", "1735732800", "n-1", []byte(`{}`)},
+	}
+	for _, tt := range tests {
+		if VerifyRequestSignature(signature, tt.secret, tt.method, tt.uri, tt.timestamp, tt.nonce, tt.body) {
+			t.Errorf("%s: signature should not verify", tt.name)
+		}
+	}
+}
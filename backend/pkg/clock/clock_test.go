@@ -0,0 +1,34 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+
+	if !c.Now().Equal(start) {
+		t.Fatalf("Now() = %s, want %s", c.Now(), start)
+	}
+
+	c.Advance(36 * time.Hour)
+	if want := start.Add(36 * time.Hour); !c.Now().Equal(want) {
+		t.Errorf("after Advance, Now() = %s, want %s", c.Now(), want)
+	}
+
+	later := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.Set(later)
+	if !c.Now().Equal(later) {
+		t.Errorf("after Set, Now() = %s, want %s", c.Now(), later)
+	}
+}
+
+func TestSystem(t *testing.T) {
+	before := time.Now()
+	now := System.Now()
+	if now.Before(before) || now.After(time.Now()) {
+		t.Errorf("System.Now() = %s, want current time", now)
+	}
+}
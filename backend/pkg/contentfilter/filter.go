@@ -0,0 +1,164 @@
+// Package contentfilter defines a pluggable filter that screens user-written text, such as
+// profanity in messages, before it is stored.
+package contentfilter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/xuangong/backend/pkg/dependency"
+)
+
+// Result is the verdict on a text. Matches lists what was found, if the filter can tell.
+type Result struct {
+	Flagged bool
+	Matches []string
+}
+
+// Filter screens text
+type Filter interface {
+	Check(ctx context.Context, text string) (Result, error)
+}
+
+// DisabledFilter lets everything through
+type DisabledFilter struct{}
+
+func (DisabledFilter) Check(ctx context.Context, text string) (Result, error) {
+	return Result{}, nil
+}
+
+// WordlistFilter flags text containing any of its words or phrases. Matching ignores case and
+// punctuation and only matches whole words, so "class" doesn't match "ass".
+type WordlistFilter struct {
+	phrases []string // Normalized, each surrounded by spaces
+}
+
+func NewWordlistFilter(words []string) *WordlistFilter {
+	f := &WordlistFilter{}
+	for _, word := range words {
+		if normalized := normalize(word); normalized != "  " {
+			f.phrases = append(f.phrases, normalized)
+		}
+	}
+	return f
+}
+
+func (f *WordlistFilter) Check(ctx context.Context, text string) (Result, error) {
+	var result Result
+	normalized := normalize(text)
+	for _, phrase := range f.phrases {
+		if strings.Contains(normalized, phrase) {
+			result.Flagged = true
+			result.Matches = append(result.Matches, strings.TrimSpace(phrase))
+		}
+	}
+	return result, nil
+}
+
+// normalize lowercases text and reduces it to words separated by single spaces, with a space
+// at either end so phrases can be matched on word boundaries
+func normalize(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return " " + strings.Join(words, " ") + " "
+}
+
+// APIFilter asks an external moderation service. It POSTs {"text": ...} with the key as a
+// bearer token and expects {"flagged": bool, "matches": [...]} back; matches are optional.
+type APIFilter struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func NewAPIFilter(url, apiKey string) *APIFilter {
+	return &APIFilter{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (f *APIFilter) Check(ctx context.Context, text string) (Result, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if f.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+f.apiKey)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("content filter request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("content filter returned status %d", resp.StatusCode)
+	}
+
+	var verdict struct {
+		Flagged bool     `json:"flagged"`
+		Matches []string `json:"matches"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return Result{}, fmt.Errorf("failed to decode content filter response: %w", err)
+	}
+	return Result{Flagged: verdict.Flagged, Matches: verdict.Matches}, nil
+}
+
+// NewFilter returns the filter selected by name ("wordlist", "api" or "none")
+func NewFilter(name string, words []string, apiURL, apiKey string) (Filter, error) {
+	switch name {
+	case "none":
+		return DisabledFilter{}, nil
+	case "", "wordlist":
+		return NewWordlistFilter(words), nil
+	case "api":
+		if apiURL == "" {
+			return nil, errors.New("CONTENT_FILTER_API_URL is required for the api content filter")
+		}
+		return NewAPIFilter(apiURL, apiKey), nil
+	default:
+		return nil, fmt.Errorf("unknown content filter %q", name)
+	}
+}
+
+// breakerFilter guards a filter with a circuit breaker so an unreachable service fails fast
+type breakerFilter struct {
+	filter  Filter
+	breaker *dependency.Breaker
+}
+
+// WithBreaker wraps f so checks fail with dependency.ErrOpen while b is open
+func WithBreaker(f Filter, b *dependency.Breaker) Filter {
+	return &breakerFilter{filter: f, breaker: b}
+}
+
+func (f *breakerFilter) Check(ctx context.Context, text string) (Result, error) {
+	var result Result
+	var checkErr error
+	err := f.breaker.Do(func() error {
+		result, checkErr = f.filter.Check(ctx, text)
+		return checkErr
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,85 @@
+package contentfilter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestWordlistFilter(t *testing.T) {
+	f := NewWordlistFilter([]string{"darn", "Heck No", "  ", ""})
+
+	tests := []struct {
+		text    string
+		matches []string
+	}{
+		{"What a nice stance", nil},
+		{"Darn, my knees!", []string{"darn"}},
+		{"DARN it, heck... no way", []string{"darn", "heck no"}},
+		{"heck, no", []string{"heck no"}},
+		{"darnation is a word", nil}, // Whole words only
+		{"", nil},
+	}
+	for _, tt := range tests {
+		result, err := f.Check(context.Background(), tt.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Flagged != (len(tt.matches) > 0) || !slices.Equal(result.Matches, tt.matches) {
+			t.Errorf("Check(%q) = %+v, want matches %v", tt.text, result, tt.matches)
+		}
+	}
+}
+
+func TestAPIFilter(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(map[string]any{"flagged": true, "matches": []string{"insult"}})
+	}))
+	defer server.Close()
+
+	result, err := NewAPIFilter(server.URL, "key").Check(context.Background(), "You are an insult")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["text"] != "You are an insult" {
+		t.Errorf("request text = %q", got["text"])
+	}
+	if !result.Flagged || !slices.Equal(result.Matches, []string{"insult"}) {
+		t.Errorf("result = %+v, want flagged for insult", result)
+	}
+}
+
+func TestAPIFilterError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if _, err := NewAPIFilter(server.URL, "").Check(context.Background(), "text"); err == nil {
+		t.Error("expected an error for a failing service")
+	}
+}
+
+func TestNewFilter(t *testing.T) {
+	if _, err := NewFilter("api", nil, "", ""); err == nil {
+		t.Error("expected an error for the api filter without a URL")
+	}
+	if _, err := NewFilter("unknown", nil, "", ""); err == nil {
+		t.Error("expected an error for an unknown filter")
+	}
+	f, err := NewFilter("", []string{"darn"}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.(*WordlistFilter); !ok {
+		t.Errorf("default filter = %T, want the wordlist filter", f)
+	}
+}
@@ -0,0 +1,121 @@
+// Package dependency tracks the health of external services and guards calls to them
+// with circuit breakers, so a failing integration degrades its feature instead of
+// slowing down or failing unrelated requests.
+package dependency
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned without calling the dependency while its circuit is open
+var ErrOpen = errors.New("dependency unavailable: circuit open")
+
+// State is the state of a circuit breaker
+type State string
+
+const (
+	StateClosed   State = "closed"    // calls pass through
+	StateOpen     State = "open"      // calls fail fast until the cooldown passes
+	StateHalfOpen State = "half_open" // one trial call decides whether to close again
+)
+
+// Breaker opens after a number of consecutive failures and lets a single trial call
+// through once the cooldown has passed.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	trial    bool
+	lastErr  error
+}
+
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     StateClosed,
+	}
+}
+
+// Do runs fn unless the circuit is open. Any error returned by fn counts as a failure.
+func (b *Breaker) Do(fn func() error) error {
+	if !b.allow() {
+		return ErrOpen
+	}
+	err := fn()
+	b.record(err)
+	return err
+}
+
+// State returns the current state, moving an open circuit to half-open once the cooldown has passed
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance()
+	return b.state
+}
+
+// LastError returns the most recent failure, or nil if the last call succeeded
+func (b *Breaker) LastError() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastErr
+}
+
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance()
+
+	switch b.state {
+	case StateOpen:
+		return false
+	case StateHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if err == nil {
+		b.state = StateClosed
+		b.failures = 0
+		b.lastErr = nil
+		return
+	}
+
+	b.lastErr = err
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.state = StateOpen
+		b.openedAt = b.now()
+	}
+}
+
+// advance must be called with mu held
+func (b *Breaker) advance() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		b.state = StateHalfOpen
+		b.trial = false
+	}
+}
@@ -0,0 +1,113 @@
+package dependency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errBoom = errors.New("boom")
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	fail := func() error { return errBoom }
+	succeed := func() error { return nil }
+
+	if err := b.Do(fail); err != errBoom {
+		t.Fatalf("expected first failure to pass through, got %v", err)
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("expected closed after one failure, got %s", b.State())
+	}
+
+	b.Do(fail)
+	if b.State() != StateOpen {
+		t.Fatalf("expected open after threshold, got %s", b.State())
+	}
+
+	called := false
+	if err := b.Do(func() error { called = true; return nil }); err != ErrOpen || called {
+		t.Fatalf("expected open circuit to fail fast, got err=%v called=%v", err, called)
+	}
+
+	now = now.Add(time.Minute)
+	if b.State() != StateHalfOpen {
+		t.Fatalf("expected half-open after cooldown, got %s", b.State())
+	}
+
+	// A failed trial reopens immediately
+	b.Do(fail)
+	if b.State() != StateOpen {
+		t.Fatalf("expected open after failed trial, got %s", b.State())
+	}
+
+	now = now.Add(time.Minute)
+	if err := b.Do(succeed); err != nil {
+		t.Fatalf("expected trial call to run, got %v", err)
+	}
+	if b.State() != StateClosed || b.LastError() != nil {
+		t.Fatalf("expected closed with no error after successful trial, got %s / %v", b.State(), b.LastError())
+	}
+}
+
+func TestRegistryStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(r *Registry)
+		wantStatus Status
+	}{
+		{
+			name: "all up",
+			setup: func(r *Registry) {
+				r.Register("database", true, func(ctx context.Context) error { return nil })
+				r.Register("tts", false, nil)
+			},
+			wantStatus: StatusUp,
+		},
+		{
+			name: "non-critical down degrades",
+			setup: func(r *Registry) {
+				r.Register("database", true, func(ctx context.Context) error { return nil })
+				r.Register("storage", false, func(ctx context.Context) error { return errBoom })
+			},
+			wantStatus: StatusDegraded,
+		},
+		{
+			name: "open circuit degrades",
+			setup: func(r *Registry) {
+				b := r.Register("tts", false, nil)
+				b.Do(func() error { return errBoom })
+			},
+			wantStatus: StatusDegraded,
+		},
+		{
+			name: "critical down",
+			setup: func(r *Registry) {
+				r.Register("database", true, func(ctx context.Context) error { return errBoom })
+				r.Register("tts", false, nil)
+			},
+			wantStatus: StatusDown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(1, time.Hour)
+			tt.setup(r)
+
+			report := r.Status(context.Background())
+			if report.Status != tt.wantStatus {
+				t.Errorf("expected %s, got %s (%+v)", tt.wantStatus, report.Status, report.Dependencies)
+			}
+			for _, dep := range report.Dependencies {
+				if dep.Status != StatusUp && dep.Error == nil {
+					t.Errorf("expected error message for %s", dep.Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package dependency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// checkTimeout bounds each health probe so a hanging dependency cannot stall the health endpoint
+const checkTimeout = 2 * time.Second
+
+// Check probes a dependency. Returning nil means it is reachable.
+type Check func(ctx context.Context) error
+
+// Status is the health of a single dependency or of the whole service
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// DependencyStatus reports one registered dependency
+type DependencyStatus struct {
+	Name      string  `json:"name"`
+	Status    Status  `json:"status"`
+	Critical  bool    `json:"critical"`
+	Circuit   State   `json:"circuit"`
+	LatencyMs int64   `json:"latency_ms"`
+	Error     *string `json:"error,omitempty"`
+}
+
+// Report is the aggregated health of all dependencies
+type Report struct {
+	Status       Status             `json:"status"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+type entry struct {
+	name     string
+	critical bool
+	check    Check
+	breaker  *Breaker
+}
+
+// Registry holds the external services the API depends on
+type Registry struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu      sync.RWMutex
+	entries []*entry
+}
+
+// NewRegistry creates a registry whose breakers open after threshold consecutive failures
+// and retry after cooldown
+func NewRegistry(threshold int, cooldown time.Duration) *Registry {
+	return &Registry{threshold: threshold, cooldown: cooldown}
+}
+
+// Register adds a dependency and returns the breaker that guards calls to it.
+// Critical dependencies make the whole service unhealthy when down. check may be nil,
+// in which case health is derived from the breaker alone.
+func (r *Registry) Register(name string, critical bool, check Check) *Breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := NewBreaker(r.threshold, r.cooldown)
+	r.entries = append(r.entries, &entry{name: name, critical: critical, check: check, breaker: b})
+	return b
+}
+
+// Status probes all dependencies concurrently.
+// The service is down if a critical dependency is down, and degraded if anything else is not up.
+func (r *Registry) Status(ctx context.Context) Report {
+	r.mu.RLock()
+	entries := make([]*entry, len(r.entries))
+	copy(entries, r.entries)
+	r.mu.RUnlock()
+
+	statuses := make([]DependencyStatus, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			statuses[i] = probe(ctx, e)
+		}(i, e)
+	}
+	wg.Wait()
+
+	overall := StatusUp
+	for _, s := range statuses {
+		switch {
+		case s.Status == StatusDown && s.Critical:
+			overall = StatusDown
+		case s.Status != StatusUp && overall == StatusUp:
+			overall = StatusDegraded
+		}
+	}
+	return Report{Status: overall, Dependencies: statuses}
+}
+
+func probe(ctx context.Context, e *entry) DependencyStatus {
+	status := DependencyStatus{
+		Name:     e.name,
+		Status:   StatusUp,
+		Critical: e.critical,
+		Circuit:  e.breaker.State(),
+	}
+
+	var err error
+	if e.check != nil {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		defer cancel()
+
+		start := time.Now()
+		err = e.check(checkCtx)
+		status.LatencyMs = time.Since(start).Milliseconds()
+	}
+
+	switch {
+	case err != nil:
+		status.Status = StatusDown
+	case status.Circuit == StateOpen:
+		status.Status = StatusDown
+		err = e.breaker.LastError()
+	case status.Circuit == StateHalfOpen:
+		status.Status = StatusDegraded
+		err = e.breaker.LastError()
+	}
+
+	if err != nil {
+		msg := err.Error()
+		status.Error = &msg
+	}
+	return status
+}
@@ -9,14 +9,22 @@ import (
 type ErrorCode string
 
 const (
-	ErrCodeValidation     ErrorCode = "VALIDATION_ERROR"
-	ErrCodeAuthentication ErrorCode = "AUTHENTICATION_ERROR"
-	ErrCodeAuthorization  ErrorCode = "AUTHORIZATION_ERROR"
-	ErrCodeNotFound       ErrorCode = "NOT_FOUND"
-	ErrCodeConflict       ErrorCode = "CONFLICT"
-	ErrCodeInternal       ErrorCode = "INTERNAL_ERROR"
-	ErrCodeBadRequest     ErrorCode = "BAD_REQUEST"
-	ErrCodeRateLimit      ErrorCode = "RATE_LIMIT_EXCEEDED"
+	ErrCodeValidation       ErrorCode = "VALIDATION_ERROR"
+	ErrCodeAuthentication   ErrorCode = "AUTHENTICATION_ERROR"
+	ErrCodeAuthorization    ErrorCode = "AUTHORIZATION_ERROR"
+	ErrCodeNotFound         ErrorCode = "NOT_FOUND"
+	ErrCodeConflict         ErrorCode = "CONFLICT"
+	ErrCodeInternal         ErrorCode = "INTERNAL_ERROR"
+	ErrCodeBadRequest       ErrorCode = "BAD_REQUEST"
+	ErrCodeRateLimit        ErrorCode = "RATE_LIMIT_EXCEEDED"
+	ErrCodeMethodNotAllowed ErrorCode = "METHOD_NOT_ALLOWED"
+
+	ErrCodeRegistrationDisabled ErrorCode = "REGISTRATION_DISABLED"
+	ErrCodePayloadTooLarge      ErrorCode = "PAYLOAD_TOO_LARGE"
+	ErrCodeNotImplemented       ErrorCode = "NOT_IMPLEMENTED"
+	ErrCodeQuotaExceeded        ErrorCode = "QUOTA_EXCEEDED"
+	ErrCodeContentRejected      ErrorCode = "CONTENT_REJECTED"
+	ErrCodeUpgradeRequired      ErrorCode = "UPGRADE_REQUIRED"
 )
 
 // AppError represents an application-level error with context
@@ -104,3 +112,61 @@ func NewRateLimitError() *AppError {
 		http.StatusTooManyRequests,
 	)
 }
+
+// NewMethodNotAllowedError reports a request for a path that only exists under other methods
+func NewMethodNotAllowedError(method string, allowed []string) *AppError {
+	return NewAppError(
+		ErrCodeMethodNotAllowed,
+		fmt.Sprintf("Method %s is not allowed for this path", method),
+		http.StatusMethodNotAllowed,
+	).WithDetails("allowed", allowed)
+}
+
+func NewRegistrationDisabledError() *AppError {
+	return NewAppError(
+		ErrCodeRegistrationDisabled,
+		"Open registration is disabled. Please ask an administrator for an invitation.",
+		http.StatusForbidden,
+	)
+}
+
+func NewPayloadTooLargeError(maxBytes int64) *AppError {
+	return NewAppError(
+		ErrCodePayloadTooLarge,
+		fmt.Sprintf("Request body exceeds the limit of %d bytes", maxBytes),
+		http.StatusRequestEntityTooLarge,
+	).WithDetails("max_bytes", maxBytes)
+}
+
+// NewUnprocessableError reports a well-formed request whose content is rejected, such as oversized metadata
+func NewUnprocessableError(message string) *AppError {
+	return NewAppError(ErrCodeValidation, message, http.StatusUnprocessableEntity)
+}
+
+// NewNotImplementedError reports a supported request asking for an unsupported variant, such as a response format
+func NewNotImplementedError(message string) *AppError {
+	return NewAppError(ErrCodeNotImplemented, message, http.StatusNotImplemented)
+}
+
+// NewQuotaExceededError reports content the user can't create without going over a quota
+func NewQuotaExceededError(quota, message string, limit, used int64) *AppError {
+	return NewAppError(ErrCodeQuotaExceeded, message, http.StatusUnprocessableEntity).
+		WithDetails("quota", quota).
+		WithDetails("limit", limit).
+		WithDetails("used", used)
+}
+
+// NewUpgradeRequiredError reports a client app older than the oldest version still supported on its platform
+func NewUpgradeRequiredError(platform, minVersion, upgradeURL string) *AppError {
+	return NewAppError(ErrCodeUpgradeRequired, "This version of the app is no longer supported. Please update to "+minVersion+" or newer.", http.StatusUpgradeRequired).
+		WithDetails("platform", platform).
+		WithDetails("min_version", minVersion).
+		WithDetails("upgrade_url", upgradeURL)
+}
+
+// NewContentRejectedError reports text the content filter doesn't allow, with the terms it matched
+func NewContentRejectedError(field string, matches []string) *AppError {
+	return NewAppError(ErrCodeContentRejected, "The "+field+" contains language that isn't allowed", http.StatusUnprocessableEntity).
+		WithDetails("field", field).
+		WithDetails("matches", matches)
+}
@@ -0,0 +1,149 @@
+// Package fieldcrypt encrypts sensitive column values with AES-256-GCM before they are stored.
+// Encrypted values are text of the form enc:<key id>:<base64 nonce and ciphertext>, so they fit
+// the existing columns and name the key they were written with. Older keys stay readable while
+// rows are re-encrypted under the current one.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const prefix = "enc:"
+
+// ErrNoKey is returned when a value was encrypted with a key the keyring does not have
+var ErrNoKey = errors.New("encryption key not available")
+
+// Keyring holds the current key, used for new values, and previous keys still read. A nil
+// Keyring stores values in plain text; reading encrypted values then fails with ErrNoKey.
+type Keyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyring parses keys written as <id>:<base64 of 32 bytes>. current may be empty to disable
+// encryption, but only if there are no previous keys either.
+func NewKeyring(current string, previous []string) (*Keyring, error) {
+	if current == "" {
+		if len(previous) > 0 {
+			return nil, errors.New("previous keys require a current key")
+		}
+		return nil, nil
+	}
+
+	k := &Keyring{keys: make(map[string]cipher.AEAD, len(previous)+1)}
+	for i, value := range append([]string{current}, previous...) {
+		id, aead, err := parseKey(value)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := k.keys[id]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", id)
+		}
+		k.keys[id] = aead
+		if i == 0 {
+			k.current = id
+		}
+	}
+	return k, nil
+}
+
+func parseKey(value string) (string, cipher.AEAD, error) {
+	id, encoded, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok || id == "" {
+		return "", nil, errors.New("key must be written as <id>:<base64 key>")
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, fmt.Errorf("key %q is not valid base64", id)
+	}
+	if len(raw) != 32 {
+		return "", nil, fmt.Errorf("key %q must be 32 bytes, got %d", id, len(raw))
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return "", nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", nil, err
+	}
+	return id, aead, nil
+}
+
+// CurrentID returns the ID of the key new values are encrypted with, or "" without encryption
+func (k *Keyring) CurrentID() string {
+	if k == nil {
+		return ""
+	}
+	return k.current
+}
+
+// Encrypt encrypts plaintext with the current key. The context, e.g. the table, column and row
+// ID, is authenticated with the value, so a value copied to another row does not decrypt.
+// Empty values and a nil Keyring return plaintext unchanged.
+func (k *Keyring) Encrypt(plaintext, context string) (string, error) {
+	if k == nil || plaintext == "" {
+		return plaintext, nil
+	}
+	aead := k.keys[k.current]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(context))
+	return prefix + k.current + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of a value from Encrypt with the same context. Values that are
+// not encrypted, such as rows written before encryption was enabled, are returned unchanged.
+func (k *Keyring) Decrypt(value, context string) (string, error) {
+	id, ok := KeyID(value)
+	if !ok {
+		return value, nil
+	}
+	if k == nil || k.keys[id] == nil {
+		return "", fmt.Errorf("%w: %q", ErrNoKey, id)
+	}
+	aead := k.keys[id]
+
+	sealed, err := base64.StdEncoding.DecodeString(value[len(prefix)+len(id)+1:])
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(context))
+	if err != nil {
+		return "", errors.New("encrypted value failed authentication")
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether a stored value should be rewritten: it is encrypted with an
+// older key, or is plain text while encryption is enabled
+func (k *Keyring) NeedsRotation(value string) bool {
+	if value == "" {
+		return false
+	}
+	id, ok := KeyID(value)
+	if !ok {
+		return k != nil
+	}
+	return id != k.CurrentID()
+}
+
+// KeyID returns the ID of the key value was encrypted with, and false for plain text
+func KeyID(value string) (string, bool) {
+	if !strings.HasPrefix(value, prefix) {
+		return "", false
+	}
+	id, _, ok := strings.Cut(value[len(prefix):], ":")
+	if !ok || id == "" {
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,104 @@
+package fieldcrypt
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(id string, b byte) string {
+	return id + ":" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), 32)))
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	k, err := NewKeyring(testKey("k1", 'a'), nil)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	stored, err := k.Encrypt("bad left knee", "user_limitations.notes:1")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !strings.HasPrefix(stored, "enc:k1:") || strings.Contains(stored, "knee") {
+		t.Fatalf("Encrypt() = %q, want an enc:k1: value without the plaintext", stored)
+	}
+	if again, _ := k.Encrypt("bad left knee", "user_limitations.notes:1"); again == stored {
+		t.Error("Encrypt() should use a fresh nonce for every value")
+	}
+
+	plaintext, err := k.Decrypt(stored, "user_limitations.notes:1")
+	if err != nil || plaintext != "bad left knee" {
+		t.Errorf("Decrypt() = %q, %v", plaintext, err)
+	}
+	if _, err := k.Decrypt(stored, "user_limitations.notes:2"); err == nil {
+		t.Error("Decrypt() with another row's context should fail")
+	}
+}
+
+func TestPlainValues(t *testing.T) {
+	k, _ := NewKeyring(testKey("k1", 'a'), nil)
+	if got, err := k.Decrypt("legacy notes", "ctx"); err != nil || got != "legacy notes" {
+		t.Errorf("Decrypt() of plain text = %q, %v", got, err)
+	}
+	if got, _ := k.Encrypt("", "ctx"); got != "" {
+		t.Errorf("Encrypt(\"\") = %q, want empty", got)
+	}
+
+	var disabled *Keyring
+	if got, _ := disabled.Encrypt("notes", "ctx"); got != "notes" {
+		t.Errorf("nil Keyring Encrypt() = %q, want plain text", got)
+	}
+	stored, _ := k.Encrypt("notes", "ctx")
+	if _, err := disabled.Decrypt(stored, "ctx"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("nil Keyring Decrypt() error = %v, want ErrNoKey", err)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	old, _ := NewKeyring(testKey("k1", 'a'), nil)
+	stored, _ := old.Encrypt("secret", "ctx")
+
+	k, err := NewKeyring(testKey("k2", 'b'), []string{testKey("k1", 'a')})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	if got, err := k.Decrypt(stored, "ctx"); err != nil || got != "secret" {
+		t.Errorf("Decrypt() with a previous key = %q, %v", got, err)
+	}
+	if !k.NeedsRotation(stored) || !k.NeedsRotation("plain") || k.NeedsRotation("") {
+		t.Error("NeedsRotation() should report old-key and plain values")
+	}
+	rotated, _ := k.Encrypt("secret", "ctx")
+	if k.NeedsRotation(rotated) {
+		t.Error("NeedsRotation() should be false for the current key")
+	}
+
+	if _, err := old.Decrypt(rotated, "ctx"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Decrypt() with an unknown key error = %v, want ErrNoKey", err)
+	}
+}
+
+func TestNewKeyringErrors(t *testing.T) {
+	tests := map[string]struct {
+		current  string
+		previous []string
+	}{
+		"previous without current": {"", []string{testKey("k1", 'a')}},
+		"missing id":               {"bm90IGEga2V5", nil},
+		"short key":                {"k1:" + base64.StdEncoding.EncodeToString([]byte("short")), nil},
+		"not base64":               {"k1:***", nil},
+		"duplicate id":             {testKey("k1", 'a'), []string{testKey("k1", 'b')}},
+	}
+	for name, tt := range tests {
+		if _, err := NewKeyring(tt.current, tt.previous); err == nil {
+			t.Errorf("%s: NewKeyring() should fail", name)
+		}
+	}
+
+	k, err := NewKeyring("", nil)
+	if err != nil || k != nil {
+		t.Errorf("NewKeyring() without keys = %v, %v, want nil keyring", k, err)
+	}
+}
@@ -0,0 +1,140 @@
+// Package ical writes iCalendar (RFC 5545) files for calendar invitations.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Methods for invitations sent by email (RFC 5546)
+const (
+	MethodRequest = "REQUEST"
+	MethodCancel  = "CANCEL"
+)
+
+// ContentType is the MIME type of calendar files
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	productID  = "-//Xuan Gong//Bookings//EN"
+	timeLayout = "20060102T150405Z"
+	// Content lines are folded after this many octets
+	maxLineOctets = 75
+)
+
+// Person is an organizer or attendee
+type Person struct {
+	Name  string
+	Email string
+}
+
+// Event is a single calendar entry. UID must stay the same across updates and cancellations
+// of the same event, with Sequence increasing each time.
+type Event struct {
+	UID         string
+	Sequence    int
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Organizer   Person
+	Attendees   []Person
+	Cancelled   bool
+}
+
+// Calendar renders the events as an iCalendar file. Method is MethodRequest or MethodCancel
+// for email invitations, or empty for a plain download.
+func Calendar(method string, stamp time.Time, events ...Event) []byte {
+	var b bytes.Buffer
+	line := func(name, value string) {
+		writeLine(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", productID)
+	line("CALSCALE", "GREGORIAN")
+	if method != "" {
+		line("METHOD", method)
+	}
+	for _, e := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", escape(e.UID))
+		line("SEQUENCE", fmt.Sprint(e.Sequence))
+		line("DTSTAMP", stamp.UTC().Format(timeLayout))
+		line("DTSTART", e.Start.UTC().Format(timeLayout))
+		line("DTEND", e.End.UTC().Format(timeLayout))
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escape(e.Location))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		if e.Organizer.Email != "" {
+			writeLine(&b, "ORGANIZER"+cn(e.Organizer.Name)+":mailto:"+e.Organizer.Email)
+		}
+		for _, a := range e.Attendees {
+			writeLine(&b, "ATTENDEE"+cn(a.Name)+";ROLE=REQ-PARTICIPANT:mailto:"+a.Email)
+		}
+		if e.Cancelled {
+			line("STATUS", "CANCELLED")
+		} else {
+			line("STATUS", "CONFIRMED")
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	return b.Bytes()
+}
+
+// cn returns the common name parameter for a person, quoted since names may contain separators
+func cn(name string) string {
+	if name == "" {
+		return ""
+	}
+	return `;CN="` + strings.NewReplacer(`"`, "'", "\r", "", "\n", " ").Replace(name) + `"`
+}
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(s)
+}
+
+// writeLine writes a content line terminated by CRLF, folding it so no line exceeds
+// maxLineOctets without splitting a UTF-8 sequence
+func writeLine(b *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
@@ -0,0 +1,73 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestCalendar(t *testing.T) {
+	start := time.Date(2026, 3, 2, 17, 0, 0, 0, time.FixedZone("CET", 3600))
+	event := Event{
+		UID:         "booking-1@xuangong",
+		Start:       start,
+		End:         start.Add(30 * time.Minute),
+		Summary:     "Video review: Form 1, part 2",
+		Description: "Bring your latest recording;\nwe'll go through it together",
+		Location:    "https://meet.example.com/room",
+		URL:         "https://meet.example.com/room",
+		Organizer:   Person{Name: "Master Li", Email: "li@example.com"},
+		Attendees:   []Person{{Name: "Anna", Email: "anna@example.com"}},
+	}
+
+	out := string(Calendar(MethodRequest, start, event))
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"METHOD:REQUEST\r\n",
+		"DTSTART:20260302T160000Z\r\n",
+		"DTEND:20260302T163000Z\r\n",
+		`SUMMARY:Video review: Form 1\, part 2` + "\r\n",
+		`DESCRIPTION:Bring your latest recording\;\nwe'll go through it together` + "\r\n",
+		"LOCATION:https://meet.example.com/room\r\n",
+		"URL:https://meet.example.com/room\r\n",
+		`ORGANIZER;CN="Master Li":mailto:li@example.com` + "\r\n",
+		"STATUS:CONFIRMED\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar is missing %q:\n%s", want, out)
+		}
+	}
+
+	cancelled := event
+	cancelled.Sequence = 1
+	cancelled.Cancelled = true
+	out = string(Calendar(MethodCancel, start, cancelled))
+	if !strings.Contains(out, "METHOD:CANCEL\r\n") || !strings.Contains(out, "SEQUENCE:1\r\n") || !strings.Contains(out, "STATUS:CANCELLED\r\n") {
+		t.Errorf("cancellation is missing method, sequence or status:\n%s", out)
+	}
+}
+
+func TestWriteLineFolding(t *testing.T) {
+	event := Event{
+		UID:     "fold@xuangong",
+		Summary: strings.Repeat("ä", 60), // 120 octets
+	}
+	out := string(Calendar("", time.Time{}, event))
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line exceeds %d octets: %q", maxLineOctets, line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a UTF-8 sequence: %q", line)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, "SUMMARY:"+strings.Repeat("ä", 60)+"\r\n") {
+		t.Errorf("unfolded summary does not match:\n%s", unfolded)
+	}
+}
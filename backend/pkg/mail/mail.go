@@ -0,0 +1,169 @@
+// Package mail sends plain text emails with attachments, such as calendar invitations.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// ErrDisabled is returned when no mail server is configured
+var ErrDisabled = errors.New("email is not configured")
+
+// Attachment is a file sent along with a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is a plain text email
+type Message struct {
+	To          []string
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// Sender delivers emails
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// DisabledSender is used when no mail server is configured
+type DisabledSender struct{}
+
+func (DisabledSender) Send(ctx context.Context, msg Message) error {
+	return ErrDisabled
+}
+
+// SMTPSender delivers emails through an SMTP server, upgrading to TLS when the server offers it
+type SMTPSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPSender{
+		addr: net.JoinHostPort(host, fmt.Sprint(port)),
+		auth: auth,
+		from: from,
+	}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return errors.New("message has no recipients")
+	}
+	data, err := build(s.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// net/smtp has no context support; bound the whole exchange instead
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.addr, s.auth, s.from, msg.To, data)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("smtp send failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NewSender returns an SMTP sender, or a disabled one if host is empty
+func NewSender(host string, port int, username, password, from string) (Sender, error) {
+	if host == "" {
+		return DisabledSender{}, nil
+	}
+	if from == "" {
+		return nil, errors.New("MAIL_FROM is required when SMTP_HOST is set")
+	}
+	return NewSMTPSender(host, port, username, password, from), nil
+}
+
+// build renders msg as a MIME message, multipart if it has attachments
+func build(from string, msg Message, date time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&b, "%s: %s\r\n", name, value)
+	}
+
+	header("From", from)
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	if len(msg.Attachments) == 0 {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "base64")
+		b.WriteString("\r\n")
+		writeBase64(&b, []byte(msg.Body))
+		return b.Bytes(), nil
+	}
+
+	boundary, err := newBoundary()
+	if err != nil {
+		return nil, err
+	}
+	header("Content-Type", fmt.Sprintf(`multipart/mixed; boundary="%s"`, boundary))
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "base64")
+	b.WriteString("\r\n")
+	writeBase64(&b, []byte(msg.Body))
+
+	for _, a := range msg.Attachments {
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		header("Content-Type", a.ContentType)
+		header("Content-Transfer-Encoding", "base64")
+		header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+		b.WriteString("\r\n")
+		writeBase64(&b, a.Data)
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+
+	return b.Bytes(), nil
+}
+
+// writeBase64 writes data base64 encoded in lines of 76 characters
+func writeBase64(b *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76])
+		b.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+	b.WriteString("\r\n")
+}
+
+func newBoundary() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
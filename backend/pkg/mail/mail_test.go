@@ -0,0 +1,82 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuild_WithAttachment(t *testing.T) {
+	msg := Message{
+		To:      []string{"anna@example.com", "li@example.com"},
+		Subject: "Buchung bestätigt",
+		Body:    "See you on Monday.",
+		Attachments: []Attachment{{
+			Filename:    "invite.ics",
+			ContentType: "text/calendar; charset=utf-8; method=REQUEST",
+			Data:        []byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"),
+		}},
+	}
+
+	data, err := build("Xuan Gong <noreply@example.com>", msg, time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	if got := parsed.Header.Get("To"); got != "anna@example.com, li@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if subject != msg.Subject {
+		t.Errorf("Subject = %q, want %q", subject, msg.Subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type = %q, %v", mediaType, err)
+	}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+		body, _ := io.ReadAll(part) // multipart decodes quoted-printable only, base64 is checked below
+		parts = append(parts, part.Header.Get("Content-Type")+"|"+part.FileName()+"|"+string(body))
+	}
+	if len(parts) != 2 {
+		t.Fatalf("got %d parts, want 2", len(parts))
+	}
+	if !strings.HasPrefix(parts[1], "text/calendar; charset=utf-8; method=REQUEST|invite.ics|") {
+		t.Errorf("attachment part = %q", parts[1])
+	}
+}
+
+func TestNewSender(t *testing.T) {
+	sender, err := NewSender("", 587, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sender.Send(context.Background(), Message{}); !errors.Is(err, ErrDisabled) {
+		t.Errorf("Send() error = %v, want ErrDisabled", err)
+	}
+
+	if _, err := NewSender("smtp.example.com", 587, "", "", ""); err == nil {
+		t.Error("NewSender() without MAIL_FROM should fail")
+	}
+}
@@ -0,0 +1,271 @@
+// Package meeting defines a pluggable video-conferencing provider that creates meeting rooms for bookings.
+package meeting
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xuangong/backend/pkg/dependency"
+)
+
+// ErrDisabled is returned when no meeting provider is configured
+var ErrDisabled = errors.New("video meetings are not configured")
+
+// Request describes the meeting to create
+type Request struct {
+	Topic    string
+	Start    time.Time
+	Duration time.Duration
+}
+
+// Meeting is a created meeting. ID identifies it at the provider for deletion.
+type Meeting struct {
+	Provider string
+	ID       string
+	JoinURL  string
+}
+
+// Provider creates and deletes meetings
+type Provider interface {
+	Create(ctx context.Context, req Request) (*Meeting, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// DisabledProvider is used when video meetings are not configured
+type DisabledProvider struct{}
+
+func (DisabledProvider) Create(ctx context.Context, req Request) (*Meeting, error) {
+	return nil, ErrDisabled
+}
+
+func (DisabledProvider) Delete(ctx context.Context, id string) error {
+	return ErrDisabled
+}
+
+// JitsiProvider generates rooms on a Jitsi Meet server. Rooms exist as soon as someone joins,
+// so nothing is called; the random room name keeps them from being guessed.
+type JitsiProvider struct {
+	baseURL string
+}
+
+func NewJitsiProvider(baseURL string) *JitsiProvider {
+	return &JitsiProvider{baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (p *JitsiProvider) Create(ctx context.Context, req Request) (*Meeting, error) {
+	token := make([]byte, 12)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate room name: %w", err)
+	}
+	room := "XuanGong-" + hex.EncodeToString(token)
+
+	return &Meeting{
+		Provider: "jitsi",
+		ID:       room,
+		JoinURL:  p.baseURL + "/" + room,
+	}, nil
+}
+
+// Delete is a no-op since Jitsi rooms disappear when the last participant leaves
+func (p *JitsiProvider) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+// ZoomProvider schedules meetings through the Zoom API using a server-to-server OAuth app.
+// Meetings are created for the account's user that owns the app.
+type ZoomProvider struct {
+	accountID    string
+	clientID     string
+	clientSecret string
+	tokenURL     string
+	apiURL       string
+	client       *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func NewZoomProvider(accountID, clientID, clientSecret string) *ZoomProvider {
+	return &ZoomProvider{
+		accountID:    accountID,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		tokenURL:     "https://zoom.us/oauth/token",
+		apiURL:       "https://api.zoom.us/v2",
+		client:       &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (p *ZoomProvider) Create(ctx context.Context, req Request) (*Meeting, error) {
+	body, err := json.Marshal(map[string]any{
+		"topic":      req.Topic,
+		"type":       2, // Scheduled meeting
+		"start_time": req.Start.UTC().Format("2006-01-02T15:04:05Z"),
+		"duration":   int(req.Duration.Minutes()),
+		"timezone":   "UTC",
+		"settings": map[string]any{
+			"join_before_host": false,
+			"waiting_room":     true,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var created struct {
+		ID      int64  `json:"id"`
+		JoinURL string `json:"join_url"`
+	}
+	if err := p.call(ctx, http.MethodPost, "/users/me/meetings", body, http.StatusCreated, &created); err != nil {
+		return nil, err
+	}
+	if created.JoinURL == "" {
+		return nil, errors.New("zoom returned a meeting without join url")
+	}
+
+	return &Meeting{
+		Provider: "zoom",
+		ID:       fmt.Sprint(created.ID),
+		JoinURL:  created.JoinURL,
+	}, nil
+}
+
+func (p *ZoomProvider) Delete(ctx context.Context, id string) error {
+	return p.call(ctx, http.MethodDelete, "/meetings/"+url.PathEscape(id), nil, http.StatusNoContent, nil)
+}
+
+// call sends an authenticated API request and decodes the response into out unless it is nil
+func (p *ZoomProvider) call(ctx context.Context, method, path string, body []byte, wantStatus int, out any) error {
+	token, err := p.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.apiURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("zoom request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != wantStatus {
+		return fmt.Errorf("zoom returned status %d for %s %s", resp.StatusCode, method, path)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode zoom response: %w", err)
+		}
+	}
+	return nil
+}
+
+// token returns a cached access token, requesting a new one shortly before it expires
+func (p *ZoomProvider) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Before(p.expiresAt) {
+		return p.accessToken, nil
+	}
+
+	form := url.Values{
+		"grant_type": {"account_credentials"},
+		"account_id": {p.accountID},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(p.clientID, p.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("zoom token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("zoom token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode zoom token: %w", err)
+	}
+
+	p.accessToken = token.AccessToken
+	// Refresh a minute early so a token never expires mid-request
+	p.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return p.accessToken, nil
+}
+
+// NewProvider returns the provider selected by name ("jitsi", "zoom" or "" for disabled)
+func NewProvider(name, jitsiURL, zoomAccountID, zoomClientID, zoomClientSecret string) (Provider, error) {
+	switch name {
+	case "":
+		return DisabledProvider{}, nil
+	case "jitsi":
+		if jitsiURL == "" {
+			return nil, errors.New("JITSI_URL is required for the jitsi provider")
+		}
+		return NewJitsiProvider(jitsiURL), nil
+	case "zoom":
+		if zoomAccountID == "" || zoomClientID == "" || zoomClientSecret == "" {
+			return nil, errors.New("ZOOM_ACCOUNT_ID, ZOOM_CLIENT_ID and ZOOM_CLIENT_SECRET are required for the zoom provider")
+		}
+		return NewZoomProvider(zoomAccountID, zoomClientID, zoomClientSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown meeting provider %q", name)
+	}
+}
+
+// breakerProvider guards a provider with a circuit breaker so an unreachable service fails fast
+type breakerProvider struct {
+	provider Provider
+	breaker  *dependency.Breaker
+}
+
+// WithBreaker wraps p so calls fail with dependency.ErrOpen while b is open
+func WithBreaker(p Provider, b *dependency.Breaker) Provider {
+	return &breakerProvider{provider: p, breaker: b}
+}
+
+func (p *breakerProvider) Create(ctx context.Context, req Request) (*Meeting, error) {
+	var meeting *Meeting
+	var createErr error
+	err := p.breaker.Do(func() error {
+		meeting, createErr = p.provider.Create(ctx, req)
+		return createErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return meeting, nil
+}
+
+func (p *breakerProvider) Delete(ctx context.Context, id string) error {
+	return p.breaker.Do(func() error {
+		return p.provider.Delete(ctx, id)
+	})
+}
@@ -0,0 +1,104 @@
+package meeting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJitsiProvider(t *testing.T) {
+	p := NewJitsiProvider("https://meet.example.com/")
+
+	first, err := p.Create(context.Background(), Request{Topic: "Review"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(first.JoinURL, "https://meet.example.com/XuanGong-") || first.JoinURL != "https://meet.example.com/"+first.ID {
+		t.Errorf("JoinURL = %q, ID = %q", first.JoinURL, first.ID)
+	}
+
+	second, err := p.Create(context.Background(), Request{Topic: "Review"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID == first.ID {
+		t.Errorf("expected a new room per meeting, got %q twice", first.ID)
+	}
+}
+
+func TestZoomProvider(t *testing.T) {
+	tokenRequests := 0
+	var created map[string]any
+	deleted := ""
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		if user, pass, ok := r.BasicAuth(); !ok || user != "client" || pass != "secret" {
+			t.Errorf("token request without client credentials")
+		}
+		if r.FormValue("grant_type") != "account_credentials" || r.FormValue("account_id") != "account" {
+			t.Errorf("token request form = %v", r.Form)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 3600})
+	})
+	mux.HandleFunc("POST /v2/users/me/meetings", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&created)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{"id": 85746065432, "join_url": "https://zoom.us/j/85746065432"})
+	})
+	mux.HandleFunc("DELETE /v2/meetings/{id}", func(w http.ResponseWriter, r *http.Request) {
+		deleted = r.PathValue("id")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := NewZoomProvider("account", "client", "secret")
+	p.tokenURL = server.URL + "/oauth/token"
+	p.apiURL = server.URL + "/v2"
+
+	start := time.Date(2026, 3, 2, 17, 0, 0, 0, time.FixedZone("CET", 3600))
+	m, err := p.Create(context.Background(), Request{Topic: "Video review", Start: start, Duration: 30 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Provider != "zoom" || m.ID != "85746065432" || m.JoinURL != "https://zoom.us/j/85746065432" {
+		t.Errorf("meeting = %+v", m)
+	}
+	if created["start_time"] != "2026-03-02T16:00:00Z" || created["duration"] != float64(30) {
+		t.Errorf("created meeting = %v", created)
+	}
+
+	if err := p.Delete(context.Background(), m.ID); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != m.ID {
+		t.Errorf("deleted = %q, want %q", deleted, m.ID)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("token requests = %d, want the token to be reused", tokenRequests)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider("", "", "", "", ""); err != nil {
+		t.Errorf("disabled provider: %v", err)
+	}
+	if _, err := NewProvider("jitsi", "https://meet.jit.si", "", "", ""); err != nil {
+		t.Errorf("jitsi provider: %v", err)
+	}
+	if _, err := NewProvider("zoom", "", "account", "", ""); err == nil {
+		t.Error("expected zoom without credentials to fail")
+	}
+	if _, err := NewProvider("webex", "", "", "", ""); err == nil {
+		t.Error("expected unknown provider to fail")
+	}
+}
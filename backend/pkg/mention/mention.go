@@ -0,0 +1,33 @@
+// Package mention parses @mentions in message text. Clients insert mentions from an
+// autocomplete as @[Display Name](user-id), which stays readable in clients that don't
+// render them and identifies the user unambiguously.
+package mention
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+var mentionRe = regexp.MustCompile(`@\[([^\]\n]{1,100})\]\(([0-9a-fA-F-]{36})\)`)
+
+// Parse returns the distinct user IDs mentioned in text, in order of first mention
+func Parse(text string) []uuid.UUID {
+	var ids []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, m := range mentionRe.FindAllStringSubmatch(text, -1) {
+		id, err := uuid.Parse(m[2])
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Format returns the mention markup for a user
+func Format(name string, id uuid.UUID) string {
+	return fmt.Sprintf("@[%s](%s)", name, id)
+}
@@ -0,0 +1,55 @@
+package mention
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParse(t *testing.T) {
+	stefan := uuid.MustParse("5f0c6a8e-1d2b-4c3d-9e4f-a1b2c3d4e5f6")
+	li := uuid.MustParse("0a1b2c3d-4e5f-6071-8293-a4b5c6d7e8f9")
+
+	tests := []struct {
+		name string
+		text string
+		want []uuid.UUID
+	}{
+		{
+			name: "single_mention",
+			text: "Can you take a look, " + Format("Stefan Müller", stefan) + "?",
+			want: []uuid.UUID{stefan},
+		},
+		{
+			name: "duplicates_keep_first_order",
+			text: Format("Li Wei", li) + " and " + Format("Stefan", stefan) + ", thanks " + Format("Li", li),
+			want: []uuid.UUID{li, stefan},
+		},
+		{
+			name: "uppercase_id",
+			text: "@[Stefan](5F0C6A8E-1D2B-4C3D-9E4F-A1B2C3D4E5F6)",
+			want: []uuid.UUID{stefan},
+		},
+		{
+			name: "plain_at_signs_and_emails_are_ignored",
+			text: "Mail me at stefan@xuangong.local or ping @stefan",
+		},
+		{
+			name: "invalid_id_is_ignored",
+			text: "@[Someone](not-a-uuid-not-a-uuid-not-a-uuid-not-a)",
+		},
+		{
+			name: "name_cannot_span_lines",
+			text: "@[Stefan\nMüller](" + stefan.String() + ")",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
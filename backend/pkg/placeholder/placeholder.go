@@ -0,0 +1,51 @@
+// Package placeholder expands {{name}} placeholders in user-written text templates.
+package placeholder
+
+import (
+	"regexp"
+	"strings"
+)
+
+var placeholderRe = regexp.MustCompile(`\{\{\s*([a-zA-Z_]+)\s*\}\}`)
+
+// Names returns the distinct placeholder names used in text, in order of first use
+func Names(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range placeholderRe.FindAllStringSubmatch(text, -1) {
+		name := strings.ToLower(m[1])
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Unknown returns the placeholder names used in text that are not in allowed
+func Unknown(text string, allowed []string) []string {
+	known := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		known[name] = true
+	}
+
+	var unknown []string
+	for _, name := range Names(text) {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+// Expand replaces placeholders with their values. Names are case-insensitive and may be
+// padded with spaces ({{ Student_Name }}). Placeholders without a value are left as written.
+func Expand(text string, values map[string]string) string {
+	return placeholderRe.ReplaceAllStringFunc(text, func(match string) string {
+		name := strings.ToLower(placeholderRe.FindStringSubmatch(match)[1])
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return match
+	})
+}
@@ -0,0 +1,73 @@
+package placeholder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	values := map[string]string{
+		"student_first_name": "Li",
+		"program_name":       "Zhan Zhuang",
+		"submission_title":   "Why {{program_name}}?",
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "replaces_known_placeholders",
+			text: "Hi {{student_first_name}}, nice progress in {{program_name}}!",
+			want: "Hi Li, nice progress in Zhan Zhuang!",
+		},
+		{
+			name: "case_and_spacing_insensitive",
+			text: "Hi {{ Student_First_Name }}",
+			want: "Hi Li",
+		},
+		{
+			name: "repeated_placeholder",
+			text: "{{student_first_name}}, {{student_first_name}}!",
+			want: "Li, Li!",
+		},
+		{
+			name: "unknown_placeholder_left_as_written",
+			text: "See {{video_link}}",
+			want: "See {{video_link}}",
+		},
+		{
+			name: "values_are_not_expanded_again",
+			text: "Re: {{submission_title}}",
+			want: "Re: Why {{program_name}}?",
+		},
+		{
+			name: "no_placeholders",
+			text: "Sink the qi. {single braces} stay.",
+			want: "Sink the qi. {single braces} stay.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Expand(tt.text, values); got != tt.want {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	allowed := []string{"student_name", "program_name"}
+
+	got := Unknown("{{student_name}} {{ Video }} {{program_name}} {{video}} {{teacher}}", allowed)
+	want := []string{"video", "teacher"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unknown() = %v, want %v", got, want)
+	}
+
+	if got := Unknown("Hi {{student_name}}", allowed); got != nil {
+		t.Errorf("Unknown() = %v, want none", got)
+	}
+}
@@ -0,0 +1,61 @@
+// Package richtext renders instructor-written Markdown (with limited inline HTML) into
+// sanitized HTML that clients can display without further escaping.
+package richtext
+
+import (
+	"bytes"
+	"html"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+// MaxSourceLength is the maximum length of a Markdown source in characters.
+// Request validators use the same limit.
+const MaxSourceLength = 5000
+
+var (
+	markdown = goldmark.New(
+		goldmark.WithExtensions(extension.Strikethrough, extension.Linkify),
+		goldmark.WithRendererOptions(
+			goldmarkhtml.WithHardWraps(),
+			// Raw HTML is passed through here and filtered by the policy below
+			goldmarkhtml.WithUnsafe(),
+		),
+	)
+
+	policy = newPolicy()
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements(
+		"p", "br", "hr",
+		"strong", "b", "em", "i", "u", "del", "s",
+		"ul", "ol", "li",
+		"blockquote", "code", "pre",
+		"h1", "h2", "h3", "h4", "h5", "h6",
+	)
+	p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowStandardURLs()
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// Render converts Markdown to sanitized HTML. Empty input yields an empty string.
+func Render(source string) string {
+	if source == "" {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		// Fall back to the escaped source rather than failing the response
+		return "<p>" + html.EscapeString(source) + "</p>"
+	}
+	return policy.Sanitize(buf.String())
+}
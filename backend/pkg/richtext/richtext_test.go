@@ -0,0 +1,77 @@
+package richtext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "empty",
+			input: "",
+		},
+		{
+			name:  "emphasis and lists",
+			input: "Keep the **knees soft**.\n\n- sink the *qi*\n- relax shoulders",
+			want:  []string{"<strong>knees soft</strong>", "<em>qi</em>", "<ul>", "<li>relax shoulders</li>"},
+		},
+		{
+			name:  "hard line breaks",
+			input: "inhale\nexhale",
+			want:  []string{"inhale<br", "exhale"},
+		},
+		{
+			name:    "script tags are stripped",
+			input:   "Breathe <script>alert(1)</script>slowly",
+			want:    []string{"Breathe", "slowly"},
+			notWant: []string{"<script", "alert(1)"},
+		},
+		{
+			name:    "event handlers are stripped",
+			input:   `<b onclick="steal()">root</b>`,
+			want:    []string{"<b>root</b>"},
+			notWant: []string{"onclick"},
+		},
+		{
+			name:    "javascript links are dropped",
+			input:   "[click](javascript:alert(1))",
+			want:    []string{"click"},
+			notWant: []string{"javascript:"},
+		},
+		{
+			name:  "external links get nofollow",
+			input: "[video](https://www.youtube.com/watch?v=abc)",
+			want:  []string{`href="https://www.youtube.com/watch?v=abc"`, `rel="nofollow noopener"`, `target="_blank"`},
+		},
+		{
+			name:    "images are not allowed",
+			input:   "![pose](https://example.com/pose.png)",
+			notWant: []string{"<img"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.input)
+			if tt.input == "" && got != "" {
+				t.Fatalf("expected empty output, got %q", got)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("Render(%q) = %q, missing %q", tt.input, got, w)
+				}
+			}
+			for _, nw := range tt.notWant {
+				if strings.Contains(got, nw) {
+					t.Errorf("Render(%q) = %q, should not contain %q", tt.input, got, nw)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Package secrets reads credentials such as the JWT secret and database URL from where the
+// deployment keeps them: environment variables, mounted files, HashiCorp Vault or AWS Secrets
+// Manager. Values are read on every Get, so rotated secrets are picked up without a restart.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when the provider has no value for a name
+var ErrNotFound = errors.New("secret not found")
+
+// Provider returns secrets by their configuration name, e.g. JWT_SECRET
+type Provider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// httpTimeout bounds calls to remote providers so a slow secret store cannot hang startup
+const httpTimeout = 10 * time.Second
+
+// Env reads secrets with lookup, typically os.LookupEnv
+type Env struct {
+	lookup func(name string) (string, bool)
+}
+
+func NewEnv(lookup func(name string) (string, bool)) *Env {
+	return &Env{lookup: lookup}
+}
+
+func (p *Env) Get(_ context.Context, name string) (string, error) {
+	value, ok := p.lookup(name)
+	if !ok || value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// File reads each secret from a file named like the secret in a directory, as mounted by
+// Docker and Kubernetes secrets. A trailing newline is ignored.
+type File struct {
+	dir string
+}
+
+func NewFile(dir string) *File {
+	return &File{dir: dir}
+}
+
+func (p *File) Get(_ context.Context, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(p.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: httpTimeout}
+}
@@ -0,0 +1,106 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "JWT_SECRET"), []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p := NewFile(dir)
+
+	if got, err := p.Get(context.Background(), "JWT_SECRET"); err != nil || got != "from-file" {
+		t.Errorf("Get(JWT_SECRET) = %q, %v, want the file content without the newline", got, err)
+	}
+	if _, err := p.Get(context.Background(), "DATABASE_URL"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(DATABASE_URL) error = %v, want ErrNotFound", err)
+	}
+	if _, err := p.Get(context.Background(), "../etc/passwd"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(../etc/passwd) error = %v, want an invalid name", err)
+	}
+}
+
+func TestVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/xuangong":
+			w.Write([]byte(`{"data":{"data":{"JWT_SECRET":"from-kv2"},"metadata":{"version":3}}}`))
+		case "/v1/kv/xuangong":
+			w.Write([]byte(`{"data":{"JWT_SECRET":"from-kv1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path, want string
+	}{
+		{"secret/data/xuangong", "from-kv2"},
+		{"/kv/xuangong/", "from-kv1"},
+	}
+	for _, tt := range tests {
+		got, err := NewVault(server.URL, "token", tt.path).Get(context.Background(), "JWT_SECRET")
+		if err != nil || got != tt.want {
+			t.Errorf("%s: Get(JWT_SECRET) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+	if _, err := NewVault(server.URL, "token", "secret/data/xuangong").Get(context.Background(), "DATABASE_URL"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing key error = %v, want ErrNotFound", err)
+	}
+	if _, err := NewVault(server.URL, "wrong", "secret/data/xuangong").Get(context.Background(), "JWT_SECRET"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("forbidden error = %v, want a Vault error", err)
+	}
+}
+
+func TestAWS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			r.Header.Get("X-Amz-Security-Token") != "session" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"Name":"xuangong","SecretString":"{\"DATABASE_URL\":\"postgres://from-aws\"}"}`))
+	}))
+	defer server.Close()
+
+	p := NewAWS("eu-central-1", "xuangong", AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"})
+	p.endpoint = server.URL + "/"
+
+	if got, err := p.Get(context.Background(), "DATABASE_URL"); err != nil || got != "postgres://from-aws" {
+		t.Errorf("Get(DATABASE_URL) = %q, %v, want the value from the secret's JSON", got, err)
+	}
+	if _, err := p.Get(context.Background(), "JWT_SECRET"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(JWT_SECRET) error = %v, want ErrNotFound", err)
+	}
+}
+
+// The example request from the AWS Signature Version 4 documentation
+func TestSignAWSRequest(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signAWSRequest(req, nil, AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"},
+		"us-east-1", "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Vault reads secrets from one HashiCorp Vault KV secret, with a key per secret name. Both KV
+// version 1 (path like secret/xuangong) and version 2 (secret/data/xuangong) are supported.
+type Vault struct {
+	addr   string
+	token  string
+	path   string
+	client *http.Client
+}
+
+func NewVault(addr, token, path string) *Vault {
+	return &Vault{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		path:   strings.Trim(path, "/"),
+		client: newHTTPClient(),
+	}
+}
+
+func (p *Vault) Get(ctx context.Context, name string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.addr+"/v1/"+p.path, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build Vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach Vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("vault returned %d: %s", resp.StatusCode, body)
+	}
+
+	var payload struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("failed to decode Vault response: %w", err)
+	}
+
+	values := payload.Data
+	// KV version 2 nests the values under data.data, next to data.metadata
+	if nested, ok := payload.Data["data"]; ok {
+		if _, hasMetadata := payload.Data["metadata"]; hasMetadata {
+			values = nil
+			if err := json.Unmarshal(nested, &values); err != nil {
+				return "", fmt.Errorf("failed to decode Vault KV v2 data: %w", err)
+			}
+		}
+	}
+	return stringValue(values, name)
+}
+
+// stringValue returns the string stored under name in a decoded JSON object
+func stringValue(values map[string]json.RawMessage, name string) (string, error) {
+	raw, ok := values[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("secret %s is not a string", name)
+	}
+	if value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
@@ -0,0 +1,59 @@
+// Package semver parses and compares app versions of the form major.minor.patch, as the mobile
+// apps report them. A leading v is allowed, missing minor and patch parts count as 0, and
+// pre-release or build suffixes (-beta.1, +42) are ignored, so 2.4.0-beta counts as 2.4.0.
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed app version
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// Parse reads a version such as 2.4.1, v2.4 or 2.4.1-beta.2
+func Parse(s string) (Version, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(trimmed, "-+"); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+	parts := strings.Split(trimmed, ".")
+	if trimmed == "" || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// Compare returns -1 if v is older than other, 1 if it is newer and 0 if they are the same
+func (v Version) Compare(other Version) int {
+	for _, d := range [3]int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Less reports whether v is older than other
+func (v Version) Less(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
@@ -0,0 +1,48 @@
+package semver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Version
+		wantErr bool
+	}{
+		{"2.4.1", Version{2, 4, 1}, false},
+		{"v2.4", Version{2, 4, 0}, false},
+		{"3", Version{3, 0, 0}, false},
+		{"2.4.1-beta.2", Version{2, 4, 1}, false},
+		{"2.4.1+42", Version{2, 4, 1}, false},
+		{"", Version{}, true},
+		{"2.x", Version{}, true},
+		{"1.2.3.4", Version{}, true},
+		{"-1.0", Version{}, true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Parse(%q) = %v, %v, want %v (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.4.0", "2.4.0", 0},
+		{"2.4", "2.4.0", 0},
+		{"2.3.9", "2.4.0", -1},
+		{"2.10.0", "2.9.0", 1},
+		{"3.0.0", "2.99.99", 1},
+		{"2.4.0-beta", "2.4.0", 0},
+	}
+	for _, tt := range tests {
+		a, _ := Parse(tt.a)
+		b, _ := Parse(tt.b)
+		if got := a.Compare(b); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,549 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuangong/backend/internal/database"
+	"github.com/xuangong/backend/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Default test password (hashed)
+const DefaultTestPassword = "Test123!@#"
+
+var defaultPasswordHash string
+
+func init() {
+	// Pre-compute password hash to speed up tests
+	hash, _ := bcrypt.GenerateFromPassword([]byte(DefaultTestPassword), bcrypt.MinCost)
+	defaultPasswordHash = string(hash)
+}
+
+// Builders create test data with sensible defaults, so tests only spell out what matters to them:
+//
+//	admin := testutil.NewUserBuilder().AsAdmin().Create(t, db)
+//	program, exercises := testutil.NewProgramBuilder().OwnedBy(admin).WithExercises(5).CreateWithExercises(t, db)
+//
+// Generated emails are unique, so builders are safe to use from parallel tests.
+
+// UserBuilder creates users. Defaults to an active student with DefaultTestPassword.
+type UserBuilder struct {
+	user models.User
+}
+
+func NewUserBuilder() *UserBuilder {
+	return &UserBuilder{user: models.User{
+		Email:    fmt.Sprintf("user-%s@test.com", uuid.NewString()[:8]),
+		FullName: "Test User",
+		Role:     models.RoleStudent,
+		IsActive: true,
+	}}
+}
+
+func (b *UserBuilder) WithEmail(email string) *UserBuilder {
+	b.user.Email = email
+	return b
+}
+
+func (b *UserBuilder) WithName(name string) *UserBuilder {
+	b.user.FullName = name
+	return b
+}
+
+func (b *UserBuilder) AsAdmin() *UserBuilder {
+	b.user.Role = models.RoleAdmin
+	return b
+}
+
+func (b *UserBuilder) Inactive() *UserBuilder {
+	b.user.IsActive = false
+	return b
+}
+
+func (b *UserBuilder) Create(t testing.TB, db database.DB) *models.User {
+	t.Helper()
+
+	user := b.user
+	user.ID = uuid.New()
+	user.PasswordHash = defaultPasswordHash
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+
+	exec(t, db, "user", `
+		INSERT INTO users (id, email, password_hash, full_name, role, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, user.ID, user.Email, user.PasswordHash, user.FullName, user.Role, user.IsActive, user.CreatedAt, user.UpdatedAt)
+
+	return &user
+}
+
+// ProgramBuilder creates programs, optionally with exercises. Defaults to a private,
+// non-template program without an owner.
+type ProgramBuilder struct {
+	program   models.Program
+	exercises []*ExerciseBuilder
+}
+
+func NewProgramBuilder() *ProgramBuilder {
+	return &ProgramBuilder{program: models.Program{
+		Name:                 "Test Program",
+		Description:          "Test program description",
+		RepetitionsPlanned:   intPtr(10),
+		RepetitionsCompleted: intPtr(0),
+		Tags:                 []string{"test"},
+		Metadata:             make(map[string]interface{}),
+	}}
+}
+
+func (b *ProgramBuilder) WithName(name string) *ProgramBuilder {
+	b.program.Name = name
+	return b
+}
+
+func (b *ProgramBuilder) OwnedBy(user *models.User) *ProgramBuilder {
+	b.program.OwnedBy = &user.ID
+	return b
+}
+
+// AsTemplate marks the program as a public template
+func (b *ProgramBuilder) AsTemplate() *ProgramBuilder {
+	b.program.IsTemplate = true
+	b.program.IsPublic = true
+	return b
+}
+
+func (b *ProgramBuilder) Public() *ProgramBuilder {
+	b.program.IsPublic = true
+	return b
+}
+
+func (b *ProgramBuilder) WithTags(tags ...string) *ProgramBuilder {
+	b.program.Tags = tags
+	return b
+}
+
+// WithExercises adds n timed exercises named "Exercise 1" to "Exercise n"
+func (b *ProgramBuilder) WithExercises(n int) *ProgramBuilder {
+	for i := 0; i < n; i++ {
+		b.exercises = append(b.exercises, NewExerciseBuilder().WithName(fmt.Sprintf("Exercise %d", len(b.exercises)+1)))
+	}
+	return b
+}
+
+// WithExercise adds a customized exercise; its program and order are set on creation
+func (b *ProgramBuilder) WithExercise(exercise *ExerciseBuilder) *ProgramBuilder {
+	b.exercises = append(b.exercises, exercise)
+	return b
+}
+
+func (b *ProgramBuilder) Create(t testing.TB, db database.DB) *models.Program {
+	t.Helper()
+	program, _ := b.CreateWithExercises(t, db)
+	return program
+}
+
+func (b *ProgramBuilder) CreateWithExercises(t testing.TB, db database.DB) (*models.Program, []models.Exercise) {
+	t.Helper()
+
+	program := b.program
+	program.ID = uuid.New()
+	program.CreatedAt = time.Now()
+	program.UpdatedAt = program.CreatedAt
+
+	exec(t, db, "program", `
+		INSERT INTO programs (
+			id, name, description, owned_by, is_template, is_public,
+			repetitions_planned, repetitions_completed, tags, metadata,
+			created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, program.ID, program.Name, program.Description, program.OwnedBy, program.IsTemplate, program.IsPublic,
+		program.RepetitionsPlanned, program.RepetitionsCompleted, program.Tags, program.Metadata,
+		program.CreatedAt, program.UpdatedAt)
+
+	exercises := make([]models.Exercise, 0, len(b.exercises))
+	for i, eb := range b.exercises {
+		exercises = append(exercises, *eb.ForProgram(&program).AtIndex(i).Create(t, db))
+	}
+
+	return &program, exercises
+}
+
+// ExerciseBuilder creates exercises. Defaults to a 60 second timed exercise at index 0.
+type ExerciseBuilder struct {
+	exercise models.Exercise
+}
+
+func NewExerciseBuilder() *ExerciseBuilder {
+	return &ExerciseBuilder{exercise: models.Exercise{
+		Name:             "Test Exercise",
+		Description:      "Test exercise description",
+		ExerciseType:     models.ExerciseTypeTimed,
+		DurationSeconds:  intPtr(60),
+		RestAfterSeconds: 10,
+		Metadata:         make(map[string]interface{}),
+	}}
+}
+
+func (b *ExerciseBuilder) ForProgram(program *models.Program) *ExerciseBuilder {
+	b.exercise.ProgramID = program.ID
+	return b
+}
+
+func (b *ExerciseBuilder) WithName(name string) *ExerciseBuilder {
+	b.exercise.Name = name
+	return b
+}
+
+func (b *ExerciseBuilder) AtIndex(index int) *ExerciseBuilder {
+	b.exercise.OrderIndex = index
+	return b
+}
+
+func (b *ExerciseBuilder) Timed(seconds int) *ExerciseBuilder {
+	b.exercise.ExerciseType = models.ExerciseTypeTimed
+	b.exercise.DurationSeconds = intPtr(seconds)
+	b.exercise.Repetitions = nil
+	return b
+}
+
+func (b *ExerciseBuilder) Repetitions(count int) *ExerciseBuilder {
+	b.exercise.ExerciseType = models.ExerciseTypeRepetition
+	b.exercise.Repetitions = intPtr(count)
+	b.exercise.DurationSeconds = nil
+	return b
+}
+
+// WithSides makes the exercise run once per side for the given duration
+func (b *ExerciseBuilder) WithSides(sideSeconds int) *ExerciseBuilder {
+	b.exercise.HasSides = true
+	b.exercise.SideDurationSeconds = intPtr(sideSeconds)
+	return b
+}
+
+func (b *ExerciseBuilder) Create(t testing.TB, db database.DB) *models.Exercise {
+	t.Helper()
+
+	if b.exercise.ProgramID == uuid.Nil {
+		t.Fatal("ExerciseBuilder: ForProgram is required")
+	}
+
+	exercise := b.exercise
+	exercise.ID = uuid.New()
+	exercise.CreatedAt = time.Now()
+
+	exec(t, db, "exercise", `
+		INSERT INTO exercises (
+			id, program_id, name, description, order_index, exercise_type,
+			duration_seconds, repetitions, rest_after_seconds, has_sides,
+			side_duration_seconds, metadata, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, exercise.ID, exercise.ProgramID, exercise.Name, exercise.Description, exercise.OrderIndex, exercise.ExerciseType,
+		exercise.DurationSeconds, exercise.Repetitions, exercise.RestAfterSeconds, exercise.HasSides,
+		exercise.SideDurationSeconds, exercise.Metadata, exercise.CreatedAt)
+
+	return &exercise
+}
+
+// SessionBuilder creates practice sessions, optionally with exercise logs. Defaults to an
+// in-progress session started now; a student and program are created when not given.
+type SessionBuilder struct {
+	session models.PracticeSession
+	logFor  []models.Exercise
+}
+
+func NewSessionBuilder() *SessionBuilder {
+	return &SessionBuilder{session: models.PracticeSession{
+		StartedAt: time.Now(),
+	}}
+}
+
+func (b *SessionBuilder) ForUser(user *models.User) *SessionBuilder {
+	b.session.UserID = user.ID
+	return b
+}
+
+func (b *SessionBuilder) ForProgram(program *models.Program) *SessionBuilder {
+	b.session.ProgramID = program.ID
+	return b
+}
+
+func (b *SessionBuilder) StartedAt(startedAt time.Time) *SessionBuilder {
+	b.session.StartedAt = startedAt
+	return b
+}
+
+// Completed marks the session as finished after duration with full completion
+func (b *SessionBuilder) Completed(duration time.Duration) *SessionBuilder {
+	completedAt := b.session.StartedAt.Add(duration)
+	seconds := int(duration.Seconds())
+	rate := 100.0
+	b.session.CompletedAt = &completedAt
+	b.session.TotalDurationSeconds = &seconds
+	b.session.CompletionRate = &rate
+	return b
+}
+
+func (b *SessionBuilder) WithNotes(notes string) *SessionBuilder {
+	b.session.Notes = &notes
+	return b
+}
+
+// WithLogs adds a completed exercise log for each exercise
+func (b *SessionBuilder) WithLogs(exercises ...models.Exercise) *SessionBuilder {
+	b.logFor = append(b.logFor, exercises...)
+	return b
+}
+
+func (b *SessionBuilder) Create(t testing.TB, db database.DB) *models.PracticeSession {
+	t.Helper()
+	session, _ := b.CreateWithLogs(t, db)
+	return session
+}
+
+func (b *SessionBuilder) CreateWithLogs(t testing.TB, db database.DB) (*models.PracticeSession, []models.ExerciseLog) {
+	t.Helper()
+
+	session := b.session
+	if session.UserID == uuid.Nil {
+		session.UserID = NewUserBuilder().Create(t, db).ID
+	}
+	if session.ProgramID == uuid.Nil {
+		session.ProgramID = NewProgramBuilder().Create(t, db).ID
+	}
+	session.ID = uuid.New()
+
+	exec(t, db, "session", `
+		INSERT INTO practice_sessions (
+			id, user_id, program_id, started_at, completed_at,
+			total_duration_seconds, completion_rate, notes
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, session.ID, session.UserID, session.ProgramID, session.StartedAt, session.CompletedAt,
+		session.TotalDurationSeconds, session.CompletionRate, session.Notes)
+
+	logs := make([]models.ExerciseLog, 0, len(b.logFor))
+	startedAt := session.StartedAt
+	for _, exercise := range b.logFor {
+		exerciseID := exercise.ID
+		duration := 60
+		if exercise.DurationSeconds != nil {
+			duration = *exercise.DurationSeconds
+		}
+		logStart := startedAt
+		completedAt := logStart.Add(time.Duration(duration) * time.Second)
+		startedAt = completedAt
+
+		log := models.ExerciseLog{
+			ID:                     uuid.New(),
+			SessionID:              session.ID,
+			ExerciseID:             &exerciseID,
+			StartedAt:              &logStart,
+			CompletedAt:            &completedAt,
+			PlannedDurationSeconds: exercise.DurationSeconds,
+			ActualDurationSeconds:  &duration,
+			RepetitionsPlanned:     exercise.Repetitions,
+			RepetitionsCompleted:   exercise.Repetitions,
+		}
+		exec(t, db, "exercise log", `
+			INSERT INTO exercise_logs (
+				id, session_id, exercise_id, started_at, completed_at,
+				planned_duration_seconds, actual_duration_seconds,
+				repetitions_planned, repetitions_completed, skipped
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, log.ID, log.SessionID, log.ExerciseID, log.StartedAt, log.CompletedAt,
+			log.PlannedDurationSeconds, log.ActualDurationSeconds,
+			log.RepetitionsPlanned, log.RepetitionsCompleted, log.Skipped)
+		logs = append(logs, log)
+	}
+
+	return &session, logs
+}
+
+// SubmissionBuilder creates submission threads. A student and program are created when not
+// given; messages are posted one second apart in the order they were added.
+type SubmissionBuilder struct {
+	submission models.Submission
+	messages   []models.SubmissionMessage
+	readBy     []uuid.UUID
+}
+
+func NewSubmissionBuilder() *SubmissionBuilder {
+	return &SubmissionBuilder{submission: models.Submission{
+		Title: "Test Submission",
+	}}
+}
+
+func (b *SubmissionBuilder) ForProgram(program *models.Program) *SubmissionBuilder {
+	b.submission.ProgramID = program.ID
+	return b
+}
+
+// By sets the student who opened the submission
+func (b *SubmissionBuilder) By(user *models.User) *SubmissionBuilder {
+	b.submission.UserID = user.ID
+	return b
+}
+
+func (b *SubmissionBuilder) WithTitle(title string) *SubmissionBuilder {
+	b.submission.Title = title
+	return b
+}
+
+func (b *SubmissionBuilder) WithMessage(author *models.User, content string) *SubmissionBuilder {
+	b.messages = append(b.messages, models.SubmissionMessage{UserID: author.ID, Content: content})
+	return b
+}
+
+func (b *SubmissionBuilder) WithVideoMessage(author *models.User, content, youtubeURL string) *SubmissionBuilder {
+	b.messages = append(b.messages, models.SubmissionMessage{UserID: author.ID, Content: content, YouTubeURL: &youtubeURL})
+	return b
+}
+
+// ReadBy marks every message in the thread as read by the user
+func (b *SubmissionBuilder) ReadBy(user *models.User) *SubmissionBuilder {
+	b.readBy = append(b.readBy, user.ID)
+	return b
+}
+
+func (b *SubmissionBuilder) Create(t testing.TB, db database.DB) *models.Submission {
+	t.Helper()
+	thread := b.CreateWithMessages(t, db)
+	return &thread.Submission
+}
+
+func (b *SubmissionBuilder) CreateWithMessages(t testing.TB, db database.DB) *models.SubmissionWithMessages {
+	t.Helper()
+
+	submission := b.submission
+	if submission.UserID == uuid.Nil {
+		submission.UserID = NewUserBuilder().Create(t, db).ID
+	}
+	if submission.ProgramID == uuid.Nil {
+		submission.ProgramID = NewProgramBuilder().Create(t, db).ID
+	}
+	submission.ID = uuid.New()
+	submission.CreatedAt = time.Now()
+	submission.UpdatedAt = submission.CreatedAt
+
+	exec(t, db, "submission", `
+		INSERT INTO submissions (id, program_id, user_id, title, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, submission.ID, submission.ProgramID, submission.UserID, submission.Title, submission.CreatedAt, submission.UpdatedAt)
+
+	messages := make([]models.SubmissionMessage, 0, len(b.messages))
+	for i, m := range b.messages {
+		mb := &MessageBuilder{message: m}
+		mb.message.SubmissionID = submission.ID
+		mb.message.CreatedAt = submission.CreatedAt.Add(time.Duration(i) * time.Second)
+		messages = append(messages, *mb.Create(t, db))
+	}
+
+	for _, userID := range b.readBy {
+		for _, m := range messages {
+			MarkMessageAsRead(t, db, userID, m.ID)
+		}
+	}
+
+	return &models.SubmissionWithMessages{Submission: submission, Messages: messages}
+}
+
+// MessageBuilder posts a single message to an existing submission
+type MessageBuilder struct {
+	message models.SubmissionMessage
+}
+
+func NewMessageBuilder() *MessageBuilder {
+	return &MessageBuilder{message: models.SubmissionMessage{
+		Content: "Test message",
+	}}
+}
+
+func (b *MessageBuilder) InSubmission(submission *models.Submission) *MessageBuilder {
+	b.message.SubmissionID = submission.ID
+	return b
+}
+
+func (b *MessageBuilder) By(author *models.User) *MessageBuilder {
+	b.message.UserID = author.ID
+	return b
+}
+
+func (b *MessageBuilder) WithContent(content string) *MessageBuilder {
+	b.message.Content = content
+	return b
+}
+
+func (b *MessageBuilder) WithVideo(youtubeURL string) *MessageBuilder {
+	b.message.YouTubeURL = &youtubeURL
+	return b
+}
+
+// At sets when the message was posted (default now)
+func (b *MessageBuilder) At(createdAt time.Time) *MessageBuilder {
+	b.message.CreatedAt = createdAt
+	return b
+}
+
+func (b *MessageBuilder) Create(t testing.TB, db database.DB) *models.SubmissionMessage {
+	t.Helper()
+
+	if b.message.SubmissionID == uuid.Nil || b.message.UserID == uuid.Nil {
+		t.Fatal("MessageBuilder: InSubmission and By are required")
+	}
+
+	message := b.message
+	message.ID = uuid.New()
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now()
+	}
+
+	exec(t, db, "message", `
+		INSERT INTO submission_messages (id, submission_id, user_id, content, youtube_url, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, message.ID, message.SubmissionID, message.UserID, message.Content, message.YouTubeURL, message.CreatedAt)
+
+	return &message
+}
+
+// AssignProgramToUser creates a user_program relationship.
+func AssignProgramToUser(t testing.TB, db database.DB, userID, programID, assignedByID uuid.UUID) {
+	t.Helper()
+
+	exec(t, db, "program assignment", `
+		INSERT INTO user_programs (id, user_id, program_id, assigned_by, assigned_at, is_active, custom_settings)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, uuid.New(), userID, programID, assignedByID, time.Now(), true, make(map[string]interface{}))
+}
+
+// MarkMessageAsRead marks a message as read by a user.
+func MarkMessageAsRead(t testing.TB, db database.DB, userID, messageID uuid.UUID) {
+	t.Helper()
+
+	exec(t, db, "read status", `
+		INSERT INTO message_read_status (user_id, message_id, read_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, message_id) DO NOTHING
+	`, userID, messageID, time.Now())
+}
+
+func exec(t testing.TB, db database.DB, what, query string, args ...interface{}) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := db.Exec(ctx, query, args...); err != nil {
+		t.Fatalf("Failed to create test %s: %v", what, err)
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
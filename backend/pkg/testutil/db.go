@@ -25,10 +25,24 @@ const (
 
 // SetupTestDB creates and configures a test database connection pool.
 // It runs all migrations and returns a ready-to-use connection pool.
-// Call TeardownTestDB to clean up after tests.
-func SetupTestDB(t *testing.T) *pgxpool.Pool {
+// Call TeardownTestDB to clean up after tests. Prefer SetupTestTx, which
+// isolates tests without truncating tables.
+func SetupTestDB(t testing.TB) *pgxpool.Pool {
 	t.Helper()
 
+	pool, err := openTestPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Truncate all tables to start with clean state
+	TruncateTables(t, pool)
+
+	return pool
+}
+
+// openTestPool connects to the test database and runs all migrations
+func openTestPool() (*pgxpool.Pool, error) {
 	// Get test database URL from environment or use default
 	dbURL := os.Getenv("TEST_DATABASE_URL")
 	if dbURL == "" {
@@ -46,23 +60,20 @@ func SetupTestDB(t *testing.T) *pgxpool.Pool {
 	// Create connection pool
 	pool, err := database.NewPool(dbConfig)
 	if err != nil {
-		t.Fatalf("Failed to create test database pool: %v", err)
+		return nil, fmt.Errorf("failed to create test database pool: %w", err)
 	}
 
 	// Run migrations to ensure schema is up to date
 	if err := database.RunMigrations(dbURL, "../../migrations"); err != nil {
 		pool.Close()
-		t.Fatalf("Failed to run migrations on test database: %v", err)
+		return nil, fmt.Errorf("failed to run migrations on test database: %w", err)
 	}
 
-	// Truncate all tables to start with clean state
-	TruncateTables(t, pool)
-
-	return pool
+	return pool, nil
 }
 
 // TeardownTestDB closes the database connection pool and cleans up.
-func TeardownTestDB(t *testing.T, pool *pgxpool.Pool) {
+func TeardownTestDB(t testing.TB, pool *pgxpool.Pool) {
 	t.Helper()
 
 	if pool != nil {
@@ -74,7 +85,7 @@ func TeardownTestDB(t *testing.T, pool *pgxpool.Pool) {
 
 // TruncateTables removes all data from test tables while preserving schema.
 // This is faster than dropping/recreating tables between tests.
-func TruncateTables(t *testing.T, pool *pgxpool.Pool) {
+func TruncateTables(t testing.TB, pool *pgxpool.Pool) {
 	t.Helper()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -102,25 +113,25 @@ func TruncateTables(t *testing.T, pool *pgxpool.Pool) {
 
 // ExecuteSQL is a helper function to execute arbitrary SQL during test setup.
 // Useful for creating specific test scenarios.
-func ExecuteSQL(t *testing.T, pool *pgxpool.Pool, query string, args ...interface{}) {
+func ExecuteSQL(t testing.TB, db database.DB, query string, args ...interface{}) {
 	t.Helper()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if _, err := pool.Exec(ctx, query, args...); err != nil {
+	if _, err := db.Exec(ctx, query, args...); err != nil {
 		t.Fatalf("Failed to execute SQL: %v\nQuery: %s", err, query)
 	}
 }
 
 // QueryRow is a helper function to query a single row during tests.
-func QueryRow(t *testing.T, pool *pgxpool.Pool, query string, args ...interface{}) map[string]interface{} {
+func QueryRow(t testing.TB, db database.DB, query string, args ...interface{}) map[string]interface{} {
 	t.Helper()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	rows, err := pool.Query(ctx, query, args...)
+	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
 		t.Fatalf("Failed to query: %v\nQuery: %s", err, query)
 	}
@@ -145,7 +156,7 @@ func QueryRow(t *testing.T, pool *pgxpool.Pool, query string, args ...interface{
 }
 
 // AssertRowCount checks that a table has the expected number of rows.
-func AssertRowCount(t *testing.T, pool *pgxpool.Pool, table string, expected int) {
+func AssertRowCount(t testing.TB, db database.DB, table string, expected int) {
 	t.Helper()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -153,7 +164,7 @@ func AssertRowCount(t *testing.T, pool *pgxpool.Pool, table string, expected int
 
 	var count int
 	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", table)
-	err := pool.QueryRow(ctx, query).Scan(&count)
+	err := db.QueryRow(ctx, query).Scan(&count)
 	if err != nil {
 		t.Fatalf("Failed to count rows in %s: %v", table, err)
 	}
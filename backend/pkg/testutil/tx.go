@@ -0,0 +1,140 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/xuangong/backend/internal/database"
+)
+
+var (
+	sharedPoolOnce sync.Once
+	sharedPool     *pgxpool.Pool
+	sharedPoolErr  error
+)
+
+// SetupTestTx returns a database handle scoped to a transaction that is rolled back when
+// the test ends. Pass it to repositories and fixtures in place of a pool.
+//
+// Nothing is ever committed, so tests do not need to truncate tables and test packages can
+// run against the same database concurrently. The connection pool and migrations are shared
+// by all tests in the package.
+//
+// Each statement runs in its own savepoint, so an expected error (a constraint violation,
+// for example) does not abort the rest of the test. Keep in mind that now() is fixed for the
+// whole transaction, and that parallel tests inserting the same unique values will wait on
+// each other until one of them rolls back.
+func SetupTestTx(t testing.TB) database.DB {
+	t.Helper()
+
+	sharedPoolOnce.Do(func() {
+		sharedPool, sharedPoolErr = openTestPool()
+	})
+	if sharedPoolErr != nil {
+		t.Fatal(sharedPoolErr)
+	}
+
+	tx, err := sharedPool.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to begin test transaction: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := tx.Rollback(context.Background()); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			t.Errorf("Failed to roll back test transaction: %v", err)
+		}
+	})
+
+	return &txDB{tx: tx}
+}
+
+// txDB runs every statement of a test transaction in a savepoint
+type txDB struct {
+	tx pgx.Tx
+}
+
+func (d *txDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	sp, err := d.tx.Begin(ctx)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	tag, err := sp.Exec(ctx, sql, args...)
+	if err != nil {
+		_ = sp.Rollback(ctx)
+		return tag, err
+	}
+	return tag, sp.Commit(ctx)
+}
+
+func (d *txDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	sp, err := d.tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sp.Query(ctx, sql, args...)
+	if err != nil {
+		_ = sp.Rollback(ctx)
+		return nil, err
+	}
+	return &savepointRows{Rows: rows, sp: sp, ctx: ctx}, nil
+}
+
+func (d *txDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := d.Query(ctx, sql, args...)
+	return &savepointRow{rows: rows, err: err}
+}
+
+// Begin starts a nested savepoint, so repository transactions commit into the test transaction
+func (d *txDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return d.tx.Begin(ctx)
+}
+
+// savepointRows releases its savepoint once the rows are closed, or rolls it back on error
+type savepointRows struct {
+	pgx.Rows
+	sp     pgx.Tx
+	ctx    context.Context
+	closed bool
+}
+
+func (r *savepointRows) Close() {
+	r.Rows.Close()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	if r.Rows.Err() != nil {
+		_ = r.sp.Rollback(r.ctx)
+		return
+	}
+	_ = r.sp.Commit(r.ctx)
+}
+
+// savepointRow mirrors pgx's QueryRow semantics on top of savepointRows
+type savepointRow struct {
+	rows pgx.Rows
+	err  error
+}
+
+func (r *savepointRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+	r.rows.Close()
+	return r.rows.Err()
+}
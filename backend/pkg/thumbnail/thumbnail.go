@@ -0,0 +1,81 @@
+// Package thumbnail decodes uploaded images and renders resized JPEG variants.
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // register PNG decoder
+
+	"golang.org/x/image/draw"
+)
+
+// MaxPixels bounds the decoded image size to protect against decompression bombs
+const MaxPixels = 40_000_000
+
+// ContentType is the MIME type of every generated variant
+const ContentType = "image/jpeg"
+
+var (
+	// ErrUnsupportedFormat indicates the data is not a JPEG or PNG image
+	ErrUnsupportedFormat = errors.New("unsupported image format, use JPEG or PNG")
+
+	// ErrTooLarge indicates the image dimensions exceed MaxPixels
+	ErrTooLarge = errors.New("image dimensions are too large")
+)
+
+// Size is a named thumbnail width. Height follows the source aspect ratio.
+type Size struct {
+	Name  string
+	Width int
+}
+
+// Generate decodes data and returns one JPEG per size, keyed by size name.
+// Images are never upscaled; transparent areas are flattened onto white.
+func Generate(data []byte, sizes []Size) (map[string][]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	variants := make(map[string][]byte, len(sizes))
+	for _, size := range sizes {
+		encoded, err := resize(src, size.Width)
+		if err != nil {
+			return nil, err
+		}
+		variants[size.Name] = encoded
+	}
+	return variants, nil
+}
+
+func resize(src image.Image, width int) ([]byte, error) {
+	bounds := src.Bounds()
+	if width <= 0 || width > bounds.Dx() {
+		width = bounds.Dx()
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,56 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.NRGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerate(t *testing.T) {
+	sizes := []Size{{Name: "small", Width: 100}, {Name: "large", Width: 1000}}
+
+	variants, err := Generate(pngImage(t, 400, 200), sizes)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	want := map[string][2]int{
+		"small": {100, 50},
+		"large": {400, 200}, // never upscaled
+	}
+	for name, dims := range want {
+		data, ok := variants[name]
+		if !ok {
+			t.Fatalf("missing variant %q", name)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("variant %q is not a JPEG: %v", name, err)
+		}
+		if got := img.Bounds(); got.Dx() != dims[0] || got.Dy() != dims[1] {
+			t.Errorf("variant %q is %dx%d, want %dx%d", name, got.Dx(), got.Dy(), dims[0], dims[1])
+		}
+	}
+}
+
+func TestGenerateRejectsInvalidInput(t *testing.T) {
+	if _, err := Generate([]byte("not an image"), []Size{{Name: "small", Width: 100}}); err != ErrUnsupportedFormat {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
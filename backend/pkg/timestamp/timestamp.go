@@ -0,0 +1,91 @@
+// Package timestamp is the API's wire format for points in time: RFC3339 with an explicit
+// offset, such as 2026-03-14T09:30:00Z or 2026-03-14T10:30:00+01:00. Times are normalized to
+// UTC on the way in and out, which is how they are stored.
+package timestamp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Layout is the format timestamps are written in: RFC3339 in UTC, with fractional seconds
+// only when there are any
+const Layout = time.RFC3339Nano
+
+// Time is a point in time read from and written to requests as RFC3339 in UTC. It decodes from
+// JSON bodies as well as query and form parameters.
+type Time struct {
+	time.Time
+}
+
+// ParseError reports a value that is not an RFC3339 timestamp with an offset
+type ParseError struct {
+	Value string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("Invalid timestamp %q. Expected RFC3339 with a time zone, e.g. 2026-03-14T09:30:00Z", e.Value)
+}
+
+// New wraps t, converted to UTC
+func New(t time.Time) Time {
+	return Time{t.UTC()}
+}
+
+// Parse reads a strict RFC3339 timestamp. Values without an offset are rejected rather than
+// guessed to be in some zone.
+func Parse(value string) (Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return Time{}, &ParseError{Value: value}
+	}
+	return New(t), nil
+}
+
+// Ptr returns the time of an optional timestamp
+func (t *Time) Ptr() *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := t.UTC()
+	return &v
+}
+
+func (t Time) String() string {
+	return t.UTC().Format(Layout)
+}
+
+func (t Time) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *Time) UnmarshalText(data []byte) error {
+	parsed, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalJSON and UnmarshalJSON override the embedded time.Time's, which keep the offset
+func (t Time) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return &ParseError{Value: string(data)}
+	}
+	return t.UnmarshalText([]byte(value))
+}
+
+// UnmarshalParam decodes query and form parameters bound by gin
+func (t *Time) UnmarshalParam(param string) error {
+	return t.UnmarshalText([]byte(param))
+}
@@ -0,0 +1,69 @@
+package timestamp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{"2026-03-14T09:30:00Z", time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC), true},
+		{"2026-03-14T10:30:00+01:00", time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC), true},
+		{"2026-03-14T09:30:00.25Z", time.Date(2026, 3, 14, 9, 30, 0, 250_000_000, time.UTC), true},
+		{"2026-03-14T09:30:00", time.Time{}, false},
+		{"2026-03-14", time.Time{}, false},
+		{"14.03.2026 09:30", time.Time{}, false},
+		{"", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if !tt.ok {
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("Parse(%q) error = %v, want a ParseError", tt.value, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("Parse(%q) = %v, want %v in UTC", tt.value, got.Time, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var req struct {
+		At       Time  `json:"at"`
+		Optional *Time `json:"optional"`
+	}
+	if err := json.Unmarshal([]byte(`{"at": "2026-03-14T10:30:00+01:00", "optional": null}`), &req); err != nil {
+		t.Fatalf("Unmarshal error = %v", err)
+	}
+	if req.Optional != nil || req.Optional.Ptr() != nil {
+		t.Errorf("Optional = %v, want nil", req.Optional)
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Marshal error = %v", err)
+	}
+	if string(data) != `{"at":"2026-03-14T09:30:00Z","optional":null}` {
+		t.Errorf("Marshal = %s, want the time in UTC", data)
+	}
+
+	err = json.Unmarshal([]byte(`{"at": "2026-03-14 09:30"}`), &req)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("Unmarshal of a bad timestamp error = %v, want a ParseError", err)
+	}
+}
@@ -0,0 +1,139 @@
+// Package tts defines a pluggable text-to-speech provider used to pre-generate audio cues.
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/xuangong/backend/pkg/dependency"
+)
+
+// ErrDisabled is returned when no TTS provider is configured
+var ErrDisabled = errors.New("text-to-speech is not configured")
+
+// Audio is a synthesized MP3 clip
+type Audio struct {
+	Data        []byte
+	ContentType string
+}
+
+// Provider synthesizes speech for a piece of text in a given language
+type Provider interface {
+	Synthesize(ctx context.Context, text, language string) (*Audio, error)
+}
+
+// DisabledProvider is used when TTS is not configured
+type DisabledProvider struct{}
+
+func (DisabledProvider) Synthesize(ctx context.Context, text, language string) (*Audio, error) {
+	return nil, ErrDisabled
+}
+
+// HTTPProvider calls a generic HTTP synthesis endpoint.
+// It POSTs {"text": ..., "language": ...} as JSON and expects MP3 bytes in the response body.
+type HTTPProvider struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func NewHTTPProvider(url, apiKey string) *HTTPProvider {
+	return &HTTPProvider{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *HTTPProvider) Synthesize(ctx context.Context, text, language string) (*Audio, error) {
+	body, err := json.Marshal(map[string]string{
+		"text":     text,
+		"language": language,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "audio/mpeg")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("tts request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tts provider returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tts response: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, errors.New("tts provider returned empty audio")
+	}
+
+	return &Audio{
+		Data:        data,
+		ContentType: "audio/mpeg",
+	}, nil
+}
+
+// NewProvider returns the provider selected by name ("http" or "" for disabled)
+func NewProvider(name, url, apiKey string) (Provider, error) {
+	switch name {
+	case "":
+		return DisabledProvider{}, nil
+	case "http":
+		if url == "" {
+			return nil, errors.New("TTS_URL is required for the http provider")
+		}
+		return NewHTTPProvider(url, apiKey), nil
+	default:
+		return nil, fmt.Errorf("unknown TTS provider %q", name)
+	}
+}
+
+// breakerProvider guards a provider with a circuit breaker so an unreachable TTS service fails fast
+type breakerProvider struct {
+	provider Provider
+	breaker  *dependency.Breaker
+}
+
+// WithBreaker wraps p so calls fail with dependency.ErrOpen while b is open
+func WithBreaker(p Provider, b *dependency.Breaker) Provider {
+	return &breakerProvider{provider: p, breaker: b}
+}
+
+func (p *breakerProvider) Synthesize(ctx context.Context, text, language string) (*Audio, error) {
+	var audio *Audio
+	var synthErr error
+	err := p.breaker.Do(func() error {
+		audio, synthErr = p.provider.Synthesize(ctx, text, language)
+		// Being switched off is configuration, not an outage
+		if errors.Is(synthErr, ErrDisabled) {
+			return nil
+		}
+		return synthErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return audio, synthErr
+}